@@ -0,0 +1,422 @@
+// The control-plane API of the gateway. It mirrors the REST management API served under
+// /admin: services are described by the same JSON documents, revisions guard concurrent
+// modifications like ETags do, and WatchServices streams route changes as they happen.
+
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.5.1
+// - protoc             (unknown)
+// source: admin.proto
+
+package adminv1
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+	emptypb "google.golang.org/protobuf/types/known/emptypb"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.64.0 or later.
+const _ = grpc.SupportPackageIsVersion9
+
+const (
+	GatewayAdmin_ListServices_FullMethodName      = "/gateway.admin.v1.GatewayAdmin/ListServices"
+	GatewayAdmin_GetService_FullMethodName        = "/gateway.admin.v1.GatewayAdmin/GetService"
+	GatewayAdmin_FindServiceByName_FullMethodName = "/gateway.admin.v1.GatewayAdmin/FindServiceByName"
+	GatewayAdmin_CreateService_FullMethodName     = "/gateway.admin.v1.GatewayAdmin/CreateService"
+	GatewayAdmin_UpdateService_FullMethodName     = "/gateway.admin.v1.GatewayAdmin/UpdateService"
+	GatewayAdmin_DeleteService_FullMethodName     = "/gateway.admin.v1.GatewayAdmin/DeleteService"
+	GatewayAdmin_GetStatus_FullMethodName         = "/gateway.admin.v1.GatewayAdmin/GetStatus"
+	GatewayAdmin_WatchServices_FullMethodName     = "/gateway.admin.v1.GatewayAdmin/WatchServices"
+)
+
+// GatewayAdminClient is the client API for GatewayAdmin service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+//
+// GatewayAdmin manages the services the gateway routes to. Every call must carry the
+// "authorization" metadata of a principal with the admin role, either "Bearer <token>" or
+// "Basic <credentials>" when a credentials directory is configured.
+type GatewayAdminClient interface {
+	// ListServices returns every service, like GET /admin/services
+	ListServices(ctx context.Context, in *ListServicesRequest, opts ...grpc.CallOption) (*ListServicesResponse, error)
+	// GetService returns a service by ID, like GET /admin/services/{id}
+	GetService(ctx context.Context, in *GetServiceRequest, opts ...grpc.CallOption) (*Service, error)
+	// FindServiceByName returns a service by name, like GET /admin/services/name/{name}
+	FindServiceByName(ctx context.Context, in *FindServiceByNameRequest, opts ...grpc.CallOption) (*Service, error)
+	// CreateService creates a service, like POST /admin/services
+	CreateService(ctx context.Context, in *CreateServiceRequest, opts ...grpc.CallOption) (*Service, error)
+	// UpdateService replaces a service, like PUT /admin/services/{id}
+	UpdateService(ctx context.Context, in *UpdateServiceRequest, opts ...grpc.CallOption) (*Service, error)
+	// DeleteService deletes a service, like DELETE /admin/services/{id}
+	DeleteService(ctx context.Context, in *DeleteServiceRequest, opts ...grpc.CallOption) (*emptypb.Empty, error)
+	// GetStatus reports the health of the gateway and its backing dependencies, like GET /health
+	GetStatus(ctx context.Context, in *GetStatusRequest, opts ...grpc.CallOption) (*Status, error)
+	// WatchServices streams the changes of services and routes until the client cancels. A
+	// watcher that falls behind is ended with RESOURCE_EXHAUSTED and should watch again.
+	WatchServices(ctx context.Context, in *WatchServicesRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[ServiceEvent], error)
+}
+
+type gatewayAdminClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewGatewayAdminClient(cc grpc.ClientConnInterface) GatewayAdminClient {
+	return &gatewayAdminClient{cc}
+}
+
+func (c *gatewayAdminClient) ListServices(ctx context.Context, in *ListServicesRequest, opts ...grpc.CallOption) (*ListServicesResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListServicesResponse)
+	err := c.cc.Invoke(ctx, GatewayAdmin_ListServices_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *gatewayAdminClient) GetService(ctx context.Context, in *GetServiceRequest, opts ...grpc.CallOption) (*Service, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Service)
+	err := c.cc.Invoke(ctx, GatewayAdmin_GetService_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *gatewayAdminClient) FindServiceByName(ctx context.Context, in *FindServiceByNameRequest, opts ...grpc.CallOption) (*Service, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Service)
+	err := c.cc.Invoke(ctx, GatewayAdmin_FindServiceByName_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *gatewayAdminClient) CreateService(ctx context.Context, in *CreateServiceRequest, opts ...grpc.CallOption) (*Service, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Service)
+	err := c.cc.Invoke(ctx, GatewayAdmin_CreateService_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *gatewayAdminClient) UpdateService(ctx context.Context, in *UpdateServiceRequest, opts ...grpc.CallOption) (*Service, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Service)
+	err := c.cc.Invoke(ctx, GatewayAdmin_UpdateService_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *gatewayAdminClient) DeleteService(ctx context.Context, in *DeleteServiceRequest, opts ...grpc.CallOption) (*emptypb.Empty, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(emptypb.Empty)
+	err := c.cc.Invoke(ctx, GatewayAdmin_DeleteService_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *gatewayAdminClient) GetStatus(ctx context.Context, in *GetStatusRequest, opts ...grpc.CallOption) (*Status, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Status)
+	err := c.cc.Invoke(ctx, GatewayAdmin_GetStatus_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *gatewayAdminClient) WatchServices(ctx context.Context, in *WatchServicesRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[ServiceEvent], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &GatewayAdmin_ServiceDesc.Streams[0], GatewayAdmin_WatchServices_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[WatchServicesRequest, ServiceEvent]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type GatewayAdmin_WatchServicesClient = grpc.ServerStreamingClient[ServiceEvent]
+
+// GatewayAdminServer is the server API for GatewayAdmin service.
+// All implementations must embed UnimplementedGatewayAdminServer
+// for forward compatibility.
+//
+// GatewayAdmin manages the services the gateway routes to. Every call must carry the
+// "authorization" metadata of a principal with the admin role, either "Bearer <token>" or
+// "Basic <credentials>" when a credentials directory is configured.
+type GatewayAdminServer interface {
+	// ListServices returns every service, like GET /admin/services
+	ListServices(context.Context, *ListServicesRequest) (*ListServicesResponse, error)
+	// GetService returns a service by ID, like GET /admin/services/{id}
+	GetService(context.Context, *GetServiceRequest) (*Service, error)
+	// FindServiceByName returns a service by name, like GET /admin/services/name/{name}
+	FindServiceByName(context.Context, *FindServiceByNameRequest) (*Service, error)
+	// CreateService creates a service, like POST /admin/services
+	CreateService(context.Context, *CreateServiceRequest) (*Service, error)
+	// UpdateService replaces a service, like PUT /admin/services/{id}
+	UpdateService(context.Context, *UpdateServiceRequest) (*Service, error)
+	// DeleteService deletes a service, like DELETE /admin/services/{id}
+	DeleteService(context.Context, *DeleteServiceRequest) (*emptypb.Empty, error)
+	// GetStatus reports the health of the gateway and its backing dependencies, like GET /health
+	GetStatus(context.Context, *GetStatusRequest) (*Status, error)
+	// WatchServices streams the changes of services and routes until the client cancels. A
+	// watcher that falls behind is ended with RESOURCE_EXHAUSTED and should watch again.
+	WatchServices(*WatchServicesRequest, grpc.ServerStreamingServer[ServiceEvent]) error
+	mustEmbedUnimplementedGatewayAdminServer()
+}
+
+// UnimplementedGatewayAdminServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedGatewayAdminServer struct{}
+
+func (UnimplementedGatewayAdminServer) ListServices(context.Context, *ListServicesRequest) (*ListServicesResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListServices not implemented")
+}
+func (UnimplementedGatewayAdminServer) GetService(context.Context, *GetServiceRequest) (*Service, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetService not implemented")
+}
+func (UnimplementedGatewayAdminServer) FindServiceByName(context.Context, *FindServiceByNameRequest) (*Service, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method FindServiceByName not implemented")
+}
+func (UnimplementedGatewayAdminServer) CreateService(context.Context, *CreateServiceRequest) (*Service, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CreateService not implemented")
+}
+func (UnimplementedGatewayAdminServer) UpdateService(context.Context, *UpdateServiceRequest) (*Service, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method UpdateService not implemented")
+}
+func (UnimplementedGatewayAdminServer) DeleteService(context.Context, *DeleteServiceRequest) (*emptypb.Empty, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method DeleteService not implemented")
+}
+func (UnimplementedGatewayAdminServer) GetStatus(context.Context, *GetStatusRequest) (*Status, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetStatus not implemented")
+}
+func (UnimplementedGatewayAdminServer) WatchServices(*WatchServicesRequest, grpc.ServerStreamingServer[ServiceEvent]) error {
+	return status.Errorf(codes.Unimplemented, "method WatchServices not implemented")
+}
+func (UnimplementedGatewayAdminServer) mustEmbedUnimplementedGatewayAdminServer() {}
+func (UnimplementedGatewayAdminServer) testEmbeddedByValue()                      {}
+
+// UnsafeGatewayAdminServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to GatewayAdminServer will
+// result in compilation errors.
+type UnsafeGatewayAdminServer interface {
+	mustEmbedUnimplementedGatewayAdminServer()
+}
+
+func RegisterGatewayAdminServer(s grpc.ServiceRegistrar, srv GatewayAdminServer) {
+	// If the following call pancis, it indicates UnimplementedGatewayAdminServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&GatewayAdmin_ServiceDesc, srv)
+}
+
+func _GatewayAdmin_ListServices_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListServicesRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(GatewayAdminServer).ListServices(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: GatewayAdmin_ListServices_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(GatewayAdminServer).ListServices(ctx, req.(*ListServicesRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _GatewayAdmin_GetService_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetServiceRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(GatewayAdminServer).GetService(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: GatewayAdmin_GetService_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(GatewayAdminServer).GetService(ctx, req.(*GetServiceRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _GatewayAdmin_FindServiceByName_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(FindServiceByNameRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(GatewayAdminServer).FindServiceByName(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: GatewayAdmin_FindServiceByName_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(GatewayAdminServer).FindServiceByName(ctx, req.(*FindServiceByNameRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _GatewayAdmin_CreateService_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CreateServiceRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(GatewayAdminServer).CreateService(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: GatewayAdmin_CreateService_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(GatewayAdminServer).CreateService(ctx, req.(*CreateServiceRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _GatewayAdmin_UpdateService_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(UpdateServiceRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(GatewayAdminServer).UpdateService(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: GatewayAdmin_UpdateService_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(GatewayAdminServer).UpdateService(ctx, req.(*UpdateServiceRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _GatewayAdmin_DeleteService_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DeleteServiceRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(GatewayAdminServer).DeleteService(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: GatewayAdmin_DeleteService_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(GatewayAdminServer).DeleteService(ctx, req.(*DeleteServiceRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _GatewayAdmin_GetStatus_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetStatusRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(GatewayAdminServer).GetStatus(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: GatewayAdmin_GetStatus_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(GatewayAdminServer).GetStatus(ctx, req.(*GetStatusRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _GatewayAdmin_WatchServices_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(WatchServicesRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(GatewayAdminServer).WatchServices(m, &grpc.GenericServerStream[WatchServicesRequest, ServiceEvent]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type GatewayAdmin_WatchServicesServer = grpc.ServerStreamingServer[ServiceEvent]
+
+// GatewayAdmin_ServiceDesc is the grpc.ServiceDesc for GatewayAdmin service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var GatewayAdmin_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "gateway.admin.v1.GatewayAdmin",
+	HandlerType: (*GatewayAdminServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "ListServices",
+			Handler:    _GatewayAdmin_ListServices_Handler,
+		},
+		{
+			MethodName: "GetService",
+			Handler:    _GatewayAdmin_GetService_Handler,
+		},
+		{
+			MethodName: "FindServiceByName",
+			Handler:    _GatewayAdmin_FindServiceByName_Handler,
+		},
+		{
+			MethodName: "CreateService",
+			Handler:    _GatewayAdmin_CreateService_Handler,
+		},
+		{
+			MethodName: "UpdateService",
+			Handler:    _GatewayAdmin_UpdateService_Handler,
+		},
+		{
+			MethodName: "DeleteService",
+			Handler:    _GatewayAdmin_DeleteService_Handler,
+		},
+		{
+			MethodName: "GetStatus",
+			Handler:    _GatewayAdmin_GetStatus_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "WatchServices",
+			Handler:       _GatewayAdmin_WatchServices_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "admin.proto",
+}
@@ -0,0 +1,4 @@
+// Package adminv1 holds the protobuf messages and gRPC stubs of the gateway control-plane API
+package adminv1
+
+//go:generate protoc --go_out=. --go_opt=paths=source_relative --go-grpc_out=. --go-grpc_opt=paths=source_relative admin.proto
@@ -1,75 +1,264 @@
 package main
 
 import (
+	"context"
+	"crypto/x509"
+	"database/sql"
+	"flag"
+	"fmt"
 	"log"
+	"net/netip"
+	"net/url"
 	"os"
 	"os/signal"
+	"strings"
 	"syscall"
 	"time"
 
 	"api-gateway-sample/internal/application/usecase"
+	"api-gateway-sample/internal/domain/entity"
+	domainrepo "api-gateway-sample/internal/domain/repository"
+	"api-gateway-sample/internal/domain/service"
+	"api-gateway-sample/internal/infrastructure/alerting"
 	"api-gateway-sample/internal/infrastructure/auth"
+	"api-gateway-sample/internal/infrastructure/broker"
 	"api-gateway-sample/internal/infrastructure/cache"
 	"api-gateway-sample/internal/infrastructure/client"
+	"api-gateway-sample/internal/infrastructure/election"
+	"api-gateway-sample/internal/infrastructure/encryption"
+	"api-gateway-sample/internal/infrastructure/errorreport"
+	"api-gateway-sample/internal/infrastructure/events"
+	"api-gateway-sample/internal/infrastructure/extauthz"
+	"api-gateway-sample/internal/infrastructure/flags"
+	"api-gateway-sample/internal/infrastructure/geo"
+	"api-gateway-sample/internal/infrastructure/mail"
+	"api-gateway-sample/internal/infrastructure/metrics"
 	"api-gateway-sample/internal/infrastructure/persistence"
+	"api-gateway-sample/internal/infrastructure/policy"
 	"api-gateway-sample/internal/infrastructure/ratelimit"
 	"api-gateway-sample/internal/infrastructure/repository"
+	"api-gateway-sample/internal/infrastructure/scan"
+	"api-gateway-sample/internal/infrastructure/stream"
+	"api-gateway-sample/internal/infrastructure/webhook"
+	"api-gateway-sample/internal/infrastructure/xds"
 	"api-gateway-sample/internal/interfaces/api"
+	"api-gateway-sample/internal/interfaces/controlplane"
 	"api-gateway-sample/pkg/config"
+	"api-gateway-sample/pkg/i18n"
 	"api-gateway-sample/pkg/logger"
+	"api-gateway-sample/pkg/secrets"
+	"api-gateway-sample/pkg/sigv4"
 
 	"github.com/redis/go-redis/v9"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+)
+
+// rateLimitBackendMemory keeps rate limit counters in process memory instead of Redis
+const rateLimitBackendMemory = "memory"
+
+// Leader election backends
+const (
+	leaderElectionBackendRedis    = "redis"
+	leaderElectionBackendPostgres = "postgres"
+)
+
+// Storage backends for services and webhook subscriptions
+const (
+	storageBackendPostgres = "postgres"
+	storageBackendFile     = "file"
 )
 
 func main() {
+	validateOnly := flag.Bool("validate-config", false, "validate the configuration and exit")
+	flag.Parse()
+
 	// Load configuration
 	cfg, err := config.LoadConfig("")
 	if err != nil {
 		log.Fatalf("Failed to load configuration: %v", err)
 	}
 
+	if *validateOnly {
+		os.Exit(validateConfig(cfg))
+	}
+
 	// Initialize logger
-	appLogger, err := logger.NewZapLogger(cfg.Logging.Level, cfg.Logging.Development)
+	logOptions, err := loggerOptions(cfg.Logging)
+	if err != nil {
+		log.Fatalf("Failed to open log outputs: %v", err)
+	}
+	zapLogger, err := logger.NewZapLogger(cfg.Logging.Level, cfg.Logging.Development, logOptions)
 	if err != nil {
 		log.Fatalf("Failed to initialize logger: %v", err)
 	}
 
+	// Scrub credentials and sensitive fields from every log line
+	redactor := logger.NewRedactor(
+		cfg.Logging.Redaction.Headers,
+		cfg.Logging.Redaction.BodyFields,
+		cfg.Logging.Redaction.Mask,
+	)
+	appLogger := logger.NewRedactingLogger(zapLogger, redactor)
+	logger.SetDefault(appLogger)
+
 	appLogger.Info("Starting API Gateway")
 
-	// Initialize database
-	db, err := persistence.NewDatabase(cfg.Database)
+	// Load secrets from the configured provider
+	secretsProvider, err := secrets.NewProvider(cfg.Secrets)
+	if err != nil {
+		appLogger.Error("Failed to initialize secrets provider", "error", err)
+		os.Exit(1)
+	}
+	secretsManager := secrets.NewManager(secretsProvider, cfg.Secrets.RefreshInterval, appLogger)
+	if err := secretsManager.Refresh(context.Background()); err != nil {
+		appLogger.Error("Failed to load secrets", "error", err)
+		os.Exit(1)
+	}
+	secrets.Apply(cfg, secretsManager)
+
+	// Fail fast on invalid settings, now that secrets are applied
+	if err := cfg.Validate(); err != nil {
+		appLogger.Error("Invalid configuration", "error", err)
+		os.Exit(1)
+	}
+	for _, warning := range cfg.Warnings() {
+		appLogger.Warn("Insecure configuration", "warning", warning)
+	}
+	backgroundCtx, stopBackground := context.WithCancel(context.Background())
+	defer stopBackground()
+
+	// Initialize repositories
+	repos, err := newRepositories(cfg, appLogger)
 	if err != nil {
-		appLogger.Error("Failed to initialize database", "error", err)
+		appLogger.Error("Failed to initialize storage", "error", err)
 		os.Exit(1)
 	}
+	serviceRepo, webhookRepo, apiKeyRepo, jobRepo := repos.services, repos.webhooks, repos.apiKeys, repos.jobs
+
+	// With an xDS management server, its routes and clusters are served besides the stored services
+	var xdsServices *xds.ServiceRepository
+	if cfg.XDS.Address != "" {
+		xdsServices = xds.NewServiceRepository(serviceRepo)
+		serviceRepo = xdsServices
+	}
 
-	// Initialize Redis
-	redisClient := redis.NewClient(&redis.Options{
-		Addr:     cfg.Redis.Address,
-		Password: cfg.Redis.Password,
-		DB:       cfg.Redis.DB,
-	})
+	// Initialize Redis, which small deployments can go without by using the
+	// memcached or memory cache backend and the memory rate limiter
+	var redisClient redis.UniversalClient
+	var redisHealth *cache.RedisHealthMonitor
+	if usesRedis(cfg) {
+		redisClient, err = cache.NewRedisClient(cfg.Redis)
+		if err != nil {
+			appLogger.Error("Failed to initialize Redis", "error", err)
+			os.Exit(1)
+		}
+		redisHealth = cache.NewRedisHealthMonitor(redisClient, cfg.Redis.FailureThreshold, cfg.Redis.HealthCheckTimeout, appLogger)
+		redisHealth.Start(backgroundCtx, cfg.Redis.HealthCheckInterval)
+	}
 
 	// Initialize cache
-	cacheRepo := cache.NewRedisCache(redisClient)
+	cacheRepo, err := cache.NewCacheRepository(cfg.Cache, redisClient)
+	if err != nil {
+		appLogger.Error("Failed to initialize cache", "error", err)
+		os.Exit(1)
+	}
+	if redisHealth != nil && cfg.Cache.BypassOnFailure && (cfg.Cache.Backend == "" || cfg.Cache.Backend == cache.BackendRedis) {
+		cacheRepo = cache.NewFallbackCache(cacheRepo, redisHealth)
+	}
+	appLogger.Info("Cache initialized", "backend", cfg.Cache.Backend)
 	cacheService := cache.NewCacheService(cacheRepo)
 
-	// Initialize repositories
-	serviceRepo := repository.NewServiceRepositoryImpl(db, appLogger)
-
 	// Initialize HTTP client
 	httpClient := client.NewHTTPClient(30*time.Second, appLogger)
+	if cfg.Upstream.H2C {
+		httpClient.EnableH2C()
+	}
+	httpClient.SetHeaderDenylist(cfg.Upstream.DeniedRequestHeaders, cfg.Upstream.DeniedResponseHeaders)
+	trustedProxies := make([]netip.Prefix, len(cfg.Upstream.TrustedProxies))
+	for i, network := range cfg.Upstream.TrustedProxies {
+		trustedProxies[i] = netip.MustParsePrefix(network)
+	}
+	httpClient.SetForwarding(trustedProxies, cfg.Upstream.ForwardedHeader)
+	if cfg.Upstream.DNSCacheTTL > 0 {
+		httpClient.EnableDNSCache(cfg.Upstream.DNSCacheTTL)
+	}
+	networks := make(map[string]client.Network, len(cfg.Upstream.Networks))
+	for name, network := range cfg.Upstream.Networks {
+		upstreamNetwork := client.Network{Resolvers: network.Resolvers, Hosts: make(map[string]string, len(network.Hosts))}
+		if network.Proxy != "" {
+			upstreamNetwork.Proxy, _ = url.Parse(network.Proxy)
+		}
+		for _, host := range network.Hosts {
+			upstreamNetwork.Hosts[host.Host] = host.Address
+		}
+		networks[name] = upstreamNetwork
+	}
+	httpClient.SetNetworks(networks)
 
 	// Initialize authentication service
-	authService := auth.NewJWTAuth(
-		[]byte(cfg.Auth.SecretKey),
+	signingKey, err := loadSigningKey(cfg.Auth)
+	if err != nil {
+		appLogger.Error("Failed to initialize signing key", "error", err)
+		os.Exit(1)
+	}
+	keyRing := auth.NewKeyRing(signingKey, cfg.Auth.Expiration)
+	policyEngine, err := policy.NewCELEngine()
+	if err != nil {
+		appLogger.Error("Failed to initialize policy engine", "error", err)
+		os.Exit(1)
+	}
+	defaultPolicy := cfg.Auth.DefaultPolicy
+	if defaultPolicy == "" {
+		defaultPolicy = policy.DefaultPolicy
+	}
+	if err := policyEngine.Compile(defaultPolicy); err != nil {
+		appLogger.Error("Invalid default authorization policy", "error", err)
+		os.Exit(1)
+	}
+	authService := auth.NewJWTAuthWithKeyRing(
+		keyRing,
+		policyEngine,
+		defaultPolicy,
 		cfg.Auth.Issuer,
 		cfg.Auth.Expiration,
 		appLogger,
 	)
+	keyRing.StartRotation(backgroundCtx, cfg.Auth.RotationInterval, appLogger)
+
+	// Rotate the shared JWT signing secret whenever it changes
+	if !signingKey.IsAsymmetric() {
+		secretsManager.OnChange(secrets.AuthSecretKey, func(value string) {
+			authService.SetSecretKey([]byte(value))
+			appLogger.Info("JWT signing key rotated")
+		})
+	}
+	secretsManager.Start(backgroundCtx)
 
 	// Initialize rate limiting service
-	rateLimitService := ratelimit.NewTokenBucketRateLimiter(redisClient, appLogger)
+	var rateLimitService service.RateLimitService
+	var concurrencyLimiter service.ConcurrencyLimiter
+	var spikeArrester service.SpikeArrester
+	if cfg.RateLimit.Backend == rateLimitBackendMemory {
+		rateLimitService = ratelimit.NewInMemoryRateLimiter(appLogger)
+		concurrencyLimiter = ratelimit.NewInMemoryConcurrencyLimiter()
+		spikeArrester = ratelimit.NewInMemorySpikeArrester()
+	} else {
+		concurrencyLimiter = ratelimit.NewRedisConcurrencyLimiter(redisClient, cfg.RateLimit.ConcurrencyTTL, appLogger)
+		spikeArrester = ratelimit.NewRedisSpikeArrester(redisClient, appLogger)
+		var primary service.RateLimitService = ratelimit.NewTokenBucketRateLimiter(redisClient, appLogger)
+		if cfg.RateLimit.SyncInterval > 0 {
+			syncedLimiter := ratelimit.NewSyncedRateLimiter(redisClient, cfg.RateLimit.SyncInterval, appLogger)
+			syncedLimiter.Start(backgroundCtx)
+			primary = syncedLimiter
+		}
+		rateLimitService = ratelimit.NewFallbackRateLimiter(
+			primary,
+			redisHealth,
+			cfg.RateLimit.FailurePolicy,
+			appLogger,
+		)
+	}
 
 	// Initialize gateway service
 	gatewayService := client.NewGatewayService(httpClient, appLogger)
@@ -83,10 +272,264 @@ func main() {
 		cacheService,
 		appLogger,
 	)
+	metricsCollector := metrics.NewSlidingWindowAggregator(cfg.Metrics.Window, cfg.Metrics.Buckets)
+	proxyUseCase.SetMetricsCollector(metricsCollector)
+	proxyUseCase.SetConcurrencyLimiter(concurrencyLimiter)
+	proxyUseCase.SetSpikeArrester(spikeArrester)
+	if cfg.Cache.ETags != "" {
+		proxyUseCase.SetGeneratedETags(cfg.Cache.ETags)
+	}
+	if cfg.Idempotency.Enabled {
+		proxyUseCase.SetIdempotencyStore(cacheRepo, cfg.Idempotency.TTL, cfg.Idempotency.LockTimeout)
+	}
+	if cfg.Priority.Enabled {
+		proxyUseCase.SetPriorityScheduler(cfg.Priority.MaxInFlight, cfg.Priority.QueueSize, cfg.Priority.MaxWait, cfg.Priority.Plans, cfg.Priority.DefaultClass)
+	}
+	if cfg.Dedup.Enabled {
+		proxyUseCase.SetRequestDeduplication()
+	}
+	var requestSigner *client.RequestSigner
+	if len(cfg.Upstream.Credentials) > 0 || len(cfg.Upstream.OAuthClients) > 0 {
+		credentials := make(map[string]sigv4.Credentials, len(cfg.Upstream.Credentials))
+		for name, creds := range cfg.Upstream.Credentials {
+			credentials[name] = sigv4.Credentials{
+				AccessKeyID:     creds.AccessKeyID,
+				SecretAccessKey: creds.SecretAccessKey,
+				SessionToken:    creds.SessionToken,
+			}
+		}
+		requestSigner = client.NewRequestSigner(credentials)
+		if len(cfg.Upstream.OAuthClients) > 0 {
+			oauthClients := make(map[string]client.OAuthClient, len(cfg.Upstream.OAuthClients))
+			for name, oauthClient := range cfg.Upstream.OAuthClients {
+				oauthClients[name] = client.OAuthClient{
+					TokenURL:     oauthClient.TokenURL,
+					ClientID:     oauthClient.ClientID,
+					ClientSecret: oauthClient.ClientSecret,
+					Scopes:       oauthClient.Scopes,
+					Audience:     oauthClient.Audience,
+				}
+			}
+			requestSigner.SetOAuthTokenSource(client.NewOAuthTokenSource(oauthClients, cfg.Upstream.TokenTimeout))
+		}
+		proxyUseCase.SetRequestSigner(requestSigner)
+	}
+	// Keys of encrypted endpoints are secrets, read when used so that rotations apply at once
+	proxyUseCase.SetPayloadCipher(encryption.NewPayloadCipher(secretsManager.Get))
+	regionLocator, err := geo.NewRegionLocator(cfg.Residency)
+	if err != nil {
+		appLogger.Error("Failed to initialize region locator", "error", err)
+		os.Exit(1)
+	}
+	proxyUseCase.SetRegionLocator(regionLocator)
+
+	// Initialize the message brokers of bridge endpoints
+	var publishers []service.MessagePublisher
+	if len(cfg.Brokers.Kafka.Brokers) > 0 {
+		publisher := broker.NewKafkaPublisher(cfg.Brokers.Kafka, cfg.Brokers.Timeout, appLogger)
+		proxyUseCase.SetPublisher(entity.BrokerKafka, publisher)
+		publishers = append(publishers, publisher)
+	}
+	if cfg.Brokers.RabbitMQ.URL != "" {
+		publisher := broker.NewRabbitMQPublisher(cfg.Brokers.RabbitMQ, cfg.Brokers.Timeout, appLogger)
+		proxyUseCase.SetPublisher(entity.BrokerRabbitMQ, publisher)
+		publishers = append(publishers, publisher)
+	}
+	if cfg.Brokers.NATS.URL != "" {
+		publisher, err := broker.NewNATSPublisher(cfg.Brokers.NATS, cfg.Brokers.Timeout, appLogger)
+		if err != nil {
+			appLogger.Error("Failed to initialize NATS", "error", err)
+			os.Exit(1)
+		}
+		proxyUseCase.SetPublisher(entity.BrokerNATS, publisher)
+		publishers = append(publishers, publisher)
+	}
+	// Initialize the event bus and its subscribers. With config sync, service changes reach the
+	// subscribers of every instance, which evict their cached responses and reload file storage.
+	localBus := events.NewInMemoryBus(appLogger)
+	var eventBus service.EventBus = localBus
+	if cfg.ConfigSync.Enabled {
+		redisBus := events.NewRedisBus(localBus, redisClient, cfg.ConfigSync.Channel, appLogger)
+		if err := redisBus.Start(backgroundCtx); err != nil {
+			appLogger.Error("Failed to start config sync", "error", err)
+			os.Exit(1)
+		}
+		eventBus = redisBus
+		if repos.store != nil {
+			usecase.SubscribeRemoteReload(eventBus, repos.store.Reload, appLogger)
+		}
+		appLogger.Info("Config sync enabled", "channel", cfg.ConfigSync.Channel)
+	}
+	usecase.SubscribeCacheInvalidation(eventBus, cacheService, appLogger)
+	usecase.SubscribeAuditLog(eventBus, appLogger)
+	keyRing.SetEvents(eventBus)
+
+	// The xDS client publishes on the local bus only: every instance subscribes for itself
+	var xdsConn *grpc.ClientConn
+	if xdsServices != nil {
+		xdsConn, err = xds.Dial(cfg.XDS)
+		if err != nil {
+			appLogger.Error("Failed to initialize xDS client", "error", err)
+			os.Exit(1)
+		}
+		go xds.NewClient(xdsConn, cfg.XDS, xdsServices, localBus, appLogger).Run(backgroundCtx)
+		appLogger.Info("xDS client initialized", "address", cfg.XDS.Address, "routeConfigs", cfg.XDS.RouteConfigs)
+	}
+
+	webhookDeliverer := webhook.NewHTTPDeliverer(cfg.Webhooks.Timeout, cfg.Webhooks.MaxRetries, cfg.Webhooks.RetryBackoff, appLogger)
+	webhookUseCase := usecase.NewWebhookUseCase(webhookRepo, webhookDeliverer, appLogger)
+	webhookPool := usecase.NewWorkerPool("webhooks", cfg.Webhooks.Workers, cfg.Webhooks.QueueSize, cfg.Webhooks.DropPolicy, appLogger)
+	webhookPool.Start(backgroundCtx)
+	webhookUseCase.SetWorkerPool(webhookPool)
+	webhookUseCase.Subscribe(eventBus)
+
+	// Process the requests of async endpoints in the background, posting results to callbacks
+	// with the webhook delivery settings
+	if cfg.Async.Enabled {
+		proxyUseCase.StartAsyncWorkers(backgroundCtx, cacheRepo, cfg.Async.Workers, cfg.Async.QueueSize, cfg.Async.ResultTTL, cfg.Async.Timeout)
+		if len(cfg.Async.CallbackHosts) > 0 {
+			proxyUseCase.SetAsyncCallbacks(webhookDeliverer, cfg.Async.CallbackSecret, cfg.Async.CallbackHosts)
+		}
+	}
+
+	// Call gateway routes on the schedules of the scheduled jobs
+	schedulerUseCase := usecase.NewSchedulerUseCase(jobRepo, proxyUseCase, cfg.Scheduler.Timeout, cfg.Scheduler.HistorySize, appLogger)
+	if cfg.Scheduler.Enabled {
+		if elector := newLeaderElector(cfg.LeaderElection, repos, redisClient, appLogger); elector != nil {
+			elector.Start(backgroundCtx)
+			schedulerUseCase.SetLeaderElector(elector)
+		}
+		schedulerUseCase.Start(backgroundCtx, cfg.Scheduler.ReloadInterval)
+	}
+
+	var mailer service.Mailer
+	if mailCfg := cfg.Mail; mailCfg.Host != "" {
+		mailer = mail.NewSMTPMailer(
+			mailCfg.Host,
+			mailCfg.Port,
+			mailCfg.Username,
+			mailCfg.Password,
+			mailCfg.From,
+			mailCfg.Timeout,
+		)
+	}
+
+	// Alert channels are optional dependencies: they deliver in the background and report their health
+	notifier, alertChannels := newNotifier(backgroundCtx, cfg.Alerting, serviceRepo, mailer, appLogger)
+	if notifier != nil {
+		alerting.NewErrorRateMonitor(
+			metricsCollector,
+			notifier,
+			cfg.Alerting.ErrorRateThreshold,
+			cfg.Alerting.MinRequests,
+			appLogger,
+		).Start(backgroundCtx, cfg.Alerting.CheckInterval)
+		alerting.NewSLOMonitor(
+			metricsCollector,
+			notifier,
+			cfg.Alerting.SLOBurnRateThreshold,
+			cfg.Alerting.MinRequests,
+			appLogger,
+		).Start(backgroundCtx, cfg.Alerting.CheckInterval)
+	}
+	if scanner := cfg.Uploads.Scanner; scanner.URL != "" {
+		proxyUseCase.SetUploadScanner(scan.NewHTTPScanner(scanner.URL, scanner.FailOpen, scanner.Timeout, appLogger), cfg.Uploads.SpoolDir)
+	}
+	if external := cfg.Auth.External; external.URL != "" {
+		authorizer := extauthz.NewHTTPAuthorizer(
+			external.URL,
+			external.ForwardHeaders,
+			external.UpstreamHeaders,
+			external.FailOpen,
+			external.CacheTTL,
+			external.Timeout,
+			appLogger,
+		)
+		defer authorizer.Close()
+		proxyUseCase.SetExternalAuthorizer(authorizer, external.UpstreamHeaders)
+		appLogger.Info("External authorization enabled", "url", external.URL)
+	}
+	if len(cfg.Auth.ClaimHeaders) > 0 {
+		proxyUseCase.SetClaimHeaders(cfg.Auth.ClaimHeaders)
+		appLogger.Info("Claim headers enabled", "headers", len(cfg.Auth.ClaimHeaders))
+	}
+	if identity := cfg.Auth.IdentityToken; identity.Audience != "" {
+		authService.SetIdentityTokens(identity.Audience, identity.TTL)
+		proxyUseCase.SetIdentityTokenIssuer(authService, identity.Header)
+		appLogger.Info("Identity tokens enabled", "audience", identity.Audience, "header", identity.Header)
+	}
 
 	authUseCase := usecase.NewAuthUseCase(authService, appLogger)
+	if ldapCfg := cfg.Auth.LDAP; ldapCfg.URL != "" {
+		authUseCase.SetCredentialValidator(auth.NewLDAPAuth(
+			ldapCfg.URL,
+			ldapCfg.BindDN,
+			ldapCfg.BindPassword,
+			ldapCfg.BaseDN,
+			ldapCfg.UserFilter,
+			ldapCfg.GroupAttribute,
+			ldapCfg.GroupRoles,
+			ldapCfg.StartTLS,
+			ldapCfg.Timeout,
+			appLogger,
+		))
+		appLogger.Info("LDAP authentication enabled", "url", ldapCfg.URL)
+	}
 	rateLimitUseCase := usecase.NewRateLimitUseCase(rateLimitService, appLogger)
+	rateLimitOverrideUseCase := usecase.NewRateLimitOverrideUseCase(repos.rateLimitOverrides, serviceRepo, cacheRepo, cfg.RateLimit.OverrideCacheTTL, appLogger)
+	proxyUseCase.SetRateLimitOverrides(rateLimitOverrideUseCase)
+	routePolicyUseCase := usecase.NewRoutePolicyUseCase(repos.routePolicies, serviceRepo, cacheRepo, cfg.Routing.PolicyCacheTTL, appLogger)
+	proxyUseCase.SetRoutePolicies(routePolicyUseCase)
+	var featureFlagUseCase *usecase.FeatureFlagUseCase
+	if cfg.FeatureFlags.Provider == "ofrep" {
+		ofrep := cfg.FeatureFlags.OFREP
+		proxyUseCase.SetFeatureFlags(flags.NewOFREPProvider(ofrep.URL, ofrep.Token, ofrep.Timeout, cfg.FeatureFlags.CacheTTL, appLogger))
+	} else {
+		featureFlagUseCase = usecase.NewFeatureFlagUseCase(repos.featureFlags, policyEngine, cacheRepo, cfg.FeatureFlags.CacheTTL, appLogger)
+		proxyUseCase.SetFeatureFlags(featureFlagUseCase)
+	}
+	// Exposures are analysed in full, so they are written to their own outputs, never sampled
+	var exposures logger.Logger
+	var exposureLogger *logger.ZapLogger
+	if len(cfg.Experiments.ExposureLog) > 0 {
+		exposureOptions, err := loggerOptions(config.LoggingConfig{Outputs: cfg.Experiments.ExposureLog})
+		if err != nil {
+			appLogger.Error("Failed to open exposure log outputs", "error", err)
+			os.Exit(1)
+		}
+		if exposureLogger, err = logger.NewZapLogger("info", false, exposureOptions); err != nil {
+			appLogger.Error("Failed to initialize exposure log", "error", err)
+			os.Exit(1)
+		}
+		exposures = exposureLogger
+	}
+	proxyUseCase.SetExperiments(exposures, cfg.Experiments.CookieMaxAge)
 	serviceManagementUseCase := usecase.NewServiceManagementUseCase(serviceRepo, appLogger)
+	serviceUseCase := usecase.NewServiceUseCase(serviceRepo, cacheRepo, eventBus)
+	serviceUseCase.SetRevisionHistory(repos.revisions, appLogger)
+	serviceUseCase.SetRouteConflictPolicy(cfg.Routing.Conflicts)
+	serviceUseCase.StartExpiryCleaner(backgroundCtx, cfg.Routing.ExpiryCleanupInterval)
+	policyUseCase := usecase.NewPolicyUseCase(policyEngine, appLogger)
+	apiKeyUseCase := usecase.NewAPIKeyUseCase(apiKeyRepo, serviceRepo, eventBus, appLogger)
+	if mailer != nil {
+		apiKeyUseCase.SetMailer(mailer)
+		appLogger.Info("Consumer email enabled", "host", cfg.Mail.Host)
+	}
+	statsUseCase := usecase.NewStatsUseCase(serviceRepo, metricsCollector, appLogger)
+	if redisHealth != nil {
+		statsUseCase.AddHealthReporter(redisHealth)
+	}
+	if sizer, ok := cacheRepo.(domainrepo.CacheSizer); ok {
+		statsUseCase.SetCacheSizer(sizer)
+	}
+	for _, channel := range alertChannels {
+		statsUseCase.AddHealthReporter(channel)
+	}
+	statsUseCase.SetLogDropReporter(zapLogger)
+	statsUseCase.AddWorkerPool(webhookPool)
+	if asyncPool := proxyUseCase.AsyncWorkerPool(); asyncPool != nil {
+		statsUseCase.AddWorkerPool(asyncPool)
+	}
 
 	// Initialize handler
 	handler := api.NewHandler(
@@ -94,16 +537,133 @@ func main() {
 		authUseCase,
 		rateLimitUseCase,
 		serviceManagementUseCase,
+		statsUseCase,
 		appLogger,
 	)
+	handler.SetErrorTemplates(errorTemplates(cfg.ErrorPages))
+	translator, err := newTranslator(cfg.I18n)
+	if err != nil {
+		appLogger.Error("Failed to load message catalogs", "error", err)
+		os.Exit(1)
+	}
+	handler.SetTranslator(translator)
+	handler.SetLegacyRateLimitHeaders(cfg.RateLimit.LegacyHeaders)
 
 	// Initialize router
 	router := api.NewRouter(
 		handler,
+		proxyUseCase,
 		appLogger,
 		authUseCase,
 		rateLimitUseCase,
+		cfg,
+		api.NewServiceHandler(serviceUseCase),
+		api.NewDesiredStateHandler(serviceUseCase),
+		api.NewPolicyHandler(policyUseCase),
+		api.NewStatsHandler(statsUseCase),
+		api.NewWebhookHandler(webhookUseCase),
+		api.NewAPIKeyHandler(apiKeyUseCase),
+		api.NewScheduledJobHandler(schedulerUseCase),
 	)
+	router.SetAPIKeyUseCase(apiKeyUseCase)
+	router.AddAdminHandler(api.NewSOAPHandler(usecase.NewSOAPUseCase()))
+	router.AddAdminHandler(api.NewRateLimitOverrideHandler(rateLimitOverrideUseCase))
+	router.AddAdminHandler(api.NewRoutePolicyHandler(routePolicyUseCase))
+	if featureFlagUseCase != nil {
+		router.AddAdminHandler(api.NewFeatureFlagHandler(featureFlagUseCase))
+	}
+
+	drainUseCase := usecase.NewUpstreamDrainUseCase(serviceRepo, cfg.Upstream.DrainTimeout, appLogger)
+	proxyUseCase.SetUpstreamDrain(drainUseCase)
+	router.AddAdminHandler(api.NewDrainHandler(drainUseCase))
+
+	if cfg.Chaos.Enabled {
+		faultUseCase := usecase.NewFaultUseCase(serviceRepo, appLogger)
+		proxyUseCase.SetFaultInjection(faultUseCase)
+		router.AddAdminHandler(api.NewFaultHandler(faultUseCase))
+		appLogger.Warn("Fault injection enabled", "profile", cfg.Server.Profile)
+	}
+
+	if cfg.Debug.Profiling {
+		router.AddAdminHandler(api.NewProfilingHandler())
+		appLogger.Info("Profiling endpoints enabled", "path", "/admin/debug/pprof/")
+	}
+
+	if reporting := cfg.ErrorReporting; reporting.DSN != "" {
+		environment := reporting.Environment
+		if environment == "" {
+			environment = cfg.Server.Profile
+		}
+		reporter, err := errorreport.NewSentryReporter(reporting.DSN, environment, reporting.Release, reporting.Timeout, reporting.BufferSize, appLogger)
+		if err != nil {
+			appLogger.Error("Failed to configure error reporting", "error", err)
+			os.Exit(1)
+		}
+		reporter.Start(backgroundCtx)
+		statsUseCase.AddHealthReporter(reporter)
+		router.SetErrorReporter(reporter)
+		appLogger.Info("Error reporting enabled", "environment", environment)
+	}
+
+	// Browser users logged in with OIDC or SAML are authenticated by a session cookie
+	sessions := api.NewSessionCookie(cfg.Auth.Session.CookieName, cfg.Auth.Expiration, cfg.Auth.Session.Secure)
+	if cfg.Auth.OIDC.Issuer != "" || cfg.Auth.SAML.EntityID != "" {
+		router.AddPublicHandler(sessions)
+		router.SetSessionCookie(sessions.Name())
+	}
+
+	if oidcCfg := cfg.Auth.OIDC; oidcCfg.Issuer != "" {
+		oidcUseCase := usecase.NewOIDCUseCase(
+			auth.NewOIDCAuth(
+				oidcCfg.Issuer,
+				oidcCfg.ClientID,
+				oidcCfg.ClientSecret,
+				oidcCfg.RedirectURL,
+				oidcCfg.Scopes,
+				oidcCfg.RolesClaim,
+				oidcCfg.Timeout,
+				appLogger,
+			),
+			authService,
+			cacheRepo,
+			oidcCfg.LoginTimeout,
+			appLogger,
+		)
+		router.AddPublicHandler(api.NewOIDCHandler(oidcUseCase, sessions))
+		appLogger.Info("OIDC login enabled", "issuer", oidcCfg.Issuer)
+	}
+
+	if samlCfg := cfg.Auth.SAML; samlCfg.EntityID != "" {
+		certificate, err := loadCertificate(samlCfg.IDPCertificateFile)
+		if err != nil {
+			appLogger.Error("Failed to load SAML identity provider certificate", "error", err)
+			os.Exit(1)
+		}
+		samlUseCase := usecase.NewSAMLUseCase(
+			auth.NewSAMLAuth(
+				samlCfg.EntityID,
+				samlCfg.ACSURL,
+				samlCfg.IDPEntityID,
+				samlCfg.IDPSSOURL,
+				certificate,
+				samlCfg.RolesAttribute,
+				samlCfg.AttributeRoles,
+				samlCfg.ClockSkew,
+				appLogger,
+			),
+			authService,
+			cacheRepo,
+			samlCfg.LoginTimeout,
+			appLogger,
+		)
+		router.AddPublicHandler(api.NewSAMLHandler(samlUseCase, sessions))
+		appLogger.Info("SAML login enabled", "idp", samlCfg.IDPEntityID)
+	}
+
+	if cfg.Portal.Enabled {
+		portalUseCase := usecase.NewPortalUseCase(serviceRepo, apiKeyRepo, eventBus, appLogger)
+		router.AddPublicHandler(api.NewPortalHandler(portalUseCase, cfg.Portal.Pages))
+	}
 
 	// Initialize server
 	server := api.NewServer(
@@ -114,6 +674,84 @@ func main() {
 		cfg.Server.ShutdownTimeout,
 		appLogger,
 	)
+	if cfg.Server.TLS.CertFile != "" {
+		server.EnableTLS(cfg.Server.TLS.CertFile, cfg.Server.TLS.KeyFile)
+	}
+	if err := server.SetHTTP2(cfg.Server.HTTP2, cfg.Server.H2C); err != nil {
+		appLogger.Error("Failed to configure server", "error", err)
+		os.Exit(1)
+	}
+
+	// Start the TCP and UDP stream listeners; the specs were checked by Validate
+	streamListeners, err := cfg.Streams.ParseListeners()
+	if err != nil {
+		appLogger.Error("Invalid stream listeners", "error", err)
+		os.Exit(1)
+	}
+	streamProxies := make([]stream.Proxy, 0, len(streamListeners))
+	for _, listener := range streamListeners {
+		proxy := stream.NewProxy(listener, appLogger)
+		if err := proxy.Start(); err != nil {
+			appLogger.Error("Failed to start stream listener", "address", listener.Address, "error", err)
+			os.Exit(1)
+		}
+		streamProxies = append(streamProxies, proxy)
+	}
+
+	// Start the egress listener; its server stops on the same signals as the main server
+	if cfg.Egress.Port > 0 {
+		egressUseCase := usecase.NewEgressUseCase(egressDestinations(cfg.Egress), httpClient, rateLimitService, appLogger)
+		if requestSigner != nil {
+			egressUseCase.SetRequestSigner(requestSigner)
+		}
+		egressServer := api.NewServer(
+			api.NewEgressHandler(egressUseCase, appLogger),
+			cfg.Egress.Port,
+			cfg.Server.ReadTimeout,
+			cfg.Server.WriteTimeout,
+			cfg.Server.ShutdownTimeout,
+			appLogger,
+		)
+		go func() {
+			if err := egressServer.Start(); err != nil {
+				appLogger.Error("Egress server failed", "error", err)
+			}
+		}()
+		appLogger.Info("Egress listener initialized", "port", cfg.Egress.Port, "destinations", len(cfg.Egress.Destinations))
+	}
+
+	// Start the gRPC control plane; it stops after the main server
+	var controlPlane *controlplane.Server
+	if cfg.ControlPlane.Port > 0 {
+		controlPlaneTLS := cfg.ControlPlane.TLS
+		if controlPlaneTLS.CertFile == "" {
+			controlPlaneTLS = cfg.Server.TLS
+		}
+		var creds credentials.TransportCredentials
+		if controlPlaneTLS.CertFile != "" {
+			var err error
+			creds, err = credentials.NewServerTLSFromFile(controlPlaneTLS.CertFile, controlPlaneTLS.KeyFile)
+			if err != nil {
+				appLogger.Error("Failed to load control plane certificate", "error", err)
+				os.Exit(1)
+			}
+		} else {
+			appLogger.Warn("Control plane served in plaintext, Basic credentials are refused")
+		}
+		controlPlane = controlplane.NewServer(
+			controlplane.NewAdminService(serviceUseCase, statsUseCase, eventBus),
+			authUseCase,
+			cfg.ControlPlane.Port,
+			creds,
+			cfg.Server.ShutdownTimeout,
+			appLogger,
+		)
+		if err := controlPlane.Start(); err != nil {
+			appLogger.Error("Failed to start control plane", "error", err)
+			os.Exit(1)
+		}
+		appLogger.Info("Control plane initialized", "port", cfg.ControlPlane.Port)
+	}
 
 	// Start server
 	appLogger.Info("Server initialized", "port", cfg.Server.Port)
@@ -131,6 +769,283 @@ func main() {
 	if err := server.Stop(); err != nil {
 		appLogger.Error("Server forced to shutdown", "error", err)
 	}
+	if controlPlane != nil {
+		controlPlane.Stop()
+	}
+	if xdsConn != nil {
+		stopBackground()
+		xdsConn.Close()
+	}
+	for _, proxy := range streamProxies {
+		if err := proxy.Close(); err != nil {
+			appLogger.Error("Failed to close stream listener", "address", proxy.Addr().String(), "error", err)
+		}
+	}
+	for _, publisher := range publishers {
+		if err := publisher.Close(); err != nil {
+			appLogger.Error("Failed to close message broker", "error", err)
+		}
+	}
 
 	appLogger.Info("Server exiting")
+	if exposureLogger != nil {
+		exposureLogger.Sync()
+	}
+	zapLogger.Sync()
+}
+
+// loggerOptions returns the outputs, sampling and async writing of the application logger
+func loggerOptions(cfg config.LoggingConfig) (logger.Options, error) {
+	var options logger.Options
+	for _, outputCfg := range cfg.Outputs {
+		var output logger.Output
+		switch outputCfg.Type {
+		case "stdout":
+			output = logger.StreamOutput(os.Stdout)
+			output.Console = cfg.Development
+		case "stderr":
+			output = logger.StreamOutput(os.Stderr)
+			output.Console = cfg.Development
+		case "file":
+			output = logger.FileOutput(outputCfg.Path, outputCfg.MaxSizeMB, outputCfg.MaxBackups, outputCfg.MaxAgeDays, outputCfg.Compress)
+		case "syslog":
+			var err error
+			if output, err = logger.SyslogOutput(outputCfg.Address, outputCfg.Tag); err != nil {
+				return options, err
+			}
+		case "loki":
+			output = logger.LokiOutput(outputCfg.URL, outputCfg.Labels, outputCfg.BatchSize, outputCfg.FlushInterval, outputCfg.Timeout)
+		}
+		output.Level = outputCfg.Level
+		options.Outputs = append(options.Outputs, output)
+	}
+	if sampling := cfg.Sampling; sampling.Enabled {
+		options.Sampling = &logger.SamplingOptions{Initial: sampling.Initial, Thereafter: sampling.Thereafter, Tick: sampling.Tick}
+	}
+	if async := cfg.Async; async.Enabled {
+		options.Async = &logger.AsyncOptions{QueueSize: async.QueueSize, BufferSize: async.BufferSize, FlushInterval: async.FlushInterval}
+	}
+	return options, nil
+}
+
+// validateConfig prints configuration problems and warnings and returns the process exit code.
+// Secrets are not fetched, so values supplied by a secrets provider are validated at startup.
+func validateConfig(cfg *config.Config) int {
+	for _, warning := range cfg.Warnings() {
+		fmt.Fprintf(os.Stderr, "warning: %s\n", warning)
+	}
+	if err := cfg.Validate(); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	fmt.Println("configuration is valid")
+	return 0
+}
+
+// egressDestinations converts the configured egress destinations to entities
+func egressDestinations(cfg config.EgressConfig) []entity.EgressDestination {
+	destinations := make([]entity.EgressDestination, len(cfg.Destinations))
+	for i, destination := range cfg.Destinations {
+		destinations[i] = entity.EgressDestination{
+			Host:      destination.Host,
+			Scheme:    destination.Scheme,
+			RateLimit: destination.RateLimit,
+			Headers:   destination.Headers,
+		}
+		if signing := destination.Signing; signing != nil {
+			destinations[i].Signing = &entity.UpstreamSigning{
+				Scheme:      signing.Scheme,
+				Credentials: signing.Credentials,
+				Region:      signing.Region,
+				Service:     signing.Service,
+			}
+		}
+	}
+	return destinations
+}
+
+// errorTemplates converts the configured error templates to their entities
+func errorTemplates(cfg config.ErrorPagesConfig) []entity.ErrorTemplate {
+	templates := make([]entity.ErrorTemplate, len(cfg.Templates))
+	for i, template := range cfg.Templates {
+		templates[i] = entity.ErrorTemplate{
+			Status:      template.Status,
+			ContentType: template.ContentType,
+			Body:        template.Body,
+		}
+	}
+	return templates
+}
+
+// newTranslator creates the translator of gateway errors from the configured catalogs, nil
+// when there are none
+func newTranslator(cfg config.I18nConfig) (*i18n.Translator, error) {
+	if cfg.Directory == "" && len(cfg.Catalogs) == 0 {
+		return nil, nil
+	}
+
+	catalogs := make(map[string]i18n.Catalog)
+	if cfg.Directory != "" {
+		loaded, err := i18n.LoadCatalogs(cfg.Directory)
+		if err != nil {
+			return nil, err
+		}
+		catalogs = loaded
+	}
+	for locale, catalog := range cfg.Catalogs {
+		// The config file's locales are lowercased, e.g. pt-br for the catalog of pt-BR.yaml
+		for loaded := range catalogs {
+			if strings.EqualFold(loaded, locale) {
+				delete(catalogs, loaded)
+			}
+		}
+		catalogs[locale] = i18n.Catalog{Titles: catalog.Titles, Messages: catalog.Messages}
+	}
+	return i18n.NewTranslator(cfg.DefaultLocale, catalogs)
+}
+
+// loadSigningKey creates the initial JWT signing key for the configured algorithm
+func loadSigningKey(cfg config.AuthConfig) (*auth.SigningKey, error) {
+	if strings.HasPrefix(cfg.Algorithm, "HS") || cfg.Algorithm == "" {
+		return auth.NewHMACKey(cfg.Algorithm, []byte(cfg.SecretKey)), nil
+	}
+
+	// Configuration validation requires a key file when other instances verify the tokens
+	if cfg.PrivateKeyFile == "" {
+		return auth.GenerateKey(cfg.Algorithm)
+	}
+
+	pemData, err := os.ReadFile(cfg.PrivateKeyFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read private key file: %w", err)
+	}
+	return auth.NewKeyFromPEM(cfg.Algorithm, pemData)
+}
+
+// loadCertificate reads a PEM encoded certificate file
+func loadCertificate(file string) (*x509.Certificate, error) {
+	pemData, err := os.ReadFile(file)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read certificate file: %w", err)
+	}
+	return auth.ParseCertificatePEM(pemData)
+}
+
+// repositories groups the repositories backed by the configured storage
+type repositories struct {
+	services domainrepo.ServiceRepository
+	webhooks domainrepo.WebhookRepository
+	apiKeys  domainrepo.APIKeyRepository
+	jobs     domainrepo.ScheduledJobRepository
+	// revisions keeps the revision history of services
+	revisions domainrepo.ServiceRevisionRepository
+	// rateLimitOverrides keeps the rate limits given to consumers
+	rateLimitOverrides domainrepo.RateLimitOverrideRepository
+	// routePolicies keeps the scheduled policies of routes
+	routePolicies domainrepo.RoutePolicyRepository
+	// featureFlags keeps the flags of the built-in flag store
+	featureFlags domainrepo.FeatureFlagRepository
+	// store backs the repositories of the file backend, nil otherwise
+	store *repository.FileStore
+	// db backs the repositories of the postgres backend, nil otherwise
+	db *sql.DB
+}
+
+// newRepositories creates the repositories for the configured storage backend
+func newRepositories(cfg *config.Config, appLogger logger.Logger) (*repositories, error) {
+	switch cfg.Storage.Backend {
+	case "", storageBackendPostgres:
+		db, err := persistence.NewDatabase(cfg.Database)
+		if err != nil {
+			return nil, err
+		}
+		sqlDB, err := db.DB()
+		if err != nil {
+			return nil, err
+		}
+		return &repositories{
+			services:           repository.NewServiceRepositoryImpl(db, appLogger),
+			webhooks:           repository.NewWebhookRepositoryImpl(db, appLogger),
+			apiKeys:            repository.NewAPIKeyRepositoryImpl(db, appLogger),
+			jobs:               repository.NewScheduledJobRepositoryImpl(db, appLogger),
+			revisions:          repository.NewServiceRevisionRepositoryImpl(db, appLogger),
+			rateLimitOverrides: repository.NewRateLimitOverrideRepositoryImpl(db, appLogger),
+			routePolicies:      repository.NewRoutePolicyRepositoryImpl(db, appLogger),
+			featureFlags:       repository.NewFeatureFlagRepositoryImpl(db, appLogger),
+			db:                 sqlDB,
+		}, nil
+	case storageBackendFile:
+		store, err := repository.NewFileStore(cfg.Storage.File.Path)
+		if err != nil {
+			return nil, err
+		}
+		appLogger.Info("Using file storage", "path", cfg.Storage.File.Path)
+		return &repositories{
+			services:           repository.NewFileServiceRepository(store, appLogger),
+			webhooks:           repository.NewFileWebhookRepository(store, appLogger),
+			apiKeys:            repository.NewFileAPIKeyRepository(store, appLogger),
+			jobs:               repository.NewFileScheduledJobRepository(store, appLogger),
+			revisions:          repository.NewFileServiceRevisionRepository(store, appLogger),
+			rateLimitOverrides: repository.NewFileRateLimitOverrideRepository(store, appLogger),
+			routePolicies:      repository.NewFileRoutePolicyRepository(store, appLogger),
+			featureFlags:       repository.NewFileFeatureFlagRepository(store, appLogger),
+			store:              store,
+		}, nil
+	default:
+		return nil, fmt.Errorf("unknown storage backend %q", cfg.Storage.Backend)
+	}
+}
+
+// usesRedis reports whether the configured cache or rate limiter backend, config sync or leader
+// election needs Redis
+func usesRedis(cfg *config.Config) bool {
+	return cfg.Cache.Backend == "" || cfg.Cache.Backend == cache.BackendRedis ||
+		cfg.RateLimit.Backend != rateLimitBackendMemory || cfg.ConfigSync.Enabled ||
+		cfg.LeaderElection.Backend == leaderElectionBackendRedis
+}
+
+// leaderElector is a leader elector that campaigns in the background once started
+type leaderElector interface {
+	service.LeaderElector
+	Start(ctx context.Context)
+}
+
+// newLeaderElector creates the leader elector for the configured backend, or nil if leader election is disabled
+func newLeaderElector(cfg config.LeaderElectionConfig, repos *repositories, redisClient redis.UniversalClient, appLogger logger.Logger) leaderElector {
+	switch cfg.Backend {
+	case leaderElectionBackendRedis:
+		return election.NewRedisElector(redisClient, cfg.Key, cfg.LeaseDuration, cfg.RenewInterval, appLogger)
+	case leaderElectionBackendPostgres:
+		// Validate ensures the postgres storage backend, which opened the database
+		return election.NewPostgresElector(repos.db, cfg.Key, cfg.RenewInterval, appLogger)
+	default:
+		return nil
+	}
+}
+
+// newNotifier builds the alert dispatcher for the configured channels, or nil if none are configured.
+// Service owners are notified when email or Slack channels are configured. Channels deliver in the
+// background until ctx is cancelled and are returned to report their health.
+func newNotifier(ctx context.Context, cfg config.AlertingConfig, serviceRepo domainrepo.ServiceRepository, mailer service.Mailer, appLogger logger.Logger) (*alerting.Dispatcher, []*alerting.BufferedChannel) {
+	var channels []alerting.Channel
+	var buffered []*alerting.BufferedChannel
+	add := func(name string, channel alerting.Channel) {
+		c := alerting.NewBufferedChannel(name, channel, cfg.BufferSize, cfg.MaxRetries, cfg.FailureThreshold, cfg.RetryCooldown, appLogger)
+		c.Start(ctx)
+		channels = append(channels, c)
+		buffered = append(buffered, c)
+	}
+	for i, url := range cfg.Webhooks {
+		add(fmt.Sprintf("alerts.webhook[%d]", i), alerting.NewWebhookChannel(url, cfg.Timeout))
+	}
+	if cfg.SlackWebhookURL != "" {
+		add("alerts.slack", alerting.NewSlackChannel(cfg.SlackWebhookURL, cfg.Timeout))
+	}
+	if mailer != nil || len(cfg.SlackChannels) > 0 {
+		add("alerts.owners", alerting.NewOwnerChannel(serviceRepo, mailer, cfg.SlackChannels, cfg.Timeout))
+	}
+	if len(channels) == 0 {
+		return nil, nil
+	}
+	return alerting.NewDispatcher(channels, cfg.Cooldown, appLogger), buffered
 }
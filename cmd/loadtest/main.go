@@ -0,0 +1,117 @@
+// Command loadtest drives a steady request rate against a running gateway over a mix of routes
+// and reports latency percentiles and error rates, so releases can be compared under the same load.
+//
+//	go run ./cmd/loadtest -url http://localhost:8080 -rate 200 -duration 1m \
+//	  -route "GET /api/v1/catalog 3" -route "GET /api/v1/orders/42" -header "Authorization: Bearer <token>"
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+
+	"api-gateway-sample/pkg/loadtest"
+)
+
+// stringList collects the values of a repeatable flag
+type stringList []string
+
+func (l *stringList) String() string {
+	return strings.Join(*l, ", ")
+}
+
+func (l *stringList) Set(value string) error {
+	*l = append(*l, value)
+	return nil
+}
+
+func main() {
+	var routeFlags, headerFlags stringList
+	baseURL := flag.String("url", "http://localhost:8080", "base URL of the gateway")
+	flag.Var(&routeFlags, "route", `route in the mix as "METHOD PATH [WEIGHT]" (repeatable)`)
+	routesFile := flag.String("routes", "", "JSON file of routes with optional weight, headers and body")
+	flag.Var(&headerFlags, "header", `header sent with every request as "Name: value" (repeatable)`)
+	rate := flag.Int("rate", 100, "requests started per second")
+	duration := flag.Duration("duration", 30*time.Second, "how long to send requests")
+	concurrency := flag.Int("concurrency", 100, "requests in flight at most; requests due beyond it are skipped")
+	timeout := flag.Duration("timeout", 10*time.Second, "timeout of each request")
+	interval := flag.Duration("interval", 0, "print a report of every interval, for soak tests; 0 disables")
+	jsonOutput := flag.Bool("json", false, "print the final report as JSON")
+	maxP99 := flag.Float64("max-p99", 0, "exit with status 1 when the p99 latency in milliseconds is above this; 0 disables")
+	maxErrorRate := flag.Float64("max-error-rate", 1, "exit with status 1 when the error rate (0-1) is above this")
+	flag.Parse()
+
+	cfg := loadtest.Config{
+		BaseURL:     *baseURL,
+		Headers:     map[string]string{},
+		Rate:        *rate,
+		Duration:    *duration,
+		Concurrency: *concurrency,
+		Timeout:     *timeout,
+	}
+	if *routesFile != "" {
+		routes, err := loadtest.LoadRoutes(*routesFile)
+		if err != nil {
+			fail(err)
+		}
+		cfg.Routes = routes
+	}
+	for _, value := range routeFlags {
+		route, err := loadtest.ParseRoute(value)
+		if err != nil {
+			fail(err)
+		}
+		cfg.Routes = append(cfg.Routes, route)
+	}
+	for _, value := range headerFlags {
+		name, headerValue, ok := strings.Cut(value, ":")
+		if !ok || strings.TrimSpace(name) == "" {
+			fail(fmt.Errorf("header %q must be Name: value", value))
+		}
+		cfg.Headers[strings.TrimSpace(name)] = strings.TrimSpace(headerValue)
+	}
+
+	runner, err := loadtest.NewRunner(cfg)
+	if err != nil {
+		fail(err)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	fmt.Fprintf(os.Stderr, "Sending %d req/s to %s for %s over %d routes\n", cfg.Rate, cfg.BaseURL, cfg.Duration, len(cfg.Routes))
+	report := runner.Run(ctx, *interval, func(report *loadtest.Report) {
+		fmt.Fprintf(os.Stderr, "%s  %d requests, %.1f req/s, p50 %.1fms, p99 %.1fms, %.2f%% errors, %d skipped\n",
+			time.Now().Format(time.TimeOnly), report.Requests, report.Throughput, report.Latency.P50, report.Latency.P99, report.ErrorRate*100, report.Skipped)
+	})
+
+	if *jsonOutput {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		err = encoder.Encode(report)
+	} else {
+		err = report.Write(os.Stdout)
+	}
+	if err != nil {
+		fail(err)
+	}
+
+	if *maxP99 > 0 && report.Latency.P99 > *maxP99 {
+		fail(fmt.Errorf("p99 latency %.1fms is above %.1fms", report.Latency.P99, *maxP99))
+	}
+	if report.ErrorRate > *maxErrorRate {
+		fail(fmt.Errorf("error rate %.2f%% is above %.2f%%", report.ErrorRate*100, *maxErrorRate*100))
+	}
+}
+
+// fail prints the error and exits with status 1
+func fail(err error) {
+	fmt.Fprintln(os.Stderr, "loadtest:", err)
+	os.Exit(1)
+}
@@ -0,0 +1,25 @@
+package dto
+
+// DesiredStateRequest represents the complete set of services the gateway should serve
+type DesiredStateRequest struct {
+	Services []CreateServiceRequest `json:"services" validate:"dive"`
+}
+
+// DriftReport represents the differences between the services served and a desired state,
+// each listed by service name
+type DriftReport struct {
+	// Applied is true when the changes were made, false for a dry run or without drift
+	Applied   bool           `json:"applied"`
+	Created   []string       `json:"created"`
+	Updated   []ServiceDrift `json:"updated"`
+	Deleted   []string       `json:"deleted"`
+	Unchanged []string       `json:"unchanged"`
+	// Conflicts are the routes the desired services would serve more than once
+	Conflicts []RouteConflict `json:"conflicts"`
+}
+
+// ServiceDrift represents a service whose definition differs from the desired one
+type ServiceDrift struct {
+	Name   string   `json:"name"`
+	Fields []string `json:"fields"` // JSON names of the differing fields, e.g. baseUrl
+}
@@ -0,0 +1,26 @@
+package dto
+
+import "api-gateway-sample/internal/domain/entity"
+
+// FaultRequest represents a request to inject faults into the requests to an endpoint
+type FaultRequest struct {
+	Endpoint         string  `json:"endpoint" validate:"required,startswith=/"`
+	DelayMs          int     `json:"delayMs" validate:"min=0"`
+	DelayProbability float64 `json:"delayProbability" validate:"min=0,max=1"`
+	AbortStatus      int     `json:"abortStatus" validate:"omitempty,min=500,max=599"`
+	AbortProbability float64 `json:"abortProbability" validate:"min=0,max=1"`
+	ResetProbability float64 `json:"resetProbability" validate:"min=0,max=1"`
+}
+
+// ToEntity converts the request to the fault of an endpoint of the given service
+func (r *FaultRequest) ToEntity(serviceID string) *entity.Fault {
+	return &entity.Fault{
+		ServiceID:        serviceID,
+		Endpoint:         r.Endpoint,
+		DelayMs:          r.DelayMs,
+		DelayProbability: r.DelayProbability,
+		AbortStatus:      r.AbortStatus,
+		AbortProbability: r.AbortProbability,
+		ResetProbability: r.ResetProbability,
+	}
+}
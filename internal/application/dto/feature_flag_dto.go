@@ -0,0 +1,25 @@
+package dto
+
+import "api-gateway-sample/internal/domain/entity"
+
+// FeatureFlagRequest represents a request to create or replace a feature flag
+type FeatureFlagRequest struct {
+	// Key is ignored when a flag is replaced, as it is given by the path
+	Key         string `json:"key" validate:"max=255"`
+	Description string `json:"description"`
+	Enabled     bool   `json:"enabled"`
+	// Segment is an authorization policy expression selecting the callers, empty for all
+	Segment    string `json:"segment"`
+	Percentage *int   `json:"percentage" validate:"omitempty,min=0,max=100"`
+}
+
+// ToEntity converts the request to a flag without timestamps
+func (r *FeatureFlagRequest) ToEntity() *entity.FeatureFlag {
+	return &entity.FeatureFlag{
+		Key:         r.Key,
+		Description: r.Description,
+		Enabled:     r.Enabled,
+		Segment:     r.Segment,
+		Percentage:  r.Percentage,
+	}
+}
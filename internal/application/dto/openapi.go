@@ -0,0 +1,184 @@
+package dto
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"api-gateway-sample/internal/domain/entity"
+)
+
+// pathParameter matches {name} placeholders in endpoint paths
+var pathParameter = regexp.MustCompile(`\{([^}/]+)\}`)
+
+// OpenAPIDocument is an OpenAPI 3.0 description of a service's endpoints as exposed by the gateway
+type OpenAPIDocument struct {
+	OpenAPI    string                          `json:"openapi"`
+	Info       OpenAPIInfo                     `json:"info"`
+	Servers    []OpenAPIServer                 `json:"servers"`
+	Paths      map[string]map[string]Operation `json:"paths"`
+	Components *OpenAPIComponents              `json:"components,omitempty"`
+}
+
+// OpenAPIInfo describes the service
+type OpenAPIInfo struct {
+	Title       string `json:"title"`
+	Version     string `json:"version"`
+	Description string `json:"description,omitempty"`
+}
+
+// OpenAPIServer is the base URL requests are sent to
+type OpenAPIServer struct {
+	URL string `json:"url"`
+}
+
+// Operation describes a single method on a path
+type Operation struct {
+	OperationID string                     `json:"operationId"`
+	Summary     string                     `json:"summary"`
+	Description string                     `json:"description,omitempty"`
+	Parameters  []OpenAPIParameter         `json:"parameters,omitempty"`
+	Responses   map[string]OpenAPIResponse `json:"responses"`
+	Security    []map[string][]string      `json:"security,omitempty"`
+}
+
+// OpenAPIParameter describes a path parameter
+type OpenAPIParameter struct {
+	Name     string            `json:"name"`
+	In       string            `json:"in"`
+	Required bool              `json:"required"`
+	Schema   map[string]string `json:"schema"`
+}
+
+// OpenAPIResponse describes a response status
+type OpenAPIResponse struct {
+	Description string `json:"description"`
+}
+
+// OpenAPIComponents holds the security schemes referenced by operations
+type OpenAPIComponents struct {
+	SecuritySchemes map[string]map[string]string `json:"securitySchemes"`
+}
+
+// NewOpenAPIDocument builds the OpenAPI document of a service. Request and response
+// bodies are not known to the gateway, so only paths, methods, path parameters,
+// authentication and gateway-generated errors are described.
+func NewOpenAPIDocument(s *entity.Service, serverURL string) *OpenAPIDocument {
+	version := s.Version
+	if version == "" {
+		version = "1.0.0"
+	}
+
+	doc := &OpenAPIDocument{
+		OpenAPI: "3.0.3",
+		Info: OpenAPIInfo{
+			Title:       s.Name,
+			Version:     version,
+			Description: s.Description,
+		},
+		Servers: []OpenAPIServer{{URL: serverURL}},
+		Paths:   make(map[string]map[string]Operation),
+	}
+
+	for _, endpoint := range s.Endpoints {
+		operations, ok := doc.Paths[endpoint.Path]
+		if !ok {
+			operations = make(map[string]Operation)
+			doc.Paths[endpoint.Path] = operations
+		}
+
+		for _, method := range endpoint.Methods {
+			operations[strings.ToLower(method)] = newOperation(endpoint, method)
+		}
+
+		if endpoint.AuthRequired && doc.Components == nil {
+			doc.Components = &OpenAPIComponents{
+				SecuritySchemes: map[string]map[string]string{
+					"bearerAuth": {"type": "http", "scheme": "bearer", "bearerFormat": "JWT"},
+				},
+			}
+		}
+	}
+
+	return doc
+}
+
+func newOperation(endpoint entity.Endpoint, method string) Operation {
+	operation := Operation{
+		OperationID: operationID(method, endpoint.Path),
+		Summary:     fmt.Sprintf("%s %s", method, endpoint.Path),
+		Responses: map[string]OpenAPIResponse{
+			"200": {Description: "Response from the upstream service"},
+			"502": {Description: "The upstream service could not be reached"},
+		},
+	}
+
+	for _, match := range pathParameter.FindAllStringSubmatch(endpoint.Path, -1) {
+		operation.Parameters = append(operation.Parameters, OpenAPIParameter{
+			Name:     match[1],
+			In:       "path",
+			Required: true,
+			Schema:   map[string]string{"type": "string"},
+		})
+	}
+
+	if endpoint.AuthRequired {
+		operation.Security = []map[string][]string{{"bearerAuth": {}}}
+		operation.Responses["401"] = OpenAPIResponse{Description: "Missing or invalid credentials"}
+		operation.Responses["403"] = OpenAPIResponse{Description: "The caller is not allowed to use this endpoint"}
+	}
+	if len(endpoint.RequiredScopes) > 0 {
+		operation.Description = fmt.Sprintf("Requires the %s scopes.", strings.Join(endpoint.RequiredScopes, ", "))
+	}
+	if endpoint.RateLimit > 0 {
+		operation.Description = strings.TrimSpace(fmt.Sprintf("%s Limited to %d requests per minute per client.", operation.Description, endpoint.RateLimit))
+	}
+	if endpoint.MaxConcurrent > 0 {
+		operation.Description = strings.TrimSpace(fmt.Sprintf("%s At most %d concurrent requests per client.", operation.Description, endpoint.MaxConcurrent))
+	}
+	if endpoint.SpikeArrest != nil {
+		operation.Description = strings.TrimSpace(fmt.Sprintf("%s Requests are spaced out to %d per second.", operation.Description, endpoint.SpikeArrest.Rate))
+	}
+	if endpoint.Mock != nil {
+		operation.Description = strings.TrimSpace(operation.Description + " Responses are mocked and do not reach the service.")
+	}
+	if endpoint.SOAP != nil {
+		operation.Responses["400"] = OpenAPIResponse{Description: "The JSON request body lacks a value of the SOAP request"}
+	}
+	if endpoint.Encryption != nil {
+		operation.Description = strings.TrimSpace(operation.Description + " Bodies are exchanged as JWE compact serializations (application/jose).")
+		if endpoint.Encryption.Requests {
+			operation.Responses["400"] = OpenAPIResponse{Description: "The request body is not encrypted for this endpoint"}
+		}
+	}
+	if masking := endpoint.Masking; masking != nil {
+		if masking.Scope != "" {
+			operation.Description = strings.TrimSpace(fmt.Sprintf("%s Personal data in responses is masked without the %s scope.", operation.Description, masking.Scope))
+		} else {
+			operation.Description = strings.TrimSpace(operation.Description + " Personal data in responses is masked.")
+		}
+	}
+	if upload := endpoint.Upload; upload != nil {
+		if upload.MaxFileSize > 0 {
+			operation.Description = strings.TrimSpace(fmt.Sprintf("%s Uploaded files may not exceed %d bytes.", operation.Description, upload.MaxFileSize))
+			operation.Responses["413"] = OpenAPIResponse{Description: "An uploaded file exceeds the maximum file size"}
+		}
+		if upload.Scan {
+			operation.Responses["422"] = OpenAPIResponse{Description: "An uploaded file was rejected by the scanner"}
+		}
+	}
+
+	return operation
+}
+
+// operationID derives an identifier such as getApiV1OrdersId from the method and path
+func operationID(method string, path string) string {
+	var b strings.Builder
+	b.WriteString(strings.ToLower(method))
+	for _, part := range strings.FieldsFunc(path, func(r rune) bool {
+		return !(r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9')
+	}) {
+		b.WriteString(strings.ToUpper(part[:1]) + part[1:])
+	}
+	return b.String()
+}
@@ -0,0 +1,15 @@
+package dto
+
+// OwnershipReport lists the services by owning team, and the services no team owns
+type OwnershipReport struct {
+	Teams []TeamServices `json:"teams"`
+	// Unowned are the names of the services without an owner, whose alerts reach only the
+	// gateway-wide channels
+	Unowned []string `json:"unowned"`
+}
+
+// TeamServices lists the services a team owns by name
+type TeamServices struct {
+	Team     string   `json:"team"`
+	Services []string `json:"services"`
+}
@@ -0,0 +1,101 @@
+package dto
+
+import (
+	"time"
+
+	"api-gateway-sample/internal/domain/entity"
+)
+
+// PortalServiceResponse represents a published service in the developer portal. It omits
+// the upstream URL and other internals that consumers must not see.
+type PortalServiceResponse struct {
+	ID          string           `json:"id"`
+	Name        string           `json:"name"`
+	Version     string           `json:"version"`
+	Description string           `json:"description"`
+	Endpoints   []PortalEndpoint `json:"endpoints"`
+}
+
+// PortalEndpoint represents a service endpoint in the developer portal
+type PortalEndpoint struct {
+	Path         string   `json:"path"`
+	Methods      []string `json:"methods"`
+	AuthRequired bool     `json:"authRequired"`
+	// RateLimit is the number of requests allowed per minute, 0 if unlimited
+	RateLimit int `json:"rateLimit"`
+}
+
+// APIKeySignupRequest represents a consumer's request for an API key
+type APIKeySignupRequest struct {
+	Consumer string `json:"consumer" validate:"required"`
+	Email    string `json:"email" validate:"required,email"`
+	Purpose  string `json:"purpose"`
+}
+
+// ApproveAPIKeyRequest represents an administrator's approval of an API key request
+type ApproveAPIKeyRequest struct {
+	// Plan names the consumer's plan, which sets the priority of its requests under load
+	Plan string `json:"plan"`
+}
+
+// RejectAPIKeyRequest represents an administrator's rejection of an API key request
+type RejectAPIKeyRequest struct {
+	Reason string `json:"reason"`
+}
+
+// APIKeyResponse represents an API key in API responses
+type APIKeyResponse struct {
+	ID         string     `json:"id"`
+	ServiceID  string     `json:"serviceId"`
+	Consumer   string     `json:"consumer"`
+	Email      string     `json:"email"`
+	Purpose    string     `json:"purpose"`
+	Status     string     `json:"status"`
+	Prefix     string     `json:"prefix,omitempty"`
+	Plan       string     `json:"plan,omitempty"`
+	ReviewedBy string     `json:"reviewedBy,omitempty"`
+	ReviewedAt *time.Time `json:"reviewedAt,omitempty"`
+	Reason     string     `json:"reason,omitempty"`
+	CreatedAt  time.Time  `json:"createdAt"`
+	// Key is only set in the approval response when the key could not be emailed to the consumer
+	Key string `json:"key,omitempty"`
+}
+
+// FromPortalEntity converts a service to its developer portal representation
+func FromPortalEntity(s *entity.Service) *PortalServiceResponse {
+	endpoints := make([]PortalEndpoint, len(s.Endpoints))
+	for i, e := range s.Endpoints {
+		endpoints[i] = PortalEndpoint{
+			Path:         e.Path,
+			Methods:      e.Methods,
+			AuthRequired: e.AuthRequired,
+			RateLimit:    e.RateLimit,
+		}
+	}
+
+	return &PortalServiceResponse{
+		ID:          s.ID,
+		Name:        s.Name,
+		Version:     s.Version,
+		Description: s.Description,
+		Endpoints:   endpoints,
+	}
+}
+
+// FromAPIKeyEntity converts an API key to its response
+func FromAPIKeyEntity(key *entity.APIKey) *APIKeyResponse {
+	return &APIKeyResponse{
+		ID:         key.ID,
+		ServiceID:  key.ServiceID,
+		Consumer:   key.Consumer,
+		Email:      key.Email,
+		Purpose:    key.Purpose,
+		Status:     key.Status,
+		Prefix:     key.Prefix,
+		Plan:       key.Plan,
+		ReviewedBy: key.ReviewedBy,
+		ReviewedAt: key.ReviewedAt,
+		Reason:     key.Reason,
+		CreatedAt:  key.CreatedAt,
+	}
+}
@@ -0,0 +1,24 @@
+package dto
+
+import "api-gateway-sample/internal/domain/entity"
+
+// RateLimitOverrideRequest represents a request to create or replace a consumer's rate limit override
+type RateLimitOverrideRequest struct {
+	Consumer  string `json:"consumer" validate:"required,max=255"`
+	ServiceID string `json:"serviceId" validate:"required"`
+	// Endpoint is empty to override the limits of every endpoint of the service
+	Endpoint      string `json:"endpoint" validate:"omitempty,startswith=/"`
+	RateLimit     *int   `json:"rateLimit" validate:"required_without=MaxConcurrent,omitempty,min=0"`
+	MaxConcurrent *int   `json:"maxConcurrent" validate:"omitempty,min=0"`
+}
+
+// ToEntity converts the request to an override without ID and timestamps
+func (r *RateLimitOverrideRequest) ToEntity() *entity.RateLimitOverride {
+	return &entity.RateLimitOverride{
+		Consumer:      r.Consumer,
+		ServiceID:     r.ServiceID,
+		Endpoint:      r.Endpoint,
+		RateLimit:     r.RateLimit,
+		MaxConcurrent: r.MaxConcurrent,
+	}
+}
@@ -0,0 +1,42 @@
+package dto
+
+import "api-gateway-sample/internal/domain/entity"
+
+// RoutePolicyRequest represents a request to create or replace a scheduled route policy
+type RoutePolicyRequest struct {
+	Name      string `json:"name" validate:"max=255"`
+	ServiceID string `json:"serviceId" validate:"required"`
+	// Endpoint is empty to apply the policy to every endpoint of the service
+	Endpoint string `json:"endpoint" validate:"omitempty,startswith=/"`
+	Schedule string `json:"schedule" validate:"required"`
+	// Duration is how long each window stays open, in minutes
+	Duration      int    `json:"duration" validate:"required,min=1"`
+	Timezone      string `json:"timezone"`
+	Action        string `json:"action" validate:"required,oneof=disable maintenance rateLimit"`
+	Status        int    `json:"status" validate:"omitempty,min=400,max=599"`
+	Body          string `json:"body"`
+	ContentType   string `json:"contentType"`
+	RateLimit     *int   `json:"rateLimit" validate:"omitempty,min=0"`
+	MaxConcurrent *int   `json:"maxConcurrent" validate:"omitempty,min=0"`
+	// Enabled defaults to true
+	Enabled *bool `json:"enabled"`
+}
+
+// ToEntity converts the request to a policy without ID and timestamps
+func (r *RoutePolicyRequest) ToEntity() *entity.RoutePolicy {
+	return &entity.RoutePolicy{
+		Name:          r.Name,
+		ServiceID:     r.ServiceID,
+		Endpoint:      r.Endpoint,
+		Schedule:      r.Schedule,
+		Duration:      r.Duration,
+		Timezone:      r.Timezone,
+		Action:        r.Action,
+		Status:        r.Status,
+		Body:          r.Body,
+		ContentType:   r.ContentType,
+		RateLimit:     r.RateLimit,
+		MaxConcurrent: r.MaxConcurrent,
+		Enabled:       r.Enabled == nil || *r.Enabled,
+	}
+}
@@ -0,0 +1,78 @@
+package dto
+
+import (
+	"time"
+
+	"api-gateway-sample/internal/domain/entity"
+)
+
+// ScheduledJobRequest represents a request to create or replace a scheduled job
+type ScheduledJobRequest struct {
+	Name     string `json:"name" validate:"required"`
+	Schedule string `json:"schedule" validate:"required"`
+	// Method defaults to GET
+	Method  string            `json:"method" validate:"omitempty,oneof=GET POST PUT DELETE PATCH HEAD OPTIONS"`
+	Path    string            `json:"path" validate:"required,startswith=/"`
+	Headers map[string]string `json:"headers"`
+	Body    string            `json:"body"`
+	Timeout int               `json:"timeout" validate:"min=0"`
+	// Enabled defaults to true
+	Enabled *bool `json:"enabled"`
+}
+
+// ToEntity converts the request to a scheduled job without ID and timestamps
+func (r *ScheduledJobRequest) ToEntity() *entity.ScheduledJob {
+	job := &entity.ScheduledJob{
+		Name:     r.Name,
+		Schedule: r.Schedule,
+		Method:   r.Method,
+		Path:     r.Path,
+		Headers:  r.Headers,
+		Body:     r.Body,
+		Timeout:  r.Timeout,
+		Enabled:  true,
+	}
+	if job.Method == "" {
+		job.Method = "GET"
+	}
+	if r.Enabled != nil {
+		job.Enabled = *r.Enabled
+	}
+	return job
+}
+
+// ScheduledJobResponse represents a scheduled job in API responses
+type ScheduledJobResponse struct {
+	ID        string            `json:"id"`
+	Name      string            `json:"name"`
+	Schedule  string            `json:"schedule"`
+	Method    string            `json:"method"`
+	Path      string            `json:"path"`
+	Headers   map[string]string `json:"headers,omitempty"`
+	Body      string            `json:"body,omitempty"`
+	Timeout   int               `json:"timeout"`
+	Enabled   bool              `json:"enabled"`
+	CreatedAt time.Time         `json:"createdAt"`
+	UpdatedAt time.Time         `json:"updatedAt"`
+	// NextRun is the next scheduled run, absent for disabled jobs
+	NextRun *time.Time `json:"nextRun,omitempty"`
+	// LastRun is the most recent run on this gateway instance
+	LastRun *entity.JobRun `json:"lastRun,omitempty"`
+}
+
+// FromScheduledJobEntity converts a scheduled job to its response
+func FromScheduledJobEntity(job *entity.ScheduledJob) *ScheduledJobResponse {
+	return &ScheduledJobResponse{
+		ID:        job.ID,
+		Name:      job.Name,
+		Schedule:  job.Schedule,
+		Method:    job.Method,
+		Path:      job.Path,
+		Headers:   job.Headers,
+		Body:      job.Body,
+		Timeout:   job.Timeout,
+		Enabled:   job.Enabled,
+		CreatedAt: job.CreatedAt,
+		UpdatedAt: job.UpdatedAt,
+	}
+}
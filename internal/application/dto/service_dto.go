@@ -1,14 +1,39 @@
 package dto
 
 import (
+	"time"
+
 	"api-gateway-sample/internal/domain/entity"
 )
 
 // CreateServiceRequest represents a request to create a new service
 type CreateServiceRequest struct {
 	Name      string           `json:"name" validate:"required"`
-	BaseURL   string           `json:"baseUrl" validate:"required,url"`
+	BaseURL   string           `json:"baseUrl" validate:"required,baseurl"`
+	Published bool             `json:"published"`
 	Endpoints []EndpointConfig `json:"endpoints" validate:"required,dive"`
+	// Signing signs the requests sent to the service, omitted to send them unsigned
+	Signing *UpstreamSigningConfig `json:"signing,omitempty"`
+	// ErrorTemplates render the errors the gateway generates for the service, before the global ones
+	ErrorTemplates []ErrorTemplateConfig `json:"errorTemplates,omitempty" validate:"dive"`
+	// Residency routes requests to the upstream in the caller's region, omitted to use baseUrl
+	Residency *ResidencyConfig `json:"residency,omitempty"`
+	// Tags label the metrics, access logs and traces of the service's requests, e.g. team or tier
+	Tags map[string]string `json:"tags,omitempty" validate:"max=20,dive,keys,tagname,endkeys,max=128"`
+	// Owner is the team notified of the service's alerts, omitted when the service is unowned
+	Owner *OwnerConfig `json:"owner,omitempty"`
+	// AuthMethods restricts the service's protected endpoints to principals authenticated with
+	// one of these methods, e.g. saml, omitted to accept any
+	AuthMethods []string `json:"authMethods,omitempty" validate:"max=4,unique,dive,oneof=oidc saml ldap apikey"`
+	// Network names the upstream network in the gateway configuration the service is reached
+	// through, such as one behind a proxy, omitted for the default network
+	Network string `json:"network,omitempty" validate:"max=64"`
+	// UpstreamPath prefixes the path of requests to a unix:///path/to.sock base URL, whose own
+	// path is that of the socket
+	UpstreamPath string `json:"upstreamPath,omitempty" validate:"omitempty,startswith=/,max=2048"`
+	// ValidFrom and ValidUntil bound when the service is served, omitted for no bound
+	ValidFrom  *time.Time `json:"validFrom,omitempty"`
+	ValidUntil *time.Time `json:"validUntil,omitempty" validate:"omitempty,afterfield=ValidFrom"`
 }
 
 // EndpointConfig represents the configuration for a service endpoint
@@ -16,10 +41,15 @@ type EndpointConfig struct {
 	Path           string   `json:"path" validate:"required"`
 	Methods        []string `json:"methods" validate:"required,dive,oneof=GET POST PUT DELETE PATCH HEAD OPTIONS"`
 	RateLimit      int      `json:"rateLimit" validate:"min=0"`
+	MaxConcurrent  int      `json:"maxConcurrent" validate:"min=0"` // requests each client may have in flight
 	AuthRequired   bool     `json:"authRequired"`
 	Timeout        int      `json:"timeout" validate:"min=0"` // in seconds
 	RetryCount     int      `json:"retryCount" validate:"min=0"`
-	RetryDelay     int      `json:"retryDelay" validate:"min=0"` // in milliseconds
+	RetryDelay     int      `json:"retryDelay" validate:"min=0"`                       // in milliseconds
+	Policy         string   `json:"policy"`                                            // authorization policy expression
+	RequiredScopes []string `json:"requiredScopes,omitempty" validate:"max=20,unique"` // OAuth scopes callers must all be granted
+	Priority       int      `json:"priority,omitempty" validate:"min=-1000,max=1000"`  // orders the endpoints serving the same route, highest first
+	ContentTypes   []string `json:"contentTypes,omitempty" validate:"max=20,unique"`   // media types of the request bodies accepted, empty for any
 	CircuitBreaker struct {
 		Enabled          bool    `json:"enabled"`
 		FailureThreshold float64 `json:"failureThreshold" validate:"min=0,max=1"`
@@ -35,21 +65,626 @@ type EndpointConfig struct {
 		Request  map[string]string `json:"request"`  // header transformations
 		Response map[string]string `json:"response"` // header transformations
 	} `json:"transform"`
+	// Composite aggregates other routes instead of proxying to the service
+	Composite *CompositeConfig `json:"composite,omitempty"`
+	// Pipeline calls other routes in sequence instead of proxying to the service
+	Pipeline *PipelineConfig `json:"pipeline,omitempty" validate:"excluded_with=Composite"`
+	// Bridge publishes request bodies to a message broker instead of proxying to the service
+	Bridge *BridgeConfig `json:"bridge,omitempty" validate:"excluded_with=Composite Pipeline"`
+	// Async queues requests and answers 202 with a status URL instead of waiting for the service
+	Async bool `json:"async,omitempty"`
+	// SpikeArrest spaces out bursts of requests to the service instead of forwarding them at once
+	SpikeArrest *SpikeArrestConfig `json:"spikeArrest,omitempty"`
+	// NegativeCache caches error responses, such as 404 for missing resources, for a short time
+	NegativeCache *NegativeCacheConfig `json:"negativeCache,omitempty"`
+	// SLO is the service level objective the endpoint's compliance is tracked against
+	SLO *SLOConfig `json:"slo,omitempty"`
+	// Mock answers requests from fixtures instead of proxying to the service
+	Mock *MockConfig `json:"mock,omitempty" validate:"excluded_with=Composite Pipeline Bridge"`
+	// SOAP converts JSON requests to SOAP envelopes for the service and its XML responses to JSON
+	SOAP *SOAPConfig `json:"soap,omitempty" validate:"excluded_with=Composite Pipeline Bridge Mock"`
+	// Encryption decrypts request bodies and encrypts response bodies exchanged with clients
+	Encryption *PayloadEncryptionConfig `json:"encryption,omitempty"`
+	// Masking masks personal data in responses for callers without the scope to see it
+	Masking *ResponseMaskingConfig `json:"masking,omitempty"`
+	// Upload streams multipart uploads to the service instead of buffering them
+	Upload *UploadConfig `json:"upload,omitempty"`
+	// ResponseLimit bounds the size of the service's responses the gateway buffers
+	ResponseLimit *ResponseLimitConfig `json:"responseLimit,omitempty"`
+	// Tags add to or override the service's tags for the endpoint's requests
+	Tags map[string]string `json:"tags,omitempty" validate:"max=20,dive,keys,tagname,endkeys,max=128"`
+	// Experiment splits the endpoint's callers between variants served by different upstreams
+	Experiment *ExperimentConfig `json:"experiment,omitempty"`
+	// FeatureFlag serves the endpoint only to the callers the flag is on for
+	FeatureFlag string `json:"featureFlag,omitempty" validate:"max=255"`
+	// ValidFrom and ValidUntil bound when the endpoint is served, within its service's window
+	ValidFrom  *time.Time `json:"validFrom,omitempty"`
+	ValidUntil *time.Time `json:"validUntil,omitempty" validate:"omitempty,afterfield=ValidFrom"`
+}
+
+// CompositeConfig represents the routes a composite endpoint fans out to
+type CompositeConfig struct {
+	Timeout int                   `json:"timeout" validate:"min=0"` // in seconds, for all calls together
+	Calls   []CompositeCallConfig `json:"calls" validate:"required,min=1,unique=Name,dive"`
+}
+
+// CompositeCallConfig represents a single route called by a composite endpoint
+type CompositeCallConfig struct {
+	Name     string `json:"name" validate:"required"`
+	Method   string `json:"method,omitempty" validate:"omitempty,oneof=GET POST PUT DELETE PATCH"`
+	Path     string `json:"path" validate:"required,startswith=/"`
+	Optional bool   `json:"optional"`
+}
+
+// ToEntity converts the composite configuration to its entity, nil when the endpoint is not composite
+func (c *CompositeConfig) ToEntity() *entity.Composite {
+	if c == nil {
+		return nil
+	}
+	calls := make([]entity.CompositeCall, len(c.Calls))
+	for i, call := range c.Calls {
+		calls[i] = entity.CompositeCall(call)
+	}
+	return &entity.Composite{Timeout: c.Timeout, Calls: calls}
+}
+
+// FromCompositeEntity creates a CompositeConfig from a Composite entity
+func FromCompositeEntity(c *entity.Composite) *CompositeConfig {
+	if c == nil {
+		return nil
+	}
+	calls := make([]CompositeCallConfig, len(c.Calls))
+	for i, call := range c.Calls {
+		calls[i] = CompositeCallConfig(call)
+	}
+	return &CompositeConfig{Timeout: c.Timeout, Calls: calls}
+}
+
+// PipelineConfig represents the routes a pipeline endpoint calls in sequence
+type PipelineConfig struct {
+	Timeout int                  `json:"timeout" validate:"min=0"` // in seconds, for all steps together
+	Steps   []PipelineStepConfig `json:"steps" validate:"required,min=1,unique=Name,dive"`
+}
+
+// PipelineStepConfig represents a single step of a pipeline endpoint
+type PipelineStepConfig struct {
+	Name    string            `json:"name" validate:"required,excludes=.,ne=query"`
+	Method  string            `json:"method,omitempty" validate:"omitempty,oneof=GET POST PUT DELETE PATCH"`
+	Path    string            `json:"path" validate:"required,startswith=/"`
+	Query   map[string]string `json:"query,omitempty"`
+	OnError string            `json:"onError,omitempty" validate:"omitempty,oneof=fail continue stop"`
+}
+
+// ToEntity converts the pipeline configuration to its entity, nil when the endpoint is not a pipeline
+func (p *PipelineConfig) ToEntity() *entity.Pipeline {
+	if p == nil {
+		return nil
+	}
+	steps := make([]entity.PipelineStep, len(p.Steps))
+	for i, step := range p.Steps {
+		steps[i] = entity.PipelineStep(step)
+	}
+	return &entity.Pipeline{Timeout: p.Timeout, Steps: steps}
+}
+
+// FromPipelineEntity creates a PipelineConfig from a Pipeline entity
+func FromPipelineEntity(p *entity.Pipeline) *PipelineConfig {
+	if p == nil {
+		return nil
+	}
+	steps := make([]PipelineStepConfig, len(p.Steps))
+	for i, step := range p.Steps {
+		steps[i] = PipelineStepConfig(step)
+	}
+	return &PipelineConfig{Timeout: p.Timeout, Steps: steps}
+}
+
+// BridgeConfig represents the broker and topic a bridge endpoint publishes to
+type BridgeConfig struct {
+	Broker   string `json:"broker" validate:"required,oneof=kafka rabbitmq nats"`
+	Topic    string `json:"topic" validate:"required"`
+	Key      string `json:"key,omitempty"`
+	Delivery string `json:"delivery,omitempty" validate:"omitempty,oneof=at-least-once at-most-once"`
+}
+
+// ToEntity converts the bridge configuration to its entity, nil when the endpoint is not a bridge
+func (b *BridgeConfig) ToEntity() *entity.Bridge {
+	if b == nil {
+		return nil
+	}
+	bridge := entity.Bridge(*b)
+	return &bridge
+}
+
+// FromBridgeEntity creates a BridgeConfig from a Bridge entity
+func FromBridgeEntity(b *entity.Bridge) *BridgeConfig {
+	if b == nil {
+		return nil
+	}
+	bridge := BridgeConfig(*b)
+	return &bridge
+}
+
+// SpikeArrestConfig represents the rate at which an endpoint delivers requests to its service
+type SpikeArrestConfig struct {
+	Rate     int `json:"rate" validate:"min=1"`     // requests per second
+	MaxDelay int `json:"maxDelay" validate:"min=0"` // in milliseconds
+}
+
+// ToEntity converts the spike arrest configuration to its entity, nil when bursts are not smoothed
+func (s *SpikeArrestConfig) ToEntity() *entity.SpikeArrest {
+	if s == nil {
+		return nil
+	}
+	spikeArrest := entity.SpikeArrest(*s)
+	return &spikeArrest
+}
+
+// FromSpikeArrestEntity creates a SpikeArrestConfig from a SpikeArrest entity
+func FromSpikeArrestEntity(s *entity.SpikeArrest) *SpikeArrestConfig {
+	if s == nil {
+		return nil
+	}
+	spikeArrest := SpikeArrestConfig(*s)
+	return &spikeArrest
+}
+
+// NegativeCacheConfig represents the error responses an endpoint caches
+type NegativeCacheConfig struct {
+	TTL      int   `json:"ttl" validate:"min=1"` // in seconds
+	Statuses []int `json:"statuses,omitempty" validate:"dive,min=400,max=599"`
+}
+
+// ToEntity converts the negative cache configuration to its entity, nil when errors are not cached
+func (n *NegativeCacheConfig) ToEntity() *entity.NegativeCache {
+	if n == nil {
+		return nil
+	}
+	negativeCache := entity.NegativeCache(*n)
+	return &negativeCache
+}
+
+// FromNegativeCacheEntity creates a NegativeCacheConfig from a NegativeCache entity
+func FromNegativeCacheEntity(n *entity.NegativeCache) *NegativeCacheConfig {
+	if n == nil {
+		return nil
+	}
+	negativeCache := NegativeCacheConfig(*n)
+	return &negativeCache
+}
+
+// SLOConfig represents the service level objective of an endpoint
+type SLOConfig struct {
+	Objective float64 `json:"objective" validate:"gt=0,lt=1"` // e.g. 0.999
+	LatencyMs int     `json:"latencyMs,omitempty" validate:"min=0"`
+}
+
+// ToEntity converts the SLO configuration to its entity, nil when the endpoint has no SLO
+func (s *SLOConfig) ToEntity() *entity.SLO {
+	if s == nil {
+		return nil
+	}
+	slo := entity.SLO(*s)
+	return &slo
+}
+
+// FromSLOEntity creates an SLOConfig from an SLO entity
+func FromSLOEntity(s *entity.SLO) *SLOConfig {
+	if s == nil {
+		return nil
+	}
+	slo := SLOConfig(*s)
+	return &slo
+}
+
+// MockConfig represents the fixtures a mock endpoint answers from
+type MockConfig struct {
+	Fixtures []MockFixtureConfig `json:"fixtures" validate:"required,min=1,dive"`
+}
+
+// MockFixtureConfig represents a response served to the requests it matches
+type MockFixtureConfig struct {
+	Match struct {
+		Method  string            `json:"method,omitempty" validate:"omitempty,oneof=GET POST PUT DELETE PATCH HEAD OPTIONS"`
+		Query   map[string]string `json:"query,omitempty"`
+		Headers map[string]string `json:"headers,omitempty"`
+	} `json:"match"`
+	Response struct {
+		Status  int               `json:"status,omitempty" validate:"omitempty,min=100,max=599"`
+		Headers map[string]string `json:"headers,omitempty"`
+		Body    string            `json:"body,omitempty"`
+	} `json:"response"`
+}
+
+// ToEntity converts the mock configuration to its entity, nil when the endpoint is not mocked
+func (m *MockConfig) ToEntity() *entity.Mock {
+	if m == nil {
+		return nil
+	}
+	fixtures := make([]entity.MockFixture, len(m.Fixtures))
+	for i, fixture := range m.Fixtures {
+		fixtures[i] = entity.MockFixture{
+			Match:    entity.MockMatch(fixture.Match),
+			Response: entity.MockResponse(fixture.Response),
+		}
+	}
+	return &entity.Mock{Fixtures: fixtures}
+}
+
+// FromMockEntity creates a MockConfig from a Mock entity
+func FromMockEntity(m *entity.Mock) *MockConfig {
+	if m == nil {
+		return nil
+	}
+	fixtures := make([]MockFixtureConfig, len(m.Fixtures))
+	for i, fixture := range m.Fixtures {
+		fixtures[i].Match.Method = fixture.Match.Method
+		fixtures[i].Match.Query = fixture.Match.Query
+		fixtures[i].Match.Headers = fixture.Match.Headers
+		fixtures[i].Response.Status = fixture.Response.Status
+		fixtures[i].Response.Headers = fixture.Response.Headers
+		fixtures[i].Response.Body = fixture.Response.Body
+	}
+	return &MockConfig{Fixtures: fixtures}
+}
+
+// SOAPConfig represents the SOAP operation a SOAP endpoint calls
+type SOAPConfig struct {
+	Path     string `json:"path,omitempty"`
+	Version  string `json:"version,omitempty" validate:"omitempty,oneof=1.1 1.2"`
+	Action   string `json:"action,omitempty"`
+	Template string `json:"template" validate:"required"` // content of the SOAP body, e.g. <GetOrder><id>{{body.id}}</id></GetOrder>
+}
+
+// ToEntity converts the SOAP configuration to its entity, nil when the endpoint is not a SOAP endpoint
+func (s *SOAPConfig) ToEntity() *entity.SOAP {
+	if s == nil {
+		return nil
+	}
+	soap := entity.SOAP(*s)
+	return &soap
+}
+
+// FromSOAPEntity creates a SOAPConfig from a SOAP entity
+func FromSOAPEntity(s *entity.SOAP) *SOAPConfig {
+	if s == nil {
+		return nil
+	}
+	soap := SOAPConfig(*s)
+	return &soap
+}
+
+// PayloadEncryptionConfig represents the encryption of an endpoint's request and response bodies,
+// with keys named after secrets
+type PayloadEncryptionConfig struct {
+	Key         string `json:"key" validate:"required"`
+	ResponseKey string `json:"responseKey,omitempty"`
+	Requests    bool   `json:"requests"`
+	Responses   bool   `json:"responses"`
+}
+
+// ToEntity converts the encryption configuration to its entity, nil when payloads are not encrypted
+func (e *PayloadEncryptionConfig) ToEntity() *entity.PayloadEncryption {
+	if e == nil {
+		return nil
+	}
+	encryption := entity.PayloadEncryption(*e)
+	return &encryption
+}
+
+// FromPayloadEncryptionEntity creates a PayloadEncryptionConfig from a payload encryption entity
+func FromPayloadEncryptionEntity(e *entity.PayloadEncryption) *PayloadEncryptionConfig {
+	if e == nil {
+		return nil
+	}
+	encryption := PayloadEncryptionConfig(*e)
+	return &encryption
+}
+
+// ResponseMaskingConfig represents the masking of personal data in an endpoint's responses
+type ResponseMaskingConfig struct {
+	Scope string              `json:"scope,omitempty"`
+	Rules []MaskingRuleConfig `json:"rules" validate:"required,min=1,dive"`
+}
+
+// MaskingRuleConfig represents the masking of the response fields selected by a JSONPath expression
+type MaskingRuleConfig struct {
+	Path     string `json:"path" validate:"required"` // e.g. $.customers[*].email or $..ssn
+	Strategy string `json:"strategy,omitempty" validate:"omitempty,oneof=redact partial email hash remove"`
+}
+
+// ToEntity converts the masking configuration to its entity, nil when responses are not masked
+func (m *ResponseMaskingConfig) ToEntity() *entity.ResponseMasking {
+	if m == nil {
+		return nil
+	}
+	masking := &entity.ResponseMasking{Scope: m.Scope, Rules: make([]entity.MaskingRule, len(m.Rules))}
+	for i, rule := range m.Rules {
+		masking.Rules[i] = entity.MaskingRule(rule)
+	}
+	return masking
+}
+
+// FromResponseMaskingEntity creates a ResponseMaskingConfig from a response masking entity
+func FromResponseMaskingEntity(m *entity.ResponseMasking) *ResponseMaskingConfig {
+	if m == nil {
+		return nil
+	}
+	masking := &ResponseMaskingConfig{Scope: m.Scope, Rules: make([]MaskingRuleConfig, len(m.Rules))}
+	for i, rule := range m.Rules {
+		masking.Rules[i] = MaskingRuleConfig(rule)
+	}
+	return masking
+}
+
+// UploadConfig represents how an endpoint streams multipart uploads
+type UploadConfig struct {
+	MaxFileSize int64 `json:"maxFileSize,omitempty" validate:"min=0"` // in bytes, 0 for no limit
+	Scan        bool  `json:"scan,omitempty"`                         // check files with the upload scanner
+}
+
+// ToEntity converts the upload configuration to its entity, nil when uploads are buffered
+func (u *UploadConfig) ToEntity() *entity.Upload {
+	if u == nil {
+		return nil
+	}
+	upload := entity.Upload(*u)
+	return &upload
+}
+
+// FromUploadEntity creates an UploadConfig from an Upload entity
+func FromUploadEntity(u *entity.Upload) *UploadConfig {
+	if u == nil {
+		return nil
+	}
+	upload := UploadConfig(*u)
+	return &upload
+}
+
+// ResponseLimitConfig represents the largest upstream response an endpoint buffers
+type ResponseLimitConfig struct {
+	MaxSize int64  `json:"maxSize" validate:"min=1"`                                  // in bytes
+	Policy  string `json:"policy,omitempty" validate:"omitempty,oneof=reject stream"` // for larger responses
+}
+
+// ToEntity converts the response limit configuration to its entity, nil when responses are unbounded
+func (l *ResponseLimitConfig) ToEntity() *entity.ResponseLimit {
+	if l == nil {
+		return nil
+	}
+	limit := entity.ResponseLimit(*l)
+	return &limit
+}
+
+// FromResponseLimitEntity creates a ResponseLimitConfig from a ResponseLimit entity
+func FromResponseLimitEntity(l *entity.ResponseLimit) *ResponseLimitConfig {
+	if l == nil {
+		return nil
+	}
+	limit := ResponseLimitConfig(*l)
+	return &limit
+}
+
+// ExperimentConfig represents an A/B experiment splitting the callers of an endpoint between variants
+type ExperimentConfig struct {
+	Name     string                    `json:"name" validate:"required,max=128"`
+	StickyBy string                    `json:"stickyBy,omitempty" validate:"omitempty,oneof=cookie user"`
+	Cookie   string                    `json:"cookie,omitempty" validate:"max=128"` // "exp_" and the name when empty
+	Variants []ExperimentVariantConfig `json:"variants" validate:"required,min=2,dive"`
+}
+
+// ExperimentVariantConfig represents a variant of an experiment
+type ExperimentVariantConfig struct {
+	Name    string `json:"name" validate:"required,max=128"`
+	Weight  int    `json:"weight" validate:"min=0"`
+	BaseURL string `json:"baseUrl,omitempty" validate:"omitempty,url"` // the service's baseUrl when empty
+}
+
+// ToEntity converts the experiment configuration to its entity, nil when the endpoint runs none
+func (e *ExperimentConfig) ToEntity() *entity.Experiment {
+	if e == nil {
+		return nil
+	}
+	experiment := &entity.Experiment{Name: e.Name, StickyBy: e.StickyBy, Cookie: e.Cookie}
+	for _, variant := range e.Variants {
+		experiment.Variants = append(experiment.Variants, entity.ExperimentVariant(variant))
+	}
+	return experiment
+}
+
+// FromExperimentEntity creates an ExperimentConfig from an experiment entity
+func FromExperimentEntity(e *entity.Experiment) *ExperimentConfig {
+	if e == nil {
+		return nil
+	}
+	experiment := &ExperimentConfig{Name: e.Name, StickyBy: e.StickyBy, Cookie: e.Cookie}
+	for _, variant := range e.Variants {
+		experiment.Variants = append(experiment.Variants, ExperimentVariantConfig(variant))
+	}
+	return experiment
+}
+
+// OwnerConfig represents the team that owns a service and how it is notified of its alerts
+type OwnerConfig struct {
+	Team  string `json:"team" validate:"required,max=128"`
+	Email string `json:"email,omitempty" validate:"omitempty,email"`
+	// Slack is the name of the Slack channel, without "#"
+	Slack string `json:"slack,omitempty" validate:"omitempty,max=80,slackchannel"`
+}
+
+// ToEntity converts the owner configuration to its entity, nil when the service is unowned
+func (o *OwnerConfig) ToEntity() *entity.Owner {
+	if o == nil {
+		return nil
+	}
+	owner := entity.Owner(*o)
+	return &owner
+}
+
+// FromOwnerEntity creates an OwnerConfig from an owner entity
+func FromOwnerEntity(o *entity.Owner) *OwnerConfig {
+	if o == nil {
+		return nil
+	}
+	owner := OwnerConfig(*o)
+	return &owner
+}
+
+// ResidencyConfig represents the regional upstreams of a service with data residency
+type ResidencyConfig struct {
+	Targets       []RegionalTargetConfig `json:"targets" validate:"required,min=1,dive"`
+	DefaultRegion string                 `json:"defaultRegion,omitempty"`
+	Failover      map[string][]string    `json:"failover,omitempty"` // e.g. {"ca": ["us"]}
+}
+
+// RegionalTargetConfig represents an upstream of a service in a region
+type RegionalTargetConfig struct {
+	Region  string `json:"region" validate:"required"`
+	BaseURL string `json:"baseUrl" validate:"required,url"`
+}
+
+// ToEntity converts the residency configuration to its entity, nil when the service has none
+func (r *ResidencyConfig) ToEntity() *entity.Residency {
+	if r == nil {
+		return nil
+	}
+	residency := &entity.Residency{DefaultRegion: r.DefaultRegion, Failover: r.Failover}
+	for _, target := range r.Targets {
+		residency.Targets = append(residency.Targets, entity.RegionalTarget(target))
+	}
+	return residency
+}
+
+// FromResidencyEntity creates a ResidencyConfig from a residency entity
+func FromResidencyEntity(r *entity.Residency) *ResidencyConfig {
+	if r == nil {
+		return nil
+	}
+	residency := &ResidencyConfig{DefaultRegion: r.DefaultRegion, Failover: r.Failover}
+	for _, target := range r.Targets {
+		residency.Targets = append(residency.Targets, RegionalTargetConfig(target))
+	}
+	return residency
+}
+
+// UpstreamSigningConfig represents how the gateway signs the requests it sends to a service
+type UpstreamSigningConfig struct {
+	Scheme      string `json:"scheme" validate:"oneof=aws-sigv4 hmac oauth2"`
+	Credentials string `json:"credentials" validate:"required"` // name in upstream.credentials, or upstream.oauthClients with oauth2
+	Region      string `json:"region,omitempty"`                // aws-sigv4 only, e.g. eu-west-1
+	Service     string `json:"service,omitempty"`               // aws-sigv4 only, e.g. execute-api, lambda or s3
+}
+
+// ToEntity converts the signing configuration to its entity, nil when requests are unsigned
+func (s *UpstreamSigningConfig) ToEntity() *entity.UpstreamSigning {
+	if s == nil {
+		return nil
+	}
+	signing := entity.UpstreamSigning(*s)
+	return &signing
+}
+
+// FromUpstreamSigningEntity creates an UpstreamSigningConfig from an UpstreamSigning entity
+func FromUpstreamSigningEntity(s *entity.UpstreamSigning) *UpstreamSigningConfig {
+	if s == nil {
+		return nil
+	}
+	signing := UpstreamSigningConfig(*s)
+	return &signing
+}
+
+// ErrorTemplateConfig represents the body of the errors the gateway generates, for a status or a class of statuses
+type ErrorTemplateConfig struct {
+	Status      string `json:"status,omitempty"` // e.g. 404 or 5xx, omitted for every error
+	ContentType string `json:"contentType" validate:"required"`
+	Body        string `json:"body" validate:"required"`
+}
+
+// ToErrorTemplateEntities converts error template configurations to their entities
+func ToErrorTemplateEntities(templates []ErrorTemplateConfig) []entity.ErrorTemplate {
+	if len(templates) == 0 {
+		return nil
+	}
+	entities := make([]entity.ErrorTemplate, len(templates))
+	for i, t := range templates {
+		entities[i] = entity.ErrorTemplate(t)
+	}
+	return entities
+}
+
+// FromErrorTemplateEntities creates error template configurations from their entities
+func FromErrorTemplateEntities(templates []entity.ErrorTemplate) []ErrorTemplateConfig {
+	if len(templates) == 0 {
+		return nil
+	}
+	configs := make([]ErrorTemplateConfig, len(templates))
+	for i, t := range templates {
+		configs[i] = ErrorTemplateConfig(t)
+	}
+	return configs
 }
 
 // UpdateServiceRequest represents a request to update an existing service
 type UpdateServiceRequest struct {
 	Name      string           `json:"name" validate:"required"`
-	BaseURL   string           `json:"baseUrl" validate:"required,url"`
+	BaseURL   string           `json:"baseUrl" validate:"required,baseurl"`
+	Published bool             `json:"published"`
 	Endpoints []EndpointConfig `json:"endpoints" validate:"required,dive"`
+	// Signing signs the requests sent to the service, omitted to send them unsigned
+	Signing *UpstreamSigningConfig `json:"signing,omitempty"`
+	// ErrorTemplates render the errors the gateway generates for the service, before the global ones
+	ErrorTemplates []ErrorTemplateConfig `json:"errorTemplates,omitempty" validate:"dive"`
+	// Residency routes requests to the upstream in the caller's region, omitted to use baseUrl
+	Residency *ResidencyConfig `json:"residency,omitempty"`
+	// Tags label the metrics, access logs and traces of the service's requests, e.g. team or tier
+	Tags map[string]string `json:"tags,omitempty" validate:"max=20,dive,keys,tagname,endkeys,max=128"`
+	// Owner is the team notified of the service's alerts, omitted when the service is unowned
+	Owner *OwnerConfig `json:"owner,omitempty"`
+	// AuthMethods restricts the service's protected endpoints to principals authenticated with
+	// one of these methods, e.g. saml, omitted to accept any
+	AuthMethods []string `json:"authMethods,omitempty" validate:"max=4,unique,dive,oneof=oidc saml ldap apikey"`
+	// Network names the upstream network in the gateway configuration the service is reached
+	// through, such as one behind a proxy, omitted for the default network
+	Network string `json:"network,omitempty" validate:"max=64"`
+	// UpstreamPath prefixes the path of requests to a unix:///path/to.sock base URL, whose own
+	// path is that of the socket
+	UpstreamPath string `json:"upstreamPath,omitempty" validate:"omitempty,startswith=/,max=2048"`
+	// ValidFrom and ValidUntil bound when the service is served, omitted for no bound
+	ValidFrom  *time.Time `json:"validFrom,omitempty"`
+	ValidUntil *time.Time `json:"validUntil,omitempty" validate:"omitempty,afterfield=ValidFrom"`
+	// Revision is the revision the client last read, taken from If-Match; zero skips the check
+	Revision int64 `json:"-"`
 }
 
 // ServiceResponse represents a service in API responses
 type ServiceResponse struct {
-	ID        string           `json:"id"`
-	Name      string           `json:"name"`
-	BaseURL   string           `json:"baseUrl"`
-	Endpoints []EndpointConfig `json:"endpoints"`
+	ID        string                 `json:"id"`
+	Name      string                 `json:"name"`
+	BaseURL   string                 `json:"baseUrl"`
+	Published bool                   `json:"published"`
+	Endpoints []EndpointConfig       `json:"endpoints"`
+	Signing   *UpstreamSigningConfig `json:"signing,omitempty"`
+	// ErrorTemplates render the errors the gateway generates for the service
+	ErrorTemplates []ErrorTemplateConfig `json:"errorTemplates,omitempty"`
+	// Residency routes requests to the upstream in the caller's region
+	Residency *ResidencyConfig `json:"residency,omitempty"`
+	// Tags label the metrics, access logs and traces of the service's requests
+	Tags map[string]string `json:"tags,omitempty"`
+	// Owner is the team notified of the service's alerts
+	Owner *OwnerConfig `json:"owner,omitempty"`
+	// AuthMethods are the authentication methods the service's protected endpoints accept
+	AuthMethods []string `json:"authMethods,omitempty"`
+	// Network is the upstream network the service is reached through
+	Network string `json:"network,omitempty"`
+	// UpstreamPath prefixes the path of requests to a unix socket base URL
+	UpstreamPath string `json:"upstreamPath,omitempty"`
+	// ValidFrom and ValidUntil bound when the service is served
+	ValidFrom  *time.Time `json:"validFrom,omitempty"`
+	ValidUntil *time.Time `json:"validUntil,omitempty"`
+	Revision   int64      `json:"revision"`
+	// Conflicts are the routes other services serve with the same priority, reported after a
+	// change when route conflicts are allowed
+	Conflicts []RouteConflict `json:"conflicts,omitempty"`
 }
 
 // ToEntity converts a CreateServiceRequest to a Service entity
@@ -57,13 +692,18 @@ func (r *CreateServiceRequest) ToEntity() *entity.Service {
 	endpoints := make([]entity.Endpoint, len(r.Endpoints))
 	for i, e := range r.Endpoints {
 		endpoints[i] = entity.Endpoint{
-			Path:         e.Path,
-			Methods:      e.Methods,
-			RateLimit:    e.RateLimit,
-			AuthRequired: e.AuthRequired,
-			Timeout:      e.Timeout,
-			RetryCount:   e.RetryCount,
-			RetryDelay:   e.RetryDelay,
+			Path:           e.Path,
+			Methods:        e.Methods,
+			RateLimit:      e.RateLimit,
+			MaxConcurrent:  e.MaxConcurrent,
+			AuthRequired:   e.AuthRequired,
+			Timeout:        e.Timeout,
+			RetryCount:     e.RetryCount,
+			RetryDelay:     e.RetryDelay,
+			Policy:         e.Policy,
+			RequiredScopes: e.RequiredScopes,
+			Priority:       e.Priority,
+			ContentTypes:   e.ContentTypes,
 			CircuitBreaker: struct {
 				Enabled          bool    `json:"enabled"`
 				FailureThreshold float64 `json:"failureThreshold"`
@@ -91,13 +731,42 @@ func (r *CreateServiceRequest) ToEntity() *entity.Service {
 				Request:  e.Transform.Request,
 				Response: e.Transform.Response,
 			},
+			Composite:     e.Composite.ToEntity(),
+			Pipeline:      e.Pipeline.ToEntity(),
+			Bridge:        e.Bridge.ToEntity(),
+			Async:         e.Async,
+			SpikeArrest:   e.SpikeArrest.ToEntity(),
+			NegativeCache: e.NegativeCache.ToEntity(),
+			SLO:           e.SLO.ToEntity(),
+			Mock:          e.Mock.ToEntity(),
+			SOAP:          e.SOAP.ToEntity(),
+			Encryption:    e.Encryption.ToEntity(),
+			Masking:       e.Masking.ToEntity(),
+			Upload:        e.Upload.ToEntity(),
+			ResponseLimit: e.ResponseLimit.ToEntity(),
+			Tags:          e.Tags,
+			Experiment:    e.Experiment.ToEntity(),
+			FeatureFlag:   e.FeatureFlag,
+			ValidFrom:     e.ValidFrom,
+			ValidUntil:    e.ValidUntil,
 		}
 	}
 
 	return &entity.Service{
-		Name:      r.Name,
-		BaseURL:   r.BaseURL,
-		Endpoints: endpoints,
+		Name:           r.Name,
+		BaseURL:        r.BaseURL,
+		Published:      r.Published,
+		Endpoints:      endpoints,
+		Signing:        r.Signing.ToEntity(),
+		ErrorTemplates: ToErrorTemplateEntities(r.ErrorTemplates),
+		Residency:      r.Residency.ToEntity(),
+		Tags:           r.Tags,
+		Owner:          r.Owner.ToEntity(),
+		AuthMethods:    r.AuthMethods,
+		Network:        r.Network,
+		UpstreamPath:   r.UpstreamPath,
+		ValidFrom:      r.ValidFrom,
+		ValidUntil:     r.ValidUntil,
 	}
 }
 
@@ -106,13 +775,18 @@ func FromEntity(s *entity.Service) *ServiceResponse {
 	endpoints := make([]EndpointConfig, len(s.Endpoints))
 	for i, e := range s.Endpoints {
 		endpoints[i] = EndpointConfig{
-			Path:         e.Path,
-			Methods:      e.Methods,
-			RateLimit:    e.RateLimit,
-			AuthRequired: e.AuthRequired,
-			Timeout:      e.Timeout,
-			RetryCount:   e.RetryCount,
-			RetryDelay:   e.RetryDelay,
+			Path:           e.Path,
+			Methods:        e.Methods,
+			RateLimit:      e.RateLimit,
+			MaxConcurrent:  e.MaxConcurrent,
+			AuthRequired:   e.AuthRequired,
+			Timeout:        e.Timeout,
+			RetryCount:     e.RetryCount,
+			RetryDelay:     e.RetryDelay,
+			Policy:         e.Policy,
+			RequiredScopes: e.RequiredScopes,
+			Priority:       e.Priority,
+			ContentTypes:   e.ContentTypes,
 			CircuitBreaker: struct {
 				Enabled          bool    `json:"enabled"`
 				FailureThreshold float64 `json:"failureThreshold" validate:"min=0,max=1"`
@@ -140,13 +814,43 @@ func FromEntity(s *entity.Service) *ServiceResponse {
 				Request:  e.Transform.Request,
 				Response: e.Transform.Response,
 			},
+			Composite:     FromCompositeEntity(e.Composite),
+			Pipeline:      FromPipelineEntity(e.Pipeline),
+			Bridge:        FromBridgeEntity(e.Bridge),
+			Async:         e.Async,
+			SpikeArrest:   FromSpikeArrestEntity(e.SpikeArrest),
+			NegativeCache: FromNegativeCacheEntity(e.NegativeCache),
+			SLO:           FromSLOEntity(e.SLO),
+			Mock:          FromMockEntity(e.Mock),
+			SOAP:          FromSOAPEntity(e.SOAP),
+			Encryption:    FromPayloadEncryptionEntity(e.Encryption),
+			Masking:       FromResponseMaskingEntity(e.Masking),
+			Upload:        FromUploadEntity(e.Upload),
+			ResponseLimit: FromResponseLimitEntity(e.ResponseLimit),
+			Tags:          e.Tags,
+			Experiment:    FromExperimentEntity(e.Experiment),
+			FeatureFlag:   e.FeatureFlag,
+			ValidFrom:     e.ValidFrom,
+			ValidUntil:    e.ValidUntil,
 		}
 	}
 
 	return &ServiceResponse{
-		ID:        s.ID,
-		Name:      s.Name,
-		BaseURL:   s.BaseURL,
-		Endpoints: endpoints,
+		ID:             s.ID,
+		Name:           s.Name,
+		BaseURL:        s.BaseURL,
+		Published:      s.Published,
+		Endpoints:      endpoints,
+		Signing:        FromUpstreamSigningEntity(s.Signing),
+		ErrorTemplates: FromErrorTemplateEntities(s.ErrorTemplates),
+		Residency:      FromResidencyEntity(s.Residency),
+		Tags:           s.Tags,
+		Owner:          FromOwnerEntity(s.Owner),
+		AuthMethods:    s.AuthMethods,
+		Network:        s.Network,
+		UpstreamPath:   s.UpstreamPath,
+		ValidFrom:      s.ValidFrom,
+		ValidUntil:     s.ValidUntil,
+		Revision:       s.Revision,
 	}
 }
@@ -0,0 +1,28 @@
+package dto
+
+// ServicePlan represents the changes a service request would make, reported without making them
+type ServicePlan struct {
+	Action  string                `json:"action"`  // create or update
+	Service *ServiceResponse      `json:"service"` // the service as it would be stored
+	Changes []FieldChangeResponse `json:"changes"`
+	Routes  RouteImpact           `json:"routes"`
+}
+
+// RouteImpact represents the effect of a service change on the route table. Routes are written
+// as "METHOD path".
+type RouteImpact struct {
+	Added   []string `json:"added"`
+	Removed []string `json:"removed"`
+	// Conflicts are the routes also served by other services, of which only the first is reached
+	Conflicts []RouteConflict `json:"conflicts"`
+	// Shadowed are the routes never reached, as an earlier endpoint of the service has their path
+	Shadowed []string `json:"shadowed"`
+}
+
+// RouteConflict represents a route served by several services
+type RouteConflict struct {
+	Route    string   `json:"route"`
+	Services []string `json:"services"` // in order of precedence, the first serves the route
+	// Ambiguous is true when the first services have the same priority and are only ordered by name
+	Ambiguous bool `json:"ambiguous"`
+}
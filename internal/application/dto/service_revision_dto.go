@@ -0,0 +1,47 @@
+package dto
+
+import (
+	"encoding/json"
+	"time"
+
+	"api-gateway-sample/internal/domain/entity"
+)
+
+// ServiceRevisionResponse represents a stored revision of a service configuration
+type ServiceRevisionResponse struct {
+	Revision  int64     `json:"revision"`
+	Actor     string    `json:"actor,omitempty"`
+	CreatedAt time.Time `json:"createdAt"`
+	// RestoredFrom is the revision a rollback restored
+	RestoredFrom int64                 `json:"restoredFrom,omitempty"`
+	Changes      []FieldChangeResponse `json:"changes"`
+	Service      *ServiceResponse      `json:"service"`
+}
+
+// FieldChangeResponse represents a field changed by a revision, with its values before and after
+type FieldChangeResponse struct {
+	Field string          `json:"field"`
+	From  json.RawMessage `json:"from"`
+	To    json.RawMessage `json:"to"`
+}
+
+// FromServiceRevisionEntity converts a ServiceRevision entity to a ServiceRevisionResponse
+func FromServiceRevisionEntity(r *entity.ServiceRevision) *ServiceRevisionResponse {
+	return &ServiceRevisionResponse{
+		Revision:     r.Revision,
+		Actor:        r.Actor,
+		CreatedAt:    r.CreatedAt,
+		RestoredFrom: r.RestoredFrom,
+		Changes:      FromFieldChangeEntities(r.Changes),
+		Service:      FromEntity(r.Service),
+	}
+}
+
+// FromFieldChangeEntities converts FieldChange entities to FieldChangeResponses
+func FromFieldChangeEntities(changes []entity.FieldChange) []FieldChangeResponse {
+	responses := make([]FieldChangeResponse, len(changes))
+	for i, change := range changes {
+		responses[i] = FieldChangeResponse{Field: change.Field, From: change.From, To: change.To}
+	}
+	return responses
+}
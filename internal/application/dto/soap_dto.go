@@ -0,0 +1,10 @@
+package dto
+
+// SOAPOperationResponse represents a SOAP operation read from a WSDL document, with the
+// configuration of an endpoint calling it
+type SOAPOperationResponse struct {
+	Name string `json:"name"`
+	// Address is the URL of the SOAP service, whose origin is the base URL of the gateway service
+	Address string     `json:"address"`
+	SOAP    SOAPConfig `json:"soap"`
+}
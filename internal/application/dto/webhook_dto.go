@@ -0,0 +1,39 @@
+package dto
+
+import (
+	"time"
+
+	"api-gateway-sample/internal/domain/entity"
+)
+
+// CreateWebhookRequest represents a request to register a webhook subscription
+type CreateWebhookRequest struct {
+	URL         string   `json:"url" validate:"required,url"`
+	Events      []string `json:"events"`
+	Secret      string   `json:"secret"`
+	Description string   `json:"description"`
+}
+
+// WebhookResponse represents a webhook subscription in API responses
+type WebhookResponse struct {
+	ID          string    `json:"id"`
+	URL         string    `json:"url"`
+	Events      []string  `json:"events"`
+	Description string    `json:"description"`
+	Active      bool      `json:"active"`
+	CreatedAt   time.Time `json:"createdAt"`
+	// Secret is only returned when the subscription is created
+	Secret string `json:"secret,omitempty"`
+}
+
+// FromWebhookEntity converts a webhook subscription to its response, without the secret
+func FromWebhookEntity(webhook *entity.WebhookSubscription) *WebhookResponse {
+	return &WebhookResponse{
+		ID:          webhook.ID,
+		URL:         webhook.URL,
+		Events:      webhook.Events,
+		Description: webhook.Description,
+		Active:      webhook.Active,
+		CreatedAt:   webhook.CreatedAt,
+	}
+}
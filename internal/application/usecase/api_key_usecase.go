@@ -0,0 +1,232 @@
+package usecase
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"api-gateway-sample/internal/application/dto"
+	"api-gateway-sample/internal/domain/entity"
+	"api-gateway-sample/internal/domain/repository"
+	"api-gateway-sample/internal/domain/service"
+	"api-gateway-sample/pkg/errors"
+	"api-gateway-sample/pkg/logger"
+)
+
+// apiKeyPrefix starts every issued key so leaked keys are easy to recognise
+const apiKeyPrefix = "gw_"
+
+// apiKeyDisplayLength is the number of leading characters of a key kept to tell keys apart
+const apiKeyDisplayLength = len(apiKeyPrefix) + 8
+
+// APIKeyUseCase implements the approval workflow for API keys requested through the developer
+// portal and authenticates requests carrying an issued key
+type APIKeyUseCase struct {
+	apiKeyRepo  repository.APIKeyRepository
+	serviceRepo repository.ServiceRepository
+	events      service.EventPublisher
+	mailer      service.Mailer
+	logger      logger.Logger
+}
+
+// NewAPIKeyUseCase creates a new APIKeyUseCase instance
+func NewAPIKeyUseCase(apiKeyRepo repository.APIKeyRepository, serviceRepo repository.ServiceRepository, events service.EventPublisher, logger logger.Logger) *APIKeyUseCase {
+	return &APIKeyUseCase{
+		apiKeyRepo:  apiKeyRepo,
+		serviceRepo: serviceRepo,
+		events:      events,
+		logger:      logger,
+	}
+}
+
+// SetMailer emails review decisions, and approved keys, to consumers
+func (uc *APIKeyUseCase) SetMailer(mailer service.Mailer) {
+	uc.mailer = mailer
+}
+
+// ListKeys retrieves the API keys with the given status, or all keys when status is empty,
+// oldest first
+func (uc *APIKeyUseCase) ListKeys(ctx context.Context, status string) ([]*dto.APIKeyResponse, error) {
+	keys, err := uc.apiKeyRepo.GetAll(ctx)
+	if err != nil {
+		return nil, err
+	}
+	sort.SliceStable(keys, func(i, j int) bool {
+		return keys[i].CreatedAt.Before(keys[j].CreatedAt)
+	})
+
+	responses := make([]*dto.APIKeyResponse, 0, len(keys))
+	for _, key := range keys {
+		if status == "" || key.Status == status {
+			responses = append(responses, dto.FromAPIKeyEntity(key))
+		}
+	}
+	return responses, nil
+}
+
+// GetKey retrieves an API key by ID
+func (uc *APIKeyUseCase) GetKey(ctx context.Context, id string) (*dto.APIKeyResponse, error) {
+	key, err := uc.apiKeyRepo.Get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	return dto.FromAPIKeyEntity(key), nil
+}
+
+// ApproveKey issues the key of a pending request. Only its hash is stored: the key is emailed
+// to the consumer when a mailer is configured and otherwise returned in the response, so it
+// is delivered exactly once.
+func (uc *APIKeyUseCase) ApproveKey(ctx context.Context, id string, req *dto.ApproveAPIKeyRequest) (*dto.APIKeyResponse, error) {
+	key, err := uc.pendingKey(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	secret, err := generateAPIKey()
+	if err != nil {
+		return nil, err
+	}
+	key.Status = entity.APIKeyStatusApproved
+	key.KeyHash = entity.HashAPIKey(secret)
+	key.Prefix = secret[:apiKeyDisplayLength]
+	key.Plan = strings.TrimSpace(req.Plan)
+	uc.markReviewed(ctx, key)
+	if err := uc.apiKeyRepo.Update(ctx, key); err != nil {
+		return nil, err
+	}
+	publishAPIKeyEvent(ctx, uc.events, entity.EventAPIKeyApproved, key)
+
+	response := dto.FromAPIKeyEntity(key)
+	body := fmt.Sprintf("Your request for an API key to %s has been approved.\n\n"+
+		"API key: %s\n\n"+
+		"Send it in the X-API-Key header of your requests. Keep it secret: it is not stored "+
+		"by the gateway and cannot be shown again.\n", uc.serviceName(ctx, key.ServiceID), secret)
+	if !uc.sendMail(ctx, key, "Your API key has been approved", body) {
+		response.Key = secret
+	}
+
+	logger.FromContextOr(ctx, uc.logger).Info("API key approved", "key_id", key.ID, "service_id", key.ServiceID, "prefix", key.Prefix)
+	return response, nil
+}
+
+// RejectKey declines a pending request and tells the consumer why
+func (uc *APIKeyUseCase) RejectKey(ctx context.Context, id string, req *dto.RejectAPIKeyRequest) (*dto.APIKeyResponse, error) {
+	key, err := uc.pendingKey(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	key.Status = entity.APIKeyStatusRejected
+	key.Reason = strings.TrimSpace(req.Reason)
+	uc.markReviewed(ctx, key)
+	if err := uc.apiKeyRepo.Update(ctx, key); err != nil {
+		return nil, err
+	}
+	publishAPIKeyEvent(ctx, uc.events, entity.EventAPIKeyRejected, key)
+
+	body := fmt.Sprintf("Your request for an API key to %s has been rejected.\n", uc.serviceName(ctx, key.ServiceID))
+	if key.Reason != "" {
+		body += fmt.Sprintf("\nReason: %s\n", key.Reason)
+	}
+	uc.sendMail(ctx, key, "Your API key request has been rejected", body)
+
+	logger.FromContextOr(ctx, uc.logger).Info("API key rejected", "key_id", key.ID, "service_id", key.ServiceID)
+	return dto.FromAPIKeyEntity(key), nil
+}
+
+// Authenticate returns the approved API key matching a key presented by a client
+func (uc *APIKeyUseCase) Authenticate(ctx context.Context, secret string) (*entity.APIKey, error) {
+	if !strings.HasPrefix(secret, apiKeyPrefix) {
+		return nil, errors.ErrUnauthorized
+	}
+
+	key, err := uc.apiKeyRepo.FindByHash(ctx, entity.HashAPIKey(secret))
+	if err != nil {
+		if errors.IsNotFound(err) {
+			return nil, errors.ErrUnauthorized
+		}
+		return nil, err
+	}
+	if !key.Usable() {
+		return nil, errors.ErrUnauthorized
+	}
+	return key, nil
+}
+
+// pendingKey loads a key request that has not been reviewed yet
+func (uc *APIKeyUseCase) pendingKey(ctx context.Context, id string) (*entity.APIKey, error) {
+	key, err := uc.apiKeyRepo.Get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if key.Status != entity.APIKeyStatusPending {
+		return nil, errors.NewError(errors.CodeAlreadyExists, fmt.Sprintf("api key request is already %s", key.Status), errors.ErrAlreadyExists)
+	}
+	return key, nil
+}
+
+// markReviewed records who reviewed the key and when
+func (uc *APIKeyUseCase) markReviewed(ctx context.Context, key *entity.APIKey) {
+	now := time.Now()
+	key.ReviewedAt = &now
+	if principal, ok := entity.PrincipalFromContext(ctx); ok {
+		key.ReviewedBy = principal.UserID
+	}
+}
+
+// serviceName returns the name of a service for messages, falling back to its ID
+func (uc *APIKeyUseCase) serviceName(ctx context.Context, serviceID string) string {
+	if service, err := uc.serviceRepo.Get(ctx, serviceID); err == nil {
+		return service.Name
+	}
+	return serviceID
+}
+
+// sendMail emails the consumer of the key and reports whether the message was sent
+func (uc *APIKeyUseCase) sendMail(ctx context.Context, key *entity.APIKey, subject string, body string) bool {
+	if uc.mailer == nil {
+		return false
+	}
+	if err := uc.mailer.Send(ctx, key.Email, subject, body); err != nil {
+		logger.FromContextOr(ctx, uc.logger).Error("Failed to email consumer", "key_id", key.ID, "error", err)
+		return false
+	}
+	return true
+}
+
+// publishAPIKeyEvent announces an API key change on the event bus. The key itself is never
+// part of the event.
+func publishAPIKeyEvent(ctx context.Context, events service.EventPublisher, eventType string, key *entity.APIKey) {
+	if events == nil {
+		return
+	}
+
+	event := entity.NewEvent(eventType)
+	event.ServiceID = key.ServiceID
+	event.Data = map[string]interface{}{
+		"keyId":    key.ID,
+		"consumer": key.Consumer,
+		"email":    key.Email,
+		"status":   key.Status,
+	}
+	if key.Reason != "" {
+		event.Data["reason"] = key.Reason
+	}
+	if principal, ok := entity.PrincipalFromContext(ctx); ok {
+		event.Actor = principal.UserID
+	}
+	events.Publish(ctx, event)
+}
+
+// generateAPIKey returns a random API key
+func generateAPIKey() (string, error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", fmt.Errorf("failed to generate api key: %w", err)
+	}
+	return apiKeyPrefix + hex.EncodeToString(buf), nil
+}
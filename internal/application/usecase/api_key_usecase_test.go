@@ -0,0 +1,143 @@
+package usecase
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"api-gateway-sample/internal/application/dto"
+	"api-gateway-sample/internal/domain/entity"
+	"api-gateway-sample/internal/domain/repository"
+	"api-gateway-sample/internal/domain/repository/mock"
+	"api-gateway-sample/pkg/errors"
+)
+
+// recordingMailer records sent messages
+type recordingMailer struct {
+	to     []string
+	bodies []string
+}
+
+func (m *recordingMailer) Send(ctx context.Context, to string, subject string, body string) error {
+	m.to = append(m.to, to)
+	m.bodies = append(m.bodies, body)
+	return nil
+}
+
+// newAPIKeyFixture creates a use case with a pending key request for the orders service
+func newAPIKeyFixture(t *testing.T) (*APIKeyUseCase, repository.APIKeyRepository, *syncBus) {
+	t.Helper()
+	ctx := context.Background()
+	serviceRepo := newServiceRepository(t, newTestService("orders"))
+
+	apiKeyRepo := mock.NewAPIKeyRepositoryMock()
+	if err := apiKeyRepo.Create(ctx, &entity.APIKey{
+		ID:        "key-1",
+		ServiceID: "orders-id",
+		Consumer:  "Mobile App",
+		Email:     "mobile@example.com",
+		Status:    entity.APIKeyStatusPending,
+	}); err != nil {
+		t.Fatalf("Failed to create key request: %v", err)
+	}
+
+	bus := &syncBus{}
+	return NewAPIKeyUseCase(apiKeyRepo, serviceRepo, bus, &MockLogger{}), apiKeyRepo, bus
+}
+
+func TestAPIKeyUseCase_ApproveReturnsKeyOnce(t *testing.T) {
+	ctx := entity.ContextWithPrincipal(context.Background(), &entity.Principal{UserID: "admin"})
+	useCase, apiKeyRepo, bus := newAPIKeyFixture(t)
+
+	// 1. Without a mailer the key is returned to the administrator
+	response, err := useCase.ApproveKey(ctx, "key-1", &dto.ApproveAPIKeyRequest{})
+	if err != nil {
+		t.Fatalf("Failed to approve key: %v", err)
+	}
+	if !strings.HasPrefix(response.Key, apiKeyPrefix) || response.Prefix != response.Key[:apiKeyDisplayLength] {
+		t.Fatalf("Expected an issued key, got %+v", response)
+	}
+	if response.Status != entity.APIKeyStatusApproved || response.ReviewedBy != "admin" {
+		t.Errorf("Unexpected review state %+v", response)
+	}
+
+	// 2. Only the hash is stored and the key is not shown again
+	stored, _ := apiKeyRepo.Get(ctx, "key-1")
+	if stored.KeyHash != entity.HashAPIKey(response.Key) {
+		t.Errorf("Expected the key hash to be stored")
+	}
+	if again, _ := useCase.GetKey(ctx, "key-1"); again.Key != "" {
+		t.Errorf("Expected the key to be delivered only once")
+	}
+	if _, err := useCase.ApproveKey(ctx, "key-1", &dto.ApproveAPIKeyRequest{}); !errors.IsAlreadyExists(err) {
+		t.Errorf("Expected approving a reviewed key to fail, got %v", err)
+	}
+
+	// 3. The issued key authenticates, others do not
+	key, err := useCase.Authenticate(ctx, response.Key)
+	if err != nil || key.ID != "key-1" {
+		t.Errorf("Expected the issued key to authenticate, got %v", err)
+	}
+	if _, err := useCase.Authenticate(ctx, apiKeyPrefix+"unknown"); !errors.IsUnauthorized(err) {
+		t.Errorf("Expected an unknown key to be rejected, got %v", err)
+	}
+
+	// 4. Subscribers are notified without the key
+	if len(bus.events) != 1 || bus.events[0].Type != entity.EventAPIKeyApproved || bus.events[0].Actor != "admin" {
+		t.Fatalf("Expected an approval event, got %+v", bus.events)
+	}
+	for _, value := range bus.events[0].Data {
+		if value == response.Key {
+			t.Errorf("Expected the event not to contain the key")
+		}
+	}
+}
+
+func TestAPIKeyUseCase_ApproveEmailsKey(t *testing.T) {
+	ctx := context.Background()
+	useCase, apiKeyRepo, _ := newAPIKeyFixture(t)
+	mailer := &recordingMailer{}
+	useCase.SetMailer(mailer)
+
+	response, err := useCase.ApproveKey(ctx, "key-1", &dto.ApproveAPIKeyRequest{})
+	if err != nil {
+		t.Fatalf("Failed to approve key: %v", err)
+	}
+	if response.Key != "" {
+		t.Errorf("Expected the key to be emailed instead of returned")
+	}
+	if len(mailer.to) != 1 || mailer.to[0] != "mobile@example.com" {
+		t.Fatalf("Expected one email to the consumer, got %v", mailer.to)
+	}
+
+	stored, _ := apiKeyRepo.Get(ctx, "key-1")
+	if !strings.Contains(mailer.bodies[0], stored.Prefix) {
+		t.Errorf("Expected the email to contain the key, got %q", mailer.bodies[0])
+	}
+}
+
+func TestAPIKeyUseCase_Reject(t *testing.T) {
+	ctx := context.Background()
+	useCase, _, bus := newAPIKeyFixture(t)
+	mailer := &recordingMailer{}
+	useCase.SetMailer(mailer)
+
+	response, err := useCase.RejectKey(ctx, "key-1", &dto.RejectAPIKeyRequest{Reason: "Internal use only"})
+	if err != nil {
+		t.Fatalf("Failed to reject key: %v", err)
+	}
+	if response.Status != entity.APIKeyStatusRejected || response.Reason != "Internal use only" || response.Key != "" {
+		t.Errorf("Unexpected rejection %+v", response)
+	}
+	if len(mailer.bodies) != 1 || !strings.Contains(mailer.bodies[0], "Internal use only") {
+		t.Errorf("Expected the consumer to be told why, got %v", mailer.bodies)
+	}
+	if len(bus.events) != 1 || bus.events[0].Type != entity.EventAPIKeyRejected {
+		t.Errorf("Expected a rejection event, got %+v", bus.events)
+	}
+
+	pending, _ := useCase.ListKeys(ctx, entity.APIKeyStatusPending)
+	if len(pending) != 0 {
+		t.Errorf("Expected the review queue to be empty, got %d", len(pending))
+	}
+}
@@ -0,0 +1,256 @@
+package usecase
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"time"
+
+	"api-gateway-sample/internal/domain/entity"
+	"api-gateway-sample/internal/domain/repository"
+	"api-gateway-sample/internal/domain/service"
+	"api-gateway-sample/pkg/errors"
+	"api-gateway-sample/pkg/logger"
+)
+
+// HeaderCallbackURL asks for the result of an async request to be posted to a URL
+const HeaderCallbackURL = "X-Callback-URL"
+
+// AsyncStatusPath is the prefix of the status URLs of async requests
+const AsyncStatusPath = "/async/"
+
+// Async job states
+const (
+	asyncPending   = "pending"
+	asyncCompleted = "completed"
+	asyncFailed    = "failed"
+)
+
+// asyncJob is the stored state of an async request
+type asyncJob struct {
+	ID          string    `json:"id"`
+	Status      string    `json:"status"`
+	CreatedAt   time.Time `json:"createdAt"`
+	CompletedAt time.Time `json:"completedAt"`
+	// Response is the response of the service, or the gateway's error response when the job failed
+	Response *entity.Response `json:"response,omitempty"`
+}
+
+// asyncTask is a queued async request
+type asyncTask struct {
+	job         *asyncJob
+	request     *entity.Request
+	service     *entity.Service
+	callbackURL string
+	// principal and log are carried over from the request, whose context ends when it is answered
+	principal *entity.Principal
+	log       logger.Logger
+}
+
+// asyncQueue processes the requests of async endpoints on a pool of workers and keeps their
+// results in the cache
+type asyncQueue struct {
+	cache repository.CacheRepository
+	pool  *WorkerPool
+	// resultTTL is how long results can be fetched
+	resultTTL time.Duration
+	// timeout bounds the processing of a request by the service
+	timeout time.Duration
+
+	// callbacks post results to callback URLs on the allowed hosts, nil when disabled
+	callbacks      service.WebhookDeliverer
+	callbackSecret string
+	callbackHosts  map[string]bool
+}
+
+// StartAsyncWorkers processes the requests of async endpoints on a pool of workers until the
+// context is cancelled. At most queueSize requests wait for a worker, and results are kept in
+// the cache for resultTTL. Without workers, async endpoints are served synchronously.
+func (uc *ProxyUseCase) StartAsyncWorkers(ctx context.Context, cache repository.CacheRepository, workers int, queueSize int, resultTTL time.Duration, timeout time.Duration) {
+	uc.async = &asyncQueue{
+		cache:     cache,
+		pool:      NewWorkerPool("async", workers, queueSize, entity.DropPolicyReject, uc.logger),
+		resultTTL: resultTTL,
+		timeout:   timeout,
+	}
+	uc.async.pool.Start(ctx)
+}
+
+// AsyncWorkerPool returns the pool processing the requests of async endpoints, nil when async
+// workers were not started
+func (uc *ProxyUseCase) AsyncWorkerPool() *WorkerPool {
+	if uc.async == nil {
+		return nil
+	}
+	return uc.async.pool
+}
+
+// SetAsyncCallbacks posts the results of async requests to the URL in their X-Callback-URL
+// header, signed with secret like webhook deliveries. Only URLs on the given hosts are accepted.
+func (uc *ProxyUseCase) SetAsyncCallbacks(deliverer service.WebhookDeliverer, secret string, hosts []string) {
+	if uc.async == nil {
+		return
+	}
+	uc.async.callbacks = deliverer
+	uc.async.callbackSecret = secret
+	uc.async.callbackHosts = make(map[string]bool, len(hosts))
+	for _, host := range hosts {
+		uc.async.callbackHosts[host] = true
+	}
+}
+
+// enqueueAsync queues a request for the workers and answers 202 with its status URL
+func (uc *ProxyUseCase) enqueueAsync(ctx context.Context, request *entity.Request, service *entity.Service) (*entity.Response, error) {
+	queue := uc.async
+	callbackURL, err := queue.callbackURL(request)
+	if err != nil {
+		return nil, err
+	}
+
+	id := newAsyncJobID()
+	job := &asyncJob{ID: id, Status: asyncPending, CreatedAt: time.Now()}
+	if err := queue.cache.Set(ctx, asyncCacheKey(id), job, queue.resultTTL); err != nil {
+		return nil, fmt.Errorf("failed to store async job: %w", err)
+	}
+
+	task := &asyncTask{
+		job:         job,
+		request:     request,
+		service:     service,
+		callbackURL: callbackURL,
+		log:         logger.FromContextOr(ctx, uc.logger),
+	}
+	task.principal, _ = entity.PrincipalFromContext(ctx)
+
+	if !queue.pool.Submit(ctx, func(ctx context.Context) { uc.processAsync(ctx, task) }) {
+		queue.cache.Delete(ctx, asyncCacheKey(id))
+		return nil, errors.NewError(errors.CodeServiceUnavailable, "async queue is full", errors.ErrServiceUnavailable)
+	}
+
+	// The job belongs to the worker once queued
+	statusURL := AsyncStatusPath + id
+	body, err := json.Marshal(map[string]string{"id": id, "status": asyncPending, "statusUrl": statusURL})
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode response: %w", err)
+	}
+	return entity.NewResponse(request.ID, http.StatusAccepted, map[string][]string{
+		"Content-Type": {"application/json"},
+		"Location":     {statusURL},
+	}, body), nil
+}
+
+// AsyncResult returns the response of a completed async request, or a 202 status document
+// while it is pending
+func (uc *ProxyUseCase) AsyncResult(ctx context.Context, id string) (*entity.Response, error) {
+	if uc.async == nil {
+		return nil, errors.NewError(errors.CodeNotFound, "async request not found", errors.ErrNotFound)
+	}
+
+	var job asyncJob
+	if err := uc.async.cache.Get(ctx, asyncCacheKey(id), &job); err != nil {
+		return nil, errors.NewError(errors.CodeNotFound, "async request not found", errors.ErrNotFound)
+	}
+
+	if job.Status == asyncPending || job.Response == nil {
+		body, err := json.Marshal(map[string]string{"id": job.ID, "status": job.Status})
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode response: %w", err)
+		}
+		return entity.NewResponse(id, http.StatusAccepted, map[string][]string{
+			"Content-Type": {"application/json"},
+			"Retry-After":  {"1"},
+		}, body), nil
+	}
+	return job.Response, nil
+}
+
+// processAsync forwards a queued request, stores the result and posts it to the callback URL
+func (uc *ProxyUseCase) processAsync(ctx context.Context, task *asyncTask) {
+	queue := uc.async
+	job := task.job
+
+	requestCtx := logger.NewContext(ctx, task.log)
+	if task.principal != nil {
+		requestCtx = entity.ContextWithPrincipal(requestCtx, task.principal)
+	}
+	requestCtx, cancel := context.WithTimeout(requestCtx, queue.timeout)
+	response, err := uc.forwardRequest(requestCtx, task.request, task.service, &entity.RequestSample{})
+	cancel()
+
+	job.Status = asyncCompleted
+	job.CompletedAt = time.Now()
+	job.Response = response
+	if err != nil {
+		task.log.Warn("Async request failed", "job_id", job.ID, "error", err)
+		job.Status = asyncFailed
+		job.Response = asyncErrorResponse(task.request.ID, err)
+	}
+	if err := queue.cache.Set(ctx, asyncCacheKey(job.ID), job, queue.resultTTL); err != nil {
+		task.log.Error("Failed to store async result", "job_id", job.ID, "error", err)
+	}
+
+	if task.callbackURL != "" {
+		uc.deliverAsyncCallback(ctx, task)
+	}
+}
+
+// deliverAsyncCallback posts the result of an async request to its callback URL
+func (uc *ProxyUseCase) deliverAsyncCallback(ctx context.Context, task *asyncTask) {
+	queue := uc.async
+	job := task.job
+
+	event := entity.NewEvent(entity.EventAsyncCompleted)
+	event.Data = map[string]interface{}{
+		"id":         job.ID,
+		"status":     job.Status,
+		"statusCode": job.Response.StatusCode,
+		"headers":    job.Response.Headers,
+		"body":       string(job.Response.Body),
+	}
+	callback := &entity.WebhookSubscription{ID: job.ID, URL: task.callbackURL, Secret: queue.callbackSecret, Active: true}
+	if err := queue.callbacks.Deliver(ctx, callback, event); err != nil {
+		task.log.Warn("Failed to deliver async callback", "job_id", job.ID, "error", err)
+	}
+}
+
+// callbackURL returns the validated callback URL of a request, empty when it has none
+func (q *asyncQueue) callbackURL(request *entity.Request) (string, error) {
+	raw := http.Header(request.Headers).Get(HeaderCallbackURL)
+	if raw == "" {
+		return "", nil
+	}
+	if q.callbacks == nil {
+		return "", errors.NewError(errors.CodeInvalidInput, "async callbacks are not enabled", errors.ErrInvalidInput)
+	}
+
+	u, err := url.Parse(raw)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return "", errors.NewError(errors.CodeInvalidInput, "X-Callback-URL must be an absolute http or https URL", errors.ErrInvalidInput)
+	}
+	if !q.callbackHosts[u.Hostname()] {
+		return "", errors.NewError(errors.CodeInvalidInput, fmt.Sprintf("callbacks to %s are not allowed", u.Hostname()), errors.ErrInvalidInput)
+	}
+	return raw, nil
+}
+
+// asyncErrorResponse is the stored response of an async request the gateway could not complete
+func asyncErrorResponse(requestID string, err error) *entity.Response {
+	body, _ := json.Marshal(map[string]string{"error": err.Error()})
+	return entity.NewResponse(requestID, errors.StatusCodeOf(err, http.StatusBadGateway), map[string][]string{"Content-Type": {"application/json"}}, body)
+}
+
+// asyncCacheKey returns the cache key of an async job
+func asyncCacheKey(id string) string {
+	return "async:" + id
+}
+
+// newAsyncJobID returns an unguessable job ID, since the status URL is all a caller needs to read the result
+func newAsyncJobID() string {
+	id := make([]byte, 16)
+	rand.Read(id)
+	return hex.EncodeToString(id)
+}
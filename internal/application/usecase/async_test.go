@@ -0,0 +1,111 @@
+package usecase
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"api-gateway-sample/internal/domain/entity"
+	"api-gateway-sample/pkg/errors"
+)
+
+// callbackRecorder records async callbacks instead of posting them
+type callbackRecorder struct {
+	delivered chan *entity.Event
+	urls      chan string
+}
+
+func (r *callbackRecorder) Deliver(ctx context.Context, webhook *entity.WebhookSubscription, event *entity.Event) error {
+	r.urls <- webhook.URL
+	r.delivered <- event
+	return nil
+}
+
+// newAsyncFixture creates a proxy use case with an async report endpoint and running workers
+func newAsyncFixture(t *testing.T, workers int) (*ProxyUseCase, *callbackRecorder) {
+	t.Helper()
+	reports := newTestService("reports", entity.Endpoint{Path: "/api/v1/reports", Methods: []string{http.MethodPost}, Async: true})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+	useCase := newProxyFixture(t, &countingGateway{statuses: []int{http.StatusCreated}}, reports)
+	useCase.StartAsyncWorkers(ctx, &jsonCache{entries: map[string][]byte{}}, workers, 1, time.Hour, time.Minute)
+
+	callbacks := &callbackRecorder{delivered: make(chan *entity.Event, 1), urls: make(chan string, 1)}
+	useCase.SetAsyncCallbacks(callbacks, "secret", []string{"hooks.example.com"})
+	return useCase, callbacks
+}
+
+func newReportRequest(callbackURL string) *entity.Request {
+	headers := http.Header{}
+	if callbackURL != "" {
+		headers.Set(HeaderCallbackURL, callbackURL)
+	}
+	return entity.NewRequest(http.MethodPost, "/api/v1/reports", headers, nil, []byte(`{"year":2024}`), "127.0.0.1")
+}
+
+func TestProxyUseCase_AsyncRequest(t *testing.T) {
+	ctx := context.Background()
+	useCase, callbacks := newAsyncFixture(t, 1)
+
+	// 1. The request is accepted with a status URL
+	response, err := useCase.ProxyRequest(ctx, newReportRequest("https://hooks.example.com/reports"))
+	if err != nil {
+		t.Fatalf("Failed to enqueue request: %v", err)
+	}
+	if response.StatusCode != http.StatusAccepted {
+		t.Fatalf("Expected 202, got %d", response.StatusCode)
+	}
+	var accepted map[string]string
+	if err := json.Unmarshal(response.Body, &accepted); err != nil || accepted["statusUrl"] != AsyncStatusPath+accepted["id"] {
+		t.Fatalf("Unexpected response body %s", response.Body)
+	}
+
+	// 2. The result is posted to the callback URL once the service has answered
+	select {
+	case event := <-callbacks.delivered:
+		if url := <-callbacks.urls; url != "https://hooks.example.com/reports" {
+			t.Errorf("Unexpected callback URL %s", url)
+		}
+		if event.Type != entity.EventAsyncCompleted || event.Data["id"] != accepted["id"] || event.Data["statusCode"] != http.StatusCreated {
+			t.Errorf("Unexpected callback %+v", event)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("Expected the result to be posted to the callback URL")
+	}
+
+	// 3. The status URL returns the response of the service
+	result, err := useCase.AsyncResult(ctx, accepted["id"])
+	if err != nil {
+		t.Fatalf("Failed to get result: %v", err)
+	}
+	if result.StatusCode != http.StatusCreated || string(result.Body) != `{"id":"order-1"}` {
+		t.Errorf("Unexpected result %d %s", result.StatusCode, result.Body)
+	}
+
+	// 4. Unknown IDs are not found
+	if _, err := useCase.AsyncResult(ctx, "unknown"); errors.StatusCodeOf(err, 0) != errors.CodeNotFound {
+		t.Errorf("Expected not found, got %v", err)
+	}
+}
+
+func TestProxyUseCase_AsyncRejections(t *testing.T) {
+	ctx := context.Background()
+
+	// 1. Callbacks are only posted to the allowed hosts
+	useCase, _ := newAsyncFixture(t, 1)
+	if _, err := useCase.ProxyRequest(ctx, newReportRequest("http://169.254.169.254/latest")); errors.StatusCodeOf(err, 0) != errors.CodeInvalidInput {
+		t.Errorf("Expected the callback host to be rejected, got %v", err)
+	}
+
+	// 2. Requests are refused while the queue is full
+	useCase, _ = newAsyncFixture(t, 0)
+	if _, err := useCase.ProxyRequest(ctx, newReportRequest("")); err != nil {
+		t.Fatalf("Failed to enqueue request: %v", err)
+	}
+	if _, err := useCase.ProxyRequest(ctx, newReportRequest("")); errors.StatusCodeOf(err, 0) != errors.CodeServiceUnavailable {
+		t.Errorf("Expected the full queue to refuse the request, got %v", err)
+	}
+}
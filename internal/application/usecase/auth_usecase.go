@@ -3,14 +3,17 @@ package usecase
 import (
 	"context"
 
+	"api-gateway-sample/internal/domain/entity"
 	"api-gateway-sample/internal/domain/service"
+	"api-gateway-sample/pkg/errors"
 	"api-gateway-sample/pkg/logger"
 )
 
 // AuthUseCase implements the use case for authentication
 type AuthUseCase struct {
-	authService service.AuthService
-	logger      logger.Logger
+	authService         service.AuthService
+	credentialValidator service.CredentialValidator
+	logger              logger.Logger
 }
 
 // NewAuthUseCase creates a new AuthUseCase instance
@@ -30,3 +33,26 @@ func (uc *AuthUseCase) GenerateToken(ctx context.Context, userID string, claims
 func (uc *AuthUseCase) ValidateToken(ctx context.Context, token string) (map[string]interface{}, error) {
 	return uc.authService.ValidateToken(ctx, token)
 }
+
+// PublicKeys returns the public verification keys as a JWKS document
+func (uc *AuthUseCase) PublicKeys(ctx context.Context) (*entity.JSONWebKeySet, error) {
+	return uc.authService.PublicKeys(ctx)
+}
+
+// SetCredentialValidator enables username/password authentication
+func (uc *AuthUseCase) SetCredentialValidator(validator service.CredentialValidator) {
+	uc.credentialValidator = validator
+}
+
+// SupportsCredentials reports whether username/password authentication is enabled
+func (uc *AuthUseCase) SupportsCredentials() bool {
+	return uc.credentialValidator != nil
+}
+
+// ValidateCredentials validates a username and password and returns the caller's claims
+func (uc *AuthUseCase) ValidateCredentials(ctx context.Context, username string, password string) (map[string]interface{}, error) {
+	if uc.credentialValidator == nil {
+		return nil, errors.ErrUnauthorized
+	}
+	return uc.credentialValidator.ValidateCredentials(ctx, username, password)
+}
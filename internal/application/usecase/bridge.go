@@ -0,0 +1,120 @@
+package usecase
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"regexp"
+	"strings"
+	"time"
+
+	"api-gateway-sample/internal/domain/entity"
+	"api-gateway-sample/internal/domain/service"
+	"api-gateway-sample/pkg/errors"
+	"api-gateway-sample/pkg/logger"
+)
+
+// bridgeTopicValue matches the values callers may place in a topic, so they cannot reach other
+// topics through separators or wildcards such as "." and "*"
+var bridgeTopicValue = regexp.MustCompile(`^[A-Za-z0-9_-]+$`)
+
+// bridgeAcceptance is the body of the 202 response of a bridge endpoint
+type bridgeAcceptance struct {
+	Status    string `json:"status"`
+	RequestID string `json:"requestId"`
+	Topic     string `json:"topic"`
+}
+
+// SetPublisher publishes the requests of bridge endpoints for a broker such as "kafka" with the given publisher
+func (uc *ProxyUseCase) SetPublisher(broker string, publisher service.MessagePublisher) {
+	if uc.publishers == nil {
+		uc.publishers = make(map[string]service.MessagePublisher)
+	}
+	uc.publishers[broker] = publisher
+}
+
+// publishRequest publishes the body of a request to the broker of a bridge endpoint and
+// answers 202 Accepted with the request ID, which consumers see as the message ID
+func (uc *ProxyUseCase) publishRequest(ctx context.Context, request *entity.Request, endpoint *entity.Endpoint, sample *entity.RequestSample) (*entity.Response, error) {
+	bridge := endpoint.Bridge
+	publisher, ok := uc.publishers[bridge.Broker]
+	if !ok {
+		return nil, errors.NewError(errors.CodeServiceUnavailable, fmt.Sprintf("broker %s is not configured", bridge.Broker), errors.ErrServiceUnavailable)
+	}
+
+	resolve := func(reference string) (string, error) {
+		return resolveBridgeReference(reference, request)
+	}
+	topic, err := entity.ExpandPipelineTemplate(bridge.Topic, func(reference string) (string, error) {
+		value, err := resolve(reference)
+		if err == nil && !bridgeTopicValue.MatchString(value) {
+			err = fmt.Errorf("%s may only contain letters, digits, - and _", reference)
+		}
+		return value, err
+	})
+	if err == nil && topic == "" {
+		err = fmt.Errorf("topic is empty")
+	}
+	if err != nil {
+		return nil, errors.NewError(errors.CodeInvalidInput, fmt.Sprintf("cannot determine the topic: %s", err), errors.ErrInvalidInput)
+	}
+	key, err := entity.ExpandPipelineTemplate(bridge.Key, resolve)
+	if err != nil {
+		return nil, errors.NewError(errors.CodeInvalidInput, fmt.Sprintf("cannot determine the message key: %s", err), errors.ErrInvalidInput)
+	}
+
+	headers := map[string]string{"X-Request-ID": request.ID}
+	if contentType := http.Header(request.Headers).Get("Content-Type"); contentType != "" {
+		headers["Content-Type"] = contentType
+	}
+	message := &entity.Message{
+		ID:       request.ID,
+		Topic:    topic,
+		Key:      key,
+		Body:     request.Body,
+		Headers:  headers,
+		Delivery: bridge.DeliveryGuarantee(),
+	}
+
+	sample.Target = bridge.Broker
+	upstreamStart := time.Now()
+	err = publisher.Publish(ctx, message)
+	sample.UpstreamLatency = time.Since(upstreamStart)
+	if err != nil {
+		sample.UpstreamFailed = true
+		logger.FromContextOr(ctx, uc.logger).Error("Failed to publish message", "broker", bridge.Broker, "topic", topic, "error", err)
+		if ctx.Err() == context.DeadlineExceeded {
+			return nil, errors.NewError(errors.CodeTimeout, fmt.Sprintf("publishing to %s timed out", bridge.Broker), errors.ErrTimeout)
+		}
+		return nil, errors.NewError(errors.CodeBadGateway, fmt.Sprintf("failed to publish to %s", bridge.Broker), err)
+	}
+
+	body, err := json.Marshal(bridgeAcceptance{Status: "accepted", RequestID: request.ID, Topic: topic})
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode response: %w", err)
+	}
+	response := entity.NewResponse(request.ID, http.StatusAccepted, map[string][]string{"Content-Type": {"application/json"}}, body)
+	response.SetLatency(upstreamStart)
+	return response, nil
+}
+
+// resolveBridgeReference returns the value of a placeholder such as "header.X-Tenant-ID" or "query.type"
+func resolveBridgeReference(reference string, request *entity.Request) (string, error) {
+	scope, name, _ := strings.Cut(reference, ".")
+	var value string
+	switch scope {
+	case entity.BridgeHeaderScope:
+		value = http.Header(request.Headers).Get(name)
+	case entity.BridgeQueryScope:
+		if values := request.QueryParams[name]; len(values) > 0 {
+			value = values[0]
+		}
+	default:
+		return "", fmt.Errorf("invalid placeholder %s", reference)
+	}
+	if value == "" {
+		return "", fmt.Errorf("%s %s is missing", scope, name)
+	}
+	return value, nil
+}
@@ -0,0 +1,115 @@
+package usecase
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"api-gateway-sample/internal/domain/entity"
+	"api-gateway-sample/pkg/errors"
+)
+
+// recordingPublisher records the messages it publishes and fails when err is set
+type recordingPublisher struct {
+	messages []*entity.Message
+	err      error
+}
+
+func (p *recordingPublisher) Publish(ctx context.Context, message *entity.Message) error {
+	if p.err != nil {
+		return p.err
+	}
+	p.messages = append(p.messages, message)
+	return nil
+}
+
+func (p *recordingPublisher) Close() error {
+	return nil
+}
+
+// newBridgeFixture creates a proxy use case with an events endpoint publishing to Kafka
+func newBridgeFixture(t *testing.T, publisher *recordingPublisher) (*ProxyUseCase, *countingGateway) {
+	t.Helper()
+
+	ingest := newTestService("ingest", entity.Endpoint{
+		Path:    "/api/v1/events",
+		Methods: []string{http.MethodPost},
+		Bridge:  &entity.Bridge{Broker: entity.BrokerKafka, Topic: "events.{{header.X-Tenant-ID}}", Key: "{{query.device}}"},
+	})
+
+	gateway := &countingGateway{}
+	useCase := newProxyFixture(t, gateway, ingest)
+	if publisher != nil {
+		useCase.SetPublisher(entity.BrokerKafka, publisher)
+	}
+	return useCase, gateway
+}
+
+func newEventRequest(tenant string) *entity.Request {
+	headers := map[string][]string{"X-Tenant-Id": {tenant}, "Content-Type": {"application/json"}}
+	query := map[string][]string{"device": {"sensor-7"}}
+	return entity.NewRequest(http.MethodPost, "/api/v1/events", headers, query, []byte(`{"temperature":21.5}`), "127.0.0.1")
+}
+
+func TestProxyUseCase_BridgePublishesBody(t *testing.T) {
+	publisher := &recordingPublisher{}
+	useCase, gateway := newBridgeFixture(t, publisher)
+	request := newEventRequest("acme")
+
+	response, err := useCase.ProxyRequest(context.Background(), request)
+	if err != nil {
+		t.Fatalf("Failed to publish request: %v", err)
+	}
+
+	// 1. The body is published to the expanded topic with the expanded key
+	if len(publisher.messages) != 1 {
+		t.Fatalf("Expected one message, got %d", len(publisher.messages))
+	}
+	message := publisher.messages[0]
+	if message.Topic != "events.acme" || message.Key != "sensor-7" || string(message.Body) != `{"temperature":21.5}` {
+		t.Errorf("Unexpected message %+v", message)
+	}
+	if message.Delivery != entity.DeliveryAtLeastOnce || message.Headers["Content-Type"] != "application/json" {
+		t.Errorf("Expected an at-least-once JSON message, got %+v", message)
+	}
+
+	// 2. The caller gets 202 with the request ID
+	if response.StatusCode != http.StatusAccepted {
+		t.Fatalf("Expected 202, got %d", response.StatusCode)
+	}
+	var acceptance bridgeAcceptance
+	if err := json.Unmarshal(response.Body, &acceptance); err != nil || acceptance.RequestID != request.ID || acceptance.Topic != "events.acme" {
+		t.Errorf("Unexpected response body %s", response.Body)
+	}
+	if gateway.calls != 0 {
+		t.Errorf("Expected the service not to be called")
+	}
+}
+
+func TestProxyUseCase_BridgeFailures(t *testing.T) {
+	ctx := context.Background()
+
+	// 1. Values that could reach other topics are rejected
+	publisher := &recordingPublisher{}
+	useCase, _ := newBridgeFixture(t, publisher)
+	_, err := useCase.ProxyRequest(ctx, newEventRequest("acme.*"))
+	if errors.StatusCodeOf(err, 0) != errors.CodeInvalidInput || len(publisher.messages) != 0 {
+		t.Errorf("Expected the wildcard tenant to be rejected, got %v", err)
+	}
+
+	// 2. A broker error is a bad gateway
+	useCase, _ = newBridgeFixture(t, &recordingPublisher{err: fmt.Errorf("leader not available")})
+	_, err = useCase.ProxyRequest(ctx, newEventRequest("acme"))
+	if errors.StatusCodeOf(err, 0) != errors.CodeBadGateway {
+		t.Errorf("Expected a bad gateway error, got %v", err)
+	}
+
+	// 3. A broker without a publisher is unavailable
+	useCase, _ = newBridgeFixture(t, nil)
+	_, err = useCase.ProxyRequest(ctx, newEventRequest("acme"))
+	if errors.StatusCodeOf(err, 0) != errors.CodeServiceUnavailable {
+		t.Errorf("Expected a service unavailable error, got %v", err)
+	}
+}
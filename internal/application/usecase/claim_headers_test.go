@@ -0,0 +1,41 @@
+package usecase
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"api-gateway-sample/internal/domain/entity"
+)
+
+func TestProxyUseCase_ClaimHeaders(t *testing.T) {
+	orders := newTestService("orders", entity.Endpoint{Path: "/api/v1/orders", Methods: []string{http.MethodGet}})
+	gateway := &capturingGateway{countingGateway: countingGateway{statuses: []int{http.StatusOK, http.StatusOK}}}
+	useCase := newProxyFixture(t, gateway, orders)
+	useCase.SetClaimHeaders(map[string]string{"x-user-id": "sub", "x-tenant-id": "tenant"})
+
+	get := func(ctx context.Context) map[string][]string {
+		t.Helper()
+		headers := map[string][]string{"X-User-Id": {"admin"}, "X-Tenant-Id": {"other"}}
+		if _, err := useCase.ProxyRequest(ctx, entity.NewRequest(http.MethodGet, "/api/v1/orders", headers, nil, nil, "10.0.0.1")); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		return gateway.requests[len(gateway.requests)-1].Headers
+	}
+
+	// 1. Authenticated callers' claims replace the values they sent
+	principal := entity.NewPrincipal(map[string]interface{}{"sub": "alice", "tenant": "acme"})
+	headers := get(entity.ContextWithPrincipal(context.Background(), principal))
+	if got := headers["X-User-Id"]; len(got) != 1 || got[0] != "alice" {
+		t.Errorf("Expected X-User-Id alice, got %v", got)
+	}
+	if got := headers["X-Tenant-Id"]; len(got) != 1 || got[0] != "acme" {
+		t.Errorf("Expected X-Tenant-Id acme, got %v", got)
+	}
+
+	// 2. Anonymous callers cannot impersonate users
+	headers = get(context.Background())
+	if _, ok := headers["X-User-Id"]; ok {
+		t.Errorf("Expected the client X-User-Id to be removed, got %v", headers)
+	}
+}
@@ -0,0 +1,130 @@
+package usecase
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"api-gateway-sample/internal/domain/entity"
+	"api-gateway-sample/pkg/errors"
+	"api-gateway-sample/pkg/logger"
+)
+
+// compositeResult is the outcome of a single call of a composite endpoint
+type compositeResult struct {
+	body json.RawMessage
+	err  error
+}
+
+// composeRequest fans a request out to the calls of a composite endpoint in parallel and merges
+// their JSON responses under the call names. The calls share the endpoint's combined timeout.
+// A failed required call fails the request, a failed optional call is merged as null.
+func (uc *ProxyUseCase) composeRequest(ctx context.Context, request *entity.Request, endpoint *entity.Endpoint, sample *entity.RequestSample) (*entity.Response, error) {
+	composite := endpoint.Composite
+	if timeout := composite.TimeoutDuration(); timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+
+	upstreamStart := time.Now()
+	results := make([]compositeResult, len(composite.Calls))
+	var wg sync.WaitGroup
+	for i := range composite.Calls {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			results[i] = uc.callComposite(ctx, request, &composite.Calls[i])
+		}(i)
+	}
+	wg.Wait()
+	sample.UpstreamLatency = time.Since(upstreamStart)
+
+	log := logger.FromContextOr(ctx, uc.logger)
+	merged := make(map[string]json.RawMessage, len(composite.Calls))
+	for i, call := range composite.Calls {
+		result := results[i]
+		if result.err == nil {
+			merged[call.Name] = result.body
+			continue
+		}
+
+		if !call.Optional {
+			sample.UpstreamFailed = true
+			if ctx.Err() == context.DeadlineExceeded {
+				return nil, errors.NewError(errors.CodeTimeout, fmt.Sprintf("composite call %s timed out", call.Name), errors.ErrTimeout)
+			}
+			return nil, errors.NewError(errors.CodeBadGateway, fmt.Sprintf("composite call %s failed", call.Name), result.err)
+		}
+		log.Warn("Optional composite call failed", "call", call.Name, "error", result.err)
+		merged[call.Name] = json.RawMessage("null")
+	}
+
+	body, err := json.Marshal(merged)
+	if err != nil {
+		return nil, fmt.Errorf("failed to merge composite responses: %w", err)
+	}
+
+	response := entity.NewResponse(request.ID, http.StatusOK, map[string][]string{"Content-Type": {"application/json"}}, body)
+	response.SetLatency(upstreamStart)
+	return response, nil
+}
+
+// callComposite forwards a single call of a composite endpoint with the caller's query parameters
+func (uc *ProxyUseCase) callComposite(ctx context.Context, request *entity.Request, call *entity.CompositeCall) compositeResult {
+	body, err := uc.callRoute(ctx, request, call.CallMethod(), call.Path, request.QueryParams)
+	return compositeResult{body: body, err: err}
+}
+
+// callRoute forwards a request of a composite or pipeline endpoint to another route and returns
+// its JSON response. The call carries the caller's headers; the authentication and rate limits
+// of the calling endpoint apply instead of those of the called route.
+func (uc *ProxyUseCase) callRoute(ctx context.Context, request *entity.Request, method string, path string, query map[string][]string) (json.RawMessage, error) {
+	service, endpoint, err := uc.ResolveEndpoint(ctx, path, method)
+	if err != nil {
+		return nil, err
+	}
+	if endpoint.Aggregates() {
+		return nil, fmt.Errorf("route %s calls other routes itself", path)
+	}
+
+	// Each call gets its own headers, which are modified on the way to the backend
+	headers := make(map[string][]string, len(request.Headers))
+	for name, values := range request.Headers {
+		headers[name] = values
+	}
+	callRequest := &entity.Request{
+		ID:            request.ID,
+		Method:        method,
+		Path:          path,
+		Headers:       headers,
+		QueryParams:   query,
+		ClientIP:      request.ClientIP,
+		Timestamp:     request.Timestamp,
+		Authenticated: request.Authenticated,
+		UserID:        request.UserID,
+		Host:          request.Host,
+		Scheme:        request.Scheme,
+	}
+	if method != http.MethodGet {
+		callRequest.Body = request.Body
+	}
+
+	response, err := uc.forwardRequest(ctx, callRequest, service, &entity.RequestSample{})
+	if err != nil {
+		return nil, err
+	}
+	if response.StatusCode >= http.StatusBadRequest {
+		return nil, fmt.Errorf("route %s returned status %d", path, response.StatusCode)
+	}
+	if len(response.Body) == 0 {
+		return json.RawMessage("null"), nil
+	}
+	if !json.Valid(response.Body) {
+		return nil, fmt.Errorf("route %s did not return JSON", path)
+	}
+	return response.Body, nil
+}
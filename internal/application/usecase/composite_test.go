@@ -0,0 +1,119 @@
+package usecase
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"api-gateway-sample/internal/domain/entity"
+	"api-gateway-sample/pkg/errors"
+)
+
+// routeResponse is the canned answer of routeGateway for a path
+type routeResponse struct {
+	status int
+	body   string
+	delay  time.Duration
+}
+
+// routeGateway answers routed requests by path
+type routeGateway struct {
+	countingGateway
+	routes map[string]routeResponse
+}
+
+func (g *routeGateway) RouteRequest(ctx context.Context, request *entity.Request) (*entity.Response, error) {
+	route := g.routes[request.Path]
+	select {
+	case <-time.After(route.delay):
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+	return entity.NewResponse(request.ID, route.status, map[string][]string{}, []byte(route.body)), nil
+}
+
+// newCompositeFixture creates a proxy use case with a dashboard endpoint composing a user and an orders route
+func newCompositeFixture(t *testing.T, routes map[string]routeResponse, ordersOptional bool) *ProxyUseCase {
+	t.Helper()
+	users := newTestService("users", entity.Endpoint{Path: "/api/v1/users/me", Methods: []string{http.MethodGet}})
+	orders := newTestService("orders", entity.Endpoint{Path: "/api/v1/orders", Methods: []string{http.MethodGet}})
+	bff := newTestService("bff", entity.Endpoint{
+		Path:    "/api/v1/dashboard",
+		Methods: []string{http.MethodGet},
+		Composite: &entity.Composite{
+			Timeout: 1,
+			Calls: []entity.CompositeCall{
+				{Name: "user", Path: "/api/v1/users/me"},
+				{Name: "orders", Path: "/api/v1/orders", Optional: ordersOptional},
+			},
+		},
+	})
+
+	return newProxyFixture(t, &routeGateway{routes: routes}, users, orders, bff)
+}
+
+func newDashboardRequest() *entity.Request {
+	return entity.NewRequest(http.MethodGet, "/api/v1/dashboard", map[string][]string{}, map[string][]string{}, nil, "127.0.0.1")
+}
+
+func TestProxyUseCase_CompositeMergesResponses(t *testing.T) {
+	useCase := newCompositeFixture(t, map[string]routeResponse{
+		"/api/v1/users/me": {status: http.StatusOK, body: `{"name":"Ada"}`, delay: 20 * time.Millisecond},
+		"/api/v1/orders":   {status: http.StatusOK, body: `[{"id":"order-1"}]`, delay: 20 * time.Millisecond},
+	}, false)
+
+	response, err := useCase.ProxyRequest(context.Background(), newDashboardRequest())
+	if err != nil {
+		t.Fatalf("Failed to compose request: %v", err)
+	}
+	if response.StatusCode != http.StatusOK || response.ContentType != "application/json" {
+		t.Fatalf("Unexpected response %+v", response)
+	}
+
+	var merged map[string]json.RawMessage
+	if err := json.Unmarshal(response.Body, &merged); err != nil {
+		t.Fatalf("Expected a JSON object, got %s", response.Body)
+	}
+	if string(merged["user"]) != `{"name":"Ada"}` || string(merged["orders"]) != `[{"id":"order-1"}]` {
+		t.Errorf("Expected the responses under their call names, got %s", response.Body)
+	}
+}
+
+func TestProxyUseCase_CompositeFailures(t *testing.T) {
+	ctx := context.Background()
+	failingOrders := map[string]routeResponse{
+		"/api/v1/users/me": {status: http.StatusOK, body: `{"name":"Ada"}`},
+		"/api/v1/orders":   {status: http.StatusInternalServerError, body: `{"error":"boom"}`},
+	}
+
+	// 1. A failed required call fails the request
+	_, err := newCompositeFixture(t, failingOrders, false).ProxyRequest(ctx, newDashboardRequest())
+	if errors.StatusCodeOf(err, 0) != errors.CodeBadGateway {
+		t.Errorf("Expected a bad gateway error, got %v", err)
+	}
+
+	// 2. A failed optional call is merged as null
+	response, err := newCompositeFixture(t, failingOrders, true).ProxyRequest(ctx, newDashboardRequest())
+	if err != nil {
+		t.Fatalf("Expected the optional failure to be tolerated, got %v", err)
+	}
+	if string(response.Body) != `{"orders":null,"user":{"name":"Ada"}}` {
+		t.Errorf("Unexpected merged body %s", response.Body)
+	}
+
+	// 3. Calls exceeding the combined timeout fail the request
+	slowUser := map[string]routeResponse{
+		"/api/v1/users/me": {status: http.StatusOK, body: `{}`, delay: 5 * time.Second},
+		"/api/v1/orders":   {status: http.StatusOK, body: `[]`},
+	}
+	start := time.Now()
+	_, err = newCompositeFixture(t, slowUser, true).ProxyRequest(ctx, newDashboardRequest())
+	if errors.StatusCodeOf(err, 0) != errors.CodeTimeout {
+		t.Errorf("Expected a timeout error, got %v", err)
+	}
+	if time.Since(start) > 3*time.Second {
+		t.Errorf("Expected the calls to be cancelled at the timeout")
+	}
+}
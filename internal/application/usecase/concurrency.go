@@ -0,0 +1,47 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+
+	"api-gateway-sample/internal/domain/entity"
+	"api-gateway-sample/internal/domain/service"
+	"api-gateway-sample/pkg/errors"
+	"api-gateway-sample/pkg/logger"
+)
+
+// SetConcurrencyLimiter bounds the requests each client may have in flight on endpoints that
+// set MaxConcurrent, so that a single consumer cannot monopolize slow endpoints
+func (uc *ProxyUseCase) SetConcurrencyLimiter(limiter service.ConcurrencyLimiter) {
+	uc.concurrency = limiter
+}
+
+// acquireConcurrency takes one of the client's in-flight slots on the endpoint and returns the
+// function that gives it back. It fails with 429 when every slot is in use.
+func (uc *ProxyUseCase) acquireConcurrency(ctx context.Context, request *entity.Request, service *entity.Service, endpoint *entity.Endpoint) (func(), error) {
+	key := fmt.Sprintf("concurrency:%s:%s:%s", service.ID, endpoint.Path, concurrencyClient(request))
+
+	acquired, err := uc.concurrency.Acquire(ctx, key, endpoint.MaxConcurrent)
+	if err != nil {
+		return nil, fmt.Errorf("concurrency limit check failed: %w", err)
+	}
+	if !acquired {
+		return nil, errors.NewError(errors.CodeRateLimitExceeded, "too many concurrent requests", errors.ErrRateLimitExceeded)
+	}
+
+	return func() {
+		// Release the slot even if the client has gone away
+		if err := uc.concurrency.Release(context.WithoutCancel(ctx), key); err != nil {
+			logger.FromContextOr(ctx, uc.logger).Warn("Failed to release concurrency slot", "error", err)
+		}
+	}, nil
+}
+
+// concurrencyClient identifies the client whose requests are counted: the authenticated user
+// or API key, or the client address for anonymous requests
+func concurrencyClient(request *entity.Request) string {
+	if request.Authenticated && request.UserID != "" {
+		return request.UserID
+	}
+	return request.ClientIP
+}
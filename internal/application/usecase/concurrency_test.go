@@ -0,0 +1,99 @@
+package usecase
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"testing"
+
+	"api-gateway-sample/internal/domain/entity"
+	"api-gateway-sample/pkg/errors"
+)
+
+// countingLimiter counts the slots in use under each key
+type countingLimiter struct {
+	mu       sync.Mutex
+	inFlight map[string]int
+}
+
+func (l *countingLimiter) Acquire(ctx context.Context, key string, limit int) (bool, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.inFlight[key] >= limit {
+		return false, nil
+	}
+	l.inFlight[key]++
+	return true, nil
+}
+
+func (l *countingLimiter) Release(ctx context.Context, key string) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.inFlight[key]--
+	return nil
+}
+
+// blockingGateway holds every routed request until release is closed or its context is done
+type blockingGateway struct {
+	countingGateway
+	started chan struct{}
+	release chan struct{}
+}
+
+func (g *blockingGateway) RouteRequest(ctx context.Context, request *entity.Request) (*entity.Response, error) {
+	g.started <- struct{}{}
+	select {
+	case <-g.release:
+		return &entity.Response{RequestID: request.ID, StatusCode: http.StatusOK}, nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+func TestProxyUseCase_ConcurrencyLimit(t *testing.T) {
+	ctx := context.Background()
+	service := newTestService("reports", entity.Endpoint{Path: "/api/v1/reports", Methods: []string{http.MethodGet}, MaxConcurrent: 1})
+
+	gateway := &blockingGateway{started: make(chan struct{}, 1), release: make(chan struct{})}
+	limiter := &countingLimiter{inFlight: map[string]int{}}
+	useCase := newProxyFixture(t, gateway, service)
+	useCase.SetConcurrencyLimiter(limiter)
+	newRequest := func(clientIP string) *entity.Request {
+		return entity.NewRequest(http.MethodGet, "/api/v1/reports", map[string][]string{}, map[string][]string{}, nil, clientIP)
+	}
+
+	// 1. A second request from the same client is rejected while the first is in flight
+	done := make(chan error)
+	go func() {
+		_, err := useCase.ProxyRequest(ctx, newRequest("10.0.0.1"))
+		done <- err
+	}()
+	<-gateway.started
+
+	if _, err := useCase.ProxyRequest(ctx, newRequest("10.0.0.1")); errors.StatusCodeOf(err, 0) != errors.CodeRateLimitExceeded {
+		t.Errorf("Expected 429 for a second concurrent request, got %v", err)
+	}
+
+	// 2. Other clients are not affected
+	go func() {
+		_, err := useCase.ProxyRequest(ctx, newRequest("10.0.0.2"))
+		done <- err
+	}()
+	<-gateway.started
+
+	// 3. Slots are released when requests complete
+	close(gateway.release)
+	for i := 0; i < 2; i++ {
+		if err := <-done; err != nil {
+			t.Fatalf("Failed to proxy request: %v", err)
+		}
+	}
+	if _, err := useCase.ProxyRequest(ctx, newRequest("10.0.0.1")); err != nil {
+		t.Errorf("Expected the request to be allowed once the slot is released, got %v", err)
+	}
+	for key, count := range limiter.inFlight {
+		if count != 0 {
+			t.Errorf("Expected no slots in use under %s, got %d", key, count)
+		}
+	}
+}
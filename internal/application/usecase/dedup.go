@@ -0,0 +1,143 @@
+package usecase
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"sync"
+
+	"api-gateway-sample/internal/domain/entity"
+	"api-gateway-sample/pkg/logger"
+)
+
+// credentialHeaders are the headers carrying the credentials of callers to the upstream
+var credentialHeaders = []string{"Authorization", "Proxy-Authorization", "Cookie"}
+
+// requestCoalescer collapses identical GET requests in flight into a single upstream call whose
+// response is shared by every request waiting for it, so that retry storms reach the backend once
+type requestCoalescer struct {
+	mu    sync.Mutex
+	calls map[string]*coalescedCall
+}
+
+// coalescedCall is an upstream call shared by identical requests
+type coalescedCall struct {
+	done chan struct{}
+	// waiters is the number of requests waiting for the call besides the one running it
+	waiters  int
+	response *entity.Response
+	err      error
+}
+
+// SetRequestDeduplication collapses identical GET requests in flight into a single upstream call,
+// whether or not the endpoint is cached
+func (uc *ProxyUseCase) SetRequestDeduplication() {
+	uc.dedup = &requestCoalescer{calls: make(map[string]*coalescedCall)}
+}
+
+// deduplicate runs forward once for all identical GET requests in flight and returns its response.
+// The request that starts the call runs it without cancellation, as others may be waiting for it;
+// the others stop waiting when their own context is done.
+func (uc *ProxyUseCase) deduplicate(ctx context.Context, request *entity.Request, service *entity.Service, client conditions, forward func(context.Context) (*entity.Response, error)) (*entity.Response, error) {
+	// Conditional requests may be answered with 304, which only suits the client that sent them
+	if uc.dedup == nil || request.Method != http.MethodGet || client.ifNoneMatch != "" || client.ifModifiedSince != "" {
+		return forward(ctx)
+	}
+
+	key := dedupKey(request, service, uc.callerDigest(ctx, request))
+	c := uc.dedup
+
+	c.mu.Lock()
+	call, shared := c.calls[key]
+	if shared {
+		call.waiters++
+	} else {
+		call = &coalescedCall{done: make(chan struct{})}
+		c.calls[key] = call
+	}
+	c.mu.Unlock()
+
+	if !shared {
+		defer func() {
+			c.mu.Lock()
+			delete(c.calls, key)
+			c.mu.Unlock()
+			close(call.done)
+		}()
+		// Waiters get this error if forward panics
+		call.err = fmt.Errorf("deduplicated request failed")
+		call.response, call.err = forward(context.WithoutCancel(ctx))
+		return call.response, call.err
+	}
+
+	logger.FromContextOr(ctx, uc.logger).Debug("Request collapsed into an identical request in flight", "path", request.Path)
+	select {
+	case <-call.done:
+		if call.err != nil {
+			return nil, call.err
+		}
+		// A streamed body can only be read by one client, so the others request it themselves
+		if call.response.BodyStream != nil {
+			return forward(ctx)
+		}
+		return copyResponse(call.response), nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// dedupKey identifies identical requests: the same route, query, representation, caller and
+// variant, so that responses are never shared between users
+func dedupKey(request *entity.Request, service *entity.Service, caller string) string {
+	return fmt.Sprintf("%s:%s?%s:%s:%s:%s:%s",
+		service.ID,
+		request.Path,
+		url.Values(request.QueryParams).Encode(),
+		http.Header(request.Headers).Get("Accept"),
+		caller,
+		request.Region,
+		request.Variant,
+	)
+}
+
+// callerDigest returns a hash of the principal of a request and of the credentials and identity
+// headers it is forwarded with. Callers cleared by the external authorizer, or calling public
+// endpoints with a token, may have no principal while the upstream still tells them apart.
+func (uc *ProxyUseCase) callerDigest(ctx context.Context, request *entity.Request) string {
+	names := append([]string(nil), credentialHeaders...)
+	names = append(names, uc.extUpstreamHeaders...)
+	for name := range uc.claimHeaders {
+		names = append(names, name)
+	}
+	if uc.identityHeader != "" {
+		names = append(names, uc.identityHeader)
+	}
+	sort.Strings(names)
+
+	hash := sha256.New()
+	if principal, ok := entity.PrincipalFromContext(ctx); ok {
+		fmt.Fprintf(hash, "%q\n", principal.UserID)
+	}
+	headers := http.Header(request.Headers)
+	for _, name := range names {
+		fmt.Fprintf(hash, "%s:%q\n", name, headers.Values(name))
+	}
+	return hex.EncodeToString(hash.Sum(nil)[:16])
+}
+
+// copyResponse returns a copy of a shared response that its receiver may modify
+func copyResponse(response *entity.Response) *entity.Response {
+	if response == nil {
+		return nil
+	}
+	copied := *response
+	copied.Headers = make(map[string][]string, len(response.Headers))
+	for name, values := range response.Headers {
+		copied.Headers[name] = append([]string(nil), values...)
+	}
+	return &copied
+}
@@ -0,0 +1,99 @@
+package usecase
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+
+	"api-gateway-sample/internal/domain/entity"
+)
+
+func TestProxyUseCase_RequestDeduplication(t *testing.T) {
+	ctx := context.Background()
+	service := newTestService("reports", entity.Endpoint{Path: "/api/v1/reports", Methods: []string{http.MethodGet}})
+
+	gateway := &blockingGateway{started: make(chan struct{}, 2), release: make(chan struct{})}
+	useCase := newProxyFixture(t, gateway, service)
+	useCase.SetRequestDeduplication()
+	newRequest := func(query string, token string) *entity.Request {
+		headers := map[string][]string{}
+		if token != "" {
+			headers["Authorization"] = []string{"Bearer " + token}
+		}
+		return entity.NewRequest(http.MethodGet, "/api/v1/reports", headers, map[string][]string{"q": {query}}, nil, "10.0.0.1")
+	}
+
+	var wg sync.WaitGroup
+	responses := make(chan *entity.Response, 7)
+	send := func(query string, token string) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			response, err := useCase.ProxyRequest(ctx, newRequest(query, token))
+			if err != nil {
+				t.Errorf("Unexpected error: %v", err)
+				return
+			}
+			responses <- response
+		}()
+	}
+
+	// 1. Identical requests wait for the first one in flight
+	send("daily", "")
+	<-gateway.started
+	send("daily", "")
+	send("daily", "")
+	waitForWaiters(t, useCase, 2)
+
+	// 2. Requests with another query are forwarded on their own
+	send("weekly", "")
+	<-gateway.started
+
+	// 3. Requests forwarded with the credentials of another caller, without a principal as on
+	// public endpoints, are forwarded on their own too
+	send("daily", "alice-token")
+	<-gateway.started
+	send("daily", "alice-token")
+	waitForWaiters(t, useCase, 3)
+	send("daily", "bob-token")
+	<-gateway.started
+
+	close(gateway.release)
+	wg.Wait()
+	close(responses)
+
+	count := 0
+	for response := range responses {
+		count++
+		if response.StatusCode != http.StatusOK {
+			t.Errorf("Expected 200, got %d", response.StatusCode)
+		}
+	}
+	if count != 7 {
+		t.Errorf("Expected 7 responses, got %d", count)
+	}
+	if len(gateway.started) != 0 {
+		t.Errorf("Expected 4 upstream calls, got %d more", len(gateway.started))
+	}
+}
+
+// waitForWaiters waits until the requests in flight have the given number of waiters
+func waitForWaiters(t *testing.T, useCase *ProxyUseCase, expected int) {
+	t.Helper()
+	deadline := time.Now().Add(time.Second)
+	for time.Now().Before(deadline) {
+		useCase.dedup.mu.Lock()
+		waiters := 0
+		for _, call := range useCase.dedup.calls {
+			waiters += call.waiters
+		}
+		useCase.dedup.mu.Unlock()
+		if waiters == expected {
+			return
+		}
+		time.Sleep(time.Millisecond)
+	}
+	t.Fatalf("Timed out waiting for %d collapsed requests", expected)
+}
@@ -0,0 +1,115 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"api-gateway-sample/internal/application/dto"
+	"api-gateway-sample/internal/domain/entity"
+	"api-gateway-sample/internal/domain/repository"
+	"api-gateway-sample/pkg/errors"
+)
+
+// SyncServices makes the services match a desired state, for GitOps tools that declare every
+// service at once. Services are matched by name: those missing are created, those that differ
+// updated and those not listed deleted, all in a single transaction. The report lists the drift
+// found and the routes the services would serve more than once; with dryRun it is only reported.
+// Settings the definitions do not carry, such as metadata, are kept on update.
+func (uc *ServiceUseCase) SyncServices(ctx context.Context, req *dto.DesiredStateRequest, dryRun bool) (*dto.DriftReport, error) {
+	desiredNames := make(map[string]bool, len(req.Services))
+	for _, desired := range req.Services {
+		if desiredNames[desired.Name] {
+			return nil, errors.NewError(errors.CodeInvalidInput, fmt.Sprintf("service %s is declared twice", desired.Name), errors.ErrInvalidInput)
+		}
+		desiredNames[desired.Name] = true
+	}
+
+	services, err := uc.serviceRepo.GetAll(ctx)
+	if err != nil {
+		return nil, err
+	}
+	current := make(map[string]*entity.Service, len(services))
+	for _, service := range services {
+		current[service.Name] = service
+	}
+
+	report := &dto.DriftReport{
+		Created:   []string{},
+		Updated:   []dto.ServiceDrift{},
+		Deleted:   []string{},
+		Unchanged: []string{},
+	}
+	var changes repository.ServiceChangeSet
+	definitions := make([]*entity.Service, len(req.Services))
+	for i := range req.Services {
+		desired := &req.Services[i]
+		definitions[i] = desired.ToEntity()
+		existing, ok := current[desired.Name]
+		if !ok {
+			service := definitions[i]
+			service.ID = entity.NewRequestID()
+			changes.Create = append(changes.Create, service)
+			report.Created = append(report.Created, desired.Name)
+			continue
+		}
+
+		fields := serviceDrift(existing, desired)
+		if len(fields) == 0 {
+			report.Unchanged = append(report.Unchanged, desired.Name)
+			continue
+		}
+		// The stored revision makes the update fail if the service changes meanwhile
+		service := existing.Clone()
+		applyDefinition(service, definitions[i])
+		changes.Update = append(changes.Update, service)
+		report.Updated = append(report.Updated, dto.ServiceDrift{Name: desired.Name, Fields: fields})
+	}
+	var deleted []*entity.Service
+	for _, service := range services {
+		if !desiredNames[service.Name] {
+			deleted = append(deleted, service)
+			changes.Delete = append(changes.Delete, service.ID)
+			report.Deleted = append(report.Deleted, service.Name)
+		}
+	}
+	sort.Strings(report.Created)
+	sort.Slice(report.Updated, func(i, j int) bool { return report.Updated[i].Name < report.Updated[j].Name })
+	sort.Strings(report.Deleted)
+	sort.Strings(report.Unchanged)
+	report.Conflicts = routeConflicts(definitions)
+
+	if dryRun || len(changes.Create)+len(changes.Update)+len(changes.Delete) == 0 {
+		return report, nil
+	}
+	if _, err := uc.checkConflicts(ctx, report.Conflicts); err != nil {
+		return nil, err
+	}
+	if err := uc.serviceRepo.Apply(ctx, changes); err != nil {
+		return nil, err
+	}
+	report.Applied = true
+
+	for _, service := range deleted {
+		uc.publish(ctx, entity.EventServiceDeleted, service.ID, nil, service)
+	}
+	for _, service := range changes.Update {
+		uc.record(ctx, service, current[service.Name], 0)
+		uc.publish(ctx, entity.EventServiceUpdated, service.ID, service, current[service.Name])
+	}
+	for _, service := range changes.Create {
+		uc.record(ctx, service, nil, 0)
+		uc.publish(ctx, entity.EventServiceCreated, service.ID, service, nil)
+	}
+	return report, nil
+}
+
+// serviceDrift returns the JSON names of the fields of a service that differ from its desired
+// definition
+func serviceDrift(current *entity.Service, desired *dto.CreateServiceRequest) []string {
+	var fields []string
+	for _, change := range serviceChanges(current, desired.ToEntity()) {
+		fields = append(fields, change.Field)
+	}
+	return fields
+}
@@ -0,0 +1,123 @@
+package usecase
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"api-gateway-sample/internal/application/dto"
+	"api-gateway-sample/internal/domain/entity"
+	"api-gateway-sample/internal/domain/repository"
+	"api-gateway-sample/internal/domain/repository/mock"
+	"api-gateway-sample/pkg/errors"
+)
+
+// racingRepository updates a service after every read of all services, as another
+// administrator would while a sync is computed
+type racingRepository struct {
+	repository.ServiceRepository
+	racing *entity.Service
+}
+
+func (r *racingRepository) GetAll(ctx context.Context) ([]*entity.Service, error) {
+	services, err := r.ServiceRepository.GetAll(ctx)
+	if err == nil {
+		racing := r.racing.Clone()
+		racing.Revision = 0
+		err = r.ServiceRepository.Update(ctx, racing)
+	}
+	return services, err
+}
+
+func desiredService(name string, baseURL string) dto.CreateServiceRequest {
+	return dto.CreateServiceRequest{
+		Name:      name,
+		BaseURL:   baseURL,
+		Endpoints: []dto.EndpointConfig{{Path: "/api/v1/" + name, Methods: []string{"GET"}}},
+	}
+}
+
+func TestServiceUseCase_SyncServices(t *testing.T) {
+	ctx := context.Background()
+	repo := mock.NewServiceRepositoryMock()
+	for _, name := range []string{"orders", "users", "legacy"} {
+		service := entity.NewService(name+"-id", name, "1.0.0", "", "http://"+name, 30, 3)
+		service.AddEndpoint(entity.Endpoint{Path: "/api/v1/" + name, Methods: []string{"GET"}})
+		if err := repo.Create(ctx, service); err != nil {
+			t.Fatalf("Failed to create service: %v", err)
+		}
+	}
+	bus := &syncBus{}
+	useCase := NewServiceUseCase(repo, nil, bus)
+	desired := &dto.DesiredStateRequest{Services: []dto.CreateServiceRequest{
+		desiredService("orders", "http://orders-v2"),
+		desiredService("users", "http://users"),
+		desiredService("billing", "http://billing"),
+	}}
+
+	// 1. A dry run reports the drift without changing anything
+	report, err := useCase.SyncServices(ctx, desired, true)
+	if err != nil {
+		t.Fatalf("Failed to sync services: %v", err)
+	}
+	expected := &dto.DriftReport{
+		Created:   []string{"billing"},
+		Updated:   []dto.ServiceDrift{{Name: "orders", Fields: []string{"baseUrl"}}},
+		Deleted:   []string{"legacy"},
+		Unchanged: []string{"users"},
+		Conflicts: []dto.RouteConflict{},
+	}
+	if !reflect.DeepEqual(report, expected) {
+		t.Errorf("Expected drift %+v, got %+v", expected, report)
+	}
+	if _, err := repo.FindByName(ctx, "legacy"); err != nil || len(bus.events) != 0 {
+		t.Errorf("Expected a dry run to change nothing, got %d events", len(bus.events))
+	}
+
+	// 2. The changes are applied and announced, keeping the settings the definitions lack
+	report, err = useCase.SyncServices(ctx, desired, false)
+	if err != nil {
+		t.Fatalf("Failed to sync services: %v", err)
+	}
+	if !report.Applied {
+		t.Errorf("Expected the changes to be applied")
+	}
+	orders, err := repo.FindByName(ctx, "orders")
+	if err != nil || orders.BaseURL != "http://orders-v2" || orders.Version != "1.0.0" || orders.Revision != 2 {
+		t.Errorf("Expected orders to be updated in place, got %+v (%v)", orders, err)
+	}
+	if _, err := repo.FindByName(ctx, "billing"); err != nil {
+		t.Errorf("Expected billing to be created, got %v", err)
+	}
+	if _, err := repo.FindByName(ctx, "legacy"); !errors.IsNotFound(err) {
+		t.Errorf("Expected legacy to be deleted, got %v", err)
+	}
+	if len(bus.events) != 3 {
+		t.Errorf("Expected 3 events, got %d", len(bus.events))
+	}
+
+	// 3. Once in sync there is nothing to apply
+	report, err = useCase.SyncServices(ctx, desired, false)
+	if err != nil || report.Applied || len(report.Unchanged) != 3 {
+		t.Errorf("Expected no drift, got %+v (%v)", report, err)
+	}
+
+	// 4. A service may only be declared once
+	duplicated := &dto.DesiredStateRequest{Services: []dto.CreateServiceRequest{
+		desiredService("orders", "http://orders"),
+		desiredService("orders", "http://orders-v3"),
+	}}
+	if _, err := useCase.SyncServices(ctx, duplicated, false); !errors.IsInvalidInput(err) {
+		t.Errorf("Expected a duplicated service to be rejected, got %v", err)
+	}
+
+	// 5. A service changed during the sync fails it as a whole
+	racing := NewServiceUseCase(&racingRepository{ServiceRepository: repo, racing: orders}, nil, bus)
+	desired.Services = append(desired.Services[1:], desiredService("orders", "http://orders-v3"), desiredService("stock", "http://stock"))
+	if _, err := racing.SyncServices(ctx, desired, false); !errors.IsPreconditionFailed(err) {
+		t.Fatalf("Expected the sync to fail, got %v", err)
+	}
+	if _, err := repo.FindByName(ctx, "stock"); !errors.IsNotFound(err) {
+		t.Errorf("Expected no change to be applied, got %v", err)
+	}
+}
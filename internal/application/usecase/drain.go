@@ -0,0 +1,194 @@
+package usecase
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"api-gateway-sample/internal/domain/entity"
+	"api-gateway-sample/internal/domain/repository"
+	"api-gateway-sample/pkg/errors"
+	"api-gateway-sample/pkg/logger"
+)
+
+// UpstreamDrainUseCase implements the use case for draining the former upstreams of services.
+// When the base URL of a service changes, as on a deployment, new requests go to the new
+// upstream while those in flight complete on the former one, which is drained once they have.
+// Requests still in flight a drain timeout after the switch are aborted. The requests are
+// tracked by each gateway instance.
+type UpstreamDrainUseCase struct {
+	serviceRepo  repository.ServiceRepository
+	drainTimeout time.Duration
+	logger       logger.Logger
+
+	mu       sync.Mutex
+	services map[string]*serviceUpstreams
+	nextID   uint64
+}
+
+// serviceUpstreams holds the upstreams of one service with requests in flight
+type serviceUpstreams struct {
+	current   string
+	upstreams map[string]*upstreamRequests
+}
+
+// upstreamRequests holds the requests in flight on one upstream, by ID
+type upstreamRequests struct {
+	cancels map[uint64]context.CancelFunc
+	// drainingSince is when the upstream was replaced, zero for the current upstream
+	drainingSince time.Time
+	deadline      time.Time
+	timer         *time.Timer
+}
+
+// NewUpstreamDrainUseCase creates a new UpstreamDrainUseCase instance. A zero drain timeout
+// leaves the requests on former upstreams to complete however long they take.
+func NewUpstreamDrainUseCase(serviceRepo repository.ServiceRepository, drainTimeout time.Duration, logger logger.Logger) *UpstreamDrainUseCase {
+	return &UpstreamDrainUseCase{
+		serviceRepo:  serviceRepo,
+		drainTimeout: drainTimeout,
+		logger:       logger,
+		services:     make(map[string]*serviceUpstreams),
+	}
+}
+
+// SetUpstreamDrain tracks the requests forwarded to each upstream in the given use case, so that
+// former upstreams of services are drained
+func (uc *ProxyUseCase) SetUpstreamDrain(drains *UpstreamDrainUseCase) {
+	uc.drains = drains
+}
+
+// DrainStatus returns the requests in flight on the current and former upstreams of a service
+func (uc *UpstreamDrainUseCase) DrainStatus(ctx context.Context, serviceID string) (*entity.DrainStatus, error) {
+	service, err := uc.serviceRepo.Get(ctx, serviceID)
+	if err != nil {
+		return nil, err
+	}
+
+	uc.mu.Lock()
+	defer uc.mu.Unlock()
+
+	// The service may have switched since its last request
+	upstreams := uc.switchUpstream(ctx, serviceID, service.BaseURL)
+	status := &entity.DrainStatus{
+		ServiceID: serviceID,
+		Target:    service.BaseURL,
+		Draining:  []*entity.UpstreamDrain{},
+	}
+	for target, requests := range upstreams.upstreams {
+		if target == upstreams.current {
+			status.InFlight = len(requests.cancels)
+			continue
+		}
+		drain := &entity.UpstreamDrain{Target: target, InFlight: len(requests.cancels), Since: requests.drainingSince}
+		if !requests.deadline.IsZero() {
+			deadline := requests.deadline
+			drain.Deadline = &deadline
+		}
+		status.Draining = append(status.Draining, drain)
+	}
+	sort.Slice(status.Draining, func(i, j int) bool {
+		return status.Draining[i].Since.Before(status.Draining[j].Since)
+	})
+	status.Drained = len(status.Draining) == 0
+	return status, nil
+}
+
+// begin tracks a request forwarded to the upstream of a service until the returned function is
+// called. The returned context is cancelled when the upstream is drained past the drain timeout.
+func (uc *UpstreamDrainUseCase) begin(ctx context.Context, service *entity.Service) (context.Context, func()) {
+	ctx, cancel := context.WithCancel(ctx)
+
+	uc.mu.Lock()
+	upstreams := uc.switchUpstream(ctx, service.ID, service.BaseURL)
+	requests, ok := upstreams.upstreams[service.BaseURL]
+	if !ok {
+		requests = &upstreamRequests{cancels: make(map[uint64]context.CancelFunc)}
+		upstreams.upstreams[service.BaseURL] = requests
+	}
+	uc.nextID++
+	id := uc.nextID
+	requests.cancels[id] = cancel
+	uc.mu.Unlock()
+
+	return ctx, func() {
+		uc.mu.Lock()
+		delete(requests.cancels, id)
+		if len(requests.cancels) == 0 && upstreams.upstreams[service.BaseURL] == requests && service.BaseURL != upstreams.current {
+			uc.finishDrain(ctx, service.ID, upstreams, service.BaseURL)
+		}
+		uc.mu.Unlock()
+		cancel()
+	}
+}
+
+// switchUpstream returns the upstreams of a service, making target its current upstream and
+// draining the former one. The caller holds the lock.
+func (uc *UpstreamDrainUseCase) switchUpstream(ctx context.Context, serviceID string, target string) *serviceUpstreams {
+	upstreams, ok := uc.services[serviceID]
+	if !ok {
+		upstreams = &serviceUpstreams{current: target, upstreams: make(map[string]*upstreamRequests)}
+		uc.services[serviceID] = upstreams
+	}
+	if upstreams.current == target {
+		return upstreams
+	}
+
+	former := upstreams.current
+	upstreams.current = target
+	// Switching back to an upstream being drained makes it current again
+	if requests, ok := upstreams.upstreams[target]; ok {
+		if requests.timer != nil {
+			requests.timer.Stop()
+		}
+		requests.drainingSince, requests.deadline, requests.timer = time.Time{}, time.Time{}, nil
+	}
+
+	requests, ok := upstreams.upstreams[former]
+	if !ok || len(requests.cancels) == 0 {
+		delete(upstreams.upstreams, former)
+		return upstreams
+	}
+	requests.drainingSince = time.Now()
+	if uc.drainTimeout > 0 {
+		requests.deadline = requests.drainingSince.Add(uc.drainTimeout)
+		requests.timer = time.AfterFunc(uc.drainTimeout, func() {
+			uc.abortDrain(serviceID, former, requests)
+		})
+	}
+	logger.FromContextOr(ctx, uc.logger).Info("Draining former upstream",
+		"service_id", serviceID, "target", former, "next", target, "in_flight", len(requests.cancels))
+	return upstreams
+}
+
+// finishDrain forgets a former upstream once its last request completed. The caller holds the lock.
+func (uc *UpstreamDrainUseCase) finishDrain(ctx context.Context, serviceID string, upstreams *serviceUpstreams, target string) {
+	requests := upstreams.upstreams[target]
+	if requests.timer != nil {
+		requests.timer.Stop()
+	}
+	delete(upstreams.upstreams, target)
+	logger.FromContextOr(ctx, uc.logger).Info("Former upstream drained",
+		"service_id", serviceID, "target", target, "drain_ms", time.Since(requests.drainingSince).Milliseconds())
+}
+
+// abortDrain cancels the requests still in flight on a former upstream at its drain timeout
+func (uc *UpstreamDrainUseCase) abortDrain(serviceID string, target string, requests *upstreamRequests) {
+	uc.mu.Lock()
+	defer uc.mu.Unlock()
+
+	upstreams := uc.services[serviceID]
+	if upstreams.upstreams[target] != requests || requests.drainingSince.IsZero() {
+		return
+	}
+	for _, cancel := range requests.cancels {
+		cancel()
+	}
+	delete(upstreams.upstreams, target)
+	uc.logger.Warn("Aborted requests on former upstream past the drain timeout",
+		"service_id", serviceID, "target", target, "aborted", len(requests.cancels))
+}
+
+// errDrainAborted is the error of requests aborted by the drain of their upstream
+var errDrainAborted = errors.NewError(errors.CodeServiceUnavailable, "the service switched upstreams and the request exceeded the drain timeout", errors.ErrServiceUnavailable)
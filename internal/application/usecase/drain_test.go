@@ -0,0 +1,95 @@
+package usecase
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"api-gateway-sample/internal/domain/entity"
+	"api-gateway-sample/pkg/errors"
+)
+
+func TestUpstreamDrainUseCase_SwitchUpstream(t *testing.T) {
+	ctx := context.Background()
+	orders := entity.NewService("orders-id", "orders", "1.0.0", "", "http://orders-v1:8080", 30, 3)
+	orders.AddEndpoint(entity.Endpoint{Path: "/api/v1/orders", Methods: []string{http.MethodGet}})
+	serviceRepo := newServiceRepository(t, orders)
+	deploy := func(baseURL string) {
+		updated := *orders
+		updated.BaseURL = baseURL
+		updated.Revision = 0
+		if err := serviceRepo.Update(ctx, &updated); err != nil {
+			t.Fatalf("Failed to update service: %v", err)
+		}
+	}
+
+	gateway := &blockingGateway{started: make(chan struct{}), release: make(chan struct{})}
+	useCase := NewProxyUseCase(serviceRepo, gateway, nil, nil, nil, &MockLogger{})
+	drains := NewUpstreamDrainUseCase(serviceRepo, 0, &MockLogger{})
+	useCase.SetUpstreamDrain(drains)
+	send := func() chan error {
+		done := make(chan error, 1)
+		go func() {
+			_, err := useCase.ProxyRequest(ctx, entity.NewRequest(http.MethodGet, "/api/v1/orders", nil, nil, nil, "10.0.0.1"))
+			done <- err
+		}()
+		<-gateway.started
+		return done
+	}
+	status := func() *entity.DrainStatus {
+		status, err := drains.DrainStatus(ctx, "orders-id")
+		if err != nil {
+			t.Fatalf("DrainStatus failed: %v", err)
+		}
+		return status
+	}
+
+	// 1. A request is in flight on the current upstream
+	first := send()
+	if s := status(); s.Target != "http://orders-v1:8080" || s.InFlight != 1 || !s.Drained {
+		t.Errorf("Expected one request on the current upstream, got %+v", s)
+	}
+
+	// 2. After a deployment, the former upstream drains while new requests go to the new one
+	deploy("http://orders-v2:8080")
+	second := send()
+	s := status()
+	if s.Target != "http://orders-v2:8080" || s.InFlight != 1 || s.Drained || len(s.Draining) != 1 {
+		t.Fatalf("Expected the former upstream to drain, got %+v", s)
+	}
+	if drain := s.Draining[0]; drain.Target != "http://orders-v1:8080" || drain.InFlight != 1 || drain.Deadline != nil {
+		t.Errorf("Expected one request left on the former upstream without a deadline, got %+v", drain)
+	}
+
+	// 3. The request in flight completes on the former upstream, which is then drained
+	gateway.release <- struct{}{}
+	if err := <-first; err != nil {
+		t.Errorf("Expected the draining request to complete, got %v", err)
+	}
+	if s := status(); !s.Drained || s.InFlight != 1 {
+		t.Errorf("Expected the former upstream to be drained, got %+v", s)
+	}
+	gateway.release <- struct{}{}
+	<-second
+
+	// 4. With a drain timeout, requests left on the former upstream are aborted
+	drains = NewUpstreamDrainUseCase(serviceRepo, 20*time.Millisecond, &MockLogger{})
+	useCase.SetUpstreamDrain(drains)
+	slow := send()
+	deploy("http://orders-v3:8080")
+	if s := status(); len(s.Draining) != 1 || s.Draining[0].Deadline == nil {
+		t.Fatalf("Expected a drain deadline, got %+v", s)
+	}
+	if err := <-slow; !errors.IsServiceUnavailable(err) {
+		t.Errorf("Expected service unavailable for an aborted request, got %v", err)
+	}
+	if s := status(); !s.Drained {
+		t.Errorf("Expected the former upstream to be drained after the timeout, got %+v", s)
+	}
+
+	// 5. Unknown services have no drain status
+	if _, err := drains.DrainStatus(ctx, "missing"); !errors.IsNotFound(err) {
+		t.Errorf("Expected not found, got %v", err)
+	}
+}
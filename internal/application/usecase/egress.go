@@ -0,0 +1,172 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	"api-gateway-sample/internal/domain/entity"
+	"api-gateway-sample/internal/domain/service"
+	"api-gateway-sample/pkg/errors"
+	"api-gateway-sample/pkg/logger"
+)
+
+// egressServiceID scopes the rate limits of egress destinations
+const egressServiceID = "egress"
+
+// EgressUseCase implements the use case for egress requests, through which internal clients reach
+// external hosts. Only allowlisted destinations are reachable; the gateway bounds the rate of the
+// requests to each and injects its credentials, so that clients never hold them.
+type EgressUseCase struct {
+	destinations     []entity.EgressDestination
+	client           service.UpstreamClient
+	rateLimitService service.RateLimitService
+	logger           logger.Logger
+
+	// signer signs the requests to destinations that configure signing, nil when disabled
+	signer service.RequestSigner
+}
+
+// NewEgressUseCase creates a new EgressUseCase instance. The first destination matching a host
+// applies to it.
+func NewEgressUseCase(
+	destinations []entity.EgressDestination,
+	client service.UpstreamClient,
+	rateLimitService service.RateLimitService,
+	logger logger.Logger,
+) *EgressUseCase {
+	return &EgressUseCase{
+		destinations:     destinations,
+		client:           client,
+		rateLimitService: rateLimitService,
+		logger:           logger,
+	}
+}
+
+// SetRequestSigner signs the requests to destinations that configure signing
+func (uc *EgressUseCase) SetRequestSigner(signer service.RequestSigner) {
+	uc.signer = signer
+}
+
+// Forward sends a client request for host to its destination, with the destination's credentials
+func (uc *EgressUseCase) Forward(ctx context.Context, host string, request *entity.Request) (*entity.Response, error) {
+	log := logger.FromContextOr(ctx, uc.logger)
+	destination, err := uc.allow(ctx, host, request)
+	if err != nil {
+		return nil, err
+	}
+	if err := uc.limitRate(ctx, destination, host, request); err != nil {
+		return nil, err
+	}
+
+	scheme := destination.Scheme
+	if scheme == "" {
+		scheme = "https"
+	}
+	target := &entity.Service{
+		ID:       egressServiceID,
+		Name:     host,
+		BaseURL:  scheme + "://" + host,
+		Signing:  destination.Signing,
+		External: true,
+	}
+
+	// The client's request is left untouched, as the injected credentials must not leak back
+	forwarded := *request
+	forwarded.Headers = http.Header(request.Headers).Clone()
+	if forwarded.Headers == nil {
+		forwarded.Headers = make(map[string][]string)
+	}
+	for name, value := range destination.Headers {
+		http.Header(forwarded.Headers).Set(name, value)
+	}
+	if destination.Signing != nil {
+		if uc.signer == nil {
+			return nil, errors.NewError(errors.CodeServiceUnavailable, "upstream signing is not configured", nil)
+		}
+		if err := uc.signer.Sign(ctx, &forwarded, target); err != nil {
+			return nil, errors.NewError(errors.CodeServiceUnavailable, "failed to sign egress request", err)
+		}
+	}
+
+	start := time.Now()
+	response, err := uc.client.SendRequest(ctx, &forwarded, target)
+	if err != nil {
+		log.Warn("Egress request failed", "client_ip", request.ClientIP, "host", host, "method", request.Method, "path", request.Path, "error", err)
+		return nil, errors.NewError(errors.CodeBadGateway, fmt.Sprintf("failed to reach %s", host), err)
+	}
+	log.Info("Egress request completed",
+		"client_ip", request.ClientIP,
+		"host", host,
+		"method", request.Method,
+		"path", request.Path,
+		"status", response.StatusCode,
+		"latency_ms", time.Since(start).Milliseconds(),
+	)
+	return response, nil
+}
+
+// OpenTunnel admits a tunnel from a client to host, whose traffic the gateway relays without
+// reading it. Destinations with credentials only accept plain requests, as credentials cannot
+// be injected into a tunnel.
+func (uc *EgressUseCase) OpenTunnel(ctx context.Context, host string, clientIP string) error {
+	request := &entity.Request{Method: http.MethodConnect, ClientIP: clientIP}
+	destination, err := uc.allow(ctx, host, request)
+	if err != nil {
+		return err
+	}
+	if destination.InjectsCredentials() {
+		return errors.NewError(errors.CodeForbidden, fmt.Sprintf("%s only accepts plain requests, which the gateway adds credentials to", host), nil)
+	}
+	if err := uc.limitRate(ctx, destination, host, request); err != nil {
+		return err
+	}
+	logger.FromContextOr(ctx, uc.logger).Info("Egress tunnel opened", "client_ip", clientIP, "host", host)
+	return nil
+}
+
+// allow returns the destination allowing host, failing with 403 when none does
+func (uc *EgressUseCase) allow(ctx context.Context, host string, request *entity.Request) (*entity.EgressDestination, error) {
+	destination := uc.destination(host)
+	if destination == nil {
+		logger.FromContextOr(ctx, uc.logger).Warn("Egress request denied", "client_ip", request.ClientIP, "host", host, "method", request.Method)
+		return nil, errors.NewError(errors.CodeForbidden, fmt.Sprintf("destination %s is not allowed", host), nil)
+	}
+	return destination, nil
+}
+
+// limitRate takes a request from the rate limit of a destination, failing with 429 when it is used up
+func (uc *EgressUseCase) limitRate(ctx context.Context, destination *entity.EgressDestination, host string, request *entity.Request) error {
+	if destination.RateLimit == 0 || uc.rateLimitService == nil {
+		return nil
+	}
+	log := logger.FromContextOr(ctx, uc.logger)
+
+	// Limits are shared by all clients of a destination, whose quota is usually per account
+	limited := &entity.Request{Path: destination.Host}
+	service := &entity.Service{ID: egressServiceID}
+	endpoint := &entity.Endpoint{Path: destination.Host, RateLimit: destination.RateLimit}
+	allowed, err := uc.rateLimitService.CheckLimit(ctx, limited, service, endpoint)
+	if err != nil {
+		return errors.NewError(errors.CodeServiceUnavailable, "rate limit check failed", err)
+	}
+	if !allowed {
+		log.Warn("Egress rate limit exceeded", "client_ip", request.ClientIP, "host", host)
+		return errors.NewError(errors.CodeRateLimitExceeded, fmt.Sprintf("rate limit of %s exceeded", host), errors.ErrRateLimitExceeded)
+	}
+	if err := uc.rateLimitService.RecordRequest(ctx, limited, service, endpoint); err != nil {
+		log.Warn("Failed to record request for rate limiting", "error", err)
+	}
+	return nil
+}
+
+// destination returns the first destination allowing host, nil when none does
+func (uc *EgressUseCase) destination(host string) *entity.EgressDestination {
+	for i := range uc.destinations {
+		if uc.destinations[i].Matches(host) {
+			return &uc.destinations[i]
+		}
+	}
+	return nil
+}
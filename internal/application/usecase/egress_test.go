@@ -0,0 +1,101 @@
+package usecase
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"api-gateway-sample/internal/domain/entity"
+	"api-gateway-sample/pkg/errors"
+)
+
+// recordingClient records the requests sent upstream and answers them with 200
+type recordingClient struct {
+	requests []*entity.Request
+	services []*entity.Service
+}
+
+func (c *recordingClient) SendRequest(ctx context.Context, request *entity.Request, service *entity.Service) (*entity.Response, error) {
+	c.requests = append(c.requests, request)
+	c.services = append(c.services, service)
+	return &entity.Response{StatusCode: http.StatusOK}, nil
+}
+
+// quotaLimiter allows a fixed number of requests per rate limiting key
+type quotaLimiter struct {
+	quota int
+	used  map[string]int
+}
+
+func (l *quotaLimiter) CheckLimit(ctx context.Context, request *entity.Request, service *entity.Service, endpoint *entity.Endpoint) (bool, error) {
+	return l.used[service.ID+request.Path+request.ClientIP] < l.quota, nil
+}
+
+func (l *quotaLimiter) RecordRequest(ctx context.Context, request *entity.Request, service *entity.Service, endpoint *entity.Endpoint) error {
+	l.used[service.ID+request.Path+request.ClientIP]++
+	return nil
+}
+
+func (l *quotaLimiter) GetLimit(ctx context.Context, request *entity.Request, service *entity.Service, endpoint *entity.Endpoint) (entity.RateLimitStatus, error) {
+	return entity.RateLimitStatus{Limit: l.quota, Remaining: l.quota - l.used[service.ID+request.Path+request.ClientIP], Reset: time.Minute}, nil
+}
+
+func TestEgressUseCase(t *testing.T) {
+	ctx := context.Background()
+	client := &recordingClient{}
+	limiter := &quotaLimiter{quota: 2, used: map[string]int{}}
+	useCase := NewEgressUseCase([]entity.EgressDestination{
+		{Host: "api.stripe.com", RateLimit: 2, Headers: map[string]string{"Authorization": "Bearer sk_live"}},
+		{Host: "*.github.com", Scheme: "http"},
+	}, client, limiter, &MockLogger{})
+	newRequest := func(clientIP string) *entity.Request {
+		headers := map[string][]string{"Authorization": {"Bearer client"}}
+		return entity.NewRequest(http.MethodPost, "/v1/charges", headers, nil, nil, clientIP)
+	}
+
+	// 1. Requests to allowed hosts carry the destination's credentials instead of the client's
+	request := newRequest("10.0.0.1")
+	if _, err := useCase.Forward(ctx, "api.stripe.com", request); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	sent := client.requests[0]
+	if got := http.Header(sent.Headers).Get("Authorization"); got != "Bearer sk_live" {
+		t.Errorf("Expected the injected credentials, got %q", got)
+	}
+	if got := http.Header(request.Headers).Get("Authorization"); got != "Bearer client" {
+		t.Errorf("Expected the client's request to be left untouched, got %q", got)
+	}
+	if client.services[0].BaseURL != "https://api.stripe.com" {
+		t.Errorf("Expected requests to be sent over https, got %s", client.services[0].BaseURL)
+	}
+	if !client.services[0].External {
+		t.Error("Expected destinations not to be told about the gateway's clients")
+	}
+
+	// 2. The rate limit of a destination is shared by all clients
+	if _, err := useCase.Forward(ctx, "api.stripe.com:443", newRequest("10.0.0.2")); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	_, err := useCase.Forward(ctx, "api.stripe.com", newRequest("10.0.0.3"))
+	if errors.StatusCodeOf(err, 0) != http.StatusTooManyRequests {
+		t.Errorf("Expected 429 once the destination's quota is used, got %v", err)
+	}
+
+	// 3. Hosts without a destination are denied
+	_, err = useCase.Forward(ctx, "evil.example.com", newRequest("10.0.0.1"))
+	if errors.StatusCodeOf(err, 0) != http.StatusForbidden {
+		t.Errorf("Expected 403 for a host that is not allowed, got %v", err)
+	}
+	if _, err := useCase.Forward(ctx, "github.com", newRequest("10.0.0.1")); errors.StatusCodeOf(err, 0) != http.StatusForbidden {
+		t.Errorf("Expected wildcards to only match subdomains, got %v", err)
+	}
+
+	// 4. Tunnels are opened to destinations without credentials only
+	if err := useCase.OpenTunnel(ctx, "api.github.com:443", "10.0.0.1"); err != nil {
+		t.Errorf("Expected a tunnel to an allowed host, got %v", err)
+	}
+	if err := useCase.OpenTunnel(ctx, "api.stripe.com:443", "10.0.0.1"); errors.StatusCodeOf(err, 0) != http.StatusForbidden {
+		t.Errorf("Expected tunnels to destinations with credentials to be refused, got %v", err)
+	}
+}
@@ -0,0 +1,96 @@
+package usecase
+
+import (
+	"context"
+	"net/http"
+
+	"api-gateway-sample/internal/domain/entity"
+	"api-gateway-sample/internal/domain/service"
+	"api-gateway-sample/pkg/errors"
+)
+
+// encryptedContentType is the content type of encrypted request and response bodies
+const encryptedContentType = "application/jose"
+
+// SetPayloadCipher decrypts the request bodies and encrypts the response bodies of endpoints
+// that configure payload encryption, so that services exchange plaintext with the gateway only
+func (uc *ProxyUseCase) SetPayloadCipher(cipher service.PayloadCipher) {
+	uc.cipher = cipher
+}
+
+// dispatchEncrypted dispatches a request to an endpoint that configures payload encryption,
+// decrypting its body beforehand and encrypting the body of the response. Such endpoints never
+// exchange plaintext with clients: they fail with 503 when no cipher is set up.
+func (uc *ProxyUseCase) dispatchEncrypted(ctx context.Context, request *entity.Request, service *entity.Service, endpoint *entity.Endpoint, sample *entity.RequestSample) (*entity.Response, error) {
+	encryption := endpoint.Encryption
+	if uc.cipher == nil {
+		return nil, errors.NewError(errors.CodeServiceUnavailable, "payload encryption is not configured", nil)
+	}
+
+	if encryption.Requests && len(request.Body) > 0 {
+		decrypted, err := uc.decryptRequest(ctx, request, encryption)
+		if err != nil {
+			return nil, err
+		}
+		request = decrypted
+	}
+
+	response, err := uc.dispatchPlain(ctx, request, service, endpoint, sample)
+	if err != nil || !encryption.Responses || len(response.Body) == 0 {
+		return response, err
+	}
+	return uc.encryptResponse(ctx, response, encryption)
+}
+
+// decryptRequest returns a copy of a request with its body decrypted, typed with the content
+// type carried by the encrypted body or as JSON
+func (uc *ProxyUseCase) decryptRequest(ctx context.Context, request *entity.Request, encryption *entity.PayloadEncryption) (*entity.Request, error) {
+	body, contentType, err := uc.cipher.Decrypt(ctx, encryption.Key, request.Body)
+	if errors.IsServiceUnavailable(err) {
+		return nil, err
+	}
+	if err != nil {
+		return nil, errors.NewError(errors.CodeInvalidInput, "request body is not encrypted for this endpoint", errors.ErrInvalidInput)
+	}
+	if contentType == "" {
+		contentType = "application/json"
+	}
+
+	decrypted := *request
+	decrypted.Body = body
+	decrypted.Headers = http.Header(request.Headers).Clone()
+	if decrypted.Headers == nil {
+		decrypted.Headers = make(map[string][]string)
+	}
+	http.Header(decrypted.Headers).Del("Content-Length")
+	http.Header(decrypted.Headers).Set("Content-Type", contentType)
+	return &decrypted, nil
+}
+
+// encryptResponse returns a copy of a response with its body encrypted, keeping its content
+// type inside the encrypted body
+func (uc *ProxyUseCase) encryptResponse(ctx context.Context, response *entity.Response, encryption *entity.PayloadEncryption) (*entity.Response, error) {
+	contentType := http.Header(response.Headers).Get("Content-Type")
+	if contentType == "" {
+		contentType = response.ContentType
+	}
+	body, err := uc.cipher.Encrypt(ctx, encryption.ResponseKeyName(), response.Body, contentType)
+	if errors.IsServiceUnavailable(err) {
+		return nil, err
+	}
+	if err != nil {
+		return nil, errors.NewError(errors.CodeServiceUnavailable, "failed to encrypt response", err)
+	}
+
+	encrypted := *response
+	encrypted.Headers = http.Header(response.Headers).Clone()
+	if encrypted.Headers == nil {
+		encrypted.Headers = make(map[string][]string)
+	}
+	http.Header(encrypted.Headers).Del("Content-Length")
+	http.Header(encrypted.Headers).Set("Content-Type", encryptedContentType)
+	encrypted.ContentType = encryptedContentType
+	encrypted.ContentLength = len(body)
+	encrypted.Body = body
+	return &encrypted, nil
+}
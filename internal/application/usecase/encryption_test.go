@@ -0,0 +1,95 @@
+package usecase
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"api-gateway-sample/internal/domain/entity"
+	"api-gateway-sample/pkg/errors"
+)
+
+// prefixCipher "encrypts" payloads by prefixing them with the key name and content type
+type prefixCipher struct {
+	keys map[string]bool
+}
+
+func (c *prefixCipher) Decrypt(ctx context.Context, key string, body []byte) ([]byte, string, error) {
+	if !c.keys[key] {
+		return nil, "", errors.NewError(errors.CodeServiceUnavailable, "unknown key", errors.ErrServiceUnavailable)
+	}
+	parts := bytes.SplitN(body, []byte("|"), 3)
+	if len(parts) != 3 || string(parts[0]) != key {
+		return nil, "", fmt.Errorf("not encrypted for %s", key)
+	}
+	return parts[2], string(parts[1]), nil
+}
+
+func (c *prefixCipher) Encrypt(ctx context.Context, key string, content []byte, contentType string) ([]byte, error) {
+	if !c.keys[key] {
+		return nil, errors.NewError(errors.CodeServiceUnavailable, "unknown key", errors.ErrServiceUnavailable)
+	}
+	return []byte(key + "|" + contentType + "|" + string(content)), nil
+}
+
+func TestProxyUseCase_PayloadEncryption(t *testing.T) {
+	ctx := context.Background()
+	service := newTestService("payments", entity.Endpoint{
+		Path:       "/api/v1/payments",
+		Methods:    []string{http.MethodPost},
+		Encryption: &entity.PayloadEncryption{Key: "gateway_key", ResponseKey: "client_key", Requests: true, Responses: true},
+	})
+
+	jsonHeaders := map[string][]string{"Content-Type": {"application/json"}, "Content-Length": {"11"}}
+	gateway := &scriptedGateway{responses: []*entity.Response{
+		{StatusCode: http.StatusCreated, Headers: jsonHeaders, Body: []byte(`{"id":"p1"}`)},
+		{StatusCode: http.StatusCreated, Headers: jsonHeaders, Body: []byte(`{"id":"p2"}`)},
+	}}
+	useCase := newProxyFixture(t, gateway, service)
+	newRequest := func(body string) *entity.Request {
+		headers := map[string][]string{"Content-Type": {"application/jose"}}
+		return entity.NewRequest(http.MethodPost, "/api/v1/payments", headers, nil, []byte(body), "10.0.0.1")
+	}
+
+	// 1. Without a cipher, encrypted endpoints are unavailable rather than served in plaintext
+	_, err := useCase.ProxyRequest(ctx, newRequest(`gateway_key|application/json|{"amount":5}`))
+	if errors.StatusCodeOf(err, 0) != http.StatusServiceUnavailable {
+		t.Errorf("Expected 503 without a cipher, got %v", err)
+	}
+	useCase.SetPayloadCipher(&prefixCipher{keys: map[string]bool{"gateway_key": true, "client_key": true}})
+
+	// 2. The service receives the decrypted body typed with its content type, and the client
+	// the response encrypted for its key
+	response, err := useCase.ProxyRequest(ctx, newRequest(`gateway_key|application/json|{"amount":5}`))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	sent := gateway.requests[0]
+	if string(sent.Body) != `{"amount":5}` || http.Header(sent.Headers).Get("Content-Type") != "application/json" {
+		t.Errorf("Unexpected request to the service %v %s", sent.Headers, sent.Body)
+	}
+	if want := `client_key|application/json|{"id":"p1"}`; string(response.Body) != want {
+		t.Errorf("Expected %s, got %s", want, response.Body)
+	}
+	if got := http.Header(response.Headers).Get("Content-Type"); got != "application/jose" || http.Header(response.Headers).Get("Content-Length") != "" {
+		t.Errorf("Unexpected response headers %v", response.Headers)
+	}
+
+	// 3. Bodies that are not encrypted for the endpoint are rejected before reaching the service
+	_, err = useCase.ProxyRequest(ctx, newRequest(`{"amount":5}`))
+	if errors.StatusCodeOf(err, 0) != http.StatusBadRequest {
+		t.Errorf("Expected 400 for a plaintext body, got %v", err)
+	}
+	if len(gateway.requests) != 1 {
+		t.Errorf("Expected the plaintext request not to be forwarded, got %d requests", len(gateway.requests))
+	}
+
+	// 4. Keys missing from the secrets make the endpoint unavailable
+	useCase.SetPayloadCipher(&prefixCipher{keys: map[string]bool{"gateway_key": true}})
+	_, err = useCase.ProxyRequest(ctx, newRequest(`gateway_key|application/json|{"amount":7}`))
+	if errors.StatusCodeOf(err, 0) != http.StatusServiceUnavailable {
+		t.Errorf("Expected 503 without the response key, got %v", err)
+	}
+}
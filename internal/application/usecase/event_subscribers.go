@@ -0,0 +1,133 @@
+package usecase
+
+import (
+	"context"
+	"strings"
+
+	"api-gateway-sample/internal/domain/entity"
+	"api-gateway-sample/internal/domain/service"
+	"api-gateway-sample/pkg/logger"
+)
+
+// SubscribeCacheInvalidation evicts the cached responses of a service when it changes or is
+// deleted, on this or, with a bus shared by the instances, any other gateway instance
+func SubscribeCacheInvalidation(bus service.EventBus, cacheService service.CacheService, log logger.Logger) {
+	invalidate := func(ctx context.Context, event *entity.Event) {
+		if event.Previous == nil {
+			return
+		}
+		// Responses of services with data residency are cached by region
+		regions := []string{""}
+		if event.Previous.Residency != nil {
+			regions = append(regions, event.Previous.Residency.Regions()...)
+		}
+		// Endpoints on the same path and method are cleared by the same pattern
+		cleared := make(map[string]bool)
+		clearMatching := func(pattern string) {
+			if cleared[pattern] {
+				return
+			}
+			cleared[pattern] = true
+			if err := cacheService.ClearMatching(ctx, pattern); err != nil {
+				logger.FromContextOr(ctx, log).Warn("Failed to invalidate cached responses", "pattern", pattern, "error", err)
+			}
+		}
+		for i := range event.Previous.Endpoints {
+			endpoint := &event.Previous.Endpoints[i]
+			// Responses of endpoints running an experiment are cached by variant
+			variants := []string{""}
+			if endpoint.Experiment != nil {
+				for _, variant := range endpoint.Experiment.Variants {
+					variants = append(variants, variant.Name)
+				}
+			}
+			for _, method := range endpoint.Methods {
+				// Responses of prefix endpoints, or of endpoints accepting any method, are cached
+				// under the path and method requested, so they are cleared by pattern
+				if strings.HasSuffix(endpoint.Path, "*") || method == "*" {
+					clearMatching(responseCachePattern(event.Previous.ID, endpoint.Path, method))
+					continue
+				}
+				prefix := responseCacheKey(event.Previous.ID, endpoint.Path, method)
+				for _, variant := range variants {
+					for _, region := range regions {
+						key := scopedCacheKey(prefix, endpoint, variant, region)
+						if err := cacheService.Delete(ctx, key); err != nil {
+							logger.FromContextOr(ctx, log).Warn("Failed to invalidate cached response", "key", key, "error", err)
+						}
+					}
+				}
+				// Responses to requests with a query are cached by query, so they are cleared by pattern
+				clearMatching(escapeGlob(prefix+"?") + "*")
+			}
+		}
+	}
+
+	bus.Subscribe(entity.EventServiceUpdated, invalidate)
+	bus.Subscribe(entity.EventServiceDeleted, invalidate)
+}
+
+// responseCachePattern returns the glob pattern matching the cache keys of the responses of an
+// endpoint path and method, where a trailing "*" of the path and a "*" method match any, whatever
+// the feature flag, variant and region they are scoped to
+func responseCachePattern(serviceID string, path string, method string) string {
+	pattern := escapeGlob(serviceID + ":" + strings.TrimSuffix(path, "*"))
+	if strings.HasSuffix(path, "*") {
+		pattern += "*"
+	}
+	if method == "*" {
+		return pattern + ":*"
+	}
+	return pattern + ":" + escapeGlob(method) + "*"
+}
+
+// escapeGlob escapes the characters of s that have a meaning in glob patterns
+func escapeGlob(s string) string {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		switch s[i] {
+		case '*', '?', '[', ']', '\\':
+			b.WriteByte('\\')
+		}
+		b.WriteByte(s[i])
+	}
+	return b.String()
+}
+
+// SubscribeRemoteReload calls reload when another gateway instance changes the configuration,
+// so state loaded at startup, such as the routes of the file storage backend, follows the change.
+// Each reload is published as EventRoutesReloaded.
+func SubscribeRemoteReload(bus service.EventBus, reload func() error, log logger.Logger) {
+	handler := func(ctx context.Context, event *entity.Event) {
+		if !event.Remote {
+			return
+		}
+		if err := reload(); err != nil {
+			logger.FromContextOr(ctx, log).Error("Failed to reload configuration", "event", event.Type, "error", err)
+			return
+		}
+		reloaded := entity.NewEvent(entity.EventRoutesReloaded)
+		reloaded.ServiceID = event.ServiceID
+		bus.Publish(ctx, reloaded)
+	}
+
+	for _, eventType := range entity.ConfigEvents {
+		bus.Subscribe(eventType, handler)
+	}
+}
+
+// SubscribeAuditLog records every event in the audit log. Events of other gateway instances
+// are recorded by the instance they happened on.
+func SubscribeAuditLog(bus service.EventBus, log logger.Logger) {
+	bus.Subscribe(entity.EventAll, func(ctx context.Context, event *entity.Event) {
+		if event.Remote {
+			return
+		}
+		logger.FromContextOr(ctx, log).Info("Audit event",
+			"event_id", event.ID,
+			"event", event.Type,
+			"actor", event.Actor,
+			"service_id", event.ServiceID,
+		)
+	})
+}
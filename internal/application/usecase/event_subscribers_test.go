@@ -0,0 +1,228 @@
+package usecase
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"api-gateway-sample/internal/application/dto"
+	"api-gateway-sample/internal/domain/entity"
+	"api-gateway-sample/internal/domain/repository/mock"
+	"api-gateway-sample/internal/domain/service"
+)
+
+// syncBus is a minimal synchronous event bus for testing
+type syncBus struct {
+	handlers map[string][]service.EventHandler
+	events   []*entity.Event
+}
+
+func (b *syncBus) Subscribe(eventType string, handler service.EventHandler) func() {
+	if b.handlers == nil {
+		b.handlers = make(map[string][]service.EventHandler)
+	}
+	b.handlers[eventType] = append(b.handlers[eventType], handler)
+	return func() {}
+}
+
+func (b *syncBus) Publish(ctx context.Context, event *entity.Event) {
+	b.events = append(b.events, event)
+	for _, handler := range b.handlers[event.Type] {
+		handler(ctx, event)
+	}
+}
+
+// recordingCache records deleted keys and cleared patterns
+type recordingCache struct {
+	deleted []string
+	cleared []string
+}
+
+func (c *recordingCache) Get(ctx context.Context, key string) (interface{}, bool, error) {
+	return nil, false, nil
+}
+
+func (c *recordingCache) Set(ctx context.Context, key string, value interface{}, ttl time.Duration) error {
+	return nil
+}
+
+func (c *recordingCache) Delete(ctx context.Context, key string) error {
+	c.deleted = append(c.deleted, key)
+	return nil
+}
+
+func (c *recordingCache) Clear(ctx context.Context) error {
+	return nil
+}
+
+func (c *recordingCache) ClearMatching(ctx context.Context, pattern string) error {
+	c.cleared = append(c.cleared, pattern)
+	return nil
+}
+
+func TestServiceUseCase_UpdateInvalidatesCache(t *testing.T) {
+	ctx := context.Background()
+	repo := mock.NewServiceRepositoryMock()
+	bus := &syncBus{}
+	cache := &recordingCache{}
+	SubscribeCacheInvalidation(bus, cache, &MockLogger{})
+
+	useCase := NewServiceUseCase(repo, nil, bus)
+
+	svc := &entity.Service{
+		ID:      "svc-1",
+		Name:    "orders",
+		BaseURL: "http://orders",
+		Endpoints: []entity.Endpoint{
+			{Path: "/api/v1/orders", Methods: []string{"GET", "HEAD"}},
+			{Path: "/api/v1/orders", Methods: []string{"GET"}, Priority: 1, FeatureFlag: "composite-orders"},
+		},
+	}
+	if err := repo.Create(ctx, svc); err != nil {
+		t.Fatalf("Failed to create service: %v", err)
+	}
+
+	_, err := useCase.UpdateService(ctx, "svc-1", &dto.UpdateServiceRequest{
+		Name:    "orders",
+		BaseURL: "http://orders-v2",
+		Endpoints: []dto.EndpointConfig{
+			{Path: "/api/v2/orders", Methods: []string{"GET"}},
+		},
+	})
+	if err != nil {
+		t.Fatalf("Failed to update service: %v", err)
+	}
+
+	if len(bus.events) != 1 || bus.events[0].Type != entity.EventServiceUpdated {
+		t.Fatalf("Expected one %s event, got %v", entity.EventServiceUpdated, bus.events)
+	}
+	if bus.events[0].Previous.BaseURL != "http://orders" || bus.events[0].Service.BaseURL != "http://orders-v2" {
+		t.Errorf("Expected event to carry previous and current definitions")
+	}
+
+	expected := []string{"svc-1:/api/v1/orders:GET", "svc-1:/api/v1/orders:HEAD", "svc-1:/api/v1/orders:GET:flag:composite-orders"}
+	if len(cache.deleted) != len(expected) {
+		t.Fatalf("Expected %d invalidated keys, got %v", len(expected), cache.deleted)
+	}
+	for i, key := range expected {
+		if cache.deleted[i] != key {
+			t.Errorf("Expected invalidated key %s, got %s", key, cache.deleted[i])
+		}
+	}
+
+	// Responses to requests with a query are cleared by pattern, once per path and method
+	expected = []string{`svc-1:/api/v1/orders:GET\?*`, `svc-1:/api/v1/orders:HEAD\?*`}
+	if len(cache.cleared) != len(expected) {
+		t.Fatalf("Expected %d cleared patterns, got %v", len(expected), cache.cleared)
+	}
+	for i, pattern := range expected {
+		if cache.cleared[i] != pattern {
+			t.Errorf("Expected cleared pattern %s, got %s", pattern, cache.cleared[i])
+		}
+	}
+}
+
+func TestServiceUseCase_UpdateInvalidatesExperimentVariants(t *testing.T) {
+	ctx := context.Background()
+	repo := mock.NewServiceRepositoryMock()
+	bus := &syncBus{}
+	cache := &recordingCache{}
+	SubscribeCacheInvalidation(bus, cache, &MockLogger{})
+	useCase := NewServiceUseCase(repo, nil, bus)
+
+	experiment := &entity.Experiment{Name: "checkout", Variants: []entity.ExperimentVariant{
+		{Name: "control", Weight: 1},
+		{Name: "one-click", Weight: 1, BaseURL: "http://checkout-v2"},
+	}}
+	svc := &entity.Service{
+		ID:        "svc-1",
+		Name:      "orders",
+		BaseURL:   "http://orders",
+		Endpoints: []entity.Endpoint{{Path: "/api/v1/checkout", Methods: []string{"GET"}, Experiment: experiment}},
+	}
+	if err := repo.Create(ctx, svc); err != nil {
+		t.Fatalf("Failed to create service: %v", err)
+	}
+
+	_, err := useCase.UpdateService(ctx, "svc-1", &dto.UpdateServiceRequest{
+		Name:      "orders",
+		BaseURL:   "http://orders",
+		Endpoints: []dto.EndpointConfig{{Path: "/api/v1/checkout", Methods: []string{"GET"}}},
+	})
+	if err != nil {
+		t.Fatalf("Failed to update service: %v", err)
+	}
+
+	evicted := map[string]bool{}
+	for _, key := range cache.deleted {
+		evicted[key] = true
+	}
+	for _, variant := range experiment.Variants {
+		key := variantCacheKey(responseCacheKey("svc-1", "/api/v1/checkout", "GET"), variant.Name)
+		if !evicted[key] {
+			t.Errorf("Expected the responses of variant %s to be evicted, got %v", variant.Name, cache.deleted)
+		}
+	}
+}
+
+func TestServiceUseCase_DeleteInvalidatesPrefixEndpoints(t *testing.T) {
+	ctx := context.Background()
+	repo := mock.NewServiceRepositoryMock()
+	bus := &syncBus{}
+	cache := &recordingCache{}
+	SubscribeCacheInvalidation(bus, cache, &MockLogger{})
+	useCase := NewServiceUseCase(repo, nil, bus)
+
+	svc := &entity.Service{
+		ID:      "svc-1",
+		Name:    "files",
+		BaseURL: "http://files",
+		Endpoints: []entity.Endpoint{
+			{Path: "/files/*", Methods: []string{"GET"}},
+			{Path: "/api/v1/files", Methods: []string{"*"}},
+		},
+	}
+	if err := repo.Create(ctx, svc); err != nil {
+		t.Fatalf("Failed to create service: %v", err)
+	}
+	if err := useCase.DeleteService(ctx, "svc-1", 0); err != nil {
+		t.Fatalf("Failed to delete service: %v", err)
+	}
+
+	// Responses are cached under the path and method requested, so they are matched by pattern
+	expected := []string{"svc-1:/files/*:GET*", "svc-1:/api/v1/files:*"}
+	if len(cache.cleared) != len(expected) {
+		t.Fatalf("Expected %d cleared patterns, got %v", len(expected), cache.cleared)
+	}
+	for i, pattern := range expected {
+		if cache.cleared[i] != pattern {
+			t.Errorf("Expected cleared pattern %s, got %s", pattern, cache.cleared[i])
+		}
+	}
+	if len(cache.deleted) != 0 {
+		t.Errorf("Expected no key of a wildcard endpoint to be deleted, got %v", cache.deleted)
+	}
+}
+
+func TestSubscribeRemoteReload(t *testing.T) {
+	ctx := context.Background()
+	bus := &syncBus{}
+	reloads := 0
+	SubscribeRemoteReload(bus, func() error {
+		reloads++
+		return nil
+	}, &MockLogger{})
+
+	// Only configuration changes made on other instances reload
+	bus.Publish(ctx, &entity.Event{Type: entity.EventServiceUpdated})
+	bus.Publish(ctx, &entity.Event{Type: entity.EventServiceDeleted, ServiceID: "svc-1", Remote: true})
+	bus.Publish(ctx, &entity.Event{Type: entity.EventAPIKeyApproved, Remote: true})
+	if reloads != 1 {
+		t.Errorf("Expected 1 reload, got %d", reloads)
+	}
+
+	// The reload is published, which does not reload again
+	if len(bus.events) != 4 || bus.events[2].Type != entity.EventRoutesReloaded || bus.events[2].ServiceID != "svc-1" {
+		t.Errorf("Expected a %s event after the reload, got %v", entity.EventRoutesReloaded, bus.events)
+	}
+}
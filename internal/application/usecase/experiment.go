@@ -0,0 +1,118 @@
+package usecase
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"api-gateway-sample/internal/domain/entity"
+	"api-gateway-sample/pkg/logger"
+)
+
+// HeaderExperimentVariant tells services which variant of the endpoint's experiment to serve
+const HeaderExperimentVariant = "X-Experiment-Variant"
+
+// defaultExperimentCookieMaxAge is how long bucketing cookies last unless configured otherwise
+const defaultExperimentCookieMaxAge = 30 * 24 * time.Hour
+
+// maxBucketingIDLength bounds the bucketing IDs accepted from clients' cookies
+const maxBucketingIDLength = 64
+
+// SetExperiments writes the exposures of callers to the variants of experiments to the given
+// logger, which should not be sampled, and keeps anonymous callers in their variants for
+// cookieMaxAge. Exposures are written to the application log until it is set.
+func (uc *ProxyUseCase) SetExperiments(exposures logger.Logger, cookieMaxAge time.Duration) {
+	uc.exposures = exposures
+	uc.experimentCookieMaxAge = cookieMaxAge
+}
+
+// assignVariant assigns the caller of an endpoint running an experiment to a variant, sets it on
+// the request and in its HeaderExperimentVariant header, and logs the exposure. It returns the service as served to the variant, and the
+// cookie to set on the response when the caller was given a new bucketing ID.
+func (uc *ProxyUseCase) assignVariant(ctx context.Context, request *entity.Request, service *entity.Service, endpoint *entity.Endpoint) (*entity.Service, *http.Cookie) {
+	experiment := endpoint.Experiment
+	stickyBy := entity.ExperimentStickyCookie
+	var bucketingID string
+	var cookie *http.Cookie
+	if principal, ok := entity.PrincipalFromContext(ctx); ok && experiment.StickyByUser() && principal.UserID != "" {
+		stickyBy, bucketingID = entity.ExperimentStickyUser, principal.UserID
+	} else if bucketingID = sentBucketingID(request, experiment.CookieName()); bucketingID == "" {
+		bucketingID = entity.NewRequestID()
+		cookie = uc.newBucketingCookie(request, experiment.CookieName(), bucketingID)
+	}
+
+	variant := experiment.Assign(bucketingID)
+	if variant == nil {
+		return service, cookie
+	}
+	request.Variant = variant.Name
+	if request.Headers == nil {
+		request.Headers = make(map[string][]string)
+	}
+	request.Headers[HeaderExperimentVariant] = []string{variant.Name}
+
+	exposures := uc.exposures
+	if exposures == nil {
+		exposures = logger.FromContextOr(ctx, uc.logger)
+	}
+	exposures.Info("Experiment exposure",
+		"experiment", experiment.Name,
+		"variant", variant.Name,
+		"sticky_by", stickyBy,
+		"bucketing_id", bucketingID,
+		"service", service.Name,
+		"endpoint", endpoint.Path,
+		"request_id", request.ID,
+	)
+
+	if variant.BaseURL == "" {
+		return service, cookie
+	}
+	served := *service
+	served.BaseURL = variant.BaseURL
+	return &served, cookie
+}
+
+// sentBucketingID returns the bucketing ID a client sent in the cookie of an experiment, empty
+// when it sent none or one the gateway could not have given it
+func sentBucketingID(request *entity.Request, name string) string {
+	cookie, err := (&http.Request{Header: http.Header(request.Headers)}).Cookie(name)
+	if err != nil || cookie.Value == "" || len(cookie.Value) > maxBucketingIDLength {
+		return ""
+	}
+	return cookie.Value
+}
+
+// newBucketingCookie returns the cookie keeping a client in the variants of an experiment
+func (uc *ProxyUseCase) newBucketingCookie(request *entity.Request, name string, bucketingID string) *http.Cookie {
+	maxAge := uc.experimentCookieMaxAge
+	if maxAge <= 0 {
+		maxAge = defaultExperimentCookieMaxAge
+	}
+	return &http.Cookie{
+		Name:     name,
+		Value:    bucketingID,
+		Path:     "/",
+		MaxAge:   int(maxAge.Seconds()),
+		Secure:   request.Scheme == "https",
+		HttpOnly: true,
+		SameSite: http.SameSiteLaxMode,
+	}
+}
+
+// withCookie returns a copy of a response setting a cookie, as responses may be shared with
+// other callers
+func withCookie(response *entity.Response, cookie *http.Cookie) *entity.Response {
+	response = copyResponse(response)
+	response.Headers["Set-Cookie"] = append(response.Headers["Set-Cookie"], cookie.String())
+	return response
+}
+
+// variantCacheKey scopes the cache key of a response to the caller's experiment variant, so
+// that responses are never served to callers of another variant
+func variantCacheKey(key string, variant string) string {
+	if variant == "" {
+		return key
+	}
+	return key + ":variant:" + variant
+}
@@ -0,0 +1,119 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+
+	"api-gateway-sample/internal/domain/entity"
+)
+
+// variantGateway records the upstream and variant header of the requests it routes
+type variantGateway struct {
+	countingGateway
+	targets  []string
+	variants []string
+}
+
+func (g *variantGateway) TransformRequest(ctx context.Context, request *entity.Request, service *entity.Service) (*entity.Request, error) {
+	g.targets = append(g.targets, service.BaseURL)
+	g.variants = append(g.variants, http.Header(request.Headers).Get(HeaderExperimentVariant))
+	return request, nil
+}
+
+// exposureLogger records the exposures logged
+type exposureLogger struct {
+	MockLogger
+	exposures [][]interface{}
+}
+
+func (l *exposureLogger) Info(msg string, args ...interface{}) {
+	if msg == "Experiment exposure" {
+		l.exposures = append(l.exposures, args)
+	}
+}
+
+func TestProxyUseCase_Experiments(t *testing.T) {
+	ctx := context.Background()
+	service := newTestService("orders", entity.Endpoint{Path: "/api/v1/checkout", Methods: []string{http.MethodGet}, Experiment: &entity.Experiment{
+		Name: "checkout",
+		Variants: []entity.ExperimentVariant{
+			{Name: "control", Weight: 1},
+			{Name: "one-click", Weight: 1, BaseURL: "http://checkout-v2:8080"},
+		},
+	}})
+	serviceRepo := newServiceRepository(t, service)
+
+	statuses := make([]int, 100)
+	for i := range statuses {
+		statuses[i] = http.StatusOK
+	}
+	gateway := &variantGateway{countingGateway: countingGateway{statuses: statuses}}
+	exposures := &exposureLogger{}
+	useCase := NewProxyUseCase(serviceRepo, gateway, nil, nil, nil, &MockLogger{})
+	useCase.SetExperiments(exposures, 0)
+	proxy := func(ctx context.Context, cookie string) *entity.Response {
+		t.Helper()
+		headers := map[string][]string{}
+		if cookie != "" {
+			headers["Cookie"] = []string{cookie}
+		}
+		request := entity.NewRequest(http.MethodGet, "/api/v1/checkout", headers, map[string][]string{}, nil, "10.0.0.1")
+		response, err := useCase.ProxyRequest(ctx, request)
+		if err != nil {
+			t.Fatalf("Failed to proxy request: %v", err)
+		}
+		return response
+	}
+
+	// 1. New callers are given a bucketing ID in a cookie, and keep their variant with it
+	response := proxy(ctx, "")
+	setCookie := response.Headers["Set-Cookie"]
+	if len(setCookie) != 1 || !strings.HasPrefix(setCookie[0], "exp_checkout=") || !strings.Contains(setCookie[0], "Max-Age=2592000") {
+		t.Fatalf("Expected a bucketing cookie, got %v", setCookie)
+	}
+	cookie := strings.SplitN(setCookie[0], ";", 2)[0]
+	for i := 0; i < 3; i++ {
+		if response := proxy(ctx, cookie); len(response.Headers["Set-Cookie"]) != 0 {
+			t.Errorf("Expected no cookie for a bucketed caller, got %v", response.Headers["Set-Cookie"])
+		}
+	}
+	for i := 1; i < len(gateway.variants); i++ {
+		if gateway.variants[i] != gateway.variants[0] || gateway.targets[i] != gateway.targets[0] {
+			t.Errorf("Expected the caller to keep its variant, got %v to %v", gateway.variants, gateway.targets)
+		}
+	}
+	if len(exposures.exposures) != 4 {
+		t.Errorf("Expected an exposure for each request, got %d", len(exposures.exposures))
+	}
+
+	// 2. Callers are split between the variants, each served by its upstream
+	targets := map[string]string{}
+	for i := 0; i < 40; i++ {
+		proxy(ctx, fmt.Sprintf("exp_checkout=client-%d", i))
+		targets[gateway.variants[len(gateway.variants)-1]] = gateway.targets[len(gateway.targets)-1]
+	}
+	if targets["control"] != "http://orders:8080" || targets["one-click"] != "http://checkout-v2:8080" {
+		t.Errorf("Expected both variants to be served by their upstreams, got %v", targets)
+	}
+
+	// 3. Callers may be bucketed by user ID, without a cookie
+	service.Endpoints[0].Experiment.StickyBy = entity.ExperimentStickyUser
+	if err := serviceRepo.Update(ctx, service); err != nil {
+		t.Fatalf("Failed to update service: %v", err)
+	}
+	userCtx := entity.ContextWithPrincipal(ctx, &entity.Principal{UserID: "alice"})
+	first := len(gateway.variants)
+	for i := 0; i < 3; i++ {
+		if response := proxy(userCtx, fmt.Sprintf("exp_checkout=client-%d", i)); len(response.Headers["Set-Cookie"]) != 0 {
+			t.Errorf("Expected no cookie for an authenticated caller, got %v", response.Headers["Set-Cookie"])
+		}
+	}
+	for _, variant := range gateway.variants[first:] {
+		if variant != gateway.variants[first] {
+			t.Errorf("Expected the user to keep its variant, got %v", gateway.variants[first:])
+		}
+	}
+}
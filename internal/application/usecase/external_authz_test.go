@@ -0,0 +1,39 @@
+package usecase
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"api-gateway-sample/internal/domain/entity"
+)
+
+// stubAuthorizer allows every request with fixed upstream headers
+type stubAuthorizer struct {
+	upstreamHeaders map[string]string
+}
+
+func (a *stubAuthorizer) Check(ctx context.Context, request *entity.Request, service *entity.Service, endpoint *entity.Endpoint) (*entity.AuthzDecision, error) {
+	return &entity.AuthzDecision{Allowed: true, StatusCode: http.StatusOK, UpstreamHeaders: a.upstreamHeaders}, nil
+}
+
+func TestProxyUseCase_ExternalAuthorizationStripsUpstreamHeaders(t *testing.T) {
+	orders := newTestService("orders", entity.Endpoint{Path: "/api/v1/orders", Methods: []string{http.MethodGet}, AuthRequired: true})
+	gateway := &capturingGateway{countingGateway: countingGateway{statuses: []int{http.StatusOK}}}
+	useCase := newProxyFixture(t, gateway, orders)
+	// The authorizer only sets X-User-Id, leaving the roles of the request unset
+	useCase.SetExternalAuthorizer(&stubAuthorizer{upstreamHeaders: map[string]string{"X-User-ID": "alice"}}, []string{"X-User-ID", "X-User-Roles"})
+
+	headers := map[string][]string{"X-User-Id": {"admin"}, "X-User-Roles": {"admin"}}
+	if _, err := useCase.ProxyRequest(context.Background(), entity.NewRequest(http.MethodGet, "/api/v1/orders", headers, nil, nil, "10.0.0.1")); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	forwarded := gateway.requests[0].Headers
+	if got := forwarded["X-User-Id"]; len(got) != 1 || got[0] != "alice" {
+		t.Errorf("Expected X-User-Id alice, got %v", got)
+	}
+	if got, ok := forwarded["X-User-Roles"]; ok {
+		t.Errorf("Expected the client X-User-Roles to be removed, got %v", got)
+	}
+}
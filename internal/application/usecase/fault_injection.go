@@ -0,0 +1,147 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"sort"
+	"sync"
+	"time"
+
+	"api-gateway-sample/internal/domain/entity"
+	"api-gateway-sample/internal/domain/repository"
+	"api-gateway-sample/pkg/errors"
+	"api-gateway-sample/pkg/logger"
+)
+
+// FaultUseCase implements the use case for injecting faults into the requests to endpoints, so
+// that clients can be tested against a slow or failing backend. Faults are kept in memory by
+// each gateway instance and are lost on restart.
+type FaultUseCase struct {
+	serviceRepo repository.ServiceRepository
+	logger      logger.Logger
+
+	mu     sync.RWMutex
+	faults map[faultKey]*entity.Fault
+	// random returns a number in [0, 1) drawn for each fault of each request
+	random func() float64
+}
+
+// faultKey identifies the endpoint of a service a fault applies to
+type faultKey struct {
+	serviceID string
+	endpoint  string
+}
+
+// NewFaultUseCase creates a new FaultUseCase instance
+func NewFaultUseCase(serviceRepo repository.ServiceRepository, logger logger.Logger) *FaultUseCase {
+	return &FaultUseCase{
+		serviceRepo: serviceRepo,
+		logger:      logger,
+		faults:      make(map[faultKey]*entity.Fault),
+		random:      rand.Float64,
+	}
+}
+
+// SetFaultInjection injects the faults configured through the given use case into proxied requests
+func (uc *ProxyUseCase) SetFaultInjection(faults *FaultUseCase) {
+	uc.faults = faults
+}
+
+// SetFault injects a fault into the requests to an endpoint of a service, replacing its current fault
+func (uc *FaultUseCase) SetFault(ctx context.Context, fault *entity.Fault) error {
+	if err := fault.Validate(); err != nil {
+		return errors.NewError(errors.CodeInvalidInput, err.Error(), errors.ErrInvalidInput)
+	}
+
+	service, err := uc.serviceRepo.Get(ctx, fault.ServiceID)
+	if err != nil {
+		return err
+	}
+	if !hasEndpoint(service, fault.Endpoint) {
+		return errors.NewError(errors.CodeInvalidInput, fmt.Sprintf("service has no endpoint %s", fault.Endpoint), errors.ErrInvalidInput)
+	}
+
+	uc.mu.Lock()
+	uc.faults[faultKey{serviceID: fault.ServiceID, endpoint: fault.Endpoint}] = fault
+	uc.mu.Unlock()
+	logger.FromContextOr(ctx, uc.logger).Warn("Fault injection enabled", "service_id", fault.ServiceID, "endpoint", fault.Endpoint)
+	return nil
+}
+
+// RemoveFaults stops injecting faults into an endpoint of a service, or into all its endpoints
+// when endpoint is empty
+func (uc *FaultUseCase) RemoveFaults(ctx context.Context, serviceID string, endpoint string) {
+	uc.mu.Lock()
+	defer uc.mu.Unlock()
+
+	for key := range uc.faults {
+		if key.serviceID == serviceID && (endpoint == "" || key.endpoint == endpoint) {
+			delete(uc.faults, key)
+		}
+	}
+	logger.FromContextOr(ctx, uc.logger).Info("Fault injection disabled", "service_id", serviceID, "endpoint", endpoint)
+}
+
+// ListFaults returns the faults being injected, sorted by service and endpoint
+func (uc *FaultUseCase) ListFaults() []*entity.Fault {
+	uc.mu.RLock()
+	defer uc.mu.RUnlock()
+
+	faults := make([]*entity.Fault, 0, len(uc.faults))
+	for _, fault := range uc.faults {
+		faults = append(faults, fault)
+	}
+	sort.Slice(faults, func(i, j int) bool {
+		if faults[i].ServiceID != faults[j].ServiceID {
+			return faults[i].ServiceID < faults[j].ServiceID
+		}
+		return faults[i].Endpoint < faults[j].Endpoint
+	})
+	return faults
+}
+
+// inject applies the fault of an endpoint to a request: it may be delayed, and then either have
+// its connection reset or be answered with the abort status instead of being forwarded
+func (uc *FaultUseCase) inject(ctx context.Context, service *entity.Service, endpoint *entity.Endpoint) error {
+	uc.mu.RLock()
+	fault, ok := uc.faults[faultKey{serviceID: service.ID, endpoint: endpoint.Path}]
+	var delay, reset, abort bool
+	if ok {
+		delay = uc.random() < fault.DelayProbability
+		reset = uc.random() < fault.ResetProbability
+		abort = uc.random() < fault.AbortProbability
+	}
+	uc.mu.RUnlock()
+	if !ok {
+		return nil
+	}
+
+	if delay {
+		timer := time.NewTimer(fault.Delay())
+		defer timer.Stop()
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+
+	switch {
+	case reset:
+		return errors.NewError(errors.CodeBadGateway, "injected connection reset", errors.ErrConnectionReset)
+	case abort:
+		return errors.NewError(fault.AbortStatus, "injected fault", nil)
+	}
+	return nil
+}
+
+// hasEndpoint reports whether a service declares an endpoint with the given path
+func hasEndpoint(service *entity.Service, path string) bool {
+	for i := range service.Endpoints {
+		if service.Endpoints[i].Path == path {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,79 @@
+package usecase
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"api-gateway-sample/internal/domain/entity"
+	"api-gateway-sample/pkg/errors"
+)
+
+func TestProxyUseCase_FaultInjection(t *testing.T) {
+	ctx := context.Background()
+	service := newTestService("orders", entity.Endpoint{Path: "/api/v1/orders", Methods: []string{http.MethodGet}})
+	serviceRepo := newServiceRepository(t, service)
+
+	gateway := &countingGateway{statuses: []int{http.StatusOK, http.StatusOK}}
+	faults := NewFaultUseCase(serviceRepo, &MockLogger{})
+	useCase := NewProxyUseCase(serviceRepo, gateway, nil, nil, nil, &MockLogger{})
+	useCase.SetFaultInjection(faults)
+	newRequest := func() *entity.Request {
+		return entity.NewRequest(http.MethodGet, "/api/v1/orders", map[string][]string{}, map[string][]string{}, nil, "10.0.0.1")
+	}
+
+	// 1. Faults can only target endpoints the service declares
+	if err := faults.SetFault(ctx, &entity.Fault{ServiceID: service.ID, Endpoint: "/api/v1/carts", AbortStatus: 503, AbortProbability: 1}); !errors.IsInvalidInput(err) {
+		t.Errorf("Expected an unknown endpoint to be rejected, got %v", err)
+	}
+
+	// 2. Draws below the probability inject the fault
+	fault := &entity.Fault{
+		ServiceID:        service.ID,
+		Endpoint:         "/api/v1/orders",
+		DelayMs:          20,
+		DelayProbability: 1,
+		AbortStatus:      http.StatusServiceUnavailable,
+		AbortProbability: 0.5,
+	}
+	if err := faults.SetFault(ctx, fault); err != nil {
+		t.Fatalf("Failed to set fault: %v", err)
+	}
+	faults.random = func() float64 { return 0.25 }
+	start := time.Now()
+	if _, err := useCase.ProxyRequest(ctx, newRequest()); errors.StatusCodeOf(err, 0) != http.StatusServiceUnavailable {
+		t.Errorf("Expected an injected 503, got %v", err)
+	}
+	if elapsed := time.Since(start); elapsed < 20*time.Millisecond {
+		t.Errorf("Expected the request to be delayed by 20ms, took %v", elapsed)
+	}
+	if gateway.calls != 0 {
+		t.Errorf("Expected the aborted request not to reach the backend, got %d calls", gateway.calls)
+	}
+
+	// 3. Connection resets are reported for the handler to close the connection
+	fault.ResetProbability = 0.5
+	if err := faults.SetFault(ctx, fault); err != nil {
+		t.Fatalf("Failed to set fault: %v", err)
+	}
+	if _, err := useCase.ProxyRequest(ctx, newRequest()); !errors.IsConnectionReset(err) {
+		t.Errorf("Expected an injected connection reset, got %v", err)
+	}
+
+	// 4. Draws above the probabilities forward the request after the delay
+	faults.random = func() float64 { return 0.75 }
+	if _, err := useCase.ProxyRequest(ctx, newRequest()); err != nil {
+		t.Errorf("Expected the request to be forwarded, got %v", err)
+	}
+
+	// 5. Removed faults are no longer injected
+	faults.RemoveFaults(ctx, service.ID, "")
+	faults.random = func() float64 { return 0 }
+	if _, err := useCase.ProxyRequest(ctx, newRequest()); err != nil {
+		t.Errorf("Expected the request to be forwarded once the fault is removed, got %v", err)
+	}
+	if gateway.calls != 2 || len(faults.ListFaults()) != 0 {
+		t.Errorf("Expected 2 requests to reach the backend and no faults left, got %d calls and %d faults", gateway.calls, len(faults.ListFaults()))
+	}
+}
@@ -0,0 +1,232 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"api-gateway-sample/internal/application/dto"
+	"api-gateway-sample/internal/domain/entity"
+	"api-gateway-sample/internal/domain/repository"
+	"api-gateway-sample/internal/domain/service"
+	"api-gateway-sample/pkg/errors"
+	"api-gateway-sample/pkg/logger"
+)
+
+// featureFlagsCacheKey is the cache key of the feature flags
+const featureFlagsCacheKey = "feature-flags"
+
+// FeatureFlagUseCase manages the feature flags of the built-in flag store and evaluates them
+// as a FeatureFlagProvider. The segment of a flag is an authorization policy expression
+// evaluated against the caller. Flags are stored in the repository and cached, so that
+// requests do not query the repository.
+type FeatureFlagUseCase struct {
+	flagRepo     repository.FeatureFlagRepository
+	policyEngine service.PolicyEngine
+	cache        repository.CacheRepository
+	cacheTTL     time.Duration
+	logger       logger.Logger
+}
+
+// NewFeatureFlagUseCase creates a new FeatureFlagUseCase instance. The flags are cached for
+// cacheTTL; changes made through the use case take effect at once.
+func NewFeatureFlagUseCase(
+	flagRepo repository.FeatureFlagRepository,
+	policyEngine service.PolicyEngine,
+	cache repository.CacheRepository,
+	cacheTTL time.Duration,
+	logger logger.Logger,
+) *FeatureFlagUseCase {
+	return &FeatureFlagUseCase{
+		flagRepo:     flagRepo,
+		policyEngine: policyEngine,
+		cache:        cache,
+		cacheTTL:     cacheTTL,
+		logger:       logger,
+	}
+}
+
+// SetFeatureFlags serves the endpoints behind a feature flag only to the callers the provider
+// turns the flag on for
+func (uc *ProxyUseCase) SetFeatureFlags(flags service.FeatureFlagProvider) {
+	uc.flags = flags
+}
+
+// CreateFlag creates a feature flag
+func (uc *FeatureFlagUseCase) CreateFlag(ctx context.Context, req *dto.FeatureFlagRequest) (*entity.FeatureFlag, error) {
+	flag := req.ToEntity()
+	if err := uc.checkFlag(flag); err != nil {
+		return nil, err
+	}
+	if _, err := uc.flagRepo.Get(ctx, flag.Key); err == nil {
+		return nil, errors.ErrAlreadyExists
+	} else if !errors.IsNotFound(err) {
+		return nil, err
+	}
+
+	flag.CreatedAt = time.Now()
+	flag.UpdatedAt = flag.CreatedAt
+	if err := uc.flagRepo.Create(ctx, flag); err != nil {
+		return nil, err
+	}
+	uc.invalidate(ctx)
+	return flag, nil
+}
+
+// UpdateFlag replaces a feature flag; its key cannot be changed
+func (uc *FeatureFlagUseCase) UpdateFlag(ctx context.Context, key string, req *dto.FeatureFlagRequest) (*entity.FeatureFlag, error) {
+	existing, err := uc.flagRepo.Get(ctx, key)
+	if err != nil {
+		return nil, err
+	}
+
+	flag := req.ToEntity()
+	flag.Key = existing.Key
+	if err := uc.checkFlag(flag); err != nil {
+		return nil, err
+	}
+
+	flag.CreatedAt = existing.CreatedAt
+	flag.UpdatedAt = time.Now()
+	if err := uc.flagRepo.Update(ctx, flag); err != nil {
+		return nil, err
+	}
+	uc.invalidate(ctx)
+	return flag, nil
+}
+
+// GetFlag retrieves a feature flag by key
+func (uc *FeatureFlagUseCase) GetFlag(ctx context.Context, key string) (*entity.FeatureFlag, error) {
+	return uc.flagRepo.Get(ctx, key)
+}
+
+// ListFlags retrieves all feature flags
+func (uc *FeatureFlagUseCase) ListFlags(ctx context.Context) ([]*entity.FeatureFlag, error) {
+	flags, err := uc.flagRepo.GetAll(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if flags == nil {
+		flags = []*entity.FeatureFlag{}
+	}
+	return flags, nil
+}
+
+// DeleteFlag deletes a feature flag by key. Endpoints still behind it are no longer served to
+// anyone until it is created again.
+func (uc *FeatureFlagUseCase) DeleteFlag(ctx context.Context, key string) error {
+	if err := uc.flagRepo.Delete(ctx, key); err != nil {
+		return err
+	}
+	uc.invalidate(ctx)
+	return nil
+}
+
+// Enabled reports whether a flag is on for a caller: the flag is enabled, the caller is in its
+// segment and its rollout includes the caller
+func (uc *FeatureFlagUseCase) Enabled(ctx context.Context, key string, principal *entity.Principal) (bool, error) {
+	flags, err := uc.flags(ctx)
+	if err != nil {
+		return false, err
+	}
+	flag, ok := flags[key]
+	if !ok || !flag.Enabled {
+		return false, nil
+	}
+
+	var userID string
+	if principal != nil {
+		userID = principal.UserID
+	}
+	if flag.Segment != "" {
+		input := entity.NewPolicyInput(principal, &entity.Request{}, &entity.Service{}, &entity.Endpoint{})
+		inSegment, err := uc.policyEngine.Evaluate(ctx, flag.Segment, input)
+		if err != nil || !inSegment {
+			return false, err
+		}
+	}
+	return flag.RolledOutTo(userID), nil
+}
+
+// flags returns the flags by key from the cache, loading and caching them on a miss
+func (uc *FeatureFlagUseCase) flags(ctx context.Context) (map[string]*entity.FeatureFlag, error) {
+	var flags []*entity.FeatureFlag
+	cached := uc.cache != nil && uc.cache.Get(ctx, featureFlagsCacheKey, &flags) == nil
+	if !cached {
+		var err error
+		if flags, err = uc.flagRepo.GetAll(ctx); err != nil {
+			return nil, err
+		}
+		if flags == nil {
+			flags = []*entity.FeatureFlag{}
+		}
+		if uc.cache != nil {
+			if err := uc.cache.Set(ctx, featureFlagsCacheKey, flags, uc.cacheTTL); err != nil {
+				logger.FromContextOr(ctx, uc.logger).Warn("Failed to cache feature flags", "error", err)
+			}
+		}
+	}
+
+	byKey := make(map[string]*entity.FeatureFlag, len(flags))
+	for _, flag := range flags {
+		byKey[flag.Key] = flag
+	}
+	return byKey, nil
+}
+
+// invalidate drops the cached flags so that the next request loads them
+func (uc *FeatureFlagUseCase) invalidate(ctx context.Context) {
+	if uc.cache == nil {
+		return
+	}
+	if err := uc.cache.Delete(ctx, featureFlagsCacheKey); err != nil {
+		logger.FromContextOr(ctx, uc.logger).Warn("Failed to invalidate cached feature flags", "error", err)
+	}
+}
+
+// checkFlag validates a flag and compiles its segment
+func (uc *FeatureFlagUseCase) checkFlag(flag *entity.FeatureFlag) error {
+	if err := flag.Validate(); err != nil {
+		return errors.NewError(errors.CodeInvalidInput, err.Error(), errors.ErrInvalidInput)
+	}
+	if flag.Segment != "" {
+		if err := uc.policyEngine.Compile(flag.Segment); err != nil {
+			return errors.NewError(errors.CodeInvalidInput, fmt.Sprintf("invalid segment: %v", err), errors.ErrInvalidInput)
+		}
+	}
+	return nil
+}
+
+// flaggedEndpoints returns whether each endpoint behind a feature flag is served to the caller
+// in the context, evaluating each flag once. Flags that cannot be evaluated are off.
+func (uc *ProxyUseCase) flaggedEndpoints(ctx context.Context) func(*entity.Endpoint) bool {
+	if uc.flags == nil {
+		return nil
+	}
+	principal, _ := entity.PrincipalFromContext(ctx)
+	evaluated := make(map[string]bool)
+	return func(endpoint *entity.Endpoint) bool {
+		if endpoint.FeatureFlag == "" {
+			return true
+		}
+		enabled, ok := evaluated[endpoint.FeatureFlag]
+		if !ok {
+			var err error
+			enabled, err = uc.flags.Enabled(ctx, endpoint.FeatureFlag, principal)
+			if err != nil {
+				logger.FromContextOr(ctx, uc.logger).Warn("Failed to evaluate feature flag", "flag", endpoint.FeatureFlag, "error", err)
+			}
+			evaluated[endpoint.FeatureFlag] = enabled
+		}
+		return enabled
+	}
+}
+
+// flaggedCacheKey returns the cache key of the responses of an endpoint, apart from those of
+// the endpoint serving the same route to the callers its feature flag is off for
+func flaggedCacheKey(key string, endpoint *entity.Endpoint) string {
+	if endpoint.FeatureFlag == "" {
+		return key
+	}
+	return key + ":flag:" + endpoint.FeatureFlag
+}
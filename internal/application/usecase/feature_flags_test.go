@@ -0,0 +1,110 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"api-gateway-sample/internal/application/dto"
+	"api-gateway-sample/internal/domain/entity"
+	"api-gateway-sample/internal/domain/repository/mock"
+	"api-gateway-sample/pkg/errors"
+)
+
+// rolePolicyEngine allows the principals holding the role a policy names
+type rolePolicyEngine struct{}
+
+func (rolePolicyEngine) Compile(policy string) error {
+	if policy == "" || policy[0] == '(' {
+		return fmt.Errorf("syntax error")
+	}
+	return nil
+}
+
+func (rolePolicyEngine) Evaluate(ctx context.Context, policy string, input *entity.PolicyInput) (bool, error) {
+	for _, role := range input.Roles {
+		if role == policy {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func TestProxyUseCase_FeatureFlags(t *testing.T) {
+	ctx := context.Background()
+	service := newTestService("orders",
+		entity.Endpoint{Path: "/api/v1/orders", Methods: []string{http.MethodGet}},
+		entity.Endpoint{Path: "/api/v1/orders", Methods: []string{http.MethodGet}, Priority: 1, FeatureFlag: "composite-orders"},
+	)
+
+	flags := NewFeatureFlagUseCase(mock.NewFeatureFlagRepositoryMock(), rolePolicyEngine{}, &jsonCache{entries: map[string][]byte{}}, time.Minute, &MockLogger{})
+	useCase := newProxyFixture(t, &countingGateway{}, service)
+	useCase.SetFeatureFlags(flags)
+	resolve := func(principal *entity.Principal) *entity.Endpoint {
+		t.Helper()
+		_, endpoint, err := useCase.ResolveEndpoint(entity.ContextWithPrincipal(ctx, principal), "/api/v1/orders", http.MethodGet)
+		if err != nil {
+			t.Fatalf("Failed to resolve endpoint: %v", err)
+		}
+		return endpoint
+	}
+	employee := &entity.Principal{UserID: "alice", Roles: []string{"employee"}}
+	customer := &entity.Principal{UserID: "bob", Roles: []string{"customer"}}
+
+	// 1. Endpoints behind a flag that does not exist are not served
+	if endpoint := resolve(employee); endpoint.FeatureFlag != "" {
+		t.Errorf("Expected the unflagged endpoint without the flag, got %+v", endpoint)
+	}
+
+	// 2. Segments are compiled when flags are created
+	req := &dto.FeatureFlagRequest{Key: "composite-orders", Enabled: true, Segment: "(employee"}
+	if _, err := flags.CreateFlag(ctx, req); !errors.IsInvalidInput(err) {
+		t.Errorf("Expected an invalid segment to be rejected, got %v", err)
+	}
+
+	// 3. Only the callers in the segment are served the flagged endpoint
+	req.Segment = "employee"
+	if _, err := flags.CreateFlag(ctx, req); err != nil {
+		t.Fatalf("Failed to create flag: %v", err)
+	}
+	if _, err := flags.CreateFlag(ctx, req); err != errors.ErrAlreadyExists {
+		t.Errorf("Expected a duplicate key to be rejected, got %v", err)
+	}
+	if endpoint := resolve(employee); endpoint.FeatureFlag != "composite-orders" {
+		t.Errorf("Expected employees to be served the flagged endpoint, got %+v", endpoint)
+	}
+	if endpoint := resolve(customer); endpoint.FeatureFlag != "" {
+		t.Errorf("Expected customers to be served the unflagged endpoint, got %+v", endpoint)
+	}
+
+	// 4. Updates take effect at once, and a rollout of 0% serves no one
+	zero := 0
+	if _, err := flags.UpdateFlag(ctx, req.Key, &dto.FeatureFlagRequest{Enabled: true, Percentage: &zero}); err != nil {
+		t.Fatalf("Failed to update flag: %v", err)
+	}
+	if endpoint := resolve(employee); endpoint.FeatureFlag != "" {
+		t.Errorf("Expected no one to be served the flagged endpoint at 0%%, got %+v", endpoint)
+	}
+	if _, err := flags.UpdateFlag(ctx, req.Key, &dto.FeatureFlagRequest{Enabled: true}); err != nil {
+		t.Fatalf("Failed to update flag: %v", err)
+	}
+	if endpoint := resolve(customer); endpoint.FeatureFlag != "composite-orders" {
+		t.Errorf("Expected everyone to be served the flagged endpoint, got %+v", endpoint)
+	}
+
+	// 5. Disabled and deleted flags are off
+	if _, err := flags.UpdateFlag(ctx, req.Key, &dto.FeatureFlagRequest{}); err != nil {
+		t.Fatalf("Failed to update flag: %v", err)
+	}
+	if endpoint := resolve(employee); endpoint.FeatureFlag != "" {
+		t.Errorf("Expected the disabled flag to be off, got %+v", endpoint)
+	}
+	if err := flags.DeleteFlag(ctx, req.Key); err != nil {
+		t.Fatalf("Failed to delete flag: %v", err)
+	}
+	if _, err := flags.GetFlag(ctx, req.Key); !errors.IsNotFound(err) {
+		t.Errorf("Expected the deleted flag to be gone, got %v", err)
+	}
+}
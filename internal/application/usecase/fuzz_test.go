@@ -0,0 +1,75 @@
+package usecase
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+
+	"api-gateway-sample/internal/domain/entity"
+)
+
+// The fuzz targets run their seeds with go test; explore further with, for example,
+// go test -run '^$' -fuzz FuzzResolveEndpoint -fuzztime 30s ./internal/application/usecase/
+
+func FuzzResolveEndpoint(f *testing.F) {
+	ctx := context.Background()
+	orders := newTestService("orders",
+		entity.Endpoint{Path: "/api/v1/orders", Methods: []string{http.MethodGet, http.MethodPost}},
+		entity.Endpoint{Path: "/api/v1/orders/*", Methods: []string{"*"}},
+		entity.Endpoint{Path: "/*", Methods: []string{http.MethodGet}, Priority: -1},
+	)
+	useCase := newProxyFixture(f, nil, orders)
+
+	for _, seed := range []string{"/api/v1/orders", "/api/v1/orders/42/items", "/", "", "//", "/api/v1/orders/*", "/%2e%2e/", "/api/v1/ordersx", "no-slash", "/\x00/\xff"} {
+		f.Add(seed, http.MethodGet)
+	}
+	f.Add("/api/v1/orders/", "DELETE")
+
+	f.Fuzz(func(t *testing.T, path string, method string) {
+		patterns := routePatterns(path)
+		if patterns[0] != path {
+			t.Fatalf("First pattern of %q is %q", path, patterns[0])
+		}
+		for _, pattern := range patterns[1:] {
+			if !strings.HasSuffix(pattern, "/*") || !strings.HasPrefix(path, strings.TrimSuffix(pattern, "*")) {
+				t.Fatalf("Pattern %q does not serve %q", pattern, path)
+			}
+		}
+
+		_, endpoint, err := useCase.ResolveEndpoint(ctx, path, method)
+		if err != nil {
+			return
+		}
+		if endpoint.Path != path && !(strings.HasSuffix(endpoint.Path, "/*") && strings.HasPrefix(path, strings.TrimSuffix(endpoint.Path, "*"))) {
+			t.Fatalf("Endpoint %q matched path %q", endpoint.Path, path)
+		}
+		if methodRank(endpoint, method) == 0 {
+			t.Fatalf("Endpoint %q does not serve %q", endpoint.Path, method)
+		}
+	})
+}
+
+func FuzzResolveTemplateReferences(f *testing.F) {
+	f.Add("body.customer.id", `{"customer":{"id":42}}`, "type=order", "X-Tenant-ID")
+	f.Add("body.items.0.sku", `{"items":[{"sku":"A-1"}]}`, "", "")
+	f.Add("body.items.-1", `{"items":[]}`, "", "")
+	f.Add("body", `{}`, "", "")
+	f.Add("body.", `[`, "", "")
+	f.Add("query.type", "", "type=order&type=refund", "")
+	f.Add("query", "", "=", "")
+	f.Add("header.X-Tenant-ID", "", "", "X-Tenant-ID")
+	f.Add("request.id", "", "", "")
+	f.Add("", "", "%zz", "")
+
+	f.Fuzz(func(t *testing.T, reference string, body string, query string, header string) {
+		// Parameters the router could not parse are dropped, as they are from requests
+		queryParams, _ := url.ParseQuery(query)
+		request := entity.NewRequest(http.MethodPost, "/api/v1/orders", map[string][]string{header: {"tenant-1"}}, queryParams, []byte(body), "10.0.0.1")
+
+		resolveSOAPReference(reference, request)
+		resolveBridgeReference(reference, request)
+		resolveMockReference(reference, request)
+	})
+}
@@ -0,0 +1,513 @@
+package usecase
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"api-gateway-sample/internal/domain/entity"
+	"api-gateway-sample/pkg/logger"
+)
+
+// staleRetention is how long a stale response with a validator is kept for revalidation
+const staleRetention = time.Hour
+
+// Kinds of ETags generated for cached responses
+const (
+	// ETagStrong marks responses with byte-identical bodies as the same representation
+	ETagStrong = "strong"
+	// ETagWeak marks them as equivalent, letting clients only use them to validate caches
+	ETagWeak = "weak"
+)
+
+// cacheableStatuses are the response statuses stored in the response cache
+var cacheableStatuses = map[int]bool{
+	http.StatusOK:                   true,
+	http.StatusNonAuthoritativeInfo: true,
+	http.StatusNoContent:            true,
+	http.StatusMultipleChoices:      true,
+	http.StatusMovedPermanently:     true,
+	http.StatusPermanentRedirect:    true,
+}
+
+// cachedResponse is a response stored in the response cache
+type cachedResponse struct {
+	Response *entity.Response `json:"response"`
+	// Date is when the upstream generated the response, corrected for the Age it arrived with
+	Date time.Time `json:"date"`
+	// FreshUntil is when the response becomes stale and must be revalidated before it is reused
+	FreshUntil time.Time `json:"freshUntil"`
+	// Negative marks a cached error response, which is never revalidated
+	Negative bool `json:"negative,omitempty"`
+	// GeneratedETag marks an ETag generated by the gateway, which clients may send back but the
+	// upstream does not know
+	GeneratedETag bool `json:"generatedETag,omitempty"`
+	// Vary holds the values of the request headers named by the response's Vary header, which
+	// requests must send to be served the response
+	Vary map[string]string `json:"vary,omitempty"`
+}
+
+// SetGeneratedETags generates an ETag of the given kind, ETagStrong or ETagWeak, for the cached
+// JSON responses of services that send none, so that clients polling them are answered 304 by
+// the gateway while the response is fresh
+func (uc *ProxyUseCase) SetGeneratedETags(kind string) {
+	uc.etags = kind
+}
+
+// conditions are the conditional headers a client sent, evaluated against cached responses
+type conditions struct {
+	ifNoneMatch     string
+	ifModifiedSince string
+	// headers are the headers the client sent, before the request is changed for the upstream,
+	// that responses are stored for
+	headers http.Header
+}
+
+// clientConditions returns the conditional headers of a request
+func clientConditions(request *entity.Request) conditions {
+	headers := http.Header(request.Headers)
+	return conditions{
+		ifNoneMatch:     headers.Get("If-None-Match"),
+		ifModifiedSince: headers.Get("If-Modified-Since"),
+		headers:         headers.Clone(),
+	}
+}
+
+// cacheLookup returns the cached response of a request and whether it can be served as is.
+// A stale response is only returned when it has a validator to revalidate it with.
+func (uc *ProxyUseCase) cacheLookup(ctx context.Context, request *entity.Request, key string) (*cachedResponse, bool) {
+	if request.Method != http.MethodGet && request.Method != http.MethodHead {
+		return nil, false
+	}
+
+	value, found, err := uc.cacheService.Get(ctx, key)
+	if err != nil || !found {
+		return nil, false
+	}
+	entry, ok := decodeCachedResponse(value)
+	if !ok || !entry.matchesVary(request.Headers) {
+		return nil, false
+	}
+
+	directives := parseCacheControl(request.Headers["Cache-Control"])
+	_, noCache := directives["no-cache"]
+	if maxAge, ok := directives["max-age"]; ok && maxAge == "0" {
+		noCache = true
+	}
+	if http.Header(request.Headers).Get("Pragma") == "no-cache" {
+		noCache = true
+	}
+	if !noCache && time.Now().Before(entry.FreshUntil) {
+		return entry, true
+	}
+	if !entry.revalidatable() {
+		return nil, false
+	}
+	return entry, false
+}
+
+// revalidate makes the request conditional on the validators of a stale cached response
+func revalidate(request *entity.Request, stale *cachedResponse) {
+	headers := http.Header(request.Headers)
+	headers.Del("If-None-Match")
+	headers.Del("If-Modified-Since")
+	if etag := stale.header("ETag"); etag != "" && !stale.GeneratedETag {
+		headers.Set("If-None-Match", etag)
+	} else if lastModified := stale.header("Last-Modified"); lastModified != "" {
+		headers.Set("If-Modified-Since", lastModified)
+	}
+}
+
+// cacheStore stores a cacheable upstream response and returns the response for the client. When
+// the upstream confirms a stale cached response with 304, the cached response is refreshed and
+// served in its place, and the returned flag is true.
+func (uc *ProxyUseCase) cacheStore(ctx context.Context, request *entity.Request, key string, endpoint *entity.Endpoint, stale *cachedResponse, client conditions, response *entity.Response) (*entity.Response, bool) {
+	log := logger.FromContextOr(ctx, uc.logger)
+	now := time.Now()
+
+	if stale != nil && response.StatusCode == http.StatusNotModified {
+		// Headers sent with 304 replace the stored ones, as they describe the same representation,
+		// and the age of the stored response restarts from the revalidation
+		if stale.Response.Headers == nil {
+			stale.Response.Headers = map[string][]string{}
+		}
+		deleteHeader(stale.Response.Headers, "Date")
+		deleteHeader(stale.Response.Headers, "Age")
+		for name, values := range response.Headers {
+			deleteHeader(stale.Response.Headers, name)
+			stale.Response.Headers[name] = values
+		}
+		entry, ok := newCachedResponse(stale.Response, endpoint, now)
+		if !ok {
+			if err := uc.cacheService.Delete(ctx, key); err != nil {
+				log.Warn("Failed to evict cached response", "error", err)
+			}
+			return stale.serve(client, now), true
+		}
+		entry.GeneratedETag = stale.GeneratedETag && headerValue(response.Headers, "ETag") == ""
+		entry.Vary = varyValues(entry.Response, client.headers)
+		uc.storeCachedResponse(ctx, key, entry, now)
+		return entry.serve(client, now), true
+	}
+
+	if _, noStore := parseCacheControl(request.Headers["Cache-Control"])["no-store"]; noStore {
+		return response, false
+	}
+	if request.Method != http.MethodGet && request.Method != http.MethodHead {
+		return response, false
+	}
+	entry, ok := newCachedResponse(response, endpoint, now)
+	if !ok {
+		return response, false
+	}
+	// Responses to authorized requests are only shared when the upstream allows it, as in RFC 9111
+	if authorizedRequest(ctx, request, endpoint, client) && !sharedWithAuthorization(response) {
+		return response, false
+	}
+	entry.Vary = varyValues(response, client.headers)
+	if uc.etags != "" && !entry.Negative && entry.header("ETag") == "" && isJSON(response) {
+		response.Headers = withHeader(response.Headers, "ETag", generateETag(response.Body, uc.etags))
+		entry.GeneratedETag = true
+	}
+	uc.storeCachedResponse(ctx, key, entry, now)
+
+	// The upstream does not know generated ETags, so the client's copy is validated here
+	if entry.GeneratedETag && entry.notModified(client) {
+		return notModifiedResponse(response), false
+	}
+	return response, false
+}
+
+// authorizedRequest reports whether a request was made on behalf of an identified caller, whose
+// response may be private to them. The gateway removes the credentials it verifies, such as API
+// keys, session cookies and tokens exchanged for identity tokens, before the request reaches the
+// cache, so the caller's authenticated state decides rather than the headers left.
+func authorizedRequest(ctx context.Context, request *entity.Request, endpoint *entity.Endpoint, client conditions) bool {
+	if _, ok := entity.PrincipalFromContext(ctx); ok {
+		return true
+	}
+	return request.Authenticated || endpoint.AuthRequired || client.headers.Get("Authorization") != ""
+}
+
+// generateETag returns an ETag of a kind identifying a response body
+func generateETag(body []byte, kind string) string {
+	sum := sha256.Sum256(body)
+	etag := `"` + hex.EncodeToString(sum[:16]) + `"`
+	if kind == ETagWeak {
+		return "W/" + etag
+	}
+	return etag
+}
+
+// isJSON reports whether a response carries a JSON body, such as application/json or
+// application/problem+json
+func isJSON(response *entity.Response) bool {
+	contentType := headerValue(response.Headers, "Content-Type")
+	if contentType == "" {
+		contentType = response.ContentType
+	}
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	return err == nil && (mediaType == "application/json" || strings.HasSuffix(mediaType, "+json"))
+}
+
+// withHeader returns a copy of headers with a header set, as the header values of responses
+// may be shared
+func withHeader(headers map[string][]string, name string, value string) map[string][]string {
+	copied := make(map[string][]string, len(headers)+1)
+	for key, values := range headers {
+		copied[key] = values
+	}
+	deleteHeader(copied, name)
+	copied[name] = []string{value}
+	return copied
+}
+
+// storeCachedResponse writes an entry to the response cache, keeping it past its freshness for
+// revalidation when it has a validator
+func (uc *ProxyUseCase) storeCachedResponse(ctx context.Context, key string, entry *cachedResponse, now time.Time) {
+	ttl := entry.FreshUntil.Sub(now)
+	if entry.revalidatable() {
+		ttl += staleRetention
+	}
+	if ttl <= 0 {
+		return
+	}
+	if err := uc.cacheService.Set(ctx, key, entry, ttl); err != nil {
+		logger.FromContextOr(ctx, uc.logger).Warn("Failed to cache response", "error", err)
+	}
+}
+
+// newCachedResponse builds the cache entry of a response, reporting false if the response must
+// not be stored. The freshness lifetime is taken from s-maxage, max-age or Expires, in that
+// order, and falls back to the endpoint's CacheTTL when the upstream gives none. Error responses
+// cached by the endpoint's negative cache are fresh for its TTL.
+func newCachedResponse(response *entity.Response, endpoint *entity.Endpoint, now time.Time) (*cachedResponse, bool) {
+	negative := endpoint.NegativeCache.Caches(response.StatusCode)
+	if !negative && (endpoint.CacheTTL <= 0 || !cacheableStatuses[response.StatusCode]) {
+		return nil, false
+	}
+	for _, name := range varyNames(response) {
+		if name == "*" {
+			return nil, false
+		}
+	}
+
+	directives := parseCacheControl(headerValues(response.Headers, "Cache-Control"))
+	if _, ok := directives["no-store"]; ok {
+		return nil, false
+	}
+	// The gateway is a shared cache and must not store responses meant for a single user
+	if _, ok := directives["private"]; ok {
+		return nil, false
+	}
+
+	if negative {
+		return &cachedResponse{
+			Response:   response,
+			Date:       now,
+			FreshUntil: now.Add(endpoint.NegativeCache.Duration()),
+			Negative:   true,
+		}, true
+	}
+
+	date := now
+	if value, err := http.ParseTime(headerValue(response.Headers, "Date")); err == nil && value.Before(now) {
+		date = value
+	}
+	if age, err := strconv.Atoi(headerValue(response.Headers, "Age")); err == nil && age > 0 {
+		date = date.Add(-time.Duration(age) * time.Second)
+	}
+
+	lifetime := time.Duration(endpoint.CacheTTL) * time.Second
+	if _, ok := directives["no-cache"]; ok {
+		lifetime = 0
+	} else if seconds, ok := deltaSeconds(directives, "s-maxage"); ok {
+		lifetime = seconds
+	} else if seconds, ok := deltaSeconds(directives, "max-age"); ok {
+		lifetime = seconds
+	} else if expires := headerValue(response.Headers, "Expires"); expires != "" {
+		// Invalid dates, such as "0", mean the response is already expired
+		lifetime = 0
+		if value, err := http.ParseTime(expires); err == nil {
+			lifetime = value.Sub(date)
+		}
+	}
+
+	entry := &cachedResponse{
+		Response:   response,
+		Date:       date,
+		FreshUntil: date.Add(lifetime),
+	}
+	if !now.Before(entry.FreshUntil) && !entry.revalidatable() {
+		return nil, false
+	}
+	return entry, true
+}
+
+// sharedWithAuthorization reports whether the response to a request with Authorization may be
+// stored by a shared cache
+func sharedWithAuthorization(response *entity.Response) bool {
+	directives := parseCacheControl(headerValues(response.Headers, "Cache-Control"))
+	for _, name := range []string{"public", "s-maxage", "must-revalidate"} {
+		if _, ok := directives[name]; ok {
+			return true
+		}
+	}
+	return false
+}
+
+// varyNames returns the names of the request headers listed in the Vary header of a response
+func varyNames(response *entity.Response) []string {
+	var names []string
+	for _, value := range headerValues(response.Headers, "Vary") {
+		for _, name := range strings.Split(value, ",") {
+			if name = strings.TrimSpace(name); name != "" {
+				names = append(names, http.CanonicalHeaderKey(name))
+			}
+		}
+	}
+	return names
+}
+
+// varyValues returns the values of the request headers a response varies on, nil when it
+// varies on none
+func varyValues(response *entity.Response, headers http.Header) map[string]string {
+	names := varyNames(response)
+	if len(names) == 0 {
+		return nil
+	}
+	values := make(map[string]string, len(names))
+	for _, name := range names {
+		values[name] = strings.Join(headers.Values(name), ",")
+	}
+	return values
+}
+
+// matchesVary reports whether a request sends the header values the cached response was stored for
+func (c *cachedResponse) matchesVary(headers map[string][]string) bool {
+	for name, value := range c.Vary {
+		if strings.Join(http.Header(headers).Values(name), ",") != value {
+			return false
+		}
+	}
+	return true
+}
+
+// serve returns the cached response for a client, or 304 if it satisfies the client's conditions
+func (c *cachedResponse) serve(client conditions, now time.Time) *entity.Response {
+	headers := make(map[string][]string, len(c.Response.Headers)+1)
+	for name, values := range c.Response.Headers {
+		headers[name] = values
+	}
+	if age := now.Sub(c.Date); age > 0 {
+		deleteHeader(headers, "Age")
+		headers["Age"] = []string{strconv.Itoa(int(age.Seconds()))}
+	}
+
+	response := *c.Response
+	response.Headers = headers
+	response.CachedResult = true
+	if c.notModified(client) {
+		return notModifiedResponse(&response)
+	}
+	return &response
+}
+
+// notModifiedResponse returns the 304 answering a client that holds the representation of a response
+func notModifiedResponse(response *entity.Response) *entity.Response {
+	notModified := *response
+	notModified.StatusCode = http.StatusNotModified
+	notModified.Body = nil
+	notModified.ContentLength = 0
+	return &notModified
+}
+
+// notModified reports whether the client already holds the cached representation. As in
+// RFC 9110, If-Modified-Since is only evaluated without If-None-Match.
+func (c *cachedResponse) notModified(client conditions) bool {
+	if client.ifNoneMatch != "" {
+		etag := weakETag(c.header("ETag"))
+		if etag == "" {
+			return false
+		}
+		for _, candidate := range strings.Split(client.ifNoneMatch, ",") {
+			candidate = strings.TrimSpace(candidate)
+			if candidate == "*" || weakETag(candidate) == etag {
+				return true
+			}
+		}
+		return false
+	}
+
+	if client.ifModifiedSince != "" {
+		since, err := http.ParseTime(client.ifModifiedSince)
+		if err != nil {
+			return false
+		}
+		lastModified, err := http.ParseTime(c.header("Last-Modified"))
+		return err == nil && !lastModified.After(since)
+	}
+	return false
+}
+
+// revalidatable reports whether the cached response has a validator for conditional requests
+func (c *cachedResponse) revalidatable() bool {
+	if c.Negative {
+		return false
+	}
+	return (c.header("ETag") != "" && !c.GeneratedETag) || c.header("Last-Modified") != ""
+}
+
+// header returns the first value of a header of the cached response
+func (c *cachedResponse) header(name string) string {
+	return headerValue(c.Response.Headers, name)
+}
+
+// headerValues returns the values of a header, matching its name case-insensitively as
+// responses may carry headers that are not in canonical form, such as ETag
+func headerValues(headers map[string][]string, name string) []string {
+	var values []string
+	for key, value := range headers {
+		if strings.EqualFold(key, name) {
+			values = append(values, value...)
+		}
+	}
+	return values
+}
+
+// headerValue returns the first value of a header, matching its name case-insensitively
+func headerValue(headers map[string][]string, name string) string {
+	if values := headerValues(headers, name); len(values) > 0 {
+		return values[0]
+	}
+	return ""
+}
+
+// deleteHeader removes a header, matching its name case-insensitively
+func deleteHeader(headers map[string][]string, name string) {
+	for key := range headers {
+		if strings.EqualFold(key, name) {
+			delete(headers, key)
+		}
+	}
+}
+
+// decodeCachedResponse converts a value read from the response cache into a cache entry
+func decodeCachedResponse(value interface{}) (*cachedResponse, bool) {
+	if entry, ok := value.(*cachedResponse); ok {
+		return entry, entry.Response != nil
+	}
+
+	// Cache backends decode values generically, so the entry is re-encoded into its type
+	data, err := json.Marshal(value)
+	if err != nil {
+		return nil, false
+	}
+	var entry cachedResponse
+	if err := json.Unmarshal(data, &entry); err != nil || entry.Response == nil {
+		return nil, false
+	}
+	if entry.Response.Headers == nil {
+		entry.Response.Headers = map[string][]string{}
+	}
+	return &entry, true
+}
+
+// parseCacheControl parses Cache-Control header values into lowercased directives and their values
+func parseCacheControl(values []string) map[string]string {
+	directives := make(map[string]string)
+	for _, value := range values {
+		for _, directive := range strings.Split(value, ",") {
+			name, argument, _ := strings.Cut(strings.TrimSpace(directive), "=")
+			if name == "" {
+				continue
+			}
+			directives[strings.ToLower(name)] = strings.Trim(strings.TrimSpace(argument), `"`)
+		}
+	}
+	return directives
+}
+
+// deltaSeconds returns a directive holding a number of seconds as a duration
+func deltaSeconds(directives map[string]string, name string) (time.Duration, bool) {
+	value, ok := directives[name]
+	if !ok {
+		return 0, false
+	}
+	seconds, err := strconv.Atoi(value)
+	if err != nil || seconds < 0 {
+		return 0, true
+	}
+	return time.Duration(seconds) * time.Second, true
+}
+
+// weakETag strips the weak indicator of an entity tag, as If-None-Match uses weak comparison
+func weakETag(etag string) string {
+	return strings.TrimPrefix(strings.TrimSpace(etag), "W/")
+}
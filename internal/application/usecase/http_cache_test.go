@@ -0,0 +1,438 @@
+package usecase
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"api-gateway-sample/internal/domain/entity"
+)
+
+// jsonCacheService is a cache service that decodes values generically, like the cache service
+// adapter over the cache backends
+type jsonCacheService struct {
+	jsonCache
+}
+
+func (c *jsonCacheService) Get(ctx context.Context, key string) (interface{}, bool, error) {
+	var value interface{}
+	if err := c.jsonCache.Get(ctx, key, &value); err != nil {
+		return nil, false, nil
+	}
+	return value, true, nil
+}
+
+func (c *jsonCacheService) Clear(ctx context.Context) error {
+	return nil
+}
+
+func (c *jsonCacheService) ClearMatching(ctx context.Context, pattern string) error {
+	return nil
+}
+
+// scriptedGateway answers routed requests with the next scripted response and records the requests
+type scriptedGateway struct {
+	countingGateway
+	responses []*entity.Response
+	requests  []*entity.Request
+}
+
+func (g *scriptedGateway) RouteRequest(ctx context.Context, request *entity.Request) (*entity.Response, error) {
+	response := g.responses[len(g.requests)]
+	g.requests = append(g.requests, request)
+	return response, nil
+}
+
+// newHTTPCacheFixture creates a proxy use case for a cached articles endpoint
+func newHTTPCacheFixture(t *testing.T, responses ...*entity.Response) (*ProxyUseCase, *scriptedGateway, *jsonCacheService) {
+	t.Helper()
+	service := newTestService("articles", entity.Endpoint{Path: "/api/v1/articles", Methods: []string{http.MethodGet}, CacheTTL: 60})
+	serviceRepo := newServiceRepository(t, service)
+
+	gateway := &scriptedGateway{responses: responses}
+	cache := &jsonCacheService{jsonCache{entries: map[string][]byte{}}}
+	return NewProxyUseCase(serviceRepo, gateway, nil, nil, cache, &MockLogger{}), gateway, cache
+}
+
+func newArticlesRequest(headers map[string][]string) *entity.Request {
+	return entity.NewRequest(http.MethodGet, "/api/v1/articles", headers, map[string][]string{}, nil, "127.0.0.1")
+}
+
+func articlesResponse(status int, headers map[string][]string) *entity.Response {
+	return &entity.Response{StatusCode: status, Headers: headers, Body: []byte(`[{"id":1}]`)}
+}
+
+func TestProxyUseCase_HTTPCacheFreshness(t *testing.T) {
+	tests := []struct {
+		name         string
+		cacheControl string
+		cached       bool
+	}{
+		{name: "endpoint TTL without directives", cached: true},
+		{name: "max-age", cacheControl: "public, max-age=300", cached: true},
+		{name: "s-maxage overrides max-age", cacheControl: "max-age=300, s-maxage=0"},
+		{name: "no-store", cacheControl: "no-store"},
+		{name: "private", cacheControl: "private, max-age=300"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			headers := map[string][]string{}
+			if tt.cacheControl != "" {
+				headers["Cache-Control"] = []string{tt.cacheControl}
+			}
+			useCase, gateway, _ := newHTTPCacheFixture(t,
+				articlesResponse(http.StatusOK, headers),
+				articlesResponse(http.StatusOK, headers),
+			)
+
+			for i := 0; i < 2; i++ {
+				if _, err := useCase.ProxyRequest(context.Background(), newArticlesRequest(map[string][]string{})); err != nil {
+					t.Fatalf("Unexpected error: %v", err)
+				}
+			}
+
+			expected := 2
+			if tt.cached {
+				expected = 1
+			}
+			if len(gateway.requests) != expected {
+				t.Errorf("Expected %d upstream requests, got %d", expected, len(gateway.requests))
+			}
+		})
+	}
+}
+
+func TestProxyUseCase_HTTPCacheExpires(t *testing.T) {
+	expired := map[string][]string{"Expires": {time.Now().Add(-time.Minute).UTC().Format(http.TimeFormat)}}
+	useCase, gateway, _ := newHTTPCacheFixture(t,
+		articlesResponse(http.StatusOK, expired),
+		articlesResponse(http.StatusOK, expired),
+	)
+
+	for i := 0; i < 2; i++ {
+		if _, err := useCase.ProxyRequest(context.Background(), newArticlesRequest(map[string][]string{})); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+	}
+	if len(gateway.requests) != 2 {
+		t.Errorf("Expected an expired response not to be reused, got %d upstream requests", len(gateway.requests))
+	}
+}
+
+func TestProxyUseCase_HTTPCacheRevalidation(t *testing.T) {
+	ctx := context.Background()
+	useCase, gateway, _ := newHTTPCacheFixture(t,
+		articlesResponse(http.StatusOK, map[string][]string{"Cache-Control": {"no-cache"}, "ETag": {`"v1"`}}),
+		&entity.Response{StatusCode: http.StatusNotModified, Headers: map[string][]string{"Cache-Control": {"max-age=300"}, "ETag": {`"v1"`}}},
+	)
+
+	if _, err := useCase.ProxyRequest(ctx, newArticlesRequest(map[string][]string{})); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	// 1. The stale response is revalidated with its ETag and served from the cache on 304
+	response, err := useCase.ProxyRequest(ctx, newArticlesRequest(map[string][]string{}))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if got := http.Header(gateway.requests[1].Headers).Get("If-None-Match"); got != `"v1"` {
+		t.Errorf("Expected revalidation with If-None-Match \"v1\", got %q", got)
+	}
+	if response.StatusCode != http.StatusOK || string(response.Body) != `[{"id":1}]` || !response.CachedResult {
+		t.Errorf("Expected the cached body with 200, got %d %s", response.StatusCode, response.Body)
+	}
+
+	// 2. The 304 refreshed the response, which is now fresh, and clients holding it get 304
+	response, err = useCase.ProxyRequest(ctx, newArticlesRequest(map[string][]string{"If-None-Match": {`W/"v1"`}}))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(gateway.requests) != 2 {
+		t.Errorf("Expected the refreshed response to be served from the cache, got %d upstream requests", len(gateway.requests))
+	}
+	if response.StatusCode != http.StatusNotModified || len(response.Body) != 0 {
+		t.Errorf("Expected 304 without a body, got %d %s", response.StatusCode, response.Body)
+	}
+}
+
+func TestProxyUseCase_HTTPCacheRequestNoCache(t *testing.T) {
+	ctx := context.Background()
+	useCase, gateway, _ := newHTTPCacheFixture(t,
+		articlesResponse(http.StatusOK, map[string][]string{"Cache-Control": {"max-age=300"}}),
+		articlesResponse(http.StatusOK, map[string][]string{"Cache-Control": {"max-age=300"}}),
+	)
+
+	if _, err := useCase.ProxyRequest(ctx, newArticlesRequest(map[string][]string{})); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if _, err := useCase.ProxyRequest(ctx, newArticlesRequest(map[string][]string{"Cache-Control": {"no-cache"}})); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(gateway.requests) != 2 {
+		t.Errorf("Expected Cache-Control: no-cache to bypass the fresh response, got %d upstream requests", len(gateway.requests))
+	}
+}
+
+func TestProxyUseCase_HTTPCacheVary(t *testing.T) {
+	ctx := context.Background()
+	vary := map[string][]string{"Cache-Control": {"max-age=300"}, "Vary": {"Accept-Language"}}
+	useCase, gateway, _ := newHTTPCacheFixture(t,
+		articlesResponse(http.StatusOK, vary),
+		articlesResponse(http.StatusOK, vary),
+	)
+
+	for _, language := range []string{"en", "fr", "fr"} {
+		if _, err := useCase.ProxyRequest(ctx, newArticlesRequest(map[string][]string{"Accept-Language": {language}})); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+	}
+	if len(gateway.requests) != 2 {
+		t.Errorf("Expected responses to be served only to requests with the same Accept-Language, got %d upstream requests", len(gateway.requests))
+	}
+}
+
+func TestProxyUseCase_HTTPCacheAuthorization(t *testing.T) {
+	tests := []struct {
+		name         string
+		cacheControl string
+		cached       bool
+	}{
+		{name: "without directives"},
+		{name: "max-age", cacheControl: "max-age=300"},
+		{name: "public", cacheControl: "public, max-age=300", cached: true},
+		{name: "s-maxage", cacheControl: "s-maxage=300", cached: true},
+	}
+
+	// The router removes the API keys and session cookies it verifies, leaving only the principal
+	callers := []struct {
+		name    string
+		headers map[string][]string
+		method  string
+	}{
+		{name: "bearer token", headers: map[string][]string{"Authorization": {"Bearer token"}}},
+		{name: "session cookie", headers: map[string][]string{"Cookie": {"theme=dark"}}, method: entity.AuthMethodOIDC},
+		{name: "API key", headers: map[string][]string{}, method: entity.AuthMethodAPIKey},
+	}
+
+	for _, caller := range callers {
+		for _, tt := range tests {
+			t.Run(caller.name+"/"+tt.name, func(t *testing.T) {
+				headers := map[string][]string{}
+				if tt.cacheControl != "" {
+					headers["Cache-Control"] = []string{tt.cacheControl}
+				}
+				useCase, gateway, _ := newHTTPCacheFixture(t,
+					articlesResponse(http.StatusOK, headers),
+					articlesResponse(http.StatusOK, headers),
+				)
+
+				for _, user := range []string{"alice", "bob"} {
+					ctx := context.Background()
+					if caller.method != "" {
+						ctx = entity.ContextWithPrincipal(ctx, entity.NewPrincipal(map[string]interface{}{"sub": user, "amr": []interface{}{caller.method}}))
+					}
+					if _, err := useCase.ProxyRequest(ctx, newArticlesRequest(caller.headers)); err != nil {
+						t.Fatalf("Unexpected error: %v", err)
+					}
+				}
+
+				expected := 2
+				if tt.cached {
+					expected = 1
+				}
+				if len(gateway.requests) != expected {
+					t.Errorf("Expected %d upstream requests, got %d", expected, len(gateway.requests))
+				}
+			})
+		}
+	}
+}
+
+func TestProxyUseCase_HTTPCacheQuery(t *testing.T) {
+	ctx := context.Background()
+	useCase, gateway, _ := newHTTPCacheFixture(t,
+		articlesResponse(http.StatusOK, map[string][]string{}),
+		articlesResponse(http.StatusOK, map[string][]string{}),
+	)
+
+	for _, page := range []string{"1", "2", "1"} {
+		request := newArticlesRequest(map[string][]string{})
+		request.QueryParams = map[string][]string{"page": {page}}
+		if _, err := useCase.ProxyRequest(ctx, request); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+	}
+	if len(gateway.requests) != 2 {
+		t.Errorf("Expected responses to be cached by query, got %d upstream requests", len(gateway.requests))
+	}
+}
+
+func TestProxyUseCase_NegativeCache(t *testing.T) {
+	ctx := context.Background()
+	service := newTestService("articles", entity.Endpoint{
+		Path:          "/api/v1/articles",
+		Methods:       []string{http.MethodGet},
+		NegativeCache: &entity.NegativeCache{TTL: 5},
+	})
+	serviceRepo := newServiceRepository(t, service)
+
+	gateway := &scriptedGateway{responses: []*entity.Response{
+		articlesResponse(http.StatusNotFound, map[string][]string{}),
+		articlesResponse(http.StatusOK, map[string][]string{}),
+		articlesResponse(http.StatusOK, map[string][]string{}),
+	}}
+	cache := &jsonCacheService{jsonCache{entries: map[string][]byte{}}}
+	useCase := NewProxyUseCase(serviceRepo, gateway, nil, nil, cache, &MockLogger{})
+
+	// 1. A 404 is served from the cache to repeated requests
+	for i := 0; i < 2; i++ {
+		response, err := useCase.ProxyRequest(ctx, newArticlesRequest(map[string][]string{}))
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if response.StatusCode != http.StatusNotFound {
+			t.Errorf("Expected 404, got %d", response.StatusCode)
+		}
+	}
+	if len(gateway.requests) != 1 {
+		t.Errorf("Expected the 404 to be cached, got %d upstream requests", len(gateway.requests))
+	}
+
+	// 2. Successful responses are not cached without a cacheTTL
+	cache.entries = map[string][]byte{}
+	for i := 0; i < 2; i++ {
+		if _, err := useCase.ProxyRequest(ctx, newArticlesRequest(map[string][]string{})); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+	}
+	if len(gateway.requests) != 3 {
+		t.Errorf("Expected successful responses to reach the upstream, got %d upstream requests", len(gateway.requests))
+	}
+}
+
+func TestProxyUseCase_GeneratedETags(t *testing.T) {
+	ctx := context.Background()
+	jsonHeaders := map[string][]string{"Content-Type": {"application/json; charset=utf-8"}}
+	useCase, gateway, _ := newHTTPCacheFixture(t,
+		articlesResponse(http.StatusOK, jsonHeaders),
+		articlesResponse(http.StatusOK, jsonHeaders),
+	)
+	useCase.SetGeneratedETags(ETagWeak)
+
+	// 1. The cached JSON response is given an ETag identifying its body
+	response, err := useCase.ProxyRequest(ctx, newArticlesRequest(map[string][]string{}))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	etag := headerValue(response.Headers, "ETag")
+	if etag != generateETag([]byte(`[{"id":1}]`), ETagWeak) || etag[:2] != "W/" {
+		t.Fatalf("Expected a weak ETag of the body, got %q", etag)
+	}
+
+	// 2. Clients polling with it get 304 from the gateway while the response is fresh
+	for i := 0; i < 2; i++ {
+		response, err = useCase.ProxyRequest(ctx, newArticlesRequest(map[string][]string{"If-None-Match": {etag}}))
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if response.StatusCode != http.StatusNotModified || len(response.Body) != 0 {
+			t.Errorf("Expected 304 without a body, got %d %s", response.StatusCode, response.Body)
+		}
+	}
+	if len(gateway.requests) != 1 {
+		t.Errorf("Expected polling to be answered by the gateway, got %d upstream requests", len(gateway.requests))
+	}
+
+	// 3. Clients holding another body get the cached one
+	response, err = useCase.ProxyRequest(ctx, newArticlesRequest(map[string][]string{"If-None-Match": {`"other"`}}))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if response.StatusCode != http.StatusOK || string(response.Body) != `[{"id":1}]` {
+		t.Errorf("Expected the cached body with 200, got %d %s", response.StatusCode, response.Body)
+	}
+}
+
+func TestGeneratedETagsSkipped(t *testing.T) {
+	tests := []struct {
+		name    string
+		headers map[string][]string
+		etag    string
+	}{
+		{name: "upstream ETag kept", headers: map[string][]string{"Content-Type": {"application/json"}, "ETag": {`"v1"`}}, etag: `"v1"`},
+		{name: "not JSON", headers: map[string][]string{"Content-Type": {"text/html"}}},
+		{name: "JSON suffix", headers: map[string][]string{"Content-Type": {"application/problem+json"}}, etag: generateETag([]byte(`[{"id":1}]`), ETagStrong)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			useCase, _, _ := newHTTPCacheFixture(t, articlesResponse(http.StatusOK, tt.headers))
+			useCase.SetGeneratedETags(ETagStrong)
+
+			response, err := useCase.ProxyRequest(context.Background(), newArticlesRequest(map[string][]string{}))
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if got := headerValue(response.Headers, "ETag"); got != tt.etag {
+				t.Errorf("Expected ETag %q, got %q", tt.etag, got)
+			}
+		})
+	}
+}
+
+// closeRecorder records whether the body it reads was closed
+type closeRecorder struct {
+	io.Reader
+	closed bool
+}
+
+func (r *closeRecorder) Close() error {
+	r.closed = true
+	return nil
+}
+
+func TestProxyUseCase_StreamedResponsesBypassCache(t *testing.T) {
+	ctx := context.Background()
+	streamed := func() (*entity.Response, *closeRecorder) {
+		stream := &closeRecorder{Reader: strings.NewReader("rest")}
+		response := articlesResponse(http.StatusOK, map[string][]string{"Cache-Control": {"max-age=300"}, "Content-Length": {"14"}})
+		response.BodyStream = stream
+		return response, stream
+	}
+	first, _ := streamed()
+	second, _ := streamed()
+	third, headStream := streamed()
+	useCase, gateway, _ := newHTTPCacheFixture(t, first, second, third)
+
+	// 1. Responses too large to buffer reach the client unbuffered and are never cached
+	for i := 0; i < 2; i++ {
+		response, err := useCase.ProxyRequest(ctx, newArticlesRequest(map[string][]string{}))
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if response.BodyStream == nil {
+			t.Error("Expected the response to be streamed")
+		}
+	}
+	if len(gateway.requests) != 2 {
+		t.Errorf("Expected streamed responses not to be cached, got %d upstream requests", len(gateway.requests))
+	}
+
+	// 2. HEAD requests get the declared length, and the body is not read
+	request := newArticlesRequest(map[string][]string{})
+	request.Method = http.MethodHead
+	response, err := useCase.ProxyRequest(ctx, request)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if response.BodyStream != nil || len(response.Body) != 0 || !headStream.closed {
+		t.Error("Expected the streamed body to be closed and left out")
+	}
+	if got := headerValue(response.Headers, "Content-Length"); got != "14" {
+		t.Errorf("Expected the declared Content-Length, got %q", got)
+	}
+}
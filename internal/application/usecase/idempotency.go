@@ -0,0 +1,199 @@
+package usecase
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"net/url"
+	"time"
+
+	"api-gateway-sample/internal/domain/entity"
+	"api-gateway-sample/internal/domain/repository"
+	"api-gateway-sample/pkg/errors"
+	"api-gateway-sample/pkg/logger"
+)
+
+// Idempotency headers
+const (
+	// HeaderIdempotencyKey identifies retries of the same unsafe request
+	HeaderIdempotencyKey = "Idempotency-Key"
+	// HeaderIdempotentReplayed marks a response replayed from the idempotency store
+	HeaderIdempotentReplayed = "Idempotent-Replayed"
+)
+
+// Idempotency record states
+const (
+	idempotencyProcessing = "processing"
+	idempotencyCompleted  = "completed"
+)
+
+// codeUnprocessableEntity is returned when an idempotency key is reused for a different request
+const codeUnprocessableEntity = 422
+
+// idempotencyRecord is the stored state of a request sent with an Idempotency-Key
+type idempotencyRecord struct {
+	State string `json:"state"`
+	// Fingerprint identifies the request the key was first used with
+	Fingerprint string           `json:"fingerprint"`
+	Response    *entity.Response `json:"response,omitempty"`
+}
+
+// idempotencyStore replays the responses of POST and PATCH requests retried with the same
+// Idempotency-Key, so that retries do not repeat writes on the backend
+type idempotencyStore struct {
+	cache repository.CacheRepository
+	// ttl is how long completed responses are replayed
+	ttl time.Duration
+	// lockTimeout bounds how long a request in flight blocks its retries
+	lockTimeout time.Duration
+}
+
+// SetIdempotencyStore stores the responses of POST and PATCH requests carrying an
+// Idempotency-Key in the cache and replays them for retries within ttl
+func (uc *ProxyUseCase) SetIdempotencyStore(cache repository.CacheRepository, ttl time.Duration, lockTimeout time.Duration) {
+	uc.idempotency = &idempotencyStore{
+		cache:       cache,
+		ttl:         ttl,
+		lockTimeout: lockTimeout,
+	}
+}
+
+// idempotent reports whether the request takes part in idempotency handling
+func (uc *ProxyUseCase) idempotent(request *entity.Request) bool {
+	if uc.idempotency == nil {
+		return false
+	}
+	if request.Method != http.MethodPost && request.Method != http.MethodPatch {
+		return false
+	}
+	// Streamed uploads are not buffered, so a retry's body could not be compared with the first
+	if request.BodyStream != nil {
+		return false
+	}
+	return idempotencyKey(request) != ""
+}
+
+// beginIdempotent claims the idempotency key of a request. It returns the stored response when
+// the request was already completed, and an error when the key is in use by a request still in
+// flight or was first used with a different request.
+func (uc *ProxyUseCase) beginIdempotent(ctx context.Context, request *entity.Request, service *entity.Service) (string, *entity.Response, error) {
+	store := uc.idempotency
+	cacheKey := idempotencyCacheKey(service.ID, uc.idempotencyCaller(ctx, request), idempotencyKey(request))
+	fingerprint := requestFingerprint(request)
+
+	claimed, err := store.cache.SetNX(ctx, cacheKey, &idempotencyRecord{
+		State:       idempotencyProcessing,
+		Fingerprint: fingerprint,
+	}, store.lockTimeout)
+	if err != nil {
+		// Without the store the request is proxied as if it carried no key
+		logger.FromContextOr(ctx, uc.logger).Warn("Idempotency store unavailable", "error", err)
+		return "", nil, nil
+	}
+	if claimed {
+		return cacheKey, nil, nil
+	}
+
+	var record idempotencyRecord
+	if err := store.cache.Get(ctx, cacheKey, &record); err != nil {
+		return "", nil, errors.NewError(errors.CodeAlreadyExists, "a request with this Idempotency-Key is in progress", errors.ErrAlreadyExists)
+	}
+	if record.Fingerprint != fingerprint {
+		return "", nil, errors.NewError(codeUnprocessableEntity, "Idempotency-Key was already used with a different request", errors.ErrInvalidInput)
+	}
+	if record.State != idempotencyCompleted || record.Response == nil {
+		return "", nil, errors.NewError(errors.CodeAlreadyExists, "a request with this Idempotency-Key is in progress", errors.ErrAlreadyExists)
+	}
+	return "", replayedResponse(record.Response), nil
+}
+
+// finishIdempotent stores the response of a claimed request for replay. Failed requests and
+// server errors release the key so that the client can retry them.
+func (uc *ProxyUseCase) finishIdempotent(ctx context.Context, cacheKey string, request *entity.Request, response *entity.Response, err error) {
+	store := uc.idempotency
+	log := logger.FromContextOr(ctx, uc.logger)
+
+	// Streamed responses are too large to be stored, so retries are forwarded again
+	if err != nil || response.StatusCode >= http.StatusInternalServerError || response.BodyStream != nil {
+		if err := store.cache.Delete(ctx, cacheKey); err != nil {
+			log.Warn("Failed to release idempotency key", "error", err)
+		}
+		return
+	}
+
+	record := &idempotencyRecord{
+		State:       idempotencyCompleted,
+		Fingerprint: requestFingerprint(request),
+		Response:    response,
+	}
+	if err := store.cache.Set(ctx, cacheKey, record, store.ttl); err != nil {
+		log.Warn("Failed to store idempotent response", "error", err)
+	}
+}
+
+// idempotencyCaller identifies the caller a request's idempotency key is scoped to: the
+// authenticated user, or for callers without a principal their address and credentials
+func (uc *ProxyUseCase) idempotencyCaller(ctx context.Context, request *entity.Request) string {
+	caller := request.UserID
+	if principal, ok := entity.PrincipalFromContext(ctx); ok && caller == "" {
+		caller = principal.UserID
+	}
+	if caller != "" {
+		return "user:" + caller
+	}
+	return "client:" + request.ClientIP + ":" + uc.callerDigest(ctx, request)
+}
+
+// idempotencyKey returns the Idempotency-Key sent with a request
+func idempotencyKey(request *entity.Request) string {
+	if values := request.Headers[HeaderIdempotencyKey]; len(values) > 0 {
+		return values[0]
+	}
+	return ""
+}
+
+// idempotencyCacheKey scopes a client's idempotency key to the service and caller, so
+// different callers can never see each other's responses
+func idempotencyCacheKey(serviceID string, caller string, key string) string {
+	sum := sha256.Sum256([]byte(caller + "\x00" + key))
+	return "idempotency:" + serviceID + ":" + hex.EncodeToString(sum[:])
+}
+
+// requestFingerprint identifies the method, path, query and body of a request
+func requestFingerprint(request *entity.Request) string {
+	hash := sha256.New()
+	hash.Write([]byte(request.Method + " " + request.Path + "?" + url.Values(request.QueryParams).Encode() + "\x00"))
+	hash.Write(request.Body)
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+// replayedResponse returns a copy of a stored response marked as replayed
+func replayedResponse(stored *entity.Response) *entity.Response {
+	response := *stored
+	response.Headers = make(map[string][]string, len(stored.Headers)+1)
+	for name, values := range stored.Headers {
+		response.Headers[name] = values
+	}
+	response.Headers[HeaderIdempotentReplayed] = []string{"true"}
+	return &response
+}
+
+// forwardIdempotent forwards a request sent with an Idempotency-Key, or replays the response
+// of an earlier request sent with the same key
+func (uc *ProxyUseCase) forwardIdempotent(ctx context.Context, request *entity.Request, service *entity.Service, sample *entity.RequestSample) (*entity.Response, error) {
+	cacheKey, replayed, err := uc.beginIdempotent(ctx, request, service)
+	if err != nil {
+		return nil, err
+	}
+	if replayed != nil {
+		logger.FromContextOr(ctx, uc.logger).Debug("Replaying idempotent response", "status", replayed.StatusCode)
+		return replayed, nil
+	}
+
+	response, err := uc.forwardRequest(ctx, request, service, sample)
+	if cacheKey != "" {
+		uc.finishIdempotent(ctx, cacheKey, request, response, err)
+	}
+	return response, err
+}
@@ -0,0 +1,235 @@
+package usecase
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+
+	"api-gateway-sample/internal/domain/entity"
+	"api-gateway-sample/pkg/errors"
+)
+
+// jsonCache is a map-backed cache repository that encodes values as JSON like the Redis cache
+type jsonCache struct {
+	mu      sync.Mutex
+	entries map[string][]byte
+}
+
+func (c *jsonCache) Set(ctx context.Context, key string, value interface{}, ttl time.Duration) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	data, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+	c.entries[key] = data
+	return nil
+}
+
+func (c *jsonCache) Get(ctx context.Context, key string, value interface{}) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	data, ok := c.entries[key]
+	if !ok {
+		return errors.ErrNotFound
+	}
+	return json.Unmarshal(data, value)
+}
+
+func (c *jsonCache) Delete(ctx context.Context, key string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	delete(c.entries, key)
+	return nil
+}
+
+func (c *jsonCache) SetNX(ctx context.Context, key string, value interface{}, ttl time.Duration) (bool, error) {
+	c.mu.Lock()
+	_, exists := c.entries[key]
+	c.mu.Unlock()
+	if exists {
+		return false, nil
+	}
+	return true, c.Set(ctx, key, value, ttl)
+}
+
+func (c *jsonCache) GetWithTTL(ctx context.Context, key string, value interface{}) (time.Duration, error) {
+	return 0, c.Get(ctx, key, value)
+}
+
+func (c *jsonCache) UpdateTTL(ctx context.Context, key string, ttl time.Duration) error { return nil }
+func (c *jsonCache) Clear(ctx context.Context, pattern string) error                    { return nil }
+func (c *jsonCache) Ping(ctx context.Context) error                                     { return nil }
+func (c *jsonCache) Close() error                                                       { return nil }
+
+// countingGateway answers every routed request with the next status code and counts the calls
+type countingGateway struct {
+	statuses []int
+	calls    int
+}
+
+func (g *countingGateway) RouteRequest(ctx context.Context, request *entity.Request) (*entity.Response, error) {
+	status := g.statuses[g.calls]
+	g.calls++
+	return &entity.Response{
+		RequestID:  request.ID,
+		StatusCode: status,
+		Headers:    map[string][]string{"Content-Type": {"application/json"}},
+		Body:       []byte(`{"id":"order-1"}`),
+	}, nil
+}
+
+func (g *countingGateway) ValidateRequest(ctx context.Context, request *entity.Request) error {
+	return nil
+}
+
+func (g *countingGateway) TransformRequest(ctx context.Context, request *entity.Request, service *entity.Service) (*entity.Request, error) {
+	return request, nil
+}
+
+func (g *countingGateway) TransformResponse(ctx context.Context, response *entity.Response, service *entity.Service) (*entity.Response, error) {
+	return response, nil
+}
+
+func (g *countingGateway) HandleError(ctx context.Context, err error, request *entity.Request) (*entity.Response, error) {
+	return nil, err
+}
+
+// newIdempotencyFixture creates a proxy use case for a public orders endpoint with an idempotency store
+func newIdempotencyFixture(t *testing.T, statuses ...int) (*ProxyUseCase, *countingGateway, *jsonCache) {
+	t.Helper()
+	service := newTestService("orders", entity.Endpoint{Path: "/api/v1/orders", Methods: []string{http.MethodPost}})
+
+	gateway := &countingGateway{statuses: statuses}
+	cache := &jsonCache{entries: map[string][]byte{}}
+	useCase := newProxyFixture(t, gateway, service)
+	useCase.SetIdempotencyStore(cache, time.Hour, time.Minute)
+	return useCase, gateway, cache
+}
+
+func newOrderRequest(key string, body string) *entity.Request {
+	headers := map[string][]string{}
+	if key != "" {
+		headers[HeaderIdempotencyKey] = []string{key}
+	}
+	return entity.NewRequest(http.MethodPost, "/api/v1/orders", headers, map[string][]string{}, []byte(body), "127.0.0.1")
+}
+
+func TestProxyUseCase_IdempotencyReplaysRetries(t *testing.T) {
+	ctx := context.Background()
+	useCase, gateway, _ := newIdempotencyFixture(t, http.StatusCreated, http.StatusCreated)
+
+	// 1. The first request reaches the backend
+	first, err := useCase.ProxyRequest(ctx, newOrderRequest("key-1", `{"item":"book"}`))
+	if err != nil {
+		t.Fatalf("Failed to proxy request: %v", err)
+	}
+	if first.StatusCode != http.StatusCreated || len(first.Headers[HeaderIdempotentReplayed]) != 0 {
+		t.Fatalf("Unexpected first response %+v", first)
+	}
+
+	// 2. A retry is answered from the store
+	retry, err := useCase.ProxyRequest(ctx, newOrderRequest("key-1", `{"item":"book"}`))
+	if err != nil {
+		t.Fatalf("Failed to replay request: %v", err)
+	}
+	if gateway.calls != 1 {
+		t.Errorf("Expected the retry not to reach the backend, got %d calls", gateway.calls)
+	}
+	if retry.StatusCode != http.StatusCreated || string(retry.Body) != `{"id":"order-1"}` {
+		t.Errorf("Expected the stored response, got %+v", retry)
+	}
+	if retry.Headers[HeaderIdempotentReplayed][0] != "true" || retry.Headers["Content-Type"][0] != "application/json" {
+		t.Errorf("Expected the replayed response headers, got %v", retry.Headers)
+	}
+
+	// 3. Requests without a key are always forwarded
+	if _, err := useCase.ProxyRequest(ctx, newOrderRequest("", `{"item":"book"}`)); err != nil {
+		t.Fatalf("Failed to proxy request: %v", err)
+	}
+	if gateway.calls != 2 {
+		t.Errorf("Expected the request without a key to reach the backend, got %d calls", gateway.calls)
+	}
+}
+
+func TestProxyUseCase_IdempotencyRejectsConflicts(t *testing.T) {
+	ctx := context.Background()
+	useCase, gateway, cache := newIdempotencyFixture(t, http.StatusCreated)
+
+	// 1. A retry while the first request is in flight is rejected
+	request := newOrderRequest("key-1", `{"item":"book"}`)
+	cacheKey := idempotencyCacheKey("orders-id", useCase.idempotencyCaller(ctx, request), "key-1")
+	cache.Set(ctx, cacheKey, &idempotencyRecord{State: idempotencyProcessing, Fingerprint: requestFingerprint(request)}, time.Minute)
+	if _, err := useCase.ProxyRequest(ctx, request); errors.StatusCodeOf(err, 0) != errors.CodeAlreadyExists {
+		t.Errorf("Expected a conflict while the request is in flight, got %v", err)
+	}
+
+	// 2. Reusing a key for a different request is rejected
+	cache.Delete(ctx, cacheKey)
+	if _, err := useCase.ProxyRequest(ctx, newOrderRequest("key-1", `{"item":"book"}`)); err != nil {
+		t.Fatalf("Failed to proxy request: %v", err)
+	}
+	if _, err := useCase.ProxyRequest(ctx, newOrderRequest("key-1", `{"item":"pen"}`)); errors.StatusCodeOf(err, 0) != codeUnprocessableEntity {
+		t.Errorf("Expected a different request with the same key to be rejected, got %v", err)
+	}
+	if gateway.calls != 1 {
+		t.Errorf("Expected one backend call, got %d", gateway.calls)
+	}
+}
+
+func TestProxyUseCase_IdempotencyReleasesServerErrors(t *testing.T) {
+	ctx := context.Background()
+	useCase, gateway, _ := newIdempotencyFixture(t, http.StatusBadGateway, http.StatusCreated)
+
+	// A failed request can be retried with the same key
+	first, err := useCase.ProxyRequest(ctx, newOrderRequest("key-1", `{"item":"book"}`))
+	if err != nil || first.StatusCode != http.StatusBadGateway {
+		t.Fatalf("Expected the backend error, got %v %v", first, err)
+	}
+	retry, err := useCase.ProxyRequest(ctx, newOrderRequest("key-1", `{"item":"book"}`))
+	if err != nil || retry.StatusCode != http.StatusCreated {
+		t.Fatalf("Expected the retry to reach the backend, got %v %v", retry, err)
+	}
+	if gateway.calls != 2 {
+		t.Errorf("Expected two backend calls, got %d", gateway.calls)
+	}
+}
+
+func TestProxyUseCase_IdempotencyScopesAnonymousCallers(t *testing.T) {
+	ctx := context.Background()
+	useCase, gateway, _ := newIdempotencyFixture(t, http.StatusCreated, http.StatusCreated, http.StatusCreated, http.StatusCreated)
+
+	send := func(clientIP string, token string) *entity.Response {
+		t.Helper()
+		request := newOrderRequest("key-1", `{"item":"book"}`)
+		request.ClientIP = clientIP
+		if token != "" {
+			request.Headers["Authorization"] = []string{"Bearer " + token}
+		}
+		response, err := useCase.ProxyRequest(ctx, request)
+		if err != nil {
+			t.Fatalf("Failed to proxy request: %v", err)
+		}
+		return response
+	}
+
+	// 1. Callers without a principal reusing a key from another address or with other
+	// credentials are not answered with its response
+	send("10.0.0.1", "")
+	send("10.0.0.2", "")
+	send("10.0.0.1", "alice-token")
+	if gateway.calls != 3 {
+		t.Errorf("Expected each caller to reach the backend, got %d calls", gateway.calls)
+	}
+
+	// 2. The same caller's retry is replayed
+	if retry := send("10.0.0.1", "alice-token"); len(retry.Headers[HeaderIdempotentReplayed]) == 0 {
+		t.Errorf("Expected the retry to be replayed, got %v", retry.Headers)
+	}
+	if gateway.calls != 3 {
+		t.Errorf("Expected the retry not to reach the backend, got %d calls", gateway.calls)
+	}
+}
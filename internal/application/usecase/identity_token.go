@@ -0,0 +1,45 @@
+package usecase
+
+import (
+	"context"
+	"net/textproto"
+	"strings"
+
+	"api-gateway-sample/internal/domain/entity"
+	"api-gateway-sample/internal/domain/service"
+	"api-gateway-sample/pkg/errors"
+)
+
+// SetIdentityTokenIssuer forwards a token minted by the issuer in the given header to services,
+// instead of the credentials of the client, so that services only need to trust the gateway
+func (uc *ProxyUseCase) SetIdentityTokenIssuer(issuer service.IdentityTokenIssuer, header string) {
+	uc.identityTokens = issuer
+	uc.identityHeader = textproto.CanonicalMIMEHeaderKey(header)
+}
+
+// identifyCaller replaces the client's Authorization header with an identity token describing
+// the authenticated caller. Requests of anonymous callers are forwarded without either.
+func (uc *ProxyUseCase) identifyCaller(ctx context.Context, request *entity.Request, service *entity.Service, endpoint *entity.Endpoint) error {
+	for name := range request.Headers {
+		if strings.EqualFold(name, "Authorization") || strings.EqualFold(name, uc.identityHeader) {
+			delete(request.Headers, name)
+		}
+	}
+
+	principal, ok := entity.PrincipalFromContext(ctx)
+	if !ok {
+		return nil
+	}
+	token, err := uc.identityTokens.IssueIdentityToken(ctx, principal, request, service, endpoint)
+	if err != nil {
+		return errors.NewError(errors.CodeInternalServer, "failed to issue identity token", err)
+	}
+	if request.Headers == nil {
+		request.Headers = make(map[string][]string)
+	}
+	if uc.identityHeader == "Authorization" {
+		token = "Bearer " + token
+	}
+	request.Headers[uc.identityHeader] = []string{token}
+	return nil
+}
@@ -0,0 +1,43 @@
+package usecase
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"api-gateway-sample/internal/domain/entity"
+)
+
+// routeTokenIssuer issues tokens naming the caller and the service
+type routeTokenIssuer struct{}
+
+func (routeTokenIssuer) IssueIdentityToken(ctx context.Context, principal *entity.Principal, request *entity.Request, service *entity.Service, endpoint *entity.Endpoint) (string, error) {
+	return principal.UserID + "@" + service.Name, nil
+}
+
+func TestProxyUseCase_IdentityTokens(t *testing.T) {
+	orders := newTestService("orders", entity.Endpoint{Path: "/api/v1/orders", Methods: []string{http.MethodGet}})
+	gateway := &capturingGateway{countingGateway: countingGateway{statuses: []int{http.StatusOK, http.StatusOK}}}
+	useCase := newProxyFixture(t, gateway, orders)
+	useCase.SetIdentityTokenIssuer(routeTokenIssuer{}, "Authorization")
+
+	get := func(ctx context.Context) map[string][]string {
+		t.Helper()
+		headers := map[string][]string{"Authorization": {"Bearer client-token"}}
+		if _, err := useCase.ProxyRequest(ctx, entity.NewRequest(http.MethodGet, "/api/v1/orders", headers, nil, nil, "10.0.0.1")); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		return gateway.requests[len(gateway.requests)-1].Headers
+	}
+
+	// 1. Services receive the identity token instead of the client's token
+	ctx := entity.ContextWithPrincipal(context.Background(), &entity.Principal{UserID: "alice"})
+	if got := get(ctx)["Authorization"]; len(got) != 1 || got[0] != "Bearer alice@orders" {
+		t.Errorf("Expected the identity token, got %v", got)
+	}
+
+	// 2. Credentials of anonymous callers are not forwarded
+	if got, ok := get(context.Background())["Authorization"]; ok {
+		t.Errorf("Expected no Authorization header, got %v", got)
+	}
+}
@@ -0,0 +1,128 @@
+package usecase
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"api-gateway-sample/internal/domain/entity"
+	"api-gateway-sample/pkg/errors"
+	"api-gateway-sample/pkg/jsonpath"
+	"api-gateway-sample/pkg/logger"
+)
+
+// maskedValue replaces redacted values
+const maskedValue = "****"
+
+// maskResponse returns a copy of a JSON response with the fields selected by the masking rules
+// masked, unless the caller was granted the scope to see them. Masking fails closed: responses that
+// cannot be parsed, because they are not JSON, are encoded or are malformed, are refused with a 502
+// rather than returned unmasked.
+func (uc *ProxyUseCase) maskResponse(ctx context.Context, response *entity.Response, masking *entity.ResponseMasking) (*entity.Response, error) {
+	principal, _ := entity.PrincipalFromContext(ctx)
+	if masking.Unmasked(principal) {
+		return response, nil
+	}
+	if response.BodyStream != nil {
+		closeResponse(response)
+		return nil, errors.NewError(errors.CodeBadGateway, "streamed response cannot be masked", nil)
+	}
+	if len(response.Body) == 0 {
+		return response, nil
+	}
+	if encoding := http.Header(response.Headers).Get("Content-Encoding"); encoding != "" && !strings.EqualFold(encoding, "identity") {
+		return nil, errors.NewError(errors.CodeBadGateway, fmt.Sprintf("%s encoded response cannot be masked", encoding), nil)
+	}
+	contentType := http.Header(response.Headers).Get("Content-Type")
+	if contentType == "" {
+		contentType = response.ContentType
+	}
+	if !strings.Contains(contentType, "json") {
+		return nil, errors.NewError(errors.CodeBadGateway, "response to mask is not JSON", nil)
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(response.Body))
+	decoder.UseNumber()
+	var document interface{}
+	if err := decoder.Decode(&document); err != nil {
+		logger.FromContextOr(ctx, uc.logger).Warn("Failed to decode response to mask", "error", err)
+		return nil, errors.NewError(errors.CodeBadGateway, "invalid response to mask", err)
+	}
+	for _, rule := range masking.Rules {
+		path, err := jsonpath.Parse(rule.Path)
+		if err != nil {
+			continue
+		}
+		strategy := rule.MaskingStrategy()
+		document = path.Replace(document, func(value interface{}) (interface{}, bool) {
+			return maskValue(value, strategy)
+		})
+	}
+	body, err := json.Marshal(document)
+	if err != nil {
+		logger.FromContextOr(ctx, uc.logger).Warn("Failed to encode masked response", "error", err)
+		return nil, errors.NewError(errors.CodeBadGateway, "failed to encode masked response", err)
+	}
+
+	masked := *response
+	masked.Headers = http.Header(response.Headers).Clone()
+	if masked.Headers != nil {
+		http.Header(masked.Headers).Del("Content-Length")
+	}
+	masked.ContentLength = len(body)
+	masked.Body = body
+	return &masked, nil
+}
+
+// maskValue masks a value with a strategy, reporting false when the value is removed
+func maskValue(value interface{}, strategy string) (interface{}, bool) {
+	if value == nil {
+		return nil, strategy != entity.MaskRemove
+	}
+
+	var text string
+	switch v := value.(type) {
+	case string:
+		text = v
+	case json.Number, bool:
+		text = fmt.Sprint(v)
+	default:
+		// Objects and arrays are masked as a whole
+		if strategy == entity.MaskRemove {
+			return nil, false
+		}
+		if strategy == entity.MaskHash {
+			data, _ := json.Marshal(v)
+			text = string(data)
+			break
+		}
+		return maskedValue, true
+	}
+
+	switch strategy {
+	case entity.MaskRemove:
+		return nil, false
+	case entity.MaskPartial:
+		runes := []rune(text)
+		if len(runes) <= 4 {
+			return maskedValue, true
+		}
+		return strings.Repeat("*", len(runes)-4) + string(runes[len(runes)-4:]), true
+	case entity.MaskEmail:
+		local, domain, ok := strings.Cut(text, "@")
+		if !ok || local == "" {
+			return maskedValue, true
+		}
+		return string([]rune(local)[:1]) + "***@" + domain, true
+	case entity.MaskHash:
+		sum := sha256.Sum256([]byte(text))
+		return hex.EncodeToString(sum[:8]), true
+	default:
+		return maskedValue, true
+	}
+}
@@ -0,0 +1,103 @@
+package usecase
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"api-gateway-sample/internal/domain/entity"
+	"api-gateway-sample/pkg/errors"
+)
+
+func TestProxyUseCase_ResponseMasking(t *testing.T) {
+	service := newTestService("customers", entity.Endpoint{
+		Path:     "/api/v1/customers",
+		Methods:  []string{http.MethodGet},
+		CacheTTL: 60,
+		Masking: &entity.ResponseMasking{
+			Scope: "pii:read",
+			Rules: []entity.MaskingRule{
+				{Path: "$[*].email", Strategy: entity.MaskEmail},
+				{Path: "$..ssn", Strategy: entity.MaskPartial},
+				{Path: "$[*].notes", Strategy: entity.MaskRemove},
+				{Path: "$[*].address"},
+			},
+		},
+	})
+	serviceRepo := newServiceRepository(t, service)
+
+	unmasked := `[{"id":1,"email":"ann@example.com","ssn":"123-45-6789","notes":"VIP","address":{"city":"Lyon"}}]`
+	// The upstream lets the response to authenticated callers be shared
+	gateway := &scriptedGateway{responses: []*entity.Response{{
+		StatusCode: http.StatusOK,
+		Headers:    map[string][]string{"Content-Type": {"application/json"}, "Cache-Control": {"public"}},
+		Body:       []byte(unmasked),
+	}}}
+	cache := &jsonCacheService{jsonCache{entries: map[string][]byte{}}}
+	useCase := NewProxyUseCase(serviceRepo, gateway, nil, nil, cache, &MockLogger{})
+
+	get := func(scopes ...string) string {
+		t.Helper()
+		ctx := entity.ContextWithPrincipal(context.Background(), &entity.Principal{UserID: "caller", Scopes: scopes})
+		response, err := useCase.ProxyRequest(ctx, entity.NewRequest(http.MethodGet, "/api/v1/customers", nil, nil, nil, "10.0.0.1"))
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		return string(response.Body)
+	}
+
+	// 1. Partners see masked data, re-encoded with sorted members
+	masked := `[{"address":"****","email":"a***@example.com","id":1,"ssn":"*******6789"}]`
+	if got := get("customers:read"); got != masked {
+		t.Errorf("Expected %s, got %s", masked, got)
+	}
+
+	// 2. Employees granted the scope see the same cached response in full, and the cache
+	// still holds it unmasked for the next partner
+	if got := get("customers:read", "pii:read"); got != unmasked {
+		t.Errorf("Expected %s, got %s", unmasked, got)
+	}
+	if got := get(); got != masked {
+		t.Errorf("Expected %s, got %s", masked, got)
+	}
+	if len(gateway.requests) != 1 {
+		t.Errorf("Expected a single upstream request, got %d", len(gateway.requests))
+	}
+}
+
+func TestProxyUseCase_ResponseMaskingFailsClosed(t *testing.T) {
+	tests := []struct {
+		name    string
+		headers map[string][]string
+		body    string
+	}{
+		{name: "not JSON", headers: map[string][]string{"Content-Type": {"text/plain"}}, body: "ann@example.com"},
+		{name: "compressed", headers: map[string][]string{"Content-Type": {"application/json"}, "Content-Encoding": {"gzip"}}, body: "\x1f\x8b\x08\x00"},
+		{name: "malformed", headers: map[string][]string{"Content-Type": {"application/json"}}, body: `[{"email":"ann@example.com"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service := newTestService("customers", entity.Endpoint{
+				Path:    "/api/v1/customers",
+				Methods: []string{http.MethodGet},
+				Masking: &entity.ResponseMasking{Rules: []entity.MaskingRule{{Path: "$[*].email"}}},
+			})
+			gateway := &scriptedGateway{responses: []*entity.Response{{StatusCode: http.StatusOK, Headers: tt.headers, Body: []byte(tt.body)}}}
+			useCase := newProxyFixture(t, gateway, service)
+
+			headers := map[string][]string{"Accept-Encoding": {"gzip, br"}}
+			response, err := useCase.ProxyRequest(context.Background(), entity.NewRequest(http.MethodGet, "/api/v1/customers", headers, nil, nil, "10.0.0.1"))
+			if errors.StatusCodeOf(err, 0) != errors.CodeBadGateway {
+				t.Errorf("Expected a bad gateway error, got %v", err)
+			}
+			if response != nil {
+				t.Errorf("Expected no response, got %s", response.Body)
+			}
+			// The upstream is not offered encodings the gateway cannot parse
+			if len(gateway.requests) != 1 || gateway.requests[0].Headers["Accept-Encoding"] != nil {
+				t.Errorf("Expected a single upstream request without Accept-Encoding, got %v", gateway.requests)
+			}
+		})
+	}
+}
@@ -0,0 +1,73 @@
+package usecase
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"api-gateway-sample/internal/domain/entity"
+	"api-gateway-sample/pkg/errors"
+	"api-gateway-sample/pkg/logger"
+)
+
+// mockTarget is the upstream target recorded for requests answered from mock fixtures
+const mockTarget = "mock"
+
+// serveMock answers a request with the first fixture of a mock endpoint that matches it,
+// expanding the placeholders of its headers and body
+func (uc *ProxyUseCase) serveMock(ctx context.Context, request *entity.Request, endpoint *entity.Endpoint, sample *entity.RequestSample) (*entity.Response, error) {
+	sample.Target = mockTarget
+
+	for i := range endpoint.Mock.Fixtures {
+		fixture := &endpoint.Mock.Fixtures[i]
+		if !fixture.Match.Matches(request) {
+			continue
+		}
+
+		headers := make(map[string][]string, len(fixture.Response.Headers)+1)
+		for name, template := range fixture.Response.Headers {
+			value, _ := entity.ExpandPipelineTemplate(template, func(reference string) (string, error) {
+				return resolveMockReference(reference, request), nil
+			})
+			headers[http.CanonicalHeaderKey(name)] = []string{value}
+		}
+		// Values placed in JSON bodies are escaped so that they cannot break the document
+		escape := strings.Contains(http.Header(headers).Get("Content-Type"), "json")
+		body, _ := entity.ExpandPipelineTemplate(fixture.Response.Body, func(reference string) (string, error) {
+			value := resolveMockReference(reference, request)
+			if escape {
+				quoted, _ := json.Marshal(value)
+				value = string(quoted[1 : len(quoted)-1])
+			}
+			return value, nil
+		})
+
+		logger.FromContextOr(ctx, uc.logger).Debug("Request answered from mock fixture", "path", request.Path, "fixture", i)
+		return entity.NewResponse(request.ID, fixture.Response.StatusCode(), headers, []byte(body)), nil
+	}
+
+	return nil, errors.NewError(errors.CodeNotFound, fmt.Sprintf("no mock fixture matches %s %s", request.Method, request.Path), errors.ErrNotFound)
+}
+
+// resolveMockReference returns the value of a placeholder such as "query.id" or "request.id",
+// or an empty string when the request does not carry it
+func resolveMockReference(reference string, request *entity.Request) string {
+	scope, name, _ := strings.Cut(reference, ".")
+	switch scope {
+	case entity.MockRequestScope:
+		switch name {
+		case "id":
+			return request.ID
+		case "method":
+			return request.Method
+		case "path":
+			return request.Path
+		}
+		return ""
+	default:
+		value, _ := resolveBridgeReference(reference, request)
+		return value
+	}
+}
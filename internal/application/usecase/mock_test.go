@@ -0,0 +1,65 @@
+package usecase
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"api-gateway-sample/internal/domain/entity"
+	"api-gateway-sample/pkg/errors"
+)
+
+func TestProxyUseCase_MockEndpoint(t *testing.T) {
+	ctx := context.Background()
+	service := newTestService("users", entity.Endpoint{
+		Path:    "/api/v1/users",
+		Methods: []string{http.MethodGet, http.MethodPost},
+		Mock: &entity.Mock{Fixtures: []entity.MockFixture{
+			{
+				Match:    entity.MockMatch{Method: http.MethodGet, Query: map[string]string{"id": "missing"}},
+				Response: entity.MockResponse{Status: http.StatusNotFound},
+			},
+			{
+				Match: entity.MockMatch{Method: http.MethodGet},
+				Response: entity.MockResponse{
+					Headers: map[string]string{"Content-Type": "application/json", "X-Request-ID": "{{request.id}}"},
+					Body:    `{"id":"{{query.id}}","tenant":"{{header.X-Tenant-ID}}"}`,
+				},
+			},
+		}},
+	})
+
+	gateway := &countingGateway{}
+	useCase := newProxyFixture(t, gateway, service)
+	newRequest := func(method string, query map[string][]string) *entity.Request {
+		return entity.NewRequest(method, "/api/v1/users", map[string][]string{"X-Tenant-Id": {"acme"}}, query, nil, "10.0.0.1")
+	}
+
+	// 1. The first matching fixture is served, with its placeholders expanded and escaped for JSON
+	request := newRequest(http.MethodGet, map[string][]string{"id": {`4"2`}})
+	response, err := useCase.ProxyRequest(ctx, request)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if response.StatusCode != http.StatusOK || string(response.Body) != `{"id":"4\"2","tenant":"acme"}` {
+		t.Errorf("Expected the templated fixture, got %d %s", response.StatusCode, response.Body)
+	}
+	if got := http.Header(response.Headers).Get("X-Request-ID"); got != request.ID {
+		t.Errorf("Expected X-Request-ID %s, got %s", request.ID, got)
+	}
+
+	// 2. Fixtures are tried in order
+	response, err = useCase.ProxyRequest(ctx, newRequest(http.MethodGet, map[string][]string{"id": {"missing"}}))
+	if err != nil || response.StatusCode != http.StatusNotFound {
+		t.Errorf("Expected the 404 fixture, got %v %v", response, err)
+	}
+
+	// 3. Requests without a matching fixture are answered 404
+	if _, err := useCase.ProxyRequest(ctx, newRequest(http.MethodPost, map[string][]string{})); errors.StatusCodeOf(err, 0) != http.StatusNotFound {
+		t.Errorf("Expected 404 without a matching fixture, got %v", err)
+	}
+
+	if gateway.calls != 0 {
+		t.Errorf("Expected mock endpoints not to reach the service, got %d calls", gateway.calls)
+	}
+}
@@ -0,0 +1,127 @@
+package usecase
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"strings"
+	"time"
+
+	"api-gateway-sample/internal/domain/repository"
+	"api-gateway-sample/internal/domain/service"
+	"api-gateway-sample/pkg/errors"
+	"api-gateway-sample/pkg/logger"
+)
+
+// oidcLoginCachePrefix prefixes the cache keys of the logins in progress, keyed by their state
+const oidcLoginCachePrefix = "oidc-login:"
+
+// oidcLogin is a login in progress, kept between the redirect to the provider and the callback
+type oidcLogin struct {
+	CodeVerifier string `json:"codeVerifier"`
+	Nonce        string `json:"nonce"`
+	ReturnTo     string `json:"returnTo"`
+}
+
+// OIDCUseCase implements the OpenID Connect authorization code flow with PKCE for browser
+// applications behind the gateway. Users are sent to the identity provider to log in and, on
+// their return, issued a gateway token that serves as their session.
+type OIDCUseCase struct {
+	provider     service.OIDCProvider
+	authService  service.AuthService
+	cache        repository.CacheRepository
+	loginTimeout time.Duration
+	logger       logger.Logger
+}
+
+// NewOIDCUseCase creates a new OIDCUseCase instance. Logins in progress are kept in the cache,
+// which must be shared by the gateway instances, and expire after loginTimeout.
+func NewOIDCUseCase(
+	provider service.OIDCProvider,
+	authService service.AuthService,
+	cache repository.CacheRepository,
+	loginTimeout time.Duration,
+	logger logger.Logger,
+) *OIDCUseCase {
+	return &OIDCUseCase{
+		provider:     provider,
+		authService:  authService,
+		cache:        cache,
+		loginTimeout: loginTimeout,
+		logger:       logger,
+	}
+}
+
+// BeginLogin starts a login and returns the provider URL to redirect the user to. Users return
+// to returnTo once logged in; it must be a path on the gateway and defaults to /.
+func (uc *OIDCUseCase) BeginLogin(ctx context.Context, returnTo string) (string, error) {
+	state, err := randomToken()
+	if err != nil {
+		return "", err
+	}
+	login := oidcLogin{ReturnTo: localPath(returnTo)}
+	if login.CodeVerifier, err = randomToken(); err != nil {
+		return "", err
+	}
+	if login.Nonce, err = randomToken(); err != nil {
+		return "", err
+	}
+
+	if err := uc.cache.Set(ctx, oidcLoginCachePrefix+state, login, uc.loginTimeout); err != nil {
+		return "", err
+	}
+
+	challenge := sha256.Sum256([]byte(login.CodeVerifier))
+	return uc.provider.AuthorizationURL(ctx, state, login.Nonce, base64.RawURLEncoding.EncodeToString(challenge[:]))
+}
+
+// CompleteLogin redeems the authorization code the provider returned for the login with the
+// given state and issues the user's session token. It returns the token and the path to send
+// the user back to. Each login completes at most once.
+func (uc *OIDCUseCase) CompleteLogin(ctx context.Context, state string, code string) (string, string, error) {
+	if state == "" || code == "" {
+		return "", "", errors.NewError(errors.CodeInvalidInput, "state and code are required", errors.ErrInvalidInput)
+	}
+
+	key := oidcLoginCachePrefix + state
+	var login oidcLogin
+	if err := uc.cache.Get(ctx, key, &login); err != nil {
+		return "", "", errors.NewError(errors.CodeUnauthorized, "login expired or unknown", errors.ErrUnauthorized)
+	}
+	if err := uc.cache.Delete(ctx, key); err != nil {
+		return "", "", err
+	}
+
+	claims, err := uc.provider.Exchange(ctx, code, login.CodeVerifier, login.Nonce)
+	if err != nil {
+		logger.FromContextOr(ctx, uc.logger).Warn("OIDC code exchange failed", "error", err)
+		return "", "", errors.NewError(errors.CodeUnauthorized, "login failed", errors.ErrUnauthorized)
+	}
+
+	userID, _ := claims["sub"].(string)
+	token, err := uc.authService.GenerateToken(ctx, userID, claims)
+	if err != nil {
+		return "", "", err
+	}
+	logger.FromContextOr(ctx, uc.logger).Info("OIDC login completed", logger.FieldUserID, userID)
+	return token, login.ReturnTo, nil
+}
+
+// randomToken returns an unguessable URL-safe token, long enough to serve as a PKCE code verifier
+func randomToken() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// localPath returns path if it is a path on the gateway, else /, so that the login cannot be
+// used to redirect users to another site
+func localPath(path string) string {
+	if !strings.HasPrefix(path, "/") || strings.HasPrefix(path, "//") || strings.ContainsAny(path, "\\\r\n") {
+		return "/"
+	}
+	return path
+}
@@ -0,0 +1,115 @@
+package usecase
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"fmt"
+	"net/url"
+	"testing"
+	"time"
+
+	"api-gateway-sample/internal/domain/service"
+	"api-gateway-sample/pkg/errors"
+)
+
+// fakeOIDCProvider issues authorization URLs carrying the login parameters and accepts the
+// code "good" when the verifier matches the challenge of the last login
+type fakeOIDCProvider struct {
+	challenge string
+	nonce     string
+}
+
+func (p *fakeOIDCProvider) AuthorizationURL(ctx context.Context, state string, nonce string, codeChallenge string) (string, error) {
+	p.challenge = codeChallenge
+	p.nonce = nonce
+	return "https://idp.example.com/authorize?" + url.Values{"state": {state}}.Encode(), nil
+}
+
+func (p *fakeOIDCProvider) Exchange(ctx context.Context, code string, codeVerifier string, nonce string) (map[string]interface{}, error) {
+	sum := sha256.Sum256([]byte(codeVerifier))
+	if code != "good" || base64.RawURLEncoding.EncodeToString(sum[:]) != p.challenge || nonce != p.nonce {
+		return nil, fmt.Errorf("invalid grant")
+	}
+	return map[string]interface{}{"sub": "alice", "roles": []string{"viewer"}}, nil
+}
+
+// tokenIssuer issues tokens naming their subject
+type tokenIssuer struct {
+	service.AuthService
+}
+
+func (tokenIssuer) GenerateToken(ctx context.Context, userID string, claims map[string]interface{}) (string, error) {
+	return "token-" + userID, nil
+}
+
+func TestOIDCUseCase_Login(t *testing.T) {
+	ctx := context.Background()
+	provider := &fakeOIDCProvider{}
+	uc := NewOIDCUseCase(provider, tokenIssuer{}, &jsonCache{entries: map[string][]byte{}}, time.Minute, &MockLogger{})
+
+	// 1. Beginning a login redirects to the provider with a state
+	authorizationURL, err := uc.BeginLogin(ctx, "/app/orders")
+	if err != nil {
+		t.Fatalf("BeginLogin failed: %v", err)
+	}
+	parsed, _ := url.Parse(authorizationURL)
+	state := parsed.Query().Get("state")
+	if state == "" {
+		t.Fatalf("Expected a state in %s", authorizationURL)
+	}
+
+	// 2. An unknown state is rejected
+	if _, _, err := uc.CompleteLogin(ctx, "other", "good"); !errors.IsUnauthorized(err) {
+		t.Errorf("Expected unauthorized for an unknown state, got %v", err)
+	}
+
+	// 3. The code is redeemed with the login's verifier and a session token is issued
+	token, returnTo, err := uc.CompleteLogin(ctx, state, "good")
+	if err != nil {
+		t.Fatalf("CompleteLogin failed: %v", err)
+	}
+	if token != "token-alice" {
+		t.Errorf("Expected token-alice, got %s", token)
+	}
+	if returnTo != "/app/orders" {
+		t.Errorf("Expected to return to /app/orders, got %s", returnTo)
+	}
+
+	// 4. A login completes only once
+	if _, _, err := uc.CompleteLogin(ctx, state, "good"); !errors.IsUnauthorized(err) {
+		t.Errorf("Expected unauthorized for a replayed state, got %v", err)
+	}
+}
+
+func TestOIDCUseCase_RejectedCode(t *testing.T) {
+	ctx := context.Background()
+	uc := NewOIDCUseCase(&fakeOIDCProvider{}, tokenIssuer{}, &jsonCache{entries: map[string][]byte{}}, time.Minute, &MockLogger{})
+
+	authorizationURL, err := uc.BeginLogin(ctx, "")
+	if err != nil {
+		t.Fatalf("BeginLogin failed: %v", err)
+	}
+	parsed, _ := url.Parse(authorizationURL)
+
+	if _, _, err := uc.CompleteLogin(ctx, parsed.Query().Get("state"), "bad"); !errors.IsUnauthorized(err) {
+		t.Errorf("Expected unauthorized for a rejected code, got %v", err)
+	}
+}
+
+func TestLocalPath(t *testing.T) {
+	testCases := map[string]string{
+		"":                         "/",
+		"/app":                     "/app",
+		"/app?tab=orders":          "/app?tab=orders",
+		"https://evil.example.com": "/",
+		"//evil.example.com":       "/",
+		"/\\evil.example.com":      "/",
+		"app":                      "/",
+	}
+	for path, expected := range testCases {
+		if got := localPath(path); got != expected {
+			t.Errorf("localPath(%q) = %q, expected %q", path, got, expected)
+		}
+	}
+}
@@ -0,0 +1,154 @@
+package usecase
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"api-gateway-sample/internal/domain/entity"
+	"api-gateway-sample/pkg/errors"
+	"api-gateway-sample/pkg/logger"
+)
+
+// runPipeline calls the steps of a pipeline endpoint one after the other, expanding the
+// placeholders of each step from the caller's query and the responses of earlier steps, and
+// merges their JSON responses under the step names. The steps share the endpoint's combined timeout.
+func (uc *ProxyUseCase) runPipeline(ctx context.Context, request *entity.Request, endpoint *entity.Endpoint, sample *entity.RequestSample) (*entity.Response, error) {
+	pipeline := endpoint.Pipeline
+	if timeout := pipeline.TimeoutDuration(); timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+
+	log := logger.FromContextOr(ctx, uc.logger)
+	upstreamStart := time.Now()
+	outputs := make(map[string]json.RawMessage, len(pipeline.Steps))
+
+steps:
+	for i := range pipeline.Steps {
+		step := &pipeline.Steps[i]
+		body, err := uc.runPipelineStep(ctx, request, step, outputs)
+		if err == nil {
+			outputs[step.Name] = body
+			continue
+		}
+
+		switch step.ErrorPolicy() {
+		case entity.PipelineOnErrorContinue:
+			log.Warn("Pipeline step failed, continuing", "step", step.Name, "error", err)
+			outputs[step.Name] = json.RawMessage("null")
+		case entity.PipelineOnErrorStop:
+			log.Warn("Pipeline step failed, stopping", "step", step.Name, "error", err)
+			break steps
+		default:
+			sample.UpstreamLatency = time.Since(upstreamStart)
+			sample.UpstreamFailed = true
+			if ctx.Err() == context.DeadlineExceeded {
+				return nil, errors.NewError(errors.CodeTimeout, fmt.Sprintf("pipeline step %s timed out", step.Name), errors.ErrTimeout)
+			}
+			return nil, errors.NewError(errors.CodeBadGateway, fmt.Sprintf("pipeline step %s failed", step.Name), err)
+		}
+	}
+	sample.UpstreamLatency = time.Since(upstreamStart)
+
+	body, err := json.Marshal(outputs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to merge pipeline responses: %w", err)
+	}
+
+	response := entity.NewResponse(request.ID, http.StatusOK, map[string][]string{"Content-Type": {"application/json"}}, body)
+	response.SetLatency(upstreamStart)
+	return response, nil
+}
+
+// runPipelineStep expands the placeholders of a step and calls its route
+func (uc *ProxyUseCase) runPipelineStep(ctx context.Context, request *entity.Request, step *entity.PipelineStep, outputs map[string]json.RawMessage) (json.RawMessage, error) {
+	resolve := func(reference string) (string, error) {
+		return resolvePipelineReference(reference, request, outputs)
+	}
+
+	path, err := entity.ExpandPipelineTemplate(step.Path, func(reference string) (string, error) {
+		value, err := resolve(reference)
+		return url.PathEscape(value), err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	query := make(map[string][]string, len(step.Query))
+	for name, template := range step.Query {
+		value, err := entity.ExpandPipelineTemplate(template, resolve)
+		if err != nil {
+			return nil, err
+		}
+		query[name] = []string{value}
+	}
+
+	return uc.callRoute(ctx, request, step.StepMethod(), path, query)
+}
+
+// resolvePipelineReference returns the value of a placeholder such as "query.id" or "user.address.city".
+// Array elements are selected by their index, as in "orders.0.id".
+func resolvePipelineReference(reference string, request *entity.Request, outputs map[string]json.RawMessage) (string, error) {
+	fields := strings.Split(reference, ".")
+
+	if fields[0] == entity.PipelineQueryScope {
+		if len(fields) != 2 {
+			return "", fmt.Errorf("invalid query placeholder %s", reference)
+		}
+		values := request.QueryParams[fields[1]]
+		if len(values) == 0 {
+			return "", fmt.Errorf("query parameter %s is missing", fields[1])
+		}
+		return values[0], nil
+	}
+
+	output, ok := outputs[fields[0]]
+	if !ok {
+		return "", fmt.Errorf("step %s has no response", fields[0])
+	}
+	decoder := json.NewDecoder(bytes.NewReader(output))
+	decoder.UseNumber()
+	var value interface{}
+	if err := decoder.Decode(&value); err != nil {
+		return "", fmt.Errorf("step %s returned invalid JSON: %w", fields[0], err)
+	}
+
+	for _, field := range fields[1:] {
+		switch node := value.(type) {
+		case map[string]interface{}:
+			value, ok = node[field]
+		case []interface{}:
+			index, err := strconv.Atoi(field)
+			ok = err == nil && index >= 0 && index < len(node)
+			if ok {
+				value = node[index]
+			}
+		default:
+			ok = false
+		}
+		if !ok {
+			return "", fmt.Errorf("%s not found in the response of step %s", reference, fields[0])
+		}
+	}
+
+	switch scalar := value.(type) {
+	case string:
+		return scalar, nil
+	case json.Number:
+		return scalar.String(), nil
+	case bool:
+		return strconv.FormatBool(scalar), nil
+	case nil:
+		return "", fmt.Errorf("%s is null", reference)
+	default:
+		return "", fmt.Errorf("%s is not a string, number or boolean", reference)
+	}
+}
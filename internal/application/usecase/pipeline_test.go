@@ -0,0 +1,115 @@
+package usecase
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"api-gateway-sample/internal/domain/entity"
+	"api-gateway-sample/pkg/errors"
+)
+
+// recordingRouteGateway answers routed requests by path and records the requests it receives
+type recordingRouteGateway struct {
+	routeGateway
+	requests []*entity.Request
+}
+
+func (g *recordingRouteGateway) RouteRequest(ctx context.Context, request *entity.Request) (*entity.Response, error) {
+	g.requests = append(g.requests, request)
+	return g.routeGateway.RouteRequest(ctx, request)
+}
+
+// newPipelineFixture creates a proxy use case with a profile endpoint that looks up a user and then their orders
+func newPipelineFixture(t *testing.T, routes map[string]routeResponse, ordersOnError string) (*ProxyUseCase, *recordingRouteGateway) {
+	t.Helper()
+	users := newTestService("users",
+		entity.Endpoint{Path: "/api/v1/users/lookup", Methods: []string{http.MethodGet}},
+		entity.Endpoint{Path: "/api/v1/users/42/orders", Methods: []string{http.MethodGet}},
+	)
+	bff := newTestService("bff", entity.Endpoint{
+		Path:    "/api/v1/profile",
+		Methods: []string{http.MethodGet},
+		Pipeline: &entity.Pipeline{
+			Steps: []entity.PipelineStep{
+				{Name: "user", Path: "/api/v1/users/lookup", Query: map[string]string{"email": "{{query.email}}"}},
+				{Name: "orders", Path: "/api/v1/users/{{user.id}}/orders", Query: map[string]string{"region": "{{user.address.region}}"}, OnError: ordersOnError},
+			},
+		},
+	})
+
+	gateway := &recordingRouteGateway{routeGateway: routeGateway{routes: routes}}
+	return newProxyFixture(t, gateway, users, bff), gateway
+}
+
+func newProfileRequest() *entity.Request {
+	query := map[string][]string{"email": {"ada@example.com"}}
+	return entity.NewRequest(http.MethodGet, "/api/v1/profile", map[string][]string{}, query, nil, "127.0.0.1")
+}
+
+func TestProxyUseCase_PipelinePassesData(t *testing.T) {
+	useCase, gateway := newPipelineFixture(t, map[string]routeResponse{
+		"/api/v1/users/lookup":    {status: http.StatusOK, body: `{"id":42,"address":{"region":"eu"}}`},
+		"/api/v1/users/42/orders": {status: http.StatusOK, body: `[{"id":"order-1"}]`},
+	}, "")
+
+	response, err := useCase.ProxyRequest(context.Background(), newProfileRequest())
+	if err != nil {
+		t.Fatalf("Failed to run pipeline: %v", err)
+	}
+
+	// 1. Each step is expanded from the caller's query and the earlier responses
+	if len(gateway.requests) != 2 {
+		t.Fatalf("Expected two steps to run, got %d", len(gateway.requests))
+	}
+	if email := gateway.requests[0].QueryParams["email"]; len(email) != 1 || email[0] != "ada@example.com" {
+		t.Errorf("Expected the caller's email in the lookup, got %v", gateway.requests[0].QueryParams)
+	}
+	if region := gateway.requests[1].QueryParams["region"]; len(region) != 1 || region[0] != "eu" {
+		t.Errorf("Expected the user's region in the orders query, got %v", gateway.requests[1].QueryParams)
+	}
+
+	// 2. The responses are merged under the step names
+	if string(response.Body) != `{"orders":[{"id":"order-1"}],"user":{"id":42,"address":{"region":"eu"}}}` {
+		t.Errorf("Unexpected merged body %s", response.Body)
+	}
+}
+
+func TestProxyUseCase_PipelineErrorPolicies(t *testing.T) {
+	ctx := context.Background()
+	failingOrders := map[string]routeResponse{
+		"/api/v1/users/lookup":    {status: http.StatusOK, body: `{"id":42,"address":{"region":"eu"}}`},
+		"/api/v1/users/42/orders": {status: http.StatusServiceUnavailable},
+	}
+
+	// 1. A failing step fails the request by default
+	useCase, _ := newPipelineFixture(t, failingOrders, "")
+	if _, err := useCase.ProxyRequest(ctx, newProfileRequest()); errors.StatusCodeOf(err, 0) != errors.CodeBadGateway {
+		t.Errorf("Expected a bad gateway error, got %v", err)
+	}
+
+	// 2. "continue" merges the failed step as null
+	useCase, _ = newPipelineFixture(t, failingOrders, entity.PipelineOnErrorContinue)
+	response, err := useCase.ProxyRequest(ctx, newProfileRequest())
+	if err != nil || string(response.Body) != `{"orders":null,"user":{"id":42,"address":{"region":"eu"}}}` {
+		t.Errorf("Expected the failed step as null, got %v %v", response, err)
+	}
+
+	// 3. "stop" returns the steps run so far
+	useCase, _ = newPipelineFixture(t, failingOrders, entity.PipelineOnErrorStop)
+	response, err = useCase.ProxyRequest(ctx, newProfileRequest())
+	if err != nil || string(response.Body) != `{"user":{"id":42,"address":{"region":"eu"}}}` {
+		t.Errorf("Expected only the user step, got %v %v", response, err)
+	}
+
+	// 4. A placeholder that cannot be resolved fails its step
+	useCase, gateway := newPipelineFixture(t, map[string]routeResponse{
+		"/api/v1/users/lookup": {status: http.StatusOK, body: `{"id":42}`},
+	}, "")
+	if _, err := useCase.ProxyRequest(ctx, newProfileRequest()); errors.StatusCodeOf(err, 0) != errors.CodeBadGateway {
+		t.Errorf("Expected the unresolved placeholder to fail the request, got %v", err)
+	}
+	if len(gateway.requests) != 1 {
+		t.Errorf("Expected the orders step not to be called, got %d requests", len(gateway.requests))
+	}
+}
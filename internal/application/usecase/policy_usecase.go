@@ -0,0 +1,33 @@
+package usecase
+
+import (
+	"context"
+
+	"api-gateway-sample/internal/domain/entity"
+	"api-gateway-sample/internal/domain/service"
+	"api-gateway-sample/pkg/logger"
+)
+
+// PolicyUseCase implements the use case for managing authorization policies
+type PolicyUseCase struct {
+	policyEngine service.PolicyEngine
+	logger       logger.Logger
+}
+
+// NewPolicyUseCase creates a new PolicyUseCase instance
+func NewPolicyUseCase(policyEngine service.PolicyEngine, logger logger.Logger) *PolicyUseCase {
+	return &PolicyUseCase{
+		policyEngine: policyEngine,
+		logger:       logger,
+	}
+}
+
+// ValidatePolicy checks that a policy compiles
+func (uc *PolicyUseCase) ValidatePolicy(policy string) error {
+	return uc.policyEngine.Compile(policy)
+}
+
+// TestPolicy evaluates a policy against an arbitrary input without affecting live traffic
+func (uc *PolicyUseCase) TestPolicy(ctx context.Context, policy string, input *entity.PolicyInput) (bool, error) {
+	return uc.policyEngine.Evaluate(ctx, policy, input)
+}
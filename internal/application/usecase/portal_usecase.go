@@ -0,0 +1,117 @@
+package usecase
+
+import (
+	"context"
+	"net/mail"
+	"sort"
+	"strings"
+	"time"
+
+	"api-gateway-sample/internal/application/dto"
+	"api-gateway-sample/internal/domain/entity"
+	"api-gateway-sample/internal/domain/repository"
+	"api-gateway-sample/internal/domain/service"
+	"api-gateway-sample/pkg/errors"
+	"api-gateway-sample/pkg/logger"
+)
+
+// PortalUseCase implements the read-only developer portal: the catalog of published
+// services, their OpenAPI documents and API key signups
+type PortalUseCase struct {
+	serviceRepo repository.ServiceRepository
+	apiKeyRepo  repository.APIKeyRepository
+	events      service.EventPublisher
+	logger      logger.Logger
+}
+
+// NewPortalUseCase creates a new PortalUseCase instance
+func NewPortalUseCase(serviceRepo repository.ServiceRepository, apiKeyRepo repository.APIKeyRepository, events service.EventPublisher, logger logger.Logger) *PortalUseCase {
+	return &PortalUseCase{
+		serviceRepo: serviceRepo,
+		apiKeyRepo:  apiKeyRepo,
+		events:      events,
+		logger:      logger,
+	}
+}
+
+// ListServices returns the published services ordered by name
+func (uc *PortalUseCase) ListServices(ctx context.Context) ([]*dto.PortalServiceResponse, error) {
+	services, err := uc.serviceRepo.GetAll(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	responses := make([]*dto.PortalServiceResponse, 0, len(services))
+	for _, service := range services {
+		if service.Published {
+			responses = append(responses, dto.FromPortalEntity(service))
+		}
+	}
+	sort.Slice(responses, func(i, j int) bool {
+		return responses[i].Name < responses[j].Name
+	})
+	return responses, nil
+}
+
+// GetService returns a published service. Unpublished services are reported as not found.
+func (uc *PortalUseCase) GetService(ctx context.Context, id string) (*dto.PortalServiceResponse, error) {
+	service, err := uc.publishedService(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	return dto.FromPortalEntity(service), nil
+}
+
+// OpenAPIDocument returns the OpenAPI document of a published service, served from serverURL
+func (uc *PortalUseCase) OpenAPIDocument(ctx context.Context, id string, serverURL string) (*dto.OpenAPIDocument, error) {
+	service, err := uc.publishedService(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	return dto.NewOpenAPIDocument(service, serverURL), nil
+}
+
+// SignUp records a consumer's request for an API key to a published service.
+// The key stays pending until an administrator reviews it.
+func (uc *PortalUseCase) SignUp(ctx context.Context, serviceID string, req *dto.APIKeySignupRequest) (*dto.APIKeyResponse, error) {
+	if _, err := uc.publishedService(ctx, serviceID); err != nil {
+		return nil, err
+	}
+
+	consumer := strings.TrimSpace(req.Consumer)
+	if consumer == "" {
+		return nil, errors.NewError(errors.CodeInvalidInput, "consumer is required", errors.ErrInvalidInput)
+	}
+	address, err := mail.ParseAddress(req.Email)
+	if err != nil {
+		return nil, errors.NewError(errors.CodeInvalidInput, "email must be a valid address", errors.ErrInvalidInput)
+	}
+
+	key := &entity.APIKey{
+		ID:        entity.NewRequestID(),
+		ServiceID: serviceID,
+		Consumer:  consumer,
+		Email:     address.Address,
+		Purpose:   strings.TrimSpace(req.Purpose),
+		Status:    entity.APIKeyStatusPending,
+		CreatedAt: time.Now(),
+	}
+	if err := uc.apiKeyRepo.Create(ctx, key); err != nil {
+		return nil, err
+	}
+	publishAPIKeyEvent(ctx, uc.events, entity.EventAPIKeyRequested, key)
+
+	logger.FromContextOr(ctx, uc.logger).Info("API key requested", "key_id", key.ID, "service_id", serviceID, "consumer", consumer)
+	return dto.FromAPIKeyEntity(key), nil
+}
+
+func (uc *PortalUseCase) publishedService(ctx context.Context, id string) (*entity.Service, error) {
+	service, err := uc.serviceRepo.Get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if !service.Published {
+		return nil, errors.ErrNotFound
+	}
+	return service, nil
+}
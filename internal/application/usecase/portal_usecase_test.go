@@ -0,0 +1,96 @@
+package usecase
+
+import (
+	"context"
+	"testing"
+
+	"api-gateway-sample/internal/application/dto"
+	"api-gateway-sample/internal/domain/entity"
+	"api-gateway-sample/internal/domain/repository"
+	"api-gateway-sample/internal/domain/repository/mock"
+	"api-gateway-sample/pkg/errors"
+)
+
+// newPortalFixture creates a portal with a published "orders" and an unpublished "billing" service
+func newPortalFixture(t *testing.T) (*PortalUseCase, repository.APIKeyRepository) {
+	t.Helper()
+	orders := entity.NewService("orders-id", "orders", "2.1.0", "Order management", "http://orders.internal:8080", 30, 3)
+	orders.Published = true
+	orders.AddEndpoint(entity.Endpoint{Path: "/api/v1/orders/{id}", Methods: []string{"GET", "DELETE"}, AuthRequired: true, RateLimit: 60})
+	billing := entity.NewService("billing-id", "billing", "1.0.0", "Internal billing", "http://billing.internal:8080", 30, 3)
+	serviceRepo := newServiceRepository(t, orders, billing)
+
+	apiKeyRepo := mock.NewAPIKeyRepositoryMock()
+	return NewPortalUseCase(serviceRepo, apiKeyRepo, nil, &MockLogger{}), apiKeyRepo
+}
+
+func TestPortalUseCase_Catalog(t *testing.T) {
+	ctx := context.Background()
+	useCase, _ := newPortalFixture(t)
+
+	// 1. Only published services are listed
+	services, err := useCase.ListServices(ctx)
+	if err != nil {
+		t.Fatalf("Failed to list services: %v", err)
+	}
+	if len(services) != 1 || services[0].Name != "orders" {
+		t.Fatalf("Expected only the published orders service, got %+v", services)
+	}
+
+	if _, err := useCase.GetService(ctx, "billing-id"); !errors.IsNotFound(err) {
+		t.Errorf("Expected unpublished service to be not found, got %v", err)
+	}
+
+	// 2. The OpenAPI document describes the gateway paths, not the upstream
+	doc, err := useCase.OpenAPIDocument(ctx, "orders-id", "https://gateway.example.com")
+	if err != nil {
+		t.Fatalf("Failed to build OpenAPI document: %v", err)
+	}
+	if doc.Info.Version != "2.1.0" || doc.Servers[0].URL != "https://gateway.example.com" {
+		t.Errorf("Unexpected document info %+v servers %+v", doc.Info, doc.Servers)
+	}
+
+	operation, ok := doc.Paths["/api/v1/orders/{id}"]["get"]
+	if !ok {
+		t.Fatalf("Expected a GET operation, got %+v", doc.Paths)
+	}
+	if operation.OperationID != "getApiV1OrdersId" {
+		t.Errorf("Expected operationId getApiV1OrdersId, got %s", operation.OperationID)
+	}
+	if len(operation.Parameters) != 1 || operation.Parameters[0].Name != "id" {
+		t.Errorf("Expected the id path parameter, got %+v", operation.Parameters)
+	}
+	if len(operation.Security) != 1 || doc.Components == nil {
+		t.Errorf("Expected bearer authentication on the operation")
+	}
+}
+
+func TestPortalUseCase_SignUp(t *testing.T) {
+	ctx := context.Background()
+	useCase, apiKeyRepo := newPortalFixture(t)
+
+	key, err := useCase.SignUp(ctx, "orders-id", &dto.APIKeySignupRequest{
+		Consumer: " Mobile App ",
+		Email:    "Mobile Team <mobile@example.com>",
+		Purpose:  "Order history screen",
+	})
+	if err != nil {
+		t.Fatalf("Failed to sign up: %v", err)
+	}
+	if key.Status != entity.APIKeyStatusPending || key.Consumer != "Mobile App" || key.Email != "mobile@example.com" {
+		t.Errorf("Unexpected key %+v", key)
+	}
+	if _, err := apiKeyRepo.Get(ctx, key.ID); err != nil {
+		t.Errorf("Expected the key request to be stored: %v", err)
+	}
+
+	invalid := map[string]*dto.APIKeySignupRequest{
+		"orders-id":  {Consumer: "Mobile App", Email: "not-an-email"},
+		"billing-id": {Consumer: "Mobile App", Email: "mobile@example.com"},
+	}
+	for serviceID, req := range invalid {
+		if _, err := useCase.SignUp(ctx, serviceID, req); err == nil {
+			t.Errorf("Expected signup for %s with %+v to fail", serviceID, req)
+		}
+	}
+}
@@ -0,0 +1,200 @@
+package usecase
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"api-gateway-sample/internal/domain/entity"
+	"api-gateway-sample/pkg/errors"
+	"api-gateway-sample/pkg/logger"
+)
+
+// HeaderPriority lets a client lower the priority class of its own requests, e.g. for batch traffic
+const HeaderPriority = "X-Priority"
+
+// claimPlan is the principal claim naming the caller's plan, set from the plan of API keys
+const claimPlan = "plan"
+
+// priorityScheduler bounds the requests an instance forwards at once. Requests over the limit
+// wait in a queue per priority class and are served highest class first; when the queue is
+// full, the newest waiter of a lower class is shed to make room, so low priority traffic is
+// delayed and rejected before the rest.
+type priorityScheduler struct {
+	maxInFlight int
+	queueSize   int
+	// maxWait bounds how long a request waits in the queue
+	maxWait time.Duration
+	// plans maps caller plans to priority classes
+	plans        map[string]string
+	defaultClass string
+
+	mu       sync.Mutex
+	inFlight int
+	// queues holds the waiting requests of each class by rank, oldest first
+	queues [][]*priorityWaiter
+	queued int
+}
+
+// priorityWaiter is a queued request. ready receives true when the request is given a slot
+// and false when it is shed.
+type priorityWaiter struct {
+	ready chan bool
+}
+
+// SetPriorityScheduler forwards at most maxInFlight requests at once. Up to queueSize more wait
+// for at most maxWait, served by priority class: the class mapped from the caller's plan, the
+// default class otherwise, or a lower class requested in the X-Priority header.
+func (uc *ProxyUseCase) SetPriorityScheduler(maxInFlight int, queueSize int, maxWait time.Duration, plans map[string]string, defaultClass string) {
+	// Plans are matched case-insensitively, as configuration keys are lowercased
+	planClasses := make(map[string]string, len(plans))
+	for plan, class := range plans {
+		planClasses[strings.ToLower(plan)] = class
+	}
+
+	uc.priority = &priorityScheduler{
+		maxInFlight:  maxInFlight,
+		queueSize:    queueSize,
+		maxWait:      maxWait,
+		plans:        planClasses,
+		defaultClass: defaultClass,
+		// One queue for each of the low, normal and high classes
+		queues: make([][]*priorityWaiter, 3),
+	}
+}
+
+// schedule waits for a slot to forward the request and returns the function that gives it back.
+// It fails with 503 when the request is shed or waits too long.
+func (uc *ProxyUseCase) schedule(ctx context.Context, request *entity.Request) (func(), error) {
+	class := uc.priority.classify(ctx, request)
+	release, err := uc.priority.acquire(ctx, class)
+	if err != nil {
+		logger.FromContextOr(ctx, uc.logger).Warn("Request shed under load", "priority", class, "error", err)
+		return nil, err
+	}
+	return release, nil
+}
+
+// classify returns the priority class of a request
+func (s *priorityScheduler) classify(ctx context.Context, request *entity.Request) string {
+	class := s.defaultClass
+	if principal, ok := entity.PrincipalFromContext(ctx); ok {
+		if plan, ok := principal.Claims[claimPlan].(string); ok {
+			if planClass, ok := s.plans[strings.ToLower(plan)]; ok {
+				class = planClass
+			}
+		}
+	}
+
+	// Clients may lower the priority of their requests, but not raise it
+	requested := strings.ToLower(strings.TrimSpace(http.Header(request.Headers).Get(HeaderPriority)))
+	if requestedRank, ok := entity.PriorityRank(requested); ok {
+		if rank, _ := entity.PriorityRank(class); requestedRank < rank {
+			class = requested
+		}
+	}
+	return class
+}
+
+// acquire takes a slot, waiting in the queue of the class while every slot is in use
+func (s *priorityScheduler) acquire(ctx context.Context, class string) (func(), error) {
+	rank, _ := entity.PriorityRank(class)
+
+	s.mu.Lock()
+	if s.inFlight < s.maxInFlight {
+		s.inFlight++
+		s.mu.Unlock()
+		return s.release, nil
+	}
+	if s.queued >= s.queueSize && !s.shedLocked(rank) {
+		s.mu.Unlock()
+		return nil, errOverloaded()
+	}
+	waiter := &priorityWaiter{ready: make(chan bool, 1)}
+	s.queues[rank] = append(s.queues[rank], waiter)
+	s.queued++
+	s.mu.Unlock()
+
+	timer := time.NewTimer(s.maxWait)
+	defer timer.Stop()
+
+	var err error
+	select {
+	case granted := <-waiter.ready:
+		if !granted {
+			return nil, errOverloaded()
+		}
+		return s.release, nil
+	case <-timer.C:
+		err = errOverloaded()
+	case <-ctx.Done():
+		err = ctx.Err()
+	}
+
+	s.mu.Lock()
+	removed := s.removeLocked(rank, waiter)
+	s.mu.Unlock()
+	if removed {
+		return nil, err
+	}
+
+	// The request was given a slot or shed while it gave up
+	if <-waiter.ready {
+		return s.release, nil
+	}
+	return nil, errOverloaded()
+}
+
+// release hands a slot to the oldest waiter of the highest class, or frees it
+func (s *priorityScheduler) release() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for rank := len(s.queues) - 1; rank >= 0; rank-- {
+		if len(s.queues[rank]) == 0 {
+			continue
+		}
+		waiter := s.queues[rank][0]
+		s.queues[rank] = s.queues[rank][1:]
+		s.queued--
+		waiter.ready <- true
+		return
+	}
+	s.inFlight--
+}
+
+// shedLocked rejects the newest waiter of the lowest class below rank, reporting false if there is none
+func (s *priorityScheduler) shedLocked(rank int) bool {
+	for lower := 0; lower < rank; lower++ {
+		queue := s.queues[lower]
+		if len(queue) == 0 {
+			continue
+		}
+		waiter := queue[len(queue)-1]
+		s.queues[lower] = queue[:len(queue)-1]
+		s.queued--
+		waiter.ready <- false
+		return true
+	}
+	return false
+}
+
+// removeLocked takes a waiter out of its queue, reporting false if it is no longer queued
+func (s *priorityScheduler) removeLocked(rank int, waiter *priorityWaiter) bool {
+	queue := s.queues[rank]
+	for i, queued := range queue {
+		if queued == waiter {
+			s.queues[rank] = append(queue[:i], queue[i+1:]...)
+			s.queued--
+			return true
+		}
+	}
+	return false
+}
+
+// errOverloaded is returned to requests shed under load
+func errOverloaded() error {
+	return errors.NewError(errors.CodeServiceUnavailable, "gateway overloaded, retry later", errors.ErrServiceUnavailable)
+}
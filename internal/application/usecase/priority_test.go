@@ -0,0 +1,129 @@
+package usecase
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"api-gateway-sample/internal/domain/entity"
+	"api-gateway-sample/pkg/errors"
+)
+
+func newTestPriorityScheduler(maxInFlight int, queueSize int, maxWait time.Duration) *priorityScheduler {
+	useCase := &ProxyUseCase{}
+	useCase.SetPriorityScheduler(maxInFlight, queueSize, maxWait, map[string]string{"Enterprise": entity.PriorityHigh, "free": entity.PriorityLow}, entity.PriorityNormal)
+	return useCase.priority
+}
+
+// waitQueued waits until the scheduler has the given number of queued requests
+func waitQueued(t *testing.T, scheduler *priorityScheduler, queued int) {
+	t.Helper()
+	deadline := time.Now().Add(time.Second)
+	for time.Now().Before(deadline) {
+		scheduler.mu.Lock()
+		n := scheduler.queued
+		scheduler.mu.Unlock()
+		if n == queued {
+			return
+		}
+		time.Sleep(time.Millisecond)
+	}
+	t.Fatalf("Expected %d queued requests", queued)
+}
+
+func TestPriorityScheduler_ShedsLowPriorityFirst(t *testing.T) {
+	ctx := context.Background()
+	scheduler := newTestPriorityScheduler(1, 1, time.Second)
+
+	// 1. The only slot is taken and a low priority request waits
+	release, err := scheduler.acquire(ctx, entity.PriorityLow)
+	if err != nil {
+		t.Fatalf("Failed to acquire a slot: %v", err)
+	}
+	lowResult := make(chan error, 1)
+	go func() {
+		_, err := scheduler.acquire(ctx, entity.PriorityLow)
+		lowResult <- err
+	}()
+	waitQueued(t, scheduler, 1)
+
+	// 2. A high priority request takes its place in the full queue
+	highResult := make(chan error, 1)
+	go func() {
+		highRelease, err := scheduler.acquire(ctx, entity.PriorityHigh)
+		if err == nil {
+			highRelease()
+		}
+		highResult <- err
+	}()
+	if err := <-lowResult; errors.StatusCodeOf(err, 0) != errors.CodeServiceUnavailable {
+		t.Errorf("Expected the low priority request to be shed with 503, got %v", err)
+	}
+
+	// 3. A request of the same or lower class cannot shed it
+	if _, err := scheduler.acquire(ctx, entity.PriorityNormal); errors.StatusCodeOf(err, 0) != errors.CodeServiceUnavailable {
+		t.Errorf("Expected 503 while the queue is full, got %v", err)
+	}
+
+	// 4. The freed slot goes to the high priority request
+	release()
+	if err := <-highResult; err != nil {
+		t.Errorf("Expected the high priority request to be served, got %v", err)
+	}
+	if scheduler.inFlight != 0 || scheduler.queued != 0 {
+		t.Errorf("Expected every slot to be free, got %d in flight and %d queued", scheduler.inFlight, scheduler.queued)
+	}
+}
+
+func TestPriorityScheduler_MaxWait(t *testing.T) {
+	ctx := context.Background()
+	scheduler := newTestPriorityScheduler(1, 10, 10*time.Millisecond)
+
+	release, err := scheduler.acquire(ctx, entity.PriorityNormal)
+	if err != nil {
+		t.Fatalf("Failed to acquire a slot: %v", err)
+	}
+	defer release()
+
+	if _, err := scheduler.acquire(ctx, entity.PriorityHigh); errors.StatusCodeOf(err, 0) != errors.CodeServiceUnavailable {
+		t.Errorf("Expected 503 after waiting too long, got %v", err)
+	}
+	if scheduler.queued != 0 {
+		t.Errorf("Expected the request to leave the queue, got %d queued", scheduler.queued)
+	}
+}
+
+func TestPriorityScheduler_Classify(t *testing.T) {
+	scheduler := newTestPriorityScheduler(1, 1, time.Second)
+	withPlan := func(plan string) context.Context {
+		return entity.ContextWithPrincipal(context.Background(), entity.NewPrincipal(map[string]interface{}{"sub": "key-1", "plan": plan}))
+	}
+	newRequest := func(priority string) *entity.Request {
+		headers := map[string][]string{}
+		if priority != "" {
+			headers[HeaderPriority] = []string{priority}
+		}
+		return &entity.Request{Headers: headers}
+	}
+
+	tests := []struct {
+		name     string
+		ctx      context.Context
+		request  *entity.Request
+		expected string
+	}{
+		{"anonymous", context.Background(), newRequest(""), entity.PriorityNormal},
+		{"mapped plan", withPlan("enterprise"), newRequest(""), entity.PriorityHigh},
+		{"unmapped plan", withPlan("trial"), newRequest(""), entity.PriorityNormal},
+		{"header lowers", withPlan("enterprise"), newRequest("low"), entity.PriorityLow},
+		{"header cannot raise", withPlan("free"), newRequest("high"), entity.PriorityLow},
+		{"unknown header", context.Background(), newRequest("urgent"), entity.PriorityNormal},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if class := scheduler.classify(tt.ctx, tt.request); class != tt.expected {
+				t.Errorf("Expected class %s, got %s", tt.expected, class)
+			}
+		})
+	}
+}
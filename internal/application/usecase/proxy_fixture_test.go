@@ -0,0 +1,39 @@
+package usecase
+
+import (
+	"context"
+	"testing"
+
+	"api-gateway-sample/internal/domain/entity"
+	"api-gateway-sample/internal/domain/repository"
+	"api-gateway-sample/internal/domain/repository/mock"
+	"api-gateway-sample/internal/domain/service"
+)
+
+// newTestService returns a service called name, served by http://<name>:8080, with endpoints
+func newTestService(name string, endpoints ...entity.Endpoint) *entity.Service {
+	svc := entity.NewService(name+"-id", name, "1.0.0", "", "http://"+name+":8080", 30, 3)
+	for _, endpoint := range endpoints {
+		svc.AddEndpoint(endpoint)
+	}
+	return svc
+}
+
+// newServiceRepository returns a mock repository holding services
+func newServiceRepository(t testing.TB, services ...*entity.Service) repository.ServiceRepository {
+	t.Helper()
+	serviceRepo := mock.NewServiceRepositoryMock()
+	for _, svc := range services {
+		if err := serviceRepo.Create(context.Background(), svc); err != nil {
+			t.Fatalf("Failed to create service: %v", err)
+		}
+	}
+	return serviceRepo
+}
+
+// newProxyFixture creates a proxy use case routing the requests for services through gateway,
+// without auth, rate limit or cache services
+func newProxyFixture(t testing.TB, gateway service.GatewayService, services ...*entity.Service) *ProxyUseCase {
+	t.Helper()
+	return NewProxyUseCase(newServiceRepository(t, services...), gateway, nil, nil, nil, &MockLogger{})
+}
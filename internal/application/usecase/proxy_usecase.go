@@ -3,6 +3,14 @@ package usecase
 import (
 	"context"
 	"fmt"
+	"io"
+	"net/http"
+	"net/textproto"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
 
 	"api-gateway-sample/internal/domain/entity"
 	"api-gateway-sample/internal/domain/repository"
@@ -17,8 +25,60 @@ type ProxyUseCase struct {
 	gatewayService   service.GatewayService
 	authService      service.AuthService
 	rateLimitService service.RateLimitService
+	concurrency      service.ConcurrencyLimiter
+	spikeArrester    service.SpikeArrester
 	cacheService     service.CacheService
+	extAuthorizer    service.ExternalAuthorizer
+	metrics          service.MetricsCollector
 	logger           logger.Logger
+
+	// extUpstreamHeaders are the headers the external authorizer sets on allowed requests, which
+	// are removed from the requests of clients
+	extUpstreamHeaders []string
+	// idempotency replays retried POST and PATCH requests, nil when disabled
+	idempotency *idempotencyStore
+	// publishers publish the requests of bridge endpoints, by broker
+	publishers map[string]service.MessagePublisher
+	// async processes the requests of async endpoints in the background, nil when disabled
+	async *asyncQueue
+	// priority bounds the requests forwarded at once and queues the rest by priority, nil when disabled
+	priority *priorityScheduler
+	// dedup collapses identical GET requests in flight, nil when disabled
+	dedup *requestCoalescer
+	// faults injects delays and failures into requests to test clients, nil unless enabled
+	faults *FaultUseCase
+	// overrides replaces the limits of endpoints for the consumers given their own, nil when disabled
+	overrides *RateLimitOverrideUseCase
+	// routePolicies disables endpoints, answers for them or changes their limits during scheduled
+	// windows, nil when disabled
+	routePolicies *RoutePolicyUseCase
+	// exposures receives the exposures of callers to the variants of experiments, the
+	// application log when nil, and experimentCookieMaxAge is how long bucketing cookies last
+	exposures              logger.Logger
+	experimentCookieMaxAge time.Duration
+	// flags decides which callers the endpoints behind a feature flag are served to, nil when
+	// flagged endpoints are served to everyone
+	flags service.FeatureFlagProvider
+	// signer signs the requests to services that configure upstream signing, nil when disabled
+	signer service.RequestSigner
+	// cipher decrypts and encrypts the payloads of endpoints that configure encryption, nil when disabled
+	cipher service.PayloadCipher
+	// regions locates the callers of services with data residency, nil when disabled
+	regions service.RegionLocator
+	// claimHeaders forwards claims of the caller to services as headers, empty when disabled
+	claimHeaders entity.ClaimHeaders
+	// identityTokens mints the tokens forwarded to services in identityHeader instead of the
+	// client's credentials, nil when client credentials are forwarded
+	identityTokens service.IdentityTokenIssuer
+	identityHeader string
+	// drains tracks the requests in flight on each upstream to drain former ones, nil when disabled
+	drains *UpstreamDrainUseCase
+	// etags is the kind of ETags generated for cached JSON responses without one, empty when disabled
+	etags string
+	// scanner checks the files of uploads to endpoints that scan them, nil when disabled, and
+	// spoolDir holds the files while they are scanned
+	scanner  service.UploadScanner
+	spoolDir string
 }
 
 // NewProxyUseCase creates a new ProxyUseCase instance
@@ -40,113 +100,606 @@ func NewProxyUseCase(
 	}
 }
 
+// SetExternalAuthorizer delegates authorization of protected endpoints to an external service,
+// whose decisions set the given headers on the requests forwarded to services
+func (uc *ProxyUseCase) SetExternalAuthorizer(authorizer service.ExternalAuthorizer, upstreamHeaders []string) {
+	uc.extAuthorizer = authorizer
+	uc.extUpstreamHeaders = make([]string, len(upstreamHeaders))
+	for i, name := range upstreamHeaders {
+		uc.extUpstreamHeaders[i] = textproto.CanonicalMIMEHeaderKey(name)
+	}
+}
+
+// SetMetricsCollector records the outcome of every proxied request in the given collector
+func (uc *ProxyUseCase) SetMetricsCollector(metrics service.MetricsCollector) {
+	uc.metrics = metrics
+}
+
+// SetClaimHeaders forwards claims of authenticated callers to services in the given headers,
+// which are removed from the requests of clients
+func (uc *ProxyUseCase) SetClaimHeaders(headers map[string]string) {
+	uc.claimHeaders = make(entity.ClaimHeaders, len(headers))
+	for name, claim := range headers {
+		uc.claimHeaders[textproto.CanonicalMIMEHeaderKey(name)] = claim
+	}
+}
+
+// ResolveEndpoint finds the service and endpoint configuration matching a request path and
+// method: the exact path first, then the longest prefix endpoint, the services serving it
+// ordered by precedence. HEAD requests are served by the GET endpoint of paths no endpoint
+// serves HEAD on.
+func (uc *ProxyUseCase) ResolveEndpoint(ctx context.Context, path string, method string) (*entity.Service, *entity.Endpoint, error) {
+	service, endpoint, err := uc.resolveEndpoint(ctx, path, method)
+	if err == errors.ErrServiceNotFound && method == http.MethodHead {
+		return uc.resolveEndpoint(ctx, path, http.MethodGet)
+	}
+	return service, endpoint, err
+}
+
+func (uc *ProxyUseCase) resolveEndpoint(ctx context.Context, path string, method string) (*entity.Service, *entity.Endpoint, error) {
+	now := time.Now()
+	served := uc.flaggedEndpoints(ctx)
+	for _, pattern := range routePatterns(path) {
+		services, err := uc.serviceRepo.GetByEndpoint(ctx, pattern, method)
+		if err != nil && !errors.IsNotFound(err) {
+			return nil, nil, err
+		}
+		if service, endpoint := selectRoute(services, pattern, method, now, served); service != nil {
+			return service, endpoint, nil
+		}
+	}
+
+	return nil, nil, errors.ErrServiceNotFound
+}
+
+// routeMethods are the methods endpoints may serve
+var routeMethods = []string{
+	http.MethodGet, http.MethodHead, http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete, http.MethodOptions,
+}
+
+// AllowedMethods returns the methods a request path is served with, none when no endpoint
+// serves it. Each method is resolved on its own, as it may be served by another endpoint.
+// Paths served with GET are also served with HEAD, and every served path with OPTIONS, which
+// the gateway answers itself.
+func (uc *ProxyUseCase) AllowedMethods(ctx context.Context, path string) ([]string, error) {
+	var methods []string
+	for _, method := range routeMethods {
+		_, _, err := uc.ResolveEndpoint(ctx, path, method)
+		if err == nil {
+			methods = append(methods, method)
+		} else if err != errors.ErrServiceNotFound {
+			return nil, err
+		}
+	}
+	if len(methods) > 0 && methods[len(methods)-1] != http.MethodOptions {
+		methods = append(methods, http.MethodOptions)
+	}
+	return methods, nil
+}
+
 // ProxyRequest proxies a request to a backend service
 func (uc *ProxyUseCase) ProxyRequest(ctx context.Context, request *entity.Request) (*entity.Response, error) {
+	start := time.Now()
+	sample := &entity.RequestSample{CacheStatus: entity.CacheStatusBypass}
+
+	response, err := uc.proxyRequest(ctx, request, sample)
+
+	if uc.metrics != nil {
+		sample.Latency = time.Since(start)
+		sample.RequestBytes = max(sample.RequestBytes, len(request.Body))
+		if err != nil {
+			sample.StatusCode = errors.StatusCodeOf(err, errors.CodeInternalServer)
+		} else {
+			sample.StatusCode = response.StatusCode
+			sample.ResponseBytes = len(response.Body)
+		}
+		uc.metrics.RecordRequest(sample)
+	}
+	return response, err
+}
+
+func (uc *ProxyUseCase) proxyRequest(ctx context.Context, request *entity.Request, sample *entity.RequestSample) (response *entity.Response, err error) {
+	trace := entity.TraceFromContext(ctx)
+
 	// Validate request
 	if err := uc.gatewayService.ValidateRequest(ctx, request); err != nil {
 		return nil, fmt.Errorf("invalid request: %w", err)
 	}
 
-	// Find service by endpoint path and method
-	services, err := uc.serviceRepo.GetByEndpoint(ctx, request.Path, request.Method)
+	// Find service and endpoint by path and method
+	service, endpoint, err := uc.ResolveEndpoint(ctx, request.Path, request.Method)
 	if err != nil {
 		return nil, err
 	}
+	request.ResponseLimit = endpoint.ResponseLimit
 
-	if len(services) == 0 {
-		return nil, errors.ErrServiceNotFound
+	// HEAD is answered from a GET to the service unless the endpoint serves it, so that
+	// services need not implement it
+	if request.Method == http.MethodHead && methodRank(endpoint, http.MethodHead) == 0 {
+		request.Method = http.MethodGet
+		defer func() {
+			if err == nil {
+				response = headResponse(response)
+			}
+		}()
 	}
 
-	// For now, we'll use the first matching service
-	service := services[0]
+	tags := service.RouteTags(endpoint)
+	sample.ServiceID = service.ID
+	sample.Endpoint = endpoint.Path
+	sample.SLO = endpoint.SLO
+	sample.Tags = tags
+	entity.RouteFromContext(ctx).Set(service.Name, endpoint.Path, tags)
+	ctx = logger.WithFields(ctx, append([]interface{}{logger.FieldService, service.Name}, logger.TagFields(tags)...)...)
+	uc.locateRegion(ctx, request, service)
+	log := logger.FromContextOr(ctx, uc.logger)
+
+	// Experiments serve each caller the variant it is bucketed into, keeping anonymous callers
+	// in theirs with a cookie once the response is final
+	if endpoint.Experiment != nil {
+		var cookie *http.Cookie
+		service, cookie = uc.assignVariant(ctx, request, service, endpoint)
+		if cookie != nil {
+			defer func() {
+				if err == nil {
+					response = withCookie(response, cookie)
+				}
+			}()
+		}
+	}
 
-	// Find matching endpoint
-	var endpoint *entity.Endpoint
-	for _, e := range service.Endpoints {
-		if e.Path == request.Path {
-			endpoint = &e
-			break
+	// Scheduled policies may take the endpoint down or change its limits during their windows
+	limits := endpoint
+	if uc.routePolicies != nil {
+		var maintenance *entity.Response
+		maintenance, limits, err = uc.routePolicies.enforce(ctx, request, service, endpoint)
+		if maintenance != nil || err != nil {
+			return maintenance, err
 		}
 	}
 
-	if endpoint == nil {
-		return nil, fmt.Errorf("no endpoint found for path: %s", request.Path)
+	// Mask personal data once the response is final, as cached and coalesced responses are
+	// shared by callers that may see more or less of it. The upstream is not offered compressed
+	// encodings, so that the body the gateway has to parse reaches it as it is.
+	if endpoint.Masking != nil {
+		deleteHeader(request.Headers, "Accept-Encoding")
+		defer func() {
+			if err == nil {
+				response, err = uc.maskResponse(ctx, response, endpoint.Masking)
+			}
+		}()
+	}
+
+	// Identity headers only carry the claims of the authenticated caller, never client values
+	if len(uc.claimHeaders) > 0 {
+		principal, _ := entity.PrincipalFromContext(ctx)
+		uc.claimHeaders.Apply(request, principal)
 	}
 
 	// Check authentication if required
-	if endpoint.AuthRequired {
-		authenticated, userID, err := uc.authService.Authenticate(ctx, request)
-		if err != nil {
-			return nil, fmt.Errorf("authentication failed: %w", err)
+	if endpoint.AuthRequired && uc.extAuthorizer != nil {
+		authStart := time.Now()
+		if err := uc.authorizeExternally(ctx, request, service, endpoint); err != nil {
+			return nil, err
 		}
+		trace.Record(entity.TracePhaseAuth, authStart)
+	} else if endpoint.AuthRequired {
+		authStart := time.Now()
+		if principal, ok := entity.PrincipalFromContext(ctx); ok {
+			// Already authenticated by the auth middleware
+			request.SetAuthenticated(true, principal.UserID)
+		} else {
+			authenticated, userID, err := uc.authService.Authenticate(ctx, request)
+			if err != nil {
+				return nil, fmt.Errorf("authentication failed: %w", err)
+			}
 
-		if !authenticated {
-			return nil, fmt.Errorf("unauthorized")
+			if !authenticated {
+				return nil, fmt.Errorf("unauthorized")
+			}
+
+			// Set authenticated user ID
+			request.SetAuthenticated(true, userID)
 		}
 
-		// Set authenticated user ID
-		request.UserID = userID
+		// Services may only accept principals authenticated with some methods, e.g. SAML sessions
+		if principal, _ := entity.PrincipalFromContext(ctx); !service.AcceptsPrincipal(principal) {
+			return nil, errors.NewError(errors.CodeForbidden, "authentication method not accepted by the service", errors.ErrForbidden)
+		}
 
 		// Authorize the request
 		if err := uc.authService.Authorize(ctx, request, service, endpoint); err != nil {
 			return nil, fmt.Errorf("authorization failed: %w", err)
 		}
+		trace.Record(entity.TracePhaseAuth, authStart)
+	}
+
+	// Endpoints may require OAuth scopes on top of their authorization
+	if len(endpoint.RequiredScopes) > 0 {
+		principal, _ := entity.PrincipalFromContext(ctx)
+		if missing := endpoint.MissingScopes(principal); len(missing) > 0 {
+			return nil, errors.NewError(errors.CodeForbidden, "insufficient scope, requires "+strings.Join(missing, " "), errors.ErrForbidden)
+		}
+	}
+
+	if uc.identityTokens != nil {
+		if err := uc.identifyCaller(ctx, request, service, endpoint); err != nil {
+			return nil, err
+		}
+	}
+
+	// Consumers may be given their own limits
+	if uc.overrides != nil {
+		limits = uc.overrides.Limits(ctx, requestConsumer(ctx, request), service, limits)
 	}
 
 	// Check rate limit
-	if endpoint.RateLimit > 0 {
-		allowed, err := uc.rateLimitService.CheckLimit(ctx, request, service, endpoint)
+	if limits.RateLimit > 0 {
+		rateLimitStart := time.Now()
+		allowed, err := uc.rateLimitService.CheckLimit(ctx, request, service, limits)
 		if err != nil {
 			return nil, fmt.Errorf("rate limit check failed: %w", err)
 		}
 
 		if !allowed {
-			return nil, fmt.Errorf("rate limit exceeded")
+			sample.RateLimited = true
+			uc.reportRateLimit(ctx, request, service, limits)
+			return nil, errors.NewError(errors.CodeRateLimitExceeded, "rate limit exceeded", errors.ErrRateLimitExceeded)
 		}
 
 		// Record the request for rate limiting
-		if err := uc.rateLimitService.RecordRequest(ctx, request, service, endpoint); err != nil {
-			uc.logger.Warn("Failed to record request for rate limiting", "error", err)
+		if err := uc.rateLimitService.RecordRequest(ctx, request, service, limits); err != nil {
+			log.Warn("Failed to record request for rate limiting", "error", err)
 		}
+		uc.reportRateLimit(ctx, request, service, limits)
+		trace.Record(entity.TracePhaseRateLimit, rateLimitStart)
 	}
 
-	// Check cache
-	if endpoint.CacheTTL > 0 {
-		cacheKey := fmt.Sprintf("%s:%s:%s", service.ID, request.Path, request.Method)
-		value, found, err := uc.cacheService.Get(ctx, cacheKey)
-		if err == nil && found {
-			if response, ok := value.(*entity.Response); ok {
-				response.CachedResult = true
-				return response, nil
+	// Bound the requests the client has in flight
+	if limits.MaxConcurrent > 0 && uc.concurrency != nil {
+		release, err := uc.acquireConcurrency(ctx, request, service, limits)
+		if err != nil {
+			sample.RateLimited = errors.IsRateLimitExceeded(err)
+			return nil, err
+		}
+		defer release()
+	}
+
+	// Inject the faults configured for the endpoint, as if the backend were slow or failing
+	if uc.faults != nil {
+		if err := uc.faults.inject(ctx, service, endpoint); err != nil {
+			log.Debug("Injected fault", "error", err)
+			return nil, err
+		}
+	}
+
+	// Stream uploads to the service as they are read, answering with the error that stopped
+	// them if a file was rejected
+	if request.BodyStream != nil {
+		upload, err := uc.streamUpload(ctx, request, endpoint.Upload)
+		if err != nil {
+			return nil, err
+		}
+		if upload != nil {
+			defer func() {
+				if failure := upload.close(); failure != nil {
+					closeResponse(response)
+					response, err = nil, failure
+				}
+				sample.RequestBytes = upload.bytes()
+			}()
+		}
+	}
+
+	// Check cache, serving fresh responses and revalidating stale ones with the upstream
+	var stale *cachedResponse
+	client := clientConditions(request)
+	var cacheKey string
+	if endpoint.Cached() {
+		cacheKey = endpointCacheKey(request, service, endpoint)
+		cacheStart := time.Now()
+		entry, fresh := uc.cacheLookup(ctx, request, cacheKey)
+		trace.Record(entity.TracePhaseCache, cacheStart)
+		if fresh {
+			trace.SetCacheStatus(entity.CacheStatusHit)
+			sample.CacheStatus = entity.CacheStatusHit
+			return entry.serve(client, time.Now()), nil
+		}
+		if entry != nil {
+			stale = entry
+			revalidate(request, stale)
+		}
+		trace.SetCacheStatus(entity.CacheStatusMiss)
+		sample.CacheStatus = entity.CacheStatusMiss
+	}
+
+	// Collapse identical GET requests in flight into a single upstream call
+	return uc.deduplicate(ctx, request, service, client, func(ctx context.Context) (*entity.Response, error) {
+		// Space out bursts to endpoints whose backends cannot absorb them
+		if endpoint.SpikeArrest != nil && uc.spikeArrester != nil {
+			if err := uc.arrestSpike(ctx, service, endpoint); err != nil {
+				sample.RateLimited = errors.IsRateLimitExceeded(err)
+				return nil, err
+			}
+		}
+
+		// Wait for a slot when the gateway is under load, serving higher priorities first
+		if uc.priority != nil {
+			release, err := uc.schedule(ctx, request)
+			if err != nil {
+				return nil, err
+			}
+			defer release()
+		}
+
+		transformedResponse, err := uc.dispatchEndpoint(ctx, request, service, endpoint, sample)
+		if err != nil {
+			return nil, err
+		}
+
+		// Cache response if needed; streamed responses are too large to be cached
+		if endpoint.Cached() && transformedResponse.BodyStream != nil {
+			trace.SetCacheStatus(entity.CacheStatusBypass)
+			sample.CacheStatus = entity.CacheStatusBypass
+		} else if endpoint.Cached() {
+			var revalidated bool
+			transformedResponse, revalidated = uc.cacheStore(ctx, request, cacheKey, endpoint, stale, client, transformedResponse)
+			if revalidated {
+				trace.SetCacheStatus(entity.CacheStatusRevalidated)
+				sample.CacheStatus = entity.CacheStatusRevalidated
+			} else if stale != nil {
+				trace.SetCacheStatus(entity.CacheStatusStale)
+				sample.CacheStatus = entity.CacheStatusStale
 			}
 		}
+
+		return transformedResponse, nil
+	})
+}
+
+// Dispatch forwards a request issued by the gateway itself, such as a scheduled job, to the
+// route it matches. Client authentication and rate limits do not apply. The response is stored
+// in the response cache of cached routes, so dispatched requests can prime it.
+func (uc *ProxyUseCase) Dispatch(ctx context.Context, request *entity.Request) (*entity.Response, error) {
+	if err := uc.gatewayService.ValidateRequest(ctx, request); err != nil {
+		return nil, fmt.Errorf("invalid request: %w", err)
 	}
 
+	service, endpoint, err := uc.ResolveEndpoint(ctx, request.Path, request.Method)
+	if err != nil {
+		return nil, err
+	}
+	ctx = logger.WithFields(ctx, append([]interface{}{logger.FieldService, service.Name}, logger.TagFields(service.RouteTags(endpoint))...)...)
+	uc.locateRegion(ctx, request, service)
+
+	sample := &entity.RequestSample{ServiceID: service.ID, CacheStatus: entity.CacheStatusBypass}
+	client := clientConditions(request)
+	response, err := uc.dispatchEndpoint(ctx, request, service, endpoint, sample)
+	if err != nil {
+		return nil, err
+	}
+
+	if endpoint.Cached() {
+		cacheKey := endpointCacheKey(request, service, endpoint)
+		response, _ = uc.cacheStore(ctx, request, cacheKey, endpoint, nil, client, response)
+	}
+	return response, nil
+}
+
+// dispatchEndpoint forwards a request to the endpoint, decrypting and encrypting the payloads
+// of endpoints that configure encryption
+func (uc *ProxyUseCase) dispatchEndpoint(ctx context.Context, request *entity.Request, service *entity.Service, endpoint *entity.Endpoint, sample *entity.RequestSample) (*entity.Response, error) {
+	if endpoint.Encryption != nil {
+		return uc.dispatchEncrypted(ctx, request, service, endpoint, sample)
+	}
+	return uc.dispatchPlain(ctx, request, service, endpoint, sample)
+}
+
+// dispatchPlain forwards a request to the endpoint, answering mock endpoints from their
+// fixtures, fanning composite endpoints out to their calls, running pipeline steps, publishing
+// to brokers, adapting requests to SOAP services, queueing async requests and replaying retries
+// of requests sent with an Idempotency-Key
+func (uc *ProxyUseCase) dispatchPlain(ctx context.Context, request *entity.Request, service *entity.Service, endpoint *entity.Endpoint, sample *entity.RequestSample) (*entity.Response, error) {
+	switch {
+	case endpoint.Mock != nil:
+		return uc.serveMock(ctx, request, endpoint, sample)
+	case endpoint.Bridge != nil:
+		return uc.publishRequest(ctx, request, endpoint, sample)
+	case endpoint.SOAP != nil:
+		return uc.callSOAP(ctx, request, service, endpoint, sample)
+	case endpoint.Async && uc.async != nil:
+		return uc.enqueueAsync(ctx, request, service)
+	case endpoint.Composite != nil:
+		return uc.composeRequest(ctx, request, endpoint, sample)
+	case endpoint.Pipeline != nil:
+		return uc.runPipeline(ctx, request, endpoint, sample)
+	case uc.idempotent(request):
+		return uc.forwardIdempotent(ctx, request, service, sample)
+	default:
+		return uc.forwardRequest(ctx, request, service, sample)
+	}
+}
+
+// forwardRequest forwards a request to the backend service, tracking it on the upstream of the
+// service while upstream draining is enabled
+func (uc *ProxyUseCase) forwardRequest(ctx context.Context, request *entity.Request, service *entity.Service, sample *entity.RequestSample) (*entity.Response, error) {
+	if uc.drains != nil {
+		drainCtx, done := uc.drains.begin(ctx, service)
+		response, err := uc.forwardService(drainCtx, request, service, sample)
+		if err != nil && drainCtx.Err() != nil && ctx.Err() == nil {
+			done()
+			return nil, errDrainAborted
+		}
+		// A streamed response is in flight until the client has read it
+		if err == nil && response.BodyStream != nil {
+			response.BodyStream = &onCloseReader{ReadCloser: response.BodyStream, onClose: done}
+		} else {
+			done()
+		}
+		return response, err
+	}
+	return uc.forwardService(ctx, request, service, sample)
+}
+
+// forwardService forwards a request to the upstream of the service, in the caller's region
+// when the service has data residency
+func (uc *ProxyUseCase) forwardService(ctx context.Context, request *entity.Request, service *entity.Service, sample *entity.RequestSample) (*entity.Response, error) {
+	if service.Residency != nil {
+		return uc.forwardRegional(ctx, request, service, sample)
+	}
+	return uc.forwardUpstream(ctx, request, service, sample)
+}
+
+// forwardUpstream transforms a request, routes it to the backend service and transforms the response
+func (uc *ProxyUseCase) forwardUpstream(ctx context.Context, request *entity.Request, service *entity.Service, sample *entity.RequestSample) (*entity.Response, error) {
+	trace := entity.TraceFromContext(ctx)
+
 	// Transform request
 	transformedRequest, err := uc.gatewayService.TransformRequest(ctx, request, service)
 	if err != nil {
 		return nil, fmt.Errorf("failed to transform request: %w", err)
 	}
+	if err := uc.signRequest(ctx, transformedRequest, service); err != nil {
+		return nil, err
+	}
 
 	// Route request to backend service
+	trace.SetTarget(service.BaseURL)
+	sample.Target = service.BaseURL
+	upstreamStart := time.Now()
 	response, err := uc.gatewayService.RouteRequest(ctx, transformedRequest)
+	trace.Record(entity.TracePhaseUpstream, upstreamStart)
+	sample.UpstreamLatency = time.Since(upstreamStart)
+	sample.UpstreamFailed = err != nil || response.StatusCode >= 500
 	if err != nil {
 		return nil, fmt.Errorf("failed to route request: %w", err)
 	}
+	if response.Timings != nil {
+		sample.Timings = response.Timings
+		phases := response.Timings.Phases()
+		for _, phase := range entity.ConnectionPhases {
+			if duration, ok := phases[phase]; ok {
+				trace.Add(entity.TracePhaseUpstream+"-"+phase, duration)
+			}
+		}
+	}
 
 	// Transform response
 	transformedResponse, err := uc.gatewayService.TransformResponse(ctx, response, service)
 	if err != nil {
 		return nil, fmt.Errorf("failed to transform response: %w", err)
 	}
+	return transformedResponse, nil
+}
+
+// authorizeExternally asks the external authorizer for a decision and injects the headers it returns
+func (uc *ProxyUseCase) authorizeExternally(ctx context.Context, request *entity.Request, service *entity.Service, endpoint *entity.Endpoint) error {
+	decision, err := uc.extAuthorizer.Check(ctx, request, service, endpoint)
+	if err != nil {
+		return errors.NewError(errors.CodeServiceUnavailable, "external authorization failed", err)
+	}
 
-	// Cache response if needed
-	if endpoint.CacheTTL > 0 {
-		cacheKey := fmt.Sprintf("%s:%s:%s", service.ID, request.Path, request.Method)
-		if err := uc.cacheService.Set(ctx, cacheKey, transformedResponse, 0); err != nil {
-			uc.logger.Warn("Failed to cache response", "error", err)
+	if !decision.Allowed {
+		message := string(decision.Body)
+		if message == "" {
+			message = "forbidden"
 		}
+		return errors.NewError(decision.StatusCode, message, nil)
 	}
 
-	return transformedResponse, nil
+	if principal, ok := entity.PrincipalFromContext(ctx); ok {
+		request.SetAuthenticated(true, principal.UserID)
+	}
+	// Clients must not pass off their own values as the authorizer's
+	for _, name := range uc.extUpstreamHeaders {
+		delete(request.Headers, name)
+	}
+	for name, value := range decision.UpstreamHeaders {
+		request.Headers[textproto.CanonicalMIMEHeaderKey(name)] = []string{value}
+	}
+	return nil
+}
+
+// responseCacheKey returns the cache key of a proxied response
+func responseCacheKey(serviceID string, path string, method string) string {
+	return serviceID + ":" + path + ":" + method
+}
+
+// queriedCacheKey scopes the cache key of a response to the query of the request, so that
+// responses are never served to requests with another query
+func queriedCacheKey(key string, query map[string][]string) string {
+	encoded := url.Values(query).Encode()
+	if encoded == "" {
+		return key
+	}
+	return key + "?" + encoded
+}
+
+// endpointCacheKey returns the cache key of the response to a request to an endpoint, apart
+// from those to other queries or served to callers of another feature flag, experiment variant
+// or region
+func endpointCacheKey(request *entity.Request, service *entity.Service, endpoint *entity.Endpoint) string {
+	key := queriedCacheKey(responseCacheKey(service.ID, request.Path, request.Method), request.QueryParams)
+	return scopedCacheKey(key, endpoint, request.Variant, request.Region)
+}
+
+// scopedCacheKey scopes the cache key of the responses of an endpoint to the callers of an
+// experiment variant and a region, either empty when the responses are not scoped to one
+func scopedCacheKey(key string, endpoint *entity.Endpoint, variant string, region string) string {
+	return regionalCacheKey(variantCacheKey(flaggedCacheKey(key, endpoint), variant), region)
+}
+
+// reportRateLimit records the quota left to the client for the handler to report in the
+// response headers. The lookup is skipped when nothing in the context collects it.
+func (uc *ProxyUseCase) reportRateLimit(ctx context.Context, request *entity.Request, service *entity.Service, endpoint *entity.Endpoint) {
+	info := entity.RateLimitFromContext(ctx)
+	if info == nil {
+		return
+	}
+	status, err := uc.rateLimitService.GetLimit(ctx, request, service, endpoint)
+	if err != nil {
+		logger.FromContextOr(ctx, uc.logger).Warn("Failed to get rate limit status", "error", err)
+		return
+	}
+	info.Set(status)
+}
+
+// headResponse returns the response to a HEAD request answered from a GET response: its
+// headers, with the length of the body it leaves out. The response may be cached, so it is
+// copied rather than changed.
+func headResponse(response *entity.Response) *entity.Response {
+	head := *response
+	head.Headers = make(map[string][]string, len(response.Headers)+1)
+	for name, values := range response.Headers {
+		head.Headers[name] = values
+	}
+	// The length of a streamed body is only known when the service declared it
+	if response.BodyStream != nil {
+		closeResponse(response)
+		head.BodyStream = nil
+	} else {
+		head.Headers["Content-Length"] = []string{strconv.Itoa(len(response.Body))}
+	}
+	head.Body = nil
+	return &head
+}
+
+// closeResponse closes the body stream of a response that is not sent to the client
+func closeResponse(response *entity.Response) {
+	if response != nil && response.BodyStream != nil {
+		response.BodyStream.Close()
+	}
+}
+
+// onCloseReader calls onClose once the body it reads is closed
+type onCloseReader struct {
+	io.ReadCloser
+	onClose func()
+	once    sync.Once
+}
+
+func (r *onCloseReader) Close() error {
+	err := r.ReadCloser.Close()
+	r.once.Do(r.onClose)
+	return err
 }
@@ -0,0 +1,224 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"api-gateway-sample/internal/application/dto"
+	"api-gateway-sample/internal/domain/entity"
+	"api-gateway-sample/internal/domain/repository"
+	"api-gateway-sample/pkg/errors"
+	"api-gateway-sample/pkg/logger"
+)
+
+// rateLimitOverrideCachePrefix prefixes the cache keys of the overrides of each consumer
+const rateLimitOverrideCachePrefix = "ratelimit-override:"
+
+// RateLimitOverrideUseCase manages the rate limit overrides of consumers, which take precedence
+// over the limits of the endpoints. Overrides are stored in the repository and the overrides of
+// each consumer are cached, so that requests do not query the repository.
+type RateLimitOverrideUseCase struct {
+	overrideRepo repository.RateLimitOverrideRepository
+	serviceRepo  repository.ServiceRepository
+	cache        repository.CacheRepository
+	cacheTTL     time.Duration
+	logger       logger.Logger
+}
+
+// NewRateLimitOverrideUseCase creates a new RateLimitOverrideUseCase instance. The overrides of
+// a consumer are cached for cacheTTL; changes made through the use case take effect at once.
+func NewRateLimitOverrideUseCase(
+	overrideRepo repository.RateLimitOverrideRepository,
+	serviceRepo repository.ServiceRepository,
+	cache repository.CacheRepository,
+	cacheTTL time.Duration,
+	logger logger.Logger,
+) *RateLimitOverrideUseCase {
+	return &RateLimitOverrideUseCase{
+		overrideRepo: overrideRepo,
+		serviceRepo:  serviceRepo,
+		cache:        cache,
+		cacheTTL:     cacheTTL,
+		logger:       logger,
+	}
+}
+
+// SetRateLimitOverrides applies the rate limit overrides managed through the given use case to
+// the requests of their consumers
+func (uc *ProxyUseCase) SetRateLimitOverrides(overrides *RateLimitOverrideUseCase) {
+	uc.overrides = overrides
+}
+
+// CreateOverride creates a rate limit override for a consumer
+func (uc *RateLimitOverrideUseCase) CreateOverride(ctx context.Context, req *dto.RateLimitOverrideRequest) (*entity.RateLimitOverride, error) {
+	override := req.ToEntity()
+	if err := uc.checkOverride(ctx, override); err != nil {
+		return nil, err
+	}
+
+	override.ID = entity.NewRequestID()
+	override.CreatedAt = time.Now()
+	override.UpdatedAt = override.CreatedAt
+	if err := uc.overrideRepo.Create(ctx, override); err != nil {
+		return nil, err
+	}
+	uc.invalidate(ctx, override.Consumer)
+	return override, nil
+}
+
+// UpdateOverride replaces a rate limit override
+func (uc *RateLimitOverrideUseCase) UpdateOverride(ctx context.Context, id string, req *dto.RateLimitOverrideRequest) (*entity.RateLimitOverride, error) {
+	existing, err := uc.overrideRepo.Get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	override := req.ToEntity()
+	override.ID = existing.ID
+	if err := uc.checkOverride(ctx, override); err != nil {
+		return nil, err
+	}
+
+	override.CreatedAt = existing.CreatedAt
+	override.UpdatedAt = time.Now()
+	if err := uc.overrideRepo.Update(ctx, override); err != nil {
+		return nil, err
+	}
+	uc.invalidate(ctx, existing.Consumer)
+	uc.invalidate(ctx, override.Consumer)
+	return override, nil
+}
+
+// GetOverride retrieves a rate limit override by ID
+func (uc *RateLimitOverrideUseCase) GetOverride(ctx context.Context, id string) (*entity.RateLimitOverride, error) {
+	return uc.overrideRepo.Get(ctx, id)
+}
+
+// ListOverrides retrieves the rate limit overrides of a consumer, or all of them when consumer is empty
+func (uc *RateLimitOverrideUseCase) ListOverrides(ctx context.Context, consumer string) ([]*entity.RateLimitOverride, error) {
+	var overrides []*entity.RateLimitOverride
+	var err error
+	if consumer == "" {
+		overrides, err = uc.overrideRepo.GetAll(ctx)
+	} else {
+		overrides, err = uc.overrideRepo.FindByConsumer(ctx, consumer)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if overrides == nil {
+		overrides = []*entity.RateLimitOverride{}
+	}
+	return overrides, nil
+}
+
+// DeleteOverride deletes a rate limit override by ID
+func (uc *RateLimitOverrideUseCase) DeleteOverride(ctx context.Context, id string) error {
+	existing, err := uc.overrideRepo.Get(ctx, id)
+	if err != nil {
+		return err
+	}
+	if err := uc.overrideRepo.Delete(ctx, id); err != nil {
+		return err
+	}
+	uc.invalidate(ctx, existing.Consumer)
+	return nil
+}
+
+// Limits returns the endpoint with the limits that apply to a consumer: those of the consumer's
+// override for the endpoint, else for its service, else the endpoint's own. The endpoint's own
+// limits apply when the overrides cannot be loaded.
+func (uc *RateLimitOverrideUseCase) Limits(ctx context.Context, consumer string, service *entity.Service, endpoint *entity.Endpoint) *entity.Endpoint {
+	if consumer == "" {
+		return endpoint
+	}
+
+	overrides, err := uc.consumerOverrides(ctx, consumer)
+	if err != nil {
+		logger.FromContextOr(ctx, uc.logger).Warn("Failed to load rate limit overrides", "consumer", consumer, "error", err)
+		return endpoint
+	}
+	if override := entity.SelectRateLimitOverride(overrides, service.ID, endpoint.Path); override != nil {
+		return override.Apply(endpoint)
+	}
+	return endpoint
+}
+
+// consumerOverrides returns the overrides of a consumer from the cache, loading and caching
+// them on a miss. Consumers without overrides are cached too.
+func (uc *RateLimitOverrideUseCase) consumerOverrides(ctx context.Context, consumer string) ([]*entity.RateLimitOverride, error) {
+	key := rateLimitOverrideCachePrefix + consumer
+	var overrides []*entity.RateLimitOverride
+	if uc.cache != nil {
+		if err := uc.cache.Get(ctx, key, &overrides); err == nil {
+			return overrides, nil
+		}
+	}
+
+	overrides, err := uc.overrideRepo.FindByConsumer(ctx, consumer)
+	if err != nil {
+		return nil, err
+	}
+	if overrides == nil {
+		overrides = []*entity.RateLimitOverride{}
+	}
+	if uc.cache != nil {
+		if err := uc.cache.Set(ctx, key, overrides, uc.cacheTTL); err != nil {
+			logger.FromContextOr(ctx, uc.logger).Warn("Failed to cache rate limit overrides", "consumer", consumer, "error", err)
+		}
+	}
+	return overrides, nil
+}
+
+// invalidate drops the cached overrides of a consumer so that the next request loads them
+func (uc *RateLimitOverrideUseCase) invalidate(ctx context.Context, consumer string) {
+	if uc.cache == nil {
+		return
+	}
+	if err := uc.cache.Delete(ctx, rateLimitOverrideCachePrefix+consumer); err != nil {
+		logger.FromContextOr(ctx, uc.logger).Warn("Failed to invalidate cached rate limit overrides", "consumer", consumer, "error", err)
+	}
+}
+
+// requestConsumer identifies the consumer making a request: the authenticated user or API key,
+// empty for anonymous requests
+func requestConsumer(ctx context.Context, request *entity.Request) string {
+	if request.Authenticated && request.UserID != "" {
+		return request.UserID
+	}
+	if principal, ok := entity.PrincipalFromContext(ctx); ok {
+		return principal.UserID
+	}
+	return ""
+}
+
+// checkOverride validates an override, that its service and endpoint exist and that the
+// consumer has no other override for them
+func (uc *RateLimitOverrideUseCase) checkOverride(ctx context.Context, override *entity.RateLimitOverride) error {
+	if err := override.Validate(); err != nil {
+		return errors.NewError(errors.CodeInvalidInput, err.Error(), errors.ErrInvalidInput)
+	}
+
+	service, err := uc.serviceRepo.Get(ctx, override.ServiceID)
+	if err != nil {
+		if errors.IsNotFound(err) {
+			return errors.NewError(errors.CodeInvalidInput, fmt.Sprintf("service %s not found", override.ServiceID), errors.ErrInvalidInput)
+		}
+		return err
+	}
+	if override.Endpoint != "" && !hasEndpoint(service, override.Endpoint) {
+		return errors.NewError(errors.CodeInvalidInput, fmt.Sprintf("service %s has no endpoint %s", service.Name, override.Endpoint), errors.ErrInvalidInput)
+	}
+
+	existing, err := uc.overrideRepo.FindByConsumer(ctx, override.Consumer)
+	if err != nil {
+		return err
+	}
+	for _, other := range existing {
+		if other.ID != override.ID && other.ServiceID == override.ServiceID && other.Endpoint == override.Endpoint {
+			return errors.ErrAlreadyExists
+		}
+	}
+	return nil
+}
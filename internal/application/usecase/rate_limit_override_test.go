@@ -0,0 +1,95 @@
+package usecase
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"api-gateway-sample/internal/application/dto"
+	"api-gateway-sample/internal/domain/entity"
+	"api-gateway-sample/internal/domain/repository/mock"
+	"api-gateway-sample/pkg/errors"
+)
+
+// endpointLimiter allows the requests per minute of the endpoint it is given per client address
+type endpointLimiter struct {
+	used map[string]int
+}
+
+func (l *endpointLimiter) CheckLimit(ctx context.Context, request *entity.Request, service *entity.Service, endpoint *entity.Endpoint) (bool, error) {
+	return l.used[request.ClientIP] < endpoint.RateLimit, nil
+}
+
+func (l *endpointLimiter) RecordRequest(ctx context.Context, request *entity.Request, service *entity.Service, endpoint *entity.Endpoint) error {
+	l.used[request.ClientIP]++
+	return nil
+}
+
+func (l *endpointLimiter) GetLimit(ctx context.Context, request *entity.Request, service *entity.Service, endpoint *entity.Endpoint) (entity.RateLimitStatus, error) {
+	return entity.RateLimitStatus{Limit: endpoint.RateLimit, Remaining: endpoint.RateLimit - l.used[request.ClientIP], Reset: time.Minute}, nil
+}
+
+func TestProxyUseCase_RateLimitOverrides(t *testing.T) {
+	ctx := context.Background()
+	service := newTestService("orders", entity.Endpoint{Path: "/api/v1/orders", Methods: []string{http.MethodGet}, RateLimit: 1})
+	serviceRepo := newServiceRepository(t, service)
+
+	statuses := make([]int, 10)
+	for i := range statuses {
+		statuses[i] = http.StatusOK
+	}
+	cache := &jsonCache{entries: map[string][]byte{}}
+	overrides := NewRateLimitOverrideUseCase(mock.NewRateLimitOverrideRepositoryMock(), serviceRepo, cache, time.Minute, &MockLogger{})
+	useCase := NewProxyUseCase(serviceRepo, &countingGateway{statuses: statuses}, nil, &endpointLimiter{used: map[string]int{}}, nil, &MockLogger{})
+	useCase.SetRateLimitOverrides(overrides)
+	partnerCtx := entity.ContextWithPrincipal(ctx, &entity.Principal{UserID: "partner-key"})
+	proxy := func(ctx context.Context, clientIP string) error {
+		_, err := useCase.ProxyRequest(ctx, entity.NewRequest(http.MethodGet, "/api/v1/orders", map[string][]string{}, map[string][]string{}, nil, clientIP))
+		return err
+	}
+
+	// 1. Overrides can only target endpoints the service declares
+	limit := 3
+	req := &dto.RateLimitOverrideRequest{Consumer: "partner-key", ServiceID: service.ID, Endpoint: "/api/v1/carts", RateLimit: &limit}
+	if _, err := overrides.CreateOverride(ctx, req); !errors.IsInvalidInput(err) {
+		t.Errorf("Expected an unknown endpoint to be rejected, got %v", err)
+	}
+
+	// 2. The consumer gets its own limit, other clients the endpoint's
+	req.Endpoint = "/api/v1/orders"
+	override, err := overrides.CreateOverride(ctx, req)
+	if err != nil {
+		t.Fatalf("Failed to create override: %v", err)
+	}
+	for i := 0; i < 3; i++ {
+		if err := proxy(partnerCtx, "10.0.0.1"); err != nil {
+			t.Fatalf("Expected request %d of the consumer to be allowed, got %v", i+1, err)
+		}
+	}
+	if err := proxy(partnerCtx, "10.0.0.1"); err == nil {
+		t.Error("Expected the consumer to be limited after 3 requests")
+	}
+	if err := proxy(ctx, "10.0.0.2"); err != nil {
+		t.Fatalf("Expected the first anonymous request to be allowed, got %v", err)
+	}
+	if err := proxy(ctx, "10.0.0.2"); err == nil {
+		t.Error("Expected anonymous clients to keep the endpoint's limit")
+	}
+
+	// 3. A consumer has a single override per endpoint
+	if _, err := overrides.CreateOverride(ctx, req); err != errors.ErrAlreadyExists {
+		t.Errorf("Expected a second override to be rejected, got %v", err)
+	}
+
+	// 4. Deleting the override drops the cached overrides of the consumer
+	if _, err := overrides.consumerOverrides(ctx, "partner-key"); err != nil {
+		t.Fatalf("Failed to load overrides: %v", err)
+	}
+	if err := overrides.DeleteOverride(ctx, override.ID); err != nil {
+		t.Fatalf("Failed to delete override: %v", err)
+	}
+	if got := overrides.Limits(ctx, "partner-key", service, &service.Endpoints[0]); got.RateLimit != 1 {
+		t.Errorf("Expected the endpoint's limit once the override is deleted, got %d", got.RateLimit)
+	}
+}
@@ -27,7 +27,7 @@ func (uc *RateLimitUseCase) CheckLimit(ctx context.Context, request *entity.Requ
 	return uc.rateLimitService.CheckLimit(ctx, request, service, endpoint)
 }
 
-// GetLimit gets the current rate limit for a client
-func (uc *RateLimitUseCase) GetLimit(ctx context.Context, clientID string, service *entity.Service, endpoint *entity.Endpoint) (int, int, error) {
-	return uc.rateLimitService.GetLimit(ctx, clientID, service, endpoint)
+// GetLimit gets the quota left to the client of a request
+func (uc *RateLimitUseCase) GetLimit(ctx context.Context, request *entity.Request, service *entity.Service, endpoint *entity.Endpoint) (entity.RateLimitStatus, error) {
+	return uc.rateLimitService.GetLimit(ctx, request, service, endpoint)
 }
@@ -0,0 +1,78 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"api-gateway-sample/internal/domain/entity"
+	"api-gateway-sample/internal/domain/service"
+	"api-gateway-sample/pkg/errors"
+	"api-gateway-sample/pkg/logger"
+)
+
+// SetRegionLocator locates the region of the callers of services with data residency. Without
+// a locator, their callers are served by the default region of each service.
+func (uc *ProxyUseCase) SetRegionLocator(locator service.RegionLocator) {
+	uc.regions = locator
+}
+
+// locateRegion sets the region serving the caller of a service with data residency on the
+// request, so that its response is cached and coalesced with those of the same region only
+func (uc *ProxyUseCase) locateRegion(ctx context.Context, request *entity.Request, service *entity.Service) {
+	if service.Residency == nil {
+		return
+	}
+	var located string
+	if uc.regions != nil {
+		located = uc.regions.Locate(ctx, request)
+	}
+	request.Region = service.Residency.Serving(located)
+	logger.FromContextOr(ctx, uc.logger).Debug("Located caller region", "region", located, "serving", request.Region)
+}
+
+// forwardRegional forwards a request to the targets serving the caller's region in turn,
+// failing over to the next when a target cannot be reached or is unavailable. Requests from
+// regions the service is not available in are rejected rather than sent elsewhere.
+func (uc *ProxyUseCase) forwardRegional(ctx context.Context, request *entity.Request, service *entity.Service, sample *entity.RequestSample) (*entity.Response, error) {
+	targets := service.Residency.Route(request.Region)
+	if len(targets) == 0 {
+		return nil, errors.NewError(errors.CodeForbidden, fmt.Sprintf("service %s is not available in the caller's region", service.Name), errors.ErrForbidden)
+	}
+
+	var response *entity.Response
+	var err error
+	for i, target := range targets {
+		regional := *service
+		regional.BaseURL = target.BaseURL
+		response, err = uc.forwardUpstream(ctx, request, &regional, sample)
+		if i == len(targets)-1 || !regionalFailure(response, err) {
+			break
+		}
+		logger.FromContextOr(ctx, uc.logger).Warn("Regional upstream failed, failing over",
+			"region", target.Region, "target", target.BaseURL, "next", targets[i+1].Region, "error", err)
+	}
+	return response, err
+}
+
+// regionalFailure reports whether a target failed in a way another target may not
+func regionalFailure(response *entity.Response, err error) bool {
+	if err != nil {
+		return true
+	}
+	switch response.StatusCode {
+	case http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	default:
+		return false
+	}
+}
+
+// regionalCacheKey scopes the cache key of a response to the caller's region, so that
+// responses are never served to callers of another region
+func regionalCacheKey(key string, region string) string {
+	if region == "" {
+		return key
+	}
+	return key + ":" + region
+}
@@ -0,0 +1,99 @@
+package usecase
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"api-gateway-sample/internal/domain/entity"
+	"api-gateway-sample/pkg/errors"
+)
+
+// headerRegionLocator locates callers by the X-Region header
+type headerRegionLocator struct{}
+
+func (headerRegionLocator) Locate(ctx context.Context, request *entity.Request) string {
+	return http.Header(request.Headers).Get("X-Region")
+}
+
+// targetRecordingGateway records the base URL each request is sent to
+type targetRecordingGateway struct {
+	scriptedGateway
+	targets []string
+}
+
+func (g *targetRecordingGateway) TransformRequest(ctx context.Context, request *entity.Request, service *entity.Service) (*entity.Request, error) {
+	g.targets = append(g.targets, service.BaseURL)
+	return request, nil
+}
+
+func TestProxyUseCase_RegionalRouting(t *testing.T) {
+	ctx := context.Background()
+	service := newTestService("records", entity.Endpoint{Path: "/api/v1/records", Methods: []string{http.MethodGet}})
+	service.Residency = &entity.Residency{
+		Targets: []entity.RegionalTarget{
+			{Region: "eu", BaseURL: "http://records.eu"},
+			{Region: "us", BaseURL: "http://records.us-east"},
+			{Region: "us", BaseURL: "http://records.us-west"},
+			{Region: "ca", BaseURL: "http://records.ca"},
+		},
+		Failover: map[string][]string{"ca": {"us"}},
+	}
+
+	ok := &entity.Response{StatusCode: http.StatusOK, Body: []byte(`[]`)}
+	unavailable := &entity.Response{StatusCode: http.StatusServiceUnavailable}
+	gateway := &targetRecordingGateway{scriptedGateway: scriptedGateway{responses: []*entity.Response{
+		ok, unavailable, ok, unavailable, unavailable, ok, unavailable,
+	}}}
+	useCase := newProxyFixture(t, gateway, service)
+	useCase.SetRegionLocator(headerRegionLocator{})
+	get := func(region string) (*entity.Response, error) {
+		headers := map[string][]string{"X-Region": {region}}
+		return useCase.ProxyRequest(ctx, entity.NewRequest(http.MethodGet, "/api/v1/records", headers, nil, nil, "10.0.0.1"))
+	}
+	expectTargets := func(want ...string) {
+		t.Helper()
+		if len(gateway.targets) != len(want) {
+			t.Fatalf("Expected targets %v, got %v", want, gateway.targets)
+		}
+		for i := range want {
+			if gateway.targets[i] != want[i] {
+				t.Errorf("Expected targets %v, got %v", want, gateway.targets)
+			}
+		}
+		gateway.targets = nil
+	}
+
+	// 1. Callers are served by the target of their region
+	if _, err := get("eu"); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	expectTargets("http://records.eu")
+
+	// 2. Unavailable targets fail over to the next target of the region
+	response, err := get("us")
+	if err != nil || response.StatusCode != http.StatusOK {
+		t.Fatalf("Expected failover to succeed, got %v %v", response, err)
+	}
+	expectTargets("http://records.us-east", "http://records.us-west")
+
+	// 3. Then to the targets of the failover regions
+	response, err = get("ca")
+	if err != nil || response.StatusCode != http.StatusOK {
+		t.Fatalf("Expected failover to succeed, got %v %v", response, err)
+	}
+	expectTargets("http://records.ca", "http://records.us-east", "http://records.us-west")
+
+	// 4. Regions without failover keep their requests, even when their target is unavailable
+	response, err = get("eu")
+	if err != nil || response.StatusCode != http.StatusServiceUnavailable {
+		t.Fatalf("Expected the unavailable response of the region, got %v %v", response, err)
+	}
+	expectTargets("http://records.eu")
+
+	// 5. Callers of regions the service is not available in are rejected
+	if _, err := get("apac"); !errors.IsForbidden(err) {
+		t.Errorf("Expected a forbidden error, got %v", err)
+	}
+	expectTargets()
+}
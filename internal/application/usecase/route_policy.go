@@ -0,0 +1,319 @@
+package usecase
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/robfig/cron/v3"
+
+	"api-gateway-sample/internal/application/dto"
+	"api-gateway-sample/internal/domain/entity"
+	"api-gateway-sample/internal/domain/repository"
+	"api-gateway-sample/pkg/errors"
+	"api-gateway-sample/pkg/logger"
+)
+
+// routePolicyCachePrefix prefixes the cache keys of the policies of each service
+const routePolicyCachePrefix = "route-policies:"
+
+// RoutePolicyUseCase manages the scheduled policies of routes, which disable endpoints, answer
+// for them with a maintenance response or change their limits during recurring windows.
+// Policies are stored in the repository and the policies of each service are cached, so that
+// requests do not query the repository.
+type RoutePolicyUseCase struct {
+	policyRepo  repository.RoutePolicyRepository
+	serviceRepo repository.ServiceRepository
+	cache       repository.CacheRepository
+	cacheTTL    time.Duration
+	logger      logger.Logger
+
+	// schedules holds the parsed schedules of policies by expression
+	schedules sync.Map
+	// now returns the time windows are checked at
+	now func() time.Time
+}
+
+// NewRoutePolicyUseCase creates a new RoutePolicyUseCase instance. The policies of a service
+// are cached for cacheTTL; changes made through the use case take effect at once.
+func NewRoutePolicyUseCase(
+	policyRepo repository.RoutePolicyRepository,
+	serviceRepo repository.ServiceRepository,
+	cache repository.CacheRepository,
+	cacheTTL time.Duration,
+	logger logger.Logger,
+) *RoutePolicyUseCase {
+	return &RoutePolicyUseCase{
+		policyRepo:  policyRepo,
+		serviceRepo: serviceRepo,
+		cache:       cache,
+		cacheTTL:    cacheTTL,
+		logger:      logger,
+		now:         time.Now,
+	}
+}
+
+// SetRoutePolicies applies the scheduled route policies managed through the given use case to
+// proxied requests
+func (uc *ProxyUseCase) SetRoutePolicies(policies *RoutePolicyUseCase) {
+	uc.routePolicies = policies
+}
+
+// CreatePolicy creates a scheduled route policy
+func (uc *RoutePolicyUseCase) CreatePolicy(ctx context.Context, req *dto.RoutePolicyRequest) (*entity.RoutePolicy, error) {
+	policy := req.ToEntity()
+	if err := uc.checkPolicy(ctx, policy); err != nil {
+		return nil, err
+	}
+
+	policy.ID = entity.NewRequestID()
+	policy.CreatedAt = time.Now()
+	policy.UpdatedAt = policy.CreatedAt
+	if err := uc.policyRepo.Create(ctx, policy); err != nil {
+		return nil, err
+	}
+	uc.invalidate(ctx, policy.ServiceID)
+	return policy, nil
+}
+
+// UpdatePolicy replaces a scheduled route policy
+func (uc *RoutePolicyUseCase) UpdatePolicy(ctx context.Context, id string, req *dto.RoutePolicyRequest) (*entity.RoutePolicy, error) {
+	existing, err := uc.policyRepo.Get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	policy := req.ToEntity()
+	policy.ID = existing.ID
+	if err := uc.checkPolicy(ctx, policy); err != nil {
+		return nil, err
+	}
+
+	policy.CreatedAt = existing.CreatedAt
+	policy.UpdatedAt = time.Now()
+	if err := uc.policyRepo.Update(ctx, policy); err != nil {
+		return nil, err
+	}
+	uc.invalidate(ctx, existing.ServiceID)
+	uc.invalidate(ctx, policy.ServiceID)
+	return policy, nil
+}
+
+// GetPolicy retrieves a scheduled route policy by ID
+func (uc *RoutePolicyUseCase) GetPolicy(ctx context.Context, id string) (*entity.RoutePolicy, error) {
+	return uc.policyRepo.Get(ctx, id)
+}
+
+// ListPolicies retrieves the policies of a service, or all of them when serviceID is empty
+func (uc *RoutePolicyUseCase) ListPolicies(ctx context.Context, serviceID string) ([]*entity.RoutePolicy, error) {
+	var policies []*entity.RoutePolicy
+	var err error
+	if serviceID == "" {
+		policies, err = uc.policyRepo.GetAll(ctx)
+	} else {
+		policies, err = uc.policyRepo.FindByService(ctx, serviceID)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if policies == nil {
+		policies = []*entity.RoutePolicy{}
+	}
+	return policies, nil
+}
+
+// DeletePolicy deletes a scheduled route policy by ID
+func (uc *RoutePolicyUseCase) DeletePolicy(ctx context.Context, id string) error {
+	existing, err := uc.policyRepo.Get(ctx, id)
+	if err != nil {
+		return err
+	}
+	if err := uc.policyRepo.Delete(ctx, id); err != nil {
+		return err
+	}
+	uc.invalidate(ctx, existing.ServiceID)
+	return nil
+}
+
+// NextWindow returns when the next window of a policy opens and closes after the given time,
+// or the window open at that time
+func (uc *RoutePolicyUseCase) NextWindow(policy *entity.RoutePolicy, after time.Time) (time.Time, time.Time, error) {
+	schedule, err := uc.schedule(policy)
+	if err != nil {
+		return time.Time{}, time.Time{}, err
+	}
+	opens := schedule.Next(after.Add(-policy.WindowDuration()))
+	return opens, opens.Add(policy.WindowDuration()), nil
+}
+
+// enforce applies the policies whose window is open to a request to an endpoint: a disabled
+// endpoint is reported as not found, one under maintenance is answered with the maintenance
+// response, and otherwise the endpoint is returned with the limits that apply. Disabling takes
+// precedence over maintenance and maintenance over limits; the limits of a policy for the
+// endpoint take precedence over those of one for the whole service. The endpoint is served as
+// configured when the policies cannot be loaded.
+func (uc *RoutePolicyUseCase) enforce(ctx context.Context, request *entity.Request, service *entity.Service, endpoint *entity.Endpoint) (*entity.Response, *entity.Endpoint, error) {
+	log := logger.FromContextOr(ctx, uc.logger)
+	policies, err := uc.servicePolicies(ctx, service.ID)
+	if err != nil {
+		log.Warn("Failed to load route policies", "service_id", service.ID, "error", err)
+		return nil, endpoint, nil
+	}
+
+	now := uc.now()
+	var maintenance, serviceLimits, endpointLimits *entity.RoutePolicy
+	var maintenanceEnds time.Time
+	for _, policy := range policies {
+		if !policy.AppliesTo(service.ID, endpoint.Path) {
+			continue
+		}
+		opens, closes, err := uc.NextWindow(policy, now)
+		if err != nil {
+			log.Warn("Invalid route policy schedule", "policy_id", policy.ID, "error", err)
+			continue
+		}
+		if opens.After(now) {
+			continue
+		}
+
+		switch policy.Action {
+		case entity.RoutePolicyDisable:
+			log.Debug("Route disabled by policy", "policy_id", policy.ID)
+			return nil, nil, errors.ErrServiceNotFound
+		case entity.RoutePolicyMaintenance:
+			if maintenance == nil || closes.After(maintenanceEnds) {
+				maintenance, maintenanceEnds = policy, closes
+			}
+		case entity.RoutePolicyRateLimit:
+			if policy.Endpoint == "" {
+				serviceLimits = policy
+			} else {
+				endpointLimits = policy
+			}
+		}
+	}
+
+	if maintenance != nil {
+		response, err := maintenanceResponse(request, service, maintenance, maintenanceEnds.Sub(now))
+		return response, nil, err
+	}
+	if serviceLimits != nil {
+		endpoint = serviceLimits.Apply(endpoint)
+	}
+	if endpointLimits != nil {
+		endpoint = endpointLimits.Apply(endpoint)
+	}
+	return nil, endpoint, nil
+}
+
+// maintenanceResponse builds the response of a policy's maintenance window, telling clients
+// to retry once the window closes
+func maintenanceResponse(request *entity.Request, service *entity.Service, policy *entity.RoutePolicy, remaining time.Duration) (*entity.Response, error) {
+	status := policy.MaintenanceStatus()
+	headers := map[string][]string{}
+	if status == http.StatusServiceUnavailable || status == http.StatusTooManyRequests {
+		headers["Retry-After"] = []string{fmt.Sprint(int(math.Ceil(remaining.Seconds())))}
+	}
+
+	if policy.Body != "" {
+		contentType := policy.ContentType
+		if contentType == "" {
+			contentType = "application/json"
+		}
+		headers["Content-Type"] = []string{contentType}
+		return entity.NewResponse(request.ID, status, headers, []byte(policy.Body)), nil
+	}
+
+	problem := errors.NewProblem(errors.ProblemUnderMaintenance, service.Name+" is under maintenance")
+	problem.Status = status
+	body, err := json.Marshal(problem)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode maintenance response: %w", err)
+	}
+	headers["Content-Type"] = []string{errors.ProblemContentType}
+	return entity.NewResponse(request.ID, status, headers, body), nil
+}
+
+// schedule returns the parsed schedule of a policy in its time zone
+func (uc *RoutePolicyUseCase) schedule(policy *entity.RoutePolicy) (cron.Schedule, error) {
+	expression := policy.Schedule
+	if policy.Timezone != "" {
+		expression = "CRON_TZ=" + policy.Timezone + " " + expression
+	}
+	if schedule, ok := uc.schedules.Load(expression); ok {
+		return schedule.(cron.Schedule), nil
+	}
+
+	schedule, err := cron.ParseStandard(expression)
+	if err != nil {
+		return nil, err
+	}
+	// Windows open at fixed times; an interval from whenever the schedule is checked has none
+	if _, ok := schedule.(cron.ConstantDelaySchedule); ok {
+		return nil, fmt.Errorf("@every schedules are not supported")
+	}
+	uc.schedules.Store(expression, schedule)
+	return schedule, nil
+}
+
+// servicePolicies returns the policies of a service from the cache, loading and caching them
+// on a miss. Services without policies are cached too.
+func (uc *RoutePolicyUseCase) servicePolicies(ctx context.Context, serviceID string) ([]*entity.RoutePolicy, error) {
+	key := routePolicyCachePrefix + serviceID
+	var policies []*entity.RoutePolicy
+	if uc.cache != nil {
+		if err := uc.cache.Get(ctx, key, &policies); err == nil {
+			return policies, nil
+		}
+	}
+
+	policies, err := uc.policyRepo.FindByService(ctx, serviceID)
+	if err != nil {
+		return nil, err
+	}
+	if policies == nil {
+		policies = []*entity.RoutePolicy{}
+	}
+	if uc.cache != nil {
+		if err := uc.cache.Set(ctx, key, policies, uc.cacheTTL); err != nil {
+			logger.FromContextOr(ctx, uc.logger).Warn("Failed to cache route policies", "service_id", serviceID, "error", err)
+		}
+	}
+	return policies, nil
+}
+
+// invalidate drops the cached policies of a service so that the next request loads them
+func (uc *RoutePolicyUseCase) invalidate(ctx context.Context, serviceID string) {
+	if uc.cache == nil {
+		return
+	}
+	if err := uc.cache.Delete(ctx, routePolicyCachePrefix+serviceID); err != nil {
+		logger.FromContextOr(ctx, uc.logger).Warn("Failed to invalidate cached route policies", "service_id", serviceID, "error", err)
+	}
+}
+
+// checkPolicy validates a policy, its schedule and that its service and endpoint exist
+func (uc *RoutePolicyUseCase) checkPolicy(ctx context.Context, policy *entity.RoutePolicy) error {
+	if err := policy.Validate(); err != nil {
+		return errors.NewError(errors.CodeInvalidInput, err.Error(), errors.ErrInvalidInput)
+	}
+	if _, err := uc.schedule(policy); err != nil {
+		return errors.NewError(errors.CodeInvalidInput, fmt.Sprintf("invalid schedule: %v", err), errors.ErrInvalidInput)
+	}
+
+	service, err := uc.serviceRepo.Get(ctx, policy.ServiceID)
+	if err != nil {
+		if errors.IsNotFound(err) {
+			return errors.NewError(errors.CodeInvalidInput, fmt.Sprintf("service %s not found", policy.ServiceID), errors.ErrInvalidInput)
+		}
+		return err
+	}
+	if policy.Endpoint != "" && !hasEndpoint(service, policy.Endpoint) {
+		return errors.NewError(errors.CodeInvalidInput, fmt.Sprintf("service %s has no endpoint %s", service.Name, policy.Endpoint), errors.ErrInvalidInput)
+	}
+	return nil
+}
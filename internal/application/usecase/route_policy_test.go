@@ -0,0 +1,136 @@
+package usecase
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"api-gateway-sample/internal/application/dto"
+	"api-gateway-sample/internal/domain/entity"
+	"api-gateway-sample/internal/domain/repository/mock"
+	"api-gateway-sample/pkg/errors"
+)
+
+func TestProxyUseCase_RoutePolicies(t *testing.T) {
+	ctx := context.Background()
+	service := newTestService("orders",
+		entity.Endpoint{Path: "/api/v1/orders", Methods: []string{http.MethodGet}, RateLimit: 1},
+		entity.Endpoint{Path: "/api/v1/carts", Methods: []string{http.MethodGet}},
+	)
+	serviceRepo := newServiceRepository(t, service)
+
+	statuses := make([]int, 10)
+	for i := range statuses {
+		statuses[i] = http.StatusOK
+	}
+	cache := &jsonCache{entries: map[string][]byte{}}
+	policies := NewRoutePolicyUseCase(mock.NewRoutePolicyRepositoryMock(), serviceRepo, cache, time.Minute, &MockLogger{})
+	// Sunday 2026-10-18, 02:30 UTC
+	now := time.Date(2026, 10, 18, 2, 30, 0, 0, time.UTC)
+	policies.now = func() time.Time { return now }
+	useCase := NewProxyUseCase(serviceRepo, &countingGateway{statuses: statuses}, nil, &endpointLimiter{used: map[string]int{}}, nil, &MockLogger{})
+	useCase.SetRoutePolicies(policies)
+	proxy := func(path string, clientIP string) (*entity.Response, error) {
+		return useCase.ProxyRequest(ctx, entity.NewRequest(http.MethodGet, path, map[string][]string{}, map[string][]string{}, nil, clientIP))
+	}
+
+	// 1. Schedules are checked when policies are created
+	req := &dto.RoutePolicyRequest{ServiceID: service.ID, Schedule: "@every 1h", Duration: 60, Action: entity.RoutePolicyMaintenance}
+	if _, err := policies.CreatePolicy(ctx, req); !errors.IsInvalidInput(err) {
+		t.Errorf("Expected an @every schedule to be rejected, got %v", err)
+	}
+
+	// 2. A maintenance window answers for every endpoint of the service until it closes
+	req.Schedule = "0 2 * * 0"
+	maintenance, err := policies.CreatePolicy(ctx, req)
+	if err != nil {
+		t.Fatalf("Failed to create policy: %v", err)
+	}
+	response, err := proxy("/api/v1/carts", "10.0.0.1")
+	if err != nil {
+		t.Fatalf("Expected a maintenance response, got %v", err)
+	}
+	if response.StatusCode != http.StatusServiceUnavailable || response.Headers["Retry-After"][0] != "1800" {
+		t.Errorf("Expected 503 retried in 1800s, got %d and %v", response.StatusCode, response.Headers["Retry-After"])
+	}
+	if !strings.Contains(string(response.Body), errors.ProblemUnderMaintenance.Type) {
+		t.Errorf("Expected a maintenance problem, got %s", response.Body)
+	}
+
+	// 3. Once the window closes the endpoint is served again, and disabled during a window
+	now = time.Date(2026, 10, 18, 3, 0, 0, 0, time.UTC)
+	if _, err := proxy("/api/v1/carts", "10.0.0.1"); err != nil {
+		t.Errorf("Expected the endpoint to be served after the window, got %v", err)
+	}
+	if _, err := policies.CreatePolicy(ctx, &dto.RoutePolicyRequest{
+		ServiceID: service.ID, Endpoint: "/api/v1/carts", Schedule: "0 * * * *", Duration: 30, Action: entity.RoutePolicyDisable,
+	}); err != nil {
+		t.Fatalf("Failed to create policy: %v", err)
+	}
+	if _, err := proxy("/api/v1/carts", "10.0.0.1"); err != errors.ErrServiceNotFound {
+		t.Errorf("Expected the disabled endpoint to be reported as not found, got %v", err)
+	}
+
+	// 4. Limits are replaced during their window only
+	limit := 2
+	if _, err := policies.CreatePolicy(ctx, &dto.RoutePolicyRequest{
+		ServiceID: service.ID, Endpoint: "/api/v1/orders", Schedule: "0 3 * * *", Duration: 60, Action: entity.RoutePolicyRateLimit, RateLimit: &limit,
+	}); err != nil {
+		t.Fatalf("Failed to create policy: %v", err)
+	}
+	for i := 0; i < 2; i++ {
+		if _, err := proxy("/api/v1/orders", "10.0.0.2"); err != nil {
+			t.Fatalf("Expected request %d to be allowed by the scheduled limit, got %v", i+1, err)
+		}
+	}
+	if _, err := proxy("/api/v1/orders", "10.0.0.2"); !errors.IsRateLimitExceeded(err) {
+		t.Errorf("Expected the scheduled limit to apply, got %v", err)
+	}
+	now = time.Date(2026, 10, 18, 4, 0, 0, 0, time.UTC)
+	if _, err := proxy("/api/v1/orders", "10.0.0.3"); err != nil {
+		t.Fatalf("Expected the first request after the window to be allowed, got %v", err)
+	}
+	if _, err := proxy("/api/v1/orders", "10.0.0.3"); err == nil {
+		t.Error("Expected the endpoint's own limit after the window")
+	}
+
+	// 5. Disabled policies are kept without applying, and changes drop the cached policies
+	if _, err := policies.servicePolicies(ctx, service.ID); err != nil {
+		t.Fatalf("Failed to load policies: %v", err)
+	}
+	now = time.Date(2026, 10, 25, 2, 15, 0, 0, time.UTC)
+	disabled := false
+	req.Enabled = &disabled
+	if _, err := policies.UpdatePolicy(ctx, maintenance.ID, req); err != nil {
+		t.Fatalf("Failed to update policy: %v", err)
+	}
+	if response, err := proxy("/api/v1/orders", "10.0.0.4"); err != nil || response.StatusCode != http.StatusOK {
+		t.Errorf("Expected the disabled maintenance policy not to apply, got %v", err)
+	}
+}
+
+func TestRoutePolicyUseCase_NextWindow(t *testing.T) {
+	policies := NewRoutePolicyUseCase(mock.NewRoutePolicyRepositoryMock(), mock.NewServiceRepositoryMock(), nil, time.Minute, &MockLogger{})
+	policy := &entity.RoutePolicy{Schedule: "0 22 * * 5", Duration: 120}
+	friday := time.Date(2026, 10, 16, 23, 0, 0, 0, time.UTC)
+
+	opens, closes, err := policies.NextWindow(policy, friday)
+	if err != nil {
+		t.Fatalf("Failed to compute window: %v", err)
+	}
+	if !opens.Equal(time.Date(2026, 10, 16, 22, 0, 0, 0, time.UTC)) || !closes.Equal(time.Date(2026, 10, 17, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("Expected the open window from 22:00 to midnight, got %s to %s", opens, closes)
+	}
+
+	opens, _, _ = policies.NextWindow(policy, closes)
+	if !opens.Equal(time.Date(2026, 10, 23, 22, 0, 0, 0, time.UTC)) {
+		t.Errorf("Expected the next window on the following Friday once it closes, got %s", opens)
+	}
+
+	policy.Timezone = "Not/AZone"
+	if _, _, err := policies.NextWindow(policy, friday); err == nil {
+		t.Error("Expected an unknown time zone to be rejected")
+	}
+}
@@ -0,0 +1,298 @@
+package usecase
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"time"
+
+	"api-gateway-sample/internal/application/dto"
+	"api-gateway-sample/internal/domain/entity"
+	"api-gateway-sample/pkg/errors"
+	"api-gateway-sample/pkg/logger"
+)
+
+// Route conflict policies, applied when a change makes services serve the same route with the
+// same priority
+const (
+	// RouteConflictsReject rejects the change
+	RouteConflictsReject = "reject"
+	// RouteConflictsWarn makes the change and reports the conflicts in the response
+	RouteConflictsWarn = "warn"
+)
+
+// Routes are matched by exact path first, then by the longest prefix: an endpoint whose path
+// ends in "/*" serves every path beneath it. Among the endpoints serving a route, the one with
+// the highest priority is chosen, then one listing the method over one accepting any method
+// with "*", then the service first by name and, within a service, the endpoint declared first.
+// Services and endpoints outside their validity window are not served, and endpoints whose
+// windows do not overlap never conflict. Endpoints behind a feature flag are skipped for the
+// callers it is off for.
+
+// routePatterns returns the endpoint paths that can serve a request path, in order of precedence
+func routePatterns(path string) []string {
+	patterns := make([]string, 1, 1+strings.Count(path, "/"))
+	patterns[0] = path
+	for i := len(path) - 1; i >= 0; i-- {
+		if path[i] == '/' {
+			patterns = append(patterns, path[:i+1]+"*")
+		}
+	}
+	return patterns
+}
+
+// methodRank returns how specifically an endpoint serves a method: 2 when it lists the method,
+// 1 when it accepts any method and 0 when it does not serve it
+func methodRank(endpoint *entity.Endpoint, method string) int {
+	rank := 0
+	for _, supported := range endpoint.Methods {
+		if supported == method {
+			return 2
+		}
+		if supported == "*" {
+			rank = 1
+		}
+	}
+	return rank
+}
+
+// serviceEndpoint returns the endpoint of a service that serves a method on an endpoint path at
+// a time: the one with the highest priority, then listing the method, then declared first. The
+// zero time considers every endpoint, whatever its validity window. Endpoints served rejects are
+// skipped; a nil served considers every endpoint.
+func serviceEndpoint(service *entity.Service, path string, method string, at time.Time, served func(*entity.Endpoint) bool) *entity.Endpoint {
+	var match *entity.Endpoint
+	matchRank := 0
+	for i := range service.Endpoints {
+		endpoint := &service.Endpoints[i]
+		if endpoint.Path != path || !at.IsZero() && !endpoint.ServedAt(at) {
+			continue
+		}
+		rank := methodRank(endpoint, method)
+		if rank == 0 || served != nil && !served(endpoint) {
+			continue
+		}
+		if match == nil || endpoint.Priority > match.Priority || (endpoint.Priority == match.Priority && rank > matchRank) {
+			match, matchRank = endpoint, rank
+		}
+	}
+	return match
+}
+
+// routeCandidate is a service serving a route with one of its endpoints
+type routeCandidate struct {
+	service  *entity.Service
+	endpoint *entity.Endpoint
+	rank     int
+}
+
+// precedes reports whether c is chosen over other for the same route
+func (c routeCandidate) precedes(other routeCandidate) bool {
+	if c.endpoint.Priority != other.endpoint.Priority {
+		return c.endpoint.Priority > other.endpoint.Priority
+	}
+	if c.rank != other.rank {
+		return c.rank > other.rank
+	}
+	return c.service.Name < other.service.Name
+}
+
+// ties reports whether only the names of the services order c and other at a time both are served
+func (c routeCandidate) ties(other routeCandidate) bool {
+	if c.endpoint.Priority != other.endpoint.Priority || c.rank != other.rank {
+		return false
+	}
+	from, until := c.service.RouteWindow(c.endpoint)
+	otherFrom, otherUntil := other.service.RouteWindow(other.endpoint)
+	return entity.WindowsOverlap(from, until, otherFrom, otherUntil)
+}
+
+// selectRoute returns the service and endpoint chosen for a method on an endpoint path at a time,
+// among the endpoints served accepts
+func selectRoute(services []*entity.Service, path string, method string, at time.Time, served func(*entity.Endpoint) bool) (*entity.Service, *entity.Endpoint) {
+	var best *routeCandidate
+	for _, service := range services {
+		if !service.ServedAt(at) {
+			continue
+		}
+		endpoint := serviceEndpoint(service, path, method, at, served)
+		if endpoint == nil {
+			continue
+		}
+		candidate := routeCandidate{service: service, endpoint: endpoint, rank: methodRank(endpoint, method)}
+		if best == nil || candidate.precedes(*best) {
+			best = &candidate
+		}
+	}
+	if best == nil {
+		return nil, nil
+	}
+	return best.service, best.endpoint
+}
+
+// serviceRoutes returns the routes of a service, sorted, and those it never reaches because
+// another endpoint of the service takes precedence
+func serviceRoutes(service *entity.Service) ([]string, []string) {
+	routes := []string{}
+	shadowed := []string{}
+	seen := make(map[string]bool)
+	for i, endpoint := range service.Endpoints {
+		for _, method := range endpoint.Methods {
+			route := method + " " + endpoint.Path
+			// Endpoints behind a feature flag only shadow others for the callers it is on for
+			unflagged := func(other *entity.Endpoint) bool { return other.FeatureFlag == "" || other == &service.Endpoints[i] }
+			reached := serviceEndpoint(service, endpoint.Path, method, time.Time{}, unflagged) == &service.Endpoints[i]
+			if reached && !seen[route] {
+				routes = append(routes, route)
+			} else if !reached && !seen["shadowed "+route] {
+				shadowed = append(shadowed, route)
+				seen["shadowed "+route] = true
+			}
+			seen[route] = seen[route] || reached
+		}
+	}
+	sort.Strings(routes)
+	sort.Strings(shadowed)
+	return routes, shadowed
+}
+
+// routeConflicts returns the routes served by more than one of the services, sorted, with the
+// services in order of precedence
+func routeConflicts(services []*entity.Service) []dto.RouteConflict {
+	type route struct {
+		path   string
+		method string
+	}
+	served := make(map[route]map[*entity.Service]bool)
+	for _, service := range services {
+		for _, endpoint := range service.Endpoints {
+			for _, method := range endpoint.Methods {
+				key := route{path: endpoint.Path, method: method}
+				if served[key] == nil {
+					served[key] = make(map[*entity.Service]bool)
+				}
+				served[key][service] = true
+			}
+		}
+	}
+
+	conflicts := []dto.RouteConflict{}
+	for key := range served {
+		// Endpoints accepting any method also serve the routes with a method
+		servers := make(map[*entity.Service]bool)
+		for service := range served[key] {
+			servers[service] = true
+		}
+		if key.method != "*" {
+			for service := range served[route{path: key.path, method: "*"}] {
+				servers[service] = true
+			}
+		}
+		if len(servers) < 2 {
+			continue
+		}
+
+		candidates := make([]routeCandidate, 0, len(servers))
+		for service := range servers {
+			endpoint := serviceEndpoint(service, key.path, key.method, time.Time{}, nil)
+			candidates = append(candidates, routeCandidate{service: service, endpoint: endpoint, rank: methodRank(endpoint, key.method)})
+		}
+		sort.Slice(candidates, func(i, j int) bool { return candidates[i].precedes(candidates[j]) })
+		conflict := dto.RouteConflict{Route: key.method + " " + key.path}
+		for i, candidate := range candidates {
+			conflict.Services = append(conflict.Services, candidate.service.Name)
+			// Candidates served at different times may tie with one after the next
+			for _, other := range candidates[i+1:] {
+				conflict.Ambiguous = conflict.Ambiguous || candidate.ties(other)
+			}
+		}
+		conflicts = append(conflicts, conflict)
+	}
+	sort.Slice(conflicts, func(i, j int) bool { return conflicts[i].Route < conflicts[j].Route })
+	return conflicts
+}
+
+// subtractRoutes returns the routes not in exclude, keeping their order
+func subtractRoutes(routes []string, exclude []string) []string {
+	excluded := make(map[string]bool, len(exclude))
+	for _, route := range exclude {
+		excluded[route] = true
+	}
+	remaining := []string{}
+	for _, route := range routes {
+		if !excluded[route] {
+			remaining = append(remaining, route)
+		}
+	}
+	return remaining
+}
+
+// describeConflicts lists conflicts for a message, e.g. "GET /a (billing, orders)"
+func describeConflicts(conflicts []dto.RouteConflict) string {
+	described := make([]string, len(conflicts))
+	for i, conflict := range conflicts {
+		described[i] = conflict.Route + " (" + strings.Join(conflict.Services, ", ") + ")"
+	}
+	return strings.Join(described, "; ")
+}
+
+// SetRouteConflictPolicy sets how changes making services serve the same route with the same
+// priority are handled, RouteConflictsReject unless set
+func (uc *ServiceUseCase) SetRouteConflictPolicy(policy string) {
+	uc.conflictPolicy = policy
+}
+
+// serviceConflicts returns the routes of a service that other services also serve. The service
+// replaces the stored one with the ID replaces, if any.
+func (uc *ServiceUseCase) serviceConflicts(ctx context.Context, service *entity.Service, replaces string) ([]dto.RouteConflict, error) {
+	services, err := uc.serviceRepo.GetAll(ctx)
+	if err != nil {
+		return nil, err
+	}
+	table := []*entity.Service{service}
+	for _, other := range services {
+		if other.ID != replaces || replaces == "" {
+			table = append(table, other)
+		}
+	}
+
+	conflicts := []dto.RouteConflict{}
+	for _, conflict := range routeConflicts(table) {
+		for _, name := range conflict.Services {
+			if name == service.Name {
+				conflicts = append(conflicts, conflict)
+				break
+			}
+		}
+	}
+	return conflicts, nil
+}
+
+// checkRoutes applies the route conflict policy to a change of a service, returning the
+// ambiguous conflicts it is allowed to make
+func (uc *ServiceUseCase) checkRoutes(ctx context.Context, service *entity.Service, replaces string) ([]dto.RouteConflict, error) {
+	conflicts, err := uc.serviceConflicts(ctx, service, replaces)
+	if err != nil {
+		return nil, err
+	}
+	return uc.checkConflicts(ctx, conflicts)
+}
+
+// checkConflicts applies the route conflict policy to conflicts, returning the ambiguous ones
+// when they are allowed
+func (uc *ServiceUseCase) checkConflicts(ctx context.Context, conflicts []dto.RouteConflict) ([]dto.RouteConflict, error) {
+	var ambiguous []dto.RouteConflict
+	for _, conflict := range conflicts {
+		if conflict.Ambiguous {
+			ambiguous = append(ambiguous, conflict)
+		}
+	}
+	if len(ambiguous) == 0 {
+		return nil, nil
+	}
+	if uc.conflictPolicy != RouteConflictsWarn {
+		return nil, errors.NewError(errors.CodeAlreadyExists, "routes served by several services with the same priority: "+describeConflicts(ambiguous), errors.ErrRouteConflict)
+	}
+	logger.FromContext(ctx).Warn("Routes served by several services with the same priority", "conflicts", describeConflicts(ambiguous))
+	return ambiguous, nil
+}
@@ -0,0 +1,216 @@
+package usecase
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+
+	"api-gateway-sample/internal/application/dto"
+	"api-gateway-sample/internal/domain/entity"
+	"api-gateway-sample/internal/domain/repository/mock"
+	"api-gateway-sample/pkg/errors"
+)
+
+func TestRoutePatterns(t *testing.T) {
+	want := []string{"/api/v1/files/a.txt", "/api/v1/files/*", "/api/v1/*", "/api/*", "/*"}
+	if got := routePatterns("/api/v1/files/a.txt"); !reflect.DeepEqual(got, want) {
+		t.Errorf("Expected patterns %v, got %v", want, got)
+	}
+}
+
+func TestSelectRoute(t *testing.T) {
+	service := func(name string, priority int, methods ...string) *entity.Service {
+		s := entity.NewService(name+"-id", name, "1.0.0", "", "http://"+name, 30, 3)
+		s.AddEndpoint(entity.Endpoint{Path: "/api/v1/users", Methods: methods, Priority: priority})
+		return s
+	}
+
+	tests := []struct {
+		name     string
+		services []*entity.Service
+		want     string
+	}{
+		{"the first by name", []*entity.Service{service("users", 0, "GET"), service("accounts", 0, "GET")}, "accounts"},
+		{"the highest priority", []*entity.Service{service("accounts", 0, "GET"), service("users", 5, "GET")}, "users"},
+		{"the listed method over any method", []*entity.Service{service("accounts", 0, "*"), service("users", 0, "GET")}, "users"},
+		{"priority over the listed method", []*entity.Service{service("accounts", 1, "*"), service("users", 0, "GET")}, "accounts"},
+		{"none serving the method", []*entity.Service{service("users", 0, "POST")}, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, _ := selectRoute(tt.services, "/api/v1/users", "GET", time.Now(), nil)
+			name := ""
+			if got != nil {
+				name = got.Name
+			}
+			if name != tt.want {
+				t.Errorf("Expected %q, got %q", tt.want, name)
+			}
+		})
+	}
+}
+
+func TestServiceUseCase_RouteConflicts(t *testing.T) {
+	ctx := context.Background()
+	repo := mock.NewServiceRepositoryMock()
+	users := entity.NewService("users-id", "users", "1.0.0", "", "http://users", 30, 3)
+	users.AddEndpoint(entity.Endpoint{Path: "/api/v1/users", Methods: []string{"GET", "POST"}})
+	if err := repo.Create(ctx, users); err != nil {
+		t.Fatalf("Failed to create service: %v", err)
+	}
+	useCase := NewServiceUseCase(repo, nil, nil)
+	accounts := &dto.CreateServiceRequest{
+		Name:      "accounts",
+		BaseURL:   "http://accounts",
+		Endpoints: []dto.EndpointConfig{{Path: "/api/v1/users", Methods: []string{"GET"}}},
+	}
+
+	// 1. A route served with the same priority is rejected with the details
+	_, err := useCase.CreateService(ctx, accounts)
+	if !errors.IsRouteConflict(err) || err.Error() != "routes served by several services with the same priority: GET /api/v1/users (accounts, users): route conflict" {
+		t.Fatalf("Expected a route conflict, got %v", err)
+	}
+
+	// 2. A different priority makes the overlap intentional
+	accounts.Endpoints[0].Priority = 1
+	if _, err := useCase.CreateService(ctx, accounts); err != nil {
+		t.Fatalf("Failed to create service with a priority: %v", err)
+	}
+	if err := repo.Delete(ctx, "test-id", 0); err != nil {
+		t.Fatalf("Failed to delete service: %v", err)
+	}
+
+	// 3. With warnings the change is made and the conflicts returned
+	accounts.Endpoints[0].Priority = 0
+	useCase.SetRouteConflictPolicy(RouteConflictsWarn)
+	service, err := useCase.CreateService(ctx, accounts)
+	if err != nil {
+		t.Fatalf("Failed to create service with warnings: %v", err)
+	}
+	want := []dto.RouteConflict{{Route: "GET /api/v1/users", Services: []string{"accounts", "users"}, Ambiguous: true}}
+	if !reflect.DeepEqual(service.Conflicts, want) {
+		t.Errorf("Expected conflicts %v, got %v", want, service.Conflicts)
+	}
+}
+
+func TestProxyUseCase_ResolveEndpoint(t *testing.T) {
+	ctx := context.Background()
+	files := newTestService("files",
+		entity.Endpoint{Path: "/api/v1/files/*", Methods: []string{"GET"}},
+		entity.Endpoint{Path: "/api/v1/files/*", Methods: []string{"GET"}, Priority: 1, Timeout: 60},
+		entity.Endpoint{Path: "/api/v1/*", Methods: []string{"GET"}},
+	)
+	legacy := newTestService("legacy",
+		entity.Endpoint{Path: "/api/v1/files/report", Methods: []string{"GET"}},
+		entity.Endpoint{Path: "/api/v1/files/*", Methods: []string{"GET"}, Priority: 1},
+	)
+	useCase := newProxyFixture(t, nil, files, legacy)
+
+	tests := []struct {
+		path     string
+		service  string
+		endpoint string
+		timeout  int
+	}{
+		// The exact path is preferred over any prefix
+		{"/api/v1/files/report", "legacy", "/api/v1/files/report", 0},
+		// The longest prefix, then the highest priority endpoint, then the first service by name
+		{"/api/v1/files/a/b.txt", "files", "/api/v1/files/*", 60},
+		{"/api/v1/users", "files", "/api/v1/*", 0},
+	}
+	for _, tt := range tests {
+		service, endpoint, err := useCase.ResolveEndpoint(ctx, tt.path, "GET")
+		if err != nil {
+			t.Fatalf("Failed to resolve %s: %v", tt.path, err)
+		}
+		if service.Name != tt.service || endpoint.Path != tt.endpoint || endpoint.Timeout != tt.timeout {
+			t.Errorf("Expected %s to resolve to %s %s, got %s %+v", tt.path, tt.service, tt.endpoint, service.Name, endpoint)
+		}
+	}
+
+	if _, _, err := useCase.ResolveEndpoint(ctx, "/other", "GET"); err != errors.ErrServiceNotFound {
+		t.Errorf("Expected no service to be found, got %v", err)
+	}
+}
+
+func TestProxyUseCase_ResolveEndpointValidity(t *testing.T) {
+	ctx := context.Background()
+	now := time.Now()
+	past, future := now.Add(-time.Hour), now.Add(time.Hour)
+	// users-v1 hands the route over to users-v2 an hour ago
+	v1 := newTestService("users-v1", entity.Endpoint{Path: "/api/v1/users", Methods: []string{"GET"}, ValidUntil: &past})
+	v2 := newTestService("users-v2", entity.Endpoint{Path: "/api/v1/users", Methods: []string{"GET"}, ValidFrom: &past})
+	// promo is not served yet
+	promo := newTestService("promo", entity.Endpoint{Path: "/api/v1/promo", Methods: []string{"GET"}})
+	promo.ValidFrom = &future
+	useCase := newProxyFixture(t, nil, v1, v2, promo)
+
+	service, _, err := useCase.ResolveEndpoint(ctx, "/api/v1/users", "GET")
+	if err != nil {
+		t.Fatalf("Failed to resolve /api/v1/users: %v", err)
+	}
+	if service.Name != "users-v2" {
+		t.Errorf("Expected /api/v1/users to resolve to users-v2, got %s", service.Name)
+	}
+	if _, _, err := useCase.ResolveEndpoint(ctx, "/api/v1/promo", "GET"); err != errors.ErrServiceNotFound {
+		t.Errorf("Expected promo not to be served yet, got %v", err)
+	}
+
+	// Windows that do not overlap do not conflict
+	if conflicts := routeConflicts([]*entity.Service{v1, v2}); len(conflicts) != 1 || conflicts[0].Ambiguous {
+		t.Errorf("Expected an unambiguous overlap, got %+v", conflicts)
+	}
+}
+
+// methodGateway records the methods of the requests sent upstream
+type methodGateway struct {
+	countingGateway
+	methods []string
+}
+
+func (g *methodGateway) RouteRequest(ctx context.Context, request *entity.Request) (*entity.Response, error) {
+	g.methods = append(g.methods, request.Method)
+	return g.countingGateway.RouteRequest(ctx, request)
+}
+
+func TestProxyUseCase_ImplicitMethods(t *testing.T) {
+	ctx := context.Background()
+	orders := newTestService("orders",
+		entity.Endpoint{Path: "/api/v1/orders", Methods: []string{"GET", "POST"}},
+		entity.Endpoint{Path: "/api/v1/files", Methods: []string{"GET", "HEAD"}},
+	)
+	gateway := &methodGateway{countingGateway: countingGateway{statuses: []int{200, 200}}}
+	useCase := newProxyFixture(t, gateway, orders)
+
+	// 1. Paths served with GET are served with HEAD and OPTIONS too
+	methods, err := useCase.AllowedMethods(ctx, "/api/v1/orders")
+	if err != nil {
+		t.Fatalf("Failed to get allowed methods: %v", err)
+	}
+	if want := []string{"GET", "HEAD", "POST", "OPTIONS"}; !reflect.DeepEqual(methods, want) {
+		t.Errorf("Expected methods %v, got %v", want, methods)
+	}
+
+	// 2. HEAD is answered from a GET without its body
+	response, err := useCase.ProxyRequest(ctx, entity.NewRequest("HEAD", "/api/v1/orders", map[string][]string{}, map[string][]string{}, nil, "10.0.0.1"))
+	if err != nil {
+		t.Fatalf("Failed to proxy HEAD request: %v", err)
+	}
+	if len(response.Body) != 0 || response.Headers["Content-Length"][0] != "16" || response.Headers["Content-Type"][0] != "application/json" {
+		t.Errorf("Expected the headers of the GET response without its body, got %v %q", response.Headers, response.Body)
+	}
+
+	// 3. Endpoints serving HEAD receive it
+	if _, err := useCase.ProxyRequest(ctx, entity.NewRequest("HEAD", "/api/v1/files", map[string][]string{}, map[string][]string{}, nil, "10.0.0.1")); err != nil {
+		t.Fatalf("Failed to proxy HEAD request: %v", err)
+	}
+	if want := []string{"GET", "HEAD"}; !reflect.DeepEqual(gateway.methods, want) {
+		t.Errorf("Expected upstream methods %v, got %v", want, gateway.methods)
+	}
+
+	// 4. Paths no endpoint serves have no methods
+	if methods, _ := useCase.AllowedMethods(ctx, "/api/v1/unknown"); len(methods) != 0 {
+		t.Errorf("Expected no methods, got %v", methods)
+	}
+}
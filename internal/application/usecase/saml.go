@@ -0,0 +1,101 @@
+package usecase
+
+import (
+	"context"
+	"time"
+
+	"api-gateway-sample/internal/domain/repository"
+	"api-gateway-sample/internal/domain/service"
+	"api-gateway-sample/pkg/errors"
+	"api-gateway-sample/pkg/logger"
+)
+
+// samlLoginCachePrefix prefixes the cache keys of the logins in progress, keyed by request ID
+const samlLoginCachePrefix = "saml-login:"
+
+// samlLogin is a login in progress, kept until the identity provider answers its request
+type samlLogin struct {
+	ReturnTo string `json:"returnTo"`
+}
+
+// SAMLUseCase implements SAML 2.0 web browser single sign-on with the gateway as service
+// provider. Users are sent to the identity provider to log in and, when it posts back an
+// assertion answering their request, issued a gateway token that serves as their session.
+type SAMLUseCase struct {
+	provider     service.SAMLServiceProvider
+	authService  service.AuthService
+	cache        repository.CacheRepository
+	loginTimeout time.Duration
+	logger       logger.Logger
+}
+
+// NewSAMLUseCase creates a new SAMLUseCase instance. Logins in progress are kept in the cache,
+// which must be shared by the gateway instances, and expire after loginTimeout.
+func NewSAMLUseCase(
+	provider service.SAMLServiceProvider,
+	authService service.AuthService,
+	cache repository.CacheRepository,
+	loginTimeout time.Duration,
+	logger logger.Logger,
+) *SAMLUseCase {
+	return &SAMLUseCase{
+		provider:     provider,
+		authService:  authService,
+		cache:        cache,
+		loginTimeout: loginTimeout,
+		logger:       logger,
+	}
+}
+
+// Metadata returns the gateway's service provider metadata
+func (uc *SAMLUseCase) Metadata() ([]byte, error) {
+	return uc.provider.Metadata()
+}
+
+// BeginLogin starts a login and returns the identity provider URL to redirect the user to.
+// Users return to returnTo once logged in; it must be a path on the gateway and defaults to /.
+func (uc *SAMLUseCase) BeginLogin(ctx context.Context, returnTo string) (string, error) {
+	token, err := randomToken()
+	if err != nil {
+		return "", err
+	}
+	// SAML IDs must not start with a digit
+	requestID := "_" + token
+
+	if err := uc.cache.Set(ctx, samlLoginCachePrefix+requestID, samlLogin{ReturnTo: localPath(returnTo)}, uc.loginTimeout); err != nil {
+		return "", err
+	}
+	return uc.provider.AuthnRequestURL(requestID, "")
+}
+
+// CompleteLogin validates the SAML response the identity provider posted back and issues the
+// user's session token. It returns the token and the path to send the user back to. Only
+// responses to pending requests are accepted, each once.
+func (uc *SAMLUseCase) CompleteLogin(ctx context.Context, encodedResponse string) (string, string, error) {
+	if encodedResponse == "" {
+		return "", "", errors.NewError(errors.CodeInvalidInput, "SAMLResponse is required", errors.ErrInvalidInput)
+	}
+
+	requestID, claims, err := uc.provider.ParseResponse(ctx, encodedResponse)
+	if err != nil {
+		logger.FromContextOr(ctx, uc.logger).Warn("SAML response rejected", "error", err)
+		return "", "", errors.NewError(errors.CodeUnauthorized, "login failed", errors.ErrUnauthorized)
+	}
+
+	key := samlLoginCachePrefix + requestID
+	var login samlLogin
+	if err := uc.cache.Get(ctx, key, &login); err != nil {
+		return "", "", errors.NewError(errors.CodeUnauthorized, "login expired or unknown", errors.ErrUnauthorized)
+	}
+	if err := uc.cache.Delete(ctx, key); err != nil {
+		return "", "", err
+	}
+
+	userID, _ := claims["sub"].(string)
+	token, err := uc.authService.GenerateToken(ctx, userID, claims)
+	if err != nil {
+		return "", "", err
+	}
+	logger.FromContextOr(ctx, uc.logger).Info("SAML login completed", logger.FieldUserID, userID)
+	return token, login.ReturnTo, nil
+}
@@ -0,0 +1,118 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"testing"
+	"time"
+
+	"api-gateway-sample/internal/domain/entity"
+	"api-gateway-sample/internal/domain/service"
+	"api-gateway-sample/pkg/errors"
+)
+
+// fakeSAMLProvider sends the request ID in the SSO URL and accepts the responses "good:<request ID>"
+type fakeSAMLProvider struct{}
+
+func (fakeSAMLProvider) Metadata() ([]byte, error) {
+	return []byte("<EntityDescriptor/>"), nil
+}
+
+func (fakeSAMLProvider) AuthnRequestURL(requestID string, relayState string) (string, error) {
+	return "https://idp.example.com/sso?" + url.Values{"id": {requestID}}.Encode(), nil
+}
+
+func (fakeSAMLProvider) ParseResponse(ctx context.Context, encodedResponse string) (string, map[string]interface{}, error) {
+	var requestID string
+	if _, err := fmt.Sscanf(encodedResponse, "good:%s", &requestID); err != nil {
+		return "", nil, fmt.Errorf("invalid signature")
+	}
+	return requestID, map[string]interface{}{"sub": "bob", "roles": []string{"admin"}, "amr": []string{"saml"}}, nil
+}
+
+func TestSAMLUseCase_Login(t *testing.T) {
+	ctx := context.Background()
+	uc := NewSAMLUseCase(fakeSAMLProvider{}, tokenIssuer{}, &jsonCache{entries: map[string][]byte{}}, time.Minute, &MockLogger{})
+
+	// 1. Beginning a login redirects to the provider with a request ID
+	ssoURL, err := uc.BeginLogin(ctx, "https://evil.example.com/")
+	if err != nil {
+		t.Fatalf("BeginLogin failed: %v", err)
+	}
+	parsed, _ := url.Parse(ssoURL)
+	requestID := parsed.Query().Get("id")
+	if len(requestID) < 2 || requestID[0] != '_' {
+		t.Fatalf("Expected a request ID starting with _, got %q", requestID)
+	}
+
+	// 2. Responses to requests the gateway did not send are rejected
+	if _, _, err := uc.CompleteLogin(ctx, "good:_unknown"); !errors.IsUnauthorized(err) {
+		t.Errorf("Expected unauthorized for an unsolicited response, got %v", err)
+	}
+
+	// 3. Invalid responses are rejected
+	if _, _, err := uc.CompleteLogin(ctx, "forged"); !errors.IsUnauthorized(err) {
+		t.Errorf("Expected unauthorized for an invalid response, got %v", err)
+	}
+
+	// 4. A valid response issues a session token; external return paths fall back to /
+	token, returnTo, err := uc.CompleteLogin(ctx, "good:"+requestID)
+	if err != nil {
+		t.Fatalf("CompleteLogin failed: %v", err)
+	}
+	if token != "token-bob" {
+		t.Errorf("Expected token-bob, got %s", token)
+	}
+	if returnTo != "/" {
+		t.Errorf("Expected to return to /, got %s", returnTo)
+	}
+
+	// 5. A response is accepted only once
+	if _, _, err := uc.CompleteLogin(ctx, "good:"+requestID); !errors.IsUnauthorized(err) {
+		t.Errorf("Expected unauthorized for a replayed response, got %v", err)
+	}
+
+	// 6. An empty response is invalid input
+	if _, _, err := uc.CompleteLogin(ctx, ""); !errors.IsInvalidInput(err) {
+		t.Errorf("Expected invalid input for an empty response, got %v", err)
+	}
+}
+
+// allowingAuth authorizes every authenticated request
+type allowingAuth struct {
+	service.AuthService
+}
+
+func (allowingAuth) Authorize(ctx context.Context, request *entity.Request, service *entity.Service, endpoint *entity.Endpoint) error {
+	return nil
+}
+
+func TestProxyUseCase_ServiceAuthMethods(t *testing.T) {
+	hr := newTestService("hr", entity.Endpoint{Path: "/api/v1/payslips", Methods: []string{http.MethodGet}, AuthRequired: true})
+	hr.AuthMethods = []string{entity.AuthMethodSAML}
+	serviceRepo := newServiceRepository(t, hr)
+	gateway := &countingGateway{statuses: []int{http.StatusOK}}
+	useCase := NewProxyUseCase(serviceRepo, gateway, allowingAuth{}, nil, nil, &MockLogger{})
+
+	get := func(method string) error {
+		principal := entity.NewPrincipal(map[string]interface{}{"sub": "alice", "amr": []interface{}{method}})
+		ctx := entity.ContextWithPrincipal(context.Background(), principal)
+		_, err := useCase.ProxyRequest(ctx, entity.NewRequest(http.MethodGet, "/api/v1/payslips", nil, nil, nil, "10.0.0.1"))
+		return err
+	}
+
+	// 1. Principals authenticated with another method are forbidden
+	if err := get(entity.AuthMethodAPIKey); !errors.IsForbidden(err) {
+		t.Errorf("Expected forbidden for an API key, got %v", err)
+	}
+	if gateway.calls != 0 {
+		t.Errorf("Expected no upstream request, got %d", gateway.calls)
+	}
+
+	// 2. SAML sessions are routed
+	if err := get(entity.AuthMethodSAML); err != nil {
+		t.Errorf("Expected a SAML session to be routed, got %v", err)
+	}
+}
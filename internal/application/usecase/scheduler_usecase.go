@@ -0,0 +1,392 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/robfig/cron/v3"
+
+	"api-gateway-sample/internal/application/dto"
+	"api-gateway-sample/internal/domain/entity"
+	"api-gateway-sample/internal/domain/repository"
+	"api-gateway-sample/internal/domain/service"
+	"api-gateway-sample/pkg/errors"
+	"api-gateway-sample/pkg/logger"
+)
+
+// HeaderScheduledJob carries the job ID on the requests of scheduled jobs
+const HeaderScheduledJob = "X-Scheduled-Job"
+
+// schedulerTick is how often the scheduler looks for due jobs; cron schedules have minute precision
+const schedulerTick = time.Second
+
+// scheduledEntry is a job known to the scheduler with its parsed schedule
+type scheduledEntry struct {
+	job      *entity.ScheduledJob
+	schedule cron.Schedule
+	next     time.Time
+	// running is set while a scheduled run is in flight, so slow jobs never overlap
+	running bool
+}
+
+// SchedulerUseCase implements the use case for scheduled jobs, which call gateway routes on a
+// cron schedule and keep the outcome of their recent runs
+type SchedulerUseCase struct {
+	jobRepo     repository.ScheduledJobRepository
+	proxy       *ProxyUseCase
+	timeout     time.Duration
+	historySize int
+	logger      logger.Logger
+
+	// elector restricts scheduled runs to the leading instance, nil runs them on every instance
+	elector service.LeaderElector
+
+	mu      sync.Mutex
+	entries map[string]*scheduledEntry
+	// runs holds the recent runs of each job, newest first
+	runs map[string][]entity.JobRun
+}
+
+// NewSchedulerUseCase creates a new SchedulerUseCase instance. Runs of jobs without a timeout
+// are limited to the given timeout, and the given number of recent runs is kept per job.
+func NewSchedulerUseCase(
+	jobRepo repository.ScheduledJobRepository,
+	proxy *ProxyUseCase,
+	timeout time.Duration,
+	historySize int,
+	logger logger.Logger,
+) *SchedulerUseCase {
+	return &SchedulerUseCase{
+		jobRepo:     jobRepo,
+		proxy:       proxy,
+		timeout:     timeout,
+		historySize: historySize,
+		logger:      logger,
+		entries:     make(map[string]*scheduledEntry),
+		runs:        make(map[string][]entity.JobRun),
+	}
+}
+
+// SetLeaderElector runs the jobs on schedule only while this instance leads. Manual runs are
+// not affected.
+func (uc *SchedulerUseCase) SetLeaderElector(elector service.LeaderElector) {
+	uc.elector = elector
+}
+
+// Start loads the jobs and runs them when due until the context is cancelled. The jobs are
+// reloaded from the repository at the given interval to pick up changes made elsewhere.
+func (uc *SchedulerUseCase) Start(ctx context.Context, reloadInterval time.Duration) {
+	uc.reload(ctx)
+
+	go func() {
+		tick := time.NewTicker(schedulerTick)
+		defer tick.Stop()
+		reload := time.NewTicker(reloadInterval)
+		defer reload.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case now := <-tick.C:
+				uc.runDue(ctx, now)
+			case <-reload.C:
+				uc.reload(ctx)
+			}
+		}
+	}()
+}
+
+// CreateJob creates a scheduled job
+func (uc *SchedulerUseCase) CreateJob(ctx context.Context, req *dto.ScheduledJobRequest) (*dto.ScheduledJobResponse, error) {
+	job := req.ToEntity()
+	if err := checkScheduledJob(job); err != nil {
+		return nil, err
+	}
+
+	job.ID = entity.NewRequestID()
+	job.CreatedAt = time.Now()
+	job.UpdatedAt = job.CreatedAt
+	if err := uc.jobRepo.Create(ctx, job); err != nil {
+		return nil, err
+	}
+
+	uc.schedule(job)
+	return uc.toResponse(job), nil
+}
+
+// UpdateJob replaces the configuration of a scheduled job
+func (uc *SchedulerUseCase) UpdateJob(ctx context.Context, id string, req *dto.ScheduledJobRequest) (*dto.ScheduledJobResponse, error) {
+	existing, err := uc.jobRepo.Get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	job := req.ToEntity()
+	if err := checkScheduledJob(job); err != nil {
+		return nil, err
+	}
+
+	job.ID = existing.ID
+	job.CreatedAt = existing.CreatedAt
+	job.UpdatedAt = time.Now()
+	if err := uc.jobRepo.Update(ctx, job); err != nil {
+		return nil, err
+	}
+
+	uc.schedule(job)
+	return uc.toResponse(job), nil
+}
+
+// GetJob retrieves a scheduled job by ID
+func (uc *SchedulerUseCase) GetJob(ctx context.Context, id string) (*dto.ScheduledJobResponse, error) {
+	job, err := uc.jobRepo.Get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	return uc.toResponse(job), nil
+}
+
+// ListJobs retrieves all scheduled jobs
+func (uc *SchedulerUseCase) ListJobs(ctx context.Context) ([]*dto.ScheduledJobResponse, error) {
+	jobs, err := uc.jobRepo.GetAll(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	responses := make([]*dto.ScheduledJobResponse, len(jobs))
+	for i, job := range jobs {
+		responses[i] = uc.toResponse(job)
+	}
+	return responses, nil
+}
+
+// DeleteJob deletes a scheduled job by ID
+func (uc *SchedulerUseCase) DeleteJob(ctx context.Context, id string) error {
+	if err := uc.jobRepo.Delete(ctx, id); err != nil {
+		return err
+	}
+
+	uc.mu.Lock()
+	delete(uc.entries, id)
+	delete(uc.runs, id)
+	uc.mu.Unlock()
+	return nil
+}
+
+// ListRuns retrieves the recent runs of a scheduled job, newest first
+func (uc *SchedulerUseCase) ListRuns(ctx context.Context, id string) ([]entity.JobRun, error) {
+	if _, err := uc.jobRepo.Get(ctx, id); err != nil {
+		return nil, err
+	}
+
+	uc.mu.Lock()
+	defer uc.mu.Unlock()
+	return append(make([]entity.JobRun, 0, len(uc.runs[id])), uc.runs[id]...), nil
+}
+
+// RunJob runs a scheduled job immediately, even when it is disabled, and returns the outcome
+func (uc *SchedulerUseCase) RunJob(ctx context.Context, id string) (*entity.JobRun, error) {
+	job, err := uc.jobRepo.Get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	run := uc.run(ctx, job, entity.JobTriggerManual)
+	return &run, nil
+}
+
+// runDue starts the enabled jobs whose next run is due and schedules their following run
+func (uc *SchedulerUseCase) runDue(ctx context.Context, now time.Time) {
+	leading := uc.elector == nil || uc.elector.IsLeader()
+
+	uc.mu.Lock()
+	defer uc.mu.Unlock()
+
+	for _, entry := range uc.entries {
+		if now.Before(entry.next) {
+			continue
+		}
+		// Disabled jobs and followers keep the next run current, so enabling a job or taking
+		// over the leadership does not run the missed runs at once
+		entry.next = entry.schedule.Next(now)
+		if !entry.job.Enabled || !leading {
+			continue
+		}
+		if entry.running {
+			uc.logger.Warn("Skipping scheduled job, previous run still in progress", "job_id", entry.job.ID, "job", entry.job.Name)
+			continue
+		}
+
+		entry.running = true
+		go func(job *entity.ScheduledJob) {
+			uc.run(ctx, job, entity.JobTriggerSchedule)
+
+			uc.mu.Lock()
+			if entry, ok := uc.entries[job.ID]; ok {
+				entry.running = false
+			}
+			uc.mu.Unlock()
+		}(entry.job)
+	}
+}
+
+// run calls the route of a job and records the outcome
+func (uc *SchedulerUseCase) run(ctx context.Context, job *entity.ScheduledJob, trigger string) entity.JobRun {
+	requestID := entity.NewRequestID()
+	log := logger.With(uc.logger, logger.FieldRequestID, requestID, "job_id", job.ID, "job", job.Name)
+
+	timeout := job.TimeoutDuration()
+	if timeout == 0 {
+		timeout = uc.timeout
+	}
+	runCtx, cancel := context.WithTimeout(logger.NewContext(ctx, log), timeout)
+	defer cancel()
+
+	// The path was checked when the job was saved
+	path, rawQuery, _ := strings.Cut(job.Path, "?")
+	query, _ := url.ParseQuery(rawQuery)
+	headers := http.Header{}
+	for name, value := range job.Headers {
+		headers.Set(name, value)
+	}
+	headers.Set(HeaderScheduledJob, job.ID)
+
+	start := time.Now()
+	request := &entity.Request{
+		ID:          requestID,
+		Method:      job.Method,
+		Path:        path,
+		Headers:     headers,
+		QueryParams: query,
+		Body:        []byte(job.Body),
+		Timestamp:   start,
+		Timeout:     timeout,
+	}
+	response, err := uc.proxy.Dispatch(runCtx, request)
+
+	run := entity.JobRun{
+		JobID:     job.ID,
+		Trigger:   trigger,
+		StartedAt: start,
+		Duration:  time.Since(start).Milliseconds(),
+	}
+	switch {
+	case err != nil:
+		run.Error = err.Error()
+		log.Warn("Scheduled job failed", "trigger", trigger, "duration_ms", run.Duration, "error", err)
+	case response.StatusCode >= http.StatusBadRequest:
+		run.StatusCode = response.StatusCode
+		run.Error = fmt.Sprintf("route returned status %d", response.StatusCode)
+		log.Warn("Scheduled job failed", "trigger", trigger, "status", response.StatusCode, "duration_ms", run.Duration)
+	default:
+		run.StatusCode = response.StatusCode
+		run.Success = true
+		log.Info("Scheduled job completed", "trigger", trigger, "status", response.StatusCode, "duration_ms", run.Duration)
+	}
+
+	uc.record(run)
+	return run
+}
+
+// record keeps a run in the history of its job, dropping the oldest beyond the history size
+func (uc *SchedulerUseCase) record(run entity.JobRun) {
+	uc.mu.Lock()
+	defer uc.mu.Unlock()
+
+	if _, ok := uc.entries[run.JobID]; !ok {
+		// The job was deleted while it ran
+		return
+	}
+	runs := append([]entity.JobRun{run}, uc.runs[run.JobID]...)
+	if len(runs) > uc.historySize {
+		runs = runs[:uc.historySize]
+	}
+	uc.runs[run.JobID] = runs
+}
+
+// reload replaces the scheduled jobs with those in the repository
+func (uc *SchedulerUseCase) reload(ctx context.Context) {
+	jobs, err := uc.jobRepo.GetAll(ctx)
+	if err != nil {
+		uc.logger.Error("Failed to load scheduled jobs", "error", err)
+		return
+	}
+
+	seen := make(map[string]bool, len(jobs))
+	for _, job := range jobs {
+		seen[job.ID] = true
+		uc.schedule(job)
+	}
+
+	uc.mu.Lock()
+	defer uc.mu.Unlock()
+	for id := range uc.entries {
+		if !seen[id] {
+			delete(uc.entries, id)
+			delete(uc.runs, id)
+		}
+	}
+}
+
+// schedule adds or replaces a job, keeping its next run while its schedule is unchanged
+func (uc *SchedulerUseCase) schedule(job *entity.ScheduledJob) {
+	schedule, err := cron.ParseStandard(job.Schedule)
+	if err != nil {
+		uc.logger.Error("Invalid scheduled job", "job_id", job.ID, "schedule", job.Schedule, "error", err)
+		return
+	}
+
+	uc.mu.Lock()
+	defer uc.mu.Unlock()
+
+	entry := &scheduledEntry{job: job, schedule: schedule}
+	if existing, ok := uc.entries[job.ID]; ok {
+		entry.running = existing.running
+		if existing.job.Schedule == job.Schedule {
+			entry.next = existing.next
+		}
+	}
+	if entry.next.IsZero() {
+		entry.next = schedule.Next(time.Now())
+	}
+	uc.entries[job.ID] = entry
+}
+
+// toResponse converts a job to its response with its next and last run on this instance
+func (uc *SchedulerUseCase) toResponse(job *entity.ScheduledJob) *dto.ScheduledJobResponse {
+	response := dto.FromScheduledJobEntity(job)
+
+	uc.mu.Lock()
+	defer uc.mu.Unlock()
+	if entry, ok := uc.entries[job.ID]; ok && job.Enabled {
+		next := entry.next
+		response.NextRun = &next
+	}
+	if runs := uc.runs[job.ID]; len(runs) > 0 {
+		last := runs[0]
+		response.LastRun = &last
+	}
+	return response
+}
+
+// checkScheduledJob validates a job and its cron schedule
+func checkScheduledJob(job *entity.ScheduledJob) error {
+	if err := job.Validate(); err != nil {
+		return errors.NewError(errors.CodeInvalidInput, err.Error(), errors.ErrInvalidInput)
+	}
+	if _, err := cron.ParseStandard(job.Schedule); err != nil {
+		return errors.NewError(errors.CodeInvalidInput, fmt.Sprintf("invalid schedule: %v", err), errors.ErrInvalidInput)
+	}
+	if _, rawQuery, ok := strings.Cut(job.Path, "?"); ok {
+		if _, err := url.ParseQuery(rawQuery); err != nil {
+			return errors.NewError(errors.CodeInvalidInput, fmt.Sprintf("invalid query in path: %v", err), errors.ErrInvalidInput)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,215 @@
+package usecase
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"api-gateway-sample/internal/application/dto"
+	"api-gateway-sample/internal/domain/entity"
+	"api-gateway-sample/internal/domain/repository/mock"
+	"api-gateway-sample/pkg/errors"
+)
+
+// capturingGateway records the requests routed to the backend
+type capturingGateway struct {
+	countingGateway
+	requests []*entity.Request
+}
+
+func (g *capturingGateway) RouteRequest(ctx context.Context, request *entity.Request) (*entity.Response, error) {
+	g.requests = append(g.requests, request)
+	return g.countingGateway.RouteRequest(ctx, request)
+}
+
+// newSchedulerFixture creates a scheduler calling a protected report endpoint that keeps two runs per job
+func newSchedulerFixture(t *testing.T, statuses ...int) (*SchedulerUseCase, *capturingGateway) {
+	t.Helper()
+	reports := newTestService("reports", entity.Endpoint{Path: "/api/v1/reports", Methods: []string{http.MethodPost}, AuthRequired: true, RateLimit: 1})
+
+	// Without auth and rate limit services, a run fails if client checks apply to it
+	gateway := &capturingGateway{countingGateway: countingGateway{statuses: statuses}}
+	proxy := newProxyFixture(t, gateway, reports)
+	return NewSchedulerUseCase(mock.NewScheduledJobRepositoryMock(), proxy, time.Minute, 2, &MockLogger{}), gateway
+}
+
+func newReportJobRequest() *dto.ScheduledJobRequest {
+	return &dto.ScheduledJobRequest{
+		Name:     "nightly-report",
+		Schedule: "0 2 * * *",
+		Method:   http.MethodPost,
+		Path:     "/api/v1/reports?type=daily",
+		Headers:  map[string]string{"content-type": "application/json"},
+		Body:     `{"format":"pdf"}`,
+	}
+}
+
+func TestSchedulerUseCase_CreateJob(t *testing.T) {
+	ctx := context.Background()
+	useCase, _ := newSchedulerFixture(t)
+
+	job, err := useCase.CreateJob(ctx, newReportJobRequest())
+	if err != nil {
+		t.Fatalf("Failed to create job: %v", err)
+	}
+	if !job.Enabled || job.NextRun == nil || job.NextRun.Hour() != 2 || job.NextRun.Minute() != 0 {
+		t.Errorf("Expected an enabled job due at 02:00, got %+v", job)
+	}
+
+	invalid := []*dto.ScheduledJobRequest{
+		{Name: "report", Schedule: "every night", Path: "/api/v1/reports"},
+		{Name: "report", Schedule: "0 2 * * * *", Path: "/api/v1/reports"},
+		{Name: "report", Schedule: "@daily", Method: "FETCH", Path: "/api/v1/reports"},
+		{Name: "report", Schedule: "@daily", Path: "api/v1/reports"},
+		{Name: "report", Schedule: "@daily", Path: "/api/v1/reports?type=%zz"},
+	}
+	for _, req := range invalid {
+		if _, err := useCase.CreateJob(ctx, req); !errors.IsInvalidInput(err) {
+			t.Errorf("Expected invalid input for %+v, got %v", req, err)
+		}
+	}
+}
+
+func TestSchedulerUseCase_RunJob(t *testing.T) {
+	ctx := context.Background()
+	useCase, gateway := newSchedulerFixture(t, http.StatusOK, http.StatusInternalServerError, http.StatusAccepted)
+
+	job, err := useCase.CreateJob(ctx, newReportJobRequest())
+	if err != nil {
+		t.Fatalf("Failed to create job: %v", err)
+	}
+
+	// 1. The job calls its route without client checks, with its headers, query and body
+	run, err := useCase.RunJob(ctx, job.ID)
+	if err != nil {
+		t.Fatalf("Failed to run job: %v", err)
+	}
+	if !run.Success || run.StatusCode != http.StatusOK || run.Trigger != entity.JobTriggerManual {
+		t.Fatalf("Expected a successful manual run, got %+v", run)
+	}
+	request := gateway.requests[0]
+	headers := http.Header(request.Headers)
+	if request.Path != "/api/v1/reports" || request.QueryParams["type"][0] != "daily" || string(request.Body) != `{"format":"pdf"}` {
+		t.Errorf("Unexpected request %+v", request)
+	}
+	if headers.Get(HeaderScheduledJob) != job.ID || headers.Get("Content-Type") != "application/json" {
+		t.Errorf("Unexpected request headers %v", request.Headers)
+	}
+
+	// 2. A failing route fails the run
+	run, err = useCase.RunJob(ctx, job.ID)
+	if err != nil {
+		t.Fatalf("Failed to run job: %v", err)
+	}
+	if run.Success || run.StatusCode != http.StatusInternalServerError || run.Error == "" {
+		t.Errorf("Expected a failed run, got %+v", run)
+	}
+
+	// 3. Only the most recent runs are kept, newest first
+	if _, err := useCase.RunJob(ctx, job.ID); err != nil {
+		t.Fatalf("Failed to run job: %v", err)
+	}
+	runs, err := useCase.ListRuns(ctx, job.ID)
+	if err != nil {
+		t.Fatalf("Failed to list runs: %v", err)
+	}
+	if len(runs) != 2 || runs[0].StatusCode != http.StatusAccepted || runs[1].StatusCode != http.StatusInternalServerError {
+		t.Errorf("Unexpected runs %+v", runs)
+	}
+
+	stored, err := useCase.GetJob(ctx, job.ID)
+	if err != nil {
+		t.Fatalf("Failed to get job: %v", err)
+	}
+	if stored.LastRun == nil || stored.LastRun.StatusCode != http.StatusAccepted {
+		t.Errorf("Expected the last run on the job, got %+v", stored.LastRun)
+	}
+
+	if _, err := useCase.RunJob(ctx, "missing"); !errors.IsNotFound(err) {
+		t.Errorf("Expected not found for an unknown job, got %v", err)
+	}
+}
+
+func TestSchedulerUseCase_RunDue(t *testing.T) {
+	ctx := context.Background()
+	useCase, _ := newSchedulerFixture(t, http.StatusOK)
+
+	enabled, err := useCase.CreateJob(ctx, newReportJobRequest())
+	if err != nil {
+		t.Fatalf("Failed to create job: %v", err)
+	}
+	paused := newReportJobRequest()
+	paused.Enabled = new(bool)
+	disabled, err := useCase.CreateJob(ctx, paused)
+	if err != nil {
+		t.Fatalf("Failed to create job: %v", err)
+	}
+
+	// 1. Only the enabled job runs once it is due
+	useCase.runDue(ctx, time.Now().Add(24*time.Hour))
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		runs, _ := useCase.ListRuns(ctx, enabled.ID)
+		if len(runs) == 1 && runs[0].Trigger == entity.JobTriggerSchedule && runs[0].Success {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("Expected a scheduled run, got %+v", runs)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	if runs, _ := useCase.ListRuns(ctx, disabled.ID); len(runs) != 0 {
+		t.Errorf("Expected the disabled job not to run, got %+v", runs)
+	}
+
+	// 2. Both jobs are scheduled for their following run
+	for _, id := range []string{enabled.ID, disabled.ID} {
+		useCase.mu.Lock()
+		next := useCase.entries[id].next
+		useCase.mu.Unlock()
+		if !next.After(time.Now().Add(24 * time.Hour)) {
+			t.Errorf("Expected job %s to be scheduled after the run, got %s", id, next)
+		}
+	}
+
+	// 3. Deleting a job removes it from the schedule
+	if err := useCase.DeleteJob(ctx, enabled.ID); err != nil {
+		t.Fatalf("Failed to delete job: %v", err)
+	}
+	if _, err := useCase.ListRuns(ctx, enabled.ID); !errors.IsNotFound(err) {
+		t.Errorf("Expected not found for a deleted job, got %v", err)
+	}
+}
+
+// staticElector is a leader elector with a fixed outcome
+type staticElector bool
+
+func (e staticElector) IsLeader() bool {
+	return bool(e)
+}
+
+func TestSchedulerUseCase_RunDueOnlyOnLeader(t *testing.T) {
+	ctx := context.Background()
+	useCase, gateway := newSchedulerFixture(t, http.StatusOK)
+	useCase.SetLeaderElector(staticElector(false))
+
+	job, err := useCase.CreateJob(ctx, newReportJobRequest())
+	if err != nil {
+		t.Fatalf("Failed to create job: %v", err)
+	}
+
+	// A follower skips the due run but still schedules the following one
+	useCase.runDue(ctx, time.Now().Add(24*time.Hour))
+	time.Sleep(50 * time.Millisecond)
+	if runs, _ := useCase.ListRuns(ctx, job.ID); len(runs) != 0 || len(gateway.requests) != 0 {
+		t.Errorf("Expected a follower not to run the job, got %+v", runs)
+	}
+
+	useCase.mu.Lock()
+	next := useCase.entries[job.ID].next
+	useCase.mu.Unlock()
+	if !next.After(time.Now().Add(24 * time.Hour)) {
+		t.Errorf("Expected the job to be scheduled after the skipped run, got %s", next)
+	}
+}
@@ -0,0 +1,40 @@
+package usecase
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"api-gateway-sample/internal/domain/entity"
+	"api-gateway-sample/pkg/errors"
+)
+
+func TestProxyUseCase_RequiredScopes(t *testing.T) {
+	orders := newTestService("orders", entity.Endpoint{Path: "/api/v1/orders", Methods: []string{http.MethodPost}, AuthRequired: true, RequiredScopes: []string{"orders:write"}})
+	serviceRepo := newServiceRepository(t, orders)
+	gateway := &countingGateway{statuses: []int{http.StatusCreated, http.StatusCreated}}
+	useCase := NewProxyUseCase(serviceRepo, gateway, allowingAuth{}, nil, nil, &MockLogger{})
+
+	post := func(scope string) error {
+		principal := entity.NewPrincipal(map[string]interface{}{"sub": "partner", "scope": scope})
+		ctx := entity.ContextWithPrincipal(context.Background(), principal)
+		_, err := useCase.ProxyRequest(ctx, entity.NewRequest(http.MethodPost, "/api/v1/orders", nil, nil, nil, "10.0.0.1"))
+		return err
+	}
+
+	// 1. Callers without the scope are forbidden before reaching the service
+	if err := post("orders:read"); !errors.IsForbidden(err) {
+		t.Errorf("Expected forbidden without orders:write, got %v", err)
+	}
+	if gateway.calls != 0 {
+		t.Errorf("Expected no upstream request, got %d", gateway.calls)
+	}
+
+	// 2. The scope itself or a wildcard over it is accepted
+	if err := post("orders:write"); err != nil {
+		t.Errorf("Expected orders:write to be accepted, got %v", err)
+	}
+	if err := post("profile orders:*"); err != nil {
+		t.Errorf("Expected orders:* to be accepted, got %v", err)
+	}
+}
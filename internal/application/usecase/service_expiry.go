@@ -0,0 +1,102 @@
+package usecase
+
+import (
+	"context"
+	"time"
+
+	"api-gateway-sample/internal/domain/entity"
+	"api-gateway-sample/pkg/errors"
+	"api-gateway-sample/pkg/logger"
+)
+
+// ArchiveExpired removes the services and endpoints whose validity window ended by now. Expired
+// endpoints are removed with a recorded update, and services that expired or have no endpoint
+// left are deleted; their revision history keeps their definitions. It returns the number of
+// services changed.
+func (uc *ServiceUseCase) ArchiveExpired(ctx context.Context, now time.Time) (int, error) {
+	services, err := uc.serviceRepo.GetAll(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	log := logger.FromContextOr(ctx, uc.logger)
+	archived := 0
+	for _, previous := range services {
+		service, expired := expiredEndpoints(previous, now)
+		if previous.ExpiredAt(now) || service != nil && len(service.Endpoints) == 0 {
+			// The stored revision makes the deletion fail if the service changes meanwhile
+			if err := uc.serviceRepo.Delete(ctx, previous.ID, previous.Revision); err != nil {
+				if !errors.IsNotFound(err) {
+					log.Error("Failed to archive expired service", "service_id", previous.ID, "error", err)
+				}
+				continue
+			}
+			uc.publish(ctx, entity.EventServiceDeleted, previous.ID, nil, previous)
+			log.Info("Archived expired service", "service_id", previous.ID, "name", previous.Name, "revision", previous.Revision)
+			archived++
+			continue
+		}
+		if service == nil {
+			continue
+		}
+
+		// The stored revision makes the update fail if the service changes meanwhile
+		if err := uc.serviceRepo.Update(ctx, service); err != nil {
+			log.Error("Failed to archive expired endpoints", "service_id", previous.ID, "error", err)
+			continue
+		}
+		uc.record(ctx, service, previous, 0)
+		uc.publish(ctx, entity.EventServiceUpdated, service.ID, service, previous)
+		log.Info("Archived expired endpoints", "service_id", service.ID, "name", service.Name, "endpoints", expired)
+		archived++
+	}
+	return archived, nil
+}
+
+// StartExpiryCleaner archives expired services and endpoints on the given interval until the
+// context is cancelled
+func (uc *ServiceUseCase) StartExpiryCleaner(ctx context.Context, interval time.Duration) {
+	if interval <= 0 {
+		return
+	}
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case now := <-ticker.C:
+				if _, err := uc.ArchiveExpired(ctx, now); err != nil {
+					logger.FromContextOr(ctx, uc.logger).Error("Failed to archive expired services", "error", err)
+				}
+			}
+		}
+	}()
+}
+
+// expiredEndpoints returns a copy of the service without its endpoints expired at now, and the
+// paths of those endpoints. It returns nil when no endpoint expired.
+func expiredEndpoints(service *entity.Service, now time.Time) (*entity.Service, []string) {
+	var expired []string
+	for i := range service.Endpoints {
+		if service.Endpoints[i].ExpiredAt(now) {
+			expired = append(expired, service.Endpoints[i].Path)
+		}
+	}
+	if len(expired) == 0 {
+		return nil, nil
+	}
+
+	updated := service.Clone()
+	kept := updated.Endpoints[:0]
+	for _, endpoint := range updated.Endpoints {
+		if !endpoint.ExpiredAt(now) {
+			kept = append(kept, endpoint)
+		}
+	}
+	updated.Endpoints = kept
+	return updated, expired
+}
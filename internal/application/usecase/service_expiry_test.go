@@ -0,0 +1,73 @@
+package usecase
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"api-gateway-sample/internal/domain/entity"
+	"api-gateway-sample/internal/domain/repository/mock"
+	"api-gateway-sample/pkg/errors"
+	"api-gateway-sample/pkg/logger"
+)
+
+func TestServiceUseCase_ArchiveExpired(t *testing.T) {
+	ctx := context.Background()
+	now := time.Now()
+	past, future := now.Add(-time.Hour), now.Add(time.Hour)
+	repo := mock.NewServiceRepositoryMock()
+	revisions := mock.NewServiceRevisionRepositoryMock()
+
+	// 1. A service with one expired endpoint, an expired service, a service whose only endpoint
+	// expired and a service still served
+	users := entity.NewService("users-id", "users", "1.0.0", "", "http://users", 30, 3)
+	users.AddEndpoint(entity.Endpoint{Path: "/api/v1/users", Methods: []string{"GET"}})
+	users.AddEndpoint(entity.Endpoint{Path: "/api/v0/users", Methods: []string{"GET"}, ValidUntil: &past})
+	campaign := entity.NewService("campaign-id", "campaign", "1.0.0", "", "http://campaign", 30, 3)
+	campaign.ValidUntil = &past
+	campaign.AddEndpoint(entity.Endpoint{Path: "/api/v1/campaign", Methods: []string{"GET"}})
+	legacy := entity.NewService("legacy-id", "legacy", "1.0.0", "", "http://legacy", 30, 3)
+	legacy.AddEndpoint(entity.Endpoint{Path: "/api/v1/legacy", Methods: []string{"GET"}, ValidUntil: &past})
+	promo := entity.NewService("promo-id", "promo", "1.0.0", "", "http://promo", 30, 3)
+	promo.ValidUntil = &future
+	promo.AddEndpoint(entity.Endpoint{Path: "/api/v1/promo", Methods: []string{"GET"}})
+	for _, service := range []*entity.Service{users, campaign, legacy, promo} {
+		if err := repo.Create(ctx, service); err != nil {
+			t.Fatalf("Failed to create service: %v", err)
+		}
+	}
+	useCase := NewServiceUseCase(repo, nil, nil)
+	useCase.SetRevisionHistory(revisions, logger.Default())
+
+	// 2. Archive what expired
+	archived, err := useCase.ArchiveExpired(ctx, now)
+	if err != nil {
+		t.Fatalf("Failed to archive expired services: %v", err)
+	}
+	if archived != 3 {
+		t.Errorf("Expected 3 services archived, got %d", archived)
+	}
+
+	// 3. The expired endpoint is removed with a recorded revision
+	service, err := repo.Get(ctx, "users-id")
+	if err != nil {
+		t.Fatalf("Failed to get users: %v", err)
+	}
+	if len(service.Endpoints) != 1 || service.Endpoints[0].Path != "/api/v1/users" {
+		t.Errorf("Expected only /api/v1/users to be left, got %+v", service.Endpoints)
+	}
+	history, err := revisions.List(ctx, "users-id")
+	if err != nil || len(history) != 1 {
+		t.Fatalf("Expected the archive to be recorded, got %v %v", history, err)
+	}
+
+	// 4. Expired services and those left without endpoints are deleted, the others kept
+	for _, id := range []string{"campaign-id", "legacy-id"} {
+		if _, err := repo.Get(ctx, id); !errors.IsNotFound(err) {
+			t.Errorf("Expected %s to be deleted, got %v", id, err)
+		}
+	}
+	if _, err := repo.Get(ctx, "promo-id"); err != nil {
+		t.Errorf("Expected promo to be kept, got %v", err)
+	}
+}
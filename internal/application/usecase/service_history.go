@@ -0,0 +1,168 @@
+package usecase
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"time"
+
+	"api-gateway-sample/internal/application/dto"
+	"api-gateway-sample/internal/domain/entity"
+	"api-gateway-sample/internal/domain/repository"
+	"api-gateway-sample/pkg/errors"
+	"api-gateway-sample/pkg/logger"
+)
+
+// SetRevisionHistory stores a revision of a service on every change made through the use case,
+// so that changes can be reviewed and rolled back. Failures to store one are logged and do not
+// fail the change.
+func (uc *ServiceUseCase) SetRevisionHistory(revisions repository.ServiceRevisionRepository, log logger.Logger) {
+	uc.revisions = revisions
+	uc.logger = log
+}
+
+// ListRevisions retrieves the stored revisions of a service, newest first
+func (uc *ServiceUseCase) ListRevisions(ctx context.Context, id string) ([]*dto.ServiceRevisionResponse, error) {
+	if uc.revisions == nil {
+		return nil, errors.NewError(errors.CodeNotFound, "revision history is disabled", errors.ErrNotFound)
+	}
+	revisions, err := uc.revisions.List(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	// A service changed before the history was enabled has no revisions yet
+	if len(revisions) == 0 {
+		if _, err := uc.serviceRepo.Get(ctx, id); err != nil {
+			return nil, err
+		}
+	}
+
+	responses := make([]*dto.ServiceRevisionResponse, len(revisions))
+	for i, revision := range revisions {
+		responses[i] = dto.FromServiceRevisionEntity(revision)
+	}
+	return responses, nil
+}
+
+// RollbackService restores the configuration of a service as of a stored revision. The rollback
+// is itself a new revision. A non-zero expected revision must match the stored one.
+func (uc *ServiceUseCase) RollbackService(ctx context.Context, id string, revision int64, expected int64) (*dto.ServiceResponse, error) {
+	if uc.revisions == nil {
+		return nil, errors.NewError(errors.CodeNotFound, "revision history is disabled", errors.ErrNotFound)
+	}
+	target, err := uc.revisions.Get(ctx, id, revision)
+	if err != nil {
+		return nil, err
+	}
+	service, err := uc.serviceRepo.Get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if expected > 0 && expected != service.Revision {
+		return nil, errors.ErrPreconditionFailed
+	}
+	previous := service.Clone()
+
+	// The name may have been taken by another service since the revision
+	if target.Service.Name != service.Name {
+		if existing, err := uc.serviceRepo.FindByName(ctx, target.Service.Name); err == nil && existing.ID != id {
+			return nil, errors.ErrAlreadyExists
+		} else if err != nil && !errors.IsNotFound(err) {
+			return nil, err
+		}
+	}
+
+	service.Name = target.Service.Name
+	applyDefinition(service, target.Service.Clone())
+	// Routes may have been taken by other services since the revision
+	conflicts, err := uc.checkRoutes(ctx, service, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := uc.serviceRepo.Update(ctx, service); err != nil {
+		return nil, err
+	}
+	uc.record(ctx, service, previous, revision)
+	uc.publish(ctx, entity.EventServiceUpdated, service.ID, service, previous)
+
+	response := dto.FromEntity(service)
+	response.Conflicts = conflicts
+	return response, nil
+}
+
+// record stores the revision a change produced, with the fields changed from the previous one
+func (uc *ServiceUseCase) record(ctx context.Context, current *entity.Service, previous *entity.Service, restoredFrom int64) {
+	if uc.revisions == nil {
+		return
+	}
+
+	revision := &entity.ServiceRevision{
+		ServiceID:    current.ID,
+		Revision:     current.Revision,
+		CreatedAt:    time.Now(),
+		RestoredFrom: restoredFrom,
+		Service:      current.Clone(),
+	}
+	if principal, ok := entity.PrincipalFromContext(ctx); ok {
+		revision.Actor = principal.UserID
+	}
+	if previous != nil {
+		revision.Changes = serviceChanges(previous, current)
+	}
+	if err := uc.revisions.Create(ctx, revision); err != nil {
+		logger.FromContextOr(ctx, uc.logger).Error("Failed to store service revision", "service_id", current.ID, "revision", current.Revision, "error", err)
+	}
+}
+
+// applyDefinition copies the routing definition of a service onto another, keeping its identity
+// and the settings the definition does not carry, such as metadata
+func applyDefinition(service *entity.Service, definition *entity.Service) {
+	service.BaseURL = definition.BaseURL
+	service.Published = definition.Published
+	service.Endpoints = definition.Endpoints
+	service.Signing = definition.Signing
+	service.ErrorTemplates = definition.ErrorTemplates
+	service.Residency = definition.Residency
+	service.Tags = definition.Tags
+	service.Owner = definition.Owner
+	service.AuthMethods = definition.AuthMethods
+	service.Network = definition.Network
+	service.UpstreamPath = definition.UpstreamPath
+	service.ValidFrom = definition.ValidFrom
+	service.ValidUntil = definition.ValidUntil
+}
+
+// serviceChanges returns the fields of a service definition that differ between two services.
+// Both are compared in their API form, so that unset and empty values are equal.
+func serviceChanges(before *entity.Service, after *entity.Service) []entity.FieldChange {
+	from := dto.FromEntity(before)
+	to := dto.FromEntity(after)
+
+	var changes []entity.FieldChange
+	for _, field := range []struct {
+		name     string
+		from, to interface{}
+	}{
+		{"name", from.Name, to.Name},
+		{"baseUrl", from.BaseURL, to.BaseURL},
+		{"published", from.Published, to.Published},
+		{"endpoints", from.Endpoints, to.Endpoints},
+		{"signing", from.Signing, to.Signing},
+		{"errorTemplates", from.ErrorTemplates, to.ErrorTemplates},
+		{"residency", from.Residency, to.Residency},
+		{"tags", from.Tags, to.Tags},
+		{"owner", from.Owner, to.Owner},
+		{"authMethods", from.AuthMethods, to.AuthMethods},
+		{"network", from.Network, to.Network},
+		{"upstreamPath", from.UpstreamPath, to.UpstreamPath},
+		{"validFrom", from.ValidFrom, to.ValidFrom},
+		{"validUntil", from.ValidUntil, to.ValidUntil},
+	} {
+		fromJSON, _ := json.Marshal(field.from)
+		toJSON, _ := json.Marshal(field.to)
+		if !bytes.Equal(fromJSON, toJSON) {
+			changes = append(changes, entity.FieldChange{Field: field.name, From: fromJSON, To: toJSON})
+		}
+	}
+	return changes
+}
@@ -0,0 +1,78 @@
+package usecase
+
+import (
+	"context"
+	"testing"
+
+	"api-gateway-sample/internal/application/dto"
+	"api-gateway-sample/internal/domain/entity"
+	"api-gateway-sample/internal/domain/repository/mock"
+	"api-gateway-sample/pkg/errors"
+	"api-gateway-sample/pkg/logger"
+)
+
+func TestServiceUseCase_RollbackService(t *testing.T) {
+	ctx := entity.ContextWithPrincipal(context.Background(), &entity.Principal{UserID: "alice"})
+	bus := &syncBus{}
+	useCase := NewServiceUseCase(mock.NewServiceRepositoryMock(), nil, bus)
+	useCase.SetRevisionHistory(mock.NewServiceRevisionRepositoryMock(), logger.Default())
+
+	// 1. Creating and updating a service stores a revision for each change
+	created := desiredService("orders", "http://orders")
+	service, err := useCase.CreateService(ctx, &created)
+	if err != nil {
+		t.Fatalf("Failed to create service: %v", err)
+	}
+	update := &dto.UpdateServiceRequest{Name: "orders", BaseURL: "http://orders-broken", Endpoints: created.Endpoints}
+	if _, err := useCase.UpdateService(ctx, service.ID, update); err != nil {
+		t.Fatalf("Failed to update service: %v", err)
+	}
+
+	revisions, err := useCase.ListRevisions(ctx, service.ID)
+	if err != nil {
+		t.Fatalf("Failed to list revisions: %v", err)
+	}
+	if len(revisions) != 2 || revisions[0].Revision != 2 || revisions[1].Revision != 1 {
+		t.Fatalf("Expected revisions 2 and 1, got %+v", revisions)
+	}
+	if revisions[0].Actor != "alice" {
+		t.Errorf("Expected the revision to record the actor, got %q", revisions[0].Actor)
+	}
+	changes := revisions[0].Changes
+	if len(changes) != 1 || changes[0].Field != "baseUrl" || string(changes[0].From) != `"http://orders"` || string(changes[0].To) != `"http://orders-broken"` {
+		t.Errorf("Expected the base URL change, got %+v", changes)
+	}
+	if len(revisions[1].Changes) != 0 {
+		t.Errorf("Expected no changes for the first revision, got %+v", revisions[1].Changes)
+	}
+
+	// 2. A stale expected revision is rejected
+	if _, err := useCase.RollbackService(ctx, service.ID, 1, 1); !errors.IsPreconditionFailed(err) {
+		t.Errorf("Expected precondition failed, got %v", err)
+	}
+
+	// 3. Rolling back restores the configuration as a new revision
+	restored, err := useCase.RollbackService(ctx, service.ID, 1, 2)
+	if err != nil {
+		t.Fatalf("Failed to roll back service: %v", err)
+	}
+	if restored.BaseURL != "http://orders" || restored.Revision != 3 {
+		t.Errorf("Expected revision 3 with the original base URL, got %+v", restored)
+	}
+	revisions, _ = useCase.ListRevisions(ctx, service.ID)
+	if len(revisions) != 3 || revisions[0].RestoredFrom != 1 {
+		t.Errorf("Expected a third revision restored from the first, got %+v", revisions)
+	}
+	last := bus.events[len(bus.events)-1]
+	if last.Type != entity.EventServiceUpdated || last.Service.BaseURL != "http://orders" {
+		t.Errorf("Expected an updated event for the rollback, got %+v", last)
+	}
+
+	// 4. Unknown revisions and services are not found
+	if _, err := useCase.RollbackService(ctx, service.ID, 7, 0); !errors.IsNotFound(err) {
+		t.Errorf("Expected not found for an unknown revision, got %v", err)
+	}
+	if _, err := useCase.ListRevisions(ctx, "missing"); !errors.IsNotFound(err) {
+		t.Errorf("Expected not found for an unknown service, got %v", err)
+	}
+}
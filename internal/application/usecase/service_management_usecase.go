@@ -44,5 +44,5 @@ func (uc *ServiceManagementUseCase) UpdateService(ctx context.Context, service *
 
 // DeleteService deletes a service by its ID
 func (uc *ServiceManagementUseCase) DeleteService(ctx context.Context, id string) error {
-	return uc.serviceRepo.Delete(ctx, id)
+	return uc.serviceRepo.Delete(ctx, id, 0)
 }
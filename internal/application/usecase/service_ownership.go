@@ -0,0 +1,33 @@
+package usecase
+
+import (
+	"context"
+	"sort"
+
+	"api-gateway-sample/internal/application/dto"
+)
+
+// GetOwnership reports the services each team owns and the services no team owns, sorted by name
+func (uc *ServiceUseCase) GetOwnership(ctx context.Context) (*dto.OwnershipReport, error) {
+	services, err := uc.serviceRepo.GetAll(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &dto.OwnershipReport{Teams: []dto.TeamServices{}, Unowned: []string{}}
+	teams := make(map[string][]string)
+	for _, service := range services {
+		if service.Owner == nil || service.Owner.Team == "" {
+			report.Unowned = append(report.Unowned, service.Name)
+			continue
+		}
+		teams[service.Owner.Team] = append(teams[service.Owner.Team], service.Name)
+	}
+	for team, names := range teams {
+		sort.Strings(names)
+		report.Teams = append(report.Teams, dto.TeamServices{Team: team, Services: names})
+	}
+	sort.Slice(report.Teams, func(i, j int) bool { return report.Teams[i].Team < report.Teams[j].Team })
+	sort.Strings(report.Unowned)
+	return report, nil
+}
@@ -0,0 +1,48 @@
+package usecase
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"api-gateway-sample/internal/application/dto"
+	"api-gateway-sample/internal/domain/entity"
+	"api-gateway-sample/internal/domain/repository/mock"
+)
+
+func TestServiceUseCase_GetOwnership(t *testing.T) {
+	ctx := context.Background()
+	repo := mock.NewServiceRepositoryMock()
+	for _, service := range []struct {
+		id, name, team string
+	}{
+		{"refunds-id", "refunds", "payments"},
+		{"checkout-id", "checkout", "payments"},
+		{"users-id", "users", "identity"},
+		{"legacy-id", "legacy", ""},
+	} {
+		s := entity.NewService(service.id, service.name, "1.0.0", "", "http://"+service.name, 30, 3)
+		if service.team != "" {
+			s.Owner = &entity.Owner{Team: service.team}
+		}
+		if err := repo.Create(ctx, s); err != nil {
+			t.Fatalf("Failed to create service: %v", err)
+		}
+	}
+	useCase := NewServiceUseCase(repo, nil, nil)
+
+	report, err := useCase.GetOwnership(ctx)
+	if err != nil {
+		t.Fatalf("Failed to get ownership: %v", err)
+	}
+	want := &dto.OwnershipReport{
+		Teams: []dto.TeamServices{
+			{Team: "identity", Services: []string{"users"}},
+			{Team: "payments", Services: []string{"checkout", "refunds"}},
+		},
+		Unowned: []string{"legacy"},
+	}
+	if !reflect.DeepEqual(report, want) {
+		t.Errorf("Expected ownership %+v, got %+v", want, report)
+	}
+}
@@ -0,0 +1,64 @@
+package usecase
+
+import (
+	"context"
+
+	"api-gateway-sample/internal/application/dto"
+	"api-gateway-sample/internal/domain/entity"
+)
+
+// Service plan actions
+const (
+	PlanActionCreate = "create"
+	PlanActionUpdate = "update"
+)
+
+// PlanCreateService validates a creation request and reports what it would change, without
+// creating the service
+func (uc *ServiceUseCase) PlanCreateService(ctx context.Context, req *dto.CreateServiceRequest) (*dto.ServicePlan, error) {
+	service, err := uc.newService(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	return uc.plan(ctx, PlanActionCreate, service, nil)
+}
+
+// PlanUpdateService validates an update request and reports what it would change, without
+// updating the service
+func (uc *ServiceUseCase) PlanUpdateService(ctx context.Context, id string, req *dto.UpdateServiceRequest) (*dto.ServicePlan, error) {
+	service, previous, err := uc.updatedService(ctx, id, req)
+	if err != nil {
+		return nil, err
+	}
+	return uc.plan(ctx, PlanActionUpdate, service, previous)
+}
+
+// plan reports the changes from the previous state of a service, nil when it is created, and
+// their effect on the routes of every service
+func (uc *ServiceUseCase) plan(ctx context.Context, action string, service *entity.Service, previous *entity.Service) (*dto.ServicePlan, error) {
+	var before []string
+	var changes []entity.FieldChange
+	replaces := ""
+	if previous != nil {
+		before, _ = serviceRoutes(previous)
+		changes = serviceChanges(previous, service)
+		replaces = previous.ID
+	}
+	conflicts, err := uc.serviceConflicts(ctx, service, replaces)
+	if err != nil {
+		return nil, err
+	}
+	routes, shadowed := serviceRoutes(service)
+
+	return &dto.ServicePlan{
+		Action:  action,
+		Service: dto.FromEntity(service),
+		Changes: dto.FromFieldChangeEntities(changes),
+		Routes: dto.RouteImpact{
+			Added:     subtractRoutes(routes, before),
+			Removed:   subtractRoutes(before, routes),
+			Conflicts: conflicts,
+			Shadowed:  shadowed,
+		},
+	}, nil
+}
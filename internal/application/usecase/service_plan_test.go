@@ -0,0 +1,85 @@
+package usecase
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"api-gateway-sample/internal/application/dto"
+	"api-gateway-sample/internal/domain/entity"
+	"api-gateway-sample/internal/domain/repository/mock"
+	"api-gateway-sample/pkg/errors"
+)
+
+func TestServiceUseCase_PlanServices(t *testing.T) {
+	ctx := context.Background()
+	repo := mock.NewServiceRepositoryMock()
+	orders := entity.NewService("orders-id", "orders", "1.0.0", "", "http://orders", 30, 3)
+	orders.AddEndpoint(entity.Endpoint{Path: "/api/v1/orders", Methods: []string{"GET", "POST"}})
+	if err := repo.Create(ctx, orders); err != nil {
+		t.Fatalf("Failed to create service: %v", err)
+	}
+	bus := &syncBus{}
+	useCase := NewServiceUseCase(repo, nil, bus)
+
+	// 1. Planning a creation reports its routes, conflicts and shadowed endpoints
+	create := &dto.CreateServiceRequest{
+		Name:    "billing",
+		BaseURL: "http://billing",
+		Endpoints: []dto.EndpointConfig{
+			{Path: "/api/v1/orders", Methods: []string{"POST"}},
+			{Path: "/api/v1/invoices", Methods: []string{"GET"}},
+			{Path: "/api/v1/invoices", Methods: []string{"GET", "DELETE"}},
+		},
+	}
+	plan, err := useCase.PlanCreateService(ctx, create)
+	if err != nil {
+		t.Fatalf("Failed to plan creation: %v", err)
+	}
+	if plan.Action != PlanActionCreate || len(plan.Changes) != 0 {
+		t.Errorf("Expected a creation without field changes, got %+v", plan)
+	}
+	if want := []string{"DELETE /api/v1/invoices", "GET /api/v1/invoices", "POST /api/v1/orders"}; !reflect.DeepEqual(plan.Routes.Added, want) {
+		t.Errorf("Expected added routes %v, got %v", want, plan.Routes.Added)
+	}
+	if want := []string{"GET /api/v1/invoices"}; !reflect.DeepEqual(plan.Routes.Shadowed, want) {
+		t.Errorf("Expected shadowed routes %v, got %v", want, plan.Routes.Shadowed)
+	}
+	want := []dto.RouteConflict{{Route: "POST /api/v1/orders", Services: []string{"billing", "orders"}, Ambiguous: true}}
+	if !reflect.DeepEqual(plan.Routes.Conflicts, want) {
+		t.Errorf("Expected conflicts %v, got %v", want, plan.Routes.Conflicts)
+	}
+
+	// 2. Planning an update reports the changed fields and routes
+	update := &dto.UpdateServiceRequest{
+		Name:      "orders",
+		BaseURL:   "http://orders-v2",
+		Endpoints: []dto.EndpointConfig{{Path: "/api/v1/orders", Methods: []string{"GET"}}},
+	}
+	plan, err = useCase.PlanUpdateService(ctx, "orders-id", update)
+	if err != nil {
+		t.Fatalf("Failed to plan update: %v", err)
+	}
+	if len(plan.Changes) != 2 || plan.Changes[0].Field != "baseUrl" || plan.Changes[1].Field != "endpoints" {
+		t.Errorf("Expected baseUrl and endpoints changes, got %+v", plan.Changes)
+	}
+	if len(plan.Routes.Added) != 0 || !reflect.DeepEqual(plan.Routes.Removed, []string{"POST /api/v1/orders"}) {
+		t.Errorf("Expected only POST to be removed, got %+v", plan.Routes)
+	}
+
+	// 3. Plans are validated like the changes but nothing is stored or announced
+	update.Revision = 7
+	if _, err := useCase.PlanUpdateService(ctx, "orders-id", update); !errors.IsPreconditionFailed(err) {
+		t.Errorf("Expected precondition failed, got %v", err)
+	}
+	if _, err := useCase.PlanCreateService(ctx, &dto.CreateServiceRequest{Name: "orders"}); !errors.IsAlreadyExists(err) {
+		t.Errorf("Expected already exists, got %v", err)
+	}
+	stored, _ := repo.Get(ctx, "orders-id")
+	if stored.BaseURL != "http://orders" || stored.Revision != 1 {
+		t.Errorf("Expected the service to be unchanged, got %+v", stored)
+	}
+	if services, _ := repo.GetAll(ctx); len(services) != 1 || len(bus.events) != 0 {
+		t.Errorf("Expected no service to be created nor event published, got %d services and %d events", len(services), len(bus.events))
+	}
+}
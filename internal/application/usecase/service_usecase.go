@@ -6,42 +6,53 @@ import (
 	"api-gateway-sample/internal/application/dto"
 	"api-gateway-sample/internal/domain/entity"
 	"api-gateway-sample/internal/domain/repository"
+	"api-gateway-sample/internal/domain/service"
 	"api-gateway-sample/pkg/errors"
+	"api-gateway-sample/pkg/logger"
 )
 
 // ServiceUseCase handles service-related business logic
 type ServiceUseCase struct {
 	serviceRepo repository.ServiceRepository
 	cache       repository.CacheRepository
+	events      service.EventPublisher
+	revisions   repository.ServiceRevisionRepository
+	logger      logger.Logger
+	// conflictPolicy is RouteConflictsReject or RouteConflictsWarn
+	conflictPolicy string
 }
 
 // NewServiceUseCase creates a new ServiceUseCase instance
-func NewServiceUseCase(serviceRepo repository.ServiceRepository, cache repository.CacheRepository) *ServiceUseCase {
+func NewServiceUseCase(serviceRepo repository.ServiceRepository, cache repository.CacheRepository, events service.EventPublisher) *ServiceUseCase {
 	return &ServiceUseCase{
 		serviceRepo: serviceRepo,
 		cache:       cache,
+		events:      events,
 	}
 }
 
 // CreateService creates a new service
 func (uc *ServiceUseCase) CreateService(ctx context.Context, req *dto.CreateServiceRequest) (*dto.ServiceResponse, error) {
-	// Check if service with the same name already exists
-	if _, err := uc.serviceRepo.FindByName(ctx, req.Name); err == nil {
-		return nil, errors.ErrAlreadyExists
-	} else if !errors.IsNotFound(err) {
+	service, err := uc.newService(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	conflicts, err := uc.checkRoutes(ctx, service, "")
+	if err != nil {
 		return nil, err
 	}
-
-	// Convert request to entity
-	service := req.ToEntity()
 
 	// Create service
 	if err := uc.serviceRepo.Create(ctx, service); err != nil {
 		return nil, err
 	}
+	uc.record(ctx, service, nil, 0)
+	uc.publish(ctx, entity.EventServiceCreated, service.ID, service, nil)
 
 	// Convert entity to response
-	return dto.FromEntity(service), nil
+	response := dto.FromEntity(service)
+	response.Conflicts = conflicts
+	return response, nil
 }
 
 // GetService retrieves a service by ID
@@ -56,34 +67,92 @@ func (uc *ServiceUseCase) GetService(ctx context.Context, id string) (*dto.Servi
 
 // UpdateService updates an existing service
 func (uc *ServiceUseCase) UpdateService(ctx context.Context, id string, req *dto.UpdateServiceRequest) (*dto.ServiceResponse, error) {
-	// Check if service exists
-	service, err := uc.serviceRepo.Get(ctx, id)
+	service, previous, err := uc.updatedService(ctx, id, req)
+	if err != nil {
+		return nil, err
+	}
+	conflicts, err := uc.checkRoutes(ctx, service, id)
 	if err != nil {
 		return nil, err
 	}
 
+	// Update service
+	if err := uc.serviceRepo.Update(ctx, service); err != nil {
+		return nil, err
+	}
+	uc.record(ctx, service, previous, 0)
+	uc.publish(ctx, entity.EventServiceUpdated, service.ID, service, previous)
+
+	response := dto.FromEntity(service)
+	response.Conflicts = conflicts
+	return response, nil
+}
+
+// newService validates a creation request and converts it to the service to create
+func (uc *ServiceUseCase) newService(ctx context.Context, req *dto.CreateServiceRequest) (*entity.Service, error) {
+	// Check if service with the same name already exists
+	if _, err := uc.serviceRepo.FindByName(ctx, req.Name); err == nil {
+		return nil, errors.ErrAlreadyExists
+	} else if !errors.IsNotFound(err) {
+		return nil, err
+	}
+
+	// Convert request to entity
+	return req.ToEntity(), nil
+}
+
+// updatedService validates an update request and returns the service as it would be updated,
+// along with its current state
+func (uc *ServiceUseCase) updatedService(ctx context.Context, id string, req *dto.UpdateServiceRequest) (*entity.Service, *entity.Service, error) {
+	// Check if service exists
+	previous, err := uc.serviceRepo.Get(ctx, id)
+	if err != nil {
+		return nil, nil, err
+	}
+	if req.Revision > 0 && req.Revision != previous.Revision {
+		return nil, nil, errors.ErrPreconditionFailed
+	}
+	// A plan must not change the service a repository may share
+	service := previous.Clone()
+
 	// Check if new name is already taken by another service
 	if req.Name != service.Name {
 		if existing, err := uc.serviceRepo.FindByName(ctx, req.Name); err == nil && existing.ID != id {
-			return nil, errors.ErrAlreadyExists
+			return nil, nil, errors.ErrAlreadyExists
 		} else if err != nil && !errors.IsNotFound(err) {
-			return nil, err
+			return nil, nil, err
 		}
 	}
 
 	// Update service fields
 	service.Name = req.Name
 	service.BaseURL = req.BaseURL
+	service.Published = req.Published
+	service.Signing = req.Signing.ToEntity()
+	service.ErrorTemplates = dto.ToErrorTemplateEntities(req.ErrorTemplates)
+	service.Residency = req.Residency.ToEntity()
+	service.Tags = req.Tags
+	service.Owner = req.Owner.ToEntity()
+	service.AuthMethods = req.AuthMethods
+	service.Network = req.Network
+	service.UpstreamPath = req.UpstreamPath
+	service.ValidFrom = req.ValidFrom
+	service.ValidUntil = req.ValidUntil
 	service.Endpoints = make([]entity.Endpoint, len(req.Endpoints))
 	for i, e := range req.Endpoints {
 		service.Endpoints[i] = entity.Endpoint{
-			Path:         e.Path,
-			Methods:      e.Methods,
-			RateLimit:    e.RateLimit,
-			AuthRequired: e.AuthRequired,
-			Timeout:      e.Timeout,
-			RetryCount:   e.RetryCount,
-			RetryDelay:   e.RetryDelay,
+			Path:           e.Path,
+			Methods:        e.Methods,
+			RateLimit:      e.RateLimit,
+			MaxConcurrent:  e.MaxConcurrent,
+			AuthRequired:   e.AuthRequired,
+			Timeout:        e.Timeout,
+			RetryCount:     e.RetryCount,
+			RetryDelay:     e.RetryDelay,
+			Policy:         e.Policy,
+			RequiredScopes: e.RequiredScopes,
+			Priority:       e.Priority,
+			ContentTypes:   e.ContentTypes,
 			CircuitBreaker: struct {
 				Enabled          bool    `json:"enabled"`
 				FailureThreshold float64 `json:"failureThreshold"`
@@ -111,20 +180,43 @@ func (uc *ServiceUseCase) UpdateService(ctx context.Context, id string, req *dto
 				Request:  e.Transform.Request,
 				Response: e.Transform.Response,
 			},
+			Composite:     e.Composite.ToEntity(),
+			Pipeline:      e.Pipeline.ToEntity(),
+			Bridge:        e.Bridge.ToEntity(),
+			Async:         e.Async,
+			SpikeArrest:   e.SpikeArrest.ToEntity(),
+			NegativeCache: e.NegativeCache.ToEntity(),
+			SLO:           e.SLO.ToEntity(),
+			Mock:          e.Mock.ToEntity(),
+			SOAP:          e.SOAP.ToEntity(),
+			Encryption:    e.Encryption.ToEntity(),
+			Masking:       e.Masking.ToEntity(),
+			Upload:        e.Upload.ToEntity(),
+			ResponseLimit: e.ResponseLimit.ToEntity(),
+			Tags:          e.Tags,
+			Experiment:    e.Experiment.ToEntity(),
+			FeatureFlag:   e.FeatureFlag,
+			ValidFrom:     e.ValidFrom,
+			ValidUntil:    e.ValidUntil,
 		}
 	}
 
-	// Update service
-	if err := uc.serviceRepo.Update(ctx, service); err != nil {
-		return nil, err
-	}
-
-	return dto.FromEntity(service), nil
+	return service, previous, nil
 }
 
-// DeleteService deletes a service by ID
-func (uc *ServiceUseCase) DeleteService(ctx context.Context, id string) error {
-	return uc.serviceRepo.Delete(ctx, id)
+// DeleteService deletes a service by ID. A non-zero revision must match the stored one.
+func (uc *ServiceUseCase) DeleteService(ctx context.Context, id string, revision int64) error {
+	// The previous definition lets subscribers clean up after the service
+	previous, err := uc.serviceRepo.Get(ctx, id)
+	if err == nil && revision > 0 && revision != previous.Revision {
+		return errors.ErrPreconditionFailed
+	}
+	// The repository compares the revision again, as the service may change in between
+	if err := uc.serviceRepo.Delete(ctx, id, revision); err != nil {
+		return err
+	}
+	uc.publish(ctx, entity.EventServiceDeleted, id, nil, previous)
+	return nil
 }
 
 // ListServices retrieves all services
@@ -151,3 +243,19 @@ func (uc *ServiceUseCase) FindServiceByName(ctx context.Context, name string) (*
 
 	return dto.FromEntity(service), nil
 }
+
+// publish announces a service change on the event bus
+func (uc *ServiceUseCase) publish(ctx context.Context, eventType string, serviceID string, current *entity.Service, previous *entity.Service) {
+	if uc.events == nil {
+		return
+	}
+
+	event := entity.NewEvent(eventType)
+	event.ServiceID = serviceID
+	event.Service = current
+	event.Previous = previous
+	if principal, ok := entity.PrincipalFromContext(ctx); ok {
+		event.Actor = principal.UserID
+	}
+	uc.events.Publish(ctx, event)
+}
@@ -0,0 +1,30 @@
+package usecase
+
+import (
+	"context"
+
+	"api-gateway-sample/internal/domain/entity"
+	"api-gateway-sample/internal/domain/service"
+	"api-gateway-sample/pkg/errors"
+)
+
+// SetRequestSigner signs the requests forwarded to services that configure upstream signing,
+// so that the gateway can front cloud services that authenticate callers by signature
+func (uc *ProxyUseCase) SetRequestSigner(signer service.RequestSigner) {
+	uc.signer = signer
+}
+
+// signRequest signs a request for the service when it configures upstream signing. Requests to
+// such services are never forwarded unsigned: they fail with 503 when no signer is set up.
+func (uc *ProxyUseCase) signRequest(ctx context.Context, request *entity.Request, service *entity.Service) error {
+	if service.Signing == nil {
+		return nil
+	}
+	if uc.signer == nil {
+		return errors.NewError(errors.CodeServiceUnavailable, "upstream signing is not configured", nil)
+	}
+	if err := uc.signer.Sign(ctx, request, service); err != nil {
+		return errors.NewError(errors.CodeServiceUnavailable, "failed to sign upstream request", err)
+	}
+	return nil
+}
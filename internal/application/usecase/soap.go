@@ -0,0 +1,215 @@
+package usecase
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"api-gateway-sample/internal/domain/entity"
+	"api-gateway-sample/pkg/errors"
+)
+
+// SOAP envelope namespaces and content types by version
+var (
+	soapNamespaces = map[string]string{
+		entity.SOAPVersion11: "http://schemas.xmlsoap.org/soap/envelope/",
+		entity.SOAPVersion12: "http://www.w3.org/2003/05/soap-envelope",
+	}
+	soapContentTypes = map[string]string{
+		entity.SOAPVersion11: "text/xml; charset=utf-8",
+		entity.SOAPVersion12: "application/soap+xml; charset=utf-8",
+	}
+)
+
+// soapRequestHeaders are the client headers replaced by those of the SOAP request
+var soapRequestHeaders = []string{"Content-Type", "Content-Length", "Accept", "Accept-Encoding", "Soapaction"}
+
+// callSOAP renders a JSON request into the SOAP envelope of the endpoint, forwards it to the
+// service and converts the XML response to JSON. Faults are returned as {"fault": {...}}.
+func (uc *ProxyUseCase) callSOAP(ctx context.Context, request *entity.Request, service *entity.Service, endpoint *entity.Endpoint, sample *entity.RequestSample) (*entity.Response, error) {
+	soap := endpoint.SOAP
+	version := soap.SOAPVersion()
+
+	content, err := entity.ExpandPipelineTemplate(soap.Template, func(reference string) (string, error) {
+		value, err := resolveSOAPReference(reference, request)
+		if err != nil {
+			return "", err
+		}
+		var escaped strings.Builder
+		xml.EscapeText(&escaped, []byte(value))
+		return escaped.String(), nil
+	})
+	if err != nil {
+		return nil, errors.NewError(errors.CodeInvalidInput, err.Error(), errors.ErrInvalidInput)
+	}
+	envelope := fmt.Sprintf(`<?xml version="1.0" encoding="utf-8"?><soap:Envelope xmlns:soap="%s"><soap:Body>%s</soap:Body></soap:Envelope>`,
+		soapNamespaces[version], content)
+
+	soapRequest := *request
+	soapRequest.Method = http.MethodPost
+	soapRequest.Body = []byte(envelope)
+	if soap.Path != "" {
+		soapRequest.Path = soap.Path
+	}
+	headers := http.Header(request.Headers).Clone()
+	if headers == nil {
+		headers = make(http.Header)
+	}
+	for _, name := range soapRequestHeaders {
+		headers.Del(name)
+	}
+	contentType := soapContentTypes[version]
+	if version == entity.SOAPVersion11 {
+		headers.Set("SOAPAction", `"`+soap.Action+`"`)
+	} else if soap.Action != "" {
+		contentType += fmt.Sprintf(`; action="%s"`, soap.Action)
+	}
+	headers.Set("Content-Type", contentType)
+	soapRequest.Headers = headers
+
+	response, err := uc.forwardRequest(ctx, &soapRequest, service, sample)
+	if err != nil {
+		return nil, err
+	}
+
+	// Errors answered without a SOAP envelope, such as a 404 page, are returned as they are
+	if response.StatusCode >= 400 && !strings.Contains(http.Header(response.Headers).Get("Content-Type"), "xml") {
+		return response, nil
+	}
+	body, fault, err := soapResponseBody(response.Body)
+	if err != nil {
+		return nil, errors.NewError(errors.CodeBadGateway, "invalid soap response", err)
+	}
+	statusCode := response.StatusCode
+	if fault {
+		body = map[string]interface{}{"fault": body}
+		if statusCode < 400 {
+			statusCode = http.StatusBadGateway
+		}
+	}
+	data, err := json.Marshal(body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode soap response: %w", err)
+	}
+
+	converted := *response
+	converted.StatusCode = statusCode
+	converted.Headers = http.Header(response.Headers).Clone()
+	http.Header(converted.Headers).Del("Content-Length")
+	http.Header(converted.Headers).Set("Content-Type", "application/json")
+	converted.ContentType = "application/json"
+	converted.Body = data
+	return &converted, nil
+}
+
+// resolveSOAPReference returns the value of a placeholder such as "body.customer.id",
+// "query.id" or "header.X-Tenant-ID"
+func resolveSOAPReference(reference string, request *entity.Request) (string, error) {
+	if !strings.HasPrefix(reference, entity.SOAPBodyScope+".") {
+		return resolveBridgeReference(reference, request)
+	}
+	if len(request.Body) == 0 {
+		return "", fmt.Errorf("%s is missing: the request has no body", reference)
+	}
+	value, err := resolvePipelineReference(reference, request, map[string]json.RawMessage{entity.SOAPBodyScope: request.Body})
+	if err != nil {
+		return "", fmt.Errorf("invalid request body: %s", strings.ReplaceAll(err.Error(), " in the response of step body", ""))
+	}
+	return value, nil
+}
+
+// xmlElement is a parsed XML element
+type xmlElement struct {
+	name     string
+	attrs    []xml.Attr
+	children []*xmlElement
+	text     strings.Builder
+}
+
+// soapResponseBody returns the content of the first element in the body of a SOAP envelope as
+// JSON values, and whether that element is a fault
+func soapResponseBody(data []byte) (interface{}, bool, error) {
+	decoder := xml.NewDecoder(bytes.NewReader(data))
+	root := &xmlElement{}
+	stack := []*xmlElement{root}
+	for {
+		token, err := decoder.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, false, err
+		}
+		parent := stack[len(stack)-1]
+		switch t := token.(type) {
+		case xml.StartElement:
+			element := &xmlElement{name: t.Name.Local, attrs: t.Attr}
+			parent.children = append(parent.children, element)
+			stack = append(stack, element)
+		case xml.EndElement:
+			stack = stack[:len(stack)-1]
+		case xml.CharData:
+			parent.text.Write(t)
+		}
+	}
+
+	envelope := root.child("Envelope")
+	if envelope == nil || envelope.child("Body") == nil {
+		return nil, false, fmt.Errorf("no soap envelope body")
+	}
+	body := envelope.child("Body")
+	if len(body.children) == 0 {
+		return map[string]interface{}{}, false, nil
+	}
+	content := body.children[0]
+	return content.value(), content.name == "Fault", nil
+}
+
+// child returns the first child element with a local name, nil when there is none
+func (e *xmlElement) child(name string) *xmlElement {
+	for _, child := range e.children {
+		if child.name == name {
+			return child
+		}
+	}
+	return nil
+}
+
+// value converts an element to JSON values: the text of elements without children or
+// attributes, or else an object of child elements, repeated ones as arrays, attributes as
+// "@name" and any text as "#text"
+func (e *xmlElement) value() interface{} {
+	text := strings.TrimSpace(e.text.String())
+	if len(e.children) == 0 && len(e.attrs) == 0 {
+		return text
+	}
+
+	object := make(map[string]interface{}, len(e.children)+len(e.attrs))
+	for _, attr := range e.attrs {
+		// Namespace declarations are XML syntax rather than content
+		if attr.Name.Space == "xmlns" || attr.Name.Local == "xmlns" {
+			continue
+		}
+		object["@"+attr.Name.Local] = attr.Value
+	}
+	for _, child := range e.children {
+		value := child.value()
+		switch existing := object[child.name].(type) {
+		case nil:
+			object[child.name] = value
+		case []interface{}:
+			object[child.name] = append(existing, value)
+		default:
+			object[child.name] = []interface{}{existing, value}
+		}
+	}
+	if text != "" {
+		object["#text"] = text
+	}
+	return object
+}
@@ -0,0 +1,46 @@
+package usecase
+
+import (
+	"net/url"
+
+	"api-gateway-sample/internal/application/dto"
+	"api-gateway-sample/pkg/errors"
+	"api-gateway-sample/pkg/wsdl"
+)
+
+// SOAPUseCase implements the use case for generating the configuration of SOAP endpoints from
+// the WSDL documents of SOAP services
+type SOAPUseCase struct{}
+
+// NewSOAPUseCase creates a new SOAPUseCase instance
+func NewSOAPUseCase() *SOAPUseCase {
+	return &SOAPUseCase{}
+}
+
+// GenerateEndpoints returns the SOAP configuration of an endpoint for each operation of a WSDL
+// document. Each simple value of a request is a {{body.*}} placeholder named after its element.
+func (uc *SOAPUseCase) GenerateEndpoints(document []byte) ([]*dto.SOAPOperationResponse, error) {
+	operations, err := wsdl.Operations(document)
+	if err != nil {
+		return nil, errors.NewError(errors.CodeInvalidInput, err.Error(), errors.ErrInvalidInput)
+	}
+
+	responses := make([]*dto.SOAPOperationResponse, len(operations))
+	for i, operation := range operations {
+		var path string
+		if address, err := url.Parse(operation.Address); err == nil {
+			path = address.Path
+		}
+		responses[i] = &dto.SOAPOperationResponse{
+			Name:    operation.Name,
+			Address: operation.Address,
+			SOAP: dto.SOAPConfig{
+				Path:     path,
+				Version:  operation.Version,
+				Action:   operation.Action,
+				Template: operation.Template,
+			},
+		}
+	}
+	return responses, nil
+}
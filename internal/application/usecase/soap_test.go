@@ -0,0 +1,80 @@
+package usecase
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"api-gateway-sample/internal/domain/entity"
+	"api-gateway-sample/pkg/errors"
+)
+
+func TestProxyUseCase_SOAPEndpoint(t *testing.T) {
+	ctx := context.Background()
+	service := newTestService("orders", entity.Endpoint{
+		Path:    "/api/v1/orders",
+		Methods: []string{http.MethodPost},
+		SOAP: &entity.SOAP{
+			Path:     "/ws/orders",
+			Action:   "urn:GetOrder",
+			Template: `<o:GetOrder xmlns:o="urn:orders"><o:id>{{body.order.id}}</o:id><o:tenant>{{header.X-Tenant-Id}}</o:tenant></o:GetOrder>`,
+		},
+	})
+
+	xmlHeaders := map[string][]string{"Content-Type": {"text/xml; charset=utf-8"}}
+	gateway := &scriptedGateway{responses: []*entity.Response{
+		{StatusCode: http.StatusOK, Headers: xmlHeaders, Body: []byte(`<?xml version="1.0"?>
+<soap:Envelope xmlns:soap="http://schemas.xmlsoap.org/soap/envelope/"><soap:Body>
+  <o:GetOrderResponse xmlns:o="urn:orders"><o:id>42</o:id><o:item sku="a">Pen</o:item><o:item sku="b">Ink</o:item></o:GetOrderResponse>
+</soap:Body></soap:Envelope>`)},
+		{StatusCode: http.StatusInternalServerError, Headers: xmlHeaders, Body: []byte(`<soap:Envelope xmlns:soap="http://schemas.xmlsoap.org/soap/envelope/"><soap:Body>
+  <soap:Fault><faultcode>soap:Client</faultcode><faultstring>Unknown order</faultstring></soap:Fault>
+</soap:Body></soap:Envelope>`)},
+	}}
+	useCase := newProxyFixture(t, gateway, service)
+	newRequest := func(body string) *entity.Request {
+		headers := map[string][]string{"X-Tenant-Id": {"a&b"}, "Content-Type": {"application/json"}}
+		return entity.NewRequest(http.MethodPost, "/api/v1/orders", headers, nil, []byte(body), "10.0.0.1")
+	}
+
+	// 1. The JSON request is rendered into an envelope, with its values escaped for XML
+	response, err := useCase.ProxyRequest(ctx, newRequest(`{"order":{"id":42}}`))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	sent := gateway.requests[0]
+	wantEnvelope := `<?xml version="1.0" encoding="utf-8"?><soap:Envelope xmlns:soap="http://schemas.xmlsoap.org/soap/envelope/"><soap:Body>` +
+		`<o:GetOrder xmlns:o="urn:orders"><o:id>42</o:id><o:tenant>a&amp;b</o:tenant></o:GetOrder></soap:Body></soap:Envelope>`
+	if string(sent.Body) != wantEnvelope {
+		t.Errorf("Unexpected envelope %s", sent.Body)
+	}
+	if sent.Path != "/ws/orders" || http.Header(sent.Headers).Get("SOAPAction") != `"urn:GetOrder"` ||
+		http.Header(sent.Headers).Get("Content-Type") != "text/xml; charset=utf-8" {
+		t.Errorf("Unexpected SOAP request %s %v", sent.Path, sent.Headers)
+	}
+
+	// 2. The response element is converted to JSON, repeated elements to arrays
+	want := `{"id":"42","item":[{"#text":"Pen","@sku":"a"},{"#text":"Ink","@sku":"b"}]}`
+	if response.StatusCode != http.StatusOK || string(response.Body) != want {
+		t.Errorf("Expected %s, got %d %s", want, response.StatusCode, response.Body)
+	}
+	if got := http.Header(response.Headers).Get("Content-Type"); got != "application/json" {
+		t.Errorf("Expected a JSON response, got %s", got)
+	}
+
+	// 3. Faults keep their status
+	response, err = useCase.ProxyRequest(ctx, newRequest(`{"order":{"id":7}}`))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	want = `{"fault":{"faultcode":"soap:Client","faultstring":"Unknown order"}}`
+	if response.StatusCode != http.StatusInternalServerError || string(response.Body) != want {
+		t.Errorf("Expected %s, got %d %s", want, response.StatusCode, response.Body)
+	}
+
+	// 4. Requests missing a placeholder's value are rejected before reaching the service
+	_, err = useCase.ProxyRequest(ctx, newRequest(`{"order":{}}`))
+	if errors.StatusCodeOf(err, 0) != http.StatusBadRequest || len(gateway.requests) != 2 {
+		t.Errorf("Expected 400 without calling the service, got %v", err)
+	}
+}
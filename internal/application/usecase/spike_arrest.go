@@ -0,0 +1,47 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"api-gateway-sample/internal/domain/entity"
+	"api-gateway-sample/internal/domain/service"
+	"api-gateway-sample/pkg/errors"
+	"api-gateway-sample/pkg/logger"
+)
+
+// SetSpikeArrester smooths bursts to endpoints that configure a spike arrest, delaying requests
+// so that they reach the backend evenly spaced instead of rejecting them
+func (uc *ProxyUseCase) SetSpikeArrester(arrester service.SpikeArrester) {
+	uc.spikeArrester = arrester
+}
+
+// arrestSpike waits for the endpoint's next delivery slot. It fails with 429 when the wait
+// would exceed the endpoint's max delay. The spacing applies to every client of the endpoint,
+// as it protects the backend rather than sharing it out.
+func (uc *ProxyUseCase) arrestSpike(ctx context.Context, service *entity.Service, endpoint *entity.Endpoint) error {
+	key := fmt.Sprintf("spikearrest:%s:%s", service.ID, endpoint.Path)
+
+	delay, ok, err := uc.spikeArrester.Reserve(ctx, key, endpoint.SpikeArrest.Interval(), endpoint.SpikeArrest.MaxDelayDuration())
+	if err != nil {
+		// Smoothing is a courtesy to the backend, so requests are forwarded unsmoothed
+		logger.FromContextOr(ctx, uc.logger).Warn("Spike arrest check failed", "error", err)
+		return nil
+	}
+	if !ok {
+		return errors.NewError(errors.CodeRateLimitExceeded, "too many requests, spike arrest queue is full", errors.ErrRateLimitExceeded)
+	}
+	if delay <= 0 {
+		return nil
+	}
+
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
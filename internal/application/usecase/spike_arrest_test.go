@@ -0,0 +1,67 @@
+package usecase
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"api-gateway-sample/internal/domain/entity"
+	"api-gateway-sample/pkg/errors"
+)
+
+// slotArrester books slots on a fixed clock, recording the delay handed out for each
+type slotArrester struct {
+	nextSlot time.Duration
+	delays   []time.Duration
+}
+
+func (a *slotArrester) Reserve(ctx context.Context, key string, interval time.Duration, maxDelay time.Duration) (time.Duration, bool, error) {
+	if a.nextSlot > maxDelay {
+		return 0, false, nil
+	}
+	delay := a.nextSlot
+	a.nextSlot += interval
+	a.delays = append(a.delays, delay)
+	return delay, true, nil
+}
+
+func TestProxyUseCase_SpikeArrest(t *testing.T) {
+	ctx := context.Background()
+	service := newTestService("legacy", entity.Endpoint{
+		Path:        "/api/v1/legacy",
+		Methods:     []string{http.MethodGet},
+		SpikeArrest: &entity.SpikeArrest{Rate: 100, MaxDelay: 20},
+	})
+
+	gateway := &countingGateway{statuses: []int{http.StatusOK, http.StatusOK, http.StatusOK}}
+	arrester := &slotArrester{}
+	useCase := newProxyFixture(t, gateway, service)
+	useCase.SetSpikeArrester(arrester)
+	newRequest := func() *entity.Request {
+		return entity.NewRequest(http.MethodGet, "/api/v1/legacy", map[string][]string{}, map[string][]string{}, nil, "10.0.0.1")
+	}
+
+	// 1. A burst is delivered 10ms apart, each request waiting for its slot
+	start := time.Now()
+	for i := 0; i < 3; i++ {
+		if _, err := useCase.ProxyRequest(ctx, newRequest()); err != nil {
+			t.Fatalf("Expected request %d to be delivered, got %v", i, err)
+		}
+	}
+	if elapsed := time.Since(start); elapsed < 30*time.Millisecond {
+		t.Errorf("Expected the burst to be spaced out over 30ms, took %v", elapsed)
+	}
+	if gateway.calls != 3 {
+		t.Errorf("Expected 3 requests to reach the backend, got %d", gateway.calls)
+	}
+
+	// 2. Requests that would wait longer than the max delay are rejected
+	arrester.nextSlot = 30 * time.Millisecond
+	if _, err := useCase.ProxyRequest(ctx, newRequest()); errors.StatusCodeOf(err, 0) != errors.CodeRateLimitExceeded {
+		t.Errorf("Expected 429 past the max delay, got %v", err)
+	}
+	if gateway.calls != 3 {
+		t.Errorf("Expected the rejected request not to reach the backend, got %d calls", gateway.calls)
+	}
+}
@@ -0,0 +1,178 @@
+package usecase
+
+import (
+	"context"
+	"sort"
+	"strconv"
+	"sync"
+	"sync/atomic"
+
+	"api-gateway-sample/internal/domain/entity"
+	"api-gateway-sample/internal/domain/repository"
+	"api-gateway-sample/internal/domain/service"
+	"api-gateway-sample/pkg/errors"
+	"api-gateway-sample/pkg/logger"
+)
+
+// StatsUseCase implements the use case for reading service traffic statistics
+type StatsUseCase struct {
+	serviceRepo repository.ServiceRepository
+	metrics     service.MetricsCollector
+	health      []service.HealthReporter
+	cacheSizer  repository.CacheSizer
+	pools       []*WorkerPool
+	panics      atomic.Int64
+	errorsMu    sync.Mutex
+	errorCounts map[string]*entity.ErrorCount
+	logDrops    logger.DropReporter
+	logger      logger.Logger
+}
+
+// NewStatsUseCase creates a new StatsUseCase instance
+func NewStatsUseCase(serviceRepo repository.ServiceRepository, metrics service.MetricsCollector, logger logger.Logger) *StatsUseCase {
+	return &StatsUseCase{
+		serviceRepo: serviceRepo,
+		metrics:     metrics,
+		logger:      logger,
+	}
+}
+
+// GetServiceStats returns the rolling traffic statistics of a service
+func (uc *StatsUseCase) GetServiceStats(ctx context.Context, id string) (*entity.ServiceStats, error) {
+	if _, err := uc.serviceRepo.Get(ctx, id); err != nil {
+		return nil, err
+	}
+
+	return uc.metrics.ServiceStats(id), nil
+}
+
+// TargetLatencies returns the upstream latency histograms of every upstream target
+func (uc *StatsUseCase) TargetLatencies() []*entity.TargetLatency {
+	return uc.metrics.TargetLatencies()
+}
+
+// SLOStatuses returns the compliance of every endpoint with an SLO
+func (uc *StatsUseCase) SLOStatuses() []*entity.SLOStatus {
+	return uc.metrics.SLOStatuses()
+}
+
+// RouteRequests returns the requests served by every endpoint, labelled with its tags
+func (uc *StatsUseCase) RouteRequests() []*entity.RouteRequests {
+	return uc.metrics.RouteRequests()
+}
+
+// UpstreamConnections returns the connection phases and keep-alive reuse of every service
+func (uc *StatsUseCase) UpstreamConnections() []*entity.UpstreamConnectionStats {
+	return uc.metrics.UpstreamConnections()
+}
+
+// AddHealthReporter registers a backing dependency whose health is reported
+func (uc *StatsUseCase) AddHealthReporter(reporter service.HealthReporter) {
+	uc.health = append(uc.health, reporter)
+}
+
+// DependencyHealth returns the health of every registered backing dependency
+func (uc *StatsUseCase) DependencyHealth() []*entity.DependencyHealth {
+	health := make([]*entity.DependencyHealth, 0, len(uc.health))
+	for _, reporter := range uc.health {
+		health = append(health, reporter.Health())
+	}
+	return health
+}
+
+// AddWorkerPool registers a pool of background workers whose queue is reported
+func (uc *StatsUseCase) AddWorkerPool(pool *WorkerPool) {
+	uc.pools = append(uc.pools, pool)
+}
+
+// WorkerPools returns the queue depth and dropped tasks of every registered worker pool
+func (uc *StatsUseCase) WorkerPools() []*entity.WorkerPoolStats {
+	stats := make([]*entity.WorkerPoolStats, 0, len(uc.pools))
+	for _, pool := range uc.pools {
+		stats = append(stats, pool.Stats())
+	}
+	return stats
+}
+
+// RecordPanic counts a panic recovered while serving a request
+func (uc *StatsUseCase) RecordPanic() {
+	uc.panics.Add(1)
+}
+
+// Panics returns the number of panics recovered while serving requests since startup
+func (uc *StatsUseCase) Panics() int64 {
+	return uc.panics.Load()
+}
+
+// RecordError counts an error answered with a problem type
+func (uc *StatsUseCase) RecordError(problemType errors.ProblemType) {
+	uc.errorsMu.Lock()
+	defer uc.errorsMu.Unlock()
+	if uc.errorCounts == nil {
+		uc.errorCounts = make(map[string]*entity.ErrorCount)
+	}
+	key := problemType.Type + " " + strconv.Itoa(problemType.Status)
+	count, ok := uc.errorCounts[key]
+	if !ok {
+		count = &entity.ErrorCount{Code: problemType.Code(), Status: problemType.Status, Retryable: problemType.Retryable}
+		uc.errorCounts[key] = count
+	}
+	count.Count++
+}
+
+// Errors returns the number of errors answered with each problem type since startup, by code
+// and status
+func (uc *StatsUseCase) Errors() []*entity.ErrorCount {
+	uc.errorsMu.Lock()
+	counts := make([]*entity.ErrorCount, 0, len(uc.errorCounts))
+	for _, count := range uc.errorCounts {
+		snapshot := *count
+		counts = append(counts, &snapshot)
+	}
+	uc.errorsMu.Unlock()
+
+	sort.Slice(counts, func(i, j int) bool {
+		if counts[i].Code != counts[j].Code {
+			return counts[i].Code < counts[j].Code
+		}
+		return counts[i].Status < counts[j].Status
+	})
+	return counts
+}
+
+// SetLogDropReporter reports the log lines the application logger sampled out or dropped
+func (uc *StatsUseCase) SetLogDropReporter(reporter logger.DropReporter) {
+	uc.logDrops = reporter
+}
+
+// LogDrops returns the log lines that were never written, false when no reporter is set
+func (uc *StatsUseCase) LogDrops() (logger.Drops, bool) {
+	if uc.logDrops == nil {
+		return logger.Drops{}, false
+	}
+	return uc.logDrops.Drops(), true
+}
+
+// SetCacheSizer reports the size of the response cache backend in the cache statistics
+func (uc *StatsUseCase) SetCacheSizer(sizer repository.CacheSizer) {
+	uc.cacheSizer = sizer
+}
+
+// CacheStats returns the size of the response cache and the cache lookups of every endpoint.
+// The size is left out when the backend cannot report it.
+func (uc *StatsUseCase) CacheStats(ctx context.Context) *entity.CacheStats {
+	stats := &entity.CacheStats{Endpoints: uc.metrics.CacheStats()}
+	if uc.cacheSizer == nil {
+		return stats
+	}
+
+	entries, bytes, err := uc.cacheSizer.Size(ctx)
+	if err != nil {
+		logger.FromContextOr(ctx, uc.logger).Warn("Failed to read cache size", "error", err)
+		return stats
+	}
+	stats.SizeAvailable = true
+	stats.Entries = entries
+	stats.MemoryBytes = bytes
+	return stats
+}
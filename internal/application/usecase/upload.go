@@ -0,0 +1,214 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/textproto"
+	"os"
+	"sync"
+
+	"api-gateway-sample/internal/domain/entity"
+	"api-gateway-sample/internal/domain/service"
+	"api-gateway-sample/pkg/errors"
+	"api-gateway-sample/pkg/logger"
+)
+
+// SetUploadScanner checks the files of uploads to endpoints that scan them with the scanner
+// before they are forwarded. Files are spooled to spoolDir while they are scanned, the system
+// temporary directory when empty, so that uploads are never held in memory.
+func (uc *ProxyUseCase) SetUploadScanner(scanner service.UploadScanner, spoolDir string) {
+	uc.scanner = scanner
+	uc.spoolDir = spoolDir
+}
+
+// StreamsUpload reports whether the body of a request is an upload the matched endpoint streams
+// to its service, which the caller then passes as the request's BodyStream instead of reading it
+func (uc *ProxyUseCase) StreamsUpload(ctx context.Context, request *entity.Request) bool {
+	if _, ok := entity.MultipartBoundary(request.Headers); !ok {
+		return false
+	}
+	_, endpoint, err := uc.ResolveEndpoint(ctx, request.Path, request.Method)
+	return err == nil && endpoint.Upload != nil
+}
+
+// uploadStream is the body of an upload as forwarded to the service: the client's parts,
+// re-encoded with the same boundary as they are checked
+type uploadStream struct {
+	body *io.PipeReader
+
+	mu   sync.Mutex
+	err  error
+	size int64
+}
+
+// streamUpload replaces the body stream of a request with one forwarding its parts as they
+// are read and checked. The upstream receives the upload chunked, as its length is only known
+// once it is read. The stream of a request the endpoint does not stream uploads of, as its
+// configuration changed since, is read into the request body instead, and nil is returned.
+func (uc *ProxyUseCase) streamUpload(ctx context.Context, request *entity.Request, upload *entity.Upload) (*uploadStream, error) {
+	boundary, ok := entity.MultipartBoundary(request.Headers)
+	if !ok || upload == nil {
+		body, err := io.ReadAll(request.BodyStream)
+		if err != nil {
+			return nil, errors.NewTypedError(errors.ProblemInvalidInput, "failed to read request body", err)
+		}
+		request.Body, request.BodyStream = body, nil
+		return nil, nil
+	}
+
+	reader, writer := io.Pipe()
+	stream := &uploadStream{body: reader}
+	parts := multipart.NewReader(&countingReader{Reader: request.BodyStream, count: stream.add}, boundary)
+	request.BodyStream = reader
+	go func() {
+		err := uc.copyUpload(ctx, writer, parts, boundary, upload)
+		if err != nil && err != io.ErrClosedPipe {
+			stream.fail(err)
+		}
+		writer.CloseWithError(err)
+	}()
+	return stream, nil
+}
+
+// close stops forwarding the upload and returns the error the upload was rejected with, nil
+// when it was forwarded in full or the service answered before reading it all
+func (s *uploadStream) close() error {
+	s.body.CloseWithError(io.ErrClosedPipe)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.err
+}
+
+// bytes returns the size of the upload read from the client so far
+func (s *uploadStream) bytes() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return int(s.size)
+}
+
+func (s *uploadStream) fail(err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.err = err
+}
+
+func (s *uploadStream) add(n int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.size += int64(n)
+}
+
+// copyUpload writes the parts of an upload to w, checking each file
+func (uc *ProxyUseCase) copyUpload(ctx context.Context, w io.Writer, parts *multipart.Reader, boundary string, upload *entity.Upload) error {
+	form := multipart.NewWriter(w)
+	if err := form.SetBoundary(boundary); err != nil {
+		return errors.NewTypedError(errors.ProblemInvalidInput, "invalid multipart boundary", err)
+	}
+	for {
+		// Raw parts are forwarded as sent, without decoding their transfer encoding
+		part, err := parts.NextRawPart()
+		if err == io.EOF {
+			return form.Close()
+		}
+		if err != nil {
+			return errors.NewTypedError(errors.ProblemInvalidInput, "malformed multipart body", err)
+		}
+		dst, err := form.CreatePart(part.Header)
+		if err != nil {
+			return err
+		}
+		if part.FileName() == "" {
+			_, err = io.Copy(dst, part)
+		} else {
+			err = uc.copyUploadedFile(ctx, dst, part, upload)
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
+
+// copyUploadedFile writes a file part to dst, rejecting it when it exceeds the endpoint's
+// maxFileSize or, for endpoints that scan uploads, when the scanner does not find it clean
+func (uc *ProxyUseCase) copyUploadedFile(ctx context.Context, dst io.Writer, part *multipart.Part, upload *entity.Upload) error {
+	content := io.Reader(part)
+	if upload.MaxFileSize > 0 {
+		content = &maxSizeReader{Reader: part, remaining: upload.MaxFileSize, filename: part.FileName()}
+	}
+	if !upload.Scan {
+		_, err := io.Copy(dst, content)
+		return err
+	}
+
+	if uc.scanner == nil {
+		logger.FromContextOr(ctx, uc.logger).Warn("Upload scanning is required but no scanner is configured")
+		return errors.NewError(errors.CodeServiceUnavailable, "upload scanning unavailable", errors.ErrServiceUnavailable)
+	}
+	spool, err := os.CreateTemp(uc.spoolDir, "upload-*")
+	if err != nil {
+		return fmt.Errorf("failed to spool upload: %w", err)
+	}
+	defer os.Remove(spool.Name())
+	defer spool.Close()
+
+	size, err := io.Copy(spool, content)
+	if err != nil {
+		return err
+	}
+	if _, err := spool.Seek(0, io.SeekStart); err != nil {
+		return fmt.Errorf("failed to spool upload: %w", err)
+	}
+	file := entity.UploadedFile{
+		Field:       part.FormName(),
+		Filename:    part.FileName(),
+		ContentType: textproto.MIMEHeader(part.Header).Get("Content-Type"),
+		Size:        size,
+	}
+	verdict, err := uc.scanner.Scan(ctx, file, spool)
+	if err != nil {
+		return errors.NewError(errors.CodeServiceUnavailable, "upload scan failed", err)
+	}
+	if !verdict.Clean {
+		logger.FromContextOr(ctx, uc.logger).Warn("Rejected uploaded file", "filename", file.Filename, "threat", verdict.Threat)
+		return errors.NewTypedError(errors.ProblemUploadRejected, fmt.Sprintf("file %q was rejected: %s", file.Filename, verdict.Threat), nil)
+	}
+
+	if _, err := spool.Seek(0, io.SeekStart); err != nil {
+		return fmt.Errorf("failed to spool upload: %w", err)
+	}
+	_, err = io.Copy(dst, spool)
+	return err
+}
+
+// maxSizeReader reads a file, failing once it exceeds its maximum size
+type maxSizeReader struct {
+	io.Reader
+	remaining int64
+	filename  string
+}
+
+func (r *maxSizeReader) Read(p []byte) (int, error) {
+	if int64(len(p)) > r.remaining+1 {
+		p = p[:r.remaining+1]
+	}
+	n, err := r.Reader.Read(p)
+	r.remaining -= int64(n)
+	if r.remaining < 0 {
+		return 0, errors.NewTypedError(errors.ProblemPayloadTooLarge, fmt.Sprintf("file %q exceeds the maximum file size", r.filename), nil)
+	}
+	return n, err
+}
+
+// countingReader reports the bytes read from a reader
+type countingReader struct {
+	io.Reader
+	count func(n int)
+}
+
+func (r *countingReader) Read(p []byte) (int, error) {
+	n, err := r.Reader.Read(p)
+	r.count(n)
+	return n, err
+}
@@ -0,0 +1,172 @@
+package usecase
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"api-gateway-sample/internal/domain/entity"
+	"api-gateway-sample/pkg/errors"
+)
+
+// uploadGateway reads the uploads routed to it as a service would, recording their files
+type uploadGateway struct {
+	countingGateway
+	files map[string]string
+}
+
+func (g *uploadGateway) RouteRequest(ctx context.Context, request *entity.Request) (*entity.Response, error) {
+	boundary, _ := entity.MultipartBoundary(request.Headers)
+	parts := multipart.NewReader(request.BodyStream, boundary)
+	g.files = map[string]string{}
+	for {
+		part, err := parts.NextPart()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		content, err := io.ReadAll(part)
+		if err != nil {
+			return nil, err
+		}
+		g.files[part.FormName()] = string(content)
+	}
+	return &entity.Response{StatusCode: http.StatusCreated}, nil
+}
+
+// stubScanner rejects the files containing a signature
+type stubScanner struct {
+	scanned []entity.UploadedFile
+}
+
+func (s *stubScanner) Scan(ctx context.Context, file entity.UploadedFile, content io.Reader) (*entity.ScanVerdict, error) {
+	s.scanned = append(s.scanned, file)
+	data, err := io.ReadAll(content)
+	if err != nil {
+		return nil, err
+	}
+	if strings.Contains(string(data), "EICAR") {
+		return &entity.ScanVerdict{Threat: "Eicar-Test-Signature"}, nil
+	}
+	return &entity.ScanVerdict{Clean: true}, nil
+}
+
+func newUploadFixture(t *testing.T, upload *entity.Upload) (*ProxyUseCase, *uploadGateway) {
+	t.Helper()
+	service := newTestService("documents", entity.Endpoint{Path: "/api/v1/documents", Methods: []string{http.MethodPost}, Upload: upload})
+	gateway := &uploadGateway{}
+	return newProxyFixture(t, gateway, service), gateway
+}
+
+func newUploadRequest(t *testing.T, files map[string]string) *entity.Request {
+	t.Helper()
+	var body bytes.Buffer
+	form := multipart.NewWriter(&body)
+	form.WriteField("title", "report")
+	for name, content := range files {
+		w, err := form.CreateFormFile(name, name+".txt")
+		if err != nil {
+			t.Fatalf("Failed to create form file: %v", err)
+		}
+		w.Write([]byte(content))
+	}
+	form.Close()
+
+	request := entity.NewRequest(http.MethodPost, "/api/v1/documents", map[string][]string{"Content-Type": {form.FormDataContentType()}}, map[string][]string{}, nil, "127.0.0.1")
+	request.BodyStream = &body
+	return request
+}
+
+func TestProxyUseCase_StreamUpload(t *testing.T) {
+	tests := []struct {
+		name    string
+		upload  *entity.Upload
+		scanner bool
+		files   map[string]string
+		status  int
+	}{
+		{name: "forwarded", upload: &entity.Upload{MaxFileSize: 16}, files: map[string]string{"a": "small", "b": "sixteen bytes..."}},
+		{name: "file too large", upload: &entity.Upload{MaxFileSize: 16}, files: map[string]string{"a": "seventeen bytes.."}, status: http.StatusRequestEntityTooLarge},
+		{name: "clean file", upload: &entity.Upload{Scan: true}, scanner: true, files: map[string]string{"a": "clean"}},
+		{name: "infected file", upload: &entity.Upload{Scan: true}, scanner: true, files: map[string]string{"a": "EICAR"}, status: http.StatusUnprocessableEntity},
+		{name: "no scanner", upload: &entity.Upload{Scan: true}, files: map[string]string{"a": "clean"}, status: http.StatusServiceUnavailable},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			useCase, gateway := newUploadFixture(t, tt.upload)
+			scanner := &stubScanner{}
+			if tt.scanner {
+				useCase.SetUploadScanner(scanner, t.TempDir())
+			}
+
+			response, err := useCase.ProxyRequest(context.Background(), newUploadRequest(t, tt.files))
+			if tt.status != 0 {
+				if got := errors.StatusCodeOf(err, 0); got != tt.status {
+					t.Fatalf("Expected the upload to be rejected with %d, got %d: %v", tt.status, got, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if response.StatusCode != http.StatusCreated {
+				t.Errorf("Expected the service's response, got %d", response.StatusCode)
+			}
+			for name, content := range tt.files {
+				if gateway.files[name] != content {
+					t.Errorf("Expected file %s to be forwarded as %q, got %q", name, content, gateway.files[name])
+				}
+			}
+			if gateway.files["title"] != "report" {
+				t.Errorf("Expected form fields to be forwarded, got %q", gateway.files["title"])
+			}
+			if tt.scanner && (len(scanner.scanned) != 1 || scanner.scanned[0].Filename != "a.txt" || scanner.scanned[0].Size != 5) {
+				t.Errorf("Expected the file to be scanned with its name and size, got %+v", scanner.scanned)
+			}
+		})
+	}
+}
+
+func TestProxyUseCase_StreamsUpload(t *testing.T) {
+	useCase, _ := newUploadFixture(t, &entity.Upload{})
+	if !useCase.StreamsUpload(context.Background(), newUploadRequest(t, nil)) {
+		t.Error("Expected multipart requests to upload endpoints to be streamed")
+	}
+
+	request := newUploadRequest(t, nil)
+	request.Headers["Content-Type"] = []string{"application/json"}
+	if useCase.StreamsUpload(context.Background(), request) {
+		t.Error("Expected other bodies to be read")
+	}
+}
+
+func TestProxyUseCase_StreamUploadSkipsIdempotency(t *testing.T) {
+	useCase, gateway := newUploadFixture(t, &entity.Upload{})
+	cache := &jsonCache{entries: map[string][]byte{}}
+	useCase.SetIdempotencyStore(cache, time.Hour, time.Minute)
+
+	// Streamed bodies cannot be compared, so uploads reusing a key are forwarded rather than
+	// replayed the response to another file
+	for _, content := range []string{"first", "second"} {
+		request := newUploadRequest(t, map[string]string{"a": content})
+		request.Headers[HeaderIdempotencyKey] = []string{"key-1"}
+		response, err := useCase.ProxyRequest(context.Background(), request)
+		if err != nil {
+			t.Fatalf("Failed to proxy upload: %v", err)
+		}
+		if len(response.Headers[HeaderIdempotentReplayed]) != 0 || gateway.files["a"] != content {
+			t.Errorf("Expected the upload of %q to reach the service, got %v", content, gateway.files)
+		}
+	}
+	if len(cache.entries) != 0 {
+		t.Errorf("Expected no stored responses, got %d", len(cache.entries))
+	}
+}
@@ -0,0 +1,156 @@
+package usecase
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"net/url"
+	"time"
+
+	"api-gateway-sample/internal/application/dto"
+	"api-gateway-sample/internal/domain/entity"
+	"api-gateway-sample/internal/domain/repository"
+	"api-gateway-sample/internal/domain/service"
+	"api-gateway-sample/pkg/errors"
+	"api-gateway-sample/pkg/logger"
+)
+
+// WebhookUseCase implements the use case for webhook subscriptions to configuration changes
+type WebhookUseCase struct {
+	webhookRepo repository.WebhookRepository
+	deliverer   service.WebhookDeliverer
+	pool        *WorkerPool
+	logger      logger.Logger
+}
+
+// NewWebhookUseCase creates a new WebhookUseCase instance
+func NewWebhookUseCase(webhookRepo repository.WebhookRepository, deliverer service.WebhookDeliverer, logger logger.Logger) *WebhookUseCase {
+	return &WebhookUseCase{
+		webhookRepo: webhookRepo,
+		deliverer:   deliverer,
+		logger:      logger,
+	}
+}
+
+// SetWorkerPool dispatches events from the bus on a pool of workers instead of a goroutine each,
+// so that a burst of changes cannot queue unbounded deliveries
+func (uc *WebhookUseCase) SetWorkerPool(pool *WorkerPool) {
+	uc.pool = pool
+}
+
+// CreateWebhook registers a webhook subscription. A signing secret is generated when none
+// is given; it is only returned in this response.
+func (uc *WebhookUseCase) CreateWebhook(ctx context.Context, req *dto.CreateWebhookRequest) (*dto.WebhookResponse, error) {
+	target, err := url.Parse(req.URL)
+	if err != nil || (target.Scheme != "http" && target.Scheme != "https") || target.Host == "" {
+		return nil, errors.NewError(errors.CodeInvalidInput, "url must be an absolute http or https URL", errors.ErrInvalidInput)
+	}
+	for _, eventType := range req.Events {
+		if !entity.IsWebhookEvent(eventType) {
+			return nil, errors.NewError(errors.CodeInvalidInput, fmt.Sprintf("unsupported event %q", eventType), errors.ErrInvalidInput)
+		}
+	}
+
+	secret := req.Secret
+	if secret == "" {
+		if secret, err = generateSecret(); err != nil {
+			return nil, err
+		}
+	}
+
+	webhook := &entity.WebhookSubscription{
+		ID:          entity.NewRequestID(),
+		URL:         req.URL,
+		Secret:      secret,
+		Events:      req.Events,
+		Description: req.Description,
+		Active:      true,
+		CreatedAt:   time.Now(),
+	}
+	if webhook.Events == nil {
+		webhook.Events = []string{}
+	}
+	if err := uc.webhookRepo.Create(ctx, webhook); err != nil {
+		return nil, err
+	}
+
+	response := dto.FromWebhookEntity(webhook)
+	response.Secret = secret
+	return response, nil
+}
+
+// GetWebhook retrieves a webhook subscription by ID
+func (uc *WebhookUseCase) GetWebhook(ctx context.Context, id string) (*dto.WebhookResponse, error) {
+	webhook, err := uc.webhookRepo.Get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	return dto.FromWebhookEntity(webhook), nil
+}
+
+// ListWebhooks retrieves all webhook subscriptions
+func (uc *WebhookUseCase) ListWebhooks(ctx context.Context) ([]*dto.WebhookResponse, error) {
+	webhooks, err := uc.webhookRepo.GetAll(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	responses := make([]*dto.WebhookResponse, len(webhooks))
+	for i, webhook := range webhooks {
+		responses[i] = dto.FromWebhookEntity(webhook)
+	}
+	return responses, nil
+}
+
+// DeleteWebhook deletes a webhook subscription by ID
+func (uc *WebhookUseCase) DeleteWebhook(ctx context.Context, id string) error {
+	return uc.webhookRepo.Delete(ctx, id)
+}
+
+// Subscribe forwards configuration change events on the bus to the matching webhooks. Events
+// of other gateway instances are delivered by the instance they happened on.
+func (uc *WebhookUseCase) Subscribe(bus service.EventBus) {
+	for _, eventType := range entity.WebhookEvents {
+		bus.Subscribe(eventType, func(ctx context.Context, event *entity.Event) {
+			if event.Remote {
+				return
+			}
+			// Deliveries outlive the request that caused the change
+			if uc.pool == nil {
+				go uc.Dispatch(context.Background(), event)
+				return
+			}
+			if !uc.pool.Submit(ctx, func(ctx context.Context) { uc.Dispatch(ctx, event) }) {
+				uc.logger.Warn("Webhook delivery queue is full, dropped event", "event", event.Type)
+			}
+		})
+	}
+}
+
+// Dispatch delivers the event to every active webhook subscribed to its type
+func (uc *WebhookUseCase) Dispatch(ctx context.Context, event *entity.Event) {
+	webhooks, err := uc.webhookRepo.GetAll(ctx)
+	if err != nil {
+		uc.logger.Error("Failed to load webhooks", "event", event.Type, "error", err)
+		return
+	}
+
+	for _, webhook := range webhooks {
+		if !webhook.Matches(event.Type) {
+			continue
+		}
+		if err := uc.deliverer.Deliver(ctx, webhook, event); err != nil {
+			uc.logger.Error("Failed to deliver webhook", "webhook_id", webhook.ID, "event", event.Type, "error", err)
+		}
+	}
+}
+
+// generateSecret returns a random hex-encoded webhook signing secret
+func generateSecret() (string, error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", fmt.Errorf("failed to generate webhook secret: %w", err)
+	}
+	return hex.EncodeToString(buf), nil
+}
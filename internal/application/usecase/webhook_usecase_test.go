@@ -0,0 +1,79 @@
+package usecase
+
+import (
+	"context"
+	"testing"
+
+	"api-gateway-sample/internal/application/dto"
+	"api-gateway-sample/internal/domain/entity"
+	"api-gateway-sample/internal/domain/repository/mock"
+	"api-gateway-sample/pkg/errors"
+)
+
+// recordingDeliverer records the webhooks each event was delivered to
+type recordingDeliverer struct {
+	delivered []string
+}
+
+func (d *recordingDeliverer) Deliver(ctx context.Context, webhook *entity.WebhookSubscription, event *entity.Event) error {
+	d.delivered = append(d.delivered, webhook.URL+" "+event.Type)
+	return nil
+}
+
+func TestWebhookUseCase_CreateWebhook(t *testing.T) {
+	ctx := context.Background()
+	useCase := NewWebhookUseCase(mock.NewWebhookRepositoryMock(), &recordingDeliverer{}, &MockLogger{})
+
+	webhook, err := useCase.CreateWebhook(ctx, &dto.CreateWebhookRequest{URL: "https://ci.example.com/hook"})
+	if err != nil {
+		t.Fatalf("Failed to create webhook: %v", err)
+	}
+	if len(webhook.Secret) != 64 {
+		t.Errorf("Expected a generated secret, got %q", webhook.Secret)
+	}
+
+	stored, err := useCase.GetWebhook(ctx, webhook.ID)
+	if err != nil {
+		t.Fatalf("Failed to get webhook: %v", err)
+	}
+	if stored.Secret != "" {
+		t.Errorf("Expected secret to be hidden after creation")
+	}
+
+	invalid := []*dto.CreateWebhookRequest{
+		{URL: "ftp://ci.example.com/hook"},
+		{URL: "/relative"},
+		{URL: "https://ci.example.com/hook", Events: []string{"key.revoked"}},
+	}
+	for _, req := range invalid {
+		if _, err := useCase.CreateWebhook(ctx, req); !errors.IsInvalidInput(err) {
+			t.Errorf("Expected invalid input for %+v, got %v", req, err)
+		}
+	}
+}
+
+func TestWebhookUseCase_Dispatch(t *testing.T) {
+	ctx := context.Background()
+	deliverer := &recordingDeliverer{}
+	useCase := NewWebhookUseCase(mock.NewWebhookRepositoryMock(), deliverer, &MockLogger{})
+
+	for _, req := range []*dto.CreateWebhookRequest{
+		{URL: "https://all.example.com"},
+		{URL: "https://deletes.example.com", Events: []string{entity.EventServiceDeleted}},
+	} {
+		if _, err := useCase.CreateWebhook(ctx, req); err != nil {
+			t.Fatalf("Failed to create webhook: %v", err)
+		}
+	}
+
+	useCase.Dispatch(ctx, entity.NewEvent(entity.EventServiceUpdated))
+	if len(deliverer.delivered) != 1 || deliverer.delivered[0] != "https://all.example.com service.updated" {
+		t.Errorf("Expected update to reach only the catch-all webhook, got %v", deliverer.delivered)
+	}
+
+	deliverer.delivered = nil
+	useCase.Dispatch(ctx, entity.NewEvent(entity.EventServiceDeleted))
+	if len(deliverer.delivered) != 2 {
+		t.Errorf("Expected delete to reach both webhooks, got %v", deliverer.delivered)
+	}
+}
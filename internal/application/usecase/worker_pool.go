@@ -0,0 +1,106 @@
+package usecase
+
+import (
+	"context"
+	"sync/atomic"
+
+	"api-gateway-sample/internal/domain/entity"
+	"api-gateway-sample/pkg/logger"
+)
+
+// WorkerPool processes background work on a fixed number of workers from a bounded queue, so
+// that bursts of work wait or are dropped rather than piling up in memory
+type WorkerPool struct {
+	name    string
+	workers int
+	policy  string
+	tasks   chan func(context.Context)
+	logger  logger.Logger
+
+	completed atomic.Int64
+	dropped   atomic.Int64
+}
+
+// NewWorkerPool creates a new WorkerPool instance. At most queueSize tasks wait for a worker;
+// policy is one of the entity.DropPolicy constants and decides what happens to tasks submitted
+// while the queue is full. drop-oldest needs a queue and behaves as reject without one.
+func NewWorkerPool(name string, workers int, queueSize int, policy string, logger logger.Logger) *WorkerPool {
+	if policy == entity.DropPolicyOldest && queueSize == 0 {
+		policy = entity.DropPolicyReject
+	}
+	return &WorkerPool{
+		name:    name,
+		workers: workers,
+		policy:  policy,
+		tasks:   make(chan func(context.Context), queueSize),
+		logger:  logger,
+	}
+}
+
+// Start runs the workers until the context is cancelled. Tasks are given that context.
+func (p *WorkerPool) Start(ctx context.Context) {
+	for i := 0; i < p.workers; i++ {
+		go p.run(ctx)
+	}
+}
+
+// Submit queues a task and reports whether it was queued. A task queued under drop-oldest may
+// still be discarded by later submissions. Under block, ctx bounds the wait for room.
+func (p *WorkerPool) Submit(ctx context.Context, task func(context.Context)) bool {
+	select {
+	case p.tasks <- task:
+		return true
+	default:
+	}
+
+	switch p.policy {
+	case entity.DropPolicyOldest:
+		for {
+			select {
+			case <-p.tasks:
+				p.dropped.Add(1)
+				p.logger.Warn("Worker pool queue is full, dropped the oldest task", "pool", p.name)
+			default:
+			}
+			select {
+			case p.tasks <- task:
+				return true
+			default:
+			}
+		}
+	case entity.DropPolicyBlock:
+		select {
+		case p.tasks <- task:
+			return true
+		case <-ctx.Done():
+		}
+	}
+	p.dropped.Add(1)
+	return false
+}
+
+// Stats returns a snapshot of the queue and the tasks processed and dropped so far
+func (p *WorkerPool) Stats() *entity.WorkerPoolStats {
+	return &entity.WorkerPoolStats{
+		Name:          p.name,
+		Workers:       p.workers,
+		DropPolicy:    p.policy,
+		QueueDepth:    len(p.tasks),
+		QueueCapacity: cap(p.tasks),
+		Completed:     p.completed.Load(),
+		Dropped:       p.dropped.Load(),
+	}
+}
+
+// run processes queued tasks until the context is cancelled
+func (p *WorkerPool) run(ctx context.Context) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case task := <-p.tasks:
+			task(ctx)
+			p.completed.Add(1)
+		}
+	}
+}
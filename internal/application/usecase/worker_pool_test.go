@@ -0,0 +1,114 @@
+package usecase
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"api-gateway-sample/internal/domain/entity"
+)
+
+func TestWorkerPool_Process(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	pool := NewWorkerPool("test", 2, 10, entity.DropPolicyReject, &MockLogger{})
+	pool.Start(ctx)
+
+	done := make(chan int, 5)
+	for i := 0; i < 5; i++ {
+		i := i
+		if !pool.Submit(ctx, func(context.Context) { done <- i }) {
+			t.Fatalf("Expected task %d to be queued", i)
+		}
+	}
+	for i := 0; i < 5; i++ {
+		select {
+		case <-done:
+		case <-time.After(time.Second):
+			t.Fatal("Timed out waiting for tasks")
+		}
+	}
+
+	deadline := time.Now().Add(time.Second)
+	for pool.Stats().Completed != 5 && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	stats := pool.Stats()
+	if stats.Completed != 5 || stats.Dropped != 0 || stats.QueueDepth != 0 || stats.QueueCapacity != 10 {
+		t.Errorf("Unexpected stats %+v", stats)
+	}
+}
+
+func TestWorkerPool_DropPolicies(t *testing.T) {
+	ctx := context.Background()
+
+	// The pools are not started, so tasks stay queued
+	t.Run("Reject", func(t *testing.T) {
+		pool := NewWorkerPool("test", 1, 2, entity.DropPolicyReject, &MockLogger{})
+		results := []bool{
+			pool.Submit(ctx, func(context.Context) {}),
+			pool.Submit(ctx, func(context.Context) {}),
+			pool.Submit(ctx, func(context.Context) {}),
+		}
+		if !results[0] || !results[1] || results[2] {
+			t.Errorf("Expected only the third task to be rejected, got %v", results)
+		}
+		if stats := pool.Stats(); stats.QueueDepth != 2 || stats.Dropped != 1 {
+			t.Errorf("Unexpected stats %+v", stats)
+		}
+	})
+
+	t.Run("DropOldest", func(t *testing.T) {
+		pool := NewWorkerPool("test", 1, 2, entity.DropPolicyOldest, &MockLogger{})
+		var ran []int
+		for i := 0; i < 4; i++ {
+			i := i
+			if !pool.Submit(ctx, func(context.Context) { ran = append(ran, i) }) {
+				t.Fatalf("Expected task %d to be queued", i)
+			}
+		}
+		if stats := pool.Stats(); stats.QueueDepth != 2 || stats.Dropped != 2 {
+			t.Errorf("Unexpected stats %+v", stats)
+		}
+		for len(pool.tasks) > 0 {
+			(<-pool.tasks)(ctx)
+		}
+		if len(ran) != 2 || ran[0] != 2 || ran[1] != 3 {
+			t.Errorf("Expected the newest tasks to remain, got %v", ran)
+		}
+	})
+
+	t.Run("DropOldestWithoutQueue", func(t *testing.T) {
+		pool := NewWorkerPool("test", 1, 0, entity.DropPolicyOldest, &MockLogger{})
+		if pool.Submit(ctx, func(context.Context) {}) {
+			t.Error("Expected the task to be rejected without a queue")
+		}
+		if stats := pool.Stats(); stats.DropPolicy != entity.DropPolicyReject {
+			t.Errorf("Expected reject policy, got %s", stats.DropPolicy)
+		}
+	})
+
+	t.Run("Block", func(t *testing.T) {
+		pool := NewWorkerPool("test", 1, 1, entity.DropPolicyBlock, &MockLogger{})
+		pool.Submit(ctx, func(context.Context) {})
+
+		waitCtx, cancel := context.WithTimeout(ctx, 20*time.Millisecond)
+		defer cancel()
+		if pool.Submit(waitCtx, func(context.Context) {}) {
+			t.Error("Expected the task to give up once its context ended")
+		}
+
+		// Room made by a worker lets a waiting task in
+		go func() {
+			time.Sleep(10 * time.Millisecond)
+			<-pool.tasks
+		}()
+		if !pool.Submit(ctx, func(context.Context) {}) {
+			t.Error("Expected the task to be queued once there was room")
+		}
+		if stats := pool.Stats(); stats.Dropped != 1 || stats.QueueDepth != 1 {
+			t.Errorf("Unexpected stats %+v", stats)
+		}
+	})
+}
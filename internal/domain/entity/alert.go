@@ -0,0 +1,25 @@
+package entity
+
+import "time"
+
+// Alert types raised by the gateway
+const (
+	AlertErrorRate = "error_rate"
+	AlertSLOBudget = "slo_budget_exhausted"
+)
+
+// Alert is an operational event that operators should be notified about
+type Alert struct {
+	Type      string    `json:"type"`
+	ServiceID string    `json:"serviceId,omitempty"`
+	Target    string    `json:"target,omitempty"`
+	Message   string    `json:"message"`
+	Value     float64   `json:"value,omitempty"`
+	Threshold float64   `json:"threshold,omitempty"`
+	Timestamp time.Time `json:"timestamp"`
+}
+
+// DedupKey identifies repeated occurrences of the same alert
+func (a *Alert) DedupKey() string {
+	return a.Type + "|" + a.ServiceID + "|" + a.Target
+}
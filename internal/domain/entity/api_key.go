@@ -0,0 +1,50 @@
+package entity
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"time"
+)
+
+// API key statuses
+const (
+	// APIKeyStatusPending is a signup waiting for an administrator's review
+	APIKeyStatusPending = "pending"
+	// APIKeyStatusApproved is an issued key that may be used
+	APIKeyStatusApproved = "approved"
+	// APIKeyStatusRejected is a signup an administrator declined
+	APIKeyStatusRejected = "rejected"
+)
+
+// APIKey is a consumer's key for calling a published service, created by a portal signup
+type APIKey struct {
+	ID        string `json:"id"`
+	ServiceID string `json:"serviceId"`
+	Consumer  string `json:"consumer"`
+	Email     string `json:"email"`
+	Purpose   string `json:"purpose"`
+	Status    string `json:"status"`
+	// KeyHash is the SHA-256 of the issued key; the key itself is never stored
+	KeyHash string `json:"keyHash,omitempty"`
+	// Prefix is the start of the issued key, shown to tell keys apart
+	Prefix string `json:"prefix,omitempty"`
+	// Plan names the consumer's plan, which sets the priority of its requests under load
+	Plan       string     `json:"plan,omitempty"`
+	ReviewedBy string     `json:"reviewedBy,omitempty"`
+	ReviewedAt *time.Time `json:"reviewedAt,omitempty"`
+	// Reason explains a rejection to the consumer
+	Reason    string    `json:"reason,omitempty"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+// Usable reports whether the key may authenticate requests
+func (k *APIKey) Usable() bool {
+	return k.Status == APIKeyStatusApproved && k.KeyHash != ""
+}
+
+// HashAPIKey returns the hex-encoded SHA-256 under which a key is stored. Keys are
+// random and long, so a fast hash is enough and lets keys be looked up directly.
+func HashAPIKey(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,47 @@
+package entity
+
+import "fmt"
+
+// Authentication methods, recorded in the "amr" claim of the principals the gateway authenticates
+const (
+	AuthMethodOIDC   = "oidc"
+	AuthMethodSAML   = "saml"
+	AuthMethodLDAP   = "ldap"
+	AuthMethodAPIKey = "apikey"
+)
+
+// AuthMethods are the authentication methods services can be restricted to
+var AuthMethods = []string{AuthMethodOIDC, AuthMethodSAML, AuthMethodLDAP, AuthMethodAPIKey}
+
+// AuthenticatedWith reports whether the principal was authenticated with the given method
+func (p *Principal) AuthenticatedWith(method string) bool {
+	return containsString(stringList(p.Claims["amr"]), method)
+}
+
+// AcceptsPrincipal reports whether the service accepts a principal on its protected endpoints:
+// any principal when the service is not restricted to authentication methods, else one
+// authenticated with one of them
+func (s *Service) AcceptsPrincipal(principal *Principal) bool {
+	if len(s.AuthMethods) == 0 {
+		return true
+	}
+	if principal == nil {
+		return false
+	}
+	for _, method := range s.AuthMethods {
+		if principal.AuthenticatedWith(method) {
+			return true
+		}
+	}
+	return false
+}
+
+// validateAuthMethods checks that the methods a service is restricted to are known
+func validateAuthMethods(methods []string) error {
+	for _, method := range methods {
+		if !containsString(AuthMethods, method) {
+			return fmt.Errorf("unknown authentication method %q", method)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,45 @@
+package entity
+
+import "testing"
+
+func TestService_AcceptsPrincipal(t *testing.T) {
+	samlUser := NewPrincipal(map[string]interface{}{"sub": "alice", "amr": []interface{}{"saml"}})
+	apiKeyUser := NewPrincipal(map[string]interface{}{"sub": "ci", "amr": []string{"apikey"}})
+	tokenUser := NewPrincipal(map[string]interface{}{"sub": "bob"})
+
+	tests := []struct {
+		name      string
+		methods   []string
+		principal *Principal
+		accepted  bool
+	}{
+		{"unrestricted", nil, tokenUser, true},
+		{"matching method", []string{AuthMethodSAML}, samlUser, true},
+		{"one of several methods", []string{AuthMethodOIDC, AuthMethodAPIKey}, apiKeyUser, true},
+		{"other method", []string{AuthMethodSAML}, apiKeyUser, false},
+		{"no method recorded", []string{AuthMethodSAML}, tokenUser, false},
+		{"no principal", []string{AuthMethodSAML}, nil, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service := &Service{AuthMethods: tt.methods}
+			if accepted := service.AcceptsPrincipal(tt.principal); accepted != tt.accepted {
+				t.Errorf("Expected accepted %v, got %v", tt.accepted, accepted)
+			}
+		})
+	}
+}
+
+func TestService_ValidateAuthMethods(t *testing.T) {
+	service := NewService("id", "users", "1.0.0", "", "http://users", 30, 3)
+	service.AddEndpoint(Endpoint{Path: "/users", Methods: []string{"GET"}})
+
+	service.AuthMethods = []string{AuthMethodSAML, AuthMethodLDAP}
+	if err := service.Validate(); err != nil {
+		t.Errorf("Expected known methods to be valid, got %v", err)
+	}
+	service.AuthMethods = []string{"kerberos"}
+	if err := service.Validate(); err == nil {
+		t.Error("Expected an unknown method to be invalid")
+	}
+}
@@ -0,0 +1,11 @@
+package entity
+
+// AuthzDecision is the outcome of an external authorization check
+type AuthzDecision struct {
+	Allowed bool
+	// StatusCode and Body are returned to the client when the request is denied
+	StatusCode int
+	Body       []byte
+	// UpstreamHeaders are injected into the request forwarded to the backend when allowed
+	UpstreamHeaders map[string]string
+}
@@ -0,0 +1,91 @@
+package entity
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Message brokers a bridge endpoint can publish to
+const (
+	BrokerKafka    = "kafka"
+	BrokerRabbitMQ = "rabbitmq"
+	BrokerNATS     = "nats"
+)
+
+// Delivery guarantees of a bridge endpoint
+const (
+	// DeliveryAtLeastOnce answers once the broker has acknowledged the message, the default
+	DeliveryAtLeastOnce = "at-least-once"
+	// DeliveryAtMostOnce answers once the message is handed to the broker, without waiting for an acknowledgement
+	DeliveryAtMostOnce = "at-most-once"
+)
+
+// Bridge template scopes, as in "{{header.X-Tenant-ID}}" and "{{query.type}}"
+const (
+	BridgeHeaderScope = "header"
+	BridgeQueryScope  = "query"
+)
+
+// Bridge configures an endpoint that publishes the request body to a message broker and
+// answers 202 Accepted instead of proxying to the service
+type Bridge struct {
+	// Broker is "kafka", "rabbitmq" or "nats"
+	Broker string `json:"broker"`
+	// Topic is the Kafka topic, RabbitMQ routing key or NATS subject. It may contain
+	// placeholders such as "{{header.X-Tenant-ID}}" or "{{query.type}}".
+	Topic string `json:"topic"`
+	// Key is the template of the Kafka message key, which selects the partition
+	Key string `json:"key,omitempty"`
+	// Delivery is "at-least-once" or "at-most-once"; defaults to "at-least-once"
+	Delivery string `json:"delivery,omitempty"`
+}
+
+// DeliveryGuarantee returns the delivery guarantee of the bridge
+func (b *Bridge) DeliveryGuarantee() string {
+	if b.Delivery == "" {
+		return DeliveryAtLeastOnce
+	}
+	return b.Delivery
+}
+
+// Validate validates the bridge configuration
+func (b *Bridge) Validate() error {
+	switch b.Broker {
+	case BrokerKafka, BrokerRabbitMQ, BrokerNATS:
+	default:
+		return fmt.Errorf("invalid bridge broker: %s", b.Broker)
+	}
+
+	if b.Topic == "" {
+		return fmt.Errorf("bridge topic is required")
+	}
+
+	switch b.DeliveryGuarantee() {
+	case DeliveryAtLeastOnce, DeliveryAtMostOnce:
+	default:
+		return fmt.Errorf("invalid bridge delivery guarantee: %s", b.Delivery)
+	}
+
+	// Placeholders may only refer to the caller's headers and query
+	for _, reference := range append(PipelineReferences(b.Topic), PipelineReferences(b.Key)...) {
+		fields := strings.SplitN(reference, ".", 2)
+		if len(fields) != 2 || (fields[0] != BridgeHeaderScope && fields[0] != BridgeQueryScope) {
+			return fmt.Errorf("invalid bridge placeholder %s", reference)
+		}
+	}
+
+	return nil
+}
+
+// Message is a request body published to a message broker by a bridge endpoint
+type Message struct {
+	// ID is the ID of the request that published the message
+	ID    string
+	Topic string
+	Key   string
+	Body  []byte
+	// Headers are sent as Kafka record headers, AMQP headers or NATS headers
+	Headers map[string]string
+	// Delivery is the delivery guarantee the publisher must provide
+	Delivery string
+}
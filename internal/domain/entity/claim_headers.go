@@ -0,0 +1,59 @@
+package entity
+
+import (
+	"strconv"
+	"strings"
+)
+
+// ClaimHeaders maps the names of headers forwarded to backends to the claims of the caller they
+// carry, such as X-User-Id to sub. Backends can trust them, as the gateway removes any value
+// clients send for them.
+type ClaimHeaders map[string]string
+
+// Apply removes the headers from the request and sets them to the claims of the principal, if
+// any. List claims such as roles are joined with commas; claims that are missing, structured
+// or not valid header values are left out.
+func (h ClaimHeaders) Apply(request *Request, principal *Principal) {
+	for name := range request.Headers {
+		for header := range h {
+			if strings.EqualFold(name, header) {
+				delete(request.Headers, name)
+			}
+		}
+	}
+	if principal == nil {
+		return
+	}
+
+	for header, claim := range h {
+		value, ok := claimHeaderValue(principal.Claims[claim])
+		if !ok {
+			continue
+		}
+		if request.Headers == nil {
+			request.Headers = make(map[string][]string)
+		}
+		request.Headers[header] = []string{value}
+	}
+}
+
+// claimHeaderValue formats a claim as a header value
+func claimHeaderValue(claim interface{}) (string, bool) {
+	var value string
+	switch claim := claim.(type) {
+	case string:
+		value = claim
+	case bool:
+		value = strconv.FormatBool(claim)
+	case float64:
+		value = strconv.FormatFloat(claim, 'f', -1, 64)
+	case []string, []interface{}:
+		value = strings.Join(stringList(claim), ",")
+	default:
+		return "", false
+	}
+	if value == "" || strings.ContainsAny(value, "\r\n\x00") {
+		return "", false
+	}
+	return value, true
+}
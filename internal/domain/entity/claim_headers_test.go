@@ -0,0 +1,38 @@
+package entity
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestClaimHeaders_Apply(t *testing.T) {
+	headers := ClaimHeaders{"X-User-Id": "sub", "X-User-Roles": "roles", "X-Tenant-Id": "tenant", "X-Org": "org"}
+	principal := NewPrincipal(map[string]interface{}{
+		"sub":   "alice",
+		"roles": []interface{}{"admin", "viewer"},
+		"org":   map[string]interface{}{"id": "acme"},
+	})
+
+	request := NewRequest("GET", "/orders", map[string][]string{
+		"x-user-id":    {"mallory"},
+		"X-Tenant-Id":  {"other-tenant"},
+		"X-Request-Id": {"req-1"},
+	}, nil, nil, "10.0.0.1")
+	headers.Apply(request, principal)
+
+	expected := map[string][]string{
+		"X-User-Id":    {"alice"},
+		"X-User-Roles": {"admin,viewer"},
+		"X-Request-Id": {"req-1"},
+	}
+	if !reflect.DeepEqual(request.Headers, expected) {
+		t.Errorf("Expected headers %v, got %v", expected, request.Headers)
+	}
+
+	// Anonymous callers only have the client values removed
+	anonymous := NewRequest("GET", "/orders", map[string][]string{"X-User-Id": {"mallory"}}, nil, nil, "10.0.0.1")
+	headers.Apply(anonymous, nil)
+	if len(anonymous.Headers) != 0 {
+		t.Errorf("Expected the client header to be removed, got %v", anonymous.Headers)
+	}
+}
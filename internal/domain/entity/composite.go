@@ -0,0 +1,72 @@
+package entity
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// Composite configures an endpoint that fans a request out to several gateway routes in
+// parallel and merges their JSON responses, instead of proxying it to its own service
+type Composite struct {
+	// Timeout bounds all calls together, in seconds (0 leaves them unbounded)
+	Timeout int             `json:"timeout"`
+	Calls   []CompositeCall `json:"calls"`
+}
+
+// CompositeCall is a single route called by a composite endpoint
+type CompositeCall struct {
+	// Name is the key of the call's response in the merged body
+	Name string `json:"name"`
+	// Method defaults to GET. The request body is only forwarded to calls with other methods.
+	Method string `json:"method,omitempty"`
+	// Path is a gateway route such as "/api/v1/users"
+	Path string `json:"path"`
+	// Optional calls that fail are merged as null instead of failing the request
+	Optional bool `json:"optional"`
+}
+
+// CallMethod returns the HTTP method of the call
+func (c *CompositeCall) CallMethod() string {
+	if c.Method == "" {
+		return http.MethodGet
+	}
+	return c.Method
+}
+
+// TimeoutDuration returns the combined timeout of the calls
+func (c *Composite) TimeoutDuration() time.Duration {
+	return time.Duration(c.Timeout) * time.Second
+}
+
+// Validate validates the composite configuration of the endpoint at path
+func (c *Composite) Validate(path string) error {
+	if c.Timeout < 0 {
+		return fmt.Errorf("composite timeout cannot be negative")
+	}
+
+	if len(c.Calls) == 0 {
+		return fmt.Errorf("composite endpoint requires at least one call")
+	}
+
+	names := make(map[string]bool, len(c.Calls))
+	for _, call := range c.Calls {
+		if call.Name == "" {
+			return fmt.Errorf("composite call name is required")
+		}
+		if names[call.Name] {
+			return fmt.Errorf("duplicate composite call name: %s", call.Name)
+		}
+		names[call.Name] = true
+
+		if !strings.HasPrefix(call.Path, "/") {
+			return fmt.Errorf("composite call path must start with /")
+		}
+		if call.Path == path {
+			return fmt.Errorf("composite call %s cannot call its own endpoint", call.Name)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,40 @@
+package entity
+
+import (
+	"fmt"
+	"mime"
+	"strings"
+)
+
+// AcceptsContentType reports whether the endpoint accepts request bodies of a Content-Type. An
+// endpoint without allowed content types accepts any, and an allowed type ending in /* accepts
+// every subtype, as application/* accepts application/json and */* every type. Parameters such as charset are
+// ignored.
+func (e *Endpoint) AcceptsContentType(contentType string) bool {
+	if len(e.ContentTypes) == 0 {
+		return true
+	}
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return false
+	}
+	for _, allowed := range e.ContentTypes {
+		allowed = strings.ToLower(allowed)
+		if allowed == mediaType || allowed == "*/*" || strings.HasSuffix(allowed, "/*") && strings.HasPrefix(mediaType, strings.TrimSuffix(allowed, "*")) {
+			return true
+		}
+	}
+	return false
+}
+
+// validateContentTypes checks that the allowed content types of an endpoint are media types
+// without parameters
+func validateContentTypes(contentTypes []string) error {
+	for _, contentType := range contentTypes {
+		mediaType, params, err := mime.ParseMediaType(contentType)
+		if err != nil || len(params) > 0 || !strings.Contains(mediaType, "/") {
+			return fmt.Errorf("invalid content type %q", contentType)
+		}
+	}
+	return nil
+}
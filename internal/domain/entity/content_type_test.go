@@ -0,0 +1,44 @@
+package entity
+
+import "testing"
+
+func TestEndpoint_AcceptsContentType(t *testing.T) {
+	endpoint := &Endpoint{ContentTypes: []string{"application/json", "Text/*"}}
+
+	tests := []struct {
+		contentType string
+		accepted    bool
+	}{
+		{"application/json", true},
+		{"Application/JSON; charset=utf-8", true},
+		{"text/plain", true},
+		{"application/xml", false},
+		{"textual/plain", false},
+		{"", false},
+		{"not a media type;", false},
+	}
+	for _, tt := range tests {
+		if accepted := endpoint.AcceptsContentType(tt.contentType); accepted != tt.accepted {
+			t.Errorf("Expected %q accepted %v, got %v", tt.contentType, tt.accepted, accepted)
+		}
+	}
+
+	if !(&Endpoint{}).AcceptsContentType("application/octet-stream") {
+		t.Error("Expected an endpoint without content types to accept any")
+	}
+}
+
+func TestEndpoint_ValidateContentTypes(t *testing.T) {
+	for _, contentTypes := range [][]string{{"application/json"}, {"text/*"}} {
+		endpoint := Endpoint{Path: "/api/v1/orders", Methods: []string{"POST"}, ContentTypes: contentTypes}
+		if err := endpoint.Validate(); err != nil {
+			t.Errorf("Expected %v to be valid, got %v", contentTypes, err)
+		}
+	}
+	for _, contentTypes := range [][]string{{"json"}, {"application/json; charset=utf-8"}, {""}} {
+		endpoint := Endpoint{Path: "/api/v1/orders", Methods: []string{"POST"}, ContentTypes: contentTypes}
+		if err := endpoint.Validate(); err == nil {
+			t.Errorf("Expected %v to be rejected", contentTypes)
+		}
+	}
+}
@@ -0,0 +1,25 @@
+package entity
+
+import "time"
+
+// DrainStatus describes how far a service has switched to its current upstream. Requests in
+// flight on former upstreams, such as those of a previous deployment, complete there while new
+// requests go to the current one.
+type DrainStatus struct {
+	ServiceID string `json:"serviceId"`
+	// Target is the current upstream and InFlight the requests being sent to it
+	Target   string           `json:"target"`
+	InFlight int              `json:"inFlight"`
+	Draining []*UpstreamDrain `json:"draining"`
+	// Drained reports whether no request is left on a former upstream
+	Drained bool `json:"drained"`
+}
+
+// UpstreamDrain is a former upstream of a service with requests still in flight
+type UpstreamDrain struct {
+	Target   string    `json:"target"`
+	InFlight int       `json:"inFlight"`
+	Since    time.Time `json:"since"`
+	// Deadline is when the requests still in flight are aborted, nil when they are left to complete
+	Deadline *time.Time `json:"deadline,omitempty"`
+}
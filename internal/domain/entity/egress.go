@@ -0,0 +1,44 @@
+package entity
+
+import (
+	"net"
+	"strings"
+)
+
+// EgressDestination is an external host that internal clients may reach through the egress
+// listener, with the limits and credentials applied to the requests sent to it
+type EgressDestination struct {
+	// Host is an exact host name, optionally with a port, or a wildcard such as "*.example.com"
+	// matching its subdomains
+	Host string `json:"host"`
+	// Scheme is the scheme of the requests sent to the host, "https" unless set
+	Scheme string `json:"scheme,omitempty"`
+	// RateLimit bounds the requests per minute sent to the host by all clients, 0 is unlimited
+	RateLimit int `json:"rateLimit,omitempty"`
+	// Headers are set on every request to the host, replacing the client's, e.g. an API key
+	Headers map[string]string `json:"headers,omitempty"`
+	// Signing signs the requests to the host with gateway credentials, nil to send them unsigned
+	Signing *UpstreamSigning `json:"signing,omitempty"`
+}
+
+// Matches reports whether the destination allows a host, with or without a port, compared without case
+func (d *EgressDestination) Matches(host string) bool {
+	host = strings.ToLower(host)
+	pattern := strings.ToLower(d.Host)
+	if !strings.Contains(pattern, ":") {
+		// Destinations without a port allow every port of the host
+		if name, _, err := net.SplitHostPort(host); err == nil {
+			host = name
+		}
+	}
+	if suffix, ok := strings.CutPrefix(pattern, "*"); ok {
+		return strings.HasSuffix(host, suffix) && len(host) > len(suffix)
+	}
+	return host == pattern
+}
+
+// InjectsCredentials reports whether requests to the destination carry gateway credentials,
+// which cannot be added to tunneled connections
+func (d *EgressDestination) InjectsCredentials() bool {
+	return len(d.Headers) > 0 || d.Signing != nil
+}
@@ -0,0 +1,28 @@
+package entity
+
+import "testing"
+
+func TestEgressDestination_Matches(t *testing.T) {
+	tests := []struct {
+		pattern string
+		host    string
+		want    bool
+	}{
+		{"api.stripe.com", "api.stripe.com", true},
+		{"api.stripe.com", "API.Stripe.com:443", true},
+		{"api.stripe.com", "stripe.com", false},
+		{"api.stripe.com:8443", "api.stripe.com:443", false},
+		{"api.stripe.com:8443", "api.stripe.com:8443", true},
+		{"*.github.com", "api.github.com", true},
+		{"*.github.com", "uploads.api.github.com:443", true},
+		{"*.github.com", "github.com", false},
+		{"*.github.com", "evilgithub.com", false},
+	}
+
+	for _, tt := range tests {
+		destination := &EgressDestination{Host: tt.pattern}
+		if got := destination.Matches(tt.host); got != tt.want {
+			t.Errorf("%s.Matches(%s) = %v, want %v", tt.pattern, tt.host, got, tt.want)
+		}
+	}
+}
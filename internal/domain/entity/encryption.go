@@ -0,0 +1,37 @@
+package entity
+
+import "fmt"
+
+// PayloadEncryption makes an endpoint exchange encrypted bodies with its clients, as JWE
+// compact serializations, while the service reads and writes plaintext
+type PayloadEncryption struct {
+	// Key names the secret decrypting request bodies: a base64 AES key, or an RSA private key
+	// in PEM for bodies encrypted with its public key
+	Key string `json:"key"`
+	// ResponseKey names the secret responses are encrypted for, such as the client's RSA public
+	// key in PEM; Key encrypts them when empty
+	ResponseKey string `json:"responseKey,omitempty"`
+	// Requests requires request bodies to be encrypted
+	Requests bool `json:"requests"`
+	// Responses encrypts response bodies
+	Responses bool `json:"responses"`
+}
+
+// ResponseKeyName returns the name of the secret responses are encrypted for
+func (e *PayloadEncryption) ResponseKeyName() string {
+	if e.ResponseKey != "" {
+		return e.ResponseKey
+	}
+	return e.Key
+}
+
+// Validate validates the payload encryption
+func (e *PayloadEncryption) Validate() error {
+	if e.Key == "" {
+		return fmt.Errorf("encryption key is required")
+	}
+	if !e.Requests && !e.Responses {
+		return fmt.Errorf("encryption must apply to requests, responses or both")
+	}
+	return nil
+}
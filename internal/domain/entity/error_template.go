@@ -0,0 +1,89 @@
+package entity
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Error template scopes, as in "{{error.status}}", "{{error.type}}" and "{{service.name}}".
+// The request scope offers "{{request.id}}", "{{request.method}}" and "{{request.path}}".
+const (
+	ErrorScope        = "error"
+	ErrorServiceScope = "service"
+)
+
+// errorTemplateStatus matches the statuses a template applies to, such as "404" or "5xx"
+var errorTemplateStatus = regexp.MustCompile(`^[45]([0-9][0-9]|xx)$`)
+
+// ErrorTemplate renders the body of the error responses generated by the gateway, such as 401
+// for a missing token or 502 for an unreachable service. Responses of the services are not affected.
+type ErrorTemplate struct {
+	// Status is the status, such as "404", or the class, such as "5xx", of the errors the
+	// template applies to; empty applies it to every error
+	Status string `json:"status,omitempty"`
+	// ContentType is the content type of the body, e.g. "application/json" or "text/html"
+	ContentType string `json:"contentType"`
+	// Body may contain placeholders such as "{{error.status}}", "{{error.type}}",
+	// "{{error.message}}", "{{request.id}}" or "{{service.name}}"
+	Body string `json:"body"`
+}
+
+// specificity returns how closely the template matches a status: 2 for the status itself,
+// 1 for its class, 0 for a template without status and -1 when it does not apply
+func (t *ErrorTemplate) specificity(status int) int {
+	code := strconv.Itoa(status)
+	switch {
+	case t.Status == "":
+		return 0
+	case t.Status == code:
+		return 2
+	case strings.HasSuffix(t.Status, "xx") && t.Status[0] == code[0]:
+		return 1
+	default:
+		return -1
+	}
+}
+
+// SelectErrorTemplate returns the template applying most closely to a status, nil when none does
+func SelectErrorTemplate(templates []ErrorTemplate, status int) *ErrorTemplate {
+	var selected *ErrorTemplate
+	best := -1
+	for i := range templates {
+		if specificity := templates[i].specificity(status); specificity > best {
+			selected, best = &templates[i], specificity
+		}
+	}
+	return selected
+}
+
+// Validate validates the error template
+func (t *ErrorTemplate) Validate() error {
+	if t.Status != "" && !errorTemplateStatus.MatchString(t.Status) {
+		return fmt.Errorf("invalid error template status %s, expected e.g. 404 or 5xx", t.Status)
+	}
+	if t.ContentType == "" {
+		return fmt.Errorf("error template content type is required")
+	}
+	if t.Body == "" {
+		return fmt.Errorf("error template body is required")
+	}
+
+	for _, reference := range PipelineReferences(t.Body) {
+		scope, field, _ := strings.Cut(reference, ".")
+		var valid bool
+		switch scope {
+		case ErrorScope:
+			valid = field == "status" || field == "type" || field == "title" || field == "message"
+		case MockRequestScope:
+			valid = field == "id" || field == "method" || field == "path"
+		case ErrorServiceScope:
+			valid = field == "name"
+		}
+		if !valid {
+			return fmt.Errorf("invalid error template placeholder %s", reference)
+		}
+	}
+	return nil
+}
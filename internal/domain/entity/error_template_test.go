@@ -0,0 +1,50 @@
+package entity
+
+import "testing"
+
+func TestSelectErrorTemplate(t *testing.T) {
+	templates := []ErrorTemplate{
+		{Status: "5xx", ContentType: "text/html", Body: "class"},
+		{ContentType: "text/html", Body: "default"},
+		{Status: "503", ContentType: "text/html", Body: "status"},
+	}
+
+	tests := []struct {
+		status int
+		want   string
+	}{
+		{503, "status"},
+		{502, "class"},
+		{404, "default"},
+	}
+
+	for _, tt := range tests {
+		if got := SelectErrorTemplate(templates, tt.status); got == nil || got.Body != tt.want {
+			t.Errorf("SelectErrorTemplate(%d) = %v, want %s", tt.status, got, tt.want)
+		}
+	}
+	if got := SelectErrorTemplate(templates[:1], 404); got != nil {
+		t.Errorf("Expected no template for 404, got %v", got)
+	}
+}
+
+func TestErrorTemplate_Validate(t *testing.T) {
+	tests := []struct {
+		template ErrorTemplate
+		valid    bool
+	}{
+		{ErrorTemplate{Status: "404", ContentType: "text/html", Body: "{{error.status}} {{request.id}} {{service.name}}"}, true},
+		{ErrorTemplate{Status: "4xx", ContentType: "application/json", Body: `{"type":"{{error.type}}","error":"{{error.message}}"}`}, true},
+		{ErrorTemplate{Status: "200", ContentType: "text/html", Body: "ok"}, false},
+		{ErrorTemplate{Status: "5XX", ContentType: "text/html", Body: "error"}, false},
+		{ErrorTemplate{ContentType: "text/html"}, false},
+		{ErrorTemplate{Body: "error"}, false},
+		{ErrorTemplate{ContentType: "text/html", Body: "{{header.Authorization}}"}, false},
+	}
+
+	for _, tt := range tests {
+		if err := tt.template.Validate(); (err == nil) != tt.valid {
+			t.Errorf("Validate(%+v) = %v, want valid %v", tt.template, err, tt.valid)
+		}
+	}
+}
@@ -0,0 +1,60 @@
+package entity
+
+import "time"
+
+// Gateway lifecycle event types
+const (
+	EventServiceCreated = "service.created"
+	EventServiceUpdated = "service.updated"
+	EventServiceDeleted = "service.deleted"
+	// EventRoutesReloaded reports that the routes were reloaded from storage after a change made
+	// on another gateway instance
+	EventRoutesReloaded = "routes.reloaded"
+	// EventKeyRotated reports that a new key signs the gateway's tokens
+	EventKeyRotated = "key.rotated"
+
+	// API key provisioning, for consumers' keys requested through the developer portal
+	EventAPIKeyRequested = "apikey.requested"
+	EventAPIKeyApproved  = "apikey.approved"
+	EventAPIKeyRejected  = "apikey.rejected"
+
+	// EventAsyncCompleted is delivered to the callback URL of an async request, not to subscriptions
+	EventAsyncCompleted = "async.completed"
+
+	// EventAll subscribes to every event type
+	EventAll = "*"
+)
+
+// ConfigEvents are the configuration changes propagated to the other gateway instances
+var ConfigEvents = []string{
+	EventServiceCreated,
+	EventServiceUpdated,
+	EventServiceDeleted,
+}
+
+// Event describes a change in the gateway that other subsystems may react to
+type Event struct {
+	ID        string    `json:"id"`
+	Type      string    `json:"type"`
+	Timestamp time.Time `json:"timestamp"`
+	// Actor is the user that caused the event, empty for system events
+	Actor     string `json:"actor,omitempty"`
+	ServiceID string `json:"serviceId,omitempty"`
+	// Service is the state after the change and Previous the state before it
+	Service  *Service               `json:"service,omitempty"`
+	Previous *Service               `json:"previous,omitempty"`
+	Data     map[string]interface{} `json:"data,omitempty"`
+
+	// Remote is set on events received from another gateway instance. Subscribers with side
+	// effects outside the instance, such as webhooks, skip them; the origin instance handles them.
+	Remote bool `json:"-"`
+}
+
+// NewEvent creates an Event of the given type
+func NewEvent(eventType string) *Event {
+	return &Event{
+		ID:        NewRequestID(),
+		Type:      eventType,
+		Timestamp: time.Now(),
+	}
+}
@@ -0,0 +1,134 @@
+package entity
+
+import (
+	"fmt"
+	"hash/fnv"
+	"net/url"
+	"regexp"
+)
+
+// What the callers of an experiment are bucketed by
+const (
+	// ExperimentStickyCookie buckets callers by an ID the gateway keeps in a cookie
+	ExperimentStickyCookie = "cookie"
+	// ExperimentStickyUser buckets authenticated callers by user ID, and anonymous callers by
+	// cookie
+	ExperimentStickyUser = "user"
+)
+
+var (
+	// experimentName matches experiment and variant names such as "checkout-v2"
+	experimentName = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9._-]*$`)
+	// cookieName matches the names a cookie can be given
+	cookieName = regexp.MustCompile("^[A-Za-z0-9!#$%&'*+.^_`|~-]+$")
+)
+
+// Experiment splits the callers of an endpoint between variants served by different upstreams,
+// for A/B tests. Callers are assigned to a variant by a hash of their bucketing ID, so they keep
+// it for as long as the weights are unchanged.
+type Experiment struct {
+	// Name identifies the experiment in exposure logs; renaming it reshuffles the callers
+	Name string `json:"name"`
+	// StickyBy is what callers are bucketed by, ExperimentStickyCookie when empty
+	StickyBy string `json:"stickyBy,omitempty"`
+	// Cookie is the name of the cookie holding the bucketing ID, "exp_" and Name when empty
+	Cookie   string              `json:"cookie,omitempty"`
+	Variants []ExperimentVariant `json:"variants"`
+}
+
+// ExperimentVariant is a variant of an experiment and the share of callers it is served to
+type ExperimentVariant struct {
+	Name string `json:"name"`
+	// Weight is the variant's share of the callers, relative to the weights of the others
+	Weight int `json:"weight"`
+	// BaseURL is the upstream serving the variant, the service's own when empty
+	BaseURL string `json:"baseUrl,omitempty"`
+}
+
+// CookieName returns the name of the cookie holding the bucketing ID of the experiment
+func (e *Experiment) CookieName() string {
+	if e.Cookie == "" {
+		return "exp_" + e.Name
+	}
+	return e.Cookie
+}
+
+// StickyByUser reports whether authenticated callers are bucketed by user ID
+func (e *Experiment) StickyByUser() bool {
+	return e.StickyBy == ExperimentStickyUser
+}
+
+// RoutesUpstream reports whether a variant is served by an upstream other than the service's
+func (e *Experiment) RoutesUpstream() bool {
+	for _, variant := range e.Variants {
+		if variant.BaseURL != "" {
+			return true
+		}
+	}
+	return false
+}
+
+// Assign returns the variant of the caller with a bucketing ID
+func (e *Experiment) Assign(bucketingID string) *ExperimentVariant {
+	total := 0
+	for _, variant := range e.Variants {
+		total += variant.Weight
+	}
+	if total == 0 {
+		return nil
+	}
+
+	hash := fnv.New32a()
+	hash.Write([]byte(e.Name + ":" + bucketingID))
+	bucket := int(hash.Sum32() % uint32(total))
+	for i := range e.Variants {
+		if bucket < e.Variants[i].Weight {
+			return &e.Variants[i]
+		}
+		bucket -= e.Variants[i].Weight
+	}
+	return nil
+}
+
+// Validate validates the experiment configuration
+func (e *Experiment) Validate() error {
+	if !experimentName.MatchString(e.Name) {
+		return fmt.Errorf("invalid experiment name: %q", e.Name)
+	}
+	switch e.StickyBy {
+	case "", ExperimentStickyCookie, ExperimentStickyUser:
+	default:
+		return fmt.Errorf("invalid experiment stickyBy: %s", e.StickyBy)
+	}
+	if !cookieName.MatchString(e.CookieName()) {
+		return fmt.Errorf("invalid experiment cookie name: %q", e.CookieName())
+	}
+
+	if len(e.Variants) < 2 {
+		return fmt.Errorf("experiment %s requires at least two variants", e.Name)
+	}
+	names := make(map[string]bool, len(e.Variants))
+	total := 0
+	for _, variant := range e.Variants {
+		if !experimentName.MatchString(variant.Name) {
+			return fmt.Errorf("invalid variant name in experiment %s: %q", e.Name, variant.Name)
+		}
+		if names[variant.Name] {
+			return fmt.Errorf("duplicate variant %s in experiment %s", variant.Name, e.Name)
+		}
+		names[variant.Name] = true
+		if variant.Weight < 0 {
+			return fmt.Errorf("weight of variant %s must not be negative", variant.Name)
+		}
+		total += variant.Weight
+		if variant.BaseURL != "" {
+			if u, err := url.Parse(variant.BaseURL); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+				return fmt.Errorf("invalid base URL of variant %s: %s", variant.Name, variant.BaseURL)
+			}
+		}
+	}
+	if total == 0 {
+		return fmt.Errorf("experiment %s requires a variant with a positive weight", e.Name)
+	}
+	return nil
+}
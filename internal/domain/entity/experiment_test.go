@@ -0,0 +1,56 @@
+package entity
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestExperiment_Validate(t *testing.T) {
+	variants := []ExperimentVariant{{Name: "control", Weight: 1}, {Name: "treatment", Weight: 1, BaseURL: "http://orders-v2:8080"}}
+	tests := []struct {
+		name       string
+		experiment Experiment
+		wantErr    bool
+	}{
+		{name: "valid", experiment: Experiment{Name: "checkout", Variants: variants}},
+		{name: "missing name", experiment: Experiment{Variants: variants}, wantErr: true},
+		{name: "invalid stickyBy", experiment: Experiment{Name: "checkout", StickyBy: "ip", Variants: variants}, wantErr: true},
+		{name: "invalid cookie", experiment: Experiment{Name: "checkout", Cookie: "exp checkout", Variants: variants}, wantErr: true},
+		{name: "single variant", experiment: Experiment{Name: "checkout", Variants: variants[:1]}, wantErr: true},
+		{name: "duplicate variant", experiment: Experiment{Name: "checkout", Variants: []ExperimentVariant{variants[0], variants[0]}}, wantErr: true},
+		{name: "no weight", experiment: Experiment{Name: "checkout", Variants: []ExperimentVariant{{Name: "a"}, {Name: "b"}}}, wantErr: true},
+		{name: "invalid base URL", experiment: Experiment{Name: "checkout", Variants: []ExperimentVariant{variants[0], {Name: "b", Weight: 1, BaseURL: "orders-v2"}}}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.experiment.Validate(); (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestExperiment_Assign(t *testing.T) {
+	experiment := Experiment{Name: "checkout", Variants: []ExperimentVariant{
+		{Name: "control", Weight: 3},
+		{Name: "treatment", Weight: 1},
+		{Name: "paused", Weight: 0},
+	}}
+
+	assigned := map[string]int{}
+	for i := 0; i < 1000; i++ {
+		id := fmt.Sprintf("client-%d", i)
+		variant := experiment.Assign(id)
+		if variant != experiment.Assign(id) {
+			t.Fatalf("Expected the assignment to be stable for %s", id)
+		}
+		assigned[variant.Name]++
+	}
+	if assigned["paused"] != 0 {
+		t.Errorf("Expected no caller in a variant without weight, got %d", assigned["paused"])
+	}
+	if assigned["treatment"] < 200 || assigned["treatment"] > 300 {
+		t.Errorf("Expected about 25%% of callers in the treatment, got %d of 1000", assigned["treatment"])
+	}
+}
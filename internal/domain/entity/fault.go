@@ -0,0 +1,45 @@
+package entity
+
+import (
+	"fmt"
+	"time"
+)
+
+// Fault describes failures injected into the requests to an endpoint, to test how clients cope
+// with a slow or failing backend. Each fault is drawn independently for every request.
+type Fault struct {
+	ServiceID string `json:"serviceId"`
+	Endpoint  string `json:"endpoint"`
+	// DelayMs delays requests by this many milliseconds with DelayProbability
+	DelayMs          int     `json:"delayMs,omitempty"`
+	DelayProbability float64 `json:"delayProbability,omitempty"`
+	// AbortStatus answers requests with this 5xx status with AbortProbability instead of forwarding them
+	AbortStatus      int     `json:"abortStatus,omitempty"`
+	AbortProbability float64 `json:"abortProbability,omitempty"`
+	// ResetProbability is the probability of closing the client connection without a response
+	ResetProbability float64 `json:"resetProbability,omitempty"`
+}
+
+// Delay returns the injected delay as a duration
+func (f *Fault) Delay() time.Duration {
+	return time.Duration(f.DelayMs) * time.Millisecond
+}
+
+// Validate validates the fault configuration
+func (f *Fault) Validate() error {
+	for _, probability := range []float64{f.DelayProbability, f.AbortProbability, f.ResetProbability} {
+		if probability < 0 || probability > 1 {
+			return fmt.Errorf("fault probabilities must be between 0 and 1")
+		}
+	}
+	if f.DelayProbability > 0 && f.DelayMs <= 0 {
+		return fmt.Errorf("fault delay must be positive")
+	}
+	if f.AbortProbability > 0 && (f.AbortStatus < 500 || f.AbortStatus > 599) {
+		return fmt.Errorf("fault abort status must be a 5xx status")
+	}
+	if f.DelayProbability == 0 && f.AbortProbability == 0 && f.ResetProbability == 0 {
+		return fmt.Errorf("fault must inject a delay, an abort or a connection reset")
+	}
+	return nil
+}
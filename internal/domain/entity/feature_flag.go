@@ -0,0 +1,55 @@
+package entity
+
+import (
+	"fmt"
+	"hash/fnv"
+	"regexp"
+	"time"
+)
+
+// featureFlagKey is the format of feature flag keys, e.g. new-checkout or orders.composite-v2
+var featureFlagKey = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9._-]*$`)
+
+// FeatureFlag turns features of routes on for a segment of their callers, such as a new
+// version of an endpoint only employees see before it is released to everyone
+type FeatureFlag struct {
+	Key         string `json:"key"`
+	Description string `json:"description,omitempty"`
+	// Enabled turns the flag on for its segment; a disabled flag is off for every caller
+	Enabled bool `json:"enabled"`
+	// Segment is an authorization policy expression over the caller, e.g.
+	// `"employee" in roles`, selecting the callers the flag is on for; empty for every caller
+	Segment string `json:"segment,omitempty"`
+	// Percentage rolls the flag out to this share of the segment's callers, chosen by user so
+	// that each caller consistently sees the same, nil for all of them. Anonymous callers are
+	// only included at 100.
+	Percentage *int      `json:"percentage,omitempty"`
+	CreatedAt  time.Time `json:"createdAt"`
+	UpdatedAt  time.Time `json:"updatedAt"`
+}
+
+// Validate validates the feature flag. The segment expression itself is compiled by the use case.
+func (f *FeatureFlag) Validate() error {
+	if !featureFlagKey.MatchString(f.Key) || len(f.Key) > 255 {
+		return fmt.Errorf("invalid feature flag key: %q", f.Key)
+	}
+	if f.Percentage != nil && (*f.Percentage < 0 || *f.Percentage > 100) {
+		return fmt.Errorf("feature flag percentage must be between 0 and 100")
+	}
+	return nil
+}
+
+// RolledOutTo reports whether the rollout of the flag includes a user, empty for anonymous
+// callers. Users are assigned to one of 100 buckets by a hash of the flag and their ID, so that
+// raising the percentage only adds users.
+func (f *FeatureFlag) RolledOutTo(userID string) bool {
+	if f.Percentage == nil || *f.Percentage >= 100 {
+		return true
+	}
+	if userID == "" {
+		return false
+	}
+	hash := fnv.New32a()
+	hash.Write([]byte(f.Key + ":" + userID))
+	return int(hash.Sum32()%100) < *f.Percentage
+}
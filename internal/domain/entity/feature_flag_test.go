@@ -0,0 +1,56 @@
+package entity
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestFeatureFlag_Validate(t *testing.T) {
+	percentage := 101
+	tests := []struct {
+		name    string
+		flag    FeatureFlag
+		wantErr bool
+	}{
+		{name: "valid", flag: FeatureFlag{Key: "new-checkout.v2"}},
+		{name: "missing key", flag: FeatureFlag{}, wantErr: true},
+		{name: "invalid key", flag: FeatureFlag{Key: "new checkout"}, wantErr: true},
+		{name: "percentage out of range", flag: FeatureFlag{Key: "checkout", Percentage: &percentage}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.flag.Validate(); (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestFeatureFlag_RolledOutTo(t *testing.T) {
+	percentage := 30
+	flag := FeatureFlag{Key: "checkout", Percentage: &percentage}
+
+	included := 0
+	for i := 0; i < 1000; i++ {
+		user := fmt.Sprintf("user-%d", i)
+		rolledOut := flag.RolledOutTo(user)
+		if rolledOut != flag.RolledOutTo(user) {
+			t.Fatalf("Expected the rollout to be stable for %s", user)
+		}
+		if rolledOut {
+			included++
+		}
+	}
+	if included < 250 || included > 350 {
+		t.Errorf("Expected about 30%% of users to be included, got %d of 1000", included)
+	}
+	if flag.RolledOutTo("") {
+		t.Error("Expected anonymous callers to be excluded from a partial rollout")
+	}
+
+	flag.Percentage = nil
+	if !flag.RolledOutTo("") {
+		t.Error("Expected everyone to be included without a percentage")
+	}
+}
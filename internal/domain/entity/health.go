@@ -0,0 +1,29 @@
+package entity
+
+import "time"
+
+// Criticality tiers of backing dependencies, by what their failure costs the proxy path
+const (
+	// CriticalityDegradable dependencies, such as Redis, are replaced by a degradation policy
+	// while they are unavailable; the gateway keeps serving, with weaker guarantees
+	CriticalityDegradable = "degradable"
+	// CriticalityOptional dependencies, such as alert channels, only receive what the gateway
+	// exports. Their failures are buffered and retried off the proxy path and do not degrade it.
+	CriticalityOptional = "optional"
+)
+
+// DependencyHealth is a snapshot of the connectivity to a backing dependency such as Redis
+type DependencyHealth struct {
+	Name        string `json:"name"`
+	Criticality string `json:"criticality"`
+	Healthy     bool   `json:"healthy"`
+	// ConsecutiveFailures is the number of failed checks since the last successful one
+	ConsecutiveFailures int   `json:"consecutiveFailures"`
+	Failures            int64 `json:"failures"`
+	// Fallbacks counts operations served by a degradation policy instead of the dependency
+	Fallbacks int64 `json:"fallbacks"`
+	// Dropped counts exports discarded because the buffer of an optional dependency was full
+	Dropped   int64     `json:"dropped"`
+	LastError string    `json:"lastError,omitempty"`
+	LastCheck time.Time `json:"lastCheck"`
+}
@@ -0,0 +1,74 @@
+package entity
+
+import (
+	"fmt"
+
+	"api-gateway-sample/pkg/jsonpath"
+)
+
+// Masking strategies of response fields
+const (
+	// MaskRedact replaces the value with "****"
+	MaskRedact = "redact"
+	// MaskPartial keeps the last four characters of the value, as in "*******6789"
+	MaskPartial = "partial"
+	// MaskEmail keeps the first character of the local part and the domain, as in "a***@example.com"
+	MaskEmail = "email"
+	// MaskHash replaces the value with a hash of it, so that masked values can still be correlated
+	MaskHash = "hash"
+	// MaskRemove removes the field
+	MaskRemove = "remove"
+)
+
+// ResponseMasking masks personal data in the JSON responses of an endpoint, for callers that
+// were not granted the scope to see it
+type ResponseMasking struct {
+	// Scope lets callers granted it see responses unmasked; responses are masked for every
+	// caller when empty
+	Scope string `json:"scope,omitempty"`
+	// Rules are the fields to mask
+	Rules []MaskingRule `json:"rules"`
+}
+
+// MaskingRule masks the response fields selected by a JSONPath expression
+type MaskingRule struct {
+	// Path selects the fields, e.g. "$.email", "$.customers[*].ssn" or "$..cardNumber"
+	Path string `json:"path"`
+	// Strategy is one of redact, partial, email, hash or remove; defaults to redact
+	Strategy string `json:"strategy,omitempty"`
+}
+
+// MaskingStrategy returns the strategy of the rule
+func (r MaskingRule) MaskingStrategy() string {
+	if r.Strategy == "" {
+		return MaskRedact
+	}
+	return r.Strategy
+}
+
+// Unmasked reports whether a caller sees responses unmasked
+func (m *ResponseMasking) Unmasked(principal *Principal) bool {
+	return m.Scope != "" && principal != nil && principal.HasScope(m.Scope)
+}
+
+// Validate validates the response masking
+func (m *ResponseMasking) Validate() error {
+	if len(m.Rules) == 0 {
+		return fmt.Errorf("masking requires at least one rule")
+	}
+	for i, rule := range m.Rules {
+		path, err := jsonpath.Parse(rule.Path)
+		if err != nil {
+			return fmt.Errorf("masking rule %d: %w", i, err)
+		}
+		if path.Root() {
+			return fmt.Errorf("masking rule %d must select fields of the response, not the response itself", i)
+		}
+		switch rule.MaskingStrategy() {
+		case MaskRedact, MaskPartial, MaskEmail, MaskHash, MaskRemove:
+		default:
+			return fmt.Errorf("masking rule %d: invalid strategy %s", i, rule.Strategy)
+		}
+	}
+	return nil
+}
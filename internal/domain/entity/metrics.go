@@ -0,0 +1,161 @@
+package entity
+
+import "time"
+
+// RequestSample describes the outcome of a single proxied request for metrics collection
+type RequestSample struct {
+	ServiceID string
+	// Endpoint is the path of the endpoint the request matched
+	Endpoint    string
+	StatusCode  int
+	Latency     time.Duration
+	CacheStatus string
+	RateLimited bool
+	// Target is the upstream the request was sent to, empty if it never left the gateway
+	Target          string
+	UpstreamLatency time.Duration
+	UpstreamFailed  bool
+	// Timings break down the upstream request, nil when it was not measured
+	Timings *UpstreamTimings
+	// SLO is the objective of the endpoint the request matched, nil when it declares none
+	SLO           *SLO
+	RequestBytes  int
+	ResponseBytes int
+	// Tags are the tags of the route the request matched
+	Tags map[string]string
+}
+
+// IsError reports whether the request failed with a server error
+func (s *RequestSample) IsError() bool {
+	return s.StatusCode >= 500
+}
+
+// ServiceStats is a snapshot of a service's traffic over a rolling window
+type ServiceStats struct {
+	ServiceID           string        `json:"serviceId"`
+	Window              time.Duration `json:"-"`
+	WindowSeconds       float64       `json:"windowSeconds"`
+	Requests            int64         `json:"requests"`
+	RequestRate         float64       `json:"requestRate"`
+	Errors              int64         `json:"errors"`
+	ErrorRate           float64       `json:"errorRate"`
+	LatencyP50Ms        float64       `json:"latencyP50Ms"`
+	LatencyP95Ms        float64       `json:"latencyP95Ms"`
+	LatencyP99Ms        float64       `json:"latencyP99Ms"`
+	CacheHits           int64         `json:"cacheHits"`
+	CacheHitRatio       float64       `json:"cacheHitRatio"`
+	RateLimitRejections int64         `json:"rateLimitRejections"`
+}
+
+// EndpointCacheStats counts the response cache lookups of one endpoint since startup
+type EndpointCacheStats struct {
+	ServiceID string `json:"serviceId"`
+	Endpoint  string `json:"endpoint"`
+	Hits      int64  `json:"hits"`
+	Misses    int64  `json:"misses"`
+	// Stale counts stale responses the upstream replaced, and Revalidated those it confirmed
+	Stale       int64   `json:"stale"`
+	Revalidated int64   `json:"revalidated"`
+	HitRatio    float64 `json:"hitRatio"`
+	// Tags are the tags of the endpoint's latest request
+	Tags map[string]string `json:"tags,omitempty"`
+}
+
+// RouteRequests counts the requests served by one endpoint since startup, by status class
+type RouteRequests struct {
+	ServiceID string
+	Endpoint  string
+	// Tags are the tags of the endpoint's latest request
+	Tags map[string]string
+	// Classes maps a status class, such as 2xx, to the number of requests answered with it
+	Classes map[string]int64
+}
+
+// CacheStats is a snapshot of the response cache
+type CacheStats struct {
+	// Entries and MemoryBytes describe the cache backend, which reports them when SizeAvailable
+	SizeAvailable bool                  `json:"sizeAvailable"`
+	Entries       int64                 `json:"entries"`
+	MemoryBytes   int64                 `json:"memoryBytes"`
+	Endpoints     []*EndpointCacheStats `json:"endpoints"`
+}
+
+// ErrorCount counts the errors the gateway answered with a problem type
+type ErrorCount struct {
+	// Code is the machine-readable code of the problem type, such as rate-limit-exceeded
+	Code      string `json:"code"`
+	Status    int    `json:"status"`
+	Retryable bool   `json:"retryable"`
+	Count     int64  `json:"count"`
+}
+
+// UpstreamTimings break an upstream request down into the phases of its connection. Requests
+// on a reused keep-alive connection spend no time resolving, connecting or in a TLS handshake.
+type UpstreamTimings struct {
+	DNS     time.Duration
+	Connect time.Duration
+	TLS     time.Duration
+	// FirstByte is the time from the start of the request, connection included, to the first
+	// byte of the response
+	FirstByte time.Duration
+	Reused    bool
+}
+
+// Connection phases of UpstreamTimings
+const (
+	ConnectionPhaseDNS       = "dns"
+	ConnectionPhaseConnect   = "connect"
+	ConnectionPhaseTLS       = "tls"
+	ConnectionPhaseFirstByte = "first-byte"
+)
+
+// ConnectionPhases lists the connection phases in the order they happen
+var ConnectionPhases = []string{ConnectionPhaseDNS, ConnectionPhaseConnect, ConnectionPhaseTLS, ConnectionPhaseFirstByte}
+
+// Phases returns the duration of every phase the request went through, leaving out those a
+// reused connection or a cached address skipped
+func (t *UpstreamTimings) Phases() map[string]time.Duration {
+	phases := make(map[string]time.Duration, len(ConnectionPhases))
+	for phase, duration := range map[string]time.Duration{
+		ConnectionPhaseDNS:       t.DNS,
+		ConnectionPhaseConnect:   t.Connect,
+		ConnectionPhaseTLS:       t.TLS,
+		ConnectionPhaseFirstByte: t.FirstByte,
+	} {
+		if duration > 0 {
+			phases[phase] = duration
+		}
+	}
+	return phases
+}
+
+// UpstreamConnectionStats summarizes the upstream connections of one service since startup
+type UpstreamConnectionStats struct {
+	ServiceID string
+	// Phases maps a connection phase, such as dns, to its latency histogram
+	Phases map[string]*PhaseLatency
+	// Reused counts the requests sent on a keep-alive connection and Opened those that opened one
+	Reused int64
+	Opened int64
+}
+
+// PhaseLatency is the cumulative latency histogram of one connection phase, in the
+// Prometheus histogram convention of TargetLatency
+type PhaseLatency struct {
+	BucketsMs []float64
+	Counts    []int64
+	Count     int64
+	SumMs     float64
+}
+
+// TargetLatency is the cumulative upstream latency histogram of one upstream target
+type TargetLatency struct {
+	Target string
+	// BucketsMs are the histogram upper bounds and Counts the cumulative observations
+	// at or below each bound, in the Prometheus histogram convention
+	BucketsMs []float64
+	Counts    []int64
+	Count     int64
+	SumMs     float64
+	Failures  int64
+}
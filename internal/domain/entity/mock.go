@@ -0,0 +1,100 @@
+package entity
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// MockRequestScope is the template scope of the request itself, as in "{{request.id}}",
+// "{{request.method}}" and "{{request.path}}"
+const MockRequestScope = "request"
+
+// Mock configures an endpoint that answers from fixtures instead of proxying to the service,
+// so clients can be developed before the service exists
+type Mock struct {
+	// Fixtures are tried in order and the first one matching the request is served
+	Fixtures []MockFixture `json:"fixtures"`
+}
+
+// MockFixture is a response served to the requests it matches
+type MockFixture struct {
+	Match    MockMatch    `json:"match"`
+	Response MockResponse `json:"response"`
+}
+
+// MockMatch selects requests by method, query parameters and headers. Empty fields match any request.
+type MockMatch struct {
+	Method  string            `json:"method,omitempty"`
+	Query   map[string]string `json:"query,omitempty"`
+	Headers map[string]string `json:"headers,omitempty"`
+}
+
+// MockResponse is the response of a fixture. Header values and the body may contain placeholders
+// such as "{{query.id}}", "{{header.X-Tenant-ID}}" or "{{request.id}}".
+type MockResponse struct {
+	// Status defaults to 200
+	Status  int               `json:"status,omitempty"`
+	Headers map[string]string `json:"headers,omitempty"`
+	Body    string            `json:"body,omitempty"`
+}
+
+// StatusCode returns the status of the response
+func (r *MockResponse) StatusCode() int {
+	if r.Status == 0 {
+		return http.StatusOK
+	}
+	return r.Status
+}
+
+// Matches reports whether a request matches the fixture
+func (m *MockMatch) Matches(request *Request) bool {
+	if m.Method != "" && !strings.EqualFold(m.Method, request.Method) {
+		return false
+	}
+	for name, value := range m.Query {
+		values := request.QueryParams[name]
+		if len(values) == 0 || values[0] != value {
+			return false
+		}
+	}
+	for name, value := range m.Headers {
+		if http.Header(request.Headers).Get(name) != value {
+			return false
+		}
+	}
+	return true
+}
+
+// Validate validates the mock configuration
+func (m *Mock) Validate() error {
+	if len(m.Fixtures) == 0 {
+		return fmt.Errorf("mock requires at least one fixture")
+	}
+
+	for i, fixture := range m.Fixtures {
+		if status := fixture.Response.Status; status != 0 && (status < 100 || status > 599) {
+			return fmt.Errorf("mock fixture %d has an invalid status %d", i, status)
+		}
+
+		templates := []string{fixture.Response.Body}
+		for _, value := range fixture.Response.Headers {
+			templates = append(templates, value)
+		}
+		// Placeholders may only refer to the request
+		for _, template := range templates {
+			for _, reference := range PipelineReferences(template) {
+				scope, field, _ := strings.Cut(reference, ".")
+				valid := field != "" && (scope == BridgeHeaderScope || scope == BridgeQueryScope)
+				if scope == MockRequestScope {
+					valid = field == "id" || field == "method" || field == "path"
+				}
+				if !valid {
+					return fmt.Errorf("invalid mock placeholder %s", reference)
+				}
+			}
+		}
+	}
+
+	return nil
+}
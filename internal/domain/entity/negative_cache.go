@@ -0,0 +1,51 @@
+package entity
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// NegativeCache caches error responses of an endpoint for a short time, so that clients
+// repeatedly requesting missing resources do not reach the service every time
+type NegativeCache struct {
+	// TTL is how long an error response is served from the cache, in seconds
+	TTL int `json:"ttl"`
+	// Statuses are the error statuses cached, 404 when empty
+	Statuses []int `json:"statuses,omitempty"`
+}
+
+// Duration returns how long an error response is served from the cache
+func (n *NegativeCache) Duration() time.Duration {
+	return time.Duration(n.TTL) * time.Second
+}
+
+// Caches reports whether responses with the status are cached. It is safe to call on a nil
+// configuration, which caches nothing.
+func (n *NegativeCache) Caches(status int) bool {
+	if n == nil {
+		return false
+	}
+	if len(n.Statuses) == 0 {
+		return status == http.StatusNotFound
+	}
+	for _, cached := range n.Statuses {
+		if cached == status {
+			return true
+		}
+	}
+	return false
+}
+
+// Validate validates the negative cache configuration
+func (n *NegativeCache) Validate() error {
+	if n.TTL <= 0 {
+		return fmt.Errorf("negative cache ttl must be positive")
+	}
+	for _, status := range n.Statuses {
+		if status < 400 || status > 599 {
+			return fmt.Errorf("negative cache status %d is not an error status", status)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,41 @@
+package entity
+
+import (
+	"fmt"
+	"net/mail"
+	"regexp"
+)
+
+// slackChannelName matches Slack channel names such as "payments-oncall"
+var slackChannelName = regexp.MustCompile(`^[a-z0-9][a-z0-9_-]*$`)
+
+// ValidSlackChannel reports whether name is a Slack channel name, given without "#"
+func ValidSlackChannel(name string) bool {
+	return slackChannelName.MatchString(name)
+}
+
+// Owner is the team that owns a service and is notified of its alerts
+type Owner struct {
+	Team string `json:"team"`
+	// Email receives the alerts of the service, empty when they are not emailed
+	Email string `json:"email,omitempty"`
+	// Slack is the Slack channel, without "#", receiving the alerts of the service, empty when
+	// they are not posted to Slack
+	Slack string `json:"slack,omitempty"`
+}
+
+// Validate validates the owner
+func (o *Owner) Validate() error {
+	if o.Team == "" {
+		return fmt.Errorf("owner team is required")
+	}
+	if o.Email != "" {
+		if _, err := mail.ParseAddress(o.Email); err != nil {
+			return fmt.Errorf("invalid owner email: %s", o.Email)
+		}
+	}
+	if o.Slack != "" && !ValidSlackChannel(o.Slack) {
+		return fmt.Errorf("invalid owner Slack channel: %s", o.Slack)
+	}
+	return nil
+}
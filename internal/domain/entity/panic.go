@@ -0,0 +1,49 @@
+package entity
+
+import (
+	"strconv"
+	"strings"
+	"time"
+)
+
+// PanicReport describes a panic recovered while serving a request
+type PanicReport struct {
+	// Value is the value passed to panic, formatted, and Type its Go type
+	Value string
+	Type  string
+	// Stack lists the frames of the panicking goroutine, innermost first
+	Stack []StackFrame
+	Time  time.Time
+
+	RequestID  string
+	TraceID    string
+	Method     string
+	Path       string
+	RemoteAddr string
+	UserAgent  string
+	// Service and Endpoint name the route being served, empty when the request matched none
+	Service  string
+	Endpoint string
+}
+
+// StackFrame is a function call on a goroutine stack
+type StackFrame struct {
+	// Function is the package-qualified function name, such as api-gateway-sample/internal/x.(*T).M
+	Function string
+	File     string
+	Line     int
+}
+
+// StackTrace formats the stack as runtime/debug.Stack does, a function and its location per frame
+func (r *PanicReport) StackTrace() string {
+	var b strings.Builder
+	for _, frame := range r.Stack {
+		b.WriteString(frame.Function)
+		b.WriteString("\n\t")
+		b.WriteString(frame.File)
+		b.WriteByte(':')
+		b.WriteString(strconv.Itoa(frame.Line))
+		b.WriteByte('\n')
+	}
+	return b.String()
+}
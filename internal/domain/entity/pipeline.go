@@ -0,0 +1,153 @@
+package entity
+
+import (
+	"fmt"
+	"net/http"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// Pipeline step error policies
+const (
+	// PipelineOnErrorFail fails the request, the default
+	PipelineOnErrorFail = "fail"
+	// PipelineOnErrorContinue records the step's output as null and runs the next steps
+	PipelineOnErrorContinue = "continue"
+	// PipelineOnErrorStop returns the outputs of the steps run so far
+	PipelineOnErrorStop = "stop"
+)
+
+// PipelineQueryScope is the template scope of the caller's query parameters, as in "{{query.id}}"
+const PipelineQueryScope = "query"
+
+// pipelineTemplate matches a "{{scope.field}}" placeholder
+var pipelineTemplate = regexp.MustCompile(`\{\{\s*([^{}\s]+)\s*\}\}`)
+
+// Pipeline configures an endpoint that calls gateway routes one after the other, passing values
+// from the JSON responses of earlier steps into the path and query of later ones
+type Pipeline struct {
+	// Timeout bounds all steps together, in seconds (0 leaves them unbounded)
+	Timeout int            `json:"timeout"`
+	Steps   []PipelineStep `json:"steps"`
+}
+
+// PipelineStep is a single route called by a pipeline endpoint. Path and query values may
+// contain placeholders such as "{{user.id}}", which take the "id" field of the response of the
+// step named "user", or "{{query.id}}", which takes a query parameter of the caller.
+type PipelineStep struct {
+	// Name is the key of the step's response in the merged body and in placeholders
+	Name string `json:"name"`
+	// Method defaults to GET. The request body is only forwarded to steps with other methods.
+	Method string `json:"method,omitempty"`
+	// Path is a gateway route such as "/api/v1/orders"
+	Path  string            `json:"path"`
+	Query map[string]string `json:"query,omitempty"`
+	// OnError is "fail", "continue" or "stop"; defaults to "fail"
+	OnError string `json:"onError,omitempty"`
+}
+
+// StepMethod returns the HTTP method of the step
+func (s *PipelineStep) StepMethod() string {
+	if s.Method == "" {
+		return http.MethodGet
+	}
+	return s.Method
+}
+
+// ErrorPolicy returns what happens when the step fails
+func (s *PipelineStep) ErrorPolicy() string {
+	if s.OnError == "" {
+		return PipelineOnErrorFail
+	}
+	return s.OnError
+}
+
+// TimeoutDuration returns the combined timeout of the steps
+func (p *Pipeline) TimeoutDuration() time.Duration {
+	return time.Duration(p.Timeout) * time.Second
+}
+
+// Validate validates the pipeline configuration of the endpoint at path
+func (p *Pipeline) Validate(path string) error {
+	if p.Timeout < 0 {
+		return fmt.Errorf("pipeline timeout cannot be negative")
+	}
+
+	if len(p.Steps) == 0 {
+		return fmt.Errorf("pipeline endpoint requires at least one step")
+	}
+
+	names := make(map[string]bool, len(p.Steps))
+	for _, step := range p.Steps {
+		if step.Name == "" {
+			return fmt.Errorf("pipeline step name is required")
+		}
+		if step.Name == PipelineQueryScope || strings.Contains(step.Name, ".") {
+			return fmt.Errorf("invalid pipeline step name: %s", step.Name)
+		}
+		if names[step.Name] {
+			return fmt.Errorf("duplicate pipeline step name: %s", step.Name)
+		}
+
+		if !strings.HasPrefix(step.Path, "/") {
+			return fmt.Errorf("pipeline step path must start with /")
+		}
+		if step.Path == path {
+			return fmt.Errorf("pipeline step %s cannot call its own endpoint", step.Name)
+		}
+
+		switch step.ErrorPolicy() {
+		case PipelineOnErrorFail, PipelineOnErrorContinue, PipelineOnErrorStop:
+		default:
+			return fmt.Errorf("invalid pipeline step error policy: %s", step.OnError)
+		}
+
+		// Placeholders may only refer to the caller's query and to earlier steps
+		templates := []string{step.Path}
+		for _, value := range step.Query {
+			templates = append(templates, value)
+		}
+		for _, template := range templates {
+			for _, reference := range PipelineReferences(template) {
+				scope := strings.SplitN(reference, ".", 2)[0]
+				if scope != PipelineQueryScope && !names[scope] {
+					return fmt.Errorf("pipeline step %s refers to unknown step %s", step.Name, scope)
+				}
+			}
+		}
+
+		names[step.Name] = true
+	}
+
+	return nil
+}
+
+// PipelineReferences returns the placeholders of a template, such as "user.id" for "{{user.id}}"
+func PipelineReferences(template string) []string {
+	var references []string
+	for _, match := range pipelineTemplate.FindAllStringSubmatch(template, -1) {
+		references = append(references, match[1])
+	}
+	return references
+}
+
+// ExpandPipelineTemplate replaces every placeholder of a template with the value resolve returns for it
+func ExpandPipelineTemplate(template string, resolve func(reference string) (string, error)) (string, error) {
+	var expandErr error
+	expanded := pipelineTemplate.ReplaceAllStringFunc(template, func(placeholder string) string {
+		if expandErr != nil {
+			return ""
+		}
+		value, err := resolve(pipelineTemplate.FindStringSubmatch(placeholder)[1])
+		if err != nil {
+			expandErr = err
+			return ""
+		}
+		return value
+	})
+	if expandErr != nil {
+		return "", expandErr
+	}
+	return expanded, nil
+}
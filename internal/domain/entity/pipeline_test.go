@@ -0,0 +1,50 @@
+package entity
+
+import (
+	"testing"
+)
+
+func FuzzExpandPipelineTemplate(f *testing.F) {
+	for _, seed := range []string{
+		"/users/{{query.id}}/orders",
+		"{{user.address.city}}{{query.id}}",
+		"{{}}",
+		"{{ query.id }}",
+		"{{{{query.id}}}}",
+		"{{query.id",
+		"query.id}}",
+		"<h1>{{error.status}}</h1>",
+		"{{\xff}}",
+	} {
+		f.Add(seed)
+	}
+
+	f.Fuzz(func(t *testing.T, template string) {
+		references := PipelineReferences(template)
+
+		// Every placeholder is resolved once, in order
+		var resolved []string
+		_, err := ExpandPipelineTemplate(template, func(reference string) (string, error) {
+			resolved = append(resolved, reference)
+			return "value", nil
+		})
+		if err != nil {
+			t.Fatalf("Failed to expand %q: %v", template, err)
+		}
+		if len(resolved) != len(references) {
+			t.Fatalf("Expected %d placeholders to be resolved in %q, got %d", len(references), template, len(resolved))
+		}
+		for i, reference := range references {
+			if resolved[i] != reference {
+				t.Fatalf("Expected placeholder %q, got %q", reference, resolved[i])
+			}
+		}
+
+		if len(references) == 0 {
+			expanded, _ := ExpandPipelineTemplate(template, nil)
+			if expanded != template {
+				t.Fatalf("Expected %q without placeholders to be unchanged, got %q", template, expanded)
+			}
+		}
+	})
+}
@@ -0,0 +1,48 @@
+package entity
+
+// PolicyInput is the data an authorization policy is evaluated against
+type PolicyInput struct {
+	UserID   string                 `json:"user"`
+	Roles    []string               `json:"roles"`
+	Scopes   []string               `json:"scopes"`
+	Claims   map[string]interface{} `json:"claims"`
+	Method   string                 `json:"method"`
+	Path     string                 `json:"path"`
+	Headers  map[string]string      `json:"headers"`
+	Service  string                 `json:"service"`
+	Endpoint string                 `json:"endpoint"`
+}
+
+// NewPolicyInput builds the policy input for a request made by a principal
+func NewPolicyInput(principal *Principal, request *Request, service *Service, endpoint *Endpoint) *PolicyInput {
+	headers := make(map[string]string, len(request.Headers))
+	for key, values := range request.Headers {
+		if len(values) > 0 {
+			headers[key] = values[0]
+		}
+	}
+
+	input := &PolicyInput{
+		Method:   request.Method,
+		Path:     request.Path,
+		Headers:  headers,
+		Service:  service.Name,
+		Endpoint: endpoint.Path,
+		Roles:    []string{},
+		Scopes:   []string{},
+		Claims:   map[string]interface{}{},
+	}
+	if principal != nil {
+		input.UserID = principal.UserID
+		if principal.Roles != nil {
+			input.Roles = principal.Roles
+		}
+		if principal.Scopes != nil {
+			input.Scopes = principal.Scopes
+		}
+		if principal.Claims != nil {
+			input.Claims = principal.Claims
+		}
+	}
+	return input
+}
@@ -0,0 +1,101 @@
+package entity
+
+import (
+	"context"
+	"strings"
+)
+
+// Principal represents the authenticated caller of a request
+type Principal struct {
+	UserID string
+	Roles  []string
+	Scopes []string
+	Claims map[string]interface{}
+}
+
+// NewPrincipal creates a Principal from validated token claims
+func NewPrincipal(claims map[string]interface{}) *Principal {
+	principal := &Principal{
+		Roles:  stringList(claims["roles"]),
+		Scopes: scopesFromClaims(claims),
+		Claims: claims,
+	}
+	if sub, ok := claims["sub"].(string); ok {
+		principal.UserID = sub
+	}
+	return principal
+}
+
+// HasRole reports whether the principal has the given role
+func (p *Principal) HasRole(role string) bool {
+	return containsString(p.Roles, role)
+}
+
+// HasScope reports whether the principal was granted the given scope, itself or through a
+// wildcard scope such as orders:*
+func (p *Principal) HasScope(scope string) bool {
+	for _, granted := range p.Scopes {
+		if scopeGrants(granted, scope) {
+			return true
+		}
+	}
+	return false
+}
+
+// Claim returns a raw token claim
+func (p *Principal) Claim(name string) (interface{}, bool) {
+	value, ok := p.Claims[name]
+	return value, ok
+}
+
+// scopesFromClaims reads OAuth scopes from the space-delimited "scope" claim
+// or the array-valued "scp"/"scopes" claims
+func scopesFromClaims(claims map[string]interface{}) []string {
+	if scope, ok := claims["scope"].(string); ok {
+		return strings.Fields(scope)
+	}
+	if scopes := stringList(claims["scp"]); len(scopes) > 0 {
+		return scopes
+	}
+	return stringList(claims["scopes"])
+}
+
+// stringList converts a decoded JSON array claim to a string slice
+func stringList(value interface{}) []string {
+	switch v := value.(type) {
+	case []string:
+		return v
+	case []interface{}:
+		list := make([]string, 0, len(v))
+		for _, item := range v {
+			if s, ok := item.(string); ok {
+				list = append(list, s)
+			}
+		}
+		return list
+	default:
+		return nil
+	}
+}
+
+func containsString(list []string, value string) bool {
+	for _, item := range list {
+		if item == value {
+			return true
+		}
+	}
+	return false
+}
+
+type principalContextKey struct{}
+
+// ContextWithPrincipal returns a context carrying the authenticated principal
+func ContextWithPrincipal(ctx context.Context, principal *Principal) context.Context {
+	return context.WithValue(ctx, principalContextKey{}, principal)
+}
+
+// PrincipalFromContext returns the authenticated principal, if any
+func PrincipalFromContext(ctx context.Context) (*Principal, bool) {
+	principal, ok := ctx.Value(principalContextKey{}).(*Principal)
+	return principal, ok && principal != nil
+}
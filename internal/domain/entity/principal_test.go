@@ -0,0 +1,61 @@
+package entity
+
+import (
+	"context"
+	"testing"
+)
+
+func TestNewPrincipal(t *testing.T) {
+	claims := map[string]interface{}{
+		"sub":   "user123",
+		"roles": []interface{}{"admin", "users-service:/api/v1/users"},
+		"scope": "read:users write:users",
+		"tier":  "gold",
+	}
+
+	principal := NewPrincipal(claims)
+
+	if principal.UserID != "user123" {
+		t.Errorf("Expected user ID %s, got %s", "user123", principal.UserID)
+	}
+	if !principal.HasRole("admin") {
+		t.Error("Principal should have the admin role")
+	}
+	if principal.HasRole("viewer") {
+		t.Error("Principal should not have the viewer role")
+	}
+	if !principal.HasScope("write:users") {
+		t.Error("Principal should have the write:users scope")
+	}
+	if tier, ok := principal.Claim("tier"); !ok || tier != "gold" {
+		t.Errorf("Expected tier claim %s, got %v", "gold", tier)
+	}
+}
+
+func TestNewPrincipal_ArrayScopes(t *testing.T) {
+	principal := NewPrincipal(map[string]interface{}{
+		"sub": "user123",
+		"scp": []interface{}{"read:orders"},
+	})
+
+	if !principal.HasScope("read:orders") {
+		t.Error("Principal should have the read:orders scope")
+	}
+	if len(principal.Roles) != 0 {
+		t.Errorf("Expected no roles, got %v", principal.Roles)
+	}
+}
+
+func TestPrincipalFromContext(t *testing.T) {
+	if _, ok := PrincipalFromContext(context.Background()); ok {
+		t.Error("Empty context should not carry a principal")
+	}
+
+	principal := &Principal{UserID: "user123"}
+	ctx := ContextWithPrincipal(context.Background(), principal)
+
+	got, ok := PrincipalFromContext(ctx)
+	if !ok || got != principal {
+		t.Error("Context should carry the principal")
+	}
+}
@@ -0,0 +1,22 @@
+package entity
+
+// Request priority classes, from the first to be served under load to the first to be shed
+const (
+	PriorityHigh   = "high"
+	PriorityNormal = "normal"
+	PriorityLow    = "low"
+)
+
+// priorityRanks orders the priority classes, higher ranks are served first
+var priorityRanks = map[string]int{
+	PriorityLow:    0,
+	PriorityNormal: 1,
+	PriorityHigh:   2,
+}
+
+// PriorityRank returns the rank of a priority class, higher ranks are served first.
+// It reports false for unknown classes.
+func PriorityRank(class string) (int, bool) {
+	rank, ok := priorityRanks[class]
+	return rank, ok
+}
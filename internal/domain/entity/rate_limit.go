@@ -0,0 +1,65 @@
+package entity
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// RateLimitStatus is the quota a client has left on an endpoint in the current window
+type RateLimitStatus struct {
+	// Limit is the requests allowed per window
+	Limit int
+	// Remaining is the requests left in the window
+	Remaining int
+	// Reset is how long until the window ends and the quota is restored
+	Reset time.Duration
+}
+
+// RateLimitInfo records the rate limit status of a request, for the handler that reports it
+// in the response headers. All methods are safe to call on a nil RateLimitInfo, which records
+// nothing.
+type RateLimitInfo struct {
+	mu     sync.Mutex
+	status RateLimitStatus
+	set    bool
+}
+
+// NewRateLimitInfo creates a new RateLimitInfo instance
+func NewRateLimitInfo() *RateLimitInfo {
+	return &RateLimitInfo{}
+}
+
+// Set records the status of the limit the request was counted against
+func (r *RateLimitInfo) Set(status RateLimitStatus) {
+	if r == nil {
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.status = status
+	r.set = true
+}
+
+// Status returns the recorded status, false when the request was not rate limited
+func (r *RateLimitInfo) Status() (RateLimitStatus, bool) {
+	if r == nil {
+		return RateLimitStatus{}, false
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.status, r.set
+}
+
+type rateLimitContextKey struct{}
+
+// ContextWithRateLimit returns a context carrying the rate limit info
+func ContextWithRateLimit(ctx context.Context, info *RateLimitInfo) context.Context {
+	return context.WithValue(ctx, rateLimitContextKey{}, info)
+}
+
+// RateLimitFromContext returns the rate limit info carried by the context, or nil when none is
+func RateLimitFromContext(ctx context.Context) *RateLimitInfo {
+	info, _ := ctx.Value(rateLimitContextKey{}).(*RateLimitInfo)
+	return info
+}
@@ -0,0 +1,77 @@
+package entity
+
+import (
+	"fmt"
+	"time"
+)
+
+// RateLimitOverride replaces the limits of a service's endpoints for one consumer, such as a
+// partner allowed more requests than the endpoints' defaults
+type RateLimitOverride struct {
+	ID string `json:"id"`
+	// Consumer is the authenticated caller the override applies to: the ID of an API key or the
+	// subject of a token
+	Consumer  string `json:"consumer"`
+	ServiceID string `json:"serviceId"`
+	// Endpoint is the path of the endpoint the override applies to, empty for every endpoint of
+	// the service
+	Endpoint string `json:"endpoint,omitempty"`
+	// RateLimit replaces the requests per minute of the endpoints, nil to keep theirs and 0 for
+	// no limit
+	RateLimit *int `json:"rateLimit,omitempty"`
+	// MaxConcurrent replaces the requests the consumer may have in flight, nil to keep the
+	// endpoints' and 0 for no limit
+	MaxConcurrent *int      `json:"maxConcurrent,omitempty"`
+	CreatedAt     time.Time `json:"createdAt"`
+	UpdatedAt     time.Time `json:"updatedAt"`
+}
+
+// Validate validates the override
+func (o *RateLimitOverride) Validate() error {
+	if o.Consumer == "" {
+		return fmt.Errorf("consumer is required")
+	}
+	if o.ServiceID == "" {
+		return fmt.Errorf("service ID is required")
+	}
+	if o.RateLimit == nil && o.MaxConcurrent == nil {
+		return fmt.Errorf("rateLimit or maxConcurrent is required")
+	}
+	if o.RateLimit != nil && *o.RateLimit < 0 {
+		return fmt.Errorf("rate limit must not be negative")
+	}
+	if o.MaxConcurrent != nil && *o.MaxConcurrent < 0 {
+		return fmt.Errorf("max concurrent requests must not be negative")
+	}
+	return nil
+}
+
+// Apply returns a copy of the endpoint with the override's limits
+func (o *RateLimitOverride) Apply(endpoint *Endpoint) *Endpoint {
+	limited := *endpoint
+	if o.RateLimit != nil {
+		limited.RateLimit = *o.RateLimit
+	}
+	if o.MaxConcurrent != nil {
+		limited.MaxConcurrent = *o.MaxConcurrent
+	}
+	return &limited
+}
+
+// SelectRateLimitOverride returns the override among a consumer's that applies to an endpoint
+// of a service: the one for the endpoint over the one for the whole service, nil if none does
+func SelectRateLimitOverride(overrides []*RateLimitOverride, serviceID string, endpoint string) *RateLimitOverride {
+	var selected *RateLimitOverride
+	for _, override := range overrides {
+		if override.ServiceID != serviceID {
+			continue
+		}
+		if override.Endpoint == endpoint {
+			return override
+		}
+		if override.Endpoint == "" {
+			selected = override
+		}
+	}
+	return selected
+}
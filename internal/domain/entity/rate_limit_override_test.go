@@ -0,0 +1,46 @@
+package entity
+
+import "testing"
+
+func TestSelectRateLimitOverride(t *testing.T) {
+	serviceWide := &RateLimitOverride{ID: "service", ServiceID: "orders"}
+	orders := &RateLimitOverride{ID: "orders", ServiceID: "orders", Endpoint: "/orders"}
+	other := &RateLimitOverride{ID: "other", ServiceID: "users", Endpoint: "/carts"}
+	overrides := []*RateLimitOverride{serviceWide, orders, other}
+
+	tests := []struct {
+		service  string
+		endpoint string
+		want     *RateLimitOverride
+	}{
+		{"orders", "/orders", orders},
+		{"orders", "/carts", serviceWide},
+		{"users", "/users", nil},
+	}
+	for _, tt := range tests {
+		if got := SelectRateLimitOverride(overrides, tt.service, tt.endpoint); got != tt.want {
+			t.Errorf("Expected override %v for %s %s, got %v", tt.want, tt.service, tt.endpoint, got)
+		}
+	}
+}
+
+func TestRateLimitOverride_Apply(t *testing.T) {
+	unlimited := 0
+	override := &RateLimitOverride{Consumer: "partner", ServiceID: "orders", RateLimit: &unlimited}
+	if err := override.Validate(); err != nil {
+		t.Fatalf("Expected override to be valid, got %v", err)
+	}
+
+	endpoint := &Endpoint{Path: "/orders", RateLimit: 10, MaxConcurrent: 2}
+	limited := override.Apply(endpoint)
+	if limited.RateLimit != 0 || limited.MaxConcurrent != 2 {
+		t.Errorf("Expected the rate limit lifted and the concurrency kept, got %d and %d", limited.RateLimit, limited.MaxConcurrent)
+	}
+	if endpoint.RateLimit != 10 {
+		t.Errorf("Expected the endpoint to be left unchanged, got %d", endpoint.RateLimit)
+	}
+
+	if err := (&RateLimitOverride{Consumer: "partner", ServiceID: "orders"}).Validate(); err == nil {
+		t.Error("Expected an override without limits to be invalid")
+	}
+}
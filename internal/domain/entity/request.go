@@ -1,7 +1,9 @@
 package entity
 
 import (
+	"io"
 	"math/rand"
+	"sync"
 	"time"
 )
 
@@ -18,6 +20,19 @@ type Request struct {
 	Authenticated bool
 	UserID        string
 	Timeout       time.Duration
+	// Region is the caller's region, located for services with data residency
+	Region string
+	// Variant is the experiment variant the caller is assigned to, for endpoints running an
+	// experiment
+	Variant string
+	// Host and Scheme are those the client addressed the gateway with, such as
+	// api.example.com and https
+	Host   string
+	Scheme string
+	// BodyStream is the body of an upload streamed to the service as it is read, in place of Body
+	BodyStream io.Reader
+	// ResponseLimit bounds the response buffered from the service, nil for no bound
+	ResponseLimit *ResponseLimit
 }
 
 // NewRequest creates a new Request instance
@@ -54,18 +69,32 @@ func (r *Request) SetTimeout(timeout time.Duration) {
 	r.Timeout = timeout
 }
 
-// generateRequestID generates a unique request ID
-func generateRequestID() string {
-	return time.Now().Format("20060102150405") + "-" + randomString(8)
+// NewRequestID generates a unique request ID
+func NewRequestID() string {
+	return generateRequestID()
 }
 
-// randomString generates a random string of specified length
-func randomString(length int) string {
-	const charset = "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789"
-	result := make([]byte, length)
-	r := rand.New(rand.NewSource(time.Now().UnixNano()))
-	for i := range result {
-		result[i] = charset[r.Intn(len(charset))]
+// requestIDCharset holds the characters of the random part of request IDs
+const requestIDCharset = "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789"
+
+// requestIDRands holds the random sources of request IDs. Sources are not safe for concurrent
+// use and costly to seed, so each is reused by one request at a time.
+var requestIDRands = sync.Pool{
+	New: func() interface{} {
+		return rand.New(rand.NewSource(time.Now().UnixNano()))
+	},
+}
+
+// generateRequestID generates a unique request ID: the time to the second followed by 8 random
+// characters, built in a single allocation
+func generateRequestID() string {
+	var id [len("20060102150405-") + 8]byte
+	buf := time.Now().AppendFormat(id[:0], "20060102150405")
+	buf = append(buf, '-')
+	r := requestIDRands.Get().(*rand.Rand)
+	for len(buf) < len(id) {
+		buf = append(buf, requestIDCharset[r.Intn(len(requestIDCharset))])
 	}
-	return string(result)
+	requestIDRands.Put(r)
+	return string(buf)
 }
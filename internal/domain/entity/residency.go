@@ -0,0 +1,102 @@
+package entity
+
+import (
+	"fmt"
+	"net/url"
+	"regexp"
+)
+
+// regionName matches region names such as "eu" or "us-east"
+var regionName = regexp.MustCompile(`^[a-z0-9][a-z0-9-]*$`)
+
+// Residency routes the requests to a service to its upstream in the caller's region, for
+// services whose data must stay in the region it belongs to
+type Residency struct {
+	// Targets are the upstreams of the service by region, tried in order within a region
+	Targets []RegionalTarget `json:"targets"`
+	// DefaultRegion serves callers whose region is unknown or has no target. Such callers are
+	// rejected when it is empty.
+	DefaultRegion string `json:"defaultRegion,omitempty"`
+	// Failover lists, by region, the regions whose targets serve its callers, in order, when its
+	// own targets fail. Regions not listed never fail over, so their data stays in them.
+	Failover map[string][]string `json:"failover,omitempty"`
+}
+
+// RegionalTarget is an upstream of a service in a region
+type RegionalTarget struct {
+	Region  string `json:"region"`
+	BaseURL string `json:"baseUrl"`
+}
+
+// Serving returns the region serving the callers of a region: the region itself when it has
+// targets, otherwise the default region, empty when they are not served
+func (r *Residency) Serving(region string) string {
+	if r.serves(region) {
+		return region
+	}
+	return r.DefaultRegion
+}
+
+// Route returns the targets serving a caller's region, in the order they are tried: the targets
+// of the serving region, then those of its failover regions
+func (r *Residency) Route(region string) []RegionalTarget {
+	region = r.Serving(region)
+	if region == "" {
+		return nil
+	}
+
+	var targets []RegionalTarget
+	for _, serving := range append([]string{region}, r.Failover[region]...) {
+		for _, target := range r.Targets {
+			if target.Region == serving {
+				targets = append(targets, target)
+			}
+		}
+	}
+	return targets
+}
+
+// Regions returns the regions that have targets
+func (r *Residency) Regions() []string {
+	var regions []string
+	for _, target := range r.Targets {
+		if !containsString(regions, target.Region) {
+			regions = append(regions, target.Region)
+		}
+	}
+	return regions
+}
+
+// serves reports whether a region has targets
+func (r *Residency) serves(region string) bool {
+	return region != "" && containsString(r.Regions(), region)
+}
+
+// Validate validates the residency configuration
+func (r *Residency) Validate() error {
+	if len(r.Targets) == 0 {
+		return fmt.Errorf("residency requires at least one regional target")
+	}
+	for i, target := range r.Targets {
+		if !regionName.MatchString(target.Region) {
+			return fmt.Errorf("residency target %d: invalid region %q", i, target.Region)
+		}
+		if parsed, err := url.Parse(target.BaseURL); err != nil || parsed.Scheme == "" || parsed.Host == "" {
+			return fmt.Errorf("residency target %d: invalid base URL %q", i, target.BaseURL)
+		}
+	}
+	if r.DefaultRegion != "" && !r.serves(r.DefaultRegion) {
+		return fmt.Errorf("residency default region %s has no target", r.DefaultRegion)
+	}
+	for region, failover := range r.Failover {
+		if !r.serves(region) {
+			return fmt.Errorf("residency failover of region %s, which has no target", region)
+		}
+		for _, to := range failover {
+			if to == region || !r.serves(to) {
+				return fmt.Errorf("residency failover of region %s to %s, which has no other target", region, to)
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,86 @@
+package entity
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestResidency_Route(t *testing.T) {
+	residency := &Residency{
+		Targets: []RegionalTarget{
+			{Region: "eu", BaseURL: "http://orders.eu-west-1.internal"},
+			{Region: "eu", BaseURL: "http://orders.eu-central-1.internal"},
+			{Region: "us", BaseURL: "http://orders.us-east-1.internal"},
+			{Region: "ca", BaseURL: "http://orders.ca-central-1.internal"},
+		},
+		DefaultRegion: "us",
+		Failover:      map[string][]string{"ca": {"us"}},
+	}
+	if err := residency.Validate(); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	tests := []struct {
+		region string
+		want   []string
+	}{
+		{"eu", []string{"http://orders.eu-west-1.internal", "http://orders.eu-central-1.internal"}},
+		{"ca", []string{"http://orders.ca-central-1.internal", "http://orders.us-east-1.internal"}},
+		{"", []string{"http://orders.us-east-1.internal"}},
+		{"apac", []string{"http://orders.us-east-1.internal"}},
+	}
+	for _, tt := range tests {
+		var got []string
+		for _, target := range residency.Route(tt.region) {
+			got = append(got, target.BaseURL)
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("Route(%q) = %v, want %v", tt.region, got, tt.want)
+		}
+	}
+
+	// Without a default region, callers of other regions are not served
+	residency.DefaultRegion = ""
+	if targets := residency.Route("apac"); len(targets) != 0 {
+		t.Errorf("Expected no target for apac, got %v", targets)
+	}
+}
+
+func TestResidency_Validate(t *testing.T) {
+	tests := []struct {
+		name      string
+		residency Residency
+		wantErr   bool
+	}{
+		{
+			name:      "invalid region",
+			residency: Residency{Targets: []RegionalTarget{{Region: "EU West", BaseURL: "http://orders.eu"}}},
+			wantErr:   true,
+		},
+		{
+			name:      "relative base URL",
+			residency: Residency{Targets: []RegionalTarget{{Region: "eu", BaseURL: "/orders"}}},
+			wantErr:   true,
+		},
+		{
+			name:      "default region without target",
+			residency: Residency{Targets: []RegionalTarget{{Region: "eu", BaseURL: "http://orders.eu"}}, DefaultRegion: "us"},
+			wantErr:   true,
+		},
+		{
+			name: "failover to the same region",
+			residency: Residency{
+				Targets:  []RegionalTarget{{Region: "eu", BaseURL: "http://orders.eu"}},
+				Failover: map[string][]string{"eu": {"eu"}},
+			},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.residency.Validate(); (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
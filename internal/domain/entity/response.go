@@ -1,6 +1,7 @@
 package entity
 
 import (
+	"io"
 	"time"
 )
 
@@ -15,6 +16,11 @@ type Response struct {
 	Timestamp     time.Time
 	LatencyMs     int64
 	CachedResult  bool
+	// Timings break down the upstream request down to the connection, nil when not measured
+	Timings *UpstreamTimings `json:"-"`
+	// BodyStream is the rest of a body too large to buffer, to be sent after Body and closed,
+	// nil when Body is complete
+	BodyStream io.ReadCloser `json:"-"`
 }
 
 // NewResponse creates a new Response instance
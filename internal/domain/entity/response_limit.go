@@ -0,0 +1,38 @@
+package entity
+
+import "fmt"
+
+// Policies for upstream responses larger than the limit of their endpoint
+const (
+	// ResponseLimitReject answers oversized responses with 502
+	ResponseLimitReject = "reject"
+	// ResponseLimitStream streams oversized responses to the client instead of buffering them
+	ResponseLimitStream = "stream"
+)
+
+// ResponseLimit bounds the upstream responses of an endpoint the gateway buffers, so that a
+// misbehaving backend cannot exhaust its memory
+type ResponseLimit struct {
+	// MaxSize is the size in bytes of the largest response body buffered
+	MaxSize int64 `json:"maxSize"`
+	// Policy is what happens to larger responses, ResponseLimitReject when empty
+	Policy string `json:"policy,omitempty"`
+}
+
+// Streams reports whether oversized responses are streamed rather than rejected
+func (l *ResponseLimit) Streams() bool {
+	return l.Policy == ResponseLimitStream
+}
+
+// Validate validates the response limit configuration
+func (l *ResponseLimit) Validate() error {
+	if l.MaxSize <= 0 {
+		return fmt.Errorf("response limit max size must be positive")
+	}
+	switch l.Policy {
+	case "", ResponseLimitReject, ResponseLimitStream:
+		return nil
+	default:
+		return fmt.Errorf("invalid response limit policy: %s", l.Policy)
+	}
+}
@@ -0,0 +1,119 @@
+package entity
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// Actions a route policy takes on the requests to its routes while its window is open
+const (
+	// RoutePolicyDisable answers the requests as if the route did not exist
+	RoutePolicyDisable = "disable"
+	// RoutePolicyMaintenance answers the requests with a maintenance response instead of
+	// forwarding them
+	RoutePolicyMaintenance = "maintenance"
+	// RoutePolicyRateLimit replaces the limits of the endpoints
+	RoutePolicyRateLimit = "rateLimit"
+)
+
+// RoutePolicy changes how the endpoints of a service are served during recurring windows, such
+// as a weekly maintenance or tighter limits during business hours
+type RoutePolicy struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+	// ServiceID is the service the policy applies to
+	ServiceID string `json:"serviceId"`
+	// Endpoint is the path of the endpoint the policy applies to, empty for every endpoint of
+	// the service
+	Endpoint string `json:"endpoint,omitempty"`
+	// Schedule is a five-field cron expression for when the window opens, such as
+	// "0 22 * * 5" for Fridays at 22:00
+	Schedule string `json:"schedule"`
+	// Duration is how long the window stays open, in minutes
+	Duration int `json:"duration"`
+	// Timezone is the IANA time zone the schedule is in, UTC when empty
+	Timezone string `json:"timezone,omitempty"`
+	// Action is what the policy does while its window is open: "disable", "maintenance" or
+	// "rateLimit"
+	Action string `json:"action"`
+	// Status is the status of maintenance responses, 503 when 0
+	Status int `json:"status,omitempty"`
+	// Body is the body of maintenance responses, a problem details document when empty
+	Body string `json:"body,omitempty"`
+	// ContentType is the media type of Body, application/json when empty
+	ContentType string `json:"contentType,omitempty"`
+	// RateLimit replaces the requests per minute of the endpoints during the window, nil to
+	// keep theirs and 0 for no limit
+	RateLimit *int `json:"rateLimit,omitempty"`
+	// MaxConcurrent replaces the requests a client may have in flight during the window, nil
+	// to keep the endpoints' and 0 for no limit
+	MaxConcurrent *int      `json:"maxConcurrent,omitempty"`
+	Enabled       bool      `json:"enabled"`
+	CreatedAt     time.Time `json:"createdAt"`
+	UpdatedAt     time.Time `json:"updatedAt"`
+}
+
+// WindowDuration returns how long the window of the policy stays open
+func (p *RoutePolicy) WindowDuration() time.Duration {
+	return time.Duration(p.Duration) * time.Minute
+}
+
+// MaintenanceStatus returns the status of the policy's maintenance responses
+func (p *RoutePolicy) MaintenanceStatus() int {
+	if p.Status == 0 {
+		return http.StatusServiceUnavailable
+	}
+	return p.Status
+}
+
+// Validate validates the route policy. The schedule expression itself is parsed by the use case.
+func (p *RoutePolicy) Validate() error {
+	if p.ServiceID == "" {
+		return fmt.Errorf("service ID is required")
+	}
+	if p.Schedule == "" {
+		return fmt.Errorf("policy schedule is required")
+	}
+	if p.Duration <= 0 {
+		return fmt.Errorf("policy duration must be positive")
+	}
+
+	switch p.Action {
+	case RoutePolicyDisable:
+	case RoutePolicyMaintenance:
+		if p.Status != 0 && (p.Status < 400 || p.Status > 599) {
+			return fmt.Errorf("maintenance status must be a 4xx or 5xx status")
+		}
+	case RoutePolicyRateLimit:
+		if p.RateLimit == nil && p.MaxConcurrent == nil {
+			return fmt.Errorf("rateLimit or maxConcurrent is required")
+		}
+		if p.RateLimit != nil && *p.RateLimit < 0 {
+			return fmt.Errorf("rate limit must not be negative")
+		}
+		if p.MaxConcurrent != nil && *p.MaxConcurrent < 0 {
+			return fmt.Errorf("max concurrent requests must not be negative")
+		}
+	default:
+		return fmt.Errorf("invalid policy action: %s", p.Action)
+	}
+	return nil
+}
+
+// AppliesTo reports whether the policy applies to an endpoint of a service
+func (p *RoutePolicy) AppliesTo(serviceID string, endpoint string) bool {
+	return p.Enabled && p.ServiceID == serviceID && (p.Endpoint == "" || p.Endpoint == endpoint)
+}
+
+// Apply returns a copy of the endpoint with the policy's limits
+func (p *RoutePolicy) Apply(endpoint *Endpoint) *Endpoint {
+	limited := *endpoint
+	if p.RateLimit != nil {
+		limited.RateLimit = *p.RateLimit
+	}
+	if p.MaxConcurrent != nil {
+		limited.MaxConcurrent = *p.MaxConcurrent
+	}
+	return &limited
+}
@@ -0,0 +1,50 @@
+package entity
+
+import (
+	"net/http"
+	"testing"
+)
+
+func TestRoutePolicy_Validate(t *testing.T) {
+	limit := 10
+	negative := -1
+	tests := []struct {
+		name    string
+		policy  RoutePolicy
+		wantErr bool
+	}{
+		{"maintenance", RoutePolicy{ServiceID: "orders", Schedule: "0 2 * * 0", Duration: 60, Action: RoutePolicyMaintenance}, false},
+		{"disable", RoutePolicy{ServiceID: "orders", Schedule: "0 2 * * 0", Duration: 60, Action: RoutePolicyDisable}, false},
+		{"rate limit", RoutePolicy{ServiceID: "orders", Schedule: "0 9 * * 1-5", Duration: 480, Action: RoutePolicyRateLimit, RateLimit: &limit}, false},
+		{"missing service", RoutePolicy{Schedule: "0 2 * * 0", Duration: 60, Action: RoutePolicyDisable}, true},
+		{"missing schedule", RoutePolicy{ServiceID: "orders", Duration: 60, Action: RoutePolicyDisable}, true},
+		{"no duration", RoutePolicy{ServiceID: "orders", Schedule: "0 2 * * 0", Action: RoutePolicyDisable}, true},
+		{"unknown action", RoutePolicy{ServiceID: "orders", Schedule: "0 2 * * 0", Duration: 60, Action: "redirect"}, true},
+		{"success status", RoutePolicy{ServiceID: "orders", Schedule: "0 2 * * 0", Duration: 60, Action: RoutePolicyMaintenance, Status: 200}, true},
+		{"no limits", RoutePolicy{ServiceID: "orders", Schedule: "0 2 * * 0", Duration: 60, Action: RoutePolicyRateLimit}, true},
+		{"negative limit", RoutePolicy{ServiceID: "orders", Schedule: "0 2 * * 0", Duration: 60, Action: RoutePolicyRateLimit, RateLimit: &negative}, true},
+	}
+	for _, tt := range tests {
+		if err := tt.policy.Validate(); (err != nil) != tt.wantErr {
+			t.Errorf("%s: expected error %v, got %v", tt.name, tt.wantErr, err)
+		}
+	}
+}
+
+func TestRoutePolicy_AppliesTo(t *testing.T) {
+	serviceWide := &RoutePolicy{ServiceID: "orders", Enabled: true}
+	carts := &RoutePolicy{ServiceID: "orders", Endpoint: "/carts", Enabled: true}
+	if !serviceWide.AppliesTo("orders", "/orders") || !carts.AppliesTo("orders", "/carts") {
+		t.Error("Expected the policies to apply to their endpoints")
+	}
+	if carts.AppliesTo("orders", "/orders") || serviceWide.AppliesTo("users", "/orders") {
+		t.Error("Expected the policies not to apply to other endpoints")
+	}
+	carts.Enabled = false
+	if carts.AppliesTo("orders", "/carts") {
+		t.Error("Expected a disabled policy not to apply")
+	}
+	if status := (&RoutePolicy{}).MaintenanceStatus(); status != http.StatusServiceUnavailable {
+		t.Errorf("Expected maintenance to default to 503, got %d", status)
+	}
+}
@@ -0,0 +1,76 @@
+package entity
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Triggers of a scheduled job run
+const (
+	JobTriggerSchedule = "schedule"
+	JobTriggerManual   = "manual"
+)
+
+// ScheduledJob periodically calls a gateway route, for example to warm a backend, prime the
+// response cache or generate a report
+type ScheduledJob struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+	// Schedule is a five-field cron expression such as "*/5 * * * *", or a descriptor such
+	// as "@hourly" or "@every 10m"
+	Schedule string `json:"schedule"`
+	// Method and Path select the gateway route that is called
+	Method  string            `json:"method"`
+	Path    string            `json:"path"`
+	Headers map[string]string `json:"headers,omitempty"`
+	Body    string            `json:"body,omitempty"`
+	// Timeout of a run in seconds; 0 uses the scheduler's default
+	Timeout   int       `json:"timeout"`
+	Enabled   bool      `json:"enabled"`
+	CreatedAt time.Time `json:"createdAt"`
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
+// TimeoutDuration returns the timeout of a run, zero when the job has none
+func (j *ScheduledJob) TimeoutDuration() time.Duration {
+	return time.Duration(j.Timeout) * time.Second
+}
+
+// Validate validates the scheduled job. The schedule expression itself is parsed by the scheduler.
+func (j *ScheduledJob) Validate() error {
+	if j.Name == "" {
+		return fmt.Errorf("job name is required")
+	}
+
+	if j.Schedule == "" {
+		return fmt.Errorf("job schedule is required")
+	}
+
+	switch j.Method {
+	case "GET", "POST", "PUT", "DELETE", "PATCH", "HEAD", "OPTIONS":
+	default:
+		return fmt.Errorf("invalid HTTP method: %s", j.Method)
+	}
+
+	if !strings.HasPrefix(j.Path, "/") {
+		return fmt.Errorf("job path must start with /")
+	}
+
+	if j.Timeout < 0 {
+		return fmt.Errorf("timeout cannot be negative")
+	}
+	return nil
+}
+
+// JobRun is the outcome of a single run of a scheduled job
+type JobRun struct {
+	JobID     string    `json:"jobId"`
+	Trigger   string    `json:"trigger"`
+	StartedAt time.Time `json:"startedAt"`
+	// Duration of the run in milliseconds
+	Duration   int64  `json:"duration"`
+	StatusCode int    `json:"statusCode,omitempty"`
+	Success    bool   `json:"success"`
+	Error      string `json:"error,omitempty"`
+}
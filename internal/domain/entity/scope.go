@@ -0,0 +1,47 @@
+package entity
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ScopeWildcard ends a scope granting every scope below it, as orders:* grants orders:read
+// and orders:items:write
+const ScopeWildcard = "*"
+
+// scopeGrants reports whether a granted scope grants the required one, either as the same
+// scope or as a wildcard over its prefix
+func scopeGrants(granted string, required string) bool {
+	if granted == required {
+		return true
+	}
+	if !strings.HasSuffix(granted, ":"+ScopeWildcard) {
+		return false
+	}
+	return strings.HasPrefix(required, strings.TrimSuffix(granted, ScopeWildcard))
+}
+
+// MissingScopes returns the required scopes of the endpoint the principal was not granted,
+// none when it may call the endpoint
+func (e *Endpoint) MissingScopes(principal *Principal) []string {
+	var missing []string
+	for _, scope := range e.RequiredScopes {
+		if principal == nil || !principal.HasScope(scope) {
+			missing = append(missing, scope)
+		}
+	}
+	return missing
+}
+
+// validateRequiredScopes checks that the required scopes of an endpoint are single scope tokens
+func validateRequiredScopes(scopes []string) error {
+	for _, scope := range scopes {
+		if scope == "" || strings.ContainsAny(scope, " \t\r\n\"\\") {
+			return fmt.Errorf("invalid required scope %q", scope)
+		}
+		if strings.Contains(scope, ScopeWildcard) {
+			return fmt.Errorf("required scope %q cannot contain a wildcard", scope)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,65 @@
+package entity
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestPrincipal_HasScopeWildcard(t *testing.T) {
+	principal := NewPrincipal(map[string]interface{}{"sub": "partner", "scope": "orders:* customers:read *"})
+
+	tests := []struct {
+		scope   string
+		granted bool
+	}{
+		{"orders:read", true},
+		{"orders:items:write", true},
+		{"orders", false},
+		{"ordersarchive:read", false},
+		{"customers:read", true},
+		{"customers:write", false},
+		// A bare wildcard is an ordinary scope name
+		{"invoices:read", false},
+	}
+	for _, tt := range tests {
+		if granted := principal.HasScope(tt.scope); granted != tt.granted {
+			t.Errorf("Expected %s granted %v, got %v", tt.scope, tt.granted, granted)
+		}
+	}
+}
+
+func TestEndpoint_MissingScopes(t *testing.T) {
+	endpoint := &Endpoint{RequiredScopes: []string{"orders:read", "orders:write"}}
+
+	reader := NewPrincipal(map[string]interface{}{"sub": "reader", "scp": []interface{}{"orders:read"}})
+	if missing := endpoint.MissingScopes(reader); !reflect.DeepEqual(missing, []string{"orders:write"}) {
+		t.Errorf("Expected orders:write to be missing, got %v", missing)
+	}
+	admin := NewPrincipal(map[string]interface{}{"sub": "admin", "scope": "orders:*"})
+	if missing := endpoint.MissingScopes(admin); len(missing) != 0 {
+		t.Errorf("Expected no missing scope, got %v", missing)
+	}
+	if missing := endpoint.MissingScopes(nil); len(missing) != 2 {
+		t.Errorf("Expected every scope to be missing without a principal, got %v", missing)
+	}
+}
+
+func TestEndpoint_ValidateRequiredScopes(t *testing.T) {
+	tests := []struct {
+		name     string
+		endpoint Endpoint
+		valid    bool
+	}{
+		{"scopes", Endpoint{Path: "/orders", Methods: []string{"GET"}, AuthRequired: true, RequiredScopes: []string{"orders:read"}}, true},
+		{"without authentication", Endpoint{Path: "/orders", Methods: []string{"GET"}, RequiredScopes: []string{"orders:read"}}, false},
+		{"wildcard", Endpoint{Path: "/orders", Methods: []string{"GET"}, AuthRequired: true, RequiredScopes: []string{"orders:*"}}, false},
+		{"several scopes in one", Endpoint{Path: "/orders", Methods: []string{"GET"}, AuthRequired: true, RequiredScopes: []string{"orders:read orders:write"}}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.endpoint.Validate(); (err == nil) != tt.valid {
+				t.Errorf("Expected valid %v, got %v", tt.valid, err)
+			}
+		})
+	}
+}
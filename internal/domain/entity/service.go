@@ -3,9 +3,19 @@ package entity
 import (
 	"fmt"
 	"net/url"
+	"regexp"
 	"strings"
+	"time"
 )
 
+// networkName matches the names of upstream networks, which the gateway configuration keys in
+// lower case
+var networkName = regexp.MustCompile(`^[a-z0-9][a-z0-9_-]*$`)
+
+// UnixScheme is the scheme of base URLs naming the unix domain socket of an upstream on the
+// same host, such as a sidecar
+const UnixScheme = "unix"
+
 // Service represents a backend service that can be accessed through the API Gateway
 type Service struct {
 	ID          string            `json:"id"`
@@ -16,8 +26,37 @@ type Service struct {
 	Timeout     int               `json:"timeout"`
 	RetryCount  int               `json:"retryCount"`
 	IsActive    bool              `json:"isActive"`
+	Published   bool              `json:"published"` // listed in the developer portal
 	Metadata    map[string]string `json:"metadata"`
 	Endpoints   []Endpoint        `json:"endpoints"`
+	// Signing signs the requests sent to the service, nil when they are sent unsigned
+	Signing *UpstreamSigning `json:"signing,omitempty"`
+	// ErrorTemplates render the errors the gateway generates for the service's routes, before the global ones
+	ErrorTemplates []ErrorTemplate `json:"errorTemplates,omitempty"`
+	// Residency routes requests to the upstream in the caller's region instead of BaseURL, nil when disabled
+	Residency *Residency `json:"residency,omitempty"`
+	// Tags label the metrics, access logs and traces of the service's requests, e.g. team or tier
+	Tags map[string]string `json:"tags,omitempty"`
+	// Owner is the team notified of the service's alerts, nil when the service is unowned
+	Owner *Owner `json:"owner,omitempty"`
+	// AuthMethods restricts the service's protected endpoints to principals authenticated with
+	// one of these methods, e.g. saml, empty to accept any
+	AuthMethods []string `json:"authMethods,omitempty"`
+	// Network names the upstream network in the gateway configuration the service is reached
+	// through, such as one behind a proxy or with its own resolvers, empty for the default network
+	Network string `json:"network,omitempty"`
+	// UpstreamPath prefixes the path of the requests sent to a unix:///path/to.sock base URL,
+	// whose own path is that of the socket
+	UpstreamPath string `json:"upstreamPath,omitempty"`
+	// ValidFrom and ValidUntil bound when the service is served, nil for no bound. It is
+	// archived once ValidUntil has passed.
+	ValidFrom  *time.Time `json:"validFrom,omitempty"`
+	ValidUntil *time.Time `json:"validUntil,omitempty"`
+	// Revision is incremented on every update for optimistic concurrency control
+	Revision int64 `json:"revision"`
+	// External marks the third-party hosts reached through egress, which are not told about the
+	// gateway's clients by forwarded headers nor sent their request IDs
+	External bool `json:"-"`
 }
 
 // Endpoint represents a service endpoint configuration
@@ -25,11 +64,16 @@ type Endpoint struct {
 	Path           string   `json:"path"`
 	Methods        []string `json:"methods"`
 	RateLimit      int      `json:"rateLimit"`
+	MaxConcurrent  int      `json:"maxConcurrent"` // requests each client may have in flight
 	AuthRequired   bool     `json:"authRequired"`
 	Timeout        int      `json:"timeout"` // in seconds
 	RetryCount     int      `json:"retryCount"`
-	RetryDelay     int      `json:"retryDelay"` // in milliseconds
-	CacheTTL       int      `json:"cacheTTL"`   // in seconds
+	RetryDelay     int      `json:"retryDelay"`               // in milliseconds
+	CacheTTL       int      `json:"cacheTTL"`                 // in seconds
+	Policy         string   `json:"policy"`                   // authorization policy expression
+	RequiredScopes []string `json:"requiredScopes,omitempty"` // OAuth scopes callers must all be granted
+	Priority       int      `json:"priority,omitempty"`       // orders the endpoints serving the same route, highest first
+	ContentTypes   []string `json:"contentTypes,omitempty"`   // media types of the request bodies accepted, e.g. application/json or text/*; empty accepts any
 	CircuitBreaker struct {
 		Enabled          bool    `json:"enabled"`
 		FailureThreshold float64 `json:"failureThreshold"`
@@ -45,6 +89,44 @@ type Endpoint struct {
 		Request  map[string]string `json:"request"`  // header transformations
 		Response map[string]string `json:"response"` // header transformations
 	} `json:"transform"`
+	// Composite makes the endpoint aggregate other routes instead of proxying to the service
+	Composite *Composite `json:"composite,omitempty"`
+	// Pipeline makes the endpoint call other routes in sequence instead of proxying to the service
+	Pipeline *Pipeline `json:"pipeline,omitempty"`
+	// Bridge makes the endpoint publish request bodies to a message broker instead of proxying to the service
+	Bridge *Bridge `json:"bridge,omitempty"`
+	// Async queues requests to the endpoint and answers 202 with a status URL instead of waiting for the service
+	Async bool `json:"async,omitempty"`
+	// SpikeArrest spaces out bursts of requests to the service instead of forwarding them at once
+	SpikeArrest *SpikeArrest `json:"spikeArrest,omitempty"`
+	// NegativeCache caches error responses, such as 404 for missing resources, for a short time
+	NegativeCache *NegativeCache `json:"negativeCache,omitempty"`
+	// SLO is the service level objective the endpoint's compliance is tracked against
+	SLO *SLO `json:"slo,omitempty"`
+	// Mock answers requests from fixtures instead of proxying to the service
+	Mock *Mock `json:"mock,omitempty"`
+	// SOAP converts JSON requests to SOAP envelopes for the service and its XML responses to JSON
+	SOAP *SOAP `json:"soap,omitempty"`
+	// Encryption decrypts request bodies and encrypts response bodies exchanged with clients
+	Encryption *PayloadEncryption `json:"encryption,omitempty"`
+	// Masking masks personal data in responses for callers without the scope to see it
+	Masking *ResponseMasking `json:"masking,omitempty"`
+	// Upload streams multipart uploads to the service instead of buffering them
+	Upload *Upload `json:"upload,omitempty"`
+	// ResponseLimit bounds the size of the service's responses the gateway buffers
+	ResponseLimit *ResponseLimit `json:"responseLimit,omitempty"`
+	// Tags add to or override the service's tags for the endpoint's requests
+	Tags map[string]string `json:"tags,omitempty"`
+	// Experiment splits the endpoint's callers between variants served by different upstreams
+	Experiment *Experiment `json:"experiment,omitempty"`
+	// FeatureFlag is the key of the feature flag the endpoint is served behind, empty when it is
+	// served to every caller. Callers the flag is off for are routed as if the endpoint did not
+	// exist, to the endpoint serving the route otherwise.
+	FeatureFlag string `json:"featureFlag,omitempty"`
+	// ValidFrom and ValidUntil bound when the endpoint is served, within its service's window,
+	// nil for no bound. It is removed from its service once ValidUntil has passed.
+	ValidFrom  *time.Time `json:"validFrom,omitempty"`
+	ValidUntil *time.Time `json:"validUntil,omitempty"`
 }
 
 // NewService creates a new Service instance
@@ -90,6 +172,23 @@ func (s *Service) FindEndpoint(path string, method string) *Endpoint {
 	return nil
 }
 
+// Clone returns a copy of the service whose metadata, tags and endpoint list can be changed independently
+func (s *Service) Clone() *Service {
+	clone := *s
+	clone.Metadata = make(map[string]string, len(s.Metadata))
+	for k, v := range s.Metadata {
+		clone.Metadata[k] = v
+	}
+	clone.Endpoints = append([]Endpoint(nil), s.Endpoints...)
+	if s.Tags != nil {
+		clone.Tags = make(map[string]string, len(s.Tags))
+		for k, v := range s.Tags {
+			clone.Tags[k] = v
+		}
+	}
+	return &clone
+}
+
 // SetActive sets the service active status
 func (s *Service) SetActive(active bool) {
 	s.IsActive = active
@@ -110,14 +209,80 @@ func (s *Service) Validate() error {
 		return fmt.Errorf("service base URL is required")
 	}
 
-	if _, err := url.Parse(s.BaseURL); err != nil {
+	baseURL, err := url.Parse(s.BaseURL)
+	if err != nil {
 		return fmt.Errorf("invalid base URL: %w", err)
 	}
 
+	if baseURL.Scheme == UnixScheme {
+		if baseURL.Host != "" || baseURL.Path == "" {
+			return fmt.Errorf("unix base URL must name a socket path, such as unix:///run/app.sock")
+		}
+		if s.Network != "" {
+			return fmt.Errorf("unix socket upstreams cannot be reached through a network")
+		}
+	} else if s.UpstreamPath != "" {
+		return fmt.Errorf("upstream path is only used with unix base URLs")
+	}
+
+	if s.UpstreamPath != "" && !strings.HasPrefix(s.UpstreamPath, "/") {
+		return fmt.Errorf("upstream path must start with /")
+	}
+
 	if len(s.Endpoints) == 0 {
 		return fmt.Errorf("at least one endpoint is required")
 	}
 
+	if s.Signing != nil {
+		if err := s.Signing.Validate(); err != nil {
+			return err
+		}
+		for _, endpoint := range s.Endpoints {
+			if endpoint.Upload != nil {
+				return fmt.Errorf("endpoint %s streams uploads, which cannot be signed", endpoint.Path)
+			}
+		}
+	}
+
+	if s.Residency != nil {
+		if err := s.Residency.Validate(); err != nil {
+			return err
+		}
+		for _, endpoint := range s.Endpoints {
+			if endpoint.Experiment != nil && endpoint.Experiment.RoutesUpstream() {
+				return fmt.Errorf("endpoint %s routes experiment variants to other upstreams, which residency would bypass", endpoint.Path)
+			}
+		}
+	}
+
+	if err := validateTags(s.Tags); err != nil {
+		return err
+	}
+
+	if err := validateWindow(s.ValidFrom, s.ValidUntil); err != nil {
+		return err
+	}
+
+	if s.Owner != nil {
+		if err := s.Owner.Validate(); err != nil {
+			return err
+		}
+	}
+
+	if err := validateAuthMethods(s.AuthMethods); err != nil {
+		return err
+	}
+
+	if s.Network != "" && !networkName.MatchString(s.Network) {
+		return fmt.Errorf("invalid network name %q", s.Network)
+	}
+
+	for i := range s.ErrorTemplates {
+		if err := s.ErrorTemplates[i].Validate(); err != nil {
+			return fmt.Errorf("invalid error template at index %d: %w", i, err)
+		}
+	}
+
 	for i, endpoint := range s.Endpoints {
 		if err := endpoint.Validate(); err != nil {
 			return fmt.Errorf("invalid endpoint at index %d: %w", i, err)
@@ -127,6 +292,16 @@ func (s *Service) Validate() error {
 	return nil
 }
 
+// Aggregates reports whether the endpoint calls other routes instead of proxying to its service
+func (e *Endpoint) Aggregates() bool {
+	return e.Composite != nil || e.Pipeline != nil
+}
+
+// Cached reports whether responses of the endpoint are stored in the response cache
+func (e *Endpoint) Cached() bool {
+	return e.CacheTTL > 0 || e.NegativeCache != nil
+}
+
 // Validate validates the endpoint configuration
 func (e *Endpoint) Validate() error {
 	if e.Path == "" {
@@ -157,10 +332,22 @@ func (e *Endpoint) Validate() error {
 		}
 	}
 
+	if err := validateTags(e.Tags); err != nil {
+		return err
+	}
+
+	if err := validateWindow(e.ValidFrom, e.ValidUntil); err != nil {
+		return err
+	}
+
 	if e.RateLimit < 0 {
 		return fmt.Errorf("rate limit cannot be negative")
 	}
 
+	if e.MaxConcurrent < 0 {
+		return fmt.Errorf("max concurrent requests cannot be negative")
+	}
+
 	if e.Timeout < 0 {
 		return fmt.Errorf("timeout cannot be negative")
 	}
@@ -195,5 +382,144 @@ func (e *Endpoint) Validate() error {
 		return fmt.Errorf("cache TTL cannot be negative")
 	}
 
+	if e.Composite != nil {
+		if err := e.Composite.Validate(e.Path); err != nil {
+			return err
+		}
+	}
+
+	if e.Pipeline != nil {
+		if e.Composite != nil {
+			return fmt.Errorf("endpoint cannot be both composite and a pipeline")
+		}
+		if err := e.Pipeline.Validate(e.Path); err != nil {
+			return err
+		}
+	}
+
+	if e.Bridge != nil {
+		if e.Aggregates() {
+			return fmt.Errorf("endpoint cannot both publish to a broker and call other routes")
+		}
+		if err := e.Bridge.Validate(); err != nil {
+			return err
+		}
+	}
+
+	if e.SpikeArrest != nil {
+		if err := e.SpikeArrest.Validate(); err != nil {
+			return err
+		}
+	}
+
+	if e.NegativeCache != nil {
+		if err := e.NegativeCache.Validate(); err != nil {
+			return err
+		}
+	}
+
+	if e.SLO != nil {
+		if err := e.SLO.Validate(); err != nil {
+			return err
+		}
+	}
+
+	if e.Mock != nil {
+		if e.Bridge != nil || e.Aggregates() {
+			return fmt.Errorf("mock endpoint cannot publish to a broker or call other routes")
+		}
+		if err := e.Mock.Validate(); err != nil {
+			return err
+		}
+	}
+
+	if e.SOAP != nil {
+		if e.Bridge != nil || e.Aggregates() || e.Mock != nil {
+			return fmt.Errorf("soap endpoint must proxy to its service")
+		}
+		if err := e.SOAP.Validate(); err != nil {
+			return err
+		}
+	}
+
+	if e.Encryption != nil {
+		if err := e.Encryption.Validate(); err != nil {
+			return err
+		}
+	}
+
+	if e.Masking != nil {
+		if err := e.Masking.Validate(); err != nil {
+			return err
+		}
+		if e.Encryption != nil && e.Encryption.Responses {
+			return fmt.Errorf("masking cannot apply to encrypted responses")
+		}
+	}
+
+	if len(e.RequiredScopes) > 0 {
+		if !e.AuthRequired {
+			return fmt.Errorf("required scopes need authentication")
+		}
+		if err := validateRequiredScopes(e.RequiredScopes); err != nil {
+			return err
+		}
+	}
+
+	if err := validateContentTypes(e.ContentTypes); err != nil {
+		return err
+	}
+
+	if e.Async {
+		if e.Bridge != nil || e.Aggregates() || e.Mock != nil || e.SOAP != nil {
+			return fmt.Errorf("async endpoint must proxy to its service")
+		}
+		if e.Encryption != nil {
+			return fmt.Errorf("async endpoint cannot encrypt payloads, as its results are fetched separately")
+		}
+		if e.Masking != nil {
+			return fmt.Errorf("async endpoint cannot mask responses, as its results are fetched separately")
+		}
+		if e.Cached() {
+			return fmt.Errorf("async endpoint cannot be cached")
+		}
+	}
+
+	if e.Upload != nil {
+		if e.Bridge != nil || e.Aggregates() || e.Mock != nil || e.SOAP != nil || e.Async {
+			return fmt.Errorf("upload endpoint must proxy to its service")
+		}
+		if e.Encryption != nil && e.Encryption.Requests {
+			return fmt.Errorf("upload endpoint cannot decrypt requests, as they are streamed")
+		}
+		if err := e.Upload.Validate(); err != nil {
+			return err
+		}
+	}
+
+	if e.Experiment != nil {
+		if e.Bridge != nil || e.Aggregates() || e.Mock != nil || e.Async {
+			return fmt.Errorf("experiment endpoint must proxy to its service")
+		}
+		if err := e.Experiment.Validate(); err != nil {
+			return err
+		}
+	}
+
+	if e.ResponseLimit != nil {
+		if err := e.ResponseLimit.Validate(); err != nil {
+			return err
+		}
+		// Streamed responses reach the client as the service sends them
+		if e.ResponseLimit.Streams() {
+			if e.SOAP != nil || e.Masking != nil || (e.Encryption != nil && e.Encryption.Responses) {
+				return fmt.Errorf("streamed responses cannot be converted, masked or encrypted")
+			}
+			if e.Async {
+				return fmt.Errorf("async endpoint cannot stream responses, as its results are stored")
+			}
+		}
+	}
+
 	return nil
 }
@@ -0,0 +1,29 @@
+package entity
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// ServiceRevision is a stored revision of a service configuration, kept so that a change can
+// be reviewed and reverted
+type ServiceRevision struct {
+	ServiceID string `json:"serviceId"`
+	Revision  int64  `json:"revision"`
+	// Actor is the user that made the change, empty for system changes
+	Actor     string    `json:"actor,omitempty"`
+	CreatedAt time.Time `json:"createdAt"`
+	// RestoredFrom is the revision a rollback restored, zero for other changes
+	RestoredFrom int64 `json:"restoredFrom,omitempty"`
+	// Changes are the fields changed from the previous revision, none for the first one
+	Changes []FieldChange `json:"changes,omitempty"`
+	// Service is the configuration as of the revision
+	Service *Service `json:"service"`
+}
+
+// FieldChange is a changed field of a configuration, with its JSON values before and after
+type FieldChange struct {
+	Field string          `json:"field"`
+	From  json.RawMessage `json:"from"`
+	To    json.RawMessage `json:"to"`
+}
@@ -193,6 +193,69 @@ func TestService_Validate(t *testing.T) {
 			},
 			wantErr: true,
 		},
+		{
+			name: "invalid service - invalid network name",
+			service: &Service{
+				ID:      "1",
+				Name:    "test-service",
+				BaseURL: "http://localhost:8080",
+				Network: "Private VPC",
+				Endpoints: []Endpoint{
+					{
+						Path:    "/api/test",
+						Methods: []string{"GET"},
+					},
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "valid service - unix socket upstream",
+			service: &Service{
+				ID:           "1",
+				Name:         "test-service",
+				BaseURL:      "unix:///run/app.sock",
+				UpstreamPath: "/api",
+				Endpoints: []Endpoint{
+					{
+						Path:    "/api/test",
+						Methods: []string{"GET"},
+					},
+				},
+			},
+			wantErr: false,
+		},
+		{
+			name: "invalid service - unix base URL without socket path",
+			service: &Service{
+				ID:      "1",
+				Name:    "test-service",
+				BaseURL: "unix://app.sock",
+				Endpoints: []Endpoint{
+					{
+						Path:    "/api/test",
+						Methods: []string{"GET"},
+					},
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "invalid service - upstream path without unix base URL",
+			service: &Service{
+				ID:           "1",
+				Name:         "test-service",
+				BaseURL:      "http://localhost:8080",
+				UpstreamPath: "/api",
+				Endpoints: []Endpoint{
+					{
+						Path:    "/api/test",
+						Methods: []string{"GET"},
+					},
+				},
+			},
+			wantErr: true,
+		},
 	}
 
 	for _, tt := range tests {
@@ -313,6 +376,197 @@ func TestEndpoint_Validate(t *testing.T) {
 			},
 			wantErr: true,
 		},
+		{
+			name: "valid composite endpoint",
+			endpoint: &Endpoint{
+				Path:    "/api/v1/dashboard",
+				Methods: []string{"GET"},
+				Composite: &Composite{
+					Timeout: 2,
+					Calls: []CompositeCall{
+						{Name: "user", Path: "/api/v1/users/me"},
+						{Name: "orders", Path: "/api/v1/orders", Optional: true},
+					},
+				},
+			},
+			wantErr: false,
+		},
+		{
+			name: "invalid composite endpoint - duplicate call names",
+			endpoint: &Endpoint{
+				Path:    "/api/v1/dashboard",
+				Methods: []string{"GET"},
+				Composite: &Composite{
+					Calls: []CompositeCall{
+						{Name: "user", Path: "/api/v1/users/me"},
+						{Name: "user", Path: "/api/v1/orders"},
+					},
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "invalid pipeline endpoint - refers to a later step",
+			endpoint: &Endpoint{
+				Path:    "/api/v1/profile",
+				Methods: []string{"GET"},
+				Pipeline: &Pipeline{
+					Steps: []PipelineStep{
+						{Name: "orders", Path: "/api/v1/users/{{user.id}}/orders"},
+						{Name: "user", Path: "/api/v1/users/me"},
+					},
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "valid bridge endpoint",
+			endpoint: &Endpoint{
+				Path:    "/api/v1/events",
+				Methods: []string{"POST"},
+				Bridge:  &Bridge{Broker: BrokerNATS, Topic: "events.{{header.X-Tenant-ID}}", Delivery: DeliveryAtMostOnce},
+			},
+			wantErr: false,
+		},
+		{
+			name: "invalid bridge endpoint - placeholder outside header and query",
+			endpoint: &Endpoint{
+				Path:    "/api/v1/events",
+				Methods: []string{"POST"},
+				Bridge:  &Bridge{Broker: BrokerKafka, Topic: "events.{{user.id}}"},
+			},
+			wantErr: true,
+		},
+		{
+			name: "invalid composite endpoint - calls itself",
+			endpoint: &Endpoint{
+				Path:    "/api/v1/dashboard",
+				Methods: []string{"GET"},
+				Composite: &Composite{
+					Calls: []CompositeCall{{Name: "self", Path: "/api/v1/dashboard"}},
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "valid endpoint with slo",
+			endpoint: &Endpoint{
+				Path:    "/api/v1/users",
+				Methods: []string{"GET"},
+				SLO:     &SLO{Objective: 0.999, LatencyMs: 300},
+			},
+			wantErr: false,
+		},
+		{
+			name: "invalid slo - objective of 100%",
+			endpoint: &Endpoint{
+				Path:    "/api/v1/users",
+				Methods: []string{"GET"},
+				SLO:     &SLO{Objective: 1},
+			},
+			wantErr: true,
+		},
+		{
+			name: "valid encrypted endpoint",
+			endpoint: &Endpoint{
+				Path:       "/api/v1/payments",
+				Methods:    []string{"POST"},
+				Encryption: &PayloadEncryption{Key: "payments_key", Requests: true, Responses: true},
+			},
+			wantErr: false,
+		},
+		{
+			name: "invalid encrypted endpoint - applies to neither requests nor responses",
+			endpoint: &Endpoint{
+				Path:       "/api/v1/payments",
+				Methods:    []string{"POST"},
+				Encryption: &PayloadEncryption{Key: "payments_key"},
+			},
+			wantErr: true,
+		},
+		{
+			name: "invalid encrypted endpoint - async",
+			endpoint: &Endpoint{
+				Path:       "/api/v1/payments",
+				Methods:    []string{"POST"},
+				Async:      true,
+				Encryption: &PayloadEncryption{Key: "payments_key", Requests: true},
+			},
+			wantErr: true,
+		},
+		{
+			name: "valid masked endpoint",
+			endpoint: &Endpoint{
+				Path:    "/api/v1/customers",
+				Methods: []string{"GET"},
+				Masking: &ResponseMasking{Scope: "pii:read", Rules: []MaskingRule{{Path: "$..email", Strategy: MaskEmail}, {Path: "$.ssn"}}},
+			},
+			wantErr: false,
+		},
+		{
+			name: "invalid masked endpoint - path outside the response",
+			endpoint: &Endpoint{
+				Path:    "/api/v1/customers",
+				Methods: []string{"GET"},
+				Masking: &ResponseMasking{Rules: []MaskingRule{{Path: "customers.email"}}},
+			},
+			wantErr: true,
+		},
+		{
+			name: "invalid masked endpoint - unknown strategy",
+			endpoint: &Endpoint{
+				Path:    "/api/v1/customers",
+				Methods: []string{"GET"},
+				Masking: &ResponseMasking{Rules: []MaskingRule{{Path: "$.ssn", Strategy: "scramble"}}},
+			},
+			wantErr: true,
+		},
+		{
+			name: "valid soap endpoint",
+			endpoint: &Endpoint{
+				Path:    "/api/v1/orders",
+				Methods: []string{"POST"},
+				SOAP:    &SOAP{Action: "urn:GetOrder", Template: "<GetOrder><id>{{body.id}}</id></GetOrder>"},
+			},
+			wantErr: false,
+		},
+		{
+			name: "invalid soap endpoint - placeholder outside body, header and query",
+			endpoint: &Endpoint{
+				Path:    "/api/v1/orders",
+				Methods: []string{"POST"},
+				SOAP:    &SOAP{Template: "<GetOrder><id>{{request.id}}</id></GetOrder>"},
+			},
+			wantErr: true,
+		},
+		{
+			name: "valid streamed response limit",
+			endpoint: &Endpoint{
+				Path:          "/api/v1/exports",
+				Methods:       []string{"GET"},
+				ResponseLimit: &ResponseLimit{MaxSize: 1 << 20, Policy: ResponseLimitStream},
+			},
+			wantErr: false,
+		},
+		{
+			name: "invalid response limit - streamed responses masked",
+			endpoint: &Endpoint{
+				Path:          "/api/v1/exports",
+				Methods:       []string{"GET"},
+				ResponseLimit: &ResponseLimit{MaxSize: 1 << 20, Policy: ResponseLimitStream},
+				Masking:       &ResponseMasking{Rules: []MaskingRule{{Path: "$.ssn"}}},
+			},
+			wantErr: true,
+		},
+		{
+			name: "invalid response limit - unknown policy",
+			endpoint: &Endpoint{
+				Path:          "/api/v1/exports",
+				Methods:       []string{"GET"},
+				ResponseLimit: &ResponseLimit{MaxSize: 1 << 20, Policy: "truncate"},
+			},
+			wantErr: true,
+		},
 	}
 
 	for _, tt := range tests {
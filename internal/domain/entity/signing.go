@@ -0,0 +1,45 @@
+package entity
+
+import "fmt"
+
+// Upstream request signing schemes
+const (
+	// SigningAWSSigV4 signs requests with AWS Signature Version 4, for API Gateway, Lambda function URLs and S3
+	SigningAWSSigV4 = "aws-sigv4"
+	// SigningHMAC signs the method, path, query, timestamp and body hash with a shared HMAC-SHA256 key
+	SigningHMAC = "hmac"
+	// SigningOAuth2 sends an access token obtained with the OAuth 2.0 client credentials grant as a bearer token
+	SigningOAuth2 = "oauth2"
+)
+
+// UpstreamSigning configures how the gateway signs the requests it sends to a service. The
+// credentials are configured on the gateway and referenced by name, so that keys are never
+// stored with the service.
+type UpstreamSigning struct {
+	// Scheme is "aws-sigv4", "hmac" or "oauth2"
+	Scheme string `json:"scheme"`
+	// Credentials is the name of the signing credentials in the gateway configuration, or of
+	// the OAuth client with the oauth2 scheme
+	Credentials string `json:"credentials"`
+	// Region and Service scope AWS signatures, e.g. "eu-west-1" and "execute-api"
+	Region  string `json:"region,omitempty"`
+	Service string `json:"service,omitempty"`
+}
+
+// Validate validates the signing configuration
+func (s *UpstreamSigning) Validate() error {
+	if s.Credentials == "" {
+		return fmt.Errorf("signing credentials are required")
+	}
+
+	switch s.Scheme {
+	case SigningAWSSigV4:
+		if s.Region == "" || s.Service == "" {
+			return fmt.Errorf("aws-sigv4 signing requires a region and a service")
+		}
+	case SigningHMAC, SigningOAuth2:
+	default:
+		return fmt.Errorf("invalid signing scheme: %s", s.Scheme)
+	}
+	return nil
+}
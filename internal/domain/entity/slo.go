@@ -0,0 +1,58 @@
+package entity
+
+import (
+	"fmt"
+	"time"
+)
+
+// SLO declares the service level objective of an endpoint: the fraction of requests that must
+// succeed, and optionally complete within a latency, e.g. 99% of requests under 300ms
+type SLO struct {
+	// Objective is the fraction (0-1) of requests that must meet the SLO; the rest is the error budget
+	Objective float64 `json:"objective"`
+	// LatencyMs is the latency a request must complete within, in milliseconds; 0 only counts errors
+	LatencyMs int `json:"latencyMs,omitempty"`
+}
+
+// Breached reports whether a request failed to meet the SLO: it failed with a server error
+// or was slower than the latency objective
+func (s *SLO) Breached(statusCode int, latency time.Duration) bool {
+	if statusCode >= 500 {
+		return true
+	}
+	return s.LatencyMs > 0 && latency > time.Duration(s.LatencyMs)*time.Millisecond
+}
+
+// Validate validates the SLO configuration
+func (s *SLO) Validate() error {
+	if s.Objective <= 0 || s.Objective >= 1 {
+		return fmt.Errorf("slo objective must be between 0 and 1 (exclusive)")
+	}
+	if s.LatencyMs < 0 {
+		return fmt.Errorf("slo latency cannot be negative")
+	}
+	return nil
+}
+
+// SLOStatus is the compliance of an endpoint with its SLO over a rolling window
+type SLOStatus struct {
+	ServiceID     string  `json:"serviceId"`
+	Endpoint      string  `json:"endpoint"`
+	Objective     float64 `json:"objective"`
+	LatencyMs     int     `json:"latencyMs,omitempty"`
+	WindowSeconds float64 `json:"windowSeconds"`
+	Requests      int64   `json:"requests"`
+	// Breaches counts the requests that failed or were slower than the latency objective
+	Breaches   int64   `json:"breaches"`
+	Compliance float64 `json:"compliance"`
+	// BurnRate is how fast the error budget is being spent: 1 spends exactly the budget, and
+	// above 1 the budget is exhausted
+	BurnRate float64 `json:"burnRate"`
+	// BudgetRemaining is the fraction of the error budget left over the window, negative once overspent
+	BudgetRemaining  float64 `json:"budgetRemaining"`
+	LatencyP99Ms     float64 `json:"latencyP99Ms"`
+	AvgRequestBytes  float64 `json:"avgRequestBytes"`
+	AvgResponseBytes float64 `json:"avgResponseBytes"`
+	// Tags are the tags of the endpoint's latest request
+	Tags map[string]string `json:"tags,omitempty"`
+}
@@ -0,0 +1,58 @@
+package entity
+
+import (
+	"fmt"
+	"strings"
+)
+
+// SOAPBodyScope is the template scope of the JSON request body, as in "{{body.customer.id}}"
+const SOAPBodyScope = "body"
+
+// SOAP versions
+const (
+	SOAPVersion11 = "1.1"
+	SOAPVersion12 = "1.2"
+)
+
+// SOAP configures an endpoint that calls a SOAP service: the JSON request is rendered into a
+// SOAP envelope and the XML response is converted back to JSON
+type SOAP struct {
+	// Path is the path of the SOAP service on the backend, the request path unless set
+	Path string `json:"path,omitempty"`
+	// Version is "1.1" or "1.2"; defaults to "1.1"
+	Version string `json:"version,omitempty"`
+	// Action is the SOAP action of the operation
+	Action string `json:"action,omitempty"`
+	// Template is the content of the SOAP body. It may contain placeholders such as
+	// "{{body.customer.id}}", "{{query.id}}" or "{{header.X-Tenant-ID}}".
+	Template string `json:"template"`
+}
+
+// SOAPVersion returns the SOAP version of the endpoint
+func (s *SOAP) SOAPVersion() string {
+	if s.Version == "" {
+		return SOAPVersion11
+	}
+	return s.Version
+}
+
+// Validate validates the SOAP configuration
+func (s *SOAP) Validate() error {
+	if strings.TrimSpace(s.Template) == "" {
+		return fmt.Errorf("soap template is required")
+	}
+	if version := s.SOAPVersion(); version != SOAPVersion11 && version != SOAPVersion12 {
+		return fmt.Errorf("invalid soap version: %s", s.Version)
+	}
+	if s.Path != "" && !strings.HasPrefix(s.Path, "/") {
+		return fmt.Errorf("soap path must start with /")
+	}
+
+	for _, reference := range PipelineReferences(s.Template) {
+		scope, field, _ := strings.Cut(reference, ".")
+		if field == "" || (scope != SOAPBodyScope && scope != BridgeHeaderScope && scope != BridgeQueryScope) {
+			return fmt.Errorf("invalid soap placeholder %s", reference)
+		}
+	}
+	return nil
+}
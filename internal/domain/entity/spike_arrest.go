@@ -0,0 +1,38 @@
+package entity
+
+import (
+	"fmt"
+	"time"
+)
+
+// SpikeArrest smooths bursts of requests to an endpoint into an even flow: requests are
+// delivered to the service at most Rate per second, evenly spaced, and requests that would
+// have to wait longer than MaxDelay are rejected with 429 instead
+type SpikeArrest struct {
+	// Rate is the number of requests per second delivered to the service
+	Rate int `json:"rate"`
+	// MaxDelay is how long a request may be held back, in milliseconds; 0 rejects every request
+	// arriving before its slot
+	MaxDelay int `json:"maxDelay"`
+}
+
+// Interval returns the spacing between two requests delivered to the service
+func (s *SpikeArrest) Interval() time.Duration {
+	return time.Second / time.Duration(s.Rate)
+}
+
+// MaxDelayDuration returns how long a request may be held back
+func (s *SpikeArrest) MaxDelayDuration() time.Duration {
+	return time.Duration(s.MaxDelay) * time.Millisecond
+}
+
+// Validate validates the spike arrest configuration
+func (s *SpikeArrest) Validate() error {
+	if s.Rate <= 0 {
+		return fmt.Errorf("spike arrest rate must be positive")
+	}
+	if s.MaxDelay < 0 {
+		return fmt.Errorf("spike arrest max delay cannot be negative")
+	}
+	return nil
+}
@@ -0,0 +1,113 @@
+package entity
+
+import (
+	"context"
+	"fmt"
+	"sync"
+)
+
+// reservedTagNames are the labels the gateway sets on its own metrics
+var reservedTagNames = map[string]bool{
+	"service":    true,
+	"endpoint":   true,
+	"class":      true,
+	"target":     true,
+	"result":     true,
+	"dependency": true,
+	"le":         true,
+}
+
+// ValidTagName reports whether a tag can be used as a metrics label: letters, digits and
+// underscores not starting with a digit or "__", and not one of the gateway's own labels
+func ValidTagName(name string) bool {
+	if name == "" || reservedTagNames[name] || len(name) >= 2 && name[:2] == "__" {
+		return false
+	}
+	for i, c := range name {
+		switch {
+		case c >= 'a' && c <= 'z', c >= 'A' && c <= 'Z', c == '_':
+		case c >= '0' && c <= '9' && i > 0:
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// validateTags checks that every tag can be used as a metrics label
+func validateTags(tags map[string]string) error {
+	for name := range tags {
+		if !ValidTagName(name) {
+			return fmt.Errorf("invalid tag name: %s", name)
+		}
+	}
+	return nil
+}
+
+// RouteTags returns the tags of the requests served by one of the service's endpoints: the
+// service's tags, with those of the endpoint added or overriding them. It returns nil when
+// neither has tags.
+func (s *Service) RouteTags(endpoint *Endpoint) map[string]string {
+	if len(s.Tags) == 0 && (endpoint == nil || len(endpoint.Tags) == 0) {
+		return nil
+	}
+	tags := make(map[string]string, len(s.Tags))
+	for k, v := range s.Tags {
+		tags[k] = v
+	}
+	if endpoint != nil {
+		for k, v := range endpoint.Tags {
+			tags[k] = v
+		}
+	}
+	return tags
+}
+
+// RouteInfo records the route a request matched, for the middleware that logs the request
+// once it is served. All methods are safe to call on a nil RouteInfo, which records nothing.
+type RouteInfo struct {
+	mu       sync.Mutex
+	service  string
+	endpoint string
+	tags     map[string]string
+}
+
+// NewRouteInfo creates a new RouteInfo instance
+func NewRouteInfo() *RouteInfo {
+	return &RouteInfo{}
+}
+
+// Set records the service and endpoint path the request matched and its tags
+func (r *RouteInfo) Set(service, endpoint string, tags map[string]string) {
+	if r == nil {
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.service = service
+	r.endpoint = endpoint
+	r.tags = tags
+}
+
+// Route returns the recorded service, endpoint path and tags, empty when the request matched no route
+func (r *RouteInfo) Route() (string, string, map[string]string) {
+	if r == nil {
+		return "", "", nil
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.service, r.endpoint, r.tags
+}
+
+type routeContextKey struct{}
+
+// ContextWithRoute returns a context carrying the route info
+func ContextWithRoute(ctx context.Context, route *RouteInfo) context.Context {
+	return context.WithValue(ctx, routeContextKey{}, route)
+}
+
+// RouteFromContext returns the route info carried by the context, or nil when none is
+func RouteFromContext(ctx context.Context) *RouteInfo {
+	route, _ := ctx.Value(routeContextKey{}).(*RouteInfo)
+	return route
+}
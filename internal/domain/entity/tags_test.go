@@ -0,0 +1,61 @@
+package entity
+
+import (
+	"context"
+	"reflect"
+	"testing"
+)
+
+func TestValidTagName(t *testing.T) {
+	tests := []struct {
+		name  string
+		valid bool
+	}{
+		{"team", true},
+		{"cost_center", true},
+		{"_tier2", true},
+		{"", false},
+		{"2tier", false},
+		{"cost-center", false},
+		{"__name", false},
+		// Labels the gateway sets itself
+		{"service", false},
+		{"endpoint", false},
+	}
+	for _, tt := range tests {
+		if got := ValidTagName(tt.name); got != tt.valid {
+			t.Errorf("ValidTagName(%q) = %v, want %v", tt.name, got, tt.valid)
+		}
+	}
+}
+
+func TestService_RouteTags(t *testing.T) {
+	service := &Service{Tags: map[string]string{"team": "payments", "tier": "1"}}
+	endpoint := &Endpoint{Tags: map[string]string{"tier": "2", "domain": "refunds"}}
+
+	want := map[string]string{"team": "payments", "tier": "2", "domain": "refunds"}
+	if got := service.RouteTags(endpoint); !reflect.DeepEqual(got, want) {
+		t.Errorf("Expected tags %v, got %v", want, got)
+	}
+	// The service's tags are left unchanged
+	if service.Tags["tier"] != "1" {
+		t.Errorf("Expected the service tier to stay 1, got %s", service.Tags["tier"])
+	}
+	if got := (&Service{}).RouteTags(&Endpoint{}); got != nil {
+		t.Errorf("Expected no tags, got %v", got)
+	}
+}
+
+func TestRouteInfo(t *testing.T) {
+	ctx := context.Background()
+	// Recording a route without route info in the context does nothing
+	RouteFromContext(ctx).Set("users", "/api/v1/users", nil)
+
+	route := NewRouteInfo()
+	RouteFromContext(ContextWithRoute(ctx, route)).Set("users", "/api/v1/users", map[string]string{"team": "identity"})
+
+	service, endpoint, tags := route.Route()
+	if service != "users" || endpoint != "/api/v1/users" || tags["team"] != "identity" {
+		t.Errorf("Unexpected route %s %s %v", service, endpoint, tags)
+	}
+}
@@ -0,0 +1,106 @@
+package entity
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// Cache status values recorded in a RequestTrace
+const (
+	CacheStatusHit    = "HIT"
+	CacheStatusMiss   = "MISS"
+	CacheStatusBypass = "BYPASS"
+	// CacheStatusStale is a stale cached response the upstream replaced
+	CacheStatusStale = "STALE"
+	// CacheStatusRevalidated is a stale cached response the upstream confirmed with 304
+	CacheStatusRevalidated = "REVALIDATED"
+)
+
+// Phases recorded in a RequestTrace
+const (
+	TracePhaseAuth      = "auth"
+	TracePhaseRateLimit = "rate-limit"
+	TracePhaseCache     = "cache"
+	TracePhaseUpstream  = "upstream"
+)
+
+// RequestTrace collects a timing breakdown of a single proxied request.
+// All methods are safe to call on a nil trace, which records nothing.
+type RequestTrace struct {
+	mu          sync.Mutex
+	phases      map[string]time.Duration
+	order       []string
+	CacheStatus string
+	Target      string
+}
+
+// NewRequestTrace creates a new RequestTrace instance
+func NewRequestTrace() *RequestTrace {
+	return &RequestTrace{
+		phases:      make(map[string]time.Duration),
+		CacheStatus: CacheStatusBypass,
+	}
+}
+
+// Record adds the duration since start to the named phase
+func (t *RequestTrace) Record(phase string, start time.Time) {
+	t.Add(phase, time.Since(start))
+}
+
+// Add adds a duration measured elsewhere, such as by the upstream client, to the named phase
+func (t *RequestTrace) Add(phase string, duration time.Duration) {
+	if t == nil {
+		return
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if _, ok := t.phases[phase]; !ok {
+		t.order = append(t.order, phase)
+	}
+	t.phases[phase] += duration
+}
+
+// SetCacheStatus records whether the response was served from cache
+func (t *RequestTrace) SetCacheStatus(status string) {
+	if t == nil {
+		return
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.CacheStatus = status
+}
+
+// SetTarget records the upstream chosen for the request
+func (t *RequestTrace) SetTarget(target string) {
+	if t == nil {
+		return
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.Target = target
+}
+
+// Phases returns the recorded phases in the order they were first recorded
+func (t *RequestTrace) Phases() ([]string, map[string]time.Duration) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	phases := make(map[string]time.Duration, len(t.phases))
+	for k, v := range t.phases {
+		phases[k] = v
+	}
+	return append([]string(nil), t.order...), phases
+}
+
+type traceContextKey struct{}
+
+// ContextWithTrace returns a context carrying the trace
+func ContextWithTrace(ctx context.Context, trace *RequestTrace) context.Context {
+	return context.WithValue(ctx, traceContextKey{}, trace)
+}
+
+// TraceFromContext returns the trace carried by the context, or nil when the request is not traced
+func TraceFromContext(ctx context.Context) *RequestTrace {
+	trace, _ := ctx.Value(traceContextKey{}).(*RequestTrace)
+	return trace
+}
@@ -0,0 +1,60 @@
+package entity
+
+import (
+	"fmt"
+	"mime"
+	"strings"
+)
+
+// Upload streams the multipart/form-data requests of an endpoint to the service as they are
+// received, instead of buffering them, checking each file on the way
+type Upload struct {
+	// MaxFileSize is the size in bytes a file may not exceed, 0 for no limit
+	MaxFileSize int64 `json:"maxFileSize,omitempty"`
+	// Scan has each file checked by the upload scanner before it is forwarded
+	Scan bool `json:"scan,omitempty"`
+}
+
+// Validate validates the upload configuration
+func (u *Upload) Validate() error {
+	if u.MaxFileSize < 0 {
+		return fmt.Errorf("upload max file size cannot be negative")
+	}
+	return nil
+}
+
+// UploadedFile is a file part of an upload, as submitted to the upload scanner
+type UploadedFile struct {
+	// Field is the form field the file was sent in
+	Field string
+	// Filename is the name the client gave the file
+	Filename string
+	// ContentType is the media type the client declared for the file
+	ContentType string
+	// Size is the size of the file in bytes
+	Size int64
+}
+
+// ScanVerdict is the outcome of scanning an uploaded file
+type ScanVerdict struct {
+	// Clean reports whether the file may be forwarded
+	Clean bool
+	// Threat names what was found in a file that is not clean, such as a virus signature
+	Threat string
+}
+
+// MultipartBoundary returns the boundary of a multipart/form-data request, false when the
+// request is not one
+func MultipartBoundary(headers map[string][]string) (string, bool) {
+	var contentType string
+	for name, values := range headers {
+		if strings.EqualFold(name, "Content-Type") && len(values) > 0 {
+			contentType = values[0]
+		}
+	}
+	mediaType, params, err := mime.ParseMediaType(contentType)
+	if err != nil || mediaType != "multipart/form-data" || params["boundary"] == "" {
+		return "", false
+	}
+	return params["boundary"], true
+}
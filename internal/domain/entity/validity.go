@@ -0,0 +1,66 @@
+package entity
+
+import (
+	"fmt"
+	"time"
+)
+
+// ServedAt reports whether the service is served at t, within its validity window
+func (s *Service) ServedAt(t time.Time) bool {
+	return withinWindow(s.ValidFrom, s.ValidUntil, t)
+}
+
+// ExpiredAt reports whether the service is no longer served from t on
+func (s *Service) ExpiredAt(t time.Time) bool {
+	return s.ValidUntil != nil && !t.Before(*s.ValidUntil)
+}
+
+// ServedAt reports whether the endpoint is served at t, within its validity window
+func (e *Endpoint) ServedAt(t time.Time) bool {
+	return withinWindow(e.ValidFrom, e.ValidUntil, t)
+}
+
+// ExpiredAt reports whether the endpoint is no longer served from t on
+func (e *Endpoint) ExpiredAt(t time.Time) bool {
+	return e.ValidUntil != nil && !t.Before(*e.ValidUntil)
+}
+
+// RouteWindow returns when one of the service's endpoints is served: from the later of their
+// ValidFrom until the earlier of their ValidUntil. Nil bounds are open.
+func (s *Service) RouteWindow(endpoint *Endpoint) (*time.Time, *time.Time) {
+	from, until := s.ValidFrom, s.ValidUntil
+	if endpoint.ValidFrom != nil && (from == nil || endpoint.ValidFrom.After(*from)) {
+		from = endpoint.ValidFrom
+	}
+	if endpoint.ValidUntil != nil && (until == nil || endpoint.ValidUntil.Before(*until)) {
+		until = endpoint.ValidUntil
+	}
+	return from, until
+}
+
+// WindowsOverlap reports whether two validity windows share an instant. Nil bounds are open.
+func WindowsOverlap(from1, until1, from2, until2 *time.Time) bool {
+	if until2 != nil && from1 != nil && !from1.Before(*until2) {
+		return false
+	}
+	if until1 != nil && from2 != nil && !from2.Before(*until1) {
+		return false
+	}
+	return true
+}
+
+// withinWindow reports whether t is at or after from and before until. Nil bounds are open.
+func withinWindow(from, until *time.Time, t time.Time) bool {
+	if from != nil && t.Before(*from) {
+		return false
+	}
+	return until == nil || t.Before(*until)
+}
+
+// validateWindow checks that a validity window ends after it starts
+func validateWindow(from, until *time.Time) error {
+	if from != nil && until != nil && !until.After(*from) {
+		return fmt.Errorf("validUntil must be after validFrom")
+	}
+	return nil
+}
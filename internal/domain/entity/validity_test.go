@@ -0,0 +1,64 @@
+package entity
+
+import (
+	"testing"
+	"time"
+)
+
+func TestService_ServedAt(t *testing.T) {
+	now := time.Now()
+	past, future := now.Add(-time.Hour), now.Add(time.Hour)
+
+	tests := []struct {
+		name    string
+		from    *time.Time
+		until   *time.Time
+		served  bool
+		expired bool
+	}{
+		{"no window", nil, nil, true, false},
+		{"started", &past, nil, true, false},
+		{"not started", &future, nil, false, false},
+		{"ending", nil, &future, true, false},
+		{"ended", nil, &past, false, true},
+		{"ends now", &past, &now, false, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service := &Service{ValidFrom: tt.from, ValidUntil: tt.until}
+			if served := service.ServedAt(now); served != tt.served {
+				t.Errorf("Expected served %v, got %v", tt.served, served)
+			}
+			if expired := service.ExpiredAt(now); expired != tt.expired {
+				t.Errorf("Expected expired %v, got %v", tt.expired, expired)
+			}
+		})
+	}
+}
+
+func TestService_RouteWindow(t *testing.T) {
+	now := time.Now()
+	early, late := now.Add(-time.Hour), now.Add(time.Hour)
+	service := &Service{ValidFrom: &early, ValidUntil: &late}
+
+	from, until := service.RouteWindow(&Endpoint{ValidFrom: &now})
+	if from != &now || until != &late {
+		t.Errorf("Expected the window from the endpoint start to the service end, got %v %v", from, until)
+	}
+	if !WindowsOverlap(from, until, nil, nil) {
+		t.Error("Expected an open window to overlap")
+	}
+	if WindowsOverlap(&early, &now, &now, nil) {
+		t.Error("Expected a window ending when another starts not to overlap")
+	}
+}
+
+func TestService_ValidateWindow(t *testing.T) {
+	now := time.Now()
+	past := now.Add(-time.Hour)
+	service := NewService("id", "users", "1.0.0", "", "http://users", 30, 3)
+	service.AddEndpoint(Endpoint{Path: "/users", Methods: []string{"GET"}, ValidFrom: &now, ValidUntil: &past})
+	if err := service.Validate(); err == nil {
+		t.Error("Expected an endpoint ending before it starts to be invalid")
+	}
+}
@@ -0,0 +1,41 @@
+package entity
+
+import "time"
+
+// WebhookEvents are the event types external systems may subscribe to
+var WebhookEvents = []string{
+	EventServiceCreated,
+	EventServiceUpdated,
+	EventServiceDeleted,
+	EventAPIKeyRequested,
+	EventAPIKeyApproved,
+	EventAPIKeyRejected,
+}
+
+// WebhookSubscription registers an external URL to be called when configuration changes
+type WebhookSubscription struct {
+	ID          string    `json:"id"`
+	URL         string    `json:"url"`
+	Secret      string    `json:"-"`
+	Events      []string  `json:"events"`
+	Description string    `json:"description"`
+	Active      bool      `json:"active"`
+	CreatedAt   time.Time `json:"createdAt"`
+}
+
+// Matches reports whether the subscription wants events of the given type.
+// A subscription without events receives every webhook event.
+func (w *WebhookSubscription) Matches(eventType string) bool {
+	if !w.Active {
+		return false
+	}
+	if len(w.Events) == 0 {
+		return IsWebhookEvent(eventType)
+	}
+	return containsString(w.Events, eventType)
+}
+
+// IsWebhookEvent reports whether the event type can be delivered to webhooks
+func IsWebhookEvent(eventType string) bool {
+	return containsString(WebhookEvents, eventType)
+}
@@ -0,0 +1,24 @@
+package entity
+
+// Drop policies decide what a worker pool does with work submitted while its queue is full
+const (
+	// DropPolicyReject refuses the new work
+	DropPolicyReject = "reject"
+	// DropPolicyOldest discards the longest queued work to make room for the new work
+	DropPolicyOldest = "drop-oldest"
+	// DropPolicyBlock makes the submitter wait for room until its context ends
+	DropPolicyBlock = "block"
+)
+
+// WorkerPoolStats is a snapshot of a pool of workers processing background work from a bounded queue
+type WorkerPoolStats struct {
+	Name       string `json:"name"`
+	Workers    int    `json:"workers"`
+	DropPolicy string `json:"dropPolicy"`
+	// QueueDepth is the number of tasks waiting for a worker, at most QueueCapacity
+	QueueDepth    int   `json:"queueDepth"`
+	QueueCapacity int   `json:"queueCapacity"`
+	Completed     int64 `json:"completed"`
+	// Dropped counts tasks that were refused, discarded from the queue or gave up waiting for room
+	Dropped int64 `json:"dropped"`
+}
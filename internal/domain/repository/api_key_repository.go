@@ -0,0 +1,25 @@
+package repository
+
+import (
+	"context"
+
+	"api-gateway-sample/internal/domain/entity"
+)
+
+// APIKeyRepository defines the interface for API key operations
+type APIKeyRepository interface {
+	// Create creates a new API key
+	Create(ctx context.Context, key *entity.APIKey) error
+
+	// Get retrieves an API key by ID
+	Get(ctx context.Context, id string) (*entity.APIKey, error)
+
+	// FindByHash retrieves an API key by the hash of the issued key
+	FindByHash(ctx context.Context, keyHash string) (*entity.APIKey, error)
+
+	// GetAll retrieves all API keys
+	GetAll(ctx context.Context) ([]*entity.APIKey, error)
+
+	// Update updates an existing API key
+	Update(ctx context.Context, key *entity.APIKey) error
+}
@@ -34,3 +34,9 @@ type CacheRepository interface {
 	// Close closes the cache connection
 	Close() error
 }
+
+// CacheSizer is implemented by cache repositories that can report how much they hold
+type CacheSizer interface {
+	// Size returns the number of keys stored and an estimate of the memory they use in bytes
+	Size(ctx context.Context) (int64, int64, error)
+}
@@ -0,0 +1,25 @@
+package repository
+
+import (
+	"context"
+
+	"api-gateway-sample/internal/domain/entity"
+)
+
+// FeatureFlagRepository defines the interface for feature flag operations
+type FeatureFlagRepository interface {
+	// Create creates a new flag
+	Create(ctx context.Context, flag *entity.FeatureFlag) error
+
+	// Get retrieves a flag by key
+	Get(ctx context.Context, key string) (*entity.FeatureFlag, error)
+
+	// GetAll retrieves all flags
+	GetAll(ctx context.Context) ([]*entity.FeatureFlag, error)
+
+	// Update updates an existing flag
+	Update(ctx context.Context, flag *entity.FeatureFlag) error
+
+	// Delete deletes a flag by key
+	Delete(ctx context.Context, key string) error
+}
@@ -0,0 +1,88 @@
+package mock
+
+import (
+	"context"
+	"sort"
+	"sync"
+
+	"api-gateway-sample/internal/domain/entity"
+	"api-gateway-sample/internal/domain/repository"
+	"api-gateway-sample/pkg/errors"
+)
+
+// APIKeyRepositoryMock is a mock implementation of the APIKeyRepository interface
+type APIKeyRepositoryMock struct {
+	keys map[string]*entity.APIKey
+	mu   sync.RWMutex
+}
+
+// NewAPIKeyRepositoryMock creates a new APIKeyRepositoryMock instance
+func NewAPIKeyRepositoryMock() repository.APIKeyRepository {
+	return &APIKeyRepositoryMock{
+		keys: make(map[string]*entity.APIKey),
+	}
+}
+
+// Create creates a new API key
+func (r *APIKeyRepositoryMock) Create(ctx context.Context, key *entity.APIKey) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.keys[key.ID]; ok {
+		return errors.ErrAlreadyExists
+	}
+	r.keys[key.ID] = key
+	return nil
+}
+
+// Get retrieves an API key by ID
+func (r *APIKeyRepositoryMock) Get(ctx context.Context, id string) (*entity.APIKey, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	key, ok := r.keys[id]
+	if !ok {
+		return nil, errors.ErrNotFound
+	}
+	return key, nil
+}
+
+// FindByHash retrieves an API key by the hash of the issued key
+func (r *APIKeyRepositoryMock) FindByHash(ctx context.Context, keyHash string) (*entity.APIKey, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for _, key := range r.keys {
+		if key.KeyHash != "" && key.KeyHash == keyHash {
+			return key, nil
+		}
+	}
+	return nil, errors.ErrNotFound
+}
+
+// GetAll retrieves all API keys ordered by ID
+func (r *APIKeyRepositoryMock) GetAll(ctx context.Context) ([]*entity.APIKey, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	keys := make([]*entity.APIKey, 0, len(r.keys))
+	for _, key := range r.keys {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		return keys[i].ID < keys[j].ID
+	})
+	return keys, nil
+}
+
+// Update updates an existing API key
+func (r *APIKeyRepositoryMock) Update(ctx context.Context, key *entity.APIKey) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.keys[key.ID]; !ok {
+		return errors.ErrNotFound
+	}
+	r.keys[key.ID] = key
+	return nil
+}
@@ -0,0 +1,87 @@
+package mock
+
+import (
+	"context"
+	"sort"
+	"sync"
+
+	"api-gateway-sample/internal/domain/entity"
+	"api-gateway-sample/internal/domain/repository"
+	"api-gateway-sample/pkg/errors"
+)
+
+// FeatureFlagRepositoryMock is a mock implementation of the FeatureFlagRepository interface
+type FeatureFlagRepositoryMock struct {
+	flags map[string]*entity.FeatureFlag
+	mu    sync.RWMutex
+}
+
+// NewFeatureFlagRepositoryMock creates a new FeatureFlagRepositoryMock instance
+func NewFeatureFlagRepositoryMock() repository.FeatureFlagRepository {
+	return &FeatureFlagRepositoryMock{
+		flags: make(map[string]*entity.FeatureFlag),
+	}
+}
+
+// Create creates a new flag
+func (r *FeatureFlagRepositoryMock) Create(ctx context.Context, flag *entity.FeatureFlag) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.flags[flag.Key]; ok {
+		return errors.ErrAlreadyExists
+	}
+	r.flags[flag.Key] = flag
+	return nil
+}
+
+// Get retrieves a flag by key
+func (r *FeatureFlagRepositoryMock) Get(ctx context.Context, key string) (*entity.FeatureFlag, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	flag, ok := r.flags[key]
+	if !ok {
+		return nil, errors.ErrNotFound
+	}
+	return flag, nil
+}
+
+// GetAll retrieves all flags ordered by key
+func (r *FeatureFlagRepositoryMock) GetAll(ctx context.Context) ([]*entity.FeatureFlag, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	flags := make([]*entity.FeatureFlag, 0, len(r.flags))
+	for _, flag := range r.flags {
+		flags = append(flags, flag)
+	}
+	sort.Slice(flags, func(i, j int) bool {
+		return flags[i].Key < flags[j].Key
+	})
+	return flags, nil
+}
+
+// Update updates an existing flag
+func (r *FeatureFlagRepositoryMock) Update(ctx context.Context, flag *entity.FeatureFlag) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.flags[flag.Key]; !ok {
+		return errors.ErrNotFound
+	}
+	r.flags[flag.Key] = flag
+	return nil
+}
+
+// Delete deletes a flag by key
+func (r *FeatureFlagRepositoryMock) Delete(ctx context.Context, key string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.flags[key]; !ok {
+		return errors.ErrNotFound
+	}
+	delete(r.flags, key)
+	return nil
+}
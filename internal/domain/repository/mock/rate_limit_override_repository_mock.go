@@ -0,0 +1,99 @@
+package mock
+
+import (
+	"context"
+	"sort"
+	"sync"
+
+	"api-gateway-sample/internal/domain/entity"
+	"api-gateway-sample/internal/domain/repository"
+	"api-gateway-sample/pkg/errors"
+)
+
+// RateLimitOverrideRepositoryMock is a mock implementation of the RateLimitOverrideRepository interface
+type RateLimitOverrideRepositoryMock struct {
+	overrides map[string]*entity.RateLimitOverride
+	mu        sync.RWMutex
+}
+
+// NewRateLimitOverrideRepositoryMock creates a new RateLimitOverrideRepositoryMock instance
+func NewRateLimitOverrideRepositoryMock() repository.RateLimitOverrideRepository {
+	return &RateLimitOverrideRepositoryMock{
+		overrides: make(map[string]*entity.RateLimitOverride),
+	}
+}
+
+// Create creates a new override
+func (r *RateLimitOverrideRepositoryMock) Create(ctx context.Context, override *entity.RateLimitOverride) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.overrides[override.ID]; ok {
+		return errors.ErrAlreadyExists
+	}
+	r.overrides[override.ID] = override
+	return nil
+}
+
+// Get retrieves an override by ID
+func (r *RateLimitOverrideRepositoryMock) Get(ctx context.Context, id string) (*entity.RateLimitOverride, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	override, ok := r.overrides[id]
+	if !ok {
+		return nil, errors.ErrNotFound
+	}
+	return override, nil
+}
+
+// GetAll retrieves all overrides ordered by ID
+func (r *RateLimitOverrideRepositoryMock) GetAll(ctx context.Context) ([]*entity.RateLimitOverride, error) {
+	return r.find(func(*entity.RateLimitOverride) bool { return true }), nil
+}
+
+// FindByConsumer retrieves the overrides of a consumer ordered by ID
+func (r *RateLimitOverrideRepositoryMock) FindByConsumer(ctx context.Context, consumer string) ([]*entity.RateLimitOverride, error) {
+	return r.find(func(override *entity.RateLimitOverride) bool { return override.Consumer == consumer }), nil
+}
+
+// Update updates an existing override
+func (r *RateLimitOverrideRepositoryMock) Update(ctx context.Context, override *entity.RateLimitOverride) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.overrides[override.ID]; !ok {
+		return errors.ErrNotFound
+	}
+	r.overrides[override.ID] = override
+	return nil
+}
+
+// Delete deletes an override by ID
+func (r *RateLimitOverrideRepositoryMock) Delete(ctx context.Context, id string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.overrides[id]; !ok {
+		return errors.ErrNotFound
+	}
+	delete(r.overrides, id)
+	return nil
+}
+
+// find returns the overrides matching a filter ordered by ID
+func (r *RateLimitOverrideRepositoryMock) find(match func(*entity.RateLimitOverride) bool) []*entity.RateLimitOverride {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	overrides := make([]*entity.RateLimitOverride, 0, len(r.overrides))
+	for _, override := range r.overrides {
+		if match(override) {
+			overrides = append(overrides, override)
+		}
+	}
+	sort.Slice(overrides, func(i, j int) bool {
+		return overrides[i].ID < overrides[j].ID
+	})
+	return overrides
+}
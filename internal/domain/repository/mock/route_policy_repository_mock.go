@@ -0,0 +1,99 @@
+package mock
+
+import (
+	"context"
+	"sort"
+	"sync"
+
+	"api-gateway-sample/internal/domain/entity"
+	"api-gateway-sample/internal/domain/repository"
+	"api-gateway-sample/pkg/errors"
+)
+
+// RoutePolicyRepositoryMock is a mock implementation of the RoutePolicyRepository interface
+type RoutePolicyRepositoryMock struct {
+	policies map[string]*entity.RoutePolicy
+	mu       sync.RWMutex
+}
+
+// NewRoutePolicyRepositoryMock creates a new RoutePolicyRepositoryMock instance
+func NewRoutePolicyRepositoryMock() repository.RoutePolicyRepository {
+	return &RoutePolicyRepositoryMock{
+		policies: make(map[string]*entity.RoutePolicy),
+	}
+}
+
+// Create creates a new policy
+func (r *RoutePolicyRepositoryMock) Create(ctx context.Context, policy *entity.RoutePolicy) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.policies[policy.ID]; ok {
+		return errors.ErrAlreadyExists
+	}
+	r.policies[policy.ID] = policy
+	return nil
+}
+
+// Get retrieves a policy by ID
+func (r *RoutePolicyRepositoryMock) Get(ctx context.Context, id string) (*entity.RoutePolicy, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	policy, ok := r.policies[id]
+	if !ok {
+		return nil, errors.ErrNotFound
+	}
+	return policy, nil
+}
+
+// GetAll retrieves all policies ordered by ID
+func (r *RoutePolicyRepositoryMock) GetAll(ctx context.Context) ([]*entity.RoutePolicy, error) {
+	return r.find(func(*entity.RoutePolicy) bool { return true }), nil
+}
+
+// FindByService retrieves the policies of a service ordered by ID
+func (r *RoutePolicyRepositoryMock) FindByService(ctx context.Context, serviceID string) ([]*entity.RoutePolicy, error) {
+	return r.find(func(policy *entity.RoutePolicy) bool { return policy.ServiceID == serviceID }), nil
+}
+
+// Update updates an existing policy
+func (r *RoutePolicyRepositoryMock) Update(ctx context.Context, policy *entity.RoutePolicy) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.policies[policy.ID]; !ok {
+		return errors.ErrNotFound
+	}
+	r.policies[policy.ID] = policy
+	return nil
+}
+
+// Delete deletes a policy by ID
+func (r *RoutePolicyRepositoryMock) Delete(ctx context.Context, id string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.policies[id]; !ok {
+		return errors.ErrNotFound
+	}
+	delete(r.policies, id)
+	return nil
+}
+
+// find returns the policies matching a filter ordered by ID
+func (r *RoutePolicyRepositoryMock) find(match func(*entity.RoutePolicy) bool) []*entity.RoutePolicy {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	policies := make([]*entity.RoutePolicy, 0, len(r.policies))
+	for _, policy := range r.policies {
+		if match(policy) {
+			policies = append(policies, policy)
+		}
+	}
+	sort.Slice(policies, func(i, j int) bool {
+		return policies[i].ID < policies[j].ID
+	})
+	return policies
+}
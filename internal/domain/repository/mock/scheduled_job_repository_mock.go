@@ -0,0 +1,87 @@
+package mock
+
+import (
+	"context"
+	"sort"
+	"sync"
+
+	"api-gateway-sample/internal/domain/entity"
+	"api-gateway-sample/internal/domain/repository"
+	"api-gateway-sample/pkg/errors"
+)
+
+// ScheduledJobRepositoryMock is a mock implementation of the ScheduledJobRepository interface
+type ScheduledJobRepositoryMock struct {
+	jobs map[string]*entity.ScheduledJob
+	mu   sync.RWMutex
+}
+
+// NewScheduledJobRepositoryMock creates a new ScheduledJobRepositoryMock instance
+func NewScheduledJobRepositoryMock() repository.ScheduledJobRepository {
+	return &ScheduledJobRepositoryMock{
+		jobs: make(map[string]*entity.ScheduledJob),
+	}
+}
+
+// Create creates a new scheduled job
+func (r *ScheduledJobRepositoryMock) Create(ctx context.Context, job *entity.ScheduledJob) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.jobs[job.ID]; ok {
+		return errors.ErrAlreadyExists
+	}
+	r.jobs[job.ID] = job
+	return nil
+}
+
+// Get retrieves a scheduled job by ID
+func (r *ScheduledJobRepositoryMock) Get(ctx context.Context, id string) (*entity.ScheduledJob, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	job, ok := r.jobs[id]
+	if !ok {
+		return nil, errors.ErrNotFound
+	}
+	return job, nil
+}
+
+// GetAll retrieves all scheduled jobs ordered by ID
+func (r *ScheduledJobRepositoryMock) GetAll(ctx context.Context) ([]*entity.ScheduledJob, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	jobs := make([]*entity.ScheduledJob, 0, len(r.jobs))
+	for _, job := range r.jobs {
+		jobs = append(jobs, job)
+	}
+	sort.Slice(jobs, func(i, j int) bool {
+		return jobs[i].ID < jobs[j].ID
+	})
+	return jobs, nil
+}
+
+// Update updates an existing scheduled job
+func (r *ScheduledJobRepositoryMock) Update(ctx context.Context, job *entity.ScheduledJob) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.jobs[job.ID]; !ok {
+		return errors.ErrNotFound
+	}
+	r.jobs[job.ID] = job
+	return nil
+}
+
+// Delete deletes a scheduled job by ID
+func (r *ScheduledJobRepositoryMock) Delete(ctx context.Context, id string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.jobs[id]; !ok {
+		return errors.ErrNotFound
+	}
+	delete(r.jobs, id)
+	return nil
+}
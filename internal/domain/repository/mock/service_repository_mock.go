@@ -27,16 +27,19 @@ func (r *ServiceRepositoryMock) Create(ctx context.Context, service *entity.Serv
 	r.mu.Lock()
 	defer r.mu.Unlock()
 
-	// Check if service with the same name already exists
+	// Generate ID if not provided
+	if service.ID == "" {
+		service.ID = "test-id"
+	}
+
+	// Check if service with the same ID or name already exists
 	for _, s := range r.services {
-		if s.Name == service.Name {
+		if s.ID == service.ID || s.Name == service.Name {
 			return errors.ErrAlreadyExists
 		}
 	}
-
-	// Generate ID if not provided
-	if service.ID == "" {
-		service.ID = "test-id"
+	if service.Revision == 0 {
+		service.Revision = 1
 	}
 
 	r.services[service.ID] = service
@@ -66,9 +69,13 @@ func (r *ServiceRepositoryMock) Update(ctx context.Context, service *entity.Serv
 	r.mu.Lock()
 	defer r.mu.Unlock()
 
-	if _, ok := r.services[service.ID]; !ok {
+	existing, ok := r.services[service.ID]
+	if !ok {
 		return errors.ErrNotFound
 	}
+	if service.Revision > 0 && service.Revision != existing.Revision {
+		return errors.ErrPreconditionFailed
+	}
 
 	// Check if new name is already taken by another service
 	for _, s := range r.services {
@@ -77,18 +84,23 @@ func (r *ServiceRepositoryMock) Update(ctx context.Context, service *entity.Serv
 		}
 	}
 
+	service.Revision = existing.Revision + 1
 	r.services[service.ID] = service
 	return nil
 }
 
 // Delete deletes a service by ID
-func (r *ServiceRepositoryMock) Delete(ctx context.Context, id string) error {
+func (r *ServiceRepositoryMock) Delete(ctx context.Context, id string, revision int64) error {
 	r.mu.Lock()
 	defer r.mu.Unlock()
 
-	if _, ok := r.services[id]; !ok {
+	existing, ok := r.services[id]
+	if !ok {
 		return errors.ErrNotFound
 	}
+	if revision > 0 && revision != existing.Revision {
+		return errors.ErrPreconditionFailed
+	}
 
 	delete(r.services, id)
 	return nil
@@ -155,3 +167,38 @@ func (r *ServiceRepositoryMock) GetByEndpoint(ctx context.Context, path string,
 
 	return matchingServices, nil
 }
+
+// Apply makes the changes of a change set, restoring the previous services when one fails
+func (r *ServiceRepositoryMock) Apply(ctx context.Context, changes repository.ServiceChangeSet) error {
+	r.mu.RLock()
+	snapshot := make(map[string]*entity.Service, len(r.services))
+	for id, service := range r.services {
+		snapshot[id] = service
+	}
+	r.mu.RUnlock()
+
+	err := func() error {
+		for _, id := range changes.Delete {
+			if err := r.Delete(ctx, id, 0); err != nil {
+				return err
+			}
+		}
+		for _, service := range changes.Update {
+			if err := r.Update(ctx, service); err != nil {
+				return err
+			}
+		}
+		for _, service := range changes.Create {
+			if err := r.Create(ctx, service); err != nil {
+				return err
+			}
+		}
+		return nil
+	}()
+	if err != nil {
+		r.mu.Lock()
+		r.services = snapshot
+		r.mu.Unlock()
+	}
+	return err
+}
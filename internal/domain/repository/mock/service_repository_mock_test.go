@@ -5,6 +5,8 @@ import (
 	"testing"
 
 	"api-gateway-sample/internal/domain/entity"
+	"api-gateway-sample/internal/domain/repository"
+	"api-gateway-sample/internal/domain/repository/repositorytest"
 	"api-gateway-sample/pkg/errors"
 )
 
@@ -174,7 +176,7 @@ func TestServiceRepositoryMock(t *testing.T) {
 
 	// Test Delete
 	t.Run("Delete", func(t *testing.T) {
-		err := repo.Delete(context.Background(), "test-id")
+		err := repo.Delete(context.Background(), "test-id", 0)
 		if err != nil {
 			t.Errorf("Failed to delete service: %v", err)
 		}
@@ -186,7 +188,7 @@ func TestServiceRepositoryMock(t *testing.T) {
 		}
 
 		// Try to delete a non-existent service
-		err = repo.Delete(context.Background(), "non-existent-id")
+		err = repo.Delete(context.Background(), "non-existent-id", 0)
 		if err != errors.ErrNotFound {
 			t.Errorf("Expected ErrNotFound, got %v", err)
 		}
@@ -207,3 +209,9 @@ func TestServiceRepositoryMock(t *testing.T) {
 		}
 	})
 }
+
+func TestServiceRepositoryMock_Contract(t *testing.T) {
+	repositorytest.TestServiceRepository(t, func(t *testing.T) repository.ServiceRepository {
+		return NewServiceRepositoryMock()
+	})
+}
@@ -0,0 +1,63 @@
+package mock
+
+import (
+	"context"
+	"sort"
+	"sync"
+
+	"api-gateway-sample/internal/domain/entity"
+	"api-gateway-sample/internal/domain/repository"
+	"api-gateway-sample/pkg/errors"
+)
+
+// ServiceRevisionRepositoryMock is a mock implementation of the ServiceRevisionRepository interface
+type ServiceRevisionRepositoryMock struct {
+	revisions map[string][]*entity.ServiceRevision
+	mu        sync.RWMutex
+}
+
+// NewServiceRevisionRepositoryMock creates a new ServiceRevisionRepositoryMock instance
+func NewServiceRevisionRepositoryMock() repository.ServiceRevisionRepository {
+	return &ServiceRevisionRepositoryMock{
+		revisions: make(map[string][]*entity.ServiceRevision),
+	}
+}
+
+// Create stores a revision
+func (r *ServiceRevisionRepositoryMock) Create(ctx context.Context, revision *entity.ServiceRevision) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, existing := range r.revisions[revision.ServiceID] {
+		if existing.Revision == revision.Revision {
+			return errors.ErrAlreadyExists
+		}
+	}
+	r.revisions[revision.ServiceID] = append(r.revisions[revision.ServiceID], revision)
+	return nil
+}
+
+// Get retrieves a revision of a service
+func (r *ServiceRevisionRepositoryMock) Get(ctx context.Context, serviceID string, revision int64) (*entity.ServiceRevision, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for _, existing := range r.revisions[serviceID] {
+		if existing.Revision == revision {
+			return existing, nil
+		}
+	}
+	return nil, errors.ErrNotFound
+}
+
+// List retrieves the revisions of a service, newest first
+func (r *ServiceRevisionRepositoryMock) List(ctx context.Context, serviceID string) ([]*entity.ServiceRevision, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	revisions := append([]*entity.ServiceRevision(nil), r.revisions[serviceID]...)
+	sort.Slice(revisions, func(i, j int) bool {
+		return revisions[i].Revision > revisions[j].Revision
+	})
+	return revisions, nil
+}
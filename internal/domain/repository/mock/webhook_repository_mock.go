@@ -0,0 +1,75 @@
+package mock
+
+import (
+	"context"
+	"sort"
+	"sync"
+
+	"api-gateway-sample/internal/domain/entity"
+	"api-gateway-sample/internal/domain/repository"
+	"api-gateway-sample/pkg/errors"
+)
+
+// WebhookRepositoryMock is a mock implementation of the WebhookRepository interface
+type WebhookRepositoryMock struct {
+	webhooks map[string]*entity.WebhookSubscription
+	mu       sync.RWMutex
+}
+
+// NewWebhookRepositoryMock creates a new WebhookRepositoryMock instance
+func NewWebhookRepositoryMock() repository.WebhookRepository {
+	return &WebhookRepositoryMock{
+		webhooks: make(map[string]*entity.WebhookSubscription),
+	}
+}
+
+// Create creates a new webhook subscription
+func (r *WebhookRepositoryMock) Create(ctx context.Context, webhook *entity.WebhookSubscription) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.webhooks[webhook.ID]; ok {
+		return errors.ErrAlreadyExists
+	}
+	r.webhooks[webhook.ID] = webhook
+	return nil
+}
+
+// Get retrieves a webhook subscription by ID
+func (r *WebhookRepositoryMock) Get(ctx context.Context, id string) (*entity.WebhookSubscription, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	webhook, ok := r.webhooks[id]
+	if !ok {
+		return nil, errors.ErrNotFound
+	}
+	return webhook, nil
+}
+
+// GetAll retrieves all webhook subscriptions ordered by ID
+func (r *WebhookRepositoryMock) GetAll(ctx context.Context) ([]*entity.WebhookSubscription, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	webhooks := make([]*entity.WebhookSubscription, 0, len(r.webhooks))
+	for _, webhook := range r.webhooks {
+		webhooks = append(webhooks, webhook)
+	}
+	sort.Slice(webhooks, func(i, j int) bool {
+		return webhooks[i].ID < webhooks[j].ID
+	})
+	return webhooks, nil
+}
+
+// Delete deletes a webhook subscription by ID
+func (r *WebhookRepositoryMock) Delete(ctx context.Context, id string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.webhooks[id]; !ok {
+		return errors.ErrNotFound
+	}
+	delete(r.webhooks, id)
+	return nil
+}
@@ -0,0 +1,28 @@
+package repository
+
+import (
+	"context"
+
+	"api-gateway-sample/internal/domain/entity"
+)
+
+// RateLimitOverrideRepository defines the interface for per-consumer rate limit override operations
+type RateLimitOverrideRepository interface {
+	// Create creates a new override
+	Create(ctx context.Context, override *entity.RateLimitOverride) error
+
+	// Get retrieves an override by ID
+	Get(ctx context.Context, id string) (*entity.RateLimitOverride, error)
+
+	// GetAll retrieves all overrides
+	GetAll(ctx context.Context) ([]*entity.RateLimitOverride, error)
+
+	// FindByConsumer retrieves the overrides of a consumer
+	FindByConsumer(ctx context.Context, consumer string) ([]*entity.RateLimitOverride, error)
+
+	// Update updates an existing override
+	Update(ctx context.Context, override *entity.RateLimitOverride) error
+
+	// Delete deletes an override by ID
+	Delete(ctx context.Context, id string) error
+}
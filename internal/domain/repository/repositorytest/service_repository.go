@@ -0,0 +1,236 @@
+// Package repositorytest holds the contract tests of the domain repositories. Every
+// implementation runs them, so the storage backends and the mocks behave the same.
+package repositorytest
+
+import (
+	"context"
+	"testing"
+
+	"api-gateway-sample/internal/domain/entity"
+	"api-gateway-sample/internal/domain/repository"
+	"api-gateway-sample/pkg/errors"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestServiceRepository runs the ServiceRepository contract against the repositories created by
+// newRepository, which must each start empty
+func TestServiceRepository(t *testing.T, newRepository func(t *testing.T) repository.ServiceRepository) {
+	ctx := context.Background()
+
+	t.Run("CreateAndGet", func(t *testing.T) {
+		repo := newRepository(t)
+		service := newService("svc-orders", "orders", entity.Endpoint{Path: "/api/orders", Methods: []string{"GET", "POST"}, RateLimit: 10})
+		require.NoError(t, repo.Create(ctx, service))
+		assert.Equal(t, int64(1), service.Revision)
+
+		for _, get := range []func(context.Context, string) (*entity.Service, error){repo.Get, repo.GetByID} {
+			got, err := get(ctx, "svc-orders")
+			require.NoError(t, err)
+			assert.Equal(t, "orders", got.Name)
+			assert.Equal(t, "http://orders:8080", got.BaseURL)
+			assert.Equal(t, int64(1), got.Revision)
+			require.Len(t, got.Endpoints, 1)
+			assert.Equal(t, "/api/orders", got.Endpoints[0].Path)
+			assert.Equal(t, []string{"GET", "POST"}, got.Endpoints[0].Methods)
+			assert.Equal(t, 10, got.Endpoints[0].RateLimit)
+		}
+
+		got, err := repo.FindByName(ctx, "orders")
+		require.NoError(t, err)
+		assert.Equal(t, "svc-orders", got.ID)
+	})
+
+	t.Run("NotFound", func(t *testing.T) {
+		repo := newRepository(t)
+		_, err := repo.Get(ctx, "svc-missing")
+		assert.ErrorIs(t, err, errors.ErrNotFound)
+		_, err = repo.GetByID(ctx, "svc-missing")
+		assert.ErrorIs(t, err, errors.ErrNotFound)
+		_, err = repo.FindByName(ctx, "missing")
+		assert.ErrorIs(t, err, errors.ErrNotFound)
+		_, err = repo.GetByEndpoint(ctx, "/api/missing", "GET")
+		assert.ErrorIs(t, err, errors.ErrNotFound)
+		assert.ErrorIs(t, repo.Update(ctx, newService("svc-missing", "missing")), errors.ErrNotFound)
+		assert.ErrorIs(t, repo.Delete(ctx, "svc-missing", 0), errors.ErrNotFound)
+	})
+
+	t.Run("CreateDuplicate", func(t *testing.T) {
+		repo := newRepository(t)
+		require.NoError(t, repo.Create(ctx, newService("svc-orders", "orders")))
+
+		assert.ErrorIs(t, repo.Create(ctx, newService("svc-other", "orders")), errors.ErrAlreadyExists)
+		assert.ErrorIs(t, repo.Create(ctx, newService("svc-orders", "other")), errors.ErrAlreadyExists)
+
+		services, err := repo.GetAll(ctx)
+		require.NoError(t, err)
+		assert.Len(t, services, 1)
+	})
+
+	t.Run("GetAll", func(t *testing.T) {
+		repo := newRepository(t)
+		services, err := repo.GetAll(ctx)
+		require.NoError(t, err)
+		assert.Empty(t, services)
+
+		require.NoError(t, repo.Create(ctx, newService("svc-orders", "orders")))
+		require.NoError(t, repo.Create(ctx, newService("svc-users", "users")))
+		services, err = repo.GetAll(ctx)
+		require.NoError(t, err)
+		assert.ElementsMatch(t, []string{"svc-orders", "svc-users"}, serviceIDs(services))
+	})
+
+	t.Run("Update", func(t *testing.T) {
+		repo := newRepository(t)
+		require.NoError(t, repo.Create(ctx, newService("svc-orders", "orders", entity.Endpoint{Path: "/api/orders", Methods: []string{"GET"}})))
+		require.NoError(t, repo.Create(ctx, newService("svc-users", "users")))
+
+		// An update at the stored revision is made and increments it
+		update := newService("svc-orders", "orders", entity.Endpoint{Path: "/api/v2/orders", Methods: []string{"POST"}})
+		update.Description = "Order service"
+		update.Revision = 1
+		require.NoError(t, repo.Update(ctx, update))
+		assert.Equal(t, int64(2), update.Revision)
+
+		got, err := repo.Get(ctx, "svc-orders")
+		require.NoError(t, err)
+		assert.Equal(t, "Order service", got.Description)
+		assert.Equal(t, int64(2), got.Revision)
+		require.Len(t, got.Endpoints, 1)
+		assert.Equal(t, "/api/v2/orders", got.Endpoints[0].Path)
+
+		// An update at an older revision is refused and changes nothing
+		stale := newService("svc-orders", "orders")
+		stale.Description = "Stale"
+		stale.Revision = 1
+		assert.ErrorIs(t, repo.Update(ctx, stale), errors.ErrPreconditionFailed)
+		got, err = repo.Get(ctx, "svc-orders")
+		require.NoError(t, err)
+		assert.Equal(t, "Order service", got.Description)
+		assert.Equal(t, int64(2), got.Revision)
+
+		// An update without a revision is made unconditionally and still increments it
+		unconditional := newService("svc-orders", "orders")
+		require.NoError(t, repo.Update(ctx, unconditional))
+		assert.Equal(t, int64(3), unconditional.Revision)
+		got, err = repo.Get(ctx, "svc-orders")
+		require.NoError(t, err)
+		assert.Equal(t, int64(3), got.Revision)
+
+		// The name of another service cannot be taken
+		assert.ErrorIs(t, repo.Update(ctx, newService("svc-orders", "users")), errors.ErrAlreadyExists)
+	})
+
+	t.Run("Delete", func(t *testing.T) {
+		repo := newRepository(t)
+		require.NoError(t, repo.Create(ctx, newService("svc-orders", "orders", entity.Endpoint{Path: "/api/orders", Methods: []string{"GET"}})))
+
+		// A deletion at another revision is refused and changes nothing
+		assert.ErrorIs(t, repo.Delete(ctx, "svc-orders", 2), errors.ErrPreconditionFailed)
+		_, err := repo.Get(ctx, "svc-orders")
+		require.NoError(t, err)
+
+		require.NoError(t, repo.Delete(ctx, "svc-orders", 1))
+		_, err = repo.Get(ctx, "svc-orders")
+		assert.ErrorIs(t, err, errors.ErrNotFound)
+		_, err = repo.GetByEndpoint(ctx, "/api/orders", "GET")
+		assert.ErrorIs(t, err, errors.ErrNotFound)
+		assert.ErrorIs(t, repo.Delete(ctx, "svc-orders", 0), errors.ErrNotFound)
+
+		// The name is free again
+		require.NoError(t, repo.Create(ctx, newService("svc-orders-v2", "orders")))
+	})
+
+	t.Run("GetByEndpoint", func(t *testing.T) {
+		repo := newRepository(t)
+		require.NoError(t, repo.Create(ctx, newService("svc-orders", "orders",
+			entity.Endpoint{Path: "/api/orders", Methods: []string{"GET", "POST"}},
+			entity.Endpoint{Path: "/api/orders/*", Methods: []string{"GET"}},
+		)))
+		require.NoError(t, repo.Create(ctx, newService("svc-audit", "audit", entity.Endpoint{Path: "/api/orders", Methods: []string{"*"}})))
+		require.NoError(t, repo.Create(ctx, newService("svc-users", "users", entity.Endpoint{Path: "/api/users", Methods: []string{"GET"}})))
+
+		services, err := repo.GetByEndpoint(ctx, "/api/orders", "POST")
+		require.NoError(t, err)
+		assert.ElementsMatch(t, []string{"svc-orders", "svc-audit"}, serviceIDs(services))
+
+		// A wildcard method serves any method, and paths match exactly
+		services, err = repo.GetByEndpoint(ctx, "/api/orders", "DELETE")
+		require.NoError(t, err)
+		assert.Equal(t, []string{"svc-audit"}, serviceIDs(services))
+		services, err = repo.GetByEndpoint(ctx, "/api/orders/*", "GET")
+		require.NoError(t, err)
+		assert.Equal(t, []string{"svc-orders"}, serviceIDs(services))
+
+		// Method names are not matched by substring
+		_, err = repo.GetByEndpoint(ctx, "/api/users", "GE")
+		assert.ErrorIs(t, err, errors.ErrNotFound)
+		_, err = repo.GetByEndpoint(ctx, "/api/users/1", "GET")
+		assert.ErrorIs(t, err, errors.ErrNotFound)
+	})
+
+	t.Run("Apply", func(t *testing.T) {
+		repo := newRepository(t)
+		require.NoError(t, repo.Create(ctx, newService("svc-orders", "orders")))
+		require.NoError(t, repo.Create(ctx, newService("svc-users", "users")))
+
+		update := newService("svc-users", "users")
+		update.Description = "User service"
+		require.NoError(t, repo.Apply(ctx, repository.ServiceChangeSet{
+			Delete: []string{"svc-orders"},
+			Update: []*entity.Service{update},
+			// Deletions come first, so the name of a deleted service can be reused
+			Create: []*entity.Service{newService("svc-orders-v2", "orders")},
+		}))
+
+		services, err := repo.GetAll(ctx)
+		require.NoError(t, err)
+		assert.ElementsMatch(t, []string{"svc-users", "svc-orders-v2"}, serviceIDs(services))
+		got, err := repo.Get(ctx, "svc-users")
+		require.NoError(t, err)
+		assert.Equal(t, "User service", got.Description)
+		assert.Equal(t, int64(2), got.Revision)
+	})
+
+	t.Run("ApplyFailure", func(t *testing.T) {
+		repo := newRepository(t)
+		require.NoError(t, repo.Create(ctx, newService("svc-orders", "orders")))
+		require.NoError(t, repo.Create(ctx, newService("svc-users", "users")))
+
+		// The failing creation undoes the deletion and update before it
+		update := newService("svc-users", "users")
+		update.Description = "User service"
+		err := repo.Apply(ctx, repository.ServiceChangeSet{
+			Delete: []string{"svc-orders"},
+			Update: []*entity.Service{update},
+			Create: []*entity.Service{newService("svc-users-v2", "users")},
+		})
+		assert.ErrorIs(t, err, errors.ErrAlreadyExists)
+
+		services, err := repo.GetAll(ctx)
+		require.NoError(t, err)
+		assert.ElementsMatch(t, []string{"svc-orders", "svc-users"}, serviceIDs(services))
+		got, err := repo.Get(ctx, "svc-users")
+		require.NoError(t, err)
+		assert.Empty(t, got.Description)
+		assert.Equal(t, int64(1), got.Revision)
+	})
+}
+
+// newService returns a service with the given endpoints
+func newService(id, name string, endpoints ...entity.Endpoint) *entity.Service {
+	service := entity.NewService(id, name, "1.0.0", "", "http://"+name+":8080", 30, 3)
+	for _, endpoint := range endpoints {
+		service.AddEndpoint(endpoint)
+	}
+	return service
+}
+
+func serviceIDs(services []*entity.Service) []string {
+	ids := make([]string, len(services))
+	for i, service := range services {
+		ids[i] = service.ID
+	}
+	return ids
+}
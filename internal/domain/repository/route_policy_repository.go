@@ -0,0 +1,28 @@
+package repository
+
+import (
+	"context"
+
+	"api-gateway-sample/internal/domain/entity"
+)
+
+// RoutePolicyRepository defines the interface for scheduled route policy operations
+type RoutePolicyRepository interface {
+	// Create creates a new policy
+	Create(ctx context.Context, policy *entity.RoutePolicy) error
+
+	// Get retrieves a policy by ID
+	Get(ctx context.Context, id string) (*entity.RoutePolicy, error)
+
+	// GetAll retrieves all policies
+	GetAll(ctx context.Context) ([]*entity.RoutePolicy, error)
+
+	// FindByService retrieves the policies of a service
+	FindByService(ctx context.Context, serviceID string) ([]*entity.RoutePolicy, error)
+
+	// Update updates an existing policy
+	Update(ctx context.Context, policy *entity.RoutePolicy) error
+
+	// Delete deletes a policy by ID
+	Delete(ctx context.Context, id string) error
+}
@@ -0,0 +1,25 @@
+package repository
+
+import (
+	"context"
+
+	"api-gateway-sample/internal/domain/entity"
+)
+
+// ScheduledJobRepository defines the interface for scheduled job operations
+type ScheduledJobRepository interface {
+	// Create creates a new scheduled job
+	Create(ctx context.Context, job *entity.ScheduledJob) error
+
+	// Get retrieves a scheduled job by ID
+	Get(ctx context.Context, id string) (*entity.ScheduledJob, error)
+
+	// GetAll retrieves all scheduled jobs
+	GetAll(ctx context.Context) ([]*entity.ScheduledJob, error)
+
+	// Update updates an existing scheduled job
+	Update(ctx context.Context, job *entity.ScheduledJob) error
+
+	// Delete deletes a scheduled job by ID
+	Delete(ctx context.Context, id string) error
+}
@@ -17,11 +17,15 @@ type ServiceRepository interface {
 	// GetByID retrieves a service by ID (alias for Get)
 	GetByID(ctx context.Context, id string) (*entity.Service, error)
 
-	// Update updates an existing service
+	// Update updates an existing service and increments its revision. When service.Revision
+	// is set, the update fails with errors.ErrPreconditionFailed unless it matches the stored
+	// revision; a zero revision updates unconditionally.
 	Update(ctx context.Context, service *entity.Service) error
 
-	// Delete deletes a service by ID
-	Delete(ctx context.Context, id string) error
+	// Delete deletes a service by ID. When revision is set, the deletion fails with
+	// errors.ErrPreconditionFailed unless it matches the stored revision; a zero revision
+	// deletes unconditionally.
+	Delete(ctx context.Context, id string, revision int64) error
 
 	// GetAll retrieves all services
 	GetAll(ctx context.Context) ([]*entity.Service, error)
@@ -31,4 +35,16 @@ type ServiceRepository interface {
 
 	// GetByEndpoint finds services by endpoint path and method
 	GetByEndpoint(ctx context.Context, path string, method string) ([]*entity.Service, error)
+
+	// Apply makes the changes of a change set in a single transaction: deletions first, then
+	// updates, then creations. When one of them fails, none is made and its error is returned.
+	Apply(ctx context.Context, changes ServiceChangeSet) error
+}
+
+// ServiceChangeSet is a set of service changes applied together. Updates follow the revision
+// rules of Update.
+type ServiceChangeSet struct {
+	Create []*entity.Service
+	Update []*entity.Service
+	Delete []string
 }
@@ -0,0 +1,19 @@
+package repository
+
+import (
+	"context"
+
+	"api-gateway-sample/internal/domain/entity"
+)
+
+// ServiceRevisionRepository defines the interface for the revision history of services
+type ServiceRevisionRepository interface {
+	// Create stores a revision
+	Create(ctx context.Context, revision *entity.ServiceRevision) error
+
+	// Get retrieves a revision of a service
+	Get(ctx context.Context, serviceID string, revision int64) (*entity.ServiceRevision, error)
+
+	// List retrieves the revisions of a service, newest first
+	List(ctx context.Context, serviceID string) ([]*entity.ServiceRevision, error)
+}
@@ -0,0 +1,22 @@
+package repository
+
+import (
+	"context"
+
+	"api-gateway-sample/internal/domain/entity"
+)
+
+// WebhookRepository defines the interface for webhook subscription operations
+type WebhookRepository interface {
+	// Create creates a new webhook subscription
+	Create(ctx context.Context, webhook *entity.WebhookSubscription) error
+
+	// Get retrieves a webhook subscription by ID
+	Get(ctx context.Context, id string) (*entity.WebhookSubscription, error)
+
+	// GetAll retrieves all webhook subscriptions
+	GetAll(ctx context.Context) ([]*entity.WebhookSubscription, error)
+
+	// Delete deletes a webhook subscription by ID
+	Delete(ctx context.Context, id string) error
+}
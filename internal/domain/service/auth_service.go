@@ -18,4 +18,7 @@ type AuthService interface {
 
 	// ValidateToken validates an authentication token
 	ValidateToken(ctx context.Context, token string) (map[string]interface{}, error)
+
+	// PublicKeys returns the public verification keys as a JWKS document
+	PublicKeys(ctx context.Context) (*entity.JSONWebKeySet, error)
 }
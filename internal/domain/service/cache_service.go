@@ -18,4 +18,7 @@ type CacheService interface {
 
 	// Clear removes all values from the cache
 	Clear(ctx context.Context) error
+
+	// ClearMatching removes the values whose keys match a glob pattern, such as "orders:*"
+	ClearMatching(ctx context.Context, pattern string) error
 }
@@ -0,0 +1,12 @@
+package service
+
+import "context"
+
+// ConcurrencyLimiter defines the interface for bounding the requests a client has in flight
+type ConcurrencyLimiter interface {
+	// Acquire takes one of limit slots under key, reporting false if they are all in use
+	Acquire(ctx context.Context, key string, limit int) (bool, error)
+
+	// Release returns a slot taken by Acquire
+	Release(ctx context.Context, key string) error
+}
@@ -0,0 +1,11 @@
+package service
+
+import (
+	"context"
+)
+
+// CredentialValidator defines the interface for validating username/password credentials
+type CredentialValidator interface {
+	// ValidateCredentials checks the credentials and returns the caller's claims
+	ValidateCredentials(ctx context.Context, username string, password string) (map[string]interface{}, error)
+}
@@ -0,0 +1,9 @@
+package service
+
+import "api-gateway-sample/internal/domain/entity"
+
+// ErrorReporter defines the interface for reporting recovered panics to an error tracker
+type ErrorReporter interface {
+	// ReportPanic queues a report for delivery without blocking the request that panicked
+	ReportPanic(report *entity.PanicReport)
+}
@@ -0,0 +1,24 @@
+package service
+
+import (
+	"api-gateway-sample/internal/domain/entity"
+	"context"
+)
+
+// EventHandler reacts to a published event
+type EventHandler func(ctx context.Context, event *entity.Event)
+
+// EventPublisher defines the interface for publishing gateway lifecycle events
+type EventPublisher interface {
+	// Publish delivers the event to every subscriber of its type
+	Publish(ctx context.Context, event *entity.Event)
+}
+
+// EventBus defines the interface for publishing and subscribing to gateway lifecycle events
+type EventBus interface {
+	EventPublisher
+
+	// Subscribe registers a handler for an event type, or for all events with entity.EventAll.
+	// The returned function removes the subscription.
+	Subscribe(eventType string, handler EventHandler) func()
+}
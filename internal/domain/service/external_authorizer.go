@@ -0,0 +1,12 @@
+package service
+
+import (
+	"api-gateway-sample/internal/domain/entity"
+	"context"
+)
+
+// ExternalAuthorizer defines the interface for delegating authorization to an external service
+type ExternalAuthorizer interface {
+	// Check asks the external service whether the request is allowed
+	Check(ctx context.Context, request *entity.Request, service *entity.Service, endpoint *entity.Endpoint) (*entity.AuthzDecision, error)
+}
@@ -0,0 +1,14 @@
+package service
+
+import (
+	"context"
+
+	"api-gateway-sample/internal/domain/entity"
+)
+
+// FeatureFlagProvider defines the interface for evaluating the feature flags routes are served behind
+type FeatureFlagProvider interface {
+	// Enabled reports whether a flag is on for a caller, nil for anonymous callers. Unknown
+	// flags are off.
+	Enabled(ctx context.Context, key string, principal *entity.Principal) (bool, error)
+}
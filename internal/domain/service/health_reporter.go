@@ -0,0 +1,9 @@
+package service
+
+import "api-gateway-sample/internal/domain/entity"
+
+// HealthReporter defines the interface for reporting the health of a backing dependency
+type HealthReporter interface {
+	// Health returns a snapshot of the dependency's connectivity
+	Health() *entity.DependencyHealth
+}
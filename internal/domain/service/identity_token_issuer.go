@@ -0,0 +1,14 @@
+package service
+
+import (
+	"context"
+
+	"api-gateway-sample/internal/domain/entity"
+)
+
+// IdentityTokenIssuer mints the tokens that tell services who called them through the gateway
+type IdentityTokenIssuer interface {
+	// IssueIdentityToken returns a short-lived token describing the principal and the route of
+	// a request about to be forwarded to the service
+	IssueIdentityToken(ctx context.Context, principal *entity.Principal, request *entity.Request, service *entity.Service, endpoint *entity.Endpoint) (string, error)
+}
@@ -0,0 +1,8 @@
+package service
+
+// LeaderElector defines the interface for electing the gateway instance that runs the background
+// tasks which must only run once across instances, such as scheduled jobs
+type LeaderElector interface {
+	// IsLeader reports whether this instance currently leads
+	IsLeader() bool
+}
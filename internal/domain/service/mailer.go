@@ -0,0 +1,9 @@
+package service
+
+import "context"
+
+// Mailer defines the interface for sending email to API consumers
+type Mailer interface {
+	// Send delivers a plain text message to a single recipient
+	Send(ctx context.Context, to string, subject string, body string) error
+}
@@ -0,0 +1,16 @@
+package service
+
+import (
+	"context"
+
+	"api-gateway-sample/internal/domain/entity"
+)
+
+// MessagePublisher defines the interface for publishing messages to a message broker
+type MessagePublisher interface {
+	// Publish sends a message. With at-least-once delivery it returns once the broker has
+	// acknowledged the message.
+	Publish(ctx context.Context, message *entity.Message) error
+	// Close flushes pending messages and closes the broker connection
+	Close() error
+}
@@ -0,0 +1,32 @@
+package service
+
+import (
+	"api-gateway-sample/internal/domain/entity"
+)
+
+// MetricsCollector defines the interface for aggregating request metrics
+type MetricsCollector interface {
+	// RecordRequest adds a request outcome to the aggregated metrics
+	RecordRequest(sample *entity.RequestSample)
+
+	// ServiceIDs returns the services that have recorded requests
+	ServiceIDs() []string
+
+	// ServiceStats returns the rolling statistics for a service
+	ServiceStats(serviceID string) *entity.ServiceStats
+
+	// TargetLatencies returns the upstream latency histogram of every upstream target
+	TargetLatencies() []*entity.TargetLatency
+
+	// CacheStats returns the response cache lookups of every endpoint since startup
+	CacheStats() []*entity.EndpointCacheStats
+
+	// SLOStatuses returns the compliance of every endpoint with an SLO over the rolling window
+	SLOStatuses() []*entity.SLOStatus
+
+	// RouteRequests returns the requests served by every endpoint since startup
+	RouteRequests() []*entity.RouteRequests
+
+	// UpstreamConnections returns the connection phases of every service's upstream requests
+	UpstreamConnections() []*entity.UpstreamConnectionStats
+}
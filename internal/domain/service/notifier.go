@@ -0,0 +1,12 @@
+package service
+
+import (
+	"api-gateway-sample/internal/domain/entity"
+	"context"
+)
+
+// Notifier defines the interface for delivering operational alerts
+type Notifier interface {
+	// Notify delivers an alert; repeated alerts may be suppressed
+	Notify(ctx context.Context, alert *entity.Alert) error
+}
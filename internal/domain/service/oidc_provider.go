@@ -0,0 +1,16 @@
+package service
+
+import (
+	"context"
+)
+
+// OIDCProvider defines the interface for logging users in with an OpenID Connect identity provider
+type OIDCProvider interface {
+	// AuthorizationURL returns the provider URL users are redirected to for logging in, carrying
+	// the state, the nonce and the S256 PKCE code challenge of the login
+	AuthorizationURL(ctx context.Context, state string, nonce string, codeChallenge string) (string, error)
+
+	// Exchange redeems an authorization code with the login's PKCE code verifier, validates the
+	// returned ID token against the login's nonce and returns the user's claims
+	Exchange(ctx context.Context, code string, codeVerifier string, nonce string) (map[string]interface{}, error)
+}
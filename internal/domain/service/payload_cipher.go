@@ -0,0 +1,13 @@
+package service
+
+import "context"
+
+// PayloadCipher decrypts the request bodies and encrypts the response bodies of endpoints that
+// configure payload encryption, with keys named after secrets
+type PayloadCipher interface {
+	// Decrypt returns the content of an encrypted body and its content type, empty when the
+	// body does not carry one
+	Decrypt(ctx context.Context, key string, body []byte) ([]byte, string, error)
+	// Encrypt returns the encrypted body of content of a content type
+	Encrypt(ctx context.Context, key string, content []byte, contentType string) ([]byte, error)
+}
@@ -0,0 +1,15 @@
+package service
+
+import (
+	"api-gateway-sample/internal/domain/entity"
+	"context"
+)
+
+// PolicyEngine defines the interface for evaluating authorization policies
+type PolicyEngine interface {
+	// Compile checks that a policy is valid
+	Compile(policy string) error
+
+	// Evaluate reports whether the policy allows the input
+	Evaluate(ctx context.Context, policy string, input *entity.PolicyInput) (bool, error)
+}
@@ -13,6 +13,7 @@ type RateLimitService interface {
 	// RecordRequest records a request for rate limiting purposes
 	RecordRequest(ctx context.Context, request *entity.Request, service *entity.Service, endpoint *entity.Endpoint) error
 
-	// GetLimit gets the current rate limit for a client
-	GetLimit(ctx context.Context, clientID string, service *entity.Service, endpoint *entity.Endpoint) (int, int, error)
+	// GetLimit gets the quota left to the client of a request, counted in the same bucket as
+	// CheckLimit and RecordRequest
+	GetLimit(ctx context.Context, request *entity.Request, service *entity.Service, endpoint *entity.Endpoint) (entity.RateLimitStatus, error)
 }
@@ -0,0 +1,13 @@
+package service
+
+import (
+	"context"
+
+	"api-gateway-sample/internal/domain/entity"
+)
+
+// RegionLocator locates the region of the callers of services with data residency
+type RegionLocator interface {
+	// Locate returns the region of a request's caller, empty when it is unknown
+	Locate(ctx context.Context, request *entity.Request) string
+}
@@ -0,0 +1,14 @@
+package service
+
+import (
+	"context"
+
+	"api-gateway-sample/internal/domain/entity"
+)
+
+// RequestSigner signs the requests the gateway sends to services that require it
+type RequestSigner interface {
+	// Sign adds the signature headers required by the service's signing scheme to a request
+	// about to be sent to it
+	Sign(ctx context.Context, request *entity.Request, service *entity.Service) error
+}
@@ -0,0 +1,19 @@
+package service
+
+import (
+	"context"
+)
+
+// SAMLServiceProvider defines the interface for logging users in with a SAML 2.0 identity provider
+type SAMLServiceProvider interface {
+	// Metadata returns the service provider metadata document to register with the identity provider
+	Metadata() ([]byte, error)
+
+	// AuthnRequestURL returns the identity provider URL users are redirected to for logging in,
+	// carrying an authentication request with the given ID and relay state
+	AuthnRequestURL(requestID string, relayState string) (string, error)
+
+	// ParseResponse validates a base64 encoded SAML response posted back by the identity provider
+	// and returns the ID of the request it answers and the user's claims
+	ParseResponse(ctx context.Context, encodedResponse string) (string, map[string]interface{}, error)
+}
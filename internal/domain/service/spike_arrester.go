@@ -0,0 +1,14 @@
+package service
+
+import (
+	"context"
+	"time"
+)
+
+// SpikeArrester defines the interface for spacing out the requests delivered to an endpoint
+type SpikeArrester interface {
+	// Reserve books the next delivery slot under key, interval after the previous one, and
+	// returns how long the request must wait for it. It reports false without booking a slot
+	// when the wait would exceed maxDelay.
+	Reserve(ctx context.Context, key string, interval time.Duration, maxDelay time.Duration) (time.Duration, bool, error)
+}
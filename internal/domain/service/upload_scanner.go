@@ -0,0 +1,15 @@
+package service
+
+import (
+	"context"
+	"io"
+
+	"api-gateway-sample/internal/domain/entity"
+)
+
+// UploadScanner defines the interface for checking uploaded files, e.g. for viruses, before
+// they are forwarded to services
+type UploadScanner interface {
+	// Scan reads the content of a file and returns whether it may be forwarded
+	Scan(ctx context.Context, file entity.UploadedFile, content io.Reader) (*entity.ScanVerdict, error)
+}
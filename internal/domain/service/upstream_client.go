@@ -0,0 +1,13 @@
+package service
+
+import (
+	"context"
+
+	"api-gateway-sample/internal/domain/entity"
+)
+
+// UpstreamClient sends requests to the base URL of a service
+type UpstreamClient interface {
+	// SendRequest sends a request to the service and returns its response
+	SendRequest(ctx context.Context, request *entity.Request, service *entity.Service) (*entity.Response, error)
+}
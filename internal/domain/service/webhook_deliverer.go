@@ -0,0 +1,12 @@
+package service
+
+import (
+	"api-gateway-sample/internal/domain/entity"
+	"context"
+)
+
+// WebhookDeliverer defines the interface for delivering events to webhook subscribers
+type WebhookDeliverer interface {
+	// Deliver sends the event to the subscription's URL, retrying transient failures
+	Deliver(ctx context.Context, webhook *entity.WebhookSubscription, event *entity.Event) error
+}
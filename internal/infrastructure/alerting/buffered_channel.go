@@ -0,0 +1,51 @@
+package alerting
+
+import (
+	"context"
+	"time"
+
+	"api-gateway-sample/internal/domain/entity"
+	"api-gateway-sample/internal/infrastructure/export"
+	"api-gateway-sample/pkg/logger"
+)
+
+// BufferedChannel sends the alerts of a channel in the background, so that a slow or failing
+// destination cannot hold up the request or monitor that raised the alert. Failed alerts are
+// retried behind a circuit breaker.
+type BufferedChannel struct {
+	channel  Channel
+	exporter *export.Exporter
+}
+
+// NewBufferedChannel creates a new BufferedChannel instance, reporting its health under name.
+// The buffering and retry settings are those of export.NewExporter.
+func NewBufferedChannel(name string, channel Channel, bufferSize int, maxRetries int, failureThreshold int, cooldown time.Duration, logger logger.Logger) *BufferedChannel {
+	send := func(ctx context.Context, item interface{}) error {
+		return channel.Send(ctx, item.(*entity.Alert))
+	}
+	return &BufferedChannel{
+		channel:  channel,
+		exporter: export.NewExporter(name, send, bufferSize, maxRetries, failureThreshold, cooldown, logger),
+	}
+}
+
+// Name returns the channel name used in logs
+func (c *BufferedChannel) Name() string {
+	return c.channel.Name()
+}
+
+// Send buffers the alert for delivery; it never fails
+func (c *BufferedChannel) Send(ctx context.Context, alert *entity.Alert) error {
+	c.exporter.Export(alert)
+	return nil
+}
+
+// Start delivers buffered alerts until the context is cancelled
+func (c *BufferedChannel) Start(ctx context.Context) {
+	c.exporter.Start(ctx)
+}
+
+// Health returns a snapshot of the destination's availability
+func (c *BufferedChannel) Health() *entity.DependencyHealth {
+	return c.exporter.Health()
+}
@@ -0,0 +1,77 @@
+package alerting
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"api-gateway-sample/internal/domain/entity"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// hangingChannel blocks every send until released
+type hangingChannel struct {
+	release chan struct{}
+	sent    chan *entity.Alert
+}
+
+func (c *hangingChannel) Name() string {
+	return "hanging"
+}
+
+func (c *hangingChannel) Send(ctx context.Context, alert *entity.Alert) error {
+	<-c.release
+	c.sent <- alert
+	return nil
+}
+
+func TestBufferedChannel_DoesNotBlockNotify(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	hanging := &hangingChannel{release: make(chan struct{}), sent: make(chan *entity.Alert, 1)}
+	channel := NewBufferedChannel("alerts.hanging", hanging, 10, 3, 3, time.Minute, nopLogger{})
+	channel.Start(ctx)
+	dispatcher := NewDispatcher([]Channel{channel}, time.Minute, nopLogger{})
+
+	// Alerts are raised on the paths of requests and monitors, which must not wait for delivery
+	done := make(chan error, 1)
+	go func() {
+		done <- dispatcher.Notify(ctx, &entity.Alert{Type: entity.AlertErrorRate, ServiceID: "orders"})
+	}()
+	select {
+	case err := <-done:
+		assert.NoError(t, err)
+	case <-time.After(time.Second):
+		t.Fatal("Notify blocked on a hanging channel")
+	}
+
+	close(hanging.release)
+	select {
+	case alert := <-hanging.sent:
+		assert.Equal(t, "orders", alert.ServiceID)
+	case <-time.After(time.Second):
+		t.Fatal("Alert was not delivered once the channel recovered")
+	}
+}
+
+func TestBufferedChannel_ReportsFailingChannel(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// Nothing listens on the webhook URL
+	server := newRecordingServer(t)
+	url := server.URL
+	server.Close()
+
+	channel := NewBufferedChannel("alerts.webhook[0]", NewWebhookChannel(url, time.Second), 10, 3, 1, time.Hour, nopLogger{})
+	channel.Start(ctx)
+	require.NoError(t, channel.Send(ctx, &entity.Alert{Type: entity.AlertErrorRate, ServiceID: "orders"}))
+
+	require.Eventually(t, func() bool { return !channel.Health().Healthy }, time.Second, time.Millisecond)
+	health := channel.Health()
+	assert.Equal(t, entity.CriticalityOptional, health.Criticality)
+	assert.Equal(t, int64(1), health.Fallbacks)
+}
@@ -0,0 +1,97 @@
+package alerting
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"api-gateway-sample/internal/domain/entity"
+)
+
+// Channel delivers an alert to a single destination
+type Channel interface {
+	Name() string
+	Send(ctx context.Context, alert *entity.Alert) error
+}
+
+// WebhookChannel posts alerts as JSON to an HTTP endpoint
+type WebhookChannel struct {
+	url    string
+	client *http.Client
+}
+
+// NewWebhookChannel creates a new WebhookChannel instance
+func NewWebhookChannel(url string, timeout time.Duration) *WebhookChannel {
+	return &WebhookChannel{
+		url:    url,
+		client: &http.Client{Timeout: timeout},
+	}
+}
+
+// Name returns the channel name used in logs
+func (c *WebhookChannel) Name() string {
+	return "webhook"
+}
+
+// Send posts the alert to the webhook
+func (c *WebhookChannel) Send(ctx context.Context, alert *entity.Alert) error {
+	return postJSON(ctx, c.client, c.url, alert)
+}
+
+// SlackChannel posts alerts to a Slack incoming webhook
+type SlackChannel struct {
+	url    string
+	client *http.Client
+}
+
+// NewSlackChannel creates a new SlackChannel instance
+func NewSlackChannel(url string, timeout time.Duration) *SlackChannel {
+	return &SlackChannel{
+		url:    url,
+		client: &http.Client{Timeout: timeout},
+	}
+}
+
+// Name returns the channel name used in logs
+func (c *SlackChannel) Name() string {
+	return "slack"
+}
+
+// Send posts the alert as a Slack message
+func (c *SlackChannel) Send(ctx context.Context, alert *entity.Alert) error {
+	text := fmt.Sprintf(":rotating_light: *%s*: %s", alert.Type, alert.Message)
+	if alert.ServiceID != "" {
+		text += fmt.Sprintf(" (service `%s`)", alert.ServiceID)
+	}
+	if alert.Target != "" {
+		text += fmt.Sprintf(" (target `%s`)", alert.Target)
+	}
+	return postJSON(ctx, c.client, c.url, map[string]string{"text": text})
+}
+
+func postJSON(ctx context.Context, client *http.Client, url string, payload interface{}) error {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("failed to encode alert: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to create alert request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to send alert: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("alert rejected with status %d", resp.StatusCode)
+	}
+	return nil
+}
@@ -0,0 +1,69 @@
+package alerting
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"api-gateway-sample/internal/domain/entity"
+	"api-gateway-sample/pkg/logger"
+)
+
+// Dispatcher implements the Notifier interface by fanning alerts out to every channel.
+// An alert is suppressed if the same alert was sent within the cooldown.
+type Dispatcher struct {
+	channels []Channel
+	cooldown time.Duration
+	logger   logger.Logger
+
+	mu       sync.Mutex
+	lastSent map[string]time.Time
+	now      func() time.Time
+}
+
+// NewDispatcher creates a new Dispatcher instance
+func NewDispatcher(channels []Channel, cooldown time.Duration, logger logger.Logger) *Dispatcher {
+	return &Dispatcher{
+		channels: channels,
+		cooldown: cooldown,
+		logger:   logger,
+		lastSent: make(map[string]time.Time),
+		now:      time.Now,
+	}
+}
+
+// Notify sends the alert to all channels unless it is within its cooldown
+func (d *Dispatcher) Notify(ctx context.Context, alert *entity.Alert) error {
+	if alert.Timestamp.IsZero() {
+		alert.Timestamp = d.now()
+	}
+	if !d.claim(alert.DedupKey()) {
+		return nil
+	}
+
+	d.logger.Warn("Alert raised", "type", alert.Type, "service", alert.ServiceID, "target", alert.Target, "message", alert.Message)
+
+	var firstErr error
+	for _, channel := range d.channels {
+		if err := channel.Send(ctx, alert); err != nil {
+			d.logger.Error("Failed to deliver alert", "channel", channel.Name(), "type", alert.Type, "error", err)
+			if firstErr == nil {
+				firstErr = err
+			}
+		}
+	}
+	return firstErr
+}
+
+// claim records that the alert is being sent, reporting false if it is still cooling down
+func (d *Dispatcher) claim(key string) bool {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	now := d.now()
+	if last, ok := d.lastSent[key]; ok && now.Sub(last) < d.cooldown {
+		return false
+	}
+	d.lastSent[key] = now
+	return true
+}
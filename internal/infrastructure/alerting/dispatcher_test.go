@@ -0,0 +1,134 @@
+package alerting
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"api-gateway-sample/internal/domain/entity"
+	"api-gateway-sample/internal/infrastructure/metrics"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// nopLogger discards all log output
+type nopLogger struct{}
+
+func (nopLogger) Debug(msg string, keysAndValues ...interface{}) {}
+func (nopLogger) Info(msg string, keysAndValues ...interface{})  {}
+func (nopLogger) Warn(msg string, keysAndValues ...interface{})  {}
+func (nopLogger) Error(msg string, keysAndValues ...interface{}) {}
+func (nopLogger) Fatal(msg string, keysAndValues ...interface{}) {}
+
+// recordingServer collects the JSON bodies posted to it
+type recordingServer struct {
+	*httptest.Server
+	mu     sync.Mutex
+	bodies []map[string]interface{}
+}
+
+func newRecordingServer(t *testing.T) *recordingServer {
+	rs := &recordingServer{}
+	rs.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]interface{}
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+		rs.mu.Lock()
+		rs.bodies = append(rs.bodies, body)
+		rs.mu.Unlock()
+	}))
+	t.Cleanup(rs.Close)
+	return rs
+}
+
+func (rs *recordingServer) received() []map[string]interface{} {
+	rs.mu.Lock()
+	defer rs.mu.Unlock()
+	return append([]map[string]interface{}(nil), rs.bodies...)
+}
+
+func TestDispatcher_Cooldown(t *testing.T) {
+	webhook := newRecordingServer(t)
+	slack := newRecordingServer(t)
+
+	now := time.Unix(1700000000, 0)
+	dispatcher := NewDispatcher([]Channel{
+		NewWebhookChannel(webhook.URL, time.Second),
+		NewSlackChannel(slack.URL, time.Second),
+	}, 10*time.Minute, nopLogger{})
+	dispatcher.now = func() time.Time { return now }
+
+	alert := func(serviceID string) *entity.Alert {
+		return &entity.Alert{Type: entity.AlertErrorRate, ServiceID: serviceID, Message: "error rate exceeded"}
+	}
+
+	// 1. The first alert is delivered to every channel
+	require.NoError(t, dispatcher.Notify(context.Background(), alert("svc-1")))
+	require.Len(t, webhook.received(), 1)
+	assert.Equal(t, "error_rate", webhook.received()[0]["type"])
+	assert.Contains(t, slack.received()[0]["text"], "error rate exceeded")
+
+	// 2. Repeats within the cooldown are suppressed, other services are not
+	require.NoError(t, dispatcher.Notify(context.Background(), alert("svc-1")))
+	require.NoError(t, dispatcher.Notify(context.Background(), alert("svc-2")))
+	assert.Len(t, webhook.received(), 2)
+
+	// 3. The alert fires again once the cooldown has passed
+	now = now.Add(11 * time.Minute)
+	require.NoError(t, dispatcher.Notify(context.Background(), alert("svc-1")))
+	assert.Len(t, webhook.received(), 3)
+}
+
+func TestErrorRateMonitor_Check(t *testing.T) {
+	webhook := newRecordingServer(t)
+	dispatcher := NewDispatcher([]Channel{NewWebhookChannel(webhook.URL, time.Second)}, time.Hour, nopLogger{})
+	collector := metrics.NewSlidingWindowAggregator(time.Minute, 6)
+
+	for i := 0; i < 10; i++ {
+		status := 200
+		if i < 3 {
+			status = 503
+		}
+		collector.RecordRequest(&entity.RequestSample{ServiceID: "failing", StatusCode: status})
+		collector.RecordRequest(&entity.RequestSample{ServiceID: "healthy", StatusCode: 200})
+	}
+	// Too little traffic to judge
+	collector.RecordRequest(&entity.RequestSample{ServiceID: "quiet", StatusCode: 500})
+
+	NewErrorRateMonitor(collector, dispatcher, 0.1, 5, nopLogger{}).Check(context.Background())
+
+	received := webhook.received()
+	require.Len(t, received, 1)
+	assert.Equal(t, "error_rate", received[0]["type"])
+	assert.Equal(t, "failing", received[0]["serviceId"])
+	assert.InDelta(t, 0.3, received[0]["value"], 0.0001)
+}
+
+func TestSLOMonitor_Check(t *testing.T) {
+	webhook := newRecordingServer(t)
+	dispatcher := NewDispatcher([]Channel{NewWebhookChannel(webhook.URL, time.Second)}, time.Hour, nopLogger{})
+	collector := metrics.NewSlidingWindowAggregator(time.Minute, 6)
+
+	slo := &entity.SLO{Objective: 0.99}
+	for i := 0; i < 10; i++ {
+		status := 200
+		if i == 0 {
+			status = 503
+		}
+		collector.RecordRequest(&entity.RequestSample{ServiceID: "users", Endpoint: "/api/v1/users", StatusCode: status, SLO: slo})
+		collector.RecordRequest(&entity.RequestSample{ServiceID: "users", Endpoint: "/api/v1/profiles", StatusCode: 200, SLO: slo})
+	}
+
+	NewSLOMonitor(collector, dispatcher, 1, 5, nopLogger{}).Check(context.Background())
+
+	received := webhook.received()
+	require.Len(t, received, 1)
+	assert.Equal(t, "slo_budget_exhausted", received[0]["type"])
+	assert.Equal(t, "/api/v1/users", received[0]["target"])
+	// 10% of requests failed against a 1% budget
+	assert.InDelta(t, 10.0, received[0]["value"], 0.0001)
+}
@@ -0,0 +1,79 @@
+package alerting
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"api-gateway-sample/internal/domain/entity"
+	"api-gateway-sample/internal/domain/service"
+	"api-gateway-sample/pkg/logger"
+)
+
+// ErrorRateMonitor periodically raises an alert for every service whose 5xx rate
+// over the metrics window exceeds the threshold
+type ErrorRateMonitor struct {
+	metrics     service.MetricsCollector
+	notifier    service.Notifier
+	threshold   float64
+	minRequests int64
+	logger      logger.Logger
+}
+
+// NewErrorRateMonitor creates a new ErrorRateMonitor instance
+func NewErrorRateMonitor(
+	metrics service.MetricsCollector,
+	notifier service.Notifier,
+	threshold float64,
+	minRequests int64,
+	logger logger.Logger,
+) *ErrorRateMonitor {
+	return &ErrorRateMonitor{
+		metrics:     metrics,
+		notifier:    notifier,
+		threshold:   threshold,
+		minRequests: minRequests,
+		logger:      logger,
+	}
+}
+
+// Check raises alerts for services above the error rate threshold
+func (m *ErrorRateMonitor) Check(ctx context.Context) {
+	for _, serviceID := range m.metrics.ServiceIDs() {
+		stats := m.metrics.ServiceStats(serviceID)
+		// Too few requests make the rate meaningless
+		if stats.Requests < m.minRequests || stats.ErrorRate <= m.threshold {
+			continue
+		}
+
+		m.notifier.Notify(ctx, &entity.Alert{
+			Type:      entity.AlertErrorRate,
+			ServiceID: serviceID,
+			Message: fmt.Sprintf("5xx rate %.1f%% over the last %s exceeds %.1f%%",
+				stats.ErrorRate*100, stats.Window, m.threshold*100),
+			Value:     stats.ErrorRate,
+			Threshold: m.threshold,
+		})
+	}
+}
+
+// Start checks error rates on the given interval until the context is cancelled
+func (m *ErrorRateMonitor) Start(ctx context.Context, interval time.Duration) {
+	if interval <= 0 {
+		return
+	}
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				m.Check(ctx)
+			}
+		}
+	}()
+}
@@ -0,0 +1,94 @@
+package alerting
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"api-gateway-sample/internal/domain/entity"
+	"api-gateway-sample/internal/domain/repository"
+	"api-gateway-sample/internal/domain/service"
+)
+
+// OwnerChannel routes the alerts of a service to the team that owns it: by email, and to the
+// Slack channel it names through that channel's incoming webhook. Alerts about no service or
+// an unowned service are left to the other channels.
+type OwnerChannel struct {
+	services repository.ServiceRepository
+	mailer   service.Mailer
+	slack    map[string]*SlackChannel
+}
+
+// NewOwnerChannel creates a new OwnerChannel instance. slackWebhooks maps the Slack channels
+// owners may name to their incoming webhook URLs; mailer may be nil to send no email.
+func NewOwnerChannel(
+	services repository.ServiceRepository,
+	mailer service.Mailer,
+	slackWebhooks map[string]string,
+	timeout time.Duration,
+) *OwnerChannel {
+	slack := make(map[string]*SlackChannel, len(slackWebhooks))
+	for channel, url := range slackWebhooks {
+		slack[strings.ToLower(channel)] = NewSlackChannel(url, timeout)
+	}
+	return &OwnerChannel{
+		services: services,
+		mailer:   mailer,
+		slack:    slack,
+	}
+}
+
+// Name returns the channel name used in logs
+func (c *OwnerChannel) Name() string {
+	return "owner"
+}
+
+// Send notifies the owner of the alert's service
+func (c *OwnerChannel) Send(ctx context.Context, alert *entity.Alert) error {
+	if alert.ServiceID == "" {
+		return nil
+	}
+	svc, err := c.services.Get(ctx, alert.ServiceID)
+	if err != nil {
+		return fmt.Errorf("failed to find the owner of service %s: %w", alert.ServiceID, err)
+	}
+	owner := svc.Owner
+	if owner == nil {
+		return nil
+	}
+
+	var firstErr error
+	if owner.Email != "" && c.mailer != nil {
+		subject := fmt.Sprintf("[%s] %s alert for %s", owner.Team, alert.Type, svc.Name)
+		if err := c.mailer.Send(ctx, owner.Email, subject, alertText(alert, svc.Name)); err != nil {
+			firstErr = fmt.Errorf("failed to email %s: %w", owner.Email, err)
+		}
+	}
+	if owner.Slack != "" {
+		if channel, ok := c.slack[strings.ToLower(owner.Slack)]; !ok {
+			if firstErr == nil {
+				firstErr = fmt.Errorf("no webhook is configured for Slack channel %s", owner.Slack)
+			}
+		} else if err := channel.Send(ctx, alert); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	return firstErr
+}
+
+// alertText describes an alert in a plain text message
+func alertText(alert *entity.Alert, serviceName string) string {
+	var text strings.Builder
+	fmt.Fprintf(&text, "%s\n\n", alert.Message)
+	fmt.Fprintf(&text, "Alert: %s\n", alert.Type)
+	fmt.Fprintf(&text, "Service: %s (%s)\n", serviceName, alert.ServiceID)
+	if alert.Target != "" {
+		fmt.Fprintf(&text, "Target: %s\n", alert.Target)
+	}
+	if alert.Threshold != 0 {
+		fmt.Fprintf(&text, "Value: %g (threshold %g)\n", alert.Value, alert.Threshold)
+	}
+	fmt.Fprintf(&text, "Time: %s\n", alert.Timestamp.Format(time.RFC3339))
+	return text.String()
+}
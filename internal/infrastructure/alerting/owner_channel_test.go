@@ -0,0 +1,68 @@
+package alerting
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"api-gateway-sample/internal/domain/entity"
+	"api-gateway-sample/internal/domain/repository/mock"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// recordingMailer collects the messages sent through it
+type recordingMailer struct {
+	to, subjects, bodies []string
+}
+
+func (m *recordingMailer) Send(ctx context.Context, to string, subject string, body string) error {
+	m.to = append(m.to, to)
+	m.subjects = append(m.subjects, subject)
+	m.bodies = append(m.bodies, body)
+	return nil
+}
+
+func TestOwnerChannel_Send(t *testing.T) {
+	ctx := context.Background()
+	slack := newRecordingServer(t)
+	repo := mock.NewServiceRepositoryMock()
+
+	owned := entity.NewService("payments-id", "payments", "1.0.0", "", "http://payments", 30, 3)
+	owned.Owner = &entity.Owner{Team: "payments", Email: "payments@example.com", Slack: "Payments-Oncall"}
+	unowned := entity.NewService("legacy-id", "legacy", "1.0.0", "", "http://legacy", 30, 3)
+	require.NoError(t, repo.Create(ctx, owned))
+	require.NoError(t, repo.Create(ctx, unowned))
+
+	mailer := &recordingMailer{}
+	channel := NewOwnerChannel(repo, mailer, map[string]string{"payments-oncall": slack.URL}, time.Second)
+
+	alert := &entity.Alert{
+		Type:      entity.AlertErrorRate,
+		ServiceID: "payments-id",
+		Message:   "5xx rate 12.0% over the last 1m0s exceeds 5.0%",
+		Value:     0.12,
+		Threshold: 0.05,
+		Timestamp: time.Unix(1700000000, 0).UTC(),
+	}
+	require.NoError(t, channel.Send(ctx, alert))
+
+	assert.Equal(t, []string{"payments@example.com"}, mailer.to)
+	assert.Equal(t, []string{"[payments] error_rate alert for payments"}, mailer.subjects)
+	assert.Contains(t, mailer.bodies[0], "Service: payments (payments-id)")
+	assert.Contains(t, mailer.bodies[0], "Value: 0.12 (threshold 0.05)")
+	if assert.Len(t, slack.received(), 1) {
+		assert.Contains(t, slack.received()[0]["text"], "error_rate")
+	}
+
+	// Alerts about unowned services and about no service are left to the other channels
+	require.NoError(t, channel.Send(ctx, &entity.Alert{Type: entity.AlertErrorRate, ServiceID: "legacy-id"}))
+	require.NoError(t, channel.Send(ctx, &entity.Alert{Type: entity.AlertErrorRate, Target: "http://payments"}))
+	assert.Len(t, mailer.to, 1)
+	assert.Len(t, slack.received(), 1)
+
+	// A Slack channel without a webhook is reported
+	owned.Owner.Slack = "unknown"
+	assert.Error(t, channel.Send(ctx, alert))
+}
@@ -0,0 +1,79 @@
+package alerting
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"api-gateway-sample/internal/domain/entity"
+	"api-gateway-sample/internal/domain/service"
+	"api-gateway-sample/pkg/logger"
+)
+
+// SLOMonitor periodically raises an alert for every endpoint burning its error budget
+// over the metrics window at or above the threshold
+type SLOMonitor struct {
+	metrics     service.MetricsCollector
+	notifier    service.Notifier
+	threshold   float64
+	minRequests int64
+	logger      logger.Logger
+}
+
+// NewSLOMonitor creates a new SLOMonitor instance
+func NewSLOMonitor(
+	metrics service.MetricsCollector,
+	notifier service.Notifier,
+	threshold float64,
+	minRequests int64,
+	logger logger.Logger,
+) *SLOMonitor {
+	return &SLOMonitor{
+		metrics:     metrics,
+		notifier:    notifier,
+		threshold:   threshold,
+		minRequests: minRequests,
+		logger:      logger,
+	}
+}
+
+// Check raises alerts for endpoints at or above the burn rate threshold
+func (m *SLOMonitor) Check(ctx context.Context) {
+	for _, status := range m.metrics.SLOStatuses() {
+		if status.Requests < m.minRequests || status.BurnRate < m.threshold {
+			continue
+		}
+
+		m.notifier.Notify(ctx, &entity.Alert{
+			Type:      entity.AlertSLOBudget,
+			ServiceID: status.ServiceID,
+			Target:    status.Endpoint,
+			Message: fmt.Sprintf("%s is burning its error budget at %.1fx: %.2f%% of requests met the %.2f%% objective over the last %s",
+				status.Endpoint, status.BurnRate, status.Compliance*100, status.Objective*100,
+				time.Duration(status.WindowSeconds*float64(time.Second))),
+			Value:     status.BurnRate,
+			Threshold: m.threshold,
+		})
+	}
+}
+
+// Start checks SLO burn rates on the given interval until the context is cancelled
+func (m *SLOMonitor) Start(ctx context.Context, interval time.Duration) {
+	if interval <= 0 {
+		return
+	}
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				m.Check(ctx)
+			}
+		}
+	}()
+}
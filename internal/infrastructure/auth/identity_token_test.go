@@ -0,0 +1,63 @@
+package auth
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"api-gateway-sample/internal/domain/entity"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestJWTAuth_IssueIdentityToken(t *testing.T) {
+	ctx := context.Background()
+	key, err := GenerateKey("ES256")
+	require.NoError(t, err)
+	jwtAuth := NewJWTAuthWithKeyRing(NewKeyRing(key, time.Hour), nil, "", "api-gateway", time.Hour, nopLogger{})
+	jwtAuth.SetIdentityTokens("internal-services", time.Minute)
+
+	principal := entity.NewPrincipal(map[string]interface{}{
+		"sub":   "alice",
+		"roles": []interface{}{"admin"},
+		"scope": "orders:read",
+		"amr":   []interface{}{"saml"},
+		"email": "alice@example.com",
+	})
+	request := entity.NewRequest("GET", "/api/v1/orders/1", nil, nil, nil, "10.0.0.1")
+	service := &entity.Service{Name: "orders"}
+	endpoint := &entity.Endpoint{Path: "/api/v1/orders/"}
+
+	token, err := jwtAuth.IssueIdentityToken(ctx, principal, request, service, endpoint)
+	require.NoError(t, err)
+
+	// Services verify the token with the gateway's published keys and their audience
+	claims := jwt.MapClaims{}
+	_, err = jwt.ParseWithClaims(token, claims, func(token *jwt.Token) (interface{}, error) {
+		return key.Public, nil
+	}, jwt.WithIssuer("api-gateway"), jwt.WithAudience("internal-services"), jwt.WithExpirationRequired())
+	require.NoError(t, err)
+	assert.Equal(t, "alice", claims["sub"])
+	assert.Equal(t, "orders", claims["service"])
+	assert.Equal(t, "/api/v1/orders/", claims["endpoint"])
+	assert.Equal(t, "GET", claims["method"])
+	assert.Equal(t, request.ID, claims["jti"])
+	assert.Equal(t, []interface{}{"admin"}, claims["roles"])
+	assert.Equal(t, "orders:read", claims["scope"])
+	assert.Equal(t, []interface{}{"saml"}, claims["amr"])
+	assert.NotContains(t, claims, "email")
+	expiry, err := claims.GetExpirationTime()
+	require.NoError(t, err)
+	assert.WithinDuration(t, time.Now().Add(time.Minute), expiry.Time, 5*time.Second)
+
+	// The gateway does not accept identity tokens from clients
+	_, err = jwtAuth.ValidateToken(ctx, token)
+	assert.Error(t, err)
+
+	clientToken, err := jwtAuth.GenerateToken(ctx, "alice", nil)
+	require.NoError(t, err)
+	_, err = jwtAuth.ValidateToken(ctx, clientToken)
+	assert.NoError(t, err)
+}
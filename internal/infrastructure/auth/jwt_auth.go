@@ -7,6 +7,7 @@ import (
 	"time"
 
 	"api-gateway-sample/internal/domain/entity"
+	"api-gateway-sample/internal/domain/service"
 	"api-gateway-sample/pkg/logger"
 
 	"github.com/golang-jwt/jwt/v5"
@@ -14,22 +15,57 @@ import (
 
 // JWTAuth implements the AuthService interface using JWT
 type JWTAuth struct {
-	secretKey  []byte
-	issuer     string
-	expiration time.Duration
-	logger     logger.Logger
+	keyRing       *KeyRing
+	policyEngine  service.PolicyEngine
+	defaultPolicy string
+	issuer        string
+	expiration    time.Duration
+	logger        logger.Logger
+
+	// identityAudience and identityTTL configure the identity tokens minted for services,
+	// disabled when identityAudience is empty
+	identityAudience string
+	identityTTL      time.Duration
 }
 
-// NewJWTAuth creates a new JWTAuth instance
+// NewJWTAuth creates a new JWTAuth instance signing tokens with a shared HMAC secret
+// and authorizing requests with the built-in role check
 func NewJWTAuth(secretKey []byte, issuer string, expiration time.Duration, logger logger.Logger) *JWTAuth {
+	return NewJWTAuthWithKeyRing(NewKeyRing(NewHMACKey(jwt.SigningMethodHS256.Alg(), secretKey), expiration), nil, "", issuer, expiration, logger)
+}
+
+// NewJWTAuthWithKeyRing creates a new JWTAuth instance using the given key ring. Requests
+// are authorized by the endpoint policy, or by defaultPolicy for endpoints without one.
+// A nil policy engine falls back to the built-in role check.
+func NewJWTAuthWithKeyRing(
+	keyRing *KeyRing,
+	policyEngine service.PolicyEngine,
+	defaultPolicy string,
+	issuer string,
+	expiration time.Duration,
+	logger logger.Logger,
+) *JWTAuth {
 	return &JWTAuth{
-		secretKey:  secretKey,
-		issuer:     issuer,
-		expiration: expiration,
-		logger:     logger,
+		keyRing:       keyRing,
+		policyEngine:  policyEngine,
+		defaultPolicy: defaultPolicy,
+		issuer:        issuer,
+		expiration:    expiration,
+		logger:        logger,
 	}
 }
 
+// KeyRing returns the key ring used to sign and verify tokens
+func (a *JWTAuth) KeyRing() *KeyRing {
+	return a.keyRing
+}
+
+// SetSecretKey replaces the HMAC signing key, allowing it to rotate without a restart.
+// Tokens signed with the previous key remain valid until they expire.
+func (a *JWTAuth) SetSecretKey(secretKey []byte) {
+	a.keyRing.AddKey(NewHMACKey(a.keyRing.Algorithm(), secretKey))
+}
+
 // getAuthToken extracts the token from the Authorization header
 func getAuthToken(headers map[string][]string) string {
 	if authHeaders, ok := headers["Authorization"]; ok && len(authHeaders) > 0 {
@@ -63,43 +99,60 @@ func (a *JWTAuth) Authenticate(ctx context.Context, request *entity.Request) (bo
 }
 
 // Authorize authorizes a request for a specific service and endpoint
-func (a *JWTAuth) Authorize(ctx context.Context, request *entity.Request, service *entity.Service, endpoint *entity.Endpoint) error {
+func (a *JWTAuth) Authorize(ctx context.Context, request *entity.Request, svc *entity.Service, endpoint *entity.Endpoint) error {
 	if !endpoint.AuthRequired {
 		return nil
 	}
 
-	tokenString := getAuthToken(request.Headers)
-	if tokenString == "" {
-		return fmt.Errorf("authorization required")
+	principal, err := a.principal(ctx, request)
+	if err != nil {
+		return err
 	}
 
-	claims, err := a.ValidateToken(ctx, tokenString)
+	hasAccess, err := a.evaluatePolicy(ctx, principal, request, svc, endpoint)
 	if err != nil {
 		return err
 	}
+	if !hasAccess {
+		return fmt.Errorf("unauthorized: insufficient permissions")
+	}
 
-	// Check roles/permissions from claims
-	roles, ok := claims["roles"].([]interface{})
-	if !ok {
-		return fmt.Errorf("invalid roles in token")
+	return nil
+}
+
+// evaluatePolicy evaluates the endpoint policy, or the default policy when the endpoint has none
+func (a *JWTAuth) evaluatePolicy(ctx context.Context, principal *entity.Principal, request *entity.Request, svc *entity.Service, endpoint *entity.Endpoint) (bool, error) {
+	policy := endpoint.Policy
+	if policy == "" {
+		policy = a.defaultPolicy
 	}
 
-	// Simple role-based authorization
-	hasAccess := false
-	for _, role := range roles {
-		if roleStr, ok := role.(string); ok {
-			if roleStr == "admin" || roleStr == service.Name+":"+endpoint.Path {
-				hasAccess = true
-				break
-			}
-		}
+	if a.policyEngine == nil || policy == "" {
+		// Simple role-based authorization
+		return principal.HasRole("admin") || principal.HasRole(svc.Name+":"+endpoint.Path), nil
 	}
 
-	if !hasAccess {
-		return fmt.Errorf("unauthorized: insufficient permissions")
+	return a.policyEngine.Evaluate(ctx, policy, entity.NewPolicyInput(principal, request, svc, endpoint))
+}
+
+// principal returns the principal authenticated by the auth middleware, validating
+// the request token when the request did not pass through it
+func (a *JWTAuth) principal(ctx context.Context, request *entity.Request) (*entity.Principal, error) {
+	if principal, ok := entity.PrincipalFromContext(ctx); ok {
+		return principal, nil
 	}
 
-	return nil
+	tokenString := getAuthToken(request.Headers)
+	if tokenString == "" {
+		return nil, fmt.Errorf("authorization required")
+	}
+
+	claims, err := a.ValidateToken(ctx, tokenString)
+	if err != nil {
+		return nil, err
+	}
+
+	return entity.NewPrincipal(claims), nil
 }
 
 // GenerateToken generates an authentication token
@@ -117,18 +170,15 @@ func (a *JWTAuth) GenerateToken(ctx context.Context, userID string, claims map[s
 		tokenClaims[k] = v
 	}
 
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, tokenClaims)
-	return token.SignedString(a.secretKey)
+	key := a.keyRing.Active()
+	token := jwt.NewWithClaims(key.Method, tokenClaims)
+	token.Header["kid"] = key.ID
+	return token.SignedString(key.Private)
 }
 
 // ValidateToken validates an authentication token
 func (a *JWTAuth) ValidateToken(ctx context.Context, tokenString string) (map[string]interface{}, error) {
-	token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
-		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-			return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
-		}
-		return a.secretKey, nil
-	})
+	token, err := jwt.Parse(tokenString, a.verificationKey)
 
 	if err != nil {
 		return nil, err
@@ -142,6 +192,33 @@ func (a *JWTAuth) ValidateToken(ctx context.Context, tokenString string) (map[st
 	if !ok {
 		return nil, fmt.Errorf("invalid claims")
 	}
+	if a.isIdentityToken(claims) {
+		return nil, fmt.Errorf("identity tokens are only valid at services")
+	}
 
 	return claims, nil
 }
+
+// PublicKeys returns the public verification keys as a JWKS document
+func (a *JWTAuth) PublicKeys(ctx context.Context) (*entity.JSONWebKeySet, error) {
+	return a.keyRing.JWKS(), nil
+}
+
+// verificationKey selects the key matching the token's kid. Tokens without a kid
+// are verified against the active key for compatibility with tokens issued before rotation.
+func (a *JWTAuth) verificationKey(token *jwt.Token) (interface{}, error) {
+	key := a.keyRing.Active()
+	if kid, ok := token.Header["kid"].(string); ok {
+		found, exists := a.keyRing.Lookup(kid)
+		if !exists {
+			return nil, fmt.Errorf("unknown signing key: %s", kid)
+		}
+		key = found
+	}
+
+	if token.Method.Alg() != key.Method.Alg() {
+		return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
+	}
+
+	return key.Public, nil
+}
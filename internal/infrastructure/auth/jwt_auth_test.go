@@ -0,0 +1,155 @@
+package auth
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"api-gateway-sample/internal/domain/entity"
+	"api-gateway-sample/internal/infrastructure/events"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// nopLogger discards all log output
+type nopLogger struct{}
+
+func (nopLogger) Debug(msg string, keysAndValues ...interface{}) {}
+func (nopLogger) Info(msg string, keysAndValues ...interface{})  {}
+func (nopLogger) Warn(msg string, keysAndValues ...interface{})  {}
+func (nopLogger) Error(msg string, keysAndValues ...interface{}) {}
+func (nopLogger) Fatal(msg string, keysAndValues ...interface{}) {}
+
+func TestJWTAuth_SecretRotationKeepsOldTokensValid(t *testing.T) {
+	ctx := context.Background()
+	jwtAuth := NewJWTAuth([]byte("first-secret"), "api-gateway", time.Hour, nopLogger{})
+
+	oldToken, err := jwtAuth.GenerateToken(ctx, "user-1", nil)
+	require.NoError(t, err)
+
+	jwtAuth.SetSecretKey([]byte("second-secret"))
+
+	newToken, err := jwtAuth.GenerateToken(ctx, "user-2", nil)
+	require.NoError(t, err)
+
+	// Tokens signed with either key are accepted
+	claims, err := jwtAuth.ValidateToken(ctx, oldToken)
+	require.NoError(t, err)
+	assert.Equal(t, "user-1", claims["sub"])
+
+	claims, err = jwtAuth.ValidateToken(ctx, newToken)
+	require.NoError(t, err)
+	assert.Equal(t, "user-2", claims["sub"])
+
+	// Symmetric keys are never published
+	keySet, err := jwtAuth.PublicKeys(ctx)
+	require.NoError(t, err)
+	assert.Empty(t, keySet.Keys)
+}
+
+func TestJWTAuth_SignsWithConfiguredHMACAlgorithm(t *testing.T) {
+	ctx := context.Background()
+	for _, algorithm := range []string{"HS256", "HS384", "HS512"} {
+		t.Run(algorithm, func(t *testing.T) {
+			keyRing := NewKeyRing(NewHMACKey(algorithm, []byte("first-secret")), time.Hour)
+			jwtAuth := NewJWTAuthWithKeyRing(keyRing, nil, "", "api-gateway", time.Hour, nopLogger{})
+			// Secrets rotated in keep the configured algorithm
+			jwtAuth.SetSecretKey([]byte("second-secret"))
+
+			token, err := jwtAuth.GenerateToken(ctx, "user-1", nil)
+			require.NoError(t, err)
+			parsed, _, err := jwt.NewParser().ParseUnverified(token, jwt.MapClaims{})
+			require.NoError(t, err)
+			assert.Equal(t, algorithm, parsed.Header["alg"])
+
+			claims, err := jwtAuth.ValidateToken(ctx, token)
+			require.NoError(t, err)
+			assert.Equal(t, "user-1", claims["sub"])
+		})
+	}
+}
+
+func TestJWTAuth_AsymmetricRotation(t *testing.T) {
+	ctx := context.Background()
+	key, err := GenerateKey("ES256")
+	require.NoError(t, err)
+
+	keyRing := NewKeyRing(key, time.Hour)
+	bus := events.NewInMemoryBus(nopLogger{})
+	var published []*entity.Event
+	bus.Subscribe(entity.EventKeyRotated, func(ctx context.Context, event *entity.Event) {
+		published = append(published, event)
+	})
+	keyRing.SetEvents(bus)
+	jwtAuth := NewJWTAuthWithKeyRing(keyRing, nil, "", "api-gateway", time.Hour, nopLogger{})
+
+	oldToken, err := jwtAuth.GenerateToken(ctx, "user-1", nil)
+	require.NoError(t, err)
+
+	rotated, err := keyRing.Rotate()
+	require.NoError(t, err)
+	assert.NotEqual(t, key.ID, rotated.ID)
+	if assert.Len(t, published, 1) {
+		assert.Equal(t, rotated.ID, published[0].Data["kid"])
+		assert.Equal(t, key.ID, published[0].Data["previousKid"])
+	}
+
+	_, err = jwtAuth.ValidateToken(ctx, oldToken)
+	assert.NoError(t, err)
+
+	// Both the retired and the active key are published
+	keySet, err := jwtAuth.PublicKeys(ctx)
+	require.NoError(t, err)
+	assert.Len(t, keySet.Keys, 2)
+	for _, jwk := range keySet.Keys {
+		assert.Equal(t, "EC", jwk.KeyType)
+		assert.Equal(t, "P-256", jwk.Curve)
+	}
+}
+
+func TestJWTAuth_RejectsUnknownKeyID(t *testing.T) {
+	ctx := context.Background()
+	issuer := NewJWTAuth([]byte("issuer-secret"), "api-gateway", time.Hour, nopLogger{})
+	verifier := NewJWTAuth([]byte("other-secret"), "api-gateway", time.Hour, nopLogger{})
+
+	token, err := issuer.GenerateToken(ctx, "user-1", nil)
+	require.NoError(t, err)
+
+	_, err = verifier.ValidateToken(ctx, token)
+	assert.Error(t, err)
+}
+
+func FuzzJWTAuth_Authenticate(f *testing.F) {
+	ctx := context.Background()
+	jwtAuth := NewJWTAuth([]byte("fuzz-secret-key-of-32-bytes-long"), "api-gateway", time.Hour, nopLogger{})
+	token, err := jwtAuth.GenerateToken(ctx, "alice", map[string]interface{}{"roles": []string{"admin"}})
+	require.NoError(f, err)
+
+	header, payload, _ := strings.Cut(token, ".")
+	for _, seed := range []string{
+		"Bearer " + token,
+		token,
+		"Bearer " + token + "x",
+		"Bearer " + header + "..",
+		"Bearer " + header + "." + payload,
+		"Bearer eyJhbGciOiJub25lIn0.eyJzdWIiOiJhbGljZSJ9.",
+		"Bearer eyJhbGciOiJIUzI1NiIsImtpZCI6WzFdfQ.e30.x",
+		"Bearer ....",
+		"Bearer ",
+		"bearer " + token,
+		"Basic YWxpY2U6c2VjcmV0",
+	} {
+		f.Add(seed)
+	}
+
+	f.Fuzz(func(t *testing.T, authorization string) {
+		request := &entity.Request{Headers: map[string][]string{"Authorization": {authorization}}}
+		authenticated, userID, err := jwtAuth.Authenticate(ctx, request)
+		if authenticated && (err != nil || userID != "alice") {
+			t.Fatalf("Authenticated %q as %q with error %v", authorization, userID, err)
+		}
+	})
+}
@@ -0,0 +1,352 @@
+package auth
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"math/big"
+	"sync"
+	"time"
+
+	"api-gateway-sample/internal/domain/entity"
+	"api-gateway-sample/internal/domain/service"
+	"api-gateway-sample/pkg/logger"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// rsaKeyBits is the size of generated RSA signing keys
+const rsaKeyBits = 2048
+
+// SigningKey is a key used to sign or verify tokens, identified by its kid
+type SigningKey struct {
+	ID        string
+	Method    jwt.SigningMethod
+	Private   interface{}
+	Public    interface{}
+	CreatedAt time.Time
+	RetiredAt time.Time
+}
+
+// IsAsymmetric reports whether the key has a public half that can be published
+func (k *SigningKey) IsAsymmetric() bool {
+	_, isHMAC := k.Method.(*jwt.SigningMethodHMAC)
+	return !isHMAC
+}
+
+// NewHMACKey creates a symmetric signing key for HS256, HS384 or HS512 whose kid is derived
+// from the secret, so every gateway replica sharing the secret agrees on the kid. Other
+// algorithms sign with HS256.
+func NewHMACKey(algorithm string, secret []byte) *SigningKey {
+	method, ok := jwt.GetSigningMethod(algorithm).(*jwt.SigningMethodHMAC)
+	if !ok {
+		method = jwt.SigningMethodHS256
+	}
+	sum := sha256.Sum256(secret)
+	return &SigningKey{
+		ID:        hex.EncodeToString(sum[:8]),
+		Method:    method,
+		Private:   secret,
+		Public:    secret,
+		CreatedAt: time.Now(),
+	}
+}
+
+// GenerateKey creates a new asymmetric signing key for the given algorithm
+func GenerateKey(algorithm string) (*SigningKey, error) {
+	var private crypto.Signer
+	var err error
+
+	method := jwt.GetSigningMethod(algorithm)
+	switch method.(type) {
+	case *jwt.SigningMethodRSA:
+		private, err = rsa.GenerateKey(rand.Reader, rsaKeyBits)
+	case *jwt.SigningMethodECDSA:
+		private, err = ecdsa.GenerateKey(curveFor(algorithm), rand.Reader)
+	default:
+		return nil, fmt.Errorf("cannot generate keys for algorithm: %s", algorithm)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate %s key: %w", algorithm, err)
+	}
+
+	return newAsymmetricKey(method, private), nil
+}
+
+// NewKeyFromPEM creates an asymmetric signing key from a PEM encoded private key
+func NewKeyFromPEM(algorithm string, pemData []byte) (*SigningKey, error) {
+	method := jwt.GetSigningMethod(algorithm)
+
+	var private crypto.Signer
+	var err error
+	switch method.(type) {
+	case *jwt.SigningMethodRSA:
+		private, err = jwt.ParseRSAPrivateKeyFromPEM(pemData)
+	case *jwt.SigningMethodECDSA:
+		private, err = jwt.ParseECPrivateKeyFromPEM(pemData)
+	default:
+		return nil, fmt.Errorf("unsupported asymmetric algorithm: %s", algorithm)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse private key: %w", err)
+	}
+
+	return newAsymmetricKey(method, private), nil
+}
+
+func newAsymmetricKey(method jwt.SigningMethod, private crypto.Signer) *SigningKey {
+	public := private.Public()
+	return &SigningKey{
+		ID:        publicKeyID(public),
+		Method:    method,
+		Private:   private,
+		Public:    public,
+		CreatedAt: time.Now(),
+	}
+}
+
+func curveFor(algorithm string) elliptic.Curve {
+	switch algorithm {
+	case "ES384":
+		return elliptic.P384()
+	case "ES512":
+		return elliptic.P521()
+	default:
+		return elliptic.P256()
+	}
+}
+
+// publicKeyID derives a stable kid from the public key material
+func publicKeyID(public crypto.PublicKey) string {
+	var material []byte
+	switch k := public.(type) {
+	case *rsa.PublicKey:
+		material = append(k.N.Bytes(), big.NewInt(int64(k.E)).Bytes()...)
+	case *ecdsa.PublicKey:
+		material = append(k.X.Bytes(), k.Y.Bytes()...)
+	}
+	sum := sha256.Sum256(material)
+	return hex.EncodeToString(sum[:8])
+}
+
+// KeyRing holds the active signing key and the retired keys still accepted for verification
+type KeyRing struct {
+	mu        sync.RWMutex
+	algorithm string
+	active    *SigningKey
+	keys      map[string]*SigningKey
+	retention time.Duration
+	// events receives EventKeyRotated when the signing key changes, nil when disabled
+	events service.EventBus
+}
+
+// NewKeyRing creates a new KeyRing with the given initial signing key.
+// Retired keys stay valid for verification for the retention period, which
+// should be at least the token expiration.
+func NewKeyRing(initial *SigningKey, retention time.Duration) *KeyRing {
+	return &KeyRing{
+		algorithm: initial.Method.Alg(),
+		active:    initial,
+		keys:      map[string]*SigningKey{initial.ID: initial},
+		retention: retention,
+	}
+}
+
+// SetEvents publishes EventKeyRotated to the given bus whenever the signing key changes
+func (k *KeyRing) SetEvents(events service.EventBus) {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	k.events = events
+}
+
+// Algorithm returns the algorithm the keys of the ring sign with
+func (k *KeyRing) Algorithm() string {
+	return k.algorithm
+}
+
+// Active returns the key currently used for signing
+func (k *KeyRing) Active() *SigningKey {
+	k.mu.RLock()
+	defer k.mu.RUnlock()
+	return k.active
+}
+
+// Lookup returns the verification key with the given kid
+func (k *KeyRing) Lookup(kid string) (*SigningKey, bool) {
+	k.mu.RLock()
+	defer k.mu.RUnlock()
+	key, ok := k.keys[kid]
+	if !ok || k.isExpired(key, time.Now()) {
+		return nil, false
+	}
+	return key, true
+}
+
+// AddKey makes the key the active signing key and retires the previous one
+func (k *KeyRing) AddKey(key *SigningKey) {
+	k.mu.Lock()
+	previous := k.active
+	rotated := previous != nil && previous.ID != key.ID
+	if rotated {
+		previous.RetiredAt = time.Now()
+	}
+	k.active = key
+	k.keys[key.ID] = key
+	k.pruneLocked(time.Now())
+	events := k.events
+	k.mu.Unlock()
+
+	if rotated && events != nil {
+		// Only key IDs are published, never key material
+		event := entity.NewEvent(entity.EventKeyRotated)
+		event.Data = map[string]interface{}{
+			"kid":         key.ID,
+			"previousKid": previous.ID,
+			"algorithm":   key.Method.Alg(),
+		}
+		events.Publish(context.Background(), event)
+	}
+}
+
+// Rotate generates a new asymmetric signing key. Symmetric keys can only be
+// rotated by supplying a new shared secret through AddKey.
+func (k *KeyRing) Rotate() (*SigningKey, error) {
+	if !k.Active().IsAsymmetric() {
+		return nil, fmt.Errorf("symmetric keys cannot be generated locally")
+	}
+
+	key, err := GenerateKey(k.algorithm)
+	if err != nil {
+		return nil, err
+	}
+	k.AddKey(key)
+	return key, nil
+}
+
+// StartRotation rotates the signing key on the given interval until the context is cancelled
+func (k *KeyRing) StartRotation(ctx context.Context, interval time.Duration, logger logger.Logger) {
+	if interval <= 0 || !k.Active().IsAsymmetric() {
+		return
+	}
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				key, err := k.Rotate()
+				if err != nil {
+					logger.Error("Failed to rotate signing key", "error", err)
+					continue
+				}
+				logger.Info("Signing key rotated", "kid", key.ID)
+			}
+		}
+	}()
+}
+
+// JWKS returns the public keys of all asymmetric keys in the ring
+func (k *KeyRing) JWKS() *entity.JSONWebKeySet {
+	k.mu.RLock()
+	defer k.mu.RUnlock()
+
+	set := &entity.JSONWebKeySet{Keys: make([]entity.JSONWebKey, 0, len(k.keys))}
+	now := time.Now()
+	for _, key := range k.keys {
+		if !key.IsAsymmetric() || k.isExpired(key, now) {
+			continue
+		}
+		set.Keys = append(set.Keys, toJSONWebKey(key))
+	}
+	return set
+}
+
+// pruneLocked drops retired keys whose retention period has elapsed
+func (k *KeyRing) pruneLocked(now time.Time) {
+	for id, key := range k.keys {
+		if k.isExpired(key, now) {
+			delete(k.keys, id)
+		}
+	}
+}
+
+func (k *KeyRing) isExpired(key *SigningKey, now time.Time) bool {
+	return !key.RetiredAt.IsZero() && now.Sub(key.RetiredAt) > k.retention
+}
+
+func toJSONWebKey(key *SigningKey) entity.JSONWebKey {
+	jwk := entity.JSONWebKey{
+		KeyID:     key.ID,
+		Use:       "sig",
+		Algorithm: key.Method.Alg(),
+	}
+
+	switch public := key.Public.(type) {
+	case *rsa.PublicKey:
+		jwk.KeyType = "RSA"
+		jwk.N = base64.RawURLEncoding.EncodeToString(public.N.Bytes())
+		jwk.E = base64.RawURLEncoding.EncodeToString(big.NewInt(int64(public.E)).Bytes())
+	case *ecdsa.PublicKey:
+		size := (public.Curve.Params().BitSize + 7) / 8
+		jwk.KeyType = "EC"
+		jwk.Curve = public.Curve.Params().Name
+		jwk.X = base64.RawURLEncoding.EncodeToString(public.X.FillBytes(make([]byte, size)))
+		jwk.Y = base64.RawURLEncoding.EncodeToString(public.Y.FillBytes(make([]byte, size)))
+	}
+
+	return jwk
+}
+
+// fromJSONWebKey returns the public key of an RSA or EC JSON Web Key
+func fromJSONWebKey(jwk entity.JSONWebKey) (crypto.PublicKey, error) {
+	switch jwk.KeyType {
+	case "RSA":
+		n, err := base64.RawURLEncoding.DecodeString(jwk.N)
+		if err != nil {
+			return nil, fmt.Errorf("invalid RSA modulus: %w", err)
+		}
+		e, err := base64.RawURLEncoding.DecodeString(jwk.E)
+		if err != nil || len(e) == 0 || len(e) > 4 {
+			return nil, fmt.Errorf("invalid RSA exponent")
+		}
+		return &rsa.PublicKey{N: new(big.Int).SetBytes(n), E: int(new(big.Int).SetBytes(e).Int64())}, nil
+	case "EC":
+		var curve elliptic.Curve
+		switch jwk.Curve {
+		case "P-256":
+			curve = elliptic.P256()
+		case "P-384":
+			curve = elliptic.P384()
+		case "P-521":
+			curve = elliptic.P521()
+		default:
+			return nil, fmt.Errorf("unsupported curve: %s", jwk.Curve)
+		}
+		x, err := base64.RawURLEncoding.DecodeString(jwk.X)
+		if err != nil {
+			return nil, fmt.Errorf("invalid EC x coordinate: %w", err)
+		}
+		y, err := base64.RawURLEncoding.DecodeString(jwk.Y)
+		if err != nil {
+			return nil, fmt.Errorf("invalid EC y coordinate: %w", err)
+		}
+		public := &ecdsa.PublicKey{Curve: curve, X: new(big.Int).SetBytes(x), Y: new(big.Int).SetBytes(y)}
+		if !curve.IsOnCurve(public.X, public.Y) {
+			return nil, fmt.Errorf("EC point is not on curve %s", jwk.Curve)
+		}
+		return public, nil
+	default:
+		return nil, fmt.Errorf("unsupported key type: %s", jwk.KeyType)
+	}
+}
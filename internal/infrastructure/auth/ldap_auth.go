@@ -0,0 +1,179 @@
+package auth
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"net"
+	"strings"
+	"time"
+
+	"api-gateway-sample/internal/domain/entity"
+	"api-gateway-sample/pkg/errors"
+	"api-gateway-sample/pkg/logger"
+
+	"github.com/go-ldap/ldap/v3"
+)
+
+// LDAPAuth implements the CredentialValidator interface against an LDAP or Active Directory server.
+// A service account looks up the user entry, the user's password is verified with a bind and
+// the user's groups are mapped to gateway roles.
+type LDAPAuth struct {
+	url            string
+	bindDN         string
+	bindPassword   string
+	baseDN         string
+	userFilter     string
+	groupAttribute string
+	groupRoles     map[string]string
+	startTLS       bool
+	timeout        time.Duration
+	logger         logger.Logger
+}
+
+// NewLDAPAuth creates a new LDAPAuth instance
+func NewLDAPAuth(
+	url string,
+	bindDN string,
+	bindPassword string,
+	baseDN string,
+	userFilter string,
+	groupAttribute string,
+	groupRoles map[string]string,
+	startTLS bool,
+	timeout time.Duration,
+	logger logger.Logger,
+) *LDAPAuth {
+	return &LDAPAuth{
+		url:            url,
+		bindDN:         bindDN,
+		bindPassword:   bindPassword,
+		baseDN:         baseDN,
+		userFilter:     userFilter,
+		groupAttribute: groupAttribute,
+		groupRoles:     groupRoles,
+		startTLS:       startTLS,
+		timeout:        timeout,
+		logger:         logger,
+	}
+}
+
+// ValidateCredentials verifies the username and password and returns claims carrying the mapped roles
+func (a *LDAPAuth) ValidateCredentials(ctx context.Context, username string, password string) (map[string]interface{}, error) {
+	// An empty password would be an unauthenticated bind, which most servers accept
+	if username == "" || password == "" {
+		return nil, errors.ErrUnauthorized
+	}
+
+	conn, err := a.dial()
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	if err := conn.Bind(a.bindDN, a.bindPassword); err != nil {
+		return nil, fmt.Errorf("ldap service bind failed: %w", err)
+	}
+
+	filter := strings.ReplaceAll(a.userFilter, "{username}", ldap.EscapeFilter(username))
+	result, err := conn.Search(ldap.NewSearchRequest(
+		a.baseDN,
+		ldap.ScopeWholeSubtree, ldap.NeverDerefAliases, 2, int(a.timeout.Seconds()), false,
+		filter,
+		[]string{"dn", a.groupAttribute},
+		nil,
+	))
+	if err != nil {
+		return nil, fmt.Errorf("ldap user search failed: %w", err)
+	}
+	if len(result.Entries) != 1 {
+		return nil, errors.ErrUnauthorized
+	}
+	entry := result.Entries[0]
+
+	// Verify the user's password by binding as the user
+	if err := conn.Bind(entry.DN, password); err != nil {
+		if ldap.IsErrorWithCode(err, ldap.LDAPResultInvalidCredentials) {
+			return nil, errors.ErrUnauthorized
+		}
+		return nil, fmt.Errorf("ldap user bind failed: %w", err)
+	}
+
+	groups := entry.GetAttributeValues(a.groupAttribute)
+	roles := MapGroupsToRoles(groups, a.groupRoles)
+	logger.FromContextOr(ctx, a.logger).Debug("LDAP user authenticated", "user", username, "roles", roles)
+
+	return map[string]interface{}{
+		"sub":    username,
+		"dn":     entry.DN,
+		"groups": groups,
+		"roles":  roles,
+		"amr":    []string{entity.AuthMethodLDAP},
+	}, nil
+}
+
+func (a *LDAPAuth) dial() (*ldap.Conn, error) {
+	dialer := &net.Dialer{Timeout: a.timeout}
+	conn, err := ldap.DialURL(a.url, ldap.DialWithDialer(dialer))
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to ldap: %w", err)
+	}
+	conn.SetTimeout(a.timeout)
+
+	if a.startTLS {
+		if err := conn.StartTLS(&tls.Config{ServerName: hostname(a.url)}); err != nil {
+			conn.Close()
+			return nil, fmt.Errorf("ldap starttls failed: %w", err)
+		}
+	}
+	return conn, nil
+}
+
+// hostname extracts the host from an ldap:// URL
+func hostname(url string) string {
+	host := url
+	if i := strings.Index(host, "://"); i >= 0 {
+		host = host[i+3:]
+	}
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		return h
+	}
+	return strings.TrimSuffix(host, "/")
+}
+
+// MapGroupsToRoles converts LDAP group memberships to gateway roles. Groups may be
+// configured by their full DN or by their CN, compared case-insensitively.
+func MapGroupsToRoles(groups []string, groupRoles map[string]string) []string {
+	mapping := make(map[string]string, len(groupRoles))
+	for group, role := range groupRoles {
+		mapping[strings.ToLower(group)] = role
+	}
+
+	roles := make([]string, 0)
+	seen := make(map[string]bool)
+	for _, group := range groups {
+		role, ok := mapping[strings.ToLower(group)]
+		if !ok {
+			role, ok = mapping[strings.ToLower(groupCN(group))]
+		}
+		if ok && !seen[role] {
+			seen[role] = true
+			roles = append(roles, role)
+		}
+	}
+	return roles
+}
+
+// groupCN returns the common name of a group DN, or the value itself if it is not a DN
+func groupCN(group string) string {
+	dn, err := ldap.ParseDN(group)
+	if err != nil || len(dn.RDNs) == 0 {
+		return group
+	}
+	for _, attr := range dn.RDNs[0].Attributes {
+		if strings.EqualFold(attr.Type, "cn") {
+			return attr.Value
+		}
+	}
+	return group
+}
@@ -0,0 +1,39 @@
+package auth
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"api-gateway-sample/pkg/errors"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMapGroupsToRoles(t *testing.T) {
+	groupRoles := map[string]string{
+		"gateway-admins": "admin",
+		"CN=Order Managers,OU=Groups,DC=example,DC=com": "orders-service:/api/v1/orders",
+		"Readers": "viewer",
+	}
+
+	groups := []string{
+		"CN=Gateway-Admins,OU=Groups,DC=example,DC=com",
+		"cn=order managers,ou=groups,dc=example,dc=com",
+		"CN=Unmapped,OU=Groups,DC=example,DC=com",
+		"readers",
+		"Readers",
+	}
+
+	roles := MapGroupsToRoles(groups, groupRoles)
+	assert.Equal(t, []string{"admin", "orders-service:/api/v1/orders", "viewer"}, roles)
+	assert.Empty(t, MapGroupsToRoles(nil, groupRoles))
+}
+
+func TestLDAPAuth_RejectsEmptyPassword(t *testing.T) {
+	// An empty password must never reach the server, where it would be an unauthenticated bind
+	ldapAuth := NewLDAPAuth("ldap://127.0.0.1:1", "", "", "dc=example,dc=com", "(uid={username})", "memberOf", nil, false, time.Second, nopLogger{})
+
+	_, err := ldapAuth.ValidateCredentials(context.Background(), "alice", "")
+	assert.ErrorIs(t, err, errors.ErrUnauthorized)
+}
@@ -0,0 +1,321 @@
+package auth
+
+import (
+	"context"
+	"crypto"
+	"crypto/subtle"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"api-gateway-sample/internal/domain/entity"
+	"api-gateway-sample/pkg/logger"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const (
+	// maxOIDCResponseSize bounds the documents and token responses read from identity providers
+	maxOIDCResponseSize = 1 << 20
+	// minKeyRefreshInterval limits how often ID tokens signed with an unknown key refetch the
+	// provider's keys, so that forged tokens cannot make the gateway flood the provider
+	minKeyRefreshInterval = time.Minute
+)
+
+// idTokenAlgorithms are the ID token signing algorithms accepted from identity providers
+var idTokenAlgorithms = []string{"RS256", "RS384", "RS512", "PS256", "PS384", "PS512", "ES256", "ES384", "ES512"}
+
+// oidcDiscovery is the part of a provider's discovery document used by the login flow
+type oidcDiscovery struct {
+	Issuer                string `json:"issuer"`
+	AuthorizationEndpoint string `json:"authorization_endpoint"`
+	TokenEndpoint         string `json:"token_endpoint"`
+	JWKSURI               string `json:"jwks_uri"`
+}
+
+// OIDCAuth implements the OIDCProvider interface against an OpenID Connect identity provider.
+// The provider's endpoints are discovered on first use and its signing keys are fetched again
+// when an ID token is signed with a key the gateway does not know yet.
+type OIDCAuth struct {
+	issuer       string
+	clientID     string
+	clientSecret string
+	redirectURL  string
+	scopes       []string
+	rolesClaim   string
+	httpClient   *http.Client
+	logger       logger.Logger
+
+	mu            sync.Mutex
+	discovery     *oidcDiscovery
+	keys          map[string]crypto.PublicKey
+	keysFetchedAt time.Time
+}
+
+// NewOIDCAuth creates a new OIDCAuth instance. The values of the rolesClaim claim of ID tokens
+// become the user's roles; requests to the provider are bounded by timeout.
+func NewOIDCAuth(
+	issuer string,
+	clientID string,
+	clientSecret string,
+	redirectURL string,
+	scopes []string,
+	rolesClaim string,
+	timeout time.Duration,
+	logger logger.Logger,
+) *OIDCAuth {
+	return &OIDCAuth{
+		issuer:       issuer,
+		clientID:     clientID,
+		clientSecret: clientSecret,
+		redirectURL:  redirectURL,
+		scopes:       scopes,
+		rolesClaim:   rolesClaim,
+		httpClient:   &http.Client{Timeout: timeout},
+		logger:       logger,
+	}
+}
+
+// AuthorizationURL returns the provider's authorization endpoint with the parameters of an
+// authorization code request
+func (a *OIDCAuth) AuthorizationURL(ctx context.Context, state string, nonce string, codeChallenge string) (string, error) {
+	discovery, err := a.discover(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	authorizationURL, err := url.Parse(discovery.AuthorizationEndpoint)
+	if err != nil {
+		return "", fmt.Errorf("invalid authorization endpoint: %w", err)
+	}
+	query := authorizationURL.Query()
+	query.Set("response_type", "code")
+	query.Set("client_id", a.clientID)
+	query.Set("redirect_uri", a.redirectURL)
+	query.Set("scope", a.scope())
+	query.Set("state", state)
+	query.Set("nonce", nonce)
+	query.Set("code_challenge", codeChallenge)
+	query.Set("code_challenge_method", "S256")
+	authorizationURL.RawQuery = query.Encode()
+	return authorizationURL.String(), nil
+}
+
+// Exchange redeems an authorization code at the provider's token endpoint and returns claims
+// carrying the user's subject, profile and mapped roles
+func (a *OIDCAuth) Exchange(ctx context.Context, code string, codeVerifier string, nonce string) (map[string]interface{}, error) {
+	discovery, err := a.discover(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	form := url.Values{
+		"grant_type":    {"authorization_code"},
+		"code":          {code},
+		"redirect_uri":  {a.redirectURL},
+		"code_verifier": {codeVerifier},
+		"client_id":     {a.clientID},
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, discovery.TokenEndpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create token request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+	// Public clients authenticate with the code verifier alone
+	if a.clientSecret != "" {
+		req.SetBasicAuth(url.QueryEscape(a.clientID), url.QueryEscape(a.clientSecret))
+	}
+
+	resp, err := a.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("token request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxOIDCResponseSize))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read token response: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("token request failed with status %d", resp.StatusCode)
+	}
+	var tokenResponse struct {
+		IDToken string `json:"id_token"`
+	}
+	if err := json.Unmarshal(body, &tokenResponse); err != nil || tokenResponse.IDToken == "" {
+		return nil, fmt.Errorf("token response carries no ID token")
+	}
+
+	idClaims, err := a.verifyIDToken(ctx, discovery, tokenResponse.IDToken, nonce)
+	if err != nil {
+		return nil, err
+	}
+	claims := a.userClaims(idClaims)
+	logger.FromContextOr(ctx, a.logger).Debug("OIDC user authenticated", "user", claims["sub"], "roles", claims["roles"])
+	return claims, nil
+}
+
+// verifyIDToken checks the signature, issuer, audience, expiry and nonce of an ID token
+func (a *OIDCAuth) verifyIDToken(ctx context.Context, discovery *oidcDiscovery, idToken string, nonce string) (jwt.MapClaims, error) {
+	claims := jwt.MapClaims{}
+	_, err := jwt.ParseWithClaims(idToken, claims, func(token *jwt.Token) (interface{}, error) {
+		kid, _ := token.Header["kid"].(string)
+		return a.signingKey(ctx, discovery, kid)
+	},
+		jwt.WithValidMethods(idTokenAlgorithms),
+		jwt.WithIssuer(discovery.Issuer),
+		jwt.WithAudience(a.clientID),
+		jwt.WithExpirationRequired(),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("invalid ID token: %w", err)
+	}
+
+	tokenNonce, _ := claims["nonce"].(string)
+	if subtle.ConstantTimeCompare([]byte(tokenNonce), []byte(nonce)) != 1 {
+		return nil, fmt.Errorf("invalid ID token: nonce does not match the login")
+	}
+	if sub, _ := claims["sub"].(string); sub == "" {
+		return nil, fmt.Errorf("invalid ID token: missing subject")
+	}
+	return claims, nil
+}
+
+// userClaims returns the gateway claims of a user from the claims of their ID token
+func (a *OIDCAuth) userClaims(idClaims jwt.MapClaims) map[string]interface{} {
+	claims := map[string]interface{}{
+		"sub":   idClaims["sub"],
+		"roles": claimValues(idClaims[a.rolesClaim]),
+		"amr":   []string{entity.AuthMethodOIDC},
+	}
+	for _, name := range []string{"email", "name", "preferred_username"} {
+		if value, ok := idClaims[name].(string); ok && value != "" {
+			claims[name] = value
+		}
+	}
+	return claims
+}
+
+// claimValues returns the strings of a claim holding a list or a space separated string
+func claimValues(claim interface{}) []string {
+	values := make([]string, 0)
+	switch claim := claim.(type) {
+	case string:
+		values = append(values, strings.Fields(claim)...)
+	case []interface{}:
+		for _, value := range claim {
+			if s, ok := value.(string); ok && s != "" {
+				values = append(values, s)
+			}
+		}
+	}
+	return values
+}
+
+// scope returns the requested scopes, which always include openid
+func (a *OIDCAuth) scope() string {
+	for _, scope := range a.scopes {
+		if scope == "openid" {
+			return strings.Join(a.scopes, " ")
+		}
+	}
+	return strings.Join(append([]string{"openid"}, a.scopes...), " ")
+}
+
+// discover returns the provider's discovery document, fetching it on first use
+func (a *OIDCAuth) discover(ctx context.Context) (*oidcDiscovery, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if a.discovery != nil {
+		return a.discovery, nil
+	}
+
+	var discovery oidcDiscovery
+	if err := a.fetchJSON(ctx, strings.TrimSuffix(a.issuer, "/")+"/.well-known/openid-configuration", &discovery); err != nil {
+		return nil, fmt.Errorf("OIDC discovery failed: %w", err)
+	}
+	// The issuer must match, or ID tokens of another issuer would be accepted (OIDC Discovery 4.3)
+	if strings.TrimSuffix(discovery.Issuer, "/") != strings.TrimSuffix(a.issuer, "/") {
+		return nil, fmt.Errorf("OIDC discovery returned issuer %q, expected %q", discovery.Issuer, a.issuer)
+	}
+	if discovery.AuthorizationEndpoint == "" || discovery.TokenEndpoint == "" || discovery.JWKSURI == "" {
+		return nil, fmt.Errorf("OIDC discovery document is missing endpoints")
+	}
+	a.discovery = &discovery
+	return a.discovery, nil
+}
+
+// signingKey returns the provider key with the given kid, refetching the provider's keys when
+// it is unknown. Tokens without a kid are accepted when the provider has a single key.
+func (a *OIDCAuth) signingKey(ctx context.Context, discovery *oidcDiscovery, kid string) (crypto.PublicKey, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if key, ok := a.lookupKey(kid); ok {
+		return key, nil
+	}
+	if a.keys != nil && time.Since(a.keysFetchedAt) < minKeyRefreshInterval {
+		return nil, fmt.Errorf("unknown signing key %q", kid)
+	}
+
+	var keySet entity.JSONWebKeySet
+	if err := a.fetchJSON(ctx, discovery.JWKSURI, &keySet); err != nil {
+		return nil, fmt.Errorf("failed to fetch provider keys: %w", err)
+	}
+	keys := make(map[string]crypto.PublicKey, len(keySet.Keys))
+	for _, jwk := range keySet.Keys {
+		if jwk.Use != "" && jwk.Use != "sig" {
+			continue
+		}
+		key, err := fromJSONWebKey(jwk)
+		if err != nil {
+			a.logger.Warn("Skipping invalid provider key", "kid", jwk.KeyID, "error", err)
+			continue
+		}
+		keys[jwk.KeyID] = key
+	}
+	a.keys = keys
+	a.keysFetchedAt = time.Now()
+
+	if key, ok := a.lookupKey(kid); ok {
+		return key, nil
+	}
+	return nil, fmt.Errorf("unknown signing key %q", kid)
+}
+
+// lookupKey returns the known key with the given kid, or the only key for an empty kid
+func (a *OIDCAuth) lookupKey(kid string) (crypto.PublicKey, bool) {
+	if kid == "" && len(a.keys) == 1 {
+		for _, key := range a.keys {
+			return key, true
+		}
+	}
+	key, ok := a.keys[kid]
+	return key, ok
+}
+
+// fetchJSON decodes the JSON document served at a provider URL
+func (a *OIDCAuth) fetchJSON(ctx context.Context, documentURL string, v interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, documentURL, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := a.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("%s returned status %d", documentURL, resp.StatusCode)
+	}
+	return json.NewDecoder(io.LimitReader(resp.Body, maxOIDCResponseSize)).Decode(v)
+}
@@ -0,0 +1,135 @@
+package auth
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"api-gateway-sample/internal/domain/entity"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testProvider is an identity provider issuing ID tokens with the given claims for the code "good"
+type testProvider struct {
+	server *httptest.Server
+	key    *SigningKey
+	claims jwt.MapClaims
+	// form is the last token request
+	form url.Values
+}
+
+func newTestProvider(t *testing.T) *testProvider {
+	key, err := GenerateKey("RS256")
+	require.NoError(t, err)
+	p := &testProvider{key: key}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/.well-known/openid-configuration", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(oidcDiscovery{
+			Issuer:                p.server.URL,
+			AuthorizationEndpoint: p.server.URL + "/authorize",
+			TokenEndpoint:         p.server.URL + "/token",
+			JWKSURI:               p.server.URL + "/keys",
+		})
+	})
+	mux.HandleFunc("/keys", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(entity.JSONWebKeySet{Keys: []entity.JSONWebKey{toJSONWebKey(p.key)}})
+	})
+	mux.HandleFunc("/token", func(w http.ResponseWriter, r *http.Request) {
+		r.ParseForm()
+		p.form = r.PostForm
+		if r.PostForm.Get("code") != "good" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		token := jwt.NewWithClaims(p.key.Method, p.claims)
+		token.Header["kid"] = p.key.ID
+		idToken, err := token.SignedString(p.key.Private)
+		require.NoError(t, err)
+		json.NewEncoder(w).Encode(map[string]string{"id_token": idToken})
+	})
+	p.server = httptest.NewServer(mux)
+	t.Cleanup(p.server.Close)
+
+	p.claims = jwt.MapClaims{
+		"iss":   p.server.URL,
+		"aud":   "gateway",
+		"sub":   "alice",
+		"exp":   time.Now().Add(time.Hour).Unix(),
+		"nonce": "n-1",
+		"email": "alice@example.com",
+		"roles": []string{"admin"},
+	}
+	return p
+}
+
+func TestOIDCAuth_AuthorizationURL(t *testing.T) {
+	provider := newTestProvider(t)
+	oidc := NewOIDCAuth(provider.server.URL, "gateway", "secret", "https://gateway/auth/callback", []string{"email"}, "roles", time.Second, nopLogger{})
+
+	authorizationURL, err := oidc.AuthorizationURL(context.Background(), "s-1", "n-1", "challenge")
+	require.NoError(t, err)
+
+	parsed, err := url.Parse(authorizationURL)
+	require.NoError(t, err)
+	assert.Equal(t, provider.server.URL+"/authorize", parsed.Scheme+"://"+parsed.Host+parsed.Path)
+	query := parsed.Query()
+	assert.Equal(t, "code", query.Get("response_type"))
+	assert.Equal(t, "openid email", query.Get("scope"))
+	assert.Equal(t, "s-1", query.Get("state"))
+	assert.Equal(t, "n-1", query.Get("nonce"))
+	assert.Equal(t, "challenge", query.Get("code_challenge"))
+	assert.Equal(t, "S256", query.Get("code_challenge_method"))
+}
+
+func TestOIDCAuth_Exchange(t *testing.T) {
+	ctx := context.Background()
+	provider := newTestProvider(t)
+	oidc := NewOIDCAuth(provider.server.URL, "gateway", "secret", "https://gateway/auth/callback", nil, "roles", time.Second, nopLogger{})
+
+	claims, err := oidc.Exchange(ctx, "good", "verifier", "n-1")
+	require.NoError(t, err)
+	assert.Equal(t, "alice", claims["sub"])
+	assert.Equal(t, []string{"admin"}, claims["roles"])
+	assert.Equal(t, "alice@example.com", claims["email"])
+	assert.Equal(t, "verifier", provider.form.Get("code_verifier"))
+
+	// The nonce must be the login's
+	_, err = oidc.Exchange(ctx, "good", "verifier", "n-2")
+	assert.Error(t, err)
+
+	// Tokens issued to another client are rejected
+	provider.claims["aud"] = "other"
+	_, err = oidc.Exchange(ctx, "good", "verifier", "n-1")
+	assert.Error(t, err)
+
+	// Rejected codes fail
+	_, err = oidc.Exchange(ctx, "bad", "verifier", "n-1")
+	assert.Error(t, err)
+}
+
+func TestOIDCAuth_ExchangeRejectsForeignSignature(t *testing.T) {
+	provider := newTestProvider(t)
+	oidc := NewOIDCAuth(provider.server.URL, "gateway", "", "https://gateway/auth/callback", nil, "roles", time.Second, nopLogger{})
+
+	// A token signed with a key the provider does not publish, under a published kid
+	published := provider.key
+	forged, err := GenerateKey("RS256")
+	require.NoError(t, err)
+	forged.ID = published.ID
+	_, err = oidc.AuthorizationURL(context.Background(), "s", "n", "c")
+	require.NoError(t, err)
+	_, err = oidc.signingKey(context.Background(), oidc.discovery, published.ID)
+	require.NoError(t, err)
+	provider.key = forged
+
+	_, err = oidc.Exchange(context.Background(), "good", "verifier", "n-1")
+	assert.Error(t, err)
+}
@@ -0,0 +1,361 @@
+package auth
+
+import (
+	"bytes"
+	"compress/flate"
+	"context"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"encoding/xml"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"api-gateway-sample/internal/domain/entity"
+	"api-gateway-sample/pkg/logger"
+)
+
+// SAML 2.0 namespaces and identifiers (https://docs.oasis-open.org/security/saml/v2.0/)
+const (
+	namespaceSAMLProtocol  = "urn:oasis:names:tc:SAML:2.0:protocol"
+	namespaceSAMLAssertion = "urn:oasis:names:tc:SAML:2.0:assertion"
+	samlStatusSuccess      = "urn:oasis:names:tc:SAML:2.0:status:Success"
+	samlBindingHTTPPost    = "urn:oasis:names:tc:SAML:2.0:bindings:HTTP-POST"
+	samlMethodBearer       = "urn:oasis:names:tc:SAML:2.0:cm:bearer"
+	samlNameIDUnspecified  = "urn:oasis:names:tc:SAML:1.1:nameid-format:unspecified"
+)
+
+// maxSAMLResponseSize bounds the SAML responses accepted from identity providers
+const maxSAMLResponseSize = 1 << 20
+
+// SAMLAuth implements the SAMLServiceProvider interface: the gateway sends authentication
+// requests with the HTTP-Redirect binding and consumes responses posted with the HTTP-POST
+// binding. Either the response or its assertion must be signed by the identity provider;
+// encrypted assertions and unsolicited responses are not supported.
+type SAMLAuth struct {
+	entityID       string
+	acsURL         string
+	idpEntityID    string
+	idpSSOURL      string
+	certificate    *x509.Certificate
+	rolesAttribute string
+	attributeRoles map[string]string
+	clockSkew      time.Duration
+	now            func() time.Time
+	logger         logger.Logger
+}
+
+// NewSAMLAuth creates a new SAMLAuth instance. Responses must be signed with the identity
+// provider's certificate; the values of the rolesAttribute attribute are mapped to gateway roles
+// through attributeRoles.
+func NewSAMLAuth(
+	entityID string,
+	acsURL string,
+	idpEntityID string,
+	idpSSOURL string,
+	certificate *x509.Certificate,
+	rolesAttribute string,
+	attributeRoles map[string]string,
+	clockSkew time.Duration,
+	logger logger.Logger,
+) *SAMLAuth {
+	return &SAMLAuth{
+		entityID:       entityID,
+		acsURL:         acsURL,
+		idpEntityID:    idpEntityID,
+		idpSSOURL:      idpSSOURL,
+		certificate:    certificate,
+		rolesAttribute: rolesAttribute,
+		attributeRoles: attributeRoles,
+		clockSkew:      clockSkew,
+		now:            time.Now,
+		logger:         logger,
+	}
+}
+
+// ParseCertificatePEM parses a PEM encoded X.509 certificate
+func ParseCertificatePEM(data []byte) (*x509.Certificate, error) {
+	block, _ := pem.Decode(data)
+	if block == nil || block.Type != "CERTIFICATE" {
+		return nil, fmt.Errorf("no PEM encoded certificate found")
+	}
+	return x509.ParseCertificate(block.Bytes)
+}
+
+// samlEntityDescriptor is the metadata document describing the gateway as service provider
+type samlEntityDescriptor struct {
+	XMLName  xml.Name `xml:"urn:oasis:names:tc:SAML:2.0:metadata EntityDescriptor"`
+	EntityID string   `xml:"entityID,attr"`
+	SP       struct {
+		AuthnRequestsSigned        bool   `xml:"AuthnRequestsSigned,attr"`
+		WantAssertionsSigned       bool   `xml:"WantAssertionsSigned,attr"`
+		ProtocolSupportEnumeration string `xml:"protocolSupportEnumeration,attr"`
+		NameIDFormat               string `xml:"NameIDFormat"`
+		AssertionConsumerService   struct {
+			Binding   string `xml:"Binding,attr"`
+			Location  string `xml:"Location,attr"`
+			Index     int    `xml:"index,attr"`
+			IsDefault bool   `xml:"isDefault,attr"`
+		} `xml:"AssertionConsumerService"`
+	} `xml:"SPSSODescriptor"`
+}
+
+// samlAuthnRequest is an authentication request sent to the identity provider
+type samlAuthnRequest struct {
+	XMLName                     xml.Name `xml:"urn:oasis:names:tc:SAML:2.0:protocol AuthnRequest"`
+	ID                          string   `xml:"ID,attr"`
+	Version                     string   `xml:"Version,attr"`
+	IssueInstant                string   `xml:"IssueInstant,attr"`
+	Destination                 string   `xml:"Destination,attr"`
+	AssertionConsumerServiceURL string   `xml:"AssertionConsumerServiceURL,attr"`
+	ProtocolBinding             string   `xml:"ProtocolBinding,attr"`
+	Issuer                      string   `xml:"urn:oasis:names:tc:SAML:2.0:assertion Issuer"`
+}
+
+// Metadata returns the gateway's service provider metadata, to register with the identity provider
+func (a *SAMLAuth) Metadata() ([]byte, error) {
+	var descriptor samlEntityDescriptor
+	descriptor.EntityID = a.entityID
+	descriptor.SP.WantAssertionsSigned = true
+	descriptor.SP.ProtocolSupportEnumeration = namespaceSAMLProtocol
+	descriptor.SP.NameIDFormat = samlNameIDUnspecified
+	descriptor.SP.AssertionConsumerService.Binding = samlBindingHTTPPost
+	descriptor.SP.AssertionConsumerService.Location = a.acsURL
+	descriptor.SP.AssertionConsumerService.IsDefault = true
+
+	document, err := xml.MarshalIndent(descriptor, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append([]byte(xml.Header), document...), nil
+}
+
+// AuthnRequestURL returns the identity provider's single sign-on URL carrying an authentication
+// request with the given ID and relay state, encoded for the HTTP-Redirect binding
+func (a *SAMLAuth) AuthnRequestURL(requestID string, relayState string) (string, error) {
+	request := samlAuthnRequest{
+		ID:                          requestID,
+		Version:                     "2.0",
+		IssueInstant:                a.now().UTC().Format(time.RFC3339),
+		Destination:                 a.idpSSOURL,
+		AssertionConsumerServiceURL: a.acsURL,
+		ProtocolBinding:             samlBindingHTTPPost,
+		Issuer:                      a.entityID,
+	}
+	document, err := xml.Marshal(request)
+	if err != nil {
+		return "", err
+	}
+
+	var compressed bytes.Buffer
+	writer, err := flate.NewWriter(&compressed, flate.BestCompression)
+	if err != nil {
+		return "", err
+	}
+	writer.Write(document)
+	writer.Close()
+
+	ssoURL, err := url.Parse(a.idpSSOURL)
+	if err != nil {
+		return "", fmt.Errorf("invalid identity provider URL: %w", err)
+	}
+	query := ssoURL.Query()
+	query.Set("SAMLRequest", base64.StdEncoding.EncodeToString(compressed.Bytes()))
+	if relayState != "" {
+		query.Set("RelayState", relayState)
+	}
+	ssoURL.RawQuery = query.Encode()
+	return ssoURL.String(), nil
+}
+
+// ParseResponse validates a base64 encoded SAML response and returns the ID of the request it
+// answers and the claims of the user it asserts
+func (a *SAMLAuth) ParseResponse(ctx context.Context, encodedResponse string) (string, map[string]interface{}, error) {
+	if len(encodedResponse) > maxSAMLResponseSize {
+		return "", nil, fmt.Errorf("SAML response is too large")
+	}
+	data, err := base64.StdEncoding.DecodeString(strings.Join(strings.Fields(encodedResponse), ""))
+	if err != nil {
+		return "", nil, fmt.Errorf("SAML response is not base64 encoded")
+	}
+	response, err := parseXML(data)
+	if err != nil {
+		return "", nil, err
+	}
+	if !response.is(namespaceSAMLProtocol, "Response") {
+		return "", nil, fmt.Errorf("document is not a SAML response")
+	}
+
+	if destination := response.attr("Destination"); destination != "" && destination != a.acsURL {
+		return "", nil, fmt.Errorf("SAML response is destined for %s", destination)
+	}
+	if issuer := response.child(namespaceSAMLAssertion, "Issuer"); issuer != nil && issuer.text() != a.idpEntityID {
+		return "", nil, fmt.Errorf("SAML response was issued by %s", issuer.text())
+	}
+	if status := samlStatus(response); status != samlStatusSuccess {
+		return "", nil, fmt.Errorf("identity provider answered with status %s", status)
+	}
+
+	responseSigned, err := verifyEnvelopedSignature(response, a.certificate)
+	if err != nil {
+		return "", nil, fmt.Errorf("invalid SAML response signature: %w", err)
+	}
+	if len(response.childElements(namespaceSAMLAssertion, "EncryptedAssertion")) > 0 {
+		return "", nil, fmt.Errorf("encrypted assertions are not supported")
+	}
+	assertions := response.childElements(namespaceSAMLAssertion, "Assertion")
+	if len(assertions) != 1 {
+		return "", nil, fmt.Errorf("SAML response must carry exactly one assertion")
+	}
+	// The assertion read is the one signed, either itself or as part of the signed response
+	assertion := assertions[0]
+	assertionSigned, err := verifyEnvelopedSignature(assertion, a.certificate)
+	if err != nil {
+		return "", nil, fmt.Errorf("invalid SAML assertion signature: %w", err)
+	}
+	if !responseSigned && !assertionSigned {
+		return "", nil, fmt.Errorf("SAML response is not signed")
+	}
+
+	now := a.now()
+	if issuer := assertion.child(namespaceSAMLAssertion, "Issuer"); issuer == nil || issuer.text() != a.idpEntityID {
+		return "", nil, fmt.Errorf("SAML assertion was not issued by %s", a.idpEntityID)
+	}
+	if err := a.checkConditions(assertion, now); err != nil {
+		return "", nil, err
+	}
+	subject, inResponseTo, err := a.checkSubject(assertion, now)
+	if err != nil {
+		return "", nil, err
+	}
+	// The response's InResponseTo is only trusted when the response itself is signed
+	if responseTo := response.attr("InResponseTo"); responseTo != "" && responseSigned {
+		if inResponseTo != "" && inResponseTo != responseTo {
+			return "", nil, fmt.Errorf("SAML response and assertion answer different requests")
+		}
+		inResponseTo = responseTo
+	}
+	if inResponseTo == "" {
+		return "", nil, fmt.Errorf("unsolicited SAML responses are not accepted")
+	}
+
+	values := a.attributeValues(assertion, a.rolesAttribute)
+	claims := map[string]interface{}{
+		"sub":   subject,
+		"roles": MapGroupsToRoles(values, a.attributeRoles),
+		"amr":   []string{entity.AuthMethodSAML},
+	}
+	logger.FromContextOr(ctx, a.logger).Debug("SAML user authenticated", "user", subject, "roles", claims["roles"])
+	return inResponseTo, claims, nil
+}
+
+// checkConditions checks that the assertion is within its validity period and addressed to the gateway
+func (a *SAMLAuth) checkConditions(assertion *xmlElement, now time.Time) error {
+	conditions := assertion.child(namespaceSAMLAssertion, "Conditions")
+	if conditions == nil {
+		return fmt.Errorf("SAML assertion has no conditions")
+	}
+	if err := a.checkValidity(conditions, now); err != nil {
+		return err
+	}
+
+	// Every audience restriction must name the gateway
+	restrictions := conditions.childElements(namespaceSAMLAssertion, "AudienceRestriction")
+	if len(restrictions) == 0 {
+		return fmt.Errorf("SAML assertion has no audience restriction")
+	}
+	for _, restriction := range restrictions {
+		addressed := false
+		for _, audience := range restriction.childElements(namespaceSAMLAssertion, "Audience") {
+			addressed = addressed || audience.text() == a.entityID
+		}
+		if !addressed {
+			return fmt.Errorf("SAML assertion is not addressed to %s", a.entityID)
+		}
+	}
+	return nil
+}
+
+// checkSubject returns the subject of the assertion and the request its bearer confirmation
+// answers, which must be addressed to the gateway's assertion consumer service
+func (a *SAMLAuth) checkSubject(assertion *xmlElement, now time.Time) (string, string, error) {
+	subject := assertion.child(namespaceSAMLAssertion, "Subject")
+	if subject == nil {
+		return "", "", fmt.Errorf("SAML assertion has no subject")
+	}
+	nameID := subject.child(namespaceSAMLAssertion, "NameID")
+	if nameID == nil || nameID.text() == "" {
+		return "", "", fmt.Errorf("SAML assertion has no name ID")
+	}
+
+	for _, confirmation := range subject.childElements(namespaceSAMLAssertion, "SubjectConfirmation") {
+		data := confirmation.child(namespaceSAMLAssertion, "SubjectConfirmationData")
+		if confirmation.attr("Method") != samlMethodBearer || data == nil || data.attr("Recipient") != a.acsURL {
+			continue
+		}
+		if data.attr("NotOnOrAfter") == "" {
+			continue
+		}
+		if err := a.checkValidity(data, now); err != nil {
+			return "", "", err
+		}
+		return nameID.text(), data.attr("InResponseTo"), nil
+	}
+	return "", "", fmt.Errorf("SAML assertion has no bearer confirmation for %s", a.acsURL)
+}
+
+// checkValidity checks the NotBefore and NotOnOrAfter attributes of an element, allowing for clock skew
+func (a *SAMLAuth) checkValidity(element *xmlElement, now time.Time) error {
+	if notBefore := element.attr("NotBefore"); notBefore != "" {
+		at, err := time.Parse(time.RFC3339, notBefore)
+		if err != nil {
+			return fmt.Errorf("invalid NotBefore %q", notBefore)
+		}
+		if now.Add(a.clockSkew).Before(at) {
+			return fmt.Errorf("SAML assertion is not valid before %s", notBefore)
+		}
+	}
+	if notOnOrAfter := element.attr("NotOnOrAfter"); notOnOrAfter != "" {
+		at, err := time.Parse(time.RFC3339, notOnOrAfter)
+		if err != nil {
+			return fmt.Errorf("invalid NotOnOrAfter %q", notOnOrAfter)
+		}
+		if !now.Add(-a.clockSkew).Before(at) {
+			return fmt.Errorf("SAML assertion expired at %s", notOnOrAfter)
+		}
+	}
+	return nil
+}
+
+// attributeValues returns the values of the named attribute of the assertion
+func (a *SAMLAuth) attributeValues(assertion *xmlElement, name string) []string {
+	values := make([]string, 0)
+	for _, statement := range assertion.childElements(namespaceSAMLAssertion, "AttributeStatement") {
+		for _, attribute := range statement.childElements(namespaceSAMLAssertion, "Attribute") {
+			if attribute.attr("Name") != name {
+				continue
+			}
+			for _, value := range attribute.childElements(namespaceSAMLAssertion, "AttributeValue") {
+				if text := value.text(); text != "" {
+					values = append(values, text)
+				}
+			}
+		}
+	}
+	return values
+}
+
+// samlStatus returns the top-level status code of a SAML response
+func samlStatus(response *xmlElement) string {
+	status := response.child(namespaceSAMLProtocol, "Status")
+	if status == nil {
+		return ""
+	}
+	code := status.child(namespaceSAMLProtocol, "StatusCode")
+	if code == nil {
+		return ""
+	}
+	return code.attr("Value")
+}
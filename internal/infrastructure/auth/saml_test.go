@@ -0,0 +1,211 @@
+package auth
+
+import (
+	"bytes"
+	"compress/flate"
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"math/big"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	testSPEntityID  = "https://gateway.example.com/auth/saml/metadata"
+	testACSURL      = "https://gateway.example.com/auth/saml/acs"
+	testIDPEntityID = "https://idp.example.com"
+)
+
+// testSAMLNow is the time the test responses are validated at
+var testSAMLNow = time.Date(2026, 1, 2, 10, 0, 0, 0, time.UTC)
+
+// testIDP signs SAML responses with a self-signed certificate
+type testIDP struct {
+	key         *rsa.PrivateKey
+	certificate *x509.Certificate
+}
+
+func newTestIDP(t *testing.T) *testIDP {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "idp.example.com"},
+		NotBefore:    testSAMLNow.Add(-time.Hour),
+		NotAfter:     testSAMLNow.Add(time.Hour),
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	require.NoError(t, err)
+	certificate, err := x509.ParseCertificate(der)
+	require.NoError(t, err)
+	return &testIDP{key: key, certificate: certificate}
+}
+
+// assertionOptions vary the assertion of a test response
+type assertionOptions struct {
+	audience     string
+	notOnOrAfter time.Time
+	inResponseTo string
+}
+
+func validAssertion() assertionOptions {
+	return assertionOptions{audience: testSPEntityID, notOnOrAfter: testSAMLNow.Add(5 * time.Minute), inResponseTo: "_req1"}
+}
+
+func (idp *testIDP) assertion(opts assertionOptions) string {
+	expiry := opts.notOnOrAfter.Format(time.RFC3339)
+	return fmt.Sprintf(`<saml:Assertion xmlns:saml="urn:oasis:names:tc:SAML:2.0:assertion" ID="_assertion1" Version="2.0" IssueInstant="%s">`+
+		`<saml:Issuer>%s</saml:Issuer>`+
+		`<saml:Subject><saml:NameID>alice@example.com</saml:NameID>`+
+		`<saml:SubjectConfirmation Method="urn:oasis:names:tc:SAML:2.0:cm:bearer"><saml:SubjectConfirmationData InResponseTo="%s" NotOnOrAfter="%s" Recipient="%s"/></saml:SubjectConfirmation></saml:Subject>`+
+		`<saml:Conditions NotBefore="%s" NotOnOrAfter="%s"><saml:AudienceRestriction><saml:Audience>%s</saml:Audience></saml:AudienceRestriction></saml:Conditions>`+
+		`<saml:AttributeStatement><saml:Attribute Name="Role"><saml:AttributeValue>Gateway Admins</saml:AttributeValue><saml:AttributeValue>Staff</saml:AttributeValue></saml:Attribute></saml:AttributeStatement>`+
+		`</saml:Assertion>`,
+		testSAMLNow.Format(time.RFC3339), testIDPEntityID, opts.inResponseTo, expiry, testACSURL,
+		testSAMLNow.Add(-time.Minute).Format(time.RFC3339), expiry, opts.audience)
+}
+
+func (idp *testIDP) response(assertion string) string {
+	return fmt.Sprintf(`<samlp:Response xmlns:samlp="urn:oasis:names:tc:SAML:2.0:protocol" xmlns:saml="urn:oasis:names:tc:SAML:2.0:assertion" ID="_response1" Version="2.0" IssueInstant="%s" Destination="%s" InResponseTo="_req1">`+
+		`<saml:Issuer>%s</saml:Issuer>`+
+		`<samlp:Status><samlp:StatusCode Value="urn:oasis:names:tc:SAML:2.0:status:Success"/></samlp:Status>`+
+		`%s</samlp:Response>`,
+		testSAMLNow.Format(time.RFC3339), testACSURL, testIDPEntityID, assertion)
+}
+
+// sign inserts an enveloped signature of the element with the given ID after its issuer
+func (idp *testIDP) sign(t *testing.T, element string, id string) string {
+	root, err := parseXML([]byte(element))
+	require.NoError(t, err)
+	var content bytes.Buffer
+	root.canonicalize(&content, nil, nil)
+	digest := sha256.Sum256(content.Bytes())
+
+	signedInfo := fmt.Sprintf(`<ds:SignedInfo xmlns:ds="%s"><ds:CanonicalizationMethod Algorithm="%s"/><ds:SignatureMethod Algorithm="%s"/>`+
+		`<ds:Reference URI="#%s"><ds:Transforms><ds:Transform Algorithm="%s"/><ds:Transform Algorithm="%s"/></ds:Transforms>`+
+		`<ds:DigestMethod Algorithm="%s"/><ds:DigestValue>%s</ds:DigestValue></ds:Reference></ds:SignedInfo>`,
+		namespaceXMLDSig, algorithmExcC14N, algorithmRSASHA256, id, algorithmEnvelopedSig, algorithmExcC14N,
+		algorithmSHA256, base64.StdEncoding.EncodeToString(digest[:]))
+	parsed, err := parseXML([]byte(signedInfo))
+	require.NoError(t, err)
+	var signed bytes.Buffer
+	parsed.canonicalize(&signed, nil, nil)
+	hash := sha256.Sum256(signed.Bytes())
+	value, err := rsa.SignPKCS1v15(rand.Reader, idp.key, crypto.SHA256, hash[:])
+	require.NoError(t, err)
+
+	signature := fmt.Sprintf(`<ds:Signature xmlns:ds="%s">%s<ds:SignatureValue>%s</ds:SignatureValue></ds:Signature>`,
+		namespaceXMLDSig, signedInfo, base64.StdEncoding.EncodeToString(value))
+	issuerEnd := strings.Index(element, "</saml:Issuer>") + len("</saml:Issuer>")
+	return element[:issuerEnd] + signature + element[issuerEnd:]
+}
+
+func newTestSAMLAuth(idp *testIDP) *SAMLAuth {
+	a := NewSAMLAuth(testSPEntityID, testACSURL, testIDPEntityID, "https://idp.example.com/sso", idp.certificate,
+		"Role", map[string]string{"Gateway Admins": "admin"}, 2*time.Minute, nopLogger{})
+	a.now = func() time.Time { return testSAMLNow }
+	return a
+}
+
+func encode(document string) string {
+	return base64.StdEncoding.EncodeToString([]byte(document))
+}
+
+func TestSAMLAuthParseResponse(t *testing.T) {
+	idp := newTestIDP(t)
+	a := newTestSAMLAuth(idp)
+
+	t.Run("signed assertion", func(t *testing.T) {
+		response := idp.response(idp.sign(t, idp.assertion(validAssertion()), "_assertion1"))
+
+		requestID, claims, err := a.ParseResponse(context.Background(), encode(response))
+		require.NoError(t, err)
+		assert.Equal(t, "_req1", requestID)
+		assert.Equal(t, "alice@example.com", claims["sub"])
+		assert.Equal(t, []string{"admin"}, claims["roles"])
+		assert.Equal(t, []string{"saml"}, claims["amr"])
+	})
+
+	t.Run("signed response", func(t *testing.T) {
+		response := idp.sign(t, idp.response(idp.assertion(validAssertion())), "_response1")
+
+		requestID, claims, err := a.ParseResponse(context.Background(), encode(response))
+		require.NoError(t, err)
+		assert.Equal(t, "_req1", requestID)
+		assert.Equal(t, "alice@example.com", claims["sub"])
+	})
+}
+
+func TestSAMLAuthParseResponseRejected(t *testing.T) {
+	idp := newTestIDP(t)
+	a := newTestSAMLAuth(idp)
+	other := newTestIDP(t)
+
+	expired := validAssertion()
+	expired.notOnOrAfter = testSAMLNow.Add(-5 * time.Minute)
+	otherAudience := validAssertion()
+	otherAudience.audience = "https://other.example.com"
+	unsolicited := validAssertion()
+	unsolicited.inResponseTo = ""
+	signed := idp.sign(t, idp.assertion(validAssertion()), "_assertion1")
+
+	tests := []struct {
+		name     string
+		response string
+	}{
+		{"unsigned", idp.response(idp.assertion(validAssertion()))},
+		{"tampered", idp.response(strings.Replace(signed, "alice@example.com", "mallory@example.com", 1))},
+		{"signed by another key", idp.response(other.sign(t, idp.assertion(validAssertion()), "_assertion1"))},
+		{"expired", idp.response(idp.sign(t, idp.assertion(expired), "_assertion1"))},
+		{"other audience", idp.response(idp.sign(t, idp.assertion(otherAudience), "_assertion1"))},
+		{"unsolicited", strings.Replace(idp.response(idp.sign(t, idp.assertion(unsolicited), "_assertion1")), ` InResponseTo="_req1"`, "", 1)},
+		{"wrapped assertion", idp.response(signed + strings.Replace(idp.assertion(validAssertion()), "_assertion1", "_assertion2", 1))},
+		{"other destination", strings.Replace(idp.response(signed), testACSURL, "https://other.example.com/acs", 1)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, _, err := a.ParseResponse(context.Background(), encode(tt.response))
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestSAMLAuthAuthnRequestURL(t *testing.T) {
+	a := newTestSAMLAuth(newTestIDP(t))
+
+	ssoURL, err := a.AuthnRequestURL("_req1", "")
+	require.NoError(t, err)
+	parsed, err := url.Parse(ssoURL)
+	require.NoError(t, err)
+	assert.Equal(t, "idp.example.com", parsed.Host)
+
+	compressed, err := base64.StdEncoding.DecodeString(parsed.Query().Get("SAMLRequest"))
+	require.NoError(t, err)
+	request, err := io.ReadAll(flate.NewReader(bytes.NewReader(compressed)))
+	require.NoError(t, err)
+	assert.Contains(t, string(request), `ID="_req1"`)
+	assert.Contains(t, string(request), `AssertionConsumerServiceURL="`+testACSURL+`"`)
+	assert.Contains(t, string(request), testSPEntityID)
+}
+
+func TestSAMLAuthMetadata(t *testing.T) {
+	a := newTestSAMLAuth(newTestIDP(t))
+
+	metadata, err := a.Metadata()
+	require.NoError(t, err)
+	assert.Contains(t, string(metadata), `entityID="`+testSPEntityID+`"`)
+	assert.Contains(t, string(metadata), `Location="`+testACSURL+`"`)
+}
@@ -0,0 +1,433 @@
+package auth
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/rsa"
+	_ "crypto/sha256" // digest and signature hashes
+	_ "crypto/sha512"
+	"crypto/subtle"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+)
+
+// XML namespaces and algorithm identifiers of XML signatures (https://www.w3.org/TR/xmldsig-core1/)
+const (
+	namespaceXMLDSig      = "http://www.w3.org/2000/09/xmldsig#"
+	namespaceXML          = "http://www.w3.org/XML/1998/namespace"
+	algorithmExcC14N      = "http://www.w3.org/2001/10/xml-exc-c14n#"
+	algorithmEnvelopedSig = "http://www.w3.org/2000/09/xmldsig#enveloped-signature"
+	algorithmSHA256       = "http://www.w3.org/2001/04/xmlenc#sha256"
+	algorithmSHA512       = "http://www.w3.org/2001/04/xmlenc#sha512"
+	algorithmRSASHA256    = "http://www.w3.org/2001/04/xmldsig-more#rsa-sha256"
+	algorithmRSASHA512    = "http://www.w3.org/2001/04/xmldsig-more#rsa-sha512"
+)
+
+// maxXMLElements bounds the elements of the XML documents parsed
+const maxXMLElements = 10000
+
+// xmlElement is an element of a parsed XML document. Prefixes are kept as written, so that the
+// element can be canonicalized, and resolved against the namespaces in scope.
+type xmlElement struct {
+	prefix string
+	local  string
+	attrs  []xml.Attr
+	// children holds *xmlElement and string (character data) nodes in document order
+	children []interface{}
+	// namespaces maps the prefixes in scope, "" for the default namespace, to their URIs
+	namespaces map[string]string
+}
+
+// parseXML parses an XML document into its root element. Documents with a DTD are rejected, as
+// entity declarations could alter what was signed.
+func parseXML(data []byte) (*xmlElement, error) {
+	decoder := xml.NewDecoder(bytes.NewReader(data))
+	var root *xmlElement
+	var stack []*xmlElement
+	elements := 0
+	for {
+		token, err := decoder.RawToken()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("invalid XML: %w", err)
+		}
+
+		switch token := token.(type) {
+		case xml.StartElement:
+			if elements++; elements > maxXMLElements {
+				return nil, fmt.Errorf("XML document has too many elements")
+			}
+			element := &xmlElement{prefix: token.Name.Space, local: token.Name.Local, attrs: token.Copy().Attr}
+			inherited := map[string]string{"xml": namespaceXML}
+			if len(stack) > 0 {
+				inherited = stack[len(stack)-1].namespaces
+			} else if root != nil {
+				return nil, fmt.Errorf("invalid XML: multiple root elements")
+			}
+			element.namespaces = make(map[string]string, len(inherited)+1)
+			for prefix, uri := range inherited {
+				element.namespaces[prefix] = uri
+			}
+			for _, attr := range element.attrs {
+				if attr.Name.Space == "" && attr.Name.Local == "xmlns" {
+					element.namespaces[""] = attr.Value
+				} else if attr.Name.Space == "xmlns" {
+					element.namespaces[attr.Name.Local] = attr.Value
+				}
+			}
+			if _, ok := element.namespaces[element.prefix]; !ok && element.prefix != "" {
+				return nil, fmt.Errorf("invalid XML: undeclared prefix %s", element.prefix)
+			}
+
+			if len(stack) > 0 {
+				parent := stack[len(stack)-1]
+				parent.children = append(parent.children, element)
+			} else {
+				root = element
+			}
+			stack = append(stack, element)
+		case xml.EndElement:
+			if len(stack) == 0 {
+				return nil, fmt.Errorf("invalid XML: unexpected end element")
+			}
+			stack = stack[:len(stack)-1]
+		case xml.CharData:
+			if len(stack) > 0 {
+				parent := stack[len(stack)-1]
+				parent.children = append(parent.children, string(token))
+			}
+		case xml.Directive:
+			return nil, fmt.Errorf("XML documents with a DTD are not accepted")
+		}
+	}
+	if root == nil || len(stack) > 0 {
+		return nil, fmt.Errorf("invalid XML: incomplete document")
+	}
+	return root, nil
+}
+
+// namespace returns the namespace URI of the element
+func (e *xmlElement) namespace() string {
+	return e.namespaces[e.prefix]
+}
+
+// is reports whether the element has the given namespace and local name
+func (e *xmlElement) is(namespace string, local string) bool {
+	return e.local == local && e.namespace() == namespace
+}
+
+// attr returns the value of an unprefixed attribute
+func (e *xmlElement) attr(name string) string {
+	for _, attr := range e.attrs {
+		if attr.Name.Space == "" && attr.Name.Local == name {
+			return attr.Value
+		}
+	}
+	return ""
+}
+
+// attrNamespace returns the namespace URI of an attribute; unprefixed attributes have none
+func (e *xmlElement) attrNamespace(attr xml.Attr) string {
+	if attr.Name.Space == "" {
+		return ""
+	}
+	return e.namespaces[attr.Name.Space]
+}
+
+// childElements returns the child elements with the given namespace and local name
+func (e *xmlElement) childElements(namespace string, local string) []*xmlElement {
+	var matches []*xmlElement
+	for _, child := range e.children {
+		if element, ok := child.(*xmlElement); ok && element.is(namespace, local) {
+			matches = append(matches, element)
+		}
+	}
+	return matches
+}
+
+// child returns the first child element with the given namespace and local name, nil if none
+func (e *xmlElement) child(namespace string, local string) *xmlElement {
+	if matches := e.childElements(namespace, local); len(matches) > 0 {
+		return matches[0]
+	}
+	return nil
+}
+
+// text returns the character data directly inside the element, trimmed of surrounding space
+func (e *xmlElement) text() string {
+	var b strings.Builder
+	for _, child := range e.children {
+		if s, ok := child.(string); ok {
+			b.WriteString(s)
+		}
+	}
+	return strings.TrimSpace(b.String())
+}
+
+// canonicalize writes the exclusive canonical form of the element (https://www.w3.org/TR/xml-exc-c14n/)
+// without comments, leaving out the excluded element. Namespaces in inclusive are rendered as in
+// inclusive canonicalization.
+func (e *xmlElement) canonicalize(w *bytes.Buffer, inclusive []string, excluded *xmlElement) {
+	e.canonicalizeIn(w, map[string]string{"": ""}, inclusive, excluded)
+}
+
+func (e *xmlElement) canonicalizeIn(w *bytes.Buffer, rendered map[string]string, inclusive []string, excluded *xmlElement) {
+	// Namespaces are rendered where they are visibly utilized, unless an output ancestor already did
+	utilized := map[string]bool{e.prefix: true}
+	var attrs []xml.Attr
+	for _, attr := range e.attrs {
+		if attr.Name.Space == "xmlns" || (attr.Name.Space == "" && attr.Name.Local == "xmlns") {
+			continue
+		}
+		if attr.Name.Space != "" {
+			utilized[attr.Name.Space] = true
+		}
+		attrs = append(attrs, attr)
+	}
+	for _, prefix := range inclusive {
+		if _, ok := e.namespaces[prefix]; ok {
+			utilized[prefix] = true
+		}
+	}
+
+	var declared []string
+	scope := make(map[string]string, len(rendered))
+	for prefix, uri := range rendered {
+		scope[prefix] = uri
+	}
+	for prefix := range utilized {
+		if prefix == "xml" {
+			continue
+		}
+		uri := e.namespaces[prefix]
+		if current, ok := rendered[prefix]; ok && current == uri {
+			continue
+		}
+		if prefix != "" && uri == "" {
+			continue
+		}
+		declared = append(declared, prefix)
+		scope[prefix] = uri
+	}
+	sort.Strings(declared)
+
+	// Attributes are sorted by namespace URI, unprefixed ones having none, then local name
+	sort.Slice(attrs, func(i, j int) bool {
+		ni, nj := e.attrNamespace(attrs[i]), e.attrNamespace(attrs[j])
+		if ni != nj {
+			return ni < nj
+		}
+		return attrs[i].Name.Local < attrs[j].Name.Local
+	})
+
+	name := qualifiedName(e.prefix, e.local)
+	w.WriteString("<" + name)
+	for _, prefix := range declared {
+		if prefix == "" {
+			w.WriteString(` xmlns="`)
+		} else {
+			w.WriteString(` xmlns:` + prefix + `="`)
+		}
+		writeEscapedAttr(w, scope[prefix])
+		w.WriteString(`"`)
+	}
+	for _, attr := range attrs {
+		w.WriteString(" " + qualifiedName(attr.Name.Space, attr.Name.Local) + `="`)
+		writeEscapedAttr(w, attr.Value)
+		w.WriteString(`"`)
+	}
+	w.WriteString(">")
+
+	for _, child := range e.children {
+		switch child := child.(type) {
+		case string:
+			writeEscapedText(w, child)
+		case *xmlElement:
+			if child != excluded {
+				child.canonicalizeIn(w, scope, inclusive, excluded)
+			}
+		}
+	}
+	w.WriteString("</" + name + ">")
+}
+
+func qualifiedName(prefix string, local string) string {
+	if prefix == "" {
+		return local
+	}
+	return prefix + ":" + local
+}
+
+func writeEscapedText(w *bytes.Buffer, s string) {
+	for _, r := range s {
+		switch r {
+		case '&':
+			w.WriteString("&amp;")
+		case '<':
+			w.WriteString("&lt;")
+		case '>':
+			w.WriteString("&gt;")
+		case '\r':
+			w.WriteString("&#xD;")
+		default:
+			w.WriteRune(r)
+		}
+	}
+}
+
+func writeEscapedAttr(w *bytes.Buffer, s string) {
+	for _, r := range s {
+		switch r {
+		case '&':
+			w.WriteString("&amp;")
+		case '<':
+			w.WriteString("&lt;")
+		case '"':
+			w.WriteString("&quot;")
+		case '\t':
+			w.WriteString("&#x9;")
+		case '\n':
+			w.WriteString("&#xA;")
+		case '\r':
+			w.WriteString("&#xD;")
+		default:
+			w.WriteRune(r)
+		}
+	}
+}
+
+// verifyEnvelopedSignature verifies the enveloped signature among the element's children with
+// the certificate's key. It returns false when the element is not signed. Only references to the
+// element itself are accepted, so that the signed content is the content the caller reads.
+func verifyEnvelopedSignature(element *xmlElement, certificate *x509.Certificate) (bool, error) {
+	signatures := element.childElements(namespaceXMLDSig, "Signature")
+	if len(signatures) == 0 {
+		return false, nil
+	}
+	if len(signatures) > 1 {
+		return false, fmt.Errorf("element has several signatures")
+	}
+	signature := signatures[0]
+
+	signedInfo := signature.child(namespaceXMLDSig, "SignedInfo")
+	if signedInfo == nil {
+		return false, fmt.Errorf("signature has no SignedInfo")
+	}
+	c14n := signedInfo.child(namespaceXMLDSig, "CanonicalizationMethod")
+	if c14n == nil || c14n.attr("Algorithm") != algorithmExcC14N {
+		return false, fmt.Errorf("unsupported canonicalization method")
+	}
+
+	references := signedInfo.childElements(namespaceXMLDSig, "Reference")
+	if len(references) != 1 {
+		return false, fmt.Errorf("signature must have exactly one reference")
+	}
+	reference := references[0]
+	id := element.attr("ID")
+	if id == "" || reference.attr("URI") != "#"+id {
+		return false, fmt.Errorf("signature does not reference the signed element")
+	}
+
+	// The referenced element, less the signature, is digested in its canonical form
+	var inclusive []string
+	if transforms := reference.child(namespaceXMLDSig, "Transforms"); transforms != nil {
+		for _, transform := range transforms.childElements(namespaceXMLDSig, "Transform") {
+			switch transform.attr("Algorithm") {
+			case algorithmEnvelopedSig:
+			case algorithmExcC14N:
+				inclusive = inclusivePrefixes(transform)
+			default:
+				return false, fmt.Errorf("unsupported transform %s", transform.attr("Algorithm"))
+			}
+		}
+	}
+	digestMethod := reference.child(namespaceXMLDSig, "DigestMethod")
+	digestValue := reference.child(namespaceXMLDSig, "DigestValue")
+	if digestMethod == nil || digestValue == nil {
+		return false, fmt.Errorf("reference has no digest")
+	}
+	digestHash, err := digestAlgorithm(digestMethod.attr("Algorithm"))
+	if err != nil {
+		return false, err
+	}
+	var content bytes.Buffer
+	element.canonicalize(&content, inclusive, signature)
+	h := digestHash.New()
+	h.Write(content.Bytes())
+	expected, err := base64.StdEncoding.DecodeString(strings.Join(strings.Fields(digestValue.text()), ""))
+	if err != nil || subtle.ConstantTimeCompare(h.Sum(nil), expected) != 1 {
+		return false, fmt.Errorf("digest of the signed element does not match")
+	}
+
+	// The signature covers the canonical SignedInfo, which carries the digest
+	signatureMethod := signedInfo.child(namespaceXMLDSig, "SignatureMethod")
+	signatureValue := signature.child(namespaceXMLDSig, "SignatureValue")
+	if signatureMethod == nil || signatureValue == nil {
+		return false, fmt.Errorf("signature has no signature value")
+	}
+	signatureHash, err := signatureAlgorithm(signatureMethod.attr("Algorithm"))
+	if err != nil {
+		return false, err
+	}
+	var signed bytes.Buffer
+	signedInfo.canonicalize(&signed, inclusivePrefixes(c14n), nil)
+	h = signatureHash.New()
+	h.Write(signed.Bytes())
+	value, err := base64.StdEncoding.DecodeString(strings.Join(strings.Fields(signatureValue.text()), ""))
+	if err != nil {
+		return false, fmt.Errorf("invalid signature value")
+	}
+	public, ok := certificate.PublicKey.(*rsa.PublicKey)
+	if !ok {
+		return false, fmt.Errorf("unsupported certificate key type")
+	}
+	if err := rsa.VerifyPKCS1v15(public, signatureHash, h.Sum(nil), value); err != nil {
+		return false, fmt.Errorf("invalid signature: %w", err)
+	}
+	return true, nil
+}
+
+// inclusivePrefixes returns the PrefixList of the InclusiveNamespaces parameter of an exclusive
+// canonicalization, "#default" standing for the default namespace
+func inclusivePrefixes(method *xmlElement) []string {
+	parameter := method.child(algorithmExcC14N, "InclusiveNamespaces")
+	if parameter == nil {
+		return nil
+	}
+	prefixes := strings.Fields(parameter.attr("PrefixList"))
+	for i, prefix := range prefixes {
+		if prefix == "#default" {
+			prefixes[i] = ""
+		}
+	}
+	return prefixes
+}
+
+func digestAlgorithm(algorithm string) (crypto.Hash, error) {
+	switch algorithm {
+	case algorithmSHA256:
+		return crypto.SHA256, nil
+	case algorithmSHA512:
+		return crypto.SHA512, nil
+	default:
+		return 0, fmt.Errorf("unsupported digest method %s", algorithm)
+	}
+}
+
+func signatureAlgorithm(algorithm string) (crypto.Hash, error) {
+	switch algorithm {
+	case algorithmRSASHA256:
+		return crypto.SHA256, nil
+	case algorithmRSASHA512:
+		return crypto.SHA512, nil
+	default:
+		return 0, fmt.Errorf("unsupported signature method %s", algorithm)
+	}
+}
@@ -0,0 +1,42 @@
+package auth
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCanonicalize(t *testing.T) {
+	root, err := parseXML([]byte(`<?xml version="1.0"?>
+<root xmlns="urn:a" xmlns:b="urn:b" z="1" b:y="2" a="3"><b:child>x &amp; &lt;y&gt;</b:child><c xmlns=""/></root>`))
+	require.NoError(t, err)
+
+	var out bytes.Buffer
+	root.canonicalize(&out, nil, nil)
+	assert.Equal(t, `<root xmlns="urn:a" xmlns:b="urn:b" a="3" z="1" b:y="2"><b:child>x &amp; &lt;y&gt;</b:child><c xmlns=""></c></root>`, out.String())
+}
+
+func TestCanonicalizeSubtree(t *testing.T) {
+	// Example of the Exclusive XML Canonicalization recommendation, section 2.2: namespaces of
+	// ancestors are only rendered where they are used
+	root, err := parseXML([]byte(`<n0:local xmlns:n0="foo:bar" xmlns:n3="ftp://example.org"><n1:elem2 xmlns:n1="http://example.net" xml:lang="en"><n3:stuff xmlns:n3="ftp://example.org"/></n1:elem2></n0:local>`))
+	require.NoError(t, err)
+	elem2 := root.child("http://example.net", "elem2")
+	require.NotNil(t, elem2)
+
+	var out bytes.Buffer
+	elem2.canonicalize(&out, nil, nil)
+	assert.Equal(t, `<n1:elem2 xmlns:n1="http://example.net" xml:lang="en"><n3:stuff xmlns:n3="ftp://example.org"></n3:stuff></n1:elem2>`, out.String())
+
+	// Inclusive prefixes are rendered even where they are not used
+	out.Reset()
+	elem2.canonicalize(&out, []string{"n0"}, nil)
+	assert.Equal(t, `<n1:elem2 xmlns:n0="foo:bar" xmlns:n1="http://example.net" xml:lang="en"><n3:stuff xmlns:n3="ftp://example.org"></n3:stuff></n1:elem2>`, out.String())
+}
+
+func TestParseXMLRejectsDTD(t *testing.T) {
+	_, err := parseXML([]byte(`<!DOCTYPE root [<!ENTITY e "forged">]><root>&e;</root>`))
+	assert.Error(t, err)
+}
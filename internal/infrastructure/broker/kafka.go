@@ -0,0 +1,84 @@
+package broker
+
+import (
+	"context"
+	"time"
+
+	"github.com/segmentio/kafka-go"
+
+	"api-gateway-sample/internal/domain/entity"
+	"api-gateway-sample/pkg/config"
+	"api-gateway-sample/pkg/logger"
+)
+
+// kafkaBatchTimeout is how long a writer waits to fill a batch. The default of one second
+// would delay every acknowledged publish by as much.
+const kafkaBatchTimeout = 5 * time.Millisecond
+
+// KafkaPublisher publishes messages to Kafka topics. Messages with a key are partitioned
+// by its hash, so messages with the same key keep their order.
+type KafkaPublisher struct {
+	// acked waits for all in-sync replicas; fireAndForget writes asynchronously without acknowledgement
+	acked         *kafka.Writer
+	fireAndForget *kafka.Writer
+	timeout       time.Duration
+}
+
+// NewKafkaPublisher creates a new KafkaPublisher instance. Connections are opened on the first publish.
+func NewKafkaPublisher(cfg config.KafkaConfig, timeout time.Duration, logger logger.Logger) *KafkaPublisher {
+	return &KafkaPublisher{
+		acked: &kafka.Writer{
+			Addr:         kafka.TCP(cfg.Brokers...),
+			Balancer:     &kafka.Hash{},
+			RequiredAcks: kafka.RequireAll,
+			BatchTimeout: kafkaBatchTimeout,
+			WriteTimeout: timeout,
+		},
+		fireAndForget: &kafka.Writer{
+			Addr:         kafka.TCP(cfg.Brokers...),
+			Balancer:     &kafka.Hash{},
+			RequiredAcks: kafka.RequireNone,
+			BatchTimeout: kafkaBatchTimeout,
+			WriteTimeout: timeout,
+			Async:        true,
+			Completion: func(messages []kafka.Message, err error) {
+				if err != nil {
+					logger.Warn("Failed to publish messages to Kafka", "count", len(messages), "error", err)
+				}
+			},
+		},
+		timeout: timeout,
+	}
+}
+
+// Publish writes a message to its topic
+func (p *KafkaPublisher) Publish(ctx context.Context, message *entity.Message) error {
+	ctx, cancel := context.WithTimeout(ctx, p.timeout)
+	defer cancel()
+
+	record := kafka.Message{
+		Topic: message.Topic,
+		Value: message.Body,
+	}
+	if message.Key != "" {
+		record.Key = []byte(message.Key)
+	}
+	for name, value := range message.Headers {
+		record.Headers = append(record.Headers, kafka.Header{Key: name, Value: []byte(value)})
+	}
+
+	writer := p.acked
+	if message.Delivery == entity.DeliveryAtMostOnce {
+		writer = p.fireAndForget
+	}
+	return writer.WriteMessages(ctx, record)
+}
+
+// Close flushes pending messages and closes the connections
+func (p *KafkaPublisher) Close() error {
+	err := p.fireAndForget.Close()
+	if ackedErr := p.acked.Close(); err == nil {
+		err = ackedErr
+	}
+	return err
+}
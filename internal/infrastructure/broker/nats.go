@@ -0,0 +1,75 @@
+package broker
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/nats-io/nats.go"
+
+	"api-gateway-sample/internal/domain/entity"
+	"api-gateway-sample/pkg/config"
+	"api-gateway-sample/pkg/logger"
+)
+
+// NATSPublisher publishes messages to NATS subjects. At-least-once messages are published
+// through JetStream and acknowledged by the stream that captures the subject; the message ID
+// lets the stream discard duplicates of retried requests.
+type NATSPublisher struct {
+	conn      *nats.Conn
+	jetStream nats.JetStreamContext
+	timeout   time.Duration
+}
+
+// NewNATSPublisher creates a new NATSPublisher instance. The client keeps reconnecting while
+// the server is unavailable, including at startup.
+func NewNATSPublisher(cfg config.NATSConfig, timeout time.Duration, logger logger.Logger) (*NATSPublisher, error) {
+	conn, err := nats.Connect(cfg.URL,
+		nats.Name("api-gateway"),
+		nats.RetryOnFailedConnect(true),
+		nats.MaxReconnects(-1),
+		nats.Timeout(timeout),
+		nats.DisconnectErrHandler(func(_ *nats.Conn, err error) {
+			if err != nil {
+				logger.Warn("Disconnected from NATS", "error", err)
+			}
+		}),
+		nats.ReconnectHandler(func(conn *nats.Conn) {
+			logger.Info("Reconnected to NATS", "server", conn.ConnectedUrl())
+		}),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to NATS: %w", err)
+	}
+
+	jetStream, err := conn.JetStream()
+	if err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("failed to create JetStream context: %w", err)
+	}
+
+	return &NATSPublisher{conn: conn, jetStream: jetStream, timeout: timeout}, nil
+}
+
+// Publish sends a message to its subject
+func (p *NATSPublisher) Publish(ctx context.Context, message *entity.Message) error {
+	msg := nats.NewMsg(message.Topic)
+	msg.Data = message.Body
+	for name, value := range message.Headers {
+		msg.Header.Set(name, value)
+	}
+
+	if message.Delivery == entity.DeliveryAtMostOnce {
+		return p.conn.PublishMsg(msg)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, p.timeout)
+	defer cancel()
+	_, err := p.jetStream.PublishMsg(msg, nats.Context(ctx), nats.MsgId(message.ID))
+	return err
+}
+
+// Close flushes pending messages and closes the connection
+func (p *NATSPublisher) Close() error {
+	return p.conn.Drain()
+}
@@ -0,0 +1,129 @@
+package broker
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	amqp "github.com/rabbitmq/amqp091-go"
+
+	"api-gateway-sample/internal/domain/entity"
+	"api-gateway-sample/pkg/config"
+	"api-gateway-sample/pkg/logger"
+)
+
+// RabbitMQPublisher publishes messages to a RabbitMQ exchange with the topic as routing key.
+// At-least-once messages are persistent and confirmed by the broker.
+type RabbitMQPublisher struct {
+	url      string
+	exchange string
+	timeout  time.Duration
+	logger   logger.Logger
+
+	mu   sync.Mutex
+	conn *amqp.Connection
+	// confirmed is in confirm mode for at-least-once delivery; plain publishes without confirms
+	confirmed *amqp.Channel
+	plain     *amqp.Channel
+}
+
+// NewRabbitMQPublisher creates a new RabbitMQPublisher instance. The connection is opened on
+// the first publish and reopened after it is lost.
+func NewRabbitMQPublisher(cfg config.RabbitMQConfig, timeout time.Duration, logger logger.Logger) *RabbitMQPublisher {
+	return &RabbitMQPublisher{
+		url:      cfg.URL,
+		exchange: cfg.Exchange,
+		timeout:  timeout,
+		logger:   logger,
+	}
+}
+
+// Publish sends a message to the exchange
+func (p *RabbitMQPublisher) Publish(ctx context.Context, message *entity.Message) error {
+	ctx, cancel := context.WithTimeout(ctx, p.timeout)
+	defer cancel()
+
+	publishing := amqp.Publishing{
+		MessageId:   message.ID,
+		ContentType: message.Headers["Content-Type"],
+		Timestamp:   time.Now(),
+		Body:        message.Body,
+		Headers:     amqp.Table{},
+	}
+	for name, value := range message.Headers {
+		publishing.Headers[name] = value
+	}
+
+	if message.Delivery == entity.DeliveryAtMostOnce {
+		p.mu.Lock()
+		defer p.mu.Unlock()
+		if err := p.connect(); err != nil {
+			return err
+		}
+		return p.plain.PublishWithContext(ctx, p.exchange, message.Topic, false, false, publishing)
+	}
+
+	publishing.DeliveryMode = amqp.Persistent
+	p.mu.Lock()
+	if err := p.connect(); err != nil {
+		p.mu.Unlock()
+		return err
+	}
+	confirmation, err := p.confirmed.PublishWithDeferredConfirmWithContext(ctx, p.exchange, message.Topic, false, false, publishing)
+	p.mu.Unlock()
+	if err != nil {
+		return err
+	}
+
+	acked, err := confirmation.WaitContext(ctx)
+	if err != nil {
+		return err
+	}
+	if !acked {
+		return fmt.Errorf("message %s was rejected by the broker", message.ID)
+	}
+	return nil
+}
+
+// connect opens the connection and channels unless they are open. The caller must hold mu.
+func (p *RabbitMQPublisher) connect() error {
+	if p.conn != nil && !p.conn.IsClosed() && !p.confirmed.IsClosed() && !p.plain.IsClosed() {
+		return nil
+	}
+	if p.conn != nil {
+		p.conn.Close()
+	}
+
+	conn, err := amqp.DialConfig(p.url, amqp.Config{Dial: amqp.DefaultDial(p.timeout)})
+	if err != nil {
+		return fmt.Errorf("failed to connect to RabbitMQ: %w", err)
+	}
+	confirmed, err := conn.Channel()
+	if err == nil {
+		err = confirmed.Confirm(false)
+	}
+	if err != nil {
+		conn.Close()
+		return fmt.Errorf("failed to open confirmed RabbitMQ channel: %w", err)
+	}
+	plain, err := conn.Channel()
+	if err != nil {
+		conn.Close()
+		return fmt.Errorf("failed to open RabbitMQ channel: %w", err)
+	}
+
+	p.conn, p.confirmed, p.plain = conn, confirmed, plain
+	p.logger.Info("Connected to RabbitMQ", "exchange", p.exchange)
+	return nil
+}
+
+// Close closes the connection
+func (p *RabbitMQPublisher) Close() error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.conn == nil || p.conn.IsClosed() {
+		return nil
+	}
+	return p.conn.Close()
+}
@@ -0,0 +1,38 @@
+package cache
+
+import (
+	"fmt"
+
+	"api-gateway-sample/internal/domain/repository"
+	"api-gateway-sample/pkg/config"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// Cache backends
+const (
+	BackendRedis     = "redis"
+	BackendMemcached = "memcached"
+	BackendMemory    = "memory"
+)
+
+// NewCacheRepository creates the cache repository for the configured backend.
+// redisClient is only used by the Redis backend and may be nil otherwise.
+func NewCacheRepository(cfg config.CacheConfig, redisClient redis.UniversalClient) (repository.CacheRepository, error) {
+	switch cfg.Backend {
+	case "", BackendRedis:
+		if redisClient == nil {
+			return nil, fmt.Errorf("redis cache backend requires a redis client")
+		}
+		return NewRedisCache(redisClient), nil
+	case BackendMemcached:
+		if len(cfg.Memcached.Addresses) == 0 {
+			return nil, fmt.Errorf("memcached cache backend requires at least one address")
+		}
+		return NewMemcachedCache(cfg.Memcached.Addresses, cfg.Memcached.Timeout), nil
+	case BackendMemory:
+		return NewMemoryCache(cfg.Memory.MaxEntries, cfg.Memory.CleanupInterval), nil
+	default:
+		return nil, fmt.Errorf("unknown cache backend %q", cfg.Backend)
+	}
+}
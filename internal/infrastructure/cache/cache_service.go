@@ -44,3 +44,8 @@ func (s *CacheServiceAdapter) Delete(ctx context.Context, key string) error {
 func (s *CacheServiceAdapter) Clear(ctx context.Context) error {
 	return s.cache.Clear(ctx, "*")
 }
+
+// ClearMatching removes the values whose keys match a glob pattern
+func (s *CacheServiceAdapter) ClearMatching(ctx context.Context, pattern string) error {
+	return s.cache.Clear(ctx, pattern)
+}
@@ -0,0 +1,104 @@
+package cache
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"api-gateway-sample/internal/domain/repository"
+	"api-gateway-sample/pkg/errors"
+)
+
+// FallbackCache wraps a Redis backed cache repository and bypasses it while Redis is
+// unhealthy: reads miss and writes are dropped, so requests go to the upstream instead
+// of waiting for Redis timeouts.
+type FallbackCache struct {
+	repository.CacheRepository
+	health *RedisHealthMonitor
+}
+
+// NewFallbackCache creates a new FallbackCache instance
+func NewFallbackCache(cache repository.CacheRepository, health *RedisHealthMonitor) repository.CacheRepository {
+	return &FallbackCache{
+		CacheRepository: cache,
+		health:          health,
+	}
+}
+
+// Set stores a value in the cache unless the cache is bypassed
+func (c *FallbackCache) Set(ctx context.Context, key string, value interface{}, ttl time.Duration) error {
+	if c.bypass() {
+		return nil
+	}
+	return c.CacheRepository.Set(ctx, key, value, ttl)
+}
+
+// Get retrieves a value from the cache, missing while the cache is bypassed
+func (c *FallbackCache) Get(ctx context.Context, key string, value interface{}) error {
+	if c.bypass() {
+		return errors.ErrNotFound
+	}
+	return c.CacheRepository.Get(ctx, key, value)
+}
+
+// Delete removes a value from the cache unless the cache is bypassed
+func (c *FallbackCache) Delete(ctx context.Context, key string) error {
+	if c.bypass() {
+		return nil
+	}
+	return c.CacheRepository.Delete(ctx, key)
+}
+
+// SetNX sets a value only if the key does not exist. It fails while the cache is
+// bypassed because the existence of the key cannot be checked.
+func (c *FallbackCache) SetNX(ctx context.Context, key string, value interface{}, ttl time.Duration) (bool, error) {
+	if c.bypass() {
+		return false, errors.ErrServiceUnavailable
+	}
+	return c.CacheRepository.SetNX(ctx, key, value, ttl)
+}
+
+// GetWithTTL retrieves a value and its TTL, missing while the cache is bypassed
+func (c *FallbackCache) GetWithTTL(ctx context.Context, key string, value interface{}) (time.Duration, error) {
+	if c.bypass() {
+		return 0, errors.ErrNotFound
+	}
+	return c.CacheRepository.GetWithTTL(ctx, key, value)
+}
+
+// UpdateTTL updates the TTL of an existing key, missing while the cache is bypassed
+func (c *FallbackCache) UpdateTTL(ctx context.Context, key string, ttl time.Duration) error {
+	if c.bypass() {
+		return errors.ErrNotFound
+	}
+	return c.CacheRepository.UpdateTTL(ctx, key, ttl)
+}
+
+// Clear removes all keys matching the pattern. It fails while the cache is bypassed
+// so callers know stale entries may remain once Redis recovers.
+func (c *FallbackCache) Clear(ctx context.Context, pattern string) error {
+	if c.bypass() {
+		return errors.ErrServiceUnavailable
+	}
+	return c.CacheRepository.Clear(ctx, pattern)
+}
+
+// Size reports the size of the wrapped cache, which is unknown while the cache is bypassed
+func (c *FallbackCache) Size(ctx context.Context) (int64, int64, error) {
+	sizer, ok := c.CacheRepository.(repository.CacheSizer)
+	if !ok {
+		return 0, 0, fmt.Errorf("cache backend cannot report its size")
+	}
+	if c.bypass() {
+		return 0, 0, errors.ErrServiceUnavailable
+	}
+	return sizer.Size(ctx)
+}
+
+func (c *FallbackCache) bypass() bool {
+	if c.health.Healthy() {
+		return false
+	}
+	c.health.RecordFallback()
+	return true
+}
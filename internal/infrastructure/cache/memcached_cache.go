@@ -0,0 +1,232 @@
+package cache
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	stderrors "errors"
+	"fmt"
+	"time"
+
+	"github.com/bradfitz/gomemcache/memcache"
+
+	"api-gateway-sample/internal/domain/repository"
+	"api-gateway-sample/pkg/errors"
+)
+
+// memcachedMaxRelativeTTL is the longest expiration memcached accepts as a relative
+// number of seconds; longer expirations must be sent as a Unix timestamp
+const memcachedMaxRelativeTTL = 30 * 24 * time.Hour
+
+// memcachedMaxKeyLength is the longest key memcached accepts
+const memcachedMaxKeyLength = 250
+
+// MemcachedCache implements the repository.CacheRepository interface on Memcached.
+// Memcached cannot report the remaining TTL of an item, so values are wrapped in an
+// envelope that records their expiry. It cannot enumerate keys either, so Clear only
+// supports the "*" pattern.
+type MemcachedCache struct {
+	client *memcache.Client
+}
+
+// memcachedItem is the envelope stored for every value
+type memcachedItem struct {
+	// ExpiresAt is the expiry as Unix milliseconds, or 0 for items without a TTL
+	ExpiresAt int64           `json:"e,omitempty"`
+	Data      json.RawMessage `json:"d"`
+}
+
+// NewMemcachedCache creates a new MemcachedCache instance spreading keys over the given servers
+func NewMemcachedCache(addresses []string, timeout time.Duration) repository.CacheRepository {
+	client := memcache.New(addresses...)
+	if timeout > 0 {
+		client.Timeout = timeout
+	}
+	return &MemcachedCache{
+		client: client,
+	}
+}
+
+// Set stores a value in the cache with the specified TTL
+func (c *MemcachedCache) Set(ctx context.Context, key string, value interface{}, ttl time.Duration) error {
+	item, err := newMemcacheItem(key, value, ttl)
+	if err != nil {
+		return err
+	}
+
+	if err := c.client.Set(item); err != nil {
+		return fmt.Errorf("failed to set cache value: %w", err)
+	}
+
+	return nil
+}
+
+// Get retrieves a value from the cache
+func (c *MemcachedCache) Get(ctx context.Context, key string, value interface{}) error {
+	_, err := c.GetWithTTL(ctx, key, value)
+	return err
+}
+
+// Delete removes a value from the cache
+func (c *MemcachedCache) Delete(ctx context.Context, key string) error {
+	if err := c.client.Delete(memcachedKey(key)); err != nil && !stderrors.Is(err, memcache.ErrCacheMiss) {
+		return fmt.Errorf("failed to delete cache value: %w", err)
+	}
+
+	return nil
+}
+
+// SetNX sets a value in the cache only if the key does not exist
+func (c *MemcachedCache) SetNX(ctx context.Context, key string, value interface{}, ttl time.Duration) (bool, error) {
+	item, err := newMemcacheItem(key, value, ttl)
+	if err != nil {
+		return false, err
+	}
+
+	if err := c.client.Add(item); err != nil {
+		if stderrors.Is(err, memcache.ErrNotStored) {
+			return false, nil
+		}
+		return false, fmt.Errorf("failed to set cache value: %w", err)
+	}
+
+	return true, nil
+}
+
+// GetWithTTL retrieves a value and its remaining TTL from the cache. Items without
+// a TTL report -1, like Redis.
+func (c *MemcachedCache) GetWithTTL(ctx context.Context, key string, value interface{}) (time.Duration, error) {
+	stored, err := c.get(key)
+	if err != nil {
+		return 0, err
+	}
+
+	if err := json.Unmarshal(stored.Data, value); err != nil {
+		return 0, fmt.Errorf("failed to unmarshal cache value: %w", err)
+	}
+
+	if stored.ExpiresAt == 0 {
+		return -1, nil
+	}
+	return time.Until(time.UnixMilli(stored.ExpiresAt)), nil
+}
+
+// UpdateTTL updates the TTL of an existing key
+func (c *MemcachedCache) UpdateTTL(ctx context.Context, key string, ttl time.Duration) error {
+	stored, err := c.get(key)
+	if err != nil {
+		return err
+	}
+
+	// Rewrite the envelope so GetWithTTL reports the new expiry
+	item, err := newMemcacheItem(key, stored.Data, ttl)
+	if err != nil {
+		return err
+	}
+	if err := c.client.Replace(item); err != nil {
+		if stderrors.Is(err, memcache.ErrNotStored) {
+			return errors.ErrNotFound
+		}
+		return fmt.Errorf("failed to update cache TTL: %w", err)
+	}
+
+	return nil
+}
+
+// Clear removes all keys matching the pattern. Memcached cannot list its keys,
+// so only "*" is supported and flushes every server.
+func (c *MemcachedCache) Clear(ctx context.Context, pattern string) error {
+	if pattern != "*" {
+		return fmt.Errorf("memcached cache only supports clearing all keys, got pattern %q", pattern)
+	}
+
+	if err := c.client.FlushAll(); err != nil {
+		return fmt.Errorf("failed to clear cache: %w", err)
+	}
+
+	return nil
+}
+
+// Ping checks the connection to every Memcached server
+func (c *MemcachedCache) Ping(ctx context.Context) error {
+	return c.client.Ping()
+}
+
+// Close closes the idle connections to the Memcached servers
+func (c *MemcachedCache) Close() error {
+	return c.client.Close()
+}
+
+func (c *MemcachedCache) get(key string) (*memcachedItem, error) {
+	item, err := c.client.Get(memcachedKey(key))
+	if err != nil {
+		if stderrors.Is(err, memcache.ErrCacheMiss) {
+			return nil, errors.ErrNotFound
+		}
+		return nil, fmt.Errorf("failed to get cache value: %w", err)
+	}
+
+	var stored memcachedItem
+	if err := json.Unmarshal(item.Value, &stored); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal cache value: %w", err)
+	}
+	return &stored, nil
+}
+
+func newMemcacheItem(key string, value interface{}, ttl time.Duration) (*memcache.Item, error) {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal cache value: %w", err)
+	}
+
+	stored := memcachedItem{Data: data}
+	var expiration int32
+	if ttl > 0 {
+		expiresAt := time.Now().Add(ttl)
+		stored.ExpiresAt = expiresAt.UnixMilli()
+		expiration = memcachedExpiration(ttl, expiresAt)
+	}
+
+	envelope, err := json.Marshal(stored)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal cache value: %w", err)
+	}
+
+	return &memcache.Item{
+		Key:        memcachedKey(key),
+		Value:      envelope,
+		Expiration: expiration,
+	}, nil
+}
+
+// memcachedExpiration converts a TTL to memcached's expiration format: whole
+// seconds, rounded up, or an absolute Unix timestamp beyond 30 days
+func memcachedExpiration(ttl time.Duration, expiresAt time.Time) int32 {
+	if ttl > memcachedMaxRelativeTTL {
+		return int32(expiresAt.Unix())
+	}
+	seconds := int32((ttl + time.Second - 1) / time.Second)
+	if seconds < 1 {
+		seconds = 1
+	}
+	return seconds
+}
+
+// memcachedKey hashes keys memcached would reject because of their length or characters
+func memcachedKey(key string) string {
+	if len(key) <= memcachedMaxKeyLength && validMemcachedKey(key) {
+		return key
+	}
+	sum := sha256.Sum256([]byte(key))
+	return "sha256:" + hex.EncodeToString(sum[:])
+}
+
+func validMemcachedKey(key string) bool {
+	for i := 0; i < len(key); i++ {
+		if key[i] <= ' ' || key[i] == 0x7f {
+			return false
+		}
+	}
+	return key != ""
+}
@@ -0,0 +1,273 @@
+package cache
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"api-gateway-sample/internal/domain/repository"
+	"api-gateway-sample/pkg/errors"
+)
+
+// MemoryCache implements the repository.CacheRepository interface in process memory.
+// Values are stored JSON encoded, like in Redis, so all backends behave the same. When
+// the cache is full the entry closest to expiry is evicted.
+type MemoryCache struct {
+	mu         sync.Mutex
+	entries    map[string]memoryEntry
+	maxEntries int
+	now        func() time.Time
+	stop       chan struct{}
+	closeOnce  sync.Once
+}
+
+type memoryEntry struct {
+	data []byte
+	// expiresAt is zero for entries without a TTL
+	expiresAt time.Time
+}
+
+// NewMemoryCache creates a new MemoryCache instance holding at most maxEntries entries
+// (0 means unbounded) and purging expired entries every cleanupInterval
+func NewMemoryCache(maxEntries int, cleanupInterval time.Duration) repository.CacheRepository {
+	c := &MemoryCache{
+		entries:    make(map[string]memoryEntry),
+		maxEntries: maxEntries,
+		now:        time.Now,
+		stop:       make(chan struct{}),
+	}
+	if cleanupInterval > 0 {
+		go c.cleanup(cleanupInterval)
+	}
+	return c
+}
+
+// Set stores a value in the cache with the specified TTL
+func (c *MemoryCache) Set(ctx context.Context, key string, value interface{}, ttl time.Duration) error {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Errorf("failed to marshal cache value: %w", err)
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.setLocked(key, data, ttl)
+	return nil
+}
+
+// Get retrieves a value from the cache
+func (c *MemoryCache) Get(ctx context.Context, key string, value interface{}) error {
+	_, err := c.GetWithTTL(ctx, key, value)
+	return err
+}
+
+// Delete removes a value from the cache
+func (c *MemoryCache) Delete(ctx context.Context, key string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	delete(c.entries, key)
+	return nil
+}
+
+// SetNX sets a value in the cache only if the key does not exist
+func (c *MemoryCache) SetNX(ctx context.Context, key string, value interface{}, ttl time.Duration) (bool, error) {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return false, fmt.Errorf("failed to marshal cache value: %w", err)
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if _, ok := c.liveLocked(key); ok {
+		return false, nil
+	}
+	c.setLocked(key, data, ttl)
+	return true, nil
+}
+
+// GetWithTTL retrieves a value and its remaining TTL from the cache. Entries without
+// a TTL report -1, like Redis.
+func (c *MemoryCache) GetWithTTL(ctx context.Context, key string, value interface{}) (time.Duration, error) {
+	c.mu.Lock()
+	entry, ok := c.liveLocked(key)
+	c.mu.Unlock()
+	if !ok {
+		return 0, errors.ErrNotFound
+	}
+
+	if err := json.Unmarshal(entry.data, value); err != nil {
+		return 0, fmt.Errorf("failed to unmarshal cache value: %w", err)
+	}
+
+	if entry.expiresAt.IsZero() {
+		return -1, nil
+	}
+	return entry.expiresAt.Sub(c.now()), nil
+}
+
+// UpdateTTL updates the TTL of an existing key
+func (c *MemoryCache) UpdateTTL(ctx context.Context, key string, ttl time.Duration) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entry, ok := c.liveLocked(key)
+	if !ok {
+		return errors.ErrNotFound
+	}
+	c.setLocked(key, entry.data, ttl)
+	return nil
+}
+
+// Clear removes all keys matching the pattern
+func (c *MemoryCache) Clear(ctx context.Context, pattern string) error {
+	matcher, err := globToRegexp(pattern)
+	if err != nil {
+		return fmt.Errorf("invalid pattern %q: %w", pattern, err)
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for key := range c.entries {
+		if matcher.MatchString(key) {
+			delete(c.entries, key)
+		}
+	}
+	return nil
+}
+
+// Ping always succeeds for the in-memory cache
+func (c *MemoryCache) Ping(ctx context.Context) error {
+	return nil
+}
+
+// Close stops the background cleanup
+func (c *MemoryCache) Close() error {
+	c.closeOnce.Do(func() {
+		close(c.stop)
+	})
+	return nil
+}
+
+// globToRegexp compiles a Redis style glob, where * also matches "/", to a regular expression
+func globToRegexp(pattern string) (*regexp.Regexp, error) {
+	var b strings.Builder
+	b.WriteString("^")
+	for i := 0; i < len(pattern); i++ {
+		switch pattern[i] {
+		case '*':
+			b.WriteString("(?s:.*)")
+		case '?':
+			b.WriteString("(?s:.)")
+		case '[':
+			end := strings.IndexByte(pattern[i+1:], ']')
+			if end < 0 {
+				return nil, fmt.Errorf("unterminated character class")
+			}
+			class := pattern[i+1 : i+1+end]
+			if strings.HasPrefix(class, "^") {
+				class = "^" + regexp.QuoteMeta(class[1:])
+			} else {
+				class = regexp.QuoteMeta(class)
+			}
+			b.WriteString("[" + class + "]")
+			i += end + 1
+		case '\\':
+			if i+1 < len(pattern) {
+				i++
+			}
+			b.WriteString(regexp.QuoteMeta(pattern[i : i+1]))
+		default:
+			b.WriteString(regexp.QuoteMeta(pattern[i : i+1]))
+		}
+	}
+	b.WriteString("$")
+	return regexp.Compile(b.String())
+}
+
+// Size returns the number of live entries and the bytes held by their keys and values
+func (c *MemoryCache) Size(ctx context.Context) (int64, int64, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	var entries, bytes int64
+	for key := range c.entries {
+		entry, ok := c.liveLocked(key)
+		if !ok {
+			continue
+		}
+		entries++
+		bytes += int64(len(key) + len(entry.data))
+	}
+	return entries, bytes, nil
+}
+
+// liveLocked returns the entry for key unless it is missing or expired
+func (c *MemoryCache) liveLocked(key string) (memoryEntry, bool) {
+	entry, ok := c.entries[key]
+	if !ok {
+		return memoryEntry{}, false
+	}
+	if !entry.expiresAt.IsZero() && !c.now().Before(entry.expiresAt) {
+		delete(c.entries, key)
+		return memoryEntry{}, false
+	}
+	return entry, true
+}
+
+func (c *MemoryCache) setLocked(key string, data []byte, ttl time.Duration) {
+	if _, exists := c.entries[key]; !exists && c.maxEntries > 0 && len(c.entries) >= c.maxEntries {
+		c.evictLocked()
+	}
+
+	entry := memoryEntry{data: data}
+	if ttl > 0 {
+		entry.expiresAt = c.now().Add(ttl)
+	}
+	c.entries[key] = entry
+}
+
+// evictLocked removes expired entries, or failing that the entry closest to expiry
+func (c *MemoryCache) evictLocked() {
+	now := c.now()
+	var victim string
+	var victimExpiry time.Time
+	for key, entry := range c.entries {
+		if !entry.expiresAt.IsZero() && !now.Before(entry.expiresAt) {
+			delete(c.entries, key)
+			continue
+		}
+		// Entries without a TTL are only evicted when nothing else can be
+		expiry := entry.expiresAt
+		if expiry.IsZero() {
+			expiry = time.Unix(1<<62, 0)
+		}
+		if victim == "" || expiry.Before(victimExpiry) {
+			victim, victimExpiry = key, expiry
+		}
+	}
+	if len(c.entries) >= c.maxEntries && victim != "" {
+		delete(c.entries, victim)
+	}
+}
+
+func (c *MemoryCache) cleanup(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-c.stop:
+			return
+		case <-ticker.C:
+			c.mu.Lock()
+			for key := range c.entries {
+				c.liveLocked(key)
+			}
+			c.mu.Unlock()
+		}
+	}
+}
@@ -0,0 +1,154 @@
+package cache
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"api-gateway-sample/pkg/config"
+	"api-gateway-sample/pkg/errors"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMemoryCache(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	c := NewMemoryCache(0, 0).(*MemoryCache)
+	c.now = func() time.Time { return now }
+	defer c.Close()
+
+	// 1. Values round-trip through JSON
+	require.NoError(t, c.Set(ctx, "svc:/orders", map[string]int{"count": 3}, time.Minute))
+	var got map[string]int
+	require.NoError(t, c.Get(ctx, "svc:/orders", &got))
+	assert.Equal(t, 3, got["count"])
+
+	ttl, err := c.GetWithTTL(ctx, "svc:/orders", &got)
+	require.NoError(t, err)
+	assert.Equal(t, time.Minute, ttl)
+
+	// 2. SetNX only stores missing keys
+	ok, err := c.SetNX(ctx, "svc:/orders", "other", time.Minute)
+	require.NoError(t, err)
+	assert.False(t, ok)
+	ok, err = c.SetNX(ctx, "svc:/users", "value", 0)
+	require.NoError(t, err)
+	assert.True(t, ok)
+
+	ttl, err = c.GetWithTTL(ctx, "svc:/users", new(string))
+	require.NoError(t, err)
+	assert.Equal(t, time.Duration(-1), ttl)
+
+	// 3. Entries expire
+	now = now.Add(time.Minute)
+	assert.ErrorIs(t, c.Get(ctx, "svc:/orders", &got), errors.ErrNotFound)
+	assert.ErrorIs(t, c.UpdateTTL(ctx, "svc:/orders", time.Minute), errors.ErrNotFound)
+
+	// 4. Clear removes matching keys only
+	require.NoError(t, c.Set(ctx, "other:key", "value", 0))
+	require.NoError(t, c.Clear(ctx, "svc:*"))
+	assert.ErrorIs(t, c.Get(ctx, "svc:/users", new(string)), errors.ErrNotFound)
+	assert.NoError(t, c.Get(ctx, "other:key", new(string)))
+
+	require.NoError(t, c.Clear(ctx, "*"))
+	assert.ErrorIs(t, c.Get(ctx, "other:key", new(string)), errors.ErrNotFound)
+}
+
+func TestMemoryCache_Eviction(t *testing.T) {
+	ctx := context.Background()
+	c := NewMemoryCache(2, 0)
+	defer c.Close()
+
+	require.NoError(t, c.Set(ctx, "persistent", "value", 0))
+	require.NoError(t, c.Set(ctx, "short", "value", time.Second))
+	require.NoError(t, c.Set(ctx, "long", "value", time.Hour))
+
+	// The entry closest to expiry makes room for the new one
+	assert.ErrorIs(t, c.Get(ctx, "short", new(string)), errors.ErrNotFound)
+	assert.NoError(t, c.Get(ctx, "persistent", new(string)))
+	assert.NoError(t, c.Get(ctx, "long", new(string)))
+}
+
+func TestMemoryCache_Size(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	c := NewMemoryCache(0, 0).(*MemoryCache)
+	c.now = func() time.Time { return now }
+	defer c.Close()
+
+	require.NoError(t, c.Set(ctx, "svc:/orders:GET", "orders", time.Minute))
+	require.NoError(t, c.Set(ctx, "svc:/users:GET", "users", time.Hour))
+
+	// Expired entries are not counted
+	now = now.Add(time.Minute)
+	entries, bytes, err := c.Size(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), entries)
+	assert.Equal(t, int64(len("svc:/users:GET")+len(`"users"`)), bytes)
+}
+
+func TestMemcachedKey(t *testing.T) {
+	assert.Equal(t, "svc:/orders", memcachedKey("svc:/orders"))
+
+	hashed := memcachedKey("svc:/orders with spaces")
+	assert.True(t, strings.HasPrefix(hashed, "sha256:"))
+	assert.Len(t, memcachedKey(strings.Repeat("a", 300)), len("sha256:")+64)
+}
+
+func TestMemcachedExpiration(t *testing.T) {
+	now := time.Now()
+	assert.Equal(t, int32(1), memcachedExpiration(100*time.Millisecond, now.Add(100*time.Millisecond)))
+	assert.Equal(t, int32(2), memcachedExpiration(1500*time.Millisecond, now.Add(1500*time.Millisecond)))
+
+	// Beyond 30 days memcached expects an absolute timestamp
+	expiresAt := now.Add(60 * 24 * time.Hour)
+	assert.Equal(t, int32(expiresAt.Unix()), memcachedExpiration(60*24*time.Hour, expiresAt))
+}
+
+func TestNewCacheRepository(t *testing.T) {
+	repo, err := NewCacheRepository(config.CacheConfig{Backend: BackendMemory}, nil)
+	require.NoError(t, err)
+	assert.IsType(t, &MemoryCache{}, repo)
+	repo.Close()
+
+	repo, err = NewCacheRepository(config.CacheConfig{Backend: BackendMemcached, Memcached: config.MemcachedConfig{Addresses: []string{"localhost:11211"}}}, nil)
+	require.NoError(t, err)
+	assert.IsType(t, &MemcachedCache{}, repo)
+
+	_, err = NewCacheRepository(config.CacheConfig{Backend: BackendRedis}, nil)
+	assert.Error(t, err)
+
+	_, err = NewCacheRepository(config.CacheConfig{Backend: "dynamodb"}, nil)
+	assert.Error(t, err)
+}
+
+func TestGlobToRegexp(t *testing.T) {
+	tests := []struct {
+		pattern string
+		key     string
+		want    bool
+	}{
+		{"*", "svc:/api/v1/orders", true},
+		{"svc:*", "svc:/api/v1/orders", true},
+		{"svc:*", "other:/api", false},
+		{"svc:?", "svc:a", true},
+		{"svc:[ab]", "svc:b", true},
+		{"svc:[^ab]", "svc:b", false},
+		{"svc:[a-c]", "svc:c", true},
+		{`svc:\*`, "svc:*", true},
+		{`svc:\*`, "svc:x", false},
+		{"a.b", "axb", false},
+	}
+
+	for _, tt := range tests {
+		matcher, err := globToRegexp(tt.pattern)
+		require.NoError(t, err)
+		assert.Equal(t, tt.want, matcher.MatchString(tt.key), "%s ~ %s", tt.pattern, tt.key)
+	}
+
+	_, err := globToRegexp("svc:[ab")
+	assert.Error(t, err)
+}
@@ -4,6 +4,9 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"strconv"
+	"strings"
+	"sync"
 	"time"
 
 	"github.com/redis/go-redis/v9"
@@ -14,11 +17,11 @@ import (
 
 // RedisCache implements the repository.CacheRepository interface
 type RedisCache struct {
-	client *redis.Client
+	client redis.UniversalClient
 }
 
 // NewRedisCache creates a new RedisCache instance
-func NewRedisCache(client *redis.Client) repository.CacheRepository {
+func NewRedisCache(client redis.UniversalClient) repository.CacheRepository {
 	return &RedisCache{
 		client: client,
 	}
@@ -111,9 +114,20 @@ func (c *RedisCache) UpdateTTL(ctx context.Context, key string, ttl time.Duratio
 
 // Clear removes all keys matching the pattern
 func (c *RedisCache) Clear(ctx context.Context, pattern string) error {
-	iter := c.client.Scan(ctx, 0, pattern, 0).Iterator()
+	// A cluster spreads keys over its masters, each of which must be scanned
+	if cluster, ok := c.client.(*redis.ClusterClient); ok {
+		return cluster.ForEachMaster(ctx, func(ctx context.Context, node *redis.Client) error {
+			return clearNode(ctx, node, pattern)
+		})
+	}
+	return clearNode(ctx, c.client, pattern)
+}
+
+// clearNode removes the keys matching the pattern from a single Redis node
+func clearNode(ctx context.Context, client redis.UniversalClient, pattern string) error {
+	iter := client.Scan(ctx, 0, pattern, 0).Iterator()
 	for iter.Next(ctx) {
-		if err := c.client.Del(ctx, iter.Val()).Err(); err != nil {
+		if err := client.Del(ctx, iter.Val()).Err(); err != nil {
 			return fmt.Errorf("failed to delete key %s: %w", iter.Val(), err)
 		}
 	}
@@ -125,6 +139,52 @@ func (c *RedisCache) Clear(ctx context.Context, pattern string) error {
 	return nil
 }
 
+// Size returns the number of keys in Redis and the memory it uses. The figures cover every key
+// of the database, including rate limit counters sharing it with the cache.
+func (c *RedisCache) Size(ctx context.Context) (int64, int64, error) {
+	// A cluster spreads keys over its masters, each of which reports its own share
+	if cluster, ok := c.client.(*redis.ClusterClient); ok {
+		var mu sync.Mutex
+		var entries, bytes int64
+		err := cluster.ForEachMaster(ctx, func(ctx context.Context, node *redis.Client) error {
+			nodeEntries, nodeBytes, err := sizeNode(ctx, node)
+			if err != nil {
+				return err
+			}
+			mu.Lock()
+			defer mu.Unlock()
+			entries += nodeEntries
+			bytes += nodeBytes
+			return nil
+		})
+		return entries, bytes, err
+	}
+	return sizeNode(ctx, c.client)
+}
+
+// sizeNode returns the number of keys of a single Redis node and the memory it uses
+func sizeNode(ctx context.Context, client redis.UniversalClient) (int64, int64, error) {
+	entries, err := client.DBSize(ctx).Result()
+	if err != nil {
+		return 0, 0, fmt.Errorf("failed to count keys: %w", err)
+	}
+
+	info, err := client.Info(ctx, "memory").Result()
+	if err != nil {
+		return 0, 0, fmt.Errorf("failed to read memory usage: %w", err)
+	}
+	for _, line := range strings.Split(info, "\n") {
+		if value, ok := strings.CutPrefix(strings.TrimSpace(line), "used_memory:"); ok {
+			bytes, err := strconv.ParseInt(value, 10, 64)
+			if err != nil {
+				return 0, 0, fmt.Errorf("failed to parse memory usage: %w", err)
+			}
+			return entries, bytes, nil
+		}
+	}
+	return entries, 0, nil
+}
+
 // Ping checks the connection to Redis
 func (c *RedisCache) Ping(ctx context.Context) error {
 	if err := c.client.Ping(ctx).Err(); err != nil {
@@ -0,0 +1,57 @@
+package cache
+
+import (
+	"fmt"
+
+	"api-gateway-sample/pkg/config"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// Redis deployment modes
+const (
+	RedisModeSingle   = "single"
+	RedisModeCluster  = "cluster"
+	RedisModeSentinel = "sentinel"
+)
+
+// NewRedisClient creates a Redis client for the configured deployment mode. All modes
+// return a redis.UniversalClient so the cache and rate limiter work unchanged.
+func NewRedisClient(cfg config.RedisConfig) (redis.UniversalClient, error) {
+	addresses := cfg.Addresses
+	if len(addresses) == 0 {
+		addresses = []string{cfg.Address}
+	}
+
+	switch cfg.Mode {
+	case "", RedisModeSingle:
+		return redis.NewClient(&redis.Options{
+			Addr:     cfg.Address,
+			Username: cfg.Username,
+			Password: cfg.Password,
+			DB:       cfg.DB,
+		}), nil
+	case RedisModeCluster:
+		return redis.NewClusterClient(&redis.ClusterOptions{
+			Addrs:          addresses,
+			Username:       cfg.Username,
+			Password:       cfg.Password,
+			RouteByLatency: cfg.RouteByLatency,
+		}), nil
+	case RedisModeSentinel:
+		if cfg.MasterName == "" {
+			return nil, fmt.Errorf("redis sentinel mode requires a master name")
+		}
+		return redis.NewFailoverClient(&redis.FailoverOptions{
+			MasterName:       cfg.MasterName,
+			SentinelAddrs:    addresses,
+			SentinelUsername: cfg.SentinelUsername,
+			SentinelPassword: cfg.SentinelPassword,
+			Username:         cfg.Username,
+			Password:         cfg.Password,
+			DB:               cfg.DB,
+		}), nil
+	default:
+		return nil, fmt.Errorf("unsupported redis mode: %s", cfg.Mode)
+	}
+}
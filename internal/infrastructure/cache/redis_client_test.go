@@ -0,0 +1,59 @@
+package cache
+
+import (
+	"testing"
+
+	"api-gateway-sample/pkg/config"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewRedisClient(t *testing.T) {
+	tests := []struct {
+		name    string
+		cfg     config.RedisConfig
+		want    interface{}
+		wantErr bool
+	}{
+		{
+			name: "single node by default",
+			cfg:  config.RedisConfig{Address: "localhost:6379"},
+			want: &redis.Client{},
+		},
+		{
+			name: "cluster",
+			cfg:  config.RedisConfig{Mode: RedisModeCluster, Addresses: []string{"node-1:6379", "node-2:6379"}},
+			want: &redis.ClusterClient{},
+		},
+		{
+			name: "sentinel",
+			cfg:  config.RedisConfig{Mode: RedisModeSentinel, MasterName: "mymaster", Addresses: []string{"sentinel:26379"}},
+			want: &redis.Client{},
+		},
+		{
+			name:    "sentinel without master name",
+			cfg:     config.RedisConfig{Mode: RedisModeSentinel},
+			wantErr: true,
+		},
+		{
+			name:    "unknown mode",
+			cfg:     config.RedisConfig{Mode: "ring"},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client, err := NewRedisClient(tt.cfg)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			defer client.Close()
+			assert.IsType(t, tt.want, client)
+		})
+	}
+}
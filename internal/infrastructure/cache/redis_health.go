@@ -0,0 +1,128 @@
+package cache
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"api-gateway-sample/internal/domain/entity"
+	"api-gateway-sample/pkg/logger"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// RedisHealthMonitor pings Redis periodically and reports it unhealthy after a number
+// of consecutive failures, so callers can degrade instead of waiting on every request
+// for a connection timeout.
+type RedisHealthMonitor struct {
+	client           redis.UniversalClient
+	failureThreshold int
+	timeout          time.Duration
+	logger           logger.Logger
+
+	mu                  sync.RWMutex
+	healthy             bool
+	consecutiveFailures int
+	failures            int64
+	lastError           string
+	lastCheck           time.Time
+
+	fallbacks atomic.Int64
+}
+
+// NewRedisHealthMonitor creates a new RedisHealthMonitor instance. Redis is assumed
+// healthy until failureThreshold consecutive pings have failed.
+func NewRedisHealthMonitor(client redis.UniversalClient, failureThreshold int, timeout time.Duration, logger logger.Logger) *RedisHealthMonitor {
+	if failureThreshold < 1 {
+		failureThreshold = 1
+	}
+	return &RedisHealthMonitor{
+		client:           client,
+		failureThreshold: failureThreshold,
+		timeout:          timeout,
+		logger:           logger,
+		healthy:          true,
+	}
+}
+
+// Start pings Redis on the given interval until the context is cancelled
+func (m *RedisHealthMonitor) Start(ctx context.Context, interval time.Duration) {
+	if interval <= 0 {
+		return
+	}
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				m.Check(ctx)
+			}
+		}
+	}()
+}
+
+// Check pings Redis once and updates the health state
+func (m *RedisHealthMonitor) Check(ctx context.Context) {
+	if m.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, m.timeout)
+		defer cancel()
+	}
+	err := m.client.Ping(ctx).Err()
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.lastCheck = time.Now()
+	if err == nil {
+		if !m.healthy {
+			m.logger.Info("Redis connection recovered", "failures", m.consecutiveFailures)
+		}
+		m.healthy = true
+		m.consecutiveFailures = 0
+		m.lastError = ""
+		return
+	}
+
+	m.failures++
+	m.consecutiveFailures++
+	m.lastError = err.Error()
+	if m.healthy && m.consecutiveFailures >= m.failureThreshold {
+		m.healthy = false
+		m.logger.Error("Redis is unavailable, degrading cache and rate limiting", "error", err)
+	}
+}
+
+// Healthy reports whether Redis is currently considered reachable
+func (m *RedisHealthMonitor) Healthy() bool {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.healthy
+}
+
+// RecordFallback counts an operation that was served by a degradation policy
+func (m *RedisHealthMonitor) RecordFallback() {
+	m.fallbacks.Add(1)
+}
+
+// Health returns a snapshot of the Redis connectivity
+func (m *RedisHealthMonitor) Health() *entity.DependencyHealth {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return &entity.DependencyHealth{
+		Name:                "redis",
+		Criticality:         entity.CriticalityDegradable,
+		Healthy:             m.healthy,
+		ConsecutiveFailures: m.consecutiveFailures,
+		Failures:            m.failures,
+		Fallbacks:           m.fallbacks.Load(),
+		LastError:           m.lastError,
+		LastCheck:           m.lastCheck,
+	}
+}
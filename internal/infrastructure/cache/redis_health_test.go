@@ -0,0 +1,64 @@
+package cache
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"api-gateway-sample/pkg/errors"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// nopLogger discards all log output
+type nopLogger struct{}
+
+func (nopLogger) Debug(msg string, keysAndValues ...interface{}) {}
+func (nopLogger) Info(msg string, keysAndValues ...interface{})  {}
+func (nopLogger) Warn(msg string, keysAndValues ...interface{})  {}
+func (nopLogger) Error(msg string, keysAndValues ...interface{}) {}
+func (nopLogger) Fatal(msg string, keysAndValues ...interface{}) {}
+
+func TestRedisHealthMonitor_FallbackCache(t *testing.T) {
+	server := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: server.Addr(), MaxRetries: -1})
+	defer client.Close()
+
+	ctx := context.Background()
+	monitor := NewRedisHealthMonitor(client, 2, time.Second, nopLogger{})
+	c := NewFallbackCache(NewRedisCache(client), monitor)
+
+	// 1. While Redis is up the cache is used
+	monitor.Check(ctx)
+	require.NoError(t, c.Set(ctx, "key", "value", time.Minute))
+	var value string
+	require.NoError(t, c.Get(ctx, "key", &value))
+	assert.Equal(t, "value", value)
+
+	// 2. Redis is only reported down after the failure threshold
+	server.Close()
+	monitor.Check(ctx)
+	assert.True(t, monitor.Healthy())
+	monitor.Check(ctx)
+	assert.False(t, monitor.Healthy())
+
+	// 3. The cache is bypassed while Redis is down
+	assert.ErrorIs(t, c.Get(ctx, "key", &value), errors.ErrNotFound)
+	assert.NoError(t, c.Set(ctx, "key", "value", time.Minute))
+
+	health := monitor.Health()
+	assert.Equal(t, "redis", health.Name)
+	assert.False(t, health.Healthy)
+	assert.Equal(t, 2, health.ConsecutiveFailures)
+	assert.Equal(t, int64(2), health.Fallbacks)
+	assert.NotEmpty(t, health.LastError)
+
+	// 4. A successful ping restores the cache
+	require.NoError(t, server.Restart())
+	monitor.Check(ctx)
+	assert.True(t, monitor.Healthy())
+	assert.Equal(t, 0, monitor.Health().ConsecutiveFailures)
+}
@@ -0,0 +1,89 @@
+package client
+
+import (
+	"context"
+	"net"
+	"net/http/httptrace"
+	"sync"
+	"time"
+)
+
+// dnsCache resolves upstream host names once per TTL rather than on every new connection
+type dnsCache struct {
+	resolver *net.Resolver
+	dialer   net.Dialer
+	ttl      time.Duration
+	now      func() time.Time
+
+	mu      sync.Mutex
+	entries map[string]dnsEntry
+}
+
+// dnsEntry holds the addresses of a host until it expires
+type dnsEntry struct {
+	addrs   []string
+	expires time.Time
+}
+
+// newDNSCache creates a new dnsCache instance keeping addresses for ttl
+func newDNSCache(ttl time.Duration) *dnsCache {
+	return &dnsCache{
+		resolver: net.DefaultResolver,
+		ttl:      ttl,
+		now:      time.Now,
+		entries:  make(map[string]dnsEntry),
+	}
+}
+
+// DialContext connects to the first reachable address of a host, resolving it when its cached
+// addresses are missing or expired. Lookups are reported to the client trace of the context.
+func (c *dnsCache) DialContext(ctx context.Context, network string, address string) (net.Conn, error) {
+	host, port, err := net.SplitHostPort(address)
+	if err != nil || net.ParseIP(host) != nil {
+		return c.dialer.DialContext(ctx, network, address)
+	}
+
+	addrs, err := c.lookup(ctx, host)
+	if err != nil {
+		return nil, err
+	}
+	var conn net.Conn
+	for _, addr := range addrs {
+		conn, err = c.dialer.DialContext(ctx, network, net.JoinHostPort(addr, port))
+		if err == nil {
+			return conn, nil
+		}
+	}
+	// Addresses that no longer accept connections are looked up again by the next request
+	c.mu.Lock()
+	delete(c.entries, host)
+	c.mu.Unlock()
+	return nil, err
+}
+
+// lookup returns the cached addresses of a host, resolving them when needed
+func (c *dnsCache) lookup(ctx context.Context, host string) ([]string, error) {
+	c.mu.Lock()
+	entry, ok := c.entries[host]
+	c.mu.Unlock()
+	if ok && c.now().Before(entry.expires) {
+		return entry.addrs, nil
+	}
+
+	trace := httptrace.ContextClientTrace(ctx)
+	if trace != nil && trace.DNSStart != nil {
+		trace.DNSStart(httptrace.DNSStartInfo{Host: host})
+	}
+	addrs, err := c.resolver.LookupHost(ctx, host)
+	if trace != nil && trace.DNSDone != nil {
+		trace.DNSDone(httptrace.DNSDoneInfo{Err: err})
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	c.mu.Lock()
+	c.entries[host] = dnsEntry{addrs: addrs, expires: c.now().Add(c.ttl)}
+	c.mu.Unlock()
+	return addrs, nil
+}
@@ -0,0 +1,129 @@
+package client
+
+import (
+	"net"
+	"net/http"
+	"net/netip"
+	"strings"
+
+	"api-gateway-sample/internal/domain/entity"
+)
+
+// forwardedHeaders are the headers describing the clients and proxies a request passed through
+var forwardedHeaders = []string{
+	"Forwarded", "X-Forwarded-For", "X-Forwarded-Host", "X-Forwarded-Port", "X-Forwarded-Proto",
+}
+
+// SetForwarding sets which peers are trusted proxies, whose forwarded headers are extended
+// rather than replaced, and whether the standard Forwarded header is sent alongside the
+// X-Forwarded-* headers
+func (c *HTTPClient) SetForwarding(trustedProxies []netip.Prefix, forwarded bool) {
+	c.trustedProxies = trustedProxies
+	c.forwarded = forwarded
+}
+
+// setForwardedHeaders describes the client of a request to its upstream. The gateway's peer is
+// appended to the X-Forwarded-For chain; when the peer is a trusted proxy, the chain and the
+// protocol, host and port it forwarded are kept, otherwise they are the gateway's own.
+func (c *HTTPClient) setForwardedHeaders(header http.Header, request *entity.Request) {
+	peer := peerAddr(request.ClientIP)
+	if !c.trusted(peer) {
+		for _, name := range forwardedHeaders {
+			header.Del(name)
+		}
+	}
+
+	if peer != "" {
+		chain := strings.Join(header.Values("X-Forwarded-For"), ", ")
+		if chain != "" {
+			chain += ", "
+		}
+		header.Set("X-Forwarded-For", chain+peer)
+	}
+	if header.Get("X-Forwarded-Proto") == "" && request.Scheme != "" {
+		header.Set("X-Forwarded-Proto", request.Scheme)
+	}
+	if header.Get("X-Forwarded-Host") == "" && request.Host != "" {
+		header.Set("X-Forwarded-Host", request.Host)
+	}
+	if header.Get("X-Forwarded-Port") == "" {
+		if port := forwardedPort(header.Get("X-Forwarded-Host"), header.Get("X-Forwarded-Proto")); port != "" {
+			header.Set("X-Forwarded-Port", port)
+		}
+	}
+
+	if c.forwarded {
+		var element []string
+		if peer != "" {
+			element = append(element, "for="+forwardedValue(peer))
+		}
+		if request.Host != "" {
+			element = append(element, "host="+forwardedValue(request.Host))
+		}
+		if request.Scheme != "" {
+			element = append(element, "proto="+request.Scheme)
+		}
+		if len(element) > 0 {
+			elements := append(header.Values("Forwarded"), strings.Join(element, ";"))
+			header.Set("Forwarded", strings.Join(elements, ", "))
+		}
+	}
+}
+
+// trusted reports whether a peer is a trusted proxy
+func (c *HTTPClient) trusted(peer string) bool {
+	addr, err := netip.ParseAddr(peer)
+	if err != nil {
+		return false
+	}
+	addr = addr.Unmap()
+	for _, prefix := range c.trustedProxies {
+		if prefix.Contains(addr) {
+			return true
+		}
+	}
+	return false
+}
+
+// peerAddr returns the address of a peer without its port
+func peerAddr(clientIP string) string {
+	if host, _, err := net.SplitHostPort(clientIP); err == nil {
+		return host
+	}
+	return clientIP
+}
+
+// forwardedPort returns the port a client connected to, that of the host it addressed or the
+// default port of its protocol
+func forwardedPort(host string, proto string) string {
+	if _, port, err := net.SplitHostPort(host); err == nil {
+		return port
+	}
+	switch proto {
+	case "https":
+		return "443"
+	case "http":
+		return "80"
+	}
+	return ""
+}
+
+// forwardedValue formats a value of the Forwarded header, quoting values that are not tokens
+// such as IPv6 addresses, which are bracketed as RFC 7239 requires
+func forwardedValue(value string) string {
+	if addr, err := netip.ParseAddr(value); err == nil && addr.Is6() {
+		value = "[" + value + "]"
+	}
+	if strings.IndexFunc(value, func(r rune) bool { return !isTokenChar(r) }) < 0 {
+		return value
+	}
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(value) + `"`
+}
+
+// isTokenChar reports whether a character may appear in a token of an HTTP header
+func isTokenChar(r rune) bool {
+	if r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' {
+		return true
+	}
+	return strings.ContainsRune("!#$%&'*+-.^_`|~", r)
+}
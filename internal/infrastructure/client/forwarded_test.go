@@ -0,0 +1,70 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"api-gateway-sample/internal/domain/entity"
+)
+
+func TestHTTPClient_ForwardedHeaders(t *testing.T) {
+	// 1. An upstream echoes the forwarded headers it receives
+	var received http.Header
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received = r.Header.Clone()
+	}))
+	defer upstream.Close()
+	service := &entity.Service{Name: "orders", BaseURL: upstream.URL}
+
+	httpClient := NewHTTPClient(5*time.Second, nopLogger{})
+	httpClient.SetForwarding([]netip.Prefix{netip.MustParsePrefix("10.0.0.0/8")}, true)
+	send := func(clientIP string) {
+		request := entity.NewRequest(http.MethodGet, "/orders", map[string][]string{
+			"X-Forwarded-For":   {"203.0.113.7, 198.51.100.2"},
+			"X-Forwarded-Proto": {"https"},
+			"X-Forwarded-Host":  {"shop.example.com"},
+			"Forwarded":         {`for=203.0.113.7;proto=https`},
+		}, nil, nil, clientIP)
+		request.Host = "gateway.internal:8080"
+		request.Scheme = "http"
+		_, err := httpClient.SendRequest(context.Background(), request, service)
+		require.NoError(t, err)
+	}
+
+	// 2. The headers of a trusted proxy are kept and the chains extended with the proxy
+	send("10.1.2.3:41000")
+	assert.Equal(t, "203.0.113.7, 198.51.100.2, 10.1.2.3", received.Get("X-Forwarded-For"))
+	assert.Equal(t, "https", received.Get("X-Forwarded-Proto"))
+	assert.Equal(t, "shop.example.com", received.Get("X-Forwarded-Host"))
+	assert.Equal(t, "443", received.Get("X-Forwarded-Port"))
+	assert.Equal(t, `for=203.0.113.7;proto=https, for=10.1.2.3;host="gateway.internal:8080";proto=http`, received.Get("Forwarded"))
+
+	// 3. The headers of other clients are replaced with the gateway's view of the connection
+	send("[2001:db8::1]:41000")
+	assert.Equal(t, "2001:db8::1", received.Get("X-Forwarded-For"))
+	assert.Equal(t, "http", received.Get("X-Forwarded-Proto"))
+	assert.Equal(t, "gateway.internal:8080", received.Get("X-Forwarded-Host"))
+	assert.Equal(t, "8080", received.Get("X-Forwarded-Port"))
+	assert.Equal(t, `for="[2001:db8::1]";host="gateway.internal:8080";proto=http`, received.Get("Forwarded"))
+
+	// 4. The Forwarded header is only sent when enabled
+	httpClient.SetForwarding(nil, false)
+	send("10.1.2.3:41000")
+	assert.Equal(t, "10.1.2.3", received.Get("X-Forwarded-For"))
+	assert.Empty(t, received.Values("Forwarded"))
+
+	// 5. External hosts are not told about the clients, even by their trusted proxies
+	httpClient.SetForwarding([]netip.Prefix{netip.MustParsePrefix("10.0.0.0/8")}, true)
+	service.External = true
+	send("10.1.2.3:41000")
+	for _, name := range append(forwardedHeaders, "X-Request-Id") {
+		assert.Empty(t, received.Values(name), name)
+	}
+}
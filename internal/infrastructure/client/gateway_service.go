@@ -40,15 +40,19 @@ func (s *GatewayService) ValidateRequest(ctx context.Context, request *entity.Re
 func (s *GatewayService) TransformRequest(ctx context.Context, request *entity.Request, service *entity.Service) (*entity.Request, error) {
 	// Create a new request with the same data
 	transformed := &entity.Request{
-		ID:          request.ID,
-		Method:      request.Method,
-		Path:        request.Path,
-		Headers:     request.Headers,
-		QueryParams: request.QueryParams,
-		Body:        request.Body,
-		ClientIP:    request.ClientIP,
-		Timestamp:   request.Timestamp,
-		UserID:      request.UserID,
+		ID:            request.ID,
+		Method:        request.Method,
+		Path:          request.Path,
+		Headers:       request.Headers,
+		QueryParams:   request.QueryParams,
+		Body:          request.Body,
+		ClientIP:      request.ClientIP,
+		Timestamp:     request.Timestamp,
+		UserID:        request.UserID,
+		Host:          request.Host,
+		Scheme:        request.Scheme,
+		BodyStream:    request.BodyStream,
+		ResponseLimit: request.ResponseLimit,
 	}
 
 	// Add service-specific headers
@@ -84,6 +88,7 @@ func (s *GatewayService) TransformResponse(ctx context.Context, response *entity
 		Timestamp:    response.Timestamp,
 		LatencyMs:    response.LatencyMs,
 		CachedResult: response.CachedResult,
+		BodyStream:   response.BodyStream,
 	}
 
 	// Add service-specific headers
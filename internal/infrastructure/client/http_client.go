@@ -3,54 +3,172 @@ package client
 import (
 	"bytes"
 	"context"
+	"crypto/tls"
 	"fmt"
 	"io"
+	"net"
 	"net/http"
+	"net/netip"
+	"net/textproto"
+	"strings"
+	"sync"
 	"time"
 
+	"golang.org/x/net/http2"
+
 	"api-gateway-sample/internal/domain/entity"
+	"api-gateway-sample/pkg/bufferpool"
+	"api-gateway-sample/pkg/errors"
 	"api-gateway-sample/pkg/logger"
 )
 
+// hopHeaders are the hop-by-hop headers of RFC 7230, which describe a single connection and are
+// never forwarded by proxies
+var hopHeaders = []string{
+	"Connection", "Keep-Alive", "Proxy-Authenticate", "Proxy-Authorization", "Proxy-Connection",
+	"Te", "Trailer", "Transfer-Encoding", "Upgrade",
+}
+
 // HTTPClient implements an HTTP client for communicating with backend services
 type HTTPClient struct {
 	client *http.Client
 	logger logger.Logger
+
+	// deniedRequestHeaders are never sent to upstreams, deniedResponseHeaders never returned
+	// from them
+	deniedRequestHeaders  []string
+	deniedResponseHeaders []string
+
+	// trustedProxies are the peers whose forwarded headers are kept; forwarded sends the
+	// Forwarded header as well
+	trustedProxies []netip.Prefix
+	forwarded      bool
+
+	// dnsCache resolves the hosts of new connections, nil when every connection resolves its host
+	dnsCache *dnsCache
+
+	// networks are the clients of the services reaching their upstreams through a network, by name
+	networks map[string]*http.Client
+
+	// sockets are the clients of unix socket upstreams, by socket path
+	socketsMu sync.Mutex
+	sockets   map[string]*http.Client
 }
 
 // NewHTTPClient creates a new HTTPClient instance
 func NewHTTPClient(timeout time.Duration, logger logger.Logger) *HTTPClient {
-	return &HTTPClient{
-		client: &http.Client{
-			Timeout: timeout,
-			Transport: &http.Transport{
-				MaxIdleConns:        100,
-				MaxIdleConnsPerHost: 10,
-				IdleConnTimeout:     90 * time.Second,
+	c := &HTTPClient{logger: logger}
+	c.client = &http.Client{
+		Timeout:   timeout,
+		Transport: newTransport(c.dialContext),
+	}
+	return c
+}
+
+// newTransport creates the transport of upstream requests opening connections with dial
+func newTransport(dial func(ctx context.Context, network string, addr string) (net.Conn, error)) *http.Transport {
+	return &http.Transport{
+		DialContext:         dial,
+		MaxIdleConns:        100,
+		MaxIdleConnsPerHost: 10,
+		IdleConnTimeout:     90 * time.Second,
+		// Custom transports only negotiate HTTP/2 with TLS upstreams when asked to
+		ForceAttemptHTTP2: true,
+	}
+}
+
+// EnableDNSCache keeps the addresses of upstream hosts for ttl, so that new connections to an
+// upstream skip the lookup. Upstreams whose addresses change are reached at their new
+// addresses once the TTL expires or an old address refuses connections.
+func (c *HTTPClient) EnableDNSCache(ttl time.Duration) {
+	c.dnsCache = newDNSCache(ttl)
+}
+
+// dialContext opens the connections of the transports
+func (c *HTTPClient) dialContext(ctx context.Context, network string, addr string) (net.Conn, error) {
+	if c.dnsCache != nil {
+		return c.dnsCache.DialContext(ctx, network, addr)
+	}
+	var dialer net.Dialer
+	return dialer.DialContext(ctx, network, addr)
+}
+
+// EnableH2C sends requests to http:// upstreams over cleartext HTTP/2 with prior knowledge, so
+// that concurrent requests to an upstream share a few multiplexed connections. The upstreams
+// must accept h2c. Requests to https:// upstreams keep using the existing transport.
+func (c *HTTPClient) EnableH2C() {
+	c.client.Transport = &h2cTransport{
+		cleartext: &http2.Transport{
+			AllowHTTP: true,
+			DialTLSContext: func(ctx context.Context, network string, addr string, _ *tls.Config) (net.Conn, error) {
+				return c.dialContext(ctx, network, addr)
 			},
 		},
-		logger: logger,
+		next: c.client.Transport,
 	}
 }
 
+// SetHeaderDenylist removes headers from every request sent to upstreams and every response
+// received from them, in addition to the hop-by-hop headers. Request headers are removed once
+// the gateway has set its own, so a denied header is never forwarded even when the gateway
+// would set it, as with the Authorization header of signed requests.
+func (c *HTTPClient) SetHeaderDenylist(request []string, response []string) {
+	c.deniedRequestHeaders = request
+	c.deniedResponseHeaders = response
+}
+
+// removeHeaders removes the hop-by-hop headers, including those the Connection header lists,
+// and the denied headers
+func removeHeaders(header http.Header, denied []string) {
+	for _, value := range header.Values("Connection") {
+		for _, name := range strings.Split(value, ",") {
+			if name = textproto.TrimString(name); name != "" {
+				header.Del(name)
+			}
+		}
+	}
+	for _, name := range hopHeaders {
+		header.Del(name)
+	}
+	for _, name := range denied {
+		header.Del(name)
+	}
+}
+
+// h2cTransport routes http:// requests to a cleartext HTTP/2 transport
+type h2cTransport struct {
+	cleartext http.RoundTripper
+	next      http.RoundTripper
+}
+
+// RoundTrip sends a request over cleartext HTTP/2 when its scheme is http
+func (t *h2cTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.URL.Scheme == "http" {
+		return t.cleartext.RoundTrip(req)
+	}
+	return t.next.RoundTrip(req)
+}
+
 // SendRequest sends an HTTP request to a backend service
 func (c *HTTPClient) SendRequest(ctx context.Context, request *entity.Request, service *entity.Service) (*entity.Response, error) {
 	startTime := time.Now()
 
-	// Create target URL
-	targetURL := fmt.Sprintf("%s%s", service.BaseURL, request.Path)
-	if request.QueryParams != nil && len(request.QueryParams) > 0 {
-		targetURL += "?"
-		for key, values := range request.QueryParams {
-			for _, value := range values {
-				targetURL += fmt.Sprintf("%s=%s&", key, value)
-			}
-		}
-		targetURL = targetURL[:len(targetURL)-1] // Remove trailing &
+	client, err := c.clientFor(service)
+	if err != nil {
+		return nil, err
 	}
 
-	// Create HTTP request
-	httpReq, err := http.NewRequestWithContext(ctx, request.Method, targetURL, bytes.NewReader(request.Body))
+	// Create HTTP request, with the query encoded as signed requests expect
+	target, err := upstreamURL(service, request)
+	if err != nil {
+		return nil, fmt.Errorf("invalid upstream URL: %w", err)
+	}
+	requestBody := io.Reader(bytes.NewReader(request.Body))
+	if request.BodyStream != nil {
+		// Streamed bodies are sent chunked, as their length is unknown
+		requestBody = request.BodyStream
+	}
+	httpReq, err := http.NewRequestWithContext(ctx, request.Method, target.String(), requestBody)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create request: %w", err)
 	}
@@ -62,24 +180,33 @@ func (c *HTTPClient) SendRequest(ctx context.Context, request *entity.Request, s
 		}
 	}
 
-	// Add X-Forwarded headers
-	httpReq.Header.Set("X-Forwarded-For", request.ClientIP)
-	httpReq.Header.Set("X-Request-ID", request.ID)
+	// Add forwarded headers, which describe the gateway's clients to its own services only
+	if service.External {
+		for _, name := range forwardedHeaders {
+			httpReq.Header.Del(name)
+		}
+		httpReq.Header.Del("X-Request-ID")
+	} else {
+		c.setForwardedHeaders(httpReq.Header, request)
+		httpReq.Header.Set("X-Request-ID", request.ID)
+	}
+	removeHeaders(httpReq.Header, c.deniedRequestHeaders)
 
-	// Send request
-	httpResp, err := c.client.Do(httpReq)
+	// Send request, measuring the phases of its connection
+	traceCtx, connection := traceConnection(ctx, startTime)
+	httpResp, err := client.Do(httpReq.WithContext(traceCtx))
 	if err != nil {
 		return nil, fmt.Errorf("failed to send request: %w", err)
 	}
-	defer httpResp.Body.Close()
 
-	// Read response body
-	body, err := io.ReadAll(httpResp.Body)
+	// Read response body, streaming or rejecting what exceeds the limit of the endpoint
+	body, stream, err := readResponseBody(httpResp, request.ResponseLimit)
 	if err != nil {
-		return nil, fmt.Errorf("failed to read response body: %w", err)
+		return nil, err
 	}
 
-	// Create response
+	// Create response, without the headers that are not returned to clients
+	removeHeaders(httpResp.Header, c.deniedResponseHeaders)
 	response := &entity.Response{
 		RequestID:    request.ID,
 		StatusCode:   httpResp.StatusCode,
@@ -89,17 +216,53 @@ func (c *HTTPClient) SendRequest(ctx context.Context, request *entity.Request, s
 		Timestamp:    time.Now(),
 		LatencyMs:    time.Since(startTime).Milliseconds(),
 		CachedResult: false,
+		Timings:      connection.Timings(),
+		BodyStream:   stream,
 	}
 
-	// Log request details
-	c.logger.Info("Request completed",
-		"request_id", request.ID,
+	// Log request details; request, trace and user IDs come from the request-scoped logger
+	logger.FromContextOr(ctx, c.logger).Info("Upstream request completed",
 		"method", request.Method,
 		"path", request.Path,
-		"service", service.Name,
+		"upstream", service.Name,
 		"status", response.StatusCode,
 		"latency_ms", response.LatencyMs,
 	)
 
 	return response, nil
 }
+
+// readResponseBody reads the body of an upstream response within a limit. A larger body is
+// rejected, or, when the limit streams it, returned as the bytes read and a stream of the rest,
+// which the caller closes. The response body is closed otherwise.
+func readResponseBody(httpResp *http.Response, limit *entity.ResponseLimit) ([]byte, io.ReadCloser, error) {
+	if limit == nil {
+		defer httpResp.Body.Close()
+		body, err := bufferpool.ReadAll(httpResp.Body)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to read response body: %w", err)
+		}
+		return body, nil, nil
+	}
+
+	// A declared length over the limit is not read at all
+	var body []byte
+	if httpResp.ContentLength <= limit.MaxSize {
+		var err error
+		body, err = bufferpool.ReadAll(io.LimitReader(httpResp.Body, limit.MaxSize+1))
+		if err != nil {
+			httpResp.Body.Close()
+			return nil, nil, fmt.Errorf("failed to read response body: %w", err)
+		}
+		if int64(len(body)) <= limit.MaxSize {
+			httpResp.Body.Close()
+			return body, nil, nil
+		}
+	}
+
+	if limit.Streams() {
+		return body, httpResp.Body, nil
+	}
+	httpResp.Body.Close()
+	return nil, nil, errors.NewTypedError(errors.ProblemResponseTooLarge, fmt.Sprintf("upstream response exceeds %d bytes", limit.MaxSize), nil)
+}
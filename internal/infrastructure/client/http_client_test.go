@@ -0,0 +1,270 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/net/http2"
+	"golang.org/x/net/http2/h2c"
+
+	"api-gateway-sample/internal/domain/entity"
+	"api-gateway-sample/pkg/errors"
+)
+
+// nopLogger discards all log output
+type nopLogger struct{}
+
+func (nopLogger) Debug(msg string, keysAndValues ...interface{}) {}
+func (nopLogger) Info(msg string, keysAndValues ...interface{})  {}
+func (nopLogger) Warn(msg string, keysAndValues ...interface{})  {}
+func (nopLogger) Error(msg string, keysAndValues ...interface{}) {}
+func (nopLogger) Fatal(msg string, keysAndValues ...interface{}) {}
+
+func TestHTTPClient_EnableH2C(t *testing.T) {
+	// 1. An upstream serving cleartext HTTP/2 reports the protocol of each request
+	upstream := httptest.NewServer(h2c.NewHandler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(r.Proto))
+	}), &http2.Server{}))
+	defer upstream.Close()
+
+	request := entity.NewRequest(http.MethodGet, "/orders", map[string][]string{}, nil, nil, "127.0.0.1")
+	service := &entity.Service{Name: "orders", BaseURL: upstream.URL}
+
+	// 2. Requests use HTTP/1.1 by default
+	httpClient := NewHTTPClient(5*time.Second, nopLogger{})
+	response, err := httpClient.SendRequest(context.Background(), request, service)
+	require.NoError(t, err)
+	assert.Equal(t, "HTTP/1.1", string(response.Body))
+
+	// 3. With h2c they use HTTP/2 without TLS
+	httpClient.EnableH2C()
+	response, err = httpClient.SendRequest(context.Background(), request, service)
+	require.NoError(t, err)
+	assert.Equal(t, "HTTP/2.0", string(response.Body))
+}
+
+func TestHTTPClient_RemovesHeaders(t *testing.T) {
+	// 1. An upstream echoes the headers it receives and sends hop-by-hop and denied headers back
+	var received http.Header
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received = r.Header.Clone()
+		w.Header().Set("Connection", "X-Upstream-Hop")
+		w.Header().Set("X-Upstream-Hop", "1")
+		w.Header().Set("Keep-Alive", "timeout=5")
+		w.Header().Set("X-Powered-By", "Express")
+		w.Header().Set("Content-Type", "application/json")
+	}))
+	defer upstream.Close()
+
+	httpClient := NewHTTPClient(5*time.Second, nopLogger{})
+	httpClient.SetHeaderDenylist([]string{"Cookie", "x-request-id"}, []string{"X-Powered-By"})
+	request := entity.NewRequest(http.MethodGet, "/orders", map[string][]string{
+		"Connection":          {"keep-alive, X-Client-Hop"},
+		"X-Client-Hop":        {"1"},
+		"Keep-Alive":          {"timeout=5"},
+		"Proxy-Authorization": {"Basic cHJveHk6c2VjcmV0"},
+		"Upgrade":             {"websocket"},
+		"Cookie":              {"session=abc"},
+		"Authorization":       {"Bearer token"},
+	}, nil, nil, "127.0.0.1")
+	response, err := httpClient.SendRequest(context.Background(), request, &entity.Service{Name: "orders", BaseURL: upstream.URL})
+	require.NoError(t, err)
+
+	// 2. Hop-by-hop headers, those the Connection header names and denied headers are not forwarded
+	for _, name := range []string{"X-Client-Hop", "Keep-Alive", "Proxy-Authorization", "Upgrade", "Cookie", "X-Request-Id"} {
+		assert.Empty(t, received.Values(name), name)
+	}
+	assert.Equal(t, "Bearer token", received.Get("Authorization"))
+	assert.Equal(t, "127.0.0.1", received.Get("X-Forwarded-For"))
+
+	// 3. The same applies to the response
+	for _, name := range []string{"Connection", "X-Upstream-Hop", "Keep-Alive", "X-Powered-By"} {
+		assert.Empty(t, http.Header(response.Headers).Values(name), name)
+	}
+	assert.Equal(t, "application/json", response.ContentType)
+}
+
+func TestHTTPClient_EscapesURL(t *testing.T) {
+	// 1. An upstream echoes the path and query it receives
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(r.URL.EscapedPath() + "?" + r.URL.RawQuery))
+	}))
+	defer upstream.Close()
+
+	httpClient := NewHTTPClient(5*time.Second, nopLogger{})
+	service := &entity.Service{Name: "search", BaseURL: upstream.URL + "/api/?tenant=acme"}
+
+	// 2. Special characters are escaped and repeated parameters kept, after those of the base URL
+	request := entity.NewRequest(http.MethodGet, "/search/a b?#", map[string][]string{}, map[string][]string{
+		"q":      {"fish & chips=good"},
+		"tag":    {"a", "b+c"},
+		"tenant": {"evil"},
+	}, nil, "127.0.0.1")
+	response, err := httpClient.SendRequest(context.Background(), request, service)
+	require.NoError(t, err)
+	assert.Equal(t, "/api/search/a%20b%3F%23?q=fish%20%26%20chips%3Dgood&tag=a&tag=b%2Bc&tenant=acme&tenant=evil", string(response.Body))
+
+	// 3. Without parameters no query is sent
+	request = entity.NewRequest(http.MethodGet, "/orders", map[string][]string{}, nil, nil, "127.0.0.1")
+	response, err = httpClient.SendRequest(context.Background(), request, &entity.Service{Name: "orders", BaseURL: upstream.URL})
+	require.NoError(t, err)
+	assert.Equal(t, "/orders?", string(response.Body))
+}
+
+func TestHTTPClient_ConnectionTimings(t *testing.T) {
+	// 1. An upstream is reached through its host name
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer upstream.Close()
+	service := &entity.Service{Name: "orders", BaseURL: strings.Replace(upstream.URL, "127.0.0.1", "localhost", 1)}
+	request := entity.NewRequest(http.MethodGet, "/orders", map[string][]string{}, nil, nil, "127.0.0.1")
+
+	httpClient := NewHTTPClient(5*time.Second, nopLogger{})
+	httpClient.EnableDNSCache(time.Minute)
+	send := func() *entity.UpstreamTimings {
+		response, err := httpClient.SendRequest(context.Background(), request, service)
+		require.NoError(t, err)
+		require.NotNil(t, response.Timings)
+		return response.Timings
+	}
+
+	// 2. The first request resolves the host and opens a connection
+	timings := send()
+	assert.False(t, timings.Reused)
+	assert.Positive(t, timings.DNS)
+	assert.Positive(t, timings.Connect)
+	assert.Positive(t, timings.FirstByte)
+
+	// 3. The next one reuses the keep-alive connection
+	timings = send()
+	assert.True(t, timings.Reused)
+	assert.Zero(t, timings.Connect)
+	assert.Positive(t, timings.FirstByte)
+
+	// 4. New connections reuse the cached addresses
+	httpClient.client.CloseIdleConnections()
+	timings = send()
+	assert.False(t, timings.Reused)
+	assert.Zero(t, timings.DNS)
+	assert.Positive(t, timings.Connect)
+}
+
+func TestHTTPClient_Networks(t *testing.T) {
+	// 1. An upstream only reachable by the host name of its network, and a proxy of another network
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("upstream " + r.Host))
+	}))
+	defer upstream.Close()
+	proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("proxy " + r.URL.String()))
+	}))
+	defer proxy.Close()
+	proxyURL, err := url.Parse(proxy.URL)
+	require.NoError(t, err)
+	port := upstream.Listener.Addr().(*net.TCPAddr).Port
+
+	httpClient := NewHTTPClient(5*time.Second, nopLogger{})
+	httpClient.SetNetworks(map[string]Network{
+		"vpc":     {Hosts: map[string]string{"Orders.Internal": "127.0.0.1"}},
+		"bastion": {Proxy: proxyURL},
+	})
+	request := entity.NewRequest(http.MethodGet, "/orders", map[string][]string{}, nil, nil, "127.0.0.1")
+	baseURL := fmt.Sprintf("http://orders.internal:%d", port)
+
+	// 2. Host overrides connect to their address, keeping the host name
+	response, err := httpClient.SendRequest(context.Background(), request, &entity.Service{BaseURL: baseURL, Network: "vpc"})
+	require.NoError(t, err)
+	assert.Equal(t, fmt.Sprintf("upstream orders.internal:%d", port), string(response.Body))
+
+	// 3. Proxied networks send the request to their proxy
+	response, err = httpClient.SendRequest(context.Background(), request, &entity.Service{BaseURL: baseURL, Network: "bastion"})
+	require.NoError(t, err)
+	assert.Equal(t, "proxy "+baseURL+"/orders", string(response.Body))
+
+	// 4. Services of an unknown network are not sent outside of it
+	_, err = httpClient.SendRequest(context.Background(), request, &entity.Service{BaseURL: upstream.URL, Network: "dmz"})
+	assert.EqualError(t, err, "upstream network dmz is not configured")
+}
+
+func TestHTTPClient_UnixSocket(t *testing.T) {
+	// 1. A sidecar listens on a unix socket and reports the requests it receives
+	path := filepath.Join(t.TempDir(), "orders.sock")
+	listener, err := net.Listen("unix", path)
+	require.NoError(t, err)
+	upstream := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(r.Host + " " + r.URL.RequestURI()))
+	}))
+	upstream.Listener = listener
+	upstream.Start()
+	defer upstream.Close()
+
+	// 2. Requests are sent over the socket below the upstream path of the service
+	httpClient := NewHTTPClient(5*time.Second, nopLogger{})
+	request := entity.NewRequest(http.MethodGet, "/orders", map[string][]string{}, map[string][]string{"page": {"2"}}, nil, "127.0.0.1")
+	service := &entity.Service{BaseURL: "unix://" + path, UpstreamPath: "/api/v1/"}
+	response, err := httpClient.SendRequest(context.Background(), request, service)
+	require.NoError(t, err)
+	assert.Equal(t, "localhost /api/v1/orders?page=2", string(response.Body))
+}
+
+func TestHTTPClient_ResponseLimit(t *testing.T) {
+	// 1. An upstream sends bodies of the requested size, chunked unless their length is declared
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body := strings.Repeat("x", len(r.URL.Query().Get("size")))
+		if r.URL.Query().Get("declared") != "" {
+			w.Header().Set("Content-Length", fmt.Sprint(len(body)))
+		}
+		w.Write([]byte(body))
+		w.(http.Flusher).Flush()
+	}))
+	defer upstream.Close()
+
+	httpClient := NewHTTPClient(5*time.Second, nopLogger{})
+	service := &entity.Service{Name: "files", BaseURL: upstream.URL}
+	send := func(size int, declared bool, limit *entity.ResponseLimit) (*entity.Response, error) {
+		query := map[string][]string{"size": {strings.Repeat("1", size)}}
+		if declared {
+			query["declared"] = []string{"1"}
+		}
+		request := entity.NewRequest(http.MethodGet, "/files", map[string][]string{}, query, nil, "127.0.0.1")
+		request.ResponseLimit = limit
+		return httpClient.SendRequest(context.Background(), request, service)
+	}
+
+	// 2. Bodies within the limit are buffered
+	response, err := send(8, false, &entity.ResponseLimit{MaxSize: 8})
+	require.NoError(t, err)
+	assert.Equal(t, "xxxxxxxx", string(response.Body))
+	assert.Nil(t, response.BodyStream)
+
+	// 3. Larger ones are rejected with 502, whether or not their length is declared
+	for _, declared := range []bool{false, true} {
+		_, err = send(9, declared, &entity.ResponseLimit{MaxSize: 8})
+		assert.Equal(t, http.StatusBadGateway, errors.StatusCodeOf(err, 0))
+		assert.Equal(t, errors.ProblemResponseTooLarge, errors.ProblemTypeOf(err, http.StatusBadGateway))
+	}
+
+	// 4. Or streamed after the bytes read, which are none when the length is declared
+	for _, declared := range []bool{false, true} {
+		response, err = send(20, declared, &entity.ResponseLimit{MaxSize: 8, Policy: entity.ResponseLimitStream})
+		require.NoError(t, err)
+		require.NotNil(t, response.BodyStream)
+		rest, err := io.ReadAll(response.BodyStream)
+		require.NoError(t, err)
+		response.BodyStream.Close()
+		assert.Equal(t, strings.Repeat("x", 20), string(response.Body)+string(rest))
+		if declared {
+			assert.Empty(t, response.Body)
+		}
+	}
+}
@@ -0,0 +1,99 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync/atomic"
+
+	"api-gateway-sample/internal/domain/entity"
+)
+
+// Network describes how the gateway reaches the upstreams of services in an isolated network
+type Network struct {
+	// Proxy is the http, https or socks5 proxy requests are sent through, nil to connect directly
+	Proxy *url.URL
+	// Resolvers are the DNS servers, as host:port, that resolve upstream hosts, tried in turn;
+	// empty to use those of the system
+	Resolvers []string
+	// Hosts maps host names to the addresses they are reached at, without a lookup
+	Hosts map[string]string
+}
+
+// SetNetworks sends the requests to services naming one of the networks through its proxy,
+// resolvers and host overrides. Networks do not use the DNS cache.
+func (c *HTTPClient) SetNetworks(networks map[string]Network) {
+	c.networks = make(map[string]*http.Client, len(networks))
+	for name, network := range networks {
+		dialer := newNetworkDialer(network)
+		transport := newTransport(dialer.DialContext)
+		if network.Proxy != nil {
+			transport.Proxy = http.ProxyURL(network.Proxy)
+		}
+		c.networks[name] = &http.Client{Timeout: c.client.Timeout, Transport: transport}
+	}
+}
+
+// clientFor returns the client reaching the upstream of a service
+func (c *HTTPClient) clientFor(service *entity.Service) (*http.Client, error) {
+	if path, ok := socketPath(service.BaseURL); ok {
+		return c.socketClient(path), nil
+	}
+	if service.Network == "" {
+		return c.client, nil
+	}
+	client, ok := c.networks[service.Network]
+	if !ok {
+		return nil, fmt.Errorf("upstream network %s is not configured", service.Network)
+	}
+	return client, nil
+}
+
+// networkDialer connects to the hosts of a network, resolving them with its resolvers and overrides
+type networkDialer struct {
+	hosts  map[string]string
+	dialer net.Dialer
+}
+
+// newNetworkDialer creates a new networkDialer instance
+func newNetworkDialer(network Network) *networkDialer {
+	d := &networkDialer{hosts: make(map[string]string, len(network.Hosts))}
+	for host, addr := range network.Hosts {
+		d.hosts[strings.ToLower(host)] = addr
+	}
+	if len(network.Resolvers) > 0 {
+		resolvers := network.Resolvers
+		var next uint32
+		d.dialer.Resolver = &net.Resolver{
+			PreferGo: true,
+			// Each query goes to the next resolver that accepts a connection
+			Dial: func(ctx context.Context, protocol string, _ string) (net.Conn, error) {
+				var dialer net.Dialer
+				var err error
+				start := atomic.AddUint32(&next, 1)
+				for i := range resolvers {
+					var conn net.Conn
+					conn, err = dialer.DialContext(ctx, protocol, resolvers[(int(start)+i)%len(resolvers)])
+					if err == nil {
+						return conn, nil
+					}
+				}
+				return nil, err
+			},
+		}
+	}
+	return d
+}
+
+// DialContext connects to an address, replacing overridden host names with their address
+func (d *networkDialer) DialContext(ctx context.Context, network string, address string) (net.Conn, error) {
+	if host, port, err := net.SplitHostPort(address); err == nil {
+		if addr, ok := d.hosts[strings.ToLower(host)]; ok {
+			address = net.JoinHostPort(addr, port)
+		}
+	}
+	return d.dialer.DialContext(ctx, network, address)
+}
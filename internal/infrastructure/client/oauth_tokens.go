@@ -0,0 +1,167 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/sync/singleflight"
+)
+
+const (
+	// tokenExpiryMargin is how long before it expires a token is refreshed, so that it does not
+	// expire on the way to the upstream
+	tokenExpiryMargin = 30 * time.Second
+	// defaultTokenLifetime is how long tokens are cached whose response does not say when they expire
+	defaultTokenLifetime = time.Minute
+	// maxTokenResponseSize bounds the token responses read from identity providers
+	maxTokenResponseSize = 1 << 20
+)
+
+// OAuthClient is an OAuth 2.0 client that obtains access tokens with the client credentials grant
+type OAuthClient struct {
+	TokenURL     string
+	ClientID     string
+	ClientSecret string
+	Scopes       []string
+	// Audience is sent as the audience parameter some identity providers require, if set
+	Audience string
+}
+
+// cachedToken is an access token and when it must be refreshed
+type cachedToken struct {
+	accessToken string
+	refreshAt   time.Time
+}
+
+// OAuthTokenSource obtains the access tokens of named OAuth clients and caches each until shortly
+// before it expires. Requests needing the token of a client whose token is missing or expiring
+// share a single request to its identity provider, so that a burst of requests does not each
+// request a token when one expires.
+type OAuthTokenSource struct {
+	clients    map[string]OAuthClient
+	httpClient *http.Client
+	now        func() time.Time
+
+	mu     sync.RWMutex
+	tokens map[string]cachedToken
+	// refreshes runs a single token request per client at a time
+	refreshes singleflight.Group
+}
+
+// NewOAuthTokenSource creates a new OAuthTokenSource instance. Each token request is bounded by timeout.
+func NewOAuthTokenSource(clients map[string]OAuthClient, timeout time.Duration) *OAuthTokenSource {
+	return &OAuthTokenSource{
+		clients:    clients,
+		httpClient: &http.Client{Timeout: timeout},
+		now:        time.Now,
+		tokens:     make(map[string]cachedToken),
+	}
+}
+
+// Token returns an access token of the named client, requesting one from its identity provider
+// when none is cached or the cached one is expiring. Callers stop waiting for a shared request
+// when their context is done; the request itself goes on for the others.
+func (s *OAuthTokenSource) Token(ctx context.Context, name string) (string, error) {
+	s.mu.RLock()
+	token, ok := s.tokens[name]
+	s.mu.RUnlock()
+	if ok && s.now().Before(token.refreshAt) {
+		return token.accessToken, nil
+	}
+
+	client, ok := s.clients[name]
+	if !ok {
+		return "", fmt.Errorf("OAuth client %s is not configured", name)
+	}
+
+	result := s.refreshes.DoChan(name, func() (interface{}, error) {
+		return s.refresh(context.WithoutCancel(ctx), name, client)
+	})
+	select {
+	case <-ctx.Done():
+		return "", ctx.Err()
+	case res := <-result:
+		if res.Err != nil {
+			return "", res.Err
+		}
+		return res.Val.(string), nil
+	}
+}
+
+// Invalidate drops the cached token of the named client, e.g. once an upstream rejected it
+func (s *OAuthTokenSource) Invalidate(name string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.tokens, name)
+}
+
+// refresh requests a new access token of a client and caches it
+func (s *OAuthTokenSource) refresh(ctx context.Context, name string, client OAuthClient) (string, error) {
+	// A request that waited for another's refresh may find the token already renewed
+	s.mu.RLock()
+	token, ok := s.tokens[name]
+	s.mu.RUnlock()
+	if ok && s.now().Before(token.refreshAt) {
+		return token.accessToken, nil
+	}
+
+	form := url.Values{"grant_type": {"client_credentials"}}
+	if len(client.Scopes) > 0 {
+		form.Set("scope", strings.Join(client.Scopes, " "))
+	}
+	if client.Audience != "" {
+		form.Set("audience", client.Audience)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, client.TokenURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return "", fmt.Errorf("failed to create token request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+	req.SetBasicAuth(url.QueryEscape(client.ClientID), url.QueryEscape(client.ClientSecret))
+
+	requestedAt := s.now()
+	resp, err := s.httpClient.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("token request for OAuth client %s failed: %w", name, err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxTokenResponseSize))
+	if err != nil {
+		return "", fmt.Errorf("failed to read token response for OAuth client %s: %w", name, err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("token request for OAuth client %s failed with status %d", name, resp.StatusCode)
+	}
+	var tokenResponse struct {
+		AccessToken string `json:"access_token"`
+		ExpiresIn   int64  `json:"expires_in"`
+	}
+	if err := json.Unmarshal(body, &tokenResponse); err != nil || tokenResponse.AccessToken == "" {
+		return "", fmt.Errorf("invalid token response for OAuth client %s", name)
+	}
+
+	// Expiry is counted from the request, as the token may have been issued any time after it
+	lifetime := defaultTokenLifetime
+	if tokenResponse.ExpiresIn > 0 {
+		lifetime = time.Duration(tokenResponse.ExpiresIn) * time.Second
+	}
+	if lifetime > 2*tokenExpiryMargin {
+		lifetime -= tokenExpiryMargin
+	} else {
+		lifetime /= 2
+	}
+
+	s.mu.Lock()
+	s.tokens[name] = cachedToken{accessToken: tokenResponse.AccessToken, refreshAt: requestedAt.Add(lifetime)}
+	s.mu.Unlock()
+	return tokenResponse.AccessToken, nil
+}
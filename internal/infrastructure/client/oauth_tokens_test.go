@@ -0,0 +1,109 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"api-gateway-sample/internal/domain/entity"
+)
+
+func TestOAuthTokenSource_CoalescesRefreshes(t *testing.T) {
+	var requests atomic.Int32
+	release := make(chan struct{})
+	idp := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := requests.Add(1)
+		clientID, secret, _ := r.BasicAuth()
+		assert.Equal(t, "gateway", clientID)
+		assert.Equal(t, "secret", secret)
+		assert.Equal(t, "client_credentials", r.FormValue("grant_type"))
+		assert.Equal(t, "billing.read billing.write", r.FormValue("scope"))
+		<-release
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprintf(w, `{"access_token": "token-%d", "token_type": "Bearer", "expires_in": 3600}`, n)
+	}))
+	defer idp.Close()
+
+	source := NewOAuthTokenSource(map[string]OAuthClient{
+		"billing": {TokenURL: idp.URL, ClientID: "gateway", ClientSecret: "secret", Scopes: []string{"billing.read", "billing.write"}},
+	}, 5*time.Second)
+	now := time.Now()
+	source.now = func() time.Time { return now }
+
+	// 1. Concurrent requests without a token share a single token request
+	var wg sync.WaitGroup
+	tokens := make([]string, 50)
+	for i := range tokens {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			token, err := source.Token(context.Background(), "billing")
+			assert.NoError(t, err)
+			tokens[i] = token
+		}(i)
+	}
+	time.Sleep(50 * time.Millisecond)
+	close(release)
+	wg.Wait()
+	assert.Equal(t, int32(1), requests.Load())
+	for _, token := range tokens {
+		assert.Equal(t, "token-1", token)
+	}
+
+	// 2. The token is cached until shortly before it expires
+	now = now.Add(59 * time.Minute)
+	token, err := source.Token(context.Background(), "billing")
+	require.NoError(t, err)
+	assert.Equal(t, "token-1", token)
+	assert.Equal(t, int32(1), requests.Load())
+
+	now = now.Add(40 * time.Second)
+	token, err = source.Token(context.Background(), "billing")
+	require.NoError(t, err)
+	assert.Equal(t, "token-2", token)
+
+	// 3. Unknown clients have no token
+	_, err = source.Token(context.Background(), "unknown")
+	assert.Error(t, err)
+}
+
+func TestOAuthTokenSource_FailedRequest(t *testing.T) {
+	idp := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, `{"error": "invalid_client"}`, http.StatusUnauthorized)
+	}))
+	defer idp.Close()
+
+	source := NewOAuthTokenSource(map[string]OAuthClient{"billing": {TokenURL: idp.URL, ClientID: "gateway"}}, 5*time.Second)
+	_, err := source.Token(context.Background(), "billing")
+	assert.ErrorContains(t, err, "status 401")
+}
+
+func TestRequestSigner_OAuth2(t *testing.T) {
+	idp := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"access_token": "upstream-token", "expires_in": 300}`))
+	}))
+	defer idp.Close()
+
+	signer := NewRequestSigner(nil)
+	signer.SetOAuthTokenSource(NewOAuthTokenSource(map[string]OAuthClient{"billing": {TokenURL: idp.URL, ClientID: "gateway"}}, 5*time.Second))
+	service := &entity.Service{
+		BaseURL: "https://billing.internal",
+		Signing: &entity.UpstreamSigning{Scheme: entity.SigningOAuth2, Credentials: "billing"},
+	}
+
+	// The caller's Authorization header is replaced on a copy
+	headers := map[string][]string{"authorization": {"Bearer client-token"}}
+	request := entity.NewRequest(http.MethodGet, "/invoices", headers, nil, nil, "127.0.0.1")
+	require.NoError(t, signer.Sign(context.Background(), request, service))
+	assert.Equal(t, []string{"Bearer upstream-token"}, request.Headers["Authorization"])
+	assert.NotContains(t, request.Headers, "authorization")
+	assert.Equal(t, []string{"Bearer client-token"}, headers["authorization"])
+}
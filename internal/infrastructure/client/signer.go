@@ -0,0 +1,176 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"api-gateway-sample/internal/domain/entity"
+	"api-gateway-sample/pkg/sigv4"
+)
+
+// Headers of requests signed with the HMAC scheme
+const (
+	HeaderSignature          = "X-Signature"
+	HeaderSignatureTimestamp = "X-Signature-Timestamp"
+	HeaderSignatureKeyID     = "X-Signature-Key-Id"
+)
+
+// awsSignatureHeaders are the headers set by SigV4 signing that are copied onto the request
+var awsSignatureHeaders = []string{"Authorization", "X-Amz-Date", "X-Amz-Content-Sha256", "X-Amz-Security-Token"}
+
+// RequestSigner implements the RequestSigner interface with AWS Signature Version 4, an
+// HMAC-SHA256 scheme and OAuth 2.0 bearer tokens, using credentials configured on the gateway by name
+type RequestSigner struct {
+	credentials map[string]sigv4.Credentials
+	// tokens obtains the access tokens of the oauth2 scheme, nil when no OAuth client is configured
+	tokens *OAuthTokenSource
+	now    func() time.Time
+}
+
+// NewRequestSigner creates a new RequestSigner instance. For the HMAC scheme, the secret access key
+// is the shared key and the access key ID, if any, is sent as the key ID.
+func NewRequestSigner(credentials map[string]sigv4.Credentials) *RequestSigner {
+	return &RequestSigner{
+		credentials: credentials,
+		now:         time.Now,
+	}
+}
+
+// SetOAuthTokenSource sends the access tokens of the given source to services with the oauth2 scheme
+func (s *RequestSigner) SetOAuthTokenSource(tokens *OAuthTokenSource) {
+	s.tokens = tokens
+}
+
+// Sign adds the signature headers of the service's signing scheme to a request
+func (s *RequestSigner) Sign(ctx context.Context, request *entity.Request, service *entity.Service) error {
+	signing := service.Signing
+	if signing.Scheme == entity.SigningOAuth2 {
+		return s.authorize(ctx, request, signing.Credentials)
+	}
+	creds, ok := s.credentials[signing.Credentials]
+	if !ok {
+		return fmt.Errorf("signing credentials %s are not configured", signing.Credentials)
+	}
+
+	target, err := upstreamURL(service, request)
+	if err != nil {
+		return fmt.Errorf("invalid upstream URL: %w", err)
+	}
+
+	// Signature headers replace the caller's, such as its Authorization header, on a copy so
+	// that the caller's request is left untouched
+	headers := make(map[string][]string, len(request.Headers)+4)
+	for name, values := range request.Headers {
+		headers[name] = values
+	}
+	request.Headers = headers
+
+	switch signing.Scheme {
+	case entity.SigningAWSSigV4:
+		// Only the host, content type and signature headers are signed, so that headers added
+		// on the way to the upstream, such as X-Forwarded-For, cannot invalidate the signature
+		signed, err := http.NewRequestWithContext(ctx, request.Method, target.String(), bytes.NewReader(request.Body))
+		if err != nil {
+			return fmt.Errorf("failed to create request: %w", err)
+		}
+		if contentType := http.Header(request.Headers).Get("Content-Type"); contentType != "" {
+			signed.Header.Set("Content-Type", contentType)
+		}
+		sigv4.Sign(signed, request.Body, creds, signing.Region, signing.Service, s.now())
+		for _, name := range awsSignatureHeaders {
+			deleteHeader(request.Headers, name)
+			if value := signed.Header.Get(name); value != "" {
+				request.Headers[name] = []string{value}
+			}
+		}
+	case entity.SigningHMAC:
+		timestamp := strconv.FormatInt(s.now().Unix(), 10)
+		bodyHash := sha256.Sum256(request.Body)
+		stringToSign := strings.Join([]string{
+			request.Method,
+			target.EscapedPath(),
+			target.RawQuery,
+			timestamp,
+			hex.EncodeToString(bodyHash[:]),
+		}, "\n")
+		mac := hmac.New(sha256.New, []byte(creds.SecretAccessKey))
+		mac.Write([]byte(stringToSign))
+
+		deleteHeader(request.Headers, HeaderSignatureKeyID)
+		if creds.AccessKeyID != "" {
+			request.Headers[HeaderSignatureKeyID] = []string{creds.AccessKeyID}
+		}
+		deleteHeader(request.Headers, HeaderSignatureTimestamp)
+		deleteHeader(request.Headers, HeaderSignature)
+		request.Headers[HeaderSignatureTimestamp] = []string{timestamp}
+		request.Headers[HeaderSignature] = []string{hex.EncodeToString(mac.Sum(nil))}
+	default:
+		return fmt.Errorf("unsupported signing scheme: %s", signing.Scheme)
+	}
+	return nil
+}
+
+// authorize replaces the Authorization header of a request with an access token of the named
+// OAuth client, on a copy of the headers so that the caller's request is left untouched
+func (s *RequestSigner) authorize(ctx context.Context, request *entity.Request, client string) error {
+	if s.tokens == nil {
+		return fmt.Errorf("OAuth client %s is not configured", client)
+	}
+	token, err := s.tokens.Token(ctx, client)
+	if err != nil {
+		return err
+	}
+
+	headers := make(map[string][]string, len(request.Headers)+1)
+	for name, values := range request.Headers {
+		headers[name] = values
+	}
+	deleteHeader(headers, "Authorization")
+	headers["Authorization"] = []string{"Bearer " + token}
+	request.Headers = headers
+	return nil
+}
+
+// upstreamURL returns the URL a request is sent to: the request path below the base URL of the
+// service, with the query parameters of both escaped. Requests to unix sockets are sent to
+// localhost below the upstream path of the service.
+func upstreamURL(service *entity.Service, request *entity.Request) (*url.URL, error) {
+	target, err := url.Parse(service.BaseURL)
+	if err != nil {
+		return nil, err
+	}
+	if target.Scheme == entity.UnixScheme {
+		target = &url.URL{Scheme: "http", Host: "localhost", Path: service.UpstreamPath, RawQuery: target.RawQuery}
+	}
+	query := target.Query()
+	for name, values := range request.QueryParams {
+		query[name] = append(query[name], values...)
+	}
+	target.Path = strings.TrimSuffix(target.Path, "/") + request.Path
+	target.RawPath = ""
+	target.RawQuery = encodeQuery(query)
+	return target, nil
+}
+
+// encodeQuery encodes query parameters sorted by name, with spaces as %20 as signatures expect
+func encodeQuery(query map[string][]string) string {
+	return strings.ReplaceAll(url.Values(query).Encode(), "+", "%20")
+}
+
+// deleteHeader removes a header whatever the case of its name
+func deleteHeader(headers map[string][]string, name string) {
+	for key := range headers {
+		if strings.EqualFold(key, name) {
+			delete(headers, key)
+		}
+	}
+}
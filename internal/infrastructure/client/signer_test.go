@@ -0,0 +1,112 @@
+package client
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"api-gateway-sample/internal/domain/entity"
+	"api-gateway-sample/pkg/sigv4"
+)
+
+func TestRequestSigner_HMAC(t *testing.T) {
+	signer := NewRequestSigner(map[string]sigv4.Credentials{
+		"partner": {AccessKeyID: "gateway", SecretAccessKey: "shared-secret"},
+	})
+	signer.now = func() time.Time { return time.Unix(1700000000, 0) }
+	service := &entity.Service{
+		BaseURL: "https://partner.example.com",
+		Signing: &entity.UpstreamSigning{Scheme: entity.SigningHMAC, Credentials: "partner"},
+	}
+
+	// 1. The signature covers the method, path, sorted query, timestamp and body hash
+	headers := map[string][]string{"X-Signature": {"forged"}}
+	request := entity.NewRequest(http.MethodPost, "/orders", headers, map[string][]string{"b": {"two words"}, "a": {"1"}}, []byte(`{"id":1}`), "127.0.0.1")
+	require.NoError(t, signer.Sign(context.Background(), request, service))
+
+	bodyHash := sha256.Sum256([]byte(`{"id":1}`))
+	mac := hmac.New(sha256.New, []byte("shared-secret"))
+	mac.Write([]byte("POST\n/orders\na=1&b=two%20words\n1700000000\n" + hex.EncodeToString(bodyHash[:])))
+	assert.Equal(t, []string{hex.EncodeToString(mac.Sum(nil))}, request.Headers["X-Signature"])
+	assert.Equal(t, []string{"1700000000"}, request.Headers["X-Signature-Timestamp"])
+	assert.Equal(t, []string{"gateway"}, request.Headers["X-Signature-Key-Id"])
+
+	// 2. The caller's headers are left untouched
+	assert.Equal(t, []string{"forged"}, headers["X-Signature"])
+
+	// 3. Services referencing unknown credentials cannot be signed
+	service.Signing.Credentials = "unknown"
+	assert.Error(t, signer.Sign(context.Background(), request, service))
+}
+
+func TestRequestSigner_AWSSigV4(t *testing.T) {
+	signer := NewRequestSigner(map[string]sigv4.Credentials{
+		"lambda": {AccessKeyID: "AKIDEXAMPLE", SecretAccessKey: "secret", SessionToken: "token"},
+	})
+	signer.now = func() time.Time { return time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC) }
+	service := &entity.Service{
+		BaseURL: "https://abc.lambda-url.eu-west-1.on.aws",
+		Signing: &entity.UpstreamSigning{Scheme: entity.SigningAWSSigV4, Credentials: "lambda", Region: "eu-west-1", Service: "lambda"},
+	}
+
+	// 1. The caller's Authorization header is replaced by the SigV4 signature
+	request := entity.NewRequest(http.MethodGet, "/reports", map[string][]string{"Authorization": {"Bearer client-token"}, "X-Request-Id": {"abc"}}, nil, nil, "127.0.0.1")
+	require.NoError(t, signer.Sign(context.Background(), request, service))
+
+	authorization := http.Header(request.Headers).Get("Authorization")
+	assert.Contains(t, authorization, "AWS4-HMAC-SHA256 Credential=AKIDEXAMPLE/20240301/eu-west-1/lambda/aws4_request")
+	assert.Equal(t, "20240301T120000Z", http.Header(request.Headers).Get("X-Amz-Date"))
+	assert.Equal(t, "token", http.Header(request.Headers).Get("X-Amz-Security-Token"))
+
+	// 2. Headers added on the way to the upstream are not signed
+	assert.NotContains(t, authorization, "x-request-id")
+	assert.Equal(t, "abc", http.Header(request.Headers).Get("X-Request-Id"))
+}
+
+func FuzzUpstreamURL(f *testing.F) {
+	f.Add("http://orders:8080", "/api/v1/orders", "page=2&sort=desc")
+	f.Add("http://orders:8080/v2/?key=1", "/api/v1/orders/42", "key=2&q=a+b")
+	f.Add("https://orders.example.com/base/", "/a%2Fb/c", "q=%zz&x")
+	f.Add("unix:///run/orders.sock?tenant=1", "/orders", "=&&=x")
+	f.Add("http://[::1]:80", "/\x00\xff", "a;b=c")
+	f.Add("::", "/", "")
+
+	f.Fuzz(func(t *testing.T, baseURL string, path string, rawQuery string) {
+		// The router only hands the proxy absolute paths
+		if !strings.HasPrefix(path, "/") {
+			path = "/" + path
+		}
+		query, _ := url.ParseQuery(rawQuery)
+		service := &entity.Service{BaseURL: baseURL, UpstreamPath: "/upstream"}
+		request := &entity.Request{Method: http.MethodGet, Path: path, QueryParams: query}
+
+		target, err := upstreamURL(service, request)
+		if err != nil {
+			return
+		}
+		if !strings.HasSuffix(target.Path, path) {
+			t.Fatalf("Expected the path of %s to end with %q, got %q", target, path, target.Path)
+		}
+
+		// The query carries the parameters of the base URL and then those of the request
+		base, _ := url.Parse(baseURL)
+		expected := base.Query()
+		for name, values := range query {
+			expected[name] = append(expected[name], values...)
+		}
+		sent, err := url.ParseQuery(target.RawQuery)
+		if err != nil {
+			t.Fatalf("Failed to parse the query %q: %v", target.RawQuery, err)
+		}
+		assert.Equal(t, url.Values(expected), sent)
+	})
+}
@@ -0,0 +1,40 @@
+package client
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/url"
+
+	"api-gateway-sample/internal/domain/entity"
+)
+
+// socketPath returns the path of the unix socket a base URL names, if it is a unix:// URL
+func socketPath(baseURL string) (string, bool) {
+	u, err := url.Parse(baseURL)
+	if err != nil || u.Scheme != entity.UnixScheme {
+		return "", false
+	}
+	return u.Path, true
+}
+
+// socketClient returns the client sending requests over the unix socket at path, creating it on
+// first use so that each socket has its own connection pool
+func (c *HTTPClient) socketClient(path string) *http.Client {
+	c.socketsMu.Lock()
+	defer c.socketsMu.Unlock()
+
+	if client, ok := c.sockets[path]; ok {
+		return client
+	}
+	if c.sockets == nil {
+		c.sockets = make(map[string]*http.Client)
+	}
+	transport := newTransport(func(ctx context.Context, _ string, _ string) (net.Conn, error) {
+		var dialer net.Dialer
+		return dialer.DialContext(ctx, "unix", path)
+	})
+	client := &http.Client{Timeout: c.client.Timeout, Transport: transport}
+	c.sockets[path] = client
+	return client
+}
@@ -0,0 +1,120 @@
+package election
+
+import (
+	"context"
+	"database/sql"
+	"hash/fnv"
+	"sync"
+	"time"
+
+	"api-gateway-sample/pkg/logger"
+)
+
+// PostgresElector elects a leader with a session-level Postgres advisory lock. The leader keeps
+// a dedicated connection holding the lock; if the connection or the instance dies, Postgres
+// releases the lock and another instance acquires it on its next campaign.
+type PostgresElector struct {
+	db            *sql.DB
+	key           string
+	lockID        int64
+	renewInterval time.Duration
+	logger        logger.Logger
+
+	mu   sync.Mutex
+	conn *sql.Conn
+}
+
+// NewPostgresElector creates a new PostgresElector instance. The advisory lock is derived from
+// the key, so gateway deployments sharing a database elect separate leaders with separate keys.
+func NewPostgresElector(db *sql.DB, key string, renewInterval time.Duration, logger logger.Logger) *PostgresElector {
+	hash := fnv.New64a()
+	hash.Write([]byte(key))
+	return &PostgresElector{
+		db:            db,
+		key:           key,
+		lockID:        int64(hash.Sum64()),
+		renewInterval: renewInterval,
+		logger:        logger,
+	}
+}
+
+// Start campaigns for the leadership on the renew interval until the context is cancelled,
+// then releases the lock if this instance holds it
+func (e *PostgresElector) Start(ctx context.Context) {
+	e.Campaign(ctx)
+
+	go func() {
+		ticker := time.NewTicker(e.renewInterval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				e.release()
+				return
+			case <-ticker.C:
+				e.Campaign(ctx)
+			}
+		}
+	}()
+}
+
+// IsLeader reports whether this instance holds the lock
+func (e *PostgresElector) IsLeader() bool {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return e.conn != nil
+}
+
+// Campaign checks that the leader's connection still holds the lock, or tries to acquire it otherwise
+func (e *PostgresElector) Campaign(ctx context.Context) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	callCtx, cancel := context.WithTimeout(ctx, e.renewInterval)
+	defer cancel()
+
+	if e.conn != nil {
+		err := e.conn.PingContext(callCtx)
+		if err == nil {
+			return
+		}
+		e.logger.Warn("Lost leadership", "key", e.key, "error", err)
+		e.conn.Close()
+		e.conn = nil
+	}
+
+	conn, err := e.db.Conn(callCtx)
+	if err != nil {
+		e.logger.Warn("Leader election failed", "key", e.key, "error", err)
+		return
+	}
+	var acquired bool
+	if err := conn.QueryRowContext(callCtx, "SELECT pg_try_advisory_lock($1)", e.lockID).Scan(&acquired); err != nil || !acquired {
+		if err != nil {
+			e.logger.Warn("Leader election failed", "key", e.key, "error", err)
+		}
+		conn.Close()
+		return
+	}
+
+	e.conn = conn
+	e.logger.Info("Acquired leadership", "key", e.key)
+}
+
+// release unlocks the advisory lock so another instance can take over at once
+func (e *PostgresElector) release() {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if e.conn == nil {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), e.renewInterval)
+	defer cancel()
+	if _, err := e.conn.ExecContext(ctx, "SELECT pg_advisory_unlock($1)", e.lockID); err != nil {
+		e.logger.Warn("Failed to release leadership", "key", e.key, "error", err)
+	}
+	e.conn.Close()
+	e.conn = nil
+}
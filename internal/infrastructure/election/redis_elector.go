@@ -0,0 +1,147 @@
+package election
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"sync"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+
+	"api-gateway-sample/pkg/logger"
+)
+
+// renewScript extends the lease if this instance still holds it
+var renewScript = redis.NewScript(`
+if redis.call("GET", KEYS[1]) == ARGV[1] then
+	return redis.call("PEXPIRE", KEYS[1], ARGV[2])
+end
+return 0
+`)
+
+// releaseScript deletes the lease if this instance still holds it
+var releaseScript = redis.NewScript(`
+if redis.call("GET", KEYS[1]) == ARGV[1] then
+	return redis.call("DEL", KEYS[1])
+end
+return 0
+`)
+
+// RedisElector elects a leader with a lease key in Redis. The leader renews the lease; when it
+// stops, for example because it crashed, another instance acquires the key once it expires.
+// The leader steps down when its lease runs out locally, even if Redis cannot be reached to
+// tell, so two instances never lead at once while their clocks agree.
+type RedisElector struct {
+	client        redis.UniversalClient
+	key           string
+	id            string
+	lease         time.Duration
+	renewInterval time.Duration
+	logger        logger.Logger
+
+	mu         sync.Mutex
+	leaseUntil time.Time
+	leading    bool
+}
+
+// NewRedisElector creates a new RedisElector instance. The lease must be longer than the
+// renew interval, so the leader renews it before it expires.
+func NewRedisElector(client redis.UniversalClient, key string, lease time.Duration, renewInterval time.Duration, logger logger.Logger) *RedisElector {
+	id := make([]byte, 8)
+	rand.Read(id)
+	return &RedisElector{
+		client:        client,
+		key:           key,
+		id:            hex.EncodeToString(id),
+		lease:         lease,
+		renewInterval: renewInterval,
+		logger:        logger,
+	}
+}
+
+// Start campaigns for the leadership on the renew interval until the context is cancelled,
+// then releases the lease if this instance holds it
+func (e *RedisElector) Start(ctx context.Context) {
+	e.Campaign(ctx)
+
+	go func() {
+		ticker := time.NewTicker(e.renewInterval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				e.release()
+				return
+			case <-ticker.C:
+				e.Campaign(ctx)
+			}
+		}
+	}()
+}
+
+// IsLeader reports whether this instance holds an unexpired lease
+func (e *RedisElector) IsLeader() bool {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return time.Now().Before(e.leaseUntil)
+}
+
+// Campaign renews the lease if this instance leads, or tries to acquire it otherwise
+func (e *RedisElector) Campaign(ctx context.Context) {
+	// The lease is counted from before the call, so it never outlasts the key in Redis
+	start := time.Now()
+	callCtx, cancel := context.WithTimeout(ctx, e.renewInterval)
+	defer cancel()
+
+	var held bool
+	var err error
+	if e.IsLeader() {
+		var renewed int
+		renewed, err = renewScript.Run(callCtx, e.client, []string{e.key}, e.id, e.lease.Milliseconds()).Int()
+		held = renewed == 1
+	} else {
+		held, err = e.client.SetNX(callCtx, e.key, e.id, e.lease).Result()
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	switch {
+	case err != nil:
+		// Keep leading until the lease runs out; the next campaign may reach Redis again
+		e.logger.Warn("Leader election failed", "key", e.key, "error", err)
+	case held:
+		e.leaseUntil = start.Add(e.lease)
+	default:
+		e.leaseUntil = time.Time{}
+	}
+
+	leading := time.Now().Before(e.leaseUntil)
+	if leading != e.leading {
+		e.leading = leading
+		if leading {
+			e.logger.Info("Acquired leadership", "key", e.key, "instance", e.id)
+		} else {
+			e.logger.Warn("Lost leadership", "key", e.key, "instance", e.id)
+		}
+	}
+}
+
+// release gives up the lease so another instance can take over without waiting for it to expire
+func (e *RedisElector) release() {
+	if !e.IsLeader() {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), e.renewInterval)
+	defer cancel()
+	if err := releaseScript.Run(ctx, e.client, []string{e.key}, e.id).Err(); err != nil {
+		e.logger.Warn("Failed to release leadership", "key", e.key, "error", err)
+	}
+
+	e.mu.Lock()
+	e.leaseUntil = time.Time{}
+	e.leading = false
+	e.mu.Unlock()
+}
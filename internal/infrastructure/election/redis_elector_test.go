@@ -0,0 +1,82 @@
+package election
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// nopLogger discards all log output
+type nopLogger struct{}
+
+func (nopLogger) Debug(msg string, keysAndValues ...interface{}) {}
+func (nopLogger) Info(msg string, keysAndValues ...interface{})  {}
+func (nopLogger) Warn(msg string, keysAndValues ...interface{})  {}
+func (nopLogger) Error(msg string, keysAndValues ...interface{}) {}
+func (nopLogger) Fatal(msg string, keysAndValues ...interface{}) {}
+
+func TestRedisElector(t *testing.T) {
+	server := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: server.Addr(), MaxRetries: -1})
+	t.Cleanup(func() { client.Close() })
+	ctx := context.Background()
+
+	first := NewRedisElector(client, "api-gateway:leader", time.Minute, time.Second, nopLogger{})
+	second := NewRedisElector(client, "api-gateway:leader", time.Minute, time.Second, nopLogger{})
+
+	// 1. The first instance to campaign leads and keeps leading when it renews
+	first.Campaign(ctx)
+	second.Campaign(ctx)
+	assert.True(t, first.IsLeader())
+	assert.False(t, second.IsLeader())
+
+	first.Campaign(ctx)
+	assert.True(t, first.IsLeader())
+	assert.Equal(t, time.Minute, server.TTL("api-gateway:leader"))
+
+	// 2. Another instance takes over once the lease of a stopped leader expires
+	server.FastForward(2 * time.Minute)
+	second.Campaign(ctx)
+	assert.True(t, second.IsLeader())
+
+	// 3. The former leader steps down when it finds the lease taken
+	first.Campaign(ctx)
+	assert.False(t, first.IsLeader())
+}
+
+func TestRedisElector_ReleasesOnStop(t *testing.T) {
+	server := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: server.Addr(), MaxRetries: -1})
+	t.Cleanup(func() { client.Close() })
+
+	ctx, cancel := context.WithCancel(context.Background())
+	elector := NewRedisElector(client, "api-gateway:leader", time.Minute, time.Second, nopLogger{})
+	elector.Start(ctx)
+	require.True(t, elector.IsLeader())
+
+	// A stopped leader deletes the lease so another instance does not wait for it to expire
+	cancel()
+	assert.Eventually(t, func() bool { return !server.Exists("api-gateway:leader") }, time.Second, 10*time.Millisecond)
+	assert.False(t, elector.IsLeader())
+}
+
+func TestRedisElector_StepsDownWhenLeaseRunsOut(t *testing.T) {
+	server := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: server.Addr(), MaxRetries: -1})
+	t.Cleanup(func() { client.Close() })
+	ctx := context.Background()
+
+	elector := NewRedisElector(client, "api-gateway:leader", 50*time.Millisecond, 10*time.Millisecond, nopLogger{})
+	elector.Campaign(ctx)
+	require.True(t, elector.IsLeader())
+
+	// Without Redis the lease cannot be renewed, and the leader steps down when it runs out
+	server.Close()
+	elector.Campaign(ctx)
+	assert.Eventually(t, func() bool { return !elector.IsLeader() }, time.Second, 10*time.Millisecond)
+}
@@ -0,0 +1,84 @@
+package encryption
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"api-gateway-sample/pkg/errors"
+	"api-gateway-sample/pkg/jwe"
+)
+
+// KeyLookup returns the value of a secret by name, such as secrets.Manager.Get
+type KeyLookup func(name string) (string, bool)
+
+// PayloadCipher implements the PayloadCipher interface with JWE compact serializations, using
+// keys held as secrets. Keys are parsed again when their secret is rotated.
+type PayloadCipher struct {
+	lookup KeyLookup
+
+	mu   sync.Mutex
+	keys map[string]parsedKey
+}
+
+// parsedKey is a key parsed from the value of its secret
+type parsedKey struct {
+	value string
+	key   *jwe.Key
+}
+
+// NewPayloadCipher creates a new PayloadCipher instance
+func NewPayloadCipher(lookup KeyLookup) *PayloadCipher {
+	return &PayloadCipher{
+		lookup: lookup,
+		keys:   make(map[string]parsedKey),
+	}
+}
+
+// Decrypt returns the content of a JWE encrypted for the key and its content type
+func (c *PayloadCipher) Decrypt(ctx context.Context, name string, body []byte) ([]byte, string, error) {
+	key, err := c.key(name)
+	if err != nil {
+		return nil, "", err
+	}
+	content, header, err := jwe.Decrypt(string(body), key)
+	if err != nil {
+		return nil, "", err
+	}
+	return content, header.ContentType, nil
+}
+
+// Encrypt returns a JWE of content encrypted for the key
+func (c *PayloadCipher) Encrypt(ctx context.Context, name string, content []byte, contentType string) ([]byte, error) {
+	key, err := c.key(name)
+	if err != nil {
+		return nil, err
+	}
+	token, err := jwe.Encrypt(content, key, contentType)
+	if err != nil {
+		return nil, err
+	}
+	return []byte(token), nil
+}
+
+// key returns the parsed key held by a secret. Missing and invalid keys are reported as
+// unavailable, since they are configuration problems rather than problems of the payload.
+func (c *PayloadCipher) key(name string) (*jwe.Key, error) {
+	value, ok := c.lookup(name)
+	if !ok {
+		return nil, errors.NewError(errors.CodeServiceUnavailable, fmt.Sprintf("encryption key %s is not configured", name), errors.ErrServiceUnavailable)
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if cached, ok := c.keys[name]; ok && cached.value == value {
+		return cached.key, nil
+	}
+	key, err := jwe.ParseKey(value)
+	if err != nil {
+		return nil, errors.NewError(errors.CodeServiceUnavailable, fmt.Sprintf("invalid encryption key %s: %v", name, err), errors.ErrServiceUnavailable)
+	}
+	key.ID = name
+	c.keys[name] = parsedKey{value: value, key: key}
+	return key, nil
+}
@@ -0,0 +1,75 @@
+package encryption
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"api-gateway-sample/pkg/errors"
+)
+
+func TestPayloadCipher_SharedKey(t *testing.T) {
+	ctx := context.Background()
+	secrets := map[string]string{"orders_key": base64.StdEncoding.EncodeToString(make([]byte, 32))}
+	cipher := NewPayloadCipher(func(name string) (string, bool) {
+		value, ok := secrets[name]
+		return value, ok
+	})
+
+	// 1. Content encrypted with a key is decrypted with it, along with its content type
+	token, err := cipher.Encrypt(ctx, "orders_key", []byte(`{"id":1}`), "application/json")
+	require.NoError(t, err)
+	content, contentType, err := cipher.Decrypt(ctx, "orders_key", token)
+	require.NoError(t, err)
+	assert.Equal(t, `{"id":1}`, string(content))
+	assert.Equal(t, "application/json", contentType)
+
+	// 2. Rotated keys are picked up, and no longer decrypt content encrypted with the old key
+	rotated := make([]byte, 32)
+	rotated[0] = 1
+	secrets["orders_key"] = base64.StdEncoding.EncodeToString(rotated)
+	_, _, err = cipher.Decrypt(ctx, "orders_key", token)
+	require.Error(t, err)
+	assert.False(t, errors.IsServiceUnavailable(err))
+
+	// 3. Missing and invalid keys are reported as unavailable
+	_, err = cipher.Encrypt(ctx, "payments_key", []byte("{}"), "application/json")
+	assert.True(t, errors.IsServiceUnavailable(err))
+	secrets["orders_key"] = "not a key"
+	_, _, err = cipher.Decrypt(ctx, "orders_key", token)
+	assert.True(t, errors.IsServiceUnavailable(err))
+}
+
+func TestPayloadCipher_RSAKeys(t *testing.T) {
+	ctx := context.Background()
+	private, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	public, err := x509.MarshalPKIXPublicKey(&private.PublicKey)
+	require.NoError(t, err)
+	secrets := map[string]string{
+		"gateway_private_key": string(pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(private)})),
+		"client_public_key":   string(pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: public})),
+	}
+	cipher := NewPayloadCipher(func(name string) (string, bool) {
+		value, ok := secrets[name]
+		return value, ok
+	})
+
+	// Content encrypted for a public key is decrypted with its private key only
+	token, err := cipher.Encrypt(ctx, "client_public_key", []byte("<order/>"), "application/xml")
+	require.NoError(t, err)
+	content, contentType, err := cipher.Decrypt(ctx, "gateway_private_key", token)
+	require.NoError(t, err)
+	assert.Equal(t, "<order/>", string(content))
+	assert.Equal(t, "application/xml", contentType)
+
+	_, _, err = cipher.Decrypt(ctx, "client_public_key", token)
+	assert.Error(t, err)
+}
@@ -0,0 +1,251 @@
+// Package errorreport sends recovered panics to error trackers
+package errorreport
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"api-gateway-sample/internal/domain/entity"
+	"api-gateway-sample/internal/infrastructure/export"
+	"api-gateway-sample/pkg/logger"
+)
+
+// Delivery settings of the reports, which are buffered and retried behind a circuit breaker as
+// described by export.NewExporter
+const (
+	sentryMaxRetries       = 3
+	sentryFailureThreshold = 3
+	sentryRetryCooldown    = time.Minute
+)
+
+// SentryReporter implements the ErrorReporter interface for Sentry and the trackers compatible
+// with its store API, such as GlitchTip. Reports are sent in the background, so a slow or
+// unavailable tracker never holds up the request that panicked.
+type SentryReporter struct {
+	storeURL    string
+	auth        string
+	environment string
+	release     string
+	serverName  string
+	client      *http.Client
+	exporter    *export.Exporter
+}
+
+// NewSentryReporter creates a new SentryReporter instance for a DSN such as
+// https://<key>@o0.ingest.sentry.io/<project>. At most bufferSize reports wait to be sent.
+func NewSentryReporter(dsn string, environment string, release string, timeout time.Duration, bufferSize int, logger logger.Logger) (*SentryReporter, error) {
+	storeURL, auth, err := parseDSN(dsn)
+	if err != nil {
+		return nil, err
+	}
+	serverName, _ := os.Hostname()
+
+	r := &SentryReporter{
+		storeURL:    storeURL,
+		auth:        auth,
+		environment: environment,
+		release:     release,
+		serverName:  serverName,
+		client:      &http.Client{Timeout: timeout},
+	}
+	send := func(ctx context.Context, item interface{}) error {
+		return r.send(ctx, item.(*entity.PanicReport))
+	}
+	r.exporter = export.NewExporter("errors.sentry", send, bufferSize, sentryMaxRetries, sentryFailureThreshold, sentryRetryCooldown, logger)
+	return r, nil
+}
+
+// parseDSN returns the store endpoint of a DSN and the authentication header of its requests
+func parseDSN(dsn string) (string, string, error) {
+	u, err := url.Parse(dsn)
+	if err != nil {
+		return "", "", fmt.Errorf("invalid error reporting DSN: %w", err)
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return "", "", fmt.Errorf("invalid error reporting DSN: scheme must be http or https")
+	}
+	if u.User == nil || u.User.Username() == "" {
+		return "", "", fmt.Errorf("invalid error reporting DSN: missing public key")
+	}
+	// The project ID is the last path segment; a path before it prefixes the API
+	prefix, projectID := "", strings.TrimPrefix(u.Path, "/")
+	if i := strings.LastIndex(projectID, "/"); i >= 0 {
+		prefix, projectID = "/"+projectID[:i], projectID[i+1:]
+	}
+	if projectID == "" {
+		return "", "", fmt.Errorf("invalid error reporting DSN: missing project ID")
+	}
+
+	auth := "Sentry sentry_version=7, sentry_client=api-gateway/1.0, sentry_key=" + u.User.Username()
+	if secret, ok := u.User.Password(); ok {
+		auth += ", sentry_secret=" + secret
+	}
+	return fmt.Sprintf("%s://%s%s/api/%s/store/", u.Scheme, u.Host, prefix, projectID), auth, nil
+}
+
+// ReportPanic buffers the report for delivery
+func (r *SentryReporter) ReportPanic(report *entity.PanicReport) {
+	r.exporter.Export(report)
+}
+
+// Start delivers buffered reports until the context is cancelled
+func (r *SentryReporter) Start(ctx context.Context) {
+	r.exporter.Start(ctx)
+}
+
+// Health returns a snapshot of the tracker's availability
+func (r *SentryReporter) Health() *entity.DependencyHealth {
+	return r.exporter.Health()
+}
+
+// sentryEvent is the subset of the Sentry event payload the reports fill in
+type sentryEvent struct {
+	EventID     string            `json:"event_id"`
+	Timestamp   string            `json:"timestamp"`
+	Platform    string            `json:"platform"`
+	Level       string            `json:"level"`
+	Logger      string            `json:"logger"`
+	ServerName  string            `json:"server_name,omitempty"`
+	Environment string            `json:"environment,omitempty"`
+	Release     string            `json:"release,omitempty"`
+	Transaction string            `json:"transaction,omitempty"`
+	Tags        map[string]string `json:"tags,omitempty"`
+	Request     sentryRequest     `json:"request"`
+	Exception   struct {
+		Values []sentryException `json:"values"`
+	} `json:"exception"`
+}
+
+type sentryRequest struct {
+	Method  string            `json:"method"`
+	URL     string            `json:"url"`
+	Headers map[string]string `json:"headers,omitempty"`
+	Env     map[string]string `json:"env,omitempty"`
+}
+
+type sentryException struct {
+	Type      string `json:"type"`
+	Value     string `json:"value"`
+	Mechanism struct {
+		Type    string `json:"type"`
+		Handled bool   `json:"handled"`
+	} `json:"mechanism"`
+	Stacktrace struct {
+		Frames []sentryFrame `json:"frames"`
+	} `json:"stacktrace"`
+}
+
+type sentryFrame struct {
+	Function string `json:"function"`
+	Module   string `json:"module,omitempty"`
+	AbsPath  string `json:"abs_path"`
+	Lineno   int    `json:"lineno"`
+}
+
+// newEvent converts a report into a Sentry event. Request headers other than the user agent, and
+// the query string, are left out, as they may carry credentials.
+func (r *SentryReporter) newEvent(report *entity.PanicReport) *sentryEvent {
+	event := &sentryEvent{
+		EventID:     newEventID(),
+		Timestamp:   report.Time.UTC().Format(time.RFC3339Nano),
+		Platform:    "go",
+		Level:       "fatal",
+		Logger:      "api-gateway",
+		ServerName:  r.serverName,
+		Environment: r.environment,
+		Release:     r.release,
+		Tags:        map[string]string{},
+		Request: sentryRequest{
+			Method: report.Method,
+			URL:    report.Path,
+			Env:    map[string]string{"REMOTE_ADDR": report.RemoteAddr},
+		},
+	}
+	if report.UserAgent != "" {
+		event.Request.Headers = map[string]string{"User-Agent": report.UserAgent}
+	}
+	for name, value := range map[string]string{
+		"request_id": report.RequestID,
+		"trace_id":   report.TraceID,
+		"service":    report.Service,
+		"endpoint":   report.Endpoint,
+	} {
+		if value != "" {
+			event.Tags[name] = value
+		}
+	}
+	if report.Service != "" {
+		event.Transaction = report.Method + " " + report.Endpoint
+	}
+
+	exception := sentryException{Type: report.Type, Value: report.Value}
+	exception.Mechanism.Type = "recovery"
+	exception.Mechanism.Handled = true
+	// Sentry lists frames outermost first
+	exception.Stacktrace.Frames = make([]sentryFrame, 0, len(report.Stack))
+	for i := len(report.Stack) - 1; i >= 0; i-- {
+		frame := report.Stack[i]
+		module, function := splitFunction(frame.Function)
+		exception.Stacktrace.Frames = append(exception.Stacktrace.Frames, sentryFrame{
+			Function: function,
+			Module:   module,
+			AbsPath:  frame.File,
+			Lineno:   frame.Line,
+		})
+	}
+	event.Exception.Values = []sentryException{exception}
+	return event
+}
+
+// send posts a report to the store endpoint
+func (r *SentryReporter) send(ctx context.Context, report *entity.PanicReport) error {
+	body, err := json.Marshal(r.newEvent(report))
+	if err != nil {
+		return fmt.Errorf("failed to encode error report: %w", err)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, r.storeURL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Sentry-Auth", r.auth)
+
+	resp, err := r.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to send error report: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("error tracker answered with status %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// splitFunction splits a package-qualified function name, such as
+// api-gateway-sample/internal/x.(*T).M, into its package and the function within it
+func splitFunction(name string) (string, string) {
+	dir := ""
+	if i := strings.LastIndex(name, "/"); i >= 0 {
+		dir, name = name[:i+1], name[i+1:]
+	}
+	if i := strings.Index(name, "."); i >= 0 {
+		return dir + name[:i], name[i+1:]
+	}
+	return "", dir + name
+}
+
+// newEventID returns a random event ID, 32 hex digits as Sentry expects
+func newEventID() string {
+	var b [16]byte
+	rand.Read(b[:])
+	return hex.EncodeToString(b[:])
+}
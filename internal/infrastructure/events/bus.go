@@ -0,0 +1,84 @@
+package events
+
+import (
+	"context"
+	"sync"
+
+	"api-gateway-sample/internal/domain/entity"
+	"api-gateway-sample/internal/domain/service"
+	"api-gateway-sample/pkg/logger"
+)
+
+// InMemoryBus implements the EventBus interface within the process. Handlers run
+// synchronously in subscription order; a panicking handler is logged and skipped.
+type InMemoryBus struct {
+	mu            sync.RWMutex
+	nextID        int
+	subscriptions map[string][]subscription
+	logger        logger.Logger
+}
+
+type subscription struct {
+	id      int
+	handler service.EventHandler
+}
+
+// NewInMemoryBus creates a new InMemoryBus instance
+func NewInMemoryBus(logger logger.Logger) *InMemoryBus {
+	return &InMemoryBus{
+		subscriptions: make(map[string][]subscription),
+		logger:        logger,
+	}
+}
+
+// Subscribe registers a handler for an event type, or for all events with entity.EventAll
+func (b *InMemoryBus) Subscribe(eventType string, handler service.EventHandler) func() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.nextID++
+	id := b.nextID
+	b.subscriptions[eventType] = append(b.subscriptions[eventType], subscription{id: id, handler: handler})
+
+	return func() {
+		b.mu.Lock()
+		defer b.mu.Unlock()
+
+		remaining := make([]subscription, 0, len(b.subscriptions[eventType]))
+		for _, sub := range b.subscriptions[eventType] {
+			if sub.id != id {
+				remaining = append(remaining, sub)
+			}
+		}
+		b.subscriptions[eventType] = remaining
+	}
+}
+
+// Publish delivers the event to the subscribers of its type, then to entity.EventAll subscribers
+func (b *InMemoryBus) Publish(ctx context.Context, event *entity.Event) {
+	for _, handler := range b.subscribers(event.Type) {
+		b.dispatch(ctx, handler, event)
+	}
+}
+
+func (b *InMemoryBus) subscribers(eventType string) []service.EventHandler {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	var handlers []service.EventHandler
+	for _, key := range []string{eventType, entity.EventAll} {
+		for _, sub := range b.subscriptions[key] {
+			handlers = append(handlers, sub.handler)
+		}
+	}
+	return handlers
+}
+
+func (b *InMemoryBus) dispatch(ctx context.Context, handler service.EventHandler, event *entity.Event) {
+	defer func() {
+		if err := recover(); err != nil {
+			logger.FromContextOr(ctx, b.logger).Error("Event handler panicked", "event", event.Type, "error", err)
+		}
+	}()
+	handler(ctx, event)
+}
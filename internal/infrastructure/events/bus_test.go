@@ -0,0 +1,52 @@
+package events
+
+import (
+	"context"
+	"testing"
+
+	"api-gateway-sample/internal/domain/entity"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// nopLogger discards all log output
+type nopLogger struct{}
+
+func (nopLogger) Debug(msg string, keysAndValues ...interface{}) {}
+func (nopLogger) Info(msg string, keysAndValues ...interface{})  {}
+func (nopLogger) Warn(msg string, keysAndValues ...interface{})  {}
+func (nopLogger) Error(msg string, keysAndValues ...interface{}) {}
+func (nopLogger) Fatal(msg string, keysAndValues ...interface{}) {}
+
+func TestInMemoryBus_Publish(t *testing.T) {
+	bus := NewInMemoryBus(nopLogger{})
+	ctx := context.Background()
+
+	var received []string
+	bus.Subscribe(entity.EventServiceCreated, func(ctx context.Context, event *entity.Event) {
+		received = append(received, "created:"+event.ServiceID)
+	})
+	bus.Subscribe(entity.EventServiceCreated, func(ctx context.Context, event *entity.Event) {
+		panic("broken subscriber")
+	})
+	unsubscribe := bus.Subscribe(entity.EventAll, func(ctx context.Context, event *entity.Event) {
+		received = append(received, "all:"+event.Type)
+	})
+
+	// 1. Subscribers of the type run before wildcard subscribers, and a panic does not stop delivery
+	event := entity.NewEvent(entity.EventServiceCreated)
+	event.ServiceID = "svc-1"
+	bus.Publish(ctx, event)
+	assert.Equal(t, []string{"created:svc-1", "all:service.created"}, received)
+
+	// 2. Other event types only reach wildcard subscribers
+	received = nil
+	bus.Publish(ctx, entity.NewEvent(entity.EventKeyRotated))
+	assert.Equal(t, []string{"all:key.rotated"}, received)
+
+	// 3. Unsubscribed handlers no longer receive events
+	received = nil
+	unsubscribe()
+	bus.Publish(ctx, entity.NewEvent(entity.EventKeyRotated))
+	assert.Empty(t, received)
+}
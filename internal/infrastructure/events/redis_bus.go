@@ -0,0 +1,121 @@
+package events
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+
+	"github.com/redis/go-redis/v9"
+
+	"api-gateway-sample/internal/domain/entity"
+	"api-gateway-sample/internal/domain/service"
+	"api-gateway-sample/pkg/logger"
+)
+
+// RedisBus implements the EventBus interface across gateway instances. Events are delivered to
+// the subscribers of this instance through an InMemoryBus, and configuration events are also
+// published on a Redis pub/sub channel, from which every other instance delivers them to its
+// own subscribers as remote events.
+type RedisBus struct {
+	local      *InMemoryBus
+	client     redis.UniversalClient
+	channel    string
+	instanceID string
+	propagated map[string]bool
+	logger     logger.Logger
+}
+
+// redisEnvelope is a message on the channel; the origin lets an instance ignore its own events
+type redisEnvelope struct {
+	Origin string        `json:"origin"`
+	Event  *entity.Event `json:"event"`
+}
+
+// NewRedisBus creates a new RedisBus instance delivering local events through the given bus
+func NewRedisBus(local *InMemoryBus, client redis.UniversalClient, channel string, logger logger.Logger) *RedisBus {
+	id := make([]byte, 8)
+	rand.Read(id)
+
+	propagated := make(map[string]bool, len(entity.ConfigEvents))
+	for _, eventType := range entity.ConfigEvents {
+		propagated[eventType] = true
+	}
+	return &RedisBus{
+		local:      local,
+		client:     client,
+		channel:    channel,
+		instanceID: hex.EncodeToString(id),
+		propagated: propagated,
+		logger:     logger,
+	}
+}
+
+// Subscribe registers a handler for an event type, or for all events with entity.EventAll
+func (b *RedisBus) Subscribe(eventType string, handler service.EventHandler) func() {
+	return b.local.Subscribe(eventType, handler)
+}
+
+// Publish delivers the event to the subscribers of this instance, then publishes configuration
+// events to the other instances. A failed publish is logged; the local delivery stands.
+func (b *RedisBus) Publish(ctx context.Context, event *entity.Event) {
+	b.local.Publish(ctx, event)
+	if event.Remote || !b.propagated[event.Type] {
+		return
+	}
+
+	log := logger.FromContextOr(ctx, b.logger)
+	data, err := json.Marshal(redisEnvelope{Origin: b.instanceID, Event: event})
+	if err != nil {
+		log.Error("Failed to encode event", "event", event.Type, "error", err)
+		return
+	}
+	if err := b.client.Publish(ctx, b.channel, data).Err(); err != nil {
+		log.Error("Failed to propagate event", "event", event.Type, "channel", b.channel, "error", err)
+	}
+}
+
+// Start subscribes to the channel and delivers the events of other instances until the context
+// is cancelled. It returns once the subscription is established; the subscription reconnects
+// by itself if Redis becomes unavailable later.
+func (b *RedisBus) Start(ctx context.Context) error {
+	pubsub := b.client.Subscribe(ctx, b.channel)
+	if _, err := pubsub.Receive(ctx); err != nil {
+		pubsub.Close()
+		return fmt.Errorf("failed to subscribe to %s: %w", b.channel, err)
+	}
+
+	go func() {
+		defer pubsub.Close()
+		messages := pubsub.Channel()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case message, ok := <-messages:
+				if !ok {
+					return
+				}
+				b.receive(ctx, message.Payload)
+			}
+		}
+	}()
+	return nil
+}
+
+// receive delivers an event published by another instance to the subscribers of this instance
+func (b *RedisBus) receive(ctx context.Context, payload string) {
+	var envelope redisEnvelope
+	if err := json.Unmarshal([]byte(payload), &envelope); err != nil || envelope.Event == nil {
+		b.logger.Warn("Ignoring invalid event message", "channel", b.channel, "error", err)
+		return
+	}
+	if envelope.Origin == b.instanceID {
+		return
+	}
+
+	envelope.Event.Remote = true
+	b.logger.Debug("Received event from another instance", "event", envelope.Event.Type, "origin", envelope.Origin)
+	b.local.Publish(ctx, envelope.Event)
+}
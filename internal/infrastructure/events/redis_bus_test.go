@@ -0,0 +1,67 @@
+package events
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"api-gateway-sample/internal/domain/entity"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRedisBus_PropagatesConfigEvents(t *testing.T) {
+	server := miniredis.RunT(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+
+	// Two instances share the channel
+	newInstance := func() (*RedisBus, chan *entity.Event) {
+		client := redis.NewClient(&redis.Options{Addr: server.Addr()})
+		t.Cleanup(func() { client.Close() })
+		bus := NewRedisBus(NewInMemoryBus(nopLogger{}), client, "api-gateway:config", nopLogger{})
+		require.NoError(t, bus.Start(ctx))
+
+		received := make(chan *entity.Event, 10)
+		bus.Subscribe(entity.EventAll, func(ctx context.Context, event *entity.Event) {
+			received <- event
+		})
+		return bus, received
+	}
+	origin, originEvents := newInstance()
+	_, peerEvents := newInstance()
+
+	// 1. Events reach the subscribers of the origin as local events
+	updated := entity.NewEvent(entity.EventServiceUpdated)
+	updated.ServiceID = "svc-1"
+	updated.Previous = &entity.Service{ID: "svc-1", BaseURL: "http://orders"}
+	origin.Publish(ctx, entity.NewEvent(entity.EventAPIKeyRequested))
+	origin.Publish(ctx, updated)
+
+	assert.Equal(t, entity.EventAPIKeyRequested, (<-originEvents).Type)
+	local := <-originEvents
+	assert.Equal(t, entity.EventServiceUpdated, local.Type)
+	assert.False(t, local.Remote)
+
+	// 2. Only configuration events reach the other instance, as remote events
+	select {
+	case remote := <-peerEvents:
+		assert.Equal(t, entity.EventServiceUpdated, remote.Type)
+		assert.True(t, remote.Remote)
+		assert.Equal(t, "svc-1", remote.ServiceID)
+		require.NotNil(t, remote.Previous)
+		assert.Equal(t, "http://orders", remote.Previous.BaseURL)
+	case <-time.After(2 * time.Second):
+		t.Fatal("Expected the event to reach the other instance")
+	}
+
+	// 3. The origin does not receive its own events back
+	select {
+	case event := <-originEvents:
+		t.Fatalf("Unexpected event %+v", event)
+	case <-time.After(100 * time.Millisecond):
+	}
+}
@@ -0,0 +1,196 @@
+// Package export sends data to optional backends, such as alert channels, without letting their
+// failures reach the proxy path
+package export
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"api-gateway-sample/internal/domain/entity"
+	"api-gateway-sample/pkg/logger"
+)
+
+// SendFunc delivers one item to the backend
+type SendFunc func(ctx context.Context, item interface{}) error
+
+// Exporter implements the HealthReporter interface for an optional backend. Items are buffered
+// and sent by a background worker, so exporting never blocks or fails the caller. After a number
+// of consecutive failures the circuit opens: items stay buffered for the cooldown, then one is
+// retried before the rest. An item is dropped once its retries are used up, and the oldest item
+// is dropped when the buffer is full.
+type Exporter struct {
+	name             string
+	send             SendFunc
+	bufferSize       int
+	maxRetries       int
+	failureThreshold int
+	cooldown         time.Duration
+	logger           logger.Logger
+	ready            chan struct{}
+
+	mu                  sync.Mutex
+	buffer              []*pending
+	consecutiveFailures int
+	failures            int64
+	deferred            int64
+	dropped             int64
+	openUntil           time.Time
+	lastError           string
+	lastCheck           time.Time
+	now                 func() time.Time
+}
+
+// pending is a buffered item and the number of times sending it failed
+type pending struct {
+	item     interface{}
+	failures int
+}
+
+// NewExporter creates a new Exporter instance. At most bufferSize items wait to be sent, each is
+// retried up to maxRetries times, and the circuit opens for cooldown after failureThreshold
+// consecutive failures.
+func NewExporter(name string, send SendFunc, bufferSize int, maxRetries int, failureThreshold int, cooldown time.Duration, logger logger.Logger) *Exporter {
+	if bufferSize < 1 {
+		bufferSize = 1
+	}
+	if failureThreshold < 1 {
+		failureThreshold = 1
+	}
+	return &Exporter{
+		name:             name,
+		send:             send,
+		bufferSize:       bufferSize,
+		maxRetries:       maxRetries,
+		failureThreshold: failureThreshold,
+		cooldown:         cooldown,
+		logger:           logger,
+		ready:            make(chan struct{}, 1),
+		now:              time.Now,
+	}
+}
+
+// Export buffers an item for the background worker
+func (e *Exporter) Export(item interface{}) {
+	e.mu.Lock()
+	if len(e.buffer) == e.bufferSize {
+		e.buffer = e.buffer[1:]
+		e.dropped++
+		e.logger.Warn("Export buffer is full, dropped the oldest item", "exporter", e.name)
+	}
+	e.buffer = append(e.buffer, &pending{item: item})
+	e.mu.Unlock()
+
+	select {
+	case e.ready <- struct{}{}:
+	default:
+	}
+}
+
+// Start sends buffered items until the context is cancelled
+func (e *Exporter) Start(ctx context.Context) {
+	go func() {
+		for {
+			if wait := e.flush(ctx); wait > 0 {
+				// Items arriving while the circuit is open wait for the cooldown
+				timer := time.NewTimer(wait)
+				select {
+				case <-ctx.Done():
+					timer.Stop()
+					return
+				case <-timer.C:
+				}
+				continue
+			}
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-e.ready:
+			}
+		}
+	}()
+}
+
+// flush sends buffered items until the buffer is empty, returning 0, or the circuit is open,
+// returning how long until the next attempt
+func (e *Exporter) flush(ctx context.Context) time.Duration {
+	for {
+		next, wait, ok := e.next()
+		if !ok {
+			return wait
+		}
+		e.record(next, e.send(ctx, next.item))
+	}
+}
+
+// next takes the oldest buffered item unless the buffer is empty or the circuit is open
+func (e *Exporter) next() (*pending, time.Duration, bool) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if wait := e.openUntil.Sub(e.now()); wait > 0 {
+		return nil, wait, false
+	}
+	if len(e.buffer) == 0 {
+		return nil, 0, false
+	}
+	next := e.buffer[0]
+	e.buffer = e.buffer[1:]
+	return next, 0, true
+}
+
+// record updates the circuit with the result of sending an item, putting it back for a retry
+// while it has retries left
+func (e *Exporter) record(sent *pending, err error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	e.lastCheck = e.now()
+	if err == nil {
+		if e.consecutiveFailures >= e.failureThreshold {
+			e.logger.Info("Export backend recovered", "exporter", e.name, "failures", e.consecutiveFailures)
+		}
+		e.consecutiveFailures = 0
+		e.lastError = ""
+		return
+	}
+
+	e.failures++
+	e.consecutiveFailures++
+	e.lastError = err.Error()
+	sent.failures++
+	switch {
+	case sent.failures > e.maxRetries:
+		e.dropped++
+		e.logger.Warn("Export failed after retries, dropped the item", "exporter", e.name, "error", err)
+	case len(e.buffer) == e.bufferSize:
+		e.dropped++
+	default:
+		e.buffer = append([]*pending{sent}, e.buffer...)
+		e.deferred++
+	}
+	if e.consecutiveFailures >= e.failureThreshold {
+		if e.consecutiveFailures == e.failureThreshold {
+			e.logger.Error("Export backend is unavailable, buffering exports", "exporter", e.name, "error", err)
+		}
+		e.openUntil = e.now().Add(e.cooldown)
+	}
+}
+
+// Health returns a snapshot of the backend's availability
+func (e *Exporter) Health() *entity.DependencyHealth {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return &entity.DependencyHealth{
+		Name:                e.name,
+		Criticality:         entity.CriticalityOptional,
+		Healthy:             e.consecutiveFailures < e.failureThreshold,
+		ConsecutiveFailures: e.consecutiveFailures,
+		Failures:            e.failures,
+		Fallbacks:           e.deferred,
+		Dropped:             e.dropped,
+		LastError:           e.lastError,
+		LastCheck:           e.lastCheck,
+	}
+}
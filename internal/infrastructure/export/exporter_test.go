@@ -0,0 +1,162 @@
+package export
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"api-gateway-sample/internal/domain/entity"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// nopLogger discards all log output
+type nopLogger struct{}
+
+func (nopLogger) Debug(msg string, keysAndValues ...interface{}) {}
+func (nopLogger) Info(msg string, keysAndValues ...interface{})  {}
+func (nopLogger) Warn(msg string, keysAndValues ...interface{})  {}
+func (nopLogger) Error(msg string, keysAndValues ...interface{}) {}
+func (nopLogger) Fatal(msg string, keysAndValues ...interface{}) {}
+
+// backend records the items sent to it and fails while down
+type backend struct {
+	mu   sync.Mutex
+	down bool
+	sent []interface{}
+}
+
+func (b *backend) send(ctx context.Context, item interface{}) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.down {
+		return errors.New("connection refused")
+	}
+	b.sent = append(b.sent, item)
+	return nil
+}
+
+func (b *backend) setDown(down bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.down = down
+}
+
+func (b *backend) received() []interface{} {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return append([]interface{}(nil), b.sent...)
+}
+
+// newTestExporter returns an exporter on a clock the test advances
+func newTestExporter(b *backend, bufferSize int, maxRetries int) (*Exporter, *time.Time) {
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	e := NewExporter("alerts.test", b.send, bufferSize, maxRetries, 2, time.Minute, nopLogger{})
+	e.now = func() time.Time { return now }
+	return e, &now
+}
+
+func TestExporter_Delivers(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	b := &backend{}
+	e := NewExporter("alerts.test", b.send, 10, 3, 2, time.Minute, nopLogger{})
+	e.Start(ctx)
+
+	e.Export("a")
+	e.Export("b")
+	require.Eventually(t, func() bool { return len(b.received()) == 2 }, time.Second, time.Millisecond)
+	assert.Equal(t, []interface{}{"a", "b"}, b.received())
+
+	health := e.Health()
+	assert.Equal(t, "alerts.test", health.Name)
+	assert.Equal(t, entity.CriticalityOptional, health.Criticality)
+	assert.True(t, health.Healthy)
+}
+
+func TestExporter_BackendDown(t *testing.T) {
+	ctx := context.Background()
+	b := &backend{down: true}
+	e, now := newTestExporter(b, 10, 5)
+
+	// Exporting while the backend is down neither blocks nor fails
+	e.Export("a")
+	e.Export("b")
+
+	// Two consecutive failures open the circuit for the cooldown
+	assert.Equal(t, time.Minute, e.flush(ctx))
+	health := e.Health()
+	assert.False(t, health.Healthy)
+	assert.Equal(t, 2, health.ConsecutiveFailures)
+	assert.Equal(t, int64(2), health.Fallbacks)
+	assert.Equal(t, "connection refused", health.LastError)
+
+	// Nothing is sent while the circuit is open
+	*now = now.Add(30 * time.Second)
+	assert.Equal(t, 30*time.Second, e.flush(ctx))
+	assert.Equal(t, int64(2), e.Health().Failures)
+
+	// The buffered items are sent in order once the backend is back
+	b.setDown(false)
+	*now = now.Add(30 * time.Second)
+	assert.Equal(t, time.Duration(0), e.flush(ctx))
+	assert.Equal(t, []interface{}{"a", "b"}, b.received())
+	assert.True(t, e.Health().Healthy)
+	assert.Equal(t, int64(0), e.Health().Dropped)
+}
+
+func TestExporter_HalfOpenFailure(t *testing.T) {
+	ctx := context.Background()
+	b := &backend{down: true}
+	e, now := newTestExporter(b, 10, 5)
+
+	e.Export("a")
+	e.Export("b")
+	e.flush(ctx)
+
+	// A single failed retry after the cooldown opens the circuit again
+	*now = now.Add(time.Minute)
+	assert.Equal(t, time.Minute, e.flush(ctx))
+	assert.Equal(t, int64(3), e.Health().Failures)
+}
+
+func TestExporter_BufferFull(t *testing.T) {
+	ctx := context.Background()
+	b := &backend{down: true}
+	e, now := newTestExporter(b, 2, 5)
+
+	e.Export("a")
+	e.Export("b")
+	e.Export("c")
+	assert.Equal(t, int64(1), e.Health().Dropped)
+
+	b.setDown(false)
+	*now = now.Add(time.Minute)
+	e.flush(ctx)
+	assert.Equal(t, []interface{}{"b", "c"}, b.received())
+}
+
+func TestExporter_RetriesExhausted(t *testing.T) {
+	ctx := context.Background()
+	b := &backend{down: true}
+	e, now := newTestExporter(b, 10, 2)
+
+	// The first attempt and a retry fail before the circuit opens
+	e.Export("a")
+	e.flush(ctx)
+	assert.Equal(t, int64(0), e.Health().Dropped)
+
+	// The retry after the cooldown is the last
+	*now = now.Add(time.Minute)
+	e.flush(ctx)
+	assert.Equal(t, int64(1), e.Health().Dropped)
+
+	b.setDown(false)
+	*now = now.Add(time.Minute)
+	e.flush(ctx)
+	assert.Empty(t, b.received())
+}
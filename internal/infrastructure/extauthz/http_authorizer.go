@@ -0,0 +1,222 @@
+package extauthz
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"api-gateway-sample/internal/domain/entity"
+	"api-gateway-sample/pkg/logger"
+)
+
+// maxDeniedBodySize bounds the denial body relayed to the client
+const maxDeniedBodySize = 64 * 1024
+
+// uncachedHeaders are forwarded headers that identify a request rather than its caller, so they
+// are left out of the decision cache key that would otherwise never be hit
+var uncachedHeaders = map[string]bool{
+	"X-Request-Id": true,
+}
+
+// HTTPAuthorizer implements the ExternalAuthorizer interface by calling an HTTP
+// authorization service in the style of Envoy's ext_authz: the original method and
+// path are sent with the selected request headers, a 2xx response allows the request
+// and any other response denies it.
+type HTTPAuthorizer struct {
+	url             string
+	forwardHeaders  []string
+	upstreamHeaders []string
+	failOpen        bool
+	cacheTTL        time.Duration
+	client          *http.Client
+	logger          logger.Logger
+
+	mu        sync.Mutex
+	cache     map[string]cachedDecision
+	stop      chan struct{}
+	closeOnce sync.Once
+}
+
+type cachedDecision struct {
+	decision  *entity.AuthzDecision
+	expiresAt time.Time
+}
+
+// NewHTTPAuthorizer creates a new HTTPAuthorizer instance, purging expired decisions every
+// cacheTTL until it is closed
+func NewHTTPAuthorizer(
+	url string,
+	forwardHeaders []string,
+	upstreamHeaders []string,
+	failOpen bool,
+	cacheTTL time.Duration,
+	timeout time.Duration,
+	logger logger.Logger,
+) *HTTPAuthorizer {
+	a := &HTTPAuthorizer{
+		url:             strings.TrimRight(url, "/"),
+		forwardHeaders:  forwardHeaders,
+		upstreamHeaders: upstreamHeaders,
+		failOpen:        failOpen,
+		cacheTTL:        cacheTTL,
+		client:          &http.Client{Timeout: timeout},
+		logger:          logger,
+		cache:           make(map[string]cachedDecision),
+		stop:            make(chan struct{}),
+	}
+	if cacheTTL > 0 {
+		go a.cleanup(cacheTTL)
+	}
+	return a
+}
+
+// Close stops purging expired decisions
+func (a *HTTPAuthorizer) Close() error {
+	a.closeOnce.Do(func() {
+		close(a.stop)
+	})
+	return nil
+}
+
+// Check asks the authorization service whether the request is allowed
+func (a *HTTPAuthorizer) Check(ctx context.Context, request *entity.Request, service *entity.Service, endpoint *entity.Endpoint) (*entity.AuthzDecision, error) {
+	key := a.cacheKey(request)
+	if decision, ok := a.cached(key); ok {
+		return decision, nil
+	}
+
+	decision, err := a.call(ctx, request, service)
+	if err != nil {
+		if a.failOpen {
+			logger.FromContextOr(ctx, a.logger).Warn("External authorization unavailable, failing open", "error", err)
+			return &entity.AuthzDecision{Allowed: true}, nil
+		}
+		return nil, err
+	}
+
+	// Server errors are not cached so that a transient outage does not outlive itself
+	if decision.Allowed || decision.StatusCode < http.StatusInternalServerError {
+		a.store(key, decision)
+	}
+	return decision, nil
+}
+
+func (a *HTTPAuthorizer) call(ctx context.Context, request *entity.Request, service *entity.Service) (*entity.AuthzDecision, error) {
+	httpReq, err := http.NewRequestWithContext(ctx, request.Method, a.url+request.Path, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create authorization request: %w", err)
+	}
+
+	headers := http.Header(request.Headers)
+	for _, name := range a.forwardHeaders {
+		for _, value := range headers.Values(name) {
+			httpReq.Header.Add(name, value)
+		}
+	}
+	httpReq.Header.Set("X-Forwarded-Method", request.Method)
+	httpReq.Header.Set("X-Forwarded-Uri", request.Path)
+	httpReq.Header.Set("X-Forwarded-For", request.ClientIP)
+	httpReq.Header.Set("X-Service-Name", service.Name)
+
+	resp, err := a.client.Do(httpReq)
+	if err != nil {
+		return nil, fmt.Errorf("authorization service call failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		decision := &entity.AuthzDecision{
+			Allowed:         true,
+			StatusCode:      resp.StatusCode,
+			UpstreamHeaders: make(map[string]string),
+		}
+		for _, name := range a.upstreamHeaders {
+			if value := resp.Header.Get(name); value != "" {
+				decision.UpstreamHeaders[name] = value
+			}
+		}
+		return decision, nil
+	}
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxDeniedBodySize))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read authorization response: %w", err)
+	}
+	return &entity.AuthzDecision{
+		Allowed:    false,
+		StatusCode: resp.StatusCode,
+		Body:       body,
+	}, nil
+}
+
+// cacheKey identifies a decision by the request method, path and forwarded headers, but for
+// uncachedHeaders
+func (a *HTTPAuthorizer) cacheKey(request *entity.Request) string {
+	hash := sha256.New()
+	hash.Write([]byte(request.Method + " " + request.Path + "\n"))
+	headers := http.Header(request.Headers)
+	for _, name := range a.forwardHeaders {
+		if uncachedHeaders[http.CanonicalHeaderKey(name)] {
+			continue
+		}
+		hash.Write([]byte(name + ":" + strings.Join(headers.Values(name), ",") + "\n"))
+	}
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+func (a *HTTPAuthorizer) cached(key string) (*entity.AuthzDecision, bool) {
+	if a.cacheTTL <= 0 {
+		return nil, false
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	entry, ok := a.cache[key]
+	if !ok {
+		return nil, false
+	}
+	if time.Now().After(entry.expiresAt) {
+		delete(a.cache, key)
+		return nil, false
+	}
+	return entry.decision, true
+}
+
+func (a *HTTPAuthorizer) store(key string, decision *entity.AuthzDecision) {
+	if a.cacheTTL <= 0 {
+		return
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	a.cache[key] = cachedDecision{decision: decision, expiresAt: time.Now().Add(a.cacheTTL)}
+}
+
+// cleanup drops expired decisions every interval, keeping the cache bounded by the TTL
+func (a *HTTPAuthorizer) cleanup(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-a.stop:
+			return
+		case now := <-ticker.C:
+			a.mu.Lock()
+			for key, entry := range a.cache {
+				if now.After(entry.expiresAt) {
+					delete(a.cache, key)
+				}
+			}
+			a.mu.Unlock()
+		}
+	}
+}
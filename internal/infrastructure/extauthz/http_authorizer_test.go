@@ -0,0 +1,136 @@
+package extauthz
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"api-gateway-sample/internal/domain/entity"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// nopLogger discards all log output
+type nopLogger struct{}
+
+func (nopLogger) Debug(msg string, keysAndValues ...interface{}) {}
+func (nopLogger) Info(msg string, keysAndValues ...interface{})  {}
+func (nopLogger) Warn(msg string, keysAndValues ...interface{})  {}
+func (nopLogger) Error(msg string, keysAndValues ...interface{}) {}
+func (nopLogger) Fatal(msg string, keysAndValues ...interface{}) {}
+
+func newRequest(token string) *entity.Request {
+	return &entity.Request{
+		Method:  http.MethodGet,
+		Path:    "/api/v1/orders",
+		Headers: map[string][]string{"Authorization": {token}},
+	}
+}
+
+func TestHTTPAuthorizer_Check(t *testing.T) {
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		assert.Equal(t, "/api/v1/orders", r.URL.Path)
+		assert.Equal(t, "orders-service", r.Header.Get("X-Service-Name"))
+
+		if r.Header.Get("Authorization") != "Bearer good" {
+			w.WriteHeader(http.StatusForbidden)
+			w.Write([]byte("access denied"))
+			return
+		}
+		w.Header().Set("X-User-ID", "user-1")
+		w.Header().Set("X-Internal", "not forwarded")
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	authorizer := NewHTTPAuthorizer(server.URL, []string{"Authorization"}, []string{"X-User-ID"}, false, time.Minute, time.Second, nopLogger{})
+	defer authorizer.Close()
+	svc := &entity.Service{Name: "orders-service"}
+	ctx := context.Background()
+
+	// 1. Allowed requests carry the selected upstream headers
+	decision, err := authorizer.Check(ctx, newRequest("Bearer good"), svc, nil)
+	require.NoError(t, err)
+	assert.True(t, decision.Allowed)
+	assert.Equal(t, map[string]string{"X-User-ID": "user-1"}, decision.UpstreamHeaders)
+
+	// 2. Denied requests return the service's status and body
+	decision, err = authorizer.Check(ctx, newRequest("Bearer bad"), svc, nil)
+	require.NoError(t, err)
+	assert.False(t, decision.Allowed)
+	assert.Equal(t, http.StatusForbidden, decision.StatusCode)
+	assert.Equal(t, "access denied", string(decision.Body))
+
+	// 3. Repeated requests are answered from the decision cache
+	_, err = authorizer.Check(ctx, newRequest("Bearer good"), svc, nil)
+	require.NoError(t, err)
+	_, err = authorizer.Check(ctx, newRequest("Bearer bad"), svc, nil)
+	require.NoError(t, err)
+	assert.Equal(t, int32(2), atomic.LoadInt32(&calls))
+}
+
+func TestHTTPAuthorizer_Unavailable(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	url := server.URL
+	server.Close()
+
+	svc := &entity.Service{Name: "orders-service"}
+
+	// Fail closed by default
+	authorizer := NewHTTPAuthorizer(url, nil, nil, false, time.Minute, time.Second, nopLogger{})
+	_, err := authorizer.Check(context.Background(), newRequest("Bearer good"), svc, nil)
+	assert.Error(t, err)
+
+	// Fail open when configured
+	authorizer = NewHTTPAuthorizer(url, nil, nil, true, time.Minute, time.Second, nopLogger{})
+	decision, err := authorizer.Check(context.Background(), newRequest("Bearer good"), svc, nil)
+	require.NoError(t, err)
+	assert.True(t, decision.Allowed)
+}
+
+func TestHTTPAuthorizer_CacheIgnoresRequestID(t *testing.T) {
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		assert.NotEmpty(t, r.Header.Get("X-Request-ID"))
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	authorizer := NewHTTPAuthorizer(server.URL, []string{"Authorization", "X-Request-ID"}, nil, false, time.Minute, time.Second, nopLogger{})
+	defer authorizer.Close()
+	svc := &entity.Service{Name: "orders-service"}
+
+	for _, requestID := range []string{"req-1", "req-2"} {
+		request := newRequest("Bearer good")
+		http.Header(request.Headers).Set("X-Request-ID", requestID)
+		decision, err := authorizer.Check(context.Background(), request, svc, nil)
+		require.NoError(t, err)
+		assert.True(t, decision.Allowed)
+	}
+	assert.Equal(t, int32(1), atomic.LoadInt32(&calls))
+}
+
+func TestHTTPAuthorizer_PurgesExpiredDecisions(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	authorizer := NewHTTPAuthorizer(server.URL, []string{"Authorization"}, nil, false, 10*time.Millisecond, time.Second, nopLogger{})
+	defer authorizer.Close()
+
+	_, err := authorizer.Check(context.Background(), newRequest("Bearer good"), &entity.Service{Name: "orders-service"}, nil)
+	require.NoError(t, err)
+	assert.Eventually(t, func() bool {
+		authorizer.mu.Lock()
+		defer authorizer.mu.Unlock()
+		return len(authorizer.cache) == 0
+	}, time.Second, 5*time.Millisecond)
+}
@@ -0,0 +1,146 @@
+package flags
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"api-gateway-sample/internal/domain/entity"
+	"api-gateway-sample/pkg/logger"
+)
+
+// maxCachedEvaluations bounds the evaluations cached; the cache is emptied once it is full
+const maxCachedEvaluations = 10000
+
+// OFREPProvider implements the FeatureFlagProvider interface with a flag service speaking the
+// OpenFeature Remote Evaluation Protocol, such as flagd or GO Feature Flag. The caller is sent
+// as the evaluation context: its user ID as the targeting key, with its roles, scopes and
+// claims. Flags that are not found, or that the service cannot evaluate without a targeting
+// key for anonymous callers, are off.
+type OFREPProvider struct {
+	baseURL  string
+	token    string
+	cacheTTL time.Duration
+	client   *http.Client
+	logger   logger.Logger
+
+	mu          sync.Mutex
+	evaluations map[string]ofrepEvaluation
+}
+
+// ofrepEvaluation is a cached evaluation of a flag for a caller
+type ofrepEvaluation struct {
+	enabled bool
+	expires time.Time
+}
+
+// ofrepResponse is the body of an OFREP evaluation response, successful or not
+type ofrepResponse struct {
+	Value        interface{} `json:"value"`
+	ErrorCode    string      `json:"errorCode"`
+	ErrorDetails string      `json:"errorDetails"`
+}
+
+// NewOFREPProvider creates a new OFREPProvider instance. The evaluations of each caller are
+// cached for cacheTTL.
+func NewOFREPProvider(baseURL string, token string, timeout time.Duration, cacheTTL time.Duration, logger logger.Logger) *OFREPProvider {
+	return &OFREPProvider{
+		baseURL:     strings.TrimSuffix(baseURL, "/"),
+		token:       token,
+		cacheTTL:    cacheTTL,
+		client:      &http.Client{Timeout: timeout},
+		logger:      logger,
+		evaluations: make(map[string]ofrepEvaluation),
+	}
+}
+
+// Enabled evaluates a boolean flag for a caller
+func (p *OFREPProvider) Enabled(ctx context.Context, key string, principal *entity.Principal) (bool, error) {
+	evalContext := map[string]interface{}{}
+	if principal != nil {
+		for name, value := range principal.Claims {
+			evalContext[name] = value
+		}
+		evalContext["targetingKey"] = principal.UserID
+		evalContext["roles"] = principal.Roles
+		evalContext["scopes"] = principal.Scopes
+	}
+	body, err := json.Marshal(map[string]interface{}{"context": evalContext})
+	if err != nil {
+		return false, fmt.Errorf("failed to encode evaluation context: %w", err)
+	}
+
+	cacheKey := key + "\x00" + string(body)
+	if enabled, ok := p.cached(cacheKey); ok {
+		return enabled, nil
+	}
+	enabled, err := p.evaluate(ctx, key, body)
+	if err != nil {
+		return false, err
+	}
+	p.store(cacheKey, enabled)
+	return enabled, nil
+}
+
+func (p *OFREPProvider) evaluate(ctx context.Context, key string, body []byte) (bool, error) {
+	endpoint := p.baseURL + "/ofrep/v1/evaluate/flags/" + url.PathEscape(key)
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewReader(body))
+	if err != nil {
+		return false, fmt.Errorf("failed to create flag evaluation request: %w", err)
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+	if p.token != "" {
+		httpReq.Header.Set("Authorization", "Bearer "+p.token)
+	}
+
+	resp, err := p.client.Do(httpReq)
+	if err != nil {
+		return false, fmt.Errorf("flag evaluation failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	var result ofrepResponse
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil && resp.StatusCode == http.StatusOK {
+		return false, fmt.Errorf("failed to decode flag evaluation: %w", err)
+	}
+	switch {
+	case resp.StatusCode == http.StatusOK:
+		enabled, ok := result.Value.(bool)
+		if !ok {
+			return false, fmt.Errorf("flag %s is not a boolean flag", key)
+		}
+		return enabled, nil
+	case resp.StatusCode == http.StatusNotFound:
+		logger.FromContextOr(ctx, p.logger).Debug("Feature flag not found", "flag", key)
+		return false, nil
+	case resp.StatusCode == http.StatusBadRequest && result.ErrorCode == "TARGETING_KEY_MISSING":
+		return false, nil
+	default:
+		return false, fmt.Errorf("flag evaluation returned status %d: %s %s", resp.StatusCode, result.ErrorCode, result.ErrorDetails)
+	}
+}
+
+func (p *OFREPProvider) cached(cacheKey string) (bool, bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	evaluation, ok := p.evaluations[cacheKey]
+	if !ok || time.Now().After(evaluation.expires) {
+		return false, false
+	}
+	return evaluation.enabled, true
+}
+
+func (p *OFREPProvider) store(cacheKey string, enabled bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if len(p.evaluations) >= maxCachedEvaluations {
+		p.evaluations = make(map[string]ofrepEvaluation)
+	}
+	p.evaluations[cacheKey] = ofrepEvaluation{enabled: enabled, expires: time.Now().Add(p.cacheTTL)}
+}
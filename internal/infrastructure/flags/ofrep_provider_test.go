@@ -0,0 +1,89 @@
+package flags
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"api-gateway-sample/internal/domain/entity"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// nopLogger discards all log output
+type nopLogger struct{}
+
+func (nopLogger) Debug(msg string, keysAndValues ...interface{}) {}
+func (nopLogger) Info(msg string, keysAndValues ...interface{})  {}
+func (nopLogger) Warn(msg string, keysAndValues ...interface{})  {}
+func (nopLogger) Error(msg string, keysAndValues ...interface{}) {}
+func (nopLogger) Fatal(msg string, keysAndValues ...interface{}) {}
+
+func TestOFREPProvider_Enabled(t *testing.T) {
+	calls := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		assert.Equal(t, http.MethodPost, r.Method)
+		assert.Equal(t, "Bearer secret", r.Header.Get("Authorization"))
+		var body struct {
+			Context map[string]interface{} `json:"context"`
+		}
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/ofrep/v1/evaluate/flags/composite-orders":
+			if body.Context["targetingKey"] == nil {
+				w.WriteHeader(http.StatusBadRequest)
+				w.Write([]byte(`{"errorCode":"TARGETING_KEY_MISSING"}`))
+				return
+			}
+			roles, _ := body.Context["roles"].([]interface{})
+			enabled := len(roles) > 0 && roles[0] == "employee"
+			json.NewEncoder(w).Encode(map[string]interface{}{"key": "composite-orders", "value": enabled})
+		case "/ofrep/v1/evaluate/flags/theme":
+			w.Write([]byte(`{"key":"theme","value":"dark"}`))
+		case "/ofrep/v1/evaluate/flags/broken":
+			w.WriteHeader(http.StatusInternalServerError)
+			w.Write([]byte(`{"errorCode":"GENERAL","errorDetails":"flag store unavailable"}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"errorCode":"FLAG_NOT_FOUND"}`))
+		}
+	}))
+	defer server.Close()
+
+	provider := NewOFREPProvider(server.URL+"/", "secret", time.Second, time.Minute, nopLogger{})
+	ctx := context.Background()
+	employee := &entity.Principal{UserID: "alice", Roles: []string{"employee"}}
+	customer := &entity.Principal{UserID: "bob", Roles: []string{"customer"}}
+
+	enabled, err := provider.Enabled(ctx, "composite-orders", employee)
+	require.NoError(t, err)
+	assert.True(t, enabled)
+	enabled, err = provider.Enabled(ctx, "composite-orders", customer)
+	require.NoError(t, err)
+	assert.False(t, enabled)
+
+	// Evaluations are cached per caller
+	_, err = provider.Enabled(ctx, "composite-orders", employee)
+	require.NoError(t, err)
+	assert.Equal(t, 2, calls)
+
+	// Anonymous callers and unknown flags are off
+	enabled, err = provider.Enabled(ctx, "composite-orders", nil)
+	require.NoError(t, err)
+	assert.False(t, enabled)
+	enabled, err = provider.Enabled(ctx, "unknown", employee)
+	require.NoError(t, err)
+	assert.False(t, enabled)
+
+	_, err = provider.Enabled(ctx, "theme", employee)
+	assert.Error(t, err)
+	_, err = provider.Enabled(ctx, "broken", employee)
+	assert.Error(t, err)
+}
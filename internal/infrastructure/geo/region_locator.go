@@ -0,0 +1,164 @@
+package geo
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"net/netip"
+	"os"
+	"sort"
+	"strings"
+
+	"api-gateway-sample/internal/domain/entity"
+	"api-gateway-sample/pkg/config"
+)
+
+// RegionLocator implements the RegionLocator interface from trusted headers, the client networks
+// of each region and a GeoIP database of countries
+type RegionLocator struct {
+	regionHeader  string
+	countryHeader string
+	// countries maps upper case country codes to regions
+	countries map[string]string
+	// networks are the configured networks of each region, most specific first
+	networks []regionalNetwork
+	// geoIP holds the networks of the GeoIP database sorted by address
+	geoIP []countryNetwork
+}
+
+type regionalNetwork struct {
+	prefix netip.Prefix
+	region string
+}
+
+type countryNetwork struct {
+	prefix  netip.Prefix
+	country string
+}
+
+// NewRegionLocator creates a new RegionLocator instance, loading the GeoIP database if any
+func NewRegionLocator(cfg config.ResidencyConfig) (*RegionLocator, error) {
+	locator := &RegionLocator{
+		regionHeader:  cfg.RegionHeader,
+		countryHeader: cfg.CountryHeader,
+		countries:     make(map[string]string, len(cfg.Countries)),
+	}
+	for country, region := range cfg.Countries {
+		locator.countries[strings.ToUpper(country)] = region
+	}
+
+	for region, networks := range cfg.Networks {
+		for _, network := range networks {
+			prefix, err := netip.ParsePrefix(network)
+			if err != nil {
+				return nil, fmt.Errorf("invalid network %s of region %s: %w", network, region, err)
+			}
+			locator.networks = append(locator.networks, regionalNetwork{prefix: prefix.Masked(), region: region})
+		}
+	}
+	sort.SliceStable(locator.networks, func(i, j int) bool {
+		return locator.networks[i].prefix.Bits() > locator.networks[j].prefix.Bits()
+	})
+
+	if cfg.GeoIPFile != "" {
+		geoIP, err := loadGeoIP(cfg.GeoIPFile)
+		if err != nil {
+			return nil, err
+		}
+		locator.geoIP = geoIP
+	}
+	return locator, nil
+}
+
+// loadGeoIP reads a CSV database of "network,country" lines. A header line and lines
+// starting with # are skipped, as are networks without a country.
+func loadGeoIP(path string) ([]countryNetwork, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open GeoIP database: %w", err)
+	}
+	defer file.Close()
+
+	var networks []countryNetwork
+	scanner := bufio.NewScanner(file)
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+		network, country, _ := strings.Cut(text, ",")
+		prefix, err := netip.ParsePrefix(strings.TrimSpace(network))
+		if err != nil {
+			if line == 1 {
+				continue
+			}
+			return nil, fmt.Errorf("invalid network on line %d of GeoIP database: %w", line, err)
+		}
+		if country = strings.ToUpper(strings.TrimSpace(country)); country != "" {
+			networks = append(networks, countryNetwork{prefix: prefix.Masked(), country: country})
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read GeoIP database: %w", err)
+	}
+
+	sort.Slice(networks, func(i, j int) bool {
+		return networks[i].prefix.Addr().Less(networks[j].prefix.Addr())
+	})
+	return networks, nil
+}
+
+// Locate returns the region of a request's caller, empty when it is unknown
+func (l *RegionLocator) Locate(ctx context.Context, request *entity.Request) string {
+	headers := http.Header(request.Headers)
+	if l.regionHeader != "" {
+		if region := strings.ToLower(strings.TrimSpace(headers.Get(l.regionHeader))); region != "" {
+			return region
+		}
+	}
+	if l.countryHeader != "" {
+		if region, ok := l.countries[strings.ToUpper(strings.TrimSpace(headers.Get(l.countryHeader)))]; ok {
+			return region
+		}
+	}
+
+	addr, ok := clientAddr(request.ClientIP)
+	if !ok {
+		return ""
+	}
+	for _, network := range l.networks {
+		if network.prefix.Contains(addr) {
+			return network.region
+		}
+	}
+	if country := l.country(addr); country != "" {
+		return l.countries[country]
+	}
+	return ""
+}
+
+// country returns the country of an address in the GeoIP database. Its networks do not
+// overlap, so the address can only be in the last network starting at or before it.
+func (l *RegionLocator) country(addr netip.Addr) string {
+	i := sort.Search(len(l.geoIP), func(i int) bool {
+		return addr.Less(l.geoIP[i].prefix.Addr())
+	})
+	if i > 0 && l.geoIP[i-1].prefix.Contains(addr) {
+		return l.geoIP[i-1].country
+	}
+	return ""
+}
+
+// clientAddr parses a client IP, which may carry a port
+func clientAddr(clientIP string) (netip.Addr, bool) {
+	if host, _, err := net.SplitHostPort(clientIP); err == nil {
+		clientIP = host
+	}
+	addr, err := netip.ParseAddr(clientIP)
+	if err != nil {
+		return netip.Addr{}, false
+	}
+	return addr.Unmap(), true
+}
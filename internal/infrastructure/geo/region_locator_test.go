@@ -0,0 +1,67 @@
+package geo
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"api-gateway-sample/internal/domain/entity"
+	"api-gateway-sample/pkg/config"
+)
+
+func TestRegionLocator_Locate(t *testing.T) {
+	geoIPFile := filepath.Join(t.TempDir(), "countries.csv")
+	require.NoError(t, os.WriteFile(geoIPFile, []byte(`network,country
+# test networks
+81.0.0.0/8,DE
+2a02:8100::/24,de
+98.0.0.0/8,US
+99.0.0.0/8,
+`), 0o600))
+
+	locator, err := NewRegionLocator(config.ResidencyConfig{
+		RegionHeader:  "X-Gateway-Region",
+		CountryHeader: "CF-IPCountry",
+		// Viper lowercases the keys of maps
+		Countries: map[string]string{"de": "eu", "fr": "eu", "us": "us"},
+		Networks:  map[string][]string{"eu": {"10.0.0.0/8"}, "us": {"10.1.0.0/16"}},
+		GeoIPFile: geoIPFile,
+	})
+	require.NoError(t, err)
+
+	tests := []struct {
+		name     string
+		headers  map[string][]string
+		clientIP string
+		want     string
+	}{
+		{"region header", map[string][]string{"X-Gateway-Region": {"APAC"}, "Cf-Ipcountry": {"FR"}}, "81.2.3.4", "apac"},
+		{"country header", map[string][]string{"Cf-Ipcountry": {"fr"}}, "98.2.3.4", "eu"},
+		{"most specific network", nil, "10.1.2.3:54321", "us"},
+		{"network", nil, "10.2.3.4", "eu"},
+		{"geoip", nil, "81.2.3.4", "eu"},
+		{"geoip ipv6", nil, "2a02:8100::1", "eu"},
+		{"geoip before the first network", nil, "1.2.3.4", ""},
+		{"geoip without country", nil, "99.2.3.4", ""},
+		{"country without region", map[string][]string{"Cf-Ipcountry": {"JP"}}, "98.2.3.4", "us"},
+		{"invalid client ip", nil, "unknown", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			request := entity.NewRequest("GET", "/api/v1/orders", tt.headers, nil, nil, tt.clientIP)
+			assert.Equal(t, tt.want, locator.Locate(context.Background(), request))
+		})
+	}
+}
+
+func TestNewRegionLocator_InvalidGeoIP(t *testing.T) {
+	geoIPFile := filepath.Join(t.TempDir(), "countries.csv")
+	require.NoError(t, os.WriteFile(geoIPFile, []byte("81.0.0.0/8,DE\nnot-a-network,FR\n"), 0o600))
+
+	_, err := NewRegionLocator(config.ResidencyConfig{GeoIPFile: geoIPFile})
+	assert.ErrorContains(t, err, "line 2")
+}
@@ -0,0 +1,97 @@
+package mail
+
+import (
+	"bufio"
+	"context"
+	"net"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeSMTPServer accepts a single session and returns the envelope and message it received
+func fakeSMTPServer(t *testing.T) (string, int, <-chan []string) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { listener.Close() })
+
+	received := make(chan []string, 1)
+	go func() {
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+
+		reader := bufio.NewReader(conn)
+		reply := func(line string) { conn.Write([]byte(line + "\r\n")) }
+		var lines []string
+		reply("220 localhost ESMTP")
+		for {
+			line, err := reader.ReadString('\n')
+			if err != nil {
+				return
+			}
+			line = strings.TrimRight(line, "\r\n")
+			switch {
+			case strings.HasPrefix(line, "EHLO"):
+				reply("250 localhost")
+			case strings.HasPrefix(line, "MAIL"), strings.HasPrefix(line, "RCPT"):
+				lines = append(lines, line)
+				reply("250 OK")
+			case line == "DATA":
+				reply("354 End data with <CR><LF>.<CR><LF>")
+				for {
+					data, err := reader.ReadString('\n')
+					if err != nil || data == ".\r\n" {
+						break
+					}
+					lines = append(lines, strings.TrimRight(data, "\r\n"))
+				}
+				reply("250 OK")
+			case line == "QUIT":
+				reply("221 Bye")
+				received <- lines
+				return
+			default:
+				reply("502 Unsupported")
+			}
+		}
+	}()
+
+	host, port, err := net.SplitHostPort(listener.Addr().String())
+	require.NoError(t, err)
+	portNumber, err := strconv.Atoi(port)
+	require.NoError(t, err)
+	return host, portNumber, received
+}
+
+func TestSMTPMailer_Send(t *testing.T) {
+	host, port, received := fakeSMTPServer(t)
+	mailer := NewSMTPMailer(host, port, "", "", "gateway@example.com", 5*time.Second)
+	mailer.now = func() time.Time { return time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC) }
+
+	err := mailer.Send(context.Background(), "dev@example.com", "Your API key\r\nBcc: evil@example.com", "Hello\nKey: gw_123")
+	require.NoError(t, err)
+
+	lines := <-received
+	assert.Equal(t, "MAIL FROM:<gateway@example.com>", lines[0])
+	assert.Equal(t, "RCPT TO:<dev@example.com>", lines[1])
+	assert.Contains(t, lines, "Subject: Your API key  Bcc: evil@example.com")
+	assert.Contains(t, lines, "Date: Fri, 02 Jan 2026 03:04:05 +0000")
+	assert.Equal(t, []string{"Hello", "Key: gw_123"}, lines[len(lines)-2:])
+}
+
+func TestSMTPMailer_ConnectionRefused(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	port := listener.Addr().(*net.TCPAddr).Port
+	listener.Close()
+
+	mailer := NewSMTPMailer("127.0.0.1", port, "", "", "gateway@example.com", time.Second)
+	assert.Error(t, mailer.Send(context.Background(), "dev@example.com", "subject", "body"))
+}
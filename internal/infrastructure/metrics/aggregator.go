@@ -0,0 +1,420 @@
+package metrics
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"api-gateway-sample/internal/domain/entity"
+)
+
+// SlidingWindowAggregator implements the MetricsCollector interface by keeping per-service
+// counters in a ring of time buckets covering the rolling window. Upstream latencies are
+// additionally kept per target in cumulative histograms, and requests and cache lookups per
+// endpoint in cumulative counters. Endpoints with an SLO get their own ring of buckets to track
+// compliance.
+type SlidingWindowAggregator struct {
+	mu         sync.Mutex
+	window     time.Duration
+	bucketSize time.Duration
+	services   map[string]*serviceWindow
+	targets    map[string]*targetHistogram
+	caches     map[endpointKey]*entity.EndpointCacheStats
+	routes     map[endpointKey]*entity.RouteRequests
+	slos       map[endpointKey]*sloWindow
+	// connections holds the connection phases of each service's upstream requests
+	connections map[string]*connectionStats
+	now         func() time.Time
+}
+
+// endpointKey identifies an endpoint of a service
+type endpointKey struct {
+	serviceID string
+	endpoint  string
+}
+
+// targetHistogram holds the upstream latency of one target since startup
+type targetHistogram struct {
+	latency  *Histogram
+	sumMs    float64
+	failures int64
+}
+
+// serviceWindow is the ring of buckets for one service
+type serviceWindow struct {
+	buckets []windowBucket
+}
+
+// sloWindow is the ring of buckets for one endpoint with an SLO
+type sloWindow struct {
+	slo    entity.SLO
+	tags   map[string]string
+	window serviceWindow
+}
+
+// windowBucket holds the counters for one slice of the window
+type windowBucket struct {
+	slot        int64
+	requests    int64
+	errors      int64
+	cacheHits   int64
+	cacheLookup int64
+	rateLimited int64
+	// breaches, requestBytes and responseBytes are only counted in SLO windows
+	breaches      int64
+	requestBytes  int64
+	responseBytes int64
+	latency       *Histogram
+}
+
+// NewSlidingWindowAggregator creates a new SlidingWindowAggregator instance covering window
+// in the given number of buckets
+func NewSlidingWindowAggregator(window time.Duration, buckets int) *SlidingWindowAggregator {
+	if buckets <= 0 {
+		buckets = 60
+	}
+	bucketSize := window / time.Duration(buckets)
+	if bucketSize <= 0 {
+		bucketSize = time.Second
+	}
+	return &SlidingWindowAggregator{
+		window:      bucketSize * time.Duration(buckets),
+		bucketSize:  bucketSize,
+		services:    make(map[string]*serviceWindow),
+		targets:     make(map[string]*targetHistogram),
+		caches:      make(map[endpointKey]*entity.EndpointCacheStats),
+		routes:      make(map[endpointKey]*entity.RouteRequests),
+		slos:        make(map[endpointKey]*sloWindow),
+		connections: make(map[string]*connectionStats),
+		now:         time.Now,
+	}
+}
+
+// RecordRequest adds a request outcome to the service's current bucket
+func (a *SlidingWindowAggregator) RecordRequest(sample *entity.RequestSample) {
+	if sample == nil || sample.ServiceID == "" {
+		return
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	bucket := a.bucket(sample.ServiceID)
+	bucket.requests++
+	if sample.IsError() {
+		bucket.errors++
+	}
+	if sample.RateLimited {
+		bucket.rateLimited++
+	}
+	switch sample.CacheStatus {
+	case entity.CacheStatusHit, entity.CacheStatusRevalidated:
+		bucket.cacheHits++
+		bucket.cacheLookup++
+	case entity.CacheStatusMiss, entity.CacheStatusStale:
+		bucket.cacheLookup++
+	}
+	bucket.latency.Observe(sample.Latency)
+
+	if sample.Target != "" {
+		a.recordTarget(sample)
+	}
+	if sample.Endpoint != "" {
+		a.recordRoute(sample)
+	}
+	if sample.CacheStatus != entity.CacheStatusBypass && sample.CacheStatus != "" && sample.Endpoint != "" {
+		a.recordCache(sample)
+	}
+	if sample.SLO != nil && sample.Endpoint != "" {
+		a.recordSLO(sample)
+	}
+	if sample.Timings != nil {
+		a.recordConnection(sample)
+	}
+}
+
+// recordSLO adds a request outcome to its endpoint's SLO window. A changed SLO applies to the
+// requests already in the window.
+func (a *SlidingWindowAggregator) recordSLO(sample *entity.RequestSample) {
+	key := endpointKey{serviceID: sample.ServiceID, endpoint: sample.Endpoint}
+	sw, ok := a.slos[key]
+	if !ok {
+		sw = &sloWindow{window: a.newWindow()}
+		a.slos[key] = sw
+	}
+	sw.slo = *sample.SLO
+	sw.tags = sample.Tags
+
+	bucket := a.currentBucket(&sw.window)
+	bucket.requests++
+	if sample.SLO.Breached(sample.StatusCode, sample.Latency) {
+		bucket.breaches++
+	}
+	bucket.requestBytes += int64(sample.RequestBytes)
+	bucket.responseBytes += int64(sample.ResponseBytes)
+	bucket.latency.Observe(sample.Latency)
+}
+
+// SLOStatuses returns the compliance of every endpoint with an SLO over the rolling window,
+// sorted by service and endpoint
+func (a *SlidingWindowAggregator) SLOStatuses() []*entity.SLOStatus {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	statuses := make([]*entity.SLOStatus, 0, len(a.slos))
+	current := a.slot()
+	for key, sw := range a.slos {
+		status := &entity.SLOStatus{
+			ServiceID:     key.serviceID,
+			Endpoint:      key.endpoint,
+			Objective:     sw.slo.Objective,
+			LatencyMs:     sw.slo.LatencyMs,
+			WindowSeconds: a.window.Seconds(),
+			Tags:          sw.tags,
+			// An empty window has spent none of the budget
+			Compliance:      1,
+			BudgetRemaining: 1,
+		}
+
+		var requestBytes, responseBytes int64
+		latency := NewHistogram()
+		for i := range sw.window.buckets {
+			bucket := &sw.window.buckets[i]
+			if bucket.latency == nil || current-bucket.slot >= int64(len(sw.window.buckets)) {
+				continue
+			}
+			status.Requests += bucket.requests
+			status.Breaches += bucket.breaches
+			requestBytes += bucket.requestBytes
+			responseBytes += bucket.responseBytes
+			latency.Merge(bucket.latency)
+		}
+
+		if status.Requests > 0 {
+			breachRate := float64(status.Breaches) / float64(status.Requests)
+			status.Compliance = 1 - breachRate
+			status.BurnRate = breachRate / (1 - sw.slo.Objective)
+			status.BudgetRemaining = 1 - status.BurnRate
+			status.AvgRequestBytes = float64(requestBytes) / float64(status.Requests)
+			status.AvgResponseBytes = float64(responseBytes) / float64(status.Requests)
+		}
+		status.LatencyP99Ms = latency.Percentile(0.99)
+		statuses = append(statuses, status)
+	}
+	sort.Slice(statuses, func(i, j int) bool {
+		if statuses[i].ServiceID != statuses[j].ServiceID {
+			return statuses[i].ServiceID < statuses[j].ServiceID
+		}
+		return statuses[i].Endpoint < statuses[j].Endpoint
+	})
+	return statuses
+}
+
+// recordCache counts the cache lookup of a request against its endpoint
+func (a *SlidingWindowAggregator) recordCache(sample *entity.RequestSample) {
+	key := endpointKey{serviceID: sample.ServiceID, endpoint: sample.Endpoint}
+	stats, ok := a.caches[key]
+	if !ok {
+		stats = &entity.EndpointCacheStats{ServiceID: sample.ServiceID, Endpoint: sample.Endpoint}
+		a.caches[key] = stats
+	}
+	stats.Tags = sample.Tags
+	switch sample.CacheStatus {
+	case entity.CacheStatusHit:
+		stats.Hits++
+	case entity.CacheStatusMiss:
+		stats.Misses++
+	case entity.CacheStatusStale:
+		stats.Stale++
+	case entity.CacheStatusRevalidated:
+		stats.Revalidated++
+	}
+}
+
+// CacheStats returns the cache lookups of every endpoint, sorted by service and endpoint
+func (a *SlidingWindowAggregator) CacheStats() []*entity.EndpointCacheStats {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	stats := make([]*entity.EndpointCacheStats, 0, len(a.caches))
+	for _, counters := range a.caches {
+		endpoint := *counters
+		if lookups := endpoint.Hits + endpoint.Misses + endpoint.Stale + endpoint.Revalidated; lookups > 0 {
+			endpoint.HitRatio = float64(endpoint.Hits+endpoint.Revalidated) / float64(lookups)
+		}
+		stats = append(stats, &endpoint)
+	}
+	sort.Slice(stats, func(i, j int) bool {
+		if stats[i].ServiceID != stats[j].ServiceID {
+			return stats[i].ServiceID < stats[j].ServiceID
+		}
+		return stats[i].Endpoint < stats[j].Endpoint
+	})
+	return stats
+}
+
+// recordRoute counts a request against its endpoint by status class
+func (a *SlidingWindowAggregator) recordRoute(sample *entity.RequestSample) {
+	key := endpointKey{serviceID: sample.ServiceID, endpoint: sample.Endpoint}
+	route, ok := a.routes[key]
+	if !ok {
+		route = &entity.RouteRequests{ServiceID: sample.ServiceID, Endpoint: sample.Endpoint, Classes: make(map[string]int64)}
+		a.routes[key] = route
+	}
+	route.Tags = sample.Tags
+	route.Classes[fmt.Sprintf("%dxx", sample.StatusCode/100)]++
+}
+
+// RouteRequests returns the requests of every endpoint by status class, sorted by service and endpoint
+func (a *SlidingWindowAggregator) RouteRequests() []*entity.RouteRequests {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	routes := make([]*entity.RouteRequests, 0, len(a.routes))
+	for _, counters := range a.routes {
+		route := *counters
+		route.Classes = make(map[string]int64, len(counters.Classes))
+		for class, count := range counters.Classes {
+			route.Classes[class] = count
+		}
+		routes = append(routes, &route)
+	}
+	sort.Slice(routes, func(i, j int) bool {
+		if routes[i].ServiceID != routes[j].ServiceID {
+			return routes[i].ServiceID < routes[j].ServiceID
+		}
+		return routes[i].Endpoint < routes[j].Endpoint
+	})
+	return routes
+}
+
+// recordTarget adds the upstream latency of a request to its target's histogram
+func (a *SlidingWindowAggregator) recordTarget(sample *entity.RequestSample) {
+	target, ok := a.targets[sample.Target]
+	if !ok {
+		target = &targetHistogram{latency: NewHistogram()}
+		a.targets[sample.Target] = target
+	}
+	target.latency.Observe(sample.UpstreamLatency)
+	target.sumMs += float64(sample.UpstreamLatency) / float64(time.Millisecond)
+	if sample.UpstreamFailed {
+		target.failures++
+	}
+}
+
+// TargetLatencies returns the upstream latency histogram of every target, sorted by target
+func (a *SlidingWindowAggregator) TargetLatencies() []*entity.TargetLatency {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	latencies := make([]*entity.TargetLatency, 0, len(a.targets))
+	for name, target := range a.targets {
+		latencies = append(latencies, &entity.TargetLatency{
+			Target:    name,
+			BucketsMs: LatencyBucketsMs,
+			Counts:    target.latency.Cumulative(),
+			Count:     target.latency.Count(),
+			SumMs:     target.sumMs,
+			Failures:  target.failures,
+		})
+	}
+	sort.Slice(latencies, func(i, j int) bool {
+		return latencies[i].Target < latencies[j].Target
+	})
+	return latencies
+}
+
+// ServiceIDs returns the services that have recorded requests, sorted by ID
+func (a *SlidingWindowAggregator) ServiceIDs() []string {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	ids := make([]string, 0, len(a.services))
+	for id := range a.services {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	return ids
+}
+
+// ServiceStats returns the statistics of a service over the rolling window
+func (a *SlidingWindowAggregator) ServiceStats(serviceID string) *entity.ServiceStats {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	stats := &entity.ServiceStats{
+		ServiceID:     serviceID,
+		Window:        a.window,
+		WindowSeconds: a.window.Seconds(),
+	}
+
+	sw, ok := a.services[serviceID]
+	if !ok {
+		return stats
+	}
+
+	var cacheLookups int64
+	latency := NewHistogram()
+	current := a.slot()
+	for i := range sw.buckets {
+		bucket := &sw.buckets[i]
+		if bucket.latency == nil || current-bucket.slot >= int64(len(sw.buckets)) {
+			continue
+		}
+		stats.Requests += bucket.requests
+		stats.Errors += bucket.errors
+		stats.CacheHits += bucket.cacheHits
+		stats.RateLimitRejections += bucket.rateLimited
+		cacheLookups += bucket.cacheLookup
+		latency.Merge(bucket.latency)
+	}
+
+	stats.RequestRate = float64(stats.Requests) / a.window.Seconds()
+	if stats.Requests > 0 {
+		stats.ErrorRate = float64(stats.Errors) / float64(stats.Requests)
+	}
+	if cacheLookups > 0 {
+		stats.CacheHitRatio = float64(stats.CacheHits) / float64(cacheLookups)
+	}
+	stats.LatencyP50Ms = latency.Percentile(0.50)
+	stats.LatencyP95Ms = latency.Percentile(0.95)
+	stats.LatencyP99Ms = latency.Percentile(0.99)
+	return stats
+}
+
+// slot returns the index of the current bucket since the epoch
+func (a *SlidingWindowAggregator) slot() int64 {
+	return a.now().UnixNano() / int64(a.bucketSize)
+}
+
+// newWindow returns an empty ring of buckets covering the window
+func (a *SlidingWindowAggregator) newWindow() serviceWindow {
+	return serviceWindow{buckets: make([]windowBucket, int(a.window/a.bucketSize))}
+}
+
+// bucket returns the service's bucket for the current slot
+func (a *SlidingWindowAggregator) bucket(serviceID string) *windowBucket {
+	sw, ok := a.services[serviceID]
+	if !ok {
+		window := a.newWindow()
+		sw = &window
+		a.services[serviceID] = sw
+	}
+	return a.currentBucket(sw)
+}
+
+// currentBucket returns the window's bucket for the current slot, resetting it if it holds stale data
+func (a *SlidingWindowAggregator) currentBucket(sw *serviceWindow) *windowBucket {
+	slot := a.slot()
+	bucket := &sw.buckets[slot%int64(len(sw.buckets))]
+	if bucket.latency == nil {
+		bucket.latency = NewHistogram()
+	}
+	if bucket.slot != slot {
+		latency := bucket.latency
+		latency.Reset()
+		*bucket = windowBucket{slot: slot, latency: latency}
+	}
+	return bucket
+}
@@ -0,0 +1,264 @@
+package metrics
+
+import (
+	"testing"
+	"time"
+
+	"api-gateway-sample/internal/domain/entity"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHistogram_Percentile(t *testing.T) {
+	h := NewHistogram()
+	assert.Equal(t, 0.0, h.Percentile(0.5))
+
+	for i := 0; i < 90; i++ {
+		h.Observe(3 * time.Millisecond)
+	}
+	for i := 0; i < 10; i++ {
+		h.Observe(400 * time.Millisecond)
+	}
+
+	assert.Equal(t, int64(100), h.Count())
+	assert.InDelta(t, 3.67, h.Percentile(0.50), 0.01)
+	assert.InDelta(t, 375, h.Percentile(0.95), 0.01)
+	assert.InDelta(t, 475, h.Percentile(0.99), 0.01)
+
+	// Observations beyond the largest bucket are reported at that bound
+	h.Reset()
+	h.Observe(time.Minute)
+	assert.Equal(t, 10000.0, h.Percentile(0.99))
+}
+
+func TestSlidingWindowAggregator_ServiceStats(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	aggregator := NewSlidingWindowAggregator(time.Minute, 6)
+	aggregator.now = func() time.Time { return now }
+
+	record := func(status int, cache string, rateLimited bool) {
+		aggregator.RecordRequest(&entity.RequestSample{
+			ServiceID:   "svc-1",
+			StatusCode:  status,
+			Latency:     20 * time.Millisecond,
+			CacheStatus: cache,
+			RateLimited: rateLimited,
+		})
+	}
+
+	// 1. Record a mix of outcomes
+	record(200, entity.CacheStatusHit, false)
+	record(200, entity.CacheStatusMiss, false)
+	record(502, entity.CacheStatusMiss, false)
+	record(500, entity.CacheStatusBypass, true)
+
+	stats := aggregator.ServiceStats("svc-1")
+	assert.Equal(t, int64(4), stats.Requests)
+	assert.Equal(t, int64(2), stats.Errors)
+	assert.Equal(t, 0.5, stats.ErrorRate)
+	assert.InDelta(t, 4.0/60, stats.RequestRate, 0.0001)
+	assert.InDelta(t, 1.0/3, stats.CacheHitRatio, 0.0001)
+	assert.Equal(t, int64(1), stats.RateLimitRejections)
+	assert.InDelta(t, 20, stats.LatencyP50Ms, 5)
+
+	// 2. Requests leave the window once it has rolled past them
+	now = now.Add(50 * time.Second)
+	record(200, entity.CacheStatusBypass, false)
+	assert.Equal(t, int64(5), aggregator.ServiceStats("svc-1").Requests)
+
+	now = now.Add(20 * time.Second)
+	assert.Equal(t, int64(1), aggregator.ServiceStats("svc-1").Requests)
+
+	// 3. Unknown services report empty statistics
+	assert.Equal(t, int64(0), aggregator.ServiceStats("svc-2").Requests)
+}
+
+func TestSlidingWindowAggregator_TargetLatencies(t *testing.T) {
+	aggregator := NewSlidingWindowAggregator(time.Minute, 6)
+
+	record := func(target string, latency time.Duration, failed bool) {
+		aggregator.RecordRequest(&entity.RequestSample{
+			ServiceID:       "svc-1",
+			StatusCode:      200,
+			Target:          target,
+			UpstreamLatency: latency,
+			UpstreamFailed:  failed,
+		})
+	}
+
+	record("http://10.0.0.2:8080", 4*time.Millisecond, false)
+	record("http://10.0.0.1:8080", 8*time.Millisecond, false)
+	record("http://10.0.0.2:8080", 3*time.Second, true)
+
+	// Requests served by the gateway itself have no target
+	aggregator.RecordRequest(&entity.RequestSample{ServiceID: "svc-1", StatusCode: 200})
+
+	latencies := aggregator.TargetLatencies()
+	if assert.Len(t, latencies, 2) {
+		assert.Equal(t, "http://10.0.0.1:8080", latencies[0].Target)
+
+		slow := latencies[1]
+		assert.Equal(t, "http://10.0.0.2:8080", slow.Target)
+		assert.Equal(t, int64(2), slow.Count)
+		assert.Equal(t, int64(1), slow.Failures)
+		assert.InDelta(t, 3004, slow.SumMs, 0.001)
+		// Counts are cumulative: one request at or below 5ms, both at or below 5s
+		assert.Equal(t, int64(1), slow.Counts[2])
+		assert.Equal(t, int64(2), slow.Counts[len(slow.Counts)-2])
+	}
+}
+
+func TestSlidingWindowAggregator_CacheStats(t *testing.T) {
+	aggregator := NewSlidingWindowAggregator(time.Minute, 6)
+
+	record := func(endpoint string, cacheStatus string) {
+		aggregator.RecordRequest(&entity.RequestSample{
+			ServiceID:   "svc-1",
+			Endpoint:    endpoint,
+			StatusCode:  200,
+			CacheStatus: cacheStatus,
+		})
+	}
+
+	record("/api/v1/users", entity.CacheStatusMiss)
+	record("/api/v1/users", entity.CacheStatusHit)
+	record("/api/v1/users", entity.CacheStatusHit)
+	record("/api/v1/users", entity.CacheStatusRevalidated)
+	record("/api/v1/orders", entity.CacheStatusStale)
+
+	// Endpoints without caching are not reported
+	record("/api/v1/payments", entity.CacheStatusBypass)
+
+	stats := aggregator.CacheStats()
+	if assert.Len(t, stats, 2) {
+		assert.Equal(t, "/api/v1/orders", stats[0].Endpoint)
+		assert.Equal(t, int64(1), stats[0].Stale)
+		assert.Equal(t, 0.0, stats[0].HitRatio)
+
+		users := stats[1]
+		assert.Equal(t, "/api/v1/users", users.Endpoint)
+		assert.Equal(t, int64(2), users.Hits)
+		assert.Equal(t, int64(1), users.Misses)
+		assert.Equal(t, int64(1), users.Revalidated)
+		assert.InDelta(t, 0.75, users.HitRatio, 0.001)
+	}
+}
+
+func TestSlidingWindowAggregator_RouteRequests(t *testing.T) {
+	aggregator := NewSlidingWindowAggregator(time.Minute, 6)
+
+	record := func(endpoint string, status int, tags map[string]string) {
+		aggregator.RecordRequest(&entity.RequestSample{
+			ServiceID:  "svc-1",
+			Endpoint:   endpoint,
+			StatusCode: status,
+			Tags:       tags,
+		})
+	}
+
+	payments := map[string]string{"team": "payments"}
+	record("/api/v1/users", 200, nil)
+	record("/api/v1/payments", 200, payments)
+	record("/api/v1/payments", 201, payments)
+	record("/api/v1/payments", 503, payments)
+
+	// Requests matching no endpoint are not reported per route
+	record("", 404, nil)
+
+	routes := aggregator.RouteRequests()
+	if assert.Len(t, routes, 2) {
+		assert.Equal(t, "/api/v1/payments", routes[0].Endpoint)
+		assert.Equal(t, payments, routes[0].Tags)
+		assert.Equal(t, map[string]int64{"2xx": 2, "5xx": 1}, routes[0].Classes)
+
+		assert.Equal(t, "/api/v1/users", routes[1].Endpoint)
+		assert.Nil(t, routes[1].Tags)
+		assert.Equal(t, map[string]int64{"2xx": 1}, routes[1].Classes)
+	}
+}
+
+func TestSlidingWindowAggregator_SLOStatuses(t *testing.T) {
+	aggregator := NewSlidingWindowAggregator(time.Minute, 6)
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	aggregator.now = func() time.Time { return now }
+
+	slo := &entity.SLO{Objective: 0.9, LatencyMs: 100}
+	record := func(statusCode int, latency time.Duration) {
+		aggregator.RecordRequest(&entity.RequestSample{
+			ServiceID:     "svc-1",
+			Endpoint:      "/api/v1/users",
+			StatusCode:    statusCode,
+			Latency:       latency,
+			SLO:           slo,
+			RequestBytes:  100,
+			ResponseBytes: 1000,
+		})
+	}
+
+	for i := 0; i < 8; i++ {
+		record(200, 10*time.Millisecond)
+	}
+	record(200, 250*time.Millisecond)
+	record(503, 10*time.Millisecond)
+
+	// Endpoints without an SLO are not reported
+	aggregator.RecordRequest(&entity.RequestSample{ServiceID: "svc-1", Endpoint: "/api/v1/orders", StatusCode: 500})
+
+	statuses := aggregator.SLOStatuses()
+	if assert.Len(t, statuses, 1) {
+		status := statuses[0]
+		assert.Equal(t, "/api/v1/users", status.Endpoint)
+		assert.Equal(t, int64(10), status.Requests)
+		assert.Equal(t, int64(2), status.Breaches)
+		assert.InDelta(t, 0.8, status.Compliance, 0.001)
+		// 20% of requests breached against a 10% budget
+		assert.InDelta(t, 2.0, status.BurnRate, 0.001)
+		assert.InDelta(t, -1.0, status.BudgetRemaining, 0.001)
+		assert.InDelta(t, 100, status.AvgRequestBytes, 0.001)
+		assert.InDelta(t, 1000, status.AvgResponseBytes, 0.001)
+	}
+
+	// Once the breaches leave the window, the budget is whole again
+	now = now.Add(2 * time.Minute)
+	statuses = aggregator.SLOStatuses()
+	if assert.Len(t, statuses, 1) {
+		assert.Equal(t, int64(0), statuses[0].Requests)
+		assert.Equal(t, 1.0, statuses[0].BudgetRemaining)
+	}
+}
+
+func TestSlidingWindowAggregator_UpstreamConnections(t *testing.T) {
+	aggregator := NewSlidingWindowAggregator(time.Minute, 6)
+
+	record := func(serviceID string, timings *entity.UpstreamTimings) {
+		aggregator.RecordRequest(&entity.RequestSample{ServiceID: serviceID, StatusCode: 200, Timings: timings})
+	}
+
+	record("orders", &entity.UpstreamTimings{DNS: 2 * time.Millisecond, Connect: 3 * time.Millisecond, TLS: 20 * time.Millisecond, FirstByte: 40 * time.Millisecond})
+	record("orders", &entity.UpstreamTimings{FirstByte: 10 * time.Millisecond, Reused: true})
+	record("orders", &entity.UpstreamTimings{FirstByte: 12 * time.Millisecond, Reused: true})
+	record("billing", &entity.UpstreamTimings{Connect: time.Millisecond, FirstByte: 5 * time.Millisecond})
+
+	// Cached responses are not measured
+	record("orders", nil)
+
+	connections := aggregator.UpstreamConnections()
+	if assert.Len(t, connections, 2) {
+		assert.Equal(t, "billing", connections[0].ServiceID)
+		assert.NotContains(t, connections[0].Phases, entity.ConnectionPhaseTLS)
+
+		orders := connections[1]
+		assert.Equal(t, "orders", orders.ServiceID)
+		assert.Equal(t, int64(2), orders.Reused)
+		assert.Equal(t, int64(1), orders.Opened)
+		// Only the request that opened a connection went through the DNS, connect and TLS phases
+		assert.Equal(t, int64(1), orders.Phases[entity.ConnectionPhaseDNS].Count)
+		assert.Equal(t, int64(1), orders.Phases[entity.ConnectionPhaseTLS].Count)
+		firstByte := orders.Phases[entity.ConnectionPhaseFirstByte]
+		assert.Equal(t, int64(3), firstByte.Count)
+		assert.InDelta(t, 62, firstByte.SumMs, 0.001)
+		// Counts are cumulative: two requests at or below 25ms, all three at or below 50ms
+		assert.Equal(t, int64(2), firstByte.Counts[4])
+		assert.Equal(t, int64(3), firstByte.Counts[5])
+	}
+}
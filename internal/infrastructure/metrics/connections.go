@@ -0,0 +1,74 @@
+package metrics
+
+import (
+	"sort"
+	"time"
+
+	"api-gateway-sample/internal/domain/entity"
+)
+
+// connectionStats holds the connection phase histograms of one service since startup
+type connectionStats struct {
+	phases map[string]*phaseHistogram
+	reused int64
+	opened int64
+}
+
+// phaseHistogram holds the latency of one connection phase
+type phaseHistogram struct {
+	latency *Histogram
+	sumMs   float64
+}
+
+// recordConnection adds the connection phases of a request to its service's histograms
+func (a *SlidingWindowAggregator) recordConnection(sample *entity.RequestSample) {
+	stats, ok := a.connections[sample.ServiceID]
+	if !ok {
+		stats = &connectionStats{phases: make(map[string]*phaseHistogram)}
+		a.connections[sample.ServiceID] = stats
+	}
+	if sample.Timings.Reused {
+		stats.reused++
+	} else {
+		stats.opened++
+	}
+	for phase, duration := range sample.Timings.Phases() {
+		histogram, ok := stats.phases[phase]
+		if !ok {
+			histogram = &phaseHistogram{latency: NewHistogram()}
+			stats.phases[phase] = histogram
+		}
+		histogram.latency.Observe(duration)
+		histogram.sumMs += float64(duration) / float64(time.Millisecond)
+	}
+}
+
+// UpstreamConnections returns the connection phases and keep-alive reuse of every service,
+// sorted by service
+func (a *SlidingWindowAggregator) UpstreamConnections() []*entity.UpstreamConnectionStats {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	connections := make([]*entity.UpstreamConnectionStats, 0, len(a.connections))
+	for serviceID, stats := range a.connections {
+		phases := make(map[string]*entity.PhaseLatency, len(stats.phases))
+		for phase, histogram := range stats.phases {
+			phases[phase] = &entity.PhaseLatency{
+				BucketsMs: LatencyBucketsMs,
+				Counts:    histogram.latency.Cumulative(),
+				Count:     histogram.latency.Count(),
+				SumMs:     histogram.sumMs,
+			}
+		}
+		connections = append(connections, &entity.UpstreamConnectionStats{
+			ServiceID: serviceID,
+			Phases:    phases,
+			Reused:    stats.reused,
+			Opened:    stats.opened,
+		})
+	}
+	sort.Slice(connections, func(i, j int) bool {
+		return connections[i].ServiceID < connections[j].ServiceID
+	})
+	return connections
+}
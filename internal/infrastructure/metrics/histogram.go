@@ -0,0 +1,103 @@
+package metrics
+
+import (
+	"math"
+	"time"
+)
+
+// LatencyBucketsMs are the upper bounds, in milliseconds, of the latency histogram buckets
+var LatencyBucketsMs = []float64{1, 2, 5, 10, 25, 50, 100, 250, 500, 1000, 2500, 5000, 10000}
+
+// Histogram counts latencies in fixed buckets. The last count holds observations above
+// the largest bound. A Histogram is not safe for concurrent use.
+type Histogram struct {
+	counts []int64
+	total  int64
+}
+
+// NewHistogram creates a new Histogram instance
+func NewHistogram() *Histogram {
+	return &Histogram{counts: make([]int64, len(LatencyBucketsMs)+1)}
+}
+
+// Observe records a latency
+func (h *Histogram) Observe(latency time.Duration) {
+	ms := float64(latency) / float64(time.Millisecond)
+	i := 0
+	for i < len(LatencyBucketsMs) && ms > LatencyBucketsMs[i] {
+		i++
+	}
+	h.counts[i]++
+	h.total++
+}
+
+// Merge adds the observations of other to h
+func (h *Histogram) Merge(other *Histogram) {
+	for i, count := range other.counts {
+		h.counts[i] += count
+	}
+	h.total += other.total
+}
+
+// Reset discards all observations
+func (h *Histogram) Reset() {
+	for i := range h.counts {
+		h.counts[i] = 0
+	}
+	h.total = 0
+}
+
+// Count returns the number of observations
+func (h *Histogram) Count() int64 {
+	return h.total
+}
+
+// Counts returns the per-bucket observation counts
+func (h *Histogram) Counts() []int64 {
+	counts := make([]int64, len(h.counts))
+	copy(counts, h.counts)
+	return counts
+}
+
+// Cumulative returns the observations at or below each bound, in the Prometheus histogram
+// convention
+func (h *Histogram) Cumulative() []int64 {
+	cumulative := make([]int64, len(LatencyBucketsMs))
+	var total int64
+	for i := range LatencyBucketsMs {
+		total += h.counts[i]
+		cumulative[i] = total
+	}
+	return cumulative
+}
+
+// Percentile estimates the q-th percentile (0-1) in milliseconds by interpolating
+// linearly inside the bucket that contains it
+func (h *Histogram) Percentile(q float64) float64 {
+	if h.total == 0 {
+		return 0
+	}
+
+	rank := q * float64(h.total)
+	var cumulative int64
+	for i, count := range h.counts {
+		if count == 0 {
+			continue
+		}
+		if float64(cumulative+count) >= rank {
+			lower := 0.0
+			if i > 0 {
+				lower = LatencyBucketsMs[i-1]
+			}
+			// Observations above the largest bound are reported at that bound
+			if i == len(LatencyBucketsMs) {
+				return lower
+			}
+			upper := LatencyBucketsMs[i]
+			fraction := (rank - float64(cumulative)) / float64(count)
+			return math.Round((lower+(upper-lower)*fraction)*100) / 100
+		}
+		cumulative += count
+	}
+	return LatencyBucketsMs[len(LatencyBucketsMs)-1]
+}
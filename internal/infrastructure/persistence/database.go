@@ -21,7 +21,8 @@ func NewDatabase(config config.DatabaseConfig) (*gorm.DB, error) {
 		config.SSLMode,
 	)
 
-	db, err := gorm.Open(postgres.Open(dsn), &gorm.Config{})
+	// Constraint violations are translated, so repositories report taken keys as such
+	db, err := gorm.Open(postgres.Open(dsn), &gorm.Config{TranslateError: true})
 	if err != nil {
 		return nil, fmt.Errorf("failed to connect to database: %w", err)
 	}
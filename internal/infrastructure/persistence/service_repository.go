@@ -136,8 +136,9 @@ func (r *ServiceRepository) Update(ctx context.Context, service *entity.Service)
 	return nil
 }
 
-// Delete deletes a service by ID
-func (r *ServiceRepository) Delete(ctx context.Context, id string) error {
+// Delete deletes a service by ID. Revisions are not stored by this repository, so the revision
+// is not compared.
+func (r *ServiceRepository) Delete(ctx context.Context, id string, revision int64) error {
 	if err := r.db.WithContext(ctx).Delete(&ServiceModel{}, id).Error; err != nil {
 		if err == gorm.ErrRecordNotFound {
 			return errors.ErrNotFound
@@ -153,6 +154,41 @@ func (r *ServiceRepository) Delete(ctx context.Context, id string) error {
 	return nil
 }
 
+// Apply makes the changes of a change set in a single transaction
+func (r *ServiceRepository) Apply(ctx context.Context, changes repository.ServiceChangeSet) error {
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		txRepo := &ServiceRepository{db: tx, cache: r.cache}
+		for _, id := range changes.Delete {
+			if err := txRepo.Delete(ctx, id, 0); err != nil {
+				return err
+			}
+		}
+		for _, service := range changes.Update {
+			if err := txRepo.Update(ctx, service); err != nil {
+				return err
+			}
+		}
+		for _, service := range changes.Create {
+			if err := txRepo.Create(ctx, service); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		// Services cached by the rolled back changes are stale
+		for _, services := range [][]*entity.Service{changes.Update, changes.Create} {
+			for _, service := range services {
+				if service.ID != "" {
+					r.cache.Delete(ctx, r.getCacheKey(service.ID))
+				}
+			}
+		}
+		return err
+	}
+	return nil
+}
+
 // GetAll retrieves all services
 func (r *ServiceRepository) GetAll(ctx context.Context) ([]*entity.Service, error) {
 	var models []ServiceModel
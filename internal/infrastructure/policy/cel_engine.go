@@ -0,0 +1,100 @@
+package policy
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"api-gateway-sample/internal/domain/entity"
+
+	"github.com/google/cel-go/cel"
+)
+
+// DefaultPolicy grants access to admins and to holders of the "<service>:<endpoint>" role
+const DefaultPolicy = `"admin" in roles || (service + ":" + endpoint) in roles`
+
+// CELEngine implements the PolicyEngine interface using the Common Expression Language.
+// Policies are boolean expressions over the variables user, roles, scopes, claims,
+// method, path, headers, service and endpoint.
+type CELEngine struct {
+	env      *cel.Env
+	programs sync.Map // policy expression -> cel.Program
+}
+
+// NewCELEngine creates a new CELEngine instance
+func NewCELEngine() (*CELEngine, error) {
+	env, err := cel.NewEnv(
+		cel.Variable("user", cel.StringType),
+		cel.Variable("roles", cel.ListType(cel.StringType)),
+		cel.Variable("scopes", cel.ListType(cel.StringType)),
+		cel.Variable("claims", cel.MapType(cel.StringType, cel.DynType)),
+		cel.Variable("method", cel.StringType),
+		cel.Variable("path", cel.StringType),
+		cel.Variable("headers", cel.MapType(cel.StringType, cel.StringType)),
+		cel.Variable("service", cel.StringType),
+		cel.Variable("endpoint", cel.StringType),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create policy environment: %w", err)
+	}
+
+	return &CELEngine{env: env}, nil
+}
+
+// Compile checks that a policy is a valid boolean expression
+func (e *CELEngine) Compile(policy string) error {
+	_, err := e.program(policy)
+	return err
+}
+
+// Evaluate reports whether the policy allows the input
+func (e *CELEngine) Evaluate(ctx context.Context, policy string, input *entity.PolicyInput) (bool, error) {
+	program, err := e.program(policy)
+	if err != nil {
+		return false, err
+	}
+
+	result, _, err := program.ContextEval(ctx, map[string]interface{}{
+		"user":     input.UserID,
+		"roles":    input.Roles,
+		"scopes":   input.Scopes,
+		"claims":   input.Claims,
+		"method":   input.Method,
+		"path":     input.Path,
+		"headers":  input.Headers,
+		"service":  input.Service,
+		"endpoint": input.Endpoint,
+	})
+	if err != nil {
+		return false, fmt.Errorf("failed to evaluate policy: %w", err)
+	}
+
+	allowed, ok := result.Value().(bool)
+	if !ok {
+		return false, fmt.Errorf("policy did not evaluate to a boolean")
+	}
+	return allowed, nil
+}
+
+// program returns the compiled program for a policy, compiling it on first use
+func (e *CELEngine) program(policy string) (cel.Program, error) {
+	if cached, ok := e.programs.Load(policy); ok {
+		return cached.(cel.Program), nil
+	}
+
+	ast, issues := e.env.Compile(policy)
+	if issues != nil && issues.Err() != nil {
+		return nil, fmt.Errorf("invalid policy: %w", issues.Err())
+	}
+	if ast.OutputType() != cel.BoolType {
+		return nil, fmt.Errorf("invalid policy: expression must evaluate to a boolean, got %s", ast.OutputType())
+	}
+
+	program, err := e.env.Program(ast)
+	if err != nil {
+		return nil, fmt.Errorf("invalid policy: %w", err)
+	}
+
+	e.programs.Store(policy, program)
+	return program, nil
+}
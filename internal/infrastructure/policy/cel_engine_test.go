@@ -0,0 +1,73 @@
+package policy
+
+import (
+	"context"
+	"testing"
+
+	"api-gateway-sample/internal/domain/entity"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCELEngine_Evaluate(t *testing.T) {
+	engine, err := NewCELEngine()
+	require.NoError(t, err)
+
+	input := &entity.PolicyInput{
+		UserID:   "user-1",
+		Roles:    []string{"users-service:/api/v1/users"},
+		Scopes:   []string{"read:users"},
+		Claims:   map[string]interface{}{"tenant": "acme"},
+		Method:   "GET",
+		Path:     "/api/v1/users",
+		Headers:  map[string]string{"X-Tenant": "acme"},
+		Service:  "users-service",
+		Endpoint: "/api/v1/users",
+	}
+
+	tests := []struct {
+		name    string
+		policy  string
+		allowed bool
+	}{
+		{
+			name:    "default policy grants endpoint role",
+			policy:  DefaultPolicy,
+			allowed: true,
+		},
+		{
+			name:    "scope and method",
+			policy:  `"read:users" in scopes && method == "GET"`,
+			allowed: true,
+		},
+		{
+			name:    "claim matches header",
+			policy:  `claims.tenant == headers["X-Tenant"]`,
+			allowed: true,
+		},
+		{
+			name:    "missing role",
+			policy:  `"admin" in roles`,
+			allowed: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			allowed, err := engine.Evaluate(context.Background(), tt.policy, input)
+			require.NoError(t, err)
+			assert.Equal(t, tt.allowed, allowed)
+		})
+	}
+}
+
+func TestCELEngine_Compile(t *testing.T) {
+	engine, err := NewCELEngine()
+	require.NoError(t, err)
+
+	assert.NoError(t, engine.Compile(DefaultPolicy))
+	assert.Error(t, engine.Compile(`roles +`), "syntax errors should be rejected")
+	assert.Error(t, engine.Compile(`user`), "non-boolean policies should be rejected")
+	assert.Error(t, engine.Compile(`unknown == "x"`), "undeclared variables should be rejected")
+}
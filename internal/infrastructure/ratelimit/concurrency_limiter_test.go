@@ -0,0 +1,62 @@
+package ratelimit
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"api-gateway-sample/internal/domain/service"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConcurrencyLimiters(t *testing.T) {
+	server := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: server.Addr()})
+	defer client.Close()
+
+	limiters := map[string]service.ConcurrencyLimiter{
+		"redis":  NewRedisConcurrencyLimiter(client, time.Minute, nopLogger{}),
+		"memory": NewInMemoryConcurrencyLimiter(),
+	}
+	for name, limiter := range limiters {
+		t.Run(name, func(t *testing.T) {
+			ctx := context.Background()
+			key := "concurrency:svc-1:/api/v1/reports:" + name
+
+			// 1. Slots are taken until the limit is reached
+			for i := 0; i < 2; i++ {
+				acquired, err := limiter.Acquire(ctx, key, 2)
+				require.NoError(t, err)
+				assert.True(t, acquired)
+			}
+			acquired, err := limiter.Acquire(ctx, key, 2)
+			require.NoError(t, err)
+			assert.False(t, acquired)
+
+			// 2. A released slot can be taken again
+			require.NoError(t, limiter.Release(ctx, key))
+			acquired, err = limiter.Acquire(ctx, key, 2)
+			require.NoError(t, err)
+			assert.True(t, acquired)
+		})
+	}
+
+	t.Run("redis slots expire", func(t *testing.T) {
+		ctx := context.Background()
+		limiter := NewRedisConcurrencyLimiter(client, time.Minute, nopLogger{})
+		key := "concurrency:svc-1:/api/v1/reports:expiry"
+
+		acquired, err := limiter.Acquire(ctx, key, 1)
+		require.NoError(t, err)
+		assert.True(t, acquired)
+
+		server.FastForward(time.Minute + time.Second)
+		acquired, err = limiter.Acquire(ctx, key, 1)
+		require.NoError(t, err)
+		assert.True(t, acquired)
+	})
+}
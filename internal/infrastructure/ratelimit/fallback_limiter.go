@@ -0,0 +1,108 @@
+package ratelimit
+
+import (
+	"context"
+
+	"api-gateway-sample/internal/domain/entity"
+	"api-gateway-sample/internal/domain/service"
+	"api-gateway-sample/pkg/errors"
+	"api-gateway-sample/pkg/logger"
+)
+
+// Policies applied while the rate limit store is unavailable
+const (
+	// FailurePolicyFailOpen allows every request
+	FailurePolicyFailOpen = "fail-open"
+	// FailurePolicyFailClosed rejects every rate limited request with 503
+	FailurePolicyFailClosed = "fail-closed"
+	// FailurePolicyLocal counts requests in process memory, per gateway instance
+	FailurePolicyLocal = "local"
+)
+
+// dependencyHealth reports the availability of the rate limit store
+type dependencyHealth interface {
+	Healthy() bool
+	RecordFallback()
+}
+
+// FallbackRateLimiter wraps a Redis backed rate limiter and applies a failure policy
+// while Redis is unhealthy or returns an error
+type FallbackRateLimiter struct {
+	primary service.RateLimitService
+	local   *InMemoryRateLimiter
+	health  dependencyHealth
+	policy  string
+	logger  logger.Logger
+}
+
+// NewFallbackRateLimiter creates a new FallbackRateLimiter instance
+func NewFallbackRateLimiter(primary service.RateLimitService, health dependencyHealth, policy string, logger logger.Logger) *FallbackRateLimiter {
+	return &FallbackRateLimiter{
+		primary: primary,
+		local:   NewInMemoryRateLimiter(logger),
+		health:  health,
+		policy:  policy,
+		logger:  logger,
+	}
+}
+
+// CheckLimit checks if a request exceeds the rate limit
+func (r *FallbackRateLimiter) CheckLimit(ctx context.Context, request *entity.Request, service *entity.Service, endpoint *entity.Endpoint) (bool, error) {
+	if r.health.Healthy() {
+		allowed, err := r.primary.CheckLimit(ctx, request, service, endpoint)
+		if err == nil {
+			return allowed, nil
+		}
+		logger.FromContextOr(ctx, r.logger).Warn("Rate limit store failed, applying failure policy", "policy", r.policy, "error", err)
+	}
+
+	r.health.RecordFallback()
+	switch r.policy {
+	case FailurePolicyFailClosed:
+		return false, errors.NewError(errors.CodeServiceUnavailable, "rate limiting unavailable", errors.ErrServiceUnavailable)
+	case FailurePolicyLocal:
+		return r.local.CheckLimit(ctx, request, service, endpoint)
+	default:
+		return true, nil
+	}
+}
+
+// RecordRequest records a request for rate limiting purposes
+func (r *FallbackRateLimiter) RecordRequest(ctx context.Context, request *entity.Request, service *entity.Service, endpoint *entity.Endpoint) error {
+	if r.health.Healthy() {
+		err := r.primary.RecordRequest(ctx, request, service, endpoint)
+		if err == nil {
+			return nil
+		}
+		if r.policy != FailurePolicyLocal {
+			return err
+		}
+	}
+
+	if r.policy == FailurePolicyLocal {
+		return r.local.RecordRequest(ctx, request, service, endpoint)
+	}
+	return nil
+}
+
+// GetLimit gets the quota left to the client of a request
+func (r *FallbackRateLimiter) GetLimit(ctx context.Context, request *entity.Request, service *entity.Service, endpoint *entity.Endpoint) (entity.RateLimitStatus, error) {
+	if r.health.Healthy() {
+		status, err := r.primary.GetLimit(ctx, request, service, endpoint)
+		if err == nil {
+			return status, nil
+		}
+		if r.policy != FailurePolicyLocal {
+			return entity.RateLimitStatus{}, err
+		}
+	}
+
+	switch r.policy {
+	case FailurePolicyFailClosed:
+		return entity.RateLimitStatus{Limit: endpoint.RateLimit, Remaining: 0, Reset: rateLimitWindow}, nil
+	case FailurePolicyLocal:
+		return r.local.GetLimit(ctx, request, service, endpoint)
+	default:
+		return entity.RateLimitStatus{Limit: endpoint.RateLimit, Remaining: endpoint.RateLimit, Reset: rateLimitWindow}, nil
+	}
+}
@@ -0,0 +1,78 @@
+package ratelimit
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"api-gateway-sample/internal/domain/entity"
+	"api-gateway-sample/pkg/errors"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// failingRateLimiter fails every call like a rate limiter whose store is down
+type failingRateLimiter struct{}
+
+func (failingRateLimiter) CheckLimit(ctx context.Context, request *entity.Request, service *entity.Service, endpoint *entity.Endpoint) (bool, error) {
+	return false, fmt.Errorf("connection refused")
+}
+
+func (failingRateLimiter) RecordRequest(ctx context.Context, request *entity.Request, service *entity.Service, endpoint *entity.Endpoint) error {
+	return fmt.Errorf("connection refused")
+}
+
+func (failingRateLimiter) GetLimit(ctx context.Context, request *entity.Request, service *entity.Service, endpoint *entity.Endpoint) (entity.RateLimitStatus, error) {
+	return entity.RateLimitStatus{}, fmt.Errorf("connection refused")
+}
+
+// stubHealth reports a fixed health and counts fallbacks
+type stubHealth struct {
+	healthy   bool
+	fallbacks int
+}
+
+func (h *stubHealth) Healthy() bool   { return h.healthy }
+func (h *stubHealth) RecordFallback() { h.fallbacks++ }
+
+func TestFallbackRateLimiter(t *testing.T) {
+	ctx := context.Background()
+	svc := &entity.Service{ID: "svc-1"}
+	endpoint := &entity.Endpoint{Path: "/api/v1/orders", RateLimit: 1}
+	request := &entity.Request{Path: "/api/v1/orders", ClientIP: "10.0.0.1"}
+
+	t.Run("fail open allows requests", func(t *testing.T) {
+		health := &stubHealth{healthy: true}
+		limiter := NewFallbackRateLimiter(failingRateLimiter{}, health, FailurePolicyFailOpen, nopLogger{})
+
+		allowed, err := limiter.CheckLimit(ctx, request, svc, endpoint)
+		require.NoError(t, err)
+		assert.True(t, allowed)
+		assert.Equal(t, 1, health.fallbacks)
+	})
+
+	t.Run("fail closed rejects requests with 503", func(t *testing.T) {
+		health := &stubHealth{healthy: false}
+		limiter := NewFallbackRateLimiter(failingRateLimiter{}, health, FailurePolicyFailClosed, nopLogger{})
+
+		allowed, err := limiter.CheckLimit(ctx, request, svc, endpoint)
+		assert.False(t, allowed)
+		assert.Equal(t, http.StatusServiceUnavailable, errors.StatusCodeOf(err, http.StatusInternalServerError))
+	})
+
+	t.Run("local limits requests in memory", func(t *testing.T) {
+		health := &stubHealth{healthy: false}
+		limiter := NewFallbackRateLimiter(failingRateLimiter{}, health, FailurePolicyLocal, nopLogger{})
+
+		allowed, err := limiter.CheckLimit(ctx, request, svc, endpoint)
+		require.NoError(t, err)
+		assert.True(t, allowed)
+		require.NoError(t, limiter.RecordRequest(ctx, request, svc, endpoint))
+
+		allowed, err = limiter.CheckLimit(ctx, request, svc, endpoint)
+		require.NoError(t, err)
+		assert.False(t, allowed)
+	})
+}
@@ -0,0 +1,101 @@
+package ratelimit
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"api-gateway-sample/internal/domain/entity"
+	"api-gateway-sample/pkg/logger"
+)
+
+// InMemoryRateLimiter implements the same token counting as TokenBucketRateLimiter in
+// process memory. Limits are enforced per gateway instance, so it is only suitable for
+// single-instance deployments.
+type InMemoryRateLimiter struct {
+	mu      sync.Mutex
+	buckets map[string]*memoryBucket
+	// lastPurge is when expired buckets were last dropped
+	lastPurge time.Time
+	now       func() time.Time
+	logger    logger.Logger
+}
+
+type memoryBucket struct {
+	tokens  int
+	resetAt time.Time
+}
+
+// NewInMemoryRateLimiter creates a new InMemoryRateLimiter instance
+func NewInMemoryRateLimiter(logger logger.Logger) *InMemoryRateLimiter {
+	return &InMemoryRateLimiter{
+		buckets: make(map[string]*memoryBucket),
+		now:     time.Now,
+		logger:  logger,
+	}
+}
+
+// CheckLimit checks if a request exceeds the rate limit
+func (r *InMemoryRateLimiter) CheckLimit(ctx context.Context, request *entity.Request, service *entity.Service, endpoint *entity.Endpoint) (bool, error) {
+	key := bucketKey(service.ID, request.Path, request.ClientIP)
+	return r.tokens(key, endpoint.RateLimit) > 0, nil
+}
+
+// RecordRequest records a request for rate limiting purposes
+func (r *InMemoryRateLimiter) RecordRequest(ctx context.Context, request *entity.Request, service *entity.Service, endpoint *entity.Endpoint) error {
+	key := bucketKey(service.ID, request.Path, request.ClientIP)
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	// Take a token, starting a new window if the bucket does not exist or has expired
+	now := r.now()
+	bucket, ok := r.buckets[key]
+	if !ok || !now.Before(bucket.resetAt) {
+		r.purgeExpiredLocked(now)
+		bucket = &memoryBucket{tokens: endpoint.RateLimit, resetAt: now.Add(rateLimitWindow)}
+		r.buckets[key] = bucket
+	}
+	bucket.tokens--
+	return nil
+}
+
+// GetLimit gets the quota left to the client of a request
+func (r *InMemoryRateLimiter) GetLimit(ctx context.Context, request *entity.Request, service *entity.Service, endpoint *entity.Endpoint) (entity.RateLimitStatus, error) {
+	key := bucketKey(service.ID, request.Path, request.ClientIP)
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := r.now()
+	bucket, ok := r.buckets[key]
+	if !ok || !now.Before(bucket.resetAt) {
+		return entity.RateLimitStatus{Limit: endpoint.RateLimit, Remaining: endpoint.RateLimit, Reset: rateLimitWindow}, nil
+	}
+	return entity.RateLimitStatus{Limit: endpoint.RateLimit, Remaining: max(bucket.tokens, 0), Reset: bucket.resetAt.Sub(now)}, nil
+}
+
+// tokens returns the tokens left in a bucket, or the full limit if it does not exist or has expired
+func (r *InMemoryRateLimiter) tokens(key string, limit int) int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	bucket, ok := r.buckets[key]
+	if !ok || !r.now().Before(bucket.resetAt) {
+		return limit
+	}
+	return bucket.tokens
+}
+
+// purgeExpiredLocked drops expired buckets, at most once per window, so idle clients do not accumulate
+func (r *InMemoryRateLimiter) purgeExpiredLocked(now time.Time) {
+	if now.Sub(r.lastPurge) < rateLimitWindow {
+		return
+	}
+	r.lastPurge = now
+	for key, bucket := range r.buckets {
+		if !now.Before(bucket.resetAt) {
+			delete(r.buckets, key)
+		}
+	}
+}
@@ -0,0 +1,45 @@
+package ratelimit
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"api-gateway-sample/internal/domain/entity"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInMemoryRateLimiter(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	limiter := NewInMemoryRateLimiter(nopLogger{})
+	limiter.now = func() time.Time { return now }
+	svc := &entity.Service{ID: "svc-1"}
+	endpoint := &entity.Endpoint{Path: "/api/v1/orders", RateLimit: 2}
+	request := &entity.Request{Path: "/api/v1/orders", ClientIP: "10.0.0.1"}
+
+	// 1. Tokens are taken until the bucket is empty
+	for i := 0; i < 2; i++ {
+		allowed, err := limiter.CheckLimit(ctx, request, svc, endpoint)
+		require.NoError(t, err)
+		assert.True(t, allowed)
+		require.NoError(t, limiter.RecordRequest(ctx, request, svc, endpoint))
+	}
+
+	allowed, err := limiter.CheckLimit(ctx, request, svc, endpoint)
+	require.NoError(t, err)
+	assert.False(t, allowed)
+
+	now = now.Add(15 * time.Second)
+	status, err := limiter.GetLimit(ctx, request, svc, endpoint)
+	require.NoError(t, err)
+	assert.Equal(t, entity.RateLimitStatus{Limit: 2, Remaining: 0, Reset: 45 * time.Second}, status)
+
+	// 2. The bucket is refilled once the window has passed
+	now = now.Add(rateLimitWindow)
+	allowed, err = limiter.CheckLimit(ctx, request, svc, endpoint)
+	require.NoError(t, err)
+	assert.True(t, allowed)
+}
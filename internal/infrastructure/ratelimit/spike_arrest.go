@@ -0,0 +1,92 @@
+package ratelimit
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"api-gateway-sample/pkg/logger"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// reserveScript books the next delivery slot of a leaky bucket whose key holds the time, in
+// microseconds of the Redis clock, at which the next request may be delivered. Using the
+// Redis clock keeps the spacing consistent across gateway instances whose clocks drift.
+// It returns the wait for the booked slot, or -1 if it would exceed ARGV[2].
+var reserveScript = redis.NewScript(`
+local time = redis.call("TIME")
+local now = tonumber(time[1]) * 1000000 + tonumber(time[2])
+local interval = tonumber(ARGV[1])
+local nextSlot = tonumber(redis.call("GET", KEYS[1]) or "0")
+if nextSlot < now then
+	nextSlot = now
+end
+local delay = nextSlot - now
+if delay > tonumber(ARGV[2]) then
+	return -1
+end
+redis.call("SET", KEYS[1], nextSlot + interval, "PX", math.ceil((delay + interval) / 1000) + 1000)
+return delay
+`)
+
+// RedisSpikeArrester spaces out the requests delivered to an endpoint by every gateway instance
+type RedisSpikeArrester struct {
+	client redis.UniversalClient
+	logger logger.Logger
+}
+
+// NewRedisSpikeArrester creates a new RedisSpikeArrester instance
+func NewRedisSpikeArrester(client redis.UniversalClient, logger logger.Logger) *RedisSpikeArrester {
+	return &RedisSpikeArrester{
+		client: client,
+		logger: logger,
+	}
+}
+
+// Reserve books the next delivery slot under key and returns how long the request must wait for it
+func (a *RedisSpikeArrester) Reserve(ctx context.Context, key string, interval time.Duration, maxDelay time.Duration) (time.Duration, bool, error) {
+	delay, err := reserveScript.Run(ctx, a.client, []string{key}, interval.Microseconds(), maxDelay.Microseconds()).Int64()
+	if err != nil {
+		return 0, false, err
+	}
+	if delay < 0 {
+		return 0, false, nil
+	}
+	return time.Duration(delay) * time.Microsecond, true, nil
+}
+
+// InMemorySpikeArrester spaces out the requests delivered to an endpoint in process memory.
+// Requests are spaced per gateway instance, so it is only suitable for single-instance deployments.
+type InMemorySpikeArrester struct {
+	mu sync.Mutex
+	// nextSlots holds the time at which the next request to each endpoint may be delivered
+	nextSlots map[string]time.Time
+	now       func() time.Time
+}
+
+// NewInMemorySpikeArrester creates a new InMemorySpikeArrester instance
+func NewInMemorySpikeArrester() *InMemorySpikeArrester {
+	return &InMemorySpikeArrester{
+		nextSlots: make(map[string]time.Time),
+		now:       time.Now,
+	}
+}
+
+// Reserve books the next delivery slot under key and returns how long the request must wait for it
+func (a *InMemorySpikeArrester) Reserve(ctx context.Context, key string, interval time.Duration, maxDelay time.Duration) (time.Duration, bool, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	now := a.now()
+	nextSlot := a.nextSlots[key]
+	if nextSlot.Before(now) {
+		nextSlot = now
+	}
+	delay := nextSlot.Sub(now)
+	if delay > maxDelay {
+		return 0, false, nil
+	}
+	a.nextSlots[key] = nextSlot.Add(interval)
+	return delay, true, nil
+}
@@ -0,0 +1,47 @@
+package ratelimit
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"api-gateway-sample/internal/domain/service"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSpikeArresters(t *testing.T) {
+	server := miniredis.RunT(t)
+	server.SetTime(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+	client := redis.NewClient(&redis.Options{Addr: server.Addr()})
+	defer client.Close()
+
+	memory := NewInMemorySpikeArrester()
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	memory.now = func() time.Time { return now }
+
+	arresters := map[string]service.SpikeArrester{
+		"redis":  NewRedisSpikeArrester(client, nopLogger{}),
+		"memory": memory,
+	}
+	for name, arrester := range arresters {
+		t.Run(name, func(t *testing.T) {
+			ctx := context.Background()
+			key := "spikearrest:svc-1:/api/v1/legacy:" + name
+
+			// A burst is spaced out by the interval until the wait would exceed the max delay
+			for i := 0; i < 3; i++ {
+				delay, ok, err := arrester.Reserve(ctx, key, 100*time.Millisecond, 250*time.Millisecond)
+				require.NoError(t, err)
+				assert.True(t, ok)
+				assert.Equal(t, time.Duration(i)*100*time.Millisecond, delay)
+			}
+			_, ok, err := arrester.Reserve(ctx, key, 100*time.Millisecond, 250*time.Millisecond)
+			require.NoError(t, err)
+			assert.False(t, ok)
+		})
+	}
+}
@@ -0,0 +1,240 @@
+package ratelimit
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"api-gateway-sample/internal/domain/entity"
+	"api-gateway-sample/pkg/logger"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// syncScript atomically takes the tokens a gateway instance consumed since its last sync and
+// returns the tokens left for every instance and the seconds left in the window, starting the
+// window if the key does not exist. Like consumeScript it touches a single key, so it is safe
+// on Redis Cluster.
+var syncScript = redis.NewScript(`
+local count = redis.call("GET", KEYS[1])
+if not count then
+	if tonumber(ARGV[3]) == 0 then
+		return {tonumber(ARGV[1]), tonumber(ARGV[2])}
+	end
+	redis.call("SET", KEYS[1], tonumber(ARGV[1]) - tonumber(ARGV[3]), "EX", ARGV[2])
+	return {tonumber(ARGV[1]) - tonumber(ARGV[3]), tonumber(ARGV[2])}
+end
+if tonumber(ARGV[3]) ~= 0 then
+	count = redis.call("DECRBY", KEYS[1], ARGV[3])
+end
+return {tonumber(count), redis.call("TTL", KEYS[1])}
+`)
+
+// SyncedRateLimiter shares token counts between gateway instances through the same Redis keys
+// as TokenBucketRateLimiter, but caches them locally and only talks to Redis once per sync
+// interval for each client, or as soon as the local view runs out of tokens. Limits stay
+// approximately global: between syncs every instance may let through the tokens it last saw
+// as remaining, so a client can exceed its limit by at most that amount on each instance.
+type SyncedRateLimiter struct {
+	client       redis.UniversalClient
+	syncInterval time.Duration
+
+	mu      sync.Mutex
+	entries map[string]*syncedEntry
+	// lastPurge is when idle entries were last dropped
+	lastPurge time.Time
+	now       func() time.Time
+	logger    logger.Logger
+}
+
+type syncedEntry struct {
+	// remaining is the number of tokens left for every instance at the last sync
+	remaining int
+	// pending is the number of tokens taken on this instance since the last sync
+	pending  int
+	limit    int
+	syncedAt time.Time
+	// resetAt is when the window seen at the last sync ends
+	resetAt time.Time
+}
+
+// NewSyncedRateLimiter creates a new SyncedRateLimiter instance
+func NewSyncedRateLimiter(client redis.UniversalClient, syncInterval time.Duration, logger logger.Logger) *SyncedRateLimiter {
+	return &SyncedRateLimiter{
+		client:       client,
+		syncInterval: syncInterval,
+		entries:      make(map[string]*syncedEntry),
+		now:          time.Now,
+		logger:       logger,
+	}
+}
+
+// Start flushes locally taken tokens to Redis on the sync interval until the context is
+// cancelled, so that clients which stop sending requests are still counted by other instances
+func (r *SyncedRateLimiter) Start(ctx context.Context) {
+	if r.syncInterval <= 0 {
+		return
+	}
+
+	go func() {
+		ticker := time.NewTicker(r.syncInterval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				r.Flush(ctx)
+			}
+		}
+	}()
+}
+
+// Flush syncs every entry that has tokens taken since its last sync
+func (r *SyncedRateLimiter) Flush(ctx context.Context) {
+	r.mu.Lock()
+	keys := make([]string, 0, len(r.entries))
+	for key, entry := range r.entries {
+		if entry.pending > 0 {
+			keys = append(keys, key)
+		}
+	}
+	r.mu.Unlock()
+
+	for _, key := range keys {
+		if _, err := r.sync(ctx, key, 0); err != nil {
+			logger.FromContextOr(ctx, r.logger).Warn("Failed to sync rate limit tokens", "key", key, "error", err)
+		}
+	}
+}
+
+// CheckLimit checks if a request exceeds the rate limit
+func (r *SyncedRateLimiter) CheckLimit(ctx context.Context, request *entity.Request, service *entity.Service, endpoint *entity.Endpoint) (bool, error) {
+	key := bucketKey(service.ID, request.Path, request.ClientIP)
+
+	tokens, err := r.tokens(ctx, key, endpoint.RateLimit)
+	if err != nil {
+		return false, err
+	}
+	return tokens > 0, nil
+}
+
+// RecordRequest records a request for rate limiting purposes
+func (r *SyncedRateLimiter) RecordRequest(ctx context.Context, request *entity.Request, service *entity.Service, endpoint *entity.Endpoint) error {
+	key := bucketKey(service.ID, request.Path, request.ClientIP)
+
+	// Take a token locally, syncing right away once the local view is exhausted so that
+	// other instances see the client run out of tokens without waiting for the interval
+	r.mu.Lock()
+	entry := r.entryLocked(key, endpoint.RateLimit)
+	entry.pending++
+	exhausted := entry.remaining-entry.pending <= 0
+	stale := r.staleLocked(entry)
+	r.mu.Unlock()
+
+	if exhausted || stale {
+		_, err := r.sync(ctx, key, endpoint.RateLimit)
+		return err
+	}
+	return nil
+}
+
+// GetLimit gets the quota left to the client of a request as seen by this instance
+func (r *SyncedRateLimiter) GetLimit(ctx context.Context, request *entity.Request, service *entity.Service, endpoint *entity.Endpoint) (entity.RateLimitStatus, error) {
+	key := bucketKey(service.ID, request.Path, request.ClientIP)
+
+	tokens, err := r.tokens(ctx, key, endpoint.RateLimit)
+	if err != nil {
+		return entity.RateLimitStatus{}, err
+	}
+
+	status := entity.RateLimitStatus{Limit: endpoint.RateLimit, Remaining: max(tokens, 0), Reset: rateLimitWindow}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if entry, ok := r.entries[key]; ok && !entry.resetAt.IsZero() {
+		status.Reset = max(entry.resetAt.Sub(r.now()), 0)
+	}
+	return status, nil
+}
+
+// tokens returns the tokens left for a client as seen by this instance, syncing first if the
+// cached count is older than the sync interval
+func (r *SyncedRateLimiter) tokens(ctx context.Context, key string, limit int) (int, error) {
+	r.mu.Lock()
+	entry, ok := r.entries[key]
+	if ok && !r.staleLocked(entry) {
+		tokens := entry.remaining - entry.pending
+		r.mu.Unlock()
+		return tokens, nil
+	}
+	r.mu.Unlock()
+
+	return r.sync(ctx, key, limit)
+}
+
+// sync flushes the tokens taken on this instance to Redis and refreshes the cached count.
+// A limit of 0 keeps the limit the entry was created with and skips entries that no longer exist.
+func (r *SyncedRateLimiter) sync(ctx context.Context, key string, limit int) (int, error) {
+	// Take the pending tokens out before the round trip so requests are not blocked on Redis
+	r.mu.Lock()
+	if _, ok := r.entries[key]; !ok && limit <= 0 {
+		r.mu.Unlock()
+		return 0, nil
+	}
+	entry := r.entryLocked(key, limit)
+	if limit > 0 {
+		entry.limit = limit
+	}
+	limit = entry.limit
+	pending := entry.pending
+	entry.pending = 0
+	r.mu.Unlock()
+
+	result, err := syncScript.Run(ctx, r.client, []string{key}, limit, int(rateLimitWindow.Seconds()), pending).Int64Slice()
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if err != nil {
+		// Keep the tokens so that the next sync counts them
+		entry.pending += pending
+		return 0, err
+	}
+	entry.remaining = int(result[0])
+	entry.syncedAt = r.now()
+	entry.resetAt = time.Time{}
+	if result[1] >= 0 {
+		entry.resetAt = entry.syncedAt.Add(time.Duration(result[1]) * time.Second)
+	}
+	return entry.remaining - entry.pending, nil
+}
+
+// entryLocked returns the entry for a key, creating it with a full bucket if it does not exist
+func (r *SyncedRateLimiter) entryLocked(key string, limit int) *syncedEntry {
+	entry, ok := r.entries[key]
+	if !ok {
+		r.purgeIdleLocked(r.now())
+		entry = &syncedEntry{remaining: limit, limit: limit}
+		r.entries[key] = entry
+	}
+	return entry
+}
+
+// staleLocked reports whether an entry has not been synced within the sync interval
+func (r *SyncedRateLimiter) staleLocked(entry *syncedEntry) bool {
+	return entry.syncedAt.IsZero() || r.now().Sub(entry.syncedAt) >= r.syncInterval
+}
+
+// purgeIdleLocked drops entries with nothing to flush that have not been synced for a window,
+// at most once per window, so idle clients do not accumulate
+func (r *SyncedRateLimiter) purgeIdleLocked(now time.Time) {
+	if now.Sub(r.lastPurge) < rateLimitWindow {
+		return
+	}
+	r.lastPurge = now
+	for key, entry := range r.entries {
+		if entry.pending == 0 && now.Sub(entry.syncedAt) >= rateLimitWindow {
+			delete(r.entries, key)
+		}
+	}
+}
@@ -0,0 +1,78 @@
+package ratelimit
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"api-gateway-sample/internal/domain/entity"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSyncedRateLimiter(t *testing.T) {
+	server := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: server.Addr()})
+	defer client.Close()
+
+	ctx := context.Background()
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	newLimiter := func() *SyncedRateLimiter {
+		limiter := NewSyncedRateLimiter(client, time.Second, nopLogger{})
+		limiter.now = func() time.Time { return now }
+		return limiter
+	}
+	first, second := newLimiter(), newLimiter()
+	svc := &entity.Service{ID: "svc-1"}
+	endpoint := &entity.Endpoint{Path: "/api/v1/orders", RateLimit: 4}
+	request := &entity.Request{Path: "/api/v1/orders", ClientIP: "10.0.0.1"}
+	key := "ratelimit:svc-1:/api/v1/orders:10.0.0.1"
+
+	// 1. Tokens taken within the sync interval are only counted locally
+	for i := 0; i < 2; i++ {
+		allowed, err := first.CheckLimit(ctx, request, svc, endpoint)
+		require.NoError(t, err)
+		assert.True(t, allowed)
+		require.NoError(t, first.RecordRequest(ctx, request, svc, endpoint))
+	}
+	assert.False(t, server.Exists(key))
+
+	status, err := first.GetLimit(ctx, request, svc, endpoint)
+	require.NoError(t, err)
+	assert.Equal(t, 2, status.Remaining)
+
+	// 2. Flushing shares the count with the other instance
+	first.Flush(ctx)
+	count, err := server.Get(key)
+	require.NoError(t, err)
+	assert.Equal(t, "2", count)
+
+	server.FastForward(20 * time.Second)
+	now = now.Add(20 * time.Second)
+	status, err = second.GetLimit(ctx, request, svc, endpoint)
+	require.NoError(t, err)
+	assert.Equal(t, entity.RateLimitStatus{Limit: 4, Remaining: 2, Reset: 40 * time.Second}, status)
+
+	// 3. Running out of tokens syncs right away and rejects requests on every instance
+	for i := 0; i < 2; i++ {
+		require.NoError(t, second.RecordRequest(ctx, request, svc, endpoint))
+	}
+	allowed, err := second.CheckLimit(ctx, request, svc, endpoint)
+	require.NoError(t, err)
+	assert.False(t, allowed)
+
+	now = now.Add(time.Second)
+	allowed, err = first.CheckLimit(ctx, request, svc, endpoint)
+	require.NoError(t, err)
+	assert.False(t, allowed)
+
+	// 4. The bucket is refilled once the window expires
+	server.FastForward(rateLimitWindow + time.Second)
+	now = now.Add(time.Second)
+	allowed, err = first.CheckLimit(ctx, request, svc, endpoint)
+	require.NoError(t, err)
+	assert.True(t, allowed)
+}
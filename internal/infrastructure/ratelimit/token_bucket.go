@@ -2,7 +2,6 @@ package ratelimit
 
 import (
 	"context"
-	"fmt"
 	"time"
 
 	"api-gateway-sample/internal/domain/entity"
@@ -11,14 +10,35 @@ import (
 	"github.com/redis/go-redis/v9"
 )
 
+// consumeScript atomically takes a token, initializing the bucket with its limit and window
+// on first use. It touches a single key, so it is safe on Redis Cluster without hash tags;
+// scripts that touch several keys must keep them in one slot with a shared {hash tag}.
+var consumeScript = redis.NewScript(`
+local count = redis.call("GET", KEYS[1])
+if not count then
+	redis.call("SET", KEYS[1], tonumber(ARGV[1]) - 1, "EX", ARGV[2])
+	return tonumber(ARGV[1]) - 1
+end
+return redis.call("DECR", KEYS[1])
+`)
+
+// rateLimitWindow is how long a client's token count lives before it is reset
+const rateLimitWindow = time.Minute
+
+// bucketKey returns the key of a client's token count on a path of a service. It is built on
+// every request, so it is concatenated rather than formatted.
+func bucketKey(serviceID string, path string, client string) string {
+	return "ratelimit:" + serviceID + ":" + path + ":" + client
+}
+
 // TokenBucketRateLimiter implements rate limiting using the token bucket algorithm
 type TokenBucketRateLimiter struct {
-	client *redis.Client
+	client redis.UniversalClient
 	logger logger.Logger
 }
 
 // NewTokenBucketRateLimiter creates a new TokenBucketRateLimiter instance
-func NewTokenBucketRateLimiter(client *redis.Client, logger logger.Logger) *TokenBucketRateLimiter {
+func NewTokenBucketRateLimiter(client redis.UniversalClient, logger logger.Logger) *TokenBucketRateLimiter {
 	return &TokenBucketRateLimiter{
 		client: client,
 		logger: logger,
@@ -27,7 +47,7 @@ func NewTokenBucketRateLimiter(client *redis.Client, logger logger.Logger) *Toke
 
 // CheckLimit checks if a request exceeds the rate limit
 func (r *TokenBucketRateLimiter) CheckLimit(ctx context.Context, request *entity.Request, service *entity.Service, endpoint *entity.Endpoint) (bool, error) {
-	key := fmt.Sprintf("ratelimit:%s:%s:%s", service.ID, request.Path, request.ClientIP)
+	key := bucketKey(service.ID, request.Path, request.ClientIP)
 
 	// Get current token count
 	count, err := r.client.Get(ctx, key).Int()
@@ -50,35 +70,36 @@ func (r *TokenBucketRateLimiter) CheckLimit(ctx context.Context, request *entity
 
 // RecordRequest records a request for rate limiting purposes
 func (r *TokenBucketRateLimiter) RecordRequest(ctx context.Context, request *entity.Request, service *entity.Service, endpoint *entity.Endpoint) error {
-	key := fmt.Sprintf("ratelimit:%s:%s:%s", service.ID, request.Path, request.ClientIP)
-
-	// Decrement token count
-	count, err := r.client.Decr(ctx, key).Result()
-	if err != nil {
-		return err
-	}
-
-	// Set expiration if this is a new key
-	if int(count) == endpoint.RateLimit-1 {
-		r.client.Expire(ctx, key, time.Minute)
-	}
+	key := bucketKey(service.ID, request.Path, request.ClientIP)
 
-	return nil
+	// Take a token, starting a new window if the key does not exist
+	return consumeScript.Run(ctx, r.client, []string{key}, endpoint.RateLimit, int(rateLimitWindow.Seconds())).Err()
 }
 
-// GetLimit gets the current rate limit for a client
-func (r *TokenBucketRateLimiter) GetLimit(ctx context.Context, clientID string, service *entity.Service, endpoint *entity.Endpoint) (int, int, error) {
-	key := fmt.Sprintf("ratelimit:%s:%s:%s", service.ID, endpoint.Path, clientID)
-
-	// Get current token count
-	count, err := r.client.Get(ctx, key).Int()
-	if err != nil && err != redis.Nil {
-		return 0, 0, err
+// GetLimit gets the quota left to the client of a request. The window ends when the key
+// expires, a full window away when the client has not started one.
+func (r *TokenBucketRateLimiter) GetLimit(ctx context.Context, request *entity.Request, service *entity.Service, endpoint *entity.Endpoint) (entity.RateLimitStatus, error) {
+	key := bucketKey(service.ID, request.Path, request.ClientIP)
+
+	// Get current token count and the time left in the window in one round trip
+	pipe := r.client.Pipeline()
+	get := pipe.Get(ctx, key)
+	ttl := pipe.TTL(ctx, key)
+	if _, err := pipe.Exec(ctx); err != nil && err != redis.Nil {
+		return entity.RateLimitStatus{}, err
 	}
 
+	status := entity.RateLimitStatus{Limit: endpoint.RateLimit, Remaining: endpoint.RateLimit, Reset: rateLimitWindow}
+	count, err := get.Int()
 	if err == redis.Nil {
-		count = endpoint.RateLimit
+		return status, nil
 	}
-
-	return count, endpoint.RateLimit, nil
+	if err != nil {
+		return entity.RateLimitStatus{}, err
+	}
+	status.Remaining = max(count, 0)
+	if reset := ttl.Val(); reset >= 0 {
+		status.Reset = reset
+	}
+	return status, nil
 }
@@ -0,0 +1,62 @@
+package ratelimit
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"api-gateway-sample/internal/domain/entity"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// nopLogger discards all log output
+type nopLogger struct{}
+
+func (nopLogger) Debug(msg string, keysAndValues ...interface{}) {}
+func (nopLogger) Info(msg string, keysAndValues ...interface{})  {}
+func (nopLogger) Warn(msg string, keysAndValues ...interface{})  {}
+func (nopLogger) Error(msg string, keysAndValues ...interface{}) {}
+func (nopLogger) Fatal(msg string, keysAndValues ...interface{}) {}
+
+func TestTokenBucketRateLimiter(t *testing.T) {
+	server := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: server.Addr()})
+	defer client.Close()
+
+	ctx := context.Background()
+	limiter := NewTokenBucketRateLimiter(client, nopLogger{})
+	svc := &entity.Service{ID: "svc-1"}
+	endpoint := &entity.Endpoint{Path: "/api/v1/orders", RateLimit: 2}
+	request := &entity.Request{Path: "/api/v1/orders", ClientIP: "10.0.0.1"}
+
+	// 1. Tokens are taken until the bucket is empty
+	for i := 0; i < 2; i++ {
+		allowed, err := limiter.CheckLimit(ctx, request, svc, endpoint)
+		require.NoError(t, err)
+		assert.True(t, allowed)
+		require.NoError(t, limiter.RecordRequest(ctx, request, svc, endpoint))
+	}
+
+	allowed, err := limiter.CheckLimit(ctx, request, svc, endpoint)
+	require.NoError(t, err)
+	assert.False(t, allowed)
+
+	// 2. The first request starts the window
+	ttl := server.TTL("ratelimit:svc-1:/api/v1/orders:10.0.0.1")
+	assert.Equal(t, rateLimitWindow, ttl)
+
+	server.FastForward(20 * time.Second)
+	status, err := limiter.GetLimit(ctx, request, svc, endpoint)
+	require.NoError(t, err)
+	assert.Equal(t, entity.RateLimitStatus{Limit: 2, Remaining: 0, Reset: 40 * time.Second}, status)
+
+	// 3. The bucket is refilled once the window expires
+	server.FastForward(rateLimitWindow + time.Second)
+	allowed, err = limiter.CheckLimit(ctx, request, svc, endpoint)
+	require.NoError(t, err)
+	assert.True(t, allowed)
+}
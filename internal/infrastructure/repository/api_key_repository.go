@@ -0,0 +1,158 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"api-gateway-sample/internal/domain/entity"
+	"api-gateway-sample/internal/domain/repository"
+	"api-gateway-sample/pkg/errors"
+	"api-gateway-sample/pkg/logger"
+
+	"gorm.io/gorm"
+)
+
+// APIKeyModel represents the API key database model
+type APIKeyModel struct {
+	ID         string `gorm:"primaryKey"`
+	ServiceID  string `gorm:"index"`
+	Consumer   string
+	Email      string
+	Purpose    string
+	Status     string `gorm:"index"`
+	KeyHash    string `gorm:"index"`
+	Prefix     string
+	Plan       string
+	ReviewedBy string
+	ReviewedAt *time.Time
+	Reason     string
+	CreatedAt  time.Time
+	UpdatedAt  time.Time
+}
+
+// TableName returns the API key table name
+func (APIKeyModel) TableName() string {
+	return "api_keys"
+}
+
+// APIKeyRepositoryImpl implements the repository.APIKeyRepository interface
+type APIKeyRepositoryImpl struct {
+	db     *gorm.DB
+	logger logger.Logger
+}
+
+// NewAPIKeyRepositoryImpl creates a new APIKeyRepositoryImpl instance
+func NewAPIKeyRepositoryImpl(db *gorm.DB, logger logger.Logger) repository.APIKeyRepository {
+	return &APIKeyRepositoryImpl{
+		db:     db,
+		logger: logger,
+	}
+}
+
+// Create creates a new API key
+func (r *APIKeyRepositoryImpl) Create(ctx context.Context, key *entity.APIKey) error {
+	if err := r.db.WithContext(ctx).Create(mapAPIKeyToModel(key)).Error; err != nil {
+		return fmt.Errorf("failed to create api key: %w", err)
+	}
+	return nil
+}
+
+// Get retrieves an API key by ID
+func (r *APIKeyRepositoryImpl) Get(ctx context.Context, id string) (*entity.APIKey, error) {
+	var model APIKeyModel
+	if err := r.db.WithContext(ctx).First(&model, "id = ?", id).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, errors.ErrNotFound
+		}
+		return nil, fmt.Errorf("failed to get api key: %w", err)
+	}
+	return mapModelToAPIKey(&model), nil
+}
+
+// FindByHash retrieves an API key by the hash of the issued key
+func (r *APIKeyRepositoryImpl) FindByHash(ctx context.Context, keyHash string) (*entity.APIKey, error) {
+	var model APIKeyModel
+	if err := r.db.WithContext(ctx).First(&model, "key_hash = ?", keyHash).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, errors.ErrNotFound
+		}
+		return nil, fmt.Errorf("failed to find api key: %w", err)
+	}
+	return mapModelToAPIKey(&model), nil
+}
+
+// GetAll retrieves all API keys
+func (r *APIKeyRepositoryImpl) GetAll(ctx context.Context) ([]*entity.APIKey, error) {
+	var models []APIKeyModel
+	if err := r.db.WithContext(ctx).Order("created_at").Find(&models).Error; err != nil {
+		return nil, fmt.Errorf("failed to get api keys: %w", err)
+	}
+
+	keys := make([]*entity.APIKey, len(models))
+	for i := range models {
+		keys[i] = mapModelToAPIKey(&models[i])
+	}
+	return keys, nil
+}
+
+// Update updates an existing API key
+func (r *APIKeyRepositoryImpl) Update(ctx context.Context, key *entity.APIKey) error {
+	result := r.db.WithContext(ctx).Model(&APIKeyModel{}).Where("id = ?", key.ID).Updates(map[string]interface{}{
+		"consumer":    key.Consumer,
+		"email":       key.Email,
+		"purpose":     key.Purpose,
+		"status":      key.Status,
+		"key_hash":    key.KeyHash,
+		"prefix":      key.Prefix,
+		"plan":        key.Plan,
+		"reviewed_by": key.ReviewedBy,
+		"reviewed_at": key.ReviewedAt,
+		"reason":      key.Reason,
+	})
+	if result.Error != nil {
+		return fmt.Errorf("failed to update api key: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return errors.ErrNotFound
+	}
+	return nil
+}
+
+// Helper functions
+
+func mapAPIKeyToModel(key *entity.APIKey) *APIKeyModel {
+	return &APIKeyModel{
+		ID:         key.ID,
+		ServiceID:  key.ServiceID,
+		Consumer:   key.Consumer,
+		Email:      key.Email,
+		Purpose:    key.Purpose,
+		Status:     key.Status,
+		KeyHash:    key.KeyHash,
+		Prefix:     key.Prefix,
+		Plan:       key.Plan,
+		ReviewedBy: key.ReviewedBy,
+		ReviewedAt: key.ReviewedAt,
+		Reason:     key.Reason,
+		CreatedAt:  key.CreatedAt,
+	}
+}
+
+func mapModelToAPIKey(model *APIKeyModel) *entity.APIKey {
+	return &entity.APIKey{
+		ID:         model.ID,
+		ServiceID:  model.ServiceID,
+		Consumer:   model.Consumer,
+		Email:      model.Email,
+		Purpose:    model.Purpose,
+		Status:     model.Status,
+		KeyHash:    model.KeyHash,
+		Prefix:     model.Prefix,
+		Plan:       model.Plan,
+		ReviewedBy: model.ReviewedBy,
+		ReviewedAt: model.ReviewedAt,
+		Reason:     model.Reason,
+		CreatedAt:  model.CreatedAt,
+	}
+}
@@ -0,0 +1,135 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"api-gateway-sample/internal/domain/entity"
+	"api-gateway-sample/internal/domain/repository"
+	"api-gateway-sample/pkg/errors"
+	"api-gateway-sample/pkg/logger"
+
+	"gorm.io/gorm"
+)
+
+// FeatureFlagModel represents the feature flag database model
+type FeatureFlagModel struct {
+	Key         string `gorm:"primaryKey"`
+	Description string
+	Enabled     bool
+	Segment     string
+	Percentage  *int
+
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+// TableName returns the feature flag table name
+func (FeatureFlagModel) TableName() string {
+	return "feature_flags"
+}
+
+// FeatureFlagRepositoryImpl implements the repository.FeatureFlagRepository interface
+type FeatureFlagRepositoryImpl struct {
+	db     *gorm.DB
+	logger logger.Logger
+}
+
+// NewFeatureFlagRepositoryImpl creates a new FeatureFlagRepositoryImpl instance
+func NewFeatureFlagRepositoryImpl(db *gorm.DB, logger logger.Logger) repository.FeatureFlagRepository {
+	return &FeatureFlagRepositoryImpl{
+		db:     db,
+		logger: logger,
+	}
+}
+
+// Create creates a new flag
+func (r *FeatureFlagRepositoryImpl) Create(ctx context.Context, flag *entity.FeatureFlag) error {
+	if err := r.db.WithContext(ctx).Create(mapFeatureFlagToModel(flag)).Error; err != nil {
+		return fmt.Errorf("failed to create feature flag: %w", err)
+	}
+	return nil
+}
+
+// Get retrieves a flag by key
+func (r *FeatureFlagRepositoryImpl) Get(ctx context.Context, key string) (*entity.FeatureFlag, error) {
+	var model FeatureFlagModel
+	if err := r.db.WithContext(ctx).First(&model, "key = ?", key).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, errors.ErrNotFound
+		}
+		return nil, fmt.Errorf("failed to get feature flag: %w", err)
+	}
+	return mapModelToFeatureFlag(&model), nil
+}
+
+// GetAll retrieves all flags ordered by key
+func (r *FeatureFlagRepositoryImpl) GetAll(ctx context.Context) ([]*entity.FeatureFlag, error) {
+	var models []FeatureFlagModel
+	if err := r.db.WithContext(ctx).Order("key").Find(&models).Error; err != nil {
+		return nil, fmt.Errorf("failed to get feature flags: %w", err)
+	}
+
+	flags := make([]*entity.FeatureFlag, len(models))
+	for i := range models {
+		flags[i] = mapModelToFeatureFlag(&models[i])
+	}
+	return flags, nil
+}
+
+// Update updates an existing flag
+func (r *FeatureFlagRepositoryImpl) Update(ctx context.Context, flag *entity.FeatureFlag) error {
+	result := r.db.WithContext(ctx).Model(&FeatureFlagModel{}).Where("key = ?", flag.Key).Updates(map[string]interface{}{
+		"description": flag.Description,
+		"enabled":     flag.Enabled,
+		"segment":     flag.Segment,
+		"percentage":  flag.Percentage,
+		"updated_at":  flag.UpdatedAt,
+	})
+	if result.Error != nil {
+		return fmt.Errorf("failed to update feature flag: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return errors.ErrNotFound
+	}
+	return nil
+}
+
+// Delete deletes a flag by key
+func (r *FeatureFlagRepositoryImpl) Delete(ctx context.Context, key string) error {
+	result := r.db.WithContext(ctx).Delete(&FeatureFlagModel{}, "key = ?", key)
+	if result.Error != nil {
+		return fmt.Errorf("failed to delete feature flag: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return errors.ErrNotFound
+	}
+	return nil
+}
+
+// Helper functions
+
+func mapFeatureFlagToModel(flag *entity.FeatureFlag) *FeatureFlagModel {
+	return &FeatureFlagModel{
+		Key:         flag.Key,
+		Description: flag.Description,
+		Enabled:     flag.Enabled,
+		Segment:     flag.Segment,
+		Percentage:  flag.Percentage,
+		CreatedAt:   flag.CreatedAt,
+		UpdatedAt:   flag.UpdatedAt,
+	}
+}
+
+func mapModelToFeatureFlag(model *FeatureFlagModel) *entity.FeatureFlag {
+	return &entity.FeatureFlag{
+		Key:         model.Key,
+		Description: model.Description,
+		Enabled:     model.Enabled,
+		Segment:     model.Segment,
+		Percentage:  model.Percentage,
+		CreatedAt:   model.CreatedAt,
+		UpdatedAt:   model.UpdatedAt,
+	}
+}
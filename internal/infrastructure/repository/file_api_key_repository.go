@@ -0,0 +1,101 @@
+package repository
+
+import (
+	"context"
+
+	"api-gateway-sample/internal/domain/entity"
+	"api-gateway-sample/internal/domain/repository"
+	"api-gateway-sample/pkg/errors"
+	"api-gateway-sample/pkg/logger"
+)
+
+// FileAPIKeyRepository implements the repository.APIKeyRepository interface on a FileStore
+type FileAPIKeyRepository struct {
+	store  *FileStore
+	logger logger.Logger
+}
+
+// NewFileAPIKeyRepository creates a new FileAPIKeyRepository instance
+func NewFileAPIKeyRepository(store *FileStore, logger logger.Logger) repository.APIKeyRepository {
+	return &FileAPIKeyRepository{
+		store:  store,
+		logger: logger,
+	}
+}
+
+// Create creates a new API key
+func (r *FileAPIKeyRepository) Create(ctx context.Context, key *entity.APIKey) error {
+	return r.store.update(func(doc *fileDocument) error {
+		for _, existing := range doc.APIKeys {
+			if existing.ID == key.ID {
+				return errors.ErrAlreadyExists
+			}
+		}
+		stored := *key
+		doc.APIKeys = append(doc.APIKeys, &stored)
+		return nil
+	})
+}
+
+// Get retrieves an API key by ID
+func (r *FileAPIKeyRepository) Get(ctx context.Context, id string) (*entity.APIKey, error) {
+	var found *entity.APIKey
+	r.store.read(func(doc *fileDocument) {
+		for _, key := range doc.APIKeys {
+			if key.ID == id {
+				copied := *key
+				found = &copied
+				return
+			}
+		}
+	})
+	if found == nil {
+		return nil, errors.ErrNotFound
+	}
+	return found, nil
+}
+
+// FindByHash retrieves an API key by the hash of the issued key
+func (r *FileAPIKeyRepository) FindByHash(ctx context.Context, keyHash string) (*entity.APIKey, error) {
+	var found *entity.APIKey
+	r.store.read(func(doc *fileDocument) {
+		for _, key := range doc.APIKeys {
+			if key.KeyHash != "" && key.KeyHash == keyHash {
+				copied := *key
+				found = &copied
+				return
+			}
+		}
+	})
+	if found == nil {
+		return nil, errors.ErrNotFound
+	}
+	return found, nil
+}
+
+// GetAll retrieves all API keys
+func (r *FileAPIKeyRepository) GetAll(ctx context.Context) ([]*entity.APIKey, error) {
+	var keys []*entity.APIKey
+	r.store.read(func(doc *fileDocument) {
+		keys = make([]*entity.APIKey, len(doc.APIKeys))
+		for i, key := range doc.APIKeys {
+			copied := *key
+			keys[i] = &copied
+		}
+	})
+	return keys, nil
+}
+
+// Update updates an existing API key
+func (r *FileAPIKeyRepository) Update(ctx context.Context, key *entity.APIKey) error {
+	return r.store.update(func(doc *fileDocument) error {
+		for i, existing := range doc.APIKeys {
+			if existing.ID == key.ID {
+				stored := *key
+				doc.APIKeys[i] = &stored
+				return nil
+			}
+		}
+		return errors.ErrNotFound
+	})
+}
@@ -0,0 +1,104 @@
+package repository
+
+import (
+	"context"
+
+	"api-gateway-sample/internal/domain/entity"
+	"api-gateway-sample/internal/domain/repository"
+	"api-gateway-sample/pkg/errors"
+	"api-gateway-sample/pkg/logger"
+)
+
+// FileFeatureFlagRepository implements the repository.FeatureFlagRepository interface on a FileStore
+type FileFeatureFlagRepository struct {
+	store  *FileStore
+	logger logger.Logger
+}
+
+// NewFileFeatureFlagRepository creates a new FileFeatureFlagRepository instance
+func NewFileFeatureFlagRepository(store *FileStore, logger logger.Logger) repository.FeatureFlagRepository {
+	return &FileFeatureFlagRepository{
+		store:  store,
+		logger: logger,
+	}
+}
+
+// Create creates a new flag
+func (r *FileFeatureFlagRepository) Create(ctx context.Context, flag *entity.FeatureFlag) error {
+	return r.store.update(func(doc *fileDocument) error {
+		for _, existing := range doc.FeatureFlags {
+			if existing.Key == flag.Key {
+				return errors.ErrAlreadyExists
+			}
+		}
+		doc.FeatureFlags = append(doc.FeatureFlags, copyFeatureFlag(flag))
+		return nil
+	})
+}
+
+// Get retrieves a flag by key
+func (r *FileFeatureFlagRepository) Get(ctx context.Context, key string) (*entity.FeatureFlag, error) {
+	var found *entity.FeatureFlag
+	r.store.read(func(doc *fileDocument) {
+		for _, flag := range doc.FeatureFlags {
+			if flag.Key == key {
+				found = copyFeatureFlag(flag)
+				return
+			}
+		}
+	})
+	if found == nil {
+		return nil, errors.ErrNotFound
+	}
+	return found, nil
+}
+
+// GetAll retrieves all flags
+func (r *FileFeatureFlagRepository) GetAll(ctx context.Context) ([]*entity.FeatureFlag, error) {
+	var flags []*entity.FeatureFlag
+	r.store.read(func(doc *fileDocument) {
+		flags = make([]*entity.FeatureFlag, len(doc.FeatureFlags))
+		for i, flag := range doc.FeatureFlags {
+			flags[i] = copyFeatureFlag(flag)
+		}
+	})
+	return flags, nil
+}
+
+// Update updates an existing flag
+func (r *FileFeatureFlagRepository) Update(ctx context.Context, flag *entity.FeatureFlag) error {
+	return r.store.update(func(doc *fileDocument) error {
+		for i, existing := range doc.FeatureFlags {
+			if existing.Key == flag.Key {
+				doc.FeatureFlags[i] = copyFeatureFlag(flag)
+				return nil
+			}
+		}
+		return errors.ErrNotFound
+	})
+}
+
+// Delete deletes a flag by key
+func (r *FileFeatureFlagRepository) Delete(ctx context.Context, key string) error {
+	return r.store.update(func(doc *fileDocument) error {
+		for i, existing := range doc.FeatureFlags {
+			if existing.Key == key {
+				doc.FeatureFlags = append(doc.FeatureFlags[:i:i], doc.FeatureFlags[i+1:]...)
+				return nil
+			}
+		}
+		return errors.ErrNotFound
+	})
+}
+
+// Helper functions
+
+// copyFeatureFlag copies a flag, including its percentage, so callers never share the stored one
+func copyFeatureFlag(flag *entity.FeatureFlag) *entity.FeatureFlag {
+	copied := *flag
+	if flag.Percentage != nil {
+		percentage := *flag.Percentage
+		copied.Percentage = &percentage
+	}
+	return &copied
+}
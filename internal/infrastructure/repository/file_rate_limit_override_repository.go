@@ -0,0 +1,121 @@
+package repository
+
+import (
+	"context"
+
+	"api-gateway-sample/internal/domain/entity"
+	"api-gateway-sample/internal/domain/repository"
+	"api-gateway-sample/pkg/errors"
+	"api-gateway-sample/pkg/logger"
+)
+
+// FileRateLimitOverrideRepository implements the repository.RateLimitOverrideRepository interface on a FileStore
+type FileRateLimitOverrideRepository struct {
+	store  *FileStore
+	logger logger.Logger
+}
+
+// NewFileRateLimitOverrideRepository creates a new FileRateLimitOverrideRepository instance
+func NewFileRateLimitOverrideRepository(store *FileStore, logger logger.Logger) repository.RateLimitOverrideRepository {
+	return &FileRateLimitOverrideRepository{
+		store:  store,
+		logger: logger,
+	}
+}
+
+// Create creates a new override
+func (r *FileRateLimitOverrideRepository) Create(ctx context.Context, override *entity.RateLimitOverride) error {
+	return r.store.update(func(doc *fileDocument) error {
+		for _, existing := range doc.RateLimitOverrides {
+			if existing.ID == override.ID {
+				return errors.ErrAlreadyExists
+			}
+		}
+		doc.RateLimitOverrides = append(doc.RateLimitOverrides, copyRateLimitOverride(override))
+		return nil
+	})
+}
+
+// Get retrieves an override by ID
+func (r *FileRateLimitOverrideRepository) Get(ctx context.Context, id string) (*entity.RateLimitOverride, error) {
+	var found *entity.RateLimitOverride
+	r.store.read(func(doc *fileDocument) {
+		for _, override := range doc.RateLimitOverrides {
+			if override.ID == id {
+				found = copyRateLimitOverride(override)
+				return
+			}
+		}
+	})
+	if found == nil {
+		return nil, errors.ErrNotFound
+	}
+	return found, nil
+}
+
+// GetAll retrieves all overrides
+func (r *FileRateLimitOverrideRepository) GetAll(ctx context.Context) ([]*entity.RateLimitOverride, error) {
+	var overrides []*entity.RateLimitOverride
+	r.store.read(func(doc *fileDocument) {
+		overrides = make([]*entity.RateLimitOverride, len(doc.RateLimitOverrides))
+		for i, override := range doc.RateLimitOverrides {
+			overrides[i] = copyRateLimitOverride(override)
+		}
+	})
+	return overrides, nil
+}
+
+// FindByConsumer retrieves the overrides of a consumer
+func (r *FileRateLimitOverrideRepository) FindByConsumer(ctx context.Context, consumer string) ([]*entity.RateLimitOverride, error) {
+	var overrides []*entity.RateLimitOverride
+	r.store.read(func(doc *fileDocument) {
+		for _, override := range doc.RateLimitOverrides {
+			if override.Consumer == consumer {
+				overrides = append(overrides, copyRateLimitOverride(override))
+			}
+		}
+	})
+	return overrides, nil
+}
+
+// Update updates an existing override
+func (r *FileRateLimitOverrideRepository) Update(ctx context.Context, override *entity.RateLimitOverride) error {
+	return r.store.update(func(doc *fileDocument) error {
+		for i, existing := range doc.RateLimitOverrides {
+			if existing.ID == override.ID {
+				doc.RateLimitOverrides[i] = copyRateLimitOverride(override)
+				return nil
+			}
+		}
+		return errors.ErrNotFound
+	})
+}
+
+// Delete deletes an override by ID
+func (r *FileRateLimitOverrideRepository) Delete(ctx context.Context, id string) error {
+	return r.store.update(func(doc *fileDocument) error {
+		for i, existing := range doc.RateLimitOverrides {
+			if existing.ID == id {
+				doc.RateLimitOverrides = append(doc.RateLimitOverrides[:i:i], doc.RateLimitOverrides[i+1:]...)
+				return nil
+			}
+		}
+		return errors.ErrNotFound
+	})
+}
+
+// Helper functions
+
+// copyRateLimitOverride copies an override, including its limits, so callers never share the stored one
+func copyRateLimitOverride(override *entity.RateLimitOverride) *entity.RateLimitOverride {
+	copied := *override
+	if override.RateLimit != nil {
+		limit := *override.RateLimit
+		copied.RateLimit = &limit
+	}
+	if override.MaxConcurrent != nil {
+		limit := *override.MaxConcurrent
+		copied.MaxConcurrent = &limit
+	}
+	return &copied
+}
@@ -0,0 +1,121 @@
+package repository
+
+import (
+	"context"
+
+	"api-gateway-sample/internal/domain/entity"
+	"api-gateway-sample/internal/domain/repository"
+	"api-gateway-sample/pkg/errors"
+	"api-gateway-sample/pkg/logger"
+)
+
+// FileRoutePolicyRepository implements the repository.RoutePolicyRepository interface on a FileStore
+type FileRoutePolicyRepository struct {
+	store  *FileStore
+	logger logger.Logger
+}
+
+// NewFileRoutePolicyRepository creates a new FileRoutePolicyRepository instance
+func NewFileRoutePolicyRepository(store *FileStore, logger logger.Logger) repository.RoutePolicyRepository {
+	return &FileRoutePolicyRepository{
+		store:  store,
+		logger: logger,
+	}
+}
+
+// Create creates a new policy
+func (r *FileRoutePolicyRepository) Create(ctx context.Context, policy *entity.RoutePolicy) error {
+	return r.store.update(func(doc *fileDocument) error {
+		for _, existing := range doc.RoutePolicies {
+			if existing.ID == policy.ID {
+				return errors.ErrAlreadyExists
+			}
+		}
+		doc.RoutePolicies = append(doc.RoutePolicies, copyRoutePolicy(policy))
+		return nil
+	})
+}
+
+// Get retrieves a policy by ID
+func (r *FileRoutePolicyRepository) Get(ctx context.Context, id string) (*entity.RoutePolicy, error) {
+	var found *entity.RoutePolicy
+	r.store.read(func(doc *fileDocument) {
+		for _, policy := range doc.RoutePolicies {
+			if policy.ID == id {
+				found = copyRoutePolicy(policy)
+				return
+			}
+		}
+	})
+	if found == nil {
+		return nil, errors.ErrNotFound
+	}
+	return found, nil
+}
+
+// GetAll retrieves all policies
+func (r *FileRoutePolicyRepository) GetAll(ctx context.Context) ([]*entity.RoutePolicy, error) {
+	var policies []*entity.RoutePolicy
+	r.store.read(func(doc *fileDocument) {
+		policies = make([]*entity.RoutePolicy, len(doc.RoutePolicies))
+		for i, policy := range doc.RoutePolicies {
+			policies[i] = copyRoutePolicy(policy)
+		}
+	})
+	return policies, nil
+}
+
+// FindByService retrieves the policies of a service
+func (r *FileRoutePolicyRepository) FindByService(ctx context.Context, serviceID string) ([]*entity.RoutePolicy, error) {
+	var policies []*entity.RoutePolicy
+	r.store.read(func(doc *fileDocument) {
+		for _, policy := range doc.RoutePolicies {
+			if policy.ServiceID == serviceID {
+				policies = append(policies, copyRoutePolicy(policy))
+			}
+		}
+	})
+	return policies, nil
+}
+
+// Update updates an existing policy
+func (r *FileRoutePolicyRepository) Update(ctx context.Context, policy *entity.RoutePolicy) error {
+	return r.store.update(func(doc *fileDocument) error {
+		for i, existing := range doc.RoutePolicies {
+			if existing.ID == policy.ID {
+				doc.RoutePolicies[i] = copyRoutePolicy(policy)
+				return nil
+			}
+		}
+		return errors.ErrNotFound
+	})
+}
+
+// Delete deletes a policy by ID
+func (r *FileRoutePolicyRepository) Delete(ctx context.Context, id string) error {
+	return r.store.update(func(doc *fileDocument) error {
+		for i, existing := range doc.RoutePolicies {
+			if existing.ID == id {
+				doc.RoutePolicies = append(doc.RoutePolicies[:i:i], doc.RoutePolicies[i+1:]...)
+				return nil
+			}
+		}
+		return errors.ErrNotFound
+	})
+}
+
+// Helper functions
+
+// copyRoutePolicy copies a policy, including its limits, so callers never share the stored one
+func copyRoutePolicy(policy *entity.RoutePolicy) *entity.RoutePolicy {
+	copied := *policy
+	if policy.RateLimit != nil {
+		limit := *policy.RateLimit
+		copied.RateLimit = &limit
+	}
+	if policy.MaxConcurrent != nil {
+		limit := *policy.MaxConcurrent
+		copied.MaxConcurrent = &limit
+	}
+	return &copied
+}
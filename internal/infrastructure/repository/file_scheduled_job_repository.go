@@ -0,0 +1,106 @@
+package repository
+
+import (
+	"context"
+
+	"api-gateway-sample/internal/domain/entity"
+	"api-gateway-sample/internal/domain/repository"
+	"api-gateway-sample/pkg/errors"
+	"api-gateway-sample/pkg/logger"
+)
+
+// FileScheduledJobRepository implements the repository.ScheduledJobRepository interface on a FileStore
+type FileScheduledJobRepository struct {
+	store  *FileStore
+	logger logger.Logger
+}
+
+// NewFileScheduledJobRepository creates a new FileScheduledJobRepository instance
+func NewFileScheduledJobRepository(store *FileStore, logger logger.Logger) repository.ScheduledJobRepository {
+	return &FileScheduledJobRepository{
+		store:  store,
+		logger: logger,
+	}
+}
+
+// Create creates a new scheduled job
+func (r *FileScheduledJobRepository) Create(ctx context.Context, job *entity.ScheduledJob) error {
+	return r.store.update(func(doc *fileDocument) error {
+		for _, existing := range doc.Jobs {
+			if existing.ID == job.ID {
+				return errors.ErrAlreadyExists
+			}
+		}
+		doc.Jobs = append(doc.Jobs, copyScheduledJob(job))
+		return nil
+	})
+}
+
+// Get retrieves a scheduled job by ID
+func (r *FileScheduledJobRepository) Get(ctx context.Context, id string) (*entity.ScheduledJob, error) {
+	var found *entity.ScheduledJob
+	r.store.read(func(doc *fileDocument) {
+		for _, job := range doc.Jobs {
+			if job.ID == id {
+				found = copyScheduledJob(job)
+				return
+			}
+		}
+	})
+	if found == nil {
+		return nil, errors.ErrNotFound
+	}
+	return found, nil
+}
+
+// GetAll retrieves all scheduled jobs
+func (r *FileScheduledJobRepository) GetAll(ctx context.Context) ([]*entity.ScheduledJob, error) {
+	var jobs []*entity.ScheduledJob
+	r.store.read(func(doc *fileDocument) {
+		jobs = make([]*entity.ScheduledJob, len(doc.Jobs))
+		for i, job := range doc.Jobs {
+			jobs[i] = copyScheduledJob(job)
+		}
+	})
+	return jobs, nil
+}
+
+// Update updates an existing scheduled job
+func (r *FileScheduledJobRepository) Update(ctx context.Context, job *entity.ScheduledJob) error {
+	return r.store.update(func(doc *fileDocument) error {
+		for i, existing := range doc.Jobs {
+			if existing.ID == job.ID {
+				doc.Jobs[i] = copyScheduledJob(job)
+				return nil
+			}
+		}
+		return errors.ErrNotFound
+	})
+}
+
+// Delete deletes a scheduled job by ID
+func (r *FileScheduledJobRepository) Delete(ctx context.Context, id string) error {
+	return r.store.update(func(doc *fileDocument) error {
+		for i, existing := range doc.Jobs {
+			if existing.ID == id {
+				doc.Jobs = append(doc.Jobs[:i:i], doc.Jobs[i+1:]...)
+				return nil
+			}
+		}
+		return errors.ErrNotFound
+	})
+}
+
+// Helper functions
+
+// copyScheduledJob copies a job, including its headers, so callers never share the stored one
+func copyScheduledJob(job *entity.ScheduledJob) *entity.ScheduledJob {
+	copied := *job
+	if job.Headers != nil {
+		copied.Headers = make(map[string]string, len(job.Headers))
+		for name, value := range job.Headers {
+			copied.Headers[name] = value
+		}
+	}
+	return &copied
+}
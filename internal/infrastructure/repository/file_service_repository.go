@@ -0,0 +1,191 @@
+package repository
+
+import (
+	"context"
+
+	"api-gateway-sample/internal/domain/entity"
+	"api-gateway-sample/internal/domain/repository"
+	"api-gateway-sample/pkg/errors"
+	"api-gateway-sample/pkg/logger"
+)
+
+// FileServiceRepository implements the repository.ServiceRepository interface on a FileStore
+type FileServiceRepository struct {
+	store  *FileStore
+	logger logger.Logger
+}
+
+// NewFileServiceRepository creates a new FileServiceRepository instance
+func NewFileServiceRepository(store *FileStore, logger logger.Logger) repository.ServiceRepository {
+	return &FileServiceRepository{
+		store:  store,
+		logger: logger,
+	}
+}
+
+// Create creates a new service
+func (r *FileServiceRepository) Create(ctx context.Context, service *entity.Service) error {
+	return r.store.update(func(doc *fileDocument) error {
+		return createService(doc, service)
+	})
+}
+
+// Get retrieves a service by ID
+func (r *FileServiceRepository) Get(ctx context.Context, id string) (*entity.Service, error) {
+	return r.find(func(service *entity.Service) bool { return service.ID == id })
+}
+
+// GetByID retrieves a service by ID (alias for Get)
+func (r *FileServiceRepository) GetByID(ctx context.Context, id string) (*entity.Service, error) {
+	return r.Get(ctx, id)
+}
+
+// Update updates an existing service
+func (r *FileServiceRepository) Update(ctx context.Context, service *entity.Service) error {
+	return r.store.update(func(doc *fileDocument) error {
+		return updateService(doc, service)
+	})
+}
+
+// Delete deletes a service by ID
+func (r *FileServiceRepository) Delete(ctx context.Context, id string, revision int64) error {
+	return r.store.update(func(doc *fileDocument) error {
+		return deleteService(doc, id, revision)
+	})
+}
+
+// Apply makes the changes of a change set in a single write of the store file
+func (r *FileServiceRepository) Apply(ctx context.Context, changes repository.ServiceChangeSet) error {
+	return r.store.update(func(doc *fileDocument) error {
+		for _, id := range changes.Delete {
+			if err := deleteService(doc, id, 0); err != nil {
+				return err
+			}
+		}
+		for _, service := range changes.Update {
+			if err := updateService(doc, service); err != nil {
+				return err
+			}
+		}
+		for _, service := range changes.Create {
+			if err := createService(doc, service); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// GetAll retrieves all services
+func (r *FileServiceRepository) GetAll(ctx context.Context) ([]*entity.Service, error) {
+	var services []*entity.Service
+	r.store.read(func(doc *fileDocument) {
+		services = make([]*entity.Service, len(doc.Services))
+		for i, service := range doc.Services {
+			services[i] = service.Clone()
+		}
+	})
+	return services, nil
+}
+
+// FindByName finds a service by name
+func (r *FileServiceRepository) FindByName(ctx context.Context, name string) (*entity.Service, error) {
+	return r.find(func(service *entity.Service) bool { return service.Name == name })
+}
+
+// GetByEndpoint finds services by endpoint path and method
+func (r *FileServiceRepository) GetByEndpoint(ctx context.Context, path string, method string) ([]*entity.Service, error) {
+	var services []*entity.Service
+	r.store.read(func(doc *fileDocument) {
+		for _, service := range doc.Services {
+			if hasEndpoint(service, path, method) {
+				services = append(services, service.Clone())
+			}
+		}
+	})
+	if len(services) == 0 {
+		return nil, errors.ErrNotFound
+	}
+	return services, nil
+}
+
+// Helper functions
+
+func createService(doc *fileDocument, service *entity.Service) error {
+	for _, existing := range doc.Services {
+		if existing.ID == service.ID || existing.Name == service.Name {
+			return errors.ErrAlreadyExists
+		}
+	}
+	if service.Revision == 0 {
+		service.Revision = 1
+	}
+	doc.Services = append(doc.Services, service.Clone())
+	return nil
+}
+
+func updateService(doc *fileDocument, service *entity.Service) error {
+	index := -1
+	for i, existing := range doc.Services {
+		if existing.ID == service.ID {
+			index = i
+		} else if existing.Name == service.Name {
+			return errors.ErrAlreadyExists
+		}
+	}
+	if index < 0 {
+		return errors.ErrNotFound
+	}
+	current := doc.Services[index].Revision
+	if service.Revision > 0 && service.Revision != current {
+		return errors.ErrPreconditionFailed
+	}
+	updated := service.Clone()
+	updated.Revision = current + 1
+	doc.Services[index] = updated
+	service.Revision = updated.Revision
+	return nil
+}
+
+func deleteService(doc *fileDocument, id string, revision int64) error {
+	for i, existing := range doc.Services {
+		if existing.ID == id {
+			if revision > 0 && revision != existing.Revision {
+				return errors.ErrPreconditionFailed
+			}
+			doc.Services = append(doc.Services[:i:i], doc.Services[i+1:]...)
+			return nil
+		}
+	}
+	return errors.ErrNotFound
+}
+
+func (r *FileServiceRepository) find(match func(service *entity.Service) bool) (*entity.Service, error) {
+	var found *entity.Service
+	r.store.read(func(doc *fileDocument) {
+		for _, service := range doc.Services {
+			if match(service) {
+				found = service.Clone()
+				return
+			}
+		}
+	})
+	if found == nil {
+		return nil, errors.ErrNotFound
+	}
+	return found, nil
+}
+
+func hasEndpoint(service *entity.Service, path string, method string) bool {
+	for _, endpoint := range service.Endpoints {
+		if endpoint.Path != path {
+			continue
+		}
+		for _, supported := range endpoint.Methods {
+			if supported == method || supported == "*" {
+				return true
+			}
+		}
+	}
+	return false
+}
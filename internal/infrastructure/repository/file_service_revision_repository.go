@@ -0,0 +1,77 @@
+package repository
+
+import (
+	"context"
+
+	"api-gateway-sample/internal/domain/entity"
+	"api-gateway-sample/internal/domain/repository"
+	"api-gateway-sample/pkg/errors"
+	"api-gateway-sample/pkg/logger"
+)
+
+// FileServiceRevisionRepository implements the repository.ServiceRevisionRepository interface on a FileStore
+type FileServiceRevisionRepository struct {
+	store  *FileStore
+	logger logger.Logger
+}
+
+// NewFileServiceRevisionRepository creates a new FileServiceRevisionRepository instance
+func NewFileServiceRevisionRepository(store *FileStore, logger logger.Logger) repository.ServiceRevisionRepository {
+	return &FileServiceRevisionRepository{
+		store:  store,
+		logger: logger,
+	}
+}
+
+// Create stores a revision
+func (r *FileServiceRevisionRepository) Create(ctx context.Context, revision *entity.ServiceRevision) error {
+	return r.store.update(func(doc *fileDocument) error {
+		for _, existing := range doc.Revisions {
+			if existing.ServiceID == revision.ServiceID && existing.Revision == revision.Revision {
+				return errors.ErrAlreadyExists
+			}
+		}
+		doc.Revisions = append(doc.Revisions, copyServiceRevision(revision))
+		return nil
+	})
+}
+
+// Get retrieves a revision of a service
+func (r *FileServiceRevisionRepository) Get(ctx context.Context, serviceID string, revision int64) (*entity.ServiceRevision, error) {
+	var found *entity.ServiceRevision
+	r.store.read(func(doc *fileDocument) {
+		for _, existing := range doc.Revisions {
+			if existing.ServiceID == serviceID && existing.Revision == revision {
+				found = copyServiceRevision(existing)
+				return
+			}
+		}
+	})
+	if found == nil {
+		return nil, errors.ErrNotFound
+	}
+	return found, nil
+}
+
+// List retrieves the revisions of a service, newest first
+func (r *FileServiceRevisionRepository) List(ctx context.Context, serviceID string) ([]*entity.ServiceRevision, error) {
+	var revisions []*entity.ServiceRevision
+	r.store.read(func(doc *fileDocument) {
+		// Revisions are appended in order
+		for i := len(doc.Revisions) - 1; i >= 0; i-- {
+			if doc.Revisions[i].ServiceID == serviceID {
+				revisions = append(revisions, copyServiceRevision(doc.Revisions[i]))
+			}
+		}
+	})
+	return revisions, nil
+}
+
+func copyServiceRevision(revision *entity.ServiceRevision) *entity.ServiceRevision {
+	clone := *revision
+	clone.Changes = append([]entity.FieldChange(nil), revision.Changes...)
+	if revision.Service != nil {
+		clone.Service = revision.Service.Clone()
+	}
+	return &clone
+}
@@ -0,0 +1,194 @@
+package repository
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"api-gateway-sample/internal/domain/entity"
+
+	"gopkg.in/yaml.v3"
+)
+
+// FileStore persists services and their revisions, webhook subscriptions, API keys and scheduled
+// jobs to a single local JSON or YAML file, so the gateway can run as a standalone edge proxy
+// without Postgres.
+// The format follows the file extension. Every change rewrites the file atomically.
+type FileStore struct {
+	path string
+	yaml bool
+
+	mu  sync.RWMutex
+	doc fileDocument
+}
+
+// fileDocument is the content of the store file
+type fileDocument struct {
+	Services []*entity.Service `json:"services"`
+	Webhooks []*fileWebhook    `json:"webhooks"`
+	APIKeys  []*entity.APIKey  `json:"apiKeys"`
+
+	Jobs []*entity.ScheduledJob `json:"jobs"`
+
+	Revisions []*entity.ServiceRevision `json:"revisions,omitempty"`
+
+	RateLimitOverrides []*entity.RateLimitOverride `json:"rateLimitOverrides,omitempty"`
+
+	RoutePolicies []*entity.RoutePolicy `json:"routePolicies,omitempty"`
+
+	FeatureFlags []*entity.FeatureFlag `json:"featureFlags,omitempty"`
+}
+
+// fileWebhook is the stored form of a webhook subscription; unlike the entity it keeps the secret
+type fileWebhook struct {
+	ID          string    `json:"id"`
+	URL         string    `json:"url"`
+	Secret      string    `json:"secret"`
+	Events      []string  `json:"events"`
+	Description string    `json:"description"`
+	Active      bool      `json:"active"`
+	CreatedAt   time.Time `json:"createdAt"`
+}
+
+// NewFileStore creates a new FileStore instance, loading the file if it exists
+func NewFileStore(path string) (*FileStore, error) {
+	ext := strings.ToLower(filepath.Ext(path))
+	store := &FileStore{
+		path: path,
+		yaml: ext == ".yaml" || ext == ".yml",
+	}
+
+	if err := store.Reload(); err != nil {
+		return nil, err
+	}
+	return store, nil
+}
+
+// Reload replaces the document with the content of the file, to pick up changes written by
+// another gateway instance sharing it. A missing file leaves the document unchanged.
+func (s *FileStore) Reload() error {
+	data, err := os.ReadFile(s.path)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read store file: %w", err)
+	}
+
+	var doc fileDocument
+	if err := s.decode(data, &doc); err != nil {
+		return fmt.Errorf("failed to parse store file %s: %w", s.path, err)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.doc = doc
+	return nil
+}
+
+// read calls fn with the current document under a read lock
+func (s *FileStore) read(fn func(doc *fileDocument)) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	fn(&s.doc)
+}
+
+// update applies fn to a copy of the document and persists it if fn succeeds
+func (s *FileStore) update(fn func(doc *fileDocument) error) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	// The whole document is copied so that no collection is left out, and its slices are cloned
+	// so that fn cannot change the current document when it fails or the file is not written
+	doc := s.doc
+	doc.Services = append([]*entity.Service(nil), doc.Services...)
+	doc.Webhooks = append([]*fileWebhook(nil), doc.Webhooks...)
+	doc.APIKeys = append([]*entity.APIKey(nil), doc.APIKeys...)
+	doc.Jobs = append([]*entity.ScheduledJob(nil), doc.Jobs...)
+	doc.Revisions = append([]*entity.ServiceRevision(nil), doc.Revisions...)
+	doc.RateLimitOverrides = append([]*entity.RateLimitOverride(nil), doc.RateLimitOverrides...)
+	doc.RoutePolicies = append([]*entity.RoutePolicy(nil), doc.RoutePolicies...)
+	doc.FeatureFlags = append([]*entity.FeatureFlag(nil), doc.FeatureFlags...)
+	if err := fn(&doc); err != nil {
+		return err
+	}
+
+	if err := s.write(&doc); err != nil {
+		return err
+	}
+	s.doc = doc
+	return nil
+}
+
+// write replaces the store file through a temporary file so a crash never leaves it half written
+func (s *FileStore) write(doc *fileDocument) error {
+	data, err := s.encode(doc)
+	if err != nil {
+		return fmt.Errorf("failed to encode store file: %w", err)
+	}
+
+	dir := filepath.Dir(s.path)
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return fmt.Errorf("failed to create store directory: %w", err)
+	}
+
+	tmp, err := os.CreateTemp(dir, filepath.Base(s.path)+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("failed to write store file: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write store file: %w", err)
+	}
+	if err := tmp.Chmod(0o600); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write store file: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write store file: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), s.path); err != nil {
+		return fmt.Errorf("failed to write store file: %w", err)
+	}
+	return nil
+}
+
+// encode serializes the document. YAML goes through JSON so both formats use the entities' JSON field names.
+func (s *FileStore) encode(doc *fileDocument) ([]byte, error) {
+	data, err := json.MarshalIndent(doc, "", "  ")
+	if err != nil || !s.yaml {
+		return data, err
+	}
+
+	var generic interface{}
+	if err := json.Unmarshal(data, &generic); err != nil {
+		return nil, err
+	}
+	return yaml.Marshal(generic)
+}
+
+func (s *FileStore) decode(data []byte, doc *fileDocument) error {
+	if s.yaml {
+		var generic interface{}
+		if err := yaml.Unmarshal(data, &generic); err != nil {
+			return err
+		}
+		if generic == nil {
+			return nil
+		}
+		var err error
+		if data, err = json.Marshal(generic); err != nil {
+			return err
+		}
+	}
+	if len(strings.TrimSpace(string(data))) == 0 {
+		return nil
+	}
+	return json.Unmarshal(data, doc)
+}
@@ -0,0 +1,304 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"api-gateway-sample/internal/domain/entity"
+	"api-gateway-sample/internal/domain/repository"
+	"api-gateway-sample/internal/domain/repository/repositorytest"
+	"api-gateway-sample/pkg/errors"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// nopLogger discards all log output
+type nopLogger struct{}
+
+func (nopLogger) Debug(msg string, keysAndValues ...interface{}) {}
+func (nopLogger) Info(msg string, keysAndValues ...interface{})  {}
+func (nopLogger) Warn(msg string, keysAndValues ...interface{})  {}
+func (nopLogger) Error(msg string, keysAndValues ...interface{}) {}
+func (nopLogger) Fatal(msg string, keysAndValues ...interface{}) {}
+
+func TestFileStore(t *testing.T) {
+	for _, name := range []string{"gateway.json", "gateway.yaml"} {
+		t.Run(name, func(t *testing.T) {
+			ctx := context.Background()
+			path := filepath.Join(t.TempDir(), "data", name)
+
+			store, err := NewFileStore(path)
+			require.NoError(t, err)
+			services := NewFileServiceRepository(store, nopLogger{})
+			webhooks := NewFileWebhookRepository(store, nopLogger{})
+
+			// 1. Services and webhooks are written to the file
+			service := entity.NewService("svc-1", "orders", "1.0.0", "Orders", "http://orders:8080", 30, 3)
+			service.AddEndpoint(entity.Endpoint{Path: "/api/orders", Methods: []string{"GET"}, RateLimit: 10})
+			require.NoError(t, services.Create(ctx, service))
+			assert.ErrorIs(t, services.Create(ctx, service), errors.ErrAlreadyExists)
+
+			webhook := &entity.WebhookSubscription{
+				ID:        "wh-1",
+				URL:       "https://example.com/hook",
+				Secret:    "s3cret",
+				Events:    []string{entity.EventServiceCreated},
+				Active:    true,
+				CreatedAt: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+			}
+			require.NoError(t, webhooks.Create(ctx, webhook))
+
+			info, err := os.Stat(path)
+			require.NoError(t, err)
+			assert.Equal(t, os.FileMode(0o600), info.Mode().Perm())
+
+			// 2. A new store reads them back, including the webhook secret
+			reloaded, err := NewFileStore(path)
+			require.NoError(t, err)
+			services = NewFileServiceRepository(reloaded, nopLogger{})
+			webhooks = NewFileWebhookRepository(reloaded, nopLogger{})
+
+			got, err := services.Get(ctx, "svc-1")
+			require.NoError(t, err)
+			assert.Equal(t, "http://orders:8080", got.BaseURL)
+			require.Len(t, got.Endpoints, 1)
+			assert.Equal(t, 10, got.Endpoints[0].RateLimit)
+
+			matches, err := services.GetByEndpoint(ctx, "/api/orders", "GET")
+			require.NoError(t, err)
+			assert.Len(t, matches, 1)
+
+			gotWebhook, err := webhooks.Get(ctx, "wh-1")
+			require.NoError(t, err)
+			assert.Equal(t, "s3cret", gotWebhook.Secret)
+			assert.True(t, gotWebhook.CreatedAt.Equal(webhook.CreatedAt))
+
+			// 3. Updates and deletes are persisted
+			got.Description = "Order service"
+			require.NoError(t, services.Update(ctx, got))
+			require.NoError(t, services.Delete(ctx, "svc-1", 0))
+			assert.ErrorIs(t, services.Delete(ctx, "svc-1", 0), errors.ErrNotFound)
+			require.NoError(t, webhooks.Delete(ctx, "wh-1"))
+
+			reloaded, err = NewFileStore(path)
+			require.NoError(t, err)
+			all, err := NewFileServiceRepository(reloaded, nopLogger{}).GetAll(ctx)
+			require.NoError(t, err)
+			assert.Empty(t, all)
+		})
+	}
+}
+
+func TestFileStore_KeepsRateLimitOverrides(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "gateway.json")
+	store, err := NewFileStore(path)
+	require.NoError(t, err)
+	services := NewFileServiceRepository(store, nopLogger{})
+	overrides := NewFileRateLimitOverrideRepository(store, nopLogger{})
+
+	service := entity.NewService("svc-1", "orders", "1.0.0", "Orders", "http://orders:8080", 30, 3)
+	service.AddEndpoint(entity.Endpoint{Path: "/api/orders", Methods: []string{"GET"}})
+	require.NoError(t, services.Create(ctx, service))
+	limit := 100
+	require.NoError(t, overrides.Create(ctx, &entity.RateLimitOverride{ID: "ovr-1", Consumer: "partner-a", ServiceID: "svc-1", RateLimit: &limit}))
+	require.NoError(t, overrides.Create(ctx, &entity.RateLimitOverride{ID: "ovr-2", Consumer: "partner-b", ServiceID: "svc-1", RateLimit: &limit}))
+
+	// Writes to other parts of the document keep the overrides, in memory and in the file
+	service.BaseURL = "http://orders-v2:8080"
+	require.NoError(t, services.Update(ctx, service))
+	got, err := overrides.GetAll(ctx)
+	require.NoError(t, err)
+	assert.Len(t, got, 2)
+
+	reloaded, err := NewFileStore(path)
+	require.NoError(t, err)
+	got, err = NewFileRateLimitOverrideRepository(reloaded, nopLogger{}).GetAll(ctx)
+	require.NoError(t, err)
+	assert.Len(t, got, 2)
+}
+
+func TestFileStore_KeepsRoutePolicies(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "gateway.json")
+	store, err := NewFileStore(path)
+	require.NoError(t, err)
+	services := NewFileServiceRepository(store, nopLogger{})
+	policies := NewFileRoutePolicyRepository(store, nopLogger{})
+
+	service := entity.NewService("svc-1", "orders", "1.0.0", "Orders", "http://orders:8080", 30, 3)
+	service.AddEndpoint(entity.Endpoint{Path: "/api/orders", Methods: []string{"GET"}})
+	require.NoError(t, services.Create(ctx, service))
+	require.NoError(t, policies.Create(ctx, &entity.RoutePolicy{ID: "pol-1", ServiceID: "svc-1", Schedule: "0 2 * * 0", Duration: 60, Action: entity.RoutePolicyMaintenance}))
+	require.NoError(t, policies.Create(ctx, &entity.RoutePolicy{ID: "pol-2", ServiceID: "svc-1", Schedule: "0 * * * *", Duration: 5, Action: entity.RoutePolicyDisable}))
+
+	// Writes to other parts of the document keep the policies, in memory and in the file
+	service.BaseURL = "http://orders-v2:8080"
+	require.NoError(t, services.Update(ctx, service))
+	got, err := policies.FindByService(ctx, "svc-1")
+	require.NoError(t, err)
+	assert.Len(t, got, 2)
+
+	reloaded, err := NewFileStore(path)
+	require.NoError(t, err)
+	got, err = NewFileRoutePolicyRepository(reloaded, nopLogger{}).GetAll(ctx)
+	require.NoError(t, err)
+	assert.Len(t, got, 2)
+}
+
+func TestFileStore_KeepsFeatureFlags(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "gateway.json")
+	store, err := NewFileStore(path)
+	require.NoError(t, err)
+	services := NewFileServiceRepository(store, nopLogger{})
+	flags := NewFileFeatureFlagRepository(store, nopLogger{})
+
+	service := entity.NewService("svc-1", "orders", "1.0.0", "Orders", "http://orders:8080", 30, 3)
+	service.AddEndpoint(entity.Endpoint{Path: "/api/orders", Methods: []string{"GET"}})
+	require.NoError(t, services.Create(ctx, service))
+	require.NoError(t, flags.Create(ctx, &entity.FeatureFlag{Key: "composite-orders", Enabled: true}))
+	require.NoError(t, flags.Create(ctx, &entity.FeatureFlag{Key: "new-checkout"}))
+
+	// Writes to other parts of the document keep the flags, in memory and in the file
+	service.BaseURL = "http://orders-v2:8080"
+	require.NoError(t, services.Update(ctx, service))
+	got, err := flags.GetAll(ctx)
+	require.NoError(t, err)
+	assert.Len(t, got, 2)
+
+	reloaded, err := NewFileStore(path)
+	require.NoError(t, err)
+	got, err = NewFileFeatureFlagRepository(reloaded, nopLogger{}).GetAll(ctx)
+	require.NoError(t, err)
+	assert.Len(t, got, 2)
+}
+
+// TestFileStore_UpdateCopiesEveryCollection covers the collections of the document through
+// reflection, so that those added later are covered too
+func TestFileStore_UpdateCopiesEveryCollection(t *testing.T) {
+	store, err := NewFileStore(filepath.Join(t.TempDir(), "gateway.json"))
+	require.NoError(t, err)
+
+	current := reflect.ValueOf(&store.doc).Elem()
+	for i := 0; i < current.NumField(); i++ {
+		field := current.Field(i)
+		field.Set(reflect.Append(field, reflect.New(field.Type().Elem().Elem())))
+	}
+
+	// 1. A failed update leaves the current document as it was
+	require.Error(t, store.update(func(doc *fileDocument) error {
+		updated := reflect.ValueOf(doc).Elem()
+		for i := 0; i < updated.NumField(); i++ {
+			element := updated.Field(i).Index(0)
+			element.Set(reflect.Zero(element.Type()))
+		}
+		return fmt.Errorf("rejected")
+	}))
+	for i := 0; i < current.NumField(); i++ {
+		assert.False(t, current.Field(i).Index(0).IsNil(), current.Type().Field(i).Name)
+	}
+
+	// 2. Updates keep every collection
+	require.NoError(t, store.update(func(doc *fileDocument) error { return nil }))
+	for i := 0; i < current.NumField(); i++ {
+		assert.Equal(t, 1, current.Field(i).Len(), current.Type().Field(i).Name)
+	}
+}
+
+func TestFileServiceRepository_ReturnsCopies(t *testing.T) {
+	ctx := context.Background()
+	store, err := NewFileStore(filepath.Join(t.TempDir(), "gateway.json"))
+	require.NoError(t, err)
+	services := NewFileServiceRepository(store, nopLogger{})
+
+	require.NoError(t, services.Create(ctx, entity.NewService("svc-1", "orders", "1.0.0", "", "http://orders:8080", 30, 3)))
+
+	// Changing a returned service must not change the store without Update
+	got, err := services.Get(ctx, "svc-1")
+	require.NoError(t, err)
+	got.BaseURL = "http://changed:8080"
+
+	got, err = services.Get(ctx, "svc-1")
+	require.NoError(t, err)
+	assert.Equal(t, "http://orders:8080", got.BaseURL)
+}
+
+func TestFileServiceRepository_Apply(t *testing.T) {
+	ctx := context.Background()
+	store, err := NewFileStore(filepath.Join(t.TempDir(), "gateway.json"))
+	require.NoError(t, err)
+	services := NewFileServiceRepository(store, nopLogger{})
+	require.NoError(t, services.Create(ctx, entity.NewService("svc-1", "orders", "1.0.0", "", "http://orders:8080", 30, 3)))
+	require.NoError(t, services.Create(ctx, entity.NewService("svc-2", "users", "1.0.0", "", "http://users:8080", 30, 3)))
+
+	// 1. A failing change leaves every service as it was
+	stale := entity.NewService("svc-1", "orders", "1.0.0", "", "http://orders-v2:8080", 30, 3)
+	stale.Revision = 5
+	err = services.Apply(ctx, repository.ServiceChangeSet{
+		Create: []*entity.Service{entity.NewService("svc-3", "billing", "1.0.0", "", "http://billing:8080", 30, 3)},
+		Update: []*entity.Service{stale},
+		Delete: []string{"svc-2"},
+	})
+	assert.ErrorIs(t, err, errors.ErrPreconditionFailed)
+	all, err := services.GetAll(ctx)
+	require.NoError(t, err)
+	assert.Len(t, all, 2)
+
+	// 2. Otherwise all of them are made, deletions first so that names can be reused
+	stale.Revision = 1
+	err = services.Apply(ctx, repository.ServiceChangeSet{
+		Create: []*entity.Service{entity.NewService("svc-3", "users", "2.0.0", "", "http://users-v2:8080", 30, 3)},
+		Update: []*entity.Service{stale},
+		Delete: []string{"svc-2"},
+	})
+	require.NoError(t, err)
+	got, err := services.FindByName(ctx, "users")
+	require.NoError(t, err)
+	assert.Equal(t, "svc-3", got.ID)
+	got, err = services.Get(ctx, "svc-1")
+	require.NoError(t, err)
+	assert.Equal(t, "http://orders-v2:8080", got.BaseURL)
+}
+
+func TestFileStore_Reload(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "gateway.json")
+	writer, err := NewFileStore(path)
+	require.NoError(t, err)
+	reader, err := NewFileStore(path)
+	require.NoError(t, err)
+
+	// A change written by another store sharing the file is seen after a reload
+	require.NoError(t, NewFileServiceRepository(writer, nopLogger{}).Create(ctx, entity.NewService("svc-1", "orders", "1.0.0", "", "http://orders:8080", 30, 3)))
+	services := NewFileServiceRepository(reader, nopLogger{})
+	_, err = services.Get(ctx, "svc-1")
+	assert.ErrorIs(t, err, errors.ErrNotFound)
+
+	require.NoError(t, reader.Reload())
+	got, err := services.Get(ctx, "svc-1")
+	require.NoError(t, err)
+	assert.Equal(t, "http://orders:8080", got.BaseURL)
+}
+
+func TestNewFileStore_InvalidFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "gateway.json")
+	require.NoError(t, os.WriteFile(path, []byte("{not json"), 0o600))
+
+	_, err := NewFileStore(path)
+	assert.Error(t, err)
+}
+
+func TestFileServiceRepository_Contract(t *testing.T) {
+	repositorytest.TestServiceRepository(t, func(t *testing.T) repository.ServiceRepository {
+		store, err := NewFileStore(filepath.Join(t.TempDir(), "gateway.json"))
+		require.NoError(t, err)
+		return NewFileServiceRepository(store, nopLogger{})
+	})
+}
@@ -0,0 +1,106 @@
+package repository
+
+import (
+	"context"
+
+	"api-gateway-sample/internal/domain/entity"
+	"api-gateway-sample/internal/domain/repository"
+	"api-gateway-sample/pkg/errors"
+	"api-gateway-sample/pkg/logger"
+)
+
+// FileWebhookRepository implements the repository.WebhookRepository interface on a FileStore
+type FileWebhookRepository struct {
+	store  *FileStore
+	logger logger.Logger
+}
+
+// NewFileWebhookRepository creates a new FileWebhookRepository instance
+func NewFileWebhookRepository(store *FileStore, logger logger.Logger) repository.WebhookRepository {
+	return &FileWebhookRepository{
+		store:  store,
+		logger: logger,
+	}
+}
+
+// Create creates a new webhook subscription
+func (r *FileWebhookRepository) Create(ctx context.Context, webhook *entity.WebhookSubscription) error {
+	return r.store.update(func(doc *fileDocument) error {
+		for _, existing := range doc.Webhooks {
+			if existing.ID == webhook.ID {
+				return errors.ErrAlreadyExists
+			}
+		}
+		doc.Webhooks = append(doc.Webhooks, mapWebhookToFile(webhook))
+		return nil
+	})
+}
+
+// Get retrieves a webhook subscription by ID
+func (r *FileWebhookRepository) Get(ctx context.Context, id string) (*entity.WebhookSubscription, error) {
+	var found *entity.WebhookSubscription
+	r.store.read(func(doc *fileDocument) {
+		for _, webhook := range doc.Webhooks {
+			if webhook.ID == id {
+				found = mapFileToWebhook(webhook)
+				return
+			}
+		}
+	})
+	if found == nil {
+		return nil, errors.ErrNotFound
+	}
+	return found, nil
+}
+
+// GetAll retrieves all webhook subscriptions
+func (r *FileWebhookRepository) GetAll(ctx context.Context) ([]*entity.WebhookSubscription, error) {
+	var webhooks []*entity.WebhookSubscription
+	r.store.read(func(doc *fileDocument) {
+		webhooks = make([]*entity.WebhookSubscription, len(doc.Webhooks))
+		for i, webhook := range doc.Webhooks {
+			webhooks[i] = mapFileToWebhook(webhook)
+		}
+	})
+	return webhooks, nil
+}
+
+// Delete deletes a webhook subscription by ID
+func (r *FileWebhookRepository) Delete(ctx context.Context, id string) error {
+	return r.store.update(func(doc *fileDocument) error {
+		for i, existing := range doc.Webhooks {
+			if existing.ID == id {
+				doc.Webhooks = append(doc.Webhooks[:i:i], doc.Webhooks[i+1:]...)
+				return nil
+			}
+		}
+		return errors.ErrNotFound
+	})
+}
+
+// Helper functions
+
+func mapWebhookToFile(webhook *entity.WebhookSubscription) *fileWebhook {
+	return &fileWebhook{
+		ID:          webhook.ID,
+		URL:         webhook.URL,
+		Secret:      webhook.Secret,
+		Events:      append([]string(nil), webhook.Events...),
+		Description: webhook.Description,
+		Active:      webhook.Active,
+		CreatedAt:   webhook.CreatedAt,
+	}
+}
+
+func mapFileToWebhook(webhook *fileWebhook) *entity.WebhookSubscription {
+	events := append(make([]string, 0, len(webhook.Events)), webhook.Events...)
+	return &entity.WebhookSubscription{
+		ID:          webhook.ID,
+		URL:         webhook.URL,
+		Secret:      webhook.Secret,
+		Events:      events,
+		Description: webhook.Description,
+		Active:      webhook.Active,
+		CreatedAt:   webhook.CreatedAt,
+	}
+}
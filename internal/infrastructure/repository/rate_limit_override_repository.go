@@ -0,0 +1,149 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"api-gateway-sample/internal/domain/entity"
+	"api-gateway-sample/internal/domain/repository"
+	"api-gateway-sample/pkg/errors"
+	"api-gateway-sample/pkg/logger"
+
+	"gorm.io/gorm"
+)
+
+// RateLimitOverrideModel represents the rate limit override database model
+type RateLimitOverrideModel struct {
+	ID            string `gorm:"primaryKey"`
+	Consumer      string `gorm:"index"`
+	ServiceID     string
+	Endpoint      string
+	RateLimit     *int
+	MaxConcurrent *int
+
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+// TableName returns the rate limit override table name
+func (RateLimitOverrideModel) TableName() string {
+	return "rate_limit_overrides"
+}
+
+// RateLimitOverrideRepositoryImpl implements the repository.RateLimitOverrideRepository interface
+type RateLimitOverrideRepositoryImpl struct {
+	db     *gorm.DB
+	logger logger.Logger
+}
+
+// NewRateLimitOverrideRepositoryImpl creates a new RateLimitOverrideRepositoryImpl instance
+func NewRateLimitOverrideRepositoryImpl(db *gorm.DB, logger logger.Logger) repository.RateLimitOverrideRepository {
+	return &RateLimitOverrideRepositoryImpl{
+		db:     db,
+		logger: logger,
+	}
+}
+
+// Create creates a new override
+func (r *RateLimitOverrideRepositoryImpl) Create(ctx context.Context, override *entity.RateLimitOverride) error {
+	if err := r.db.WithContext(ctx).Create(mapRateLimitOverrideToModel(override)).Error; err != nil {
+		return fmt.Errorf("failed to create rate limit override: %w", err)
+	}
+	return nil
+}
+
+// Get retrieves an override by ID
+func (r *RateLimitOverrideRepositoryImpl) Get(ctx context.Context, id string) (*entity.RateLimitOverride, error) {
+	var model RateLimitOverrideModel
+	if err := r.db.WithContext(ctx).First(&model, "id = ?", id).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, errors.ErrNotFound
+		}
+		return nil, fmt.Errorf("failed to get rate limit override: %w", err)
+	}
+	return mapModelToRateLimitOverride(&model), nil
+}
+
+// GetAll retrieves all overrides
+func (r *RateLimitOverrideRepositoryImpl) GetAll(ctx context.Context) ([]*entity.RateLimitOverride, error) {
+	return r.find(r.db.WithContext(ctx))
+}
+
+// FindByConsumer retrieves the overrides of a consumer
+func (r *RateLimitOverrideRepositoryImpl) FindByConsumer(ctx context.Context, consumer string) ([]*entity.RateLimitOverride, error) {
+	return r.find(r.db.WithContext(ctx).Where("consumer = ?", consumer))
+}
+
+// Update updates an existing override
+func (r *RateLimitOverrideRepositoryImpl) Update(ctx context.Context, override *entity.RateLimitOverride) error {
+	result := r.db.WithContext(ctx).Model(&RateLimitOverrideModel{}).Where("id = ?", override.ID).Updates(map[string]interface{}{
+		"consumer":       override.Consumer,
+		"service_id":     override.ServiceID,
+		"endpoint":       override.Endpoint,
+		"rate_limit":     override.RateLimit,
+		"max_concurrent": override.MaxConcurrent,
+		"updated_at":     override.UpdatedAt,
+	})
+	if result.Error != nil {
+		return fmt.Errorf("failed to update rate limit override: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return errors.ErrNotFound
+	}
+	return nil
+}
+
+// Delete deletes an override by ID
+func (r *RateLimitOverrideRepositoryImpl) Delete(ctx context.Context, id string) error {
+	result := r.db.WithContext(ctx).Delete(&RateLimitOverrideModel{}, "id = ?", id)
+	if result.Error != nil {
+		return fmt.Errorf("failed to delete rate limit override: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return errors.ErrNotFound
+	}
+	return nil
+}
+
+// find retrieves the overrides matching a query in creation order
+func (r *RateLimitOverrideRepositoryImpl) find(query *gorm.DB) ([]*entity.RateLimitOverride, error) {
+	var models []RateLimitOverrideModel
+	if err := query.Order("created_at").Find(&models).Error; err != nil {
+		return nil, fmt.Errorf("failed to get rate limit overrides: %w", err)
+	}
+
+	overrides := make([]*entity.RateLimitOverride, len(models))
+	for i := range models {
+		overrides[i] = mapModelToRateLimitOverride(&models[i])
+	}
+	return overrides, nil
+}
+
+// Helper functions
+
+func mapRateLimitOverrideToModel(override *entity.RateLimitOverride) *RateLimitOverrideModel {
+	return &RateLimitOverrideModel{
+		ID:            override.ID,
+		Consumer:      override.Consumer,
+		ServiceID:     override.ServiceID,
+		Endpoint:      override.Endpoint,
+		RateLimit:     override.RateLimit,
+		MaxConcurrent: override.MaxConcurrent,
+		CreatedAt:     override.CreatedAt,
+		UpdatedAt:     override.UpdatedAt,
+	}
+}
+
+func mapModelToRateLimitOverride(model *RateLimitOverrideModel) *entity.RateLimitOverride {
+	return &entity.RateLimitOverride{
+		ID:            model.ID,
+		Consumer:      model.Consumer,
+		ServiceID:     model.ServiceID,
+		Endpoint:      model.Endpoint,
+		RateLimit:     model.RateLimit,
+		MaxConcurrent: model.MaxConcurrent,
+		CreatedAt:     model.CreatedAt,
+		UpdatedAt:     model.UpdatedAt,
+	}
+}
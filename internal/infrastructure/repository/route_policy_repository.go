@@ -0,0 +1,181 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"api-gateway-sample/internal/domain/entity"
+	"api-gateway-sample/internal/domain/repository"
+	"api-gateway-sample/pkg/errors"
+	"api-gateway-sample/pkg/logger"
+
+	"gorm.io/gorm"
+)
+
+// RoutePolicyModel represents the route policy database model
+type RoutePolicyModel struct {
+	ID            string `gorm:"primaryKey"`
+	Name          string
+	ServiceID     string `gorm:"index"`
+	Endpoint      string
+	Schedule      string
+	Duration      int
+	Timezone      string
+	Action        string
+	Status        int
+	Body          string
+	ContentType   string
+	RateLimit     *int
+	MaxConcurrent *int
+	Enabled       bool
+
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+// TableName returns the route policy table name
+func (RoutePolicyModel) TableName() string {
+	return "route_policies"
+}
+
+// RoutePolicyRepositoryImpl implements the repository.RoutePolicyRepository interface
+type RoutePolicyRepositoryImpl struct {
+	db     *gorm.DB
+	logger logger.Logger
+}
+
+// NewRoutePolicyRepositoryImpl creates a new RoutePolicyRepositoryImpl instance
+func NewRoutePolicyRepositoryImpl(db *gorm.DB, logger logger.Logger) repository.RoutePolicyRepository {
+	return &RoutePolicyRepositoryImpl{
+		db:     db,
+		logger: logger,
+	}
+}
+
+// Create creates a new policy
+func (r *RoutePolicyRepositoryImpl) Create(ctx context.Context, policy *entity.RoutePolicy) error {
+	if err := r.db.WithContext(ctx).Create(mapRoutePolicyToModel(policy)).Error; err != nil {
+		return fmt.Errorf("failed to create route policy: %w", err)
+	}
+	return nil
+}
+
+// Get retrieves a policy by ID
+func (r *RoutePolicyRepositoryImpl) Get(ctx context.Context, id string) (*entity.RoutePolicy, error) {
+	var model RoutePolicyModel
+	if err := r.db.WithContext(ctx).First(&model, "id = ?", id).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, errors.ErrNotFound
+		}
+		return nil, fmt.Errorf("failed to get route policy: %w", err)
+	}
+	return mapModelToRoutePolicy(&model), nil
+}
+
+// GetAll retrieves all policies
+func (r *RoutePolicyRepositoryImpl) GetAll(ctx context.Context) ([]*entity.RoutePolicy, error) {
+	return r.find(r.db.WithContext(ctx))
+}
+
+// FindByService retrieves the policies of a service
+func (r *RoutePolicyRepositoryImpl) FindByService(ctx context.Context, serviceID string) ([]*entity.RoutePolicy, error) {
+	return r.find(r.db.WithContext(ctx).Where("service_id = ?", serviceID))
+}
+
+// Update updates an existing policy
+func (r *RoutePolicyRepositoryImpl) Update(ctx context.Context, policy *entity.RoutePolicy) error {
+	result := r.db.WithContext(ctx).Model(&RoutePolicyModel{}).Where("id = ?", policy.ID).Updates(map[string]interface{}{
+		"name":           policy.Name,
+		"service_id":     policy.ServiceID,
+		"endpoint":       policy.Endpoint,
+		"schedule":       policy.Schedule,
+		"duration":       policy.Duration,
+		"timezone":       policy.Timezone,
+		"action":         policy.Action,
+		"status":         policy.Status,
+		"body":           policy.Body,
+		"content_type":   policy.ContentType,
+		"rate_limit":     policy.RateLimit,
+		"max_concurrent": policy.MaxConcurrent,
+		"enabled":        policy.Enabled,
+		"updated_at":     policy.UpdatedAt,
+	})
+	if result.Error != nil {
+		return fmt.Errorf("failed to update route policy: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return errors.ErrNotFound
+	}
+	return nil
+}
+
+// Delete deletes a policy by ID
+func (r *RoutePolicyRepositoryImpl) Delete(ctx context.Context, id string) error {
+	result := r.db.WithContext(ctx).Delete(&RoutePolicyModel{}, "id = ?", id)
+	if result.Error != nil {
+		return fmt.Errorf("failed to delete route policy: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return errors.ErrNotFound
+	}
+	return nil
+}
+
+// find retrieves the policies matching a query in creation order
+func (r *RoutePolicyRepositoryImpl) find(query *gorm.DB) ([]*entity.RoutePolicy, error) {
+	var models []RoutePolicyModel
+	if err := query.Order("created_at").Find(&models).Error; err != nil {
+		return nil, fmt.Errorf("failed to get route policies: %w", err)
+	}
+
+	policies := make([]*entity.RoutePolicy, len(models))
+	for i := range models {
+		policies[i] = mapModelToRoutePolicy(&models[i])
+	}
+	return policies, nil
+}
+
+// Helper functions
+
+func mapRoutePolicyToModel(policy *entity.RoutePolicy) *RoutePolicyModel {
+	return &RoutePolicyModel{
+		ID:            policy.ID,
+		Name:          policy.Name,
+		ServiceID:     policy.ServiceID,
+		Endpoint:      policy.Endpoint,
+		Schedule:      policy.Schedule,
+		Duration:      policy.Duration,
+		Timezone:      policy.Timezone,
+		Action:        policy.Action,
+		Status:        policy.Status,
+		Body:          policy.Body,
+		ContentType:   policy.ContentType,
+		RateLimit:     policy.RateLimit,
+		MaxConcurrent: policy.MaxConcurrent,
+		Enabled:       policy.Enabled,
+		CreatedAt:     policy.CreatedAt,
+		UpdatedAt:     policy.UpdatedAt,
+	}
+}
+
+func mapModelToRoutePolicy(model *RoutePolicyModel) *entity.RoutePolicy {
+	return &entity.RoutePolicy{
+		ID:            model.ID,
+		Name:          model.Name,
+		ServiceID:     model.ServiceID,
+		Endpoint:      model.Endpoint,
+		Schedule:      model.Schedule,
+		Duration:      model.Duration,
+		Timezone:      model.Timezone,
+		Action:        model.Action,
+		Status:        model.Status,
+		Body:          model.Body,
+		ContentType:   model.ContentType,
+		RateLimit:     model.RateLimit,
+		MaxConcurrent: model.MaxConcurrent,
+		Enabled:       model.Enabled,
+		CreatedAt:     model.CreatedAt,
+		UpdatedAt:     model.UpdatedAt,
+	}
+}
@@ -0,0 +1,168 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"api-gateway-sample/internal/domain/entity"
+	"api-gateway-sample/internal/domain/repository"
+	"api-gateway-sample/pkg/errors"
+	"api-gateway-sample/pkg/logger"
+
+	"gorm.io/gorm"
+)
+
+// ScheduledJobModel represents the scheduled job database model
+type ScheduledJobModel struct {
+	ID       string `gorm:"primaryKey"`
+	Name     string
+	Schedule string
+	Method   string
+	Path     string
+	Headers  string // JSON object of header names to values
+	Body     string
+	Timeout  int
+	Enabled  bool
+
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+// TableName returns the scheduled job table name
+func (ScheduledJobModel) TableName() string {
+	return "scheduled_jobs"
+}
+
+// ScheduledJobRepositoryImpl implements the repository.ScheduledJobRepository interface
+type ScheduledJobRepositoryImpl struct {
+	db     *gorm.DB
+	logger logger.Logger
+}
+
+// NewScheduledJobRepositoryImpl creates a new ScheduledJobRepositoryImpl instance
+func NewScheduledJobRepositoryImpl(db *gorm.DB, logger logger.Logger) repository.ScheduledJobRepository {
+	return &ScheduledJobRepositoryImpl{
+		db:     db,
+		logger: logger,
+	}
+}
+
+// Create creates a new scheduled job
+func (r *ScheduledJobRepositoryImpl) Create(ctx context.Context, job *entity.ScheduledJob) error {
+	model := mapScheduledJobToModel(job)
+	if err := r.db.WithContext(ctx).Create(model).Error; err != nil {
+		return fmt.Errorf("failed to create scheduled job: %w", err)
+	}
+	return nil
+}
+
+// Get retrieves a scheduled job by ID
+func (r *ScheduledJobRepositoryImpl) Get(ctx context.Context, id string) (*entity.ScheduledJob, error) {
+	var model ScheduledJobModel
+	if err := r.db.WithContext(ctx).First(&model, "id = ?", id).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, errors.ErrNotFound
+		}
+		return nil, fmt.Errorf("failed to get scheduled job: %w", err)
+	}
+	return mapModelToScheduledJob(&model)
+}
+
+// GetAll retrieves all scheduled jobs
+func (r *ScheduledJobRepositoryImpl) GetAll(ctx context.Context) ([]*entity.ScheduledJob, error) {
+	var models []ScheduledJobModel
+	if err := r.db.WithContext(ctx).Order("created_at").Find(&models).Error; err != nil {
+		return nil, fmt.Errorf("failed to get scheduled jobs: %w", err)
+	}
+
+	jobs := make([]*entity.ScheduledJob, len(models))
+	for i := range models {
+		job, err := mapModelToScheduledJob(&models[i])
+		if err != nil {
+			return nil, err
+		}
+		jobs[i] = job
+	}
+	return jobs, nil
+}
+
+// Update updates an existing scheduled job
+func (r *ScheduledJobRepositoryImpl) Update(ctx context.Context, job *entity.ScheduledJob) error {
+	model := mapScheduledJobToModel(job)
+	result := r.db.WithContext(ctx).Model(&ScheduledJobModel{}).Where("id = ?", job.ID).Updates(map[string]interface{}{
+		"name":       model.Name,
+		"schedule":   model.Schedule,
+		"method":     model.Method,
+		"path":       model.Path,
+		"headers":    model.Headers,
+		"body":       model.Body,
+		"timeout":    model.Timeout,
+		"enabled":    model.Enabled,
+		"updated_at": model.UpdatedAt,
+	})
+	if result.Error != nil {
+		return fmt.Errorf("failed to update scheduled job: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return errors.ErrNotFound
+	}
+	return nil
+}
+
+// Delete deletes a scheduled job by ID
+func (r *ScheduledJobRepositoryImpl) Delete(ctx context.Context, id string) error {
+	result := r.db.WithContext(ctx).Delete(&ScheduledJobModel{}, "id = ?", id)
+	if result.Error != nil {
+		return fmt.Errorf("failed to delete scheduled job: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return errors.ErrNotFound
+	}
+	return nil
+}
+
+// Helper functions
+
+func mapScheduledJobToModel(job *entity.ScheduledJob) *ScheduledJobModel {
+	headers := ""
+	if len(job.Headers) > 0 {
+		data, _ := json.Marshal(job.Headers)
+		headers = string(data)
+	}
+	return &ScheduledJobModel{
+		ID:        job.ID,
+		Name:      job.Name,
+		Schedule:  job.Schedule,
+		Method:    job.Method,
+		Path:      job.Path,
+		Headers:   headers,
+		Body:      job.Body,
+		Timeout:   job.Timeout,
+		Enabled:   job.Enabled,
+		CreatedAt: job.CreatedAt,
+		UpdatedAt: job.UpdatedAt,
+	}
+}
+
+func mapModelToScheduledJob(model *ScheduledJobModel) (*entity.ScheduledJob, error) {
+	job := &entity.ScheduledJob{
+		ID:        model.ID,
+		Name:      model.Name,
+		Schedule:  model.Schedule,
+		Method:    model.Method,
+		Path:      model.Path,
+		Body:      model.Body,
+		Timeout:   model.Timeout,
+		Enabled:   model.Enabled,
+		CreatedAt: model.CreatedAt,
+		UpdatedAt: model.UpdatedAt,
+	}
+	if model.Headers != "" {
+		if err := json.Unmarshal([]byte(model.Headers), &job.Headers); err != nil {
+			return nil, fmt.Errorf("failed to decode scheduled job headers: %w", err)
+		}
+	}
+	return job, nil
+}
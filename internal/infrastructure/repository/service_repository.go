@@ -2,11 +2,14 @@ package repository
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
+	"strings"
 	"time"
 
 	"api-gateway-sample/internal/domain/entity"
 	"api-gateway-sample/internal/domain/repository"
+	"api-gateway-sample/pkg/errors"
 	"api-gateway-sample/pkg/logger"
 
 	"gorm.io/gorm"
@@ -14,30 +17,89 @@ import (
 
 // ServiceModel represents the service database model
 type ServiceModel struct {
-	ID          string `gorm:"primaryKey"`
-	Name        string `gorm:"uniqueIndex"`
-	Version     string
-	Description string
-	BaseURL     string
-	Timeout     int
-	RetryCount  int
-	IsActive    bool
-	CreatedAt   time.Time
-	UpdatedAt   time.Time
+	ID           string `gorm:"primaryKey"`
+	Name         string `gorm:"uniqueIndex"`
+	Version      string
+	Description  string
+	BaseURL      string
+	Timeout      int
+	RetryCount   int
+	IsActive     bool
+	Published    bool
+	Signing      string // JSON upstream signing configuration, empty when requests are sent unsigned
+	Errors       string // JSON error templates, empty when the service has none
+	Residency    string // JSON regional upstreams, empty when the service has no data residency
+	Tags         string // JSON tags, empty when the service has none
+	Owner        string // JSON owner, empty when the service is unowned
+	AuthMethods  string // Comma-separated authentication methods, empty to accept any
+	Network      string // Upstream network name, empty for the default network
+	UpstreamPath string // Path prefix of requests to a unix socket upstream
+	ValidFrom    *time.Time
+	ValidUntil   *time.Time
+	Revision     int64 `gorm:"not null;default:1"`
+	CreatedAt    time.Time
+	UpdatedAt    time.Time
 }
 
 // EndpointModel represents the endpoint database model
 type EndpointModel struct {
-	ID           uint `gorm:"primaryKey"`
-	ServiceID    string
-	Path         string
-	Methods      string // Comma-separated list of HTTP methods
-	RateLimit    int
-	AuthRequired bool
-	Timeout      int
-	CacheTTL     int
-	CreatedAt    time.Time
-	UpdatedAt    time.Time
+	ID            uint `gorm:"primaryKey"`
+	ServiceID     string
+	Path          string
+	Methods       string // Comma-separated list of HTTP methods
+	RateLimit     int
+	MaxConcurrent int
+	AuthRequired  bool
+	Timeout       int
+	CacheTTL      int
+	Policy        string
+	// RequiredScopes is the space-separated list of OAuth scopes callers must be granted
+	RequiredScopes string
+	Priority       int
+	ContentTypes   string // Comma-separated list of media types of accepted request bodies
+	CreatedAt      time.Time
+	UpdatedAt      time.Time
+
+	// Composite, Pipeline and Bridge are JSON configurations, empty for proxied endpoints
+	Composite string
+	Pipeline  string
+	Bridge    string
+	Async     bool
+	// SpikeArrest is the JSON spike arrest configuration, empty when bursts are not smoothed
+	SpikeArrest string
+	// NegativeCache is the JSON negative cache configuration, empty when error responses are not cached
+	NegativeCache string
+	// SLO is the JSON service level objective, empty when the endpoint has none
+	SLO string
+	// Mock is the JSON mock fixtures, empty when the endpoint proxies to its service
+	Mock string
+	// SOAP is the JSON SOAP operation, empty when the endpoint proxies requests unchanged
+	SOAP string
+	// Encryption is the JSON payload encryption, empty when payloads are exchanged in plaintext
+	Encryption string
+	// Masking is the JSON response masking, empty when responses are returned unmasked
+	Masking string
+	// Upload is the JSON upload streaming, empty when uploads are buffered
+	Upload string
+	// ResponseLimit is the JSON response size limit, empty when responses are unbounded
+	ResponseLimit string
+	// Experiment is the JSON A/B experiment, empty when the endpoint runs none
+	Experiment string
+	// Tags is the JSON tags, empty when the endpoint only has its service's
+	Tags        string
+	FeatureFlag string
+	ValidFrom   *time.Time
+	ValidUntil  *time.Time
+}
+
+// TableName returns the service table name
+func (ServiceModel) TableName() string {
+	return "services"
+}
+
+// TableName returns the endpoint table name
+func (EndpointModel) TableName() string {
+	return "endpoints"
 }
 
 // ServiceRepositoryImpl implements the repository.ServiceRepository interface
@@ -58,10 +120,16 @@ func NewServiceRepositoryImpl(db *gorm.DB, logger logger.Logger) repository.Serv
 func (r *ServiceRepositoryImpl) Get(ctx context.Context, id string) (*entity.Service, error) {
 	var model ServiceModel
 	if err := r.db.WithContext(ctx).First(&model, "id = ?", id).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, errors.ErrNotFound
+		}
 		return nil, fmt.Errorf("failed to get service: %w", err)
 	}
 
-	service := r.mapModelToEntity(&model)
+	service, err := r.mapModelToEntity(&model)
+	if err != nil {
+		return nil, err
+	}
 	if err := r.loadEndpoints(ctx, service); err != nil {
 		return nil, err
 	}
@@ -83,7 +151,10 @@ func (r *ServiceRepositoryImpl) GetAll(ctx context.Context) ([]*entity.Service,
 
 	services := make([]*entity.Service, len(models))
 	for i, model := range models {
-		service := r.mapModelToEntity(&model)
+		service, err := r.mapModelToEntity(&model)
+		if err != nil {
+			return nil, err
+		}
 		if err := r.loadEndpoints(ctx, service); err != nil {
 			return nil, err
 		}
@@ -93,67 +164,138 @@ func (r *ServiceRepositoryImpl) GetAll(ctx context.Context) ([]*entity.Service,
 	return services, nil
 }
 
-// Create creates a new service
+// Create creates a new service. A service whose ID or name is taken fails with
+// errors.ErrAlreadyExists when the database translates constraint errors.
 func (r *ServiceRepositoryImpl) Create(ctx context.Context, service *entity.Service) error {
-	model := r.mapEntityToModel(service)
-	if err := r.db.WithContext(ctx).Create(&model).Error; err != nil {
-		return fmt.Errorf("failed to create service: %w", err)
+	if service.Revision == 0 {
+		service.Revision = 1
 	}
-
-	for _, endpoint := range service.Endpoints {
-		endpointModel := r.mapEndpointToModel(&endpoint, service.ID)
-		if err := r.db.WithContext(ctx).Create(&endpointModel).Error; err != nil {
-			return fmt.Errorf("failed to create endpoint: %w", err)
+	model := r.mapEntityToModel(service)
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&model).Error; err != nil {
+			if err == gorm.ErrDuplicatedKey {
+				return errors.ErrAlreadyExists
+			}
+			return fmt.Errorf("failed to create service: %w", err)
 		}
-	}
-
-	return nil
+		return r.createEndpoints(tx, service)
+	})
 }
 
 // Update updates an existing service
 func (r *ServiceRepositoryImpl) Update(ctx context.Context, service *entity.Service) error {
 	model := r.mapEntityToModel(service)
-	if err := r.db.WithContext(ctx).Save(&model).Error; err != nil {
-		return fmt.Errorf("failed to update service: %w", err)
-	}
-
-	// Delete existing endpoints
-	if err := r.db.WithContext(ctx).Where("service_id = ?", service.ID).Delete(&EndpointModel{}).Error; err != nil {
-		return fmt.Errorf("failed to delete endpoints: %w", err)
-	}
+	var revision int64
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		// The update compares the revision and locks the row until it is incremented
+		query := tx.Model(&ServiceModel{}).Where("id = ?", service.ID)
+		if service.Revision > 0 {
+			query = query.Where("revision = ?", service.Revision)
+		}
+		result := query.Select("*").Omit("id", "created_at", "revision").Updates(model)
+		if result.Error != nil {
+			if result.Error == gorm.ErrDuplicatedKey {
+				return errors.ErrAlreadyExists
+			}
+			return fmt.Errorf("failed to update service: %w", result.Error)
+		}
+		if result.RowsAffected == 0 {
+			var count int64
+			if err := tx.Model(&ServiceModel{}).Where("id = ?", service.ID).Count(&count).Error; err != nil {
+				return fmt.Errorf("failed to update service: %w", err)
+			}
+			if count == 0 {
+				return errors.ErrNotFound
+			}
+			return errors.ErrPreconditionFailed
+		}
+		if err := tx.Model(&ServiceModel{}).Where("id = ?", service.ID).UpdateColumn("revision", gorm.Expr("revision + 1")).Error; err != nil {
+			return fmt.Errorf("failed to update service revision: %w", err)
+		}
+		if err := tx.Model(&ServiceModel{}).Where("id = ?", service.ID).Select("revision").Scan(&revision).Error; err != nil {
+			return fmt.Errorf("failed to read service revision: %w", err)
+		}
 
-	// Create new endpoints
-	for _, endpoint := range service.Endpoints {
-		endpointModel := r.mapEndpointToModel(&endpoint, service.ID)
-		if err := r.db.WithContext(ctx).Create(&endpointModel).Error; err != nil {
-			return fmt.Errorf("failed to create endpoint: %w", err)
+		// Replace the endpoints
+		if err := tx.Where("service_id = ?", service.ID).Delete(&EndpointModel{}).Error; err != nil {
+			return fmt.Errorf("failed to delete endpoints: %w", err)
 		}
+		return r.createEndpoints(tx, service)
+	})
+	if err != nil {
+		return err
 	}
-
+	service.Revision = revision
 	return nil
 }
 
 // Delete deletes a service by ID
-func (r *ServiceRepositoryImpl) Delete(ctx context.Context, id string) error {
-	if err := r.db.WithContext(ctx).Where("service_id = ?", id).Delete(&EndpointModel{}).Error; err != nil {
-		return fmt.Errorf("failed to delete endpoints: %w", err)
-	}
+func (r *ServiceRepositoryImpl) Delete(ctx context.Context, id string, revision int64) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		// The deletion compares the revision and locks the row until the endpoints are deleted
+		query := tx.Where("id = ?", id)
+		if revision > 0 {
+			query = query.Where("revision = ?", revision)
+		}
+		result := query.Delete(&ServiceModel{})
+		if result.Error != nil {
+			return fmt.Errorf("failed to delete service: %w", result.Error)
+		}
+		if result.RowsAffected == 0 {
+			var count int64
+			if err := tx.Model(&ServiceModel{}).Where("id = ?", id).Count(&count).Error; err != nil {
+				return fmt.Errorf("failed to delete service: %w", err)
+			}
+			if count == 0 {
+				return errors.ErrNotFound
+			}
+			return errors.ErrPreconditionFailed
+		}
 
-	if err := r.db.WithContext(ctx).Delete(&ServiceModel{}, "id = ?", id).Error; err != nil {
-		return fmt.Errorf("failed to delete service: %w", err)
-	}
+		if err := tx.Where("service_id = ?", id).Delete(&EndpointModel{}).Error; err != nil {
+			return fmt.Errorf("failed to delete endpoints: %w", err)
+		}
+		return nil
+	})
+}
 
-	return nil
+// Apply makes the changes of a change set in a single transaction
+func (r *ServiceRepositoryImpl) Apply(ctx context.Context, changes repository.ServiceChangeSet) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		txRepo := &ServiceRepositoryImpl{db: tx, logger: r.logger}
+		for _, id := range changes.Delete {
+			if err := txRepo.Delete(ctx, id, 0); err != nil {
+				return err
+			}
+		}
+		for _, service := range changes.Update {
+			if err := txRepo.Update(ctx, service); err != nil {
+				return err
+			}
+		}
+		for _, service := range changes.Create {
+			if err := txRepo.Create(ctx, service); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
 }
 
 // FindByName finds a service by name
 func (r *ServiceRepositoryImpl) FindByName(ctx context.Context, name string) (*entity.Service, error) {
 	var model ServiceModel
 	if err := r.db.WithContext(ctx).Where("name = ?", name).First(&model).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, errors.ErrNotFound
+		}
 		return nil, fmt.Errorf("failed to find service: %w", err)
 	}
 
-	service := r.mapModelToEntity(&model)
+	service, err := r.mapModelToEntity(&model)
+	if err != nil {
+		return nil, err
+	}
 	if err := r.loadEndpoints(ctx, service); err != nil {
 		return nil, err
 	}
@@ -163,21 +305,28 @@ func (r *ServiceRepositoryImpl) FindByName(ctx context.Context, name string) (*e
 
 // GetByEndpoint finds services by endpoint path and method
 func (r *ServiceRepositoryImpl) GetByEndpoint(ctx context.Context, path string, method string) ([]*entity.Service, error) {
+	// Services with an endpoint at the path are loaded and their methods matched here
 	var models []ServiceModel
-	if err := r.db.WithContext(ctx).
-		Joins("JOIN endpoints ON endpoints.service_id = services.id").
-		Where("endpoints.path = ? AND endpoints.methods LIKE ?", path, "%"+method+"%").
-		Find(&models).Error; err != nil {
+	endpoints := r.db.WithContext(ctx).Model(&EndpointModel{}).Select("service_id").Where("path = ?", path)
+	if err := r.db.WithContext(ctx).Where("id IN (?)", endpoints).Find(&models).Error; err != nil {
 		return nil, fmt.Errorf("failed to get services: %w", err)
 	}
 
-	services := make([]*entity.Service, len(models))
-	for i, model := range models {
-		service := r.mapModelToEntity(&model)
+	var services []*entity.Service
+	for _, model := range models {
+		service, err := r.mapModelToEntity(&model)
+		if err != nil {
+			return nil, err
+		}
 		if err := r.loadEndpoints(ctx, service); err != nil {
 			return nil, err
 		}
-		services[i] = service
+		if hasEndpoint(service, path, method) {
+			services = append(services, service)
+		}
+	}
+	if len(services) == 0 {
+		return nil, errors.ErrNotFound
 	}
 
 	return services, nil
@@ -185,43 +334,126 @@ func (r *ServiceRepositoryImpl) GetByEndpoint(ctx context.Context, path string,
 
 // Helper functions
 
-func (r *ServiceRepositoryImpl) mapModelToEntity(model *ServiceModel) *entity.Service {
-	return &entity.Service{
-		ID:          model.ID,
-		Name:        model.Name,
-		Version:     model.Version,
-		Description: model.Description,
-		BaseURL:     model.BaseURL,
-		Timeout:     model.Timeout,
-		RetryCount:  model.RetryCount,
-		IsActive:    model.IsActive,
-		Endpoints:   make([]entity.Endpoint, 0),
-		Metadata:    make(map[string]string),
+func (r *ServiceRepositoryImpl) mapModelToEntity(model *ServiceModel) (*entity.Service, error) {
+	service := &entity.Service{
+		ID:           model.ID,
+		Name:         model.Name,
+		Version:      model.Version,
+		Description:  model.Description,
+		BaseURL:      model.BaseURL,
+		Timeout:      model.Timeout,
+		RetryCount:   model.RetryCount,
+		IsActive:     model.IsActive,
+		Published:    model.Published,
+		Network:      model.Network,
+		UpstreamPath: model.UpstreamPath,
+		Revision:     model.Revision,
+		ValidFrom:    model.ValidFrom,
+		ValidUntil:   model.ValidUntil,
+		Endpoints:    make([]entity.Endpoint, 0),
+		Metadata:     make(map[string]string),
+	}
+	if model.Signing != "" {
+		service.Signing = &entity.UpstreamSigning{}
+		if err := json.Unmarshal([]byte(model.Signing), service.Signing); err != nil {
+			return nil, fmt.Errorf("failed to decode upstream signing: %w", err)
+		}
+	}
+	if model.Errors != "" {
+		if err := json.Unmarshal([]byte(model.Errors), &service.ErrorTemplates); err != nil {
+			return nil, fmt.Errorf("failed to decode error templates: %w", err)
+		}
+	}
+	if model.Residency != "" {
+		service.Residency = &entity.Residency{}
+		if err := json.Unmarshal([]byte(model.Residency), service.Residency); err != nil {
+			return nil, fmt.Errorf("failed to decode residency: %w", err)
+		}
+	}
+	if model.Tags != "" {
+		if err := json.Unmarshal([]byte(model.Tags), &service.Tags); err != nil {
+			return nil, fmt.Errorf("failed to decode tags: %w", err)
+		}
 	}
+	if model.Owner != "" {
+		service.Owner = &entity.Owner{}
+		if err := json.Unmarshal([]byte(model.Owner), service.Owner); err != nil {
+			return nil, fmt.Errorf("failed to decode owner: %w", err)
+		}
+	}
+	if model.AuthMethods != "" {
+		service.AuthMethods = strings.Split(model.AuthMethods, ",")
+	}
+	return service, nil
 }
 
 func (r *ServiceRepositoryImpl) mapEntityToModel(service *entity.Service) *ServiceModel {
 	return &ServiceModel{
-		ID:          service.ID,
-		Name:        service.Name,
-		Version:     service.Version,
-		Description: service.Description,
-		BaseURL:     service.BaseURL,
-		Timeout:     service.Timeout,
-		RetryCount:  service.RetryCount,
-		IsActive:    service.IsActive,
+		ID:           service.ID,
+		Name:         service.Name,
+		Version:      service.Version,
+		Description:  service.Description,
+		BaseURL:      service.BaseURL,
+		Timeout:      service.Timeout,
+		RetryCount:   service.RetryCount,
+		IsActive:     service.IsActive,
+		Published:    service.Published,
+		Signing:      encodeSigning(service.Signing),
+		Errors:       encodeErrorTemplates(service.ErrorTemplates),
+		Residency:    encodeResidency(service.Residency),
+		Tags:         encodeTags(service.Tags),
+		Owner:        encodeOwner(service.Owner),
+		AuthMethods:  strings.Join(service.AuthMethods, ","),
+		Network:      service.Network,
+		UpstreamPath: service.UpstreamPath,
+		ValidFrom:    service.ValidFrom,
+		ValidUntil:   service.ValidUntil,
+		Revision:     service.Revision,
 	}
 }
 
 func (r *ServiceRepositoryImpl) mapEndpointToModel(endpoint *entity.Endpoint, serviceID string) *EndpointModel {
 	return &EndpointModel{
-		ServiceID:    serviceID,
-		Path:         endpoint.Path,
-		Methods:      fmt.Sprintf("%v", endpoint.Methods), // Convert slice to string
-		RateLimit:    endpoint.RateLimit,
-		AuthRequired: endpoint.AuthRequired,
-		Timeout:      endpoint.Timeout,
+		ServiceID:      serviceID,
+		Path:           endpoint.Path,
+		Methods:        strings.Join(endpoint.Methods, ","),
+		RateLimit:      endpoint.RateLimit,
+		MaxConcurrent:  endpoint.MaxConcurrent,
+		AuthRequired:   endpoint.AuthRequired,
+		Timeout:        endpoint.Timeout,
+		Policy:         endpoint.Policy,
+		RequiredScopes: strings.Join(endpoint.RequiredScopes, " "),
+		Priority:       endpoint.Priority,
+		ContentTypes:   strings.Join(endpoint.ContentTypes, ","),
+		Composite:      encodeComposite(endpoint.Composite),
+		Pipeline:       encodePipeline(endpoint.Pipeline),
+		Bridge:         encodeBridge(endpoint.Bridge),
+		Async:          endpoint.Async,
+		SpikeArrest:    encodeSpikeArrest(endpoint.SpikeArrest),
+		NegativeCache:  encodeNegativeCache(endpoint.NegativeCache),
+		SLO:            encodeSLO(endpoint.SLO),
+		Mock:           encodeMock(endpoint.Mock),
+		SOAP:           encodeSOAP(endpoint.SOAP),
+		Encryption:     encodeEncryption(endpoint.Encryption),
+		Masking:        encodeMasking(endpoint.Masking),
+		Upload:         encodeUpload(endpoint.Upload),
+		ResponseLimit:  encodeResponseLimit(endpoint.ResponseLimit),
+		Experiment:     encodeExperiment(endpoint.Experiment),
+		Tags:           encodeTags(endpoint.Tags),
+		FeatureFlag:    endpoint.FeatureFlag,
+		ValidFrom:      endpoint.ValidFrom,
+		ValidUntil:     endpoint.ValidUntil,
+	}
+}
+
+func (r *ServiceRepositoryImpl) createEndpoints(tx *gorm.DB, service *entity.Service) error {
+	for _, endpoint := range service.Endpoints {
+		endpointModel := r.mapEndpointToModel(&endpoint, service.ID)
+		if err := tx.Create(&endpointModel).Error; err != nil {
+			return fmt.Errorf("failed to create endpoint: %w", err)
+		}
 	}
+	return nil
 }
 
 func (r *ServiceRepositoryImpl) loadEndpoints(ctx context.Context, service *entity.Service) error {
@@ -232,14 +464,276 @@ func (r *ServiceRepositoryImpl) loadEndpoints(ctx context.Context, service *enti
 
 	for _, model := range models {
 		endpoint := entity.Endpoint{
-			Path:         model.Path,
-			Methods:      []string{}, // Parse methods string to slice
-			RateLimit:    model.RateLimit,
-			AuthRequired: model.AuthRequired,
-			Timeout:      model.Timeout,
+			Path:          model.Path,
+			Methods:       []string{},
+			RateLimit:     model.RateLimit,
+			MaxConcurrent: model.MaxConcurrent,
+			AuthRequired:  model.AuthRequired,
+			Timeout:       model.Timeout,
+			Policy:        model.Policy,
+			Priority:      model.Priority,
+			Async:         model.Async,
+			FeatureFlag:   model.FeatureFlag,
+			ValidFrom:     model.ValidFrom,
+			ValidUntil:    model.ValidUntil,
+		}
+		if model.Methods != "" {
+			endpoint.Methods = strings.Split(model.Methods, ",")
+		}
+		if model.RequiredScopes != "" {
+			endpoint.RequiredScopes = strings.Fields(model.RequiredScopes)
+		}
+		if model.ContentTypes != "" {
+			endpoint.ContentTypes = strings.Split(model.ContentTypes, ",")
+		}
+		if model.Composite != "" {
+			endpoint.Composite = &entity.Composite{}
+			if err := json.Unmarshal([]byte(model.Composite), endpoint.Composite); err != nil {
+				return fmt.Errorf("failed to decode composite endpoint: %w", err)
+			}
+		}
+		if model.Pipeline != "" {
+			endpoint.Pipeline = &entity.Pipeline{}
+			if err := json.Unmarshal([]byte(model.Pipeline), endpoint.Pipeline); err != nil {
+				return fmt.Errorf("failed to decode pipeline endpoint: %w", err)
+			}
+		}
+		if model.SpikeArrest != "" {
+			endpoint.SpikeArrest = &entity.SpikeArrest{}
+			if err := json.Unmarshal([]byte(model.SpikeArrest), endpoint.SpikeArrest); err != nil {
+				return fmt.Errorf("failed to decode spike arrest: %w", err)
+			}
+		}
+		if model.NegativeCache != "" {
+			endpoint.NegativeCache = &entity.NegativeCache{}
+			if err := json.Unmarshal([]byte(model.NegativeCache), endpoint.NegativeCache); err != nil {
+				return fmt.Errorf("failed to decode negative cache: %w", err)
+			}
+		}
+		if model.SLO != "" {
+			endpoint.SLO = &entity.SLO{}
+			if err := json.Unmarshal([]byte(model.SLO), endpoint.SLO); err != nil {
+				return fmt.Errorf("failed to decode slo: %w", err)
+			}
+		}
+		if model.Mock != "" {
+			endpoint.Mock = &entity.Mock{}
+			if err := json.Unmarshal([]byte(model.Mock), endpoint.Mock); err != nil {
+				return fmt.Errorf("failed to decode mock: %w", err)
+			}
+		}
+		if model.SOAP != "" {
+			endpoint.SOAP = &entity.SOAP{}
+			if err := json.Unmarshal([]byte(model.SOAP), endpoint.SOAP); err != nil {
+				return fmt.Errorf("failed to decode soap endpoint: %w", err)
+			}
+		}
+		if model.Encryption != "" {
+			endpoint.Encryption = &entity.PayloadEncryption{}
+			if err := json.Unmarshal([]byte(model.Encryption), endpoint.Encryption); err != nil {
+				return fmt.Errorf("failed to decode payload encryption: %w", err)
+			}
+		}
+		if model.Masking != "" {
+			endpoint.Masking = &entity.ResponseMasking{}
+			if err := json.Unmarshal([]byte(model.Masking), endpoint.Masking); err != nil {
+				return fmt.Errorf("failed to decode response masking: %w", err)
+			}
+		}
+		if model.Upload != "" {
+			endpoint.Upload = &entity.Upload{}
+			if err := json.Unmarshal([]byte(model.Upload), endpoint.Upload); err != nil {
+				return fmt.Errorf("failed to decode upload: %w", err)
+			}
+		}
+		if model.ResponseLimit != "" {
+			endpoint.ResponseLimit = &entity.ResponseLimit{}
+			if err := json.Unmarshal([]byte(model.ResponseLimit), endpoint.ResponseLimit); err != nil {
+				return fmt.Errorf("failed to decode response limit: %w", err)
+			}
+		}
+		if model.Experiment != "" {
+			endpoint.Experiment = &entity.Experiment{}
+			if err := json.Unmarshal([]byte(model.Experiment), endpoint.Experiment); err != nil {
+				return fmt.Errorf("failed to decode experiment: %w", err)
+			}
+		}
+
+		if model.Tags != "" {
+			if err := json.Unmarshal([]byte(model.Tags), &endpoint.Tags); err != nil {
+				return fmt.Errorf("failed to decode endpoint tags: %w", err)
+			}
+		}
+		if model.Bridge != "" {
+			endpoint.Bridge = &entity.Bridge{}
+			if err := json.Unmarshal([]byte(model.Bridge), endpoint.Bridge); err != nil {
+				return fmt.Errorf("failed to decode bridge endpoint: %w", err)
+			}
 		}
 		service.AddEndpoint(endpoint)
 	}
 
 	return nil
 }
+
+// encodeComposite returns the JSON composite configuration of an endpoint, empty when it has none
+func encodeComposite(composite *entity.Composite) string {
+	if composite == nil {
+		return ""
+	}
+	data, _ := json.Marshal(composite)
+	return string(data)
+}
+
+// encodePipeline returns the JSON pipeline configuration of an endpoint, empty when it has none
+func encodePipeline(pipeline *entity.Pipeline) string {
+	if pipeline == nil {
+		return ""
+	}
+	data, _ := json.Marshal(pipeline)
+	return string(data)
+}
+
+// encodeBridge returns the JSON bridge configuration of an endpoint, empty when it has none
+func encodeBridge(bridge *entity.Bridge) string {
+	if bridge == nil {
+		return ""
+	}
+	data, _ := json.Marshal(bridge)
+	return string(data)
+}
+
+// encodeSpikeArrest returns the JSON spike arrest configuration of an endpoint, empty when it has none
+func encodeSpikeArrest(spikeArrest *entity.SpikeArrest) string {
+	if spikeArrest == nil {
+		return ""
+	}
+	data, _ := json.Marshal(spikeArrest)
+	return string(data)
+}
+
+// encodeNegativeCache returns the JSON negative cache configuration of an endpoint, empty when it has none
+func encodeNegativeCache(negativeCache *entity.NegativeCache) string {
+	if negativeCache == nil {
+		return ""
+	}
+	data, _ := json.Marshal(negativeCache)
+	return string(data)
+}
+
+// encodeSigning returns the JSON upstream signing configuration of a service, empty when it has none
+func encodeSigning(signing *entity.UpstreamSigning) string {
+	if signing == nil {
+		return ""
+	}
+	data, _ := json.Marshal(signing)
+	return string(data)
+}
+
+// encodeErrorTemplates returns the JSON error templates of a service, empty when it has none
+func encodeErrorTemplates(templates []entity.ErrorTemplate) string {
+	if len(templates) == 0 {
+		return ""
+	}
+	data, _ := json.Marshal(templates)
+	return string(data)
+}
+
+// encodeResidency returns the JSON regional upstreams of a service, empty when it has none
+func encodeResidency(residency *entity.Residency) string {
+	if residency == nil {
+		return ""
+	}
+	data, _ := json.Marshal(residency)
+	return string(data)
+}
+
+// encodeTags returns the JSON tags of a service or endpoint, empty when it has none
+func encodeTags(tags map[string]string) string {
+	if len(tags) == 0 {
+		return ""
+	}
+	data, _ := json.Marshal(tags)
+	return string(data)
+}
+
+// encodeOwner returns the JSON owner of a service, empty when it is unowned
+func encodeOwner(owner *entity.Owner) string {
+	if owner == nil {
+		return ""
+	}
+	data, _ := json.Marshal(owner)
+	return string(data)
+}
+
+// encodeSOAP returns the JSON SOAP operation of an endpoint, empty when it has none
+func encodeSOAP(soap *entity.SOAP) string {
+	if soap == nil {
+		return ""
+	}
+	data, _ := json.Marshal(soap)
+	return string(data)
+}
+
+// encodeEncryption returns the JSON payload encryption of an endpoint, empty when it has none
+func encodeEncryption(encryption *entity.PayloadEncryption) string {
+	if encryption == nil {
+		return ""
+	}
+	data, _ := json.Marshal(encryption)
+	return string(data)
+}
+
+// encodeUpload returns the JSON upload streaming of an endpoint, empty when it has none
+func encodeUpload(upload *entity.Upload) string {
+	if upload == nil {
+		return ""
+	}
+	data, _ := json.Marshal(upload)
+	return string(data)
+}
+
+// encodeResponseLimit returns the JSON response size limit of an endpoint, empty when it has none
+func encodeResponseLimit(limit *entity.ResponseLimit) string {
+	if limit == nil {
+		return ""
+	}
+	data, _ := json.Marshal(limit)
+	return string(data)
+}
+
+// encodeExperiment returns the JSON A/B experiment of an endpoint, empty when it runs none
+func encodeExperiment(experiment *entity.Experiment) string {
+	if experiment == nil {
+		return ""
+	}
+	data, _ := json.Marshal(experiment)
+	return string(data)
+}
+
+// encodeMasking returns the JSON response masking of an endpoint, empty when it has none
+func encodeMasking(masking *entity.ResponseMasking) string {
+	if masking == nil {
+		return ""
+	}
+	data, _ := json.Marshal(masking)
+	return string(data)
+}
+
+// encodeMock returns the JSON mock fixtures of an endpoint, empty when it has none
+func encodeMock(mock *entity.Mock) string {
+	if mock == nil {
+		return ""
+	}
+	data, _ := json.Marshal(mock)
+	return string(data)
+}
+
+// encodeSLO returns the JSON service level objective of an endpoint, empty when it has none
+func encodeSLO(slo *entity.SLO) string {
+	if slo == nil {
+		return ""
+	}
+	data, _ := json.Marshal(slo)
+	return string(data)
+}
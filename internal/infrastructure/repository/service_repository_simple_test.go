@@ -106,7 +106,7 @@ func TestServiceRepository(t *testing.T) {
 
 	// Test Delete
 	t.Run("Delete", func(t *testing.T) {
-		err := repo.Delete(context.Background(), "test-id")
+		err := repo.Delete(context.Background(), "test-id", 0)
 		if err != nil {
 			t.Errorf("Delete() error = %v", err)
 		}
@@ -0,0 +1,39 @@
+package repository
+
+import (
+	"path/filepath"
+	"testing"
+
+	"api-gateway-sample/internal/domain/repository"
+	"api-gateway-sample/internal/domain/repository/repositorytest"
+
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// openTestDatabase opens a SQLite database with the schema of the given models, skipping the
+// test when the SQLite driver is unavailable, as in builds without cgo
+func openTestDatabase(t *testing.T, models ...interface{}) *gorm.DB {
+	db, err := gorm.Open(sqlite.Open(filepath.Join(t.TempDir(), "gateway.db")), &gorm.Config{
+		Logger:         logger.Discard,
+		TranslateError: true,
+	})
+	if err != nil {
+		t.Skipf("SQLite is unavailable: %v", err)
+	}
+	require.NoError(t, db.AutoMigrate(models...))
+	t.Cleanup(func() {
+		if sqlDB, err := db.DB(); err == nil {
+			sqlDB.Close()
+		}
+	})
+	return db
+}
+
+func TestServiceRepositoryImpl_Contract(t *testing.T) {
+	repositorytest.TestServiceRepository(t, func(t *testing.T) repository.ServiceRepository {
+		return NewServiceRepositoryImpl(openTestDatabase(t, &ServiceModel{}, &EndpointModel{}), nopLogger{})
+	})
+}
@@ -0,0 +1,132 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"api-gateway-sample/internal/domain/entity"
+	"api-gateway-sample/internal/domain/repository"
+	"api-gateway-sample/pkg/errors"
+	"api-gateway-sample/pkg/logger"
+
+	"gorm.io/gorm"
+)
+
+// ServiceRevisionModel represents the service revision database model
+type ServiceRevisionModel struct {
+	ServiceID    string `gorm:"primaryKey"`
+	Revision     int64  `gorm:"primaryKey;autoIncrement:false"`
+	Actor        string
+	RestoredFrom int64
+	Changes      string // JSON field changes, empty for the first revision
+	Service      string // JSON service configuration
+	CreatedAt    time.Time
+}
+
+// TableName returns the service revision table name
+func (ServiceRevisionModel) TableName() string {
+	return "service_revisions"
+}
+
+// ServiceRevisionRepositoryImpl implements the repository.ServiceRevisionRepository interface
+type ServiceRevisionRepositoryImpl struct {
+	db     *gorm.DB
+	logger logger.Logger
+}
+
+// NewServiceRevisionRepositoryImpl creates a new ServiceRevisionRepositoryImpl instance
+func NewServiceRevisionRepositoryImpl(db *gorm.DB, logger logger.Logger) repository.ServiceRevisionRepository {
+	return &ServiceRevisionRepositoryImpl{
+		db:     db,
+		logger: logger,
+	}
+}
+
+// Create stores a revision
+func (r *ServiceRevisionRepositoryImpl) Create(ctx context.Context, revision *entity.ServiceRevision) error {
+	model, err := mapServiceRevisionToModel(revision)
+	if err != nil {
+		return err
+	}
+	if err := r.db.WithContext(ctx).Create(model).Error; err != nil {
+		return fmt.Errorf("failed to create service revision: %w", err)
+	}
+	return nil
+}
+
+// Get retrieves a revision of a service
+func (r *ServiceRevisionRepositoryImpl) Get(ctx context.Context, serviceID string, revision int64) (*entity.ServiceRevision, error) {
+	var model ServiceRevisionModel
+	if err := r.db.WithContext(ctx).First(&model, "service_id = ? AND revision = ?", serviceID, revision).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, errors.ErrNotFound
+		}
+		return nil, fmt.Errorf("failed to get service revision: %w", err)
+	}
+	return mapModelToServiceRevision(&model)
+}
+
+// List retrieves the revisions of a service, newest first
+func (r *ServiceRevisionRepositoryImpl) List(ctx context.Context, serviceID string) ([]*entity.ServiceRevision, error) {
+	var models []ServiceRevisionModel
+	if err := r.db.WithContext(ctx).Where("service_id = ?", serviceID).Order("revision DESC").Find(&models).Error; err != nil {
+		return nil, fmt.Errorf("failed to get service revisions: %w", err)
+	}
+
+	revisions := make([]*entity.ServiceRevision, len(models))
+	for i := range models {
+		revision, err := mapModelToServiceRevision(&models[i])
+		if err != nil {
+			return nil, err
+		}
+		revisions[i] = revision
+	}
+	return revisions, nil
+}
+
+// Helper functions
+
+func mapServiceRevisionToModel(revision *entity.ServiceRevision) (*ServiceRevisionModel, error) {
+	service, err := json.Marshal(revision.Service)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode service revision: %w", err)
+	}
+	changes := ""
+	if len(revision.Changes) > 0 {
+		data, err := json.Marshal(revision.Changes)
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode service revision changes: %w", err)
+		}
+		changes = string(data)
+	}
+	return &ServiceRevisionModel{
+		ServiceID:    revision.ServiceID,
+		Revision:     revision.Revision,
+		Actor:        revision.Actor,
+		RestoredFrom: revision.RestoredFrom,
+		Changes:      changes,
+		Service:      string(service),
+		CreatedAt:    revision.CreatedAt,
+	}, nil
+}
+
+func mapModelToServiceRevision(model *ServiceRevisionModel) (*entity.ServiceRevision, error) {
+	revision := &entity.ServiceRevision{
+		ServiceID:    model.ServiceID,
+		Revision:     model.Revision,
+		Actor:        model.Actor,
+		RestoredFrom: model.RestoredFrom,
+		CreatedAt:    model.CreatedAt,
+	}
+	if err := json.Unmarshal([]byte(model.Service), &revision.Service); err != nil {
+		return nil, fmt.Errorf("failed to decode service revision: %w", err)
+	}
+	if model.Changes != "" {
+		if err := json.Unmarshal([]byte(model.Changes), &revision.Changes); err != nil {
+			return nil, fmt.Errorf("failed to decode service revision changes: %w", err)
+		}
+	}
+	return revision, nil
+}
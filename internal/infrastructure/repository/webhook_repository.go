@@ -0,0 +1,123 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"api-gateway-sample/internal/domain/entity"
+	"api-gateway-sample/internal/domain/repository"
+	"api-gateway-sample/pkg/errors"
+	"api-gateway-sample/pkg/logger"
+
+	"gorm.io/gorm"
+)
+
+// WebhookModel represents the webhook subscription database model
+type WebhookModel struct {
+	ID          string `gorm:"primaryKey"`
+	URL         string
+	Secret      string
+	Events      string // Comma-separated list of event types
+	Description string
+	Active      bool
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+}
+
+// TableName returns the webhook subscription table name
+func (WebhookModel) TableName() string {
+	return "webhook_subscriptions"
+}
+
+// WebhookRepositoryImpl implements the repository.WebhookRepository interface
+type WebhookRepositoryImpl struct {
+	db     *gorm.DB
+	logger logger.Logger
+}
+
+// NewWebhookRepositoryImpl creates a new WebhookRepositoryImpl instance
+func NewWebhookRepositoryImpl(db *gorm.DB, logger logger.Logger) repository.WebhookRepository {
+	return &WebhookRepositoryImpl{
+		db:     db,
+		logger: logger,
+	}
+}
+
+// Create creates a new webhook subscription
+func (r *WebhookRepositoryImpl) Create(ctx context.Context, webhook *entity.WebhookSubscription) error {
+	model := mapWebhookToModel(webhook)
+	if err := r.db.WithContext(ctx).Create(model).Error; err != nil {
+		return fmt.Errorf("failed to create webhook: %w", err)
+	}
+	return nil
+}
+
+// Get retrieves a webhook subscription by ID
+func (r *WebhookRepositoryImpl) Get(ctx context.Context, id string) (*entity.WebhookSubscription, error) {
+	var model WebhookModel
+	if err := r.db.WithContext(ctx).First(&model, "id = ?", id).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, errors.ErrNotFound
+		}
+		return nil, fmt.Errorf("failed to get webhook: %w", err)
+	}
+	return mapModelToWebhook(&model), nil
+}
+
+// GetAll retrieves all webhook subscriptions
+func (r *WebhookRepositoryImpl) GetAll(ctx context.Context) ([]*entity.WebhookSubscription, error) {
+	var models []WebhookModel
+	if err := r.db.WithContext(ctx).Order("created_at").Find(&models).Error; err != nil {
+		return nil, fmt.Errorf("failed to get webhooks: %w", err)
+	}
+
+	webhooks := make([]*entity.WebhookSubscription, len(models))
+	for i := range models {
+		webhooks[i] = mapModelToWebhook(&models[i])
+	}
+	return webhooks, nil
+}
+
+// Delete deletes a webhook subscription by ID
+func (r *WebhookRepositoryImpl) Delete(ctx context.Context, id string) error {
+	result := r.db.WithContext(ctx).Delete(&WebhookModel{}, "id = ?", id)
+	if result.Error != nil {
+		return fmt.Errorf("failed to delete webhook: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return errors.ErrNotFound
+	}
+	return nil
+}
+
+// Helper functions
+
+func mapWebhookToModel(webhook *entity.WebhookSubscription) *WebhookModel {
+	return &WebhookModel{
+		ID:          webhook.ID,
+		URL:         webhook.URL,
+		Secret:      webhook.Secret,
+		Events:      strings.Join(webhook.Events, ","),
+		Description: webhook.Description,
+		Active:      webhook.Active,
+		CreatedAt:   webhook.CreatedAt,
+	}
+}
+
+func mapModelToWebhook(model *WebhookModel) *entity.WebhookSubscription {
+	events := make([]string, 0)
+	if model.Events != "" {
+		events = strings.Split(model.Events, ",")
+	}
+	return &entity.WebhookSubscription{
+		ID:          model.ID,
+		URL:         model.URL,
+		Secret:      model.Secret,
+		Events:      events,
+		Description: model.Description,
+		Active:      model.Active,
+		CreatedAt:   model.CreatedAt,
+	}
+}
@@ -0,0 +1,87 @@
+package scan
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"api-gateway-sample/internal/domain/entity"
+	"api-gateway-sample/pkg/logger"
+)
+
+// maxThreatSize bounds the description of a threat read from a rejection body
+const maxThreatSize = 1024
+
+// HTTPScanner implements the UploadScanner interface by posting each file to an HTTP scanning
+// service, such as a ClamAV REST frontend: a 2xx response means the file is clean, 403, 406 or
+// 422 that it is infected, with the threat in the X-Scan-Threat header or the body, and any
+// other response that the file could not be scanned.
+type HTTPScanner struct {
+	url      string
+	failOpen bool
+	client   *http.Client
+	logger   logger.Logger
+}
+
+// NewHTTPScanner creates a new HTTPScanner instance
+func NewHTTPScanner(url string, failOpen bool, timeout time.Duration, logger logger.Logger) *HTTPScanner {
+	return &HTTPScanner{
+		url:      url,
+		failOpen: failOpen,
+		client:   &http.Client{Timeout: timeout},
+		logger:   logger,
+	}
+}
+
+// Scan submits a file to the scanning service
+func (s *HTTPScanner) Scan(ctx context.Context, file entity.UploadedFile, content io.Reader) (*entity.ScanVerdict, error) {
+	verdict, err := s.call(ctx, file, content)
+	if err != nil {
+		if s.failOpen {
+			logger.FromContextOr(ctx, s.logger).Warn("Upload scanner unavailable, failing open", "error", err, "filename", file.Filename)
+			return &entity.ScanVerdict{Clean: true}, nil
+		}
+		return nil, err
+	}
+	return verdict, nil
+}
+
+func (s *HTTPScanner) call(ctx context.Context, file entity.UploadedFile, content io.Reader) (*entity.ScanVerdict, error) {
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, s.url, content)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create scan request: %w", err)
+	}
+	httpReq.ContentLength = file.Size
+	httpReq.Header.Set("Content-Type", "application/octet-stream")
+	httpReq.Header.Set("X-Upload-Filename", file.Filename)
+	httpReq.Header.Set("X-Upload-Field", file.Field)
+	if file.ContentType != "" {
+		httpReq.Header.Set("X-Upload-Content-Type", file.ContentType)
+	}
+
+	resp, err := s.client.Do(httpReq)
+	if err != nil {
+		return nil, fmt.Errorf("upload scanner call failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	switch {
+	case resp.StatusCode >= 200 && resp.StatusCode < 300:
+		return &entity.ScanVerdict{Clean: true}, nil
+	case resp.StatusCode == http.StatusForbidden || resp.StatusCode == http.StatusNotAcceptable || resp.StatusCode == http.StatusUnprocessableEntity:
+		threat := resp.Header.Get("X-Scan-Threat")
+		if threat == "" {
+			body, _ := io.ReadAll(io.LimitReader(resp.Body, maxThreatSize))
+			threat = strings.TrimSpace(string(body))
+		}
+		if threat == "" {
+			threat = "infected"
+		}
+		return &entity.ScanVerdict{Clean: false, Threat: threat}, nil
+	default:
+		return nil, fmt.Errorf("upload scanner returned status %d", resp.StatusCode)
+	}
+}
@@ -0,0 +1,71 @@
+package scan
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"api-gateway-sample/internal/domain/entity"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// nopLogger discards all log output
+type nopLogger struct{}
+
+func (nopLogger) Debug(msg string, keysAndValues ...interface{}) {}
+func (nopLogger) Info(msg string, keysAndValues ...interface{})  {}
+func (nopLogger) Warn(msg string, keysAndValues ...interface{})  {}
+func (nopLogger) Error(msg string, keysAndValues ...interface{}) {}
+func (nopLogger) Fatal(msg string, keysAndValues ...interface{}) {}
+
+func TestHTTPScanner_Scan(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "report.pdf", r.Header.Get("X-Upload-Filename"))
+		content, _ := io.ReadAll(r.Body)
+		switch string(content) {
+		case "clean":
+			w.WriteHeader(http.StatusOK)
+		case "header":
+			w.Header().Set("X-Scan-Threat", "Eicar-Test-Signature")
+			w.WriteHeader(http.StatusNotAcceptable)
+		case "body":
+			w.WriteHeader(http.StatusForbidden)
+			w.Write([]byte("Win.Test.EICAR_HDB-1\n"))
+		default:
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+	}))
+	defer server.Close()
+
+	scan := func(scanner *HTTPScanner, content string) (*entity.ScanVerdict, error) {
+		file := entity.UploadedFile{Field: "file", Filename: "report.pdf", Size: int64(len(content))}
+		return scanner.Scan(context.Background(), file, strings.NewReader(content))
+	}
+	scanner := NewHTTPScanner(server.URL, false, time.Second, nopLogger{})
+
+	// 1. 2xx means clean
+	verdict, err := scan(scanner, "clean")
+	require.NoError(t, err)
+	assert.True(t, verdict.Clean)
+
+	// 2. Rejections name the threat from the header, else the body
+	verdict, err = scan(scanner, "header")
+	require.NoError(t, err)
+	assert.Equal(t, entity.ScanVerdict{Threat: "Eicar-Test-Signature"}, *verdict)
+	verdict, err = scan(scanner, "body")
+	require.NoError(t, err)
+	assert.Equal(t, entity.ScanVerdict{Threat: "Win.Test.EICAR_HDB-1"}, *verdict)
+
+	// 3. Other responses are failures, unless failing open
+	_, err = scan(scanner, "broken")
+	assert.Error(t, err)
+	verdict, err = scan(NewHTTPScanner(server.URL, true, time.Second, nopLogger{}), "broken")
+	require.NoError(t, err)
+	assert.True(t, verdict.Clean)
+}
@@ -0,0 +1,30 @@
+package stream
+
+import (
+	"net"
+	"time"
+
+	"api-gateway-sample/pkg/config"
+	"api-gateway-sample/pkg/logger"
+)
+
+// dialTimeout bounds connecting to an upstream
+const dialTimeout = 10 * time.Second
+
+// Proxy forwards the raw traffic of a listener to its upstream
+type Proxy interface {
+	// Start listens on the configured address and serves connections in the background
+	Start() error
+	// Addr returns the address the proxy listens on, once started
+	Addr() net.Addr
+	// Close stops listening and closes every open connection
+	Close() error
+}
+
+// NewProxy creates a TCP or UDP proxy for a listener
+func NewProxy(listener config.StreamListener, logger logger.Logger) Proxy {
+	if listener.Protocol == "udp" {
+		return NewUDPProxy(listener, logger)
+	}
+	return NewTCPProxy(listener, logger)
+}
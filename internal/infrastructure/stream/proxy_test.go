@@ -0,0 +1,144 @@
+package stream
+
+import (
+	"io"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"api-gateway-sample/pkg/config"
+)
+
+// nopLogger discards all log output
+type nopLogger struct{}
+
+func (nopLogger) Debug(msg string, keysAndValues ...interface{}) {}
+func (nopLogger) Info(msg string, keysAndValues ...interface{})  {}
+func (nopLogger) Warn(msg string, keysAndValues ...interface{})  {}
+func (nopLogger) Error(msg string, keysAndValues ...interface{}) {}
+func (nopLogger) Fatal(msg string, keysAndValues ...interface{}) {}
+
+// startTCPEcho starts a TCP upstream that echoes everything it receives
+func startTCPEcho(t *testing.T) string {
+	t.Helper()
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { listener.Close() })
+
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				defer conn.Close()
+				io.Copy(conn, conn)
+			}()
+		}
+	}()
+	return listener.Addr().String()
+}
+
+// startUDPEcho starts a UDP upstream that echoes every datagram to its sender
+func startUDPEcho(t *testing.T) string {
+	t.Helper()
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { conn.Close() })
+
+	go func() {
+		buf := make([]byte, maxDatagramSize)
+		for {
+			n, addr, err := conn.ReadFrom(buf)
+			if err != nil {
+				return
+			}
+			conn.WriteTo(buf[:n], addr)
+		}
+	}()
+	return conn.LocalAddr().String()
+}
+
+func startProxy(t *testing.T, listener config.StreamListener) Proxy {
+	t.Helper()
+	proxy := NewProxy(listener, nopLogger{})
+	require.NoError(t, proxy.Start())
+	t.Cleanup(func() { proxy.Close() })
+	return proxy
+}
+
+// roundTrip writes a message and reads back as many bytes
+func roundTrip(conn net.Conn, message string) (string, error) {
+	if _, err := conn.Write([]byte(message)); err != nil {
+		return "", err
+	}
+	conn.SetReadDeadline(time.Now().Add(2 * time.Second))
+	buf := make([]byte, len(message))
+	_, err := io.ReadFull(conn, buf)
+	return string(buf), err
+}
+
+func TestTCPProxy_ForwardsConnections(t *testing.T) {
+	proxy := startProxy(t, config.StreamListener{
+		Protocol: "tcp", Address: "127.0.0.1:0", Upstream: startTCPEcho(t), MaxConnections: 1, IdleTimeout: 200 * time.Millisecond,
+	})
+
+	// 1. Traffic is relayed to the upstream and back
+	conn, err := net.Dial("tcp", proxy.Addr().String())
+	require.NoError(t, err)
+	defer conn.Close()
+	reply, err := roundTrip(conn, "CONNECT")
+	require.NoError(t, err)
+	assert.Equal(t, "CONNECT", reply)
+
+	// 2. Connections over the limit are closed straight away
+	rejected, err := net.Dial("tcp", proxy.Addr().String())
+	require.NoError(t, err)
+	defer rejected.Close()
+	_, err = roundTrip(rejected, "PING")
+	assert.Error(t, err)
+
+	// 3. An idle connection is closed after the idle timeout
+	time.Sleep(400 * time.Millisecond)
+	_, err = roundTrip(conn, "PING")
+	assert.Error(t, err)
+
+	// 4. Its slot is free again
+	conn, err = net.Dial("tcp", proxy.Addr().String())
+	require.NoError(t, err)
+	defer conn.Close()
+	reply, err = roundTrip(conn, "PING")
+	require.NoError(t, err)
+	assert.Equal(t, "PING", reply)
+}
+
+func TestUDPProxy_ForwardsDatagrams(t *testing.T) {
+	proxy := startProxy(t, config.StreamListener{
+		Protocol: "udp", Address: "127.0.0.1:0", Upstream: startUDPEcho(t), MaxConnections: 1, IdleTimeout: 200 * time.Millisecond,
+	})
+
+	// 1. Datagrams are relayed to the upstream and replies returned to the sender
+	conn, err := net.Dial("udp", proxy.Addr().String())
+	require.NoError(t, err)
+	defer conn.Close()
+	reply, err := roundTrip(conn, "query")
+	require.NoError(t, err)
+	assert.Equal(t, "query", reply)
+
+	// 2. A second peer is dropped while the first session is active
+	other, err := net.Dial("udp", proxy.Addr().String())
+	require.NoError(t, err)
+	defer other.Close()
+	_, err = roundTrip(other, "query")
+	assert.Error(t, err)
+
+	// 3. Once the session is idle, another peer can be served
+	time.Sleep(400 * time.Millisecond)
+	reply, err = roundTrip(other, "query")
+	require.NoError(t, err)
+	assert.Equal(t, "query", reply)
+}
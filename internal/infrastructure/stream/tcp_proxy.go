@@ -0,0 +1,198 @@
+package stream
+
+import (
+	"errors"
+	"net"
+	"sync"
+	"time"
+
+	"api-gateway-sample/pkg/config"
+	"api-gateway-sample/pkg/logger"
+)
+
+// TCPProxy forwards TCP connections to an upstream, one upstream connection per client
+type TCPProxy struct {
+	config config.StreamListener
+	logger logger.Logger
+
+	mu       sync.Mutex
+	listener net.Listener
+	conns    map[net.Conn]struct{}
+	clients  int
+	closed   bool
+	wg       sync.WaitGroup
+}
+
+// NewTCPProxy creates a new TCPProxy instance
+func NewTCPProxy(listener config.StreamListener, logger logger.Logger) *TCPProxy {
+	return &TCPProxy{
+		config: listener,
+		logger: logger,
+		conns:  make(map[net.Conn]struct{}),
+	}
+}
+
+// Start listens on the configured address and accepts connections in the background
+func (p *TCPProxy) Start() error {
+	listener, err := net.Listen("tcp", p.config.Address)
+	if err != nil {
+		return err
+	}
+
+	p.mu.Lock()
+	p.listener = listener
+	p.mu.Unlock()
+
+	p.logger.Info("TCP stream listener started", "address", listener.Addr().String(), "upstream", p.config.Upstream)
+	p.wg.Add(1)
+	go p.serve(listener)
+	return nil
+}
+
+// Addr returns the address the proxy listens on
+func (p *TCPProxy) Addr() net.Addr {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.listener == nil {
+		return nil
+	}
+	return p.listener.Addr()
+}
+
+// Close stops accepting connections, closes the open ones and waits for them to finish
+func (p *TCPProxy) Close() error {
+	p.mu.Lock()
+	p.closed = true
+	var err error
+	if p.listener != nil {
+		err = p.listener.Close()
+	}
+	for conn := range p.conns {
+		conn.Close()
+	}
+	p.mu.Unlock()
+
+	p.wg.Wait()
+	return err
+}
+
+func (p *TCPProxy) serve(listener net.Listener) {
+	defer p.wg.Done()
+	for {
+		conn, err := listener.Accept()
+		if err != nil {
+			if errors.Is(err, net.ErrClosed) {
+				return
+			}
+			p.logger.Warn("Failed to accept stream connection", "address", p.config.Address, "error", err)
+			time.Sleep(100 * time.Millisecond)
+			continue
+		}
+
+		if !p.accept(conn) {
+			p.logger.Warn("Stream connection limit reached, rejecting connection",
+				"address", p.config.Address,
+				"client", conn.RemoteAddr().String(),
+				"max_connections", p.config.MaxConnections,
+			)
+			conn.Close()
+			continue
+		}
+
+		p.wg.Add(1)
+		go p.handle(conn)
+	}
+}
+
+// accept registers a client connection unless the proxy is closed or at its connection limit
+func (p *TCPProxy) accept(conn net.Conn) bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.closed || (p.config.MaxConnections > 0 && p.clients >= p.config.MaxConnections) {
+		return false
+	}
+	p.clients++
+	p.conns[conn] = struct{}{}
+	return true
+}
+
+// release unregisters a client connection
+func (p *TCPProxy) release(conn net.Conn) {
+	p.mu.Lock()
+	p.clients--
+	delete(p.conns, conn)
+	p.mu.Unlock()
+}
+
+// track registers an upstream connection so Close can interrupt it
+func (p *TCPProxy) track(conn net.Conn) bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.closed {
+		return false
+	}
+	p.conns[conn] = struct{}{}
+	return true
+}
+
+func (p *TCPProxy) untrack(conn net.Conn) {
+	p.mu.Lock()
+	delete(p.conns, conn)
+	p.mu.Unlock()
+}
+
+func (p *TCPProxy) handle(client net.Conn) {
+	defer p.wg.Done()
+	defer p.release(client)
+	defer client.Close()
+
+	upstream, err := net.DialTimeout("tcp", p.config.Upstream, dialTimeout)
+	if err != nil {
+		p.logger.Warn("Failed to connect to stream upstream", "upstream", p.config.Upstream, "error", err)
+		return
+	}
+	if !p.track(upstream) {
+		upstream.Close()
+		return
+	}
+	defer p.untrack(upstream)
+	defer upstream.Close()
+
+	// Either direction finishing ends the connection; closing both unblocks the other copy
+	done := make(chan struct{}, 2)
+	go func() {
+		pipe(upstream, client, p.config.IdleTimeout)
+		done <- struct{}{}
+	}()
+	go func() {
+		pipe(client, upstream, p.config.IdleTimeout)
+		done <- struct{}{}
+	}()
+	<-done
+	client.Close()
+	upstream.Close()
+	<-done
+}
+
+// pipe copies src to dst until either fails. Traffic in either direction pushes back the
+// read deadlines of both connections, so only a connection idle both ways times out.
+func pipe(dst net.Conn, src net.Conn, idleTimeout time.Duration) {
+	buf := make([]byte, 32*1024)
+	for {
+		if idleTimeout > 0 {
+			src.SetReadDeadline(time.Now().Add(idleTimeout))
+		}
+		n, err := src.Read(buf)
+		if n > 0 {
+			if idleTimeout > 0 {
+				dst.SetReadDeadline(time.Now().Add(idleTimeout))
+			}
+			if _, werr := dst.Write(buf[:n]); werr != nil {
+				return
+			}
+		}
+		if err != nil {
+			return
+		}
+	}
+}
@@ -0,0 +1,157 @@
+package stream
+
+import (
+	"errors"
+	"net"
+	"sync"
+	"time"
+
+	"api-gateway-sample/pkg/config"
+	"api-gateway-sample/pkg/logger"
+)
+
+// maxDatagramSize is the largest UDP payload
+const maxDatagramSize = 64 * 1024
+
+// UDPProxy forwards UDP datagrams to an upstream. Each client address gets a session with its
+// own upstream socket, so replies reach the client that sent the request. Sessions end after
+// the idle timeout.
+type UDPProxy struct {
+	config config.StreamListener
+	logger logger.Logger
+
+	mu       sync.Mutex
+	conn     net.PacketConn
+	sessions map[string]net.Conn
+	closed   bool
+	wg       sync.WaitGroup
+}
+
+// NewUDPProxy creates a new UDPProxy instance
+func NewUDPProxy(listener config.StreamListener, logger logger.Logger) *UDPProxy {
+	return &UDPProxy{
+		config:   listener,
+		logger:   logger,
+		sessions: make(map[string]net.Conn),
+	}
+}
+
+// Start listens on the configured address and forwards datagrams in the background
+func (p *UDPProxy) Start() error {
+	conn, err := net.ListenPacket("udp", p.config.Address)
+	if err != nil {
+		return err
+	}
+
+	p.mu.Lock()
+	p.conn = conn
+	p.mu.Unlock()
+
+	p.logger.Info("UDP stream listener started", "address", conn.LocalAddr().String(), "upstream", p.config.Upstream)
+	p.wg.Add(1)
+	go p.serve(conn)
+	return nil
+}
+
+// Addr returns the address the proxy listens on
+func (p *UDPProxy) Addr() net.Addr {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.conn == nil {
+		return nil
+	}
+	return p.conn.LocalAddr()
+}
+
+// Close stops listening, ends every session and waits for them to finish
+func (p *UDPProxy) Close() error {
+	p.mu.Lock()
+	p.closed = true
+	var err error
+	if p.conn != nil {
+		err = p.conn.Close()
+	}
+	for _, upstream := range p.sessions {
+		upstream.Close()
+	}
+	p.mu.Unlock()
+
+	p.wg.Wait()
+	return err
+}
+
+func (p *UDPProxy) serve(conn net.PacketConn) {
+	defer p.wg.Done()
+	buf := make([]byte, maxDatagramSize)
+	for {
+		n, client, err := conn.ReadFrom(buf)
+		if err != nil {
+			if errors.Is(err, net.ErrClosed) {
+				return
+			}
+			p.logger.Warn("Failed to read stream datagram", "address", p.config.Address, "error", err)
+			continue
+		}
+
+		upstream, err := p.session(conn, client)
+		if err != nil {
+			p.logger.Warn("Dropping stream datagram", "address", p.config.Address, "client", client.String(), "error", err)
+			continue
+		}
+		// Client traffic keeps the session alive as much as upstream replies do
+		upstream.SetReadDeadline(time.Now().Add(p.config.IdleTimeout))
+		if _, err := upstream.Write(buf[:n]); err != nil {
+			p.logger.Warn("Failed to forward stream datagram", "upstream", p.config.Upstream, "error", err)
+		}
+	}
+}
+
+// session returns the upstream socket of a client, creating it unless the proxy is closed
+// or at its session limit
+func (p *UDPProxy) session(conn net.PacketConn, client net.Addr) (net.Conn, error) {
+	key := client.String()
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if upstream, ok := p.sessions[key]; ok {
+		return upstream, nil
+	}
+	if p.closed {
+		return nil, net.ErrClosed
+	}
+	if p.config.MaxConnections > 0 && len(p.sessions) >= p.config.MaxConnections {
+		return nil, errors.New("session limit reached")
+	}
+
+	upstream, err := net.DialTimeout("udp", p.config.Upstream, dialTimeout)
+	if err != nil {
+		return nil, err
+	}
+	p.sessions[key] = upstream
+	p.wg.Add(1)
+	go p.reply(conn, client, upstream)
+	return upstream, nil
+}
+
+// reply sends the upstream's datagrams back to the client until the session is idle
+func (p *UDPProxy) reply(conn net.PacketConn, client net.Addr, upstream net.Conn) {
+	defer p.wg.Done()
+	defer func() {
+		p.mu.Lock()
+		delete(p.sessions, client.String())
+		p.mu.Unlock()
+		upstream.Close()
+	}()
+
+	buf := make([]byte, maxDatagramSize)
+	for {
+		upstream.SetReadDeadline(time.Now().Add(p.config.IdleTimeout))
+		n, err := upstream.Read(buf)
+		if err != nil {
+			return
+		}
+		if _, err := conn.WriteTo(buf[:n], client); err != nil {
+			return
+		}
+	}
+}
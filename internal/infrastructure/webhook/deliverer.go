@@ -0,0 +1,116 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"api-gateway-sample/internal/domain/entity"
+	"api-gateway-sample/pkg/logger"
+)
+
+// Headers sent with every webhook delivery
+const (
+	HeaderEvent     = "X-Gateway-Event"
+	HeaderDelivery  = "X-Gateway-Delivery"
+	HeaderTimestamp = "X-Gateway-Timestamp"
+	HeaderSignature = "X-Gateway-Signature"
+)
+
+// HTTPDeliverer implements the WebhookDeliverer interface over HTTP. Payloads are signed
+// with HMAC-SHA256 over "<timestamp>.<body>" using the subscription secret, and
+// network errors, 429 and 5xx responses are retried with exponential backoff.
+type HTTPDeliverer struct {
+	client     *http.Client
+	maxRetries int
+	backoff    time.Duration
+	logger     logger.Logger
+	now        func() time.Time
+}
+
+// NewHTTPDeliverer creates a new HTTPDeliverer instance
+func NewHTTPDeliverer(timeout time.Duration, maxRetries int, backoff time.Duration, logger logger.Logger) *HTTPDeliverer {
+	return &HTTPDeliverer{
+		client:     &http.Client{Timeout: timeout},
+		maxRetries: maxRetries,
+		backoff:    backoff,
+		logger:     logger,
+		now:        time.Now,
+	}
+}
+
+// Deliver posts the event to the webhook, retrying transient failures
+func (d *HTTPDeliverer) Deliver(ctx context.Context, webhook *entity.WebhookSubscription, event *entity.Event) error {
+	body, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("failed to encode event: %w", err)
+	}
+
+	var lastErr error
+	for attempt := 0; attempt <= d.maxRetries; attempt++ {
+		if attempt > 0 {
+			delay := d.backoff << (attempt - 1)
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-time.After(delay):
+			}
+		}
+
+		retry, err := d.send(ctx, webhook, event, body)
+		if err == nil {
+			return nil
+		}
+		lastErr = err
+		if !retry {
+			break
+		}
+		d.logger.Warn("Webhook delivery failed", "webhook_id", webhook.ID, "event", event.Type, "attempt", attempt+1, "error", err)
+	}
+	return fmt.Errorf("webhook %s delivery failed: %w", webhook.ID, lastErr)
+}
+
+// send makes one delivery attempt and reports whether a failure is worth retrying
+func (d *HTTPDeliverer) send(ctx context.Context, webhook *entity.WebhookSubscription, event *entity.Event, body []byte) (bool, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, webhook.URL, bytes.NewReader(body))
+	if err != nil {
+		return false, fmt.Errorf("failed to create webhook request: %w", err)
+	}
+
+	timestamp := strconv.FormatInt(d.now().Unix(), 10)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(HeaderEvent, event.Type)
+	req.Header.Set(HeaderDelivery, event.ID)
+	req.Header.Set(HeaderTimestamp, timestamp)
+	req.Header.Set(HeaderSignature, "sha256="+Sign(webhook.Secret, timestamp, body))
+
+	resp, err := d.client.Do(req)
+	if err != nil {
+		return true, err
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, resp.Body)
+
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return false, nil
+	}
+	retry := resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500
+	return retry, fmt.Errorf("unexpected status %d", resp.StatusCode)
+}
+
+// Sign returns the hex HMAC-SHA256 of "<timestamp>.<body>" with the given secret
+func Sign(secret string, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,69 @@
+package webhook
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"api-gateway-sample/internal/domain/entity"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// nopLogger discards all log output
+type nopLogger struct{}
+
+func (nopLogger) Debug(msg string, keysAndValues ...interface{}) {}
+func (nopLogger) Info(msg string, keysAndValues ...interface{})  {}
+func (nopLogger) Warn(msg string, keysAndValues ...interface{})  {}
+func (nopLogger) Error(msg string, keysAndValues ...interface{}) {}
+func (nopLogger) Fatal(msg string, keysAndValues ...interface{}) {}
+
+func TestHTTPDeliverer_SignsAndRetries(t *testing.T) {
+	var attempts int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		timestamp := r.Header.Get(HeaderTimestamp)
+		assert.Equal(t, "sha256="+Sign("s3cret", timestamp, body), r.Header.Get(HeaderSignature))
+		assert.Equal(t, entity.EventServiceUpdated, r.Header.Get(HeaderEvent))
+		assert.Equal(t, "evt-1", r.Header.Get(HeaderDelivery))
+
+		// Fail the first attempt to exercise the retry
+		if atomic.AddInt32(&attempts, 1) == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	deliverer := NewHTTPDeliverer(time.Second, 2, time.Millisecond, nopLogger{})
+	deliverer.now = func() time.Time { return time.Unix(1700000000, 0) }
+
+	webhook := &entity.WebhookSubscription{ID: "wh-1", URL: server.URL, Secret: "s3cret", Active: true}
+	event := &entity.Event{ID: "evt-1", Type: entity.EventServiceUpdated, ServiceID: "svc-1"}
+
+	require.NoError(t, deliverer.Deliver(context.Background(), webhook, event))
+	assert.Equal(t, int32(2), atomic.LoadInt32(&attempts))
+}
+
+func TestHTTPDeliverer_DoesNotRetryClientErrors(t *testing.T) {
+	var attempts int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&attempts, 1)
+		w.WriteHeader(http.StatusGone)
+	}))
+	defer server.Close()
+
+	deliverer := NewHTTPDeliverer(time.Second, 3, time.Millisecond, nopLogger{})
+	webhook := &entity.WebhookSubscription{ID: "wh-1", URL: server.URL, Secret: "s3cret", Active: true}
+
+	err := deliverer.Deliver(context.Background(), webhook, entity.NewEvent(entity.EventServiceDeleted))
+	assert.Error(t, err)
+	assert.Equal(t, int32(1), atomic.LoadInt32(&attempts))
+}
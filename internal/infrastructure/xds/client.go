@@ -0,0 +1,288 @@
+// Package xds consumes the routes and clusters of an Envoy xDS management server and serves
+// them as services of the gateway
+package xds
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"reflect"
+	"sort"
+	"time"
+
+	clusterv3 "github.com/envoyproxy/go-control-plane/envoy/config/cluster/v3"
+	corev3 "github.com/envoyproxy/go-control-plane/envoy/config/core/v3"
+	endpointv3 "github.com/envoyproxy/go-control-plane/envoy/config/endpoint/v3"
+	routev3 "github.com/envoyproxy/go-control-plane/envoy/config/route/v3"
+	discoveryv3 "github.com/envoyproxy/go-control-plane/envoy/service/discovery/v3"
+	rpcstatus "google.golang.org/genproto/googleapis/rpc/status"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+
+	"api-gateway-sample/internal/domain/entity"
+	"api-gateway-sample/internal/domain/service"
+	"api-gateway-sample/pkg/config"
+	"api-gateway-sample/pkg/logger"
+)
+
+// Type URLs of the subscribed resources
+const (
+	clusterType  = "type.googleapis.com/envoy.config.cluster.v3.Cluster"
+	endpointType = "type.googleapis.com/envoy.config.endpoint.v3.ClusterLoadAssignment"
+	routeType    = "type.googleapis.com/envoy.config.route.v3.RouteConfiguration"
+)
+
+// Delays before reconnecting to the management server, doubled after every failed stream
+const (
+	minReconnectDelay = time.Second
+	maxReconnectDelay = 30 * time.Second
+)
+
+// Dial creates the connection to the management server of the configuration
+func Dial(cfg config.XDSConfig) (*grpc.ClientConn, error) {
+	creds := insecure.NewCredentials()
+	if cfg.TLS {
+		creds = credentials.NewTLS(&tls.Config{MinVersion: tls.VersionTLS12})
+	}
+	conn, err := grpc.NewClient(cfg.Address, grpc.WithTransportCredentials(creds))
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to xDS server %s: %w", cfg.Address, err)
+	}
+	return conn, nil
+}
+
+// Client subscribes to clusters, their load assignments and the configured route
+// configurations over the aggregated discovery service (ADS), and replaces the translated
+// services of a ServiceRepository whenever they change. Invalid responses are rejected (NACK)
+// and the last accepted resources kept.
+type Client struct {
+	ads      discoveryv3.AggregatedDiscoveryServiceClient
+	node     *corev3.Node
+	routes   []string
+	services *ServiceRepository
+	events   service.EventPublisher
+	logger   logger.Logger
+
+	state   resources
+	skipped []string
+	// versions holds the last accepted version of each type, sent again when resubscribing
+	versions map[string]string
+}
+
+// NewClient creates a new Client instance. The changes of the translated services are
+// published on events as service events.
+func NewClient(conn grpc.ClientConnInterface, cfg config.XDSConfig, services *ServiceRepository, events service.EventPublisher, logger logger.Logger) *Client {
+	return &Client{
+		ads:      discoveryv3.NewAggregatedDiscoveryServiceClient(conn),
+		node:     &corev3.Node{Id: cfg.NodeID, Cluster: cfg.Cluster},
+		routes:   cfg.RouteConfigs,
+		services: services,
+		events:   events,
+		logger:   logger,
+		state: resources{
+			clusters:    make(map[string]*clusterv3.Cluster),
+			assignments: make(map[string]*endpointv3.ClusterLoadAssignment),
+			routes:      make(map[string]*routev3.RouteConfiguration),
+		},
+		versions: make(map[string]string),
+	}
+}
+
+// Run keeps a stream to the management server open until ctx is done, reconnecting with
+// exponential backoff. The services last received are served while disconnected.
+func (c *Client) Run(ctx context.Context) {
+	delay := minReconnectDelay
+	for {
+		started := time.Now()
+		err := c.subscribe(ctx)
+		if ctx.Err() != nil {
+			return
+		}
+		if time.Since(started) > maxReconnectDelay {
+			delay = minReconnectDelay
+		}
+		c.logger.Warn("xDS stream ended, reconnecting", "error", err, "delay", delay.String())
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(delay):
+		}
+		if delay *= 2; delay > maxReconnectDelay {
+			delay = maxReconnectDelay
+		}
+	}
+}
+
+// subscribe opens a stream, subscribes to the resources and applies the responses until the
+// stream fails
+func (c *Client) subscribe(ctx context.Context) error {
+	stream, err := c.ads.StreamAggregatedResources(ctx)
+	if err != nil {
+		return err
+	}
+
+	// Clusters are a wildcard subscription; load assignments follow the EDS clusters received
+	subscriptions := map[string][]string{
+		clusterType: nil,
+		routeType:   c.routes,
+	}
+	if names := c.edsServiceNames(); len(names) > 0 {
+		subscriptions[endpointType] = names
+	}
+	nonces := make(map[string]string)
+	request := func(typeURL string, errorDetail error) error {
+		req := &discoveryv3.DiscoveryRequest{
+			VersionInfo:   c.versions[typeURL],
+			Node:          c.node,
+			ResourceNames: subscriptions[typeURL],
+			TypeUrl:       typeURL,
+			ResponseNonce: nonces[typeURL],
+		}
+		if errorDetail != nil {
+			req.ErrorDetail = &rpcstatus.Status{Code: int32(codes.InvalidArgument), Message: errorDetail.Error()}
+		}
+		return stream.Send(req)
+	}
+
+	for _, typeURL := range []string{clusterType, endpointType, routeType} {
+		if _, ok := subscriptions[typeURL]; ok {
+			if err := request(typeURL, nil); err != nil {
+				return err
+			}
+		}
+	}
+
+	for {
+		resp, err := stream.Recv()
+		if err != nil {
+			return err
+		}
+		typeURL := resp.GetTypeUrl()
+		nonces[typeURL] = resp.GetNonce()
+
+		if err := c.apply(resp); err != nil {
+			c.logger.Warn("Rejected xDS response", "type", typeURL, "version", resp.GetVersionInfo(), "error", err)
+			if err := request(typeURL, err); err != nil {
+				return err
+			}
+			continue
+		}
+		c.versions[typeURL] = resp.GetVersionInfo()
+		if err := request(typeURL, nil); err != nil {
+			return err
+		}
+
+		// New or removed EDS clusters change the load assignments to subscribe to
+		if typeURL == clusterType {
+			if names := c.edsServiceNames(); !reflect.DeepEqual(names, subscriptions[endpointType]) {
+				subscriptions[endpointType] = names
+				if err := request(endpointType, nil); err != nil {
+					return err
+				}
+			}
+		}
+		c.publish(ctx)
+	}
+}
+
+// apply validates the resources of a response and stores them. Clusters are the complete
+// set, while route configurations and load assignments update those received before.
+func (c *Client) apply(resp *discoveryv3.DiscoveryResponse) error {
+	switch resp.GetTypeUrl() {
+	case clusterType:
+		clusters := make(map[string]*clusterv3.Cluster)
+		for _, resource := range resp.GetResources() {
+			cluster := &clusterv3.Cluster{}
+			if err := resource.UnmarshalTo(cluster); err != nil {
+				return err
+			}
+			if err := cluster.Validate(); err != nil {
+				return fmt.Errorf("cluster %s: %w", cluster.GetName(), err)
+			}
+			clusters[cluster.GetName()] = cluster
+		}
+		c.state.clusters = clusters
+	case endpointType:
+		assignments := make(map[string]*endpointv3.ClusterLoadAssignment)
+		for _, resource := range resp.GetResources() {
+			assignment := &endpointv3.ClusterLoadAssignment{}
+			if err := resource.UnmarshalTo(assignment); err != nil {
+				return err
+			}
+			if err := assignment.Validate(); err != nil {
+				return fmt.Errorf("load assignment %s: %w", assignment.GetClusterName(), err)
+			}
+			assignments[assignment.GetClusterName()] = assignment
+		}
+		for name, assignment := range assignments {
+			c.state.assignments[name] = assignment
+		}
+	case routeType:
+		routes := make(map[string]*routev3.RouteConfiguration)
+		for _, resource := range resp.GetResources() {
+			route := &routev3.RouteConfiguration{}
+			if err := resource.UnmarshalTo(route); err != nil {
+				return err
+			}
+			if err := route.Validate(); err != nil {
+				return fmt.Errorf("route configuration %s: %w", route.GetName(), err)
+			}
+			routes[route.GetName()] = route
+		}
+		for name, route := range routes {
+			c.state.routes[name] = route
+		}
+	default:
+		return fmt.Errorf("unexpected resource type %s", resp.GetTypeUrl())
+	}
+	return nil
+}
+
+// edsServiceNames returns the sorted EDS service names of the clusters
+func (c *Client) edsServiceNames() []string {
+	var names []string
+	for name, cluster := range c.state.clusters {
+		if cluster.GetType() != clusterv3.Cluster_EDS {
+			continue
+		}
+		if serviceName := cluster.GetEdsClusterConfig().GetServiceName(); serviceName != "" {
+			name = serviceName
+		}
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// publish translates the resources, replaces the services and announces their changes
+func (c *Client) publish(ctx context.Context) {
+	services, skipped := translate(c.state)
+	if !reflect.DeepEqual(skipped, c.skipped) {
+		for _, reason := range skipped {
+			c.logger.Warn("Skipped xDS resource", "reason", reason)
+		}
+		c.skipped = skipped
+	}
+
+	for _, change := range c.services.Replace(services) {
+		var event *entity.Event
+		switch {
+		case change.Previous == nil:
+			event = entity.NewEvent(entity.EventServiceCreated)
+			event.ServiceID = change.Current.ID
+		case change.Current == nil:
+			event = entity.NewEvent(entity.EventServiceDeleted)
+			event.ServiceID = change.Previous.ID
+		default:
+			event = entity.NewEvent(entity.EventServiceUpdated)
+			event.ServiceID = change.Current.ID
+		}
+		event.Service = change.Current
+		event.Previous = change.Previous
+		event.Data = map[string]interface{}{"source": "xds"}
+		c.events.Publish(ctx, event)
+	}
+}
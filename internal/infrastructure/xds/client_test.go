@@ -0,0 +1,207 @@
+package xds
+
+import (
+	"context"
+	"net"
+	"sync"
+	"testing"
+	"time"
+
+	routev3 "github.com/envoyproxy/go-control-plane/envoy/config/route/v3"
+	discoveryv3 "github.com/envoyproxy/go-control-plane/envoy/service/discovery/v3"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/test/bufconn"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/anypb"
+
+	"api-gateway-sample/internal/domain/entity"
+	repomock "api-gateway-sample/internal/domain/repository/mock"
+	"api-gateway-sample/pkg/config"
+	"api-gateway-sample/pkg/errors"
+)
+
+// nopLogger discards all log output
+type nopLogger struct{}
+
+func (nopLogger) Debug(msg string, keysAndValues ...interface{}) {}
+func (nopLogger) Info(msg string, keysAndValues ...interface{})  {}
+func (nopLogger) Warn(msg string, keysAndValues ...interface{})  {}
+func (nopLogger) Error(msg string, keysAndValues ...interface{}) {}
+func (nopLogger) Fatal(msg string, keysAndValues ...interface{}) {}
+
+// eventRecorder collects the published events
+type eventRecorder struct {
+	events chan *entity.Event
+}
+
+func (r *eventRecorder) Publish(ctx context.Context, event *entity.Event) {
+	r.events <- event
+}
+
+// adsServer is a management server whose responses are sent by the test
+type adsServer struct {
+	discoveryv3.UnimplementedAggregatedDiscoveryServiceServer
+	requests  chan *discoveryv3.DiscoveryRequest
+	responses chan *discoveryv3.DiscoveryResponse
+}
+
+func (s *adsServer) StreamAggregatedResources(stream discoveryv3.AggregatedDiscoveryService_StreamAggregatedResourcesServer) error {
+	go func() {
+		for {
+			req, err := stream.Recv()
+			if err != nil {
+				return
+			}
+			s.requests <- req
+		}
+	}()
+	for {
+		select {
+		case <-stream.Context().Done():
+			return nil
+		case resp := <-s.responses:
+			if err := stream.Send(resp); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// expectRequest returns the next request of the client, which must be of the given type
+func (s *adsServer) expectRequest(t *testing.T, typeURL string) *discoveryv3.DiscoveryRequest {
+	t.Helper()
+	select {
+	case req := <-s.requests:
+		require.Equal(t, typeURL, req.TypeUrl)
+		return req
+	case <-time.After(5 * time.Second):
+		t.Fatalf("no %s request", typeURL)
+		return nil
+	}
+}
+
+func (s *adsServer) respond(t *testing.T, typeURL string, version string, nonce string, messages ...proto.Message) {
+	t.Helper()
+	resp := &discoveryv3.DiscoveryResponse{TypeUrl: typeURL, VersionInfo: version, Nonce: nonce}
+	for _, message := range messages {
+		resource, err := anypb.New(message)
+		require.NoError(t, err)
+		resp.Resources = append(resp.Resources, resource)
+	}
+	s.responses <- resp
+}
+
+func expectEvent(t *testing.T, recorder *eventRecorder, eventType string, serviceID string) *entity.Event {
+	t.Helper()
+	select {
+	case event := <-recorder.events:
+		assert.Equal(t, eventType, event.Type)
+		assert.Equal(t, serviceID, event.ServiceID)
+		return event
+	case <-time.After(5 * time.Second):
+		t.Fatalf("no %s event", eventType)
+		return nil
+	}
+}
+
+func TestClient_SubscribesOverADS(t *testing.T) {
+	server := &adsServer{
+		requests:  make(chan *discoveryv3.DiscoveryRequest, 16),
+		responses: make(chan *discoveryv3.DiscoveryResponse),
+	}
+	grpcServer := grpc.NewServer()
+	discoveryv3.RegisterAggregatedDiscoveryServiceServer(grpcServer, server)
+	listener := bufconn.Listen(1 << 20)
+	go grpcServer.Serve(listener)
+	defer grpcServer.Stop()
+
+	conn, err := grpc.NewClient("passthrough:///bufconn",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return listener.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	require.NoError(t, err)
+	defer conn.Close()
+
+	repo := NewServiceRepository(repomock.NewServiceRepositoryMock())
+	recorder := &eventRecorder{events: make(chan *entity.Event, 16)}
+	cfg := config.XDSConfig{NodeID: "gateway-1", Cluster: "edge", RouteConfigs: []string{"gateway"}}
+	client := NewClient(conn, cfg, repo, recorder, nopLogger{})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		client.Run(ctx)
+	}()
+	defer wg.Wait()
+	defer cancel()
+
+	// 1. Clusters are subscribed to by wildcard and routes by the configured names
+	req := server.expectRequest(t, clusterType)
+	assert.Equal(t, "gateway-1", req.Node.Id)
+	assert.Empty(t, req.ResourceNames)
+	req = server.expectRequest(t, routeType)
+	assert.Equal(t, []string{"gateway"}, req.ResourceNames)
+
+	// 2. Clusters are acknowledged, and the load assignments of EDS clusters subscribed to
+	server.respond(t, clusterType, "1", "c1", staticCluster("users", "10.0.0.1", 8080), edsCluster("orders"))
+	req = server.expectRequest(t, clusterType)
+	assert.Equal(t, "1", req.VersionInfo)
+	assert.Equal(t, "c1", req.ResponseNonce)
+	assert.Nil(t, req.ErrorDetail)
+	req = server.expectRequest(t, endpointType)
+	assert.Equal(t, []string{"orders"}, req.ResourceNames)
+
+	// 3. Services appear as routes and load assignments arrive
+	server.respond(t, routeType, "1", "r1", routeConfiguration("gateway",
+		pathRoute("/api/v1/users", "GET", "users"),
+		pathRoute("/api/v1/orders", "", "orders"),
+	))
+	server.expectRequest(t, routeType)
+	expectEvent(t, recorder, entity.EventServiceCreated, "xds-users")
+
+	server.respond(t, endpointType, "1", "e1", loadAssignment("orders", "10.0.0.2", 9090))
+	server.expectRequest(t, endpointType)
+	event := expectEvent(t, recorder, entity.EventServiceCreated, "xds-orders")
+	assert.Equal(t, "http://10.0.0.2:9090", event.Service.BaseURL)
+
+	services, err := repo.GetByEndpoint(ctx, "/api/v1/orders", "POST")
+	require.NoError(t, err)
+	require.Len(t, services, 1)
+	assert.Equal(t, "orders", services[0].Name)
+
+	// 4. Invalid resources are rejected and the accepted ones kept
+	server.respond(t, routeType, "2", "r2", &routev3.RouteConfiguration{
+		Name:         "gateway",
+		VirtualHosts: []*routev3.VirtualHost{{Name: "gateway", Routes: []*routev3.Route{{}}}},
+	})
+	req = server.expectRequest(t, routeType)
+	assert.Equal(t, "1", req.VersionInfo)
+	assert.Equal(t, "r2", req.ResponseNonce)
+	require.NotNil(t, req.ErrorDetail)
+
+	// 5. Changes are published with both states, and translated services are read-only
+	server.respond(t, endpointType, "2", "e2", loadAssignment("orders", "10.0.0.3", 9090))
+	server.expectRequest(t, endpointType)
+	event = expectEvent(t, recorder, entity.EventServiceUpdated, "xds-orders")
+	assert.Equal(t, "http://10.0.0.2:9090", event.Previous.BaseURL)
+	assert.Equal(t, int64(2), event.Service.Revision)
+
+	err = repo.Delete(ctx, "xds-orders", 0)
+	assert.True(t, errors.IsForbidden(err))
+	_, err = repo.Get(ctx, "xds-users")
+	assert.NoError(t, err)
+
+	// 6. Clusters missing from the complete set remove their services
+	server.respond(t, clusterType, "2", "c2", edsCluster("orders"))
+	server.expectRequest(t, clusterType)
+	expectEvent(t, recorder, entity.EventServiceDeleted, "xds-users")
+	_, err = repo.Get(ctx, "xds-users")
+	assert.True(t, errors.IsNotFound(err))
+}
@@ -0,0 +1,190 @@
+package xds
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"sync"
+
+	"api-gateway-sample/internal/domain/entity"
+	"api-gateway-sample/internal/domain/repository"
+	"api-gateway-sample/pkg/errors"
+)
+
+// serviceIDPrefix marks the IDs of the services translated from xDS resources
+const serviceIDPrefix = "xds-"
+
+// errManaged is returned when a service of the management server is modified locally
+var errManaged = errors.NewError(errors.CodeForbidden, "service is managed by the xDS control plane", errors.ErrForbidden)
+
+// ServiceRepository serves the services translated from xDS resources besides those of another
+// repository, which are matched first. The translated services are held in memory and are
+// read-only: the management server is their source of truth.
+type ServiceRepository struct {
+	next repository.ServiceRepository
+
+	mu       sync.RWMutex
+	services []*entity.Service
+}
+
+// NewServiceRepository creates a new ServiceRepository instance in front of next
+func NewServiceRepository(next repository.ServiceRepository) *ServiceRepository {
+	return &ServiceRepository{next: next}
+}
+
+// Replace swaps the translated services for the given ones and returns the changes, each with
+// the service before and after it: nil before for a new service and nil after a removed one
+func (r *ServiceRepository) Replace(services []*entity.Service) []ServiceChange {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	previous := make(map[string]*entity.Service, len(r.services))
+	for _, service := range r.services {
+		previous[service.ID] = service
+	}
+
+	var changes []ServiceChange
+	for _, service := range services {
+		old, ok := previous[service.ID]
+		delete(previous, service.ID)
+		if ok {
+			service.Revision = old.Revision
+			if reflect.DeepEqual(old, service) {
+				continue
+			}
+			service.Revision++
+		} else {
+			service.Revision = 1
+		}
+		changes = append(changes, ServiceChange{Previous: old, Current: service})
+	}
+	for _, old := range r.services {
+		if _, removed := previous[old.ID]; removed {
+			changes = append(changes, ServiceChange{Previous: old})
+		}
+	}
+
+	r.services = services
+	return changes
+}
+
+// ServiceChange is a change of a translated service
+type ServiceChange struct {
+	Previous *entity.Service
+	Current  *entity.Service
+}
+
+// Create creates a service in the next repository
+func (r *ServiceRepository) Create(ctx context.Context, service *entity.Service) error {
+	return r.next.Create(ctx, service)
+}
+
+// Get retrieves a service by ID
+func (r *ServiceRepository) Get(ctx context.Context, id string) (*entity.Service, error) {
+	if !managed(id) {
+		return r.next.Get(ctx, id)
+	}
+	if service := r.find(func(s *entity.Service) bool { return s.ID == id }); service != nil {
+		return service, nil
+	}
+	return nil, errors.ErrNotFound
+}
+
+// GetByID retrieves a service by ID (alias for Get)
+func (r *ServiceRepository) GetByID(ctx context.Context, id string) (*entity.Service, error) {
+	return r.Get(ctx, id)
+}
+
+// Update updates a service of the next repository; translated services cannot be updated
+func (r *ServiceRepository) Update(ctx context.Context, service *entity.Service) error {
+	if managed(service.ID) {
+		return errManaged
+	}
+	return r.next.Update(ctx, service)
+}
+
+// Delete deletes a service of the next repository; translated services cannot be deleted
+func (r *ServiceRepository) Delete(ctx context.Context, id string, revision int64) error {
+	if managed(id) {
+		return errManaged
+	}
+	return r.next.Delete(ctx, id, revision)
+}
+
+// Apply makes the changes of a change set in the next repository; translated services cannot
+// be updated or deleted
+func (r *ServiceRepository) Apply(ctx context.Context, changes repository.ServiceChangeSet) error {
+	for _, service := range changes.Update {
+		if managed(service.ID) {
+			return errManaged
+		}
+	}
+	for _, id := range changes.Delete {
+		if managed(id) {
+			return errManaged
+		}
+	}
+	return r.next.Apply(ctx, changes)
+}
+
+// GetAll retrieves the services of the next repository followed by the translated ones
+func (r *ServiceRepository) GetAll(ctx context.Context) ([]*entity.Service, error) {
+	services, err := r.next.GetAll(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return append(services, r.filter(func(*entity.Service) bool { return true })...), nil
+}
+
+// FindByName finds a service by name, in the next repository first
+func (r *ServiceRepository) FindByName(ctx context.Context, name string) (*entity.Service, error) {
+	service, err := r.next.FindByName(ctx, name)
+	if err == nil || !errors.IsNotFound(err) {
+		return service, err
+	}
+	if service := r.find(func(s *entity.Service) bool { return s.Name == name }); service != nil {
+		return service, nil
+	}
+	return nil, err
+}
+
+// GetByEndpoint finds services by endpoint path and method, those of the next repository first
+func (r *ServiceRepository) GetByEndpoint(ctx context.Context, path string, method string) ([]*entity.Service, error) {
+	services, err := r.next.GetByEndpoint(ctx, path, method)
+	if err != nil && !errors.IsNotFound(err) {
+		return nil, err
+	}
+	services = append(services, r.filter(func(s *entity.Service) bool {
+		return s.FindEndpoint(path, method) != nil
+	})...)
+	if len(services) == 0 {
+		return nil, err
+	}
+	return services, nil
+}
+
+func (r *ServiceRepository) find(match func(*entity.Service) bool) *entity.Service {
+	if found := r.filter(match); len(found) > 0 {
+		return found[0]
+	}
+	return nil
+}
+
+// filter returns copies of the translated services that match
+func (r *ServiceRepository) filter(match func(*entity.Service) bool) []*entity.Service {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var found []*entity.Service
+	for _, service := range r.services {
+		if match(service) {
+			found = append(found, service.Clone())
+		}
+	}
+	return found
+}
+
+// managed reports whether a service ID is one of a translated service
+func managed(id string) bool {
+	return strings.HasPrefix(id, serviceIDPrefix)
+}
@@ -0,0 +1,16 @@
+package xds
+
+import (
+	"testing"
+
+	"api-gateway-sample/internal/domain/repository"
+	repomock "api-gateway-sample/internal/domain/repository/mock"
+	"api-gateway-sample/internal/domain/repository/repositorytest"
+)
+
+func TestServiceRepository_Contract(t *testing.T) {
+	// Without translated services, the repository behaves as the one it is in front of
+	repositorytest.TestServiceRepository(t, func(t *testing.T) repository.ServiceRepository {
+		return NewServiceRepository(repomock.NewServiceRepositoryMock())
+	})
+}
@@ -0,0 +1,198 @@
+package xds
+
+import (
+	"fmt"
+	"net"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	clusterv3 "github.com/envoyproxy/go-control-plane/envoy/config/cluster/v3"
+	corev3 "github.com/envoyproxy/go-control-plane/envoy/config/core/v3"
+	endpointv3 "github.com/envoyproxy/go-control-plane/envoy/config/endpoint/v3"
+	routev3 "github.com/envoyproxy/go-control-plane/envoy/config/route/v3"
+
+	"api-gateway-sample/internal/domain/entity"
+)
+
+// allMethods are the methods of routes that do not match the :method header
+var allMethods = []string{"GET", "POST", "PUT", "DELETE", "PATCH", "HEAD", "OPTIONS"}
+
+// resources is the state of the subscribed xDS resources, by name
+type resources struct {
+	clusters    map[string]*clusterv3.Cluster
+	assignments map[string]*endpointv3.ClusterLoadAssignment
+	routes      map[string]*routev3.RouteConfiguration
+}
+
+// translate converts the routes to services, one per cluster they lead to, sorted by name.
+// Virtual host domains are ignored, since the gateway routes by path alone. Routes and
+// clusters the gateway cannot represent are skipped, and the reasons returned.
+func translate(res resources) ([]*entity.Service, []string) {
+	var skipped []string
+	services := make(map[string]*entity.Service)
+
+	names := make([]string, 0, len(res.routes))
+	for name := range res.routes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		for _, virtualHost := range res.routes[name].GetVirtualHosts() {
+			for i, route := range virtualHost.GetRoutes() {
+				routeName := route.GetName()
+				if routeName == "" {
+					routeName = fmt.Sprintf("%s/%s[%d]", name, virtualHost.GetName(), i)
+				}
+
+				endpoint, cluster, err := translateRoute(route)
+				if err != nil {
+					skipped = append(skipped, fmt.Sprintf("route %s: %v", routeName, err))
+					continue
+				}
+
+				service, ok := services[cluster]
+				if !ok {
+					baseURL, err := clusterBaseURL(res, cluster)
+					if err != nil {
+						skipped = append(skipped, fmt.Sprintf("route %s: cluster %s: %v", routeName, cluster, err))
+						continue
+					}
+					service = &entity.Service{
+						ID:       serviceIDPrefix + cluster,
+						Name:     cluster,
+						BaseURL:  baseURL,
+						IsActive: true,
+						Metadata: map[string]string{"source": "xds"},
+					}
+					services[cluster] = service
+				}
+				addEndpoint(service, endpoint)
+			}
+		}
+	}
+
+	translated := make([]*entity.Service, 0, len(services))
+	for _, service := range services {
+		translated = append(translated, service)
+	}
+	sort.Slice(translated, func(i, j int) bool { return translated[i].Name < translated[j].Name })
+	return translated, skipped
+}
+
+// translateRoute converts a route to an endpoint and the cluster it forwards to
+func translateRoute(route *routev3.Route) (entity.Endpoint, string, error) {
+	action := route.GetRoute()
+	if action == nil {
+		return entity.Endpoint{}, "", fmt.Errorf("only routes to a cluster are supported")
+	}
+	cluster := action.GetCluster()
+	if cluster == "" {
+		return entity.Endpoint{}, "", fmt.Errorf("only routes to a single named cluster are supported")
+	}
+	path := route.GetMatch().GetPath()
+	if path == "" {
+		return entity.Endpoint{}, "", fmt.Errorf("only exact path matches are supported")
+	}
+
+	endpoint := entity.Endpoint{
+		Path:    path,
+		Methods: append([]string(nil), allMethods...),
+	}
+	for _, header := range route.GetMatch().GetHeaders() {
+		if header.GetName() != ":method" {
+			continue
+		}
+		method := header.GetStringMatch().GetExact()
+		if method == "" {
+			method = header.GetExactMatch()
+		}
+		if method == "" || header.GetInvertMatch() {
+			return entity.Endpoint{}, "", fmt.Errorf("only exact :method matches are supported")
+		}
+		endpoint.Methods = []string{strings.ToUpper(method)}
+	}
+
+	if timeout := action.GetTimeout().AsDuration(); timeout > 0 {
+		// Timeouts are whole seconds, rounded up so that a route is never cut short
+		endpoint.Timeout = int((timeout + time.Second - 1) / time.Second)
+	}
+	if retry := action.GetRetryPolicy(); retry != nil {
+		// Envoy retries once when the policy does not set a number of retries
+		endpoint.RetryCount = 1
+		if retry.GetNumRetries() != nil {
+			endpoint.RetryCount = int(retry.GetNumRetries().GetValue())
+		}
+		endpoint.RetryDelay = int(retry.GetRetryBackOff().GetBaseInterval().AsDuration().Milliseconds())
+	}
+	return endpoint, cluster, nil
+}
+
+// addEndpoint adds an endpoint to a service, or its methods to the endpoint of the same path
+// since endpoints are matched by path first. The settings of the first route of a path apply.
+func addEndpoint(service *entity.Service, endpoint entity.Endpoint) {
+	for i := range service.Endpoints {
+		existing := &service.Endpoints[i]
+		if existing.Path != endpoint.Path {
+			continue
+		}
+		for _, method := range endpoint.Methods {
+			if !containsMethod(existing.Methods, method) {
+				existing.Methods = append(append([]string(nil), existing.Methods...), method)
+			}
+		}
+		return
+	}
+	service.AddEndpoint(endpoint)
+}
+
+func containsMethod(methods []string, method string) bool {
+	for _, m := range methods {
+		if m == method {
+			return true
+		}
+	}
+	return false
+}
+
+// clusterBaseURL returns the URL of the first available endpoint of a cluster, taken from its
+// load assignment or from the one of its EDS service. Clusters with a transport socket are
+// reached over HTTPS.
+func clusterBaseURL(res resources, name string) (string, error) {
+	cluster, ok := res.clusters[name]
+	if !ok {
+		return "", fmt.Errorf("not received")
+	}
+
+	assignment := cluster.GetLoadAssignment()
+	if cluster.GetType() == clusterv3.Cluster_EDS {
+		serviceName := cluster.GetEdsClusterConfig().GetServiceName()
+		if serviceName == "" {
+			serviceName = name
+		}
+		if assignment, ok = res.assignments[serviceName]; !ok {
+			return "", fmt.Errorf("load assignment %s not received", serviceName)
+		}
+	}
+
+	scheme := "http"
+	if cluster.GetTransportSocket() != nil {
+		scheme = "https"
+	}
+	for _, locality := range assignment.GetEndpoints() {
+		for _, lbEndpoint := range locality.GetLbEndpoints() {
+			switch lbEndpoint.GetHealthStatus() {
+			case corev3.HealthStatus_UNHEALTHY, corev3.HealthStatus_DRAINING, corev3.HealthStatus_TIMEOUT:
+				continue
+			}
+			address := lbEndpoint.GetEndpoint().GetAddress().GetSocketAddress()
+			if address.GetAddress() == "" || address.GetPortValue() == 0 {
+				continue
+			}
+			return scheme + "://" + net.JoinHostPort(address.GetAddress(), strconv.Itoa(int(address.GetPortValue()))), nil
+		}
+	}
+	return "", fmt.Errorf("no available endpoint with a socket address")
+}
@@ -0,0 +1,137 @@
+package xds
+
+import (
+	"testing"
+	"time"
+
+	clusterv3 "github.com/envoyproxy/go-control-plane/envoy/config/cluster/v3"
+	corev3 "github.com/envoyproxy/go-control-plane/envoy/config/core/v3"
+	endpointv3 "github.com/envoyproxy/go-control-plane/envoy/config/endpoint/v3"
+	routev3 "github.com/envoyproxy/go-control-plane/envoy/config/route/v3"
+	matcherv3 "github.com/envoyproxy/go-control-plane/envoy/type/matcher/v3"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/types/known/durationpb"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+)
+
+func staticCluster(name string, host string, port uint32) *clusterv3.Cluster {
+	return &clusterv3.Cluster{
+		Name:                 name,
+		ClusterDiscoveryType: &clusterv3.Cluster_Type{Type: clusterv3.Cluster_STRICT_DNS},
+		LoadAssignment:       loadAssignment(name, host, port),
+	}
+}
+
+func edsCluster(name string) *clusterv3.Cluster {
+	return &clusterv3.Cluster{
+		Name:                 name,
+		ClusterDiscoveryType: &clusterv3.Cluster_Type{Type: clusterv3.Cluster_EDS},
+		EdsClusterConfig: &clusterv3.Cluster_EdsClusterConfig{
+			EdsConfig: &corev3.ConfigSource{ConfigSourceSpecifier: &corev3.ConfigSource_Ads{Ads: &corev3.AggregatedConfigSource{}}},
+		},
+	}
+}
+
+func loadAssignment(name string, host string, port uint32) *endpointv3.ClusterLoadAssignment {
+	return &endpointv3.ClusterLoadAssignment{
+		ClusterName: name,
+		Endpoints: []*endpointv3.LocalityLbEndpoints{{
+			LbEndpoints: []*endpointv3.LbEndpoint{{
+				HostIdentifier: &endpointv3.LbEndpoint_Endpoint{Endpoint: &endpointv3.Endpoint{
+					Address: &corev3.Address{Address: &corev3.Address_SocketAddress{SocketAddress: &corev3.SocketAddress{
+						Address:       host,
+						PortSpecifier: &corev3.SocketAddress_PortValue{PortValue: port},
+					}}},
+				}},
+			}},
+		}},
+	}
+}
+
+func pathRoute(path string, method string, cluster string) *routev3.Route {
+	match := &routev3.RouteMatch{PathSpecifier: &routev3.RouteMatch_Path{Path: path}}
+	if method != "" {
+		match.Headers = []*routev3.HeaderMatcher{{
+			Name: ":method",
+			HeaderMatchSpecifier: &routev3.HeaderMatcher_StringMatch{StringMatch: &matcherv3.StringMatcher{
+				MatchPattern: &matcherv3.StringMatcher_Exact{Exact: method},
+			}},
+		}}
+	}
+	return &routev3.Route{
+		Match:  match,
+		Action: &routev3.Route_Route{Route: &routev3.RouteAction{ClusterSpecifier: &routev3.RouteAction_Cluster{Cluster: cluster}}},
+	}
+}
+
+func routeConfiguration(name string, routes ...*routev3.Route) *routev3.RouteConfiguration {
+	return &routev3.RouteConfiguration{
+		Name:         name,
+		VirtualHosts: []*routev3.VirtualHost{{Name: "gateway", Domains: []string{"*"}, Routes: routes}},
+	}
+}
+
+func TestTranslate(t *testing.T) {
+	users := pathRoute("/api/v1/users", "GET", "users")
+	users.GetRoute().Timeout = durationpb.New(2500 * time.Millisecond)
+	users.GetRoute().RetryPolicy = &routev3.RetryPolicy{
+		NumRetries:   wrapperspb.UInt32(3),
+		RetryBackOff: &routev3.RetryPolicy_RetryBackOff{BaseInterval: durationpb.New(250 * time.Millisecond)},
+	}
+	prefix := &routev3.Route{
+		Name:   "catch-all",
+		Match:  &routev3.RouteMatch{PathSpecifier: &routev3.RouteMatch_Prefix{Prefix: "/"}},
+		Action: &routev3.Route_Route{Route: &routev3.RouteAction{ClusterSpecifier: &routev3.RouteAction_Cluster{Cluster: "users"}}},
+	}
+
+	tlsCluster := staticCluster("billing", "billing.internal", 443)
+	tlsCluster.TransportSocket = &corev3.TransportSocket{Name: "envoy.transport_sockets.tls"}
+
+	services, skipped := translate(resources{
+		clusters: map[string]*clusterv3.Cluster{
+			"users":   staticCluster("users", "10.0.0.1", 8080),
+			"orders":  edsCluster("orders"),
+			"billing": tlsCluster,
+			"stock":   edsCluster("stock"),
+		},
+		assignments: map[string]*endpointv3.ClusterLoadAssignment{
+			"orders": loadAssignment("orders", "10.0.0.2", 9090),
+		},
+		routes: map[string]*routev3.RouteConfiguration{
+			"gateway": routeConfiguration("gateway",
+				users,
+				pathRoute("/api/v1/users", "POST", "users"),
+				pathRoute("/api/v1/orders", "", "orders"),
+				pathRoute("/api/v1/invoices", "GET", "billing"),
+				pathRoute("/api/v1/stock", "GET", "stock"),
+				prefix,
+			),
+		},
+	})
+
+	// 1. One service per cluster, with the routes to it as endpoints
+	require.Len(t, services, 3)
+	assert.Equal(t, "xds-billing", services[0].ID)
+	assert.Equal(t, "https://billing.internal:443", services[0].BaseURL)
+	assert.Equal(t, "http://10.0.0.2:9090", services[1].BaseURL)
+	assert.Equal(t, allMethods, services[1].Endpoints[0].Methods)
+
+	// 2. Routes of the same path are merged, and the first one's settings apply
+	require.Len(t, services[2].Endpoints, 1)
+	endpoint := services[2].Endpoints[0]
+	assert.Equal(t, "http://10.0.0.1:8080", services[2].BaseURL)
+	assert.Equal(t, []string{"GET", "POST"}, endpoint.Methods)
+	assert.Equal(t, 3, endpoint.Timeout)
+	assert.Equal(t, 3, endpoint.RetryCount)
+	assert.Equal(t, 250, endpoint.RetryDelay)
+	for _, service := range services {
+		assert.NoError(t, service.Validate())
+	}
+
+	// 3. What the gateway cannot represent is reported
+	assert.Equal(t, []string{
+		"route gateway/gateway[4]: cluster stock: load assignment stock not received",
+		"route catch-all: only exact path matches are supported",
+	}, skipped)
+}
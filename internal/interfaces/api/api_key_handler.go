@@ -0,0 +1,106 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/gorilla/mux"
+
+	"api-gateway-sample/internal/application/dto"
+	"api-gateway-sample/internal/application/usecase"
+	"api-gateway-sample/pkg/errors"
+)
+
+// APIKeyHandler handles HTTP requests for reviewing API key requests
+type APIKeyHandler struct {
+	apiKeyUseCase *usecase.APIKeyUseCase
+}
+
+// NewAPIKeyHandler creates a new APIKeyHandler instance
+func NewAPIKeyHandler(apiKeyUseCase *usecase.APIKeyUseCase) *APIKeyHandler {
+	return &APIKeyHandler{
+		apiKeyUseCase: apiKeyUseCase,
+	}
+}
+
+// RegisterRoutes registers the API key review routes
+func (h *APIKeyHandler) RegisterRoutes(router *mux.Router) {
+	router.HandleFunc("/api-keys", h.ListKeys).Methods(http.MethodGet)
+	router.HandleFunc("/api-keys/{id}", h.GetKey).Methods(http.MethodGet)
+	router.HandleFunc("/api-keys/{id}/approve", h.ApproveKey).Methods(http.MethodPost)
+	router.HandleFunc("/api-keys/{id}/reject", h.RejectKey).Methods(http.MethodPost)
+}
+
+// ListKeys handles API key listing requests, optionally filtered by ?status=
+func (h *APIKeyHandler) ListKeys(w http.ResponseWriter, r *http.Request) {
+	keys, err := h.apiKeyUseCase.ListKeys(r.Context(), r.URL.Query().Get("status"))
+	if err != nil {
+		writeProblem(w, r, errors.StatusProblem(http.StatusInternalServerError, "Failed to list API keys"))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(keys)
+}
+
+// GetKey handles API key retrieval requests
+func (h *APIKeyHandler) GetKey(w http.ResponseWriter, r *http.Request) {
+	key, err := h.apiKeyUseCase.GetKey(r.Context(), mux.Vars(r)["id"])
+	if err != nil {
+		h.writeError(w, r, err, "Failed to get API key")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(key)
+}
+
+// ApproveKey handles API key approval requests
+func (h *APIKeyHandler) ApproveKey(w http.ResponseWriter, r *http.Request) {
+	var req dto.ApproveAPIKeyRequest
+	// The plan is optional, so an empty body is accepted
+	if r.ContentLength != 0 && !decodeRequest(w, r, &req) {
+		return
+	}
+
+	key, err := h.apiKeyUseCase.ApproveKey(r.Context(), mux.Vars(r)["id"], &req)
+	if err != nil {
+		h.writeError(w, r, err, "Failed to approve API key")
+		return
+	}
+
+	// The response may carry the only copy of the key
+	w.Header().Set("Cache-Control", "no-store")
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(key)
+}
+
+// RejectKey handles API key rejection requests
+func (h *APIKeyHandler) RejectKey(w http.ResponseWriter, r *http.Request) {
+	var req dto.RejectAPIKeyRequest
+	// The reason is optional, so an empty body is accepted
+	if r.ContentLength != 0 && !decodeRequest(w, r, &req) {
+		return
+	}
+
+	key, err := h.apiKeyUseCase.RejectKey(r.Context(), mux.Vars(r)["id"], &req)
+	if err != nil {
+		h.writeError(w, r, err, "Failed to reject API key")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(key)
+}
+
+// writeError maps review errors to HTTP responses
+func (h *APIKeyHandler) writeError(w http.ResponseWriter, r *http.Request, err error, message string) {
+	switch {
+	case errors.IsNotFound(err):
+		writeProblem(w, r, errors.StatusProblem(http.StatusNotFound, "API key not found"))
+	case errors.IsAlreadyExists(err):
+		writeProblem(w, r, errors.StatusProblem(http.StatusConflict, "API key request has already been reviewed"))
+	default:
+		writeProblem(w, r, errors.StatusProblem(http.StatusInternalServerError, message))
+	}
+}
@@ -0,0 +1,141 @@
+package api
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"api-gateway-sample/internal/domain/entity"
+)
+
+const (
+	// debugHeader carries "<unix timestamp>.<hex HMAC-SHA256 of the method, path and timestamp>"
+	debugHeader = "X-Gateway-Debug"
+	// debugHeaderPrefix prefixes the timing breakdown headers
+	debugHeaderPrefix = "X-Gateway-Debug-"
+)
+
+// debugMiddleware attaches a request trace when the request carries a valid
+// admin-signed debug header or is picked by traffic sampling
+func (r *Router) debugMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		signed := r.verifyDebugHeader(req, time.Now())
+		sampled := r.config.Debug.SampleRate > 0 && rand.Float64() < r.config.Debug.SampleRate
+		// Never forward the signature to upstream services
+		req.Header.Del(debugHeader)
+
+		if !signed && !sampled {
+			next.ServeHTTP(w, req)
+			return
+		}
+
+		trace := entity.NewRequestTrace()
+		req = req.WithContext(entity.ContextWithTrace(req.Context(), trace))
+
+		if signed {
+			w = &debugResponseWriter{ResponseWriter: w, trace: trace}
+		}
+
+		start := time.Now()
+		next.ServeHTTP(w, req)
+
+		if sampled {
+			r.requestLogger(req).Info("Sampled request trace", traceLogFields(req, trace, time.Since(start))...)
+		}
+	})
+}
+
+// verifyDebugHeader checks the HMAC signature and freshness of the debug header of a request.
+// The signature covers the method and path, so that a header seen on one request cannot turn on
+// debugging for other routes while it is fresh.
+func (r *Router) verifyDebugHeader(req *http.Request, now time.Time) bool {
+	value := req.Header.Get(debugHeader)
+	if value == "" || r.config.Debug.Secret == "" {
+		return false
+	}
+
+	timestamp, signature, ok := strings.Cut(value, ".")
+	if !ok {
+		return false
+	}
+
+	unix, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil {
+		return false
+	}
+	age := now.Sub(time.Unix(unix, 0))
+	if age < -r.config.Debug.MaxClockSkew || age > r.config.Debug.MaxClockSkew {
+		return false
+	}
+
+	expected, err := hex.DecodeString(signature)
+	if err != nil {
+		return false
+	}
+	return hmac.Equal(expected, signDebugRequest(r.config.Debug.Secret, req.Method, req.URL.Path, timestamp))
+}
+
+// signDebugRequest computes the debug header signature for a request at a timestamp
+func signDebugRequest(secret, method, path, timestamp string) []byte {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(method + "\n" + path + "\n" + timestamp))
+	return mac.Sum(nil)
+}
+
+// debugResponseWriter adds the timing breakdown headers right before the response headers are sent
+type debugResponseWriter struct {
+	http.ResponseWriter
+	trace       *entity.RequestTrace
+	wroteHeader bool
+}
+
+func (w *debugResponseWriter) WriteHeader(status int) {
+	if !w.wroteHeader {
+		w.wroteHeader = true
+		writeTraceHeaders(w.Header(), w.trace)
+	}
+	w.ResponseWriter.WriteHeader(status)
+}
+
+func (w *debugResponseWriter) Write(b []byte) (int, error) {
+	if !w.wroteHeader {
+		w.WriteHeader(http.StatusOK)
+	}
+	return w.ResponseWriter.Write(b)
+}
+
+func writeTraceHeaders(header http.Header, trace *entity.RequestTrace) {
+	order, phases := trace.Phases()
+	for _, phase := range order {
+		header.Set(debugHeaderPrefix+phase+"-Ms", formatMillis(phases[phase]))
+	}
+	header.Set(debugHeaderPrefix+"Cache", trace.CacheStatus)
+	if trace.Target != "" {
+		header.Set(debugHeaderPrefix+"Target", trace.Target)
+	}
+}
+
+func traceLogFields(req *http.Request, trace *entity.RequestTrace, total time.Duration) []interface{} {
+	fields := []interface{}{
+		"method", req.Method,
+		"path", req.URL.Path,
+		"total_ms", formatMillis(total),
+		"cache", trace.CacheStatus,
+		"target", trace.Target,
+	}
+	order, phases := trace.Phases()
+	for _, phase := range order {
+		fields = append(fields, phase+"_ms", formatMillis(phases[phase]))
+	}
+	return append(fields, routeLogFields(entity.RouteFromContext(req.Context()))...)
+}
+
+func formatMillis(d time.Duration) string {
+	return fmt.Sprintf("%.2f", float64(d)/float64(time.Millisecond))
+}
@@ -0,0 +1,68 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/gorilla/mux"
+
+	"api-gateway-sample/internal/application/dto"
+	"api-gateway-sample/internal/application/usecase"
+	"api-gateway-sample/pkg/errors"
+)
+
+// DesiredStateHandler handles HTTP requests that declare the complete set of services
+type DesiredStateHandler struct {
+	serviceUseCase *usecase.ServiceUseCase
+}
+
+// NewDesiredStateHandler creates a new DesiredStateHandler instance
+func NewDesiredStateHandler(serviceUseCase *usecase.ServiceUseCase) *DesiredStateHandler {
+	return &DesiredStateHandler{
+		serviceUseCase: serviceUseCase,
+	}
+}
+
+// RegisterRoutes registers the desired state routes
+func (h *DesiredStateHandler) RegisterRoutes(router *mux.Router) {
+	router.HandleFunc("/desired-state", h.SyncServices).Methods(http.MethodPut)
+}
+
+// SyncServices handles requests to make the services match a desired state. With the dry_run
+// query parameter, or dryRun, the drift is only reported.
+func (h *DesiredStateHandler) SyncServices(w http.ResponseWriter, r *http.Request) {
+	dryRun, ok := dryRunParam(w, r, "dry_run", "dryRun")
+	if !ok {
+		return
+	}
+
+	var req dto.DesiredStateRequest
+	if !decodeRequest(w, r, &req) {
+		return
+	}
+
+	report, err := h.serviceUseCase.SyncServices(r.Context(), &req, dryRun)
+	if err != nil {
+		if errors.IsInvalidInput(err) {
+			writeProblem(w, r, errors.StatusProblem(http.StatusBadRequest, err.Error()))
+			return
+		}
+		if errors.IsRouteConflict(err) {
+			writeProblem(w, r, errors.ProblemOf(err, http.StatusConflict))
+			return
+		}
+		if errors.IsPreconditionFailed(err) || errors.IsAlreadyExists(err) {
+			writeProblem(w, r, errors.StatusProblem(http.StatusConflict, "Services were modified during the sync, retry"))
+			return
+		}
+		if errors.IsForbidden(err) {
+			writeProblem(w, r, errors.StatusProblem(http.StatusForbidden, "Service is managed by the xDS control plane"))
+			return
+		}
+		writeProblem(w, r, errors.StatusProblem(http.StatusInternalServerError, "Failed to sync services"))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(report)
+}
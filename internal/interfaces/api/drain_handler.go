@@ -0,0 +1,44 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/gorilla/mux"
+
+	"api-gateway-sample/internal/application/usecase"
+	"api-gateway-sample/pkg/errors"
+)
+
+// DrainHandler handles HTTP requests for the drain status of service upstreams
+type DrainHandler struct {
+	drainUseCase *usecase.UpstreamDrainUseCase
+}
+
+// NewDrainHandler creates a new DrainHandler instance
+func NewDrainHandler(drainUseCase *usecase.UpstreamDrainUseCase) *DrainHandler {
+	return &DrainHandler{
+		drainUseCase: drainUseCase,
+	}
+}
+
+// RegisterRoutes registers the drain status routes
+func (h *DrainHandler) RegisterRoutes(router *mux.Router) {
+	router.HandleFunc("/services/{id}/drain", h.GetDrainStatus).Methods(http.MethodGet)
+}
+
+// GetDrainStatus handles drain status requests
+func (h *DrainHandler) GetDrainStatus(w http.ResponseWriter, r *http.Request) {
+	status, err := h.drainUseCase.DrainStatus(r.Context(), mux.Vars(r)["id"])
+	if err != nil {
+		if errors.IsNotFound(err) {
+			writeProblem(w, r, errors.StatusProblem(http.StatusNotFound, "Service not found"))
+			return
+		}
+		writeProblem(w, r, errors.StatusProblem(http.StatusInternalServerError, "Failed to get drain status"))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(status)
+}
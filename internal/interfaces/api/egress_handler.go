@@ -0,0 +1,114 @@
+package api
+
+import (
+	"io"
+	"net"
+	"net/http"
+	"time"
+
+	"api-gateway-sample/internal/application/usecase"
+	"api-gateway-sample/internal/domain/entity"
+	"api-gateway-sample/pkg/bufferpool"
+	"api-gateway-sample/pkg/errors"
+	"api-gateway-sample/pkg/logger"
+)
+
+// tunnelDialTimeout bounds connecting to the destination of a tunnel
+const tunnelDialTimeout = 10 * time.Second
+
+// EgressHandler serves the egress listener as an HTTP forward proxy. Clients send plain requests
+// with an absolute URL, which the gateway forwards over the destination's scheme with its
+// credentials, or open CONNECT tunnels to destinations without credentials.
+type EgressHandler struct {
+	egressUseCase *usecase.EgressUseCase
+	logger        logger.Logger
+}
+
+// NewEgressHandler creates a new EgressHandler instance
+func NewEgressHandler(egressUseCase *usecase.EgressUseCase, logger logger.Logger) *EgressHandler {
+	return &EgressHandler{
+		egressUseCase: egressUseCase,
+		logger:        logger,
+	}
+}
+
+// ServeHTTP handles egress requests
+func (h *EgressHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method == http.MethodConnect {
+		h.tunnel(w, r)
+		return
+	}
+	if !r.URL.IsAbs() {
+		writeProblem(w, r, errors.StatusProblem(http.StatusBadRequest, "Egress requests must use an absolute URL"))
+		return
+	}
+
+	body, err := bufferpool.ReadAll(r.Body)
+	if err != nil {
+		writeProblem(w, r, errors.StatusProblem(http.StatusBadRequest, "Failed to read request body"))
+		return
+	}
+	request := entity.NewRequest(r.Method, r.URL.Path, r.Header, r.URL.Query(), body, r.RemoteAddr)
+
+	response, err := h.egressUseCase.Forward(r.Context(), r.URL.Host, request)
+	if err != nil {
+		writeProblem(w, r, errors.ProblemOf(err, errors.StatusCodeOf(err, http.StatusBadGateway)))
+		return
+	}
+
+	for key, values := range response.Headers {
+		for _, value := range values {
+			w.Header().Add(key, value)
+		}
+	}
+	w.WriteHeader(response.StatusCode)
+	w.Write(response.Body)
+}
+
+// tunnel relays a CONNECT tunnel between the client and an allowed destination
+func (h *EgressHandler) tunnel(w http.ResponseWriter, r *http.Request) {
+	if err := h.egressUseCase.OpenTunnel(r.Context(), r.Host, r.RemoteAddr); err != nil {
+		writeProblem(w, r, errors.ProblemOf(err, errors.StatusCodeOf(err, http.StatusBadGateway)))
+		return
+	}
+
+	hijacker, ok := w.(http.Hijacker)
+	if !ok {
+		writeProblem(w, r, errors.StatusProblem(http.StatusInternalServerError, "Tunnels are not supported over this connection"))
+		return
+	}
+	upstream, err := net.DialTimeout("tcp", r.Host, tunnelDialTimeout)
+	if err != nil {
+		logger.FromContextOr(r.Context(), h.logger).Warn("Failed to connect egress tunnel", "host", r.Host, "error", err)
+		writeProblem(w, r, errors.StatusProblem(http.StatusBadGateway, "Failed to reach "+r.Host))
+		return
+	}
+	defer upstream.Close()
+
+	client, buffered, err := hijacker.Hijack()
+	if err != nil {
+		return
+	}
+	defer client.Close()
+	// The server's timeouts apply to requests, not to the tunnel that replaces them
+	client.SetDeadline(time.Time{})
+	if _, err := client.Write([]byte("HTTP/1.1 200 Connection Established\r\n\r\n")); err != nil {
+		return
+	}
+
+	// Either direction finishing ends the tunnel; closing both unblocks the other copy
+	done := make(chan struct{}, 2)
+	go func() {
+		// The client may have sent bytes that the server already buffered
+		io.Copy(upstream, buffered)
+		done <- struct{}{}
+	}()
+	go func() {
+		io.Copy(client, upstream)
+		done <- struct{}{}
+	}()
+	<-done
+	client.Close()
+	upstream.Close()
+	<-done
+}
@@ -0,0 +1,47 @@
+package api
+
+import (
+	"net/http"
+	"strings"
+
+	"api-gateway-sample/pkg/errors"
+)
+
+// proxyPathPrefix is the prefix of the paths proxied to services
+const proxyPathPrefix = "/api/v1/"
+
+// endpointConstraintsMiddleware rejects proxied requests the matched endpoint does not accept,
+// before they are authenticated or reach the service: methods the path is not served with get
+// 405 with the Allow header, and bodies of a content type the endpoint does not list get 415.
+// Paths no endpoint serves are left to the proxy, which answers them with 404.
+func (r *Router) endpointConstraintsMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if r.proxyUseCase == nil || !strings.HasPrefix(req.URL.Path, proxyPathPrefix) {
+			next.ServeHTTP(w, req)
+			return
+		}
+
+		_, endpoint, err := r.proxyUseCase.ResolveEndpoint(req.Context(), req.URL.Path, req.Method)
+		if err == errors.ErrServiceNotFound {
+			if methods := r.routeMethods(req); len(methods) > 0 {
+				w.Header().Set("Allow", strings.Join(methods, ", "))
+				r.writeError(w, req, errors.NewProblem(errors.ProblemMethodNotAllowed, req.Method+" is not allowed on "+req.URL.Path))
+				return
+			}
+		}
+		if err != nil {
+			next.ServeHTTP(w, req)
+			return
+		}
+
+		contentType := req.Header.Get("Content-Type")
+		hasBody := req.ContentLength != 0 || contentType != ""
+		if hasBody && !endpoint.AcceptsContentType(contentType) {
+			r.writeError(w, req, errors.NewProblem(errors.ProblemUnsupportedMediaType,
+				"Content-Type "+contentType+" is not accepted, expected "+strings.Join(endpoint.ContentTypes, ", ")))
+			return
+		}
+
+		next.ServeHTTP(w, req)
+	})
+}
@@ -0,0 +1,106 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"api-gateway-sample/internal/application/usecase"
+	"api-gateway-sample/internal/domain/entity"
+	repomock "api-gateway-sample/internal/domain/repository/mock"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEndpointConstraintsMiddleware(t *testing.T) {
+	serviceRepo := repomock.NewServiceRepositoryMock()
+	err := serviceRepo.Create(context.Background(), &entity.Service{
+		ID:      "orders",
+		Name:    "orders-service",
+		BaseURL: "http://orders-service:8080",
+		Endpoints: []entity.Endpoint{
+			{Path: "/api/v1/orders", Methods: []string{http.MethodGet}, AuthRequired: true},
+			{Path: "/api/v1/orders", Methods: []string{http.MethodPost}, AuthRequired: true, ContentTypes: []string{"application/json", "text/*"}},
+			{Path: "/api/v1/orders/*", Methods: []string{http.MethodDelete}, AuthRequired: true},
+		},
+	})
+	assert.NoError(t, err)
+
+	router := &Router{
+		logger:       &MockLogger{},
+		proxyUseCase: usecase.NewProxyUseCase(serviceRepo, nil, nil, nil, nil, &MockLogger{}),
+	}
+	testHandler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+	// Requests are checked before they are authenticated
+	handler := router.endpointConstraintsMiddleware(router.authMiddleware(testHandler))
+
+	testCases := []struct {
+		name           string
+		method         string
+		path           string
+		contentType    string
+		body           string
+		expectedStatus int
+		expectedAllow  string
+	}{
+		{name: "Served method", method: http.MethodGet, path: "/api/v1/orders", expectedStatus: http.StatusUnauthorized},
+		{name: "Method served by another endpoint", method: http.MethodPut, path: "/api/v1/orders", expectedStatus: http.StatusMethodNotAllowed, expectedAllow: "GET, HEAD, POST, OPTIONS"},
+		{name: "Method of a prefix endpoint", method: http.MethodPatch, path: "/api/v1/orders/1", expectedStatus: http.StatusMethodNotAllowed, expectedAllow: "DELETE, OPTIONS"},
+		{name: "Unknown path", method: http.MethodGet, path: "/api/v1/unknown", expectedStatus: http.StatusUnauthorized},
+		{name: "Allowed content type", method: http.MethodPost, path: "/api/v1/orders", contentType: "application/json; charset=utf-8", body: "{}", expectedStatus: http.StatusUnauthorized},
+		{name: "Allowed subtype", method: http.MethodPost, path: "/api/v1/orders", contentType: "text/csv", body: "id", expectedStatus: http.StatusUnauthorized},
+		{name: "Other content type", method: http.MethodPost, path: "/api/v1/orders", contentType: "application/xml", body: "<order/>", expectedStatus: http.StatusUnsupportedMediaType},
+		{name: "Body without content type", method: http.MethodPost, path: "/api/v1/orders", body: "{}", expectedStatus: http.StatusUnsupportedMediaType},
+		{name: "No body", method: http.MethodPost, path: "/api/v1/orders", expectedStatus: http.StatusUnauthorized},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			req := httptest.NewRequest(tc.method, tc.path, strings.NewReader(tc.body))
+			if tc.contentType != "" {
+				req.Header.Set("Content-Type", tc.contentType)
+			}
+			rr := httptest.NewRecorder()
+			handler.ServeHTTP(rr, req)
+
+			assert.Equal(t, tc.expectedStatus, rr.Code)
+			assert.Equal(t, tc.expectedAllow, rr.Header().Get("Allow"))
+		})
+	}
+}
+
+func TestCorsMiddleware_RouteMethods(t *testing.T) {
+	serviceRepo := repomock.NewServiceRepositoryMock()
+	err := serviceRepo.Create(context.Background(), &entity.Service{
+		ID:        "orders",
+		Name:      "orders-service",
+		BaseURL:   "http://orders-service:8080",
+		Endpoints: []entity.Endpoint{{Path: "/api/v1/orders/*", Methods: []string{http.MethodGet, http.MethodPatch}, AuthRequired: true}},
+	})
+	assert.NoError(t, err)
+
+	router := &Router{
+		logger:       &MockLogger{},
+		proxyUseCase: usecase.NewProxyUseCase(serviceRepo, nil, nil, nil, nil, &MockLogger{}),
+	}
+	handler := router.corsMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Error("Expected OPTIONS to be answered by the gateway")
+	}))
+
+	// Proxied paths list the methods of their endpoints, without authentication
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, httptest.NewRequest(http.MethodOptions, "/api/v1/orders/1", nil))
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Equal(t, "GET, HEAD, PATCH, OPTIONS", rr.Header().Get("Allow"))
+	assert.Equal(t, "GET, HEAD, PATCH, OPTIONS", rr.Header().Get("Access-Control-Allow-Methods"))
+
+	// Other paths keep the default methods
+	rr = httptest.NewRecorder()
+	handler.ServeHTTP(rr, httptest.NewRequest(http.MethodOptions, "/api/v1/unknown", nil))
+	assert.Empty(t, rr.Header().Get("Allow"))
+	assert.Equal(t, "GET, POST, PUT, DELETE, OPTIONS", rr.Header().Get("Access-Control-Allow-Methods"))
+}
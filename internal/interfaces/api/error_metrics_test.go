@@ -0,0 +1,38 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"api-gateway-sample/internal/application/usecase"
+	repomock "api-gateway-sample/internal/domain/repository/mock"
+	"api-gateway-sample/internal/infrastructure/metrics"
+	"api-gateway-sample/pkg/errors"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHandler_ErrorsAreCountedByCode(t *testing.T) {
+	stats := usecase.NewStatsUseCase(repomock.NewServiceRepositoryMock(), metrics.NewSlidingWindowAggregator(time.Minute, 6), &MockLogger{})
+	handler := &Handler{statsUseCase: stats, logger: &MockLogger{}}
+	router := &Router{handler: handler}
+
+	fail := func(err error, status int) {
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/orders", nil)
+		handler.handleError(httptest.NewRecorder(), req, err, errors.StatusCodeOf(err, status))
+	}
+	fail(errors.NewError(errors.CodeTimeout, "upstream timed out", errors.ErrTimeout), http.StatusInternalServerError)
+	fail(errors.NewError(errors.CodeTimeout, "upstream timed out", errors.ErrTimeout), http.StatusInternalServerError)
+	fail(errors.NewTypedError(errors.ProblemRouteNotFound, "no route", errors.ErrNotFound), http.StatusInternalServerError)
+	// Errors written by the router's middlewares are counted alike
+	router.writeError(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil), errors.NewProblem(errors.ProblemUnauthorized, "Invalid token"))
+
+	rr := httptest.NewRecorder()
+	handler.MetricsHandler(rr, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	body := rr.Body.String()
+	assert.Contains(t, body, `gateway_errors_total{code="upstream-timeout",status="504",retryable="true"} 2`)
+	assert.Contains(t, body, `gateway_errors_total{code="route-not-found",status="404",retryable="false"} 1`)
+	assert.Contains(t, body, `gateway_errors_total{code="unauthorized",status="401",retryable="false"} 1`)
+}
@@ -0,0 +1,116 @@
+package api
+
+import (
+	"encoding/json"
+	"html"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"api-gateway-sample/internal/application/usecase"
+	"api-gateway-sample/internal/domain/entity"
+	"api-gateway-sample/pkg/errors"
+	"api-gateway-sample/pkg/i18n"
+)
+
+// errorPages renders the errors the gateway generates on proxied routes
+type errorPages struct {
+	templates  []entity.ErrorTemplate
+	translator *i18n.Translator
+}
+
+// SetErrorTemplates sets the templates of the errors the gateway generates, used for the
+// statuses the requested service has no template of its own for
+func (h *Handler) SetErrorTemplates(templates []entity.ErrorTemplate) {
+	h.errorPages.templates = templates
+}
+
+// SetTranslator translates the errors the gateway generates on proxied routes into the
+// language of the client's Accept-Language
+func (h *Handler) SetTranslator(translator *i18n.Translator) {
+	h.errorPages.translator = translator
+}
+
+// writeError writes an error generated by the gateway on behalf of a router middleware
+func (r *Router) writeError(w http.ResponseWriter, req *http.Request, problem *errors.Problem) {
+	var pages errorPages
+	if r.handler != nil {
+		pages = r.handler.errorPages
+		r.handler.recordError(problem)
+	}
+	pages.write(w, req, r.proxyUseCase, problem)
+}
+
+// write writes an error generated by the gateway on a proxied route, translated into the
+// client's language. The template of the requested service applies first, then the global
+// templates; without any, the error is written as problem details.
+func (p errorPages) write(w http.ResponseWriter, req *http.Request, proxyUseCase *usecase.ProxyUseCase, problem *errors.Problem) {
+	if p.translator != nil {
+		locale := p.translator.Locale(req.Header.Get("Accept-Language"))
+		problem.Title = p.translator.Title(locale, problem.Name(), problem.Title)
+		problem.Detail = p.translator.Message(locale, problem.Detail, problem.MessageID, problem.Name())
+		w.Header().Set("Content-Language", locale)
+		w.Header().Add("Vary", "Accept-Language")
+	}
+
+	values := map[string]string{
+		"error.status":    strconv.Itoa(problem.Status),
+		"error.type":      problem.Type,
+		"error.code":      problem.Code(),
+		"error.retryable": strconv.FormatBool(problem.Retryable),
+		"error.title":     problem.Title,
+		"error.message":   problem.Detail,
+		"request.id":      req.Header.Get("X-Request-ID"),
+		"request.method":  req.Method,
+		"request.path":    req.URL.Path,
+	}
+
+	template := entity.SelectErrorTemplate(p.templates, problem.Status)
+	if proxyUseCase != nil {
+		if service, _, err := proxyUseCase.ResolveEndpoint(req.Context(), req.URL.Path, req.Method); err == nil {
+			values["service.name"] = service.Name
+			problem.With("service", service.Name)
+			if serviceTemplate := entity.SelectErrorTemplate(service.ErrorTemplates, problem.Status); serviceTemplate != nil {
+				template = serviceTemplate
+			}
+		}
+	}
+
+	if template == nil {
+		writeProblem(w, req, problem)
+		return
+	}
+
+	// Values are escaped so that they cannot break the document or inject markup
+	escape := func(value string) string { return value }
+	switch {
+	case strings.Contains(template.ContentType, "json"):
+		escape = func(value string) string {
+			quoted, _ := json.Marshal(value)
+			return string(quoted[1 : len(quoted)-1])
+		}
+	case strings.Contains(template.ContentType, "html"), strings.Contains(template.ContentType, "xml"):
+		escape = html.EscapeString
+	}
+	body, _ := entity.ExpandPipelineTemplate(template.Body, func(reference string) (string, error) {
+		return escape(values[reference]), nil
+	})
+
+	w.Header().Set("Content-Type", template.ContentType)
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.WriteHeader(problem.Status)
+	w.Write([]byte(body))
+}
+
+// writeProblem writes an error as problem details, identified by the request path and ID
+func writeProblem(w http.ResponseWriter, r *http.Request, problem *errors.Problem) {
+	problem.Instance = r.URL.Path
+	if requestID := r.Header.Get("X-Request-ID"); requestID != "" {
+		problem.With("requestId", requestID)
+	}
+
+	w.Header().Set("Content-Type", errors.ProblemContentType)
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.WriteHeader(problem.Status)
+	json.NewEncoder(w).Encode(problem)
+}
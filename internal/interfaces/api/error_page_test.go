@@ -0,0 +1,169 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"api-gateway-sample/internal/application/usecase"
+	"api-gateway-sample/internal/domain/entity"
+	repomock "api-gateway-sample/internal/domain/repository/mock"
+	"api-gateway-sample/pkg/errors"
+	"api-gateway-sample/pkg/i18n"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWriteErrorPage(t *testing.T) {
+	serviceRepo := repomock.NewServiceRepositoryMock()
+	service := entity.NewService("orders-id", "orders", "1.0.0", "", "http://orders:8080", 30, 3)
+	service.AddEndpoint(entity.Endpoint{Path: "/api/v1/orders", Methods: []string{http.MethodGet}})
+	service.ErrorTemplates = []entity.ErrorTemplate{
+		{Status: "401", ContentType: "application/json", Body: `{"code":{{error.status}},"service":"{{service.name}}","message":"{{error.message}}"}`},
+	}
+	assert.NoError(t, serviceRepo.Create(context.Background(), service))
+
+	router := &Router{
+		logger:       &MockLogger{},
+		handler:      &Handler{},
+		proxyUseCase: usecase.NewProxyUseCase(serviceRepo, nil, nil, nil, nil, &MockLogger{}),
+	}
+	router.handler.SetErrorTemplates([]entity.ErrorTemplate{
+		{Status: "5xx", ContentType: "text/html", Body: "<h1>{{error.status}}</h1><p>{{error.message}}</p><p>{{request.id}}</p>"},
+		{ContentType: "text/plain", Body: "{{request.method}} {{request.path}}: {{error.message}}"},
+	})
+
+	testCases := []struct {
+		name                string
+		path                string
+		problem             *errors.Problem
+		expectedContentType string
+		expectedBody        string
+	}{
+		{
+			name:                "Service template",
+			path:                "/api/v1/orders",
+			problem:             errors.NewProblem(errors.ProblemUnauthorized, `Invalid "token"`),
+			expectedContentType: "application/json",
+			expectedBody:        `{"code":401,"service":"orders","message":"Invalid \"token\""}`,
+		},
+		{
+			name:                "Global template for the class",
+			path:                "/api/v1/orders",
+			problem:             errors.NewProblem(errors.ProblemUpstreamFailed, "<unreachable>"),
+			expectedContentType: "text/html",
+			expectedBody:        "<h1>502</h1><p>&lt;unreachable&gt;</p><p>req-1</p>",
+		},
+		{
+			name:                "Global template without status",
+			path:                "/unknown",
+			problem:             errors.NewProblem(errors.ProblemRouteNotFound, "Not found"),
+			expectedContentType: "text/plain",
+			expectedBody:        "GET /unknown: Not found",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, tc.path, nil)
+			req.Header.Set("X-Request-ID", "req-1")
+			rr := httptest.NewRecorder()
+
+			router.writeError(rr, req, tc.problem)
+
+			assert.Equal(t, tc.problem.Status, rr.Code)
+			assert.Equal(t, tc.expectedContentType, rr.Header().Get("Content-Type"))
+			assert.Equal(t, tc.expectedBody, rr.Body.String())
+		})
+	}
+}
+
+func TestWriteErrorPage_ProblemDetails(t *testing.T) {
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/orders", nil)
+	req.Header.Set("X-Request-ID", "req-1")
+	rr := httptest.NewRecorder()
+
+	(&Router{}).writeError(rr, req, errors.NewProblem(errors.ProblemUnauthorized, "Invalid token"))
+
+	assert.Equal(t, http.StatusUnauthorized, rr.Code)
+	assert.Equal(t, errors.ProblemContentType, rr.Header().Get("Content-Type"))
+	assert.JSONEq(t, `{
+		"type": "urn:api-gateway:problem:unauthorized",
+		"title": "Unauthorized",
+		"status": 401,
+		"code": "unauthorized",
+		"retryable": false,
+		"detail": "Invalid token",
+		"instance": "/api/v1/orders",
+		"requestId": "req-1"
+	}`, rr.Body.String())
+}
+
+func TestWriteErrorPage_Translated(t *testing.T) {
+	translator, err := i18n.NewTranslator("en", map[string]i18n.Catalog{
+		"fr": {
+			Titles:   map[string]string{"unauthorized": "Non autorisé", "rate-limit-exceeded": "Limite dépassée"},
+			Messages: map[string]string{"invalid-token": "Le jeton est invalide", "rate-limit-exceeded": "Réessayez plus tard"},
+		},
+	})
+	require.NoError(t, err)
+	router := &Router{handler: &Handler{}}
+	router.handler.SetTranslator(translator)
+
+	testCases := []struct {
+		name             string
+		acceptLanguage   string
+		problem          *errors.Problem
+		expectedLanguage string
+		expectedTitle    string
+		expectedDetail   string
+	}{
+		{
+			name:             "Message ID",
+			acceptLanguage:   "fr-FR,fr;q=0.9",
+			problem:          errors.NewLocalizedProblem(errors.ProblemUnauthorized, "invalid-token", "Invalid token"),
+			expectedLanguage: "fr",
+			expectedTitle:    "Non autorisé",
+			expectedDetail:   "Le jeton est invalide",
+		},
+		{
+			name:             "Problem type",
+			acceptLanguage:   "fr",
+			problem:          errors.ProblemOf(errors.ErrRateLimitExceeded, http.StatusTooManyRequests),
+			expectedLanguage: "fr",
+			expectedTitle:    "Limite dépassée",
+			expectedDetail:   "Réessayez plus tard",
+		},
+		{
+			name:             "Unsupported language",
+			acceptLanguage:   "de",
+			problem:          errors.NewLocalizedProblem(errors.ProblemUnauthorized, "invalid-token", "Invalid token"),
+			expectedLanguage: "en",
+			expectedTitle:    "Unauthorized",
+			expectedDetail:   "Invalid token",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/api/v1/orders", nil)
+			req.Header.Set("Accept-Language", tc.acceptLanguage)
+			rr := httptest.NewRecorder()
+
+			router.writeError(rr, req, tc.problem)
+
+			assert.Equal(t, tc.expectedLanguage, rr.Header().Get("Content-Language"))
+			assert.Equal(t, "Accept-Language", rr.Header().Get("Vary"))
+			var problem struct {
+				Title  string `json:"title"`
+				Detail string `json:"detail"`
+			}
+			require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &problem))
+			assert.Equal(t, tc.expectedTitle, problem.Title)
+			assert.Equal(t, tc.expectedDetail, problem.Detail)
+		})
+	}
+}
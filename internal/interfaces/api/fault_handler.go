@@ -0,0 +1,69 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/gorilla/mux"
+
+	"api-gateway-sample/internal/application/dto"
+	"api-gateway-sample/internal/application/usecase"
+	"api-gateway-sample/pkg/errors"
+)
+
+// FaultHandler handles HTTP requests for fault injection
+type FaultHandler struct {
+	faultUseCase *usecase.FaultUseCase
+}
+
+// NewFaultHandler creates a new FaultHandler instance
+func NewFaultHandler(faultUseCase *usecase.FaultUseCase) *FaultHandler {
+	return &FaultHandler{
+		faultUseCase: faultUseCase,
+	}
+}
+
+// RegisterRoutes registers the fault injection routes
+func (h *FaultHandler) RegisterRoutes(router *mux.Router) {
+	router.HandleFunc("/faults", h.ListFaults).Methods(http.MethodGet)
+	router.HandleFunc("/services/{id}/faults", h.SetFault).Methods(http.MethodPut)
+	router.HandleFunc("/services/{id}/faults", h.RemoveFaults).Methods(http.MethodDelete)
+}
+
+// ListFaults handles fault listing requests
+func (h *FaultHandler) ListFaults(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(h.faultUseCase.ListFaults())
+}
+
+// SetFault handles requests to inject faults into an endpoint
+func (h *FaultHandler) SetFault(w http.ResponseWriter, r *http.Request) {
+	var req dto.FaultRequest
+	if !decodeRequest(w, r, &req) {
+		return
+	}
+
+	fault := req.ToEntity(mux.Vars(r)["id"])
+	if err := h.faultUseCase.SetFault(r.Context(), fault); err != nil {
+		if errors.IsNotFound(err) {
+			writeProblem(w, r, errors.StatusProblem(http.StatusNotFound, "Service not found"))
+			return
+		}
+		if errors.IsInvalidInput(err) {
+			writeProblem(w, r, errors.StatusProblem(http.StatusBadRequest, err.Error()))
+			return
+		}
+		writeProblem(w, r, errors.StatusProblem(http.StatusInternalServerError, "Failed to set fault"))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(fault)
+}
+
+// RemoveFaults handles requests to stop injecting faults into the endpoint given by the
+// endpoint query parameter, or into every endpoint of the service without it
+func (h *FaultHandler) RemoveFaults(w http.ResponseWriter, r *http.Request) {
+	h.faultUseCase.RemoveFaults(r.Context(), mux.Vars(r)["id"], r.URL.Query().Get("endpoint"))
+	w.WriteHeader(http.StatusNoContent)
+}
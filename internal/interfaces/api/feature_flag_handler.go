@@ -0,0 +1,126 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/gorilla/mux"
+
+	"api-gateway-sample/internal/application/dto"
+	"api-gateway-sample/internal/application/usecase"
+	"api-gateway-sample/pkg/errors"
+)
+
+// FeatureFlagHandler handles HTTP requests for feature flags
+type FeatureFlagHandler struct {
+	flagUseCase *usecase.FeatureFlagUseCase
+}
+
+// NewFeatureFlagHandler creates a new FeatureFlagHandler instance
+func NewFeatureFlagHandler(flagUseCase *usecase.FeatureFlagUseCase) *FeatureFlagHandler {
+	return &FeatureFlagHandler{
+		flagUseCase: flagUseCase,
+	}
+}
+
+// RegisterRoutes registers the feature flag routes
+func (h *FeatureFlagHandler) RegisterRoutes(router *mux.Router) {
+	router.HandleFunc("/feature-flags", h.CreateFlag).Methods(http.MethodPost)
+	router.HandleFunc("/feature-flags", h.ListFlags).Methods(http.MethodGet)
+	router.HandleFunc("/feature-flags/{key}", h.GetFlag).Methods(http.MethodGet)
+	router.HandleFunc("/feature-flags/{key}", h.UpdateFlag).Methods(http.MethodPut)
+	router.HandleFunc("/feature-flags/{key}", h.DeleteFlag).Methods(http.MethodDelete)
+}
+
+// CreateFlag handles feature flag creation requests
+func (h *FeatureFlagHandler) CreateFlag(w http.ResponseWriter, r *http.Request) {
+	var req dto.FeatureFlagRequest
+	if !decodeRequest(w, r, &req) {
+		return
+	}
+
+	flag, err := h.flagUseCase.CreateFlag(r.Context(), &req)
+	if err != nil {
+		h.writeChangeError(w, r, err, "Failed to create feature flag")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(flag)
+}
+
+// GetFlag handles feature flag retrieval requests
+func (h *FeatureFlagHandler) GetFlag(w http.ResponseWriter, r *http.Request) {
+	flag, err := h.flagUseCase.GetFlag(r.Context(), mux.Vars(r)["key"])
+	if err != nil {
+		if errors.IsNotFound(err) {
+			writeProblem(w, r, errors.StatusProblem(http.StatusNotFound, "Feature flag not found"))
+			return
+		}
+		writeProblem(w, r, errors.StatusProblem(http.StatusInternalServerError, "Failed to get feature flag"))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(flag)
+}
+
+// ListFlags handles feature flag listing requests
+func (h *FeatureFlagHandler) ListFlags(w http.ResponseWriter, r *http.Request) {
+	flags, err := h.flagUseCase.ListFlags(r.Context())
+	if err != nil {
+		writeProblem(w, r, errors.StatusProblem(http.StatusInternalServerError, "Failed to list feature flags"))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(flags)
+}
+
+// UpdateFlag handles feature flag update requests
+func (h *FeatureFlagHandler) UpdateFlag(w http.ResponseWriter, r *http.Request) {
+	var req dto.FeatureFlagRequest
+	if !decodeRequest(w, r, &req) {
+		return
+	}
+
+	flag, err := h.flagUseCase.UpdateFlag(r.Context(), mux.Vars(r)["key"], &req)
+	if err != nil {
+		if errors.IsNotFound(err) {
+			writeProblem(w, r, errors.StatusProblem(http.StatusNotFound, "Feature flag not found"))
+			return
+		}
+		h.writeChangeError(w, r, err, "Failed to update feature flag")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(flag)
+}
+
+// DeleteFlag handles feature flag deletion requests
+func (h *FeatureFlagHandler) DeleteFlag(w http.ResponseWriter, r *http.Request) {
+	if err := h.flagUseCase.DeleteFlag(r.Context(), mux.Vars(r)["key"]); err != nil {
+		if errors.IsNotFound(err) {
+			writeProblem(w, r, errors.StatusProblem(http.StatusNotFound, "Feature flag not found"))
+			return
+		}
+		writeProblem(w, r, errors.StatusProblem(http.StatusInternalServerError, "Failed to delete feature flag"))
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// writeChangeError writes the problem for a flag that could not be created or updated
+func (h *FeatureFlagHandler) writeChangeError(w http.ResponseWriter, r *http.Request, err error, message string) {
+	switch {
+	case errors.IsInvalidInput(err):
+		writeProblem(w, r, errors.StatusProblem(http.StatusBadRequest, err.Error()))
+	case errors.IsAlreadyExists(err):
+		writeProblem(w, r, errors.StatusProblem(http.StatusConflict, "A feature flag with this key already exists"))
+	default:
+		writeProblem(w, r, errors.StatusProblem(http.StatusInternalServerError, message))
+	}
+}
@@ -2,11 +2,21 @@ package api
 
 import (
 	"encoding/json"
+	"fmt"
+	"io"
 	"net/http"
+	"net/textproto"
+	"sort"
+	"strconv"
+	"strings"
 
 	"api-gateway-sample/internal/application/usecase"
 	"api-gateway-sample/internal/domain/entity"
+	"api-gateway-sample/pkg/bufferpool"
+	"api-gateway-sample/pkg/errors"
 	"api-gateway-sample/pkg/logger"
+
+	"github.com/gorilla/mux"
 )
 
 // Handler handles HTTP requests
@@ -15,7 +25,11 @@ type Handler struct {
 	authUseCase              *usecase.AuthUseCase
 	rateLimitUseCase         *usecase.RateLimitUseCase
 	serviceManagementUseCase *usecase.ServiceManagementUseCase
+	statsUseCase             *usecase.StatsUseCase
 	logger                   logger.Logger
+	errorPages               errorPages
+	// legacyRateLimitHeaders also reports quotas under the X-RateLimit-* names
+	legacyRateLimitHeaders bool
 }
 
 // NewHandler creates a new Handler instance
@@ -24,6 +38,7 @@ func NewHandler(
 	authUseCase *usecase.AuthUseCase,
 	rateLimitUseCase *usecase.RateLimitUseCase,
 	serviceManagementUseCase *usecase.ServiceManagementUseCase,
+	statsUseCase *usecase.StatsUseCase,
 	logger logger.Logger,
 ) *Handler {
 	return &Handler{
@@ -31,6 +46,7 @@ func NewHandler(
 		authUseCase:              authUseCase,
 		rateLimitUseCase:         rateLimitUseCase,
 		serviceManagementUseCase: serviceManagementUseCase,
+		statsUseCase:             statsUseCase,
 		logger:                   logger,
 	}
 }
@@ -39,28 +55,41 @@ func NewHandler(
 func (h *Handler) ProxyHandler(w http.ResponseWriter, r *http.Request) {
 	// Create request entity
 	request := &entity.Request{
-		ID:          r.Header.Get("X-Request-ID"),
+		ID:          r.Header.Get(requestIDHeader),
 		Method:      r.Method,
 		Path:        r.URL.Path,
 		Headers:     r.Header,
 		QueryParams: r.URL.Query(),
 		ClientIP:    r.RemoteAddr,
+		Host:        r.Host,
+		Scheme:      requestScheme(r),
+	}
+	if principal, ok := entity.PrincipalFromContext(r.Context()); ok {
+		request.SetAuthenticated(true, principal.UserID)
 	}
 
-	// Read request body if present
-	if r.Body != nil {
+	// Read request body if present, unless it is an upload streamed to the service
+	if r.Body != nil && h.proxyUseCase.StreamsUpload(r.Context(), request) {
+		request.BodyStream = r.Body
+	} else if r.Body != nil {
 		body, err := readBody(r)
 		if err != nil {
-			h.handleError(w, err, http.StatusBadRequest)
+			h.handleError(w, r, err, http.StatusBadRequest)
 			return
 		}
 		request.Body = body
 	}
 
-	// Proxy request
-	response, err := h.proxyUseCase.ProxyRequest(r.Context(), request)
+	// Proxy request, collecting the quota left to the client for the response headers
+	rateLimit := entity.NewRateLimitInfo()
+	response, err := h.proxyUseCase.ProxyRequest(entity.ContextWithRateLimit(r.Context(), rateLimit), request)
+	h.writeRateLimitHeaders(w, rateLimit, err)
 	if err != nil {
-		h.handleError(w, err, http.StatusInternalServerError)
+		if errors.IsConnectionReset(err) {
+			// The server closes the connection without writing a response
+			panic(http.ErrAbortHandler)
+		}
+		h.handleError(w, r, err, errors.StatusCodeOf(err, http.StatusInternalServerError))
 		return
 	}
 
@@ -68,38 +97,297 @@ func (h *Handler) ProxyHandler(w http.ResponseWriter, r *http.Request) {
 	h.writeResponse(w, response)
 }
 
-// HealthCheckHandler handles health check requests
+// AsyncResultHandler returns the result of an async request, or its status while it is pending
+func (h *Handler) AsyncResultHandler(w http.ResponseWriter, r *http.Request) {
+	response, err := h.proxyUseCase.AsyncResult(r.Context(), mux.Vars(r)["id"])
+	if err != nil {
+		h.handleError(w, r, err, errors.StatusCodeOf(err, http.StatusInternalServerError))
+		return
+	}
+	h.writeResponse(w, response)
+}
+
+// HealthCheckHandler handles health check requests. An unavailable degradable dependency
+// reports the gateway as degraded but keeps it in rotation, since it keeps serving traffic.
+// Optional dependencies are listed but do not affect the status.
 func (h *Handler) HealthCheckHandler(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusOK)
-	json.NewEncoder(w).Encode(map[string]string{"status": "ok"})
+
+	dependencies := h.dependencyHealth()
+	if len(dependencies) == 0 {
+		json.NewEncoder(w).Encode(map[string]string{"status": "ok"})
+		return
+	}
+
+	status := "ok"
+	for _, dependency := range dependencies {
+		if !dependency.Healthy && dependency.Criticality != entity.CriticalityOptional {
+			status = "degraded"
+		}
+	}
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"status":       status,
+		"dependencies": dependencies,
+	})
+}
+
+// JWKSHandler publishes the public keys used to verify gateway-issued tokens
+func (h *Handler) JWKSHandler(w http.ResponseWriter, r *http.Request) {
+	keySet, err := h.authUseCase.PublicKeys(r.Context())
+	if err != nil {
+		h.handleError(w, r, err, http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "public, max-age=300")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(keySet)
+}
+
+// MetricsHandler exposes upstream latency histograms per target in the Prometheus text format.
+// The series of each endpoint are also labelled with the tags of its route.
+func (h *Handler) MetricsHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	w.WriteHeader(http.StatusOK)
+
+	fmt.Fprintln(w, "# HELP gateway_upstream_latency_ms Latency of requests sent to each upstream target in milliseconds.")
+	fmt.Fprintln(w, "# TYPE gateway_upstream_latency_ms histogram")
+	latencies := h.statsUseCase.TargetLatencies()
+	for _, target := range latencies {
+		label := strconv.Quote(target.Target)
+		for i, bound := range target.BucketsMs {
+			fmt.Fprintf(w, "gateway_upstream_latency_ms_bucket{target=%s,le=\"%g\"} %d\n", label, bound, target.Counts[i])
+		}
+		fmt.Fprintf(w, "gateway_upstream_latency_ms_bucket{target=%s,le=\"+Inf\"} %d\n", label, target.Count)
+		fmt.Fprintf(w, "gateway_upstream_latency_ms_sum{target=%s} %g\n", label, target.SumMs)
+		fmt.Fprintf(w, "gateway_upstream_latency_ms_count{target=%s} %d\n", label, target.Count)
+	}
+
+	fmt.Fprintln(w, "# HELP gateway_upstream_failures_total Requests to each upstream target that failed or returned a 5xx status.")
+	fmt.Fprintln(w, "# TYPE gateway_upstream_failures_total counter")
+	for _, target := range latencies {
+		fmt.Fprintf(w, "gateway_upstream_failures_total{target=%s} %d\n", strconv.Quote(target.Target), target.Failures)
+	}
+
+	dependencies := h.dependencyHealth()
+	fmt.Fprintln(w, "# HELP gateway_dependency_up Whether a backing dependency such as Redis is reachable.")
+	fmt.Fprintln(w, "# TYPE gateway_dependency_up gauge")
+	for _, dependency := range dependencies {
+		up := 0
+		if dependency.Healthy {
+			up = 1
+		}
+		fmt.Fprintf(w, "gateway_dependency_up{dependency=%s} %d\n", strconv.Quote(dependency.Name), up)
+	}
+
+	fmt.Fprintln(w, "# HELP gateway_dependency_check_failures_total Failed connectivity checks of each backing dependency.")
+	fmt.Fprintln(w, "# TYPE gateway_dependency_check_failures_total counter")
+	for _, dependency := range dependencies {
+		fmt.Fprintf(w, "gateway_dependency_check_failures_total{dependency=%s} %d\n", strconv.Quote(dependency.Name), dependency.Failures)
+	}
+
+	fmt.Fprintln(w, "# HELP gateway_dependency_fallbacks_total Operations served by a degradation policy instead of the dependency.")
+	fmt.Fprintln(w, "# TYPE gateway_dependency_fallbacks_total counter")
+	for _, dependency := range dependencies {
+		fmt.Fprintf(w, "gateway_dependency_fallbacks_total{dependency=%s} %d\n", strconv.Quote(dependency.Name), dependency.Fallbacks)
+	}
+
+	fmt.Fprintln(w, "# HELP gateway_dependency_dropped_total Exports to an optional dependency discarded after a full buffer or exhausted retries.")
+	fmt.Fprintln(w, "# TYPE gateway_dependency_dropped_total counter")
+	for _, dependency := range dependencies {
+		fmt.Fprintf(w, "gateway_dependency_dropped_total{dependency=%s} %d\n", strconv.Quote(dependency.Name), dependency.Dropped)
+	}
+
+	pools := h.statsUseCase.WorkerPools()
+	fmt.Fprintln(w, "# HELP gateway_worker_pool_queue_depth Background tasks waiting for a worker of each pool.")
+	fmt.Fprintln(w, "# TYPE gateway_worker_pool_queue_depth gauge")
+	for _, pool := range pools {
+		fmt.Fprintf(w, "gateway_worker_pool_queue_depth{pool=%s} %d\n", strconv.Quote(pool.Name), pool.QueueDepth)
+	}
+	fmt.Fprintln(w, "# HELP gateway_worker_pool_queue_capacity Background tasks each pool queues before applying its drop policy.")
+	fmt.Fprintln(w, "# TYPE gateway_worker_pool_queue_capacity gauge")
+	for _, pool := range pools {
+		fmt.Fprintf(w, "gateway_worker_pool_queue_capacity{pool=%s} %d\n", strconv.Quote(pool.Name), pool.QueueCapacity)
+	}
+	fmt.Fprintln(w, "# HELP gateway_worker_pool_tasks_total Background tasks of each pool by whether they were completed or dropped.")
+	fmt.Fprintln(w, "# TYPE gateway_worker_pool_tasks_total counter")
+	for _, pool := range pools {
+		label := strconv.Quote(pool.Name)
+		fmt.Fprintf(w, "gateway_worker_pool_tasks_total{pool=%s,outcome=\"completed\"} %d\n", label, pool.Completed)
+		fmt.Fprintf(w, "gateway_worker_pool_tasks_total{pool=%s,outcome=\"dropped\"} %d\n", label, pool.Dropped)
+	}
+
+	fmt.Fprintln(w, "# HELP gateway_panics_total Panics recovered while serving requests, each answered with a 500.")
+	fmt.Fprintln(w, "# TYPE gateway_panics_total counter")
+	fmt.Fprintf(w, "gateway_panics_total %d\n", h.statsUseCase.Panics())
+
+	fmt.Fprintln(w, "# HELP gateway_errors_total Errors the gateway answered, by the code, status and retryability of their problem type.")
+	fmt.Fprintln(w, "# TYPE gateway_errors_total counter")
+	for _, count := range h.statsUseCase.Errors() {
+		fmt.Fprintf(w, "gateway_errors_total{code=%s,status=\"%d\",retryable=\"%t\"} %d\n", strconv.Quote(count.Code), count.Status, count.Retryable, count.Count)
+	}
+
+	if drops, ok := h.statsUseCase.LogDrops(); ok {
+		fmt.Fprintln(w, "# HELP gateway_log_dropped_total Log lines never written, left out by sampling, logged while an async queue was full or failed to deliver.")
+		fmt.Fprintln(w, "# TYPE gateway_log_dropped_total counter")
+		fmt.Fprintf(w, "gateway_log_dropped_total{reason=\"sampled\"} %d\n", drops.Sampled)
+		fmt.Fprintf(w, "gateway_log_dropped_total{reason=\"overflow\"} %d\n", drops.Overflowed)
+		fmt.Fprintf(w, "gateway_log_dropped_total{reason=\"failed\"} %d\n", drops.Failed)
+	}
+
+	cacheStats := h.statsUseCase.CacheStats(r.Context())
+	fmt.Fprintln(w, "# HELP gateway_cache_lookups_total Response cache lookups of each endpoint by result.")
+	fmt.Fprintln(w, "# TYPE gateway_cache_lookups_total counter")
+	for _, endpoint := range cacheStats.Endpoints {
+		labels := fmt.Sprintf("service=%s,endpoint=%s%s", strconv.Quote(endpoint.ServiceID), strconv.Quote(endpoint.Endpoint), tagLabels(endpoint.Tags))
+		fmt.Fprintf(w, "gateway_cache_lookups_total{%s,result=\"hit\"} %d\n", labels, endpoint.Hits)
+		fmt.Fprintf(w, "gateway_cache_lookups_total{%s,result=\"miss\"} %d\n", labels, endpoint.Misses)
+		fmt.Fprintf(w, "gateway_cache_lookups_total{%s,result=\"stale\"} %d\n", labels, endpoint.Stale)
+		fmt.Fprintf(w, "gateway_cache_lookups_total{%s,result=\"revalidated\"} %d\n", labels, endpoint.Revalidated)
+	}
+
+	if cacheStats.SizeAvailable {
+		fmt.Fprintln(w, "# HELP gateway_cache_entries Keys stored in the cache backend.")
+		fmt.Fprintln(w, "# TYPE gateway_cache_entries gauge")
+		fmt.Fprintf(w, "gateway_cache_entries %d\n", cacheStats.Entries)
+		fmt.Fprintln(w, "# HELP gateway_cache_memory_bytes Estimated memory used by the cache backend.")
+		fmt.Fprintln(w, "# TYPE gateway_cache_memory_bytes gauge")
+		fmt.Fprintf(w, "gateway_cache_memory_bytes %d\n", cacheStats.MemoryBytes)
+	}
+
+	slos := h.statsUseCase.SLOStatuses()
+	sloGauges := []struct {
+		name  string
+		help  string
+		value func(*entity.SLOStatus) float64
+	}{
+		{"gateway_slo_compliance", "Fraction of requests meeting the endpoint SLO over the window.", func(s *entity.SLOStatus) float64 { return s.Compliance }},
+		{"gateway_slo_burn_rate", "Rate at which the endpoint spends its error budget; 1 exhausts it over the window.", func(s *entity.SLOStatus) float64 { return s.BurnRate }},
+		{"gateway_slo_budget_remaining", "Fraction of the endpoint error budget left over the window.", func(s *entity.SLOStatus) float64 { return s.BudgetRemaining }},
+		{"gateway_slo_latency_p99_ms", "99th percentile latency of the endpoint over the window.", func(s *entity.SLOStatus) float64 { return s.LatencyP99Ms }},
+		{"gateway_slo_request_bytes_avg", "Average request body size of the endpoint over the window.", func(s *entity.SLOStatus) float64 { return s.AvgRequestBytes }},
+		{"gateway_slo_response_bytes_avg", "Average response body size of the endpoint over the window.", func(s *entity.SLOStatus) float64 { return s.AvgResponseBytes }},
+	}
+	for _, gauge := range sloGauges {
+		if len(slos) == 0 {
+			break
+		}
+		fmt.Fprintf(w, "# HELP %s %s\n", gauge.name, gauge.help)
+		fmt.Fprintf(w, "# TYPE %s gauge\n", gauge.name)
+		for _, slo := range slos {
+			fmt.Fprintf(w, "%s{service=%s,endpoint=%s%s} %g\n", gauge.name, strconv.Quote(slo.ServiceID), strconv.Quote(slo.Endpoint), tagLabels(slo.Tags), gauge.value(slo))
+		}
+	}
+
+	fmt.Fprintln(w, "# HELP gateway_route_requests_total Requests served by each endpoint by status class.")
+	fmt.Fprintln(w, "# TYPE gateway_route_requests_total counter")
+	for _, route := range h.statsUseCase.RouteRequests() {
+		classes := make([]string, 0, len(route.Classes))
+		for class := range route.Classes {
+			classes = append(classes, class)
+		}
+		sort.Strings(classes)
+		labels := fmt.Sprintf("service=%s,endpoint=%s%s", strconv.Quote(route.ServiceID), strconv.Quote(route.Endpoint), tagLabels(route.Tags))
+		for _, class := range classes {
+			fmt.Fprintf(w, "gateway_route_requests_total{%s,class=%q} %d\n", labels, class, route.Classes[class])
+		}
+	}
+
+	connections := h.statsUseCase.UpstreamConnections()
+	fmt.Fprintln(w, "# HELP gateway_upstream_phase_ms Latency of the DNS, connect, TLS and first byte phases of each service's upstream requests in milliseconds.")
+	fmt.Fprintln(w, "# TYPE gateway_upstream_phase_ms histogram")
+	for _, service := range connections {
+		for _, phase := range entity.ConnectionPhases {
+			latency, ok := service.Phases[phase]
+			if !ok {
+				continue
+			}
+			labels := fmt.Sprintf("service=%s,phase=%q", strconv.Quote(service.ServiceID), phase)
+			for i, bound := range latency.BucketsMs {
+				fmt.Fprintf(w, "gateway_upstream_phase_ms_bucket{%s,le=\"%g\"} %d\n", labels, bound, latency.Counts[i])
+			}
+			fmt.Fprintf(w, "gateway_upstream_phase_ms_bucket{%s,le=\"+Inf\"} %d\n", labels, latency.Count)
+			fmt.Fprintf(w, "gateway_upstream_phase_ms_sum{%s} %g\n", labels, latency.SumMs)
+			fmt.Fprintf(w, "gateway_upstream_phase_ms_count{%s} %d\n", labels, latency.Count)
+		}
+	}
+
+	fmt.Fprintln(w, "# HELP gateway_upstream_connections_total Upstream requests of each service by whether they reused a keep-alive connection.")
+	fmt.Fprintln(w, "# TYPE gateway_upstream_connections_total counter")
+	for _, service := range connections {
+		label := strconv.Quote(service.ServiceID)
+		fmt.Fprintf(w, "gateway_upstream_connections_total{service=%s,reused=\"true\"} %d\n", label, service.Reused)
+		fmt.Fprintf(w, "gateway_upstream_connections_total{service=%s,reused=\"false\"} %d\n", label, service.Opened)
+	}
 }
 
 // Helper functions
 
+func (h *Handler) dependencyHealth() []*entity.DependencyHealth {
+	if h.statsUseCase == nil {
+		return nil
+	}
+	return h.statsUseCase.DependencyHealth()
+}
+
+// tagLabels returns the Prometheus labels of a route's tags, sorted by name and each preceded by a comma
+func tagLabels(tags map[string]string) string {
+	names := make([]string, 0, len(tags))
+	for name := range tags {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var labels strings.Builder
+	for _, name := range names {
+		fmt.Fprintf(&labels, ",%s=%s", name, strconv.Quote(tags[name]))
+	}
+	return labels.String()
+}
+
+// readBody reads the body of a request through a pooled buffer
 func readBody(r *http.Request) ([]byte, error) {
 	defer r.Body.Close()
-	return json.Marshal(r.Body)
+	return bufferpool.ReadAll(r.Body)
 }
 
-func (h *Handler) handleError(w http.ResponseWriter, err error, statusCode int) {
-	h.logger.Error("Request failed", "error", err)
-	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(statusCode)
-	json.NewEncoder(w).Encode(map[string]string{"error": err.Error()})
+func (h *Handler) handleError(w http.ResponseWriter, r *http.Request, err error, statusCode int) {
+	problem := errors.ProblemOf(err, statusCode)
+	logger.FromContextOr(r.Context(), h.logger).Error("Request failed", "error", err,
+		logger.FieldErrorCode, problem.Code(), "status", problem.Status, "retryable", problem.Retryable)
+	h.recordError(problem)
+	h.errorPages.write(w, r, h.proxyUseCase, problem)
+}
+
+// recordError counts an error the gateway answered on /metrics
+func (h *Handler) recordError(problem *errors.Problem) {
+	if h.statsUseCase != nil {
+		h.statsUseCase.RecordError(problem.ProblemType)
+	}
 }
 
 func (h *Handler) writeResponse(w http.ResponseWriter, response *entity.Response) {
-	// Set headers
+	// Set headers, appending the values of each at once. The values of cached responses are
+	// shared, so they are capped to be copied rather than appended to.
+	header := w.Header()
 	for key, values := range response.Headers {
-		for _, value := range values {
-			w.Header().Add(key, value)
+		key = textproto.CanonicalMIMEHeaderKey(key)
+		if existing := header[key]; len(existing) > 0 {
+			header[key] = append(existing, values...)
+		} else {
+			header[key] = values[:len(values):len(values)]
 		}
 	}
 
 	// Set status code
 	w.WriteHeader(response.StatusCode)
 
-	// Write body
+	// Write body, then the rest of a body too large to be buffered as the service sends it
 	w.Write(response.Body)
+	if response.BodyStream != nil {
+		defer response.BodyStream.Close()
+		io.Copy(w, response.BodyStream)
+	}
 }
@@ -0,0 +1,76 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"api-gateway-sample/internal/application/usecase"
+	"api-gateway-sample/internal/domain/entity"
+	repomock "api-gateway-sample/internal/domain/repository/mock"
+	"api-gateway-sample/internal/infrastructure/metrics"
+)
+
+// staticHealth reports a fixed dependency state
+type staticHealth entity.DependencyHealth
+
+func (h staticHealth) Health() *entity.DependencyHealth {
+	health := entity.DependencyHealth(h)
+	return &health
+}
+
+func TestHealthCheckHandler_Criticality(t *testing.T) {
+	tests := []struct {
+		name         string
+		dependencies []staticHealth
+		expected     string
+	}{
+		{
+			name:         "Healthy",
+			dependencies: []staticHealth{{Name: "redis", Criticality: entity.CriticalityDegradable, Healthy: true}},
+			expected:     "ok",
+		},
+		{
+			name:         "DegradableDown",
+			dependencies: []staticHealth{{Name: "redis", Criticality: entity.CriticalityDegradable}},
+			expected:     "degraded",
+		},
+		{
+			name: "OptionalDown",
+			dependencies: []staticHealth{
+				{Name: "redis", Criticality: entity.CriticalityDegradable, Healthy: true},
+				{Name: "alerts.slack", Criticality: entity.CriticalityOptional},
+			},
+			expected: "ok",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stats := usecase.NewStatsUseCase(repomock.NewServiceRepositoryMock(), metrics.NewSlidingWindowAggregator(time.Minute, 6), &MockLogger{})
+			for _, dependency := range tt.dependencies {
+				stats.AddHealthReporter(dependency)
+			}
+			handler := NewHandler(nil, nil, nil, nil, stats, &MockLogger{})
+
+			rr := httptest.NewRecorder()
+			handler.HealthCheckHandler(rr, httptest.NewRequest(http.MethodGet, "/health", nil))
+
+			var body struct {
+				Status       string                     `json:"status"`
+				Dependencies []*entity.DependencyHealth `json:"dependencies"`
+			}
+			if err := json.NewDecoder(rr.Body).Decode(&body); err != nil {
+				t.Fatalf("Failed to decode response: %v", err)
+			}
+			if rr.Code != http.StatusOK || body.Status != tt.expected {
+				t.Errorf("Expected 200 %s, got %d %s", tt.expected, rr.Code, body.Status)
+			}
+			if len(body.Dependencies) != len(tt.dependencies) {
+				t.Errorf("Expected %d dependencies, got %d", len(tt.dependencies), len(body.Dependencies))
+			}
+		})
+	}
+}
@@ -1,11 +1,25 @@
 package api
 
 import (
+	"context"
+	"encoding/hex"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"strconv"
+	"strings"
 	"testing"
+	"time"
+
+	"api-gateway-sample/internal/application/usecase"
+	"api-gateway-sample/internal/domain/entity"
+	repomock "api-gateway-sample/internal/domain/repository/mock"
+	"api-gateway-sample/internal/infrastructure/auth"
+	"api-gateway-sample/pkg/config"
+	"api-gateway-sample/pkg/logger"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 // MockLogger is a simple mock implementation of the logger interface
@@ -105,6 +119,51 @@ func TestRecoveryMiddlewareSimple(t *testing.T) {
 	assert.True(t, mockLogger.errorCalled, "Error method should have been called")
 }
 
+// lineLogger keeps the messages and fields of the lines logged to it
+type lineLogger struct {
+	lines []string
+}
+
+func (l *lineLogger) log(msg string, args []interface{}) {
+	l.lines = append(l.lines, fmt.Sprint(append([]interface{}{msg}, args...)...))
+}
+
+func (l *lineLogger) Debug(msg string, args ...interface{}) { l.log(msg, args) }
+func (l *lineLogger) Info(msg string, args ...interface{})  { l.log(msg, args) }
+func (l *lineLogger) Warn(msg string, args ...interface{})  { l.log(msg, args) }
+func (l *lineLogger) Error(msg string, args ...interface{}) { l.log(msg, args) }
+func (l *lineLogger) Fatal(msg string, args ...interface{}) { l.log(msg, args) }
+
+func TestLoggingMiddlewareRedactsCredentials(t *testing.T) {
+	lines := &lineLogger{}
+	redactor := logger.NewRedactor([]string{"Authorization", "Cookie"}, nil, "")
+	router := &Router{
+		logger: logger.NewRedactingLogger(lines, redactor),
+	}
+
+	// The handler fails with an error quoting the caller's credentials
+	handler := router.loggingMiddleware(router.recoveryMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		panic(fmt.Errorf("upstream rejected %s", r.Header.Get("Authorization")))
+	})))
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/orders", nil)
+	req.Header.Set("Authorization", "Bearer secret-token")
+	req.Header.Set("Cookie", "session=secret-session")
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, req)
+
+	assert.Equal(t, http.StatusInternalServerError, rr.Code)
+	// Only the error and access log lines are written, without the credentials
+	require.Len(t, lines.lines, 2)
+	assert.True(t, strings.HasPrefix(lines.lines[0], "Panic recovered"))
+	assert.Contains(t, lines.lines[0], "Bearer "+logger.DefaultRedactionMask)
+	assert.True(t, strings.HasPrefix(lines.lines[1], "Request completed"))
+	for _, line := range lines.lines {
+		assert.NotContains(t, line, "secret-token")
+		assert.NotContains(t, line, "secret-session")
+	}
+}
+
 func TestCorsMiddlewareSimple(t *testing.T) {
 	// Create a router
 	router := &Router{}
@@ -160,3 +219,348 @@ func TestCorsMiddlewareSimple(t *testing.T) {
 		})
 	}
 }
+
+func TestSecurityHeadersMiddlewareSimple(t *testing.T) {
+	// Create a router with a security policy
+	router := &Router{
+		config: &config.Config{
+			Security: config.SecurityConfig{
+				Headers: config.SecurityHeadersConfig{
+					ContentTypeOptions: "nosniff",
+					FrameOptions:       "DENY",
+				},
+				StripHeaders: []string{"Server", "X-Powered-By"},
+			},
+		},
+	}
+
+	// Create a test handler that leaks upstream headers
+	testHandler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Server", "nginx/1.25")
+		w.Header().Set("X-Powered-By", "PHP/8.2")
+		w.Write([]byte("OK"))
+	})
+
+	// Apply the security headers middleware
+	handler := router.securityHeadersMiddleware(testHandler)
+
+	// Create a test request and response recorder
+	req := httptest.NewRequest(http.MethodGet, "/test", nil)
+	rr := httptest.NewRecorder()
+
+	// Call the handler
+	handler.ServeHTTP(rr, req)
+
+	// Verify the response
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Equal(t, "nosniff", rr.Header().Get("X-Content-Type-Options"))
+	assert.Equal(t, "DENY", rr.Header().Get("X-Frame-Options"))
+	assert.Empty(t, rr.Header().Get("Strict-Transport-Security"))
+	assert.Empty(t, rr.Header().Get("Server"))
+	assert.Empty(t, rr.Header().Get("X-Powered-By"))
+}
+
+func TestDebugMiddlewareSimple(t *testing.T) {
+	// Create a router with a debug secret
+	router := &Router{
+		logger: &MockLogger{},
+		config: &config.Config{
+			Debug: config.DebugConfig{
+				Secret:       "debug-secret",
+				MaxClockSkew: time.Minute,
+			},
+		},
+	}
+
+	// Create a test handler that records a traced phase
+	testHandler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		trace := entity.TraceFromContext(r.Context())
+		trace.Record(entity.TracePhaseUpstream, time.Now())
+		trace.SetTarget("http://users-service:8080")
+		assert.Empty(t, r.Header.Get(debugHeader), "Debug header should not be forwarded")
+		w.WriteHeader(http.StatusOK)
+	})
+
+	// Apply the debug middleware
+	handler := router.debugMiddleware(testHandler)
+
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+	sign := func(method, path string) string {
+		return timestamp + "." + hex.EncodeToString(signDebugRequest("debug-secret", method, path, timestamp))
+	}
+
+	testCases := []struct {
+		name          string
+		header        string
+		expectHeaders bool
+	}{
+		{
+			name:          "Valid signature",
+			header:        sign(http.MethodGet, "/api/v1/users"),
+			expectHeaders: true,
+		},
+		{
+			name:          "Signature for another path",
+			header:        sign(http.MethodGet, "/api/v1/orders"),
+			expectHeaders: false,
+		},
+		{
+			name:          "Signature for another method",
+			header:        sign(http.MethodDelete, "/api/v1/users"),
+			expectHeaders: false,
+		},
+		{
+			name:          "Invalid signature",
+			header:        timestamp + ".deadbeef",
+			expectHeaders: false,
+		},
+		{
+			name:          "No debug header",
+			header:        "",
+			expectHeaders: false,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/api/v1/users", nil)
+			if tc.header != "" {
+				req.Header.Set(debugHeader, tc.header)
+			}
+			rr := httptest.NewRecorder()
+
+			handler.ServeHTTP(rr, req)
+
+			assert.Equal(t, http.StatusOK, rr.Code)
+			if tc.expectHeaders {
+				assert.NotEmpty(t, rr.Header().Get("X-Gateway-Debug-Upstream-Ms"))
+				assert.Equal(t, entity.CacheStatusBypass, rr.Header().Get("X-Gateway-Debug-Cache"))
+				assert.Equal(t, "http://users-service:8080", rr.Header().Get("X-Gateway-Debug-Target"))
+			} else {
+				assert.Empty(t, rr.Header().Get("X-Gateway-Debug-Cache"))
+			}
+		})
+	}
+}
+
+func TestAuthMiddlewareRouteAwareSimple(t *testing.T) {
+	// Register a service with a public and a protected endpoint
+	serviceRepo := repomock.NewServiceRepositoryMock()
+	err := serviceRepo.Create(context.Background(), &entity.Service{
+		ID:      "users",
+		Name:    "users-service",
+		BaseURL: "http://users-service:8080",
+		Endpoints: []entity.Endpoint{
+			{Path: "/api/v1/public", Methods: []string{http.MethodGet}},
+			{Path: "/api/v1/private", Methods: []string{http.MethodGet}, AuthRequired: true},
+		},
+	})
+	assert.NoError(t, err)
+
+	// Create a router resolving endpoints through the proxy use case
+	router := &Router{
+		logger:       &MockLogger{},
+		proxyUseCase: usecase.NewProxyUseCase(serviceRepo, nil, nil, nil, nil, &MockLogger{}),
+	}
+
+	// Create a test handler
+	testHandler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+
+	// Apply the auth middleware
+	handler := router.authMiddleware(testHandler)
+
+	testCases := []struct {
+		name           string
+		path           string
+		expectedStatus int
+	}{
+		{
+			name:           "Anonymous request to public endpoint",
+			path:           "/api/v1/public",
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "Anonymous request to protected endpoint",
+			path:           "/api/v1/private",
+			expectedStatus: http.StatusUnauthorized,
+		},
+		{
+			name:           "Anonymous request to unknown endpoint",
+			path:           "/api/v1/unknown",
+			expectedStatus: http.StatusUnauthorized,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, tc.path, nil)
+			rr := httptest.NewRecorder()
+
+			handler.ServeHTTP(rr, req)
+
+			assert.Equal(t, tc.expectedStatus, rr.Code)
+		})
+	}
+}
+
+func TestAdminMiddlewareSimple(t *testing.T) {
+	// Create a router
+	router := &Router{}
+
+	// Create a test handler
+	testHandler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+
+	// Apply the admin middleware
+	handler := router.adminMiddleware(testHandler)
+
+	testCases := []struct {
+		name           string
+		principal      *entity.Principal
+		expectedStatus int
+	}{
+		{
+			name:           "Administrator",
+			principal:      &entity.Principal{UserID: "admin-1", Roles: []string{"admin"}},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "Regular user",
+			principal:      &entity.Principal{UserID: "user-1", Roles: []string{"viewer"}},
+			expectedStatus: http.StatusForbidden,
+		},
+		{
+			name:           "Anonymous",
+			expectedStatus: http.StatusUnauthorized,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/api/policies/test", nil)
+			if tc.principal != nil {
+				req = req.WithContext(entity.ContextWithPrincipal(req.Context(), tc.principal))
+			}
+			rr := httptest.NewRecorder()
+
+			handler.ServeHTTP(rr, req)
+
+			assert.Equal(t, tc.expectedStatus, rr.Code)
+		})
+	}
+}
+
+func TestAuthMiddlewareAPIKeySimple(t *testing.T) {
+	// Register two services and an approved key for the first one
+	ctx := context.Background()
+	serviceRepo := repomock.NewServiceRepositoryMock()
+	for _, id := range []string{"orders", "billing"} {
+		err := serviceRepo.Create(ctx, &entity.Service{
+			ID:        id,
+			Name:      id,
+			BaseURL:   "http://" + id + ":8080",
+			Endpoints: []entity.Endpoint{{Path: "/api/v1/" + id, Methods: []string{http.MethodGet}, AuthRequired: true}},
+		})
+		assert.NoError(t, err)
+	}
+	apiKeyRepo := repomock.NewAPIKeyRepositoryMock()
+	err := apiKeyRepo.Create(ctx, &entity.APIKey{
+		ID:        "key-1",
+		ServiceID: "orders",
+		Status:    entity.APIKeyStatusApproved,
+		KeyHash:   entity.HashAPIKey("gw_secret"),
+	})
+	assert.NoError(t, err)
+
+	router := &Router{
+		logger:       &MockLogger{},
+		proxyUseCase: usecase.NewProxyUseCase(serviceRepo, nil, nil, nil, nil, &MockLogger{}),
+	}
+	router.SetAPIKeyUseCase(usecase.NewAPIKeyUseCase(apiKeyRepo, serviceRepo, nil, &MockLogger{}))
+
+	// The test handler echoes the principal and the forwarded key header
+	testHandler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		principal, ok := entity.PrincipalFromContext(r.Context())
+		assert.True(t, ok)
+		assert.Equal(t, "key-1", principal.UserID)
+		assert.True(t, principal.HasRole("orders:/api/v1/orders"))
+		assert.Empty(t, r.Header.Get(HeaderAPIKey))
+		w.WriteHeader(http.StatusOK)
+	})
+	handler := router.authMiddleware(testHandler)
+
+	testCases := []struct {
+		name           string
+		path           string
+		key            string
+		expectedStatus int
+	}{
+		{name: "Key for the service", path: "/api/v1/orders", key: "gw_secret", expectedStatus: http.StatusOK},
+		{name: "Key for another service", path: "/api/v1/billing", key: "gw_secret", expectedStatus: http.StatusForbidden},
+		{name: "Unknown key", path: "/api/v1/orders", key: "gw_other", expectedStatus: http.StatusUnauthorized},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, tc.path, nil)
+			req.Header.Set(HeaderAPIKey, tc.key)
+			rr := httptest.NewRecorder()
+
+			handler.ServeHTTP(rr, req)
+
+			assert.Equal(t, tc.expectedStatus, rr.Code)
+		})
+	}
+}
+
+func TestAuthMiddlewareSessionCookieSimple(t *testing.T) {
+	ctx := context.Background()
+	jwtAuth := auth.NewJWTAuth([]byte("session-secret"), "api-gateway", time.Hour, &MockLogger{})
+	token, err := jwtAuth.GenerateToken(ctx, "alice", map[string]interface{}{"roles": []string{"viewer"}})
+	assert.NoError(t, err)
+
+	router := &Router{
+		logger:      &MockLogger{},
+		authUseCase: usecase.NewAuthUseCase(jwtAuth, &MockLogger{}),
+	}
+	router.SetSessionCookie("gateway_session")
+
+	// The test handler checks the principal and that the session cookie is not forwarded
+	testHandler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		principal, ok := entity.PrincipalFromContext(r.Context())
+		assert.True(t, ok)
+		assert.Equal(t, "alice", principal.UserID)
+		_, err := r.Cookie("gateway_session")
+		assert.Error(t, err)
+		theme, err := r.Cookie("theme")
+		assert.NoError(t, err)
+		assert.Equal(t, "dark", theme.Value)
+		w.WriteHeader(http.StatusOK)
+	})
+	handler := router.authMiddleware(testHandler)
+
+	testCases := []struct {
+		name           string
+		session        string
+		expectedStatus int
+	}{
+		{name: "Valid session", session: token, expectedStatus: http.StatusOK},
+		{name: "Invalid session", session: "forged", expectedStatus: http.StatusUnauthorized},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/api/v1/orders", nil)
+			req.AddCookie(&http.Cookie{Name: "gateway_session", Value: tc.session})
+			req.AddCookie(&http.Cookie{Name: "theme", Value: "dark"})
+			rr := httptest.NewRecorder()
+
+			handler.ServeHTTP(rr, req)
+
+			assert.Equal(t, tc.expectedStatus, rr.Code)
+		})
+	}
+}
@@ -0,0 +1,62 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/gorilla/mux"
+
+	"api-gateway-sample/internal/application/usecase"
+	"api-gateway-sample/pkg/errors"
+)
+
+// OIDCHandler handles the browser login flow with an OpenID Connect identity provider
+type OIDCHandler struct {
+	oidcUseCase *usecase.OIDCUseCase
+	sessions    *SessionCookie
+}
+
+// NewOIDCHandler creates a new OIDCHandler instance storing sessions in the given cookie
+func NewOIDCHandler(oidcUseCase *usecase.OIDCUseCase, sessions *SessionCookie) *OIDCHandler {
+	return &OIDCHandler{
+		oidcUseCase: oidcUseCase,
+		sessions:    sessions,
+	}
+}
+
+// RegisterRoutes registers the login routes
+func (h *OIDCHandler) RegisterRoutes(router *mux.Router) {
+	router.HandleFunc("/auth/login", h.Login).Methods(http.MethodGet)
+	router.HandleFunc("/auth/callback", h.Callback).Methods(http.MethodGet)
+}
+
+// Login redirects the user to the identity provider, to return to the return_to path once logged in
+func (h *OIDCHandler) Login(w http.ResponseWriter, r *http.Request) {
+	authorizationURL, err := h.oidcUseCase.BeginLogin(r.Context(), r.URL.Query().Get("return_to"))
+	if err != nil {
+		writeProblem(w, r, errors.StatusProblem(http.StatusBadGateway, "Identity provider unavailable"))
+		return
+	}
+
+	w.Header().Set("Cache-Control", "no-store")
+	http.Redirect(w, r, authorizationURL, http.StatusFound)
+}
+
+// Callback completes the login the identity provider redirected the user back from, sets the
+// session cookie and redirects the user to the path they started from
+func (h *OIDCHandler) Callback(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	if providerError := query.Get("error"); providerError != "" {
+		writeProblem(w, r, errors.StatusProblem(http.StatusUnauthorized, "Login failed: "+providerError))
+		return
+	}
+
+	token, returnTo, err := h.oidcUseCase.CompleteLogin(r.Context(), query.Get("state"), query.Get("code"))
+	if err != nil {
+		writeLoginError(w, r, err)
+		return
+	}
+
+	h.sessions.Set(w, token)
+	w.Header().Set("Cache-Control", "no-store")
+	http.Redirect(w, r, returnTo, http.StatusFound)
+}
@@ -0,0 +1,65 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/gorilla/mux"
+
+	"api-gateway-sample/internal/application/usecase"
+	"api-gateway-sample/internal/domain/entity"
+)
+
+// PolicyHandler handles HTTP requests for authorization policy administration
+type PolicyHandler struct {
+	policyUseCase *usecase.PolicyUseCase
+}
+
+// NewPolicyHandler creates a new PolicyHandler instance
+func NewPolicyHandler(policyUseCase *usecase.PolicyUseCase) *PolicyHandler {
+	return &PolicyHandler{
+		policyUseCase: policyUseCase,
+	}
+}
+
+// TestPolicyRequest represents a request to evaluate a policy against a sample input
+type TestPolicyRequest struct {
+	Policy string             `json:"policy"`
+	Input  entity.PolicyInput `json:"input"`
+}
+
+// TestPolicyResponse represents the outcome of a policy evaluation
+type TestPolicyResponse struct {
+	Allowed bool   `json:"allowed"`
+	Error   string `json:"error,omitempty"`
+}
+
+// RegisterRoutes registers the policy routes
+func (h *PolicyHandler) RegisterRoutes(router *mux.Router) {
+	router.HandleFunc("/policies/test", h.TestPolicy).Methods(http.MethodPost)
+}
+
+// TestPolicy handles policy evaluation requests
+func (h *PolicyHandler) TestPolicy(w http.ResponseWriter, r *http.Request) {
+	var req TestPolicyRequest
+	if !decodeRequest(w, r, &req) {
+		return
+	}
+
+	if err := h.policyUseCase.ValidatePolicy(req.Policy); err != nil {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusUnprocessableEntity)
+		json.NewEncoder(w).Encode(TestPolicyResponse{Error: err.Error()})
+		return
+	}
+
+	response := TestPolicyResponse{}
+	allowed, err := h.policyUseCase.TestPolicy(r.Context(), req.Policy, &req.Input)
+	if err != nil {
+		response.Error = err.Error()
+	}
+	response.Allowed = allowed
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
@@ -0,0 +1,57 @@
+function el(tag, attrs, children) {
+  const node = document.createElement(tag);
+  Object.entries(attrs || {}).forEach(([k, v]) => node.setAttribute(k, v));
+  (children || []).forEach(c => node.append(c));
+  return node;
+}
+
+function renderService(service) {
+  const rows = service.endpoints.map(e => el("tr", {}, [
+    el("td", {}, [el("code", {}, [e.methods.join(", ")])]),
+    el("td", {}, [el("code", {}, [e.path])]),
+    el("td", {}, [e.authRequired ? "required" : "none"]),
+    el("td", {}, [e.rateLimit ? e.rateLimit + "/min" : "unlimited"]),
+  ]));
+  const message = el("p", {class: "message"});
+  const form = el("form", {}, [
+    el("input", {name: "consumer", placeholder: "Application or team", required: ""}),
+    el("input", {name: "email", type: "email", placeholder: "Contact email", required: ""}),
+    el("textarea", {name: "purpose", placeholder: "What will you use the API for?"}),
+    el("button", {type: "submit"}, ["Request an API key"]),
+  ]);
+  form.addEventListener("submit", async event => {
+    event.preventDefault();
+    const data = Object.fromEntries(new FormData(form));
+    const response = await fetch("/portal/services/" + encodeURIComponent(service.id) + "/keys", {
+      method: "POST",
+      headers: {"Content-Type": "application/json"},
+      body: JSON.stringify(data),
+    });
+    message.textContent = response.ok
+      ? "Request received. You will be contacted once it has been reviewed."
+      : "Request failed: " + (await response.text());
+    if (response.ok) form.reset();
+  });
+
+  return el("div", {class: "service"}, [
+    el("h2", {}, [service.name]),
+    el("div", {class: "version"}, ["Version " + (service.version || "unversioned")]),
+    el("p", {}, [service.description || ""]),
+    el("a", {href: "/portal/services/" + encodeURIComponent(service.id) + "/openapi.json"}, ["OpenAPI document"]),
+    el("table", {}, [
+      el("tr", {}, [el("th", {}, ["Methods"]), el("th", {}, ["Path"]), el("th", {}, ["Auth"]), el("th", {}, ["Rate limit"])]),
+      ...rows,
+    ]),
+    form,
+    message,
+  ]);
+}
+
+fetch("/portal/services")
+  .then(r => r.json())
+  .then(services => {
+    const container = document.getElementById("services");
+    container.textContent = services.length ? "" : "No services have been published yet.";
+    services.forEach(s => container.append(renderService(s)));
+  })
+  .catch(err => { document.getElementById("services").textContent = "Failed to load the catalog: " + err; });
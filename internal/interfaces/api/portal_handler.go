@@ -0,0 +1,143 @@
+package api
+
+import (
+	"embed"
+	"encoding/json"
+	"io/fs"
+	"net/http"
+
+	"github.com/gorilla/mux"
+
+	"api-gateway-sample/internal/application/dto"
+	"api-gateway-sample/internal/application/usecase"
+	"api-gateway-sample/pkg/errors"
+)
+
+//go:embed portal
+var portalFiles embed.FS
+
+// portalContentSecurityPolicy lets the portal page load its own script and style and call the portal API
+const portalContentSecurityPolicy = "default-src 'none'; script-src 'self'; style-src 'self'; connect-src 'self'; form-action 'self'; frame-ancestors 'none'"
+
+// PortalHandler handles the public developer portal requests
+type PortalHandler struct {
+	portalUseCase *usecase.PortalUseCase
+	// pages serves the embedded catalog page on /portal
+	pages bool
+}
+
+// NewPortalHandler creates a new PortalHandler instance
+func NewPortalHandler(portalUseCase *usecase.PortalUseCase, pages bool) *PortalHandler {
+	return &PortalHandler{
+		portalUseCase: portalUseCase,
+		pages:         pages,
+	}
+}
+
+// RegisterRoutes registers the portal routes
+func (h *PortalHandler) RegisterRoutes(router *mux.Router) {
+	if h.pages {
+		assets, _ := fs.Sub(portalFiles, "portal")
+		router.HandleFunc("/portal", h.Page).Methods(http.MethodGet)
+		router.PathPrefix("/portal/assets/").Handler(http.StripPrefix("/portal/assets/", http.FileServer(http.FS(assets)))).Methods(http.MethodGet)
+	}
+	router.HandleFunc("/portal/services", h.ListServices).Methods(http.MethodGet)
+	router.HandleFunc("/portal/services/{id}", h.GetService).Methods(http.MethodGet)
+	router.HandleFunc("/portal/services/{id}/openapi.json", h.GetOpenAPIDocument).Methods(http.MethodGet)
+	router.HandleFunc("/portal/services/{id}/keys", h.SignUp).Methods(http.MethodPost)
+}
+
+// Page serves the embedded API catalog page
+func (h *PortalHandler) Page(w http.ResponseWriter, r *http.Request) {
+	page, err := portalFiles.ReadFile("portal/index.html")
+	if err != nil {
+		writeProblem(w, r, errors.StatusProblem(http.StatusNotFound, "Portal page not found"))
+		return
+	}
+
+	overrideContentSecurityPolicy(w, portalContentSecurityPolicy)
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.Write(page)
+}
+
+// ListServices handles published service listing requests
+func (h *PortalHandler) ListServices(w http.ResponseWriter, r *http.Request) {
+	services, err := h.portalUseCase.ListServices(r.Context())
+	if err != nil {
+		writeProblem(w, r, errors.StatusProblem(http.StatusInternalServerError, "Failed to list services"))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(services)
+}
+
+// GetService handles published service retrieval requests
+func (h *PortalHandler) GetService(w http.ResponseWriter, r *http.Request) {
+	service, err := h.portalUseCase.GetService(r.Context(), mux.Vars(r)["id"])
+	if err != nil {
+		if errors.IsNotFound(err) {
+			writeProblem(w, r, errors.StatusProblem(http.StatusNotFound, "Service not found"))
+			return
+		}
+		writeProblem(w, r, errors.StatusProblem(http.StatusInternalServerError, "Failed to get service"))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(service)
+}
+
+// GetOpenAPIDocument handles OpenAPI document requests for a published service
+func (h *PortalHandler) GetOpenAPIDocument(w http.ResponseWriter, r *http.Request) {
+	document, err := h.portalUseCase.OpenAPIDocument(r.Context(), mux.Vars(r)["id"], requestBaseURL(r))
+	if err != nil {
+		if errors.IsNotFound(err) {
+			writeProblem(w, r, errors.StatusProblem(http.StatusNotFound, "Service not found"))
+			return
+		}
+		writeProblem(w, r, errors.StatusProblem(http.StatusInternalServerError, "Failed to build OpenAPI document"))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(document)
+}
+
+// SignUp handles API key requests from consumers
+func (h *PortalHandler) SignUp(w http.ResponseWriter, r *http.Request) {
+	var req dto.APIKeySignupRequest
+	if !decodeRequest(w, r, &req) {
+		return
+	}
+
+	key, err := h.portalUseCase.SignUp(r.Context(), mux.Vars(r)["id"], &req)
+	if err != nil {
+		switch {
+		case errors.IsNotFound(err):
+			writeProblem(w, r, errors.StatusProblem(http.StatusNotFound, "Service not found"))
+		case errors.IsInvalidInput(err):
+			writeProblem(w, r, errors.StatusProblem(http.StatusBadRequest, err.Error()))
+		default:
+			writeProblem(w, r, errors.StatusProblem(http.StatusInternalServerError, "Failed to request API key"))
+		}
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusAccepted)
+	json.NewEncoder(w).Encode(key)
+}
+
+// requestBaseURL returns the scheme and host the client used to reach the gateway
+func requestBaseURL(r *http.Request) string {
+	return requestScheme(r) + "://" + r.Host
+}
+
+// requestScheme returns the scheme the client used to reach the gateway
+func requestScheme(r *http.Request) string {
+	if r.TLS != nil {
+		return "https"
+	}
+	return "http"
+}
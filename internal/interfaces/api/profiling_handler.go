@@ -0,0 +1,138 @@
+package api
+
+import (
+	"encoding/json"
+	"math"
+	"net/http"
+	"net/http/pprof"
+	"runtime"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/gorilla/mux"
+)
+
+// profileWriteMargin is the part of the server's write timeout left to send a CPU profile or
+// execution trace once it is collected
+const profileWriteMargin = 5 * time.Second
+
+// ProfilingHandler serves the Go profiles and runtime statistics of the gateway process
+type ProfilingHandler struct{}
+
+// NewProfilingHandler creates a new ProfilingHandler instance
+func NewProfilingHandler() *ProfilingHandler {
+	return &ProfilingHandler{}
+}
+
+// RegisterRoutes registers the profiling routes
+func (h *ProfilingHandler) RegisterRoutes(router *mux.Router) {
+	router.HandleFunc("/debug/runtime", h.GetRuntimeStats).Methods(http.MethodGet)
+	router.HandleFunc("/debug/pprof/", pprof.Index).Methods(http.MethodGet)
+	router.HandleFunc("/debug/pprof/cmdline", pprof.Cmdline).Methods(http.MethodGet)
+	router.HandleFunc("/debug/pprof/profile", withinWriteTimeout(pprof.Profile, 30)).Methods(http.MethodGet)
+	router.HandleFunc("/debug/pprof/symbol", pprof.Symbol).Methods(http.MethodGet, http.MethodPost)
+	router.HandleFunc("/debug/pprof/trace", withinWriteTimeout(pprof.Trace, 1)).Methods(http.MethodGet)
+	// pprof.Index only serves named profiles under /debug/pprof/, so they are served here
+	router.HandleFunc("/debug/pprof/{profile}", func(w http.ResponseWriter, r *http.Request) {
+		pprof.Handler(mux.Vars(r)["profile"]).ServeHTTP(w, r)
+	}).Methods(http.MethodGet)
+}
+
+// withinWriteTimeout shortens the seconds a CPU profile or execution trace is collected for,
+// defaultSeconds when unset, to end before the server's write timeout, as pprof rejects longer
+// ones and would otherwise reject its own default profile duration
+func withinWriteTimeout(handler http.HandlerFunc, defaultSeconds float64) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		srv, ok := r.Context().Value(http.ServerContextKey).(*http.Server)
+		if !ok || srv.WriteTimeout <= 0 {
+			handler(w, r)
+			return
+		}
+		limit := math.Max(math.Floor((srv.WriteTimeout - profileWriteMargin).Seconds()), 1)
+		query := r.URL.Query()
+		seconds, err := strconv.ParseFloat(query.Get("seconds"), 64)
+		if err != nil || seconds <= 0 {
+			seconds = defaultSeconds
+		}
+		if seconds > limit {
+			query.Set("seconds", strconv.FormatFloat(limit, 'f', -1, 64))
+			r = r.Clone(r.Context())
+			r.URL.RawQuery = query.Encode()
+			r.Form = nil
+		}
+		handler(w, r)
+	}
+}
+
+// RuntimeStats is a snapshot of the goroutines, heap and garbage collector of the process
+type RuntimeStats struct {
+	Goroutines      int    `json:"goroutines"`
+	HeapAllocBytes  uint64 `json:"heapAllocBytes"`
+	HeapInuseBytes  uint64 `json:"heapInuseBytes"`
+	HeapObjects     uint64 `json:"heapObjects"`
+	SysBytes        uint64 `json:"sysBytes"`
+	NextGCHeapBytes uint64 `json:"nextGcHeapBytes"`
+	GCCycles        uint32 `json:"gcCycles"`
+	// GCCPUFraction is the fraction of CPU time used by the garbage collector since startup
+	GCCPUFraction float64 `json:"gcCpuFraction"`
+	// GCPauseTotalMs is the time the world was stopped for garbage collection since startup
+	GCPauseTotalMs float64 `json:"gcPauseTotalMs"`
+	// GCPauses are percentiles of the stop-the-world pauses of the last 256 cycles at most
+	GCPauses GCPauses `json:"gcPauses"`
+	// LastGC is when the last cycle finished, unset before the first
+	LastGC *time.Time `json:"lastGc,omitempty"`
+}
+
+// GCPauses are garbage collection pause percentiles in milliseconds
+type GCPauses struct {
+	P50 float64 `json:"p50"`
+	P99 float64 `json:"p99"`
+	Max float64 `json:"max"`
+}
+
+// GetRuntimeStats handles runtime statistics requests
+func (h *ProfilingHandler) GetRuntimeStats(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(readRuntimeStats())
+}
+
+// readRuntimeStats reads the runtime statistics, briefly stopping the world
+func readRuntimeStats() *RuntimeStats {
+	var mem runtime.MemStats
+	runtime.ReadMemStats(&mem)
+
+	stats := &RuntimeStats{
+		Goroutines:      runtime.NumGoroutine(),
+		HeapAllocBytes:  mem.HeapAlloc,
+		HeapInuseBytes:  mem.HeapInuse,
+		HeapObjects:     mem.HeapObjects,
+		SysBytes:        mem.Sys,
+		NextGCHeapBytes: mem.NextGC,
+		GCCycles:        mem.NumGC,
+		GCCPUFraction:   mem.GCCPUFraction,
+		GCPauseTotalMs:  float64(mem.PauseTotalNs) / float64(time.Millisecond),
+	}
+	if mem.NumGC > 0 {
+		lastGC := time.Unix(0, int64(mem.LastGC)).UTC()
+		stats.LastGC = &lastGC
+	}
+
+	// PauseNs is a circular buffer holding the pauses of the most recent cycles
+	recent := int(mem.NumGC)
+	if recent > len(mem.PauseNs) {
+		recent = len(mem.PauseNs)
+	}
+	if recent > 0 {
+		pauses := make([]uint64, recent)
+		for i := range pauses {
+			pauses[i] = mem.PauseNs[(int(mem.NumGC)-1-i+len(mem.PauseNs))%len(mem.PauseNs)]
+		}
+		sort.Slice(pauses, func(i, j int) bool { return pauses[i] < pauses[j] })
+		at := func(p float64) float64 {
+			return float64(pauses[int(p*float64(len(pauses)-1))]) / float64(time.Millisecond)
+		}
+		stats.GCPauses = GCPauses{P50: at(0.50), P99: at(0.99), Max: at(1)}
+	}
+	return stats
+}
@@ -0,0 +1,125 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"runtime"
+	"testing"
+	"time"
+
+	"api-gateway-sample/internal/application/usecase"
+	"api-gateway-sample/internal/infrastructure/auth"
+	"api-gateway-sample/pkg/config"
+
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestProfilingHandler(t *testing.T) {
+	router := mux.NewRouter()
+	NewProfilingHandler().RegisterRoutes(router.PathPrefix("/admin").Subrouter())
+
+	get := func(path string) *httptest.ResponseRecorder {
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, path, nil))
+		return rr
+	}
+
+	// The index lists the profiles, which are served under the admin prefix
+	rr := get("/admin/debug/pprof/")
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Contains(t, rr.Body.String(), "goroutine")
+
+	rr = get("/admin/debug/pprof/goroutine?debug=1")
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Contains(t, rr.Body.String(), "TestProfilingHandler")
+
+	rr = get("/admin/debug/pprof/heap")
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Equal(t, "application/octet-stream", rr.Header().Get("Content-Type"))
+
+	assert.Equal(t, http.StatusNotFound, get("/admin/debug/pprof/unknown").Code)
+
+	runtime.GC()
+	rr = get("/admin/debug/runtime")
+	require.Equal(t, http.StatusOK, rr.Code)
+	var stats RuntimeStats
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &stats))
+	assert.Greater(t, stats.Goroutines, 0)
+	assert.Greater(t, stats.HeapAllocBytes, uint64(0))
+	assert.Greater(t, stats.GCCycles, uint32(0))
+	assert.NotNil(t, stats.LastGC)
+	assert.LessOrEqual(t, stats.GCPauses.P50, stats.GCPauses.P99)
+	assert.LessOrEqual(t, stats.GCPauses.P99, stats.GCPauses.Max)
+}
+
+func TestProfilingHandler_WithinWriteTimeout(t *testing.T) {
+	tests := []struct {
+		name         string
+		query        string
+		writeTimeout time.Duration
+		expected     string
+	}{
+		{name: "default profile", writeTimeout: 30 * time.Second, expected: "25"},
+		{name: "long profile", query: "?seconds=60", writeTimeout: 30 * time.Second, expected: "25"},
+		{name: "short profile", query: "?seconds=10", writeTimeout: 30 * time.Second, expected: "10"},
+		{name: "short write timeout", query: "?seconds=10", writeTimeout: 3 * time.Second, expected: "1"},
+		{name: "no write timeout", query: "?seconds=60", expected: "60"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var seconds string
+			handler := withinWriteTimeout(func(w http.ResponseWriter, r *http.Request) {
+				seconds = r.FormValue("seconds")
+			}, 30)
+
+			req := httptest.NewRequest(http.MethodGet, "/admin/debug/pprof/profile"+tt.query, nil)
+			srv := &http.Server{WriteTimeout: tt.writeTimeout}
+			handler(httptest.NewRecorder(), req.WithContext(context.WithValue(req.Context(), http.ServerContextKey, srv)))
+
+			assert.Equal(t, tt.expected, seconds)
+		})
+	}
+}
+
+func TestProfilingHandler_AdminOnly(t *testing.T) {
+	ctx := context.Background()
+	jwtAuth := auth.NewJWTAuth([]byte("profiling-secret"), "api-gateway", time.Hour, &MockLogger{})
+	router := NewRouter(nil, nil, &MockLogger{}, usecase.NewAuthUseCase(jwtAuth, &MockLogger{}), nil, &config.Config{}, NewProfilingHandler())
+	handler := router.Setup()
+
+	token := func(roles ...string) string {
+		t.Helper()
+		token, err := jwtAuth.GenerateToken(ctx, "user-1", map[string]interface{}{"roles": roles})
+		require.NoError(t, err)
+		return token
+	}
+	viewer, admin := token("viewer"), token("admin")
+
+	// Profiles expose the memory of the process, so only administrators may read them
+	for _, path := range []string{"/admin/debug/pprof/", "/admin/debug/pprof/heap", "/admin/debug/pprof/profile", "/admin/debug/runtime", "/api/debug/pprof/trace"} {
+		t.Run(path, func(t *testing.T) {
+			get := func(token string) int {
+				req := httptest.NewRequest(http.MethodGet, path, nil)
+				if token != "" {
+					req.Header.Set("Authorization", "Bearer "+token)
+				}
+				rr := httptest.NewRecorder()
+				handler.ServeHTTP(rr, req)
+				return rr.Code
+			}
+			assert.Equal(t, http.StatusUnauthorized, get(""))
+			assert.Equal(t, http.StatusForbidden, get(viewer))
+		})
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/admin/debug/pprof/", nil)
+	req.Header.Set("Authorization", "Bearer "+admin)
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, req)
+	assert.Equal(t, http.StatusOK, rr.Code)
+}
@@ -0,0 +1,104 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"api-gateway-sample/internal/application/usecase"
+	"api-gateway-sample/internal/domain/entity"
+	repomock "api-gateway-sample/internal/domain/repository/mock"
+	"api-gateway-sample/internal/infrastructure/auth"
+	"api-gateway-sample/internal/infrastructure/cache"
+	"api-gateway-sample/internal/infrastructure/client"
+	"api-gateway-sample/internal/infrastructure/ratelimit"
+	"api-gateway-sample/pkg/config"
+)
+
+// staticUpstream transforms requests as the gateway does and answers them without a network
+// round trip, so that benchmarks measure the gateway alone
+type staticUpstream struct {
+	*client.GatewayService
+}
+
+func (staticUpstream) RouteRequest(ctx context.Context, request *entity.Request) (*entity.Response, error) {
+	return &entity.Response{
+		RequestID:   request.ID,
+		StatusCode:  http.StatusOK,
+		Headers:     map[string][]string{"Content-Type": {"application/json"}},
+		Body:        []byte(`{"id":"order-1","status":"shipped"}`),
+		ContentType: "application/json",
+	}, nil
+}
+
+// newBenchmarkRouter serves a public catalog endpoint and an orders endpoint requiring
+// authentication and rate limiting, with the router's global middleware
+func newBenchmarkRouter(b *testing.B) (http.Handler, string) {
+	b.Helper()
+	ctx := context.Background()
+	serviceRepo := repomock.NewServiceRepositoryMock()
+	orders := entity.NewService("orders-id", "orders", "1.0.0", "", "http://orders:8080", 30, 3)
+	orders.AddEndpoint(entity.Endpoint{Path: "/api/v1/catalog", Methods: []string{http.MethodGet}})
+	orders.AddEndpoint(entity.Endpoint{Path: "/api/v1/orders/*", Methods: []string{http.MethodGet, http.MethodPost}, AuthRequired: true, RateLimit: 1 << 30})
+	if err := serviceRepo.Create(ctx, orders); err != nil {
+		b.Fatalf("Failed to create service: %v", err)
+	}
+
+	log := &MockLogger{}
+	jwtAuth := auth.NewJWTAuth([]byte("benchmark-secret-key-of-32-bytes!"), "api-gateway", time.Hour, log)
+	token, err := jwtAuth.GenerateToken(ctx, "alice", map[string]interface{}{"roles": []string{"admin"}})
+	if err != nil {
+		b.Fatalf("Failed to generate token: %v", err)
+	}
+
+	gateway := staticUpstream{client.NewGatewayService(client.NewHTTPClient(time.Second, log), log)}
+	cacheService := cache.NewCacheService(cache.NewMemoryCache(1000, time.Minute))
+	proxyUseCase := usecase.NewProxyUseCase(serviceRepo, gateway, jwtAuth, ratelimit.NewInMemoryRateLimiter(log), cacheService, log)
+	authUseCase := usecase.NewAuthUseCase(jwtAuth, log)
+	handler := NewHandler(proxyUseCase, authUseCase, nil, nil, nil, log)
+	router := NewRouter(handler, proxyUseCase, log, authUseCase, nil, &config.Config{})
+	return router.Setup(), token
+}
+
+// BenchmarkProxy measures a request through the whole proxy path: middleware, routing,
+// authentication, rate limiting, transformation and the upstream call
+func BenchmarkProxy(b *testing.B) {
+	router, token := newBenchmarkRouter(b)
+
+	order := `{"items":[` + strings.Repeat(`{"sku":"SKU-0001","quantity":1,"price":"19.99"},`, 80) + `{"sku":"SKU-0002","quantity":2,"price":"5.00"}]}`
+
+	benchmarks := []struct {
+		name    string
+		method  string
+		path    string
+		body    string
+		headers map[string]string
+	}{
+		{name: "Public", method: http.MethodGet, path: "/api/v1/catalog?page=2"},
+		{name: "Authenticated", method: http.MethodGet, path: "/api/v1/orders/42", headers: map[string]string{"Authorization": "Bearer " + token}},
+		{name: "Body", method: http.MethodPost, path: "/api/v1/orders/42", body: order, headers: map[string]string{"Authorization": "Bearer " + token, "Content-Type": "application/json"}},
+	}
+
+	for _, bm := range benchmarks {
+		b.Run(bm.name, func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				req := httptest.NewRequest(bm.method, bm.path, strings.NewReader(bm.body))
+				req.RemoteAddr = "10.0.0.1:52000"
+				req.Header.Set("Accept", "application/json")
+				req.Header.Set("User-Agent", "benchmark/1.0")
+				for name, value := range bm.headers {
+					req.Header.Set(name, value)
+				}
+				rr := httptest.NewRecorder()
+				router.ServeHTTP(rr, req)
+				if rr.Code != http.StatusOK {
+					b.Fatalf("Expected status 200, got %d: %s", rr.Code, rr.Body.String())
+				}
+			}
+		})
+	}
+}
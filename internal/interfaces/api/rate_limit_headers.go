@@ -0,0 +1,61 @@
+package api
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"api-gateway-sample/internal/domain/entity"
+	"api-gateway-sample/pkg/errors"
+)
+
+// Headers reporting the quota left to the client, named after the IETF RateLimit header fields draft
+const (
+	headerRateLimitLimit     = "RateLimit-Limit"
+	headerRateLimitRemaining = "RateLimit-Remaining"
+	headerRateLimitReset     = "RateLimit-Reset"
+)
+
+// Legacy names of the quota headers, for clients written against them
+const (
+	headerLegacyRateLimitLimit     = "X-RateLimit-Limit"
+	headerLegacyRateLimitRemaining = "X-RateLimit-Remaining"
+	headerLegacyRateLimitReset     = "X-RateLimit-Reset"
+)
+
+// SetLegacyRateLimitHeaders also reports the quota of rate-limited endpoints under the
+// X-RateLimit-* names, with the same values as the RateLimit-* headers
+func (h *Handler) SetLegacyRateLimitHeaders(enabled bool) {
+	h.legacyRateLimitHeaders = enabled
+}
+
+// writeRateLimitHeaders reports the quota left to the client when the request was rate
+// limited, with a Retry-After when it was rejected for running out of it
+func (h *Handler) writeRateLimitHeaders(w http.ResponseWriter, info *entity.RateLimitInfo, err error) {
+	status, ok := info.Status()
+	if !ok {
+		return
+	}
+
+	header := w.Header()
+	limit := strconv.Itoa(status.Limit)
+	remaining := strconv.Itoa(status.Remaining)
+	reset := strconv.Itoa(resetSeconds(status.Reset))
+	header.Set(headerRateLimitLimit, limit)
+	header.Set(headerRateLimitRemaining, remaining)
+	header.Set(headerRateLimitReset, reset)
+	if h.legacyRateLimitHeaders {
+		header.Set(headerLegacyRateLimitLimit, limit)
+		header.Set(headerLegacyRateLimitRemaining, remaining)
+		header.Set(headerLegacyRateLimitReset, reset)
+	}
+	if err != nil && status.Remaining == 0 && errors.IsRateLimitExceeded(err) {
+		header.Set("Retry-After", reset)
+	}
+}
+
+// resetSeconds rounds the time until a window ends up to whole seconds, so that clients
+// waiting for it do not retry early
+func resetSeconds(reset time.Duration) int {
+	return int((reset + time.Second - 1) / time.Second)
+}
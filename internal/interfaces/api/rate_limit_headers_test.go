@@ -0,0 +1,76 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"api-gateway-sample/internal/application/usecase"
+	"api-gateway-sample/internal/domain/entity"
+	repomock "api-gateway-sample/internal/domain/repository/mock"
+	"api-gateway-sample/internal/infrastructure/client"
+	"api-gateway-sample/internal/infrastructure/ratelimit"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestProxyHandler_RateLimitHeaders(t *testing.T) {
+	serviceRepo := repomock.NewServiceRepositoryMock()
+	orders := entity.NewService("orders-id", "orders", "1.0.0", "", "http://orders:8080", 30, 3)
+	orders.AddEndpoint(entity.Endpoint{Path: "/api/v1/catalog", Methods: []string{http.MethodGet}})
+	orders.AddEndpoint(entity.Endpoint{Path: "/api/v1/orders/*", Methods: []string{http.MethodGet}, RateLimit: 2})
+	require.NoError(t, serviceRepo.Create(context.Background(), orders))
+
+	log := &MockLogger{}
+	gateway := staticUpstream{client.NewGatewayService(client.NewHTTPClient(time.Second, log), log)}
+	proxyUseCase := usecase.NewProxyUseCase(serviceRepo, gateway, nil, ratelimit.NewInMemoryRateLimiter(log), nil, log)
+	handler := &Handler{proxyUseCase: proxyUseCase, logger: log}
+	proxy := func(path string) *httptest.ResponseRecorder {
+		rr := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		req.RemoteAddr = "10.0.0.1"
+		handler.ProxyHandler(rr, req)
+		return rr
+	}
+
+	// 1. Responses of rate-limited endpoints report the quota left in the window of the
+	// requested path
+	rr := proxy("/api/v1/orders/1")
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Equal(t, "2", rr.Header().Get("RateLimit-Limit"))
+	assert.Equal(t, "1", rr.Header().Get("RateLimit-Remaining"))
+	assert.Equal(t, "60", rr.Header().Get("RateLimit-Reset"))
+	assert.Empty(t, rr.Header().Get("X-RateLimit-Limit"))
+	assert.Empty(t, rr.Header().Get("Retry-After"))
+
+	// 2. Legacy names carry the same values
+	handler.SetLegacyRateLimitHeaders(true)
+	rr = proxy("/api/v1/orders/1")
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Equal(t, "0", rr.Header().Get("RateLimit-Remaining"))
+	assert.Equal(t, "2", rr.Header().Get("X-RateLimit-Limit"))
+	assert.Equal(t, "0", rr.Header().Get("X-RateLimit-Remaining"))
+	assert.Equal(t, "60", rr.Header().Get("X-RateLimit-Reset"))
+
+	// 3. Requests over the limit are rejected with when to retry
+	rr = proxy("/api/v1/orders/1")
+	assert.Equal(t, http.StatusTooManyRequests, rr.Code)
+	assert.Equal(t, "0", rr.Header().Get("RateLimit-Remaining"))
+	assert.Equal(t, "60", rr.Header().Get("Retry-After"))
+
+	// 4. Other paths are counted apart, and unlimited endpoints report nothing
+	rr = proxy("/api/v1/orders/2")
+	assert.Equal(t, "1", rr.Header().Get("RateLimit-Remaining"))
+	rr = proxy("/api/v1/catalog")
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Empty(t, rr.Header().Get("RateLimit-Limit"))
+}
+
+func TestResetSeconds(t *testing.T) {
+	assert.Equal(t, 0, resetSeconds(0))
+	assert.Equal(t, 1, resetSeconds(10*time.Millisecond))
+	assert.Equal(t, 60, resetSeconds(time.Minute))
+}
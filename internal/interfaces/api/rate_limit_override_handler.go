@@ -0,0 +1,127 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/gorilla/mux"
+
+	"api-gateway-sample/internal/application/dto"
+	"api-gateway-sample/internal/application/usecase"
+	"api-gateway-sample/pkg/errors"
+)
+
+// RateLimitOverrideHandler handles HTTP requests for per-consumer rate limit overrides
+type RateLimitOverrideHandler struct {
+	overrideUseCase *usecase.RateLimitOverrideUseCase
+}
+
+// NewRateLimitOverrideHandler creates a new RateLimitOverrideHandler instance
+func NewRateLimitOverrideHandler(overrideUseCase *usecase.RateLimitOverrideUseCase) *RateLimitOverrideHandler {
+	return &RateLimitOverrideHandler{
+		overrideUseCase: overrideUseCase,
+	}
+}
+
+// RegisterRoutes registers the rate limit override routes
+func (h *RateLimitOverrideHandler) RegisterRoutes(router *mux.Router) {
+	router.HandleFunc("/rate-limit-overrides", h.CreateOverride).Methods(http.MethodPost)
+	router.HandleFunc("/rate-limit-overrides", h.ListOverrides).Methods(http.MethodGet)
+	router.HandleFunc("/rate-limit-overrides/{id}", h.GetOverride).Methods(http.MethodGet)
+	router.HandleFunc("/rate-limit-overrides/{id}", h.UpdateOverride).Methods(http.MethodPut)
+	router.HandleFunc("/rate-limit-overrides/{id}", h.DeleteOverride).Methods(http.MethodDelete)
+}
+
+// CreateOverride handles rate limit override creation requests
+func (h *RateLimitOverrideHandler) CreateOverride(w http.ResponseWriter, r *http.Request) {
+	var req dto.RateLimitOverrideRequest
+	if !decodeRequest(w, r, &req) {
+		return
+	}
+
+	override, err := h.overrideUseCase.CreateOverride(r.Context(), &req)
+	if err != nil {
+		h.writeChangeError(w, r, err, "Failed to create rate limit override")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(override)
+}
+
+// GetOverride handles rate limit override retrieval requests
+func (h *RateLimitOverrideHandler) GetOverride(w http.ResponseWriter, r *http.Request) {
+	override, err := h.overrideUseCase.GetOverride(r.Context(), mux.Vars(r)["id"])
+	if err != nil {
+		if errors.IsNotFound(err) {
+			writeProblem(w, r, errors.StatusProblem(http.StatusNotFound, "Rate limit override not found"))
+			return
+		}
+		writeProblem(w, r, errors.StatusProblem(http.StatusInternalServerError, "Failed to get rate limit override"))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(override)
+}
+
+// ListOverrides handles rate limit override listing requests, optionally for the consumer given
+// by the consumer query parameter
+func (h *RateLimitOverrideHandler) ListOverrides(w http.ResponseWriter, r *http.Request) {
+	overrides, err := h.overrideUseCase.ListOverrides(r.Context(), r.URL.Query().Get("consumer"))
+	if err != nil {
+		writeProblem(w, r, errors.StatusProblem(http.StatusInternalServerError, "Failed to list rate limit overrides"))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(overrides)
+}
+
+// UpdateOverride handles rate limit override update requests
+func (h *RateLimitOverrideHandler) UpdateOverride(w http.ResponseWriter, r *http.Request) {
+	var req dto.RateLimitOverrideRequest
+	if !decodeRequest(w, r, &req) {
+		return
+	}
+
+	override, err := h.overrideUseCase.UpdateOverride(r.Context(), mux.Vars(r)["id"], &req)
+	if err != nil {
+		if errors.IsNotFound(err) {
+			writeProblem(w, r, errors.StatusProblem(http.StatusNotFound, "Rate limit override not found"))
+			return
+		}
+		h.writeChangeError(w, r, err, "Failed to update rate limit override")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(override)
+}
+
+// DeleteOverride handles rate limit override deletion requests
+func (h *RateLimitOverrideHandler) DeleteOverride(w http.ResponseWriter, r *http.Request) {
+	if err := h.overrideUseCase.DeleteOverride(r.Context(), mux.Vars(r)["id"]); err != nil {
+		if errors.IsNotFound(err) {
+			writeProblem(w, r, errors.StatusProblem(http.StatusNotFound, "Rate limit override not found"))
+			return
+		}
+		writeProblem(w, r, errors.StatusProblem(http.StatusInternalServerError, "Failed to delete rate limit override"))
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// writeChangeError writes the problem for an override that could not be created or updated
+func (h *RateLimitOverrideHandler) writeChangeError(w http.ResponseWriter, r *http.Request, err error, message string) {
+	switch {
+	case errors.IsInvalidInput(err):
+		writeProblem(w, r, errors.StatusProblem(http.StatusBadRequest, err.Error()))
+	case errors.IsAlreadyExists(err):
+		writeProblem(w, r, errors.StatusProblem(http.StatusConflict, "The consumer already has an override for this service and endpoint"))
+	default:
+		writeProblem(w, r, errors.StatusProblem(http.StatusInternalServerError, message))
+	}
+}
@@ -0,0 +1,97 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"runtime"
+	"strings"
+	"time"
+
+	"api-gateway-sample/internal/domain/entity"
+	"api-gateway-sample/pkg/errors"
+	"api-gateway-sample/pkg/logger"
+)
+
+// maxPanicFrames bounds the stack frames captured from a panicking goroutine
+const maxPanicFrames = 64
+
+// recoveryMiddleware turns a panic while serving a request into a 500 response. The panic is
+// logged with its stack and request context, counted on /metrics and sent to the error reporter.
+func (r *Router) recoveryMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		defer func() {
+			if err := recover(); err != nil {
+				if err == http.ErrAbortHandler {
+					panic(err)
+				}
+				report := newPanicReport(req, err, panicStack())
+				r.recordPanic(req, report)
+				r.writeError(w, req, errors.NewProblem(errors.ProblemInternal, "Internal server error"))
+			}
+		}()
+		next.ServeHTTP(w, req)
+	})
+}
+
+// recordPanic logs, counts and reports a recovered panic
+func (r *Router) recordPanic(req *http.Request, report *entity.PanicReport) {
+	fields := []interface{}{"error", report.Value, "panic_type", report.Type, "method", report.Method, "path", report.Path}
+	if report.Service != "" {
+		fields = append(fields, logger.FieldService, report.Service, "endpoint", report.Endpoint)
+	}
+	r.requestLogger(req).Error("Panic recovered", append(fields, "stack", report.StackTrace())...)
+
+	if r.handler != nil && r.handler.statsUseCase != nil {
+		r.handler.statsUseCase.RecordPanic()
+	}
+	if r.errorReporter != nil {
+		r.errorReporter.ReportPanic(report)
+	}
+}
+
+// newPanicReport describes a panic and the request it interrupted
+func newPanicReport(req *http.Request, value interface{}, stack []entity.StackFrame) *entity.PanicReport {
+	report := &entity.PanicReport{
+		Value:      fmt.Sprint(value),
+		Type:       fmt.Sprintf("%T", value),
+		Stack:      stack,
+		Time:       time.Now(),
+		RequestID:  req.Header.Get(requestIDHeader),
+		TraceID:    req.Header.Get(traceIDHeader),
+		Method:     req.Method,
+		Path:       req.URL.Path,
+		RemoteAddr: req.RemoteAddr,
+		UserAgent:  req.UserAgent(),
+	}
+	if route := entity.RouteFromContext(req.Context()); route != nil {
+		report.Service, report.Endpoint, _ = route.Route()
+	}
+	return report
+}
+
+// panicStack returns the stack of the panicking goroutine from the function that panicked,
+// skipping the deferred recovery and the runtime's panic machinery
+func panicStack() []entity.StackFrame {
+	pcs := make([]uintptr, maxPanicFrames+16)
+	frames := runtime.CallersFrames(pcs[:runtime.Callers(1, pcs)])
+
+	var stack []entity.StackFrame
+	panicking := false
+	for {
+		frame, more := frames.Next()
+		switch {
+		case frame.Function == "runtime.gopanic":
+			// The frames so far are the recovery's
+			panicking = true
+			stack = stack[:0]
+		case panicking && len(stack) == 0 && strings.HasPrefix(frame.Function, "runtime."):
+			// Runtime errors, such as a nil dereference, panic from runtime.sigpanic and the like
+		case len(stack) < maxPanicFrames:
+			stack = append(stack, entity.StackFrame{Function: frame.Function, File: frame.File, Line: frame.Line})
+		}
+		if !more {
+			break
+		}
+	}
+	return stack
+}
@@ -0,0 +1,94 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"api-gateway-sample/internal/application/usecase"
+	"api-gateway-sample/internal/domain/entity"
+	repomock "api-gateway-sample/internal/domain/repository/mock"
+	"api-gateway-sample/internal/infrastructure/metrics"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// recordingReporter keeps the panics reported to it
+type recordingReporter struct {
+	reports []*entity.PanicReport
+}
+
+func (r *recordingReporter) ReportPanic(report *entity.PanicReport) {
+	r.reports = append(r.reports, report)
+}
+
+type order struct {
+	id string
+}
+
+func (o *order) ID() string {
+	return o.id
+}
+
+// panickingHandler dereferences a nil order, panicking from inside the runtime
+func panickingHandler(w http.ResponseWriter, req *http.Request) {
+	var orders map[string]*order
+	w.Write([]byte(orders["42"].ID()))
+}
+
+func TestRecoveryMiddleware_ReportsPanic(t *testing.T) {
+	stats := usecase.NewStatsUseCase(repomock.NewServiceRepositoryMock(), metrics.NewSlidingWindowAggregator(time.Minute, 6), &MockLogger{})
+	reporter := &recordingReporter{}
+	router := &Router{logger: &MockLogger{}, handler: &Handler{statsUseCase: stats}}
+	router.SetErrorReporter(reporter)
+
+	handler := router.recoveryMiddleware(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		entity.RouteFromContext(req.Context()).Set("orders", "/api/v1/orders/{id}", nil)
+		panickingHandler(w, req)
+	}))
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/orders/42?token=secret", nil)
+	req.Header.Set(requestIDHeader, "req-1")
+	req.Header.Set("User-Agent", "curl/8.0")
+	req = req.WithContext(entity.ContextWithRoute(req.Context(), &entity.RouteInfo{}))
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, req)
+
+	assert.Equal(t, http.StatusInternalServerError, rr.Code)
+	assert.Equal(t, int64(1), stats.Panics())
+
+	require.Len(t, reporter.reports, 1)
+	report := reporter.reports[0]
+	assert.True(t, strings.HasPrefix(report.Type, "runtime."), report.Type)
+	assert.Contains(t, report.Value, "nil pointer dereference")
+	assert.Equal(t, "req-1", report.RequestID)
+	assert.Equal(t, "/api/v1/orders/42", report.Path)
+	assert.Equal(t, "curl/8.0", report.UserAgent)
+	assert.Equal(t, "orders", report.Service)
+	assert.Equal(t, "/api/v1/orders/{id}", report.Endpoint)
+
+	// The stack starts at the function that panicked, below the recovery and the runtime
+	require.NotEmpty(t, report.Stack)
+	assert.True(t, strings.HasSuffix(report.Stack[0].Function, ".(*order).ID"), report.Stack[0].Function)
+	assert.True(t, strings.HasSuffix(report.Stack[1].Function, ".panickingHandler"), report.Stack[1].Function)
+	assert.Contains(t, report.StackTrace(), "recovery_test.go:")
+}
+
+func TestRecoveryMiddleware_AbortHandler(t *testing.T) {
+	reporter := &recordingReporter{}
+	router := &Router{logger: &MockLogger{}}
+	router.SetErrorReporter(reporter)
+
+	handler := router.recoveryMiddleware(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		panic(http.ErrAbortHandler)
+	}))
+
+	// The server aborts the response itself, so the panic is not an error
+	assert.PanicsWithValue(t, http.ErrAbortHandler, func() {
+		handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+	})
+	assert.Empty(t, reporter.reports)
+}
@@ -0,0 +1,72 @@
+package api
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"net/http"
+	"strings"
+
+	"api-gateway-sample/internal/domain/entity"
+	"api-gateway-sample/pkg/logger"
+)
+
+// The headers are named in their canonical form, which headers are stored under, so that
+// looking them up on every request does not allocate their canonical names
+const (
+	requestIDHeader   = "X-Request-Id"
+	traceIDHeader     = "X-Trace-Id"
+	traceparentHeader = "Traceparent"
+)
+
+// requestContextMiddleware assigns the request and trace IDs and installs a
+// request-scoped logger carrying them into the request context
+func (r *Router) requestContextMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		requestID := req.Header.Get(requestIDHeader)
+		if requestID == "" {
+			requestID = entity.NewRequestID()
+			req.Header.Set(requestIDHeader, requestID)
+		}
+		traceID := traceIDFromHeaders(req.Header)
+		req.Header.Set(traceIDHeader, traceID)
+
+		w.Header().Set(requestIDHeader, requestID)
+		w.Header().Set(traceIDHeader, traceID)
+
+		requestLogger := logger.With(r.logger,
+			logger.FieldRequestID, requestID,
+			logger.FieldTraceID, traceID,
+		)
+		ctx := logger.NewContext(req.Context(), requestLogger)
+
+		next.ServeHTTP(w, req.WithContext(ctx))
+	})
+}
+
+// requestLogger returns the request-scoped logger, or the router logger outside a request context
+func (r *Router) requestLogger(req *http.Request) logger.Logger {
+	return logger.FromContextOr(req.Context(), r.logger)
+}
+
+// traceIDFromHeaders extracts the trace ID from a W3C traceparent or X-Trace-ID header,
+// generating a new one when the request is not part of a trace
+func traceIDFromHeaders(header http.Header) string {
+	// traceparent: <version>-<32 hex trace-id>-<16 hex parent-id>-<flags>
+	if _, rest, ok := strings.Cut(header.Get(traceparentHeader), "-"); ok {
+		if traceID, rest, ok := strings.Cut(rest, "-"); ok && len(traceID) == 32 && strings.Count(rest, "-") == 1 {
+			return traceID
+		}
+	}
+	if traceID := header.Get(traceIDHeader); traceID != "" {
+		return traceID
+	}
+	return newTraceID()
+}
+
+func newTraceID() string {
+	var b [16]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		return entity.NewRequestID()
+	}
+	return hex.EncodeToString(b[:])
+}
@@ -0,0 +1,180 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"reflect"
+	"strings"
+	"time"
+
+	"api-gateway-sample/internal/domain/entity"
+	"api-gateway-sample/pkg/errors"
+
+	"github.com/go-playground/validator/v10"
+)
+
+// requestValidator checks request bodies against their `validate` struct tags. Fields are
+// reported by their JSON names.
+var requestValidator = newRequestValidator()
+
+func newRequestValidator() *validator.Validate {
+	v := validator.New(validator.WithRequiredStructEnabled())
+	v.RegisterTagNameFunc(func(field reflect.StructField) string {
+		name := strings.SplitN(field.Tag.Get("json"), ",", 2)[0]
+		if name == "-" {
+			return ""
+		}
+		if name == "" {
+			return field.Name
+		}
+		return name
+	})
+	// tagname accepts tag names that can be used as metrics labels
+	v.RegisterValidation("tagname", func(fl validator.FieldLevel) bool {
+		return entity.ValidTagName(fl.Field().String())
+	})
+	// baseurl accepts absolute URLs and the unix:/// URLs of unix socket upstreams
+	v.RegisterValidation("baseurl", func(fl validator.FieldLevel) bool {
+		u, err := url.Parse(fl.Field().String())
+		if err != nil || u.Scheme == "" {
+			return false
+		}
+		if u.Scheme == entity.UnixScheme {
+			return u.Host == "" && u.Path != ""
+		}
+		return u.Host != ""
+	})
+	v.RegisterValidation("slackchannel", func(fl validator.FieldLevel) bool {
+		return entity.ValidSlackChannel(fl.Field().String())
+	})
+	// afterfield accepts times after the time in the named sibling field, or any time when it is unset
+	v.RegisterValidation("afterfield", func(fl validator.FieldLevel) bool {
+		after, ok := fl.Field().Interface().(time.Time)
+		if !ok {
+			return false
+		}
+		before, ok := reflect.Indirect(fl.Parent()).FieldByName(fl.Param()).Interface().(*time.Time)
+		return !ok || before == nil || after.After(*before)
+	})
+	return v
+}
+
+// FieldError describes a request field that failed validation
+type FieldError struct {
+	// Field is the JSON path of the field, such as "endpoints[0].methods[1]"
+	Field   string `json:"field"`
+	Rule    string `json:"rule"`
+	Message string `json:"message"`
+}
+
+// decodeRequest decodes the JSON request body into req and validates it. On failure it
+// writes a 400 response and returns false.
+func decodeRequest(w http.ResponseWriter, r *http.Request, req interface{}) bool {
+	if err := json.NewDecoder(r.Body).Decode(req); err != nil {
+		writeProblem(w, r, errors.NewProblem(errors.ProblemInvalidInput, "Invalid request body"))
+		return false
+	}
+	return validateRequest(w, r, req)
+}
+
+// validateRequest validates a decoded request. On failure it writes a 400 response whose
+// "fields" member lists the invalid fields and returns false.
+func validateRequest(w http.ResponseWriter, r *http.Request, req interface{}) bool {
+	fields, err := ValidateRequest(req)
+	if err != nil {
+		writeProblem(w, r, errors.NewProblem(errors.ProblemInvalidInput, "Invalid request body"))
+		return false
+	}
+	if len(fields) == 0 {
+		return true
+	}
+
+	problem := errors.NewProblem(errors.ProblemValidationFailed, "The request body has invalid fields")
+	writeProblem(w, r, problem.With("fields", fields))
+	return false
+}
+
+// ValidateRequest validates a decoded request against its `validate` struct tags and returns
+// its invalid fields, none when it is valid. An error means req cannot be validated at all.
+func ValidateRequest(req interface{}) ([]FieldError, error) {
+	err := requestValidator.Struct(req)
+	if err == nil {
+		return nil, nil
+	}
+
+	validationErrors, ok := err.(validator.ValidationErrors)
+	if !ok {
+		return nil, err
+	}
+
+	fields := make([]FieldError, len(validationErrors))
+	for i, fieldErr := range validationErrors {
+		fields[i] = FieldError{
+			Field:   fieldPath(fieldErr.Namespace()),
+			Rule:    fieldErr.Tag(),
+			Message: fieldMessage(fieldErr),
+		}
+	}
+	return fields, nil
+}
+
+// fieldPath strips the request type from a validator namespace such as
+// "CreateServiceRequest.endpoints[0].path"
+func fieldPath(namespace string) string {
+	if i := strings.Index(namespace, "."); i >= 0 {
+		return namespace[i+1:]
+	}
+	return namespace
+}
+
+// fieldMessage describes a failed validation rule
+func fieldMessage(fieldErr validator.FieldError) string {
+	switch fieldErr.Tag() {
+	case "required":
+		return "is required"
+	case "url":
+		return "must be a valid URL"
+	case "baseurl":
+		return "must be a valid URL or a unix:/// socket path"
+	case "email":
+		return "must be a valid email address"
+	case "oneof":
+		return fmt.Sprintf("must be one of %s", fieldErr.Param())
+	case "tagname":
+		return "must be letters, digits and underscores not starting with a digit, and not a label the gateway sets"
+	case "slackchannel":
+		return "must be a lowercase Slack channel name without #"
+	case "required_without":
+		return fmt.Sprintf("is required without %s", jsonFieldName(fieldErr.Param()))
+	case "afterfield":
+		return fmt.Sprintf("must be after %s", jsonFieldName(fieldErr.Param()))
+	case "min":
+		return fmt.Sprintf("must be at least %s%s", fieldErr.Param(), lengthUnit(fieldErr.Kind()))
+	case "max":
+		return fmt.Sprintf("must be at most %s%s", fieldErr.Param(), lengthUnit(fieldErr.Kind()))
+	default:
+		return fmt.Sprintf("must satisfy %s", strings.TrimSpace(fieldErr.Tag()+" "+fieldErr.Param()))
+	}
+}
+
+// jsonFieldName returns the JSON name of a request field from its Go name, e.g. validFrom for ValidFrom
+func jsonFieldName(name string) string {
+	if name == "" {
+		return name
+	}
+	return strings.ToLower(name[:1]) + name[1:]
+}
+
+// lengthUnit names what min and max count for strings and collections
+func lengthUnit(kind reflect.Kind) string {
+	switch kind {
+	case reflect.String:
+		return " characters long"
+	case reflect.Slice, reflect.Array, reflect.Map:
+		return " items long"
+	default:
+		return ""
+	}
+}
@@ -0,0 +1,231 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"api-gateway-sample/pkg/errors"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// validationProblem is the problem details body of responses to invalid requests
+type validationProblem struct {
+	Type   string       `json:"type"`
+	Status int          `json:"status"`
+	Fields []FieldError `json:"fields"`
+}
+
+func TestCreateServiceValidationSimple(t *testing.T) {
+	// The use case must not be reached with an invalid request
+	mockUseCase := new(MockServiceUseCase)
+	handler := NewServiceHandler(mockUseCase)
+
+	body := `{
+		"name": "",
+		"baseUrl": "not a url",
+		"endpoints": [
+			{"path": "/api/v1/users", "methods": ["GET", "FETCH"], "rateLimit": -1,
+			 "circuitBreaker": {"failureThreshold": 2}}
+		]
+	}`
+	req := httptest.NewRequest(http.MethodPost, "/services", bytes.NewBufferString(body))
+	rr := httptest.NewRecorder()
+
+	handler.CreateService(rr, req)
+
+	assert.Equal(t, http.StatusBadRequest, rr.Code)
+	assert.Equal(t, errors.ProblemContentType, rr.Header().Get("Content-Type"))
+
+	var response validationProblem
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &response))
+	assert.Equal(t, errors.ProblemValidationFailed.Type, response.Type)
+	assert.Equal(t, http.StatusBadRequest, response.Status)
+	assert.ElementsMatch(t, []FieldError{
+		{Field: "name", Rule: "required", Message: "is required"},
+		{Field: "baseUrl", Rule: "baseurl", Message: "must be a valid URL or a unix:/// socket path"},
+		{Field: "endpoints[0].methods[1]", Rule: "oneof", Message: "must be one of GET POST PUT DELETE PATCH HEAD OPTIONS"},
+		{Field: "endpoints[0].rateLimit", Rule: "min", Message: "must be at least 0"},
+		{Field: "endpoints[0].circuitBreaker.failureThreshold", Rule: "max", Message: "must be at most 1"},
+	}, response.Fields)
+	mockUseCase.AssertNotCalled(t, "CreateService")
+}
+
+func TestCreateServiceMalformedBodySimple(t *testing.T) {
+	handler := NewServiceHandler(new(MockServiceUseCase))
+
+	req := httptest.NewRequest(http.MethodPost, "/services", bytes.NewBufferString("{"))
+	rr := httptest.NewRecorder()
+
+	handler.CreateService(rr, req)
+
+	assert.Equal(t, http.StatusBadRequest, rr.Code)
+}
+
+func TestCreatePipelineServiceValidationSimple(t *testing.T) {
+	mockUseCase := new(MockServiceUseCase)
+	handler := NewServiceHandler(mockUseCase)
+
+	body := `{
+		"name": "bff",
+		"baseUrl": "http://bff:8080",
+		"endpoints": [
+			{"path": "/api/v1/profile", "methods": ["GET"], "pipeline": {"steps": [
+				{"name": "user", "path": "/api/v1/users/me"},
+				{"name": "query", "path": "api/v1/orders", "onError": "retry"}
+			]}}
+		]
+	}`
+	req := httptest.NewRequest(http.MethodPost, "/services", bytes.NewBufferString(body))
+	rr := httptest.NewRecorder()
+
+	handler.CreateService(rr, req)
+
+	assert.Equal(t, http.StatusBadRequest, rr.Code)
+	var response validationProblem
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &response))
+	assert.ElementsMatch(t, []FieldError{
+		{Field: "endpoints[0].pipeline.steps[1].name", Rule: "ne", Message: "must satisfy ne query"},
+		{Field: "endpoints[0].pipeline.steps[1].path", Rule: "startswith", Message: "must satisfy startswith /"},
+		{Field: "endpoints[0].pipeline.steps[1].onError", Rule: "oneof", Message: "must be one of fail continue stop"},
+	}, response.Fields)
+	mockUseCase.AssertNotCalled(t, "CreateService")
+}
+
+func TestCreateBridgeServiceValidationSimple(t *testing.T) {
+	mockUseCase := new(MockServiceUseCase)
+	handler := NewServiceHandler(mockUseCase)
+
+	body := `{
+		"name": "ingest",
+		"baseUrl": "http://ingest:8080",
+		"endpoints": [
+			{"path": "/api/v1/events", "methods": ["POST"], "bridge": {"broker": "sqs", "delivery": "exactly-once"}}
+		]
+	}`
+	req := httptest.NewRequest(http.MethodPost, "/services", bytes.NewBufferString(body))
+	rr := httptest.NewRecorder()
+
+	handler.CreateService(rr, req)
+
+	assert.Equal(t, http.StatusBadRequest, rr.Code)
+	var response validationProblem
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &response))
+	assert.ElementsMatch(t, []FieldError{
+		{Field: "endpoints[0].bridge.broker", Rule: "oneof", Message: "must be one of kafka rabbitmq nats"},
+		{Field: "endpoints[0].bridge.topic", Rule: "required", Message: "is required"},
+		{Field: "endpoints[0].bridge.delivery", Rule: "oneof", Message: "must be one of at-least-once at-most-once"},
+	}, response.Fields)
+	mockUseCase.AssertNotCalled(t, "CreateService")
+}
+
+func TestCreateServicePriorityValidationSimple(t *testing.T) {
+	mockUseCase := new(MockServiceUseCase)
+	handler := NewServiceHandler(mockUseCase)
+
+	body := `{
+		"name": "users",
+		"baseUrl": "http://users:8080",
+		"endpoints": [
+			{"path": "/api/v1/users", "methods": ["GET"], "priority": 5000},
+			{"path": "/api/v1/users", "methods": ["POST"], "priority": -1001}
+		]
+	}`
+	req := httptest.NewRequest(http.MethodPost, "/services", bytes.NewBufferString(body))
+	rr := httptest.NewRecorder()
+
+	handler.CreateService(rr, req)
+
+	assert.Equal(t, http.StatusBadRequest, rr.Code)
+	var response validationProblem
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &response))
+	assert.ElementsMatch(t, []FieldError{
+		{Field: "endpoints[0].priority", Rule: "max", Message: "must be at most 1000"},
+		{Field: "endpoints[1].priority", Rule: "min", Message: "must be at least -1000"},
+	}, response.Fields)
+	mockUseCase.AssertNotCalled(t, "CreateService")
+}
+
+func TestCreateServiceTagValidationSimple(t *testing.T) {
+	mockUseCase := new(MockServiceUseCase)
+	handler := NewServiceHandler(mockUseCase)
+
+	body := `{
+		"name": "users",
+		"baseUrl": "http://users:8080",
+		"tags": {"team": "identity", "cost-center": "42"},
+		"endpoints": [
+			{"path": "/api/v1/users", "methods": ["GET"], "tags": {"service": "other"}}
+		]
+	}`
+	req := httptest.NewRequest(http.MethodPost, "/services", bytes.NewBufferString(body))
+	rr := httptest.NewRecorder()
+
+	handler.CreateService(rr, req)
+
+	assert.Equal(t, http.StatusBadRequest, rr.Code)
+	var response validationProblem
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &response))
+	message := "must be letters, digits and underscores not starting with a digit, and not a label the gateway sets"
+	assert.ElementsMatch(t, []FieldError{
+		{Field: "tags[cost-center]", Rule: "tagname", Message: message},
+		{Field: "endpoints[0].tags[service]", Rule: "tagname", Message: message},
+	}, response.Fields)
+	mockUseCase.AssertNotCalled(t, "CreateService")
+}
+
+func TestCreateServiceOwnerValidationSimple(t *testing.T) {
+	mockUseCase := new(MockServiceUseCase)
+	handler := NewServiceHandler(mockUseCase)
+
+	body := `{
+		"name": "users",
+		"baseUrl": "http://users:8080",
+		"owner": {"email": "identity", "slack": "#identity"},
+		"endpoints": [{"path": "/api/v1/users", "methods": ["GET"]}]
+	}`
+	req := httptest.NewRequest(http.MethodPost, "/services", bytes.NewBufferString(body))
+	rr := httptest.NewRecorder()
+
+	handler.CreateService(rr, req)
+
+	assert.Equal(t, http.StatusBadRequest, rr.Code)
+	var response validationProblem
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &response))
+	assert.ElementsMatch(t, []FieldError{
+		{Field: "owner.team", Rule: "required", Message: "is required"},
+		{Field: "owner.email", Rule: "email", Message: "must be a valid email address"},
+		{Field: "owner.slack", Rule: "slackchannel", Message: "must be a lowercase Slack channel name without #"},
+	}, response.Fields)
+	mockUseCase.AssertNotCalled(t, "CreateService")
+}
+
+func TestCreateServiceValidityValidationSimple(t *testing.T) {
+	mockUseCase := new(MockServiceUseCase)
+	handler := NewServiceHandler(mockUseCase)
+
+	body := `{
+		"name": "users",
+		"baseUrl": "http://users:8080",
+		"validUntil": "2026-01-01T00:00:00Z",
+		"endpoints": [
+			{"path": "/api/v1/users", "methods": ["GET"], "validFrom": "2026-02-01T00:00:00Z", "validUntil": "2026-01-01T00:00:00Z"}
+		]
+	}`
+	req := httptest.NewRequest(http.MethodPost, "/services", bytes.NewBufferString(body))
+	rr := httptest.NewRecorder()
+
+	handler.CreateService(rr, req)
+
+	assert.Equal(t, http.StatusBadRequest, rr.Code)
+	var response validationProblem
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &response))
+	assert.Equal(t, []FieldError{
+		{Field: "endpoints[0].validUntil", Rule: "afterfield", Message: "must be after validFrom"},
+	}, response.Fields)
+	mockUseCase.AssertNotCalled(t, "CreateService")
+}
@@ -0,0 +1,125 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/gorilla/mux"
+
+	"api-gateway-sample/internal/application/dto"
+	"api-gateway-sample/internal/application/usecase"
+	"api-gateway-sample/pkg/errors"
+)
+
+// RoutePolicyHandler handles HTTP requests for scheduled route policies
+type RoutePolicyHandler struct {
+	policyUseCase *usecase.RoutePolicyUseCase
+}
+
+// NewRoutePolicyHandler creates a new RoutePolicyHandler instance
+func NewRoutePolicyHandler(policyUseCase *usecase.RoutePolicyUseCase) *RoutePolicyHandler {
+	return &RoutePolicyHandler{
+		policyUseCase: policyUseCase,
+	}
+}
+
+// RegisterRoutes registers the route policy routes
+func (h *RoutePolicyHandler) RegisterRoutes(router *mux.Router) {
+	router.HandleFunc("/route-policies", h.CreatePolicy).Methods(http.MethodPost)
+	router.HandleFunc("/route-policies", h.ListPolicies).Methods(http.MethodGet)
+	router.HandleFunc("/route-policies/{id}", h.GetPolicy).Methods(http.MethodGet)
+	router.HandleFunc("/route-policies/{id}", h.UpdatePolicy).Methods(http.MethodPut)
+	router.HandleFunc("/route-policies/{id}", h.DeletePolicy).Methods(http.MethodDelete)
+}
+
+// CreatePolicy handles route policy creation requests
+func (h *RoutePolicyHandler) CreatePolicy(w http.ResponseWriter, r *http.Request) {
+	var req dto.RoutePolicyRequest
+	if !decodeRequest(w, r, &req) {
+		return
+	}
+
+	policy, err := h.policyUseCase.CreatePolicy(r.Context(), &req)
+	if err != nil {
+		h.writeChangeError(w, r, err, "Failed to create route policy")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(policy)
+}
+
+// GetPolicy handles route policy retrieval requests
+func (h *RoutePolicyHandler) GetPolicy(w http.ResponseWriter, r *http.Request) {
+	policy, err := h.policyUseCase.GetPolicy(r.Context(), mux.Vars(r)["id"])
+	if err != nil {
+		if errors.IsNotFound(err) {
+			writeProblem(w, r, errors.StatusProblem(http.StatusNotFound, "Route policy not found"))
+			return
+		}
+		writeProblem(w, r, errors.StatusProblem(http.StatusInternalServerError, "Failed to get route policy"))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(policy)
+}
+
+// ListPolicies handles route policy listing requests, optionally for the service given
+// by the serviceId query parameter
+func (h *RoutePolicyHandler) ListPolicies(w http.ResponseWriter, r *http.Request) {
+	policies, err := h.policyUseCase.ListPolicies(r.Context(), r.URL.Query().Get("serviceId"))
+	if err != nil {
+		writeProblem(w, r, errors.StatusProblem(http.StatusInternalServerError, "Failed to list route policies"))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(policies)
+}
+
+// UpdatePolicy handles route policy update requests
+func (h *RoutePolicyHandler) UpdatePolicy(w http.ResponseWriter, r *http.Request) {
+	var req dto.RoutePolicyRequest
+	if !decodeRequest(w, r, &req) {
+		return
+	}
+
+	policy, err := h.policyUseCase.UpdatePolicy(r.Context(), mux.Vars(r)["id"], &req)
+	if err != nil {
+		if errors.IsNotFound(err) {
+			writeProblem(w, r, errors.StatusProblem(http.StatusNotFound, "Route policy not found"))
+			return
+		}
+		h.writeChangeError(w, r, err, "Failed to update route policy")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(policy)
+}
+
+// DeletePolicy handles route policy deletion requests
+func (h *RoutePolicyHandler) DeletePolicy(w http.ResponseWriter, r *http.Request) {
+	if err := h.policyUseCase.DeletePolicy(r.Context(), mux.Vars(r)["id"]); err != nil {
+		if errors.IsNotFound(err) {
+			writeProblem(w, r, errors.StatusProblem(http.StatusNotFound, "Route policy not found"))
+			return
+		}
+		writeProblem(w, r, errors.StatusProblem(http.StatusInternalServerError, "Failed to delete route policy"))
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// writeChangeError writes the problem for a policy that could not be created or updated
+func (h *RoutePolicyHandler) writeChangeError(w http.ResponseWriter, r *http.Request, err error, message string) {
+	switch {
+	case errors.IsInvalidInput(err):
+		writeProblem(w, r, errors.StatusProblem(http.StatusBadRequest, err.Error()))
+	default:
+		writeProblem(w, r, errors.StatusProblem(http.StatusInternalServerError, message))
+	}
+}
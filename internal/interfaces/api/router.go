@@ -3,58 +3,151 @@ package api
 import (
 	"context"
 	"net/http"
+	"strings"
+	"sync"
 	"time"
 
 	"api-gateway-sample/internal/application/usecase"
+	"api-gateway-sample/internal/domain/entity"
+	"api-gateway-sample/internal/domain/service"
+	"api-gateway-sample/pkg/config"
+	"api-gateway-sample/pkg/errors"
 	"api-gateway-sample/pkg/logger"
 
 	"github.com/gorilla/mux"
 )
 
+// HeaderAPIKey carries an API key issued through the developer portal
+const HeaderAPIKey = "X-API-Key"
+
+// RouteRegistrar registers a group of routes on a router
+type RouteRegistrar interface {
+	RegisterRoutes(router *mux.Router)
+}
+
 // Router handles HTTP routing
 type Router struct {
 	handler          *Handler
+	proxyUseCase     *usecase.ProxyUseCase
 	logger           logger.Logger
 	authUseCase      *usecase.AuthUseCase
 	rateLimitUseCase *usecase.RateLimitUseCase
+	config           *config.Config
+	adminHandlers    []RouteRegistrar
+	publicHandlers   []RouteRegistrar
+	apiKeyUseCase    *usecase.APIKeyUseCase
+	// sessionCookie names the cookie carrying the token of browser sessions, empty to ignore cookies
+	sessionCookie string
+	// errorReporter receives the panics recovered while serving requests, nil to only log them
+	errorReporter service.ErrorReporter
 }
 
 // NewRouter creates a new Router instance
 func NewRouter(
 	handler *Handler,
+	proxyUseCase *usecase.ProxyUseCase,
 	logger logger.Logger,
 	authUseCase *usecase.AuthUseCase,
 	rateLimitUseCase *usecase.RateLimitUseCase,
+	cfg *config.Config,
+	adminHandlers ...RouteRegistrar,
 ) *Router {
 	return &Router{
 		handler:          handler,
+		proxyUseCase:     proxyUseCase,
 		logger:           logger,
 		authUseCase:      authUseCase,
 		rateLimitUseCase: rateLimitUseCase,
+		config:           cfg,
+		adminHandlers:    adminHandlers,
 	}
 }
 
+// AddAdminHandler registers routes restricted to administrators besides those given to NewRouter
+func (r *Router) AddAdminHandler(handler RouteRegistrar) {
+	r.adminHandlers = append(r.adminHandlers, handler)
+}
+
+// AddPublicHandler registers routes served without authentication, such as the developer portal
+func (r *Router) AddPublicHandler(handler RouteRegistrar) {
+	r.publicHandlers = append(r.publicHandlers, handler)
+}
+
+// SetAPIKeyUseCase accepts API keys issued through the developer portal on the
+// endpoints of the service each key was issued for
+func (r *Router) SetAPIKeyUseCase(apiKeyUseCase *usecase.APIKeyUseCase) {
+	r.apiKeyUseCase = apiKeyUseCase
+}
+
+// SetErrorReporter reports the panics recovered while serving requests to an error tracker
+func (r *Router) SetErrorReporter(reporter service.ErrorReporter) {
+	r.errorReporter = reporter
+}
+
+// SetSessionCookie accepts the token in the named cookie from requests without an Authorization
+// header, authenticating browser users logged in through the OIDC login flow
+func (r *Router) SetSessionCookie(name string) {
+	r.sessionCookie = name
+}
+
 // Setup sets up the router
 func (r *Router) Setup() http.Handler {
 	router := mux.NewRouter()
 
 	// Apply global middleware
 	router.Use(
+		r.requestContextMiddleware,
 		r.loggingMiddleware,
 		r.recoveryMiddleware,
+		r.debugMiddleware,
+		r.securityHeadersMiddleware,
 		r.corsMiddleware,
 	)
 
 	// Health check route
 	router.HandleFunc("/health", r.handler.HealthCheckHandler).Methods(http.MethodGet)
 
+	// Public verification keys for tokens issued by the gateway
+	router.HandleFunc("/.well-known/jwks.json", r.handler.JWKSHandler).Methods(http.MethodGet)
+
+	// Prometheus metrics
+	if r.config != nil && r.config.Metrics.Enabled {
+		router.HandleFunc("/metrics", r.handler.MetricsHandler).Methods(http.MethodGet)
+	}
+
+	// Results of async requests, readable by anyone holding the unguessable status URL
+	router.HandleFunc(usecase.AsyncStatusPath+"{id}", r.handler.AsyncResultHandler).Methods(http.MethodGet)
+
+	// Public routes
+	for _, h := range r.publicHandlers {
+		h.RegisterRoutes(router)
+	}
+
 	// API routes
 	api := router.PathPrefix("/api").Subrouter()
-	api.Use(r.authMiddleware)
+	api.Use(r.endpointConstraintsMiddleware, r.authMiddleware)
+
+	// Management routes, restricted to administrators and served under both /api and /admin
+	admin := api.NewRoute().Subrouter()
+	admin.Use(r.adminMiddleware)
+	adminRoot := router.PathPrefix("/admin").Subrouter()
+	adminRoot.Use(r.authMiddleware, r.adminMiddleware)
+	for _, h := range r.adminHandlers {
+		h.RegisterRoutes(admin)
+		h.RegisterRoutes(adminRoot)
+	}
 
 	// Proxy routes
 	api.PathPrefix("/v1/").Handler(http.HandlerFunc(r.handler.ProxyHandler))
 
+	// Unmatched routes are answered with the error templates too
+	router.NotFoundHandler = http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		r.writeError(w, req, errors.NewProblem(errors.ProblemRouteNotFound, "No route matches "+req.URL.Path))
+	})
+	router.MethodNotAllowedHandler = http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		r.writeError(w, req, errors.NewProblem(errors.ProblemMethodNotAllowed, req.Method+" is not allowed on "+req.URL.Path))
+	})
+
 	return router
 }
 
@@ -64,42 +157,56 @@ func (r *Router) loggingMiddleware(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
 		start := time.Now()
 
-		// Create a response writer wrapper to capture status code
-		rw := &responseWriter{ResponseWriter: w}
+		// Wrap the response writer to capture the status code, reusing a pooled wrapper
+		rw := responseWriters.Get().(*responseWriter)
+		rw.ResponseWriter = w
+		defer rw.release()
+		// The proxy records the route it serves, to label the log line with its tags
+		route := entity.NewRouteInfo()
+		req = req.WithContext(entity.ContextWithRoute(req.Context(), route))
 
 		// Call next handler
 		next.ServeHTTP(rw, req)
 
 		// Log request details
-		r.logger.Info("Request completed",
+		requestLogger := r.requestLogger(req)
+		routeFields := routeLogFields(route)
+		fields := make([]interface{}, 0, 10+len(routeFields))
+		fields = append(fields,
 			"method", req.Method,
 			"path", req.URL.Path,
 			"status", rw.status,
 			"duration_ms", time.Since(start).Milliseconds(),
 			"remote_addr", req.RemoteAddr,
 		)
+		requestLogger.Info("Request completed", append(fields, routeFields...)...)
 	})
 }
 
-func (r *Router) recoveryMiddleware(next http.Handler) http.Handler {
-	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
-		defer func() {
-			if err := recover(); err != nil {
-				r.logger.Error("Panic recovered", "error", err)
-				http.Error(w, "Internal server error", http.StatusInternalServerError)
-			}
-		}()
-		next.ServeHTTP(w, req)
-	})
+// routeLogFields returns the log fields naming the route a request matched and its tags, none
+// when it matched no route
+func routeLogFields(route *entity.RouteInfo) []interface{} {
+	service, endpoint, tags := route.Route()
+	if service == "" {
+		return nil
+	}
+	return append([]interface{}{logger.FieldService, service, "endpoint", endpoint}, logger.TagFields(tags)...)
 }
 
+// corsMiddleware allows cross-origin requests and answers OPTIONS requests itself, so that
+// services need not implement them. Proxied paths list the methods their endpoints serve.
 func (r *Router) corsMiddleware(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
 		w.Header().Set("Access-Control-Allow-Origin", "*")
 		w.Header().Set("Access-Control-Allow-Methods", "GET, POST, PUT, DELETE, OPTIONS")
-		w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization")
+		w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization, "+HeaderAPIKey+", "+usecase.HeaderIdempotencyKey+", "+usecase.HeaderCallbackURL+", "+usecase.HeaderPriority)
 
 		if req.Method == http.MethodOptions {
+			if methods := r.routeMethods(req); len(methods) > 0 {
+				allow := strings.Join(methods, ", ")
+				w.Header().Set("Allow", allow)
+				w.Header().Set("Access-Control-Allow-Methods", allow)
+			}
 			w.WriteHeader(http.StatusOK)
 			return
 		}
@@ -116,36 +223,186 @@ func (r *Router) authMiddleware(next http.Handler) http.Handler {
 			return
 		}
 
-		// Get token from Authorization header
-		token := req.Header.Get("Authorization")
+		// Authenticate Basic credentials against the directory when it is configured
+		if username, password, ok := req.BasicAuth(); ok && r.authUseCase.SupportsCredentials() {
+			claims, err := r.authUseCase.ValidateCredentials(req.Context(), username, password)
+			if err != nil {
+				r.requestLogger(req).Warn("Credential validation failed", "user", username, "error", err)
+				w.Header().Set("WWW-Authenticate", `Basic realm="api-gateway"`)
+				r.writeError(w, req, errors.NewLocalizedProblem(errors.ProblemUnauthorized, "invalid-credentials", "Invalid credentials"))
+				return
+			}
+			next.ServeHTTP(w, req.WithContext(r.withPrincipal(req.Context(), claims)))
+			return
+		}
+
+		// Authenticate API keys issued through the developer portal
+		if secret := req.Header.Get(HeaderAPIKey); secret != "" && r.apiKeyUseCase != nil {
+			key, err := r.apiKeyUseCase.Authenticate(req.Context(), secret)
+			if err != nil {
+				r.requestLogger(req).Warn("API key authentication failed", "error", err)
+				r.writeError(w, req, errors.NewLocalizedProblem(errors.ProblemUnauthorized, "invalid-api-key", "Invalid API key"))
+				return
+			}
+			claims, ok := r.apiKeyClaims(req, key)
+			if !ok {
+				r.writeError(w, req, errors.NewLocalizedProblem(errors.ProblemForbidden, "api-key-not-valid-for-service", "API key is not valid for this service"))
+				return
+			}
+
+			// The key is a gateway credential and is not passed on to the upstream
+			req.Header.Del(HeaderAPIKey)
+			next.ServeHTTP(w, req.WithContext(r.withPrincipal(req.Context(), claims)))
+			return
+		}
+
+		// Get token from Authorization header, or from the session cookie of browser users
+		token := strings.TrimPrefix(req.Header.Get("Authorization"), "Bearer ")
 		if token == "" {
-			http.Error(w, "Unauthorized", http.StatusUnauthorized)
+			token = r.sessionToken(req)
+		}
+		if token == "" {
+			if r.allowsAnonymous(req) || r.externalAuthEnabled() {
+				next.ServeHTTP(w, req)
+				return
+			}
+			r.writeError(w, req, errors.NewLocalizedProblem(errors.ProblemUnauthorized, "missing-credentials", "Unauthorized"))
 			return
 		}
 
 		// Validate token
 		claims, err := r.authUseCase.ValidateToken(req.Context(), token)
 		if err != nil {
-			http.Error(w, "Invalid token", http.StatusUnauthorized)
+			// Tokens the gateway did not issue are judged by the external authorizer
+			if r.externalAuthEnabled() {
+				next.ServeHTTP(w, req)
+				return
+			}
+			r.writeError(w, req, errors.NewLocalizedProblem(errors.ProblemUnauthorized, "invalid-token", "Invalid token"))
 			return
 		}
 
-		// Add claims to request context
-		ctx := req.Context()
-		for key, value := range claims {
-			ctx = context.WithValue(ctx, key, value)
+		next.ServeHTTP(w, req.WithContext(r.withPrincipal(req.Context(), claims)))
+	})
+}
+
+// sessionToken returns the token of the request's session cookie and removes the cookie from the
+// request, as it is a gateway credential and is not passed on to the upstream
+func (r *Router) sessionToken(req *http.Request) string {
+	if r.sessionCookie == "" {
+		return ""
+	}
+	session, err := req.Cookie(r.sessionCookie)
+	if err != nil {
+		return ""
+	}
+
+	cookies := req.Cookies()
+	req.Header.Del("Cookie")
+	for _, cookie := range cookies {
+		if cookie.Name != r.sessionCookie {
+			req.AddCookie(cookie)
 		}
+	}
+	return session.Value
+}
+
+// withPrincipal adds the authenticated principal to the request context
+func (r *Router) withPrincipal(ctx context.Context, claims map[string]interface{}) context.Context {
+	principal := entity.NewPrincipal(claims)
+	ctx = entity.ContextWithPrincipal(ctx, principal)
+	return logger.WithFields(ctx, logger.FieldUserID, principal.UserID)
+}
+
+// externalAuthEnabled reports whether proxied requests are authorized by an external service
+func (r *Router) externalAuthEnabled() bool {
+	return r.config != nil && r.config.Auth.External.URL != ""
+}
 
-		next.ServeHTTP(w, req.WithContext(ctx))
+// adminMiddleware rejects requests whose principal does not have the admin role
+func (r *Router) adminMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		principal, ok := entity.PrincipalFromContext(req.Context())
+		if !ok {
+			r.writeError(w, req, errors.NewLocalizedProblem(errors.ProblemUnauthorized, "missing-credentials", "Unauthorized"))
+			return
+		}
+		if !principal.HasRole("admin") {
+			r.writeError(w, req, errors.NewLocalizedProblem(errors.ProblemForbidden, "admin-role-required", "Forbidden"))
+			return
+		}
+		next.ServeHTTP(w, req)
 	})
 }
 
+// apiKeyClaims returns the claims of an API key principal if the request targets an endpoint
+// of the service the key was issued for. The key is granted the "<service>:<endpoint>" role of
+// that endpoint, which the default policy requires; keys never reach other routes.
+func (r *Router) apiKeyClaims(req *http.Request, key *entity.APIKey) (map[string]interface{}, bool) {
+	if r.proxyUseCase == nil {
+		return nil, false
+	}
+
+	service, endpoint, err := r.proxyUseCase.ResolveEndpoint(req.Context(), req.URL.Path, req.Method)
+	if err != nil || service.ID != key.ServiceID {
+		return nil, false
+	}
+	return map[string]interface{}{
+		"sub":        key.ID,
+		"roles":      []string{service.Name + ":" + endpoint.Path},
+		"consumer":   key.Consumer,
+		"service_id": key.ServiceID,
+		"plan":       key.Plan,
+		"amr":        []string{entity.AuthMethodAPIKey},
+	}, true
+}
+
+// routeMethods returns the methods the endpoints of a proxied path serve, none for other paths
+func (r *Router) routeMethods(req *http.Request) []string {
+	if r.proxyUseCase == nil || !strings.HasPrefix(req.URL.Path, proxyPathPrefix) {
+		return nil
+	}
+	methods, err := r.proxyUseCase.AllowedMethods(req.Context(), req.URL.Path)
+	if err != nil {
+		r.requestLogger(req).Warn("Failed to resolve the methods of a route", "error", err)
+		return nil
+	}
+	return methods
+}
+
+// allowsAnonymous reports whether the endpoint matched by the request is configured
+// without AuthRequired. Unresolvable routes require authentication.
+func (r *Router) allowsAnonymous(req *http.Request) bool {
+	if r.proxyUseCase == nil {
+		return false
+	}
+
+	_, endpoint, err := r.proxyUseCase.ResolveEndpoint(req.Context(), req.URL.Path, req.Method)
+	if err != nil {
+		return false
+	}
+	return !endpoint.AuthRequired
+}
+
 // responseWriter wraps http.ResponseWriter to capture status code
 type responseWriter struct {
 	http.ResponseWriter
 	status int
 }
 
+// responseWriters holds the responseWriter wrappers of requests that completed
+var responseWriters = sync.Pool{
+	New: func() interface{} {
+		return new(responseWriter)
+	},
+}
+
+// release returns the wrapper to the pool once the request completed
+func (rw *responseWriter) release() {
+	*rw = responseWriter{}
+	responseWriters.Put(rw)
+}
+
 func (rw *responseWriter) WriteHeader(status int) {
 	rw.status = status
 	rw.ResponseWriter.WriteHeader(status)
@@ -0,0 +1,75 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/gorilla/mux"
+
+	"api-gateway-sample/internal/application/usecase"
+	"api-gateway-sample/pkg/errors"
+)
+
+// SAMLHandler handles the SAML 2.0 service provider endpoints of the browser login flow
+type SAMLHandler struct {
+	samlUseCase *usecase.SAMLUseCase
+	sessions    *SessionCookie
+}
+
+// NewSAMLHandler creates a new SAMLHandler instance storing sessions in the given cookie
+func NewSAMLHandler(samlUseCase *usecase.SAMLUseCase, sessions *SessionCookie) *SAMLHandler {
+	return &SAMLHandler{
+		samlUseCase: samlUseCase,
+		sessions:    sessions,
+	}
+}
+
+// RegisterRoutes registers the SAML routes
+func (h *SAMLHandler) RegisterRoutes(router *mux.Router) {
+	router.HandleFunc("/auth/saml/metadata", h.Metadata).Methods(http.MethodGet)
+	router.HandleFunc("/auth/saml/login", h.Login).Methods(http.MethodGet)
+	router.HandleFunc("/auth/saml/acs", h.AssertionConsumerService).Methods(http.MethodPost)
+}
+
+// Metadata serves the service provider metadata to register with the identity provider
+func (h *SAMLHandler) Metadata(w http.ResponseWriter, r *http.Request) {
+	metadata, err := h.samlUseCase.Metadata()
+	if err != nil {
+		writeProblem(w, r, errors.StatusProblem(http.StatusInternalServerError, "Failed to generate metadata"))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/samlmetadata+xml")
+	w.Write(metadata)
+}
+
+// Login redirects the user to the identity provider, to return to the return_to path once logged in
+func (h *SAMLHandler) Login(w http.ResponseWriter, r *http.Request) {
+	requestURL, err := h.samlUseCase.BeginLogin(r.Context(), r.URL.Query().Get("return_to"))
+	if err != nil {
+		writeProblem(w, r, errors.StatusProblem(http.StatusInternalServerError, "Failed to start login"))
+		return
+	}
+
+	w.Header().Set("Cache-Control", "no-store")
+	http.Redirect(w, r, requestURL, http.StatusFound)
+}
+
+// AssertionConsumerService completes the login with the response the identity provider posted,
+// sets the session cookie and redirects the user to the path they started from
+func (h *SAMLHandler) AssertionConsumerService(w http.ResponseWriter, r *http.Request) {
+	r.Body = http.MaxBytesReader(w, r.Body, 2<<20)
+	if err := r.ParseForm(); err != nil {
+		writeProblem(w, r, errors.StatusProblem(http.StatusBadRequest, "Invalid form"))
+		return
+	}
+
+	token, returnTo, err := h.samlUseCase.CompleteLogin(r.Context(), r.PostForm.Get("SAMLResponse"))
+	if err != nil {
+		writeLoginError(w, r, err)
+		return
+	}
+
+	h.sessions.Set(w, token)
+	w.Header().Set("Cache-Control", "no-store")
+	http.Redirect(w, r, returnTo, http.StatusSeeOther)
+}
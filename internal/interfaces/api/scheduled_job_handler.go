@@ -0,0 +1,157 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/gorilla/mux"
+
+	"api-gateway-sample/internal/application/dto"
+	"api-gateway-sample/internal/application/usecase"
+	"api-gateway-sample/pkg/errors"
+)
+
+// ScheduledJobHandler handles HTTP requests for scheduled jobs
+type ScheduledJobHandler struct {
+	schedulerUseCase *usecase.SchedulerUseCase
+}
+
+// NewScheduledJobHandler creates a new ScheduledJobHandler instance
+func NewScheduledJobHandler(schedulerUseCase *usecase.SchedulerUseCase) *ScheduledJobHandler {
+	return &ScheduledJobHandler{
+		schedulerUseCase: schedulerUseCase,
+	}
+}
+
+// RegisterRoutes registers the scheduled job routes
+func (h *ScheduledJobHandler) RegisterRoutes(router *mux.Router) {
+	router.HandleFunc("/jobs", h.CreateJob).Methods(http.MethodPost)
+	router.HandleFunc("/jobs", h.ListJobs).Methods(http.MethodGet)
+	router.HandleFunc("/jobs/{id}", h.GetJob).Methods(http.MethodGet)
+	router.HandleFunc("/jobs/{id}", h.UpdateJob).Methods(http.MethodPut)
+	router.HandleFunc("/jobs/{id}", h.DeleteJob).Methods(http.MethodDelete)
+	router.HandleFunc("/jobs/{id}/runs", h.ListRuns).Methods(http.MethodGet)
+	router.HandleFunc("/jobs/{id}/run", h.RunJob).Methods(http.MethodPost)
+}
+
+// CreateJob handles scheduled job creation requests
+func (h *ScheduledJobHandler) CreateJob(w http.ResponseWriter, r *http.Request) {
+	var req dto.ScheduledJobRequest
+	if !decodeRequest(w, r, &req) {
+		return
+	}
+
+	job, err := h.schedulerUseCase.CreateJob(r.Context(), &req)
+	if err != nil {
+		if errors.IsInvalidInput(err) {
+			writeProblem(w, r, errors.StatusProblem(http.StatusBadRequest, err.Error()))
+			return
+		}
+		writeProblem(w, r, errors.StatusProblem(http.StatusInternalServerError, "Failed to create job"))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(job)
+}
+
+// GetJob handles scheduled job retrieval requests
+func (h *ScheduledJobHandler) GetJob(w http.ResponseWriter, r *http.Request) {
+	job, err := h.schedulerUseCase.GetJob(r.Context(), mux.Vars(r)["id"])
+	if err != nil {
+		if errors.IsNotFound(err) {
+			writeProblem(w, r, errors.StatusProblem(http.StatusNotFound, "Job not found"))
+			return
+		}
+		writeProblem(w, r, errors.StatusProblem(http.StatusInternalServerError, "Failed to get job"))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(job)
+}
+
+// ListJobs handles scheduled job listing requests
+func (h *ScheduledJobHandler) ListJobs(w http.ResponseWriter, r *http.Request) {
+	jobs, err := h.schedulerUseCase.ListJobs(r.Context())
+	if err != nil {
+		writeProblem(w, r, errors.StatusProblem(http.StatusInternalServerError, "Failed to list jobs"))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(jobs)
+}
+
+// UpdateJob handles scheduled job update requests
+func (h *ScheduledJobHandler) UpdateJob(w http.ResponseWriter, r *http.Request) {
+	var req dto.ScheduledJobRequest
+	if !decodeRequest(w, r, &req) {
+		return
+	}
+
+	job, err := h.schedulerUseCase.UpdateJob(r.Context(), mux.Vars(r)["id"], &req)
+	if err != nil {
+		if errors.IsNotFound(err) {
+			writeProblem(w, r, errors.StatusProblem(http.StatusNotFound, "Job not found"))
+			return
+		}
+		if errors.IsInvalidInput(err) {
+			writeProblem(w, r, errors.StatusProblem(http.StatusBadRequest, err.Error()))
+			return
+		}
+		writeProblem(w, r, errors.StatusProblem(http.StatusInternalServerError, "Failed to update job"))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(job)
+}
+
+// DeleteJob handles scheduled job deletion requests
+func (h *ScheduledJobHandler) DeleteJob(w http.ResponseWriter, r *http.Request) {
+	if err := h.schedulerUseCase.DeleteJob(r.Context(), mux.Vars(r)["id"]); err != nil {
+		if errors.IsNotFound(err) {
+			writeProblem(w, r, errors.StatusProblem(http.StatusNotFound, "Job not found"))
+			return
+		}
+		writeProblem(w, r, errors.StatusProblem(http.StatusInternalServerError, "Failed to delete job"))
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// ListRuns handles requests for the recent runs of a scheduled job
+func (h *ScheduledJobHandler) ListRuns(w http.ResponseWriter, r *http.Request) {
+	runs, err := h.schedulerUseCase.ListRuns(r.Context(), mux.Vars(r)["id"])
+	if err != nil {
+		if errors.IsNotFound(err) {
+			writeProblem(w, r, errors.StatusProblem(http.StatusNotFound, "Job not found"))
+			return
+		}
+		writeProblem(w, r, errors.StatusProblem(http.StatusInternalServerError, "Failed to list job runs"))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(runs)
+}
+
+// RunJob handles requests to run a scheduled job immediately. It answers with the outcome of
+// the run, also when the run failed.
+func (h *ScheduledJobHandler) RunJob(w http.ResponseWriter, r *http.Request) {
+	run, err := h.schedulerUseCase.RunJob(r.Context(), mux.Vars(r)["id"])
+	if err != nil {
+		if errors.IsNotFound(err) {
+			writeProblem(w, r, errors.StatusProblem(http.StatusNotFound, "Job not found"))
+			return
+		}
+		writeProblem(w, r, errors.StatusProblem(http.StatusInternalServerError, "Failed to run job"))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(run)
+}
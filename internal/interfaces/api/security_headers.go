@@ -0,0 +1,70 @@
+package api
+
+import (
+	"net/http"
+
+	"api-gateway-sample/pkg/config"
+)
+
+// securityHeadersMiddleware injects the configured security headers and strips
+// headers that leak upstream implementation details
+func (r *Router) securityHeadersMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		sw := &securityHeadersWriter{
+			ResponseWriter: w,
+			security:       r.config.Security,
+		}
+		next.ServeHTTP(sw, req)
+	})
+}
+
+// securityHeadersWriter applies the security policy right before the headers are sent,
+// so that headers copied from upstream responses are covered as well
+type securityHeadersWriter struct {
+	http.ResponseWriter
+	security    config.SecurityConfig
+	wroteHeader bool
+}
+
+// overrideContentSecurityPolicy replaces the configured Content-Security-Policy for a
+// response served by the gateway itself, such as an HTML page that needs its own scripts.
+// It has no effect when the security headers middleware is not in the chain.
+func overrideContentSecurityPolicy(w http.ResponseWriter, policy string) {
+	if sw, ok := w.(*securityHeadersWriter); ok {
+		sw.security.Headers.ContentSecurityPolicy = policy
+	}
+}
+
+func (w *securityHeadersWriter) WriteHeader(status int) {
+	if !w.wroteHeader {
+		w.wroteHeader = true
+		applySecurityPolicy(w.Header(), w.security)
+	}
+	w.ResponseWriter.WriteHeader(status)
+}
+
+func (w *securityHeadersWriter) Write(b []byte) (int, error) {
+	if !w.wroteHeader {
+		w.WriteHeader(http.StatusOK)
+	}
+	return w.ResponseWriter.Write(b)
+}
+
+// applySecurityPolicy sets the configured security headers and removes the stripped ones
+func applySecurityPolicy(header http.Header, security config.SecurityConfig) {
+	for _, name := range security.StripHeaders {
+		header.Del(name)
+	}
+
+	setIfNotEmpty(header, "Strict-Transport-Security", security.Headers.StrictTransportSecurity)
+	setIfNotEmpty(header, "X-Content-Type-Options", security.Headers.ContentTypeOptions)
+	setIfNotEmpty(header, "X-Frame-Options", security.Headers.FrameOptions)
+	setIfNotEmpty(header, "Content-Security-Policy", security.Headers.ContentSecurityPolicy)
+	setIfNotEmpty(header, "Referrer-Policy", security.Headers.ReferrerPolicy)
+}
+
+func setIfNotEmpty(header http.Header, key, value string) {
+	if value != "" {
+		header.Set(key, value)
+	}
+}
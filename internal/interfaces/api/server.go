@@ -2,6 +2,7 @@ package api
 
 import (
 	"context"
+	"crypto/tls"
 	"fmt"
 	"net/http"
 	"os"
@@ -9,6 +10,9 @@ import (
 	"syscall"
 	"time"
 
+	"golang.org/x/net/http2"
+	"golang.org/x/net/http2/h2c"
+
 	"api-gateway-sample/pkg/logger"
 )
 
@@ -17,6 +21,10 @@ type Server struct {
 	server   *http.Server
 	logger   logger.Logger
 	shutdown chan os.Signal
+
+	// certFile and keyFile serve HTTPS when set
+	certFile string
+	keyFile  string
 }
 
 // NewServer creates a new Server instance
@@ -33,6 +41,32 @@ func NewServer(handler http.Handler, port int, readTimeout, writeTimeout, shutdo
 	}
 }
 
+// EnableTLS serves HTTPS with the given certificate and private key files
+func (s *Server) EnableTLS(certFile string, keyFile string) {
+	s.certFile = certFile
+	s.keyFile = keyFile
+}
+
+// SetHTTP2 selects the HTTP/2 support of the listener. With TLS, HTTP/2 is negotiated through
+// ALPN unless it is disabled. Without TLS, cleartext HTTP/2 (h2c) is served when enabled, both
+// with prior knowledge and through the HTTP/1.1 Upgrade header.
+func (s *Server) SetHTTP2(enabled bool, cleartext bool) error {
+	if !enabled {
+		// A non-nil empty map turns off the automatic HTTP/2 support of net/http
+		s.server.TLSNextProto = map[string]func(*http.Server, *tls.Conn, http.Handler){}
+		return nil
+	}
+
+	h2Server := &http2.Server{IdleTimeout: s.server.IdleTimeout}
+	if err := http2.ConfigureServer(s.server, h2Server); err != nil {
+		return fmt.Errorf("failed to configure HTTP/2: %w", err)
+	}
+	if cleartext {
+		s.server.Handler = h2c.NewHandler(s.server.Handler, h2Server)
+	}
+	return nil
+}
+
 // Start starts the server
 func (s *Server) Start() error {
 	// Set up signal handling
@@ -40,8 +74,14 @@ func (s *Server) Start() error {
 
 	// Start server in a goroutine
 	go func() {
-		s.logger.Info("Starting server", "addr", s.server.Addr)
-		if err := s.server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+		s.logger.Info("Starting server", "addr", s.server.Addr, "tls", s.certFile != "")
+		var err error
+		if s.certFile != "" {
+			err = s.server.ListenAndServeTLS(s.certFile, s.keyFile)
+		} else {
+			err = s.server.ListenAndServe()
+		}
+		if err != nil && err != http.ErrServerClosed {
 			s.logger.Error("Server failed", "error", err)
 		}
 	}()
@@ -0,0 +1,56 @@
+package api
+
+import (
+	"context"
+	"crypto/tls"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/net/http2"
+)
+
+func TestServer_SetHTTP2Cleartext(t *testing.T) {
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(r.Proto))
+	})
+	server := NewServer(handler, 8080, time.Second, time.Second, time.Second, &MockLogger{})
+	require.NoError(t, server.SetHTTP2(true, true))
+
+	ts := httptest.NewServer(server.server.Handler)
+	defer ts.Close()
+
+	// A client with prior knowledge speaks HTTP/2 without TLS
+	client := &http.Client{Transport: &http2.Transport{
+		AllowHTTP: true,
+		DialTLSContext: func(ctx context.Context, network string, addr string, _ *tls.Config) (net.Conn, error) {
+			var dialer net.Dialer
+			return dialer.DialContext(ctx, network, addr)
+		},
+	}}
+	resp, err := client.Get(ts.URL)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	body, _ := io.ReadAll(resp.Body)
+	assert.Equal(t, "HTTP/2.0", string(body))
+
+	// HTTP/1.1 clients are still served
+	resp, err = http.Get(ts.URL)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	body, _ = io.ReadAll(resp.Body)
+	assert.Equal(t, "HTTP/1.1", string(body))
+}
+
+func TestServer_SetHTTP2Disabled(t *testing.T) {
+	server := NewServer(http.NotFoundHandler(), 8080, time.Second, time.Second, time.Second, &MockLogger{})
+	require.NoError(t, server.SetHTTP2(false, false))
+
+	assert.NotNil(t, server.server.TLSNextProto)
+	assert.Empty(t, server.server.TLSNextProto)
+}
@@ -3,6 +3,8 @@ package api
 import (
 	"encoding/json"
 	"net/http"
+	"strconv"
+	"strings"
 
 	"github.com/gorilla/mux"
 
@@ -30,27 +32,52 @@ func (h *ServiceHandler) RegisterRoutes(router *mux.Router) {
 	router.HandleFunc("/services/{id}", h.UpdateService).Methods(http.MethodPut)
 	router.HandleFunc("/services/{id}", h.DeleteService).Methods(http.MethodDelete)
 	router.HandleFunc("/services/name/{name}", h.FindServiceByName).Methods(http.MethodGet)
+	router.HandleFunc("/services/{id}/revisions", h.ListRevisions).Methods(http.MethodGet)
+	router.HandleFunc("/services/{id}/rollback/{revision}", h.RollbackService).Methods(http.MethodPost)
+	router.HandleFunc("/ownership", h.GetOwnership).Methods(http.MethodGet)
 }
 
-// CreateService handles service creation requests
+// CreateService handles service creation requests. With the dry_run query parameter the
+// creation is only planned.
 func (h *ServiceHandler) CreateService(w http.ResponseWriter, r *http.Request) {
+	dryRun, ok := dryRunParam(w, r, "dry_run")
+	if !ok {
+		return
+	}
+
 	var req dto.CreateServiceRequest
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		http.Error(w, "Invalid request body", http.StatusBadRequest)
+	if !decodeRequest(w, r, &req) {
 		return
 	}
 
-	service, err := h.serviceUseCase.CreateService(r.Context(), &req)
+	var service *dto.ServiceResponse
+	var plan *dto.ServicePlan
+	var err error
+	if dryRun {
+		plan, err = h.serviceUseCase.PlanCreateService(r.Context(), &req)
+	} else {
+		service, err = h.serviceUseCase.CreateService(r.Context(), &req)
+	}
 	if err != nil {
+		if errors.IsRouteConflict(err) {
+			writeProblem(w, r, errors.ProblemOf(err, http.StatusConflict))
+			return
+		}
 		if errors.IsAlreadyExists(err) {
-			http.Error(w, "Service already exists", http.StatusConflict)
+			writeProblem(w, r, errors.StatusProblem(http.StatusConflict, "Service already exists"))
 			return
 		}
-		http.Error(w, "Failed to create service", http.StatusInternalServerError)
+		writeProblem(w, r, errors.StatusProblem(http.StatusInternalServerError, "Failed to create service"))
+		return
+	}
+	if plan != nil {
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(plan)
 		return
 	}
 
 	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("ETag", serviceETag(service.Revision))
 	w.WriteHeader(http.StatusCreated)
 	json.NewEncoder(w).Encode(service)
 }
@@ -63,43 +90,79 @@ func (h *ServiceHandler) GetService(w http.ResponseWriter, r *http.Request) {
 	service, err := h.serviceUseCase.GetService(r.Context(), id)
 	if err != nil {
 		if errors.IsNotFound(err) {
-			http.Error(w, "Service not found", http.StatusNotFound)
+			writeProblem(w, r, errors.StatusProblem(http.StatusNotFound, "Service not found"))
 			return
 		}
-		http.Error(w, "Failed to get service", http.StatusInternalServerError)
+		writeProblem(w, r, errors.StatusProblem(http.StatusInternalServerError, "Failed to get service"))
 		return
 	}
 
 	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("ETag", serviceETag(service.Revision))
 	json.NewEncoder(w).Encode(service)
 }
 
-// UpdateService handles service update requests
+// UpdateService handles service update requests. With the dry_run query parameter the update
+// is only planned.
 func (h *ServiceHandler) UpdateService(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
 	id := vars["id"]
 
+	dryRun, ok := dryRunParam(w, r, "dry_run")
+	if !ok {
+		return
+	}
+	revision, ok := ifMatchRevision(w, r)
+	if !ok {
+		return
+	}
+
 	var req dto.UpdateServiceRequest
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		http.Error(w, "Invalid request body", http.StatusBadRequest)
+	if !decodeRequest(w, r, &req) {
 		return
 	}
+	req.Revision = revision
 
-	service, err := h.serviceUseCase.UpdateService(r.Context(), id, &req)
+	var service *dto.ServiceResponse
+	var plan *dto.ServicePlan
+	var err error
+	if dryRun {
+		plan, err = h.serviceUseCase.PlanUpdateService(r.Context(), id, &req)
+	} else {
+		service, err = h.serviceUseCase.UpdateService(r.Context(), id, &req)
+	}
 	if err != nil {
 		if errors.IsNotFound(err) {
-			http.Error(w, "Service not found", http.StatusNotFound)
+			writeProblem(w, r, errors.StatusProblem(http.StatusNotFound, "Service not found"))
+			return
+		}
+		if errors.IsPreconditionFailed(err) {
+			writeProblem(w, r, errors.StatusProblem(http.StatusPreconditionFailed, "Service was modified, fetch it again and retry"))
+			return
+		}
+		if errors.IsForbidden(err) {
+			writeProblem(w, r, errors.StatusProblem(http.StatusForbidden, "Service is managed by the xDS control plane"))
+			return
+		}
+		if errors.IsRouteConflict(err) {
+			writeProblem(w, r, errors.ProblemOf(err, http.StatusConflict))
 			return
 		}
 		if errors.IsAlreadyExists(err) {
-			http.Error(w, "Service name already taken", http.StatusConflict)
+			writeProblem(w, r, errors.StatusProblem(http.StatusConflict, "Service name already taken"))
 			return
 		}
-		http.Error(w, "Failed to update service", http.StatusInternalServerError)
+		writeProblem(w, r, errors.StatusProblem(http.StatusInternalServerError, "Failed to update service"))
+		return
+	}
+	if plan != nil {
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(plan)
 		return
 	}
 
 	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("ETag", serviceETag(service.Revision))
 	json.NewEncoder(w).Encode(service)
 }
 
@@ -108,12 +171,25 @@ func (h *ServiceHandler) DeleteService(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
 	id := vars["id"]
 
-	if err := h.serviceUseCase.DeleteService(r.Context(), id); err != nil {
+	revision, ok := ifMatchRevision(w, r)
+	if !ok {
+		return
+	}
+
+	if err := h.serviceUseCase.DeleteService(r.Context(), id, revision); err != nil {
 		if errors.IsNotFound(err) {
-			http.Error(w, "Service not found", http.StatusNotFound)
+			writeProblem(w, r, errors.StatusProblem(http.StatusNotFound, "Service not found"))
+			return
+		}
+		if errors.IsPreconditionFailed(err) {
+			writeProblem(w, r, errors.StatusProblem(http.StatusPreconditionFailed, "Service was modified, fetch it again and retry"))
 			return
 		}
-		http.Error(w, "Failed to delete service", http.StatusInternalServerError)
+		if errors.IsForbidden(err) {
+			writeProblem(w, r, errors.StatusProblem(http.StatusForbidden, "Service is managed by the xDS control plane"))
+			return
+		}
+		writeProblem(w, r, errors.StatusProblem(http.StatusInternalServerError, "Failed to delete service"))
 		return
 	}
 
@@ -124,7 +200,7 @@ func (h *ServiceHandler) DeleteService(w http.ResponseWriter, r *http.Request) {
 func (h *ServiceHandler) ListServices(w http.ResponseWriter, r *http.Request) {
 	services, err := h.serviceUseCase.ListServices(r.Context())
 	if err != nil {
-		http.Error(w, "Failed to list services", http.StatusInternalServerError)
+		writeProblem(w, r, errors.StatusProblem(http.StatusInternalServerError, "Failed to list services"))
 		return
 	}
 
@@ -132,6 +208,18 @@ func (h *ServiceHandler) ListServices(w http.ResponseWriter, r *http.Request) {
 	json.NewEncoder(w).Encode(services)
 }
 
+// GetOwnership handles requests for the services of each owning team and the unowned services
+func (h *ServiceHandler) GetOwnership(w http.ResponseWriter, r *http.Request) {
+	report, err := h.serviceUseCase.GetOwnership(r.Context())
+	if err != nil {
+		writeProblem(w, r, errors.StatusProblem(http.StatusInternalServerError, "Failed to get service ownership"))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(report)
+}
+
 // FindServiceByName handles service lookup by name
 func (h *ServiceHandler) FindServiceByName(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
@@ -140,13 +228,133 @@ func (h *ServiceHandler) FindServiceByName(w http.ResponseWriter, r *http.Reques
 	service, err := h.serviceUseCase.FindServiceByName(r.Context(), name)
 	if err != nil {
 		if errors.IsNotFound(err) {
-			http.Error(w, "Service not found", http.StatusNotFound)
+			writeProblem(w, r, errors.StatusProblem(http.StatusNotFound, "Service not found"))
 			return
 		}
-		http.Error(w, "Failed to find service", http.StatusInternalServerError)
+		writeProblem(w, r, errors.StatusProblem(http.StatusInternalServerError, "Failed to find service"))
 		return
 	}
 
 	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("ETag", serviceETag(service.Revision))
 	json.NewEncoder(w).Encode(service)
 }
+
+// ListRevisions handles service revision history requests
+func (h *ServiceHandler) ListRevisions(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id := vars["id"]
+
+	revisions, err := h.serviceUseCase.ListRevisions(r.Context(), id)
+	if err != nil {
+		if errors.IsNotFound(err) {
+			writeProblem(w, r, errors.StatusProblem(http.StatusNotFound, "Service not found"))
+			return
+		}
+		writeProblem(w, r, errors.StatusProblem(http.StatusInternalServerError, "Failed to list service revisions"))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(revisions)
+}
+
+// RollbackService handles requests restoring a service to a stored revision. If-Match is
+// optional, so that a bad change can be reverted in one call.
+func (h *ServiceHandler) RollbackService(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id := vars["id"]
+
+	target, err := strconv.ParseInt(vars["revision"], 10, 64)
+	if err != nil || target <= 0 {
+		writeProblem(w, r, errors.StatusProblem(http.StatusBadRequest, "Invalid revision"))
+		return
+	}
+	var expected int64
+	if r.Header.Get("If-Match") != "" {
+		revision, ok := ifMatchRevision(w, r)
+		if !ok {
+			return
+		}
+		expected = revision
+	}
+
+	service, err := h.serviceUseCase.RollbackService(r.Context(), id, target, expected)
+	if err != nil {
+		if errors.IsNotFound(err) {
+			writeProblem(w, r, errors.StatusProblem(http.StatusNotFound, "Service revision not found"))
+			return
+		}
+		if errors.IsPreconditionFailed(err) {
+			writeProblem(w, r, errors.StatusProblem(http.StatusPreconditionFailed, "Service was modified, fetch it again and retry"))
+			return
+		}
+		if errors.IsForbidden(err) {
+			writeProblem(w, r, errors.StatusProblem(http.StatusForbidden, "Service is managed by the xDS control plane"))
+			return
+		}
+		if errors.IsRouteConflict(err) {
+			writeProblem(w, r, errors.ProblemOf(err, http.StatusConflict))
+			return
+		}
+		if errors.IsAlreadyExists(err) {
+			writeProblem(w, r, errors.StatusProblem(http.StatusConflict, "Service name already taken"))
+			return
+		}
+		writeProblem(w, r, errors.StatusProblem(http.StatusInternalServerError, "Failed to roll back service"))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("ETag", serviceETag(service.Revision))
+	json.NewEncoder(w).Encode(service)
+}
+
+// dryRunParam reads whether a change is only to be planned from the first of the given query
+// parameters that is set. An invalid value is answered with 400.
+func dryRunParam(w http.ResponseWriter, r *http.Request, names ...string) (bool, bool) {
+	for _, name := range names {
+		value := r.URL.Query().Get(name)
+		if value == "" {
+			continue
+		}
+		dryRun, err := strconv.ParseBool(value)
+		if err != nil {
+			writeProblem(w, r, errors.StatusProblem(http.StatusBadRequest, name+" must be true or false"))
+			return false, false
+		}
+		return dryRun, true
+	}
+	return false, true
+}
+
+// serviceETag returns the strong entity tag of a service revision
+func serviceETag(revision int64) string {
+	return `"` + strconv.FormatInt(revision, 10) + `"`
+}
+
+// ifMatchRevision reads the service revision a modification is conditional on from the
+// If-Match header. "*" matches any revision and yields zero. A missing header is answered
+// with 428 and a weak or malformed tag, which can never match, with 412.
+func ifMatchRevision(w http.ResponseWriter, r *http.Request) (int64, bool) {
+	header := strings.TrimSpace(r.Header.Get("If-Match"))
+	if header == "" {
+		writeProblem(w, r, errors.StatusProblem(http.StatusPreconditionRequired, "If-Match header with the service ETag is required"))
+		return 0, false
+	}
+	if header == "*" {
+		return 0, true
+	}
+
+	tag, err := strconv.Unquote(header)
+	if err != nil {
+		writeProblem(w, r, errors.StatusProblem(http.StatusPreconditionFailed, "If-Match does not match the service ETag"))
+		return 0, false
+	}
+	revision, err := strconv.ParseInt(tag, 10, 64)
+	if err != nil || revision <= 0 {
+		writeProblem(w, r, errors.StatusProblem(http.StatusPreconditionFailed, "If-Match does not match the service ETag"))
+		return 0, false
+	}
+	return revision, true
+}
@@ -0,0 +1,70 @@
+package api
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"api-gateway-sample/internal/application/usecase"
+	repomock "api-gateway-sample/internal/domain/repository/mock"
+
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestServiceConditionalRequestsSimple(t *testing.T) {
+	// Serve the service routes backed by the in-memory repository
+	router := mux.NewRouter()
+	NewServiceHandler(usecase.NewServiceUseCase(repomock.NewServiceRepositoryMock(), nil, nil)).RegisterRoutes(router)
+
+	send := func(method string, path string, ifMatch string, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, bytes.NewBufferString(body))
+		if ifMatch != "" {
+			req.Header.Set("If-Match", ifMatch)
+		}
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, req)
+		return rr
+	}
+	service := `{"name": "users", "baseUrl": "http://users:8080", "endpoints": [{"path": "/api/v1/users", "methods": ["GET"]}]}`
+
+	// 1. A new service starts at revision 1
+	rr := send(http.MethodPost, "/services", "", service)
+	require.Equal(t, http.StatusCreated, rr.Code)
+	assert.Equal(t, `"1"`, rr.Header().Get("ETag"))
+
+	// 2. Modifications require If-Match
+	rr = send(http.MethodPut, "/services/test-id", "", service)
+	assert.Equal(t, http.StatusPreconditionRequired, rr.Code)
+
+	// 3. A matching ETag updates the service and returns the next one
+	rr = send(http.MethodPut, "/services/test-id", `"1"`, service)
+	require.Equal(t, http.StatusOK, rr.Code)
+	assert.Equal(t, `"2"`, rr.Header().Get("ETag"))
+
+	// 4. Stale and weak ETags are rejected
+	rr = send(http.MethodPut, "/services/test-id", `"1"`, service)
+	assert.Equal(t, http.StatusPreconditionFailed, rr.Code)
+	rr = send(http.MethodDelete, "/services/test-id", `W/"2"`, "")
+	assert.Equal(t, http.StatusPreconditionFailed, rr.Code)
+
+	rr = send(http.MethodGet, "/services/test-id", "", "")
+	require.Equal(t, http.StatusOK, rr.Code)
+	assert.Equal(t, `"2"`, rr.Header().Get("ETag"))
+
+	// 5. The current ETag deletes the service
+	rr = send(http.MethodDelete, "/services/test-id", `"2"`, "")
+	assert.Equal(t, http.StatusNoContent, rr.Code)
+}
+
+func TestIfMatchWildcardSimple(t *testing.T) {
+	req := httptest.NewRequest(http.MethodPut, "/services/test-id", nil)
+	req.Header.Set("If-Match", "*")
+	revision, ok := ifMatchRevision(httptest.NewRecorder(), req)
+
+	assert.True(t, ok)
+	assert.Zero(t, revision)
+	assert.Equal(t, `"7"`, serviceETag(7))
+}
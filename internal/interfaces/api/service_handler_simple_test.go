@@ -29,6 +29,22 @@ func (m *MockServiceUseCase) CreateService(ctx context.Context, req *dto.CreateS
 	return args.Get(0).(*dto.ServiceResponse), args.Error(1)
 }
 
+func (m *MockServiceUseCase) PlanCreateService(ctx context.Context, req *dto.CreateServiceRequest) (*dto.ServicePlan, error) {
+	args := m.Called(ctx, req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*dto.ServicePlan), args.Error(1)
+}
+
+func (m *MockServiceUseCase) PlanUpdateService(ctx context.Context, id string, req *dto.UpdateServiceRequest) (*dto.ServicePlan, error) {
+	args := m.Called(ctx, id, req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*dto.ServicePlan), args.Error(1)
+}
+
 func (m *MockServiceUseCase) GetService(ctx context.Context, id string) (*dto.ServiceResponse, error) {
 	args := m.Called(ctx, id)
 	if args.Get(0) == nil {
@@ -45,8 +61,8 @@ func (m *MockServiceUseCase) UpdateService(ctx context.Context, id string, req *
 	return args.Get(0).(*dto.ServiceResponse), args.Error(1)
 }
 
-func (m *MockServiceUseCase) DeleteService(ctx context.Context, id string) error {
-	args := m.Called(ctx, id)
+func (m *MockServiceUseCase) DeleteService(ctx context.Context, id string, revision int64) error {
+	args := m.Called(ctx, id, revision)
 	return args.Error(0)
 }
 
@@ -66,6 +82,30 @@ func (m *MockServiceUseCase) FindServiceByName(ctx context.Context, name string)
 	return args.Get(0).(*dto.ServiceResponse), args.Error(1)
 }
 
+func (m *MockServiceUseCase) ListRevisions(ctx context.Context, id string) ([]*dto.ServiceRevisionResponse, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*dto.ServiceRevisionResponse), args.Error(1)
+}
+
+func (m *MockServiceUseCase) RollbackService(ctx context.Context, id string, revision int64, expected int64) (*dto.ServiceResponse, error) {
+	args := m.Called(ctx, id, revision, expected)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*dto.ServiceResponse), args.Error(1)
+}
+
+func (m *MockServiceUseCase) GetOwnership(ctx context.Context) (*dto.OwnershipReport, error) {
+	args := m.Called(ctx)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*dto.OwnershipReport), args.Error(1)
+}
+
 func TestCreateServiceSimple(t *testing.T) {
 	// Create mock use case
 	mockUseCase := new(MockServiceUseCase)
@@ -262,3 +302,80 @@ func TestListServicesSimple(t *testing.T) {
 	// Verify expectations
 	mockUseCase.AssertExpectations(t)
 }
+
+func TestRollbackServiceSimple(t *testing.T) {
+	// Create mock use case
+	mockUseCase := new(MockServiceUseCase)
+
+	// Create handler with the mock
+	handler := &ServiceHandler{
+		serviceUseCase: mockUseCase,
+	}
+
+	// Set up expectations
+	serviceResp := &dto.ServiceResponse{ID: "test-id", Name: "test-service", Revision: 4}
+	mockUseCase.On("RollbackService", mock.Anything, "test-id", int64(2), int64(0)).Return(serviceResp, nil)
+	mockUseCase.On("RollbackService", mock.Anything, "test-id", int64(9), int64(3)).Return(nil, errors.ErrNotFound)
+
+	router := mux.NewRouter()
+	handler.RegisterRoutes(router)
+
+	// Without If-Match the rollback is unconditional
+	req, _ := http.NewRequest(http.MethodPost, "/services/test-id/rollback/2", nil)
+	rr := httptest.NewRecorder()
+	router.ServeHTTP(rr, req)
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Equal(t, `"4"`, rr.Header().Get("ETag"))
+
+	// An unknown revision is not found
+	req, _ = http.NewRequest(http.MethodPost, "/services/test-id/rollback/9", nil)
+	req.Header.Set("If-Match", `"3"`)
+	rr = httptest.NewRecorder()
+	router.ServeHTTP(rr, req)
+	assert.Equal(t, http.StatusNotFound, rr.Code)
+
+	// A malformed revision is rejected before reaching the use case
+	req, _ = http.NewRequest(http.MethodPost, "/services/test-id/rollback/latest", nil)
+	rr = httptest.NewRecorder()
+	router.ServeHTTP(rr, req)
+	assert.Equal(t, http.StatusBadRequest, rr.Code)
+
+	// Verify expectations
+	mockUseCase.AssertExpectations(t)
+}
+
+func TestCreateServiceDryRunSimple(t *testing.T) {
+	// Create mock use case
+	mockUseCase := new(MockServiceUseCase)
+
+	// Create handler with the mock
+	handler := &ServiceHandler{
+		serviceUseCase: mockUseCase,
+	}
+
+	// Set up expectations
+	plan := &dto.ServicePlan{Action: "create", Service: &dto.ServiceResponse{Name: "test-service"}}
+	mockUseCase.On("PlanCreateService", mock.Anything, mock.Anything).Return(plan, nil)
+
+	// Create request
+	reqBody := `{"name": "test-service", "baseUrl": "http://localhost:8080", "endpoints": [{"path": "/api/test", "methods": ["GET"]}]}`
+	req, _ := http.NewRequest(http.MethodPost, "/services?dry_run=true", bytes.NewBufferString(reqBody))
+	rr := httptest.NewRecorder()
+	handler.CreateService(rr, req)
+
+	// The plan is returned instead of a created service
+	assert.Equal(t, http.StatusOK, rr.Code)
+	var respBody dto.ServicePlan
+	assert.NoError(t, json.Unmarshal(rr.Body.Bytes(), &respBody))
+	assert.Equal(t, "create", respBody.Action)
+
+	// An invalid flag is rejected
+	req, _ = http.NewRequest(http.MethodPost, "/services?dry_run=maybe", bytes.NewBufferString(reqBody))
+	rr = httptest.NewRecorder()
+	handler.CreateService(rr, req)
+	assert.Equal(t, http.StatusBadRequest, rr.Code)
+
+	// Verify expectations
+	mockUseCase.AssertExpectations(t)
+	mockUseCase.AssertNotCalled(t, "CreateService", mock.Anything, mock.Anything)
+}
@@ -9,9 +9,14 @@ import (
 // ServiceUseCase defines the interface for service use cases
 type ServiceUseCase interface {
 	CreateService(ctx context.Context, req *dto.CreateServiceRequest) (*dto.ServiceResponse, error)
+	PlanCreateService(ctx context.Context, req *dto.CreateServiceRequest) (*dto.ServicePlan, error)
 	GetService(ctx context.Context, id string) (*dto.ServiceResponse, error)
 	UpdateService(ctx context.Context, id string, req *dto.UpdateServiceRequest) (*dto.ServiceResponse, error)
-	DeleteService(ctx context.Context, id string) error
+	PlanUpdateService(ctx context.Context, id string, req *dto.UpdateServiceRequest) (*dto.ServicePlan, error)
+	DeleteService(ctx context.Context, id string, revision int64) error
 	ListServices(ctx context.Context) ([]*dto.ServiceResponse, error)
 	FindServiceByName(ctx context.Context, name string) (*dto.ServiceResponse, error)
+	ListRevisions(ctx context.Context, id string) ([]*dto.ServiceRevisionResponse, error)
+	RollbackService(ctx context.Context, id string, revision int64, expected int64) (*dto.ServiceResponse, error)
+	GetOwnership(ctx context.Context) (*dto.OwnershipReport, error)
 }
@@ -0,0 +1,73 @@
+package api
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gorilla/mux"
+
+	"api-gateway-sample/pkg/errors"
+)
+
+// SessionCookie stores the sessions of browser users logged in with OIDC or SAML. The cookie
+// is hidden from scripts and not sent on cross-site requests other than top-level navigation.
+type SessionCookie struct {
+	name   string
+	ttl    time.Duration
+	secure bool
+}
+
+// NewSessionCookie creates a new SessionCookie instance. Cookies expire with their session token
+// after ttl; secure restricts them to HTTPS.
+func NewSessionCookie(name string, ttl time.Duration, secure bool) *SessionCookie {
+	return &SessionCookie{
+		name:   name,
+		ttl:    ttl,
+		secure: secure,
+	}
+}
+
+// RegisterRoutes registers the logout route
+func (c *SessionCookie) RegisterRoutes(router *mux.Router) {
+	router.HandleFunc("/auth/logout", c.Logout).Methods(http.MethodPost)
+}
+
+// Logout clears the session cookie. The session token stays valid until it expires.
+func (c *SessionCookie) Logout(w http.ResponseWriter, r *http.Request) {
+	c.write(w, "", -1)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// Name returns the name of the cookie
+func (c *SessionCookie) Name() string {
+	return c.name
+}
+
+// Set stores a session token in the cookie
+func (c *SessionCookie) Set(w http.ResponseWriter, token string) {
+	c.write(w, token, int(c.ttl.Seconds()))
+}
+
+func (c *SessionCookie) write(w http.ResponseWriter, token string, maxAge int) {
+	http.SetCookie(w, &http.Cookie{
+		Name:     c.name,
+		Value:    token,
+		Path:     "/",
+		MaxAge:   maxAge,
+		HttpOnly: true,
+		Secure:   c.secure,
+		SameSite: http.SameSiteLaxMode,
+	})
+}
+
+// writeLoginError writes the problem for a login that could not be completed
+func writeLoginError(w http.ResponseWriter, r *http.Request, err error) {
+	switch {
+	case errors.IsInvalidInput(err):
+		writeProblem(w, r, errors.StatusProblem(http.StatusBadRequest, err.Error()))
+	case errors.IsUnauthorized(err):
+		writeProblem(w, r, errors.StatusProblem(http.StatusUnauthorized, "Login failed"))
+	default:
+		writeProblem(w, r, errors.StatusProblem(http.StatusInternalServerError, "Failed to complete login"))
+	}
+}
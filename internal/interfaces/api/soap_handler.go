@@ -0,0 +1,55 @@
+package api
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+
+	"github.com/gorilla/mux"
+
+	"api-gateway-sample/internal/application/usecase"
+	"api-gateway-sample/pkg/errors"
+)
+
+// maxWSDLSize bounds the WSDL documents accepted for template generation
+const maxWSDLSize = 10 << 20
+
+// SOAPHandler handles HTTP requests for SOAP endpoint configuration
+type SOAPHandler struct {
+	soapUseCase *usecase.SOAPUseCase
+}
+
+// NewSOAPHandler creates a new SOAPHandler instance
+func NewSOAPHandler(soapUseCase *usecase.SOAPUseCase) *SOAPHandler {
+	return &SOAPHandler{
+		soapUseCase: soapUseCase,
+	}
+}
+
+// RegisterRoutes registers the SOAP routes
+func (h *SOAPHandler) RegisterRoutes(router *mux.Router) {
+	router.HandleFunc("/soap/endpoints", h.GenerateEndpoints).Methods(http.MethodPost)
+}
+
+// GenerateEndpoints handles requests to generate the SOAP endpoint configuration of the
+// operations of the WSDL document sent as the request body
+func (h *SOAPHandler) GenerateEndpoints(w http.ResponseWriter, r *http.Request) {
+	document, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxWSDLSize))
+	if err != nil {
+		writeProblem(w, r, errors.StatusProblem(http.StatusBadRequest, "Invalid request body"))
+		return
+	}
+
+	operations, err := h.soapUseCase.GenerateEndpoints(document)
+	if err != nil {
+		if errors.IsInvalidInput(err) {
+			writeProblem(w, r, errors.StatusProblem(http.StatusBadRequest, err.Error()))
+			return
+		}
+		writeProblem(w, r, errors.StatusProblem(http.StatusInternalServerError, "Failed to read WSDL document"))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(operations)
+}
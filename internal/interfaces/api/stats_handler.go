@@ -0,0 +1,60 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/gorilla/mux"
+
+	"api-gateway-sample/internal/application/usecase"
+	"api-gateway-sample/pkg/errors"
+)
+
+// StatsHandler handles HTTP requests for service traffic statistics
+type StatsHandler struct {
+	statsUseCase *usecase.StatsUseCase
+}
+
+// NewStatsHandler creates a new StatsHandler instance
+func NewStatsHandler(statsUseCase *usecase.StatsUseCase) *StatsHandler {
+	return &StatsHandler{
+		statsUseCase: statsUseCase,
+	}
+}
+
+// RegisterRoutes registers the statistics routes
+func (h *StatsHandler) RegisterRoutes(router *mux.Router) {
+	router.HandleFunc("/services/{id}/stats", h.GetServiceStats).Methods(http.MethodGet)
+	router.HandleFunc("/cache/stats", h.GetCacheStats).Methods(http.MethodGet)
+	router.HandleFunc("/slos", h.GetSLOStatuses).Methods(http.MethodGet)
+}
+
+// GetServiceStats handles service statistics requests
+func (h *StatsHandler) GetServiceStats(w http.ResponseWriter, r *http.Request) {
+	id := mux.Vars(r)["id"]
+
+	stats, err := h.statsUseCase.GetServiceStats(r.Context(), id)
+	if err != nil {
+		if errors.IsNotFound(err) {
+			writeProblem(w, r, errors.StatusProblem(http.StatusNotFound, "Service not found"))
+			return
+		}
+		writeProblem(w, r, errors.StatusProblem(http.StatusInternalServerError, "Failed to get service stats"))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(stats)
+}
+
+// GetCacheStats handles response cache statistics requests
+func (h *StatsHandler) GetCacheStats(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(h.statsUseCase.CacheStats(r.Context()))
+}
+
+// GetSLOStatuses handles SLO compliance requests
+func (h *StatsHandler) GetSLOStatuses(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(h.statsUseCase.SLOStatuses())
+}
@@ -0,0 +1,96 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/gorilla/mux"
+
+	"api-gateway-sample/internal/application/dto"
+	"api-gateway-sample/internal/application/usecase"
+	"api-gateway-sample/pkg/errors"
+)
+
+// WebhookHandler handles HTTP requests for webhook subscriptions
+type WebhookHandler struct {
+	webhookUseCase *usecase.WebhookUseCase
+}
+
+// NewWebhookHandler creates a new WebhookHandler instance
+func NewWebhookHandler(webhookUseCase *usecase.WebhookUseCase) *WebhookHandler {
+	return &WebhookHandler{
+		webhookUseCase: webhookUseCase,
+	}
+}
+
+// RegisterRoutes registers the webhook routes
+func (h *WebhookHandler) RegisterRoutes(router *mux.Router) {
+	router.HandleFunc("/webhooks", h.CreateWebhook).Methods(http.MethodPost)
+	router.HandleFunc("/webhooks", h.ListWebhooks).Methods(http.MethodGet)
+	router.HandleFunc("/webhooks/{id}", h.GetWebhook).Methods(http.MethodGet)
+	router.HandleFunc("/webhooks/{id}", h.DeleteWebhook).Methods(http.MethodDelete)
+}
+
+// CreateWebhook handles webhook registration requests
+func (h *WebhookHandler) CreateWebhook(w http.ResponseWriter, r *http.Request) {
+	var req dto.CreateWebhookRequest
+	if !decodeRequest(w, r, &req) {
+		return
+	}
+
+	webhook, err := h.webhookUseCase.CreateWebhook(r.Context(), &req)
+	if err != nil {
+		if errors.IsInvalidInput(err) {
+			writeProblem(w, r, errors.StatusProblem(http.StatusBadRequest, err.Error()))
+			return
+		}
+		writeProblem(w, r, errors.StatusProblem(http.StatusInternalServerError, "Failed to create webhook"))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(webhook)
+}
+
+// GetWebhook handles webhook retrieval requests
+func (h *WebhookHandler) GetWebhook(w http.ResponseWriter, r *http.Request) {
+	webhook, err := h.webhookUseCase.GetWebhook(r.Context(), mux.Vars(r)["id"])
+	if err != nil {
+		if errors.IsNotFound(err) {
+			writeProblem(w, r, errors.StatusProblem(http.StatusNotFound, "Webhook not found"))
+			return
+		}
+		writeProblem(w, r, errors.StatusProblem(http.StatusInternalServerError, "Failed to get webhook"))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(webhook)
+}
+
+// ListWebhooks handles webhook listing requests
+func (h *WebhookHandler) ListWebhooks(w http.ResponseWriter, r *http.Request) {
+	webhooks, err := h.webhookUseCase.ListWebhooks(r.Context())
+	if err != nil {
+		writeProblem(w, r, errors.StatusProblem(http.StatusInternalServerError, "Failed to list webhooks"))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(webhooks)
+}
+
+// DeleteWebhook handles webhook deletion requests
+func (h *WebhookHandler) DeleteWebhook(w http.ResponseWriter, r *http.Request) {
+	if err := h.webhookUseCase.DeleteWebhook(r.Context(), mux.Vars(r)["id"]); err != nil {
+		if errors.IsNotFound(err) {
+			writeProblem(w, r, errors.StatusProblem(http.StatusNotFound, "Webhook not found"))
+			return
+		}
+		writeProblem(w, r, errors.StatusProblem(http.StatusInternalServerError, "Failed to delete webhook"))
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
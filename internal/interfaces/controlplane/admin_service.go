@@ -0,0 +1,340 @@
+package controlplane
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/emptypb"
+	"google.golang.org/protobuf/types/known/structpb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	adminv1 "api-gateway-sample/api/proto/admin/v1"
+	"api-gateway-sample/internal/application/dto"
+	"api-gateway-sample/internal/domain/entity"
+	"api-gateway-sample/internal/domain/service"
+	"api-gateway-sample/internal/interfaces/api"
+	"api-gateway-sample/pkg/errors"
+)
+
+// watchBuffer is the number of events a watcher may fall behind before its stream is ended
+const watchBuffer = 64
+
+// HealthReporter reports the connectivity to the backing dependencies of the gateway
+type HealthReporter interface {
+	DependencyHealth() []*entity.DependencyHealth
+}
+
+// eventTypes maps the configuration events to the event types of the API
+var eventTypes = map[string]adminv1.ServiceEventType{
+	entity.EventServiceCreated: adminv1.ServiceEventType_SERVICE_EVENT_TYPE_CREATED,
+	entity.EventServiceUpdated: adminv1.ServiceEventType_SERVICE_EVENT_TYPE_UPDATED,
+	entity.EventServiceDeleted: adminv1.ServiceEventType_SERVICE_EVENT_TYPE_DELETED,
+	entity.EventRoutesReloaded: adminv1.ServiceEventType_SERVICE_EVENT_TYPE_ROUTES_RELOADED,
+}
+
+// AdminService implements the GatewayAdmin API on the use cases of the REST management API
+type AdminService struct {
+	adminv1.UnimplementedGatewayAdminServer
+
+	services api.ServiceUseCase
+	health   HealthReporter
+	events   service.EventBus
+}
+
+// NewAdminService creates a new AdminService instance. WatchServices streams the
+// configuration events published on events.
+func NewAdminService(services api.ServiceUseCase, health HealthReporter, events service.EventBus) *AdminService {
+	return &AdminService{
+		services: services,
+		health:   health,
+		events:   events,
+	}
+}
+
+// ListServices returns every service
+func (s *AdminService) ListServices(ctx context.Context, req *adminv1.ListServicesRequest) (*adminv1.ListServicesResponse, error) {
+	services, err := s.services.ListServices(ctx)
+	if err != nil {
+		return nil, status.Error(codes.Internal, "Failed to list services")
+	}
+
+	resp := &adminv1.ListServicesResponse{Services: make([]*adminv1.Service, len(services))}
+	for i, svc := range services {
+		if resp.Services[i], err = serviceMessage(svc); err != nil {
+			return nil, err
+		}
+	}
+	return resp, nil
+}
+
+// GetService returns a service by ID
+func (s *AdminService) GetService(ctx context.Context, req *adminv1.GetServiceRequest) (*adminv1.Service, error) {
+	svc, err := s.services.GetService(ctx, req.GetId())
+	if err != nil {
+		return nil, serviceError(err, "Failed to get service")
+	}
+	return serviceMessage(svc)
+}
+
+// FindServiceByName returns a service by name
+func (s *AdminService) FindServiceByName(ctx context.Context, req *adminv1.FindServiceByNameRequest) (*adminv1.Service, error) {
+	svc, err := s.services.FindServiceByName(ctx, req.GetName())
+	if err != nil {
+		return nil, serviceError(err, "Failed to find service")
+	}
+	return serviceMessage(svc)
+}
+
+// CreateService creates a service from its definition
+func (s *AdminService) CreateService(ctx context.Context, req *adminv1.CreateServiceRequest) (*adminv1.Service, error) {
+	var create dto.CreateServiceRequest
+	if err := decodeDefinition(req.GetDefinition(), &create); err != nil {
+		return nil, err
+	}
+
+	svc, err := s.services.CreateService(ctx, &create)
+	if err != nil {
+		return nil, serviceError(err, "Failed to create service")
+	}
+	return serviceMessage(svc)
+}
+
+// UpdateService replaces a service with its new definition
+func (s *AdminService) UpdateService(ctx context.Context, req *adminv1.UpdateServiceRequest) (*adminv1.Service, error) {
+	revision, err := expectedRevision(req.GetRevision(), req.GetForce())
+	if err != nil {
+		return nil, err
+	}
+
+	var update dto.UpdateServiceRequest
+	if err := decodeDefinition(req.GetDefinition(), &update); err != nil {
+		return nil, err
+	}
+	update.Revision = revision
+
+	svc, err := s.services.UpdateService(ctx, req.GetId(), &update)
+	if err != nil {
+		return nil, serviceError(err, "Failed to update service")
+	}
+	return serviceMessage(svc)
+}
+
+// DeleteService deletes a service
+func (s *AdminService) DeleteService(ctx context.Context, req *adminv1.DeleteServiceRequest) (*emptypb.Empty, error) {
+	revision, err := expectedRevision(req.GetRevision(), req.GetForce())
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.services.DeleteService(ctx, req.GetId(), revision); err != nil {
+		return nil, serviceError(err, "Failed to delete service")
+	}
+	return &emptypb.Empty{}, nil
+}
+
+// GetStatus reports the health of the gateway. An unavailable dependency that is not optional
+// reports it as degraded, as the health check does.
+func (s *AdminService) GetStatus(ctx context.Context, req *adminv1.GetStatusRequest) (*adminv1.Status, error) {
+	services, err := s.services.ListServices(ctx)
+	if err != nil {
+		return nil, status.Error(codes.Internal, "Failed to list services")
+	}
+
+	resp := &adminv1.Status{Status: "ok", Services: int32(len(services))}
+	for _, dependency := range s.health.DependencyHealth() {
+		if !dependency.Healthy && dependency.Criticality != entity.CriticalityOptional {
+			resp.Status = "degraded"
+		}
+		resp.Dependencies = append(resp.Dependencies, &adminv1.DependencyHealth{
+			Name:                dependency.Name,
+			Healthy:             dependency.Healthy,
+			ConsecutiveFailures: int32(dependency.ConsecutiveFailures),
+			Failures:            dependency.Failures,
+			Fallbacks:           dependency.Fallbacks,
+			LastError:           dependency.LastError,
+			LastCheck:           timestamppb.New(dependency.LastCheck),
+		})
+	}
+	return resp, nil
+}
+
+// WatchServices streams the configuration events of this and the other gateway instances
+// until the client cancels, or ends the stream once the client falls behind
+func (s *AdminService) WatchServices(req *adminv1.WatchServicesRequest, stream adminv1.GatewayAdmin_WatchServicesServer) error {
+	ctx := stream.Context()
+
+	// Handlers run on the publisher's goroutine, so events are queued rather than sent
+	events := make(chan *entity.Event, watchBuffer)
+	overflow := make(chan struct{})
+	var overflowOnce sync.Once
+	queue := func(ctx context.Context, event *entity.Event) {
+		select {
+		case events <- event:
+		default:
+			overflowOnce.Do(func() { close(overflow) })
+		}
+	}
+
+	// Subscribing before listing keeps the changes made meanwhile from being missed
+	for _, eventType := range entity.ConfigEvents {
+		unsubscribe := s.events.Subscribe(eventType, queue)
+		defer unsubscribe()
+	}
+
+	if req.GetInitialState() {
+		services, err := s.services.ListServices(ctx)
+		if err != nil {
+			return status.Error(codes.Internal, "Failed to list services")
+		}
+		for _, svc := range services {
+			msg, err := serviceMessage(svc)
+			if err != nil {
+				return err
+			}
+			if err := stream.Send(&adminv1.ServiceEvent{
+				Type:      adminv1.ServiceEventType_SERVICE_EVENT_TYPE_CREATED,
+				ServiceId: svc.ID,
+				Service:   msg,
+			}); err != nil {
+				return err
+			}
+		}
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return status.FromContextError(ctx.Err()).Err()
+		case <-overflow:
+			return status.Error(codes.ResourceExhausted, "Watcher fell behind, watch again")
+		case event := <-events:
+			msg, err := serviceEvent(event)
+			if err != nil {
+				return err
+			}
+			if err := stream.Send(msg); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// serviceEvent converts a configuration event to its message
+func serviceEvent(event *entity.Event) (*adminv1.ServiceEvent, error) {
+	msg := &adminv1.ServiceEvent{
+		Id:        event.ID,
+		Type:      eventTypes[event.Type],
+		Timestamp: timestamppb.New(event.Timestamp),
+		Actor:     event.Actor,
+		ServiceId: event.ServiceID,
+	}
+
+	var err error
+	if event.Service != nil {
+		if msg.Service, err = serviceMessage(dto.FromEntity(event.Service)); err != nil {
+			return nil, err
+		}
+	}
+	if event.Previous != nil {
+		if msg.Previous, err = serviceMessage(dto.FromEntity(event.Previous)); err != nil {
+			return nil, err
+		}
+	}
+	return msg, nil
+}
+
+// serviceMessage converts a service to its message, whose definition is the document the
+// REST API returns
+func serviceMessage(svc *dto.ServiceResponse) (*adminv1.Service, error) {
+	body, err := json.Marshal(svc)
+	if err != nil {
+		return nil, status.Error(codes.Internal, "Failed to encode service")
+	}
+	var document map[string]interface{}
+	if err := json.Unmarshal(body, &document); err != nil {
+		return nil, status.Error(codes.Internal, "Failed to encode service")
+	}
+	definition, err := structpb.NewStruct(document)
+	if err != nil {
+		return nil, status.Error(codes.Internal, "Failed to encode service")
+	}
+
+	return &adminv1.Service{
+		Id:         svc.ID,
+		Name:       svc.Name,
+		BaseUrl:    svc.BaseURL,
+		Published:  svc.Published,
+		Revision:   svc.Revision,
+		Definition: definition,
+	}, nil
+}
+
+// decodeDefinition decodes a service definition into a request of the REST API and validates
+// it the same way. Invalid fields are detailed in a BadRequest.
+func decodeDefinition(definition *structpb.Struct, req interface{}) error {
+	if definition == nil {
+		return status.Error(codes.InvalidArgument, "definition is required")
+	}
+	body, err := json.Marshal(definition.AsMap())
+	if err != nil {
+		return status.Error(codes.InvalidArgument, "Invalid service definition")
+	}
+	if err := json.Unmarshal(body, req); err != nil {
+		return status.Error(codes.InvalidArgument, "Invalid service definition")
+	}
+
+	fields, err := api.ValidateRequest(req)
+	if err != nil {
+		return status.Error(codes.InvalidArgument, "Invalid service definition")
+	}
+	if len(fields) == 0 {
+		return nil
+	}
+
+	badRequest := &errdetails.BadRequest{}
+	for _, field := range fields {
+		badRequest.FieldViolations = append(badRequest.FieldViolations, &errdetails.BadRequest_FieldViolation{
+			Field:       field.Field,
+			Description: field.Message,
+		})
+	}
+	st := status.New(codes.InvalidArgument, "The service definition has invalid fields")
+	if detailed, err := st.WithDetails(badRequest); err == nil {
+		st = detailed
+	}
+	return st.Err()
+}
+
+// expectedRevision returns the revision a modification is conditional on, zero when forced.
+// Like the If-Match header of the REST API, the revision is required.
+func expectedRevision(revision int64, force bool) (int64, error) {
+	if force {
+		return 0, nil
+	}
+	if revision <= 0 {
+		return 0, status.Error(codes.FailedPrecondition, "revision of the service is required unless force is set")
+	}
+	return revision, nil
+}
+
+// serviceError converts an error of the service use case to the status of the call
+func serviceError(err error, message string) error {
+	switch {
+	case errors.IsNotFound(err):
+		return status.Error(codes.NotFound, "Service not found")
+	case errors.IsRouteConflict(err):
+		return status.Error(codes.AlreadyExists, err.Error())
+	case errors.IsAlreadyExists(err):
+		return status.Error(codes.AlreadyExists, "Service name already taken")
+	case errors.IsPreconditionFailed(err):
+		return status.Error(codes.Aborted, "Service was modified, fetch it again and retry")
+	case errors.IsForbidden(err):
+		return status.Error(codes.PermissionDenied, "Service is managed by the xDS control plane")
+	default:
+		return status.Error(codes.Internal, message)
+	}
+}
@@ -0,0 +1,179 @@
+// Package controlplane serves the gRPC control-plane API defined in api/proto/admin/v1
+package controlplane
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"net"
+	"strings"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+
+	adminv1 "api-gateway-sample/api/proto/admin/v1"
+	"api-gateway-sample/internal/domain/entity"
+	"api-gateway-sample/pkg/logger"
+)
+
+// Authenticator validates the credentials sent with control-plane calls
+type Authenticator interface {
+	ValidateToken(ctx context.Context, token string) (map[string]interface{}, error)
+	SupportsCredentials() bool
+	ValidateCredentials(ctx context.Context, username string, password string) (map[string]interface{}, error)
+}
+
+// Server serves the control-plane API to principals with the admin role
+type Server struct {
+	server          *grpc.Server
+	auth            Authenticator
+	port            int
+	shutdownTimeout time.Duration
+	logger          logger.Logger
+}
+
+// NewServer creates a new Server instance serving admin on the given port, over TLS with the
+// given credentials or in plaintext when they are nil
+func NewServer(admin adminv1.GatewayAdminServer, auth Authenticator, port int, creds credentials.TransportCredentials, shutdownTimeout time.Duration, logger logger.Logger) *Server {
+	s := &Server{
+		auth:            auth,
+		port:            port,
+		shutdownTimeout: shutdownTimeout,
+		logger:          logger,
+	}
+	options := []grpc.ServerOption{
+		grpc.ChainUnaryInterceptor(s.authorizeUnary),
+		grpc.ChainStreamInterceptor(s.authorizeStream),
+	}
+	if creds != nil {
+		options = append(options, grpc.Creds(creds))
+	}
+	s.server = grpc.NewServer(options...)
+	adminv1.RegisterGatewayAdminServer(s.server, admin)
+	return s
+}
+
+// Start listens on the port and serves calls in the background
+func (s *Server) Start() error {
+	listener, err := net.Listen("tcp", fmt.Sprintf(":%d", s.port))
+	if err != nil {
+		return fmt.Errorf("failed to listen on port %d: %w", s.port, err)
+	}
+	go s.Serve(listener)
+	return nil
+}
+
+// Serve serves calls on the listener until the server stops
+func (s *Server) Serve(listener net.Listener) {
+	s.logger.Info("Starting control-plane server", "addr", listener.Addr().String())
+	if err := s.server.Serve(listener); err != nil {
+		s.logger.Error("Control-plane server failed", "error", err)
+	}
+}
+
+// Stop waits for pending calls to finish, up to the shutdown timeout. Watch streams, which
+// never finish on their own, are ended when it expires.
+func (s *Server) Stop() {
+	stopped := make(chan struct{})
+	go func() {
+		s.server.GracefulStop()
+		close(stopped)
+	}()
+
+	select {
+	case <-stopped:
+	case <-time.After(s.shutdownTimeout):
+		s.server.Stop()
+	}
+}
+
+func (s *Server) authorizeUnary(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+	ctx, err := s.authorize(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return handler(ctx, req)
+}
+
+func (s *Server) authorizeStream(srv interface{}, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	ctx, err := s.authorize(stream.Context())
+	if err != nil {
+		return err
+	}
+	return handler(srv, &authorizedStream{ServerStream: stream, ctx: ctx})
+}
+
+// authorize authenticates the "authorization" metadata of a call like the REST API does its
+// Authorization header, and requires the admin role of the management routes
+func (s *Server) authorize(ctx context.Context) (context.Context, error) {
+	var authorization string
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		if values := md.Get("authorization"); len(values) > 0 {
+			authorization = values[0]
+		}
+	}
+
+	var claims map[string]interface{}
+	var err error
+	scheme, credentials, _ := strings.Cut(authorization, " ")
+	switch {
+	case strings.EqualFold(scheme, "Bearer") && credentials != "":
+		claims, err = s.auth.ValidateToken(ctx, credentials)
+	case strings.EqualFold(scheme, "Basic") && s.auth.SupportsCredentials():
+		// Passwords are never accepted in plaintext, where anyone on the path can read them
+		if !overTLS(ctx) {
+			return nil, status.Error(codes.Unauthenticated, "Basic credentials require TLS")
+		}
+		username, password, ok := basicCredentials(credentials)
+		if !ok {
+			return nil, status.Error(codes.Unauthenticated, "Invalid credentials")
+		}
+		claims, err = s.auth.ValidateCredentials(ctx, username, password)
+	default:
+		return nil, status.Error(codes.Unauthenticated, "Unauthorized")
+	}
+	if err != nil {
+		return nil, status.Error(codes.Unauthenticated, "Invalid credentials")
+	}
+
+	principal := entity.NewPrincipal(claims)
+	if !principal.HasRole("admin") {
+		return nil, status.Error(codes.PermissionDenied, "Forbidden")
+	}
+	ctx = entity.ContextWithPrincipal(ctx, principal)
+	return logger.WithFields(ctx, logger.FieldUserID, principal.UserID), nil
+}
+
+// overTLS reports whether a call arrived over a TLS connection
+func overTLS(ctx context.Context) bool {
+	p, ok := peer.FromContext(ctx)
+	if !ok {
+		return false
+	}
+	_, ok = p.AuthInfo.(credentials.TLSInfo)
+	return ok
+}
+
+// basicCredentials decodes the credentials of the Basic scheme
+func basicCredentials(encoded string) (string, string, bool) {
+	decoded, err := base64.StdEncoding.DecodeString(strings.TrimSpace(encoded))
+	if err != nil {
+		return "", "", false
+	}
+	return strings.Cut(string(decoded), ":")
+}
+
+// authorizedStream carries the principal of a stream to its handler
+type authorizedStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *authorizedStream) Context() context.Context {
+	return s.ctx
+}
@@ -0,0 +1,301 @@
+package controlplane
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/base64"
+	"math/big"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+	"google.golang.org/protobuf/types/known/structpb"
+
+	adminv1 "api-gateway-sample/api/proto/admin/v1"
+	"api-gateway-sample/internal/application/usecase"
+	"api-gateway-sample/internal/domain/entity"
+	repomock "api-gateway-sample/internal/domain/repository/mock"
+	"api-gateway-sample/internal/infrastructure/events"
+	"api-gateway-sample/pkg/errors"
+)
+
+// nopLogger discards all log output
+type nopLogger struct{}
+
+func (nopLogger) Debug(msg string, keysAndValues ...interface{}) {}
+func (nopLogger) Info(msg string, keysAndValues ...interface{})  {}
+func (nopLogger) Warn(msg string, keysAndValues ...interface{})  {}
+func (nopLogger) Error(msg string, keysAndValues ...interface{}) {}
+func (nopLogger) Fatal(msg string, keysAndValues ...interface{}) {}
+
+// tokenAuthenticator accepts the tokens it knows, mapped to their claims
+type tokenAuthenticator map[string]map[string]interface{}
+
+func (a tokenAuthenticator) ValidateToken(ctx context.Context, token string) (map[string]interface{}, error) {
+	if claims, ok := a[token]; ok {
+		return claims, nil
+	}
+	return nil, errors.ErrUnauthorized
+}
+
+func (a tokenAuthenticator) SupportsCredentials() bool {
+	return false
+}
+
+func (a tokenAuthenticator) ValidateCredentials(ctx context.Context, username string, password string) (map[string]interface{}, error) {
+	return nil, errors.ErrUnauthorized
+}
+
+// passwordAuthenticator accepts the admin password of the Basic scheme
+type passwordAuthenticator struct{}
+
+func (passwordAuthenticator) ValidateToken(ctx context.Context, token string) (map[string]interface{}, error) {
+	return nil, errors.ErrUnauthorized
+}
+
+func (passwordAuthenticator) SupportsCredentials() bool {
+	return true
+}
+
+func (passwordAuthenticator) ValidateCredentials(ctx context.Context, username string, password string) (map[string]interface{}, error) {
+	if username != "admin" || password != "secret" {
+		return nil, errors.ErrUnauthorized
+	}
+	return map[string]interface{}{"sub": "admin-1", "roles": []interface{}{"admin"}}, nil
+}
+
+// noDependencies reports a gateway without backing dependencies
+type noDependencies struct{}
+
+func (noDependencies) DependencyHealth() []*entity.DependencyHealth {
+	return nil
+}
+
+// startServer serves the control-plane API over an in-memory connection and returns a client
+func startServer(t *testing.T) adminv1.GatewayAdminClient {
+	t.Helper()
+	auth := tokenAuthenticator{
+		"admin-token": {"sub": "admin-1", "roles": []interface{}{"admin"}},
+		"user-token":  {"sub": "user-1", "roles": []interface{}{"user"}},
+	}
+	return serve(t, auth, nil, insecure.NewCredentials())
+}
+
+// serve serves the control-plane API with an authenticator and transport credentials over an
+// in-memory connection and returns a client dialing it with clientCreds
+func serve(t *testing.T, auth Authenticator, creds credentials.TransportCredentials, clientCreds credentials.TransportCredentials) adminv1.GatewayAdminClient {
+	t.Helper()
+
+	bus := events.NewInMemoryBus(nopLogger{})
+	services := usecase.NewServiceUseCase(repomock.NewServiceRepositoryMock(), nil, bus)
+	server := NewServer(NewAdminService(services, noDependencies{}, bus), auth, 0, creds, time.Second, nopLogger{})
+
+	listener := bufconn.Listen(1 << 20)
+	go server.Serve(listener)
+	t.Cleanup(server.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufconn",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return listener.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(clientCreds),
+	)
+	require.NoError(t, err)
+	t.Cleanup(func() { conn.Close() })
+	return adminv1.NewGatewayAdminClient(conn)
+}
+
+// selfSignedCredentials returns the credentials of a server with a self-signed certificate for
+// localhost, and those of a client trusting it
+func selfSignedCredentials(t *testing.T) (credentials.TransportCredentials, credentials.TransportCredentials) {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "localhost"},
+		DNSNames:     []string{"localhost"},
+		NotBefore:    time.Now().Add(-time.Minute),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	require.NoError(t, err)
+	certificate, err := x509.ParseCertificate(der)
+	require.NoError(t, err)
+
+	pool := x509.NewCertPool()
+	pool.AddCert(certificate)
+	server := credentials.NewServerTLSFromCert(&tls.Certificate{Certificate: [][]byte{der}, PrivateKey: key})
+	return server, credentials.NewClientTLSFromCert(pool, "localhost")
+}
+
+func withBasic(username string, password string) context.Context {
+	encoded := base64.StdEncoding.EncodeToString([]byte(username + ":" + password))
+	return metadata.AppendToOutgoingContext(context.Background(), "authorization", "Basic "+encoded)
+}
+
+func withToken(token string) context.Context {
+	return metadata.AppendToOutgoingContext(context.Background(), "authorization", "Bearer "+token)
+}
+
+func definition(t *testing.T, name string) *structpb.Struct {
+	t.Helper()
+	def, err := structpb.NewStruct(map[string]interface{}{
+		"name":    name,
+		"baseUrl": "http://users:8080",
+		"endpoints": []interface{}{
+			map[string]interface{}{"path": "/api/v1/users", "methods": []interface{}{"GET"}, "timeout": 5},
+		},
+	})
+	require.NoError(t, err)
+	return def
+}
+
+func TestServer_RequiresAdminRole(t *testing.T) {
+	client := startServer(t)
+
+	_, err := client.ListServices(context.Background(), &adminv1.ListServicesRequest{})
+	assert.Equal(t, codes.Unauthenticated, status.Code(err))
+
+	_, err = client.ListServices(withToken("forged"), &adminv1.ListServicesRequest{})
+	assert.Equal(t, codes.Unauthenticated, status.Code(err))
+
+	_, err = client.ListServices(withToken("user-token"), &adminv1.ListServicesRequest{})
+	assert.Equal(t, codes.PermissionDenied, status.Code(err))
+
+	// Streams are authorized too
+	stream, err := client.WatchServices(withToken("user-token"), &adminv1.WatchServicesRequest{})
+	require.NoError(t, err)
+	_, err = stream.Recv()
+	assert.Equal(t, codes.PermissionDenied, status.Code(err))
+}
+
+func TestServer_BasicCredentialsRequireTLS(t *testing.T) {
+	// 1. Passwords sent in plaintext are refused, even when valid
+	client := serve(t, passwordAuthenticator{}, nil, insecure.NewCredentials())
+	_, err := client.ListServices(withBasic("admin", "secret"), &adminv1.ListServicesRequest{})
+	assert.Equal(t, codes.Unauthenticated, status.Code(err))
+
+	// 2. Over TLS they are validated
+	serverCreds, clientCreds := selfSignedCredentials(t)
+	client = serve(t, passwordAuthenticator{}, serverCreds, clientCreds)
+	_, err = client.ListServices(withBasic("admin", "secret"), &adminv1.ListServicesRequest{})
+	assert.NoError(t, err)
+	_, err = client.ListServices(withBasic("admin", "wrong"), &adminv1.ListServicesRequest{})
+	assert.Equal(t, codes.Unauthenticated, status.Code(err))
+}
+
+func TestAdminService_ManagesServices(t *testing.T) {
+	client := startServer(t)
+	ctx := withToken("admin-token")
+
+	// 1. Services are created from the documents of the REST API
+	created, err := client.CreateService(ctx, &adminv1.CreateServiceRequest{Definition: definition(t, "users")})
+	require.NoError(t, err)
+	assert.Equal(t, "users", created.Name)
+	assert.Equal(t, int64(1), created.Revision)
+	endpoint := created.Definition.Fields["endpoints"].GetListValue().Values[0].GetStructValue()
+	assert.Equal(t, 5.0, endpoint.Fields["timeout"].GetNumberValue())
+
+	_, err = client.CreateService(ctx, &adminv1.CreateServiceRequest{Definition: definition(t, "users")})
+	assert.Equal(t, codes.AlreadyExists, status.Code(err))
+
+	// 2. Invalid fields are detailed like the fields of a REST problem
+	invalid := definition(t, "orders")
+	delete(invalid.Fields, "baseUrl")
+	_, err = client.CreateService(ctx, &adminv1.CreateServiceRequest{Definition: invalid})
+	st := status.Convert(err)
+	require.Equal(t, codes.InvalidArgument, st.Code())
+	require.Len(t, st.Details(), 1)
+	violations := st.Details()[0].(*errdetails.BadRequest).FieldViolations
+	require.Len(t, violations, 1)
+	assert.Equal(t, "baseUrl", violations[0].Field)
+	assert.Equal(t, "is required", violations[0].Description)
+
+	// 3. Modifications are conditional on the revision unless forced
+	_, err = client.UpdateService(ctx, &adminv1.UpdateServiceRequest{Id: created.Id, Definition: definition(t, "users")})
+	assert.Equal(t, codes.FailedPrecondition, status.Code(err))
+
+	updated, err := client.UpdateService(ctx, &adminv1.UpdateServiceRequest{Id: created.Id, Definition: definition(t, "users"), Revision: 1})
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), updated.Revision)
+
+	_, err = client.DeleteService(ctx, &adminv1.DeleteServiceRequest{Id: created.Id, Revision: 1})
+	assert.Equal(t, codes.Aborted, status.Code(err))
+
+	found, err := client.FindServiceByName(ctx, &adminv1.FindServiceByNameRequest{Name: "users"})
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), found.Revision)
+
+	gatewayStatus, err := client.GetStatus(ctx, &adminv1.GetStatusRequest{})
+	require.NoError(t, err)
+	assert.Equal(t, "ok", gatewayStatus.Status)
+	assert.Equal(t, int32(1), gatewayStatus.Services)
+
+	// 4. A forced deletion ignores the revision
+	_, err = client.DeleteService(ctx, &adminv1.DeleteServiceRequest{Id: created.Id, Force: true})
+	require.NoError(t, err)
+	_, err = client.GetService(ctx, &adminv1.GetServiceRequest{Id: created.Id})
+	assert.Equal(t, codes.NotFound, status.Code(err))
+}
+
+func TestAdminService_WatchServices(t *testing.T) {
+	client := startServer(t)
+	ctx, cancel := context.WithCancel(withToken("admin-token"))
+	defer cancel()
+
+	created, err := client.CreateService(ctx, &adminv1.CreateServiceRequest{Definition: definition(t, "users")})
+	require.NoError(t, err)
+
+	// 1. The initial state lists the existing services, once the watch is subscribed
+	stream, err := client.WatchServices(ctx, &adminv1.WatchServicesRequest{InitialState: true})
+	require.NoError(t, err)
+	event, err := stream.Recv()
+	require.NoError(t, err)
+	assert.Equal(t, adminv1.ServiceEventType_SERVICE_EVENT_TYPE_CREATED, event.Type)
+	assert.Empty(t, event.Id)
+	assert.Equal(t, "users", event.Service.Name)
+
+	// 2. Changes are streamed with the state before and after them
+	_, err = client.UpdateService(ctx, &adminv1.UpdateServiceRequest{Id: created.Id, Definition: definition(t, "accounts"), Revision: 1})
+	require.NoError(t, err)
+	event, err = stream.Recv()
+	require.NoError(t, err)
+	assert.Equal(t, adminv1.ServiceEventType_SERVICE_EVENT_TYPE_UPDATED, event.Type)
+	assert.NotEmpty(t, event.Id)
+	assert.Equal(t, "admin-1", event.Actor)
+	assert.Equal(t, created.Id, event.ServiceId)
+	assert.Equal(t, "accounts", event.Service.Name)
+	assert.Equal(t, "users", event.Previous.Name)
+
+	_, err = client.DeleteService(ctx, &adminv1.DeleteServiceRequest{Id: created.Id, Revision: 2})
+	require.NoError(t, err)
+	event, err = stream.Recv()
+	require.NoError(t, err)
+	assert.Equal(t, adminv1.ServiceEventType_SERVICE_EVENT_TYPE_DELETED, event.Type)
+	assert.Nil(t, event.Service)
+	assert.Equal(t, "accounts", event.Previous.Name)
+
+	// 3. The stream ends when the client cancels
+	cancel()
+	_, err = stream.Recv()
+	assert.Equal(t, codes.Canceled, status.Code(err))
+}
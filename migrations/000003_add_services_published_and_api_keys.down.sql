@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS api_keys;
+
+ALTER TABLE services DROP COLUMN IF EXISTS published;
@@ -0,0 +1,15 @@
+ALTER TABLE services ADD COLUMN IF NOT EXISTS published BOOLEAN NOT NULL DEFAULT FALSE;
+
+CREATE TABLE IF NOT EXISTS api_keys (
+    id VARCHAR(64) PRIMARY KEY,
+    service_id VARCHAR(64) NOT NULL,
+    consumer VARCHAR(255) NOT NULL,
+    email VARCHAR(255) NOT NULL,
+    purpose TEXT NOT NULL DEFAULT '',
+    status VARCHAR(32) NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_api_keys_service_id ON api_keys(service_id);
+CREATE INDEX idx_api_keys_status ON api_keys(status);
@@ -0,0 +1,7 @@
+DROP INDEX IF EXISTS idx_api_keys_key_hash;
+
+ALTER TABLE api_keys DROP COLUMN IF EXISTS reason;
+ALTER TABLE api_keys DROP COLUMN IF EXISTS reviewed_at;
+ALTER TABLE api_keys DROP COLUMN IF EXISTS reviewed_by;
+ALTER TABLE api_keys DROP COLUMN IF EXISTS prefix;
+ALTER TABLE api_keys DROP COLUMN IF EXISTS key_hash;
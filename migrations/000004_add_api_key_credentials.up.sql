@@ -0,0 +1,7 @@
+ALTER TABLE api_keys ADD COLUMN IF NOT EXISTS key_hash VARCHAR(64) NOT NULL DEFAULT '';
+ALTER TABLE api_keys ADD COLUMN IF NOT EXISTS prefix VARCHAR(32) NOT NULL DEFAULT '';
+ALTER TABLE api_keys ADD COLUMN IF NOT EXISTS reviewed_by VARCHAR(255) NOT NULL DEFAULT '';
+ALTER TABLE api_keys ADD COLUMN IF NOT EXISTS reviewed_at TIMESTAMP WITH TIME ZONE;
+ALTER TABLE api_keys ADD COLUMN IF NOT EXISTS reason TEXT NOT NULL DEFAULT '';
+
+CREATE INDEX IF NOT EXISTS idx_api_keys_key_hash ON api_keys(key_hash);
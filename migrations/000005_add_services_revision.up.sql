@@ -0,0 +1 @@
+ALTER TABLE services ADD COLUMN IF NOT EXISTS revision BIGINT NOT NULL DEFAULT 1;
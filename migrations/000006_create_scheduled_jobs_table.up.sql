@@ -0,0 +1,13 @@
+CREATE TABLE IF NOT EXISTS scheduled_jobs (
+    id VARCHAR(64) PRIMARY KEY,
+    name VARCHAR(255) NOT NULL,
+    schedule VARCHAR(255) NOT NULL,
+    method VARCHAR(16) NOT NULL,
+    path VARCHAR(2048) NOT NULL,
+    headers TEXT NOT NULL DEFAULT '',
+    body TEXT NOT NULL DEFAULT '',
+    timeout INTEGER NOT NULL DEFAULT 0,
+    enabled BOOLEAN NOT NULL DEFAULT TRUE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
@@ -0,0 +1 @@
+ALTER TABLE api_keys ADD COLUMN IF NOT EXISTS plan VARCHAR(64) NOT NULL DEFAULT '';
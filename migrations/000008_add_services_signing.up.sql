@@ -0,0 +1 @@
+ALTER TABLE services ADD COLUMN IF NOT EXISTS signing TEXT NOT NULL DEFAULT '';
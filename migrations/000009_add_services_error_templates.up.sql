@@ -0,0 +1 @@
+ALTER TABLE services ADD COLUMN IF NOT EXISTS errors TEXT NOT NULL DEFAULT '';
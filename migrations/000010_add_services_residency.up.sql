@@ -0,0 +1 @@
+ALTER TABLE services ADD COLUMN IF NOT EXISTS residency TEXT NOT NULL DEFAULT '';
@@ -0,0 +1 @@
+DROP TABLE IF EXISTS service_revisions;
\ No newline at end of file
@@ -0,0 +1,10 @@
+CREATE TABLE IF NOT EXISTS service_revisions (
+    service_id VARCHAR(64) NOT NULL,
+    revision BIGINT NOT NULL,
+    actor VARCHAR(255) NOT NULL DEFAULT '',
+    restored_from BIGINT NOT NULL DEFAULT 0,
+    changes TEXT NOT NULL DEFAULT '',
+    service TEXT NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (service_id, revision)
+);
@@ -0,0 +1 @@
+ALTER TABLE services ADD COLUMN IF NOT EXISTS tags TEXT NOT NULL DEFAULT '';
@@ -0,0 +1 @@
+ALTER TABLE services ADD COLUMN IF NOT EXISTS owner TEXT NOT NULL DEFAULT '';
@@ -0,0 +1,2 @@
+ALTER TABLE services DROP COLUMN IF EXISTS valid_until;
+ALTER TABLE services DROP COLUMN IF EXISTS valid_from;
@@ -0,0 +1,2 @@
+ALTER TABLE services ADD COLUMN IF NOT EXISTS valid_from TIMESTAMP WITH TIME ZONE;
+ALTER TABLE services ADD COLUMN IF NOT EXISTS valid_until TIMESTAMP WITH TIME ZONE;
@@ -0,0 +1,13 @@
+CREATE TABLE IF NOT EXISTS rate_limit_overrides (
+    id VARCHAR(64) PRIMARY KEY,
+    consumer VARCHAR(255) NOT NULL,
+    service_id VARCHAR(64) NOT NULL,
+    endpoint VARCHAR(2048) NOT NULL DEFAULT '',
+    rate_limit INTEGER,
+    max_concurrent INTEGER,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (consumer, service_id, endpoint)
+);
+
+CREATE INDEX IF NOT EXISTS idx_rate_limit_overrides_consumer ON rate_limit_overrides (consumer);
@@ -0,0 +1 @@
+ALTER TABLE services ADD COLUMN IF NOT EXISTS auth_methods TEXT NOT NULL DEFAULT '';
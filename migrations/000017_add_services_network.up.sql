@@ -0,0 +1 @@
+ALTER TABLE services ADD COLUMN IF NOT EXISTS network TEXT NOT NULL DEFAULT '';
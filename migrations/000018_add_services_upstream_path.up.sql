@@ -0,0 +1 @@
+ALTER TABLE services ADD COLUMN IF NOT EXISTS upstream_path TEXT NOT NULL DEFAULT '';
@@ -0,0 +1 @@
+DROP TABLE IF EXISTS route_policies;
\ No newline at end of file
@@ -0,0 +1,20 @@
+CREATE TABLE IF NOT EXISTS route_policies (
+    id VARCHAR(64) PRIMARY KEY,
+    name VARCHAR(255) NOT NULL DEFAULT '',
+    service_id VARCHAR(64) NOT NULL,
+    endpoint VARCHAR(2048) NOT NULL DEFAULT '',
+    schedule VARCHAR(255) NOT NULL,
+    duration INTEGER NOT NULL,
+    timezone VARCHAR(64) NOT NULL DEFAULT '',
+    action VARCHAR(32) NOT NULL,
+    status INTEGER NOT NULL DEFAULT 0,
+    body TEXT NOT NULL DEFAULT '',
+    content_type VARCHAR(255) NOT NULL DEFAULT '',
+    rate_limit INTEGER,
+    max_concurrent INTEGER,
+    enabled BOOLEAN NOT NULL DEFAULT TRUE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_route_policies_service_id ON route_policies (service_id);
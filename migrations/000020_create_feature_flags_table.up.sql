@@ -0,0 +1,9 @@
+CREATE TABLE IF NOT EXISTS feature_flags (
+    key VARCHAR(255) PRIMARY KEY,
+    description TEXT NOT NULL DEFAULT '',
+    enabled BOOLEAN NOT NULL DEFAULT FALSE,
+    segment TEXT NOT NULL DEFAULT '',
+    percentage INTEGER,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
@@ -0,0 +1,49 @@
+// Package bufferpool reuses the buffers request and response bodies are read into, so that
+// reading a body allocates only the returned bytes rather than every buffer it outgrew
+package bufferpool
+
+import (
+	"bytes"
+	"io"
+	"sync"
+)
+
+// maxPooledSize is the capacity above which buffers are dropped rather than pooled, so that an
+// occasional large body does not keep its memory alive in the pool
+const maxPooledSize = 1 << 20
+
+var buffers = sync.Pool{
+	New: func() interface{} {
+		return new(bytes.Buffer)
+	},
+}
+
+// Get returns an empty buffer from the pool
+func Get() *bytes.Buffer {
+	buf := buffers.Get().(*bytes.Buffer)
+	buf.Reset()
+	return buf
+}
+
+// Put returns a buffer to the pool. The buffer and the bytes it returned must not be used afterwards.
+func Put(buf *bytes.Buffer) {
+	if buf.Cap() > maxPooledSize {
+		return
+	}
+	buffers.Put(buf)
+}
+
+// ReadAll reads r until EOF into a pooled buffer and returns a copy of exactly the bytes read,
+// nil when there are none
+func ReadAll(r io.Reader) ([]byte, error) {
+	buf := Get()
+	defer Put(buf)
+
+	if _, err := buf.ReadFrom(r); err != nil {
+		return nil, err
+	}
+	if buf.Len() == 0 {
+		return nil, nil
+	}
+	return bytes.Clone(buf.Bytes()), nil
+}
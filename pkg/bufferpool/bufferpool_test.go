@@ -0,0 +1,82 @@
+package bufferpool
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// failingReader returns some bytes and then an error
+type failingReader struct {
+	read bool
+}
+
+func (r *failingReader) Read(p []byte) (int, error) {
+	if r.read {
+		return 0, errors.New("connection reset")
+	}
+	r.read = true
+	return copy(p, "partial"), nil
+}
+
+func TestReadAll(t *testing.T) {
+	// 1. The bytes read are copied out of the pooled buffer
+	body, err := ReadAll(strings.NewReader(`{"id":"order-1"}`))
+	require.NoError(t, err)
+	assert.Equal(t, `{"id":"order-1"}`, string(body))
+	assert.Equal(t, len(body), cap(body))
+
+	other, err := ReadAll(strings.NewReader(`{"id":"order-2"}`))
+	require.NoError(t, err)
+	assert.Equal(t, `{"id":"order-1"}`, string(body), "a later read must not overwrite earlier bodies")
+	assert.Equal(t, `{"id":"order-2"}`, string(other))
+
+	// 2. Empty bodies are nil
+	body, err = ReadAll(strings.NewReader(""))
+	require.NoError(t, err)
+	assert.Nil(t, body)
+
+	// 3. Read errors are returned without the partial body
+	body, err = ReadAll(&failingReader{})
+	assert.EqualError(t, err, "connection reset")
+	assert.Nil(t, body)
+}
+
+func TestPut_DropsLargeBuffers(t *testing.T) {
+	buf := Get()
+	buf.Grow(maxPooledSize + 1)
+	Put(buf)
+
+	// A dropped buffer is never handed out again, whatever the pool holds
+	for i := 0; i < 10; i++ {
+		assert.LessOrEqual(t, Get().Cap(), maxPooledSize)
+	}
+}
+
+// BenchmarkReadAll compares reading a 16KB body through the pool with io.ReadAll, which
+// allocates every buffer the body outgrows
+func BenchmarkReadAll(b *testing.B) {
+	body := bytes.Repeat([]byte(`{"sku":"SKU-0001","quantity":1}`), 512)
+
+	b.Run("Pooled", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			if _, err := ReadAll(bytes.NewReader(body)); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+	b.Run("IOReadAll", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			if _, err := io.ReadAll(bytes.NewReader(body)); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
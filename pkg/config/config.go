@@ -2,6 +2,9 @@ package config
 
 import (
 	"fmt"
+	"net"
+	"net/url"
+	"strconv"
 	"strings"
 	"time"
 
@@ -10,19 +13,67 @@ import (
 
 // Config holds all configuration settings
 type Config struct {
-	Server   ServerConfig
-	Database DatabaseConfig
-	Redis    RedisConfig
-	Auth     AuthConfig
-	Logging  LoggingConfig
+	Server    ServerConfig
+	Database  DatabaseConfig
+	Storage   StorageConfig
+	Redis     RedisConfig
+	Cache     CacheConfig
+	RateLimit RateLimitConfig
+	Auth      AuthConfig
+	Logging   LoggingConfig
+	Security  SecurityConfig
+	Secrets   SecretsConfig
+	Debug     DebugConfig
+	Metrics   MetricsConfig
+	Alerting  AlertingConfig
+	Webhooks  WebhooksConfig
+	Portal    PortalConfig
+	Mail      MailConfig
+
+	Idempotency IdempotencyConfig
+	Upstream    UpstreamConfig
+	Streams     StreamsConfig
+	Brokers     BrokersConfig
+	Async       AsyncConfig
+	Scheduler   SchedulerConfig
+	ConfigSync  ConfigSyncConfig
+
+	LeaderElection LeaderElectionConfig
+	Priority       PriorityConfig
+	Dedup          DedupConfig
+	Chaos          ChaosConfig
+	Egress         EgressConfig
+	ErrorPages     ErrorPagesConfig
+	ErrorReporting ErrorReportingConfig
+	I18n           I18nConfig
+	Residency      ResidencyConfig
+	ControlPlane   ControlPlaneConfig
+	XDS            XDSConfig
+	Routing        RoutingConfig
+	Uploads        UploadsConfig
+	FeatureFlags   FeatureFlagsConfig
+	Experiments    ExperimentsConfig
 }
 
 // ServerConfig holds server-related configuration
 type ServerConfig struct {
+	// Profile is the deployment the gateway runs in: "development", "staging" or "production"
+	Profile         string
 	Port            int
 	ReadTimeout     time.Duration
 	WriteTimeout    time.Duration
 	ShutdownTimeout time.Duration
+	// HTTP2 serves HTTP/2 to clients that negotiate it over TLS
+	HTTP2 bool
+	// H2C serves cleartext HTTP/2 on a listener without TLS
+	H2C bool
+	TLS TLSConfig
+}
+
+// TLSConfig holds the certificate of the listener. HTTPS is served when both files are set.
+type TLSConfig struct {
+	CertFile string
+	KeyFile  string
 }
 
 // DatabaseConfig holds database-related configuration
@@ -35,11 +86,87 @@ type DatabaseConfig struct {
 	SSLMode  string
 }
 
+// StorageConfig selects where services and webhook subscriptions are persisted
+type StorageConfig struct {
+	// Backend is "postgres" or "file"
+	Backend string
+	File    FileStorageConfig
+}
+
+// FileStorageConfig holds settings for the file-backed store
+type FileStorageConfig struct {
+	// Path is the JSON or YAML file, chosen by its extension
+	Path string
+}
+
 // RedisConfig holds Redis-related configuration
 type RedisConfig struct {
+	// Mode is "single", "cluster" or "sentinel"
+	Mode     string
 	Address  string
+	Username string
 	Password string
 	DB       int
+	// Addresses are the cluster seed nodes or the sentinel nodes; Address is used when empty
+	Addresses []string
+	// MasterName is the name of the master monitored by the sentinels
+	MasterName       string
+	SentinelUsername string
+	SentinelPassword string
+	// RouteByLatency sends cluster read-only commands to the closest node
+	RouteByLatency bool
+	// HealthCheckInterval is how often Redis is pinged to detect outages (0 disables the checks)
+	HealthCheckInterval time.Duration
+	HealthCheckTimeout  time.Duration
+	// FailureThreshold is the number of consecutive failed pings before Redis is considered down
+	FailureThreshold int
+}
+
+// CacheConfig selects the response cache backend
+type CacheConfig struct {
+	// Backend is "redis", "memcached" or "memory"
+	Backend string
+	// BypassOnFailure skips the Redis cache while Redis is down instead of waiting for timeouts
+	BypassOnFailure bool
+	// ETags generates "strong" or "weak" ETags for cached JSON responses without one, so that the
+	// gateway answers clients polling them with 304; empty disables them
+	ETags     string
+	Memcached MemcachedConfig
+	Memory    MemoryCacheConfig
+}
+
+// MemcachedConfig holds Memcached-related configuration
+type MemcachedConfig struct {
+	Addresses []string
+	Timeout   time.Duration
+}
+
+// MemoryCacheConfig holds settings for the in-process cache
+type MemoryCacheConfig struct {
+	// MaxEntries bounds the number of cached entries (0 means unbounded)
+	MaxEntries      int
+	CleanupInterval time.Duration
+}
+
+// RateLimitConfig selects the rate limiter backend
+type RateLimitConfig struct {
+	// Backend is "redis" or "memory"; memory limits are per gateway instance
+	Backend string
+	// FailurePolicy applies while Redis is down: "fail-open", "fail-closed" or "local"
+	FailurePolicy string
+	// SyncInterval is how often each gateway instance syncs its locally counted tokens with
+	// Redis. 0 counts every request in Redis; longer intervals save round trips but let a
+	// client exceed its limit by up to the tokens each instance counts between syncs.
+	SyncInterval time.Duration
+	// ConcurrencyTTL bounds how long an in-flight slot is held in Redis if its gateway
+	// instance dies before releasing it; it should exceed the longest request timeout
+	ConcurrencyTTL time.Duration
+	// OverrideCacheTTL is how long the rate limit overrides of a consumer are cached; overrides
+	// changed through another gateway instance with the memory cache apply once it expires
+	OverrideCacheTTL time.Duration
+	// LegacyHeaders also reports the quota of rate-limited endpoints under the X-RateLimit-*
+	// names besides the RateLimit-* headers, for clients written against them
+	LegacyHeaders bool
 }
 
 // AuthConfig holds authentication-related configuration
@@ -47,12 +174,758 @@ type AuthConfig struct {
 	SecretKey  string
 	Issuer     string
 	Expiration time.Duration
+	// Algorithm is the JWT signing algorithm, e.g. HS256, RS256 or ES256
+	Algorithm string
+	// PrivateKeyFile is a PEM encoded private key for asymmetric algorithms.
+	// A key is generated at startup when it is empty, which only a single instance may do.
+	PrivateKeyFile string
+	// RotationInterval is how often a new asymmetric signing key is generated (0 disables rotation).
+	// Only a single instance may rotate its keys.
+	RotationInterval time.Duration
+	// DefaultPolicy is the CEL authorization policy for endpoints without their own policy.
+	// The built-in admin/"<service>:<endpoint>" role policy is used when it is empty.
+	DefaultPolicy string
+	External      ExternalAuthConfig
+	LDAP          LDAPConfig
+	OIDC          OIDCConfig
+	SAML          SAMLConfig
+	Session       SessionConfig
+	// ClaimHeaders maps headers forwarded to backends to the claims of the authenticated caller
+	// they carry, e.g. X-User-Id: sub. Values clients send for them are always removed.
+	ClaimHeaders  map[string]string
+	IdentityToken IdentityTokenConfig
+}
+
+// IdentityTokenConfig holds configuration for the tokens the gateway mints for services instead
+// of forwarding client credentials. Identity tokens are enabled when Audience is set.
+type IdentityTokenConfig struct {
+	// Audience identifies the services; the gateway rejects tokens addressed to it
+	Audience string
+	// TTL is how long identity tokens are valid
+	TTL time.Duration
+	// Header carries the token to services, as a bearer token when it is Authorization
+	Header string
+}
+
+// SessionConfig holds configuration for the cookie carrying the sessions of browser users logged
+// in with OIDC or SAML
+type SessionConfig struct {
+	CookieName string
+	// Secure restricts the cookie to HTTPS; disable it only to try the login flows over plain HTTP
+	Secure bool
+}
+
+// SAMLConfig holds configuration for logging browser users in with a SAML 2.0 identity provider,
+// the gateway acting as service provider. SAML login is enabled when EntityID is set.
+type SAMLConfig struct {
+	// EntityID identifies the gateway to the identity provider, e.g. its /auth/saml/metadata URL
+	EntityID string
+	// ACSURL is the gateway's /auth/saml/acs URL, to which the identity provider posts responses
+	ACSURL      string
+	IDPEntityID string
+	// IDPSSOURL is the identity provider's single sign-on endpoint for the HTTP-Redirect binding
+	IDPSSOURL string
+	// IDPCertificateFile is the PEM encoded certificate the identity provider signs with
+	IDPCertificateFile string
+	// RolesAttribute is the assertion attribute whose values are mapped to gateway roles
+	RolesAttribute string
+	// AttributeRoles maps values of RolesAttribute, such as group names, to gateway roles
+	AttributeRoles map[string]string
+	// ClockSkew is the difference tolerated between the clocks of the gateway and the provider
+	ClockSkew time.Duration
+	// LoginTimeout is how long users have to complete the login at the provider
+	LoginTimeout time.Duration
+}
+
+// OIDCConfig holds configuration for logging browser users in with an OpenID Connect identity
+// provider using the authorization code flow with PKCE. The login flow is enabled when Issuer is set.
+type OIDCConfig struct {
+	// Issuer is the provider's issuer URL; its endpoints are discovered from
+	// <issuer>/.well-known/openid-configuration
+	Issuer       string
+	ClientID     string
+	ClientSecret string
+	// RedirectURL is the gateway's /auth/callback URL as registered with the provider
+	RedirectURL string
+	Scopes      []string
+	// RolesClaim is the ID token claim whose values become the user's gateway roles
+	RolesClaim string
+	// LoginTimeout is how long users have to complete the login at the provider
+	LoginTimeout time.Duration
+	Timeout      time.Duration
+}
+
+// LDAPConfig holds configuration for validating Basic credentials against LDAP or Active
+// Directory. LDAP authentication is enabled when URL is set.
+type LDAPConfig struct {
+	URL          string
+	BindDN       string
+	BindPassword string
+	BaseDN       string
+	// UserFilter locates the user entry; {username} is replaced with the escaped username
+	UserFilter     string
+	GroupAttribute string
+	// GroupRoles maps group DNs or CNs to gateway roles
+	GroupRoles map[string]string
+	StartTLS   bool
+	Timeout    time.Duration
+}
+
+// ExternalAuthConfig holds configuration for delegating authorization to an external
+// HTTP service. External authorization is enabled when URL is set.
+type ExternalAuthConfig struct {
+	URL      string
+	Timeout  time.Duration
+	CacheTTL time.Duration
+	// FailOpen allows requests through when the authorization service is unreachable
+	FailOpen bool
+	// ForwardHeaders are the request headers sent to the authorization service
+	ForwardHeaders []string
+	// UpstreamHeaders are the authorization response headers injected into the upstream request
+	UpstreamHeaders []string
 }
 
 // LoggingConfig holds logging-related configuration
 type LoggingConfig struct {
 	Level       string
 	Development bool
+	Redaction   RedactionConfig
+	Sampling    LogSamplingConfig
+	Async       LogAsyncConfig
+	// Outputs receive every line at or above their level, stderr when there are none
+	Outputs []LogOutputConfig
+}
+
+// LogOutputConfig is a destination of log lines
+type LogOutputConfig struct {
+	// Type is stdout, stderr, file, syslog or loki
+	Type string
+	// Level is the lowest level written to the output, logging.level when empty
+	Level string
+	// Path is the file written by a file output, rotated once it reaches MaxSizeMB, 100 when
+	// zero. At most MaxBackups rotated files, none older than MaxAgeDays, are kept; zero keeps
+	// them all.
+	Path       string
+	MaxSizeMB  int
+	MaxBackups int
+	MaxAgeDays int
+	Compress   bool
+	// Address is the syslog server, such as udp://syslog:514, or the local daemon when empty
+	Address string
+	// Tag names the gateway in syslog messages, api-gateway when empty
+	Tag string
+	// URL is the Loki push endpoint, such as http://loki:3100/loki/api/v1/push
+	URL string
+	// Labels are the Loki stream labels of the lines, in addition to their level
+	Labels        map[string]string
+	BatchSize     int
+	FlushInterval time.Duration
+	Timeout       time.Duration
+}
+
+// LogSamplingConfig limits repeated debug and info lines, such as access logs, under load.
+// Each Tick, the first Initial lines with the same level and message are written, then every
+// Thereafter-th. Warnings and errors are never sampled.
+type LogSamplingConfig struct {
+	Enabled    bool
+	Initial    int
+	Thereafter int
+	Tick       time.Duration
+}
+
+// LogAsyncConfig writes log lines from a background goroutine through a buffer
+type LogAsyncConfig struct {
+	Enabled bool
+	// QueueSize is the number of lines waiting to be written; lines logged while it is full are dropped
+	QueueSize int
+	// BufferSize is the number of bytes buffered before a write to the output
+	BufferSize    int
+	FlushInterval time.Duration
+}
+
+// RedactionConfig holds the rules used to mask sensitive data in logs
+type RedactionConfig struct {
+	Headers    []string
+	BodyFields []string
+	Mask       string
+}
+
+// SecurityConfig holds response security policy configuration
+type SecurityConfig struct {
+	Headers      SecurityHeadersConfig
+	StripHeaders []string
+}
+
+// SecurityHeadersConfig holds the security headers injected on every response.
+// An empty value disables the corresponding header.
+type SecurityHeadersConfig struct {
+	StrictTransportSecurity string
+	ContentTypeOptions      string
+	FrameOptions            string
+	ContentSecurityPolicy   string
+	ReferrerPolicy          string
+}
+
+// SecretsConfig holds secret provider configuration.
+// Provider is one of "env", "file", "vault" or "aws".
+type SecretsConfig struct {
+	Provider string
+	// Names lists secrets read by the env and file providers besides the gateway's own, such as
+	// the keys of encrypted endpoints. The vault and aws providers return every secret they hold.
+	Names           []string
+	RefreshInterval time.Duration
+	Timeout         time.Duration
+	File            FileSecretsConfig
+	Vault           VaultSecretsConfig
+	AWS             AWSSecretsConfig
+}
+
+// FileSecretsConfig holds configuration for file-based secrets
+type FileSecretsConfig struct {
+	Directory string
+}
+
+// VaultSecretsConfig holds HashiCorp Vault configuration
+type VaultSecretsConfig struct {
+	Address   string
+	Token     string
+	MountPath string
+	Path      string
+}
+
+// AWSSecretsConfig holds AWS Secrets Manager configuration
+type AWSSecretsConfig struct {
+	Region          string
+	SecretID        string
+	AccessKeyID     string
+	SecretAccessKey string
+	SessionToken    string
+}
+
+// DebugConfig holds request tracing and profiling configuration
+type DebugConfig struct {
+	// Secret signs the debug header; debug headers are disabled when it is empty
+	Secret string
+	// MaxClockSkew bounds how old a signed debug header may be
+	MaxClockSkew time.Duration
+	// SampleRate is the fraction of requests (0-1) whose timing breakdown is logged
+	SampleRate float64
+	// Profiling serves Go profiles and runtime statistics under /admin/debug to administrators
+	Profiling bool
+}
+
+// MetricsConfig holds in-process traffic statistics configuration
+type MetricsConfig struct {
+	// Enabled exposes upstream latency histograms on /metrics
+	Enabled bool
+	// Window is the rolling period covered by service statistics
+	Window time.Duration
+	// Buckets is the number of slices the window is divided into
+	Buckets int
+}
+
+// AlertingConfig holds operational alert configuration. Alerting is enabled when at
+// least one webhook, a Slack webhook URL or a Slack channel is set, or when email is
+// configured to notify the owners of services.
+type AlertingConfig struct {
+	Webhooks        []string
+	SlackWebhookURL string
+	// SlackChannels maps the Slack channels service owners may name to their incoming webhook URLs
+	SlackChannels map[string]string
+	Timeout       time.Duration
+	// Cooldown suppresses repeats of the same alert for the same service or target
+	Cooldown time.Duration
+	// CheckInterval is how often service error rates are evaluated
+	CheckInterval time.Duration
+	// ErrorRateThreshold is the 5xx fraction (0-1) above which a service alerts
+	ErrorRateThreshold float64
+	// MinRequests is the number of requests in the window needed before the error rate or an SLO is evaluated
+	MinRequests int64
+	// SLOBurnRateThreshold is the error budget burn rate at or above which an endpoint with an SLO
+	// alerts; 1 means the budget for the window is exhausted
+	SLOBurnRateThreshold float64
+	// BufferSize is the number of alerts each channel holds for delivery; the oldest is dropped when it is full
+	BufferSize int
+	// MaxRetries is the number of retries of an alert a channel failed to deliver
+	MaxRetries int
+	// FailureThreshold is the number of consecutive failures after which a channel's circuit opens
+	FailureThreshold int
+	// RetryCooldown is how long an open circuit holds alerts before retrying the channel
+	RetryCooldown time.Duration
+}
+
+// WebhooksConfig holds delivery settings for configuration change webhooks
+type WebhooksConfig struct {
+	Timeout time.Duration
+	// MaxRetries is the number of retries after a failed delivery
+	MaxRetries int
+	// RetryBackoff is the delay before the first retry, doubled for each further retry
+	RetryBackoff time.Duration
+	// Workers is the number of events delivered at once
+	Workers int
+	// QueueSize is the number of events that may wait for a worker before DropPolicy applies
+	QueueSize int
+	// DropPolicy is reject to drop new events while the queue is full, drop-oldest to drop the
+	// longest waiting one instead, or block to hold up the change that raised the event
+	DropPolicy string
+}
+
+// PortalConfig holds developer portal configuration
+type PortalConfig struct {
+	// Enabled serves the public catalog of published services under /portal
+	Enabled bool
+	// Pages serves the embedded HTML catalog page in addition to the JSON API
+	Pages bool
+}
+
+// MailConfig holds the SMTP server used to email API consumers about their key requests.
+// Email is disabled when Host is empty.
+type MailConfig struct {
+	Host     string
+	Port     int
+	Username string
+	Password string
+	// From is the sender address of every message
+	From    string
+	Timeout time.Duration
+}
+
+// IdempotencyConfig holds settings for replaying retried POST and PATCH requests that carry an
+// Idempotency-Key header. Responses are stored in the cache backend.
+type IdempotencyConfig struct {
+	Enabled bool
+	// TTL is how long a completed response is replayed for retries
+	TTL time.Duration
+	// LockTimeout is how long a request in flight blocks retries with the same key
+	LockTimeout time.Duration
+}
+
+// UpstreamConfig holds settings for the connections to backend services
+type UpstreamConfig struct {
+	// H2C sends requests to http:// upstreams over cleartext HTTP/2; every such upstream must support it
+	H2C bool
+	// Credentials are the named credentials that services reference to sign their upstream requests
+	Credentials map[string]SigningCredentials
+	// OAuthClients are the named OAuth clients whose access tokens services with the oauth2
+	// signing scheme are sent
+	OAuthClients map[string]OAuthClientConfig
+	// TokenTimeout bounds each request for an access token to an identity provider
+	TokenTimeout time.Duration
+	// DeniedRequestHeaders are never forwarded to upstreams and DeniedResponseHeaders never
+	// returned to clients, in addition to the hop-by-hop headers
+	DeniedRequestHeaders  []string
+	DeniedResponseHeaders []string
+	// TrustedProxies are the networks of the proxies in front of the gateway, such as load
+	// balancers, whose X-Forwarded-* and Forwarded headers are passed on and extended. The
+	// headers of other clients are replaced.
+	TrustedProxies []string
+	// ForwardedHeader sends the standard Forwarded header alongside the X-Forwarded-* headers
+	ForwardedHeader bool
+	// DNSCacheTTL keeps the addresses of upstream hosts for new connections, 0 resolves them
+	// for every connection
+	DNSCacheTTL time.Duration
+	// DrainTimeout aborts the requests still in flight on the former upstream of a service this
+	// long after its base URL changed, 0 lets them complete
+	DrainTimeout time.Duration
+	// Networks are the named isolated networks that services reference to reach their upstreams
+	// through a proxy, custom resolvers or static host addresses
+	Networks map[string]NetworkConfig
+}
+
+// NetworkConfig holds how the gateway reaches the upstreams of an isolated network
+type NetworkConfig struct {
+	// Proxy is the http, https or socks5 proxy URL requests are sent through, if set
+	Proxy string
+	// Resolvers are the host:port addresses of the DNS servers resolving upstream hosts, tried in turn
+	Resolvers []string
+	// Hosts are the upstream hosts reached at a static IP address without a lookup
+	Hosts []HostAddress
+}
+
+// HostAddress holds the IP address a host name is reached at. Host names are listed rather than
+// used as keys, as configuration keys cannot contain dots.
+type HostAddress struct {
+	Host    string
+	Address string
+}
+
+// OAuthClientConfig holds an OAuth 2.0 client that obtains access tokens with the client
+// credentials grant
+type OAuthClientConfig struct {
+	TokenURL     string
+	ClientID     string
+	ClientSecret string
+	Scopes       []string
+	// Audience is sent as the audience parameter some identity providers require, if set
+	Audience string
+}
+
+// SigningCredentials holds the keys used to sign upstream requests. HMAC signing uses the secret
+// access key as the shared key and sends the access key ID, if any, as the key ID.
+type SigningCredentials struct {
+	AccessKeyID     string
+	SecretAccessKey string
+	SessionToken    string
+}
+
+// StreamsConfig declares raw TCP and UDP listeners that forward to an upstream, for non-HTTP
+// services served from the same gateway deployment
+type StreamsConfig struct {
+	// Listeners are specs such as "tcp://:1883?upstream=mqtt:1883", comma-separated in the environment
+	Listeners []string
+	// MaxConnections limits the concurrent TCP connections or UDP peers of a listener (0 means unlimited)
+	MaxConnections int
+	// IdleTimeout closes connections and UDP sessions without traffic in either direction
+	IdleTimeout time.Duration
+}
+
+// BrokersConfig holds the message brokers bridge endpoints publish to. A broker is enabled
+// when its addresses or URL are set.
+type BrokersConfig struct {
+	// Timeout bounds publishing a message, including waiting for the acknowledgement
+	Timeout  time.Duration
+	Kafka    KafkaConfig
+	RabbitMQ RabbitMQConfig
+	NATS     NATSConfig
+}
+
+// KafkaConfig holds the Kafka cluster bridge endpoints publish to
+type KafkaConfig struct {
+	// Brokers are the bootstrap broker addresses
+	Brokers []string
+}
+
+// RabbitMQConfig holds the RabbitMQ server bridge endpoints publish to
+type RabbitMQConfig struct {
+	URL string
+	// Exchange receives the messages, routed by the bridge topic; empty is the default exchange,
+	// which routes to the queue named by the topic
+	Exchange string
+}
+
+// NATSConfig holds the NATS server bridge endpoints publish to. At-least-once delivery
+// publishes through JetStream, so a stream must capture the subjects.
+type NATSConfig struct {
+	URL string
+}
+
+// AsyncConfig holds settings for async endpoints, whose requests are queued and processed by a
+// pool of workers. Results are stored in the cache backend.
+type AsyncConfig struct {
+	Enabled bool
+	Workers int
+	// QueueSize is the number of requests that may wait for a worker before requests are refused
+	QueueSize int
+	// ResultTTL is how long results can be fetched from their status URL
+	ResultTTL time.Duration
+	// Timeout bounds the processing of a request by the service
+	Timeout time.Duration
+	// CallbackHosts are the hosts results may be posted to; empty disables callbacks
+	CallbackHosts []string
+	// CallbackSecret signs callbacks like webhook deliveries
+	CallbackSecret string
+}
+
+// SchedulerConfig holds settings for scheduled jobs, which call gateway routes on a cron schedule
+type SchedulerConfig struct {
+	// Enabled runs the scheduled jobs on this instance; they can be managed and run manually either way
+	Enabled bool
+	// Timeout bounds the runs of jobs without their own timeout
+	Timeout time.Duration
+	// HistorySize is the number of recent runs kept per job
+	HistorySize int
+	// ReloadInterval is how often jobs are reloaded from storage to pick up changes made by other instances
+	ReloadInterval time.Duration
+}
+
+// ConfigSyncConfig holds settings for propagating configuration changes between gateway
+// instances over Redis pub/sub
+type ConfigSyncConfig struct {
+	Enabled bool
+	// Channel is the pub/sub channel shared by the instances
+	Channel string
+}
+
+// LeaderElectionConfig holds settings for electing the instance that runs the background tasks
+// which must only run once across instances, such as scheduled jobs
+type LeaderElectionConfig struct {
+	// Backend is "redis" or "postgres"; empty runs the tasks on every instance
+	Backend string
+	// Key names the lease or lock, so deployments sharing a backend elect separate leaders
+	Key string
+	// LeaseDuration is how long a Redis lease lasts without renewal before another instance can take over
+	LeaseDuration time.Duration
+	// RenewInterval is how often the leader renews its lease and the other instances try to acquire it
+	RenewInterval time.Duration
+}
+
+// PriorityConfig holds settings for scheduling proxied requests by priority under load. Each
+// instance forwards at most MaxInFlight requests at once and queues the rest by priority class.
+type PriorityConfig struct {
+	Enabled     bool
+	MaxInFlight int
+	// QueueSize is the number of requests that may wait for a slot; when it is full, queued
+	// requests of a lower class are shed to make room
+	QueueSize int
+	// MaxWait bounds how long a request waits for a slot before it is rejected with 503
+	MaxWait time.Duration
+	// Plans maps the plans of API keys and the "plan" claim of tokens to "high", "normal" or "low"
+	Plans map[string]string
+	// DefaultClass is the class of requests without a mapped plan
+	DefaultClass string
+}
+
+// DedupConfig holds settings for collapsing identical GET requests in flight into a single
+// upstream call
+type DedupConfig struct {
+	Enabled bool
+}
+
+// ChaosConfig holds settings for injecting faults into requests through the admin API. It
+// cannot be enabled in the production profile.
+type ChaosConfig struct {
+	Enabled bool
+}
+
+// EgressConfig holds settings for the egress listener, an HTTP forward proxy through which
+// internal clients reach allowlisted external hosts
+type EgressConfig struct {
+	// Port is the port of the egress listener, 0 disables it
+	Port int
+	// Destinations are the hosts clients may reach; the first matching a host applies to it
+	Destinations []EgressDestinationConfig
+}
+
+// EgressDestinationConfig is a host reachable through the egress listener
+type EgressDestinationConfig struct {
+	// Host is a host name, optionally with a port, or a wildcard such as "*.example.com"
+	Host string
+	// Scheme is http or https, the scheme of the requests sent to the host
+	Scheme string
+	// RateLimit bounds the requests per minute sent to the host by all clients, 0 is unlimited
+	RateLimit int
+	// Headers are set on every request to the host, e.g. an API key
+	Headers map[string]string
+	// Signing signs the requests to the host with upstream.credentials
+	Signing *EgressSigningConfig
+}
+
+// EgressSigningConfig holds the signing settings of an egress destination, as for services
+type EgressSigningConfig struct {
+	Scheme      string
+	Credentials string
+	Region      string
+	Service     string
+}
+
+// RoutingConfig holds how the routes of the services are managed
+type RoutingConfig struct {
+	// Conflicts is "reject" to refuse changes making services serve the same route with the
+	// same priority, or "warn" to make them and report the conflicts
+	Conflicts string
+	// ExpiryCleanupInterval is how often services and endpoints whose validity window ended are
+	// archived, 0 to keep them
+	ExpiryCleanupInterval time.Duration
+	// PolicyCacheTTL is how long the scheduled policies of a service are cached; policies
+	// changed through another gateway instance with the memory cache apply once it expires
+	PolicyCacheTTL time.Duration
+}
+
+// UploadsConfig holds settings for the multipart uploads endpoints stream to their services
+type UploadsConfig struct {
+	// SpoolDir holds the files of uploads while they are scanned, the system temporary directory
+	// when empty
+	SpoolDir string
+	Scanner  UploadScannerConfig
+}
+
+// UploadScannerConfig holds configuration for checking uploaded files with an HTTP scanning
+// service before they are forwarded. Scanning is enabled when URL is set.
+type UploadScannerConfig struct {
+	URL     string
+	Timeout time.Duration
+	// FailOpen forwards files unscanned when the scanning service is unreachable
+	FailOpen bool
+}
+
+// FeatureFlagsConfig holds settings for the feature flags endpoints are served behind
+type FeatureFlagsConfig struct {
+	// Provider is "builtin" for the flags managed through the admin API, or "ofrep" for a flag
+	// service speaking the OpenFeature Remote Evaluation Protocol
+	Provider string
+	// CacheTTL is how long the built-in flags, or the evaluations of each caller by the flag
+	// service, are cached
+	CacheTTL time.Duration
+	OFREP    OFREPConfig
+}
+
+// OFREPConfig holds the flag service of the ofrep provider
+type OFREPConfig struct {
+	// URL is the base URL of the flag service, e.g. http://flagd:8016
+	URL string
+	// Token is sent as a bearer token, empty to send none
+	Token   string
+	Timeout time.Duration
+}
+
+// ExperimentsConfig holds settings for the A/B experiments endpoints run
+type ExperimentsConfig struct {
+	// CookieMaxAge is how long the cookie keeping an anonymous caller in its variants lasts
+	CookieMaxAge time.Duration
+	// ExposureLog receives a line for each request served by a variant, written to the
+	// application log when empty. Unlike the application log, it is never sampled.
+	ExposureLog []LogOutputConfig
+}
+
+// ErrorPagesConfig holds the templates of the error responses generated by the gateway.
+// Services may define their own templates, which take precedence.
+type ErrorPagesConfig struct {
+	// Templates are matched by status, then by class, then the one without status applies
+	Templates []ErrorTemplateConfig
+}
+
+// ErrorReportingConfig holds the error tracker receiving the panics recovered while serving
+// requests. Reporting is enabled when a DSN is set.
+type ErrorReportingConfig struct {
+	// DSN is the data source name of a Sentry-compatible tracker, such as https://<key>@o0.ingest.sentry.io/<project>
+	DSN string
+	// Environment tags the reports, the server profile when empty
+	Environment string
+	// Release tags the reports with the deployed version
+	Release string
+	Timeout time.Duration
+	// BufferSize is the number of reports held for delivery; the oldest is dropped when it is full
+	BufferSize int
+}
+
+// ErrorTemplateConfig is the body of the errors with a status or a class of statuses
+type ErrorTemplateConfig struct {
+	// Status is a status such as "404" or a class such as "5xx", empty for every error
+	Status string
+	// ContentType is the content type of the body, e.g. application/json or text/html
+	ContentType string
+	// Body may contain {{error.status}}, {{error.type}}, {{error.title}}, {{error.message}},
+	// {{request.id}}, {{request.method}}, {{request.path}} and {{service.name}}
+	Body string
+}
+
+// I18nConfig holds the translations of the errors the gateway generates on proxied routes,
+// chosen by the Accept-Language of each request
+type I18nConfig struct {
+	// DefaultLocale is the locale of the built-in messages, served when no catalog matches
+	DefaultLocale string
+	// Directory holds catalogs named after their locale, such as fr.yaml or pt-BR.json
+	Directory string
+	// Catalogs are set in the config file by locale and take precedence over those of Directory
+	Catalogs map[string]I18nCatalogConfig
+}
+
+// I18nCatalogConfig holds the translations of one locale
+type I18nCatalogConfig struct {
+	// Titles translate problem titles, keyed by problem type name such as rate-limit-exceeded
+	Titles map[string]string
+	// Messages translate error messages, keyed by message ID such as invalid-token
+	Messages map[string]string
+}
+
+// ResidencyConfig holds how the gateway locates the region of the callers of services with data
+// residency. Sources are tried in order: RegionHeader, CountryHeader, Networks, then GeoIPFile.
+type ResidencyConfig struct {
+	// RegionHeader names a header carrying the caller's region, set by a trusted edge
+	RegionHeader string
+	// CountryHeader names a header carrying the caller's ISO country code, such as CF-IPCountry
+	CountryHeader string
+	// Countries maps ISO country codes to regions, for CountryHeader and GeoIPFile
+	Countries map[string]string
+	// Networks maps regions to the client networks in them, in CIDR notation
+	Networks map[string][]string
+	// GeoIPFile is a CSV database of client networks and their ISO country codes, one
+	// "network,country" line per network
+	GeoIPFile string
+}
+
+// ControlPlaneConfig holds settings for the gRPC control-plane API, which mirrors the REST
+// management API for infrastructure tooling
+type ControlPlaneConfig struct {
+	// Port is the port of the gRPC listener, 0 disables it
+	Port int
+	// TLS is the certificate of the gRPC listener, that of the HTTP listener when unset. Calls are
+	// served in plaintext when neither is set.
+	TLS TLSConfig
+}
+
+// XDSConfig holds settings for consuming routes and clusters from an Envoy xDS management
+// server, served besides the services of the storage backend
+type XDSConfig struct {
+	// Address is the host:port of the management server, empty disables xDS
+	Address string
+	// TLS connects to the management server over TLS
+	TLS bool
+	// NodeID and Cluster identify the gateway to the management server
+	NodeID  string
+	Cluster string
+	// RouteConfigs names the RouteConfiguration resources whose routes the gateway serves
+	RouteConfigs []string
+}
+
+// StreamListener is a parsed stream listener spec
+type StreamListener struct {
+	// Protocol is "tcp" or "udp"
+	Protocol       string
+	Address        string
+	Upstream       string
+	MaxConnections int
+	IdleTimeout    time.Duration
+}
+
+// ParseListeners parses the listener specs. The maxConnections and idleTimeout query
+// parameters of a spec override the defaults of the streams section.
+func (c StreamsConfig) ParseListeners() ([]StreamListener, error) {
+	listeners := make([]StreamListener, 0, len(c.Listeners))
+	for i, spec := range c.Listeners {
+		listener, err := c.parseListener(spec)
+		if err != nil {
+			return nil, fmt.Errorf("streams.listeners[%d]: %w", i, err)
+		}
+		listeners = append(listeners, listener)
+	}
+	return listeners, nil
+}
+
+func (c StreamsConfig) parseListener(spec string) (StreamListener, error) {
+	u, err := url.Parse(spec)
+	if err != nil {
+		return StreamListener{}, fmt.Errorf("invalid listener %q", spec)
+	}
+	if u.Scheme != "tcp" && u.Scheme != "udp" {
+		return StreamListener{}, fmt.Errorf("listener %q must use tcp:// or udp://", spec)
+	}
+
+	query := u.Query()
+	listener := StreamListener{
+		Protocol:       u.Scheme,
+		Address:        u.Host,
+		Upstream:       query.Get("upstream"),
+		MaxConnections: c.MaxConnections,
+		IdleTimeout:    c.IdleTimeout,
+	}
+	if _, _, err := net.SplitHostPort(listener.Address); err != nil {
+		return StreamListener{}, fmt.Errorf("listener %q needs a host:port address", spec)
+	}
+	if _, _, err := net.SplitHostPort(listener.Upstream); err != nil {
+		return StreamListener{}, fmt.Errorf("listener %q needs an upstream=host:port parameter", spec)
+	}
+	if value := query.Get("maxConnections"); value != "" {
+		if listener.MaxConnections, err = strconv.Atoi(value); err != nil || listener.MaxConnections < 0 {
+			return StreamListener{}, fmt.Errorf("listener %q has an invalid maxConnections %q", spec, value)
+		}
+	}
+	if value := query.Get("idleTimeout"); value != "" {
+		if listener.IdleTimeout, err = time.ParseDuration(value); err != nil || listener.IdleTimeout <= 0 {
+			return StreamListener{}, fmt.Errorf("listener %q has an invalid idleTimeout %q", spec, value)
+		}
+	}
+	return listener, nil
 }
 
 // LoadConfig loads configuration from environment variables and defaults
@@ -80,10 +953,15 @@ func LoadConfig(configPath string) (*Config, error) { // configPath is kept for
 // setDefaults sets default configuration values
 func setDefaults(v *viper.Viper) {
 	// Server defaults
+	v.SetDefault("server.profile", "production")
 	v.SetDefault("server.port", 8080)
 	v.SetDefault("server.readTimeout", "30s")
 	v.SetDefault("server.writeTimeout", "30s")
 	v.SetDefault("server.shutdownTimeout", "30s")
+	v.SetDefault("server.http2", true)
+	v.SetDefault("server.h2c", false)
+	v.SetDefault("server.tls.certFile", "")
+	v.SetDefault("server.tls.keyFile", "")
 
 	// Database defaults
 	v.SetDefault("database.host", "localhost")
@@ -93,17 +971,294 @@ func setDefaults(v *viper.Viper) {
 	v.SetDefault("database.database", "api_gateway")
 	v.SetDefault("database.sslmode", "disable")
 
+	// Storage defaults
+	v.SetDefault("storage.backend", "postgres")
+	v.SetDefault("storage.file.path", "data/gateway.json")
+
 	// Redis defaults
+	v.SetDefault("redis.mode", "single")
 	v.SetDefault("redis.address", "localhost:6379")
+	v.SetDefault("redis.username", "")
 	v.SetDefault("redis.password", "")
 	v.SetDefault("redis.db", 0)
+	v.SetDefault("redis.addresses", []string{})
+	v.SetDefault("redis.masterName", "")
+	v.SetDefault("redis.sentinelUsername", "")
+	v.SetDefault("redis.sentinelPassword", "")
+	v.SetDefault("redis.routeByLatency", false)
+	v.SetDefault("redis.healthCheckInterval", "5s")
+	v.SetDefault("redis.healthCheckTimeout", "1s")
+	v.SetDefault("redis.failureThreshold", 3)
+
+	// Cache defaults
+	v.SetDefault("cache.backend", "redis")
+	v.SetDefault("cache.bypassOnFailure", true)
+	v.SetDefault("cache.etags", "")
+	v.SetDefault("cache.memcached.addresses", []string{"localhost:11211"})
+	v.SetDefault("cache.memcached.timeout", "500ms")
+	v.SetDefault("cache.memory.maxEntries", 10000)
+	v.SetDefault("cache.memory.cleanupInterval", "1m")
+
+	// Rate limit defaults
+	v.SetDefault("rateLimit.backend", "redis")
+	v.SetDefault("rateLimit.failurePolicy", "local")
+	v.SetDefault("rateLimit.syncInterval", "0s")
+	v.SetDefault("rateLimit.concurrencyTTL", "5m")
+	v.SetDefault("rateLimit.overrideCacheTTL", "1m")
+	v.SetDefault("rateLimit.legacyHeaders", false)
 
 	// Auth defaults
 	v.SetDefault("auth.secretKey", "your-secret-key")
 	v.SetDefault("auth.issuer", "api-gateway")
 	v.SetDefault("auth.expiration", "24h")
+	v.SetDefault("auth.algorithm", "HS256")
+	v.SetDefault("auth.privateKeyFile", "")
+	v.SetDefault("auth.rotationInterval", "0s")
+	v.SetDefault("auth.defaultPolicy", "")
+	v.SetDefault("auth.claimHeaders", map[string]string{})
+	v.SetDefault("auth.identityToken.audience", "")
+	v.SetDefault("auth.identityToken.ttl", "1m")
+	v.SetDefault("auth.identityToken.header", "Authorization")
+	v.SetDefault("auth.external.url", "")
+	v.SetDefault("auth.external.timeout", "2s")
+	v.SetDefault("auth.external.cacheTTL", "30s")
+	v.SetDefault("auth.external.failOpen", false)
+	v.SetDefault("auth.external.forwardHeaders", []string{"Authorization", "Cookie"})
+	v.SetDefault("auth.external.upstreamHeaders", []string{"X-User-ID", "X-User-Roles"})
+	v.SetDefault("auth.ldap.url", "")
+	v.SetDefault("auth.ldap.bindDN", "")
+	v.SetDefault("auth.ldap.bindPassword", "")
+	v.SetDefault("auth.ldap.baseDN", "")
+	v.SetDefault("auth.ldap.userFilter", "(&(objectClass=person)(sAMAccountName={username}))")
+	v.SetDefault("auth.ldap.groupAttribute", "memberOf")
+	v.SetDefault("auth.ldap.groupRoles", map[string]string{})
+	v.SetDefault("auth.ldap.startTLS", false)
+	v.SetDefault("auth.ldap.timeout", "5s")
+	v.SetDefault("auth.oidc.issuer", "")
+	v.SetDefault("auth.oidc.clientID", "")
+	v.SetDefault("auth.oidc.clientSecret", "")
+	v.SetDefault("auth.oidc.redirectURL", "")
+	v.SetDefault("auth.oidc.scopes", []string{"openid", "profile", "email"})
+	v.SetDefault("auth.oidc.rolesClaim", "roles")
+	v.SetDefault("auth.oidc.loginTimeout", "10m")
+	v.SetDefault("auth.oidc.timeout", "10s")
+	v.SetDefault("auth.saml.entityID", "")
+	v.SetDefault("auth.saml.acsURL", "")
+	v.SetDefault("auth.saml.idpEntityID", "")
+	v.SetDefault("auth.saml.idpSSOURL", "")
+	v.SetDefault("auth.saml.idpCertificateFile", "")
+	v.SetDefault("auth.saml.rolesAttribute", "Role")
+	v.SetDefault("auth.saml.attributeRoles", map[string]string{})
+	v.SetDefault("auth.saml.clockSkew", "2m")
+	v.SetDefault("auth.saml.loginTimeout", "10m")
+	v.SetDefault("auth.session.cookieName", "gateway_session")
+	v.SetDefault("auth.session.secure", true)
 
 	// Logging defaults
 	v.SetDefault("logging.level", "info")
 	v.SetDefault("logging.development", false)
+	v.SetDefault("logging.redaction.headers", []string{"Authorization", "Proxy-Authorization", "Cookie", "Set-Cookie", "X-API-Key"})
+	v.SetDefault("logging.redaction.bodyFields", []string{"password", "secret", "token", "apiKey", "accessToken", "refreshToken"})
+	v.SetDefault("logging.redaction.mask", "[REDACTED]")
+	v.SetDefault("logging.sampling.enabled", true)
+	v.SetDefault("logging.sampling.initial", 100)
+	v.SetDefault("logging.sampling.thereafter", 100)
+	v.SetDefault("logging.sampling.tick", "1s")
+	v.SetDefault("logging.async.enabled", false)
+	v.SetDefault("logging.async.queueSize", 10000)
+	v.SetDefault("logging.async.bufferSize", 256*1024)
+	v.SetDefault("logging.async.flushInterval", "1s")
+	v.SetDefault("logging.outputs", []LogOutputConfig{})
+
+	// Security defaults
+	v.SetDefault("security.headers.strictTransportSecurity", "max-age=31536000; includeSubDomains")
+	v.SetDefault("security.headers.contentTypeOptions", "nosniff")
+	v.SetDefault("security.headers.frameOptions", "DENY")
+	v.SetDefault("security.headers.contentSecurityPolicy", "default-src 'none'; frame-ancestors 'none'")
+	v.SetDefault("security.headers.referrerPolicy", "no-referrer")
+	v.SetDefault("security.stripHeaders", []string{"Server", "X-Powered-By"})
+
+	// Debug defaults
+	v.SetDefault("debug.secret", "")
+	v.SetDefault("debug.maxClockSkew", "5m")
+	v.SetDefault("debug.sampleRate", 0.0)
+	v.SetDefault("debug.profiling", false)
+
+	// Metrics defaults
+	v.SetDefault("metrics.enabled", true)
+	v.SetDefault("metrics.window", "5m")
+	v.SetDefault("metrics.buckets", 60)
+
+	// Alerting defaults
+	v.SetDefault("alerting.webhooks", []string{})
+	v.SetDefault("alerting.slackWebhookURL", "")
+	v.SetDefault("alerting.slackChannels", map[string]string{})
+	v.SetDefault("alerting.timeout", "5s")
+	v.SetDefault("alerting.cooldown", "15m")
+	v.SetDefault("alerting.checkInterval", "30s")
+	v.SetDefault("alerting.errorRateThreshold", 0.05)
+	v.SetDefault("alerting.minRequests", 20)
+	v.SetDefault("alerting.sloBurnRateThreshold", 1.0)
+	v.SetDefault("alerting.bufferSize", 100)
+	v.SetDefault("alerting.maxRetries", 5)
+	v.SetDefault("alerting.failureThreshold", 3)
+	v.SetDefault("alerting.retryCooldown", "30s")
+
+	// Webhooks defaults
+	v.SetDefault("webhooks.timeout", "10s")
+	v.SetDefault("webhooks.maxRetries", 3)
+	v.SetDefault("webhooks.retryBackoff", "1s")
+	v.SetDefault("webhooks.workers", 4)
+	v.SetDefault("webhooks.queueSize", 1000)
+	v.SetDefault("webhooks.dropPolicy", "drop-oldest")
+
+	// Portal defaults
+	v.SetDefault("portal.enabled", false)
+	v.SetDefault("portal.pages", true)
+
+	// Mail defaults
+	v.SetDefault("mail.host", "")
+	v.SetDefault("mail.port", 587)
+	v.SetDefault("mail.username", "")
+	v.SetDefault("mail.password", "")
+	v.SetDefault("mail.from", "")
+	v.SetDefault("mail.timeout", "10s")
+
+	// Idempotency defaults
+	v.SetDefault("idempotency.enabled", true)
+	v.SetDefault("idempotency.ttl", "24h")
+	v.SetDefault("idempotency.lockTimeout", "1m")
+
+	// Upstream defaults
+	v.SetDefault("upstream.h2c", false)
+	v.SetDefault("upstream.oauthClients", map[string]interface{}{})
+	v.SetDefault("upstream.tokenTimeout", "10s")
+	v.SetDefault("upstream.deniedRequestHeaders", []string{})
+	v.SetDefault("upstream.deniedResponseHeaders", []string{})
+	v.SetDefault("upstream.trustedProxies", []string{})
+	v.SetDefault("upstream.forwardedHeader", false)
+	v.SetDefault("upstream.dnsCacheTTL", "0s")
+	v.SetDefault("upstream.drainTimeout", "30s")
+	v.SetDefault("upstream.networks", map[string]interface{}{})
+
+	// Streams defaults
+	v.SetDefault("streams.listeners", []string{})
+	v.SetDefault("streams.maxConnections", 1000)
+	v.SetDefault("streams.idleTimeout", "5m")
+
+	// Brokers defaults
+	v.SetDefault("brokers.timeout", "5s")
+	v.SetDefault("brokers.kafka.brokers", []string{})
+	v.SetDefault("brokers.rabbitmq.url", "")
+	v.SetDefault("brokers.rabbitmq.exchange", "")
+	v.SetDefault("brokers.nats.url", "")
+
+	// Async defaults
+	v.SetDefault("async.enabled", true)
+	v.SetDefault("async.workers", 10)
+	v.SetDefault("async.queueSize", 1000)
+	v.SetDefault("async.resultTTL", "1h")
+	v.SetDefault("async.timeout", "5m")
+	v.SetDefault("async.callbackHosts", []string{})
+	v.SetDefault("async.callbackSecret", "")
+
+	// Scheduler defaults
+	v.SetDefault("scheduler.enabled", true)
+	v.SetDefault("scheduler.timeout", "30s")
+	v.SetDefault("scheduler.historySize", 20)
+	v.SetDefault("scheduler.reloadInterval", "30s")
+
+	// Config sync defaults
+	v.SetDefault("configSync.enabled", false)
+	v.SetDefault("configSync.channel", "api-gateway:config")
+
+	// Leader election defaults
+	v.SetDefault("leaderElection.backend", "")
+	v.SetDefault("leaderElection.key", "api-gateway:leader")
+	v.SetDefault("leaderElection.leaseDuration", "15s")
+	v.SetDefault("leaderElection.renewInterval", "5s")
+
+	// Priority scheduling defaults
+	v.SetDefault("priority.enabled", false)
+	v.SetDefault("priority.maxInFlight", 500)
+	v.SetDefault("priority.queueSize", 1000)
+	v.SetDefault("priority.maxWait", "2s")
+	v.SetDefault("priority.plans", map[string]string{})
+	v.SetDefault("priority.defaultClass", "normal")
+
+	// Request deduplication defaults
+	v.SetDefault("dedup.enabled", false)
+
+	// Fault injection defaults
+	v.SetDefault("chaos.enabled", false)
+
+	// Egress defaults
+	v.SetDefault("egress.port", 0)
+
+	// Error page defaults
+	v.SetDefault("errorPages.templates", []ErrorTemplateConfig{})
+
+	// Error reporting defaults
+	v.SetDefault("errorReporting.dsn", "")
+	v.SetDefault("errorReporting.environment", "")
+	v.SetDefault("errorReporting.release", "")
+	v.SetDefault("errorReporting.timeout", "5s")
+	v.SetDefault("errorReporting.bufferSize", 100)
+
+	// Error message translation defaults
+	v.SetDefault("i18n.defaultLocale", "en")
+	v.SetDefault("i18n.directory", "")
+
+	// Data residency defaults
+	v.SetDefault("residency.regionHeader", "")
+	v.SetDefault("residency.countryHeader", "")
+	v.SetDefault("residency.geoIPFile", "")
+
+	// Control-plane defaults
+	v.SetDefault("controlPlane.port", 0)
+	v.SetDefault("controlPlane.tls.certFile", "")
+	v.SetDefault("controlPlane.tls.keyFile", "")
+
+	// xDS defaults
+	v.SetDefault("xds.address", "")
+	v.SetDefault("xds.tls", false)
+	v.SetDefault("xds.nodeID", "api-gateway")
+	v.SetDefault("xds.cluster", "api-gateway")
+	v.SetDefault("xds.routeConfigs", []string{})
+
+	// Routing defaults
+	v.SetDefault("routing.conflicts", "reject")
+	v.SetDefault("routing.expiryCleanupInterval", "1m")
+	v.SetDefault("routing.policyCacheTTL", "1m")
+
+	// Uploads defaults
+	v.SetDefault("uploads.spoolDir", "")
+	v.SetDefault("uploads.scanner.url", "")
+	v.SetDefault("uploads.scanner.timeout", "30s")
+	v.SetDefault("uploads.scanner.failOpen", false)
+
+	// Feature flags defaults
+	v.SetDefault("featureFlags.provider", "builtin")
+	v.SetDefault("featureFlags.cacheTTL", "30s")
+	v.SetDefault("featureFlags.ofrep.url", "")
+	v.SetDefault("featureFlags.ofrep.token", "")
+	v.SetDefault("featureFlags.ofrep.timeout", "2s")
+	v.SetDefault("experiments.cookieMaxAge", "720h")
+	v.SetDefault("experiments.exposureLog", []LogOutputConfig{})
+
+	// Secrets defaults
+	v.SetDefault("secrets.provider", "env")
+	v.SetDefault("secrets.names", []string{})
+	v.SetDefault("secrets.refreshInterval", "5m")
+	v.SetDefault("secrets.timeout", "10s")
+	v.SetDefault("secrets.file.directory", "/run/secrets")
+	v.SetDefault("secrets.vault.address", "http://localhost:8200")
+	v.SetDefault("secrets.vault.token", "")
+	v.SetDefault("secrets.vault.mountPath", "secret")
+	v.SetDefault("secrets.vault.path", "api-gateway")
+	v.SetDefault("secrets.aws.region", "us-east-1")
+	v.SetDefault("secrets.aws.secretID", "api-gateway")
+	v.SetDefault("secrets.aws.accessKeyID", "")
+	v.SetDefault("secrets.aws.secretAccessKey", "")
+	v.SetDefault("secrets.aws.sessionToken", "")
 }
@@ -0,0 +1,596 @@
+package config
+
+import (
+	"fmt"
+	"net"
+	"net/netip"
+	"net/url"
+	"regexp"
+	"sort"
+	"strings"
+
+	"golang.org/x/text/language"
+)
+
+// insecureSecretKeys are the placeholder JWT secrets shipped in the defaults and examples
+var insecureSecretKeys = []string{"your-secret-key", "your-secret-key-change-me"}
+
+// errorTemplateStatus matches the statuses of error templates, such as 404 or 5xx
+var errorTemplateStatus = regexp.MustCompile(`^[45]([0-9][0-9]|xx)$`)
+
+// regionName matches region names such as eu or us-east
+var regionName = regexp.MustCompile(`^[a-z0-9][a-z0-9-]*$`)
+
+// networkName matches the names of upstream networks, as services reference them
+var networkName = regexp.MustCompile(`^[a-z0-9][a-z0-9_-]*$`)
+
+// countryCode matches ISO 3166-1 alpha-2 country codes
+var countryCode = regexp.MustCompile(`^[A-Za-z]{2}$`)
+
+// headerName matches HTTP header names
+var headerName = regexp.MustCompile("^[A-Za-z0-9!#$%&'*+.^_`|~-]+$")
+
+// reservedClaimHeaders are the headers claims cannot be forwarded in, as the gateway or HTTP
+// itself sets them
+var reservedClaimHeaders = map[string]bool{
+	"authorization": true, "cookie": true, "host": true, "connection": true, "content-length": true,
+	"content-type": true, "transfer-encoding": true, "upgrade": true, "te": true, "trailer": true,
+}
+
+// minSecretKeyLength is the shortest HMAC secret that is not reported as weak
+const minSecretKeyLength = 32
+
+// ValidationError lists every invalid setting found by Validate
+type ValidationError struct {
+	Problems []string
+}
+
+// Error returns all problems, one per line
+func (e *ValidationError) Error() string {
+	return "invalid configuration:\n  - " + strings.Join(e.Problems, "\n  - ")
+}
+
+// validator collects problems so all of them are reported at once
+type validator struct {
+	problems []string
+}
+
+func (v *validator) check(ok bool, format string, args ...interface{}) {
+	if !ok {
+		v.problems = append(v.problems, fmt.Sprintf(format, args...))
+	}
+}
+
+func (v *validator) oneOf(key string, value string, allowed ...string) {
+	for _, a := range allowed {
+		if value == a {
+			return
+		}
+	}
+	v.problems = append(v.problems, fmt.Sprintf("%s must be one of %s, got %q", key, strings.Join(allowed, ", "), value))
+}
+
+func (v *validator) url(key string, value string, schemes ...string) {
+	u, err := url.Parse(value)
+	if err != nil || u.Host == "" {
+		v.problems = append(v.problems, fmt.Sprintf("%s must be an absolute URL, got %q", key, value))
+		return
+	}
+	v.oneOf(key+" scheme", u.Scheme, schemes...)
+}
+
+// Validate checks the configuration for missing or out of range values and returns a
+// *ValidationError listing every problem. It should run after secrets are applied.
+func (c *Config) Validate() error {
+	v := &validator{}
+
+	// Server
+	v.oneOf("server.profile", c.Server.Profile, "development", "staging", "production")
+	v.check(c.Server.Port > 0 && c.Server.Port <= 65535, "server.port must be between 1 and 65535, got %d", c.Server.Port)
+	v.check(c.Server.ReadTimeout > 0, "server.readTimeout must be positive, got %s", c.Server.ReadTimeout)
+	v.check(c.Server.WriteTimeout > 0, "server.writeTimeout must be positive, got %s", c.Server.WriteTimeout)
+	v.check(c.Server.ShutdownTimeout > 0, "server.shutdownTimeout must be positive, got %s", c.Server.ShutdownTimeout)
+	v.check((c.Server.TLS.CertFile == "") == (c.Server.TLS.KeyFile == ""), "server.tls.certFile and server.tls.keyFile must be set together")
+	if c.Server.H2C {
+		v.check(c.Server.HTTP2, "server.h2c requires server.http2")
+		v.check(c.Server.TLS.CertFile == "", "server.h2c cannot be combined with server.tls, which negotiates HTTP/2 itself")
+	}
+
+	// Storage
+	v.oneOf("storage.backend", c.Storage.Backend, "postgres", "file")
+	switch c.Storage.Backend {
+	case "postgres":
+		v.check(c.Database.Host != "", "database.host is required")
+		v.check(c.Database.Port > 0 && c.Database.Port <= 65535, "database.port must be between 1 and 65535, got %d", c.Database.Port)
+		v.check(c.Database.Database != "", "database.database is required")
+	case "file":
+		v.check(c.Storage.File.Path != "", "storage.file.path is required with the file storage backend")
+	}
+
+	// Redis, cache and rate limiting
+	v.oneOf("cache.backend", c.Cache.Backend, "redis", "memcached", "memory")
+	v.oneOf("rateLimit.backend", c.RateLimit.Backend, "redis", "memory")
+	v.oneOf("rateLimit.failurePolicy", c.RateLimit.FailurePolicy, "fail-open", "fail-closed", "local")
+	v.check(c.RateLimit.SyncInterval >= 0, "rateLimit.syncInterval must not be negative, got %s", c.RateLimit.SyncInterval)
+	v.check(c.RateLimit.OverrideCacheTTL > 0, "rateLimit.overrideCacheTTL must be positive, got %s", c.RateLimit.OverrideCacheTTL)
+	v.check(c.RateLimit.ConcurrencyTTL > 0, "rateLimit.concurrencyTTL must be positive, got %s", c.RateLimit.ConcurrencyTTL)
+	if c.Cache.Backend == "redis" || c.RateLimit.Backend == "redis" || c.ConfigSync.Enabled || c.LeaderElection.Backend == "redis" {
+		v.oneOf("redis.mode", c.Redis.Mode, "single", "cluster", "sentinel")
+		v.check(c.Redis.Address != "" || len(c.Redis.Addresses) > 0, "redis.address or redis.addresses is required")
+		v.check(c.Redis.Mode != "sentinel" || c.Redis.MasterName != "", "redis.masterName is required in sentinel mode")
+		v.check(c.Redis.HealthCheckInterval >= 0, "redis.healthCheckInterval must not be negative, got %s", c.Redis.HealthCheckInterval)
+		v.check(c.Redis.FailureThreshold > 0, "redis.failureThreshold must be positive, got %d", c.Redis.FailureThreshold)
+	}
+	if c.Cache.Backend == "memcached" {
+		v.check(len(c.Cache.Memcached.Addresses) > 0, "cache.memcached.addresses is required with the memcached cache backend")
+	}
+	if c.Cache.ETags != "" {
+		v.oneOf("cache.etags", c.Cache.ETags, "strong", "weak")
+	}
+	v.check(c.Cache.Memory.MaxEntries >= 0, "cache.memory.maxEntries must not be negative, got %d", c.Cache.Memory.MaxEntries)
+
+	// Auth
+	c.validateAuth(v)
+
+	// Logging and secrets
+	c.validateLogging(v)
+	v.oneOf("secrets.provider", c.Secrets.Provider, "env", "file", "vault", "aws")
+	v.check(c.Secrets.RefreshInterval >= 0, "secrets.refreshInterval must not be negative, got %s", c.Secrets.RefreshInterval)
+
+	// Observability
+	v.check(c.Debug.SampleRate >= 0 && c.Debug.SampleRate <= 1, "debug.sampleRate must be between 0 and 1, got %g", c.Debug.SampleRate)
+	v.check(c.Metrics.Window > 0, "metrics.window must be positive, got %s", c.Metrics.Window)
+	v.check(c.Metrics.Buckets > 0, "metrics.buckets must be positive, got %d", c.Metrics.Buckets)
+	for i, hook := range c.Alerting.Webhooks {
+		v.url(fmt.Sprintf("alerting.webhooks[%d]", i), hook, "http", "https")
+	}
+	if c.Alerting.SlackWebhookURL != "" {
+		v.url("alerting.slackWebhookURL", c.Alerting.SlackWebhookURL, "https")
+	}
+	slackChannels := make([]string, 0, len(c.Alerting.SlackChannels))
+	for channel := range c.Alerting.SlackChannels {
+		slackChannels = append(slackChannels, channel)
+	}
+	sort.Strings(slackChannels)
+	for _, channel := range slackChannels {
+		v.url("alerting.slackChannels."+channel, c.Alerting.SlackChannels[channel], "https")
+	}
+	v.check(c.Alerting.ErrorRateThreshold > 0 && c.Alerting.ErrorRateThreshold <= 1, "alerting.errorRateThreshold must be between 0 (exclusive) and 1, got %g", c.Alerting.ErrorRateThreshold)
+	v.check(c.Alerting.SLOBurnRateThreshold > 0, "alerting.sloBurnRateThreshold must be positive, got %g", c.Alerting.SLOBurnRateThreshold)
+	v.check(c.Alerting.BufferSize > 0, "alerting.bufferSize must be positive, got %d", c.Alerting.BufferSize)
+	v.check(c.Alerting.MaxRetries >= 0, "alerting.maxRetries must not be negative, got %d", c.Alerting.MaxRetries)
+	v.check(c.Alerting.FailureThreshold > 0, "alerting.failureThreshold must be positive, got %d", c.Alerting.FailureThreshold)
+	v.check(c.Alerting.RetryCooldown > 0, "alerting.retryCooldown must be positive, got %s", c.Alerting.RetryCooldown)
+	v.check(c.Webhooks.Timeout > 0, "webhooks.timeout must be positive, got %s", c.Webhooks.Timeout)
+	v.check(c.Webhooks.MaxRetries >= 0, "webhooks.maxRetries must not be negative, got %d", c.Webhooks.MaxRetries)
+	v.check(c.Webhooks.Workers > 0, "webhooks.workers must be positive, got %d", c.Webhooks.Workers)
+	v.check(c.Webhooks.QueueSize >= 0, "webhooks.queueSize must not be negative, got %d", c.Webhooks.QueueSize)
+	v.oneOf("webhooks.dropPolicy", c.Webhooks.DropPolicy, "reject", "drop-oldest", "block")
+	if c.Mail.Host != "" {
+		v.check(c.Mail.Port > 0 && c.Mail.Port <= 65535, "mail.port must be between 1 and 65535, got %d", c.Mail.Port)
+		v.check(c.Mail.From != "", "mail.from is required when mail.host is set")
+		v.check(c.Mail.Timeout > 0, "mail.timeout must be positive, got %s", c.Mail.Timeout)
+	}
+	credentialNames := make([]string, 0, len(c.Upstream.Credentials))
+	for name := range c.Upstream.Credentials {
+		credentialNames = append(credentialNames, name)
+	}
+	sort.Strings(credentialNames)
+	for _, name := range credentialNames {
+		v.check(c.Upstream.Credentials[name].SecretAccessKey != "", "upstream.credentials.%s.secretAccessKey is required", name)
+	}
+	oauthClientNames := make([]string, 0, len(c.Upstream.OAuthClients))
+	for name := range c.Upstream.OAuthClients {
+		oauthClientNames = append(oauthClientNames, name)
+	}
+	sort.Strings(oauthClientNames)
+	for _, name := range oauthClientNames {
+		client := c.Upstream.OAuthClients[name]
+		v.url("upstream.oauthClients."+name+".tokenURL", client.TokenURL, "http", "https")
+		v.check(client.ClientID != "", "upstream.oauthClients.%s.clientID is required", name)
+	}
+	v.check(c.Upstream.TokenTimeout > 0, "upstream.tokenTimeout must be positive, got %s", c.Upstream.TokenTimeout)
+	for _, header := range c.Upstream.DeniedRequestHeaders {
+		v.check(headerName.MatchString(header), "upstream.deniedRequestHeaders must list header names, got %q", header)
+		if c.Auth.IdentityToken.Audience != "" {
+			v.check(!strings.EqualFold(header, c.Auth.IdentityToken.Header), "upstream.deniedRequestHeaders removes the auth.identityToken.header %s", header)
+		}
+		for claimHeader := range c.Auth.ClaimHeaders {
+			v.check(!strings.EqualFold(header, claimHeader), "upstream.deniedRequestHeaders removes the auth.claimHeaders header %s", header)
+		}
+	}
+	for _, header := range c.Upstream.DeniedResponseHeaders {
+		v.check(headerName.MatchString(header), "upstream.deniedResponseHeaders must list header names, got %q", header)
+	}
+	v.check(c.Upstream.DNSCacheTTL >= 0, "upstream.dnsCacheTTL must not be negative, got %s", c.Upstream.DNSCacheTTL)
+	v.check(c.Upstream.DrainTimeout >= 0, "upstream.drainTimeout must not be negative, got %s", c.Upstream.DrainTimeout)
+	for i, network := range c.Upstream.TrustedProxies {
+		_, err := netip.ParsePrefix(network)
+		v.check(err == nil, "upstream.trustedProxies[%d] must be a network such as 10.0.0.0/8, got %q", i, network)
+	}
+	networkNames := make([]string, 0, len(c.Upstream.Networks))
+	for name := range c.Upstream.Networks {
+		networkNames = append(networkNames, name)
+	}
+	sort.Strings(networkNames)
+	for _, name := range networkNames {
+		network := c.Upstream.Networks[name]
+		key := "upstream.networks." + name
+		v.check(networkName.MatchString(name), "%s must be named with lower case letters, digits, - and _", key)
+		if network.Proxy != "" {
+			v.url(key+".proxy", network.Proxy, "http", "https", "socks5")
+		}
+		for i, resolver := range network.Resolvers {
+			_, port, err := net.SplitHostPort(resolver)
+			v.check(err == nil && port != "", "%s.resolvers[%d] must be a host:port address, got %q", key, i, resolver)
+		}
+		for i, host := range network.Hosts {
+			v.check(host.Host != "", "%s.hosts[%d].host is required", key, i)
+			_, err := netip.ParseAddr(host.Address)
+			v.check(err == nil, "%s.hosts[%d].address must be an IP address, got %q", key, i, host.Address)
+		}
+	}
+	if c.Idempotency.Enabled {
+		v.check(c.Idempotency.TTL > 0, "idempotency.ttl must be positive, got %s", c.Idempotency.TTL)
+		v.check(c.Idempotency.LockTimeout > 0, "idempotency.lockTimeout must be positive, got %s", c.Idempotency.LockTimeout)
+	}
+
+	if c.Async.Enabled {
+		v.check(c.Async.Workers > 0, "async.workers must be positive, got %d", c.Async.Workers)
+		v.check(c.Async.QueueSize >= 0, "async.queueSize must not be negative, got %d", c.Async.QueueSize)
+		v.check(c.Async.ResultTTL > 0, "async.resultTTL must be positive, got %s", c.Async.ResultTTL)
+		v.check(c.Async.Timeout > 0, "async.timeout must be positive, got %s", c.Async.Timeout)
+	}
+	v.check(c.Scheduler.Timeout > 0, "scheduler.timeout must be positive, got %s", c.Scheduler.Timeout)
+	v.check(c.Scheduler.HistorySize > 0, "scheduler.historySize must be positive, got %d", c.Scheduler.HistorySize)
+	if c.Scheduler.Enabled {
+		v.check(c.Scheduler.ReloadInterval > 0, "scheduler.reloadInterval must be positive, got %s", c.Scheduler.ReloadInterval)
+	}
+	if c.ConfigSync.Enabled {
+		v.check(c.ConfigSync.Channel != "", "configSync.channel is required when configSync is enabled")
+	}
+	if election := c.LeaderElection; election.Backend != "" {
+		v.oneOf("leaderElection.backend", election.Backend, "redis", "postgres")
+		v.check(election.Key != "", "leaderElection.key is required when leader election is enabled")
+		v.check(election.RenewInterval > 0, "leaderElection.renewInterval must be positive, got %s", election.RenewInterval)
+		if election.Backend == "redis" {
+			v.check(election.LeaseDuration > election.RenewInterval, "leaderElection.leaseDuration must be longer than leaderElection.renewInterval")
+		}
+		if election.Backend == "postgres" {
+			v.check(c.Storage.Backend == "postgres", "leaderElection.backend postgres requires storage.backend postgres")
+		}
+	}
+	if priority := c.Priority; priority.Enabled {
+		v.check(priority.MaxInFlight > 0, "priority.maxInFlight must be positive, got %d", priority.MaxInFlight)
+		v.check(priority.QueueSize >= 0, "priority.queueSize must not be negative, got %d", priority.QueueSize)
+		v.check(priority.MaxWait > 0, "priority.maxWait must be positive, got %s", priority.MaxWait)
+		v.oneOf("priority.defaultClass", priority.DefaultClass, "high", "normal", "low")
+		for plan, class := range priority.Plans {
+			v.oneOf("priority.plans."+plan, class, "high", "normal", "low")
+		}
+	}
+	if c.Chaos.Enabled {
+		v.check(c.Server.Profile != "production", "chaos.enabled is not allowed in the production profile")
+	}
+	v.oneOf("routing.conflicts", c.Routing.Conflicts, "reject", "warn")
+	v.check(c.Routing.ExpiryCleanupInterval >= 0, "routing.expiryCleanupInterval must not be negative, got %s", c.Routing.ExpiryCleanupInterval)
+	v.check(c.Routing.PolicyCacheTTL > 0, "routing.policyCacheTTL must be positive, got %s", c.Routing.PolicyCacheTTL)
+	if scanner := c.Uploads.Scanner; scanner.URL != "" {
+		v.url("uploads.scanner.url", scanner.URL, "http", "https")
+		v.check(scanner.Timeout > 0, "uploads.scanner.timeout must be positive, got %s", scanner.Timeout)
+	}
+	v.oneOf("featureFlags.provider", c.FeatureFlags.Provider, "builtin", "ofrep")
+	v.check(c.FeatureFlags.CacheTTL > 0, "featureFlags.cacheTTL must be positive, got %s", c.FeatureFlags.CacheTTL)
+	if c.FeatureFlags.Provider == "ofrep" {
+		v.url("featureFlags.ofrep.url", c.FeatureFlags.OFREP.URL, "http", "https")
+		v.check(c.FeatureFlags.OFREP.Timeout > 0, "featureFlags.ofrep.timeout must be positive, got %s", c.FeatureFlags.OFREP.Timeout)
+	}
+	v.check(c.Experiments.CookieMaxAge > 0, "experiments.cookieMaxAge must be positive, got %s", c.Experiments.CookieMaxAge)
+	validateLogOutputs(v, "experiments.exposureLog", c.Experiments.ExposureLog)
+	c.validateStreams(v)
+	c.validateEgress(v)
+	for i, template := range c.ErrorPages.Templates {
+		key := fmt.Sprintf("errorPages.templates[%d]", i)
+		v.check(template.Status == "" || errorTemplateStatus.MatchString(template.Status), "%s.status must be a status such as 404 or a class such as 5xx, got %q", key, template.Status)
+		v.check(template.ContentType != "", "%s.contentType is required", key)
+		v.check(template.Body != "", "%s.body is required", key)
+	}
+	if reporting := c.ErrorReporting; reporting.DSN != "" {
+		v.url("errorReporting.dsn", reporting.DSN, "http", "https")
+		v.check(reporting.Timeout > 0, "errorReporting.timeout must be positive, got %s", reporting.Timeout)
+		v.check(reporting.BufferSize > 0, "errorReporting.bufferSize must be positive, got %d", reporting.BufferSize)
+	}
+	_, err := language.Parse(c.I18n.DefaultLocale)
+	v.check(err == nil, "i18n.defaultLocale must be a language tag such as en or pt-BR, got %q", c.I18n.DefaultLocale)
+	locales := make([]string, 0, len(c.I18n.Catalogs))
+	for locale := range c.I18n.Catalogs {
+		locales = append(locales, locale)
+	}
+	sort.Strings(locales)
+	for _, locale := range locales {
+		_, err := language.Parse(locale)
+		v.check(err == nil, "i18n.catalogs.%s must be keyed by a language tag such as en or pt-BR", locale)
+	}
+	c.validateResidency(v)
+	if port := c.ControlPlane.Port; port != 0 {
+		v.check(port > 0 && port <= 65535, "controlPlane.port must be between 1 and 65535, got %d", port)
+		v.check(port != c.Server.Port && port != c.Egress.Port, "controlPlane.port must differ from server.port and egress.port")
+	}
+	if xds := c.XDS; xds.Address != "" {
+		_, port, err := net.SplitHostPort(xds.Address)
+		v.check(err == nil && port != "", "xds.address must be a host:port such as xds.example.com:18000, got %q", xds.Address)
+		v.check(xds.NodeID != "", "xds.nodeID is required when xds.address is set")
+		v.check(len(xds.RouteConfigs) > 0, "xds.routeConfigs is required when xds.address is set")
+	}
+
+	// Brokers
+	v.check(c.Brokers.Timeout > 0, "brokers.timeout must be positive, got %s", c.Brokers.Timeout)
+	if c.Brokers.RabbitMQ.URL != "" {
+		v.url("brokers.rabbitmq.url", c.Brokers.RabbitMQ.URL, "amqp", "amqps")
+	}
+	if c.Brokers.NATS.URL != "" {
+		v.url("brokers.nats.url", c.Brokers.NATS.URL, "nats", "tls")
+	}
+
+	if len(v.problems) > 0 {
+		return &ValidationError{Problems: v.problems}
+	}
+	return nil
+}
+
+func (c *Config) validateAuth(v *validator) {
+	auth := c.Auth
+	v.check(auth.Issuer != "", "auth.issuer is required")
+	v.check(auth.Expiration > 0, "auth.expiration must be positive, got %s", auth.Expiration)
+	v.check(auth.RotationInterval >= 0, "auth.rotationInterval must not be negative, got %s", auth.RotationInterval)
+	v.oneOf("auth.algorithm", auth.Algorithm, "HS256", "HS384", "HS512", "RS256", "RS384", "RS512", "ES256", "ES384", "ES512")
+	if auth.isHMAC() {
+		v.check(auth.SecretKey != "", "auth.secretKey is required for %s", auth.Algorithm)
+	} else if c.multiInstance() {
+		// Instances generating or rotating their own keys reject the tokens of one another
+		v.check(auth.PrivateKeyFile != "", "auth.privateKeyFile is required for %s when several instances run, as each would generate its own key", auth.Algorithm)
+		v.check(auth.RotationInterval == 0, "auth.rotationInterval must be 0 for %s when several instances run, as each would rotate to its own key", auth.Algorithm)
+	}
+
+	headers := make([]string, 0, len(auth.ClaimHeaders))
+	for header := range auth.ClaimHeaders {
+		headers = append(headers, header)
+	}
+	sort.Strings(headers)
+	for _, header := range headers {
+		v.check(headerName.MatchString(header), "auth.claimHeaders must be keyed by header names, got %q", header)
+		v.check(!reservedClaimHeaders[strings.ToLower(header)], "auth.claimHeaders cannot set the %s header", header)
+		v.check(auth.ClaimHeaders[header] != "", "auth.claimHeaders.%s must name a claim", header)
+	}
+
+	if identity := auth.IdentityToken; identity.Audience != "" {
+		v.check(identity.Audience != auth.Issuer, "auth.identityToken.audience must differ from auth.issuer")
+		v.check(identity.TTL > 0, "auth.identityToken.ttl must be positive, got %s", identity.TTL)
+		v.check(headerName.MatchString(identity.Header), "auth.identityToken.header must be a header name, got %q", identity.Header)
+		for header := range auth.ClaimHeaders {
+			v.check(!strings.EqualFold(header, identity.Header), "auth.identityToken.header %s is also in auth.claimHeaders", identity.Header)
+		}
+	}
+
+	if auth.External.URL != "" {
+		v.url("auth.external.url", auth.External.URL, "http", "https")
+		v.check(auth.External.Timeout > 0, "auth.external.timeout must be positive, got %s", auth.External.Timeout)
+	}
+
+	if auth.LDAP.URL != "" {
+		v.url("auth.ldap.url", auth.LDAP.URL, "ldap", "ldaps")
+		v.check(auth.LDAP.BaseDN != "", "auth.ldap.baseDN is required when LDAP is enabled")
+		v.check(strings.Contains(auth.LDAP.UserFilter, "{username}"), "auth.ldap.userFilter must contain {username}, got %q", auth.LDAP.UserFilter)
+		v.check(auth.LDAP.Timeout > 0, "auth.ldap.timeout must be positive, got %s", auth.LDAP.Timeout)
+	}
+	if auth.OIDC.Issuer != "" {
+		v.url("auth.oidc.issuer", auth.OIDC.Issuer, "http", "https")
+		v.check(auth.OIDC.ClientID != "", "auth.oidc.clientID is required when OIDC login is enabled")
+		v.url("auth.oidc.redirectURL", auth.OIDC.RedirectURL, "http", "https")
+		v.check(auth.OIDC.LoginTimeout > 0, "auth.oidc.loginTimeout must be positive, got %s", auth.OIDC.LoginTimeout)
+		v.check(auth.OIDC.Timeout > 0, "auth.oidc.timeout must be positive, got %s", auth.OIDC.Timeout)
+	}
+	if auth.SAML.EntityID != "" {
+		v.url("auth.saml.acsURL", auth.SAML.ACSURL, "http", "https")
+		v.check(auth.SAML.IDPEntityID != "", "auth.saml.idpEntityID is required when SAML login is enabled")
+		v.url("auth.saml.idpSSOURL", auth.SAML.IDPSSOURL, "http", "https")
+		v.check(auth.SAML.IDPCertificateFile != "", "auth.saml.idpCertificateFile is required when SAML login is enabled")
+		v.check(auth.SAML.ClockSkew >= 0, "auth.saml.clockSkew must not be negative, got %s", auth.SAML.ClockSkew)
+		v.check(auth.SAML.LoginTimeout > 0, "auth.saml.loginTimeout must be positive, got %s", auth.SAML.LoginTimeout)
+	}
+	if auth.OIDC.Issuer != "" || auth.SAML.EntityID != "" {
+		v.check(auth.Session.CookieName != "", "auth.session.cookieName is required when OIDC or SAML login is enabled")
+	}
+}
+
+func (c *Config) validateLogging(v *validator) {
+	logging := c.Logging
+	v.oneOf("logging.level", logging.Level, "debug", "info", "warn", "error")
+	if sampling := logging.Sampling; sampling.Enabled {
+		v.check(sampling.Initial >= 0, "logging.sampling.initial must not be negative, got %d", sampling.Initial)
+		v.check(sampling.Thereafter >= 0, "logging.sampling.thereafter must not be negative, got %d", sampling.Thereafter)
+		v.check(sampling.Tick > 0, "logging.sampling.tick must be positive, got %s", sampling.Tick)
+	}
+	if async := logging.Async; async.Enabled {
+		v.check(async.QueueSize > 0, "logging.async.queueSize must be positive, got %d", async.QueueSize)
+		v.check(async.BufferSize > 0, "logging.async.bufferSize must be positive, got %d", async.BufferSize)
+		v.check(async.FlushInterval > 0, "logging.async.flushInterval must be positive, got %s", async.FlushInterval)
+	}
+
+	validateLogOutputs(v, "logging.outputs", logging.Outputs)
+}
+
+// validateLogOutputs checks the outputs of a logger configured under prefix
+func validateLogOutputs(v *validator, prefix string, outputs []LogOutputConfig) {
+	for i, output := range outputs {
+		key := fmt.Sprintf("%s[%d]", prefix, i)
+		v.oneOf(key+".type", output.Type, "stdout", "stderr", "file", "syslog", "loki")
+		if output.Level != "" {
+			v.oneOf(key+".level", output.Level, "debug", "info", "warn", "error")
+		}
+		switch output.Type {
+		case "file":
+			v.check(output.Path != "", "%s.path is required for a file output", key)
+			v.check(output.MaxSizeMB >= 0, "%s.maxSizeMB must not be negative, got %d", key, output.MaxSizeMB)
+		case "syslog":
+			if output.Address != "" {
+				if u, err := url.Parse(output.Address); err != nil || (u.Host == "" && u.Path == "") {
+					v.check(false, "%s.address must be a URL such as udp://syslog:514, got %q", key, output.Address)
+				} else {
+					v.oneOf(key+".address scheme", u.Scheme, "udp", "tcp", "unix", "unixgram")
+				}
+			}
+		case "loki":
+			v.url(key+".url", output.URL, "http", "https")
+			v.check(output.BatchSize > 0, "%s.batchSize must be positive, got %d", key, output.BatchSize)
+			v.check(output.FlushInterval > 0, "%s.flushInterval must be positive, got %s", key, output.FlushInterval)
+			v.check(output.Timeout > 0, "%s.timeout must be positive, got %s", key, output.Timeout)
+		}
+	}
+}
+
+func (c *Config) validateStreams(v *validator) {
+	streams := c.Streams
+	v.check(streams.MaxConnections >= 0, "streams.maxConnections must not be negative, got %d", streams.MaxConnections)
+	v.check(streams.IdleTimeout > 0, "streams.idleTimeout must be positive, got %s", streams.IdleTimeout)
+
+	addresses := make(map[string]bool, len(streams.Listeners))
+	for i, spec := range streams.Listeners {
+		listener, err := streams.parseListener(spec)
+		if err != nil {
+			v.problems = append(v.problems, fmt.Sprintf("streams.listeners[%d]: %s", i, err))
+			continue
+		}
+		key := listener.Protocol + " " + listener.Address
+		v.check(!addresses[key], "streams.listeners[%d]: %s %s is already used by another listener", i, listener.Protocol, listener.Address)
+		addresses[key] = true
+	}
+}
+
+func (c *Config) validateEgress(v *validator) {
+	egress := c.Egress
+	if egress.Port == 0 {
+		return
+	}
+	v.check(egress.Port > 0 && egress.Port <= 65535, "egress.port must be between 1 and 65535, got %d", egress.Port)
+	v.check(egress.Port != c.Server.Port, "egress.port must differ from server.port")
+	v.check(len(egress.Destinations) > 0, "egress.destinations is required when egress.port is set")
+
+	for i, destination := range egress.Destinations {
+		key := fmt.Sprintf("egress.destinations[%d]", i)
+		v.check(destination.Host != "", "%s.host is required", key)
+		if destination.Scheme != "" {
+			v.oneOf(key+".scheme", destination.Scheme, "http", "https")
+		}
+		v.check(destination.RateLimit >= 0, "%s.rateLimit must not be negative, got %d", key, destination.RateLimit)
+		if signing := destination.Signing; signing != nil {
+			v.oneOf(key+".signing.scheme", signing.Scheme, "aws-sigv4", "hmac", "oauth2")
+			if signing.Scheme == "oauth2" {
+				_, ok := c.Upstream.OAuthClients[signing.Credentials]
+				v.check(ok, "%s.signing.credentials %q is not in upstream.oauthClients", key, signing.Credentials)
+			} else {
+				_, ok := c.Upstream.Credentials[signing.Credentials]
+				v.check(ok, "%s.signing.credentials %q is not in upstream.credentials", key, signing.Credentials)
+			}
+			if signing.Scheme == "aws-sigv4" {
+				v.check(signing.Region != "" && signing.Service != "", "%s.signing requires a region and a service with aws-sigv4", key)
+			}
+		}
+	}
+}
+
+func (c *Config) validateResidency(v *validator) {
+	countries := make([]string, 0, len(c.Residency.Countries))
+	for country := range c.Residency.Countries {
+		countries = append(countries, country)
+	}
+	sort.Strings(countries)
+	for _, country := range countries {
+		region := c.Residency.Countries[country]
+		v.check(countryCode.MatchString(country), "residency.countries must be keyed by ISO country codes such as DE, got %q", country)
+		v.check(regionName.MatchString(region), "residency.countries.%s must be a region such as eu or us-east, got %q", country, region)
+	}
+
+	regions := make([]string, 0, len(c.Residency.Networks))
+	for region := range c.Residency.Networks {
+		regions = append(regions, region)
+	}
+	sort.Strings(regions)
+	for _, region := range regions {
+		v.check(regionName.MatchString(region), "residency.networks must be keyed by regions such as eu or us-east, got %q", region)
+		for i, network := range c.Residency.Networks[region] {
+			_, err := netip.ParsePrefix(network)
+			v.check(err == nil, "residency.networks.%s[%d] must be a network such as 10.1.0.0/16, got %q", region, i, network)
+		}
+	}
+}
+
+// Warnings returns settings that are valid but insecure or unsuitable for production
+func (c *Config) Warnings() []string {
+	var warnings []string
+	warn := func(format string, args ...interface{}) {
+		warnings = append(warnings, fmt.Sprintf(format, args...))
+	}
+
+	if c.Auth.isHMAC() {
+		if isPlaceholderSecret(c.Auth.SecretKey) {
+			warn("auth.secretKey is the placeholder %q; anyone can forge tokens", c.Auth.SecretKey)
+		} else if c.Auth.SecretKey != "" && len(c.Auth.SecretKey) < minSecretKeyLength {
+			warn("auth.secretKey is shorter than %d bytes", minSecretKeyLength)
+		}
+	}
+	if c.Storage.Backend == "postgres" && c.Database.SSLMode == "disable" && !isLocalHost(c.Database.Host) {
+		warn("database.sslmode is disable for the remote host %s", c.Database.Host)
+	}
+	if c.Auth.External.URL != "" && c.Auth.External.FailOpen {
+		warn("auth.external.failOpen lets requests through while the authorization service is down")
+	}
+	if c.Uploads.Scanner.URL != "" && c.Uploads.Scanner.FailOpen {
+		warn("uploads.scanner.failOpen forwards unscanned files while the scanning service is down")
+	}
+	if c.Auth.LDAP.URL != "" && !c.Auth.LDAP.StartTLS && strings.HasPrefix(c.Auth.LDAP.URL, "ldap://") {
+		warn("auth.ldap sends passwords unencrypted; use ldaps:// or startTLS")
+	}
+	if c.Auth.IdentityToken.Audience != "" && c.Auth.isHMAC() {
+		warn("auth.identityToken with %s lets every service that verifies identity tokens forge gateway tokens; use an asymmetric algorithm", c.Auth.Algorithm)
+	}
+	if (c.Auth.OIDC.Issuer != "" || c.Auth.SAML.EntityID != "") && !c.Auth.Session.Secure {
+		warn("auth.session.secure is disabled; session cookies are sent over plain HTTP")
+	}
+	if c.Async.Enabled && len(c.Async.CallbackHosts) > 0 && c.Async.CallbackSecret == "" {
+		warn("async.callbackSecret is empty; callback receivers cannot verify the gateway sent them")
+	}
+	if c.Logging.Development {
+		warn("logging.development is enabled")
+	}
+	for _, destination := range c.Egress.Destinations {
+		if destination.Scheme == "http" && (len(destination.Headers) > 0 || destination.Signing != nil) {
+			warn("egress destination %s sends its credentials unencrypted over http", destination.Host)
+		}
+	}
+	return warnings
+}
+
+// multiInstance reports whether the configuration is shared by several gateway instances, as
+// config sync and leader election only serve deployments of more than one
+func (c *Config) multiInstance() bool {
+	return c.ConfigSync.Enabled || c.LeaderElection.Backend != ""
+}
+
+// isHMAC reports whether tokens are signed with the shared secret key
+func (a AuthConfig) isHMAC() bool {
+	return a.Algorithm == "" || strings.HasPrefix(a.Algorithm, "HS")
+}
+
+func isPlaceholderSecret(secret string) bool {
+	for _, insecure := range insecureSecretKeys {
+		if secret == insecure {
+			return true
+		}
+	}
+	return false
+}
+
+func isLocalHost(host string) bool {
+	return host == "localhost" || host == "127.0.0.1" || host == "::1"
+}
@@ -0,0 +1,197 @@
+package config
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidate_Defaults(t *testing.T) {
+	cfg, err := LoadConfig("")
+	require.NoError(t, err)
+
+	// The defaults are valid but warn about the placeholder secret key
+	assert.NoError(t, cfg.Validate())
+	assert.Contains(t, cfg.Warnings(), `auth.secretKey is the placeholder "your-secret-key"; anyone can forge tokens`)
+
+	cfg.Auth.SecretKey = "a-secret-key-that-is-long-enough-for-hs256"
+	assert.Empty(t, cfg.Warnings())
+}
+
+func TestValidate_ReportsEveryProblem(t *testing.T) {
+	cfg, err := LoadConfig("")
+	require.NoError(t, err)
+
+	cfg.Server.Port = 0
+	cfg.Server.ReadTimeout = 0
+	cfg.Auth.SecretKey = ""
+	cfg.Cache.Backend = "dynamodb"
+	cfg.Redis.Mode = "sentinel"
+	cfg.Auth.LDAP.URL = "http://ldap.example.com"
+	cfg.Mail.Host = "smtp.example.com"
+	cfg.Server.H2C = true
+	cfg.Server.TLS.CertFile = "/etc/gateway/tls.crt"
+	cfg.Brokers.NATS.URL = "http://nats:4222"
+	cfg.Async.Workers = 0
+	cfg.Webhooks.DropPolicy = "drop-newest"
+	cfg.Scheduler.HistorySize = 0
+	cfg.Server.Profile = "prod"
+	cfg.ConfigSync.Enabled = true
+	cfg.ConfigSync.Channel = ""
+	cfg.LeaderElection.Backend = "redis"
+	cfg.LeaderElection.LeaseDuration = 5 * time.Second
+	cfg.Streams.Listeners = []string{"tcp://:1883?upstream=mqtt:1883", "tcp://:1883?upstream=mqtt-2:1883", "sctp://:9000?upstream=app:9000"}
+	cfg.Upstream.Credentials = map[string]SigningCredentials{"lambda": {AccessKeyID: "AKIDEXAMPLE"}}
+	cfg.Egress.Port = 3128
+	cfg.ControlPlane.Port = 3128
+	cfg.XDS.Address = "xds-server"
+	cfg.Egress.Destinations = []EgressDestinationConfig{
+		{Host: "api.stripe.com", Headers: map[string]string{"Authorization": "Bearer sk_test"}},
+		{Host: "", Scheme: "ftp", Signing: &EgressSigningConfig{Scheme: "hmac", Credentials: "partner"}},
+	}
+	cfg.I18n.DefaultLocale = "english"
+	cfg.I18n.Catalogs = map[string]I18nCatalogConfig{"fr": {}, "français": {}}
+	cfg.Residency.Countries = map[string]string{"de": "eu", "germany": "eu"}
+	cfg.Auth.ClaimHeaders = map[string]string{"x-user-id": "sub", "authorization": "token", "x user": "email", "x-tenant-id": ""}
+	cfg.Auth.IdentityToken = IdentityTokenConfig{Audience: "services", TTL: 0, Header: "x-user-id"}
+	cfg.Upstream.DeniedRequestHeaders = []string{"X-Tenant-Id", "X Api Key"}
+	cfg.Upstream.TrustedProxies = []string{"10.0.0.0/8", "192.168.1.1"}
+	cfg.Upstream.DNSCacheTTL = -time.Second
+	cfg.Upstream.Networks = map[string]NetworkConfig{
+		"vpc": {Proxy: "ftp://proxy:21", Resolvers: []string{"10.0.0.2:53", "10.0.0.3"}, Hosts: []HostAddress{{Host: "orders.internal", Address: "orders"}}},
+	}
+	cfg.Residency.Networks = map[string][]string{"eu": {"10.1.0.0/16", "10.2.0.0"}}
+	cfg.ErrorPages.Templates = []ErrorTemplateConfig{
+		{Status: "5xx", ContentType: "text/html", Body: "<h1>{{error.status}}</h1>"},
+		{Status: "200", ContentType: "application/json"},
+	}
+	cfg.ErrorReporting.DSN = "sentry.example.com/1"
+	cfg.ErrorReporting.BufferSize = 0
+	cfg.Logging.Sampling.Tick = 0
+	cfg.Logging.Async = LogAsyncConfig{Enabled: true, QueueSize: 0, BufferSize: 4096, FlushInterval: time.Second}
+	cfg.Logging.Outputs = []LogOutputConfig{
+		{Type: "stdout", Level: "trace"},
+		{Type: "file"},
+		{Type: "syslog", Address: "syslog:514"},
+		{Type: "loki", URL: "http://loki:3100/loki/api/v1/push", BatchSize: 100, FlushInterval: time.Second},
+		{Type: "kafka"},
+	}
+
+	err = cfg.Validate()
+	var validationErr *ValidationError
+	require.ErrorAs(t, err, &validationErr)
+	assert.ElementsMatch(t, []string{
+		"server.port must be between 1 and 65535, got 0",
+		`server.profile must be one of development, staging, production, got "prod"`,
+		"server.readTimeout must be positive, got 0s",
+		"logging.sampling.tick must be positive, got 0s",
+		"logging.async.queueSize must be positive, got 0",
+		`logging.outputs[0].level must be one of debug, info, warn, error, got "trace"`,
+		"logging.outputs[1].path is required for a file output",
+		`logging.outputs[2].address must be a URL such as udp://syslog:514, got "syslog:514"`,
+		"logging.outputs[3].timeout must be positive, got 0s",
+		`logging.outputs[4].type must be one of stdout, stderr, file, syslog, loki, got "kafka"`,
+		"auth.secretKey is required for HS256",
+		`cache.backend must be one of redis, memcached, memory, got "dynamodb"`,
+		"redis.masterName is required in sentinel mode",
+		`auth.ldap.url scheme must be one of ldap, ldaps, got "http"`,
+		"auth.ldap.baseDN is required when LDAP is enabled",
+		"mail.from is required when mail.host is set",
+		"server.tls.certFile and server.tls.keyFile must be set together",
+		"server.h2c cannot be combined with server.tls, which negotiates HTTP/2 itself",
+		"streams.listeners[1]: tcp :1883 is already used by another listener",
+		"async.workers must be positive, got 0",
+		`webhooks.dropPolicy must be one of reject, drop-oldest, block, got "drop-newest"`,
+		"scheduler.historySize must be positive, got 0",
+		"configSync.channel is required when configSync is enabled",
+		"leaderElection.leaseDuration must be longer than leaderElection.renewInterval",
+		`brokers.nats.url scheme must be one of nats, tls, got "http"`,
+		`streams.listeners[2]: listener "sctp://:9000?upstream=app:9000" must use tcp:// or udp://`,
+		"upstream.credentials.lambda.secretAccessKey is required",
+		"egress.destinations[1].host is required",
+		`egress.destinations[1].scheme must be one of http, https, got "ftp"`,
+		`egress.destinations[1].signing.credentials "partner" is not in upstream.credentials`,
+		`errorPages.templates[1].status must be a status such as 404 or a class such as 5xx, got "200"`,
+		"errorPages.templates[1].body is required",
+		`errorReporting.dsn must be an absolute URL, got "sentry.example.com/1"`,
+		"errorReporting.bufferSize must be positive, got 0",
+		`i18n.defaultLocale must be a language tag such as en or pt-BR, got "english"`,
+		"i18n.catalogs.français must be keyed by a language tag such as en or pt-BR",
+		`residency.countries must be keyed by ISO country codes such as DE, got "germany"`,
+		"auth.claimHeaders cannot set the authorization header",
+		`auth.claimHeaders must be keyed by header names, got "x user"`,
+		"auth.claimHeaders.x-tenant-id must name a claim",
+		"auth.identityToken.ttl must be positive, got 0s",
+		"auth.identityToken.header x-user-id is also in auth.claimHeaders",
+		"upstream.deniedRequestHeaders removes the auth.claimHeaders header X-Tenant-Id",
+		`upstream.deniedRequestHeaders must list header names, got "X Api Key"`,
+		"upstream.dnsCacheTTL must not be negative, got -1s",
+		`upstream.trustedProxies[1] must be a network such as 10.0.0.0/8, got "192.168.1.1"`,
+		`upstream.networks.vpc.proxy scheme must be one of http, https, socks5, got "ftp"`,
+		`upstream.networks.vpc.resolvers[1] must be a host:port address, got "10.0.0.3"`,
+		`upstream.networks.vpc.hosts[0].address must be an IP address, got "orders"`,
+		`residency.networks.eu[1] must be a network such as 10.1.0.0/16, got "10.2.0.0"`,
+		"controlPlane.port must differ from server.port and egress.port",
+		`xds.address must be a host:port such as xds.example.com:18000, got "xds-server"`,
+		"xds.routeConfigs is required when xds.address is set",
+	}, validationErr.Problems)
+}
+
+func TestValidate_ChaosOutsideProduction(t *testing.T) {
+	cfg, err := LoadConfig("")
+	require.NoError(t, err)
+
+	cfg.Chaos.Enabled = true
+	var validationErr *ValidationError
+	require.ErrorAs(t, cfg.Validate(), &validationErr)
+	assert.Equal(t, []string{"chaos.enabled is not allowed in the production profile"}, validationErr.Problems)
+
+	cfg.Server.Profile = "staging"
+	assert.NoError(t, cfg.Validate())
+}
+
+func TestValidate_AsymmetricKeysNeedNoSecret(t *testing.T) {
+	cfg, err := LoadConfig("")
+	require.NoError(t, err)
+
+	cfg.Auth.Algorithm = "ES256"
+	cfg.Auth.SecretKey = ""
+	assert.NoError(t, cfg.Validate())
+	assert.Empty(t, cfg.Warnings())
+}
+
+func TestValidate_AsymmetricKeysSharedByInstances(t *testing.T) {
+	cfg, err := LoadConfig("")
+	require.NoError(t, err)
+
+	cfg.Auth.Algorithm = "RS256"
+	cfg.Auth.RotationInterval = time.Hour
+	cfg.LeaderElection.Backend = "redis"
+	var validationErr *ValidationError
+	require.ErrorAs(t, cfg.Validate(), &validationErr)
+	assert.Equal(t, []string{
+		"auth.privateKeyFile is required for RS256 when several instances run, as each would generate its own key",
+		"auth.rotationInterval must be 0 for RS256 when several instances run, as each would rotate to its own key",
+	}, validationErr.Problems)
+
+	cfg.Auth.PrivateKeyFile = "/etc/gateway/signing.pem"
+	cfg.Auth.RotationInterval = 0
+	assert.NoError(t, cfg.Validate())
+}
+
+func TestStreamsConfig_ParseListeners(t *testing.T) {
+	t.Setenv("API_GATEWAY_STREAMS_LISTENERS", "tcp://:1883?upstream=mqtt:1883,udp://0.0.0.0:5353?upstream=dns:53&maxConnections=10&idleTimeout=30s")
+	cfg, err := LoadConfig("")
+	require.NoError(t, err)
+
+	// Listeners inherit the streams defaults unless their spec overrides them
+	listeners, err := cfg.Streams.ParseListeners()
+	require.NoError(t, err)
+	assert.Equal(t, []StreamListener{
+		{Protocol: "tcp", Address: ":1883", Upstream: "mqtt:1883", MaxConnections: 1000, IdleTimeout: 5 * time.Minute},
+		{Protocol: "udp", Address: "0.0.0.0:5353", Upstream: "dns:53", MaxConnections: 10, IdleTimeout: 30 * time.Second},
+	}, listeners)
+	assert.NoError(t, cfg.Validate())
+}
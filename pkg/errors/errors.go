@@ -3,7 +3,6 @@ package errors
 import (
 	"errors"
 	"fmt"
-	"net/http"
 )
 
 // Common errors
@@ -18,13 +17,22 @@ var (
 	ErrTimeout            = errors.New("timeout")
 	ErrRateLimitExceeded  = errors.New("rate limit exceeded")
 	ErrServiceNotFound    = errors.New("service not found")
+	ErrPreconditionFailed = errors.New("precondition failed")
+	ErrRouteConflict      = errors.New("route conflict")
+	// ErrConnectionReset asks the server to close the client connection without a response
+	ErrConnectionReset = errors.New("connection reset")
 )
 
-// Error represents a custom error with additional context
+// Error represents a custom error with additional context. It is the error model of the
+// gateway: its problem type gives the HTTP status, the machine-readable code and whether the
+// request is worth retrying, which handlers, logs and metrics report alike.
 type Error struct {
+	// Code is the HTTP status the error is answered with
 	Code    int
 	Message string
 	Err     error
+	// Type classifies the error, the type of its sentinel or status when unset
+	Type ProblemType
 }
 
 // Error returns the error message
@@ -49,6 +57,16 @@ func NewError(code int, message string, err error) *Error {
 	}
 }
 
+// NewTypedError creates a new Error instance of a problem type, answered with its status
+func NewTypedError(problemType ProblemType, message string, err error) *Error {
+	return &Error{
+		Code:    problemType.Status,
+		Message: message,
+		Err:     err,
+		Type:    problemType,
+	}
+}
+
 // Is reports whether target matches the error
 func (e *Error) Is(target error) bool {
 	t, ok := target.(*Error)
@@ -66,9 +84,11 @@ const (
 	CodeUnauthorized       = 401
 	CodeForbidden          = 403
 	CodeInternalServer     = 500
+	CodeBadGateway         = 502
 	CodeServiceUnavailable = 503
 	CodeTimeout            = 504
 	CodeRateLimitExceeded  = 429
+	CodePreconditionFailed = 412
 )
 
 // Wrap wraps an error with additional context
@@ -124,57 +144,40 @@ func IsRateLimitExceeded(err error) bool {
 	return errors.Is(err, ErrRateLimitExceeded)
 }
 
-// Error represents an API error
-type APIError struct {
-	Code    int    `json:"code"`
-	Message string `json:"message"`
-	Details string `json:"details,omitempty"`
+// IsPreconditionFailed returns true if the error is a precondition failed error
+func IsPreconditionFailed(err error) bool {
+	return errors.Is(err, ErrPreconditionFailed)
 }
 
-// New creates a new Error instance
-func New(code int, message string) *APIError {
-	return &APIError{
-		Code:    code,
-		Message: message,
-	}
+// IsRouteConflict returns true if the error is a route served by several services
+func IsRouteConflict(err error) bool {
+	return errors.Is(err, ErrRouteConflict)
 }
 
-// WithDetails adds details to the error
-func (e *APIError) WithDetails(details string) *APIError {
-	e.Details = details
-	return e
+// IsConnectionReset returns true if the client connection should be closed without a response
+func IsConnectionReset(err error) bool {
+	return errors.Is(err, ErrConnectionReset)
 }
 
-// Error implements the error interface
-func (e *APIError) Error() string {
-	if e.Details != "" {
-		return fmt.Sprintf("%s: %s", e.Message, e.Details)
+// StatusCodeOf returns the status code carried by an Error in err's chain, or fallback if there is none
+func StatusCodeOf(err error, fallback int) int {
+	var e *Error
+	if errors.As(err, &e) && e.Code != 0 {
+		return e.Code
 	}
-	return e.Message
-}
-
-// StatusCode returns the HTTP status code
-func (e *APIError) StatusCode() int {
-	return e.Code
+	return fallback
 }
 
-// Common API errors
-var (
-	ErrBadRequest       = New(http.StatusBadRequest, "Bad request")
-	ErrMethodNotAllowed = New(http.StatusMethodNotAllowed, "Method not allowed")
-	ErrTooManyRequests  = New(http.StatusTooManyRequests, "Too many requests")
-)
-
-// IsAPIError checks if an error is an API error
-func IsAPIError(err error) bool {
-	_, ok := err.(*APIError)
-	return ok
+// Classify returns the problem type of an error, answered with the status it carries or fallback
+func Classify(err error, fallback int) ProblemType {
+	return ProblemTypeOf(err, StatusCodeOf(err, fallback))
 }
 
-// ToAPIError converts an error to an API error
-func ToAPIError(err error) *APIError {
-	if apiErr, ok := err.(*APIError); ok {
-		return apiErr
+// IsRetryable returns true if the request that failed with the error is worth retrying later,
+// such as after a timeout or while a service is unavailable
+func IsRetryable(err error) bool {
+	if errors.Is(err, ErrTimeout) || errors.Is(err, ErrServiceUnavailable) || errors.Is(err, ErrRateLimitExceeded) {
+		return true
 	}
-	return New(500, err.Error())
+	return Classify(err, CodeInternalServer).Retryable
 }
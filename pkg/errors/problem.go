@@ -0,0 +1,206 @@
+package errors
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// ProblemContentType is the media type of problem details responses (RFC 7807)
+const ProblemContentType = "application/problem+json"
+
+// problemTypeBase prefixes the names of the problem types registered by the gateway
+const problemTypeBase = "urn:api-gateway:problem:"
+
+// ProblemType is a kind of error reported in problem details. Its URI identifies the kind to
+// clients, which should rely on it rather than on the title or detail.
+type ProblemType struct {
+	Type   string `json:"type"`
+	Title  string `json:"title"`
+	Status int    `json:"status"`
+	// Retryable reports whether a request failing this way is worth retrying later
+	Retryable bool `json:"retryable"`
+}
+
+// Name returns the name the type was registered with, empty for unregistered types
+func (t ProblemType) Name() string {
+	if !strings.HasPrefix(t.Type, problemTypeBase) {
+		return ""
+	}
+	return strings.TrimPrefix(t.Type, problemTypeBase)
+}
+
+// Code returns the machine-readable code of the type, its name or, for unregistered types,
+// status- followed by the status, e.g. status-418
+func (t ProblemType) Code() string {
+	if name := t.Name(); name != "" {
+		return name
+	}
+	return "status-" + strconv.Itoa(t.Status)
+}
+
+// RetryableStatus returns true for the statuses of transient failures, which clients may retry
+func RetryableStatus(status int) bool {
+	switch status {
+	case http.StatusTooManyRequests, http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	}
+	return false
+}
+
+var (
+	problemTypes    []ProblemType
+	statusProblems  = make(map[int]ProblemType)
+	problemSentinel []struct {
+		err     error
+		problem ProblemType
+	}
+)
+
+// RegisterProblemType registers a problem type named, e.g., "rate-limit-exceeded", retryable when
+// its status is. The first type registered for a status is reported for errors that carry
+// nothing more specific.
+func RegisterProblemType(name string, title string, status int) ProblemType {
+	problemType := ProblemType{Type: problemTypeBase + name, Title: title, Status: status, Retryable: RetryableStatus(status)}
+	problemTypes = append(problemTypes, problemType)
+	if _, ok := statusProblems[status]; !ok {
+		statusProblems[status] = problemType
+	}
+	return problemType
+}
+
+// registerSentinel reports errors matching err as problemType when they have its status
+func registerSentinel(err error, problemType ProblemType) {
+	problemSentinel = append(problemSentinel, struct {
+		err     error
+		problem ProblemType
+	}{err, problemType})
+}
+
+// Problem types of the errors generated by the gateway
+var (
+	ProblemInvalidInput         = RegisterProblemType("invalid-input", "Invalid input", http.StatusBadRequest)
+	ProblemValidationFailed     = RegisterProblemType("validation-failed", "Validation failed", http.StatusBadRequest)
+	ProblemUnauthorized         = RegisterProblemType("unauthorized", "Unauthorized", http.StatusUnauthorized)
+	ProblemForbidden            = RegisterProblemType("forbidden", "Forbidden", http.StatusForbidden)
+	ProblemNotFound             = RegisterProblemType("not-found", "Not found", http.StatusNotFound)
+	ProblemRouteNotFound        = RegisterProblemType("route-not-found", "No route matches the request", http.StatusNotFound)
+	ProblemMethodNotAllowed     = RegisterProblemType("method-not-allowed", "Method not allowed", http.StatusMethodNotAllowed)
+	ProblemConflict             = RegisterProblemType("conflict", "Conflict", http.StatusConflict)
+	ProblemRouteConflict        = RegisterProblemType("route-conflict", "Route served by several services", http.StatusConflict)
+	ProblemPreconditionFailed   = RegisterProblemType("precondition-failed", "Precondition failed", http.StatusPreconditionFailed)
+	ProblemPreconditionRequired = RegisterProblemType("precondition-required", "Precondition required", http.StatusPreconditionRequired)
+	ProblemUnsupportedMediaType = RegisterProblemType("unsupported-media-type", "Unsupported media type", http.StatusUnsupportedMediaType)
+	ProblemPayloadTooLarge      = RegisterProblemType("payload-too-large", "Payload too large", http.StatusRequestEntityTooLarge)
+	ProblemUploadRejected       = RegisterProblemType("upload-rejected", "Uploaded file rejected", http.StatusUnprocessableEntity)
+	ProblemRateLimitExceeded    = RegisterProblemType("rate-limit-exceeded", "Rate limit exceeded", http.StatusTooManyRequests)
+	ProblemInternal             = RegisterProblemType("internal", "Internal server error", http.StatusInternalServerError)
+	ProblemUpstreamFailed       = RegisterProblemType("upstream-failed", "The service could not be reached", http.StatusBadGateway)
+	ProblemResponseTooLarge     = RegisterProblemType("response-too-large", "The service response is too large", http.StatusBadGateway)
+	ProblemServiceUnavailable   = RegisterProblemType("service-unavailable", "Service unavailable", http.StatusServiceUnavailable)
+	ProblemUnderMaintenance     = RegisterProblemType("maintenance", "Under maintenance", http.StatusServiceUnavailable)
+	ProblemUpstreamTimeout      = RegisterProblemType("upstream-timeout", "The service did not respond in time", http.StatusGatewayTimeout)
+)
+
+func init() {
+	registerSentinel(ErrServiceNotFound, ProblemRouteNotFound)
+	registerSentinel(ErrInvalidInput, ProblemInvalidInput)
+	registerSentinel(ErrRouteConflict, ProblemRouteConflict)
+	registerSentinel(ErrRateLimitExceeded, ProblemRateLimitExceeded)
+	registerSentinel(ErrTimeout, ProblemUpstreamTimeout)
+}
+
+// ProblemTypes returns every registered problem type, in registration order
+func ProblemTypes() []ProblemType {
+	return append([]ProblemType(nil), problemTypes...)
+}
+
+// ProblemTypeForStatus returns the problem type reported for a status, "about:blank" with the
+// status text when none is registered
+func ProblemTypeForStatus(status int) ProblemType {
+	if problemType, ok := statusProblems[status]; ok {
+		return problemType
+	}
+	return ProblemType{Type: "about:blank", Title: http.StatusText(status), Status: status, Retryable: RetryableStatus(status)}
+}
+
+// ProblemTypeOf returns the problem type of an error answered with a status: the type of the
+// Error in its chain if it has that status, else the type of the sentinel error in its chain if
+// it has that status, else the type of the status
+func ProblemTypeOf(err error, status int) ProblemType {
+	var typed *Error
+	if errors.As(err, &typed) && typed.Type.Type != "" && typed.Type.Status == status {
+		return typed.Type
+	}
+	for _, sentinel := range problemSentinel {
+		if sentinel.problem.Status == status && errors.Is(err, sentinel.err) {
+			return sentinel.problem
+		}
+	}
+	return ProblemTypeForStatus(status)
+}
+
+// Problem is a problem details object (RFC 7807)
+type Problem struct {
+	ProblemType
+	// Detail explains this occurrence of the problem
+	Detail string
+	// Instance identifies this occurrence, such as the request path
+	Instance string
+	// MessageID identifies Detail in message catalogs, empty when the detail is not fixed
+	MessageID string
+	// Extensions are additional members, such as the request ID
+	Extensions map[string]interface{}
+}
+
+// NewProblem creates a Problem of a type
+func NewProblem(problemType ProblemType, detail string) *Problem {
+	return &Problem{ProblemType: problemType, Detail: detail}
+}
+
+// NewLocalizedProblem creates a Problem whose detail message catalogs translate by its ID
+func NewLocalizedProblem(problemType ProblemType, messageID string, detail string) *Problem {
+	return &Problem{ProblemType: problemType, Detail: detail, MessageID: messageID}
+}
+
+// StatusProblem creates a Problem of the type reported for a status
+func StatusProblem(status int, detail string) *Problem {
+	return NewProblem(ProblemTypeForStatus(status), detail)
+}
+
+// ProblemOf creates a Problem describing an error answered with a status
+func ProblemOf(err error, status int) *Problem {
+	return NewProblem(ProblemTypeOf(err, status), err.Error())
+}
+
+// With sets an extension member; the standard members cannot be overridden
+func (p *Problem) With(key string, value interface{}) *Problem {
+	if p.Extensions == nil {
+		p.Extensions = make(map[string]interface{})
+	}
+	p.Extensions[key] = value
+	return p
+}
+
+// MarshalJSON writes the extension members alongside the standard ones, and the code and
+// retryability of the type
+func (p *Problem) MarshalJSON() ([]byte, error) {
+	members := make(map[string]interface{}, len(p.Extensions)+7)
+	for key, value := range p.Extensions {
+		members[key] = value
+	}
+	members["type"] = p.Type
+	members["title"] = p.Title
+	members["status"] = p.Status
+	members["code"] = p.Code()
+	members["retryable"] = p.Retryable
+	if p.Detail != "" {
+		members["detail"] = p.Detail
+	}
+	if p.Instance != "" {
+		members["instance"] = p.Instance
+	}
+	return json.Marshal(members)
+}
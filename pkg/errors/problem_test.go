@@ -0,0 +1,151 @@
+package errors
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestProblemTypeOf(t *testing.T) {
+	testCases := []struct {
+		name     string
+		err      error
+		status   int
+		expected ProblemType
+	}{
+		{
+			name:     "Sentinel with its status",
+			err:      NewError(CodeRateLimitExceeded, "too many requests", ErrRateLimitExceeded),
+			status:   http.StatusTooManyRequests,
+			expected: ProblemRateLimitExceeded,
+		},
+		{
+			name:     "Sentinel with another status",
+			err:      fmt.Errorf("lookup failed: %w", ErrServiceNotFound),
+			status:   http.StatusInternalServerError,
+			expected: ProblemInternal,
+		},
+		{
+			name:     "Wrapped sentinel",
+			err:      fmt.Errorf("no route: %w", ErrServiceNotFound),
+			status:   http.StatusNotFound,
+			expected: ProblemRouteNotFound,
+		},
+		{
+			name:     "Status",
+			err:      NewError(CodeBadGateway, "upstream failed", nil),
+			status:   http.StatusBadGateway,
+			expected: ProblemUpstreamFailed,
+		},
+		{
+			name:     "Typed error",
+			err:      fmt.Errorf("lookup failed: %w", NewTypedError(ProblemRouteNotFound, "no route", ErrNotFound)),
+			status:   http.StatusNotFound,
+			expected: ProblemRouteNotFound,
+		},
+		{
+			name:     "Typed error with another status",
+			err:      NewTypedError(ProblemRouteNotFound, "no route", nil),
+			status:   http.StatusInternalServerError,
+			expected: ProblemInternal,
+		},
+		{
+			name:     "Unregistered status",
+			err:      NewError(http.StatusTeapot, "teapot", nil),
+			status:   http.StatusTeapot,
+			expected: ProblemType{Type: "about:blank", Title: "I'm a teapot", Status: http.StatusTeapot},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, ProblemTypeOf(tc.err, tc.status))
+		})
+	}
+}
+
+func TestProblem_MarshalJSON(t *testing.T) {
+	problem := NewProblem(ProblemRateLimitExceeded, "Limited to 10 requests per minute")
+	problem.Instance = "/api/v1/orders"
+	problem.With("requestId", "req-1").With("status", 200)
+
+	data, err := json.Marshal(problem)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{
+		"type": "urn:api-gateway:problem:rate-limit-exceeded",
+		"title": "Rate limit exceeded",
+		"status": 429,
+		"code": "rate-limit-exceeded",
+		"retryable": true,
+		"detail": "Limited to 10 requests per minute",
+		"instance": "/api/v1/orders",
+		"requestId": "req-1"
+	}`, string(data))
+}
+
+func TestProblemType_Name(t *testing.T) {
+	assert.Equal(t, "rate-limit-exceeded", ProblemRateLimitExceeded.Name())
+	assert.Equal(t, "", ProblemTypeForStatus(http.StatusTeapot).Name())
+}
+
+func TestProblemType_Code(t *testing.T) {
+	assert.Equal(t, "rate-limit-exceeded", ProblemRateLimitExceeded.Code())
+	assert.Equal(t, "status-418", ProblemTypeForStatus(http.StatusTeapot).Code())
+}
+
+func TestClassify(t *testing.T) {
+	testCases := []struct {
+		name      string
+		err       error
+		expected  ProblemType
+		retryable bool
+	}{
+		{
+			name:      "Status",
+			err:       NewError(CodeServiceUnavailable, "gateway overloaded", ErrServiceUnavailable),
+			expected:  ProblemServiceUnavailable,
+			retryable: true,
+		},
+		{
+			name:      "Sentinel",
+			err:       NewError(CodeTimeout, "upstream timed out", ErrTimeout),
+			expected:  ProblemUpstreamTimeout,
+			retryable: true,
+		},
+		{
+			name:     "Client error",
+			err:      NewError(CodeInvalidInput, "invalid body", ErrInvalidInput),
+			expected: ProblemInvalidInput,
+		},
+		{
+			name:     "Untyped error",
+			err:      fmt.Errorf("boom"),
+			expected: ProblemInternal,
+		},
+		{
+			name:      "Bare transient sentinel",
+			err:       fmt.Errorf("dial failed: %w", ErrTimeout),
+			expected:  ProblemInternal,
+			retryable: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, Classify(tc.err, CodeInternalServer))
+			assert.Equal(t, tc.retryable, IsRetryable(tc.err))
+		})
+	}
+}
+
+func TestProblemTypes_AreUnique(t *testing.T) {
+	seen := make(map[string]bool)
+	for _, problemType := range ProblemTypes() {
+		assert.False(t, seen[problemType.Type], "duplicate problem type %s", problemType.Type)
+		seen[problemType.Type] = true
+	}
+}
@@ -0,0 +1,133 @@
+// Package i18n translates the error messages the gateway generates into the language a client
+// asks for with Accept-Language
+package i18n
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"golang.org/x/text/language"
+	"gopkg.in/yaml.v3"
+)
+
+// Catalog holds the translations of one locale
+type Catalog struct {
+	// Titles translate problem titles, keyed by problem type name such as "rate-limit-exceeded"
+	Titles map[string]string `json:"titles" yaml:"titles"`
+	// Messages translate error messages, keyed by message ID such as "invalid-token", or by
+	// problem type name for errors whose message is not fixed
+	Messages map[string]string `json:"messages" yaml:"messages"`
+}
+
+// Translator picks the catalog of the locale matching a request's Accept-Language. The
+// messages of the default locale are built in and need no catalog.
+type Translator struct {
+	locales  []string
+	catalogs map[string]Catalog
+	matcher  language.Matcher
+}
+
+// NewTranslator creates a Translator for the catalogs, keyed by locale such as "fr" or "pt-BR"
+func NewTranslator(defaultLocale string, catalogs map[string]Catalog) (*Translator, error) {
+	defaultTag, err := language.Parse(defaultLocale)
+	if err != nil {
+		return nil, fmt.Errorf("invalid default locale %s: %w", defaultLocale, err)
+	}
+
+	t := &Translator{
+		locales:  []string{defaultTag.String()},
+		catalogs: make(map[string]Catalog, len(catalogs)),
+	}
+	tags := []language.Tag{defaultTag}
+	for locale, catalog := range catalogs {
+		tag, err := language.Parse(locale)
+		if err != nil {
+			return nil, fmt.Errorf("invalid locale %s: %w", locale, err)
+		}
+		t.catalogs[tag.String()] = catalog
+		if tag != defaultTag {
+			tags = append(tags, tag)
+			t.locales = append(t.locales, tag.String())
+		}
+	}
+	t.matcher = language.NewMatcher(tags)
+	return t, nil
+}
+
+// LoadCatalogs reads the catalogs of a directory, one JSON or YAML file per locale named
+// after it, such as fr.yaml or pt-BR.json
+func LoadCatalogs(directory string) (map[string]Catalog, error) {
+	entries, err := os.ReadDir(directory)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read message catalogs: %w", err)
+	}
+
+	catalogs := make(map[string]Catalog)
+	for _, entry := range entries {
+		extension := filepath.Ext(entry.Name())
+		if entry.IsDir() || (extension != ".json" && extension != ".yaml" && extension != ".yml") {
+			continue
+		}
+		data, err := os.ReadFile(filepath.Join(directory, entry.Name()))
+		if err != nil {
+			return nil, fmt.Errorf("failed to read message catalog %s: %w", entry.Name(), err)
+		}
+
+		var catalog Catalog
+		if extension == ".json" {
+			err = json.Unmarshal(data, &catalog)
+		} else {
+			err = yaml.Unmarshal(data, &catalog)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode message catalog %s: %w", entry.Name(), err)
+		}
+		catalogs[strings.TrimSuffix(entry.Name(), extension)] = catalog
+	}
+	return catalogs, nil
+}
+
+// Locale returns the supported locale best matching an Accept-Language header, the default
+// locale when none does. A nil Translator supports no locale and returns "".
+func (t *Translator) Locale(acceptLanguage string) string {
+	if t == nil {
+		return ""
+	}
+	tags, _, err := language.ParseAcceptLanguage(acceptLanguage)
+	if err != nil || len(tags) == 0 {
+		return t.locales[0]
+	}
+	_, index, confidence := t.matcher.Match(tags...)
+	if confidence == language.No {
+		return t.locales[0]
+	}
+	return t.locales[index]
+}
+
+// Title translates the title of a problem type, returning fallback when the locale has none
+func (t *Translator) Title(locale string, problemType string, fallback string) string {
+	if t == nil {
+		return fallback
+	}
+	if title, ok := t.catalogs[locale].Titles[problemType]; ok {
+		return title
+	}
+	return fallback
+}
+
+// Message translates the message with the first of ids the locale has a translation for,
+// returning fallback when it has none
+func (t *Translator) Message(locale string, fallback string, ids ...string) string {
+	if t == nil {
+		return fallback
+	}
+	for _, id := range ids {
+		if message, ok := t.catalogs[locale].Messages[id]; ok && id != "" {
+			return message
+		}
+	}
+	return fallback
+}
@@ -0,0 +1,70 @@
+package i18n
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTranslator_Locale(t *testing.T) {
+	translator, err := NewTranslator("en", map[string]Catalog{"fr": {}, "pt-br": {}})
+	require.NoError(t, err)
+
+	testCases := []struct {
+		acceptLanguage string
+		expected       string
+	}{
+		{"fr-CA,fr;q=0.9,en;q=0.8", "fr"},
+		{"de;q=0.9, pt-BR;q=0.8", "pt-BR"},
+		{"de", "en"},
+		{"en-GB", "en"},
+		{"", "en"},
+		{"not a language;;", "en"},
+	}
+
+	for _, tc := range testCases {
+		assert.Equal(t, tc.expected, translator.Locale(tc.acceptLanguage), tc.acceptLanguage)
+	}
+}
+
+func TestTranslator_Translate(t *testing.T) {
+	translator, err := NewTranslator("en", map[string]Catalog{
+		"fr": {
+			Titles:   map[string]string{"unauthorized": "Non autorisé"},
+			Messages: map[string]string{"invalid-token": "Jeton invalide", "rate-limit-exceeded": "Trop de requêtes"},
+		},
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, "Non autorisé", translator.Title("fr", "unauthorized", "Unauthorized"))
+	assert.Equal(t, "Forbidden", translator.Title("fr", "forbidden", "Forbidden"))
+	assert.Equal(t, "Unauthorized", translator.Title("en", "unauthorized", "Unauthorized"))
+	assert.Equal(t, "Jeton invalide", translator.Message("fr", "Invalid token", "invalid-token", "unauthorized"))
+	assert.Equal(t, "Trop de requêtes", translator.Message("fr", "limit of 10 exceeded", "", "rate-limit-exceeded"))
+	assert.Equal(t, "Invalid API key", translator.Message("fr", "Invalid API key", "invalid-api-key", "unauthorized"))
+
+	var nilTranslator *Translator
+	assert.Equal(t, "", nilTranslator.Locale("fr"))
+	assert.Equal(t, "Invalid token", nilTranslator.Message("fr", "Invalid token", "invalid-token"))
+}
+
+func TestLoadCatalogs(t *testing.T) {
+	directory := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(directory, "fr.yaml"), []byte("titles:\n  forbidden: Interdit\n"), 0o600))
+	require.NoError(t, os.WriteFile(filepath.Join(directory, "de.json"), []byte(`{"messages": {"invalid-token": "Ungültiges Token"}}`), 0o600))
+	require.NoError(t, os.WriteFile(filepath.Join(directory, "README.md"), []byte("# Catalogs"), 0o600))
+
+	catalogs, err := LoadCatalogs(directory)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]Catalog{
+		"fr": {Titles: map[string]string{"forbidden": "Interdit"}},
+		"de": {Messages: map[string]string{"invalid-token": "Ungültiges Token"}},
+	}, catalogs)
+
+	require.NoError(t, os.WriteFile(filepath.Join(directory, "es.json"), []byte("{"), 0o600))
+	_, err = LoadCatalogs(directory)
+	assert.Error(t, err)
+}
@@ -0,0 +1,187 @@
+// Package jsonpath selects values of decoded JSON documents with a subset of JSONPath: member
+// names ($.user.email, $['user']['email']), array indexes ($.items[0], $.items[-1]), wildcards
+// ($.items[*].card, $.user.*) and recursive descent ($..ssn)
+package jsonpath
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Path is a parsed JSONPath expression
+type Path struct {
+	expression string
+	segments   []segment
+}
+
+// segment selects the children of a value by name, index or wildcard, and with descend the
+// matching descendants at any depth
+type segment struct {
+	name     string
+	index    int
+	indexed  bool
+	wildcard bool
+	descend  bool
+}
+
+// Parse parses a JSONPath expression, which starts at the root $
+func Parse(expression string) (*Path, error) {
+	rest, ok := strings.CutPrefix(strings.TrimSpace(expression), "$")
+	if !ok {
+		return nil, fmt.Errorf("jsonpath %q must start with $", expression)
+	}
+
+	path := &Path{expression: expression}
+	for rest != "" {
+		var seg segment
+		switch {
+		case strings.HasPrefix(rest, ".."):
+			seg.descend = true
+			rest = rest[2:]
+			if strings.HasPrefix(rest, "[") {
+				break
+			}
+			fallthrough
+		case strings.HasPrefix(rest, "."):
+			rest = strings.TrimPrefix(rest, ".")
+			end := strings.IndexAny(rest, ".[")
+			if end < 0 {
+				end = len(rest)
+			}
+			name := rest[:end]
+			if name == "" {
+				return nil, fmt.Errorf("jsonpath %q has an empty member name", expression)
+			}
+			seg.name, seg.wildcard = name, name == "*"
+			rest = rest[end:]
+			path.segments = append(path.segments, seg)
+			continue
+		case !strings.HasPrefix(rest, "["):
+			return nil, fmt.Errorf("jsonpath %q: unexpected %q", expression, rest)
+		}
+
+		end := strings.Index(rest, "]")
+		if end < 0 {
+			return nil, fmt.Errorf("jsonpath %q has an unclosed [", expression)
+		}
+		selector := strings.TrimSpace(rest[1:end])
+		rest = rest[end+1:]
+		switch {
+		case selector == "*":
+			seg.wildcard = true
+		case len(selector) >= 2 && (selector[0] == '\'' || selector[0] == '"') && selector[len(selector)-1] == selector[0]:
+			seg.name = selector[1 : len(selector)-1]
+		default:
+			index, err := strconv.Atoi(selector)
+			if err != nil {
+				return nil, fmt.Errorf("jsonpath %q: invalid selector [%s]", expression, selector)
+			}
+			seg.index, seg.indexed = index, true
+		}
+		path.segments = append(path.segments, seg)
+	}
+	return path, nil
+}
+
+// String returns the expression of the path
+func (p *Path) String() string {
+	return p.expression
+}
+
+// Root reports whether the path selects the document itself
+func (p *Path) Root() bool {
+	return len(p.segments) == 0
+}
+
+// Replace calls replace with every value the path selects in a decoded JSON document, made of
+// map[string]interface{} and []interface{}, and stores the value it returns in place of the
+// selected one. Values are removed from their object or array when replace returns false.
+// The document is modified in place, and the new root is returned.
+func (p *Path) Replace(document interface{}, replace func(value interface{}) (interface{}, bool)) interface{} {
+	value, keep := replaceAt(document, p.segments, replace)
+	if !keep {
+		return nil
+	}
+	return value
+}
+
+func replaceAt(value interface{}, segments []segment, replace func(interface{}) (interface{}, bool)) (interface{}, bool) {
+	if len(segments) == 0 {
+		return replace(value)
+	}
+	seg := segments[0]
+
+	value = seg.replaceChildren(value, func(child interface{}) (interface{}, bool) {
+		return replaceAt(child, segments[1:], replace)
+	})
+	if seg.descend {
+		value = replaceAll(value, func(child interface{}) (interface{}, bool) {
+			return replaceAt(child, segments, replace)
+		})
+	}
+	return value, true
+}
+
+// replaceChildren replaces the children of a value the segment selects
+func (s segment) replaceChildren(value interface{}, replace func(interface{}) (interface{}, bool)) interface{} {
+	if s.wildcard {
+		return replaceAll(value, replace)
+	}
+
+	switch v := value.(type) {
+	case map[string]interface{}:
+		if child, ok := v[s.name]; ok && !s.indexed {
+			if replaced, keep := replace(child); keep {
+				v[s.name] = replaced
+			} else {
+				delete(v, s.name)
+			}
+		}
+		return v
+	case []interface{}:
+		if !s.indexed {
+			return v
+		}
+		index := s.index
+		if index < 0 {
+			index += len(v)
+		}
+		if index < 0 || index >= len(v) {
+			return v
+		}
+		replaced, keep := replace(v[index])
+		if !keep {
+			return append(v[:index:index], v[index+1:]...)
+		}
+		v[index] = replaced
+		return v
+	default:
+		return value
+	}
+}
+
+// replaceAll replaces every member of an object or element of an array
+func replaceAll(value interface{}, replace func(interface{}) (interface{}, bool)) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for name, child := range v {
+			if replaced, keep := replace(child); keep {
+				v[name] = replaced
+			} else {
+				delete(v, name)
+			}
+		}
+		return v
+	case []interface{}:
+		kept := v[:0]
+		for _, child := range v {
+			if replaced, keep := replace(child); keep {
+				kept = append(kept, replaced)
+			}
+		}
+		return kept
+	default:
+		return value
+	}
+}
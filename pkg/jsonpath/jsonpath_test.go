@@ -0,0 +1,79 @@
+package jsonpath
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const document = `{
+  "user": {"email": "ann@example.com", "ssn": "123-45-6789"},
+  "orders": [
+    {"id": 1, "card": "4111111111111111", "contact": {"email": "billing@example.com"}},
+    {"id": 2, "card": "5500000000000004"}
+  ]
+}`
+
+func TestPath_Replace(t *testing.T) {
+	tests := []struct {
+		path string
+		want string
+	}{
+		{"$.user.email", `{"user":{"email":"x","ssn":"123-45-6789"},"orders":[{"id":1,"card":"4111111111111111","contact":{"email":"billing@example.com"}},{"id":2,"card":"5500000000000004"}]}`},
+		{"$['user']['ssn']", `{"user":{"email":"ann@example.com","ssn":"x"},"orders":[{"id":1,"card":"4111111111111111","contact":{"email":"billing@example.com"}},{"id":2,"card":"5500000000000004"}]}`},
+		{"$.orders[*].card", `{"user":{"email":"ann@example.com","ssn":"123-45-6789"},"orders":[{"id":1,"card":"x","contact":{"email":"billing@example.com"}},{"id":2,"card":"x"}]}`},
+		{"$.orders[-1].id", `{"user":{"email":"ann@example.com","ssn":"123-45-6789"},"orders":[{"id":1,"card":"4111111111111111","contact":{"email":"billing@example.com"}},{"id":"x","card":"5500000000000004"}]}`},
+		{"$..email", `{"user":{"email":"x","ssn":"123-45-6789"},"orders":[{"id":1,"card":"4111111111111111","contact":{"email":"x"}},{"id":2,"card":"5500000000000004"}]}`},
+		{"$.user.*", `{"user":{"email":"x","ssn":"x"},"orders":[{"id":1,"card":"4111111111111111","contact":{"email":"billing@example.com"}},{"id":2,"card":"5500000000000004"}]}`},
+		{"$.missing.email", `{"user":{"email":"ann@example.com","ssn":"123-45-6789"},"orders":[{"id":1,"card":"4111111111111111","contact":{"email":"billing@example.com"}},{"id":2,"card":"5500000000000004"}]}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			path, err := Parse(tt.path)
+			require.NoError(t, err)
+
+			var doc interface{}
+			require.NoError(t, json.Unmarshal([]byte(document), &doc))
+			doc = path.Replace(doc, func(value interface{}) (interface{}, bool) { return "x", true })
+			assert.JSONEq(t, tt.want, mustMarshal(t, doc))
+		})
+	}
+}
+
+func TestPath_ReplaceRemoves(t *testing.T) {
+	var doc interface{}
+	require.NoError(t, json.Unmarshal([]byte(document), &doc))
+
+	remove := func(value interface{}) (interface{}, bool) { return nil, false }
+	doc = mustParse(t, "$..card").Replace(doc, remove)
+	doc = mustParse(t, "$.orders[0]").Replace(doc, remove)
+	assert.JSONEq(t, `{"user":{"email":"ann@example.com","ssn":"123-45-6789"},"orders":[{"id":2}]}`, mustMarshal(t, doc))
+}
+
+func TestParse_Invalid(t *testing.T) {
+	for _, expression := range []string{"user.email", "$.", "$.user[", "$.orders[one]", "$user"} {
+		_, err := Parse(expression)
+		assert.Error(t, err, expression)
+	}
+
+	path, err := Parse("$")
+	require.NoError(t, err)
+	assert.True(t, path.Root())
+}
+
+func mustParse(t *testing.T, expression string) *Path {
+	t.Helper()
+	path, err := Parse(expression)
+	require.NoError(t, err)
+	return path
+}
+
+func mustMarshal(t *testing.T, value interface{}) string {
+	t.Helper()
+	data, err := json.Marshal(value)
+	require.NoError(t, err)
+	return string(data)
+}
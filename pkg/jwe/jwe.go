@@ -0,0 +1,233 @@
+// Package jwe reads and writes JSON Web Encryption compact serializations (RFC 7516) whose
+// content is encrypted with AES-GCM, under a shared key ("dir") or a random key encrypted
+// with RSA-OAEP-256
+package jwe
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"strings"
+)
+
+// ContentType is the media type of JWE compact serializations
+const ContentType = "application/jose"
+
+// Key management algorithms
+const (
+	AlgorithmDirect     = "dir"
+	AlgorithmRSAOAEP256 = "RSA-OAEP-256"
+)
+
+// Header is the protected header of a JWE
+type Header struct {
+	Algorithm   string `json:"alg"`
+	Encryption  string `json:"enc"`
+	KeyID       string `json:"kid,omitempty"`
+	ContentType string `json:"cty,omitempty"`
+	Compression string `json:"zip,omitempty"`
+}
+
+// Key is a key content is encrypted for or decrypted with
+type Key struct {
+	// ID is sent as the kid of encrypted content
+	ID string
+	// Symmetric is an AES key of 16, 24 or 32 bytes encrypting content directly
+	Symmetric []byte
+	// Public encrypts the content encryption keys, for a recipient holding the private key
+	Public *rsa.PublicKey
+	// Private decrypts the content encryption keys
+	Private *rsa.PrivateKey
+}
+
+// ParseKey parses a PEM encoded RSA private or public key, or a base64 encoded AES key
+func ParseKey(value string) (*Key, error) {
+	value = strings.TrimSpace(value)
+	if block, _ := pem.Decode([]byte(value)); block != nil {
+		return parsePEM(block)
+	}
+
+	for _, encoding := range []*base64.Encoding{base64.StdEncoding, base64.RawStdEncoding, base64.URLEncoding, base64.RawURLEncoding} {
+		if secret, err := encoding.DecodeString(value); err == nil {
+			if _, err := encryptionForKey(len(secret)); err != nil {
+				return nil, err
+			}
+			return &Key{Symmetric: secret}, nil
+		}
+	}
+	return nil, fmt.Errorf("key is neither PEM nor base64 encoded")
+}
+
+func parsePEM(block *pem.Block) (*Key, error) {
+	switch block.Type {
+	case "RSA PRIVATE KEY":
+		private, err := x509.ParsePKCS1PrivateKey(block.Bytes)
+		if err != nil {
+			return nil, fmt.Errorf("invalid RSA private key: %w", err)
+		}
+		return &Key{Private: private, Public: &private.PublicKey}, nil
+	case "PRIVATE KEY":
+		parsed, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+		if err != nil {
+			return nil, fmt.Errorf("invalid private key: %w", err)
+		}
+		private, ok := parsed.(*rsa.PrivateKey)
+		if !ok {
+			return nil, fmt.Errorf("private key is not an RSA key")
+		}
+		return &Key{Private: private, Public: &private.PublicKey}, nil
+	case "RSA PUBLIC KEY":
+		public, err := x509.ParsePKCS1PublicKey(block.Bytes)
+		if err != nil {
+			return nil, fmt.Errorf("invalid RSA public key: %w", err)
+		}
+		return &Key{Public: public}, nil
+	case "PUBLIC KEY":
+		parsed, err := x509.ParsePKIXPublicKey(block.Bytes)
+		if err != nil {
+			return nil, fmt.Errorf("invalid public key: %w", err)
+		}
+		public, ok := parsed.(*rsa.PublicKey)
+		if !ok {
+			return nil, fmt.Errorf("public key is not an RSA key")
+		}
+		return &Key{Public: public}, nil
+	default:
+		return nil, fmt.Errorf("unsupported PEM block %s", block.Type)
+	}
+}
+
+// encryptionForKey returns the content encryption of an AES key size
+func encryptionForKey(size int) (string, error) {
+	switch size {
+	case 16, 24, 32:
+		return fmt.Sprintf("A%dGCM", size*8), nil
+	default:
+		return "", fmt.Errorf("AES keys must be 16, 24 or 32 bytes, got %d", size)
+	}
+}
+
+// Encrypt returns the compact serialization of content encrypted for the key. The content type
+// is carried in the cty header, so the recipient knows how to read the content.
+func Encrypt(plaintext []byte, key *Key, contentType string) (string, error) {
+	header := Header{KeyID: key.ID, ContentType: contentType}
+	var cek, encryptedKey []byte
+	switch {
+	case key.Symmetric != nil:
+		header.Algorithm = AlgorithmDirect
+		header.Encryption, _ = encryptionForKey(len(key.Symmetric))
+		cek = key.Symmetric
+	case key.Public != nil:
+		header.Algorithm = AlgorithmRSAOAEP256
+		header.Encryption = "A256GCM"
+		cek = make([]byte, 32)
+		if _, err := rand.Read(cek); err != nil {
+			return "", fmt.Errorf("failed to generate content encryption key: %w", err)
+		}
+		var err error
+		encryptedKey, err = rsa.EncryptOAEP(sha256.New(), rand.Reader, key.Public, cek, nil)
+		if err != nil {
+			return "", fmt.Errorf("failed to encrypt content encryption key: %w", err)
+		}
+	default:
+		return "", fmt.Errorf("key cannot encrypt")
+	}
+
+	headerJSON, err := json.Marshal(header)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode header: %w", err)
+	}
+	protected := base64.RawURLEncoding.EncodeToString(headerJSON)
+
+	gcm, err := newGCM(cek)
+	if err != nil {
+		return "", err
+	}
+	iv := make([]byte, gcm.NonceSize())
+	if _, err := rand.Read(iv); err != nil {
+		return "", fmt.Errorf("failed to generate initialization vector: %w", err)
+	}
+	sealed := gcm.Seal(nil, iv, plaintext, []byte(protected))
+	ciphertext, tag := sealed[:len(sealed)-gcm.Overhead()], sealed[len(sealed)-gcm.Overhead():]
+
+	return strings.Join([]string{
+		protected,
+		base64.RawURLEncoding.EncodeToString(encryptedKey),
+		base64.RawURLEncoding.EncodeToString(iv),
+		base64.RawURLEncoding.EncodeToString(ciphertext),
+		base64.RawURLEncoding.EncodeToString(tag),
+	}, "."), nil
+}
+
+// Decrypt returns the content of a compact serialization encrypted for the key, and its header
+func Decrypt(token string, key *Key) ([]byte, *Header, error) {
+	parts := strings.Split(strings.TrimSpace(token), ".")
+	if len(parts) != 5 {
+		return nil, nil, fmt.Errorf("not a JWE compact serialization")
+	}
+	decoded := make([][]byte, len(parts))
+	for i, part := range parts {
+		var err error
+		if decoded[i], err = base64.RawURLEncoding.DecodeString(part); err != nil {
+			return nil, nil, fmt.Errorf("invalid base64url in part %d: %w", i+1, err)
+		}
+	}
+
+	var header Header
+	if err := json.Unmarshal(decoded[0], &header); err != nil {
+		return nil, nil, fmt.Errorf("invalid header: %w", err)
+	}
+	if header.Compression != "" {
+		return nil, nil, fmt.Errorf("compressed content is not supported")
+	}
+
+	var cek []byte
+	switch header.Algorithm {
+	case AlgorithmDirect:
+		if key.Symmetric == nil || len(decoded[1]) != 0 {
+			return nil, nil, fmt.Errorf("key cannot decrypt %s content", header.Algorithm)
+		}
+		cek = key.Symmetric
+	case AlgorithmRSAOAEP256:
+		if key.Private == nil {
+			return nil, nil, fmt.Errorf("key cannot decrypt %s content", header.Algorithm)
+		}
+		var err error
+		if cek, err = rsa.DecryptOAEP(sha256.New(), nil, key.Private, decoded[1], nil); err != nil {
+			return nil, nil, fmt.Errorf("failed to decrypt content encryption key: %w", err)
+		}
+	default:
+		return nil, nil, fmt.Errorf("unsupported algorithm %q", header.Algorithm)
+	}
+	if encryption, err := encryptionForKey(len(cek)); err != nil || encryption != header.Encryption {
+		return nil, nil, fmt.Errorf("unsupported content encryption %q", header.Encryption)
+	}
+
+	gcm, err := newGCM(cek)
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(decoded[2]) != gcm.NonceSize() {
+		return nil, nil, fmt.Errorf("initialization vector must be %d bytes", gcm.NonceSize())
+	}
+	plaintext, err := gcm.Open(nil, decoded[2], append(decoded[3], decoded[4]...), []byte(parts[0]))
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to decrypt content: %w", err)
+	}
+	return plaintext, &header, nil
+}
+
+func newGCM(cek []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(cek)
+	if err != nil {
+		return nil, fmt.Errorf("invalid content encryption key: %w", err)
+	}
+	return cipher.NewGCM(block)
+}
@@ -0,0 +1,105 @@
+package jwe
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEncryptDecrypt(t *testing.T) {
+	private, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	symmetric := &Key{ID: "shared", Symmetric: []byte("0123456789abcdef0123456789abcdef")}
+
+	testCases := []struct {
+		name       string
+		encryptFor *Key
+		decryptBy  *Key
+		algorithm  string
+		encryption string
+	}{
+		{name: "Direct", encryptFor: symmetric, decryptBy: symmetric, algorithm: AlgorithmDirect, encryption: "A256GCM"},
+		{name: "Direct 128", encryptFor: &Key{Symmetric: []byte("0123456789abcdef")}, decryptBy: &Key{Symmetric: []byte("0123456789abcdef")}, algorithm: AlgorithmDirect, encryption: "A128GCM"},
+		{name: "RSA", encryptFor: &Key{Public: &private.PublicKey}, decryptBy: &Key{Private: private}, algorithm: AlgorithmRSAOAEP256, encryption: "A256GCM"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			token, err := Encrypt([]byte(`{"ssn":"123-45-6789"}`), tc.encryptFor, "application/json")
+			require.NoError(t, err)
+			assert.Len(t, strings.Split(token, "."), 5)
+			assert.NotContains(t, token, "123-45-6789")
+
+			plaintext, header, err := Decrypt(token, tc.decryptBy)
+			require.NoError(t, err)
+			assert.Equal(t, `{"ssn":"123-45-6789"}`, string(plaintext))
+			assert.Equal(t, tc.algorithm, header.Algorithm)
+			assert.Equal(t, tc.encryption, header.Encryption)
+			assert.Equal(t, "application/json", header.ContentType)
+		})
+	}
+}
+
+func TestDecrypt_Rejects(t *testing.T) {
+	key := &Key{Symmetric: []byte("0123456789abcdef0123456789abcdef")}
+	token, err := Encrypt([]byte("secret"), key, "")
+	require.NoError(t, err)
+	parts := strings.Split(token, ".")
+
+	tampered := append([]string(nil), parts...)
+	tampered[3] = base64.RawURLEncoding.EncodeToString([]byte("SECRET"))
+
+	testCases := []struct {
+		name  string
+		token string
+		key   *Key
+	}{
+		{name: "Not a JWE", token: "eyJhbGciOiJIUzI1NiJ9.e30.sig", key: key},
+		{name: "Tampered ciphertext", token: strings.Join(tampered, "."), key: key},
+		{name: "Other key", token: token, key: &Key{Symmetric: []byte("fedcba9876543210fedcba9876543210")}},
+		{name: "Key size mismatch", token: token, key: &Key{Symmetric: []byte("0123456789abcdef")}},
+		{name: "Public key only", token: token, key: &Key{Public: &rsa.PublicKey{}}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, _, err := Decrypt(tc.token, tc.key)
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestParseKey(t *testing.T) {
+	private, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	privatePEM := pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(private)})
+	publicDER, err := x509.MarshalPKIXPublicKey(&private.PublicKey)
+	require.NoError(t, err)
+	publicPEM := pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: publicDER})
+
+	key, err := ParseKey(string(privatePEM))
+	require.NoError(t, err)
+	assert.NotNil(t, key.Private)
+	assert.NotNil(t, key.Public)
+
+	key, err = ParseKey(string(publicPEM))
+	require.NoError(t, err)
+	assert.Nil(t, key.Private)
+	assert.Equal(t, private.PublicKey.N, key.Public.N)
+
+	key, err = ParseKey(base64.StdEncoding.EncodeToString([]byte("0123456789abcdef0123456789abcdef")) + "\n")
+	require.NoError(t, err)
+	assert.Len(t, key.Symmetric, 32)
+
+	_, err = ParseKey(base64.StdEncoding.EncodeToString([]byte("too short")))
+	assert.Error(t, err)
+	_, err = ParseKey("not a key!")
+	assert.Error(t, err)
+}
@@ -0,0 +1,260 @@
+// Package loadtest drives a steady request rate against a running gateway over a weighted mix of
+// routes and summarises the latency percentiles and error rates it saw
+package loadtest
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Route is a request in the mix
+type Route struct {
+	Method string `json:"method"`
+	Path   string `json:"path"`
+	// Weight is the share of requests sent to the route relative to the others, 1 when unset
+	Weight  int               `json:"weight,omitempty"`
+	Headers map[string]string `json:"headers,omitempty"`
+	Body    string            `json:"body,omitempty"`
+}
+
+// Name identifies the route in reports
+func (r Route) Name() string {
+	return r.Method + " " + r.Path
+}
+
+// ParseRoute parses a route given as "METHOD PATH [WEIGHT]", such as "GET /api/v1/catalog 3"
+func ParseRoute(s string) (Route, error) {
+	fields := strings.Fields(s)
+	if len(fields) < 2 || len(fields) > 3 {
+		return Route{}, fmt.Errorf("route %q must be METHOD PATH [WEIGHT]", s)
+	}
+	route := Route{Method: strings.ToUpper(fields[0]), Path: fields[1], Weight: 1}
+	if !strings.HasPrefix(route.Path, "/") {
+		return Route{}, fmt.Errorf("route %q: path must start with /", s)
+	}
+	if len(fields) == 3 {
+		weight, err := strconv.Atoi(fields[2])
+		if err != nil || weight < 1 {
+			return Route{}, fmt.Errorf("route %q: weight must be a positive integer", s)
+		}
+		route.Weight = weight
+	}
+	return route, nil
+}
+
+// LoadRoutes reads a JSON array of routes, for mixes that need request bodies or headers
+func LoadRoutes(path string) ([]Route, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read routes: %w", err)
+	}
+	var routes []Route
+	if err := json.Unmarshal(data, &routes); err != nil {
+		return nil, fmt.Errorf("failed to parse routes %s: %w", path, err)
+	}
+	for i, route := range routes {
+		if route.Method == "" || !strings.HasPrefix(route.Path, "/") {
+			return nil, fmt.Errorf("routes %s: route %d needs a method and a path starting with /", path, i)
+		}
+		if route.Weight < 0 {
+			return nil, fmt.Errorf("routes %s: route %d has a negative weight", path, i)
+		}
+	}
+	return routes, nil
+}
+
+// Config describes a load test
+type Config struct {
+	// BaseURL is the gateway the route paths are sent to, such as http://localhost:8080
+	BaseURL string
+	Routes  []Route
+	// Headers are sent with every request, before the headers of the route
+	Headers map[string]string
+	// Rate is the number of requests started per second
+	Rate     int
+	Duration time.Duration
+	// Concurrency bounds the requests in flight; requests due while it is reached are skipped
+	Concurrency int
+	Timeout     time.Duration
+}
+
+// Runner sends the requests of a load test
+type Runner struct {
+	cfg    Config
+	client *http.Client
+	// mix lists route indexes in proportion to their weights, cycled through in order
+	mix []int
+}
+
+// NewRunner creates a new Runner instance
+func NewRunner(cfg Config) (*Runner, error) {
+	if cfg.BaseURL == "" {
+		return nil, fmt.Errorf("a base URL is required")
+	}
+	if len(cfg.Routes) == 0 {
+		return nil, fmt.Errorf("at least one route is required")
+	}
+	if cfg.Rate < 1 {
+		return nil, fmt.Errorf("rate must be positive, got %d", cfg.Rate)
+	}
+	if cfg.Duration <= 0 {
+		return nil, fmt.Errorf("duration must be positive, got %s", cfg.Duration)
+	}
+	if cfg.Concurrency < 1 {
+		return nil, fmt.Errorf("concurrency must be positive, got %d", cfg.Concurrency)
+	}
+
+	r := &Runner{
+		cfg: cfg,
+		client: &http.Client{
+			Timeout: cfg.Timeout,
+			Transport: &http.Transport{
+				Proxy:               http.ProxyFromEnvironment,
+				MaxIdleConns:        cfg.Concurrency,
+				MaxIdleConnsPerHost: cfg.Concurrency,
+				IdleConnTimeout:     90 * time.Second,
+			},
+		},
+	}
+	r.cfg.BaseURL = strings.TrimSuffix(cfg.BaseURL, "/")
+	for i, route := range cfg.Routes {
+		weight := route.Weight
+		if weight == 0 {
+			weight = 1
+		}
+		for j := 0; j < weight; j++ {
+			r.mix = append(r.mix, i)
+		}
+	}
+	return r, nil
+}
+
+// Run sends requests at the configured rate until the duration has passed or the context is
+// cancelled, then waits for the requests in flight. Requests the cancellation interrupts are
+// not counted. When interval is positive, progress is called
+// with a report of each interval.
+func (r *Runner) Run(ctx context.Context, interval time.Duration, progress func(*Report)) *Report {
+	total := newRecorder(r.cfg.Routes)
+	var current *recorder
+	var mu sync.Mutex
+	if interval > 0 && progress != nil {
+		current = newRecorder(r.cfg.Routes)
+		stop := make(chan struct{})
+		stopped := make(chan struct{})
+		defer func() {
+			close(stop)
+			<-stopped
+		}()
+		go func() {
+			defer close(stopped)
+			ticker := time.NewTicker(interval)
+			defer ticker.Stop()
+			for {
+				select {
+				case <-stop:
+					return
+				case <-ticker.C:
+					mu.Lock()
+					finished := current
+					current = newRecorder(r.cfg.Routes)
+					mu.Unlock()
+					progress(finished.report(interval))
+				}
+			}
+		}()
+	}
+	record := func(route int, latency time.Duration, status int, err error) {
+		total.add(route, latency, status, err)
+		mu.Lock()
+		if current != nil {
+			current.add(route, latency, status, err)
+		}
+		mu.Unlock()
+	}
+
+	start := time.Now()
+	deadline := start.Add(r.cfg.Duration)
+	period := time.Second / time.Duration(r.cfg.Rate)
+	inFlight := make(chan struct{}, r.cfg.Concurrency)
+	var wg sync.WaitGroup
+	timer := time.NewTimer(0)
+	<-timer.C
+
+loop:
+	for i := 0; ; i++ {
+		// Requests are due on a fixed schedule, so a slow gateway does not slow the load down
+		due := start.Add(time.Duration(i) * period)
+		if !due.Before(deadline) {
+			break
+		}
+		if wait := time.Until(due); wait > 0 {
+			timer.Reset(wait)
+			select {
+			case <-ctx.Done():
+				break loop
+			case <-timer.C:
+			}
+		} else if ctx.Err() != nil {
+			break loop
+		}
+
+		route := r.mix[i%len(r.mix)]
+		select {
+		case inFlight <- struct{}{}:
+		default:
+			total.skip()
+			continue
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer func() { <-inFlight }()
+			status, err := r.send(ctx, r.cfg.Routes[route])
+			if err != nil && ctx.Err() != nil {
+				// Requests interrupted by the end of the test are not counted
+				return
+			}
+			// Latency counts from when the request was due, including any time it waited to be sent
+			record(route, time.Since(due), status, err)
+		}()
+	}
+	wg.Wait()
+
+	return total.report(time.Since(start))
+}
+
+// send sends a request of a route and reads its response
+func (r *Runner) send(ctx context.Context, route Route) (int, error) {
+	var body io.Reader
+	if route.Body != "" {
+		body = strings.NewReader(route.Body)
+	}
+	req, err := http.NewRequestWithContext(ctx, route.Method, r.cfg.BaseURL+route.Path, body)
+	if err != nil {
+		return 0, err
+	}
+	for name, value := range r.cfg.Headers {
+		req.Header.Set(name, value)
+	}
+	for name, value := range route.Headers {
+		req.Header.Set(name, value)
+	}
+
+	resp, err := r.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	if _, err := io.Copy(io.Discard, resp.Body); err != nil {
+		return resp.StatusCode, err
+	}
+	return resp.StatusCode, nil
+}
@@ -0,0 +1,126 @@
+package loadtest
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseRoute(t *testing.T) {
+	route, err := ParseRoute("get /api/v1/catalog 3")
+	require.NoError(t, err)
+	assert.Equal(t, Route{Method: "GET", Path: "/api/v1/catalog", Weight: 3}, route)
+
+	route, err = ParseRoute("POST /api/v1/orders")
+	require.NoError(t, err)
+	assert.Equal(t, 1, route.Weight)
+
+	for _, invalid := range []string{"/api/v1/catalog", "GET api/v1/catalog", "GET /api/v1/catalog 0", "GET /a 1 2"} {
+		_, err := ParseRoute(invalid)
+		assert.Error(t, err, invalid)
+	}
+}
+
+func TestLoadRoutes(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "routes.json")
+	require.NoError(t, os.WriteFile(path, []byte(`[
+		{"method": "POST", "path": "/api/v1/orders", "weight": 2, "headers": {"Content-Type": "application/json"}, "body": "{\"sku\":\"SKU-1\"}"},
+		{"method": "GET", "path": "/api/v1/catalog"}
+	]`), 0o600))
+
+	routes, err := LoadRoutes(path)
+	require.NoError(t, err)
+	require.Len(t, routes, 2)
+	assert.Equal(t, `{"sku":"SKU-1"}`, routes[0].Body)
+	assert.Equal(t, "application/json", routes[0].Headers["Content-Type"])
+
+	require.NoError(t, os.WriteFile(path, []byte(`[{"method": "GET", "path": "catalog"}]`), 0o600))
+	_, err = LoadRoutes(path)
+	assert.Error(t, err)
+}
+
+func TestRunner_Run(t *testing.T) {
+	var mu sync.Mutex
+	authorized := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		if r.Header.Get("Authorization") == "Bearer token" {
+			authorized++
+		}
+		mu.Unlock()
+		if r.URL.Path == "/api/v1/orders" {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.Write([]byte(`{"items":[]}`))
+	}))
+	defer server.Close()
+
+	runner, err := NewRunner(Config{
+		BaseURL:     server.URL + "/",
+		Routes:      []Route{{Method: "GET", Path: "/api/v1/catalog", Weight: 3}, {Method: "GET", Path: "/api/v1/orders"}},
+		Headers:     map[string]string{"Authorization": "Bearer token"},
+		Rate:        200,
+		Duration:    200 * time.Millisecond,
+		Concurrency: 40,
+		Timeout:     time.Second,
+	})
+	require.NoError(t, err)
+
+	var intervals []*Report
+	report := runner.Run(context.Background(), 50*time.Millisecond, func(r *Report) { intervals = append(intervals, r) })
+
+	// 40 requests are due in 200ms at 200 req/s, three catalog requests for each orders request
+	assert.Equal(t, 40, report.Requests)
+	assert.Equal(t, 0, report.Skipped)
+	require.Len(t, report.Routes, 2)
+	assert.Equal(t, "GET /api/v1/catalog", report.Routes[0].Route)
+	assert.Equal(t, 30, report.Routes[0].Requests)
+	assert.Equal(t, 10, report.Routes[1].Requests)
+	assert.Equal(t, 0.25, report.ErrorRate)
+	assert.Equal(t, 0.0, report.Routes[0].ErrorRate)
+	assert.Equal(t, 1.0, report.Routes[1].ErrorRate)
+	assert.Equal(t, report.Routes[1].Requests, report.Statuses["5xx"])
+	assert.Greater(t, report.Latency.P50, 0.0)
+	assert.LessOrEqual(t, report.Latency.P50, report.Latency.P99)
+	assert.LessOrEqual(t, report.Latency.P99, report.Latency.Max)
+	assert.Equal(t, report.Requests, authorized)
+	assert.NotEmpty(t, intervals)
+
+	var out bytes.Buffer
+	require.NoError(t, report.Write(&out))
+	assert.Contains(t, out.String(), "GET /api/v1/orders")
+	assert.Contains(t, out.String(), "5xx=")
+}
+
+func TestRunner_Cancel(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer server.Close()
+
+	runner, err := NewRunner(Config{BaseURL: server.URL, Routes: []Route{{Method: "GET", Path: "/"}}, Rate: 100, Duration: time.Hour, Concurrency: 1})
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	runner.Run(ctx, 0, nil)
+	assert.Less(t, time.Since(start), time.Second)
+}
+
+func TestPercentiles(t *testing.T) {
+	latencies := make([]time.Duration, 100)
+	for i := range latencies {
+		latencies[len(latencies)-1-i] = time.Duration(i+1) * time.Millisecond
+	}
+	assert.Equal(t, Latencies{P50: 50, P90: 90, P95: 95, P99: 99, Max: 100}, percentiles(latencies))
+	assert.Equal(t, Latencies{P50: 7, P90: 7, P95: 7, P99: 7, Max: 7}, percentiles([]time.Duration{7 * time.Millisecond}))
+}
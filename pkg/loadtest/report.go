@@ -0,0 +1,199 @@
+package loadtest
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"strconv"
+	"sync"
+	"text/tabwriter"
+	"time"
+)
+
+// Report summarises the requests of a load test, or of an interval of one
+type Report struct {
+	Duration        time.Duration `json:"-"`
+	DurationSeconds float64       `json:"durationSeconds"`
+	// Requests counts the requests that completed, with or without a response
+	Requests int `json:"requests"`
+	// Skipped counts requests that were due while the concurrency limit was reached
+	Skipped int `json:"skipped"`
+	// Errors counts requests without a response or with a 5xx status
+	Errors    int     `json:"errors"`
+	ErrorRate float64 `json:"errorRate"`
+	// Throughput is the number of completed requests per second
+	Throughput float64   `json:"throughput"`
+	Latency    Latencies `json:"latency"`
+	// Statuses counts responses by status class, such as 2xx, and requests without one as error
+	Statuses map[string]int `json:"statuses"`
+	Routes   []*RouteReport `json:"routes,omitempty"`
+}
+
+// RouteReport summarises the requests of a route
+type RouteReport struct {
+	Route     string         `json:"route"`
+	Requests  int            `json:"requests"`
+	Errors    int            `json:"errors"`
+	ErrorRate float64        `json:"errorRate"`
+	Latency   Latencies      `json:"latency"`
+	Statuses  map[string]int `json:"statuses"`
+}
+
+// Latencies are latency percentiles in milliseconds
+type Latencies struct {
+	P50 float64 `json:"p50"`
+	P90 float64 `json:"p90"`
+	P95 float64 `json:"p95"`
+	P99 float64 `json:"p99"`
+	Max float64 `json:"max"`
+}
+
+// Write prints the report as a table, with a row per route when it has them
+func (r *Report) Write(w io.Writer) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', tabwriter.AlignRight)
+	fmt.Fprintln(tw, "ROUTE\tREQUESTS\tERRORS\tP50 MS\tP90 MS\tP95 MS\tP99 MS\tMAX MS\tSTATUSES\t")
+	for _, route := range r.Routes {
+		writeRow(tw, route.Route, route.Requests, route.ErrorRate, route.Latency, route.Statuses)
+	}
+	writeRow(tw, "total", r.Requests, r.ErrorRate, r.Latency, r.Statuses)
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+	_, err := fmt.Fprintf(w, "%d requests in %s, %.1f req/s, %.2f%% errors, %d skipped\n",
+		r.Requests, r.Duration.Round(time.Millisecond), r.Throughput, r.ErrorRate*100, r.Skipped)
+	return err
+}
+
+func writeRow(w io.Writer, name string, requests int, errorRate float64, latency Latencies, statuses map[string]int) {
+	fmt.Fprintf(w, "%s\t%d\t%.2f%%\t%.1f\t%.1f\t%.1f\t%.1f\t%.1f\t%s\t\n",
+		name, requests, errorRate*100, latency.P50, latency.P90, latency.P95, latency.P99, latency.Max, formatStatuses(statuses))
+}
+
+// formatStatuses lists status classes in order, such as "2xx=980 5xx=20"
+func formatStatuses(statuses map[string]int) string {
+	classes := make([]string, 0, len(statuses))
+	for class := range statuses {
+		classes = append(classes, class)
+	}
+	sort.Strings(classes)
+
+	var out []byte
+	for i, class := range classes {
+		if i > 0 {
+			out = append(out, ' ')
+		}
+		out = append(out, class...)
+		out = append(out, '=')
+		out = strconv.AppendInt(out, int64(statuses[class]), 10)
+	}
+	return string(out)
+}
+
+// recorder collects the results of requests
+type recorder struct {
+	routes []string
+
+	mu      sync.Mutex
+	results [][]result
+	skipped int
+}
+
+// result is the outcome of a request
+type result struct {
+	latency time.Duration
+	class   string
+	failed  bool
+}
+
+// newRecorder creates a new recorder instance
+func newRecorder(routes []Route) *recorder {
+	names := make([]string, len(routes))
+	for i, route := range routes {
+		names[i] = route.Name()
+	}
+	return &recorder{routes: names, results: make([][]result, len(routes))}
+}
+
+// add records a request to a route. Requests without a response and 5xx responses are errors.
+func (r *recorder) add(route int, latency time.Duration, status int, err error) {
+	res := result{latency: latency, class: "error", failed: true}
+	if err == nil {
+		res.class = strconv.Itoa(status/100) + "xx"
+		res.failed = status >= 500
+	}
+
+	r.mu.Lock()
+	r.results[route] = append(r.results[route], res)
+	r.mu.Unlock()
+}
+
+// skip records a request that was not sent
+func (r *recorder) skip() {
+	r.mu.Lock()
+	r.skipped++
+	r.mu.Unlock()
+}
+
+// report summarises the recorded requests over the given duration
+func (r *recorder) report(duration time.Duration) *Report {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	report := &Report{Duration: duration, DurationSeconds: duration.Seconds(), Skipped: r.skipped, Statuses: map[string]int{}}
+	var all []time.Duration
+	for i, results := range r.results {
+		if len(results) == 0 {
+			continue
+		}
+		route := &RouteReport{Route: r.routes[i], Requests: len(results), Statuses: map[string]int{}}
+		latencies := make([]time.Duration, len(results))
+		for j, res := range results {
+			latencies[j] = res.latency
+			route.Statuses[res.class]++
+			report.Statuses[res.class]++
+			if res.failed {
+				route.Errors++
+			}
+		}
+		route.ErrorRate = float64(route.Errors) / float64(route.Requests)
+		route.Latency = percentiles(latencies)
+		report.Routes = append(report.Routes, route)
+
+		report.Requests += route.Requests
+		report.Errors += route.Errors
+		all = append(all, latencies...)
+	}
+	if report.Requests > 0 {
+		report.ErrorRate = float64(report.Errors) / float64(report.Requests)
+	}
+	if duration > 0 {
+		report.Throughput = float64(report.Requests) / duration.Seconds()
+	}
+	report.Latency = percentiles(all)
+	return report
+}
+
+// percentiles returns the latency percentiles of the given latencies, which it sorts
+func percentiles(latencies []time.Duration) Latencies {
+	if len(latencies) == 0 {
+		return Latencies{}
+	}
+	sort.Slice(latencies, func(i, j int) bool { return latencies[i] < latencies[j] })
+	at := func(p float64) float64 {
+		// Nearest rank: the smallest latency at least p of the requests were as fast as
+		rank := int(math.Ceil(p*float64(len(latencies)))) - 1
+		return milliseconds(latencies[rank])
+	}
+	return Latencies{
+		P50: at(0.50),
+		P90: at(0.90),
+		P95: at(0.95),
+		P99: at(0.99),
+		Max: milliseconds(latencies[len(latencies)-1]),
+	}
+}
+
+func milliseconds(d time.Duration) float64 {
+	return float64(d) / float64(time.Millisecond)
+}
@@ -0,0 +1,138 @@
+package logger
+
+import (
+	"context"
+	"sort"
+	"sync"
+)
+
+// Standard field names attached to request-scoped loggers
+const (
+	FieldRequestID = "request_id"
+	FieldTraceID   = "trace_id"
+	FieldUserID    = "user_id"
+	FieldService   = "service"
+	// FieldErrorCode is the machine-readable code of a failed request's error, such as rate-limit-exceeded
+	FieldErrorCode = "error_code"
+	// FieldTagPrefix prefixes the names of the fields holding the tags of the route served
+	FieldTagPrefix = "tag_"
+)
+
+type contextKey struct{}
+
+var (
+	defaultMu     sync.RWMutex
+	defaultLogger Logger = nopLogger{}
+)
+
+// SetDefault sets the logger returned by FromContext when the context carries none
+func SetDefault(l Logger) {
+	defaultMu.Lock()
+	defer defaultMu.Unlock()
+	defaultLogger = l
+}
+
+// Default returns the process-wide default logger
+func Default() Logger {
+	defaultMu.RLock()
+	defer defaultMu.RUnlock()
+	return defaultLogger
+}
+
+// NewContext returns a context carrying the logger
+func NewContext(ctx context.Context, l Logger) context.Context {
+	return context.WithValue(ctx, contextKey{}, l)
+}
+
+// FromContext returns the request-scoped logger carried by the context,
+// falling back to the default logger
+func FromContext(ctx context.Context) Logger {
+	if l, ok := ctx.Value(contextKey{}).(Logger); ok {
+		return l
+	}
+	return Default()
+}
+
+// FromContextOr returns the request-scoped logger carried by the context,
+// falling back to the given logger
+func FromContextOr(ctx context.Context, fallback Logger) Logger {
+	if l, ok := ctx.Value(contextKey{}).(Logger); ok {
+		return l
+	}
+	return fallback
+}
+
+// WithFields returns a context whose logger includes the given key/value pairs in every log line
+func WithFields(ctx context.Context, keysAndValues ...interface{}) context.Context {
+	return NewContext(ctx, With(FromContext(ctx), keysAndValues...))
+}
+
+// TagFields returns the key/value pairs logging a route's tags, sorted by tag name
+func TagFields(tags map[string]string) []interface{} {
+	names := make([]string, 0, len(tags))
+	for name := range tags {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	fields := make([]interface{}, 0, 2*len(names))
+	for _, name := range names {
+		fields = append(fields, FieldTagPrefix+name, tags[name])
+	}
+	return fields
+}
+
+// With returns a logger that includes the given key/value pairs in every log line
+func With(l Logger, keysAndValues ...interface{}) Logger {
+	if fl, ok := l.(*fieldLogger); ok {
+		return &fieldLogger{
+			next:   fl.next,
+			fields: append(append([]interface{}(nil), fl.fields...), keysAndValues...),
+		}
+	}
+	return &fieldLogger{
+		next:   l,
+		fields: append([]interface{}(nil), keysAndValues...),
+	}
+}
+
+// fieldLogger prepends a fixed set of fields to every log line
+type fieldLogger struct {
+	next   Logger
+	fields []interface{}
+}
+
+func (l *fieldLogger) Debug(msg string, keysAndValues ...interface{}) {
+	l.next.Debug(msg, l.merge(keysAndValues)...)
+}
+
+func (l *fieldLogger) Info(msg string, keysAndValues ...interface{}) {
+	l.next.Info(msg, l.merge(keysAndValues)...)
+}
+
+func (l *fieldLogger) Warn(msg string, keysAndValues ...interface{}) {
+	l.next.Warn(msg, l.merge(keysAndValues)...)
+}
+
+func (l *fieldLogger) Error(msg string, keysAndValues ...interface{}) {
+	l.next.Error(msg, l.merge(keysAndValues)...)
+}
+
+func (l *fieldLogger) Fatal(msg string, keysAndValues ...interface{}) {
+	l.next.Fatal(msg, l.merge(keysAndValues)...)
+}
+
+func (l *fieldLogger) merge(keysAndValues []interface{}) []interface{} {
+	merged := make([]interface{}, 0, len(l.fields)+len(keysAndValues))
+	merged = append(merged, l.fields...)
+	return append(merged, keysAndValues...)
+}
+
+// nopLogger discards all log output
+type nopLogger struct{}
+
+func (nopLogger) Debug(msg string, keysAndValues ...interface{}) {}
+func (nopLogger) Info(msg string, keysAndValues ...interface{})  {}
+func (nopLogger) Warn(msg string, keysAndValues ...interface{})  {}
+func (nopLogger) Error(msg string, keysAndValues ...interface{}) {}
+func (nopLogger) Fatal(msg string, keysAndValues ...interface{}) {}
@@ -0,0 +1,42 @@
+package logger
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFromContext_IncludesRequestFields(t *testing.T) {
+	next := &recordingLogger{}
+
+	ctx := NewContext(context.Background(), With(next, FieldRequestID, "req-1", FieldTraceID, "trace-1"))
+	ctx = WithFields(ctx, FieldUserID, "user-1")
+
+	FromContext(ctx).Info("Request completed", "status", 200)
+
+	assert.Equal(t, []interface{}{
+		FieldRequestID, "req-1",
+		FieldTraceID, "trace-1",
+		FieldUserID, "user-1",
+		"status", 200,
+	}, next.keysAndValues)
+}
+
+func TestFromContext_FallsBackToDefault(t *testing.T) {
+	next := &recordingLogger{}
+	SetDefault(next)
+	defer SetDefault(nopLogger{})
+
+	FromContext(context.Background()).Warn("No request context", "key", "value")
+
+	assert.Equal(t, []interface{}{"key", "value"}, next.keysAndValues)
+	assert.Equal(t, Logger(next), FromContextOr(context.Background(), next))
+}
+
+func TestTagFields(t *testing.T) {
+	fields := TagFields(map[string]string{"tier": "1", "team": "payments"})
+
+	assert.Equal(t, []interface{}{"tag_team", "payments", "tag_tier", "1"}, fields)
+	assert.Empty(t, TagFields(nil))
+}
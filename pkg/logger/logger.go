@@ -1,6 +1,9 @@
 package logger
 
 import (
+	"sync/atomic"
+	"time"
+
 	"go.uber.org/zap"
 	"go.uber.org/zap/zapcore"
 )
@@ -16,40 +19,159 @@ type Logger interface {
 
 // ZapLogger implements the Logger interface using zap
 type ZapLogger struct {
-	logger *zap.SugaredLogger
+	logger  *zap.SugaredLogger
+	sampled atomic.Int64
+	writers []*asyncWriter
+	outputs []EntryWriter
+}
+
+// Options tunes where lines are written and the cost of logging under load. Nil options are
+// disabled.
+type Options struct {
+	// Outputs receive every line at or above their level, stderr when there are none
+	Outputs []Output
+	// Sampling limits repeated debug and info lines; warnings and errors are never sampled
+	Sampling *SamplingOptions
+	// Async writes the lines of stream outputs, such as stdout and files, from a background
+	// goroutine instead of the one logging them
+	Async *AsyncOptions
+}
+
+// SamplingOptions writes the first Initial lines with the same level and message each Tick,
+// then every Thereafter-th line
+type SamplingOptions struct {
+	Initial    int
+	Thereafter int
+	Tick       time.Duration
+}
+
+// AsyncOptions holds the queue and buffer of lines written in the background
+type AsyncOptions struct {
+	// QueueSize is the number of lines waiting to be written; lines logged while it is full are dropped
+	QueueSize int
+	// BufferSize is the number of bytes buffered before a write to the output
+	BufferSize    int
+	FlushInterval time.Duration
+}
+
+// Drops counts the log lines that were never written
+type Drops struct {
+	// Sampled lines were left out by sampling
+	Sampled int64
+	// Overflowed lines were logged while an async queue was full
+	Overflowed int64
+	// Failed lines could not be delivered by an output, such as a Loki push that failed
+	Failed int64
+}
+
+// DropReporter reports the log lines a logger did not write
+type DropReporter interface {
+	Drops() Drops
 }
 
 // NewZapLogger creates a new ZapLogger instance
-func NewZapLogger(level string, development bool) (*ZapLogger, error) {
+func NewZapLogger(level string, development bool, options Options) (*ZapLogger, error) {
 	var config zap.Config
 	if development {
 		config = zap.NewDevelopmentConfig()
 	} else {
 		config = zap.NewProductionConfig()
 	}
+	// Sampling is applied below so that it spares warnings and errors
+	config.Sampling = nil
+	config.Level.SetLevel(parseLevel(level))
+
+	outputs := options.Outputs
+	if len(outputs) == 0 {
+		stream, _, err := zap.Open(config.OutputPaths...)
+		if err != nil {
+			return nil, err
+		}
+		outputs = []Output{StreamOutput(stream)}
+		outputs[0].Console = config.Encoding == "console"
+	}
+
+	l := &ZapLogger{}
+	cores := make([]zapcore.Core, 0, len(outputs))
+	for _, output := range outputs {
+		encoder := zapcore.NewJSONEncoder(config.EncoderConfig)
+		if output.Console {
+			encoder = zapcore.NewConsoleEncoder(config.EncoderConfig)
+		}
+		var enabler zapcore.LevelEnabler = config.Level
+		if output.Level != "" {
+			enabler = parseLevel(output.Level)
+		}
 
-	// Set log level
+		core := &outputCore{LevelEnabler: enabler, encoder: encoder, writer: output.writer}
+		if output.stream != nil {
+			stream := output.stream
+			if async := options.Async; async != nil {
+				writer := newAsyncWriter(stream, async.QueueSize, async.BufferSize, async.FlushInterval)
+				l.writers = append(l.writers, writer)
+				stream = writer
+				// Errors often precede an exit, so they are not left in the queue
+				core.syncErrors = true
+			}
+			core.writer = streamWriter{stream}
+		}
+		l.outputs = append(l.outputs, core.writer)
+		cores = append(cores, core)
+	}
+
+	logger, err := config.Build(zap.WrapCore(func(zapcore.Core) zapcore.Core {
+		core := zapcore.NewTee(cores...)
+		if sampling := options.Sampling; sampling != nil {
+			sampler := zapcore.NewSamplerWithOptions(core, sampling.Tick, sampling.Initial, sampling.Thereafter,
+				zapcore.SamplerHook(func(_ zapcore.Entry, decision zapcore.SamplingDecision) {
+					if decision&zapcore.LogDropped != 0 {
+						l.sampled.Add(1)
+					}
+				}))
+			core = &sampledCore{Core: core, sampler: sampler}
+		}
+		return core
+	}))
+	if err != nil {
+		return nil, err
+	}
+
+	l.logger = logger.Sugar()
+	return l, nil
+}
+
+// parseLevel returns the level named, info when unknown
+func parseLevel(level string) zapcore.Level {
 	switch level {
 	case "debug":
-		config.Level.SetLevel(zapcore.DebugLevel)
-	case "info":
-		config.Level.SetLevel(zapcore.InfoLevel)
+		return zapcore.DebugLevel
 	case "warn":
-		config.Level.SetLevel(zapcore.WarnLevel)
+		return zapcore.WarnLevel
 	case "error":
-		config.Level.SetLevel(zapcore.ErrorLevel)
+		return zapcore.ErrorLevel
 	default:
-		config.Level.SetLevel(zapcore.InfoLevel)
+		return zapcore.InfoLevel
 	}
+}
 
-	logger, err := config.Build()
-	if err != nil {
-		return nil, err
+// Drops returns the number of lines sampled out, dropped from a full async queue or that an
+// output failed to deliver
+func (l *ZapLogger) Drops() Drops {
+	drops := Drops{Sampled: l.sampled.Load()}
+	for _, writer := range l.writers {
+		drops.Overflowed += writer.dropped.Load()
+	}
+	for _, output := range l.outputs {
+		if counter, ok := output.(failureCounter); ok {
+			drops.Failed += counter.Failed()
+		}
 	}
+	return drops
+}
 
-	return &ZapLogger{
-		logger: logger.Sugar(),
-	}, nil
+// Sync writes any buffered lines to the outputs
+func (l *ZapLogger) Sync() error {
+	return l.logger.Sync()
 }
 
 // Debug logs a debug message
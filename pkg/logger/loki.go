@@ -0,0 +1,147 @@
+package logger
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"go.uber.org/zap/zapcore"
+)
+
+// lokiMaxPendingBatches bounds the lines held while Loki is slow or down, in batches
+const lokiMaxPendingBatches = 10
+
+// lokiWriter batches lines in the background and pushes them to Loki, in one stream per level.
+// Lines of a failed push, or logged while the backlog is full, are dropped and counted.
+type lokiWriter struct {
+	url       string
+	labels    map[string]string
+	batchSize int
+	client    *http.Client
+
+	mu      sync.Mutex
+	pending map[zapcore.Level][][2]string
+	count   int
+	full    chan struct{}
+	pushMu  sync.Mutex
+	failed  atomic.Int64
+}
+
+// LokiOutput pushes lines to a Loki push endpoint, such as http://loki:3100/loki/api/v1/push,
+// labelled with the given labels and their level. Lines are pushed every flushInterval, or once
+// batchSize are pending.
+func LokiOutput(url string, labels map[string]string, batchSize int, flushInterval time.Duration, timeout time.Duration) Output {
+	w := &lokiWriter{
+		url:       url,
+		labels:    labels,
+		batchSize: batchSize,
+		client:    &http.Client{Timeout: timeout},
+		pending:   map[zapcore.Level][][2]string{},
+		full:      make(chan struct{}, 1),
+	}
+	go w.run(flushInterval)
+	return Output{writer: w}
+}
+
+func (w *lokiWriter) WriteEntry(entry zapcore.Entry, line []byte) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.count >= w.batchSize*lokiMaxPendingBatches {
+		w.failed.Add(1)
+		return nil
+	}
+	value := [2]string{strconv.FormatInt(entry.Time.UnixNano(), 10), string(bytes.TrimSuffix(line, []byte("\n")))}
+	w.pending[entry.Level] = append(w.pending[entry.Level], value)
+	w.count++
+	if w.count >= w.batchSize {
+		select {
+		case w.full <- struct{}{}:
+		default:
+		}
+	}
+	return nil
+}
+
+// Sync pushes the pending lines
+func (w *lokiWriter) Sync() error {
+	return w.push()
+}
+
+// Failed returns the number of lines dropped
+func (w *lokiWriter) Failed() int64 {
+	return w.failed.Load()
+}
+
+func (w *lokiWriter) run(flushInterval time.Duration) {
+	ticker := time.NewTicker(flushInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+		case <-w.full:
+		}
+		w.push()
+	}
+}
+
+type lokiStream struct {
+	Stream map[string]string `json:"stream"`
+	Values [][2]string       `json:"values"`
+}
+
+// push sends the pending lines in a single request
+func (w *lokiWriter) push() error {
+	w.pushMu.Lock()
+	defer w.pushMu.Unlock()
+
+	w.mu.Lock()
+	pending, count := w.pending, w.count
+	w.pending, w.count = map[zapcore.Level][][2]string{}, 0
+	w.mu.Unlock()
+	if count == 0 {
+		return nil
+	}
+
+	streams := make([]lokiStream, 0, len(pending))
+	for level, values := range pending {
+		labels := make(map[string]string, len(w.labels)+1)
+		for name, value := range w.labels {
+			labels[name] = value
+		}
+		labels["level"] = level.String()
+		streams = append(streams, lokiStream{Stream: labels, Values: values})
+	}
+	err := w.send(streams)
+	if err != nil {
+		w.failed.Add(int64(count))
+	}
+	return err
+}
+
+func (w *lokiWriter) send(streams []lokiStream) error {
+	body, err := json.Marshal(map[string]interface{}{"streams": streams})
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(context.Background(), http.MethodPost, w.url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := w.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to push logs to Loki: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("loki answered with status %d", resp.StatusCode)
+	}
+	return nil
+}
@@ -0,0 +1,103 @@
+package logger
+
+import (
+	"go.uber.org/zap/zapcore"
+	"gopkg.in/natefinch/lumberjack.v2"
+)
+
+// EntryWriter writes encoded log lines to an output that needs the entry they encode, such as
+// its level or time
+type EntryWriter interface {
+	WriteEntry(entry zapcore.Entry, line []byte) error
+	Sync() error
+}
+
+// failureCounter is implemented by outputs that can fail to deliver lines without failing the write
+type failureCounter interface {
+	Failed() int64
+}
+
+// Output is a destination of log lines, created by StreamOutput, FileOutput, SyslogOutput or
+// LokiOutput
+type Output struct {
+	// Level is the lowest level written to the output, the logger's level when empty
+	Level string
+	// Console writes lines in the human-readable development format instead of JSON
+	Console bool
+
+	stream zapcore.WriteSyncer
+	writer EntryWriter
+}
+
+// StreamOutput writes lines to a stream such as os.Stdout
+func StreamOutput(stream zapcore.WriteSyncer) Output {
+	return Output{stream: zapcore.Lock(stream)}
+}
+
+// FileOutput writes lines to a file, which is rotated once it reaches maxSizeMB. At most
+// maxBackups rotated files, none older than maxAgeDays, are kept; zero keeps them all.
+func FileOutput(path string, maxSizeMB int, maxBackups int, maxAgeDays int, compress bool) Output {
+	return Output{stream: zapcore.AddSync(&lumberjack.Logger{
+		Filename:   path,
+		MaxSize:    maxSizeMB,
+		MaxBackups: maxBackups,
+		MaxAge:     maxAgeDays,
+		Compress:   compress,
+	})}
+}
+
+// streamWriter writes lines to a stream regardless of their entry
+type streamWriter struct {
+	zapcore.WriteSyncer
+}
+
+func (w streamWriter) WriteEntry(entry zapcore.Entry, line []byte) error {
+	_, err := w.Write(line)
+	return err
+}
+
+// outputCore encodes the entries at or above its level and writes them to an output
+type outputCore struct {
+	zapcore.LevelEnabler
+	encoder zapcore.Encoder
+	writer  EntryWriter
+	// syncErrors syncs the output after each error, for outputs that queue lines
+	syncErrors bool
+}
+
+func (c *outputCore) With(fields []zapcore.Field) zapcore.Core {
+	clone := *c
+	clone.encoder = c.encoder.Clone()
+	for _, field := range fields {
+		field.AddTo(clone.encoder)
+	}
+	return &clone
+}
+
+func (c *outputCore) Check(entry zapcore.Entry, checked *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if c.Enabled(entry.Level) {
+		return checked.AddCore(entry, c)
+	}
+	return checked
+}
+
+func (c *outputCore) Write(entry zapcore.Entry, fields []zapcore.Field) error {
+	buf, err := c.encoder.EncodeEntry(entry, fields)
+	if err != nil {
+		return err
+	}
+	err = c.writer.WriteEntry(entry, buf.Bytes())
+	buf.Free()
+	if err != nil {
+		return err
+	}
+	if entry.Level > zapcore.ErrorLevel || (c.syncErrors && entry.Level == zapcore.ErrorLevel) {
+		// Syncing a terminal fails on some platforms, which is not worth reporting
+		c.Sync()
+	}
+	return nil
+}
+
+func (c *outputCore) Sync() error {
+	return c.writer.Sync()
+}
@@ -0,0 +1,148 @@
+package logger
+
+import (
+	"encoding/json"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zapcore"
+)
+
+// bufferStream is an in-memory stream output
+type bufferStream struct {
+	strings.Builder
+}
+
+func (s *bufferStream) Sync() error {
+	return nil
+}
+
+func TestZapLogger_OutputLevels(t *testing.T) {
+	info, debug := &bufferStream{}, &bufferStream{}
+	debugOutput := StreamOutput(debug)
+	debugOutput.Level = "debug"
+	l, err := NewZapLogger("info", false, Options{Outputs: []Output{StreamOutput(info), debugOutput}})
+	require.NoError(t, err)
+
+	l.Debug("Cache lookup", "key", "orders")
+	l.Info("Request completed", "status", 200)
+	require.NoError(t, l.Sync())
+
+	// Each output writes the lines at or above its own level
+	assert.Equal(t, 1, strings.Count(info.String(), "\n"))
+	assert.Contains(t, info.String(), `"msg":"Request completed","status":200`)
+	assert.Equal(t, 2, strings.Count(debug.String(), "\n"))
+	assert.Contains(t, debug.String(), `"msg":"Cache lookup","key":"orders"`)
+}
+
+func TestFileOutput_Rotates(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "gateway.log")
+	l, err := NewZapLogger("info", false, Options{Outputs: []Output{FileOutput(path, 1, 2, 0, false)}})
+	require.NoError(t, err)
+
+	// Lines of over a megabyte in total exceed maxSizeMB
+	payload := strings.Repeat("x", 1024)
+	for i := 0; i < 1100; i++ {
+		l.Info("Request completed", "payload", payload)
+	}
+	require.NoError(t, l.Sync())
+
+	entries, err := os.ReadDir(dir)
+	require.NoError(t, err)
+	assert.Len(t, entries, 2, "the log file and one rotated backup")
+	current, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Contains(t, string(current), `"msg":"Request completed"`)
+}
+
+func TestSyslogOutput(t *testing.T) {
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer conn.Close()
+
+	output, err := SyslogOutput("udp://"+conn.LocalAddr().String(), "gateway")
+	require.NoError(t, err)
+	l, err := NewZapLogger("info", false, Options{Outputs: []Output{output}})
+	require.NoError(t, err)
+
+	l.Warn("Upstream slow", "service", "orders")
+	conn.SetReadDeadline(time.Now().Add(2 * time.Second))
+	buf := make([]byte, 4096)
+	n, _, err := conn.ReadFrom(buf)
+	require.NoError(t, err)
+
+	// daemon facility (3) * 8 + warning severity (4)
+	message := string(buf[:n])
+	assert.True(t, strings.HasPrefix(message, "<28>"), message)
+	assert.Contains(t, message, " gateway[")
+	assert.Contains(t, message, `"msg":"Upstream slow","service":"orders"}`)
+	assert.True(t, strings.HasSuffix(message, "}\n"), message)
+
+	_, err = SyslogOutput("http://syslog:514", "")
+	assert.Error(t, err)
+}
+
+func TestLokiOutput(t *testing.T) {
+	pushes := make(chan map[string]interface{}, 10)
+	fail := false
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if fail {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		assert.Equal(t, "application/json", r.Header.Get("Content-Type"))
+		body, _ := io.ReadAll(r.Body)
+		var push map[string]interface{}
+		assert.NoError(t, json.Unmarshal(body, &push))
+		pushes <- push
+	}))
+	defer server.Close()
+
+	output := LokiOutput(server.URL+"/loki/api/v1/push", map[string]string{"app": "api-gateway"}, 100, time.Hour, time.Second)
+	l, err := NewZapLogger("info", false, Options{Outputs: []Output{output}})
+	require.NoError(t, err)
+
+	l.Info("Request completed")
+	l.Info("Request completed")
+	l.Error("Upstream failed")
+	require.NoError(t, l.Sync())
+
+	push := <-pushes
+	streams := push["streams"].([]interface{})
+	require.Len(t, streams, 2)
+	lines := map[string]int{}
+	for _, s := range streams {
+		stream := s.(map[string]interface{})
+		labels := stream["stream"].(map[string]interface{})
+		assert.Equal(t, "api-gateway", labels["app"])
+		values := stream["values"].([]interface{})
+		lines[labels["level"].(string)] = len(values)
+		value := values[0].([]interface{})
+		assert.Regexp(t, `^\d{19}$`, value[0])
+		assert.NotContains(t, value[1], "\n")
+	}
+	assert.Equal(t, map[string]int{"info": 2, "error": 1}, lines)
+
+	// Lines of a failed push are dropped and counted
+	fail = true
+	l.Info("Request completed")
+	assert.Error(t, l.Sync())
+	assert.Equal(t, int64(1), l.Drops().Failed)
+}
+
+func TestSeverity(t *testing.T) {
+	assert.Equal(t, 7, severity(zapcore.DebugLevel))
+	assert.Equal(t, 6, severity(zapcore.InfoLevel))
+	assert.Equal(t, 3, severity(zapcore.ErrorLevel))
+	assert.Equal(t, 2, severity(zapcore.FatalLevel))
+}
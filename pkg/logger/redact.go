@@ -0,0 +1,174 @@
+package logger
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"regexp"
+	"strings"
+)
+
+// DefaultRedactionMask replaces redacted values in log output
+const DefaultRedactionMask = "[REDACTED]"
+
+// bearerTokenPattern matches credentials embedded in free-form strings such as error messages
+var bearerTokenPattern = regexp.MustCompile(`(?i)(bearer|basic)\s+[A-Za-z0-9\-._~+/]+=*`)
+
+// Redactor masks sensitive headers and JSON body fields before they reach a log sink
+type Redactor struct {
+	headers    map[string]bool
+	bodyFields map[string]bool
+	mask       string
+}
+
+// NewRedactor creates a new Redactor for the given header names and JSON body field names
+func NewRedactor(headers []string, bodyFields []string, mask string) *Redactor {
+	if mask == "" {
+		mask = DefaultRedactionMask
+	}
+
+	r := &Redactor{
+		headers:    make(map[string]bool, len(headers)),
+		bodyFields: make(map[string]bool, len(bodyFields)),
+		mask:       mask,
+	}
+	for _, h := range headers {
+		r.headers[strings.ToLower(h)] = true
+	}
+	for _, f := range bodyFields {
+		r.bodyFields[strings.ToLower(f)] = true
+	}
+	return r
+}
+
+// RedactHeaders returns a copy of the headers with sensitive values masked
+func (r *Redactor) RedactHeaders(headers map[string][]string) map[string][]string {
+	redacted := make(map[string][]string, len(headers))
+	for key, values := range headers {
+		if r.headers[strings.ToLower(key)] {
+			redacted[key] = []string{r.mask}
+			continue
+		}
+		redacted[key] = values
+	}
+	return redacted
+}
+
+// RedactBody masks the configured fields of a JSON body. Non-JSON bodies are returned unchanged.
+func (r *Redactor) RedactBody(body []byte) []byte {
+	var payload interface{}
+	if err := json.Unmarshal(body, &payload); err != nil {
+		return body
+	}
+
+	redacted, err := json.Marshal(r.redactJSON(payload))
+	if err != nil {
+		return body
+	}
+	return redacted
+}
+
+// RedactString masks inline credentials such as bearer tokens
+func (r *Redactor) RedactString(s string) string {
+	return bearerTokenPattern.ReplaceAllString(s, "$1 "+r.mask)
+}
+
+func (r *Redactor) redactJSON(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for key, field := range v {
+			if r.bodyFields[strings.ToLower(key)] {
+				v[key] = r.mask
+				continue
+			}
+			v[key] = r.redactJSON(field)
+		}
+		return v
+	case []interface{}:
+		for i, item := range v {
+			v[i] = r.redactJSON(item)
+		}
+		return v
+	default:
+		return v
+	}
+}
+
+// redactKeyValue masks a single structured logging field
+func (r *Redactor) redactKeyValue(key string, value interface{}) interface{} {
+	lowerKey := strings.ToLower(key)
+	if r.headers[lowerKey] || r.bodyFields[lowerKey] {
+		return r.mask
+	}
+
+	switch v := value.(type) {
+	case http.Header:
+		return r.RedactHeaders(v)
+	case map[string][]string:
+		return r.RedactHeaders(v)
+	case []byte:
+		return string(r.RedactBody(v))
+	case string:
+		return r.RedactString(v)
+	case error:
+		return r.RedactString(v.Error())
+	case fmt.Stringer:
+		return r.RedactString(v.String())
+	default:
+		return v
+	}
+}
+
+// RedactingLogger wraps a Logger and scrubs sensitive data from every log line
+type RedactingLogger struct {
+	next     Logger
+	redactor *Redactor
+}
+
+// NewRedactingLogger creates a new RedactingLogger instance
+func NewRedactingLogger(next Logger, redactor *Redactor) *RedactingLogger {
+	return &RedactingLogger{
+		next:     next,
+		redactor: redactor,
+	}
+}
+
+// Debug logs a debug message
+func (l *RedactingLogger) Debug(msg string, keysAndValues ...interface{}) {
+	l.next.Debug(msg, l.scrub(keysAndValues)...)
+}
+
+// Info logs an info message
+func (l *RedactingLogger) Info(msg string, keysAndValues ...interface{}) {
+	l.next.Info(msg, l.scrub(keysAndValues)...)
+}
+
+// Warn logs a warning message
+func (l *RedactingLogger) Warn(msg string, keysAndValues ...interface{}) {
+	l.next.Warn(msg, l.scrub(keysAndValues)...)
+}
+
+// Error logs an error message
+func (l *RedactingLogger) Error(msg string, keysAndValues ...interface{}) {
+	l.next.Error(msg, l.scrub(keysAndValues)...)
+}
+
+// Fatal logs a fatal message and exits
+func (l *RedactingLogger) Fatal(msg string, keysAndValues ...interface{}) {
+	l.next.Fatal(msg, l.scrub(keysAndValues)...)
+}
+
+// scrub redacts the values of a key/value list without modifying the caller's slice
+func (l *RedactingLogger) scrub(keysAndValues []interface{}) []interface{} {
+	scrubbed := make([]interface{}, len(keysAndValues))
+	copy(scrubbed, keysAndValues)
+
+	for i := 0; i+1 < len(scrubbed); i += 2 {
+		key, ok := scrubbed[i].(string)
+		if !ok {
+			continue
+		}
+		scrubbed[i+1] = l.redactor.redactKeyValue(key, scrubbed[i+1])
+	}
+	return scrubbed
+}
@@ -0,0 +1,82 @@
+package logger
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// recordingLogger captures the key/value pairs passed to it
+type recordingLogger struct {
+	keysAndValues []interface{}
+}
+
+func (l *recordingLogger) Debug(msg string, keysAndValues ...interface{}) {
+	l.keysAndValues = keysAndValues
+}
+
+func (l *recordingLogger) Info(msg string, keysAndValues ...interface{}) {
+	l.keysAndValues = keysAndValues
+}
+
+func (l *recordingLogger) Warn(msg string, keysAndValues ...interface{}) {
+	l.keysAndValues = keysAndValues
+}
+
+func (l *recordingLogger) Error(msg string, keysAndValues ...interface{}) {
+	l.keysAndValues = keysAndValues
+}
+
+func (l *recordingLogger) Fatal(msg string, keysAndValues ...interface{}) {
+	l.keysAndValues = keysAndValues
+}
+
+func TestRedactor_RedactHeaders(t *testing.T) {
+	redactor := NewRedactor([]string{"authorization", "Cookie"}, nil, "")
+
+	headers := http.Header{
+		"Authorization": {"Bearer abc.def.ghi"},
+		"Cookie":        {"session=123"},
+		"Content-Type":  {"application/json"},
+	}
+
+	redacted := redactor.RedactHeaders(headers)
+
+	assert.Equal(t, []string{DefaultRedactionMask}, redacted["Authorization"])
+	assert.Equal(t, []string{DefaultRedactionMask}, redacted["Cookie"])
+	assert.Equal(t, []string{"application/json"}, redacted["Content-Type"])
+	// The original headers must not be modified
+	assert.Equal(t, "Bearer abc.def.ghi", headers.Get("Authorization"))
+}
+
+func TestRedactor_RedactBody(t *testing.T) {
+	redactor := NewRedactor(nil, []string{"password", "apiKey"}, "***")
+
+	body := []byte(`{"user":"alice","password":"hunter2","nested":[{"apikey":"k-1"}]}`)
+	redacted := redactor.RedactBody(body)
+
+	assert.JSONEq(t, `{"user":"alice","password":"***","nested":[{"apikey":"***"}]}`, string(redacted))
+
+	// Non-JSON bodies are left untouched
+	assert.Equal(t, []byte("plain text"), redactor.RedactBody([]byte("plain text")))
+}
+
+func TestRedactingLogger(t *testing.T) {
+	next := &recordingLogger{}
+	redactor := NewRedactor([]string{"Authorization"}, []string{"token"}, "")
+	log := NewRedactingLogger(next, redactor)
+
+	log.Error("Request failed",
+		"error", errors.New("upstream rejected Bearer eyJhbGciOi.payload.sig"),
+		"token", "secret-value",
+		"headers", http.Header{"Authorization": {"Bearer xyz"}},
+		"path", "/api/v1/users",
+	)
+
+	assert.Equal(t, "upstream rejected Bearer "+DefaultRedactionMask, next.keysAndValues[1])
+	assert.Equal(t, DefaultRedactionMask, next.keysAndValues[3])
+	assert.Equal(t, []string{DefaultRedactionMask}, next.keysAndValues[5].(map[string][]string)["Authorization"])
+	assert.Equal(t, "/api/v1/users", next.keysAndValues[7])
+}
@@ -0,0 +1,100 @@
+package logger
+
+import (
+	"bufio"
+	"sync/atomic"
+	"time"
+
+	"go.uber.org/zap/zapcore"
+)
+
+// asyncWriter queues encoded lines and writes them to the output from a background goroutine
+// through a buffer, so a slow output never holds up a request. Lines written while the queue is
+// full are dropped and counted.
+type asyncWriter struct {
+	output  zapcore.WriteSyncer
+	buffer  *bufio.Writer
+	lines   chan []byte
+	syncs   chan chan error
+	dropped atomic.Int64
+}
+
+func newAsyncWriter(output zapcore.WriteSyncer, queueSize int, bufferSize int, flushInterval time.Duration) *asyncWriter {
+	w := &asyncWriter{
+		output: output,
+		buffer: bufio.NewWriterSize(output, bufferSize),
+		lines:  make(chan []byte, queueSize),
+		syncs:  make(chan chan error),
+	}
+	go w.run(flushInterval)
+	return w
+}
+
+// Write queues a line. The encoder reuses p, so it is copied.
+func (w *asyncWriter) Write(p []byte) (int, error) {
+	line := make([]byte, len(p))
+	copy(line, p)
+	select {
+	case w.lines <- line:
+	default:
+		w.dropped.Add(1)
+	}
+	return len(p), nil
+}
+
+// Sync waits for the lines queued so far to be written and syncs the output
+func (w *asyncWriter) Sync() error {
+	done := make(chan error)
+	w.syncs <- done
+	return <-done
+}
+
+func (w *asyncWriter) run(flushInterval time.Duration) {
+	ticker := time.NewTicker(flushInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case line := <-w.lines:
+			w.buffer.Write(line)
+		case <-ticker.C:
+			w.buffer.Flush()
+		case done := <-w.syncs:
+			w.drain()
+			err := w.buffer.Flush()
+			if syncErr := w.output.Sync(); err == nil {
+				err = syncErr
+			}
+			done <- err
+		}
+	}
+}
+
+// drain writes the queued lines to the buffer
+func (w *asyncWriter) drain() {
+	for {
+		select {
+		case line := <-w.lines:
+			w.buffer.Write(line)
+		default:
+			return
+		}
+	}
+}
+
+// sampledCore sends debug and info lines through a sampler, and warnings and errors straight to
+// the core
+type sampledCore struct {
+	zapcore.Core
+	sampler zapcore.Core
+}
+
+func (c *sampledCore) With(fields []zapcore.Field) zapcore.Core {
+	return &sampledCore{Core: c.Core.With(fields), sampler: c.sampler.With(fields)}
+}
+
+func (c *sampledCore) Check(entry zapcore.Entry, checked *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if entry.Level >= zapcore.WarnLevel {
+		return c.Core.Check(entry, checked)
+	}
+	return c.sampler.Check(entry, checked)
+}
@@ -0,0 +1,87 @@
+package logger
+
+import (
+	"bytes"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+// gatedOutput records the writes it receives, blocking each until released
+type gatedOutput struct {
+	mu       sync.Mutex
+	written  bytes.Buffer
+	received chan struct{}
+	release  chan struct{}
+}
+
+func (o *gatedOutput) Write(p []byte) (int, error) {
+	o.received <- struct{}{}
+	<-o.release
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	return o.written.Write(p)
+}
+
+func (o *gatedOutput) Sync() error {
+	return nil
+}
+
+func TestAsyncWriter_DropsWhenQueueIsFull(t *testing.T) {
+	output := &gatedOutput{received: make(chan struct{}, 10), release: make(chan struct{})}
+	// Lines larger than the buffer are written straight to the output
+	w := newAsyncWriter(output, 1, 1, time.Hour)
+
+	w.Write([]byte("first\n"))
+	<-output.received
+	// The first line holds up the output, so the second fills the queue and the third is dropped
+	w.Write([]byte("second\n"))
+	w.Write([]byte("third\n"))
+	assert.Equal(t, int64(1), w.dropped.Load())
+
+	close(output.release)
+	assert.NoError(t, w.Sync())
+	assert.Equal(t, "first\nsecond\n", output.written.String())
+}
+
+func TestAsyncWriter_SyncFlushesBuffer(t *testing.T) {
+	output := &gatedOutput{received: make(chan struct{}, 10), release: make(chan struct{})}
+	close(output.release)
+	w := newAsyncWriter(output, 10, 4096, time.Hour)
+
+	line := []byte("line\n")
+	w.Write(line)
+	// The encoder reuses its buffer once Write returns
+	copy(line, "xxxx\n")
+	assert.NoError(t, w.Sync())
+	assert.Equal(t, "line\n", output.written.String())
+}
+
+func TestSampledCore_SparesWarnings(t *testing.T) {
+	core, logs := observer.New(zapcore.DebugLevel)
+	var sampled int
+	sampler := zapcore.NewSamplerWithOptions(core, time.Hour, 2, 0, zapcore.SamplerHook(func(_ zapcore.Entry, decision zapcore.SamplingDecision) {
+		if decision&zapcore.LogDropped != 0 {
+			sampled++
+		}
+	}))
+	c := (&sampledCore{Core: core, sampler: sampler}).With([]zapcore.Field{{Key: "pool", Type: zapcore.StringType, String: "access"}})
+
+	for i := 0; i < 5; i++ {
+		for _, level := range []zapcore.Level{zapcore.InfoLevel, zapcore.WarnLevel} {
+			entry := zapcore.Entry{Level: level, Message: "Request completed"}
+			if checked := c.Check(entry, nil); checked != nil {
+				checked.Write()
+			}
+		}
+	}
+
+	assert.Equal(t, 2, logs.FilterLevelExact(zapcore.InfoLevel).Len())
+	assert.Equal(t, 5, logs.FilterLevelExact(zapcore.WarnLevel).Len())
+	assert.Equal(t, 3, sampled)
+	assert.Equal(t, "access", logs.All()[0].ContextMap()["pool"])
+}
@@ -0,0 +1,132 @@
+package logger
+
+import (
+	"bytes"
+	"fmt"
+	"net"
+	"net/url"
+	"os"
+	"sync"
+	"time"
+
+	"go.uber.org/zap/zapcore"
+)
+
+// syslogFacility is the facility of the messages, daemon as the gateway is a system service
+const syslogFacility = 3
+
+// localSyslogPaths are the sockets of the local syslog daemon on common platforms
+var localSyslogPaths = []string{"/dev/log", "/var/run/syslog", "/var/run/log"}
+
+// syslogWriter sends each line as a syslog message whose severity follows the entry's level.
+// Messages to the local daemon use its short format, those to a remote server carry the
+// timestamp and hostname of RFC 3164, as log/syslog does.
+type syslogWriter struct {
+	network  string
+	address  string
+	tag      string
+	hostname string
+
+	mu   sync.Mutex
+	conn net.Conn
+}
+
+// SyslogOutput sends lines to a syslog server at an address such as udp://syslog:514,
+// tcp://syslog:601 or unix:///dev/log, or to the local syslog daemon when the address is empty.
+// The tag names the process in the messages, api-gateway when empty.
+func SyslogOutput(address string, tag string) (Output, error) {
+	if tag == "" {
+		tag = "api-gateway"
+	}
+	w := &syslogWriter{tag: tag}
+	if address != "" {
+		u, err := url.Parse(address)
+		if err != nil {
+			return Output{}, fmt.Errorf("invalid syslog address %q: %w", address, err)
+		}
+		switch u.Scheme {
+		case "udp", "tcp":
+			w.network, w.address = u.Scheme, u.Host
+			w.hostname, _ = os.Hostname()
+		case "unix", "unixgram":
+			w.network, w.address = u.Scheme, u.Path
+		default:
+			return Output{}, fmt.Errorf("invalid syslog address %q: scheme must be udp, tcp, unix or unixgram", address)
+		}
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if err := w.connect(); err != nil {
+		return Output{}, err
+	}
+	return Output{writer: w}, nil
+}
+
+// connect dials the server, trying the local daemon's sockets when no address is set
+func (w *syslogWriter) connect() error {
+	if w.address != "" {
+		conn, err := net.Dial(w.network, w.address)
+		if err != nil {
+			return fmt.Errorf("failed to connect to syslog: %w", err)
+		}
+		w.conn = conn
+		return nil
+	}
+	for _, path := range localSyslogPaths {
+		for _, network := range []string{"unixgram", "unix"} {
+			if conn, err := net.Dial(network, path); err == nil {
+				w.conn = conn
+				return nil
+			}
+		}
+	}
+	return fmt.Errorf("failed to connect to syslog: no local syslog daemon found")
+}
+
+// severity maps a level to a syslog severity
+func severity(level zapcore.Level) int {
+	switch {
+	case level >= zapcore.DPanicLevel:
+		return 2 // critical
+	case level == zapcore.ErrorLevel:
+		return 3 // error
+	case level == zapcore.WarnLevel:
+		return 4 // warning
+	case level == zapcore.InfoLevel:
+		return 6 // informational
+	default:
+		return 7 // debug
+	}
+}
+
+func (w *syslogWriter) WriteEntry(entry zapcore.Entry, line []byte) error {
+	priority := syslogFacility*8 + severity(entry.Level)
+	line = bytes.TrimSuffix(line, []byte("\n"))
+	var message string
+	if w.hostname != "" {
+		message = fmt.Sprintf("<%d>%s %s %s[%d]: %s\n", priority, entry.Time.Format(time.RFC3339), w.hostname, w.tag, os.Getpid(), line)
+	} else {
+		message = fmt.Sprintf("<%d>%s %s[%d]: %s\n", priority, entry.Time.Format(time.Stamp), w.tag, os.Getpid(), line)
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.conn != nil {
+		if _, err := w.conn.Write([]byte(message)); err == nil {
+			return nil
+		}
+		w.conn.Close()
+		w.conn = nil
+	}
+	// The server may have restarted, so the connection is opened again once
+	if err := w.connect(); err != nil {
+		return err
+	}
+	_, err := w.conn.Write([]byte(message))
+	return err
+}
+
+func (w *syslogWriter) Sync() error {
+	return nil
+}
@@ -0,0 +1,72 @@
+package secrets
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"api-gateway-sample/pkg/sigv4"
+)
+
+// AWSSecretsManagerProvider reads secrets from a JSON secret stored in AWS Secrets Manager
+type AWSSecretsManagerProvider struct {
+	region      string
+	secretID    string
+	credentials sigv4.Credentials
+	endpoint    string
+	client      *http.Client
+}
+
+// NewAWSSecretsManagerProvider creates a new AWSSecretsManagerProvider instance
+func NewAWSSecretsManagerProvider(region, secretID string, credentials sigv4.Credentials, timeout time.Duration) *AWSSecretsManagerProvider {
+	return &AWSSecretsManagerProvider{
+		region:      region,
+		secretID:    secretID,
+		credentials: credentials,
+		endpoint:    fmt.Sprintf("https://secretsmanager.%s.amazonaws.com/", region),
+		client:      &http.Client{Timeout: timeout},
+	}
+}
+
+// Fetch returns the key/value pairs stored in the configured secret
+func (p *AWSSecretsManagerProvider) Fetch(ctx context.Context) (map[string]string, error) {
+	payload, err := json.Marshal(map[string]string{"SecretId": p.secretID})
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal secrets manager request: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, p.endpoint, bytes.NewReader(payload))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create secrets manager request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/x-amz-json-1.1")
+	req.Header.Set("X-Amz-Target", "secretsmanager.GetSecretValue")
+	sigv4.Sign(req, payload, p.credentials, p.region, "secretsmanager", time.Now())
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read secret: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("secrets manager returned status %d", resp.StatusCode)
+	}
+
+	var body struct {
+		SecretString string `json:"SecretString"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return nil, fmt.Errorf("failed to decode secrets manager response: %w", err)
+	}
+
+	var data map[string]interface{}
+	if err := json.Unmarshal([]byte(body.SecretString), &data); err != nil {
+		return nil, fmt.Errorf("secret %s is not a JSON object: %w", p.secretID, err)
+	}
+
+	return stringValues(data), nil
+}
@@ -0,0 +1,52 @@
+package secrets
+
+import (
+	"fmt"
+
+	"api-gateway-sample/pkg/config"
+	"api-gateway-sample/pkg/sigv4"
+)
+
+// envPrefix matches the prefix used for configuration environment variables
+const envPrefix = "API_GATEWAY_SECRET"
+
+// Names lists the secrets managed by the gateway
+var Names = []string{AuthSecretKey, DatabasePassword, RedisPassword, MailPassword}
+
+// NewProvider creates the Provider selected by the configuration
+func NewProvider(cfg config.SecretsConfig) (Provider, error) {
+	names := append(append([]string{}, Names...), cfg.Names...)
+	switch cfg.Provider {
+	case "", "env":
+		return NewEnvProvider(envPrefix, names), nil
+	case "file":
+		return NewFileProvider(cfg.File.Directory, names), nil
+	case "vault":
+		return NewVaultProvider(cfg.Vault.Address, cfg.Vault.Token, cfg.Vault.MountPath, cfg.Vault.Path, cfg.Timeout), nil
+	case "aws":
+		credentials := sigv4.Credentials{
+			AccessKeyID:     cfg.AWS.AccessKeyID,
+			SecretAccessKey: cfg.AWS.SecretAccessKey,
+			SessionToken:    cfg.AWS.SessionToken,
+		}
+		return NewAWSSecretsManagerProvider(cfg.AWS.Region, cfg.AWS.SecretID, credentials, cfg.Timeout), nil
+	default:
+		return nil, fmt.Errorf("unknown secrets provider: %s", cfg.Provider)
+	}
+}
+
+// Apply overrides the configuration values with the secrets held by the manager
+func Apply(cfg *config.Config, manager *Manager) {
+	if value, ok := manager.Get(AuthSecretKey); ok {
+		cfg.Auth.SecretKey = value
+	}
+	if value, ok := manager.Get(DatabasePassword); ok {
+		cfg.Database.Password = value
+	}
+	if value, ok := manager.Get(RedisPassword); ok {
+		cfg.Redis.Password = value
+	}
+	if value, ok := manager.Get(MailPassword); ok {
+		cfg.Mail.Password = value
+	}
+}
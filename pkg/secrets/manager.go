@@ -0,0 +1,106 @@
+package secrets
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"api-gateway-sample/pkg/logger"
+)
+
+// ChangeHandler is invoked with the new value when a secret changes
+type ChangeHandler func(value string)
+
+// Manager caches secrets from a Provider and periodically refreshes them,
+// notifying subscribers when a value rotates
+type Manager struct {
+	provider        Provider
+	refreshInterval time.Duration
+	logger          logger.Logger
+
+	mu       sync.RWMutex
+	values   map[string]string
+	handlers map[string][]ChangeHandler
+}
+
+// NewManager creates a new Manager instance
+func NewManager(provider Provider, refreshInterval time.Duration, logger logger.Logger) *Manager {
+	return &Manager{
+		provider:        provider,
+		refreshInterval: refreshInterval,
+		logger:          logger,
+		values:          make(map[string]string),
+		handlers:        make(map[string][]ChangeHandler),
+	}
+}
+
+// Get returns the cached value of a secret
+func (m *Manager) Get(name string) (string, bool) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	value, ok := m.values[name]
+	return value, ok
+}
+
+// OnChange registers a handler called whenever the named secret changes
+func (m *Manager) OnChange(name string, handler ChangeHandler) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.handlers[name] = append(m.handlers[name], handler)
+}
+
+// Refresh fetches the secrets from the provider and notifies subscribers of changed values
+func (m *Manager) Refresh(ctx context.Context) error {
+	values, err := m.provider.Fetch(ctx)
+	if err != nil {
+		return err
+	}
+
+	type change struct {
+		handlers []ChangeHandler
+		value    string
+	}
+	var changes []change
+
+	m.mu.Lock()
+	for name, value := range values {
+		if current, ok := m.values[name]; ok && current == value {
+			continue
+		}
+		m.values[name] = value
+		changes = append(changes, change{handlers: m.handlers[name], value: value})
+	}
+	m.mu.Unlock()
+
+	// Handlers run outside the lock so they may safely call back into the manager
+	for _, c := range changes {
+		for _, handler := range c.handlers {
+			handler(c.value)
+		}
+	}
+
+	return nil
+}
+
+// Start refreshes the secrets periodically until the context is cancelled
+func (m *Manager) Start(ctx context.Context) {
+	if m.refreshInterval <= 0 {
+		return
+	}
+
+	go func() {
+		ticker := time.NewTicker(m.refreshInterval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				if err := m.Refresh(ctx); err != nil {
+					m.logger.Warn("Failed to refresh secrets", "error", err)
+				}
+			}
+		}
+	}()
+}
@@ -0,0 +1,62 @@
+package secrets
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// nopLogger discards all log output
+type nopLogger struct{}
+
+func (nopLogger) Debug(msg string, keysAndValues ...interface{}) {}
+func (nopLogger) Info(msg string, keysAndValues ...interface{})  {}
+func (nopLogger) Warn(msg string, keysAndValues ...interface{})  {}
+func (nopLogger) Error(msg string, keysAndValues ...interface{}) {}
+func (nopLogger) Fatal(msg string, keysAndValues ...interface{}) {}
+
+func TestManager_RefreshNotifiesOnRotation(t *testing.T) {
+	dir := t.TempDir()
+	secretPath := filepath.Join(dir, AuthSecretKey)
+	require.NoError(t, os.WriteFile(secretPath, []byte("first-key\n"), 0o600))
+
+	manager := NewManager(NewFileProvider(dir, Names), time.Minute, nopLogger{})
+
+	var rotated []string
+	manager.OnChange(AuthSecretKey, func(value string) {
+		rotated = append(rotated, value)
+	})
+
+	// Initial load
+	require.NoError(t, manager.Refresh(context.Background()))
+	value, ok := manager.Get(AuthSecretKey)
+	assert.True(t, ok)
+	assert.Equal(t, "first-key", value)
+
+	// Unchanged secrets do not notify subscribers
+	require.NoError(t, manager.Refresh(context.Background()))
+	assert.Equal(t, []string{"first-key"}, rotated)
+
+	// Rotated secrets are picked up on the next refresh
+	require.NoError(t, os.WriteFile(secretPath, []byte("second-key"), 0o600))
+	require.NoError(t, manager.Refresh(context.Background()))
+	assert.Equal(t, []string{"first-key", "second-key"}, rotated)
+
+	// Missing files are ignored
+	_, ok = manager.Get(DatabasePassword)
+	assert.False(t, ok)
+}
+
+func TestEnvProvider_Fetch(t *testing.T) {
+	t.Setenv("API_GATEWAY_SECRET_REDIS_PASSWORD", "redis-pass")
+
+	values, err := NewEnvProvider(envPrefix, Names).Fetch(context.Background())
+
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{RedisPassword: "redis-pass"}, values)
+}
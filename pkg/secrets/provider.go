@@ -0,0 +1,87 @@
+package secrets
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Well-known secret names used by the gateway
+const (
+	AuthSecretKey    = "auth_secret_key"
+	DatabasePassword = "database_password"
+	RedisPassword    = "redis_password"
+	MailPassword     = "mail_password"
+)
+
+// ErrSecretNotFound is returned when a provider does not hold the requested secret
+var ErrSecretNotFound = errors.New("secret not found")
+
+// Provider defines the interface for a secret backend
+type Provider interface {
+	// Fetch returns the current values of all secrets known to the provider
+	Fetch(ctx context.Context) (map[string]string, error)
+}
+
+// EnvProvider reads secrets from environment variables named <prefix>_<SECRET_NAME>
+type EnvProvider struct {
+	prefix string
+	names  []string
+}
+
+// NewEnvProvider creates a new EnvProvider instance
+func NewEnvProvider(prefix string, names []string) *EnvProvider {
+	return &EnvProvider{
+		prefix: prefix,
+		names:  names,
+	}
+}
+
+// Fetch returns the secrets found in the environment
+func (p *EnvProvider) Fetch(ctx context.Context) (map[string]string, error) {
+	values := make(map[string]string)
+	for _, name := range p.names {
+		key := strings.ToUpper(name)
+		if p.prefix != "" {
+			key = p.prefix + "_" + key
+		}
+		if value, ok := os.LookupEnv(key); ok {
+			values[name] = value
+		}
+	}
+	return values, nil
+}
+
+// FileProvider reads one secret per file from a directory, e.g. Docker or Kubernetes
+// mounted secrets. Files are re-read on every fetch so rotated secrets are picked up.
+type FileProvider struct {
+	directory string
+	names     []string
+}
+
+// NewFileProvider creates a new FileProvider instance
+func NewFileProvider(directory string, names []string) *FileProvider {
+	return &FileProvider{
+		directory: directory,
+		names:     names,
+	}
+}
+
+// Fetch returns the secrets found in the directory
+func (p *FileProvider) Fetch(ctx context.Context) (map[string]string, error) {
+	values := make(map[string]string)
+	for _, name := range p.names {
+		data, err := os.ReadFile(filepath.Join(p.directory, name))
+		if err != nil {
+			if errors.Is(err, os.ErrNotExist) {
+				continue
+			}
+			return nil, fmt.Errorf("failed to read secret %s: %w", name, err)
+		}
+		values[name] = strings.TrimSpace(string(data))
+	}
+	return values, nil
+}
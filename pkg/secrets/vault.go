@@ -0,0 +1,78 @@
+package secrets
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// VaultProvider reads secrets from a HashiCorp Vault KV v2 secret
+type VaultProvider struct {
+	address   string
+	token     string
+	mountPath string
+	path      string
+	client    *http.Client
+}
+
+// NewVaultProvider creates a new VaultProvider instance
+func NewVaultProvider(address, token, mountPath, path string, timeout time.Duration) *VaultProvider {
+	return &VaultProvider{
+		address:   strings.TrimRight(address, "/"),
+		token:     token,
+		mountPath: strings.Trim(mountPath, "/"),
+		path:      strings.Trim(path, "/"),
+		client:    &http.Client{Timeout: timeout},
+	}
+}
+
+// vaultKVResponse is the subset of the KV v2 read response used by the provider
+type vaultKVResponse struct {
+	Data struct {
+		Data map[string]interface{} `json:"data"`
+	} `json:"data"`
+}
+
+// Fetch returns the key/value pairs stored in the configured Vault secret
+func (p *VaultProvider) Fetch(ctx context.Context) (map[string]string, error) {
+	url := fmt.Sprintf("%s/v1/%s/data/%s", p.address, p.mountPath, p.path)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create vault request: %w", err)
+	}
+	req.Header.Set("X-Vault-Token", p.token)
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read vault secret: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, ErrSecretNotFound
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("vault returned status %d", resp.StatusCode)
+	}
+
+	var body vaultKVResponse
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return nil, fmt.Errorf("failed to decode vault response: %w", err)
+	}
+
+	return stringValues(body.Data.Data), nil
+}
+
+// stringValues keeps only the string values of a decoded JSON object
+func stringValues(data map[string]interface{}) map[string]string {
+	values := make(map[string]string, len(data))
+	for key, value := range data {
+		if s, ok := value.(string); ok {
+			values[key] = s
+		}
+	}
+	return values
+}
@@ -0,0 +1,153 @@
+package sigv4
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	algorithm       = "AWS4-HMAC-SHA256"
+	timeFormat      = "20060102T150405Z"
+	shortTimeFormat = "20060102"
+)
+
+// Credentials holds the AWS credentials used to sign a request
+type Credentials struct {
+	AccessKeyID     string
+	SecretAccessKey string
+	SessionToken    string
+}
+
+// Sign signs an HTTP request in place using AWS Signature Version 4
+func Sign(req *http.Request, body []byte, creds Credentials, region, service string, now time.Time) {
+	now = now.UTC()
+	amzDate := now.Format(timeFormat)
+	payloadHash := hashHex(body)
+
+	req.Header.Set("X-Amz-Date", amzDate)
+	req.Header.Set("X-Amz-Content-Sha256", payloadHash)
+	if creds.SessionToken != "" {
+		req.Header.Set("X-Amz-Security-Token", creds.SessionToken)
+	}
+	if req.Header.Get("Host") == "" {
+		req.Header.Set("Host", req.URL.Host)
+	}
+
+	signedHeaders, canonicalRequest := buildCanonicalRequest(req, payloadHash)
+	scope, signature := sign(canonicalRequest, creds.SecretAccessKey, now, region, service)
+
+	req.Header.Set("Authorization", fmt.Sprintf(
+		"%s Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		algorithm, creds.AccessKeyID, scope, signedHeaders, signature,
+	))
+}
+
+// buildCanonicalRequest returns the signed headers and canonical request of a request whose
+// payload has the given hash
+func buildCanonicalRequest(req *http.Request, payloadHash string) (string, string) {
+	signedHeaders, canonicalHeaders := canonicalizeHeaders(req.Header)
+	return signedHeaders, strings.Join([]string{
+		req.Method,
+		canonicalPath(req.URL),
+		canonicalQuery(req.URL.Query()),
+		canonicalHeaders,
+		signedHeaders,
+		payloadHash,
+	}, "\n")
+}
+
+// sign returns the credential scope and signature of a canonical request made at a time
+func sign(canonicalRequest, secret string, now time.Time, region, service string) (string, string) {
+	scope := fmt.Sprintf("%s/%s/%s/aws4_request", now.Format(shortTimeFormat), region, service)
+	stringToSign := strings.Join([]string{
+		algorithm,
+		now.Format(timeFormat),
+		scope,
+		hashHex([]byte(canonicalRequest)),
+	}, "\n")
+
+	signingKey := deriveSigningKey(secret, now.Format(shortTimeFormat), region, service)
+	return scope, hex.EncodeToString(hmacSHA256(signingKey, []byte(stringToSign)))
+}
+
+func deriveSigningKey(secret, date, region, service string) []byte {
+	key := hmacSHA256([]byte("AWS4"+secret), []byte(date))
+	key = hmacSHA256(key, []byte(region))
+	key = hmacSHA256(key, []byte(service))
+	return hmacSHA256(key, []byte("aws4_request"))
+}
+
+func canonicalizeHeaders(header http.Header) (string, string) {
+	names := make([]string, 0, len(header))
+	for name := range header {
+		lower := strings.ToLower(name)
+		// The Authorization header is the output of the signature, never an input
+		if lower == "authorization" {
+			continue
+		}
+		names = append(names, lower)
+	}
+	sort.Strings(names)
+
+	var canonical strings.Builder
+	for _, name := range names {
+		values := header.Values(name)
+		trimmed := make([]string, len(values))
+		for i, v := range values {
+			trimmed[i] = strings.Join(strings.Fields(v), " ")
+		}
+		canonical.WriteString(name + ":" + strings.Join(trimmed, ",") + "\n")
+	}
+
+	return strings.Join(names, ";"), canonical.String()
+}
+
+func canonicalPath(u *url.URL) string {
+	path := u.EscapedPath()
+	if path == "" {
+		return "/"
+	}
+	return path
+}
+
+func canonicalQuery(query url.Values) string {
+	keys := make([]string, 0, len(query))
+	for key := range query {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	pairs := make([]string, 0, len(keys))
+	for _, key := range keys {
+		values := append([]string(nil), query[key]...)
+		sort.Strings(values)
+		for _, value := range values {
+			pairs = append(pairs, escape(key)+"="+escape(value))
+		}
+	}
+	return strings.Join(pairs, "&")
+}
+
+// escape percent-encodes a string as required by SigV4, which differs from
+// url.QueryEscape in its handling of spaces and '~'
+func escape(s string) string {
+	return strings.ReplaceAll(url.QueryEscape(s), "+", "%20")
+}
+
+func hashHex(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+func hmacSHA256(key, data []byte) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write(data)
+	return mac.Sum(nil)
+}
@@ -0,0 +1,132 @@
+package sigv4
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// The credentials, time and scope of the AWS Signature Version 4 test suite
+var (
+	suiteCredentials = Credentials{
+		AccessKeyID:     "AKIDEXAMPLE",
+		SecretAccessKey: "wJalrXUtnFEMI/K7MDENG+bPxRfiCYEXAMPLEKEY",
+	}
+	suiteTime = time.Date(2015, 8, 30, 12, 36, 0, 0, time.UTC)
+)
+
+const emptyPayloadHash = "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855"
+
+// TestSign_TestSuite checks the canonical requests and signatures of GET requests without a
+// body from the AWS test suite, which sign only the Host and X-Amz-Date headers
+func TestSign_TestSuite(t *testing.T) {
+	tests := []struct {
+		name      string
+		target    string
+		path      string
+		query     string
+		signature string
+	}{
+		{
+			name:      "get-vanilla",
+			target:    "/",
+			path:      "/",
+			signature: "5fa00fa31553b73ebf1942676e86291e8372ff2a2260956d9b8aae1d763fbf31",
+		},
+		{
+			name:      "get-vanilla-empty-query-key",
+			target:    "/?Param1=value1",
+			path:      "/",
+			query:     "Param1=value1",
+			signature: "a67d582fa61cc504c4bae71f336f98b97f1ea3c7a6bfe1b6e45aec72011b9aeb",
+		},
+		{
+			name:      "get-vanilla-query-order-key-case",
+			target:    "/?Param2=value2&Param1=value1",
+			path:      "/",
+			query:     "Param1=value1&Param2=value2",
+			signature: "b97d918cfa904a5beff61c982a1b6f458b799221646efd99d3219ec94cdf2500",
+		},
+		{
+			name:      "get-vanilla-query-order-key",
+			target:    "/?Param1=value2&Param1=Value1",
+			path:      "/",
+			query:     "Param1=Value1&Param1=value2",
+			signature: "eedbc4e291e521cf13422ffca22be7d2eb8146eecf653089df300a15b2382bd1",
+		},
+		{
+			name:      "get-vanilla-query-order-value",
+			target:    "/?Param1=value2&Param1=value1",
+			path:      "/",
+			query:     "Param1=value1&Param1=value2",
+			signature: "5772eed61e12b33fae39ee5e7012498b51d56abc0abb7c60486157bd471c4694",
+		},
+		{
+			name:      "get-vanilla-query-unreserved",
+			target:    "/?-._~0123456789ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz=-._~0123456789ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz",
+			path:      "/",
+			query:     "-._~0123456789ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz=-._~0123456789ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz",
+			signature: "9c3e54bfcdf0b19771a7f523ee5669cdf59bc7cc0884027167c21bb143a40197",
+		},
+		{
+			name:      "get-space",
+			target:    "/example%20space/",
+			path:      "/example%20space/",
+			signature: "652487583200325589f1fba4c7e578f72c47cb61beeca81406b39ddec1366741",
+		},
+		{
+			name:      "get-utf8",
+			target:    "/%E1%88%B4",
+			path:      "/%E1%88%B4",
+			signature: "8318018e0b0f223aa2bbf98705b62bb787dc9c0e678f255a891fd03141be5d85",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, err := http.NewRequest(http.MethodGet, "https://example.amazonaws.com"+tt.target, nil)
+			require.NoError(t, err)
+			req.Header.Set("Host", "example.amazonaws.com")
+			req.Header.Set("X-Amz-Date", "20150830T123600Z")
+
+			signedHeaders, canonicalRequest := buildCanonicalRequest(req, emptyPayloadHash)
+			assert.Equal(t, "host;x-amz-date", signedHeaders)
+			assert.Equal(t, strings.Join([]string{
+				"GET",
+				tt.path,
+				tt.query,
+				"host:example.amazonaws.com",
+				"x-amz-date:20150830T123600Z",
+				"",
+				"host;x-amz-date",
+				emptyPayloadHash,
+			}, "\n"), canonicalRequest)
+
+			scope, signature := sign(canonicalRequest, suiteCredentials.SecretAccessKey, suiteTime, "us-east-1", "service")
+			assert.Equal(t, "20150830/us-east-1/service/aws4_request", scope)
+			assert.Equal(t, tt.signature, signature)
+		})
+	}
+}
+
+func TestSign_EmptyBody(t *testing.T) {
+	req, err := http.NewRequest(http.MethodGet, "https://example.amazonaws.com/?Param2=value2&Param1=value1", nil)
+	require.NoError(t, err)
+	req.Header.Set("Authorization", "Bearer client-token")
+
+	Sign(req, nil, suiteCredentials, "us-east-1", "service", suiteTime)
+
+	assert.Equal(t, "20150830T123600Z", req.Header.Get("X-Amz-Date"))
+	assert.Equal(t, emptyPayloadHash, req.Header.Get("X-Amz-Content-Sha256"))
+	assert.Equal(t, "example.amazonaws.com", req.Header.Get("Host"))
+
+	// The Authorization header being replaced is not signed
+	_, canonicalRequest := buildCanonicalRequest(req, emptyPayloadHash)
+	_, signature := sign(canonicalRequest, suiteCredentials.SecretAccessKey, suiteTime, "us-east-1", "service")
+	assert.Equal(t, "AWS4-HMAC-SHA256 Credential=AKIDEXAMPLE/20150830/us-east-1/service/aws4_request, "+
+		"SignedHeaders=host;x-amz-content-sha256;x-amz-date, Signature="+signature, req.Header.Get("Authorization"))
+}
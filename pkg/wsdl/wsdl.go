@@ -0,0 +1,333 @@
+// Package wsdl reads the SOAP operations of WSDL 1.1 documents and generates templates of their
+// requests, in which each simple value is a {{body.*}} placeholder
+package wsdl
+
+import (
+	"encoding/xml"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// soap12Namespace is the namespace of the SOAP 1.2 binding extensions
+const soap12Namespace = "http://schemas.xmlsoap.org/wsdl/soap12/"
+
+// maxDepth bounds the nesting of generated templates, as schema types may be recursive
+const maxDepth = 8
+
+// Operation is a SOAP operation of a WSDL document
+type Operation struct {
+	// Name is the name of the operation
+	Name string
+	// Address is the URL of the port serving the operation
+	Address string
+	// Version is "1.1" or "1.2"
+	Version string
+	// Action is the SOAP action of the operation
+	Action string
+	// Template is the content of the SOAP body of the request
+	Template string
+}
+
+type definitions struct {
+	TargetNamespace string     `xml:"targetNamespace,attr"`
+	Schemas         []schema   `xml:"types>schema"`
+	Messages        []message  `xml:"message"`
+	Bindings        []binding  `xml:"binding"`
+	Services        []service  `xml:"service"`
+	PortTypes       []portType `xml:"portType"`
+}
+
+type schema struct {
+	TargetNamespace    string        `xml:"targetNamespace,attr"`
+	ElementFormDefault string        `xml:"elementFormDefault,attr"`
+	Elements           []element     `xml:"element"`
+	ComplexTypes       []complexType `xml:"complexType"`
+}
+
+type element struct {
+	Name        string       `xml:"name,attr"`
+	Type        string       `xml:"type,attr"`
+	Ref         string       `xml:"ref,attr"`
+	MaxOccurs   string       `xml:"maxOccurs,attr"`
+	ComplexType *complexType `xml:"complexType"`
+}
+
+type complexType struct {
+	Name     string    `xml:"name,attr"`
+	Sequence []element `xml:"sequence>element"`
+	All      []element `xml:"all>element"`
+	Choice   []element `xml:"choice>element"`
+	// Extension adds elements to a base type
+	Extension *struct {
+		Base     string    `xml:"base,attr"`
+		Sequence []element `xml:"sequence>element"`
+	} `xml:"complexContent>extension"`
+}
+
+type message struct {
+	Name  string `xml:"name,attr"`
+	Parts []struct {
+		Name    string `xml:"name,attr"`
+		Element string `xml:"element,attr"`
+		Type    string `xml:"type,attr"`
+	} `xml:"part"`
+}
+
+type portType struct {
+	Name       string `xml:"name,attr"`
+	Operations []struct {
+		Name  string `xml:"name,attr"`
+		Input struct {
+			Message string `xml:"message,attr"`
+		} `xml:"input"`
+	} `xml:"operation"`
+}
+
+type binding struct {
+	Name string `xml:"name,attr"`
+	Type string `xml:"type,attr"`
+	// SOAP holds the soap:binding or soap12:binding extension, absent from HTTP bindings
+	SOAP []struct {
+		XMLName xml.Name
+		Style   string `xml:"style,attr"`
+	} `xml:"binding"`
+	Operations []struct {
+		Name string `xml:"name,attr"`
+		SOAP []struct {
+			SOAPAction string `xml:"soapAction,attr"`
+			Style      string `xml:"style,attr"`
+		} `xml:"operation"`
+	} `xml:"operation"`
+}
+
+type service struct {
+	Ports []struct {
+		Binding string `xml:"binding,attr"`
+		Address []struct {
+			Location string `xml:"location,attr"`
+		} `xml:"address"`
+	} `xml:"port"`
+}
+
+// Operations returns the SOAP operations of a WSDL 1.1 document, sorted by name and version
+func Operations(document []byte) ([]Operation, error) {
+	var defs definitions
+	if err := xml.Unmarshal(document, &defs); err != nil {
+		return nil, fmt.Errorf("invalid WSDL document: %w", err)
+	}
+
+	var operations []Operation
+	for _, b := range defs.Bindings {
+		if len(b.SOAP) == 0 {
+			continue
+		}
+		version := "1.1"
+		if b.SOAP[0].XMLName.Space == soap12Namespace {
+			version = "1.2"
+		}
+		address := defs.address(b.Name)
+		pt := defs.portType(localName(b.Type))
+		if pt == nil {
+			return nil, fmt.Errorf("binding %s refers to an unknown port type %s", b.Name, b.Type)
+		}
+
+		for _, op := range b.Operations {
+			style := b.SOAP[0].Style
+			action := ""
+			if len(op.SOAP) > 0 {
+				action = op.SOAP[0].SOAPAction
+				if op.SOAP[0].Style != "" {
+					style = op.SOAP[0].Style
+				}
+			}
+			template, err := defs.template(pt, op.Name, style)
+			if err != nil {
+				return nil, err
+			}
+			operations = append(operations, Operation{
+				Name:     op.Name,
+				Address:  address,
+				Version:  version,
+				Action:   action,
+				Template: template,
+			})
+		}
+	}
+	if len(operations) == 0 {
+		return nil, fmt.Errorf("WSDL document has no SOAP binding")
+	}
+
+	sort.Slice(operations, func(i, j int) bool {
+		if operations[i].Name != operations[j].Name {
+			return operations[i].Name < operations[j].Name
+		}
+		return operations[i].Version < operations[j].Version
+	})
+	return operations, nil
+}
+
+// template generates the request body of an operation. Document operations send the element of
+// their message part; rpc operations wrap their parts in an element named after the operation.
+func (d *definitions) template(pt *portType, operation string, style string) (string, error) {
+	var input string
+	for _, op := range pt.Operations {
+		if op.Name == operation {
+			input = localName(op.Input.Message)
+		}
+	}
+	msg := d.message(input)
+	if msg == nil {
+		return "", fmt.Errorf("operation %s has no input message", operation)
+	}
+
+	var out strings.Builder
+	if style == "rpc" {
+		fmt.Fprintf(&out, `<tns:%s xmlns:tns="%s">`, operation, d.TargetNamespace)
+		for _, part := range msg.Parts {
+			d.writeElement(&out, &element{Name: part.Name, Type: part.Type}, "", "body", 0)
+		}
+		fmt.Fprintf(&out, "</tns:%s>", operation)
+		return out.String(), nil
+	}
+
+	for _, part := range msg.Parts {
+		if part.Element == "" {
+			return "", fmt.Errorf("document operation %s has a part without an element", operation)
+		}
+		el, s := d.element(localName(part.Element))
+		if el == nil {
+			return "", fmt.Errorf("operation %s refers to an unknown element %s", operation, part.Element)
+		}
+		fmt.Fprintf(&out, `<tns:%s xmlns:tns="%s">`, el.Name, s.TargetNamespace)
+		prefix := ""
+		if s.ElementFormDefault == "qualified" {
+			prefix = "tns:"
+		}
+		d.writeChildren(&out, d.children(el), prefix, "body", 1)
+		fmt.Fprintf(&out, "</tns:%s>", el.Name)
+	}
+	return out.String(), nil
+}
+
+// writeElement writes an element whose simple values are placeholders under path
+func (d *definitions) writeElement(out *strings.Builder, el *element, prefix string, path string, depth int) {
+	if el.Ref != "" {
+		if ref, _ := d.element(localName(el.Ref)); ref != nil {
+			referenced := *ref
+			referenced.MaxOccurs = el.MaxOccurs
+			el = &referenced
+		}
+	}
+	path += "." + el.Name
+	// Repeated elements are templated once, from the first item of a JSON array
+	if el.MaxOccurs != "" && el.MaxOccurs != "1" && el.MaxOccurs != "0" {
+		path += ".0"
+	}
+
+	fmt.Fprintf(out, "<%s%s>", prefix, el.Name)
+	if children := d.children(el); children != nil {
+		d.writeChildren(out, children, prefix, path, depth+1)
+	} else {
+		fmt.Fprintf(out, "{{%s}}", path)
+	}
+	fmt.Fprintf(out, "</%s%s>", prefix, el.Name)
+}
+
+func (d *definitions) writeChildren(out *strings.Builder, children []element, prefix string, path string, depth int) {
+	if depth > maxDepth {
+		return
+	}
+	for i := range children {
+		d.writeElement(out, &children[i], prefix, path, depth)
+	}
+}
+
+// children returns the child elements of a complex element, nil for simple elements
+func (d *definitions) children(el *element) []element {
+	ct := el.ComplexType
+	if ct == nil && el.Type != "" {
+		ct = d.complexType(localName(el.Type))
+	}
+	if ct == nil {
+		return nil
+	}
+	// Types without child elements, such as those with simple content, hold a value
+	if elements := d.typeElements(ct, 0); len(elements) > 0 {
+		return elements
+	}
+	return nil
+}
+
+func (d *definitions) typeElements(ct *complexType, depth int) []element {
+	elements := make([]element, 0, len(ct.Sequence)+len(ct.All)+len(ct.Choice))
+	if ct.Extension != nil && depth < maxDepth {
+		if base := d.complexType(localName(ct.Extension.Base)); base != nil {
+			elements = append(elements, d.typeElements(base, depth+1)...)
+		}
+		elements = append(elements, ct.Extension.Sequence...)
+	}
+	elements = append(elements, ct.Sequence...)
+	elements = append(elements, ct.All...)
+	return append(elements, ct.Choice...)
+}
+
+func (d *definitions) element(name string) (*element, *schema) {
+	for i := range d.Schemas {
+		for j := range d.Schemas[i].Elements {
+			if d.Schemas[i].Elements[j].Name == name {
+				return &d.Schemas[i].Elements[j], &d.Schemas[i]
+			}
+		}
+	}
+	return nil, nil
+}
+
+func (d *definitions) complexType(name string) *complexType {
+	for i := range d.Schemas {
+		for j := range d.Schemas[i].ComplexTypes {
+			if d.Schemas[i].ComplexTypes[j].Name == name {
+				return &d.Schemas[i].ComplexTypes[j]
+			}
+		}
+	}
+	return nil
+}
+
+func (d *definitions) message(name string) *message {
+	for i := range d.Messages {
+		if d.Messages[i].Name == name {
+			return &d.Messages[i]
+		}
+	}
+	return nil
+}
+
+func (d *definitions) portType(name string) *portType {
+	for i := range d.PortTypes {
+		if d.PortTypes[i].Name == name {
+			return &d.PortTypes[i]
+		}
+	}
+	return nil
+}
+
+// address returns the location of the first port using a binding
+func (d *definitions) address(bindingName string) string {
+	for _, s := range d.Services {
+		for _, port := range s.Ports {
+			if localName(port.Binding) == bindingName && len(port.Address) > 0 {
+				return port.Address[0].Location
+			}
+		}
+	}
+	return ""
+}
+
+// localName strips the namespace prefix of a qualified name such as "tns:GetOrder"
+func localName(qname string) string {
+	if i := strings.LastIndex(qname, ":"); i >= 0 {
+		return qname[i+1:]
+	}
+	return qname
+}
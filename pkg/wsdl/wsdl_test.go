@@ -0,0 +1,82 @@
+package wsdl
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const ordersWSDL = `<?xml version="1.0" encoding="utf-8"?>
+<wsdl:definitions xmlns:wsdl="http://schemas.xmlsoap.org/wsdl/" xmlns:soap="http://schemas.xmlsoap.org/wsdl/soap/"
+    xmlns:soap12="http://schemas.xmlsoap.org/wsdl/soap12/" xmlns:xsd="http://www.w3.org/2001/XMLSchema"
+    xmlns:tns="urn:orders" targetNamespace="urn:orders">
+  <wsdl:types>
+    <xsd:schema targetNamespace="urn:orders" elementFormDefault="qualified">
+      <xsd:complexType name="Address">
+        <xsd:sequence>
+          <xsd:element name="city" type="xsd:string"/>
+        </xsd:sequence>
+      </xsd:complexType>
+      <xsd:element name="CreateOrder">
+        <xsd:complexType>
+          <xsd:sequence>
+            <xsd:element name="customer" type="xsd:string"/>
+            <xsd:element name="address" type="tns:Address"/>
+            <xsd:element name="sku" type="xsd:string" maxOccurs="unbounded"/>
+          </xsd:sequence>
+        </xsd:complexType>
+      </xsd:element>
+    </xsd:schema>
+  </wsdl:types>
+  <wsdl:message name="CreateOrderRequest"><wsdl:part name="parameters" element="tns:CreateOrder"/></wsdl:message>
+  <wsdl:message name="GetStatusRequest"><wsdl:part name="id" type="xsd:string"/></wsdl:message>
+  <wsdl:portType name="OrdersPort">
+    <wsdl:operation name="CreateOrder"><wsdl:input message="tns:CreateOrderRequest"/></wsdl:operation>
+    <wsdl:operation name="GetStatus"><wsdl:input message="tns:GetStatusRequest"/></wsdl:operation>
+  </wsdl:portType>
+  <wsdl:binding name="OrdersSoap" type="tns:OrdersPort">
+    <soap:binding style="document" transport="http://schemas.xmlsoap.org/soap/http"/>
+    <wsdl:operation name="CreateOrder"><soap:operation soapAction="urn:orders/CreateOrder"/></wsdl:operation>
+    <wsdl:operation name="GetStatus"><soap:operation soapAction="urn:orders/GetStatus" style="rpc"/></wsdl:operation>
+  </wsdl:binding>
+  <wsdl:binding name="OrdersSoap12" type="tns:OrdersPort">
+    <soap12:binding style="document" transport="http://schemas.xmlsoap.org/soap/http"/>
+    <wsdl:operation name="CreateOrder"><soap12:operation soapAction="urn:orders/CreateOrder"/></wsdl:operation>
+  </wsdl:binding>
+  <wsdl:service name="Orders">
+    <wsdl:port name="OrdersSoap" binding="tns:OrdersSoap"><soap:address location="http://erp.internal/ws/orders"/></wsdl:port>
+    <wsdl:port name="OrdersSoap12" binding="tns:OrdersSoap12"><soap12:address location="http://erp.internal/ws12/orders"/></wsdl:port>
+  </wsdl:service>
+</wsdl:definitions>`
+
+func TestOperations(t *testing.T) {
+	operations, err := Operations([]byte(ordersWSDL))
+	require.NoError(t, err)
+	require.Len(t, operations, 3)
+
+	// 1. Document operations send their element, with placeholders for every simple value
+	assert.Equal(t, Operation{
+		Name:    "CreateOrder",
+		Address: "http://erp.internal/ws/orders",
+		Version: "1.1",
+		Action:  "urn:orders/CreateOrder",
+		Template: `<tns:CreateOrder xmlns:tns="urn:orders"><tns:customer>{{body.customer}}</tns:customer>` +
+			`<tns:address><tns:city>{{body.address.city}}</tns:city></tns:address><tns:sku>{{body.sku.0}}</tns:sku></tns:CreateOrder>`,
+	}, operations[0])
+
+	// 2. SOAP 1.2 bindings are told apart
+	assert.Equal(t, "1.2", operations[1].Version)
+	assert.Equal(t, "http://erp.internal/ws12/orders", operations[1].Address)
+
+	// 3. Rpc operations wrap their parts in an element named after the operation
+	assert.Equal(t, `<tns:GetStatus xmlns:tns="urn:orders"><id>{{body.id}}</id></tns:GetStatus>`, operations[2].Template)
+}
+
+func TestOperations_InvalidDocument(t *testing.T) {
+	_, err := Operations([]byte("<definitions/>"))
+	assert.Error(t, err)
+
+	_, err = Operations([]byte("not xml"))
+	assert.Error(t, err)
+}